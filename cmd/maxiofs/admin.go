@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/spf13/cobra"
+)
+
+// newAdminCmd groups the offline credential-recovery subcommands. They work on
+// the data directory directly — filesystem access is the authorization — so a
+// lost admin password or JWT secret can be recovered without DB surgery and
+// without exposing any network endpoint.
+func newAdminCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Offline administration of local credentials",
+	}
+	cmd.AddCommand(newAdminResetPasswordCmd())
+	cmd.AddCommand(newAdminRegenerateJWTSecretCmd())
+	return cmd
+}
+
+func newAdminResetPasswordCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset-password",
+		Short: "Reset a local user's console password",
+		Long: `Sets a new console password for a local user directly in the auth
+database under --data-dir and clears any failed-login lockout on the account.
+
+Without --password-file a random password is generated and printed. OAuth and
+LDAP users are rejected: their password lives in the identity provider.
+
+The server may keep running: logins read the password from the database.`,
+		Example: `  maxiofs admin reset-password --data-dir /var/lib/maxiofs
+  maxiofs admin reset-password --data-dir /var/lib/maxiofs --username alice --password-file /root/new-pass.txt`,
+		RunE: runAdminResetPassword,
+	}
+	cmd.Flags().String("username", "admin", "User whose password is reset")
+	cmd.Flags().String("password-file", "", "File containing the new password (otherwise one is generated)")
+	return cmd
+}
+
+func runAdminResetPassword(cmd *cobra.Command, args []string) error {
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	if dataDir == "" {
+		return fmt.Errorf("--data-dir is required")
+	}
+	username, _ := cmd.Flags().GetString("username")
+	passwordFile, _ := cmd.Flags().GetString("password-file")
+
+	var password string
+	if passwordFile != "" {
+		data, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read password file: %w", err)
+		}
+		password = strings.TrimSpace(string(data))
+	} else {
+		generated, err := auth.GenerateRecoveryPassword()
+		if err != nil {
+			return err
+		}
+		password = generated
+	}
+
+	if err := auth.ResetUserPassword(dataDir, username, password); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Password for user %q reset.\n", username)
+	if passwordFile == "" {
+		fmt.Fprintf(out, "New password: %s\n", password)
+	}
+	fmt.Fprintln(out, "Sign in to the console and change it.")
+	return nil
+}
+
+func newAdminRegenerateJWTSecretCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "regenerate-jwt-secret",
+		Short: "Replace the console JWT signing secret",
+		Long: `Generates a new JWT signing secret and stores it in the auth database
+under --data-dir. Restart the server afterwards: every existing console
+session is signed with the old secret and becomes invalid.
+
+An explicit auth.jwt_secret in the configuration file always wins at startup;
+remove it (or set it to the printed value) for the new secret to take effect.
+In a cluster the secret is only copied to a node when it joins: set the printed
+value as auth.jwt_secret on the other nodes so sessions keep working across them.`,
+		Example: `  maxiofs admin regenerate-jwt-secret --data-dir /var/lib/maxiofs`,
+		RunE:    runAdminRegenerateJWTSecret,
+	}
+}
+
+func runAdminRegenerateJWTSecret(cmd *cobra.Command, args []string) error {
+	dataDir, _ := cmd.Flags().GetString("data-dir")
+	if dataDir == "" {
+		return fmt.Errorf("--data-dir is required")
+	}
+
+	secret, err := auth.RegenerateJWTSecret(dataDir)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "New JWT secret: %s\n", secret)
+	fmt.Fprintln(out, "Restart the server for it to take effect; existing console sessions are invalidated.")
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/settings"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runAdminCmd executes `maxiofs admin <args>` and returns its output.
+func runAdminCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	root := &cobra.Command{Use: "maxiofs"}
+	root.PersistentFlags().StringP("data-dir", "d", "", "Data directory path")
+	root.AddCommand(newAdminCmd())
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(append([]string{"admin"}, args...))
+	err := root.Execute()
+	return out.String(), err
+}
+
+// newAuthManager opens the auth database under dataDir the way the server
+// does on startup (creating the default admin/admin user on first run).
+func newAuthManager(dataDir string) auth.Manager {
+	return auth.NewManager(config.AuthConfig{
+		EnableAuth:             true,
+		JWTSecret:              "auto-generated-secret-for-testing",
+		JWTSecretAutoGenerated: true,
+	}, dataDir)
+}
+
+func TestAdminResetPassword_GeneratedPasswordAuthenticates(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	newAuthManager(dataDir)
+
+	out, err := runAdminCmd(t, "reset-password", "--data-dir", dataDir)
+	require.NoError(t, err, out)
+	match := regexp.MustCompile(`New password: (\S+)`).FindStringSubmatch(out)
+	require.Len(t, match, 2, "the generated password must be printed: %s", out)
+
+	manager := newAuthManager(dataDir)
+	user, err := manager.ValidateConsoleCredentials(ctx, "admin", match[1])
+	require.NoError(t, err)
+	assert.Equal(t, "admin", user.Username)
+	_, err = manager.ValidateConsoleCredentials(ctx, "admin", "admin")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials, "the old password must stop working")
+}
+
+func TestAdminResetPassword_FromFile(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	newAuthManager(dataDir)
+
+	passwordFile := filepath.Join(t.TempDir(), "password.txt")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("Recovered-Passw0rd\n"), 0600))
+
+	out, err := runAdminCmd(t, "reset-password", "--data-dir", dataDir, "--username", "admin", "--password-file", passwordFile)
+	require.NoError(t, err, out)
+	assert.NotContains(t, out, "Recovered-Passw0rd", "a password read from a file is not echoed")
+
+	_, err = newAuthManager(dataDir).ValidateConsoleCredentials(ctx, "admin", "Recovered-Passw0rd")
+	assert.NoError(t, err)
+}
+
+func TestAdminResetPassword_Guards(t *testing.T) {
+	dataDir := t.TempDir()
+
+	_, err := runAdminCmd(t, "reset-password")
+	assert.ErrorContains(t, err, "--data-dir is required")
+
+	_, err = runAdminCmd(t, "reset-password", "--data-dir", dataDir)
+	assert.ErrorContains(t, err, "no MaxIOFS database", "a wrong data directory must not create a new database")
+	_, statErr := os.Stat(filepath.Join(dataDir, "db", "maxiofs.db"))
+	assert.True(t, os.IsNotExist(statErr))
+
+	newAuthManager(dataDir)
+	_, err = runAdminCmd(t, "reset-password", "--data-dir", dataDir, "--username", "nobody")
+	assert.ErrorContains(t, err, `user "nobody" not found`)
+
+	shortFile := filepath.Join(t.TempDir(), "short.txt")
+	require.NoError(t, os.WriteFile(shortFile, []byte("short"), 0600))
+	_, err = runAdminCmd(t, "reset-password", "--data-dir", dataDir, "--password-file", shortFile)
+	assert.ErrorContains(t, err, "at least 8 characters")
+}
+
+func TestAdminResetPassword_AppliesPasswordRules(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	manager := newAuthManager(dataDir)
+	db, ok := manager.GetDB().(*sql.DB)
+	require.True(t, ok)
+	settingsManager, err := settings.NewManager(db, logrus.StandardLogger())
+	require.NoError(t, err)
+
+	reset := func(password string) error {
+		passwordFile := filepath.Join(t.TempDir(), "password.txt")
+		require.NoError(t, os.WriteFile(passwordFile, []byte(password), 0600))
+		_, err := runAdminCmd(t, "reset-password", "--data-dir", dataDir, "--username", "admin", "--password-file", passwordFile)
+		return err
+	}
+
+	// Uppercase letters and numbers are required by default
+	assert.ErrorContains(t, reset("only-lowercase-letters"), "uppercase letter")
+
+	require.NoError(t, settingsManager.Set("security.password_require_lowercase", "true"))
+	assert.ErrorContains(t, reset("NO-LOWERCASE-123"), "lowercase letter")
+
+	require.NoError(t, settingsManager.Set("security.password_block_common", "true"))
+	assert.ErrorContains(t, reset("Password123"), "too common")
+
+	// A generated password passes every rule
+	require.NoError(t, settingsManager.Set("security.password_require_special", "true"))
+	out, err := runAdminCmd(t, "reset-password", "--data-dir", dataDir)
+	require.NoError(t, err, out)
+
+	_, err = manager.ValidateConsoleCredentials(ctx, "admin", "admin")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials, "the password must have been reset")
+}
+
+func TestAdminResetPassword_RestartsPasswordAge(t *testing.T) {
+	dataDir := t.TempDir()
+	manager := newAuthManager(dataDir)
+	db, ok := manager.GetDB().(*sql.DB)
+	require.True(t, ok)
+	longAgo := time.Now().AddDate(-1, 0, 0).Unix()
+	_, err := db.Exec(`UPDATE users SET password_changed_at = ? WHERE username = 'admin'`, longAgo)
+	require.NoError(t, err)
+
+	before := time.Now().Unix()
+	out, err := runAdminCmd(t, "reset-password", "--data-dir", dataDir)
+	require.NoError(t, err, out)
+
+	var changedAt int64
+	require.NoError(t, db.QueryRow(`SELECT password_changed_at FROM users WHERE username = 'admin'`).Scan(&changedAt))
+	assert.GreaterOrEqual(t, changedAt, before, "a reset password must not count as expired")
+}
+
+func TestAdminRegenerateJWTSecret_InvalidatesOldSessions(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	before := newAuthManager(dataDir)
+	admin, err := before.ValidateConsoleCredentials(ctx, "admin", "admin")
+	require.NoError(t, err)
+	oldToken, err := before.GenerateJWT(ctx, admin)
+	require.NoError(t, err)
+
+	out, err := runAdminCmd(t, "regenerate-jwt-secret", "--data-dir", dataDir)
+	require.NoError(t, err, out)
+	assert.Regexp(t, `New JWT secret: \S{32,}`, out)
+
+	// After a restart the server signs with the new secret.
+	after := newAuthManager(dataDir)
+	_, err = after.ValidateJWT(ctx, oldToken)
+	assert.Error(t, err, "sessions signed with the old secret must be rejected")
+	newToken, err := after.GenerateJWT(ctx, admin)
+	require.NoError(t, err)
+	_, err = after.ValidateJWT(ctx, newToken)
+	assert.NoError(t, err)
+}
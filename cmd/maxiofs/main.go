@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/maxiofs/maxiofs/internal/server"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -43,6 +45,9 @@ built in Go with an embedded React web interface.`,
 	rootCmd.AddCommand(newRecoverCmd())
 	rootCmd.AddCommand(newRepairPointersCmd())
 
+	// Offline credential recovery (lost admin password / JWT secret)
+	rootCmd.AddCommand(newAdminCmd())
+
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -65,7 +70,7 @@ func runServer(cmd *cobra.Command, args []string) error {
 	}
 
 	// Setup logging
-	setupLogging(cfg.LogLevel)
+	setupLogging(cfg.LogLevel, cfg.LogFormat)
 
 	logrus.WithFields(logrus.Fields{
 		"version": version,
@@ -84,13 +89,22 @@ func runServer(cmd *cobra.Command, args []string) error {
 		cfg.CertFile = tlsCert
 		cfg.KeyFile = tlsKey
 	}
-	if cfg.EnableTLS {
-		logrus.WithFields(logrus.Fields{
-			"cert_file": cfg.CertFile,
-			"key_file":  cfg.KeyFile,
-		}).Info("TLS enabled - servers will use HTTPS")
-	} else {
-		logrus.Info("TLS disabled - servers will use HTTP")
+	// s3_tls / console_tls can override the global setting per listener.
+	for _, listener := range []struct {
+		name string
+		tls  config.ListenerTLS
+	}{
+		{"S3 API", cfg.S3ListenerTLS()},
+		{"console", cfg.ConsoleListenerTLS()},
+	} {
+		if listener.tls.Enabled {
+			logrus.WithFields(logrus.Fields{
+				"cert_file": listener.tls.CertFile,
+				"key_file":  listener.tls.KeyFile,
+			}).Infof("TLS enabled - %s server will use HTTPS", listener.name)
+		} else {
+			logrus.Infof("TLS disabled - %s server will use HTTP", listener.name)
+		}
 	}
 
 	// Create server
@@ -123,10 +137,23 @@ func runServer(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func setupLogging(level string) {
-	logrus.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: time.RFC3339,
-	})
+var requestIDHookOnce sync.Once
+
+// setupLogging configures the standard logger before the server starts.
+// format is "text" for human-readable output; anything else logs JSON.
+func setupLogging(level, format string) {
+	if format == "text" {
+		logrus.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: time.RFC3339,
+		})
+	} else {
+		logrus.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+		})
+	}
+	// Tag every entry logged with a request context with its correlation ID
+	requestIDHookOnce.Do(func() { logrus.AddHook(middleware.RequestIDHook{}) })
 
 	switch level {
 	case "debug":
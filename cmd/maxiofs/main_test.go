@@ -45,14 +45,14 @@ func TestSetupLogging_AllLevels(t *testing.T) {
 			name = "empty"
 		}
 		t.Run(name, func(t *testing.T) {
-			setupLogging(tt.input)
+			setupLogging(tt.input, "")
 			assert.Equal(t, tt.expected, logrus.GetLevel())
 		})
 	}
 }
 
 func TestSetupLogging_JSONFormatter(t *testing.T) {
-	setupLogging("info")
+	setupLogging("info", "")
 
 	formatter, ok := logrus.StandardLogger().Formatter.(*logrus.JSONFormatter)
 	require.True(t, ok, "Formatter should be JSONFormatter")
@@ -63,7 +63,7 @@ func TestSetupLogging_FormatterPreservedAcrossLevels(t *testing.T) {
 	levels := []string{"debug", "info", "warn", "error", "invalid"}
 
 	for _, level := range levels {
-		setupLogging(level)
+		setupLogging(level, "")
 
 		formatter, ok := logrus.StandardLogger().Formatter.(*logrus.JSONFormatter)
 		require.True(t, ok, "Formatter should always be JSONFormatter after setting level %q", level)
@@ -71,12 +71,22 @@ func TestSetupLogging_FormatterPreservedAcrossLevels(t *testing.T) {
 	}
 }
 
+func TestSetupLogging_TextFormatter(t *testing.T) {
+	defer setupLogging("info", "")
+	setupLogging("info", "text")
+
+	formatter, ok := logrus.StandardLogger().Formatter.(*logrus.TextFormatter)
+	require.True(t, ok, "Formatter should be TextFormatter")
+	assert.True(t, formatter.FullTimestamp)
+	assert.Equal(t, time.RFC3339, formatter.TimestampFormat)
+}
+
 func TestSetupLogging_OutputIsValidJSON(t *testing.T) {
 	var buf bytes.Buffer
 	logrus.SetOutput(&buf)
 	defer logrus.SetOutput(os.Stderr)
 
-	setupLogging("info")
+	setupLogging("info", "")
 
 	logrus.WithFields(logrus.Fields{
 		"key1": "value1",
@@ -101,7 +111,7 @@ func TestSetupLogging_ConcurrentCalls(t *testing.T) {
 	for _, level := range levels {
 		go func(l string) {
 			defer func() { done <- struct{}{} }()
-			setupLogging(l)
+			setupLogging(l, "")
 		}(level)
 	}
 
@@ -91,4 +91,20 @@ func IsValidCannedACL(cannedACL string) bool {
 	return false
 }
 
+// IsPublicGroup reports whether a group grantee URI makes a grant public:
+// AllUsers, or AuthenticatedUsers (anyone with credentials)
+func IsPublicGroup(uri string) bool {
+	return uri == GroupAllUsers || uri == GroupAuthenticatedUsers
+}
+
+// HasPublicGrant reports whether the ACL grants anything to a public group
+func (a *ACL) HasPublicGrant() bool {
+	for _, grant := range a.Grants {
+		if IsPublicGroup(grant.Grantee.URI) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsValidPermission checks if a permission string is valid
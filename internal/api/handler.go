@@ -7,16 +7,19 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/maxiofs/maxiofs/internal/auth"
 	"github.com/maxiofs/maxiofs/internal/bandwidth"
 	"github.com/maxiofs/maxiofs/internal/bucket"
 	"github.com/maxiofs/maxiofs/internal/cluster"
+	"github.com/maxiofs/maxiofs/internal/eventlog"
 	"github.com/maxiofs/maxiofs/internal/inventory"
 	"github.com/maxiofs/maxiofs/internal/metadata"
 	"github.com/maxiofs/maxiofs/internal/metrics"
 	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/maxiofs/maxiofs/pkg/s3compat"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/sirupsen/logrus"
@@ -41,6 +44,8 @@ type Handler struct {
 	publicConsoleURL string
 	consoleListen    string // e.g. ":8081" — used to redirect direct-access browsers to the console port
 	dataDir          string
+	spaceReporter    storage.SpaceReporter // nil for backends without a local data disk
+	maintenanceMode  func() bool           // reports read-only maintenance mode; nil = never
 }
 
 // NewHandler creates a new API handler
@@ -255,6 +260,9 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 	// Restore object
 	objectRouter.HandleFunc("", h.s3Handler.RestoreObject).Methods("POST").Queries("restore", "")
 
+	// Resumable download sessions (GET ?downloadSession=<token> is served by GetObject)
+	objectRouter.HandleFunc("", h.s3Handler.OpenDownloadSession).Methods("POST").Queries("downloadSession", "")
+
 	// S3 Select
 	objectRouter.HandleFunc("", h.s3Handler.SelectObjectContent).Methods("POST").Queries("select", "")
 
@@ -554,9 +562,49 @@ func (h *Handler) handleReady(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Read-only maintenance keeps the node ready (reads are served) but is
+	// reported so operators can see why writes get 503
+	maintenance := "off"
+	if h.maintenanceMode != nil && h.maintenanceMode() {
+		maintenance = "readonly"
+	}
+
+	// A node whose data disk is full can't take writes; report it not ready
+	// so load balancers send traffic elsewhere
+	if h.spaceReporter != nil {
+		space := h.spaceReporter.SpaceStatus()
+		w.Header().Set("Content-Type", "application/json")
+		if space.WriteBlocked {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(fmt.Sprintf(
+				`{"status":"not ready","reason":"storage write-blocked","disk_free":%d,"write_blocked":true}`,
+				space.FreeBytes,
+			)))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(fmt.Sprintf(
+			`{"status":"ready","service":"maxiofs","disk_free":%d,"write_blocked":false,"maintenance":%q}`,
+			space.FreeBytes, maintenance,
+		)))
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status": "ready", "service": "maxiofs"}`))
+	w.Write([]byte(fmt.Sprintf(`{"status":"ready","service":"maxiofs","maintenance":%q}`, maintenance)))
+}
+
+// SetSpaceReporter reports the storage backend's free space and write-blocked
+// state in the readiness check.
+func (h *Handler) SetSpaceReporter(sr storage.SpaceReporter) {
+	h.spaceReporter = sr
+}
+
+// SetMaintenanceMode reports read-only maintenance mode in the readiness
+// check.
+func (h *Handler) SetMaintenanceMode(enabled func() bool) {
+	h.maintenanceMode = enabled
 }
 
 // SetInventoryManager wires the inventory manager into the S3-compatible handler.
@@ -564,6 +612,11 @@ func (h *Handler) SetInventoryManager(m *inventory.Manager) {
 	h.s3Handler.SetInventoryManager(m)
 }
 
+// SetEventLog wires the bucket event log into the S3-compatible handler.
+func (h *Handler) SetEventLog(el *eventlog.Log) {
+	h.s3Handler.SetEventLog(el)
+}
+
 // SetReplicationManager sets the replication manager for realtime object replication hooks
 func (h *Handler) SetReplicationManager(rm interface {
 	QueueRealtimeObject(ctx context.Context, tenantID, bucket, objectKey, action string) error
@@ -576,6 +629,54 @@ func (h *Handler) SetClusterRouter(cr *cluster.Router) {
 	h.s3Handler.SetClusterRouter(cr)
 }
 
+// SetTrustedProxies sets the proxies whose forwarding headers bucket policy conditions trust.
+func (h *Handler) SetTrustedProxies(proxies []string) {
+	h.s3Handler.SetTrustedProxies(proxies)
+}
+
+// SetClockSkew sets the allowed presigned URL clock skew on the S3-compatible handler.
+func (h *Handler) SetClockSkew(skew time.Duration) {
+	h.s3Handler.SetClockSkew(skew)
+}
+
+// SetPresignedMaxExpiry sets the presigned URL lifetime cap on the S3-compatible handler.
+func (h *Handler) SetPresignedMaxExpiry(seconds int64) {
+	h.s3Handler.SetPresignedMaxExpiry(seconds)
+}
+
+// SetSigningDefaults sets the default presigned URL region and signing service on the S3-compatible handler.
+func (h *Handler) SetSigningDefaults(region, service string) {
+	h.s3Handler.SetSigningDefaults(region, service)
+}
+
+// SetListTimeout sets the per-request listing deadline on the S3-compatible handler.
+func (h *Handler) SetListTimeout(timeout time.Duration) {
+	h.s3Handler.SetListTimeout(timeout)
+}
+
+// PresignedSignatureValid reports whether r is a presigned URL request whose
+// signature and expiry verify.
+func (h *Handler) PresignedSignatureValid(r *http.Request) bool {
+	return h.s3Handler.ValidatePresignedURL(nil, r) == nil
+}
+
+// SetColdReads sets what the S3-compatible handler does with reads of archived
+// objects that have not been restored.
+func (h *Handler) SetColdReads(mode string) {
+	h.s3Handler.SetColdReads(mode)
+}
+
+// SetGzipTranscoding sets whether the S3-compatible handler decodes
+// gzip-stored objects for clients that don't accept gzip.
+func (h *Handler) SetGzipTranscoding(enabled bool) {
+	h.s3Handler.SetGzipTranscoding(enabled)
+}
+
+// SetErrorPages sets how the S3-compatible handler renders errors for browsers.
+func (h *Handler) SetErrorPages(mode, loginURL string) {
+	h.s3Handler.SetErrorPages(mode, loginURL)
+}
+
 // handleRoot handles GET / and HEAD /. Non-S3 clients are redirected by S3ClientMiddleware.
 // Both GET and HEAD run ListBuckets so that HEAD / returns the same headers (including
 // Content-Length) as GET / but without the body. Veeam uses HEAD / to detect a valid S3
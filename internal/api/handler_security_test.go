@@ -189,6 +189,36 @@ func (m *MockBucketManager) DeleteQuota(ctx context.Context, tenantID, name stri
 	return args.Error(0)
 }
 
+func (m *MockBucketManager) SetDefaultWriteLock(ctx context.Context, tenantID, name string, days int) error {
+	args := m.Called(ctx, tenantID, name, days)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetMaxVersionsPerObject(ctx context.Context, tenantID, name string, max int) error {
+	args := m.Called(ctx, tenantID, name, max)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetCacheDefaults(ctx context.Context, tenantID, name string, defaults *metadata.BucketCacheDefaults) error {
+	args := m.Called(ctx, tenantID, name, defaults)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetDefaultContentType(ctx context.Context, tenantID, name, contentType string) error {
+	args := m.Called(ctx, tenantID, name, contentType)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetScanUploads(ctx context.Context, tenantID, name string, enabled bool) error {
+	args := m.Called(ctx, tenantID, name, enabled)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetNoOverwrite(ctx context.Context, tenantID, name string, enabled bool) error {
+	args := m.Called(ctx, tenantID, name, enabled)
+	return args.Error(0)
+}
+
 func (m *MockBucketManager) GetBucketACL(ctx context.Context, tenantID, name string) (interface{}, error) {
 	args := m.Called(ctx, tenantID, name)
 	return args.Get(0), args.Error(1)
@@ -289,6 +319,22 @@ func (m *MockObjectManager) PutObject(ctx context.Context, bucket, key string, d
 	return args.Get(0).(*object.Object), args.Error(1)
 }
 
+func (m *MockObjectManager) AppendObject(ctx context.Context, bucket, key string, data io.Reader, headers http.Header, writeOffset int64) (*object.Object, error) {
+	args := m.Called(ctx, bucket, key, data, headers, writeOffset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*object.Object), args.Error(1)
+}
+
+func (m *MockObjectManager) RenameObject(ctx context.Context, bucket, srcKey, dstKey string) (*object.Object, error) {
+	args := m.Called(ctx, bucket, srcKey, dstKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*object.Object), args.Error(1)
+}
+
 func (m *MockObjectManager) DeleteObject(ctx context.Context, bucket, key string, bypassGovernance bool, versionID ...string) (string, error) {
 	args := m.Called(ctx, bucket, key, bypassGovernance, versionID)
 	return args.String(0), args.Error(1)
@@ -310,6 +356,11 @@ func (m *MockObjectManager) GetObjectMetadata(ctx context.Context, bucket, key s
 	return args.Get(0).(*object.Object), args.Error(1)
 }
 
+func (m *MockObjectManager) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	args := m.Called(ctx, bucket, key)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockObjectManager) UpdateObjectMetadata(ctx context.Context, bucket, key string, metadata map[string]string) error {
 	args := m.Called(ctx, bucket, key, metadata)
 	return args.Error(0)
@@ -393,7 +444,7 @@ func (m *MockObjectManager) CreateMultipartUpload(ctx context.Context, bucket, k
 	return args.Get(0).(*object.MultipartUpload), args.Error(1)
 }
 
-func (m *MockObjectManager) UploadPart(ctx context.Context, uploadID string, partNumber int, data io.Reader) (*object.Part, error) {
+func (m *MockObjectManager) UploadPart(ctx context.Context, uploadID string, partNumber int, data io.Reader, headers ...http.Header) (*object.Part, error) {
 	args := m.Called(ctx, uploadID, partNumber, data)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -438,6 +489,19 @@ func (m *MockObjectManager) CopyObject(ctx context.Context, srcBucket, srcKey, d
 	return args.Get(0).(*object.Object), args.Error(1)
 }
 
+func (m *MockObjectManager) ListObjectsReverse(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*object.ListObjectsResult, error) {
+	args := m.Called(ctx, bucket, prefix, delimiter, marker, maxKeys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*object.ListObjectsResult), args.Error(1)
+}
+
+func (m *MockObjectManager) ListETag(ctx context.Context, bucket string) (string, error) {
+	args := m.Called(ctx, bucket)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockObjectManager) SearchObjects(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int, filter *metadata.ObjectFilter) (*object.ListObjectsResult, error) {
 	args := m.Called(ctx, bucket, prefix, delimiter, marker, maxKeys, filter)
 	if args.Get(0) == nil {
@@ -584,6 +648,19 @@ func (m *MockAuthManager) GenerateAccessKey(ctx context.Context, userID string)
 	return args.Get(0).(*auth.AccessKey), args.Error(1)
 }
 
+func (m *MockAuthManager) GenerateNamedAccessKey(ctx context.Context, userID string, info auth.AccessKeyInfo) (*auth.AccessKey, error) {
+	args := m.Called(ctx, userID, info)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.AccessKey), args.Error(1)
+}
+
+func (m *MockAuthManager) UpdateAccessKeyInfo(ctx context.Context, accessKeyID string, info auth.AccessKeyInfo) error {
+	args := m.Called(ctx, accessKeyID, info)
+	return args.Error(0)
+}
+
 func (m *MockAuthManager) GetAccessKey(ctx context.Context, accessKeyID string) (*auth.AccessKey, error) {
 	args := m.Called(ctx, accessKeyID)
 	if args.Get(0) == nil {
@@ -925,6 +1002,18 @@ func (m *MockMetricsManager) RecordBucketOperation(operation, bucket string, suc
 	m.Called(operation, bucket, success)
 }
 
+func (m *MockMetricsManager) RecordBucketRequest(bucket, key, method string) {
+	m.Called(bucket, key, method)
+}
+
+func (m *MockMetricsManager) GetBucketRequestMetrics(bucket string, topN int) *metrics.BucketRequestMetrics {
+	args := m.Called(bucket, topN)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*metrics.BucketRequestMetrics)
+}
+
 func (m *MockMetricsManager) RecordObjectLockOperation(operation, bucket string, success bool) {
 	m.Called(operation, bucket, success)
 }
@@ -1025,6 +1114,11 @@ func setupTestHandler() (*Handler, *MockBucketManager, *MockObjectManager, *Mock
 		nil, // bucketAggregator
 	)
 
+	// Object requests evaluate key-scoped bucket policy statements; default to no policy
+	mockBucket.On("GetBucketPolicy", mock.Anything, mock.Anything, mock.Anything).Return(
+		nil, bucket.ErrPolicyNotFound,
+	).Maybe()
+
 	return handler, mockBucket, mockObject, mockAuth
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/sirupsen/logrus"
 )
 
@@ -18,6 +19,7 @@ type Manager struct {
 	store           Store
 	logger          *logrus.Logger
 	settingsManager SettingsManager
+	sinks           *sinkDispatcher
 }
 
 // NewManager creates a new audit manager
@@ -33,6 +35,16 @@ func (m *Manager) SetSettingsManager(sm SettingsManager) {
 	m.settingsManager = sm
 }
 
+// SetSinks forwards every subsequently recorded event to sinks in addition to
+// the store. Sinks run on a background worker; a slow or failing sink never
+// delays or prevents the write to the store.
+func (m *Manager) SetSinks(sinks []Sink) {
+	if len(sinks) == 0 {
+		return
+	}
+	m.sinks = newSinkDispatcher(sinks, m.logger)
+}
+
 // LogEvent records an audit event
 // This is the main entry point for logging audit events from across the application
 func (m *Manager) LogEvent(ctx context.Context, event *AuditEvent) error {
@@ -48,13 +60,14 @@ func (m *Manager) LogEvent(ctx context.Context, event *AuditEvent) error {
 			return nil
 		}
 
-		// Per-category toggles: S3 object operations vs console/admin operations
+		// Per-category toggles: S3 object operations vs console/admin operations.
+		// Object lock changes are compliance records and always kept.
 		isS3Op := event.ResourceType == ResourceTypeObject
-		if isS3Op {
+		if isS3Op && !IsObjectLockEvent(event.EventType) {
 			if log, err := m.settingsManager.GetBool("audit.log_s3_operations"); err == nil && !log {
 				return nil
 			}
-		} else {
+		} else if !isS3Op {
 			if log, err := m.settingsManager.GetBool("audit.log_console_operations"); err == nil && !log {
 				return nil
 			}
@@ -82,8 +95,33 @@ func (m *Manager) LogEvent(ctx context.Context, event *AuditEvent) error {
 		return nil
 	}
 
+	// Tie the event to the request it came from
+	withRequestDetails(ctx, event)
+
 	// Log the event
 	err := m.store.LogEvent(ctx, event)
+
+	// External sinks get the event even if the store rejected it
+	if m.sinks != nil {
+		now := time.Now().Unix()
+		m.sinks.enqueue(&AuditLog{
+			Timestamp:    now,
+			TenantID:     event.TenantID,
+			UserID:       event.UserID,
+			Username:     event.Username,
+			EventType:    event.EventType,
+			ResourceType: event.ResourceType,
+			ResourceID:   event.ResourceID,
+			ResourceName: event.ResourceName,
+			Action:       event.Action,
+			Status:       event.Status,
+			IPAddress:    event.IPAddress,
+			UserAgent:    event.UserAgent,
+			Details:      event.Details,
+			CreatedAt:    now,
+		})
+	}
+
 	if err != nil {
 		m.logger.WithError(err).WithFields(logrus.Fields{
 			"event_type": event.EventType,
@@ -288,17 +326,48 @@ func (m *Manager) runRetentionCleanup(ctx context.Context, retentionDays int) {
 	}
 }
 
-// Flush blocks until all queued audit events have been committed to the store.
+// Flush blocks until all queued audit events have been committed to the store
+// and delivered to the configured sinks.
 func (m *Manager) Flush() {
 	if m.store != nil {
 		m.store.Flush()
 	}
+	if m.sinks != nil {
+		m.sinks.flush()
+	}
 }
 
-// Close closes the audit manager and underlying store
+// Close closes the audit manager, its sinks and the underlying store
 func (m *Manager) Close() error {
+	if m.sinks != nil {
+		if err := m.sinks.close(); err != nil {
+			m.logger.WithError(err).Warn("Failed to close audit sinks")
+		}
+	}
 	if m.store != nil {
 		return m.store.Close()
 	}
 	return nil
 }
+
+// withRequestDetails records the request ID and traceparent of ctx, if any,
+// in event.Details as request_id and traceparent, unless the caller set a
+// request_id already. The caller's Details map is copied, not changed.
+func withRequestDetails(ctx context.Context, event *AuditEvent) {
+	requestID := middleware.GetRequestID(ctx)
+	if requestID == "" {
+		return
+	}
+	if _, ok := event.Details["request_id"]; ok {
+		return
+	}
+	details := make(map[string]interface{}, len(event.Details)+2)
+	for k, v := range event.Details {
+		details[k] = v
+	}
+	details["request_id"] = requestID
+	if tp := middleware.GetTraceparent(ctx); tp != "" {
+		details["traceparent"] = tp
+	}
+	event.Details = details
+}
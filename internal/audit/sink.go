@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// sinkQueueSize is the number of records buffered for external sinks.
+	// When the queue is full, records are dropped for the sinks only; the
+	// primary store is written before sinks are involved.
+	sinkQueueSize = 4096
+	// sinkTickInterval is how often buffering sinks get a chance to ship
+	// records when no new events arrive.
+	sinkTickInterval = time.Second
+)
+
+// Sink forwards audit records to an external destination (SIEM, log file,
+// object storage). Sinks are driven by a single goroutine, so implementations
+// do not need to be safe for concurrent use.
+type Sink interface {
+	// Name identifies the sink in logs
+	Name() string
+
+	// Write delivers one record
+	Write(record *AuditLog) error
+
+	// Flush pushes out any buffered records
+	Flush() error
+
+	// Close flushes and releases the sink
+	Close() error
+}
+
+// periodicSink is implemented by sinks that buffer records and need to ship
+// them on a timer as well as on Write.
+type periodicSink interface {
+	FlushIfDue(now time.Time) error
+}
+
+// NewSink builds a sink from its declarative configuration.
+func NewSink(cfg config.AuditSinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "file":
+		return NewFileSink(cfg.Path, int64(cfg.MaxSizeMB)*1024*1024, cfg.MaxBackups)
+	case "syslog":
+		return NewSyslogSink(cfg.Protocol, cfg.Host, cfg.Port, cfg.Tag)
+	case "s3":
+		return NewS3SinkFromConfig(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink type %q", cfg.Type)
+	}
+}
+
+// sinkDispatcher fans records out to every configured sink from one worker
+// goroutine, mirroring the SQLite store's single-writer design.
+type sinkDispatcher struct {
+	sinks     []Sink
+	logger    *logrus.Logger
+	queue     chan *AuditLog
+	flushChan chan chan struct{}
+	done      chan struct{}
+
+	// mu guards closed: senders hold it for reading so close never closes
+	// the queue under them.
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newSinkDispatcher(sinks []Sink, logger *logrus.Logger) *sinkDispatcher {
+	d := &sinkDispatcher{
+		sinks:     sinks,
+		logger:    logger,
+		queue:     make(chan *AuditLog, sinkQueueSize),
+		flushChan: make(chan chan struct{}, 8),
+		done:      make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// enqueue hands a record to the worker without blocking the caller. Records
+// arriving after close are dropped.
+func (d *sinkDispatcher) enqueue(record *AuditLog) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	select {
+	case d.queue <- record:
+	default:
+		d.logger.WithField("event_type", record.EventType).Warn("Audit sink queue full, dropping event for external sinks")
+	}
+}
+
+func (d *sinkDispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(sinkTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case record, ok := <-d.queue:
+			if !ok {
+				d.flushSinks()
+				return
+			}
+			d.write(record)
+
+		case reply := <-d.flushChan:
+			for len(d.queue) > 0 {
+				if record, ok := <-d.queue; ok {
+					d.write(record)
+				}
+			}
+			d.flushSinks()
+			close(reply)
+
+		case now := <-ticker.C:
+			for _, sink := range d.sinks {
+				if p, ok := sink.(periodicSink); ok {
+					if err := p.FlushIfDue(now); err != nil {
+						d.logger.WithError(err).WithField("sink", sink.Name()).Error("Failed to flush audit sink")
+					}
+				}
+			}
+		}
+	}
+}
+
+func (d *sinkDispatcher) write(record *AuditLog) {
+	for _, sink := range d.sinks {
+		if err := sink.Write(record); err != nil {
+			d.logger.WithError(err).WithFields(logrus.Fields{
+				"sink":       sink.Name(),
+				"event_type": record.EventType,
+			}).Error("Failed to write audit event to sink")
+		}
+	}
+}
+
+func (d *sinkDispatcher) flushSinks() {
+	for _, sink := range d.sinks {
+		if err := sink.Flush(); err != nil {
+			d.logger.WithError(err).WithField("sink", sink.Name()).Error("Failed to flush audit sink")
+		}
+	}
+}
+
+// flush blocks until all records queued before the call reached the sinks.
+func (d *sinkDispatcher) flush() {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	reply := make(chan struct{})
+	d.flushChan <- reply
+	<-reply
+}
+
+// close drains the queue, then closes every sink. Calling it again is a no-op.
+func (d *sinkDispatcher) close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+	<-d.done
+
+	var firstErr error
+	for _, sink := range d.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close audit sink %s: %w", sink.Name(), err)
+		}
+	}
+	return firstErr
+}
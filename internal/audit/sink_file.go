@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	defaultFileSinkMaxSize    = 100 * 1024 * 1024
+	defaultFileSinkMaxBackups = 10
+)
+
+// FileSink appends audit records to a local file as JSON lines. When the file
+// grows past maxSize it is renamed to "<path>.<timestamp>" and a new file is
+// started; only the newest maxBackups rotated files are kept.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileSink opens (or creates) path for appending. Non-positive maxSize and
+// maxBackups use the defaults (100 MiB, 10 files).
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	if maxSize <= 0 {
+		maxSize = defaultFileSinkMaxSize
+	}
+	if maxBackups <= 0 {
+		maxBackups = defaultFileSinkMaxBackups
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	s := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat audit log file: %w", err)
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// Name identifies the sink in logs
+func (s *FileSink) Name() string {
+	return "file:" + s.path
+}
+
+// Write appends record as a single JSON line, rotating first if needed
+func (s *FileSink) Write(record *AuditLog) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log file: %w", err)
+	}
+	return nil
+}
+
+// rotate renames the current file aside, prunes old backups and reopens path
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log file: %w", err)
+	}
+	s.file = nil
+
+	rotated := s.path + "." + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(s.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate audit log file: %w", err)
+	}
+
+	backups, err := filepath.Glob(s.path + ".*")
+	if err == nil && len(backups) > s.maxBackups {
+		// Timestamp suffixes sort chronologically
+		sort.Strings(backups)
+		for _, old := range backups[:len(backups)-s.maxBackups] {
+			os.Remove(old)
+		}
+	}
+
+	return s.open()
+}
+
+// Flush syncs the file to disk
+func (s *FileSink) Flush() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+// Close syncs and closes the file
+func (s *FileSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	s.file.Sync()
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
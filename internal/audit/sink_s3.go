@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/replication"
+)
+
+const (
+	defaultS3SinkBatchSize     = 500
+	defaultS3SinkFlushInterval = 60 * time.Second
+	// s3SinkMaxBufferedBatches bounds memory while the bucket is unreachable;
+	// beyond it the oldest buffered records are discarded.
+	s3SinkMaxBufferedBatches = 10
+	s3SinkUploadTimeout      = 30 * time.Second
+)
+
+// s3Uploader is the subset of replication.S3Client used by S3Sink.
+type s3Uploader interface {
+	PutObject(ctx context.Context, bucket, key string, data io.Reader, size int64, contentType string, metadata map[string]string) error
+}
+
+// S3Sink buffers audit records and uploads them as JSON-lines objects under
+// "<prefix>YYYY/MM/DD/<host>-<timestamp>.jsonl". A batch is shipped when it
+// reaches batchSize records or has been buffered for flushInterval.
+type S3Sink struct {
+	client        s3Uploader
+	bucket        string
+	prefix        string
+	host          string
+	batchSize     int
+	flushInterval time.Duration
+
+	buf     [][]byte
+	oldest  time.Time
+	dropped int
+}
+
+// NewS3SinkFromConfig creates an S3 sink for an S3-compatible endpoint. The
+// endpoint comes from the operator's config file, so internal addresses are
+// allowed.
+func NewS3SinkFromConfig(cfg config.AuditSinkConfig) *S3Sink {
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	client := replication.NewS3RemoteClient(cfg.Endpoint, region, cfg.AccessKey, cfg.SecretKey, true)
+	return NewS3Sink(client, cfg.Bucket, cfg.Prefix, cfg.BatchSize, time.Duration(cfg.FlushIntervalSeconds)*time.Second)
+}
+
+// NewS3Sink creates an S3 sink using client. Non-positive batchSize and
+// flushInterval use the defaults (500 records, 60s).
+func NewS3Sink(client s3Uploader, bucket, prefix string, batchSize int, flushInterval time.Duration) *S3Sink {
+	if batchSize <= 0 {
+		batchSize = defaultS3SinkBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultS3SinkFlushInterval
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "maxiofs"
+	}
+	return &S3Sink{
+		client:        client,
+		bucket:        bucket,
+		prefix:        prefix,
+		host:          host,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+}
+
+// Name identifies the sink in logs
+func (s *S3Sink) Name() string {
+	return "s3:" + s.bucket + "/" + s.prefix
+}
+
+// Write buffers record and uploads the batch once it is full
+func (s *S3Sink) Write(record *AuditLog) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	if len(s.buf) == 0 {
+		s.oldest = time.Now()
+	}
+	s.buf = append(s.buf, line)
+
+	if limit := s.batchSize * s3SinkMaxBufferedBatches; len(s.buf) > limit {
+		drop := len(s.buf) - limit
+		s.buf = s.buf[drop:]
+		s.dropped += drop
+	}
+
+	if len(s.buf) >= s.batchSize {
+		return s.Flush()
+	}
+	return nil
+}
+
+// FlushIfDue uploads the buffered batch once it is older than flushInterval
+func (s *S3Sink) FlushIfDue(now time.Time) error {
+	if len(s.buf) == 0 || now.Sub(s.oldest) < s.flushInterval {
+		return nil
+	}
+	return s.Flush()
+}
+
+// Flush uploads all buffered records as one object. On failure the records
+// stay buffered and are retried with the next batch.
+func (s *S3Sink) Flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	for _, line := range s.buf {
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+
+	now := time.Now().UTC()
+	key := fmt.Sprintf("%s%s/%s-%s.jsonl", s.prefix, now.Format("2006/01/02"), s.host, now.Format("20060102T150405.000000000Z"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3SinkUploadTimeout)
+	defer cancel()
+	if err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(body.Bytes()), int64(body.Len()), "application/x-ndjson", nil); err != nil {
+		return fmt.Errorf("failed to upload audit batch (%d records buffered): %w", len(s.buf), err)
+	}
+
+	s.buf = s.buf[:0]
+	if s.dropped > 0 {
+		dropped := s.dropped
+		s.dropped = 0
+		return fmt.Errorf("%d audit records were discarded while the bucket was unreachable", dropped)
+	}
+	return nil
+}
+
+// Close uploads any remaining records
+func (s *S3Sink) Close() error {
+	return s.Flush()
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/logging"
+)
+
+// SyslogSink forwards audit records to a syslog collector as RFC 5424
+// messages. Record fields are carried as structured data and the MSGID is the
+// audit action, so SIEMs can filter without parsing the JSON body.
+type SyslogSink struct {
+	output *logging.SyslogOutput
+	addr   string
+}
+
+// NewSyslogSink connects to host:port. Protocol defaults to udp, port to 514
+// and tag to "maxiofs-audit".
+func NewSyslogSink(protocol, host string, port int, tag string) (*SyslogSink, error) {
+	if protocol == "" {
+		protocol = "udp"
+	}
+	if port <= 0 {
+		port = 514
+	}
+	if tag == "" {
+		tag = "maxiofs-audit"
+	}
+
+	output, err := logging.NewSyslogOutputWithConfig(logging.SyslogConfig{
+		Protocol:   protocol,
+		Host:       host,
+		Port:       port,
+		Tag:        tag,
+		Format:     "rfc5424",
+		TLSEnabled: protocol == "tcp+tls",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{output: output, addr: fmt.Sprintf("%s://%s:%d", protocol, host, port)}, nil
+}
+
+// Name identifies the sink in logs
+func (s *SyslogSink) Name() string {
+	return "syslog:" + s.addr
+}
+
+// Write sends one record; failed actions are logged at warning severity
+func (s *SyslogSink) Write(record *AuditLog) error {
+	level := "info"
+	if record.Status == StatusFailed {
+		level = "warning"
+	}
+
+	fields := map[string]interface{}{
+		"event_type":    record.EventType,
+		"user_id":       record.UserID,
+		"username":      record.Username,
+		"action":        record.Action,
+		"status":        record.Status,
+		"resource_type": record.ResourceType,
+		"resource_id":   record.ResourceID,
+		"resource_name": record.ResourceName,
+		"ip_address":    record.IPAddress,
+		"user_agent":    record.UserAgent,
+	}
+	if record.TenantID != "" {
+		fields["tenant_id"] = record.TenantID
+	}
+	if len(record.Details) > 0 {
+		fields["details"] = record.Details
+	}
+
+	return s.output.Write(&logging.LogEntry{
+		Timestamp: time.Unix(record.Timestamp, 0).UTC(),
+		Level:     level,
+		Message:   "audit",
+		Fields:    fields,
+	})
+}
+
+// Flush is a no-op; syslog messages are sent immediately
+func (s *SyslogSink) Flush() error {
+	return nil
+}
+
+// Close closes the syslog connection
+func (s *SyslogSink) Close() error {
+	return s.output.Close()
+}
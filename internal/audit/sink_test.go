@@ -0,0 +1,244 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readJSONLines(t *testing.T, path string) []AuditLog {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var records []AuditLog
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("Line is not valid JSON: %q: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestFileSink_DeliversJSONLines(t *testing.T) {
+	mgr, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "siem", "audit.jsonl")
+	sink, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	mgr.SetSinks([]Sink{sink})
+
+	ctx := context.Background()
+	events := []*AuditEvent{
+		{TenantID: "tenant-1", UserID: "user-1", Username: "alice", EventType: EventTypeLoginSuccess,
+			ResourceType: ResourceTypeSystem, Action: ActionLogin, Status: StatusSuccess, IPAddress: "10.0.0.1"},
+		{UserID: "user-2", Username: "bob", EventType: EventTypeBucketDeleted, ResourceType: ResourceTypeBucket,
+			ResourceName: "logs", Action: ActionDelete, Status: StatusFailed, Details: map[string]interface{}{"reason": "not empty"}},
+	}
+	for _, e := range events {
+		if err := mgr.LogEvent(ctx, e); err != nil {
+			t.Fatalf("LogEvent failed: %v", err)
+		}
+	}
+	mgr.Flush()
+
+	records := readJSONLines(t, path)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 JSON lines, got %d", len(records))
+	}
+	if records[0].EventType != EventTypeLoginSuccess || records[0].Username != "alice" || records[0].TenantID != "tenant-1" {
+		t.Errorf("Unexpected first record: %+v", records[0])
+	}
+	if records[0].Timestamp == 0 {
+		t.Error("Expected record timestamp to be set")
+	}
+	if records[1].ResourceName != "logs" || records[1].Status != StatusFailed || records[1].Details["reason"] != "not empty" {
+		t.Errorf("Unexpected second record: %+v", records[1])
+	}
+
+	// The primary store still has both events
+	_, total, err := mgr.GetLogs(ctx, &AuditLogFilters{})
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if total != 2 {
+		t.Errorf("Expected 2 events in store, got %d", total)
+	}
+}
+
+func TestFileSink_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 300, 2)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 20; i++ {
+		rec := &AuditLog{Timestamp: time.Now().Unix(), UserID: "user-1", EventType: EventTypeObjectUploaded, Action: ActionUpload, Status: StatusSuccess}
+		if err := sink.Write(rec); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Errorf("Expected 2 rotated files to be kept, got %d", len(backups))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Active file missing: %v", err)
+	}
+	if info.Size() > 300 {
+		t.Errorf("Active file exceeds max size: %d bytes", info.Size())
+	}
+	for _, f := range append(backups, path) {
+		readJSONLines(t, f)
+	}
+}
+
+type failingSink struct{ writes int }
+
+func (s *failingSink) Name() string { return "failing" }
+func (s *failingSink) Write(*AuditLog) error {
+	s.writes++
+	return errors.New("collector unreachable")
+}
+func (s *failingSink) Flush() error { return nil }
+func (s *failingSink) Close() error { return nil }
+
+func TestSinkFailureDoesNotDropStoreEvents(t *testing.T) {
+	mgr, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	fileSink, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	broken := &failingSink{}
+	mgr.SetSinks([]Sink{broken, fileSink})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		err := mgr.LogEvent(ctx, &AuditEvent{UserID: "user-1", Username: "alice", EventType: EventTypeLogout,
+			ResourceType: ResourceTypeSystem, Action: ActionLogout, Status: StatusSuccess})
+		if err != nil {
+			t.Fatalf("LogEvent returned sink error: %v", err)
+		}
+	}
+	mgr.Flush()
+
+	_, total, err := mgr.GetLogs(ctx, &AuditLogFilters{})
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected 3 events in store, got %d", total)
+	}
+	if broken.writes != 3 {
+		t.Errorf("Expected failing sink to be attempted 3 times, got %d", broken.writes)
+	}
+	if got := len(readJSONLines(t, path)); got != 3 {
+		t.Errorf("Expected the other sink to receive 3 events, got %d", got)
+	}
+}
+
+type fakeUploader struct {
+	keys   []string
+	bodies []string
+	err    error
+}
+
+func (u *fakeUploader) PutObject(ctx context.Context, bucket, key string, data io.Reader, size int64, contentType string, metadata map[string]string) error {
+	if u.err != nil {
+		return u.err
+	}
+	b, _ := io.ReadAll(data)
+	u.keys = append(u.keys, bucket+"/"+key)
+	u.bodies = append(u.bodies, string(b))
+	return nil
+}
+
+func TestS3Sink_Batching(t *testing.T) {
+	up := &fakeUploader{}
+	sink := NewS3Sink(up, "audit-archive", "maxiofs", 2, time.Minute)
+	rec := &AuditLog{Timestamp: time.Now().Unix(), UserID: "user-1", EventType: EventTypeLoginSuccess}
+
+	if err := sink.Write(rec); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(up.keys) != 0 {
+		t.Fatal("Batch uploaded before it was full")
+	}
+	if err := sink.Write(rec); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(up.keys) != 1 {
+		t.Fatalf("Expected one upload for a full batch, got %d", len(up.keys))
+	}
+	if !strings.HasPrefix(up.keys[0], "audit-archive/maxiofs/") || !strings.HasSuffix(up.keys[0], ".jsonl") {
+		t.Errorf("Unexpected object key %q", up.keys[0])
+	}
+	if n := strings.Count(up.bodies[0], "\n"); n != 2 {
+		t.Errorf("Expected 2 JSON lines in batch, got %d", n)
+	}
+
+	// A failed upload keeps the records for the next attempt
+	up.err = errors.New("bucket unreachable")
+	sink.Write(rec)
+	if err := sink.FlushIfDue(time.Now().Add(2 * time.Minute)); err == nil {
+		t.Fatal("Expected upload error")
+	}
+	up.err = nil
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(up.keys) != 2 || strings.Count(up.bodies[1], "\n") != 1 {
+		t.Errorf("Expected retained record to be uploaded on close, uploads=%d", len(up.keys))
+	}
+}
+
+func TestLogEventAfterClose(t *testing.T) {
+	mgr, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	fileSink, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	mgr.SetSinks([]Sink{fileSink})
+
+	if err := mgr.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Late events from in-flight requests must not panic
+	err = mgr.LogEvent(context.Background(), &AuditEvent{UserID: "user-1", Username: "alice", EventType: EventTypeLogout,
+		ResourceType: ResourceTypeSystem, Action: ActionLogout, Status: StatusSuccess})
+	if err == nil {
+		t.Error("Expected LogEvent to report the closed store")
+	}
+	mgr.Flush()
+
+	if got := len(readJSONLines(t, path)); got != 0 {
+		t.Errorf("Expected no events delivered after Close, got %d", got)
+	}
+}
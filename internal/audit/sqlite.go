@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -35,6 +36,9 @@ type pendingWrite struct {
 	ipAddress    string
 	userAgent    string
 	detailsJSON  string
+	lockedUntil  int64
+	lockEvent    bool
+	versionID    string // object version of a lock event
 }
 
 // SQLiteStore implements the Store interface using SQLite.
@@ -45,6 +49,11 @@ type SQLiteStore struct {
 	writeChan chan *pendingWrite
 	flushChan chan chan struct{} // flush barrier requests
 	done      chan struct{}
+
+	// closeMu guards closed: senders hold it for reading so Close never
+	// closes writeChan under them.
+	closeMu sync.RWMutex
+	closed  bool
 }
 
 // NewSQLiteStore creates a new SQLite-based audit log store
@@ -101,7 +110,8 @@ func (s *SQLiteStore) initSchema() error {
 		ip_address TEXT,
 		user_agent TEXT,
 		details TEXT,
-		created_at INTEGER NOT NULL
+		created_at INTEGER NOT NULL,
+		locked_until INTEGER NOT NULL DEFAULT 0
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_audit_logs_timestamp ON audit_logs(timestamp DESC);
@@ -116,6 +126,20 @@ func (s *SQLiteStore) initSchema() error {
 		return fmt.Errorf("failed to create audit schema: %w", err)
 	}
 
+	// Databases created before object lock history was kept lack locked_until
+	var hasLockedUntil int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('audit_logs') WHERE name = 'locked_until'`).Scan(&hasLockedUntil); err != nil {
+		return fmt.Errorf("failed to inspect audit schema: %w", err)
+	}
+	if hasLockedUntil == 0 {
+		if _, err := s.db.Exec(`ALTER TABLE audit_logs ADD COLUMN locked_until INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("failed to add locked_until column: %w", err)
+		}
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_audit_logs_resource_id ON audit_logs(resource_id)`); err != nil {
+		return fmt.Errorf("failed to create audit schema: %w", err)
+	}
+
 	return nil
 }
 
@@ -181,6 +205,11 @@ func (s *SQLiteStore) writeWorker() {
 // Flush blocks until all events queued before this call have been committed to SQLite.
 // Useful in tests and for graceful shutdown scenarios.
 func (s *SQLiteStore) Flush() {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return
+	}
 	reply := make(chan struct{})
 	s.flushChan <- reply
 	<-reply
@@ -198,8 +227,8 @@ func (s *SQLiteStore) insertBatch(batch []*pendingWrite) error {
 		INSERT INTO audit_logs (
 			timestamp, tenant_id, user_id, username, event_type,
 			resource_type, resource_id, resource_name, action, status,
-			ip_address, user_agent, details, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ip_address, user_agent, details, created_at, locked_until
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
@@ -210,10 +239,24 @@ func (s *SQLiteStore) insertBatch(batch []*pendingWrite) error {
 		if _, err := stmt.Exec(
 			w.timestamp, w.tenantID, w.userID, w.username, w.eventType,
 			w.resourceType, w.resourceID, w.resourceName, w.action, w.status,
-			w.ipAddress, w.userAgent, w.detailsJSON, w.timestamp,
+			w.ipAddress, w.userAgent, w.detailsJSON, w.timestamp, w.lockedUntil,
 		); err != nil {
 			return fmt.Errorf("failed to insert audit log row: %w", err)
 		}
+		if w.lockEvent {
+			// The version's whole lock history stays as long as its current lock
+			if _, err := tx.Exec(`
+				UPDATE audit_logs SET locked_until = ?
+				WHERE tenant_id = ? AND resource_type = ? AND resource_id = ?
+				  AND event_type IN (?, ?, ?)
+				  AND COALESCE(json_extract(details, '$.version_id'), '') = ?
+			`, w.lockedUntil, w.tenantID, w.resourceType, w.resourceID,
+				EventTypeObjectRetentionSet, EventTypeObjectRetentionExtended, EventTypeObjectLegalHoldSet,
+				w.versionID,
+			); err != nil {
+				return fmt.Errorf("failed to update object lock history: %w", err)
+			}
+		}
 	}
 
 	return tx.Commit()
@@ -250,7 +293,17 @@ func (s *SQLiteStore) LogEvent(ctx context.Context, event *AuditEvent) error {
 		userAgent:    event.UserAgent,
 		detailsJSON:  detailsJSON,
 	}
+	if IsObjectLockEvent(event.EventType) {
+		w.lockEvent = true
+		w.lockedUntil = event.LockedUntil
+		w.versionID, _ = event.Details["version_id"].(string)
+	}
 
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("audit store is closed")
+	}
 	select {
 	case s.writeChan <- w:
 		return nil
@@ -349,11 +402,13 @@ func (s *SQLiteStore) GetLogByID(ctx context.Context, id int64) (*AuditLog, erro
 	return log, nil
 }
 
-// PurgeLogs deletes logs older than specified days (maintenance)
+// PurgeLogs deletes logs older than specified days (maintenance). The lock
+// history of an object version that is still locked is kept.
 func (s *SQLiteStore) PurgeLogs(ctx context.Context, olderThanDays int) (int, error) {
-	cutoffTime := time.Now().AddDate(0, 0, -olderThanDays).Unix()
+	now := time.Now()
+	cutoffTime := now.AddDate(0, 0, -olderThanDays).Unix()
 
-	result, err := s.db.ExecContext(ctx, "DELETE FROM audit_logs WHERE timestamp < ?", cutoffTime)
+	result, err := s.db.ExecContext(ctx, "DELETE FROM audit_logs WHERE timestamp < ? AND locked_until <= ?", cutoffTime, now.Unix())
 	if err != nil {
 		return 0, fmt.Errorf("failed to purge old audit logs: %w", err)
 	}
@@ -366,10 +421,18 @@ func (s *SQLiteStore) PurgeLogs(ctx context.Context, olderThanDays int) (int, er
 	return int(deleted), nil
 }
 
-// Close flushes pending writes and closes the database connection.
+// Close flushes pending writes and closes the database connection. Events
+// logged afterwards are rejected; calling Close again is a no-op.
 func (s *SQLiteStore) Close() error {
+	s.closeMu.Lock()
+	if s.closed {
+		s.closeMu.Unlock()
+		return nil
+	}
+	s.closed = true
 	// Closing the channel signals the worker to flush and exit.
 	close(s.writeChan)
+	s.closeMu.Unlock()
 	// Wait for the worker to finish flushing.
 	<-s.done
 	if s.db != nil {
@@ -399,6 +462,10 @@ func (s *SQLiteStore) buildWhereClause(filters *AuditLogFilters) (string, []inte
 		conditions = append(conditions, "resource_type = ?")
 		args = append(args, filters.ResourceType)
 	}
+	if filters.ResourceID != "" {
+		conditions = append(conditions, "resource_id = ?")
+		args = append(args, filters.ResourceID)
+	}
 	if filters.Action != "" {
 		conditions = append(conditions, "action = ?")
 		args = append(args, filters.Action)
@@ -2,11 +2,13 @@ package audit
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/sirupsen/logrus"
 )
 
@@ -605,3 +607,82 @@ func TestMultipleFilters(t *testing.T) {
 			logs[0].TenantID, logs[0].Status)
 	}
 }
+
+func TestNewSQLiteStoreUpgradesSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "audit_old.db")
+
+	// A database from before object lock history was kept
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`CREATE TABLE audit_logs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, timestamp INTEGER NOT NULL, tenant_id TEXT,
+		user_id TEXT NOT NULL, username TEXT NOT NULL, event_type TEXT NOT NULL,
+		resource_type TEXT, resource_id TEXT, resource_name TEXT, action TEXT NOT NULL,
+		status TEXT NOT NULL, ip_address TEXT, user_agent TEXT, details TEXT, created_at INTEGER NOT NULL
+	)`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	store, err := NewSQLiteStore(dbPath, logger)
+	if err != nil {
+		t.Fatalf("Failed to open old database: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.LogEvent(ctx, &AuditEvent{
+		UserID: "user-1", Username: "user", EventType: EventTypeObjectLegalHoldSet,
+		ResourceType: ResourceTypeObject, ResourceID: "bucket/key", Action: ActionUpdate,
+		Status: StatusSuccess, LockedUntil: LockedIndefinitely,
+	}); err != nil {
+		t.Fatalf("Failed to log event: %v", err)
+	}
+	store.Flush()
+
+	logs, total, err := store.GetLogs(ctx, &AuditLogFilters{ResourceID: "bucket/key", Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to get logs: %v", err)
+	}
+	if total != 1 || len(logs) != 1 {
+		t.Fatalf("Expected 1 log, got %d", total)
+	}
+}
+
+func TestLogEvent_RecordsRequestID(t *testing.T) {
+	mgr, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := middleware.WithRequestID(context.Background(), "3F2A9C0D11B7E845")
+	ctx = context.WithValue(ctx, middleware.TraceparentKey, traceparent)
+	details := map[string]interface{}{"version_id": "v1"}
+	err := mgr.LogEvent(ctx, &AuditEvent{UserID: "user-1", Username: "alice", EventType: EventTypeObjectDeleted,
+		ResourceType: ResourceTypeObject, Action: ActionDelete, Status: StatusSuccess, Details: details})
+	if err != nil {
+		t.Fatalf("LogEvent failed: %v", err)
+	}
+	mgr.Flush()
+
+	logs, _, err := mgr.GetLogs(context.Background(), &AuditLogFilters{})
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("GetLogs = %d logs, %v", len(logs), err)
+	}
+	if got := logs[0].Details["request_id"]; got != "3F2A9C0D11B7E845" {
+		t.Errorf("Expected request_id in details, got %v", got)
+	}
+	if got := logs[0].Details["traceparent"]; got != traceparent {
+		t.Errorf("Expected traceparent in details, got %v", got)
+	}
+	if got := logs[0].Details["version_id"]; got != "v1" {
+		t.Errorf("Expected caller details kept, got %v", got)
+	}
+	if _, ok := details["request_id"]; ok {
+		t.Error("The caller's Details map was changed")
+	}
+}
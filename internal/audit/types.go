@@ -1,6 +1,9 @@
 package audit
 
-import "context"
+import (
+	"context"
+	"math"
+)
 
 // Event Types - Authentication Events
 const (
@@ -39,10 +42,32 @@ const (
 	EventTypeObjectShared     = "object_shared"
 )
 
+// Event Types - Object Lock Events. Records of these events are kept past the
+// audit retention period for as long as the object version stays locked.
+const (
+	EventTypeObjectRetentionSet      = "object_retention_set"
+	EventTypeObjectRetentionExtended = "object_retention_extended"
+	EventTypeObjectLegalHoldSet      = "object_legal_hold_set"
+)
+
+// LockedIndefinitely is the AuditEvent.LockedUntil of an object version under
+// legal hold, which has no end date.
+const LockedIndefinitely int64 = math.MaxInt64
+
+// IsObjectLockEvent reports whether eventType records an object lock change.
+func IsObjectLockEvent(eventType string) bool {
+	switch eventType {
+	case EventTypeObjectRetentionSet, EventTypeObjectRetentionExtended, EventTypeObjectLegalHoldSet:
+		return true
+	}
+	return false
+}
+
 // Event Types - Access Key Events
 const (
 	EventTypeAccessKeyCreated       = "access_key_created"
 	EventTypeAccessKeyDeleted       = "access_key_deleted"
+	EventTypeAccessKeyUpdated       = "access_key_updated"
 	EventTypeAccessKeyStatusChanged = "access_key_status_changed"
 )
 
@@ -115,6 +140,12 @@ type AuditEvent struct {
 	IPAddress    string                 // Client IP address
 	UserAgent    string                 // Client user agent
 	Details      map[string]interface{} // Additional details (stored as JSON)
+
+	// LockedUntil is set on object lock events: the Unix time the object
+	// version stays locked until after the change (0 = unlocked, or
+	// LockedIndefinitely). The version's lock history can't be purged
+	// before then. Details["version_id"] identifies the version.
+	LockedUntil int64
 }
 
 // AuditLog represents a stored audit log record
@@ -142,6 +173,7 @@ type AuditLogFilters struct {
 	UserID       string // Filter by user ID
 	EventType    string // Filter by event type
 	ResourceType string // Filter by resource type
+	ResourceID   string // Filter by resource ID (e.g. "bucket/key" for objects)
 	Action       string // Filter by action
 	Status       string // Filter by status (success/failed)
 	StartDate    int64  // Filter by start date (Unix timestamp)
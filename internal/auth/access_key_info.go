@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Limits on access key labels. Tag limits follow AWS IAM resource tags.
+const (
+	MaxAccessKeyNameLength        = 64
+	MaxAccessKeyDescriptionLength = 256
+	MaxAccessKeyTags              = 50
+	MaxAccessKeyTagKeyLength      = 128
+	MaxAccessKeyTagValueLength    = 256
+)
+
+// ErrInvalidAccessKeyInfo is wrapped by every AccessKeyInfo validation error.
+var ErrInvalidAccessKeyInfo = errors.New("invalid access key info")
+
+// AccessKeyInfo holds the operator-supplied labels of an access key, used to
+// tell keys apart when a user or tenant has many of them.
+type AccessKeyInfo struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Tags        map[string]string `json:"tags"`
+}
+
+// Validate checks the labels against the length and count limits.
+func (i AccessKeyInfo) Validate() error {
+	if utf8.RuneCountInString(i.Name) > MaxAccessKeyNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidAccessKeyInfo, MaxAccessKeyNameLength)
+	}
+	if utf8.RuneCountInString(i.Description) > MaxAccessKeyDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidAccessKeyInfo, MaxAccessKeyDescriptionLength)
+	}
+	if len(i.Tags) > MaxAccessKeyTags {
+		return fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidAccessKeyInfo, MaxAccessKeyTags)
+	}
+	for key, value := range i.Tags {
+		if key == "" || utf8.RuneCountInString(key) > MaxAccessKeyTagKeyLength {
+			return fmt.Errorf("%w: tag keys must be 1 to %d characters", ErrInvalidAccessKeyInfo, MaxAccessKeyTagKeyLength)
+		}
+		if utf8.RuneCountInString(value) > MaxAccessKeyTagValueLength {
+			return fmt.Errorf("%w: tag %q value must be at most %d characters", ErrInvalidAccessKeyInfo, key, MaxAccessKeyTagValueLength)
+		}
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/maxiofs/maxiofs/internal/lru"
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/sirupsen/logrus"
 )
 
@@ -18,18 +20,35 @@ type apiRateBucket struct {
 // for the S3 API using a token-bucket algorithm.
 type APIRateLimiter struct {
 	mu      sync.Mutex
-	buckets map[string]*apiRateBucket
+	buckets *lru.Cache[string, *apiRateBucket]
+	idle    time.Duration
 }
 
-// NewAPIRateLimiter creates a new API rate limiter.
-func NewAPIRateLimiter() *APIRateLimiter {
+// defaultAPIRateIdle is how long an unused key keeps its bucket by default.
+// A dropped bucket comes back full, which a key idle that long would have
+// refilled to anyway.
+const defaultAPIRateIdle = 2 * time.Minute
+
+// NewAPIRateLimiter creates a new API rate limiter tracking at most
+// maxEntries keys (0 = unbounded), least recently used first out. Keys unused
+// for idleTimeout (0 = 2 minutes) are dropped.
+func NewAPIRateLimiter(maxEntries int, idleTimeout time.Duration) *APIRateLimiter {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultAPIRateIdle
+	}
 	rl := &APIRateLimiter{
-		buckets: make(map[string]*apiRateBucket),
+		buckets: lru.New[string, *apiRateBucket](maxEntries),
+		idle:    idleTimeout,
 	}
 	go rl.cleanupLoop()
 	return rl
 }
 
+// Cache exposes the per-key table for metrics and memory pressure handling.
+func (rl *APIRateLimiter) Cache() lru.Shrinkable {
+	return rl.buckets
+}
+
 // Allow checks and consumes one token for the given key at the given ratePerSecond.
 // Returns true if the request is allowed.
 func (rl *APIRateLimiter) Allow(key string, ratePerSecond int) bool {
@@ -42,10 +61,10 @@ func (rl *APIRateLimiter) Allow(key string, ratePerSecond int) bool {
 	defer rl.mu.Unlock()
 
 	now := time.Now()
-	b, ok := rl.buckets[key]
+	b, ok := rl.buckets.Get(key)
 	if !ok {
 		b = &apiRateBucket{tokens: rate, lastFill: now}
-		rl.buckets[key] = b
+		rl.buckets.Put(key, b)
 	}
 
 	// Refill tokens based on elapsed time
@@ -63,18 +82,13 @@ func (rl *APIRateLimiter) Allow(key string, ratePerSecond int) bool {
 	return true
 }
 
-// cleanupLoop removes stale buckets every minute.
+// cleanupLoop removes idle buckets, checking at least once a minute.
 func (rl *APIRateLimiter) cleanupLoop() {
-	ticker := time.NewTicker(1 * time.Minute)
+	interval := min(rl.idle/2, time.Minute)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	for range ticker.C {
-		rl.mu.Lock()
-		for k, b := range rl.buckets {
-			if time.Since(b.lastFill) > 2*time.Minute {
-				delete(rl.buckets, k)
-			}
-		}
-		rl.mu.Unlock()
+		rl.buckets.RemoveIdle(rl.idle)
 	}
 }
 
@@ -120,3 +134,52 @@ func APIRateLimitMiddleware(sm SettingsManager, rl *APIRateLimiter) func(http.Ha
 		})
 	}
 }
+
+// AnonymousRateLimitMiddleware returns a Gorilla Mux middleware that limits
+// unauthenticated S3 requests (public-read buckets, bucket policies, share
+// links) per client IP based on the security.ratelimit_anonymous_per_second
+// setting, so anonymous clients can't exhaust a public bucket. It must run
+// after the auth middleware: requests carrying a user are never counted.
+// A presigned URL request is authenticated too, but its signature is only
+// checked later by the S3 handler, so it is exempt only when presignedValid
+// verifies it; query parameters alone prove nothing. A nil presignedValid
+// counts every presigned request. Throttled requests get 503 SlowDown.
+func AnonymousRateLimitMiddleware(sm SettingsManager, rl *APIRateLimiter, trustedProxies []string, presignedValid func(*http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := GetUserFromContext(r.Context()); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if presignedValid != nil {
+				query := r.URL.Query()
+				if (query.Get("X-Amz-Algorithm") != "" || query.Get("AWSAccessKeyId") != "") && presignedValid(r) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			if enabled, err := sm.GetBool("security.ratelimit_enabled"); err == nil && !enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ratePerSecond := 20 // default
+			if v, err := sm.GetInt("security.ratelimit_anonymous_per_second"); err == nil {
+				ratePerSecond = v
+			}
+
+			clientIP := middleware.ClientIP(r, trustedProxies)
+			if !rl.Allow("anon:"+clientIP, ratePerSecond) {
+				logrus.WithFields(logrus.Fields{
+					"client_ip": clientIP,
+					"rate":      ratePerSecond,
+				}).Warn("Anonymous S3 rate limit exceeded")
+				w.Header().Set("Retry-After", "1")
+				writeS3Error(w, r, "SlowDown", "Please reduce your request rate.", http.StatusServiceUnavailable)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
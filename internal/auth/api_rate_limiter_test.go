@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errSettingNotFound = errors.New("setting not found")
+
+// stubSettings serves fixed rate limit settings
+type stubSettings struct {
+	ints  map[string]int
+	bools map[string]bool
+}
+
+func (s stubSettings) GetInt(key string) (int, error) {
+	if v, ok := s.ints[key]; ok {
+		return v, nil
+	}
+	return 0, errSettingNotFound
+}
+
+func (s stubSettings) GetBool(key string) (bool, error) {
+	if v, ok := s.bools[key]; ok {
+		return v, nil
+	}
+	return false, errSettingNotFound
+}
+
+func TestAnonymousRateLimitMiddleware(t *testing.T) {
+	sm := stubSettings{ints: map[string]int{"security.ratelimit_anonymous_per_second": 3}}
+	// Stands in for the S3 handler's presigned signature check
+	presignedValid := func(r *http.Request) bool {
+		return r.URL.Query().Get("X-Amz-Signature") == "valid"
+	}
+	handler := AnonymousRateLimitMiddleware(sm, NewAPIRateLimiter(0, 0), nil, presignedValid)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(remoteAddr string, user *User, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/public-bucket/object.txt"+query, nil)
+		req.RemoteAddr = remoteAddr
+		if user != nil {
+			req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("anonymous burst from one IP is throttled", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, serve("203.0.113.10:5000", nil, "").Code, "request %d within the burst", i)
+		}
+		rr := serve("203.0.113.10:5001", nil, "")
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+		assert.Contains(t, rr.Body.String(), "<Code>SlowDown</Code>")
+	})
+
+	t.Run("other IPs are unaffected", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("198.51.100.7:6000", nil, "").Code)
+	})
+
+	t.Run("authenticated requests are not counted", func(t *testing.T) {
+		user := &User{ID: "u1", TenantID: "t1"}
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, http.StatusOK, serve("203.0.113.10:5002", user, "").Code)
+		}
+	})
+
+	t.Run("presigned requests with a valid signature are not counted", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, http.StatusOK, serve("198.51.100.20:5006", nil, "?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Signature=valid").Code)
+		}
+	})
+
+	t.Run("presigned query parameters do not bypass the limit", func(t *testing.T) {
+		for _, query := range []string{"?X-Amz-Algorithm=x", "?AWSAccessKeyId=x"} {
+			assert.Equal(t, http.StatusServiceUnavailable, serve("203.0.113.10:5003", nil, query).Code, query)
+		}
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, serve("192.0.2.44:5004", nil, "?X-Amz-Algorithm=x").Code)
+		}
+		assert.Equal(t, http.StatusServiceUnavailable, serve("192.0.2.44:5005", nil, "?X-Amz-Algorithm=x").Code)
+	})
+
+	t.Run("disabled with rate limiting off or a zero limit", func(t *testing.T) {
+		for _, sm := range []stubSettings{
+			{bools: map[string]bool{"security.ratelimit_enabled": false}, ints: map[string]int{"security.ratelimit_anonymous_per_second": 1}},
+			{ints: map[string]int{"security.ratelimit_anonymous_per_second": 0}},
+		} {
+			h := AnonymousRateLimitMiddleware(sm, NewAPIRateLimiter(0, 0), nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			for i := 0; i < 5; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/public-bucket/object.txt", nil)
+				rr := httptest.NewRecorder()
+				h.ServeHTTP(rr, req)
+				assert.Equal(t, http.StatusOK, rr.Code)
+			}
+		}
+	})
+}
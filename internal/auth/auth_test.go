@@ -5,11 +5,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/maxiofs/maxiofs/internal/audit"
 	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -540,6 +543,90 @@ func TestAccountLockout(t *testing.T) {
 	assert.False(t, isLocked, "Account should be unlocked after manual unlock")
 }
 
+// TestAccountLockout_TenantPolicyAndAutoUnlock tests that a tenant's lockout
+// threshold and duration override the defaults, that the account unlocks by
+// itself once the window has passed, and that lock and unlock are audited
+func TestAccountLockout_TenantPolicyAndAutoUnlock(t *testing.T) {
+	manager, tmpDir := setupTestAuthManager(t)
+	defer cleanupTestAuthManager(t, tmpDir)
+
+	auditStore, err := audit.NewSQLiteStore(filepath.Join(tmpDir, "audit.db"), logrus.StandardLogger())
+	require.NoError(t, err)
+	auditMgr := audit.NewManager(auditStore, logrus.StandardLogger())
+	defer auditMgr.Close()
+	manager.(*authManager).SetAuditManager(auditMgr)
+
+	ctx := context.Background()
+
+	tenant := &Tenant{
+		ID:                     "tenant-lockout",
+		Name:                   "lockout-tenant",
+		Status:                 "active",
+		MaxFailedLoginAttempts: 2,
+		LockoutDurationSeconds: 1,
+	}
+	require.NoError(t, manager.CreateTenant(ctx, tenant))
+
+	stored, err := manager.GetTenant(ctx, tenant.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.MaxFailedLoginAttempts)
+	assert.Equal(t, int64(1), stored.LockoutDurationSeconds)
+
+	testUser := &User{
+		ID:        "lockout-policy-user",
+		Username:  "lockoutpolicy",
+		Password:  "TestPassword123!",
+		TenantID:  tenant.ID,
+		Status:    UserStatusActive,
+		Roles:     []string{"user"},
+		CreatedAt: time.Now().Unix(),
+		UpdatedAt: time.Now().Unix(),
+	}
+	require.NoError(t, manager.CreateUser(ctx, testUser))
+
+	// The tenant threshold is 2 attempts, not the default 5
+	require.NoError(t, manager.RecordFailedLogin(ctx, testUser.ID, "192.168.1.100"))
+	isLocked, _, err := manager.IsAccountLocked(ctx, testUser.ID)
+	require.NoError(t, err)
+	assert.False(t, isLocked)
+
+	require.NoError(t, manager.RecordFailedLogin(ctx, testUser.ID, "192.168.1.100"))
+	isLocked, lockedUntil, err := manager.IsAccountLocked(ctx, testUser.ID)
+	require.NoError(t, err)
+	require.True(t, isLocked, "Account should be locked after the tenant's 2 failed attempts")
+	assert.LessOrEqual(t, lockedUntil, time.Now().Unix()+1, "Lock should last the tenant's 1 second")
+
+	// Once the window has passed, the next check unlocks the account
+	time.Sleep(time.Until(time.Unix(lockedUntil, 0)) + 50*time.Millisecond)
+	isLocked, _, err = manager.IsAccountLocked(ctx, testUser.ID)
+	require.NoError(t, err)
+	assert.False(t, isLocked, "Account should auto-unlock after the lockout window")
+
+	user, err := manager.GetUser(ctx, testUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, user.FailedLoginAttempts)
+	assert.Equal(t, int64(0), user.LockedUntil)
+
+	// The counter starts over: one more failure doesn't lock again
+	require.NoError(t, manager.RecordFailedLogin(ctx, testUser.ID, "192.168.1.100"))
+	isLocked, _, err = manager.IsAccountLocked(ctx, testUser.ID)
+	require.NoError(t, err)
+	assert.False(t, isLocked)
+
+	auditMgr.Flush()
+	for _, eventType := range []string{audit.EventTypeUserBlocked, audit.EventTypeUserUnblocked} {
+		logs, _, err := auditMgr.GetLogs(ctx, &audit.AuditLogFilters{
+			EventType: eventType,
+			UserID:    testUser.ID,
+			Page:      1,
+			PageSize:  10,
+		})
+		require.NoError(t, err)
+		require.Len(t, logs, 1, eventType)
+		assert.Equal(t, tenant.ID, logs[0].TenantID, eventType)
+	}
+}
+
 // TestRateLimiting tests login rate limiting
 func TestRateLimiting(t *testing.T) {
 	manager, tmpDir := setupTestAuthManager(t)
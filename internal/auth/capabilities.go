@@ -22,6 +22,10 @@ const (
 	CapObjectDelete         = "object:delete"
 	CapObjectManageTags     = "object:manage_tags"
 	CapObjectManageVersions = "object:manage_versions"
+	// CapObjectBypassGovernance allows deleting objects under GOVERNANCE
+	// retention (x-amz-bypass-governance-retention). Unlike the others it is
+	// never implied by the admin role; see explicitCapabilities.
+	CapObjectBypassGovernance = "object:bypass_governance"
 
 	// Console & API access
 	CapConsoleAccess  = "console:access"
@@ -39,10 +43,18 @@ var AllCapabilities = []string{
 	CapObjectDelete,
 	CapObjectManageTags,
 	CapObjectManageVersions,
+	CapObjectBypassGovernance,
 	CapConsoleAccess,
 	CapKeysManageOwn,
 }
 
+// explicitCapabilities are granted only by an override or a role default,
+// never by the admin role alone, so that holding them is always a deliberate
+// grant that can be given to a single user without making them admin.
+var explicitCapabilities = map[string]bool{
+	CapObjectBypassGovernance: true,
+}
+
 // CapabilityOverride represents a per-user capability override set by an admin.
 type CapabilityOverride struct {
 	ID         string `json:"id"`
@@ -66,7 +78,8 @@ type EffectiveCapability struct {
 //  1. Explicit admin deny  → false (deny always wins)
 //  2. Explicit admin grant → true
 //  3. Role default         → true if the role includes this capability
-//  4. role == "admin"      → true (safety net: admin always has everything)
+//  4. role == "admin"      → true (safety net: admin always has everything
+//     except the explicitCapabilities)
 //  5. → false
 func (s *SQLiteStore) HasCapability(userID string, roles []string, capability string) (bool, error) {
 	// Check user-level overrides first.
@@ -84,7 +97,7 @@ func (s *SQLiteStore) HasCapability(userID string, roles []string, capability st
 
 	// Admin role is always allowed regardless of role_capabilities table.
 	for _, r := range roles {
-		if r == "admin" {
+		if r == "admin" && !explicitCapabilities[capability] {
 			return true, nil
 		}
 	}
@@ -158,7 +171,7 @@ func (s *SQLiteStore) GetEffectiveCapabilities(userID string, roles []string) ([
 		if g, overridden := overrides[cap]; overridden {
 			ec.Granted = g
 			ec.Source = "override"
-		} else if isAdmin || roleDefaults[cap] {
+		} else if (isAdmin && !explicitCapabilities[cap]) || roleDefaults[cap] {
 			ec.Granted = true
 			ec.Source = "role"
 		} else {
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultClockSkew is the allowed difference between a signed request's
+// timestamp and the server clock, matching AWS S3 (±15 minutes).
+const DefaultClockSkew = 15 * time.Minute
+
+// CheckRequestTimeSkew returns ErrTimestampSkew when requestTime is more than
+// skew away from now in either direction. A non-positive skew uses DefaultClockSkew.
+func CheckRequestTimeSkew(requestTime, now time.Time, skew time.Duration) error {
+	if skew <= 0 {
+		skew = DefaultClockSkew
+	}
+	diff := now.Sub(requestTime)
+	if diff > skew || diff < -skew {
+		return ErrTimestampSkew
+	}
+	return nil
+}
+
+// sigV4RequestTime returns the signing time of a header-signed SigV4 request:
+// X-Amz-Date (ISO 8601 basic format), falling back to the HTTP Date header.
+func sigV4RequestTime(r *http.Request) (time.Time, bool) {
+	if amzDate := r.Header.Get("X-Amz-Date"); amzDate != "" {
+		t, err := time.Parse("20060102T150405Z", amzDate)
+		return t, err == nil
+	}
+	if date := r.Header.Get("Date"); date != "" {
+		t, err := http.ParseTime(date)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// clockSkew returns the configured signature clock skew window.
+func (am *authManager) clockSkew() time.Duration {
+	if am.config.ClockSkewSeconds > 0 {
+		return time.Duration(am.config.ClockSkewSeconds) * time.Second
+	}
+	return DefaultClockSkew
+}
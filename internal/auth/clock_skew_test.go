@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRequestTimeSkew(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, CheckRequestTimeSkew(now.Add(-10*time.Minute), now, 0), "10 minutes behind is within the default window")
+	assert.NoError(t, CheckRequestTimeSkew(now.Add(10*time.Minute), now, 0), "10 minutes ahead is within the default window")
+	assert.ErrorIs(t, CheckRequestTimeSkew(now.Add(-20*time.Minute), now, 0), ErrTimestampSkew)
+	assert.ErrorIs(t, CheckRequestTimeSkew(now.Add(20*time.Minute), now, 0), ErrTimestampSkew)
+
+	// A configured window replaces the default
+	assert.ErrorIs(t, CheckRequestTimeSkew(now.Add(-10*time.Minute), now, 5*time.Minute), ErrTimestampSkew)
+	assert.NoError(t, CheckRequestTimeSkew(now.Add(-20*time.Minute), now, 30*time.Minute))
+}
+
+func TestSigV4RequestTime(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/bucket/key", nil)
+	_, ok := sigV4RequestTime(req)
+	assert.False(t, ok, "no date headers")
+
+	req.Header.Set("Date", "Sun, 01 Mar 2026 12:00:00 GMT")
+	got, ok := sigV4RequestTime(req)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), got.UTC())
+
+	// X-Amz-Date takes precedence over Date
+	req.Header.Set("X-Amz-Date", "20260301T121500Z")
+	got, ok = sigV4RequestTime(req)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 3, 1, 12, 15, 0, 0, time.UTC), got)
+}
+
+func TestAuthManager_ClockSkewFromConfig(t *testing.T) {
+	am := &authManager{}
+	assert.Equal(t, DefaultClockSkew, am.clockSkew())
+
+	am.config.ClockSkewSeconds = 120
+	assert.Equal(t, 2*time.Minute, am.clockSkew())
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// commonPasswordList is a bundled list of passwords that are among the first
+// tried by guessing attacks, one per line, lowercase.
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = func() map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[line] = struct{}{}
+		}
+	}
+	return set
+}()
+
+// IsCommonPassword reports whether password, ignoring case, is on the bundled
+// list of common passwords.
+func IsCommonPassword(password string) bool {
+	_, found := commonPasswords[strings.ToLower(password)]
+	return found
+}
@@ -24,6 +24,8 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/maxiofs/maxiofs/internal/audit"
 	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/lru"
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/pbkdf2"
 )
@@ -65,6 +67,8 @@ type Manager interface {
 
 	// Access key management
 	GenerateAccessKey(ctx context.Context, userID string) (*AccessKey, error)
+	GenerateNamedAccessKey(ctx context.Context, userID string, info AccessKeyInfo) (*AccessKey, error)
+	UpdateAccessKeyInfo(ctx context.Context, accessKeyID string, info AccessKeyInfo) error
 	GetAccessKey(ctx context.Context, accessKeyID string) (*AccessKey, error)
 	RevokeAccessKey(ctx context.Context, accessKey string) error
 	ListAccessKeys(ctx context.Context, userID string) ([]AccessKey, error)
@@ -170,6 +174,11 @@ type User struct {
 	LastFailedLogin     int64 `json:"last_failed_login,omitempty"`
 	LockedUntil         int64 `json:"locked_until,omitempty"`
 
+	// PasswordChangedAt is when the password was last set, for the
+	// security.password_max_age_days policy. 0 for users from before it was
+	// tracked, whose password age counts from CreatedAt.
+	PasswordChangedAt int64 `json:"password_changed_at,omitempty"`
+
 	// User preferences
 	ThemePreference    string `json:"themePreference,omitempty"`    // 'light', 'dark', 'system'
 	LanguagePreference string `json:"languagePreference,omitempty"` // 'en', 'es', etc.
@@ -194,6 +203,11 @@ type Tenant struct {
 	// (upload + download combined) in bytes/second. 0 = unlimited. Enforced by
 	// throttling (slowing), never rejecting. Set by global admins.
 	MaxBandwidthBytesPerSec int64          `json:"max_bandwidth_bytes_per_sec"`
+	// MaxFailedLoginAttempts and LockoutDurationSeconds override the global
+	// security.max_failed_attempts / security.lockout_duration settings for
+	// the tenant's users. 0 = use the global setting.
+	MaxFailedLoginAttempts int             `json:"max_failed_login_attempts"`
+	LockoutDurationSeconds int64           `json:"lockout_duration_seconds"`
 	MaxBuckets          int64             `json:"max_buckets"`
 	CurrentBuckets      int64             `json:"current_buckets"` // Incremented/decremented on create/delete
 	Metadata            map[string]string `json:"metadata,omitempty"`
@@ -245,12 +259,23 @@ type AccessKey struct {
 	Status          string `json:"status"` // active, inactive
 	CreatedAt       int64  `json:"created_at"`
 	LastUsed        int64  `json:"last_used,omitempty"`
+	// Name, Description and Tags are operator-supplied labels; they play no
+	// part in authentication.
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
 }
 
 // authManager implements the Manager interface
 type authManager struct {
 	config                    config.AuthConfig
-	jwtSecretMu               sync.RWMutex // protects config.JWTSecret for concurrent reads/writes
+	jwtSecretMu               sync.RWMutex     // protects config.JWTSecret for concurrent reads/writes
+	storageKeyMu              sync.RWMutex     // protects the master key fields below
+	masterKeySecret           string           // master key for stored secrets, see loadSecretEncryptionKey
+	masterKeyFile             string           // file masterKeySecret was read from, "" when configured
+	masterStorageKey          []byte           // AES key derived from masterKeySecret
+	previousStorageKey        []byte           // replaced master key, only to migrate secrets still using it
+	trustedNetworks           *trustedNetworks // auth.trusted_networks, nil when disabled
 	store                     *SQLiteStore
 	rateLimiter               *LoginRateLimiter
 	auditManager              *audit.Manager
@@ -283,14 +308,23 @@ func NewManager(cfg config.AuthConfig, dataDir string) Manager {
 	rateLimiter := NewLoginRateLimiter(5, 60)
 
 	manager := &authManager{
-		config:      cfg,
-		store:       store,
-		rateLimiter: rateLimiter,
+		config:           cfg,
+		store:            store,
+		rateLimiter:      rateLimiter,
+		trustedNetworks:  newTrustedNetworks(cfg.TrustedNetworks),
+	}
+
+	// The master key for stored secrets lives outside the database
+	if err := manager.loadSecretEncryptionKey(dataDir); err != nil {
+		logrus.WithError(err).Fatal("Failed to load the secret encryption key")
 	}
 
 	// Resolve JWT secret: explicit config > persisted DB value > auto-generated (save to DB)
 	manager.resolveJWTSecret()
 
+	// Keep no plaintext (or stale-key) secrets in the database
+	manager.migrateAccessKeySecrets()
+
 	// Create default admin user if not exists (without access keys)
 	_, err = store.GetUserByUsername("admin")
 	if err != nil {
@@ -751,6 +785,22 @@ func (am *authManager) ValidateS3SignatureV4(ctx context.Context, r *http.Reques
 		return nil, ErrInvalidSignature
 	}
 
+	// Reject requests signed too far from the server clock (replay window).
+	// A request without a usable date could otherwise be replayed forever.
+	requestTime, ok := sigV4RequestTime(r)
+	if !ok {
+		logrus.WithField("access_key", sig.AccessKey).Warn("SigV4 request has no valid X-Amz-Date or Date header")
+		return nil, ErrMissingRequestDate
+	}
+	if err := CheckRequestTimeSkew(requestTime, time.Now().UTC(), am.clockSkew()); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"access_key":   sig.AccessKey,
+			"request_time": requestTime,
+			"allowed_skew": am.clockSkew(),
+		}).Warn("SigV4 request time outside the allowed clock skew")
+		return nil, err
+	}
+
 	// Update last used
 	am.store.UpdateAccessKeyLastUsed(accessKey.AccessKeyID, time.Now().Unix())
 
@@ -1034,6 +1084,16 @@ func (am *authManager) ListUsers(ctx context.Context) ([]User, error) {
 
 // Access key management methods
 func (am *authManager) GenerateAccessKey(ctx context.Context, userID string) (*AccessKey, error) {
+	return am.GenerateNamedAccessKey(ctx, userID, AccessKeyInfo{})
+}
+
+// GenerateNamedAccessKey creates an access key carrying the given name,
+// description and tags.
+func (am *authManager) GenerateNamedAccessKey(ctx context.Context, userID string, info AccessKeyInfo) (*AccessKey, error) {
+	if err := info.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Verify user exists
 	_, err := am.store.GetUserByID(userID)
 	if err != nil {
@@ -1064,6 +1124,9 @@ func (am *authManager) GenerateAccessKey(ctx context.Context, userID string) (*A
 		UserID:          userID,
 		Status:          AccessKeyStatusActive,
 		CreatedAt:       time.Now().Unix(),
+		Name:            info.Name,
+		Description:     info.Description,
+		Tags:            info.Tags,
 	}
 
 	// Store in database
@@ -1078,9 +1141,20 @@ func (am *authManager) GenerateAccessKey(ctx context.Context, userID string) (*A
 		UserID:          userID,
 		Status:          AccessKeyStatusActive,
 		CreatedAt:       storeKey.CreatedAt,
+		Name:            info.Name,
+		Description:     info.Description,
+		Tags:            info.Tags,
 	}, nil
 }
 
+// UpdateAccessKeyInfo replaces the name, description and tags of an access key.
+func (am *authManager) UpdateAccessKeyInfo(ctx context.Context, accessKeyID string, info AccessKeyInfo) error {
+	if err := info.Validate(); err != nil {
+		return err
+	}
+	return am.store.UpdateAccessKeyInfo(accessKeyID, info)
+}
+
 func (am *authManager) GetAccessKey(ctx context.Context, accessKeyID string) (*AccessKey, error) {
 	key, err := am.store.GetAccessKey(accessKeyID)
 	if err != nil {
@@ -1106,10 +1180,12 @@ func (am *authManager) ListAccessKeys(ctx context.Context, userID string) ([]Acc
 		return nil, err
 	}
 
-	// Convert []*AccessKey to []AccessKey
+	// Convert []*AccessKey to []AccessKey. Listings never carry the secret;
+	// callers that need it for signing decrypt it via GetAccessKey.
 	keys := make([]AccessKey, len(keysPtrs))
 	for i, k := range keysPtrs {
 		keys[i] = *k
+		keys[i].SecretAccessKey = ""
 	}
 	return keys, nil
 }
@@ -1153,6 +1229,23 @@ func (am *authManager) Middleware() func(http.Handler) http.Handler {
 				return
 			}
 
+			// Clients on trusted networks may use a token or client certificate
+			// instead of SigV4; everyone else must sign
+			if user, ok, err := am.validateTrustedNetworkRequest(r); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"remote": r.RemoteAddr,
+					"error":  err.Error(),
+				}).Warn("Trusted network authentication failed")
+				writeS3Error(w, r, "AccessDenied", "Access Denied", http.StatusForbidden)
+				return
+			} else if ok {
+				ctx := context.WithValue(r.Context(), "user", user)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// Check if request has authentication headers
 			hasAuth := r.Header.Get("Authorization") != ""
 
@@ -1168,6 +1261,15 @@ func (am *authManager) Middleware() func(http.Handler) http.Handler {
 						"auth":   r.Header.Get("Authorization"),
 					}).Warn("Authentication failed")
 
+					if errors.Is(err, ErrTimestampSkew) {
+						writeS3Error(w, r, "RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.", http.StatusForbidden)
+						return
+					}
+					if errors.Is(err, ErrMissingRequestDate) {
+						writeS3Error(w, r, "AccessDenied", "AWS authentication requires a valid Date or x-amz-date header", http.StatusForbidden)
+						return
+					}
+
 					// Return S3-compatible XML error for 4xx errors
 					writeS3Error(w, r, "InvalidAccessKeyId", "The AWS Access Key Id you provided does not exist in our records.", http.StatusUnauthorized)
 					return
@@ -1464,8 +1566,18 @@ func (am *authManager) generateSecretAccessKey() (string, error) {
 	return base64.StdEncoding.EncodeToString(bytes), nil
 }
 
-// deriveStorageKey derives a 32-byte AES-256 key from the JWT secret for
-// encrypting S3 secret access keys at rest. Uses PBKDF2-SHA256 (SEC-04).
+// Stored secret formats. Values with neither prefix are plaintext secrets
+// written before SEC-04; they and "enc:" values are re-encrypted with the
+// master key by migrateAccessKeySecrets at startup.
+const (
+	secretPrefixJWTKey    = "enc:" // legacy: encrypted with a key derived from the JWT secret
+	secretPrefixMasterKey = "mk:"  // encrypted with the master key (see loadSecretEncryptionKey)
+)
+
+// deriveStorageKey derives the 32-byte AES-256 key older versions encrypted
+// S3 secret access keys with from the JWT secret (PBKDF2-SHA256, SEC-04).
+// The JWT secret is persisted in the database, so it is only used to read
+// such values back for migration.
 func (am *authManager) deriveStorageKey() []byte {
 	am.jwtSecretMu.RLock()
 	jwtSec := am.config.JWTSecret
@@ -1473,10 +1585,31 @@ func (am *authManager) deriveStorageKey() []byte {
 	return pbkdf2.Key([]byte(jwtSec), []byte("maxiofs-key-enc-v1"), 310000, 32, sha256.New)
 }
 
-// encryptSecret encrypts a plaintext S3 secret for storage.
-// Storage format: "enc:" + base64url(nonce || AES-256-GCM ciphertext+tag).
+// deriveMasterStorageKey derives the AES-256 key for secrets from the master
+// key, which unlike the JWT secret is not kept in the database.
+func deriveMasterStorageKey(masterKey string) []byte {
+	return pbkdf2.Key([]byte(masterKey), []byte("maxiofs-key-enc-v2"), 310000, 32, sha256.New)
+}
+
+// encryptSecret encrypts a plaintext S3 secret for storage with the master key.
+// Storage format: "mk:" + base64url(nonce || AES-256-GCM ciphertext+tag).
 func (am *authManager) encryptSecret(plaintext string) (string, error) {
-	block, err := aes.NewCipher(am.deriveStorageKey())
+	am.storageKeyMu.RLock()
+	key := am.masterStorageKey
+	am.storageKeyMu.RUnlock()
+	if key == nil {
+		return "", fmt.Errorf("encryptSecret: no secret encryption key loaded")
+	}
+	sealed, err := sealSecret(key, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return secretPrefixMasterKey + sealed, nil
+}
+
+// sealSecret encrypts plaintext with key into base64url(nonce || ciphertext+tag).
+func sealSecret(key []byte, plaintext string) (string, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", fmt.Errorf("encryptSecret: cipher init: %w", err)
 	}
@@ -1489,21 +1622,41 @@ func (am *authManager) encryptSecret(plaintext string) (string, error) {
 		return "", fmt.Errorf("encryptSecret: nonce: %w", err)
 	}
 	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return "enc:" + base64.RawURLEncoding.EncodeToString(ciphertext), nil
+	return base64.RawURLEncoding.EncodeToString(ciphertext), nil
 }
 
 // decryptSecret decrypts a value produced by encryptSecret.
-// Returns the stored value unchanged when it lacks the "enc:" prefix so that
+// Returns the stored value unchanged when it has no encryption prefix so that
 // existing plaintext keys continue to work (backward compatibility, SEC-04).
 func (am *authManager) decryptSecret(stored string) (string, error) {
-	if !strings.HasPrefix(stored, "enc:") {
+	switch {
+	case strings.HasPrefix(stored, secretPrefixMasterKey):
+		am.storageKeyMu.RLock()
+		current, previous := am.masterStorageKey, am.previousStorageKey
+		am.storageKeyMu.RUnlock()
+		encoded := stored[len(secretPrefixMasterKey):]
+		plaintext, err := openSecret(current, encoded)
+		if err != nil && previous != nil {
+			return openSecret(previous, encoded)
+		}
+		return plaintext, err
+	case strings.HasPrefix(stored, secretPrefixJWTKey):
+		return openSecret(am.deriveStorageKey(), stored[len(secretPrefixJWTKey):])
+	default:
 		return stored, nil // legacy plaintext key — unchanged
 	}
-	raw, err := base64.RawURLEncoding.DecodeString(stored[4:])
+}
+
+// openSecret decrypts the base64url(nonce || ciphertext+tag) part of a stored secret.
+func openSecret(key []byte, encoded string) (string, error) {
+	if key == nil {
+		return "", fmt.Errorf("decryptSecret: no secret encryption key loaded")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
 	if err != nil {
 		return "", fmt.Errorf("decryptSecret: base64 decode: %w", err)
 	}
-	block, err := aes.NewCipher(am.deriveStorageKey())
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", fmt.Errorf("decryptSecret: cipher init: %w", err)
 	}
@@ -1522,6 +1675,47 @@ func (am *authManager) decryptSecret(stored string) (string, error) {
 	return string(plaintext), nil
 }
 
+// migrateAccessKeySecrets re-encrypts stored secrets that are still plaintext,
+// encrypted with the legacy JWT-derived key, or encrypted with a replaced
+// master key, so the database holds no secret readable without the current key.
+func (am *authManager) migrateAccessKeySecrets() {
+	keys, err := am.store.ListAllAccessKeys()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to list access keys for secret encryption")
+		return
+	}
+
+	am.storageKeyMu.RLock()
+	current := am.masterStorageKey
+	am.storageKeyMu.RUnlock()
+	migrated := 0
+	for _, key := range keys {
+		if encoded, ok := strings.CutPrefix(key.SecretAccessKey, secretPrefixMasterKey); ok {
+			if _, err := openSecret(current, encoded); err == nil {
+				continue
+			}
+		}
+		plaintext, err := am.decryptSecret(key.SecretAccessKey)
+		if err != nil {
+			logrus.WithError(err).WithField("access_key_id", key.AccessKeyID).Warn("Cannot decrypt stored access key secret, leaving it unchanged")
+			continue
+		}
+		encrypted, err := am.encryptSecret(plaintext)
+		if err != nil {
+			logrus.WithError(err).WithField("access_key_id", key.AccessKeyID).Warn("Failed to encrypt access key secret")
+			continue
+		}
+		if err := am.store.UpdateAccessKeySecret(key.AccessKeyID, encrypted); err != nil {
+			logrus.WithError(err).WithField("access_key_id", key.AccessKeyID).Warn("Failed to store encrypted access key secret")
+			continue
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		logrus.Infof("Encrypted %d stored access key secrets", migrated)
+	}
+}
+
 // parseBasicToken parses and verifies a JWT token with HMAC-SHA256 signature
 // parseS3SignatureV4 parses AWS Signature Version 4
 func (am *authManager) parseS3SignatureV4(authHeader string, r *http.Request) (*S3SignatureV4, error) {
@@ -1973,9 +2167,7 @@ func IsAdminUser(ctx context.Context) bool {
 func writeS3Error(w http.ResponseWriter, r *http.Request, code, message string, statusCode int) {
 	// Generate S3-compatible tracing headers before WriteHeader so clients see them.
 	w.Header().Set("Content-Type", "application/xml")
-	requestID := generateS3RequestID()
-	w.Header().Set("X-Amz-Request-Id", requestID)
-	w.Header().Set("X-Amz-Id-2", generateS3AmzId2())
+	requestID, _ := middleware.AmzRequestIDs(w)
 	w.WriteHeader(statusCode)
 
 	type S3Error struct {
@@ -1996,20 +2188,6 @@ func writeS3Error(w http.ResponseWriter, r *http.Request, code, message string,
 	xml.NewEncoder(w).Encode(errorResponse)
 }
 
-// generateS3RequestID generates a 16-char uppercase hex request ID (matches S3 format).
-func generateS3RequestID() string {
-	b := make([]byte, 8)
-	rand.Read(b)
-	return strings.ToUpper(hex.EncodeToString(b))
-}
-
-// generateS3AmzId2 generates a 64-char hex host ID (matches S3 x-amz-id-2 format).
-func generateS3AmzId2() string {
-	b := make([]byte, 32)
-	rand.Read(b)
-	return hex.EncodeToString(b)
-}
-
 // CheckRateLimit checks if login is allowed from given IP address.
 // Reads ratelimit_enabled and ratelimit_login_per_minute from settings on every call (hot-reload).
 func (am *authManager) CheckRateLimit(ip string) bool {
@@ -2028,6 +2206,12 @@ func (am *authManager) CheckRateLimit(ip string) bool {
 	return am.rateLimiter.CheckAndRecord(ip)
 }
 
+// LoginRateLimitCache exposes the login rate limiter's per-IP table for
+// metrics and memory pressure handling.
+func (am *authManager) LoginRateLimitCache() lru.Shrinkable {
+	return am.rateLimiter.Cache()
+}
+
 // IsAccountLocked checks if an account is currently locked
 // Returns: (isLocked, lockedUntilTimestamp, error)
 func (am *authManager) IsAccountLocked(ctx context.Context, userID string) (bool, int64, error) {
@@ -2045,10 +2229,31 @@ func (am *authManager) IsAccountLocked(ctx context.Context, userID string) (bool
 
 	// Auto-unlock if lock period has expired
 	if lockedUntil > 0 && now >= lockedUntil {
-		am.store.UnlockAccount(userID)
+		if err := am.store.UnlockAccount(userID); err != nil {
+			return false, 0, err
+		}
 		logrus.WithFields(logrus.Fields{
 			"user_id": userID,
 		}).Info("Account auto-unlocked after lock period expired")
+
+		// Log audit event for automatic account unlock
+		if user, _ := am.store.GetUserByID(userID); user != nil {
+			am.logAuditEvent(ctx, &audit.AuditEvent{
+				TenantID:     user.TenantID,
+				UserID:       user.ID,
+				Username:     user.Username,
+				EventType:    audit.EventTypeUserUnblocked,
+				ResourceType: audit.ResourceTypeUser,
+				ResourceID:   user.ID,
+				ResourceName: user.Username,
+				Action:       audit.ActionUnblock,
+				Status:       audit.StatusSuccess,
+				Details: map[string]interface{}{
+					"reason":       "lockout_expired",
+					"locked_until": lockedUntil,
+				},
+			})
+		}
 		return false, 0, nil
 	}
 
@@ -2059,16 +2264,43 @@ func (am *authManager) IsAccountLocked(ctx context.Context, userID string) (bool
 	return false, 0, nil
 }
 
-// LockAccount manually locks a user account
-func (am *authManager) LockAccount(ctx context.Context, userID string) error {
-	// Get lockout duration from settings (default: 900 seconds = 15 minutes)
-	lockDuration := int64(15 * 60) // 15 minutes in seconds (default)
+// lockoutPolicy returns the failed-attempt threshold and lockout duration (in
+// seconds) that apply to a user: the tenant's overrides when set, otherwise
+// the global security.max_failed_attempts and security.lockout_duration
+// settings (defaults: 5 attempts, 15 minutes).
+func (am *authManager) lockoutPolicy(userID string) (maxFailedAttempts int, lockDuration int64) {
+	maxFailedAttempts = 5
+	lockDuration = int64(15 * 60)
 	if am.settingsManager != nil {
+		if max, err := am.settingsManager.GetInt("security.max_failed_attempts"); err == nil {
+			maxFailedAttempts = max
+		}
 		if duration, err := am.settingsManager.GetInt("security.lockout_duration"); err == nil {
 			lockDuration = int64(duration)
 		}
 	}
 
+	user, err := am.store.GetUserByID(userID)
+	if err != nil || user == nil || user.TenantID == "" {
+		return maxFailedAttempts, lockDuration
+	}
+	tenant, err := am.store.GetTenant(user.TenantID)
+	if err != nil || tenant == nil {
+		return maxFailedAttempts, lockDuration
+	}
+	if tenant.MaxFailedLoginAttempts > 0 {
+		maxFailedAttempts = tenant.MaxFailedLoginAttempts
+	}
+	if tenant.LockoutDurationSeconds > 0 {
+		lockDuration = tenant.LockoutDurationSeconds
+	}
+	return maxFailedAttempts, lockDuration
+}
+
+// LockAccount manually locks a user account
+func (am *authManager) LockAccount(ctx context.Context, userID string) error {
+	_, lockDuration := am.lockoutPolicy(userID)
+
 	err := am.store.LockAccount(userID, lockDuration)
 	if err != nil {
 		return err
@@ -2186,24 +2418,11 @@ func (am *authManager) RecordFailedLogin(ctx context.Context, userID, ip string)
 		"locked_until":    lockedUntil,
 	}).Warn("Failed login attempt recorded")
 
-	// Get max failed attempts from settings (default: 5)
-	maxFailedAttempts := 5
-	if am.settingsManager != nil {
-		if max, err := am.settingsManager.GetInt("security.max_failed_attempts"); err == nil {
-			maxFailedAttempts = max
-		}
-	}
+	// Threshold and duration come from the tenant, falling back to settings
+	maxFailedAttempts, lockDuration := am.lockoutPolicy(userID)
 
 	// Check if account should be locked due to failed attempts
 	if failedAttempts >= maxFailedAttempts {
-		// Get lockout duration from settings (default: 900 seconds = 15 minutes)
-		lockDuration := int64(15 * 60) // 15 minutes in seconds
-		if am.settingsManager != nil {
-			if duration, err := am.settingsManager.GetInt("security.lockout_duration"); err == nil {
-				lockDuration = int64(duration)
-			}
-		}
-
 		lockErr := am.store.LockAccount(userID, lockDuration)
 		if lockErr != nil {
 			logrus.WithError(lockErr).Error("Failed to lock account")
@@ -2217,39 +2436,34 @@ func (am *authManager) RecordFailedLogin(ctx context.Context, userID, ip string)
 				"locked_until":          time.Unix(newLockedUntil, 0).Format(time.RFC3339),
 			}).Warn("Account locked due to failed login attempts")
 
-			// Notify via callback if set
-			logrus.WithField("callback_set", am.userLockedCallback != nil).Info("Checking user locked callback")
-			if am.userLockedCallback != nil {
-				user, err := am.store.GetUserByID(userID)
-				if err != nil {
-					logrus.WithError(err).Error("Failed to get user for callback")
-				} else if user != nil {
-					logrus.WithField("user_id", userID).Info("Calling user locked callback")
+			user, err := am.store.GetUserByID(userID)
+			if err != nil || user == nil {
+				logrus.WithError(err).Error("Failed to get locked user")
+			} else {
+				// Log audit event for automatic account lock
+				am.logAuditEvent(ctx, &audit.AuditEvent{
+					TenantID:     user.TenantID,
+					UserID:       user.ID,
+					Username:     user.Username,
+					EventType:    audit.EventTypeUserBlocked,
+					ResourceType: audit.ResourceTypeUser,
+					ResourceID:   user.ID,
+					ResourceName: user.Username,
+					Action:       audit.ActionBlock,
+					Status:       audit.StatusSuccess,
+					IPAddress:    ip,
+					Details: map[string]interface{}{
+						"reason":           "max_failed_attempts",
+						"failed_attempts":  failedAttempts,
+						"duration_minutes": lockDuration / 60,
+						"locked_until":     newLockedUntil,
+					},
+				})
+
+				// Notify via callback if set
+				if am.userLockedCallback != nil {
 					am.userLockedCallback(user)
-
-					// Log audit event for automatic account lock
-					am.logAuditEvent(ctx, &audit.AuditEvent{
-						TenantID:     user.TenantID,
-						UserID:       user.ID,
-						Username:     user.Username,
-						EventType:    audit.EventTypeUserBlocked,
-						ResourceType: audit.ResourceTypeUser,
-						ResourceID:   user.ID,
-						ResourceName: user.Username,
-						Action:       audit.ActionBlock,
-						Status:       audit.StatusSuccess,
-						IPAddress:    ip,
-						Details: map[string]interface{}{
-							"reason":           "max_failed_attempts",
-							"failed_attempts":  failedAttempts,
-							"duration_minutes": lockDuration / 60,
-						},
-					})
-				} else {
-					logrus.Warn("User is nil, cannot call callback")
 				}
-			} else {
-				logrus.Warn("User locked callback is NOT set")
 			}
 		}
 	}
@@ -2455,8 +2669,20 @@ func (m *authManager) Verify2FACode(ctx context.Context, userID, code string) (b
 		return false, nil
 	}
 
-	// Verify TOTP code
-	return VerifyTOTPCode(user.TwoFactorSecret, code), nil
+	// Verify the TOTP code, once per time step: a code seen on the wire
+	// can't be replayed within its validity window
+	step, ok := matchTOTPStep(user.TwoFactorSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	claimed, err := m.store.ClaimTOTPStep(ctx, userID, step)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		logrus.WithField("user_id", userID).Warn("Rejected a TOTP code for an already used time step")
+	}
+	return claimed, nil
 }
 
 // RegenerateBackupCodes generates new backup codes for a user
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 // ========================================
@@ -1093,6 +1094,39 @@ func TestValidateS3SignatureV4_FullFlow(t *testing.T) {
 	accessKey := accessKeyObj.AccessKeyID
 	secretKey := accessKeyObj.SecretAccessKey
 
+	// signedRequest builds a correctly signed SigV4 request dated at signTime
+	signedRequest := func(signTime time.Time) *http.Request {
+		req, _ := http.NewRequest("GET", "/bucket/object.txt", nil)
+		req.Host = "s3.amazonaws.com"
+		req.Header.Set("X-Amz-Date", signTime.UTC().Format("20060102T150405Z"))
+		req.Header.Set("X-Amz-Content-Sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+
+		sig := &S3SignatureV4{
+			AccessKey:     accessKey,
+			Date:          signTime.UTC().Format("20060102"),
+			Region:        "us-east-1",
+			Service:       "s3",
+			SignedHeaders: "host;x-amz-content-sha256;x-amz-date",
+		}
+
+		// Calculate correct signature
+		canonicalRequest := manager.createCanonicalRequest(req, sig.SignedHeaders)
+		canonicalRequestHash := fmt.Sprintf("%x", sha256.Sum256([]byte(canonicalRequest)))
+		stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s/%s/%s/aws4_request\n%s",
+			req.Header.Get("X-Amz-Date"),
+			sig.Date,
+			sig.Region,
+			sig.Service,
+			canonicalRequestHash)
+		signature := manager.calculateSignatureV4(stringToSign, secretKey, sig.Date, sig.Region, sig.Service)
+
+		authHeader := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s/%s/%s/aws4_request, SignedHeaders=%s, Signature=%s",
+			accessKey, sig.Date, sig.Region, sig.Service, sig.SignedHeaders, signature)
+		req.Header.Set("Authorization", authHeader)
+
+		return req
+	}
+
 	tests := []struct {
 		name     string
 		setupReq func() *http.Request
@@ -1102,37 +1136,55 @@ func TestValidateS3SignatureV4_FullFlow(t *testing.T) {
 		{
 			name: "Valid SigV4 request",
 			setupReq: func() *http.Request {
+				return signedRequest(time.Now())
+			},
+			wantErr: false,
+		},
+		{
+			name: "Clock 10 minutes behind is within skew",
+			setupReq: func() *http.Request {
+				return signedRequest(time.Now().Add(-10 * time.Minute))
+			},
+			wantErr: false,
+		},
+		{
+			name: "Clock 20 minutes behind is rejected",
+			setupReq: func() *http.Request {
+				return signedRequest(time.Now().Add(-20 * time.Minute))
+			},
+			wantErr: true,
+			errType: ErrTimestampSkew,
+		},
+		{
+			name: "Clock 20 minutes ahead is rejected",
+			setupReq: func() *http.Request {
+				return signedRequest(time.Now().Add(20 * time.Minute))
+			},
+			wantErr: true,
+			errType: ErrTimestampSkew,
+		},
+		{
+			name: "Replayed request without a date header is rejected",
+			setupReq: func() *http.Request {
+				// Signed with only host and x-amz-content-sha256 so the
+				// signature still verifies once the date header is gone
 				req, _ := http.NewRequest("GET", "/bucket/object.txt", nil)
 				req.Host = "s3.amazonaws.com"
-				req.Header.Set("X-Amz-Date", "20240101T120000Z")
 				req.Header.Set("X-Amz-Content-Sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
 
-				sig := &S3SignatureV4{
-					AccessKey:     accessKey,
-					Date:          "20240101",
-					Region:        "us-east-1",
-					Service:       "s3",
-					SignedHeaders: "host;x-amz-content-sha256;x-amz-date",
-				}
-
-				// Calculate correct signature
-				canonicalRequest := manager.createCanonicalRequest(req, sig.SignedHeaders)
+				date := "20240101"
+				signedHeaders := "host;x-amz-content-sha256"
+				canonicalRequest := manager.createCanonicalRequest(req, signedHeaders)
 				canonicalRequestHash := fmt.Sprintf("%x", sha256.Sum256([]byte(canonicalRequest)))
-				stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s/%s/%s/aws4_request\n%s",
-					req.Header.Get("X-Amz-Date"),
-					sig.Date,
-					sig.Region,
-					sig.Service,
-					canonicalRequestHash)
-				signature := manager.calculateSignatureV4(stringToSign, secretKey, sig.Date, sig.Region, sig.Service)
-
-				authHeader := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s/%s/%s/aws4_request, SignedHeaders=%s, Signature=%s",
-					accessKey, sig.Date, sig.Region, sig.Service, sig.SignedHeaders, signature)
-				req.Header.Set("Authorization", authHeader)
-
+				stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s/us-east-1/s3/aws4_request\n%s",
+					"", date, canonicalRequestHash)
+				signature := manager.calculateSignatureV4(stringToSign, secretKey, date, "us-east-1", "s3")
+				req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s/us-east-1/s3/aws4_request, SignedHeaders=%s, Signature=%s",
+					accessKey, date, signedHeaders, signature))
 				return req
 			},
-			wantErr: false,
+			wantErr: true,
+			errType: ErrMissingRequestDate,
 		},
 		{
 			name: "Invalid signature",
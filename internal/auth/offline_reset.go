@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"unicode"
+
+	"github.com/maxiofs/maxiofs/internal/settings"
+	"github.com/sirupsen/logrus"
+)
+
+// Offline credential recovery. These functions open the auth database
+// directly from the data directory and are meant for the `maxiofs admin`
+// subcommands: having filesystem access to the data directory is the proof of
+// authority, so they are never reachable over the network.
+
+// openExistingStore opens the auth database under dataDir, refusing to create
+// a fresh one so a mistyped --data-dir fails instead of resetting nothing.
+func openExistingStore(dataDir string) (*SQLiteStore, error) {
+	dbPath := filepath.Join(dataDir, "db", "maxiofs.db")
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("no MaxIOFS database at %s: %w", dbPath, err)
+	}
+	return NewSQLiteStore(dataDir)
+}
+
+// GenerateRecoveryPassword returns a random password for ResetUserPassword
+// when the operator does not supply one. It has upper- and lowercase letters,
+// digits and a special character, so it passes the password rules whichever
+// of those are required.
+func GenerateRecoveryPassword() (string, error) {
+	b := make([]byte, 24)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to generate password: %w", err)
+		}
+		password := base64.RawURLEncoding.EncodeToString(b)
+		if containsRune(password, unicode.IsUpper) && containsRune(password, unicode.IsLower) &&
+			containsRune(password, unicode.IsDigit) && containsRune(password, isSpecialRune) {
+			return password, nil
+		}
+	}
+}
+
+// ResetUserPassword sets a local user's password (bcrypt, as for any password
+// change) and clears the account's failed-login lockout. The password must
+// pass the same rules as a change from the console. External (OAuth or LDAP)
+// users are rejected: their password lives in the identity provider.
+func ResetUserPassword(dataDir, username, password string) error {
+	store, err := openExistingStore(dataDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	policy, err := openPasswordPolicy(store)
+	if err != nil {
+		return err
+	}
+	if msg := CheckPasswordPolicy(policy, password); msg != "" {
+		return errors.New(msg)
+	}
+
+	user, err := store.GetUserByUsername(username)
+	if err != nil {
+		return fmt.Errorf("user %q not found: %w", username, err)
+	}
+	if user.AuthProvider != "" && user.AuthProvider != "local" {
+		return fmt.Errorf("user %q authenticates through %s; reset the password there", username, user.AuthProvider)
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := store.UpdateUserPassword(user.ID, hash); err != nil {
+		return err
+	}
+	if err := store.UnlockAccount(user.ID); err != nil {
+		return fmt.Errorf("password reset but failed to unlock the account: %w", err)
+	}
+	return nil
+}
+
+// openPasswordPolicy reads the password rules from the settings stored next
+// to the users, with the server's defaults for any that were never saved.
+func openPasswordPolicy(store *SQLiteStore) (PasswordPolicySettings, error) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	policy, err := settings.NewManager(store.db, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password rules: %w", err)
+	}
+	return policy, nil
+}
+
+// RegenerateJWTSecret replaces the persisted JWT signing secret with a new
+// random one and returns it. Every console session signed with the old secret
+// becomes invalid once the server restarts. An explicit auth.jwt_secret in the
+// configuration still takes precedence at startup (see resolveJWTSecret).
+func RegenerateJWTSecret(dataDir string) (string, error) {
+	store, err := openExistingStore(dataDir)
+	if err != nil {
+		return "", err
+	}
+	defer store.Close()
+
+	b := make([]byte, 48)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate JWT secret: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	am := &authManager{store: store}
+	if err := am.saveJWTSecretToDB(secret); err != nil {
+		return "", fmt.Errorf("failed to persist JWT secret: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// PasswordPolicySettings is where the password rules are read from
+// (settings.Manager). A rule whose setting can't be read is not enforced.
+type PasswordPolicySettings interface {
+	GetInt(key string) (int, error)
+	GetBool(key string) (bool, error)
+}
+
+// CheckPasswordPolicy checks a password against the settings-configured rules.
+// Returns a non-empty error message if validation fails, empty string if the password is valid.
+func CheckPasswordPolicy(settings PasswordPolicySettings, password string) string {
+	minLen := 8
+	if v, err := settings.GetInt("security.password_min_length"); err == nil && v > 0 {
+		minLen = v
+	}
+	if len(password) < minLen {
+		return fmt.Sprintf("Password must be at least %d characters", minLen)
+	}
+
+	requireUpper, _ := settings.GetBool("security.password_require_uppercase")
+	if requireUpper && !containsRune(password, unicode.IsUpper) {
+		return "Password must contain at least one uppercase letter"
+	}
+
+	requireLower, _ := settings.GetBool("security.password_require_lowercase")
+	if requireLower && !containsRune(password, unicode.IsLower) {
+		return "Password must contain at least one lowercase letter"
+	}
+
+	requireNumbers, _ := settings.GetBool("security.password_require_numbers")
+	if requireNumbers && !containsRune(password, unicode.IsDigit) {
+		return "Password must contain at least one number"
+	}
+
+	requireSpecial, _ := settings.GetBool("security.password_require_special")
+	if requireSpecial && !containsRune(password, isSpecialRune) {
+		return "Password must contain at least one special character"
+	}
+
+	blockCommon, _ := settings.GetBool("security.password_block_common")
+	if blockCommon && IsCommonPassword(password) {
+		return "Password is too common; choose a less predictable password"
+	}
+
+	return ""
+}
+
+// isSpecialRune reports whether r counts as a special character for the
+// password rules.
+func isSpecialRune(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// containsRune reports whether any rune of s satisfies f.
+func containsRune(s string, f func(rune) bool) bool {
+	for _, r := range s {
+		if f(r) {
+			return true
+		}
+	}
+	return false
+}
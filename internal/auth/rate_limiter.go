@@ -3,8 +3,15 @@ package auth
 import (
 	"sync"
 	"time"
+
+	"github.com/maxiofs/maxiofs/internal/lru"
 )
 
+// maxLoginRateEntries caps the number of IPs the login limiter tracks, so a
+// flood of logins from many addresses cannot grow it without bound. Past the
+// cap the least recently seen IP is forgotten.
+const maxLoginRateEntries = 100000
+
 // RateLimitAttempt tracks login attempts from an IP address for rate limiting
 type RateLimitAttempt struct {
 	Count    int
@@ -14,7 +21,7 @@ type RateLimitAttempt struct {
 
 // LoginRateLimiter implements in-memory rate limiting for login attempts
 type LoginRateLimiter struct {
-	attempts map[string]*RateLimitAttempt
+	attempts *lru.Cache[string, *RateLimitAttempt]
 	mu       sync.RWMutex
 	stopCh   chan struct{}
 
@@ -28,7 +35,7 @@ type LoginRateLimiter struct {
 // windowSeconds: time window in seconds for counting attempts
 func NewLoginRateLimiter(maxAttempts, windowSeconds int) *LoginRateLimiter {
 	limiter := &LoginRateLimiter{
-		attempts:      make(map[string]*RateLimitAttempt),
+		attempts:      lru.New[string, *RateLimitAttempt](maxLoginRateEntries),
 		maxAttempts:   maxAttempts,
 		windowSeconds: windowSeconds,
 		stopCh:        make(chan struct{}),
@@ -48,7 +55,7 @@ func (l *LoginRateLimiter) AllowLogin(ip string) bool {
 	defer l.mu.RUnlock()
 
 	now := time.Now()
-	attempt, exists := l.attempts[ip]
+	attempt, exists := l.attempts.Peek(ip)
 
 	if !exists {
 		// First attempt from this IP - allow it
@@ -76,25 +83,25 @@ func (l *LoginRateLimiter) RecordFailedAttempt(ip string) {
 	defer l.mu.Unlock()
 
 	now := time.Now()
-	attempt, exists := l.attempts[ip]
+	attempt, exists := l.attempts.Get(ip)
 
 	if !exists {
-		l.attempts[ip] = &RateLimitAttempt{
+		l.attempts.Put(ip, &RateLimitAttempt{
 			Count:    1,
 			FirstTry: now,
 			LastTry:  now,
-		}
+		})
 		return
 	}
 
 	// Check if window has expired
 	if now.Sub(attempt.FirstTry) > time.Duration(l.windowSeconds)*time.Second {
 		// Reset window
-		l.attempts[ip] = &RateLimitAttempt{
+		l.attempts.Put(ip, &RateLimitAttempt{
 			Count:    1,
 			FirstTry: now,
 			LastTry:  now,
-		}
+		})
 		return
 	}
 
@@ -117,7 +124,7 @@ func (l *LoginRateLimiter) UpdateMaxAttempts(n int) {
 func (l *LoginRateLimiter) ResetIP(ip string) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	delete(l.attempts, ip)
+	l.attempts.Delete(ip)
 }
 
 // CheckAndRecord atomically checks whether a login attempt from ip is allowed
@@ -129,15 +136,15 @@ func (l *LoginRateLimiter) CheckAndRecord(ip string) bool {
 	defer l.mu.Unlock()
 
 	now := time.Now()
-	attempt, exists := l.attempts[ip]
+	attempt, exists := l.attempts.Get(ip)
 
 	if !exists || now.Sub(attempt.FirstTry) > time.Duration(l.windowSeconds)*time.Second {
 		// No prior attempts or window expired — allow and record as first attempt.
-		l.attempts[ip] = &RateLimitAttempt{
+		l.attempts.Put(ip, &RateLimitAttempt{
 			Count:    1,
 			FirstTry: now,
 			LastTry:  now,
-		}
+		})
 		return true
 	}
 
@@ -156,7 +163,7 @@ func (l *LoginRateLimiter) GetAttempts(ip string) int {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
-	attempt, exists := l.attempts[ip]
+	attempt, exists := l.attempts.Peek(ip)
 	if !exists {
 		return 0
 	}
@@ -190,7 +197,12 @@ func (l *LoginRateLimiter) Stop() {
 	close(l.stopCh)
 }
 
-// cleanup removes expired entries from the map
+// Cache exposes the per-IP table for metrics and memory pressure handling.
+func (l *LoginRateLimiter) Cache() lru.Shrinkable {
+	return l.attempts
+}
+
+// cleanup removes expired entries from the table
 func (l *LoginRateLimiter) cleanup() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -198,9 +210,7 @@ func (l *LoginRateLimiter) cleanup() {
 	now := time.Now()
 	windowDuration := time.Duration(l.windowSeconds) * time.Second
 
-	for ip, attempt := range l.attempts {
-		if now.Sub(attempt.LastTry) > windowDuration {
-			delete(l.attempts, ip)
-		}
-	}
+	l.attempts.RemoveFunc(func(_ string, attempt *RateLimitAttempt) bool {
+		return now.Sub(attempt.LastTry) > windowDuration
+	})
 }
@@ -274,7 +274,7 @@ func TestLoginRateLimiter_Cleanup(t *testing.T) {
 	// After cleanup, all expired entries should be removed
 	// GetAttempts should return 0 (entries deleted)
 	limiter.mu.RLock()
-	mapSize := len(limiter.attempts)
+	mapSize := limiter.attempts.Len()
 	limiter.mu.RUnlock()
 
 	if mapSize != 0 {
@@ -304,8 +304,8 @@ func TestLoginRateLimiter_Cleanup_PartialExpiry(t *testing.T) {
 
 	// Old IP should be removed
 	limiter.mu.RLock()
-	_, oldExists := limiter.attempts[oldIP]
-	_, newExists := limiter.attempts[newIP]
+	_, oldExists := limiter.attempts.Peek(oldIP)
+	_, newExists := limiter.attempts.Peek(newIP)
 	limiter.mu.RUnlock()
 
 	if oldExists {
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecretEncryptionKey = "test-master-key-kept-outside-the-database"
+
+func newSecretTestManager(t *testing.T, dataDir, masterKey string) *authManager {
+	manager := NewManager(config.AuthConfig{
+		EnableAuth:          true,
+		JWTSecret:           "test-secret-key-for-testing-only-minimum-32-chars",
+		SecretEncryptionKey: masterKey,
+	}, dataDir).(*authManager)
+	t.Cleanup(func() { manager.store.Close() })
+	return manager
+}
+
+// storedSecret reads the secret column exactly as it is kept in the database.
+func storedSecret(t *testing.T, am *authManager, accessKeyID string) string {
+	var secret string
+	err := am.store.db.QueryRow(`SELECT secret_access_key FROM access_keys WHERE access_key_id = ?`, accessKeyID).Scan(&secret)
+	require.NoError(t, err)
+	return secret
+}
+
+func TestAccessKeySecretsEncryptedAtRest(t *testing.T) {
+	ctx := context.Background()
+	am := newSecretTestManager(t, t.TempDir(), testSecretEncryptionKey)
+
+	user := &User{Username: "secretuser", Password: "Password123!", Roles: []string{"user"}, Status: UserStatusActive}
+	require.NoError(t, am.CreateUser(ctx, user))
+	key, err := am.GenerateAccessKey(ctx, user.ID)
+	require.NoError(t, err)
+
+	stored := storedSecret(t, am, key.AccessKeyID)
+	assert.True(t, strings.HasPrefix(stored, secretPrefixMasterKey), "secret is encrypted with the master key")
+	assert.NotContains(t, stored, key.SecretAccessKey)
+
+	keys, err := am.ListAccessKeys(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Empty(t, keys[0].SecretAccessKey, "listings carry no secret")
+
+	_, err = am.ValidateCredentials(ctx, key.AccessKeyID, key.SecretAccessKey)
+	assert.NoError(t, err)
+
+	// A SigV4 request signed with the secret the client holds validates
+	// against the encrypted copy
+	now := time.Now().UTC()
+	req, _ := http.NewRequest("GET", "/bucket/object.txt", nil)
+	req.Host = "s3.amazonaws.com"
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	date := now.Format("20060102")
+	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s/us-east-1/s3/aws4_request\n%x",
+		req.Header.Get("X-Amz-Date"), date, sha256.Sum256([]byte(am.createCanonicalRequest(req, signedHeaders))))
+	signature := am.calculateSignatureV4(stringToSign, key.SecretAccessKey, date, "us-east-1", "s3")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s/us-east-1/s3/aws4_request, SignedHeaders=%s, Signature=%s",
+		key.AccessKeyID, date, signedHeaders, signature))
+
+	got, err := am.ValidateS3Signature(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+}
+
+func TestMigrateAccessKeySecrets(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+
+	am := newSecretTestManager(t, dataDir, "")
+	user := &User{Username: "legacyuser", Password: "Password123!", Roles: []string{"user"}, Status: UserStatusActive}
+	require.NoError(t, am.CreateUser(ctx, user))
+
+	// A plaintext secret written before secrets were encrypted...
+	require.NoError(t, am.store.CreateAccessKey(&AccessKey{
+		AccessKeyID:     "AKIALEGACYPLAINTEXT1",
+		SecretAccessKey: "legacy-plaintext-secret",
+		UserID:          user.ID,
+		Status:          AccessKeyStatusActive,
+		CreatedAt:       time.Now().Unix(),
+	}))
+	// ...one encrypted with the JWT-derived key by an older version...
+	legacy, err := sealSecret(am.deriveStorageKey(), "legacy-jwt-secret")
+	require.NoError(t, err)
+	require.NoError(t, am.store.CreateAccessKey(&AccessKey{
+		AccessKeyID:     "AKIALEGACYJWTKEY0001",
+		SecretAccessKey: secretPrefixJWTKey + legacy,
+		UserID:          user.ID,
+		Status:          AccessKeyStatusActive,
+		CreatedAt:       time.Now().Unix(),
+	}))
+	// ...and one encrypted with the generated key file
+	jwtKey, err := am.GenerateAccessKey(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(storedSecret(t, am, jwtKey.AccessKeyID), secretPrefixMasterKey))
+	require.NoError(t, am.store.Close())
+
+	// Restarting with a configured master key re-encrypts all three with it
+	am = newSecretTestManager(t, dataDir, testSecretEncryptionKey)
+	for accessKeyID, secret := range map[string]string{
+		"AKIALEGACYPLAINTEXT1": "legacy-plaintext-secret",
+		"AKIALEGACYJWTKEY0001": "legacy-jwt-secret",
+		jwtKey.AccessKeyID:     jwtKey.SecretAccessKey,
+	} {
+		stored := storedSecret(t, am, accessKeyID)
+		assert.True(t, strings.HasPrefix(stored, secretPrefixMasterKey), accessKeyID)
+		assert.NotContains(t, stored, secret, accessKeyID)
+
+		key, err := am.GetAccessKey(ctx, accessKeyID)
+		require.NoError(t, err)
+		assert.Equal(t, secret, key.SecretAccessKey, accessKeyID)
+	}
+	require.NoError(t, am.store.Close())
+
+	// Without the configured master key (only the key file) the secrets
+	// can't be recovered
+	am = newSecretTestManager(t, dataDir, "")
+	_, err = am.GetAccessKey(ctx, jwtKey.AccessKeyID)
+	assert.Error(t, err)
+	_, err = am.ValidateCredentials(ctx, jwtKey.AccessKeyID, jwtKey.SecretAccessKey)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestSecretsNotRecoverableFromDatabaseAlone(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+
+	// No master key configured: one is generated outside the database
+	am := newSecretTestManager(t, dataDir, "")
+	keyFile := filepath.Join(dataDir, SecretKeyFileName)
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	user := &User{Username: "dbthief", Password: "Password123!", Roles: []string{"user"}, Status: UserStatusActive}
+	require.NoError(t, am.CreateUser(ctx, user))
+	key, err := am.GenerateAccessKey(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(storedSecret(t, am, key.AccessKeyID), secretPrefixMasterKey))
+	require.NoError(t, am.store.Close())
+
+	// A copy of the database, which includes the JWT secret, without the key file
+	stolenDir := t.TempDir()
+	require.NoError(t, os.CopyFS(filepath.Join(stolenDir, "db"), os.DirFS(filepath.Join(dataDir, "db"))))
+	thief := newSecretTestManager(t, stolenDir, "")
+	_, err = thief.GetAccessKey(ctx, key.AccessKeyID)
+	assert.Error(t, err)
+	_, err = thief.ValidateCredentials(ctx, key.AccessKeyID, key.SecretAccessKey)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// The original data directory keeps working across restarts
+	am = newSecretTestManager(t, dataDir, "")
+	got, err := am.GetAccessKey(ctx, key.AccessKeyID)
+	require.NoError(t, err)
+	assert.Equal(t, key.SecretAccessKey, got.SecretAccessKey)
+}
+
+func TestAdoptSecretEncryptionKey(t *testing.T) {
+	ctx := context.Background()
+	am := newSecretTestManager(t, t.TempDir(), "")
+	user := &User{Username: "joiner", Password: "Password123!", Roles: []string{"user"}, Status: UserStatusActive}
+	require.NoError(t, am.CreateUser(ctx, user))
+	key, err := am.GenerateAccessKey(ctx, user.ID)
+	require.NoError(t, err)
+
+	// A joining node takes over the cluster's key and re-encrypts its secrets
+	require.NoError(t, am.AdoptSecretEncryptionKey(testSecretEncryptionKey))
+	assert.Equal(t, testSecretEncryptionKey, am.SecretEncryptionKey())
+	encoded, ok := strings.CutPrefix(storedSecret(t, am, key.AccessKeyID), secretPrefixMasterKey)
+	require.True(t, ok)
+	plaintext, err := openSecret(deriveMasterStorageKey(testSecretEncryptionKey), encoded)
+	require.NoError(t, err)
+	assert.Equal(t, key.SecretAccessKey, plaintext)
+
+	// A node with a configured key can't adopt a different one
+	configured := newSecretTestManager(t, t.TempDir(), testSecretEncryptionKey)
+	assert.NoError(t, configured.AdoptSecretEncryptionKey(testSecretEncryptionKey))
+	assert.Error(t, configured.AdoptSecretEncryptionKey("another-cluster-master-key-of-enough-length"))
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SecretKeyFileName is the default name, under the data directory, of the
+// generated master key for stored S3 secrets.
+const SecretKeyFileName = "secret_encryption.key"
+
+// minSecretEncryptionKeyLen matches the auth.secret_encryption_key validation.
+const minSecretEncryptionKeyLen = 32
+
+// loadSecretEncryptionKey sets the master key stored S3 secrets are encrypted
+// with. auth.secret_encryption_key wins when set; otherwise the key is read
+// from auth.secret_encryption_key_file (default <data_dir>/secret_encryption.key),
+// which is generated on first start. The key is never written to the
+// database, so a copy of the database alone does not reveal the secrets.
+//
+// When the configured key replaces one in an existing key file, the file key
+// is kept to decrypt secrets written with it until migrateAccessKeySecrets
+// has re-encrypted them.
+func (am *authManager) loadSecretEncryptionKey(dataDir string) error {
+	path := am.config.SecretEncryptionKeyFile
+	if path == "" {
+		path = filepath.Join(dataDir, SecretKeyFileName)
+	}
+
+	if configured := am.config.SecretEncryptionKey; configured != "" {
+		am.masterKeySecret = configured
+		am.masterStorageKey = deriveMasterStorageKey(configured)
+		if fileKey, err := readSecretKeyFile(path); err == nil && fileKey != configured {
+			am.previousStorageKey = deriveMasterStorageKey(fileKey)
+		}
+		return nil
+	}
+
+	key, err := readSecretKeyFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if key, err = generateSecretEncryptionKey(); err != nil {
+			return err
+		}
+		if err := writeSecretKeyFile(path, key); err != nil {
+			return err
+		}
+		logrus.WithField("path", path).Info("Generated master key for stored access key secrets; back it up separately from the database")
+	} else if err != nil {
+		return err
+	}
+
+	am.masterKeyFile = path
+	am.masterKeySecret = key
+	am.masterStorageKey = deriveMasterStorageKey(key)
+	return nil
+}
+
+// SecretEncryptionKey returns the master key for stored S3 secrets. It is
+// handed to nodes joining the cluster, which must decrypt replicated secrets.
+func (am *authManager) SecretEncryptionKey() string {
+	am.storageKeyMu.RLock()
+	defer am.storageKeyMu.RUnlock()
+	return am.masterKeySecret
+}
+
+// AdoptSecretEncryptionKey switches this node to the cluster's master key for
+// stored S3 secrets: the key file is replaced and local secrets are
+// re-encrypted. A node whose key is set in auth.secret_encryption_key cannot
+// adopt a different one and returns an error.
+func (am *authManager) AdoptSecretEncryptionKey(key string) error {
+	am.storageKeyMu.Lock()
+	if key == am.masterKeySecret {
+		am.storageKeyMu.Unlock()
+		return nil
+	}
+	if am.masterKeyFile == "" {
+		am.storageKeyMu.Unlock()
+		return fmt.Errorf("auth.secret_encryption_key differs from the cluster's; set the same value on every node")
+	}
+	if len(key) < minSecretEncryptionKeyLen {
+		am.storageKeyMu.Unlock()
+		return fmt.Errorf("cluster secret encryption key is too short")
+	}
+	if err := writeSecretKeyFile(am.masterKeyFile, key); err != nil {
+		am.storageKeyMu.Unlock()
+		return err
+	}
+	am.previousStorageKey = am.masterStorageKey
+	am.masterKeySecret = key
+	am.masterStorageKey = deriveMasterStorageKey(key)
+	am.storageKeyMu.Unlock()
+
+	am.migrateAccessKeySecrets()
+	logrus.Info("Adopted the cluster master key for stored access key secrets")
+	return nil
+}
+
+// generateSecretEncryptionKey returns a random 256-bit key, base64 encoded.
+func generateSecretEncryptionKey() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate secret encryption key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func readSecretKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(data))
+	if len(key) < minSecretEncryptionKeyLen {
+		return "", fmt.Errorf("secret encryption key file %s must hold at least %d characters", path, minSecretEncryptionKeyLen)
+	}
+	return key, nil
+}
+
+// writeSecretKeyFile replaces path with key, readable by the owner only.
+func writeSecretKeyFile(path, key string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create secret encryption key directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(key+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write secret encryption key file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write secret encryption key file: %w", err)
+	}
+	return nil
+}
@@ -90,11 +90,11 @@ func (s *SQLiteStore) CreateUser(user *User) error {
 	}
 
 	_, err = tx.Exec(`
-		INSERT INTO users (id, username, password_hash, display_name, email, status, tenant_id, roles, policies, metadata, created_at, updated_at, auth_provider, external_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (id, username, password_hash, display_name, email, status, tenant_id, roles, policies, metadata, created_at, updated_at, auth_provider, external_id, password_changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, user.ID, user.Username, hashedPassword, user.DisplayName, user.Email, user.Status,
 		nullString(user.TenantID), string(rolesJSON), string(policiesJSON), string(metadataJSON),
-		user.CreatedAt, user.UpdatedAt, authProvider, nullString(user.ExternalID))
+		user.CreatedAt, user.UpdatedAt, authProvider, nullString(user.ExternalID), user.CreatedAt)
 
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed: users.username") {
@@ -131,14 +131,14 @@ func (s *SQLiteStore) GetUserByUsername(username string) (*User, error) {
 	err := s.db.QueryRow(`
 		SELECT id, username, password_hash, display_name, email, status, tenant_id, roles, policies, metadata, created_at, updated_at,
 		       two_factor_enabled, two_factor_secret, two_factor_setup_at, backup_codes, backup_codes_used,
-		       theme_preference, language_preference, auth_provider, external_id
+		       theme_preference, language_preference, auth_provider, external_id, password_changed_at
 		FROM users
 		WHERE username = ? AND status != 'deleted'
 	`, username).Scan(
 		&user.ID, &user.Username, &user.Password, &user.DisplayName, &user.Email, &user.Status,
 		&tenantID, &rolesJSON, &policiesJSON, &metadataJSON, &user.CreatedAt, &user.UpdatedAt,
 		&user.TwoFactorEnabled, &twoFactorSecret, &twoFactorSetupAt, &backupCodesJSON, &backupCodesUsedJSON,
-		&themePreference, &languagePreference, &authProvider, &externalID,
+		&themePreference, &languagePreference, &authProvider, &externalID, &user.PasswordChangedAt,
 	)
 
 	if tenantID.Valid {
@@ -214,14 +214,14 @@ func (s *SQLiteStore) GetUserByID(userID string) (*User, error) {
 	err := s.db.QueryRow(`
 		SELECT id, username, password_hash, display_name, email, status, tenant_id, roles, policies, metadata, created_at, updated_at,
 		       two_factor_enabled, two_factor_secret, two_factor_setup_at, backup_codes, backup_codes_used,
-		       theme_preference, language_preference, auth_provider, external_id
+		       theme_preference, language_preference, auth_provider, external_id, password_changed_at
 		FROM users
 		WHERE id = ? AND status != 'deleted'
 	`, userID).Scan(
 		&user.ID, &user.Username, &user.Password, &user.DisplayName, &user.Email, &user.Status,
 		&tenantID, &rolesJSON, &policiesJSON, &metadataJSON, &user.CreatedAt, &user.UpdatedAt,
 		&user.TwoFactorEnabled, &twoFactorSecret, &twoFactorSetupAt, &backupCodesJSON, &backupCodesUsedJSON,
-		&themePreference, &languagePreference, &authProvider, &externalID,
+		&themePreference, &languagePreference, &authProvider, &externalID, &user.PasswordChangedAt,
 	)
 
 	if tenantID.Valid {
@@ -292,13 +292,13 @@ func (s *SQLiteStore) GetUserByExternalID(externalID, authProvider string) (*Use
 
 	err := s.db.QueryRow(`
 		SELECT id, username, password_hash, display_name, email, status, tenant_id, roles, policies, metadata, created_at, updated_at,
-		       two_factor_enabled, theme_preference, language_preference, auth_provider, external_id
+		       two_factor_enabled, theme_preference, language_preference, auth_provider, external_id, password_changed_at
 		FROM users
 		WHERE external_id = ? AND auth_provider = ? AND status != 'deleted'
 	`, externalID, authProvider).Scan(
 		&user.ID, &user.Username, &user.Password, &user.DisplayName, &user.Email, &user.Status,
 		&tenantID, &rolesJSON, &policiesJSON, &metadataJSON, &user.CreatedAt, &user.UpdatedAt,
-		&user.TwoFactorEnabled, &themePreference, &languagePreference, &authProv, &extID,
+		&user.TwoFactorEnabled, &themePreference, &languagePreference, &authProv, &extID, &user.PasswordChangedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -344,11 +344,14 @@ func (s *SQLiteStore) UpdateUser(user *User) error {
 	}
 	defer tx.Rollback()
 
+	// A new password hash restarts the password's age
 	_, err = tx.Exec(`
 		UPDATE users
-		SET display_name = ?, email = ?, status = ?, tenant_id = ?, roles = ?, policies = ?, metadata = ?, password_hash = ?, updated_at = ?
+		SET display_name = ?, email = ?, status = ?, tenant_id = ?, roles = ?, policies = ?, metadata = ?, password_hash = ?, updated_at = ?,
+		    password_changed_at = CASE WHEN password_hash != ? THEN ? ELSE password_changed_at END
 		WHERE id = ?
-	`, user.DisplayName, user.Email, user.Status, nullString(user.TenantID), string(rolesJSON), string(policiesJSON), string(metadataJSON), user.Password, user.UpdatedAt, user.ID)
+	`, user.DisplayName, user.Email, user.Status, nullString(user.TenantID), string(rolesJSON), string(policiesJSON), string(metadataJSON), user.Password, user.UpdatedAt,
+		user.Password, time.Now().Unix(), user.ID)
 
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
@@ -357,7 +360,8 @@ func (s *SQLiteStore) UpdateUser(user *User) error {
 	return tx.Commit()
 }
 
-// UpdateUserPassword updates only the password hash for a user
+// UpdateUserPassword updates only the password hash for a user. The new
+// password's age starts now.
 func (s *SQLiteStore) UpdateUserPassword(userID, passwordHash string) error {
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -365,11 +369,12 @@ func (s *SQLiteStore) UpdateUserPassword(userID, passwordHash string) error {
 	}
 	defer tx.Rollback()
 
+	now := time.Now().Unix()
 	_, err = tx.Exec(`
 		UPDATE users
-		SET password_hash = ?, updated_at = ?
+		SET password_hash = ?, updated_at = ?, password_changed_at = ?
 		WHERE id = ?
-	`, passwordHash, time.Now().Unix(), userID)
+	`, passwordHash, now, now, userID)
 
 	if err != nil {
 		return fmt.Errorf("failed to update user password: %w", err)
@@ -427,7 +432,7 @@ func (s *SQLiteStore) ListUsers() ([]*User, error) {
 	rows, err := s.db.Query(`
 		SELECT id, username, password_hash, display_name, email, status, tenant_id, roles, policies, metadata, created_at, updated_at,
 		       two_factor_enabled, two_factor_secret, two_factor_setup_at, backup_codes, backup_codes_used, locked_until,
-		       theme_preference, language_preference, auth_provider, external_id
+		       theme_preference, language_preference, auth_provider, external_id, password_changed_at
 		FROM users
 		WHERE status != 'deleted'
 		ORDER BY created_at DESC
@@ -456,7 +461,7 @@ func (s *SQLiteStore) ListUsers() ([]*User, error) {
 			&user.ID, &user.Username, &user.Password, &user.DisplayName, &user.Email, &user.Status,
 			&tenantID, &rolesJSON, &policiesJSON, &metadataJSON, &user.CreatedAt, &user.UpdatedAt,
 			&user.TwoFactorEnabled, &twoFactorSecret, &twoFactorSetupAt, &backupCodesJSON, &backupCodesUsedJSON, &lockedUntil,
-			&themePreference, &languagePreference, &authProvider, &externalID,
+			&themePreference, &languagePreference, &authProvider, &externalID, &user.PasswordChangedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -527,9 +532,10 @@ func (s *SQLiteStore) CreateAccessKey(key *AccessKey) error {
 	defer tx.Rollback()
 
 	_, err = tx.Exec(`
-		INSERT INTO access_keys (access_key_id, secret_access_key, user_id, status, created_at, last_used)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, key.AccessKeyID, key.SecretAccessKey, key.UserID, key.Status, key.CreatedAt, key.LastUsed)
+		INSERT INTO access_keys (access_key_id, secret_access_key, user_id, status, created_at, last_used, name, description, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, key.AccessKeyID, key.SecretAccessKey, key.UserID, key.Status, key.CreatedAt, key.LastUsed,
+		key.Name, key.Description, encodeAccessKeyTags(key.Tags))
 
 	if err != nil {
 		return fmt.Errorf("failed to create access key: %w", err)
@@ -542,13 +548,15 @@ func (s *SQLiteStore) CreateAccessKey(key *AccessKey) error {
 func (s *SQLiteStore) GetAccessKey(accessKeyID string) (*AccessKey, error) {
 	var key AccessKey
 	var lastUsed sql.NullInt64
+	var tags string
 
 	err := s.db.QueryRow(`
-		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used
+		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used, name, description, tags
 		FROM access_keys
 		WHERE access_key_id = ? AND status != 'deleted'
 	`, accessKeyID).Scan(
 		&key.AccessKeyID, &key.SecretAccessKey, &key.UserID, &key.Status, &key.CreatedAt, &lastUsed,
+		&key.Name, &key.Description, &tags,
 	)
 
 	if err == sql.ErrNoRows {
@@ -561,6 +569,7 @@ func (s *SQLiteStore) GetAccessKey(accessKeyID string) (*AccessKey, error) {
 	if lastUsed.Valid {
 		key.LastUsed = lastUsed.Int64
 	}
+	key.Tags = decodeAccessKeyTags(tags)
 
 	return &key, nil
 }
@@ -576,6 +585,53 @@ func (s *SQLiteStore) UpdateAccessKeyLastUsed(accessKeyID string, timestamp int6
 	return err
 }
 
+// UpdateAccessKeySecret replaces the stored (encrypted) secret of an access key
+func (s *SQLiteStore) UpdateAccessKeySecret(accessKeyID, secret string) error {
+	_, err := s.db.Exec(`
+		UPDATE access_keys
+		SET secret_access_key = ?
+		WHERE access_key_id = ?
+	`, secret, accessKeyID)
+
+	return err
+}
+
+// UpdateAccessKeyInfo replaces the name, description and tags of an access key
+func (s *SQLiteStore) UpdateAccessKeyInfo(accessKeyID string, info AccessKeyInfo) error {
+	result, err := s.db.Exec(`
+		UPDATE access_keys
+		SET name = ?, description = ?, tags = ?
+		WHERE access_key_id = ? AND status != 'deleted'
+	`, info.Name, info.Description, encodeAccessKeyTags(info.Tags), accessKeyID)
+	if err != nil {
+		return fmt.Errorf("failed to update access key: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("access key not found")
+	}
+	return nil
+}
+
+// encodeAccessKeyTags stores tags as a JSON object, or "" when there are none.
+func encodeAccessKeyTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(tags)
+	return string(data)
+}
+
+func decodeAccessKeyTags(data string) map[string]string {
+	if data == "" {
+		return nil
+	}
+	var tags map[string]string
+	if err := json.Unmarshal([]byte(data), &tags); err != nil {
+		return nil
+	}
+	return tags
+}
+
 // DeleteAccessKey permanently deletes an access key
 func (s *SQLiteStore) DeleteAccessKey(accessKeyID string) error {
 	tx, err := s.db.Begin()
@@ -595,7 +651,7 @@ func (s *SQLiteStore) DeleteAccessKey(accessKeyID string) error {
 // ListAccessKeysByUser returns all active access keys for a user
 func (s *SQLiteStore) ListAccessKeysByUser(userID string) ([]*AccessKey, error) {
 	rows, err := s.db.Query(`
-		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used
+		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used, name, description, tags
 		FROM access_keys
 		WHERE user_id = ? AND status != 'deleted'
 		ORDER BY created_at DESC
@@ -609,9 +665,11 @@ func (s *SQLiteStore) ListAccessKeysByUser(userID string) ([]*AccessKey, error)
 	for rows.Next() {
 		var key AccessKey
 		var lastUsed sql.NullInt64
+		var tags string
 
 		err := rows.Scan(
 			&key.AccessKeyID, &key.SecretAccessKey, &key.UserID, &key.Status, &key.CreatedAt, &lastUsed,
+			&key.Name, &key.Description, &tags,
 		)
 		if err != nil {
 			return nil, err
@@ -620,6 +678,7 @@ func (s *SQLiteStore) ListAccessKeysByUser(userID string) ([]*AccessKey, error)
 		if lastUsed.Valid {
 			key.LastUsed = lastUsed.Int64
 		}
+		key.Tags = decodeAccessKeyTags(tags)
 
 		keys = append(keys, &key)
 	}
@@ -630,7 +689,7 @@ func (s *SQLiteStore) ListAccessKeysByUser(userID string) ([]*AccessKey, error)
 // ListAllAccessKeys returns all active access keys
 func (s *SQLiteStore) ListAllAccessKeys() ([]*AccessKey, error) {
 	rows, err := s.db.Query(`
-		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used
+		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used, name, description, tags
 		FROM access_keys
 		WHERE status != 'deleted'
 		ORDER BY created_at DESC
@@ -644,9 +703,11 @@ func (s *SQLiteStore) ListAllAccessKeys() ([]*AccessKey, error) {
 	for rows.Next() {
 		var key AccessKey
 		var lastUsed sql.NullInt64
+		var tags string
 
 		err := rows.Scan(
 			&key.AccessKeyID, &key.SecretAccessKey, &key.UserID, &key.Status, &key.CreatedAt, &lastUsed,
+			&key.Name, &key.Description, &tags,
 		)
 		if err != nil {
 			return nil, err
@@ -655,6 +716,7 @@ func (s *SQLiteStore) ListAllAccessKeys() ([]*AccessKey, error) {
 		if lastUsed.Valid {
 			key.LastUsed = lastUsed.Int64
 		}
+		key.Tags = decodeAccessKeyTags(tags)
 
 		keys = append(keys, &key)
 	}
@@ -762,6 +824,7 @@ func (s *SQLiteStore) Enable2FA(ctx context.Context, userID string, secret strin
 			two_factor_setup_at = ?,
 			backup_codes = ?,
 			backup_codes_used = '[]',
+			totp_last_step = 0,
 			updated_at = ?
 		WHERE id = ?
 	`
@@ -797,6 +860,22 @@ func (s *SQLiteStore) Disable2FA(ctx context.Context, userID string) error {
 	return nil
 }
 
+// ClaimTOTPStep records step as the user's last used TOTP time step. It
+// returns false, leaving the record alone, when a code for the same or a
+// later step was already accepted.
+func (s *SQLiteStore) ClaimTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE users
+		SET totp_last_step = ?
+		WHERE id = ? AND totp_last_step < ?
+	`, step, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP step: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
 // GetUserWith2FA retrieves a user with 2FA data included
 func (s *SQLiteStore) GetUserWith2FA(ctx context.Context, userID string) (*User, error) {
 	query := `
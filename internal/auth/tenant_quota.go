@@ -0,0 +1,17 @@
+package auth
+
+import "fmt"
+
+// CheckBucketQuotaWithinTenant rejects a bucket size quota larger than the
+// space assigned to the tenant owning the bucket: the tenant quota is the hard
+// ceiling. A nil tenant or one without a storage quota imposes no limit.
+func CheckBucketQuotaWithinTenant(tenant *Tenant, maxSizeBytes int64) error {
+	if tenant == nil || tenant.MaxStorageBytes <= 0 {
+		return nil
+	}
+	if maxSizeBytes > tenant.MaxStorageBytes {
+		return fmt.Errorf("Bucket quota (%d bytes) cannot exceed the tenant's storage quota (%d bytes)",
+			maxSizeBytes, tenant.MaxStorageBytes)
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base32"
 	"fmt"
 	"strings"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
+	"github.com/sirupsen/logrus"
 	"github.com/skip2/go-qrcode"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -47,6 +50,26 @@ func Generate2FASecret(username, issuer string) (*TOTPSetup, error) {
 	}, nil
 }
 
+// totpPeriod is the TOTP time step in seconds
+const totpPeriod = 30
+
+// matchTOTPStep returns the time step, within ±1 period of now, that code
+// was generated for. Verify2FACode uses it to refuse a step already used.
+func matchTOTPStep(secret, code string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+	for step := current - 1; step <= current+1; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err == nil && subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
 // VerifyTOTPCode verifies a TOTP code against a secret
 // Allows for time skew of ±1 period (30 seconds before/after)
 func VerifyTOTPCode(secret, code string) bool {
@@ -61,6 +84,29 @@ func VerifyTOTPCode(secret, code string) bool {
 	return err == nil && valid
 }
 
+// VerifyMFAHeader checks an x-amz-mfa header ("<serial> <code>") against the
+// 2FA of the user, for operations protected by MFA Delete. Users have a
+// single TOTP device, so only the code is checked; a backup code is accepted
+// and used up like at login. A wrong code counts as a failed login from
+// clientIP, so repeated guesses lock the account like at the login form.
+func VerifyMFAHeader(ctx context.Context, am Manager, userID, header, clientIP string) error {
+	fields := strings.Fields(header)
+	if len(fields) == 0 || userID == "" {
+		return ErrMFARequired
+	}
+	if locked, _, err := am.IsAccountLocked(ctx, userID); err != nil || locked {
+		return ErrMFALocked
+	}
+	valid, err := am.Verify2FACode(ctx, userID, fields[len(fields)-1])
+	if err != nil || !valid {
+		if err := am.RecordFailedLogin(ctx, userID, clientIP); err != nil {
+			logrus.WithError(err).WithField("user_id", userID).Warn("Failed to record invalid MFA code")
+		}
+		return ErrMFAInvalid
+	}
+	return nil
+}
+
 // GenerateBackupCodes generates 10 random backup codes
 // Each code is 8 characters (uppercase alphanumeric)
 // Format: XXXX-XXXX for readability
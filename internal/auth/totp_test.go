@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -532,3 +533,37 @@ func TestBackupCodeHashVerifyRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+// TestVerify2FACode_RejectsReplay tests that a TOTP code is accepted only once
+// per time step, and that an older step is refused after a newer one
+func TestVerify2FACode_RejectsReplay(t *testing.T) {
+	manager, tmpDir := setupTestAuthManager(t)
+	defer cleanupTestAuthManager(t, tmpDir)
+	ctx := context.Background()
+
+	user := &User{ID: "replay-user", Username: "replay-user", Password: "Password123!", Status: UserStatusActive, Roles: []string{"user"}}
+	if err := manager.CreateUser(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	setup, err := manager.Setup2FA(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to set up 2FA: %v", err)
+	}
+	enableCode, _ := totp.GenerateCode(setup.Secret, time.Now())
+	if _, err := manager.Enable2FA(ctx, user.ID, enableCode, setup.Secret); err != nil {
+		t.Fatalf("Failed to enable 2FA: %v", err)
+	}
+
+	code, _ := totp.GenerateCode(setup.Secret, time.Now())
+	if valid, err := manager.Verify2FACode(ctx, user.ID, code); err != nil || !valid {
+		t.Fatalf("First use of the code should be valid: %v", err)
+	}
+	if valid, _ := manager.Verify2FACode(ctx, user.ID, code); valid {
+		t.Error("The same code must not be accepted twice")
+	}
+
+	pastCode, _ := totp.GenerateCode(setup.Secret, time.Now().Add(-30*time.Second))
+	if valid, _ := manager.Verify2FACode(ctx, user.ID, pastCode); valid {
+		t.Error("A code for an earlier time step must be refused once a later one was used")
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUntrustedIdentity is returned when a request from a trusted network
+// presents a bearer token that matches no configured identity.
+var ErrUntrustedIdentity = errors.New("unknown trusted network identity")
+
+// trustedNetworks is the parsed auth.trusted_networks configuration. A nil
+// value trusts no network.
+type trustedNetworks struct {
+	nets       []*net.IPNet
+	identities []config.TrustedIdentity
+}
+
+// newTrustedNetworks parses cfg, returning nil when no CIDRs are configured.
+// Invalid CIDRs are skipped; config validation rejects them at startup.
+func newTrustedNetworks(cfg config.TrustedNetworksConfig) *trustedNetworks {
+	if len(cfg.CIDRs) == 0 {
+		return nil
+	}
+	tn := &trustedNetworks{identities: cfg.Identities}
+	for _, cidr := range cfg.CIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			logrus.WithField("cidr", cidr).Warn("Ignoring invalid trusted network CIDR")
+			continue
+		}
+		tn.nets = append(tn.nets, ipNet)
+	}
+	return tn
+}
+
+// contains reports whether the TCP peer of r is on a trusted network.
+// Forwarded headers are deliberately ignored: they are set by the client.
+func (tn *trustedNetworks) contains(r *http.Request) bool {
+	if tn == nil {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range tn.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessKeyFor returns the access key the request's trusted credential maps
+// to. A bearer token must match an identity (ErrUntrustedIdentity
+// otherwise); a verified client certificate with an unmapped subject is not
+// an error, the request just falls back to SigV4. ok is false when the
+// request carries no trusted credential.
+func (tn *trustedNetworks) accessKeyFor(r *http.Request) (accessKey string, ok bool, err error) {
+	if token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		for _, id := range tn.identities {
+			if id.BearerToken != "" && subtle.ConstantTimeCompare([]byte(id.BearerToken), []byte(token)) == 1 {
+				return id.AccessKey, true, nil
+			}
+		}
+		return "", false, ErrUntrustedIdentity
+	}
+
+	// VerifiedChains is only set when the certificate chains to the client CA
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		subject := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		for _, id := range tn.identities {
+			if id.CertSubject != "" && id.CertSubject == subject {
+				return id.AccessKey, true, nil
+			}
+		}
+	}
+	return "", false, nil
+}
+
+// validateTrustedNetworkRequest authenticates a request from a trusted
+// network by its bearer token or client certificate instead of a signature.
+// ok is false when the request isn't from a trusted network or carries no
+// trusted credential; it must then be authenticated with SigV4. The mapped
+// access key and its user must be active, and the user's permissions apply
+// as for a signed request.
+func (am *authManager) validateTrustedNetworkRequest(r *http.Request) (*User, bool, error) {
+	if !am.trustedNetworks.contains(r) {
+		return nil, false, nil
+	}
+	accessKeyID, ok, err := am.trustedNetworks.accessKeyFor(r)
+	if !ok {
+		return nil, false, err
+	}
+
+	key, err := am.store.GetAccessKey(accessKeyID)
+	if err != nil || key.Status != AccessKeyStatusActive {
+		logrus.WithField("access_key", accessKeyID).Warn("Trusted network identity maps to a missing or inactive access key")
+		return nil, false, ErrInvalidCredentials
+	}
+	user, err := am.store.GetUserByID(key.UserID)
+	if err != nil {
+		return nil, false, ErrUserNotFound
+	}
+	if user.Status != UserStatusActive {
+		return nil, false, ErrInvalidCredentials
+	}
+
+	am.store.UpdateAccessKeyLastUsed(key.AccessKeyID, time.Now().Unix())
+	return user, true, nil
+}
@@ -18,7 +18,11 @@ var (
 	ErrMissingSignature     = errors.New("missing signature")
 	ErrInvalidSignature     = errors.New("invalid signature")
 	ErrTimestampSkew        = errors.New("timestamp skew too large")
+	ErrMissingRequestDate   = errors.New("request has no valid X-Amz-Date or Date header")
 	ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+	ErrMFARequired          = errors.New("MFA authentication must be used for this request")
+	ErrMFAInvalid           = errors.New("the MFA code provided is not valid")
+	ErrMFALocked            = errors.New("too many invalid MFA codes, the account is temporarily locked")
 )
 
 // Role represents a user role
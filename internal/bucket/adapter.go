@@ -1,6 +1,8 @@
 package bucket
 
 import (
+	"sort"
+
 	"github.com/maxiofs/maxiofs/internal/metadata"
 )
 
@@ -42,8 +44,18 @@ func toMetadataBucket(b *Bucket) *metadata.BucketMetadata {
 		// Per-bucket quota
 		Quota: b.Quota,
 
+		// Automatic retention on write
+		DefaultWriteLockDays: b.DefaultWriteLockDays,
+		NoOverwrite:          b.NoOverwrite,
+		MaxVersionsPerObject: b.MaxVersionsPerObject,
+		CacheDefaults:        b.CacheDefaults,
+		DefaultContentType:   b.DefaultContentType,
+		ScanUploads:          b.ScanUploads,
+
 		// HA replication
 		HA: b.HA,
+
+		Revision: b.Revision,
 	}
 }
 
@@ -84,8 +96,18 @@ func fromMetadataBucket(mb *metadata.BucketMetadata) *Bucket {
 		// Per-bucket quota
 		Quota: mb.Quota,
 
+		// Automatic retention on write
+		DefaultWriteLockDays: mb.DefaultWriteLockDays,
+		NoOverwrite:          mb.NoOverwrite,
+		MaxVersionsPerObject: mb.MaxVersionsPerObject,
+		CacheDefaults:        mb.CacheDefaults,
+		DefaultContentType:   mb.DefaultContentType,
+		ScanUploads:          mb.ScanUploads,
+
 		// HA replication
 		HA: mb.HA,
+
+		Revision: mb.Revision,
 	}
 }
 
@@ -95,8 +117,9 @@ func toMetadataVersioning(v *VersioningConfig) *metadata.VersioningMetadata {
 		return nil
 	}
 	return &metadata.VersioningMetadata{
-		Enabled: v.Status == "Enabled",
-		Status:  v.Status,
+		Enabled:   v.Status == "Enabled",
+		Status:    v.Status,
+		MFADelete: v.MFADelete,
 	}
 }
 
@@ -105,7 +128,8 @@ func fromMetadataVersioning(v *metadata.VersioningMetadata) *VersioningConfig {
 		return nil
 	}
 	return &VersioningConfig{
-		Status: v.Status,
+		Status:    v.Status,
+		MFADelete: v.MFADelete,
 	}
 }
 
@@ -253,10 +277,22 @@ func toMetadataLifecycleRule(r *LifecycleRule) metadata.LifecycleRule {
 		Status: r.Status,
 	}
 
-	// Simple prefix filter
+	// Prefix, single tag, or And filter
 	rule.Filter = &metadata.LifecycleFilter{
 		Prefix: r.Filter.Prefix,
 	}
+	if r.Filter.Tag != nil {
+		rule.Filter.Tags = map[string]string{r.Filter.Tag.Key: r.Filter.Tag.Value}
+	}
+	if r.Filter.And != nil {
+		rule.Filter.And = &metadata.LifecycleAnd{
+			Prefix: r.Filter.And.Prefix,
+			Tags:   make(map[string]string, len(r.Filter.And.Tags)),
+		}
+		for _, tag := range r.Filter.And.Tags {
+			rule.Filter.And.Tags[tag.Key] = tag.Value
+		}
+	}
 
 	// Expiration
 	if r.Expiration != nil {
@@ -289,7 +325,8 @@ func toMetadataLifecycleRule(r *LifecycleRule) metadata.LifecycleRule {
 	// NoncurrentVersionExpiration
 	if r.NoncurrentVersionExpiration != nil {
 		rule.NoncurrentVersionExpiration = &metadata.NoncurrentExpiration{
-			NoncurrentDays: r.NoncurrentVersionExpiration.NoncurrentDays,
+			NoncurrentDays:          r.NoncurrentVersionExpiration.NoncurrentDays,
+			NewerNoncurrentVersions: r.NoncurrentVersionExpiration.NewerNoncurrentVersions,
 		}
 	}
 
@@ -309,11 +346,20 @@ func fromMetadataLifecycleRule(r *metadata.LifecycleRule) LifecycleRule {
 		Status: r.Status,
 	}
 
-	// Simple prefix filter
+	// Prefix, single tag, or And filter
 	if r.Filter != nil {
 		rule.Filter = LifecycleFilter{
 			Prefix: r.Filter.Prefix,
 		}
+		for key, value := range r.Filter.Tags {
+			rule.Filter.Tag = &Tag{Key: key, Value: value}
+		}
+		if r.Filter.And != nil {
+			rule.Filter.And = &LifecycleFilterAnd{
+				Prefix: r.Filter.And.Prefix,
+				Tags:   sortedTags(r.Filter.And.Tags),
+			}
+		}
 	}
 
 	// Expiration
@@ -339,7 +385,8 @@ func fromMetadataLifecycleRule(r *metadata.LifecycleRule) LifecycleRule {
 	// NoncurrentVersionExpiration
 	if r.NoncurrentVersionExpiration != nil {
 		rule.NoncurrentVersionExpiration = &NoncurrentVersionExpiration{
-			NoncurrentDays: r.NoncurrentVersionExpiration.NoncurrentDays,
+			NoncurrentDays:          r.NoncurrentVersionExpiration.NoncurrentDays,
+			NewerNoncurrentVersions: r.NoncurrentVersionExpiration.NewerNoncurrentVersions,
 		}
 	}
 
@@ -353,6 +400,17 @@ func fromMetadataLifecycleRule(r *metadata.LifecycleRule) LifecycleRule {
 	return rule
 }
 
+// sortedTags converts a tag map to a slice ordered by key so that a stored
+// lifecycle filter reads back the same way every time.
+func sortedTags(m map[string]string) []Tag {
+	tags := make([]Tag, 0, len(m))
+	for key, value := range m {
+		tags = append(tags, Tag{Key: key, Value: value})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	return tags
+}
+
 // CORS conversion
 func toMetadataCORS(c *CORSConfig) *metadata.CORSMetadata {
 	if c == nil {
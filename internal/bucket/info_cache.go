@@ -0,0 +1,191 @@
+package bucket
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/lru"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+)
+
+// Bucket info cache consistency modes (storage.bucket_cache_consistency).
+const (
+	// BucketCacheStrong drops a bucket's entry on every change made through
+	// the manager, its object count and size included.
+	BucketCacheStrong = "strong"
+	// BucketCacheEventual drops it on configuration changes only: the object
+	// count and size it reports may lag by up to the TTL, and object writes
+	// keep hitting the cache.
+	BucketCacheEventual = "eventual"
+)
+
+// bucketInfoCache keeps recently read bucket metadata in memory so that
+// GetBucketInfo and BucketExists, called on every object request, don't read
+// the metadata store each time. Entries expire after ttl, which bounds how
+// long a change made without going through the manager (another node, a
+// direct store write) can go unseen; changes made through the manager drop
+// the entry right away. At most maxEntries buckets are kept, least recently
+// used first out.
+type bucketInfoCache struct {
+	ttl    time.Duration
+	strong bool
+
+	entries *lru.Cache[string, bucketInfoEntry]
+
+	mu sync.RWMutex
+	// gen is bumped by every invalidation. A read started before it is not
+	// cached, so a miss racing an update can't put the old metadata back.
+	gen uint64
+}
+
+type bucketInfoEntry struct {
+	meta    *metadata.BucketMetadata
+	expires time.Time
+}
+
+func newBucketInfoCache(ttl time.Duration, consistency string, maxEntries int) *bucketInfoCache {
+	return &bucketInfoCache{
+		ttl:     ttl,
+		strong:  consistency != BucketCacheEventual,
+		entries: lru.New[string, bucketInfoEntry](maxEntries),
+	}
+}
+
+func bucketInfoCacheKey(tenantID, name string) string {
+	return tenantID + "/" + name
+}
+
+// get returns the cached metadata of a bucket, or nil when there is none
+// or it has expired.
+func (c *bucketInfoCache) get(tenantID, name string) *metadata.BucketMetadata {
+	key := bucketInfoCacheKey(tenantID, name)
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		c.entries.Delete(key)
+		return nil
+	}
+	return entry.meta
+}
+
+// generation returns the value to pass to put for a read starting now.
+func (c *bucketInfoCache) generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gen
+}
+
+// put caches meta unless an invalidation happened since gen was taken.
+func (c *bucketInfoCache) put(tenantID, name string, meta *metadata.BucketMetadata, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen {
+		return
+	}
+	c.entries.Put(bucketInfoCacheKey(tenantID, name), bucketInfoEntry{meta: meta, expires: time.Now().Add(c.ttl)})
+}
+
+func (c *bucketInfoCache) invalidate(tenantID, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.entries.Delete(bucketInfoCacheKey(tenantID, name))
+}
+
+// SetBucketInfoCache turns on caching of bucket metadata for ttl with the
+// given consistency (BucketCacheStrong or BucketCacheEventual), holding at
+// most maxEntries buckets (0 = unbounded). A ttl of 0 turns it off, so every
+// read goes to the metadata store.
+func (bm *badgerBucketManager) SetBucketInfoCache(ttl time.Duration, consistency string, maxEntries int) {
+	if ttl <= 0 {
+		bm.infoCache = nil
+		return
+	}
+	bm.infoCache = newBucketInfoCache(ttl, consistency, maxEntries)
+}
+
+// BucketInfoCache returns the bucket metadata cache for metrics and memory
+// pressure handling, or nil when caching is off.
+func (bm *badgerBucketManager) BucketInfoCache() lru.Shrinkable {
+	if cache := bm.infoCache; cache != nil {
+		return cache.entries
+	}
+	return nil
+}
+
+// getBucketMetadata reads a bucket's metadata through the cache. The result
+// is the caller's own copy.
+func (bm *badgerBucketManager) getBucketMetadata(ctx context.Context, tenantID, name string) (*metadata.BucketMetadata, error) {
+	cache := bm.infoCache
+	if cache == nil {
+		return bm.metadataStore.GetBucket(ctx, tenantID, name)
+	}
+	if meta := cache.get(tenantID, name); meta != nil {
+		return cloneBucketMetadata(meta), nil
+	}
+	gen := cache.generation()
+	meta, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
+	if err != nil {
+		return nil, err
+	}
+	cache.put(tenantID, name, cloneBucketMetadata(meta), gen)
+	return meta, nil
+}
+
+// invalidateBucketInfo drops a bucket's cached metadata after a change to it.
+func (bm *badgerBucketManager) invalidateBucketInfo(tenantID, name string) {
+	if cache := bm.infoCache; cache != nil {
+		cache.invalidate(tenantID, name)
+	}
+}
+
+// invalidateBucketMetrics drops a bucket's cached metadata after a change to
+// its object count or size, in strong mode only.
+func (bm *badgerBucketManager) invalidateBucketMetrics(tenantID, name string) {
+	if cache := bm.infoCache; cache != nil && cache.strong {
+		cache.invalidate(tenantID, name)
+	}
+}
+
+// updateBucket writes a bucket's metadata and drops its cached copy.
+func (bm *badgerBucketManager) updateBucket(ctx context.Context, metaBucket *metadata.BucketMetadata) error {
+	err := bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	bm.invalidateBucketInfo(metaBucket.TenantID, metaBucket.Name)
+	return err
+}
+
+// cloneBucketMetadata copies the parts of meta that fromMetadataBucket hands
+// on by reference, so callers changing a Bucket in place can't change the
+// cached metadata. The configuration structs are rebuilt by the conversion.
+func cloneBucketMetadata(meta *metadata.BucketMetadata) *metadata.BucketMetadata {
+	clone := *meta
+	if meta.Tags != nil {
+		clone.Tags = make(map[string]string, len(meta.Tags))
+		for k, v := range meta.Tags {
+			clone.Tags[k] = v
+		}
+	}
+	if meta.Metadata != nil {
+		clone.Metadata = make(map[string]string, len(meta.Metadata))
+		for k, v := range meta.Metadata {
+			clone.Metadata[k] = v
+		}
+	}
+	if meta.Quota != nil {
+		quota := *meta.Quota
+		clone.Quota = &quota
+	}
+	if meta.CacheDefaults != nil {
+		defaults := *meta.CacheDefaults
+		clone.CacheDefaults = &defaults
+	}
+	if meta.HA != nil {
+		ha := *meta.HA
+		ha.ReplicaNodes = append([]metadata.HAReplicaNode(nil), meta.HA.ReplicaNodes...)
+		clone.HA = &ha
+	}
+	return &clone
+}
@@ -0,0 +1,171 @@
+package bucket
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore counts the bucket reads that reach the metadata store.
+type countingStore struct {
+	metadata.Store
+	reads atomic.Int64
+}
+
+func (s *countingStore) GetBucket(ctx context.Context, tenantID, name string) (*metadata.BucketMetadata, error) {
+	s.reads.Add(1)
+	return s.Store.GetBucket(ctx, tenantID, name)
+}
+
+func (s *countingStore) BucketExists(ctx context.Context, tenantID, name string) (bool, error) {
+	s.reads.Add(1)
+	return s.Store.BucketExists(ctx, tenantID, name)
+}
+
+func setupCachedBucketManager(tb testing.TB, ttl time.Duration, consistency string) (*badgerBucketManager, *countingStore) {
+	tmpDir, err := os.MkdirTemp("", "bucket-cache-test-*")
+	require.NoError(tb, err)
+	storageBackend, err := storage.NewFilesystemBackend(config.StorageConfig{Root: tmpDir + "/storage"})
+	require.NoError(tb, err)
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	pebbleStore, err := metadata.NewPebbleStore(metadata.PebbleOptions{DataDir: tmpDir + "/metadata", Logger: logger})
+	require.NoError(tb, err)
+	tb.Cleanup(func() {
+		storageBackend.Close()
+		pebbleStore.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	store := &countingStore{Store: pebbleStore}
+	bm := NewBadgerManager(storageBackend, store).(*badgerBucketManager)
+	bm.SetBucketInfoCache(ttl, consistency, 0)
+	return bm, store
+}
+
+func TestBucketInfoCache_ConfigChangesInvalidate(t *testing.T) {
+	ctx := context.Background()
+	bm, store := setupCachedBucketManager(t, time.Hour, BucketCacheStrong)
+	require.NoError(t, bm.CreateBucket(ctx, "tenant-1", "photos", "user-1"))
+	require.NoError(t, bm.SetBucketTags(ctx, "tenant-1", "photos", map[string]string{"team": "media"}))
+
+	_, err := bm.GetBucketInfo(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	reads := store.reads.Load()
+	info, err := bm.GetBucketInfo(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	exists, err := bm.BucketExists(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, reads, store.reads.Load(), "cached reads should not reach the store")
+
+	// Callers get their own copy
+	info.Tags["team"] = "mallory"
+	info, err = bm.GetBucketInfo(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "media"}, info.Tags)
+
+	require.NoError(t, bm.SetVersioning(ctx, "tenant-1", "photos", &VersioningConfig{Status: "Enabled"}))
+	info, err = bm.GetBucketInfo(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	require.NotNil(t, info.Versioning)
+	assert.Equal(t, "Enabled", info.Versioning.Status)
+
+	policy := &Policy{Version: "2012-10-17", Statement: []Statement{{
+		Effect:    "Deny",
+		Principal: "*",
+		Action:    "s3:GetObject",
+		Resource:  "arn:aws:s3:::photos/*",
+	}}}
+	require.NoError(t, bm.SetBucketPolicy(ctx, "tenant-1", "photos", policy))
+	info, err = bm.GetBucketInfo(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	require.NotNil(t, info.Policy)
+	assert.Equal(t, "Deny", info.Policy.Statement[0].Effect)
+
+	require.NoError(t, bm.SetObjectLockConfig(ctx, "tenant-1", "photos", &ObjectLockConfig{ObjectLockEnabled: true}))
+	info, err = bm.GetBucketInfo(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	require.NotNil(t, info.ObjectLock)
+	assert.True(t, info.ObjectLock.ObjectLockEnabled)
+
+	require.NoError(t, bm.IncrementObjectCount(ctx, "tenant-1", "photos", 100))
+	info, err = bm.GetBucketInfo(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), info.ObjectCount, "strong mode tracks metrics")
+	require.NoError(t, bm.DecrementObjectCount(ctx, "tenant-1", "photos", 100))
+
+	require.NoError(t, bm.DeleteBucket(ctx, "tenant-1", "photos"))
+	_, err = bm.GetBucketInfo(ctx, "tenant-1", "photos")
+	assert.ErrorIs(t, err, ErrBucketNotFound)
+	exists, err = bm.BucketExists(ctx, "tenant-1", "photos")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestBucketInfoCache_EventualMetricsAndTTL(t *testing.T) {
+	ctx := context.Background()
+	bm, store := setupCachedBucketManager(t, 50*time.Millisecond, BucketCacheEventual)
+	require.NoError(t, bm.CreateBucket(ctx, "", "logs", "user-1"))
+
+	_, err := bm.GetBucketInfo(ctx, "", "logs")
+	require.NoError(t, err)
+	require.NoError(t, bm.IncrementObjectCount(ctx, "", "logs", 10))
+	reads := store.reads.Load()
+	info, err := bm.GetBucketInfo(ctx, "", "logs")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.ObjectCount, "eventual mode serves cached metrics")
+	assert.Equal(t, reads, store.reads.Load())
+
+	// Configuration changes still show at once
+	require.NoError(t, bm.SetNoOverwrite(ctx, "", "logs", true))
+	info, err = bm.GetBucketInfo(ctx, "", "logs")
+	require.NoError(t, err)
+	assert.True(t, info.NoOverwrite)
+	assert.Equal(t, int64(1), info.ObjectCount)
+
+	require.NoError(t, bm.IncrementObjectCount(ctx, "", "logs", 10))
+	require.Eventually(t, func() bool {
+		info, err := bm.GetBucketInfo(ctx, "", "logs")
+		return err == nil && info.ObjectCount == 2
+	}, time.Second, 10*time.Millisecond, "entries expire after the TTL")
+}
+
+// BenchmarkGetBucketInfo compares the metadata store reads of GetBucketInfo
+// under parallel load with and without the cache.
+func BenchmarkGetBucketInfo(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{"uncached", 0},
+		{"cached", 5 * time.Second},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			ctx := context.Background()
+			bm, store := setupCachedBucketManager(b, bc.ttl, BucketCacheStrong)
+			require.NoError(b, bm.CreateBucket(ctx, "tenant-1", "hot", "user-1"))
+			store.reads.Store(0)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := bm.GetBucketInfo(ctx, "tenant-1", "hot"); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+			b.ReportMetric(float64(store.reads.Load())/float64(b.N), "store-reads/op")
+		})
+	}
+}
@@ -46,8 +46,29 @@ type Bucket struct {
 	// Optional per-bucket storage quota — nil means no bucket-level limit.
 	Quota *metadata.BucketQuota `json:"quota,omitempty"`
 
+	// Automatic GOVERNANCE retention (days) applied to every new object — 0 means off.
+	DefaultWriteLockDays int `json:"default_write_lock_days,omitempty"`
+
+	// Write-once mode: existing keys can't be overwritten.
+	NoOverwrite bool `json:"no_overwrite,omitempty"`
+
+	// Version cap per key (oldest unlocked noncurrent versions expire first) — 0 means unlimited.
+	MaxVersionsPerObject int `json:"max_versions_per_object,omitempty"`
+
+	// Default caching headers for GET/HEAD responses (CDN fronting) — nil means none.
+	CacheDefaults *metadata.BucketCacheDefaults `json:"cache_defaults,omitempty"`
+
+	// Content-Type for uploads that declare none and can't be sniffed — empty means application/octet-stream.
+	DefaultContentType string `json:"default_content_type,omitempty"`
+
+	// Uploads pass the configured upload scanner before they become visible.
+	ScanUploads bool `json:"scan_uploads,omitempty"`
+
 	// HA replication — nil means factor 1 (no HA, single node)
 	HA *metadata.BucketHA `json:"ha,omitempty"`
+
+	// Configuration revision, bumped on every update (see metadata.BucketMetadata)
+	Revision int64 `json:"revision,omitempty"`
 }
 
 // Manager defines the interface for bucket management
@@ -121,6 +142,24 @@ type Manager interface {
 	SetQuota(ctx context.Context, tenantID, name string, quota *metadata.BucketQuota) error
 	DeleteQuota(ctx context.Context, tenantID, name string) error
 
+	// Default write lock (automatic retention on upload, independent of Object Lock)
+	SetDefaultWriteLock(ctx context.Context, tenantID, name string, days int) error
+
+	// No-overwrite mode (write-once keys, independent of Object Lock and versioning)
+	SetNoOverwrite(ctx context.Context, tenantID, name string, enabled bool) error
+
+	// Per-key version cap for versioned buckets (0 = unlimited)
+	SetMaxVersionsPerObject(ctx context.Context, tenantID, name string, max int) error
+
+	// Default response caching headers (nil removes them)
+	SetCacheDefaults(ctx context.Context, tenantID, name string, defaults *metadata.BucketCacheDefaults) error
+
+	// Content-Type given to uploads without one ("" removes it)
+	SetDefaultContentType(ctx context.Context, tenantID, name, contentType string) error
+
+	// Upload scanning (virus scan / content validation before objects become visible)
+	SetScanUploads(ctx context.Context, tenantID, name string, enabled bool) error
+
 	// ACL operations
 	GetBucketACL(ctx context.Context, tenantID, name string) (interface{}, error)
 	SetBucketACL(ctx context.Context, tenantID, name string, acl interface{}) error
@@ -25,6 +25,10 @@ type badgerBucketManager struct {
 	// the server can fire SSE/email alerts as usage approaches the per-bucket
 	// quota. It receives the bucket's updated total size and its size cap.
 	quotaAlertCb func(tenantID, bucketName string, currentBytes, maxBytes int64)
+
+	// infoCache, when set, serves GetBucketInfo and BucketExists from memory
+	// (see SetBucketInfoCache).
+	infoCache *bucketInfoCache
 }
 
 // SetBucketQuotaAlertCallback registers a callback fired after every cached-size
@@ -115,6 +119,9 @@ func (bm *badgerBucketManager) CreateBucket(ctx context.Context, tenantID, name
 	// Store bucket metadata in the active metadata store.
 	metaBucket := toMetadataBucket(bucket)
 
+	// A cached miss is never negative, but a bucket recreated under the same
+	// name must not be served from its predecessor's entry
+	bm.invalidateBucketInfo(tenantID, name)
 	if err := bm.metadataStore.CreateBucket(ctx, metaBucket); err != nil {
 		if err == metadata.ErrBucketAlreadyExists {
 			return ErrBucketAlreadyExists
@@ -145,6 +152,7 @@ func (bm *badgerBucketManager) CreateBucket(ctx context.Context, tenantID, name
 			"tenant-id":      tenantID,
 		})
 	if err != nil {
+		bm.invalidateBucketInfo(tenantID, name)
 		if delErr := bm.metadataStore.DeleteBucket(ctx, tenantID, name); delErr != nil && delErr != metadata.ErrBucketNotFound {
 			logrus.WithError(delErr).WithFields(logrus.Fields{
 				"tenant_id": tenantID,
@@ -196,7 +204,7 @@ func (bm *badgerBucketManager) UpdateBucket(ctx context.Context, tenantID, name
 
 	// Update bucket metadata in the active metadata store.
 	metaBucket := toMetadataBucket(bucket)
-	if err := bm.metadataStore.UpdateBucket(ctx, metaBucket); err != nil {
+	if err := bm.updateBucket(ctx, metaBucket); err != nil {
 		if err == metadata.ErrBucketNotFound {
 			return ErrBucketNotFound
 		}
@@ -206,11 +214,44 @@ func (bm *badgerBucketManager) UpdateBucket(ctx context.Context, tenantID, name
 	return nil
 }
 
+// maxBucketModifyAttempts bounds how often modifyBucket re-reads a bucket
+// that keeps changing under it.
+const maxBucketModifyAttempts = 10
+
+// modifyBucket applies a configuration change to the bucket's current
+// metadata. The write is compare-and-swap against the revision that was read,
+// so a concurrent update is never overwritten: the change is re-applied to
+// the fresh metadata instead. A caller that set an expected revision (If-Match)
+// gets ErrBucketModified rather than a retry once the bucket moved past it.
+func (bm *badgerBucketManager) modifyBucket(ctx context.Context, tenantID, name string, apply func(metaBucket *metadata.BucketMetadata)) error {
+	expected, conditional := metadata.ExpectedBucketRevision(ctx)
+	for attempt := 1; ; attempt++ {
+		metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
+		if err != nil {
+			if err == metadata.ErrBucketNotFound {
+				return ErrBucketNotFound
+			}
+			return err
+		}
+		if conditional && metaBucket.Revision != expected {
+			return ErrBucketModified
+		}
+
+		apply(metaBucket)
+		err = bm.updateBucket(metadata.WithExpectedBucketRevision(ctx, metaBucket.Revision), metaBucket)
+		if err != metadata.ErrBucketModified || conditional || attempt == maxBucketModifyAttempts {
+			return err
+		}
+	}
+}
+
 // DeleteBucket deletes a bucket
 func (bm *badgerBucketManager) DeleteBucket(ctx context.Context, tenantID, name string) error {
 	// Atomically check for objects and delete the metadata entry in a single store call,
 	// eliminating the TOCTOU gap between the old isBucketEmpty + DeleteBucket pair.
-	if err := bm.metadataStore.DeleteBucketIfEmpty(ctx, tenantID, name); err != nil {
+	err := bm.metadataStore.DeleteBucketIfEmpty(ctx, tenantID, name)
+	bm.invalidateBucketInfo(tenantID, name)
+	if err != nil {
 		switch err {
 		case metadata.ErrBucketNotFound:
 			return ErrBucketNotFound
@@ -326,7 +367,9 @@ func (bm *badgerBucketManager) ForceDeleteBucket(ctx context.Context, tenantID,
 
 	// Now delete the bucket itself using the standard method (which will succeed since it's now empty)
 	// Delete bucket metadata from the active metadata store.
-	if err := bm.metadataStore.DeleteBucket(ctx, tenantID, name); err != nil {
+	err = bm.metadataStore.DeleteBucket(ctx, tenantID, name)
+	bm.invalidateBucketInfo(tenantID, name)
+	if err != nil {
 		if err == metadata.ErrBucketNotFound {
 			return ErrBucketNotFound
 		}
@@ -389,12 +432,15 @@ func (bm *badgerBucketManager) ListBuckets(ctx context.Context, tenantID string)
 
 // BucketExists checks if a bucket exists
 func (bm *badgerBucketManager) BucketExists(ctx context.Context, tenantID, name string) (bool, error) {
+	if bm.infoCache != nil && bm.infoCache.get(tenantID, name) != nil {
+		return true, nil
+	}
 	return bm.metadataStore.BucketExists(ctx, tenantID, name)
 }
 
 // GetBucketInfo retrieves bucket information
 func (bm *badgerBucketManager) GetBucketInfo(ctx context.Context, tenantID, name string) (*Bucket, error) {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
+	metaBucket, err := bm.getBucketMetadata(ctx, tenantID, name)
 	if err != nil {
 		if err == metadata.ErrBucketNotFound {
 			return nil, ErrBucketNotFound
@@ -424,18 +470,10 @@ func (bm *badgerBucketManager) GetBucketPolicy(ctx context.Context, tenantID, na
 
 // SetBucketPolicy sets the bucket policy
 func (bm *badgerBucketManager) SetBucketPolicy(ctx context.Context, tenantID, name string, policy *Policy) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-
-	// Update policy
-	metaBucket.Policy = toMetadataPolicy(policy)
-
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		// Update policy
+		metaBucket.Policy = toMetadataPolicy(policy)
+	})
 }
 
 // DeleteBucketPolicy deletes the bucket policy
@@ -462,17 +500,9 @@ func (bm *badgerBucketManager) GetVersioning(ctx context.Context, tenantID, name
 
 // SetVersioning sets the bucket versioning configuration
 func (bm *badgerBucketManager) SetVersioning(ctx context.Context, tenantID, name string, config *VersioningConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-
-	metaBucket.Versioning = toMetadataVersioning(config)
-
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Versioning = toMetadataVersioning(config)
+	})
 }
 
 // GetLifecycle retrieves the bucket lifecycle configuration
@@ -494,17 +524,9 @@ func (bm *badgerBucketManager) GetLifecycle(ctx context.Context, tenantID, name
 
 // SetLifecycle sets the bucket lifecycle configuration
 func (bm *badgerBucketManager) SetLifecycle(ctx context.Context, tenantID, name string, config *LifecycleConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-
-	metaBucket.Lifecycle = toMetadataLifecycle(config)
-
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Lifecycle = toMetadataLifecycle(config)
+	})
 }
 
 // DeleteLifecycle deletes the bucket lifecycle configuration
@@ -531,17 +553,9 @@ func (bm *badgerBucketManager) GetCORS(ctx context.Context, tenantID, name strin
 
 // SetCORS sets the bucket CORS configuration
 func (bm *badgerBucketManager) SetCORS(ctx context.Context, tenantID, name string, config *CORSConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-
-	metaBucket.CORS = toMetadataCORS(config)
-
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.CORS = toMetadataCORS(config)
+	})
 }
 
 // DeleteCORS deletes the bucket CORS configuration
@@ -566,28 +580,16 @@ func (bm *badgerBucketManager) GetWebsite(ctx context.Context, tenantID, name st
 
 // SetWebsite stores static website hosting configuration for a bucket.
 func (bm *badgerBucketManager) SetWebsite(ctx context.Context, tenantID, name string, config *WebsiteConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.Website = toMetadataWebsite(config)
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Website = toMetadataWebsite(config)
+	})
 }
 
 // DeleteWebsite removes the static website hosting configuration from a bucket.
 func (bm *badgerBucketManager) DeleteWebsite(ctx context.Context, tenantID, name string) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.Website = nil
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Website = nil
+	})
 }
 
 // SetQuota sets (or clears, when quota is nil) the per-bucket storage quota.
@@ -595,15 +597,9 @@ func (bm *badgerBucketManager) DeleteWebsite(ctx context.Context, tenantID, name
 // cached metrics and every other config, following the same pattern as the other
 // Set* config operations.
 func (bm *badgerBucketManager) SetQuota(ctx context.Context, tenantID, name string, quota *metadata.BucketQuota) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.Quota = quota
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Quota = quota
+	})
 }
 
 // DeleteQuota removes the per-bucket storage quota (equivalent to SetQuota nil).
@@ -611,6 +607,59 @@ func (bm *badgerBucketManager) DeleteQuota(ctx context.Context, tenantID, name s
 	return bm.SetQuota(ctx, tenantID, name, nil)
 }
 
+// SetDefaultWriteLock sets how many days each newly written object is kept
+// under GOVERNANCE retention. 0 turns the write lock off; objects already
+// written keep the retention they were given.
+func (bm *badgerBucketManager) SetDefaultWriteLock(ctx context.Context, tenantID, name string, days int) error {
+	if days < 0 {
+		return fmt.Errorf("default write lock days cannot be negative")
+	}
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.DefaultWriteLockDays = days
+	})
+}
+
+// SetNoOverwrite turns the bucket's write-once mode on or off. While it is on,
+// writes onto a key that already has a current object are rejected.
+func (bm *badgerBucketManager) SetNoOverwrite(ctx context.Context, tenantID, name string, enabled bool) error {
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.NoOverwrite = enabled
+	})
+}
+
+// SetMaxVersionsPerObject sets how many versions each key of the bucket may
+// keep. Writes that go over the cap expire the oldest unlocked noncurrent
+// versions; 0 removes the cap.
+func (bm *badgerBucketManager) SetMaxVersionsPerObject(ctx context.Context, tenantID, name string, max int) error {
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.MaxVersionsPerObject = max
+	})
+}
+
+// SetCacheDefaults sets the Cache-Control/Expires defaults GET and HEAD
+// responses carry for the bucket's objects; nil removes them.
+func (bm *badgerBucketManager) SetCacheDefaults(ctx context.Context, tenantID, name string, defaults *metadata.BucketCacheDefaults) error {
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.CacheDefaults = defaults
+	})
+}
+
+// SetDefaultContentType sets the Content-Type stored on uploads that declare
+// none and whose type can't be sniffed; "" removes it.
+func (bm *badgerBucketManager) SetDefaultContentType(ctx context.Context, tenantID, name, contentType string) error {
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.DefaultContentType = contentType
+	})
+}
+
+// SetScanUploads turns upload scanning on or off for the bucket. While it is
+// on, uploads only become visible once the upload scanner allowed them.
+func (bm *badgerBucketManager) SetScanUploads(ctx context.Context, tenantID, name string, enabled bool) error {
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.ScanUploads = enabled
+	})
+}
+
 // GetPublicAccessBlock retrieves the public access block configuration for a bucket.
 func (bm *badgerBucketManager) GetPublicAccessBlock(ctx context.Context, tenantID, name string) (*PublicAccessBlock, error) {
 	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
@@ -629,28 +678,16 @@ func (bm *badgerBucketManager) GetPublicAccessBlock(ctx context.Context, tenantI
 
 // SetPublicAccessBlock stores the public access block configuration for a bucket.
 func (bm *badgerBucketManager) SetPublicAccessBlock(ctx context.Context, tenantID, name string, config *PublicAccessBlock) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.PublicAccessBlock = toMetadataPublicAccessBlock(config)
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.PublicAccessBlock = toMetadataPublicAccessBlock(config)
+	})
 }
 
 // DeletePublicAccessBlock removes the public access block configuration from a bucket.
 func (bm *badgerBucketManager) DeletePublicAccessBlock(ctx context.Context, tenantID, name string) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.PublicAccessBlock = nil
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.PublicAccessBlock = nil
+	})
 }
 
 // GetOwnershipControls retrieves the ownership controls configuration for a bucket.
@@ -670,28 +707,16 @@ func (bm *badgerBucketManager) GetOwnershipControls(ctx context.Context, tenantI
 
 // SetOwnershipControls stores the ownership controls configuration for a bucket.
 func (bm *badgerBucketManager) SetOwnershipControls(ctx context.Context, tenantID, name string, config *OwnershipControlsConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.OwnershipControls = config.ObjectOwnership
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.OwnershipControls = config.ObjectOwnership
+	})
 }
 
 // DeleteOwnershipControls removes the ownership controls configuration from a bucket.
 func (bm *badgerBucketManager) DeleteOwnershipControls(ctx context.Context, tenantID, name string) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.OwnershipControls = ""
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.OwnershipControls = ""
+	})
 }
 
 // GetLogging retrieves the server access logging configuration for a bucket.
@@ -712,28 +737,16 @@ func (bm *badgerBucketManager) GetLogging(ctx context.Context, tenantID, name st
 
 // SetLogging stores the server access logging configuration for a bucket.
 func (bm *badgerBucketManager) SetLogging(ctx context.Context, tenantID, name string, config *LoggingConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.Logging = toMetadataLogging(config)
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Logging = toMetadataLogging(config)
+	})
 }
 
 // DeleteLogging removes the server access logging configuration from a bucket.
 func (bm *badgerBucketManager) DeleteLogging(ctx context.Context, tenantID, name string) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.Logging = nil
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Logging = nil
+	})
 }
 
 // GetEncryption retrieves the server-side encryption configuration for a bucket.
@@ -754,28 +767,16 @@ func (bm *badgerBucketManager) GetEncryption(ctx context.Context, tenantID, name
 
 // SetEncryption stores server-side encryption configuration for a bucket.
 func (bm *badgerBucketManager) SetEncryption(ctx context.Context, tenantID, name string, config *EncryptionConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.Encryption = toMetadataEncryption(config)
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Encryption = toMetadataEncryption(config)
+	})
 }
 
 // DeleteEncryption removes the server-side encryption configuration from a bucket.
 func (bm *badgerBucketManager) DeleteEncryption(ctx context.Context, tenantID, name string) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.Encryption = nil
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Encryption = nil
+	})
 }
 
 // GetNotification retrieves the bucket notification configuration.
@@ -797,30 +798,16 @@ func (bm *badgerBucketManager) DeleteNotification(ctx context.Context, tenantID,
 
 // SetNotification stores the bucket notification configuration.
 func (bm *badgerBucketManager) SetNotification(ctx context.Context, tenantID, name string, config *NotificationConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-	metaBucket.Notification = toMetadataNotification(config)
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Notification = toMetadataNotification(config)
+	})
 }
 
 // SetBucketTags sets the bucket tags
 func (bm *badgerBucketManager) SetBucketTags(ctx context.Context, tenantID, name string, tags map[string]string) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-
-	metaBucket.Tags = tags
-
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.Tags = tags
+	})
 }
 
 // GetObjectLockConfig retrieves the bucket object lock configuration
@@ -844,27 +831,21 @@ func (bm *badgerBucketManager) GetObjectLockConfig(ctx context.Context, tenantID
 // AWS S3 requires versioning to be permanently Enabled on any bucket with Object Lock;
 // enabling Object Lock automatically enables versioning (it can never be suspended after).
 func (bm *badgerBucketManager) SetObjectLockConfig(ctx context.Context, tenantID, name string, config *ObjectLockConfig) error {
-	metaBucket, err := bm.metadataStore.GetBucket(ctx, tenantID, name)
-	if err != nil {
-		if err == metadata.ErrBucketNotFound {
-			return ErrBucketNotFound
-		}
-		return err
-	}
-
-	metaBucket.ObjectLock = toMetadataObjectLock(config)
+	return bm.modifyBucket(ctx, tenantID, name, func(metaBucket *metadata.BucketMetadata) {
+		metaBucket.ObjectLock = toMetadataObjectLock(config)
 
-	// Object Lock requires versioning to always be Enabled.
-	if config != nil && config.ObjectLockEnabled {
-		metaBucket.Versioning = toMetadataVersioning(&VersioningConfig{Status: "Enabled"})
-	}
-
-	return bm.metadataStore.UpdateBucket(ctx, metaBucket)
+		// Object Lock requires versioning to always be Enabled.
+		if config != nil && config.ObjectLockEnabled {
+			metaBucket.Versioning = toMetadataVersioning(&VersioningConfig{Status: "Enabled"})
+		}
+	})
 }
 
 // IncrementObjectCount increments the cached object count for a bucket
 func (bm *badgerBucketManager) IncrementObjectCount(ctx context.Context, tenantID, name string, sizeBytes int64) error {
-	if err := bm.metadataStore.UpdateBucketMetrics(ctx, tenantID, name, 1, sizeBytes); err != nil {
+	err := bm.metadataStore.UpdateBucketMetrics(ctx, tenantID, name, 1, sizeBytes)
+	bm.invalidateBucketMetrics(tenantID, name)
+	if err != nil {
 		return err
 	}
 	if sizeBytes > 0 {
@@ -875,13 +856,17 @@ func (bm *badgerBucketManager) IncrementObjectCount(ctx context.Context, tenantI
 
 // DecrementObjectCount decrements the cached object count for a bucket
 func (bm *badgerBucketManager) DecrementObjectCount(ctx context.Context, tenantID, name string, sizeBytes int64) error {
-	return bm.metadataStore.UpdateBucketMetrics(ctx, tenantID, name, -1, -sizeBytes)
+	err := bm.metadataStore.UpdateBucketMetrics(ctx, tenantID, name, -1, -sizeBytes)
+	bm.invalidateBucketMetrics(tenantID, name)
+	return err
 }
 
 // AdjustBucketSize adjusts TotalSize by sizeDelta without changing ObjectCount.
 // Use for overwrites (same key) and additional versions where count is unchanged.
 func (bm *badgerBucketManager) AdjustBucketSize(ctx context.Context, tenantID, name string, sizeDelta int64) error {
-	if err := bm.metadataStore.UpdateBucketMetrics(ctx, tenantID, name, 0, sizeDelta); err != nil {
+	err := bm.metadataStore.UpdateBucketMetrics(ctx, tenantID, name, 0, sizeDelta)
+	bm.invalidateBucketMetrics(tenantID, name)
+	if err != nil {
 		return err
 	}
 	if sizeDelta > 0 {
@@ -892,7 +877,9 @@ func (bm *badgerBucketManager) AdjustBucketSize(ctx context.Context, tenantID, n
 
 // RecalculateMetrics recalculates the object count and total size for a bucket
 func (bm *badgerBucketManager) RecalculateMetrics(ctx context.Context, tenantID, name string) error {
-	return bm.metadataStore.RecalculateBucketStats(ctx, tenantID, name)
+	err := bm.metadataStore.RecalculateBucketStats(ctx, tenantID, name)
+	bm.invalidateBucketMetrics(tenantID, name)
+	return err
 }
 
 // IsReady checks if the bucket manager is ready
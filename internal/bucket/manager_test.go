@@ -3,6 +3,7 @@ package bucket
 import (
 	"context"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/maxiofs/maxiofs/internal/acl"
@@ -495,6 +496,59 @@ func TestBucketTags(t *testing.T) {
 	})
 }
 
+// TestBucketConfigConcurrentSetters tests that configuration setters racing on
+// the same bucket don't overwrite each other's changes, and that a write made
+// conditional on an outdated revision is refused
+func TestBucketConfigConcurrentSetters(t *testing.T) {
+	manager, cleanup := setupBucketTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, manager.CreateBucket(ctx, "tenant-1", "config-bucket", ""))
+
+	setters := []func() error{
+		func() error { return manager.SetNoOverwrite(ctx, "tenant-1", "config-bucket", true) },
+		func() error { return manager.SetScanUploads(ctx, "tenant-1", "config-bucket", true) },
+		func() error { return manager.SetMaxVersionsPerObject(ctx, "tenant-1", "config-bucket", 3) },
+		func() error { return manager.SetDefaultContentType(ctx, "tenant-1", "config-bucket", "text/plain") },
+		func() error { return manager.SetDefaultWriteLock(ctx, "tenant-1", "config-bucket", 7) },
+		func() error {
+			return manager.SetBucketTags(ctx, "tenant-1", "config-bucket", map[string]string{"team": "backend"})
+		},
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(setters))
+	for i, set := range setters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = set()
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	info, err := manager.GetBucketInfo(ctx, "tenant-1", "config-bucket")
+	require.NoError(t, err)
+	assert.True(t, info.NoOverwrite)
+	assert.True(t, info.ScanUploads)
+	assert.Equal(t, 3, info.MaxVersionsPerObject)
+	assert.Equal(t, "text/plain", info.DefaultContentType)
+	assert.Equal(t, 7, info.DefaultWriteLockDays)
+	assert.Equal(t, "backend", info.Tags["team"])
+
+	stale := metadata.WithExpectedBucketRevision(ctx, info.Revision-1)
+	assert.ErrorIs(t, manager.SetObjectLockConfig(stale, "tenant-1", "config-bucket", &ObjectLockConfig{ObjectLockEnabled: true}), ErrBucketModified)
+	current := metadata.WithExpectedBucketRevision(ctx, info.Revision)
+	require.NoError(t, manager.SetObjectLockConfig(current, "tenant-1", "config-bucket", &ObjectLockConfig{ObjectLockEnabled: true}))
+
+	info, err = manager.GetBucketInfo(ctx, "tenant-1", "config-bucket")
+	require.NoError(t, err)
+	assert.True(t, info.NoOverwrite, "Object Lock update must keep the other settings")
+}
+
 // TestBucketMetrics tests object count and size metrics
 func TestBucketMetrics(t *testing.T) {
 	manager, cleanup := setupBucketTest(t)
@@ -4,17 +4,20 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
 )
 
 // PolicyEvaluationRequest contains the context for policy evaluation
 type PolicyEvaluationRequest struct {
-	Principal      string            // User ARN or canonical user ID
-	Action         string            // S3 action (e.g., "s3:GetObject", "s3:PutObject")
-	Resource       string            // Resource ARN (e.g., "arn:aws:s3:::bucket/*")
-	Bucket         string            // Bucket name
-	SourceIP       string            // Client IP address (for aws:SourceIp conditions)
-	SecureTransport bool             // Whether the request uses TLS (for aws:SecureTransport conditions)
+	Principal       string            // User ARN or canonical user ID
+	Action          string            // S3 action (e.g., "s3:GetObject", "s3:PutObject")
+	Resource        string            // Resource ARN (e.g., "arn:aws:s3:::bucket/*")
+	Bucket          string            // Bucket name
+	SourceIP        string            // Client IP address (for aws:SourceIp conditions)
+	SecureTransport bool              // Whether the request uses TLS (for aws:SecureTransport conditions)
 	RequestContext  map[string]string // Additional condition context keys (e.g., "s3:prefix")
 }
 
@@ -293,6 +296,12 @@ func matchResource(policyResource, requestResource, bucketName string) bool {
 		}
 	}
 
+	// Key-scoped patterns: "arn:aws:s3:::bucket/teamA/*", "arn:aws:s3:::bucket/logs/2024-??/*.gz".
+	// '*' and '?' may appear anywhere in the object part of the ARN.
+	if strings.ContainsAny(normalizedPolicy, "*?") {
+		return wildcardMatch(normalizedPolicy, normalizedRequest)
+	}
+
 	return false
 }
 
@@ -570,12 +579,15 @@ func toStringSlice(v interface{}) []string {
 	switch val := v.(type) {
 	case string:
 		return []string{val}
+	case bool:
+		// JSON policies often write {"Bool": {"aws:SecureTransport": false}}
+		return []string{strconv.FormatBool(val)}
+	case float64:
+		return []string{strconv.FormatFloat(val, 'f', -1, 64)}
 	case []interface{}:
 		out := make([]string, 0, len(val))
 		for _, item := range val {
-			if s, ok := item.(string); ok {
-				out = append(out, s)
-			}
+			out = append(out, toStringSlice(item)...)
 		}
 		return out
 	case []string:
@@ -591,3 +603,83 @@ func IsActionAllowed(ctx context.Context, policy *Policy, request PolicyEvaluati
 	return decision == DecisionAllow
 }
 
+// IsPolicyPublic reports whether the policy grants access to everyone: it
+// has an Allow statement whose Principal is "*" (or {"AWS": "*"}) that is not
+// narrowed to fixed source addresses by an IpAddress condition on
+// aws:SourceIp. Such a policy is what BlockPublicPolicy rejects and what
+// RestrictPublicBuckets limits to the bucket's own tenant.
+func IsPolicyPublic(policy *Policy) bool {
+	if policy == nil {
+		return false
+	}
+	for _, statement := range policy.Statement {
+		if !strings.EqualFold(statement.Effect, "Allow") || !isWildcardPrincipal(statement.Principal) {
+			continue
+		}
+		if !restrictsSourceIP(statement.Condition) {
+			return true
+		}
+	}
+	return false
+}
+
+// RestrictsPublicPolicy reports whether an Allow from policy must be discarded
+// because pab has RestrictPublicBuckets set, the policy is public and the
+// caller in ctx is anonymous or belongs to another tenant than tenantID, the
+// bucket's own.
+func RestrictsPublicPolicy(ctx context.Context, pab *PublicAccessBlock, policy *Policy, tenantID string) bool {
+	if pab == nil || !pab.RestrictPublicBuckets || !IsPolicyPublic(policy) {
+		return false
+	}
+	user, ok := auth.GetUserFromContext(ctx)
+	return !ok || user == nil || user.TenantID != tenantID
+}
+
+// isWildcardPrincipal reports whether a statement Principal matches anyone
+func isWildcardPrincipal(principal interface{}) bool {
+	switch p := principal.(type) {
+	case string:
+		return p == "*"
+	case map[string]interface{}:
+		for _, key := range []string{"AWS", "CanonicalUser"} {
+			for _, value := range toStringSlice(p[key]) {
+				if value == "*" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// restrictsSourceIP reports whether a condition block limits aws:SourceIp to
+// address ranges narrower than the whole internet
+func restrictsSourceIP(condition map[string]interface{}) bool {
+	for operator, value := range condition {
+		if !strings.EqualFold(operator, "IpAddress") {
+			continue
+		}
+		kvMap, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, cidrs := range kvMap {
+			if !strings.EqualFold(key, "aws:SourceIp") {
+				continue
+			}
+			ranges := toStringSlice(cidrs)
+			open := len(ranges) == 0
+			for _, cidr := range ranges {
+				if _, network, err := net.ParseCIDR(cidr); err == nil {
+					if ones, _ := network.Mask.Size(); ones == 0 {
+						open = true
+					}
+				}
+			}
+			if !open {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -274,6 +274,36 @@ func TestEvaluatePolicy_WildcardResources(t *testing.T) {
 			requestResource: "arn:aws:s3:::my-bucket/shared/file.txt",
 			shouldMatch:     true,
 		},
+		{
+			name:            "Key prefix does not match sibling prefix",
+			policyResource:  "arn:aws:s3:::my-bucket/teamA/*",
+			requestResource: "arn:aws:s3:::my-bucket/teamAB/file.txt",
+			shouldMatch:     false,
+		},
+		{
+			name:            "Wildcard in the middle of the key",
+			policyResource:  "arn:aws:s3:::my-bucket/logs/*/app.log",
+			requestResource: "arn:aws:s3:::my-bucket/logs/2024-01-01/app.log",
+			shouldMatch:     true,
+		},
+		{
+			name:            "Wildcard in the middle of the key does not match other suffix",
+			policyResource:  "arn:aws:s3:::my-bucket/logs/*/app.log",
+			requestResource: "arn:aws:s3:::my-bucket/logs/2024-01-01/db.log",
+			shouldMatch:     false,
+		},
+		{
+			name:            "Question mark matches a single character",
+			policyResource:  "arn:aws:s3:::my-bucket/reports/2024-0?.csv",
+			requestResource: "arn:aws:s3:::my-bucket/reports/2024-03.csv",
+			shouldMatch:     true,
+		},
+		{
+			name:            "Question mark does not match two characters",
+			policyResource:  "arn:aws:s3:::my-bucket/reports/2024-0?.csv",
+			requestResource: "arn:aws:s3:::my-bucket/reports/2024-012.csv",
+			shouldMatch:     false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -716,3 +746,118 @@ func TestNormalizeResourceARN(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluatePolicy_Conditions(t *testing.T) {
+	ctx := context.Background()
+
+	statement := func(effect string, condition map[string]interface{}) *Policy {
+		return &Policy{
+			Version: "2012-10-17",
+			Statement: []Statement{
+				{
+					Effect:    effect,
+					Principal: "*",
+					Action:    "s3:*",
+					Resource:  "arn:aws:s3:::my-bucket*",
+					Condition: condition,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		policy   *Policy
+		request  PolicyEvaluationRequest
+		expected PolicyDecision
+	}{
+		{
+			name:     "IpAddress allows client inside CIDR",
+			policy:   statement("Allow", map[string]interface{}{"IpAddress": map[string]interface{}{"aws:SourceIp": "10.0.0.0/8"}}),
+			request:  PolicyEvaluationRequest{SourceIP: "10.20.30.40"},
+			expected: DecisionAllow,
+		},
+		{
+			name:     "IpAddress does not match client outside CIDR",
+			policy:   statement("Allow", map[string]interface{}{"IpAddress": map[string]interface{}{"aws:SourceIp": "10.0.0.0/8"}}),
+			request:  PolicyEvaluationRequest{SourceIP: "203.0.113.7"},
+			expected: DecisionDeny,
+		},
+		{
+			name: "IpAddress with a list of ranges",
+			policy: statement("Allow", map[string]interface{}{"IpAddress": map[string]interface{}{
+				"aws:SourceIp": []interface{}{"192.168.0.0/16", "203.0.113.7"},
+			}}),
+			request:  PolicyEvaluationRequest{SourceIP: "203.0.113.7"},
+			expected: DecisionAllow,
+		},
+		{
+			name:     "NotIpAddress deny hits outside client",
+			policy:   statement("Deny", map[string]interface{}{"NotIpAddress": map[string]interface{}{"aws:SourceIp": "10.0.0.0/8"}}),
+			request:  PolicyEvaluationRequest{SourceIP: "203.0.113.7"},
+			expected: DecisionExplicitDeny,
+		},
+		{
+			name:     "SecureTransport deny hits plain HTTP (string value)",
+			policy:   statement("Deny", map[string]interface{}{"Bool": map[string]interface{}{"aws:SecureTransport": "false"}}),
+			request:  PolicyEvaluationRequest{SecureTransport: false},
+			expected: DecisionExplicitDeny,
+		},
+		{
+			name:     "SecureTransport deny hits plain HTTP (JSON bool value)",
+			policy:   statement("Deny", map[string]interface{}{"Bool": map[string]interface{}{"aws:SecureTransport": false}}),
+			request:  PolicyEvaluationRequest{SecureTransport: false},
+			expected: DecisionExplicitDeny,
+		},
+		{
+			name:     "SecureTransport deny skips HTTPS",
+			policy:   statement("Deny", map[string]interface{}{"Bool": map[string]interface{}{"aws:SecureTransport": false}}),
+			request:  PolicyEvaluationRequest{SecureTransport: true},
+			expected: DecisionDeny,
+		},
+		{
+			name:     "s3:prefix StringLike matches listing prefix",
+			policy:   statement("Allow", map[string]interface{}{"StringLike": map[string]interface{}{"s3:prefix": "home/alice/*"}}),
+			request:  PolicyEvaluationRequest{RequestContext: map[string]string{"s3:prefix": "home/alice/docs/"}},
+			expected: DecisionAllow,
+		},
+		{
+			name:     "s3:prefix StringLike rejects other prefix",
+			policy:   statement("Allow", map[string]interface{}{"StringLike": map[string]interface{}{"s3:prefix": "home/alice/*"}}),
+			request:  PolicyEvaluationRequest{RequestContext: map[string]string{"s3:prefix": "home/bob/"}},
+			expected: DecisionDeny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.Principal = "user-1"
+			tt.request.Action = "s3:ListBucket"
+			tt.request.Resource = "arn:aws:s3:::my-bucket"
+			tt.request.Bucket = "my-bucket"
+			assert.Equal(t, tt.expected, EvaluatePolicy(ctx, tt.policy, tt.request))
+		})
+	}
+}
+
+func TestIsPolicyPublic(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    string
+		public bool
+	}{
+		{"wildcard principal", `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`, true},
+		{"AWS wildcard", `{"Statement":[{"Effect":"Allow","Principal":{"AWS":["user-1","*"]},"Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`, true},
+		{"TLS condition only", `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*","Condition":{"Bool":{"aws:SecureTransport":"true"}}}]}`, true},
+		{"open IP range", `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*","Condition":{"IpAddress":{"aws:SourceIp":"0.0.0.0/0"}}}]}`, true},
+		{"fixed IP range", `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*","Condition":{"IpAddress":{"aws:SourceIp":["10.0.0.0/8"]}}}]}`, false},
+		{"named principal", `{"Statement":[{"Effect":"Allow","Principal":{"AWS":"user-1"},"Action":"s3:GetObject","Resource":"arn:aws:s3:::b/*"}]}`, false},
+		{"wildcard deny", `{"Statement":[{"Effect":"Deny","Principal":"*","Action":"s3:DeleteObject","Resource":"arn:aws:s3:::b/*"}]}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.public, IsPolicyPublic(parsePolicy(t, tt.doc)))
+		})
+	}
+	assert.False(t, IsPolicyPublic(nil))
+}
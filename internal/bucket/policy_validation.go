@@ -0,0 +1,338 @@
+package bucket
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Policy language versions accepted in a bucket policy
+var validPolicyVersions = map[string]bool{
+	"2012-10-17": true,
+	"2008-10-17": true,
+}
+
+// knownS3Actions lists the S3 actions a statement may name. It covers the AWS
+// S3 action set so that policies written for AWS are accepted, even for
+// actions MaxIOFS does not evaluate yet.
+var knownS3Actions = []string{
+	"s3:AbortMultipartUpload",
+	"s3:BypassGovernanceRetention",
+	"s3:CreateBucket",
+	"s3:DeleteBucket",
+	"s3:DeleteBucketPolicy",
+	"s3:DeleteBucketWebsite",
+	"s3:DeleteObject",
+	"s3:DeleteObjectTagging",
+	"s3:DeleteObjectVersion",
+	"s3:DeleteObjectVersionTagging",
+	"s3:GetAccelerateConfiguration",
+	"s3:GetAnalyticsConfiguration",
+	"s3:GetBucketAcl",
+	"s3:GetBucketCORS",
+	"s3:GetBucketLocation",
+	"s3:GetBucketLogging",
+	"s3:GetBucketNotification",
+	"s3:GetBucketObjectLockConfiguration",
+	"s3:GetBucketOwnershipControls",
+	"s3:GetBucketPolicy",
+	"s3:GetBucketPolicyStatus",
+	"s3:GetBucketPublicAccessBlock",
+	"s3:GetBucketRequestPayment",
+	"s3:GetBucketTagging",
+	"s3:GetBucketVersioning",
+	"s3:GetBucketWebsite",
+	"s3:GetEncryptionConfiguration",
+	"s3:GetInventoryConfiguration",
+	"s3:GetLifecycleConfiguration",
+	"s3:GetMetricsConfiguration",
+	"s3:GetObject",
+	"s3:GetObjectAcl",
+	"s3:GetObjectAttributes",
+	"s3:GetObjectLegalHold",
+	"s3:GetObjectRetention",
+	"s3:GetObjectTagging",
+	"s3:GetObjectTorrent",
+	"s3:GetObjectVersion",
+	"s3:GetObjectVersionAcl",
+	"s3:GetObjectVersionAttributes",
+	"s3:GetObjectVersionTagging",
+	"s3:GetReplicationConfiguration",
+	"s3:ListAllMyBuckets",
+	"s3:ListBucket",
+	"s3:ListBucketMultipartUploads",
+	"s3:ListBucketVersions",
+	"s3:ListMultipartUploadParts",
+	"s3:ObjectOwnerOverrideToBucketOwner",
+	"s3:PutAccelerateConfiguration",
+	"s3:PutAnalyticsConfiguration",
+	"s3:PutBucketAcl",
+	"s3:PutBucketCORS",
+	"s3:PutBucketLogging",
+	"s3:PutBucketNotification",
+	"s3:PutBucketObjectLockConfiguration",
+	"s3:PutBucketOwnershipControls",
+	"s3:PutBucketPolicy",
+	"s3:PutBucketPublicAccessBlock",
+	"s3:PutBucketRequestPayment",
+	"s3:PutBucketTagging",
+	"s3:PutBucketVersioning",
+	"s3:PutBucketWebsite",
+	"s3:PutEncryptionConfiguration",
+	"s3:PutInventoryConfiguration",
+	"s3:PutLifecycleConfiguration",
+	"s3:PutMetricsConfiguration",
+	"s3:PutObject",
+	"s3:PutObjectAcl",
+	"s3:PutObjectLegalHold",
+	"s3:PutObjectRetention",
+	"s3:PutObjectTagging",
+	"s3:PutObjectVersionAcl",
+	"s3:PutObjectVersionTagging",
+	"s3:PutReplicationConfiguration",
+	"s3:ReplicateDelete",
+	"s3:ReplicateObject",
+	"s3:ReplicateTags",
+	"s3:RestoreObject",
+}
+
+// supportedConditionOperators are the operators evaluateOperator implements,
+// lowercased.
+var supportedConditionOperators = map[string]bool{
+	"stringequals":              true,
+	"stringnotequals":           true,
+	"stringequalsignorecase":    true,
+	"stringnotequalsignorecase": true,
+	"stringlike":                true,
+	"stringnotlike":             true,
+	"bool":                      true,
+	"ipaddress":                 true,
+	"notipaddress":              true,
+	"arnequals":                 true,
+	"arnlike":                   true,
+	"arnnotequals":              true,
+	"arnnotlike":                true,
+	"numericequals":             true,
+	"numericnotequals":          true,
+}
+
+// supportedConditionKeys are the condition keys the request context provides
+// (see resolveConditionKey), lowercased.
+var supportedConditionKeys = map[string]bool{
+	"aws:sourceip":        true,
+	"aws:securetransport": true,
+	"s3:prefix":           true,
+	"s3:delimiter":        true,
+	"s3:max-keys":         true,
+}
+
+// ValidatePolicy checks a bucket policy document before it is stored, so that
+// a statement which could never be enforced as written is rejected instead of
+// being silently ignored. Every Resource must be an S3 ARN of bucketName. The
+// error message names the offending statement and element.
+func ValidatePolicy(policy *Policy, bucketName string) error {
+	if policy.Version == "" {
+		return fmt.Errorf("Policy must contain a Version field")
+	}
+	if !validPolicyVersions[policy.Version] {
+		return fmt.Errorf("Version %q is not supported, use \"2012-10-17\"", policy.Version)
+	}
+	if len(policy.Statement) == 0 {
+		return fmt.Errorf("Policy must contain at least one Statement")
+	}
+
+	for i, stmt := range policy.Statement {
+		where := fmt.Sprintf("Statement[%d]", i)
+		if stmt.Sid != "" {
+			where = fmt.Sprintf("Statement[%d] (Sid %q)", i, stmt.Sid)
+		}
+
+		if stmt.Effect != "Allow" && stmt.Effect != "Deny" {
+			return fmt.Errorf("%s: Effect must be \"Allow\" or \"Deny\", got %q", where, stmt.Effect)
+		}
+		if err := validatePolicyPrincipal(stmt.Principal); err != nil {
+			return fmt.Errorf("%s: Principal %v", where, err)
+		}
+
+		actions, err := policyStringList(stmt.Action)
+		if err != nil {
+			return fmt.Errorf("%s: Action %v", where, err)
+		}
+		for _, action := range actions {
+			if err := validatePolicyAction(action); err != nil {
+				return fmt.Errorf("%s: Action %v", where, err)
+			}
+		}
+
+		resources, err := policyStringList(stmt.Resource)
+		if err != nil {
+			return fmt.Errorf("%s: Resource %v", where, err)
+		}
+		for _, resource := range resources {
+			if err := validatePolicyResource(resource, bucketName); err != nil {
+				return fmt.Errorf("%s: Resource %v", where, err)
+			}
+		}
+
+		if err := validatePolicyCondition(stmt.Condition); err != nil {
+			return fmt.Errorf("%s: Condition %v", where, err)
+		}
+	}
+	return nil
+}
+
+// policyStringList returns the values of an element that may be a string or a
+// list of strings, requiring at least one non-empty value.
+func policyStringList(v interface{}) ([]string, error) {
+	var values []string
+	switch val := v.(type) {
+	case nil:
+		return nil, fmt.Errorf("is required")
+	case string:
+		values = []string{val}
+	case []string:
+		values = val
+	case []interface{}:
+		for _, item := range val {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must contain only strings, got %v", item)
+			}
+			values = append(values, s)
+		}
+	default:
+		return nil, fmt.Errorf("must be a string or a list of strings")
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("must not be empty")
+	}
+	for _, s := range values {
+		if s == "" {
+			return nil, fmt.Errorf("must not contain empty values")
+		}
+	}
+	return values, nil
+}
+
+// validatePolicyPrincipal accepts "*" or an object with AWS and/or
+// CanonicalUser entries, the forms principalMatches understands.
+func validatePolicyPrincipal(principal interface{}) error {
+	switch p := principal.(type) {
+	case nil:
+		return fmt.Errorf("is required")
+	case string:
+		if p != "*" {
+			return fmt.Errorf("must be \"*\" or an object like {\"AWS\": [...]}, got %q", p)
+		}
+		return nil
+	case map[string]interface{}:
+		if len(p) == 0 {
+			return fmt.Errorf("must name at least one principal")
+		}
+		for key, value := range p {
+			if key != "AWS" && key != "CanonicalUser" {
+				return fmt.Errorf("type %q is not supported, use \"AWS\" or \"CanonicalUser\"", key)
+			}
+			if _, err := policyStringList(value); err != nil {
+				return fmt.Errorf("%s %v", key, err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("must be \"*\" or an object like {\"AWS\": [...]}")
+	}
+}
+
+// validatePolicyAction accepts "*", "s3:*", a known S3 action, or a known
+// action prefix followed by a trailing "*" (e.g. "s3:Get*").
+func validatePolicyAction(action string) error {
+	if action == "*" || action == "s3:*" {
+		return nil
+	}
+	if !strings.HasPrefix(action, "s3:") {
+		return fmt.Errorf("%q is not an S3 action (expected \"s3:<Action>\")", action)
+	}
+
+	if prefix, ok := strings.CutSuffix(action, "*"); ok {
+		if strings.ContainsAny(prefix, "*?") {
+			return fmt.Errorf("%q: only a single trailing wildcard is supported", action)
+		}
+		for _, known := range knownS3Actions {
+			if strings.HasPrefix(known, prefix) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q does not match any S3 action", action)
+	}
+	if strings.ContainsAny(action, "*?") {
+		return fmt.Errorf("%q: only a single trailing wildcard is supported", action)
+	}
+
+	for _, known := range knownS3Actions {
+		if known == action {
+			return nil
+		}
+		if strings.EqualFold(known, action) {
+			return fmt.Errorf("%q is not a recognized S3 action (did you mean %q?)", action, known)
+		}
+	}
+	return fmt.Errorf("%q is not a recognized S3 action", action)
+}
+
+// validatePolicyResource accepts "*" and ARNs of the bucket itself
+// (arn:aws:s3:::bucket) or of keys in it (arn:aws:s3:::bucket/key-pattern).
+// A wildcard bucket part is allowed as long as it matches bucketName.
+func validatePolicyResource(resource, bucketName string) error {
+	if resource == "*" {
+		return nil
+	}
+	rest, ok := strings.CutPrefix(resource, "arn:aws:s3:::")
+	if !ok {
+		return fmt.Errorf("%q is not an S3 ARN (expected \"arn:aws:s3:::%s\" or \"arn:aws:s3:::%s/*\")", resource, bucketName, bucketName)
+	}
+
+	bucketPart, _, _ := strings.Cut(rest, "/")
+	if bucketPart == "" {
+		return fmt.Errorf("%q does not name a bucket", resource)
+	}
+	if bucketPart != bucketName && !(strings.ContainsAny(bucketPart, "*?") && wildcardMatch(bucketPart, bucketName)) {
+		return fmt.Errorf("%q refers to bucket %q, but this policy belongs to bucket %q", resource, bucketPart, bucketName)
+	}
+	return nil
+}
+
+// validatePolicyCondition checks a Condition block: supported operators and
+// keys, and non-empty values that suit the operator.
+func validatePolicyCondition(condition map[string]interface{}) error {
+	for operator, block := range condition {
+		if !supportedConditionOperators[strings.ToLower(operator)] {
+			return fmt.Errorf("operator %q is not supported", operator)
+		}
+		kvMap, ok := block.(map[string]interface{})
+		if !ok || len(kvMap) == 0 {
+			return fmt.Errorf("%s must be an object of condition keys to values", operator)
+		}
+		for key, value := range kvMap {
+			if !supportedConditionKeys[strings.ToLower(key)] {
+				return fmt.Errorf("%s: key %q is not supported (supported: aws:SourceIp, aws:SecureTransport, s3:prefix, s3:delimiter, s3:max-keys)", operator, key)
+			}
+			values := toStringSlice(value)
+			if len(values) == 0 {
+				return fmt.Errorf("%s: %s must have at least one string, boolean or number value", operator, key)
+			}
+			for _, v := range values {
+				switch strings.ToLower(operator) {
+				case "ipaddress", "notipaddress":
+					if _, _, err := net.ParseCIDR(v); err != nil && net.ParseIP(v) == nil {
+						return fmt.Errorf("%s: %s value %q is not an IP address or CIDR", operator, key, v)
+					}
+				case "bool":
+					if !strings.EqualFold(v, "true") && !strings.EqualFold(v, "false") {
+						return fmt.Errorf("%s: %s value %q must be true or false", operator, key, v)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
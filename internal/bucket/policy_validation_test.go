@@ -0,0 +1,97 @@
+package bucket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parsePolicy decodes a policy the way the PutBucketPolicy handlers do, so
+// elements arrive as interface{} values rather than typed slices
+func parsePolicy(t *testing.T, doc string) *Policy {
+	t.Helper()
+	var policy Policy
+	require.NoError(t, json.Unmarshal([]byte(doc), &policy))
+	return &policy
+}
+
+// TestValidatePolicy_Valid tests that well-formed policies are accepted
+func TestValidatePolicy_Valid(t *testing.T) {
+	policies := map[string]string{
+		"public read": `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::photos/*"}]}`,
+		"full": `{
+			"Version": "2012-10-17",
+			"Statement": [
+				{
+					"Sid": "TeamAccess",
+					"Effect": "Allow",
+					"Principal": {"AWS": ["user-1", "user-2"], "CanonicalUser": "abc"},
+					"Action": ["s3:Get*", "s3:PutObject", "s3:ListBucket"],
+					"Resource": ["arn:aws:s3:::photos", "arn:aws:s3:::photos/team-a/*", "arn:aws:s3:::pho*"],
+					"Condition": {
+						"IpAddress": {"aws:SourceIp": ["10.0.0.0/8", "192.168.1.7"]},
+						"Bool": {"aws:SecureTransport": true},
+						"StringLike": {"s3:prefix": ["team-a/*", ""]}
+					}
+				},
+				{"Effect": "Deny", "Principal": "*", "Action": "*", "Resource": "*"},
+				{"Effect": "Deny", "Principal": "*", "Action": "s3:*", "Resource": "arn:aws:s3:::photos/secret/*"}
+			]
+		}`,
+	}
+	for name, doc := range policies {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, ValidatePolicy(parsePolicy(t, doc), "photos"))
+		})
+	}
+}
+
+// TestValidatePolicy_Invalid tests that each kind of malformed element is
+// rejected with a message pointing at it
+func TestValidatePolicy_Invalid(t *testing.T) {
+	statement := func(s string) string {
+		return `{"Version":"2012-10-17","Statement":[{"Sid":"First","Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::photos/*"},` + s + `]}`
+	}
+
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"missing version", `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"*"}]}`, "Policy must contain a Version field"},
+		{"unknown version", `{"Version":"2020-01-01","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"*"}]}`, `Version "2020-01-01" is not supported`},
+		{"no statements", `{"Version":"2012-10-17","Statement":[]}`, "Policy must contain at least one Statement"},
+		{"bad effect", statement(`{"Effect":"allow","Principal":"*","Action":"s3:GetObject","Resource":"*"}`), `Statement[1]: Effect must be "Allow" or "Deny", got "allow"`},
+		{"missing principal", statement(`{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}`), "Statement[1]: Principal is required"},
+		{"bare principal", statement(`{"Effect":"Allow","Principal":"user-1","Action":"s3:GetObject","Resource":"*"}`), `Statement[1]: Principal must be "*" or an object`},
+		{"unsupported principal type", statement(`{"Effect":"Allow","Principal":{"Service":"s3.amazonaws.com"},"Action":"s3:GetObject","Resource":"*"}`), `Statement[1]: Principal type "Service" is not supported`},
+		{"empty principal list", statement(`{"Effect":"Allow","Principal":{"AWS":[]},"Action":"s3:GetObject","Resource":"*"}`), "Statement[1]: Principal AWS must not be empty"},
+		{"missing action", statement(`{"Effect":"Allow","Principal":"*","Resource":"*"}`), "Statement[1]: Action is required"},
+		{"misspelled action", statement(`{"Effect":"Allow","Principal":"*","Action":["s3:GetObject","s3:GetObjet"],"Resource":"*"}`), `Statement[1]: Action "s3:GetObjet" is not a recognized S3 action`},
+		{"wrong case action", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:getobject","Resource":"*"}`), `did you mean "s3:GetObject"?`},
+		{"non-S3 action", statement(`{"Effect":"Allow","Principal":"*","Action":"iam:PassRole","Resource":"*"}`), `Action "iam:PassRole" is not an S3 action`},
+		{"unmatched wildcard action", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:Fetch*","Resource":"*"}`), `Action "s3:Fetch*" does not match any S3 action`},
+		{"inner wildcard action", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:*Object","Resource":"*"}`), "only a single trailing wildcard is supported"},
+		{"non-string action", statement(`{"Effect":"Allow","Principal":"*","Action":["s3:GetObject",7],"Resource":"*"}`), "Statement[1]: Action must contain only strings"},
+		{"missing resource", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject"}`), "Statement[1]: Resource is required"},
+		{"non-ARN resource", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"photos/*"}`), `Statement[1]: Resource "photos/*" is not an S3 ARN`},
+		{"other bucket", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::invoices/*"}`), `Resource "arn:aws:s3:::invoices/*" refers to bucket "invoices", but this policy belongs to bucket "photos"`},
+		{"wildcard of other buckets", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::inv*/*"}`), `refers to bucket "inv*"`},
+		{"ARN without bucket", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::"}`), "does not name a bucket"},
+		{"unsupported operator", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"*","Condition":{"DateGreaterThan":{"aws:CurrentTime":"2020-01-01T00:00:00Z"}}}`), `Statement[1]: Condition operator "DateGreaterThan" is not supported`},
+		{"unsupported key", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"*","Condition":{"StringEquals":{"aws:UserAgent":"curl"}}}`), `Condition StringEquals: key "aws:UserAgent" is not supported`},
+		{"bad CIDR", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"*","Condition":{"IpAddress":{"aws:SourceIp":"10.0.0.0/33"}}}`), `value "10.0.0.0/33" is not an IP address or CIDR`},
+		{"bad bool", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"*","Condition":{"Bool":{"aws:SecureTransport":"yes"}}}`), `value "yes" must be true or false`},
+		{"condition not an object", statement(`{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"*","Condition":{"StringEquals":"s3:prefix"}}`), "StringEquals must be an object"},
+		{"sid in message", `{"Version":"2012-10-17","Statement":[{"Sid":"Broken","Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::other"}]}`, `Statement[0] (Sid "Broken"): Resource`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePolicy(parsePolicy(t, tt.doc), "photos")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
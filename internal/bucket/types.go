@@ -3,6 +3,8 @@ package bucket
 import (
 	"errors"
 	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
 )
 
 // Common bucket errors
@@ -19,6 +21,11 @@ var (
 	ErrPublicAccessBlockNotFound  = errors.New("public access block configuration not found")
 	ErrOwnershipControlsNotFound  = errors.New("ownership controls not found")
 	ErrLoggingNotFound            = errors.New("logging configuration not found")
+
+	// ErrBucketModified is returned by a conditional update (see
+	// metadata.WithExpectedBucketRevision) when the bucket changed since the
+	// revision the caller read.
+	ErrBucketModified = metadata.ErrBucketModified
 )
 
 // WebsiteConfig represents static website hosting configuration for a bucket.
@@ -70,6 +77,10 @@ type Statement struct {
 // VersioningConfig represents bucket versioning configuration
 type VersioningConfig struct {
 	Status string `json:"Status"` // Enabled, Suspended
+
+	// MFADelete requires a valid x-amz-mfa code to permanently delete a
+	// version or to change the versioning state
+	MFADelete bool `json:"MFADelete,omitempty"`
 }
 
 // LifecycleConfig represents bucket lifecycle configuration
@@ -88,10 +99,18 @@ type LifecycleRule struct {
 	AbortIncompleteMultipartUpload *LifecycleAbortIncompleteMultipartUpload `json:"AbortIncompleteMultipartUpload,omitempty"`
 }
 
-// LifecycleFilter represents lifecycle rule filter
+// LifecycleFilter represents lifecycle rule filter. At most one of Prefix,
+// Tag or And is set; And combines a prefix with one or more tags.
 type LifecycleFilter struct {
+	Prefix string              `json:"Prefix,omitempty"`
+	Tag    *Tag                `json:"Tag,omitempty"`
+	And    *LifecycleFilterAnd `json:"And,omitempty"`
+}
+
+// LifecycleFilterAnd matches objects that have the prefix and all of the tags
+type LifecycleFilterAnd struct {
 	Prefix string `json:"Prefix,omitempty"`
-	Tag    *Tag   `json:"Tag,omitempty"`
+	Tags   []Tag  `json:"Tags,omitempty"`
 }
 
 // LifecycleExpiration represents object expiration settings
@@ -110,7 +129,8 @@ type LifecycleTransition struct {
 
 // NoncurrentVersionExpiration represents noncurrent version expiration settings
 type NoncurrentVersionExpiration struct {
-	NoncurrentDays int `json:"NoncurrentDays"` // Delete noncurrent versions after this many days
+	NoncurrentDays          int `json:"NoncurrentDays"`                    // Delete noncurrent versions after this many days
+	NewerNoncurrentVersions int `json:"NewerNoncurrentVersions,omitempty"` // Keep only this many newest noncurrent versions
 }
 
 // LifecycleAbortIncompleteMultipartUpload represents incomplete multipart upload abort settings
@@ -24,6 +24,9 @@ type AccessKeyData struct {
 	Status          string `json:"status"`
 	CreatedAt       int64  `json:"created_at"`
 	LastUsed        *int64 `json:"last_used,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Description     string `json:"description,omitempty"`
+	Tags            string `json:"tags,omitempty"` // JSON object, as stored in access_keys.tags
 }
 
 // AccessKeySyncManager handles automatic access key synchronization between cluster nodes
@@ -245,7 +248,7 @@ func (m *AccessKeySyncManager) sendAccessKeyToNode(ctx context.Context, accessKe
 // listLocalAccessKeys retrieves all access keys from the local database
 func (m *AccessKeySyncManager) listLocalAccessKeys(ctx context.Context) ([]*AccessKeyData, error) {
 	query := `
-		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used
+		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used, name, description, tags
 		FROM access_keys
 		WHERE status = 'active'
 	`
@@ -267,6 +270,9 @@ func (m *AccessKeySyncManager) listLocalAccessKeys(ctx context.Context) ([]*Acce
 			&accessKey.Status,
 			&accessKey.CreatedAt,
 			&lastUsed,
+			&accessKey.Name,
+			&accessKey.Description,
+			&accessKey.Tags,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan access key: %w", err)
@@ -283,12 +289,15 @@ func (m *AccessKeySyncManager) listLocalAccessKeys(ctx context.Context) ([]*Acce
 // computeAccessKeyChecksum computes a checksum for access key data to detect changes
 func (m *AccessKeySyncManager) computeAccessKeyChecksum(accessKey *AccessKeyData) string {
 	// Create a string representation of relevant access key fields
-	data := fmt.Sprintf("%s|%s|%s|%s|%d",
+	data := fmt.Sprintf("%s|%s|%s|%s|%d|%s|%s|%s",
 		accessKey.AccessKeyID,
 		accessKey.SecretAccessKey,
 		accessKey.UserID,
 		accessKey.Status,
 		accessKey.CreatedAt,
+		accessKey.Name,
+		accessKey.Description,
+		accessKey.Tags,
 	)
 
 	hash := sha256.Sum256([]byte(data))
@@ -40,6 +40,9 @@ func TestAccessKeySyncManager_ListLocalAccessKeys(t *testing.T) {
 			status TEXT DEFAULT 'active',
 			created_at INTEGER NOT NULL,
 			last_used INTEGER,
+			name TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT '',
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)
 	`)
@@ -639,7 +642,10 @@ func TestAccessKeySyncManager_SyncLoop(t *testing.T) {
 			user_id TEXT,
 			status TEXT DEFAULT 'active',
 			created_at INTEGER,
-			last_used INTEGER
+			last_used INTEGER,
+			name TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT ''
 		)
 	`)
 	if err != nil {
@@ -654,7 +660,7 @@ func TestAccessKeySyncManager_SyncLoop(t *testing.T) {
 	}
 
 	_, err = db.ExecContext(ctx, `
-		INSERT INTO access_keys VALUES ('AKIA1234567890ABCDEF', 'secret', 'user-1', 'active', ?, NULL)
+		INSERT INTO access_keys VALUES ('AKIA1234567890ABCDEF', 'secret', 'user-1', 'active', ?, NULL, '', '', '')
 	`, now)
 	if err != nil {
 		t.Fatalf("Failed to insert access key: %v", err)
@@ -212,6 +212,30 @@ func (m *MockBucketManagerForLocation) SetQuota(ctx context.Context, tenantID, n
 func (m *MockBucketManagerForLocation) DeleteQuota(ctx context.Context, tenantID, name string) error {
 	return nil
 }
+
+func (m *MockBucketManagerForLocation) SetDefaultWriteLock(ctx context.Context, tenantID, name string, days int) error {
+	return nil
+}
+
+func (m *MockBucketManagerForLocation) SetMaxVersionsPerObject(ctx context.Context, tenantID, name string, max int) error {
+	return nil
+}
+
+func (m *MockBucketManagerForLocation) SetCacheDefaults(ctx context.Context, tenantID, name string, defaults *metadata.BucketCacheDefaults) error {
+	return nil
+}
+
+func (m *MockBucketManagerForLocation) SetDefaultContentType(ctx context.Context, tenantID, name, contentType string) error {
+	return nil
+}
+
+func (m *MockBucketManagerForLocation) SetScanUploads(ctx context.Context, tenantID, name string, enabled bool) error {
+	return nil
+}
+
+func (m *MockBucketManagerForLocation) SetNoOverwrite(ctx context.Context, tenantID, name string, enabled bool) error {
+	return nil
+}
 func (m *MockBucketManagerForLocation) IsReady() bool {
 	return true
 }
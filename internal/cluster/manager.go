@@ -35,6 +35,7 @@ type Manager struct {
 	stopChan            chan struct{}
 	log                 *logrus.Entry
 	storage             storage.Backend
+	keyPaths            *storage.KeyPaths // nil: object keys are storage paths
 	aclManager          acl.Manager
 	bucketManager       bucketManagerForMigration
 	tlsConfig           *tls.Config
@@ -123,6 +124,12 @@ func (m *Manager) SetStorage(s storage.Backend) {
 	m.storage = s
 }
 
+// SetKeyPaths sets how object keys map to storage paths, as configured for
+// the object manager
+func (m *Manager) SetKeyPaths(p *storage.KeyPaths) {
+	m.keyPaths = p
+}
+
 // SetACLManager sets the ACL manager for the cluster manager
 func (m *Manager) SetACLManager(aclMgr acl.Manager) {
 	m.aclManager = aclMgr
@@ -241,17 +248,20 @@ func NodesToJoinPackage(nodes []*Node) []*JoinPackageNode {
 // Node B uses the CA key to generate its OWN cert+key (with its own IP in the SANs),
 // so every node has a cert valid for its own address, all signed by the same CA.
 type ClusterJoinPackage struct {
-	NodeID       string             `json:"node_id"`
-	NodeName     string             `json:"node_name"`
-	ClusterToken string             `json:"cluster_token"`
-	Region       string             `json:"region"`
-	CACertPEM    string             `json:"ca_cert"`
-	CAKeyPEM     string             `json:"ca_key"` // sent once so Node B can sign its own cert
-	JWTSecret    string             `json:"jwt_secret"`
-	SelfEndpoint string             `json:"self_endpoint"` // Node B's 8082 URL — used for cert SANs
-	NodeEndpoint string             `json:"node_endpoint"` // Node A's 8082 URL
-	APIURL       string             `json:"api_url"`       // Node B's S3 API public URL
-	Nodes        []*JoinPackageNode `json:"nodes"`
+	NodeID       string `json:"node_id"`
+	NodeName     string `json:"node_name"`
+	ClusterToken string `json:"cluster_token"`
+	Region       string `json:"region"`
+	CACertPEM    string `json:"ca_cert"`
+	CAKeyPEM     string `json:"ca_key"` // sent once so Node B can sign its own cert
+	JWTSecret    string `json:"jwt_secret"`
+	// SecretEncryptionKey is the master key access key secrets are stored
+	// with; access keys are replicated in encrypted form.
+	SecretEncryptionKey string             `json:"secret_encryption_key,omitempty"`
+	SelfEndpoint        string             `json:"self_endpoint"` // Node B's 8082 URL — used for cert SANs
+	NodeEndpoint        string             `json:"node_endpoint"` // Node A's 8082 URL
+	APIURL              string             `json:"api_url"`       // Node B's S3 API public URL
+	Nodes               []*JoinPackageNode `json:"nodes"`
 	// EncryptionKeys carries the cluster-shared KEK versions so every node
 	// wraps new objects with the same key — the basis for ciphertext HA
 	// replication (replicas store encrypted bytes as-is, no decrypt/re-encrypt).
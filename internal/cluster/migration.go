@@ -652,10 +652,10 @@ func (m *Manager) copyObject(ctx context.Context, proxyClient *ProxyClient, targ
 	var objectPath string
 	if versionID != "" && versionID != "null" {
 		// Versioned object
-		objectPath = fmt.Sprintf("%s/.versions/%s/%s", bucket, key, versionID)
+		objectPath = m.keyPaths.VersionPath(bucket, key, versionID)
 	} else {
 		// Regular object
-		objectPath = fmt.Sprintf("%s/%s", bucket, key)
+		objectPath = m.keyPaths.ObjectPath(bucket, key)
 	}
 
 	// Get object data from storage
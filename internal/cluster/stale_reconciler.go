@@ -559,14 +559,14 @@ func (r *StaleReconciler) pushUser(ctx context.Context, id string, peer *Node, l
 		       COALESCE(metadata, ''), failed_login_attempts, locked_until,
 		       last_failed_login, theme_preference, language_preference,
 		       COALESCE(auth_provider, 'local'), COALESCE(external_id, ''),
-		       created_at, updated_at
+		       created_at, updated_at, password_changed_at
 		FROM users WHERE id = ?
 	`, id).Scan(
 		&u.ID, &u.Username, &u.PasswordHash, &u.DisplayName, &u.Email, &u.Status,
 		&u.TenantID, &u.Roles, &u.Policies, &u.Metadata,
 		&u.FailedLoginAttempts, &u.LockedUntil, &u.LastFailedLogin,
 		&u.ThemePreference, &u.LanguagePreference, &u.AuthProvider, &u.ExternalID,
-		&u.CreatedAt, &u.UpdatedAt,
+		&u.CreatedAt, &u.UpdatedAt, &u.PasswordChangedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil
@@ -581,10 +581,11 @@ func (r *StaleReconciler) pushAccessKey(ctx context.Context, accessKeyID string,
 	var k AccessKeyData
 	var lastUsed sql.NullInt64
 	err := r.db.QueryRowContext(ctx, `
-		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used
+		SELECT access_key_id, secret_access_key, user_id, status, created_at, last_used, name, description, tags
 		FROM access_keys WHERE access_key_id = ?
 	`, accessKeyID).Scan(
 		&k.AccessKeyID, &k.SecretAccessKey, &k.UserID, &k.Status, &k.CreatedAt, &lastUsed,
+		&k.Name, &k.Description, &k.Tags,
 	)
 	if err == sql.ErrNoRows {
 		return nil
@@ -87,7 +87,8 @@ func createEntityTablesForReconciler(ctx context.Context, db *sql.DB) error {
 			auth_provider TEXT DEFAULT 'local',
 			external_id TEXT,
 			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
+			updated_at INTEGER NOT NULL,
+			password_changed_at INTEGER NOT NULL DEFAULT 0
 		)`,
 		// access_keys — no updated_at; created_at used as timestamp proxy in snapshots
 		`CREATE TABLE IF NOT EXISTS access_keys (
@@ -96,7 +97,10 @@ func createEntityTablesForReconciler(ctx context.Context, db *sql.DB) error {
 			user_id TEXT NOT NULL,
 			status TEXT DEFAULT 'active',
 			created_at INTEGER NOT NULL,
-			last_used INTEGER
+			last_used INTEGER,
+			name TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT ''
 		)`,
 		// bucket_permissions — no updated_at; granted_at used as timestamp proxy
 		`CREATE TABLE IF NOT EXISTS bucket_permissions (
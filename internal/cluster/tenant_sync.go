@@ -26,6 +26,8 @@ type TenantData struct {
 	MaxAccessKeys       int               `json:"max_access_keys"`
 	MaxStorageBytes     int64             `json:"max_storage_bytes"`
 	MaxBandwidthBytesPerSec int64         `json:"max_bandwidth_bytes_per_sec"`
+	MaxFailedLoginAttempts int           `json:"max_failed_login_attempts"`
+	LockoutDurationSeconds int64         `json:"lockout_duration_seconds"`
 	CurrentStorageBytes int64             `json:"current_storage_bytes"`
 	MaxBuckets          int               `json:"max_buckets"`
 	CurrentBuckets      int               `json:"current_buckets"`
@@ -253,7 +255,8 @@ func (m *TenantSyncManager) sendTenantToNode(ctx context.Context, tenant *Tenant
 func (m *TenantSyncManager) listLocalTenants(ctx context.Context) ([]*TenantData, error) {
 	query := `
 		SELECT id, name, display_name, description, status, max_access_keys,
-		       max_storage_bytes, max_bandwidth_bytes_per_sec, current_storage_bytes, max_buckets, current_buckets,
+		       max_storage_bytes, max_bandwidth_bytes_per_sec, max_failed_login_attempts, lockout_duration_seconds,
+		       current_storage_bytes, max_buckets, current_buckets,
 		       metadata, created_at, updated_at
 		FROM tenants
 		WHERE status != 'deleted'
@@ -279,6 +282,8 @@ func (m *TenantSyncManager) listLocalTenants(ctx context.Context) ([]*TenantData
 			&tenant.MaxAccessKeys,
 			&tenant.MaxStorageBytes,
 			&tenant.MaxBandwidthBytesPerSec,
+			&tenant.MaxFailedLoginAttempts,
+			&tenant.LockoutDurationSeconds,
 			&tenant.CurrentStorageBytes,
 			&tenant.MaxBuckets,
 			&tenant.CurrentBuckets,
@@ -309,7 +314,7 @@ func (m *TenantSyncManager) listLocalTenants(ctx context.Context) ([]*TenantData
 // computeTenantChecksum computes a SHA256 checksum of tenant data
 func (m *TenantSyncManager) computeTenantChecksum(tenant *TenantData) string {
 	// Create deterministic representation
-	data := fmt.Sprintf("%s|%s|%s|%s|%s|%d|%d|%d|%d|%d|%s|%s",
+	data := fmt.Sprintf("%s|%s|%s|%s|%s|%d|%d|%d|%d|%d|%d|%d|%s|%s",
 		tenant.ID,
 		tenant.Name,
 		tenant.DisplayName,
@@ -318,6 +323,8 @@ func (m *TenantSyncManager) computeTenantChecksum(tenant *TenantData) string {
 		tenant.MaxAccessKeys,
 		tenant.MaxStorageBytes,
 		tenant.MaxBandwidthBytesPerSec,
+		tenant.MaxFailedLoginAttempts,
+		tenant.LockoutDurationSeconds,
 		tenant.MaxBuckets,
 		tenant.CurrentBuckets,
 		tenant.UpdatedAt.Format(time.RFC3339),
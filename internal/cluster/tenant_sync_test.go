@@ -44,6 +44,8 @@ func TestTenantSyncManager_ListLocalTenants(t *testing.T) {
 			max_access_keys INTEGER DEFAULT 5,
 			max_storage_bytes INTEGER DEFAULT 0,
 			max_bandwidth_bytes_per_sec INTEGER DEFAULT 0,
+			max_failed_login_attempts INTEGER DEFAULT 0,
+			lockout_duration_seconds INTEGER DEFAULT 0,
 			current_storage_bytes INTEGER DEFAULT 0,
 			max_buckets INTEGER DEFAULT 10,
 			current_buckets INTEGER DEFAULT 0,
@@ -450,6 +452,8 @@ func TestTenantSyncManager_SyncLoop(t *testing.T) {
 			max_access_keys INTEGER DEFAULT 5,
 			max_storage_bytes INTEGER DEFAULT 0,
 			max_bandwidth_bytes_per_sec INTEGER DEFAULT 0,
+			max_failed_login_attempts INTEGER DEFAULT 0,
+			lockout_duration_seconds INTEGER DEFAULT 0,
 			current_storage_bytes INTEGER DEFAULT 0,
 			max_buckets INTEGER DEFAULT 10,
 			current_buckets INTEGER DEFAULT 0,
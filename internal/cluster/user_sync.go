@@ -37,6 +37,7 @@ type UserData struct {
 	ExternalID          string                  `json:"external_id"`
 	CreatedAt           int64                   `json:"created_at"`
 	UpdatedAt           int64                   `json:"updated_at"`
+	PasswordChangedAt   int64                   `json:"password_changed_at"`
 	// CapabilityOverrides carries per-user capability overrides along with the user record.
 	CapabilityOverrides []CapabilityOverrideData `json:"capability_overrides,omitempty"`
 }
@@ -274,7 +275,7 @@ func (m *UserSyncManager) listLocalUsers(ctx context.Context) ([]*UserData, erro
 		       COALESCE(metadata, ''), failed_login_attempts, locked_until,
 		       last_failed_login, theme_preference, language_preference,
 		       COALESCE(auth_provider, 'local'), COALESCE(external_id, ''),
-		       created_at, updated_at
+		       created_at, updated_at, password_changed_at
 		FROM users
 		WHERE status != 'deleted'
 	`
@@ -308,6 +309,7 @@ func (m *UserSyncManager) listLocalUsers(ctx context.Context) ([]*UserData, erro
 			&user.ExternalID,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.PasswordChangedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
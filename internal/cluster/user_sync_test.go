@@ -54,7 +54,8 @@ func TestUserSyncManager_ListLocalUsers(t *testing.T) {
 			auth_provider TEXT NOT NULL DEFAULT 'local',
 			external_id TEXT,
 			created_at INTEGER NOT NULL,
-			updated_at INTEGER NOT NULL
+			updated_at INTEGER NOT NULL,
+			password_changed_at INTEGER NOT NULL DEFAULT 0
 		)
 	`)
 	require.NoError(t, err)
@@ -410,7 +411,8 @@ func TestUserSyncManager_SyncLoop(t *testing.T) {
 			auth_provider TEXT NOT NULL DEFAULT 'local',
 			external_id TEXT,
 			created_at INTEGER,
-			updated_at INTEGER
+			updated_at INTEGER,
+			password_changed_at INTEGER NOT NULL DEFAULT 0
 		)
 	`)
 	require.NoError(t, err)
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -21,6 +22,7 @@ type Config struct {
 	ClusterAdvertiseAddress string `mapstructure:"cluster_advertise_address"` // external IP for cluster registration (useful in Docker/K8s)
 	DataDir                 string `mapstructure:"data_dir"`
 	LogLevel                string `mapstructure:"log_level"`
+	LogFormat               string `mapstructure:"log_format"` // "json" or "text"; empty defers to the console logging.format setting
 
 	// Public URLs (for redirects, presigned URLs, etc.)
 	PublicAPIURL     string `mapstructure:"public_api_url"`     // e.g., https://s3.example.com or http://localhost:8080
@@ -31,14 +33,49 @@ type Config struct {
 	// Example: "s3-website.example.com" → mybucket.s3-website.example.com serves the bucket.
 	WebsiteHostname string `mapstructure:"website_hostname"`
 
-	// TLS configuration
+	// TLS configuration, used by both the S3 API and the console listener
+	// unless S3TLS or ConsoleTLS override it
 	EnableTLS bool   `mapstructure:"enable_tls"`
 	CertFile  string `mapstructure:"cert_file"`
 	KeyFile   string `mapstructure:"key_file"`
 
+	// Per-listener TLS overrides, e.g. an HTTPS-only S3 endpoint with the
+	// console served over HTTP on localhost behind a reverse proxy
+	S3TLS      ListenerTLSConfig `mapstructure:"s3_tls"`
+	ConsoleTLS ListenerTLSConfig `mapstructure:"console_tls"`
+
 	// Trusted proxies (public IPs only — private networks are trusted automatically)
 	TrustedProxies []string `mapstructure:"trusted_proxies"`
 
+	// HonorRequestIDHeaders makes S3 responses reuse a valid request ID sent
+	// by the client (X-Amz-Request-Id or X-Request-Id) instead of generating
+	// one, so a request keeps one ID across services
+	HonorRequestIDHeaders bool `mapstructure:"honor_request_id_headers"`
+
+	// Console API CORS (which browser origins may call the console API)
+	ConsoleCORS ConsoleCORSConfig `mapstructure:"console_cors"`
+
+	// GlobalBucketNamespace makes bucket names unique across all tenants, so
+	// an S3 request resolves to the owning tenant from the bucket name alone.
+	// When false, each tenant has its own bucket namespace.
+	GlobalBucketNamespace bool `mapstructure:"global_bucket_namespace"`
+
+	// ListTimeoutSeconds is the per-request deadline for S3 object and version
+	// listings. 0 disables it; listings still stop when the client disconnects.
+	ListTimeoutSeconds int `mapstructure:"list_timeout_seconds"`
+
+	// Concurrency caps simultaneous S3 object operations
+	Concurrency ConcurrencyConfig `mapstructure:"concurrency"`
+
+	// Lifecycle tunes the background worker that applies lifecycle rules
+	Lifecycle LifecycleConfig `mapstructure:"lifecycle"`
+
+	// Memory bounds the in-memory caches and rate-limiter state
+	Memory MemoryConfig `mapstructure:"memory"`
+
+	// ErrorPages controls what browsers get for S3 errors
+	ErrorPages ErrorPagesConfig `mapstructure:"error_pages"`
+
 	// Storage configuration
 	Storage StorageConfig `mapstructure:"storage"`
 
@@ -55,13 +92,76 @@ type Config struct {
 	Replication ReplicationYAMLConfig `mapstructure:"replication"`
 }
 
+// ConcurrencyConfig limits how many S3 object operations of each kind are in
+// flight at once. Requests beyond a limit get 503 SlowDown instead of
+// queueing. 0 means unlimited.
+type ConcurrencyConfig struct {
+	MaxPut       int `mapstructure:"max_put"`       // PutObject and CopyObject
+	MaxGet       int `mapstructure:"max_get"`       // GetObject
+	MaxMultipart int `mapstructure:"max_multipart"` // every multipart upload request, parts included
+}
+
+// LifecycleConfig balances the lifecycle worker against live traffic. Workers
+// is how many buckets are processed at once; ScanRate caps the objects per
+// second all workers together read while looking for expired ones (0 means
+// unlimited).
+type LifecycleConfig struct {
+	Workers  int `mapstructure:"workers"`
+	ScanRate int `mapstructure:"scan_rate"`
+}
+
+// MemoryConfig bounds what the server keeps in memory besides in-flight
+// requests. Caches and rate-limiter tables drop their least recently used
+// entries once full; rate-limiter entries unused for RateLimiterIdleSeconds
+// are dropped as well. With SoftLimitMB set, the Go runtime collects garbage
+// harder as the heap nears it, and every cache is halved when the heap
+// goes over it. 0 means unbounded for the entry caps, off for the soft
+// limit, and the defaults for the idle timeout and thumbnail cache size.
+type MemoryConfig struct {
+	SoftLimitMB            int `mapstructure:"soft_limit_mb"`
+	BucketCacheMaxEntries  int `mapstructure:"bucket_cache_max_entries"`
+	RateLimiterMaxEntries  int `mapstructure:"rate_limiter_max_entries"`
+	RateLimiterIdleSeconds int `mapstructure:"rate_limiter_idle_seconds"`
+	ThumbnailCacheMB       int `mapstructure:"thumbnail_cache_mb"`
+}
+
+// ErrorPagesConfig decides how S3 errors are rendered for a browser, i.e. an
+// unsigned request whose Accept header prefers text/html. Mode "xml" (or
+// empty) keeps the S3 XML body for everyone; "html" returns a minimal error
+// page; "redirect" sends 401/403 errors to LoginURL and renders the others as
+// "html". SDK clients always get XML.
+type ErrorPagesConfig struct {
+	Mode     string `mapstructure:"mode"`
+	LoginURL string `mapstructure:"login_url"`
+}
+
 // StorageConfig defines storage backend configuration
 type StorageConfig struct {
-	Backend string `mapstructure:"backend"` // filesystem, s3, gcs, azure
+	Backend string `mapstructure:"backend"` // filesystem, s3
 
 	// Filesystem backend
 	Root string `mapstructure:"root"`
 
+	// S3 backend (gateway mode): object data lives in a bucket of a remote
+	// S3-compatible endpoint, metadata stays in the local metadata store
+	S3 S3BackendConfig `mapstructure:"s3"`
+
+	// ShardDepth spreads object files over this many levels of hash-named
+	// subdirectories (256 per level) inside their directory, so a bucket with
+	// millions of objects never puts them all in one directory. 0 keeps the
+	// flat layout.
+	ShardDepth int `mapstructure:"shard_depth"`
+
+	// ObjectKeyLayout is how object files are named: "path" uses the object
+	// key as the storage path, "opaque" an id derived from it with a secret, so
+	// keys never appear on disk and live only in the metadata store.
+	ObjectKeyLayout string `mapstructure:"object_key_layout"`
+
+	// ReservedFreeMB is the free space in MB the filesystem backend keeps on
+	// the data disk: writes are refused with 503 once less is left. 0 only
+	// refuses writes when the disk is completely full.
+	ReservedFreeMB int64 `mapstructure:"reserved_free_mb"`
+
 	// Encryption
 	EnableEncryption bool   `mapstructure:"enable_encryption"`
 	EncryptionKey    string `mapstructure:"encryption_key"`
@@ -69,8 +169,102 @@ type StorageConfig struct {
 	// Object locking
 	EnableObjectLock bool `mapstructure:"enable_object_lock"`
 
+	// Multipart uploads. MultipartMaxParts caps the parts of one upload (and
+	// so the highest part number, 10000 at most); MultipartMinPartSize is the
+	// smallest size in bytes of every part but the last, checked when the
+	// upload is completed. 0 disables the minimum.
+	MultipartMaxParts    int   `mapstructure:"multipart_max_parts"`
+	MultipartMinPartSize int64 `mapstructure:"multipart_min_part_size"`
+	// Caps on multipart uploads in progress (created, not yet completed or
+	// aborted) per bucket and per tenant; new uploads beyond them get 503
+	// SlowDown. 0 means no cap.
+	MaxMultipartUploadsPerBucket int `mapstructure:"max_multipart_uploads_per_bucket"`
+	MaxMultipartUploadsPerTenant int `mapstructure:"max_multipart_uploads_per_tenant"`
+
 	// Metadata store tuning
 	MetadataCacheSizeMB int `mapstructure:"metadata_cache_size_mb"` // Pebble block cache (default 256 MB)
+	// BucketCacheTTLSeconds keeps bucket metadata read on the object request
+	// path in memory for this long (0 disables the cache). Changes made on
+	// this node drop the entry at once; BucketCacheConsistency "strong" does
+	// so for object count/size updates too, "eventual" lets them lag.
+	BucketCacheTTLSeconds  int    `mapstructure:"bucket_cache_ttl_seconds"`
+	BucketCacheConsistency string `mapstructure:"bucket_cache_consistency"`
+
+	// UploadSpillThreshold is the largest upload body in bytes that PutObject
+	// keeps in memory while hashing it; larger bodies are spooled to a temp
+	// file in UploadTempDir (the storage root when empty). 0 always spools to
+	// disk.
+	UploadSpillThreshold int64  `mapstructure:"upload_spill_threshold"`
+	UploadTempDir        string `mapstructure:"upload_temp_dir"`
+
+	// DisableContentTypeSniffing stores uploads sent without a Content-Type as
+	// application/octet-stream instead of detecting the type from their content
+	// and key extension.
+	DisableContentTypeSniffing bool `mapstructure:"disable_content_type_sniffing"`
+
+	// ColdReads is what a read of a GLACIER or DEEP_ARCHIVE object that has
+	// not been restored does: "allow" reads it like any other object, "deny"
+	// answers 403 InvalidObjectState as S3 does, "wait" restores the object
+	// and serves it once it is thawed.
+	ColdReads string `mapstructure:"cold_reads"`
+
+	// GzipTranscoding decodes objects stored with Content-Encoding gzip on
+	// GET for clients whose Accept-Encoding doesn't allow gzip, as Cloud
+	// Storage's decompressive transcoding does. Ranges then address the
+	// decoded bytes.
+	GzipTranscoding bool `mapstructure:"gzip_transcoding"`
+
+	// ReadRepair is what GET does when the metadata store and the stored
+	// data disagree. "flag" answers 500 InternalError for an object whose
+	// data is missing and marks it for the integrity report, "rebuild" also
+	// recreates the metadata of a data file that has none, and "off" answers
+	// 404 NoSuchKey for missing data as if the object didn't exist.
+	ReadRepair string `mapstructure:"read_repair"`
+
+	// ReservedKeyPrefixes are object key prefixes client writes (PUT, copy,
+	// multipart) are refused with 403 AccessDenied; MaxIOFS itself can still
+	// write there. Defaults to the VEEAM SOSAPI system namespace.
+	ReservedKeyPrefixes []string `mapstructure:"reserved_key_prefixes"`
+
+	// UploadScan is the external scanner (antivirus, content validation)
+	// uploads to buckets with scanning enabled must pass before they become
+	// visible.
+	UploadScan UploadScanConfig `mapstructure:"upload_scan"`
+}
+
+// UploadScanConfig defines the HTTP upload scanner. Each upload is POSTed to
+// Endpoint, which answers {"allowed": <bool>, "reason": "..."}. When the
+// scanner can't be reached or answers anything else, the upload is rejected
+// unless FailOpen is set.
+type UploadScanConfig struct {
+	Endpoint       string `mapstructure:"endpoint"`        // http(s) URL; empty disables scanning
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // per upload (default: 60)
+	FailOpen       bool   `mapstructure:"fail_open"`       // accept uploads the scanner couldn't judge
+}
+
+// S3BackendConfig defines the remote bucket used by the s3 storage backend
+type S3BackendConfig struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	Prefix    string `mapstructure:"prefix"` // Key prefix inside the remote bucket
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+
+	// PartSizeMB is the size of the parts large objects are streamed to the
+	// remote in; it is also the most a single upload keeps in memory.
+	PartSizeMB int `mapstructure:"part_size_mb"`
+}
+
+// ConsoleCORSConfig defines the CORS policy of the console API. An empty
+// AllowedOrigins allows only the console itself: public_console_url, the
+// console listen address and the frontend dev server. Empty methods or
+// headers keep the built-in lists.
+type ConsoleCORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"` // exact origins or "*.example.com"
+	AllowedMethods   []string `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
 }
 
 // AuthConfig defines authentication configuration
@@ -90,6 +284,66 @@ type AuthConfig struct {
 
 	// Users configuration file
 	UsersFile string `mapstructure:"users_file"`
+
+	// SecretEncryptionKey is the master key S3 secret access keys are
+	// encrypted with in the database. It is kept out of the database so a
+	// copy of the DB alone doesn't reveal the secrets. When empty, the key
+	// is read from SecretEncryptionKeyFile, generated on first start.
+	SecretEncryptionKey string `mapstructure:"secret_encryption_key"`
+
+	// SecretEncryptionKeyFile holds the generated master key when
+	// SecretEncryptionKey is empty. Empty uses <data_dir>/secret_encryption.key.
+	SecretEncryptionKeyFile string `mapstructure:"secret_encryption_key_file"`
+
+	// ClockSkewSeconds is how far a SigV4 request timestamp (header or presigned
+	// X-Amz-Date) may drift from the server clock before RequestTimeTooSkewed.
+	// 0 uses the default of 900 (±15 minutes).
+	ClockSkewSeconds int `mapstructure:"clock_skew_seconds"`
+
+	// PresignedMaxExpirySeconds caps the lifetime of presigned URLs generated
+	// by the console. 0 uses the default of 604800 (7 days, the SigV4 maximum).
+	PresignedMaxExpirySeconds int `mapstructure:"presigned_max_expiry_seconds"`
+
+	// DefaultRegion is the region presigned URLs are scoped to for buckets
+	// without a region of their own. Empty uses us-east-1.
+	DefaultRegion string `mapstructure:"default_region"`
+
+	// SigningService is the SigV4 service name presigned URLs are signed
+	// for. Empty uses s3; URLs scoped to s3 are always accepted.
+	SigningService string `mapstructure:"signing_service"`
+
+	// TrustedNetworks lets S3 clients on internal networks authenticate with
+	// a client certificate or a shared token instead of a SigV4 signature.
+	// Disabled unless CIDRs are listed. This weakens authentication for those
+	// networks: anyone who can reach the API from them with the token (or a
+	// certificate from the client CA) acts as the mapped access key.
+	TrustedNetworks TrustedNetworksConfig `mapstructure:"trusted_networks"`
+}
+
+// TrustedNetworksConfig configures the SigV4 bypass for trusted networks.
+type TrustedNetworksConfig struct {
+	// CIDRs are the networks whose requests may use a trusted identity. The
+	// client address is the TCP peer; forwarded headers are not consulted.
+	CIDRs []string `mapstructure:"cidrs"`
+
+	// ClientCAFile is a PEM bundle of CAs that sign client certificates.
+	// Setting it makes the S3 API (enable_tls required) ask clients for a
+	// certificate; connections without one still work.
+	ClientCAFile string `mapstructure:"client_ca_file"`
+
+	// Identities map a certificate subject or a bearer token to an access key.
+	Identities []TrustedIdentity `mapstructure:"identities"`
+}
+
+// TrustedIdentity maps one way of proving identity on a trusted network to
+// an access key. Exactly one of CertSubject and BearerToken is set. The
+// request is authorized as the access key's user.
+type TrustedIdentity struct {
+	AccessKey string `mapstructure:"access_key"`
+	// CertSubject is matched against the client certificate's common name.
+	CertSubject string `mapstructure:"cert_subject"`
+	// BearerToken is sent as "Authorization: Bearer <token>".
+	BearerToken string `mapstructure:"bearer_token"`
 }
 
 // MetricsConfig defines metrics configuration
@@ -104,6 +358,36 @@ type AuditConfig struct {
 	Enable        bool   `mapstructure:"enable"`
 	RetentionDays int    `mapstructure:"retention_days"`
 	DBPath        string `mapstructure:"db_path"`
+
+	// Sinks receive a copy of every audit event in addition to the local store.
+	Sinks []AuditSinkConfig `mapstructure:"sinks"`
+}
+
+// AuditSinkConfig declares one external audit sink. Type selects the
+// destination ("syslog", "file" or "s3"); only that type's fields apply.
+type AuditSinkConfig struct {
+	Type string `mapstructure:"type"`
+
+	// syslog (RFC 5424)
+	Protocol string `mapstructure:"protocol"` // udp, tcp or tcp+tls (default: udp)
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"` // default: 514
+	Tag      string `mapstructure:"tag"`  // default: maxiofs-audit
+
+	// file (append-only JSON lines)
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"` // rotate above this size (default: 100)
+	MaxBackups int    `mapstructure:"max_backups"` // rotated files kept (default: 10)
+
+	// s3 (JSON-lines batches uploaded as objects)
+	Endpoint             string `mapstructure:"endpoint"`
+	Region               string `mapstructure:"region"` // default: us-east-1
+	Bucket               string `mapstructure:"bucket"`
+	Prefix               string `mapstructure:"prefix"`
+	AccessKey            string `mapstructure:"access_key"`
+	SecretKey            string `mapstructure:"secret_key"`
+	BatchSize            int    `mapstructure:"batch_size"`             // events per object (default: 500)
+	FlushIntervalSeconds int    `mapstructure:"flush_interval_seconds"` // max buffering time (default: 60)
 }
 
 // ReplicationYAMLConfig defines replication configuration (static, from config.yaml)
@@ -159,6 +443,30 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("console_listen", ":8081") // Web console listen address
 	// NO default for data_dir - must be explicitly configured
 	v.SetDefault("log_level", "info")
+	v.SetDefault("log_format", "")          // Empty: console logging.format setting decides (json by default)
+	v.SetDefault("list_timeout_seconds", 0) // No listing deadline beyond the client connection
+	v.SetDefault("honor_request_id_headers", true)
+	v.SetDefault("global_bucket_namespace", true)
+
+	// Concurrency defaults (0 = unlimited)
+	v.SetDefault("concurrency.max_put", 0)
+	v.SetDefault("concurrency.max_get", 0)
+	v.SetDefault("concurrency.max_multipart", 0)
+
+	// Lifecycle worker defaults
+	v.SetDefault("lifecycle.workers", 4)
+	v.SetDefault("lifecycle.scan_rate", 0)
+
+	// Memory bounds
+	v.SetDefault("memory.soft_limit_mb", 0)
+	v.SetDefault("memory.bucket_cache_max_entries", 10000)
+	v.SetDefault("memory.rate_limiter_max_entries", 100000)
+	v.SetDefault("memory.rate_limiter_idle_seconds", 120)
+	v.SetDefault("memory.thumbnail_cache_mb", 64)
+
+	// S3 error rendering for browsers
+	v.SetDefault("error_pages.mode", "xml")
+	v.SetDefault("error_pages.login_url", "")
 
 	// Public URL defaults (external URLs for reverse proxy scenarios)
 	// These are used for generating links, shares, presigned URLs, etc.
@@ -169,15 +477,43 @@ func setDefaults(v *viper.Viper) {
 	// TLS defaults
 	v.SetDefault("enable_tls", false)
 
+	// Console CORS defaults - origins default to the console's own URLs
+	v.SetDefault("console_cors.allow_credentials", true)
+
 	// Storage defaults
 	v.SetDefault("storage.backend", "filesystem")
 	v.SetDefault("storage.root", "") // Empty by default, will be set based on data_dir
+	v.SetDefault("storage.shard_depth", 2)
+	v.SetDefault("storage.object_key_layout", "path")
+	v.SetDefault("storage.reserved_free_mb", 512)
+	v.SetDefault("storage.s3.region", "us-east-1")
+	v.SetDefault("storage.s3.part_size_mb", 16)
 	v.SetDefault("storage.enable_encryption", false)
 	v.SetDefault("storage.enable_object_lock", true)
+	v.SetDefault("storage.multipart_max_parts", 10000)
+	v.SetDefault("storage.multipart_min_part_size", 5*1024*1024) // 5 MiB, as in S3
+	v.SetDefault("storage.max_multipart_uploads_per_bucket", 0)
+	v.SetDefault("storage.max_multipart_uploads_per_tenant", 0)
 	v.SetDefault("storage.metadata_cache_size_mb", 256)
+	v.SetDefault("storage.bucket_cache_ttl_seconds", 5)
+	v.SetDefault("storage.bucket_cache_consistency", "strong")
+	v.SetDefault("storage.upload_spill_threshold", 1024*1024) // 1 MiB
+	v.SetDefault("storage.disable_content_type_sniffing", false)
+	v.SetDefault("storage.cold_reads", "allow")
+	v.SetDefault("storage.gzip_transcoding", false)
+	v.SetDefault("storage.read_repair", "flag")
+	v.SetDefault("storage.reserved_key_prefixes", []string{".system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c/"})
+	v.SetDefault("storage.upload_scan.timeout_seconds", 60)
+	v.SetDefault("storage.upload_scan.fail_open", false)
 
 	// Auth defaults - NO default credentials for security
 	v.SetDefault("auth.enable_auth", true)
+	v.SetDefault("auth.clock_skew_seconds", 900)              // ±15 minutes, same as AWS
+	v.SetDefault("auth.presigned_max_expiry_seconds", 604800) // 7 days, the SigV4 maximum
+	v.SetDefault("auth.default_region", "us-east-1")
+	v.SetDefault("auth.signing_service", "s3")
+	v.SetDefault("auth.secret_encryption_key", "")
+	v.SetDefault("auth.secret_encryption_key_file", "") // empty: <data_dir>/secret_encryption.key
 	// access_key and secret_key must be explicitly configured
 	// or created through the web console on first setup
 
@@ -242,11 +578,118 @@ func validate(cfg *Config) error {
 			return fmt.Errorf("failed to create storage root: %w", err)
 		}
 	}
-	// Validate TLS configuration
-	if cfg.EnableTLS {
-		if cfg.CertFile == "" || cfg.KeyFile == "" {
-			return fmt.Errorf("TLS enabled but cert-file or key-file not specified")
+	if cfg.Storage.UploadTempDir != "" {
+		if err := os.MkdirAll(cfg.Storage.UploadTempDir, 0700); err != nil {
+			return fmt.Errorf("failed to create storage.upload_temp_dir: %w", err)
+		}
+	}
+	if cfg.LogFormat != "" && cfg.LogFormat != "json" && cfg.LogFormat != "text" {
+		return fmt.Errorf("log_format must be \"json\" or \"text\", got %q", cfg.LogFormat)
+	}
+	if cfg.ListTimeoutSeconds < 0 {
+		return fmt.Errorf("list_timeout_seconds must not be negative, got %d", cfg.ListTimeoutSeconds)
+	}
+	if c := cfg.Concurrency; c.MaxPut < 0 || c.MaxGet < 0 || c.MaxMultipart < 0 {
+		return fmt.Errorf("concurrency limits must not be negative (0 = unlimited)")
+	}
+	if c := cfg.Lifecycle; c.Workers < 0 || c.ScanRate < 0 {
+		return fmt.Errorf("lifecycle.workers and lifecycle.scan_rate must not be negative")
+	}
+	if m := cfg.Memory; m.SoftLimitMB < 0 || m.BucketCacheMaxEntries < 0 || m.RateLimiterMaxEntries < 0 ||
+		m.RateLimiterIdleSeconds < 0 || m.ThumbnailCacheMB < 0 {
+		return fmt.Errorf("memory limits must not be negative")
+	}
+	if m := cfg.Storage.ColdReads; m != "" && m != "allow" && m != "deny" && m != "wait" {
+		return fmt.Errorf("storage.cold_reads must be \"allow\", \"deny\" or \"wait\", got %q", m)
+	}
+	if m := cfg.Storage.ReadRepair; m != "" && m != "off" && m != "flag" && m != "rebuild" {
+		return fmt.Errorf("storage.read_repair must be \"off\", \"flag\" or \"rebuild\", got %q", m)
+	}
+	if endpoint := cfg.Storage.UploadScan.Endpoint; endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("storage.upload_scan.endpoint must be an http(s) URL, got %q", endpoint)
+		}
+	}
+	if cfg.Storage.UploadScan.TimeoutSeconds < 0 {
+		return fmt.Errorf("storage.upload_scan.timeout_seconds must not be negative")
+	}
+	switch cfg.ErrorPages.Mode {
+	case "", "xml", "html":
+	case "redirect":
+		if cfg.ErrorPages.LoginURL == "" {
+			return fmt.Errorf("error_pages.mode redirect requires error_pages.login_url")
+		}
+	default:
+		return fmt.Errorf("error_pages.mode must be \"xml\", \"html\" or \"redirect\", got %q", cfg.ErrorPages.Mode)
+	}
+	switch cfg.Storage.Backend {
+	case "", "filesystem":
+	case "s3":
+		if cfg.Storage.S3.Endpoint == "" || cfg.Storage.S3.Bucket == "" {
+			return fmt.Errorf("storage.backend s3 requires storage.s3.endpoint and storage.s3.bucket")
+		}
+		if n := cfg.Storage.S3.PartSizeMB; n < 0 || (n > 0 && n < 5) || n > 5120 {
+			return fmt.Errorf("storage.s3.part_size_mb must be between 5 and 5120 (0 = default), got %d", cfg.Storage.S3.PartSizeMB)
 		}
+	default:
+		return fmt.Errorf("storage.backend must be \"filesystem\" or \"s3\", got %q", cfg.Storage.Backend)
+	}
+	if cfg.Storage.ShardDepth < 0 || cfg.Storage.ShardDepth > 3 {
+		return fmt.Errorf("storage.shard_depth must be between 0 and 3, got %d", cfg.Storage.ShardDepth)
+	}
+	switch cfg.Storage.ObjectKeyLayout {
+	case "", "path", "opaque":
+	default:
+		return fmt.Errorf("storage.object_key_layout must be \"path\" or \"opaque\", got %q", cfg.Storage.ObjectKeyLayout)
+	}
+	if cfg.Storage.ReservedFreeMB < 0 {
+		return fmt.Errorf("storage.reserved_free_mb must not be negative, got %d", cfg.Storage.ReservedFreeMB)
+	}
+	if cfg.Storage.MultipartMaxParts < 0 || cfg.Storage.MultipartMaxParts > 10000 {
+		return fmt.Errorf("storage.multipart_max_parts must be between 1 and 10000 (0 = default), got %d", cfg.Storage.MultipartMaxParts)
+	}
+	if cfg.Storage.UploadSpillThreshold < 0 {
+		return fmt.Errorf("storage.upload_spill_threshold must not be negative, got %d", cfg.Storage.UploadSpillThreshold)
+	}
+	if cfg.Storage.MultipartMinPartSize < 0 {
+		return fmt.Errorf("storage.multipart_min_part_size must not be negative, got %d", cfg.Storage.MultipartMinPartSize)
+	}
+	if cfg.Storage.MaxMultipartUploadsPerBucket < 0 || cfg.Storage.MaxMultipartUploadsPerTenant < 0 {
+		return fmt.Errorf("storage.max_multipart_uploads_per_bucket and max_multipart_uploads_per_tenant must not be negative")
+	}
+	if cfg.Storage.BucketCacheTTLSeconds < 0 {
+		return fmt.Errorf("storage.bucket_cache_ttl_seconds must not be negative, got %d", cfg.Storage.BucketCacheTTLSeconds)
+	}
+	switch cfg.Storage.BucketCacheConsistency {
+	case "", "strong", "eventual":
+	default:
+		return fmt.Errorf("storage.bucket_cache_consistency must be strong or eventual, got %q", cfg.Storage.BucketCacheConsistency)
+	}
+	if cfg.Auth.ClockSkewSeconds < 0 {
+		return fmt.Errorf("auth.clock_skew_seconds must not be negative, got %d", cfg.Auth.ClockSkewSeconds)
+	}
+	if cfg.Auth.PresignedMaxExpirySeconds < 0 || cfg.Auth.PresignedMaxExpirySeconds > 604800 {
+		return fmt.Errorf("auth.presigned_max_expiry_seconds must be between 0 and 604800 (0 = default), got %d", cfg.Auth.PresignedMaxExpirySeconds)
+	}
+	if cfg.Auth.SecretEncryptionKey != "" && len(cfg.Auth.SecretEncryptionKey) < 32 {
+		return fmt.Errorf("auth.secret_encryption_key must be at least 32 characters")
+	}
+	// Both end up in the SigV4 credential scope, which is "/"-separated
+	if strings.ContainsAny(cfg.Auth.DefaultRegion, "/ ") {
+		return fmt.Errorf("auth.default_region must not contain '/' or spaces, got %q", cfg.Auth.DefaultRegion)
+	}
+	if strings.ContainsAny(cfg.Auth.SigningService, "/ ") {
+		return fmt.Errorf("auth.signing_service must not contain '/' or spaces, got %q", cfg.Auth.SigningService)
+	}
+
+	// Validate TLS configuration
+	if err := validateListenerTLS(cfg); err != nil {
+		return err
+	}
+
+	if err := validateTrustedNetworks(cfg); err != nil {
+		return err
 	}
 
 	// The S3 API and the web console are distinct services. If their public URLs
@@ -260,6 +703,10 @@ func validate(cfg *Config) error {
 		}
 	}
 
+	if err := validateConsoleCORS(cfg.ConsoleCORS); err != nil {
+		return fmt.Errorf("console_cors: %w", err)
+	}
+
 	// Generate JWT secret if not provided
 	if cfg.Auth.EnableAuth && cfg.Auth.JWTSecret == "" {
 		cfg.Auth.JWTSecret = generateRandomString(32)
@@ -271,6 +718,161 @@ func validate(cfg *Config) error {
 		cfg.Audit.DBPath = filepath.Join(cfg.DataDir, "audit.db")
 	}
 
+	for i, sink := range cfg.Audit.Sinks {
+		if err := validateAuditSink(sink); err != nil {
+			return fmt.Errorf("audit.sinks[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateAuditSink checks that a sink declares a known type and the fields
+// that type requires.
+func validateAuditSink(sink AuditSinkConfig) error {
+	switch sink.Type {
+	case "syslog":
+		if sink.Host == "" {
+			return fmt.Errorf("syslog sink requires host")
+		}
+		switch sink.Protocol {
+		case "", "udp", "tcp", "tcp+tls":
+		default:
+			return fmt.Errorf("unsupported syslog protocol %q (use udp, tcp or tcp+tls)", sink.Protocol)
+		}
+	case "file":
+		if sink.Path == "" {
+			return fmt.Errorf("file sink requires path")
+		}
+	case "s3":
+		if sink.Endpoint == "" || sink.Bucket == "" {
+			return fmt.Errorf("s3 sink requires endpoint and bucket")
+		}
+	default:
+		return fmt.Errorf("unknown sink type %q (use syslog, file or s3)", sink.Type)
+	}
+	if sink.MaxSizeMB < 0 || sink.MaxBackups < 0 || sink.BatchSize < 0 || sink.FlushIntervalSeconds < 0 {
+		return fmt.Errorf("sink sizes and intervals must not be negative")
+	}
+	return nil
+}
+
+// validateConsoleCORS checks that every allowed origin is a bare origin
+// (scheme://host[:port]) or a "*.domain" pattern. A bare "*" is refused with
+// credentials, since it would let any site call the API with a user's token.
+func validateConsoleCORS(cors ConsoleCORSConfig) error {
+	for _, origin := range cors.AllowedOrigins {
+		if origin == "*" {
+			if cors.AllowCredentials {
+				return fmt.Errorf("allowed_origins must not contain \"*\" when allow_credentials is enabled")
+			}
+			continue
+		}
+		if strings.HasPrefix(origin, "*.") {
+			if strings.ContainsAny(origin[2:], "/:* ") || origin[2:] == "" {
+				return fmt.Errorf("invalid origin pattern %q (use *.example.com)", origin)
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return fmt.Errorf("invalid origin %q (use scheme://host[:port])", origin)
+		}
+	}
+	return nil
+}
+
+// ListenerTLSConfig overrides the global TLS settings (enable_tls, cert_file,
+// key_file) for one listener. Unset fields fall back to the global values.
+type ListenerTLSConfig struct {
+	// Enable turns TLS on or off for the listener; nil follows enable_tls
+	Enable   *bool  `mapstructure:"enable"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+}
+
+// ListenerTLS is the effective TLS setting of one listener.
+type ListenerTLS struct {
+	Enabled  bool
+	CertFile string
+	KeyFile  string
+}
+
+// S3ListenerTLS returns the TLS setting of the S3 API listener.
+func (c *Config) S3ListenerTLS() ListenerTLS {
+	return c.resolveListenerTLS(c.S3TLS)
+}
+
+// ConsoleListenerTLS returns the TLS setting of the web console listener.
+func (c *Config) ConsoleListenerTLS() ListenerTLS {
+	return c.resolveListenerTLS(c.ConsoleTLS)
+}
+
+func (c *Config) resolveListenerTLS(override ListenerTLSConfig) ListenerTLS {
+	resolved := ListenerTLS{Enabled: c.EnableTLS, CertFile: c.CertFile, KeyFile: c.KeyFile}
+	if override.Enable != nil {
+		resolved.Enabled = *override.Enable
+	}
+	if override.CertFile != "" {
+		resolved.CertFile = override.CertFile
+	}
+	if override.KeyFile != "" {
+		resolved.KeyFile = override.KeyFile
+	}
+	return resolved
+}
+
+// validateListenerTLS requires a certificate and key for every listener that
+// serves TLS. Whether they form a valid pair is checked when the server loads
+// them.
+func validateListenerTLS(cfg *Config) error {
+	for _, listener := range []struct {
+		name string
+		tls  ListenerTLS
+	}{
+		{"s3_tls", cfg.S3ListenerTLS()},
+		{"console_tls", cfg.ConsoleListenerTLS()},
+	} {
+		if listener.tls.Enabled && (listener.tls.CertFile == "" || listener.tls.KeyFile == "") {
+			return fmt.Errorf("%s: TLS enabled but cert-file or key-file not specified", listener.name)
+		}
+	}
+	return nil
+}
+
+// validateTrustedNetworks checks the SigV4 bypass for trusted networks. Each
+// identity names an access key and exactly one credential; certificate
+// subjects need a client CA, which in turn needs TLS on the S3 API.
+func validateTrustedNetworks(cfg *Config) error {
+	tn := cfg.Auth.TrustedNetworks
+	if len(tn.CIDRs) == 0 {
+		if len(tn.Identities) > 0 || tn.ClientCAFile != "" {
+			return fmt.Errorf("auth.trusted_networks needs cidrs when identities or client_ca_file are set")
+		}
+		return nil
+	}
+	for _, cidr := range tn.CIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("auth.trusted_networks.cidrs: invalid CIDR %q", cidr)
+		}
+	}
+	if tn.ClientCAFile != "" && !cfg.S3ListenerTLS().Enabled {
+		return fmt.Errorf("auth.trusted_networks.client_ca_file requires enable_tls (or s3_tls.enable)")
+	}
+	for i, id := range tn.Identities {
+		if id.AccessKey == "" {
+			return fmt.Errorf("auth.trusted_networks.identities[%d]: access_key is required", i)
+		}
+		if (id.CertSubject == "") == (id.BearerToken == "") {
+			return fmt.Errorf("auth.trusted_networks.identities[%d]: set exactly one of cert_subject and bearer_token", i)
+		}
+		if id.CertSubject != "" && tn.ClientCAFile == "" {
+			return fmt.Errorf("auth.trusted_networks.identities[%d]: cert_subject requires client_ca_file", i)
+		}
+		if id.BearerToken != "" && len(id.BearerToken) < 32 {
+			return fmt.Errorf("auth.trusted_networks.identities[%d]: bearer_token must be at least 32 characters", i)
+		}
+	}
 	return nil
 }
 
@@ -36,6 +36,8 @@ func TestSetDefaults_Auth(t *testing.T) {
 
 	// Auth should be enabled by default
 	assert.True(t, v.GetBool("auth.enable_auth"))
+	assert.Equal(t, 900, v.GetInt("auth.clock_skew_seconds"))
+	assert.Equal(t, 604800, v.GetInt("auth.presigned_max_expiry_seconds"))
 }
 
 func TestSetDefaults_Metrics(t *testing.T) {
@@ -236,6 +238,96 @@ func TestValidate_TLSEnabledWithoutCerts(t *testing.T) {
 	assert.Contains(t, err.Error(), "TLS enabled but cert-file or key-file not specified")
 }
 
+func TestValidate_PresignedMaxExpiryAboveSigV4Limit(t *testing.T) {
+	cfg := &Config{
+		DataDir: t.TempDir(),
+		Auth:    AuthConfig{PresignedMaxExpirySeconds: 30 * 24 * 3600},
+	}
+
+	err := validate(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth.presigned_max_expiry_seconds")
+}
+
+func TestValidate_ShortSecretEncryptionKey(t *testing.T) {
+	cfg := &Config{
+		DataDir: t.TempDir(),
+		Auth:    AuthConfig{SecretEncryptionKey: "too-short"},
+	}
+
+	err := validate(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth.secret_encryption_key")
+}
+
+func TestValidate_InvalidSigningScope(t *testing.T) {
+	cfg := &Config{
+		DataDir: t.TempDir(),
+		Auth:    AuthConfig{DefaultRegion: "eu-west-1/x"},
+	}
+	err := validate(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth.default_region")
+
+	cfg.Auth = AuthConfig{DefaultRegion: "eu-west-1", SigningService: "s3 "}
+	err = validate(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth.signing_service")
+}
+
+func TestValidate_ConsoleCORS(t *testing.T) {
+	tests := []struct {
+		name    string
+		cors    ConsoleCORSConfig
+		wantErr string
+	}{
+		{"defaults", ConsoleCORSConfig{AllowCredentials: true}, ""},
+		{"allowlist", ConsoleCORSConfig{AllowedOrigins: []string{"https://console.example.com", "http://localhost:5173", "*.example.com"}, AllowCredentials: true}, ""},
+		{"wildcard without credentials", ConsoleCORSConfig{AllowedOrigins: []string{"*"}}, ""},
+		{"wildcard with credentials", ConsoleCORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, "allow_credentials"},
+		{"origin with path", ConsoleCORSConfig{AllowedOrigins: []string{"https://console.example.com/ui"}}, "invalid origin"},
+		{"origin without scheme", ConsoleCORSConfig{AllowedOrigins: []string{"console.example.com"}}, "invalid origin"},
+		{"bad pattern", ConsoleCORSConfig{AllowedOrigins: []string{"*.example.com:8081"}}, "invalid origin pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(&Config{DataDir: t.TempDir(), ConsoleCORS: tt.cors})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "console_cors")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_ErrorPages(t *testing.T) {
+	tests := []struct {
+		name    string
+		pages   ErrorPagesConfig
+		wantErr string
+	}{
+		{"unset", ErrorPagesConfig{}, ""},
+		{"html", ErrorPagesConfig{Mode: "html"}, ""},
+		{"redirect", ErrorPagesConfig{Mode: "redirect", LoginURL: "https://console.example.com/login"}, ""},
+		{"redirect without url", ErrorPagesConfig{Mode: "redirect"}, "error_pages.login_url"},
+		{"unknown mode", ErrorPagesConfig{Mode: "json"}, "error_pages.mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(&Config{DataDir: t.TempDir(), ErrorPages: tt.pages})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestValidate_TLSEnabledWithCerts(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -441,6 +533,7 @@ func TestLoad_WithDefaults(t *testing.T) {
 	assert.Equal(t, ":8081", cfg.ConsoleListen)
 	assert.Equal(t, tempDir, cfg.DataDir)
 	assert.Equal(t, "info", cfg.LogLevel)
+	assert.True(t, cfg.GlobalBucketNamespace)
 	assert.Equal(t, "filesystem", cfg.Storage.Backend)
 	assert.True(t, cfg.Auth.EnableAuth)
 	assert.True(t, cfg.Metrics.Enable)
@@ -688,3 +781,196 @@ func TestNormalizeURLForCompare(t *testing.T) {
 		}
 	}
 }
+
+func TestLoad_AuditSinksFromConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.yaml")
+
+	configContent := "data_dir: \"" + filepath.ToSlash(tempDir) + "\"\n" +
+		"audit:\n" +
+		"  enable: true\n" +
+		"  sinks:\n" +
+		"    - type: file\n" +
+		"      path: \"" + filepath.ToSlash(filepath.Join(tempDir, "audit.jsonl")) + "\"\n" +
+		"      max_size_mb: 50\n" +
+		"    - type: syslog\n" +
+		"      host: siem.example.com\n" +
+		"      protocol: tcp\n" +
+		"      port: 6514\n"
+	require.NoError(t, os.WriteFile(configFile, []byte(configContent), 0644))
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("listen", ":8080", "listen address")
+	cmd.Flags().String("console-listen", ":8081", "console listen address")
+	cmd.Flags().String("data-dir", "", "data directory")
+	cmd.Flags().String("log-level", "info", "log level")
+	cmd.Flags().String("tls-cert", "", "TLS certificate file")
+	cmd.Flags().String("tls-key", "", "TLS key file")
+	cmd.Flags().String("config", configFile, "config file")
+	require.NoError(t, cmd.Flags().Set("config", configFile))
+
+	cfg, err := Load(cmd)
+	require.NoError(t, err)
+	require.Len(t, cfg.Audit.Sinks, 2)
+	assert.Equal(t, "file", cfg.Audit.Sinks[0].Type)
+	assert.Equal(t, 50, cfg.Audit.Sinks[0].MaxSizeMB)
+	assert.Equal(t, "syslog", cfg.Audit.Sinks[1].Type)
+	assert.Equal(t, "siem.example.com", cfg.Audit.Sinks[1].Host)
+	assert.Equal(t, 6514, cfg.Audit.Sinks[1].Port)
+}
+
+func TestValidate_AuditSinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		sink    AuditSinkConfig
+		wantErr string
+	}{
+		{"file", AuditSinkConfig{Type: "file", Path: "/var/log/maxiofs/audit.jsonl"}, ""},
+		{"syslog", AuditSinkConfig{Type: "syslog", Host: "siem.local"}, ""},
+		{"s3", AuditSinkConfig{Type: "s3", Endpoint: "https://s3.example.com", Bucket: "audit"}, ""},
+		{"unknown type", AuditSinkConfig{Type: "kafka"}, "unknown sink type"},
+		{"file without path", AuditSinkConfig{Type: "file"}, "requires path"},
+		{"syslog bad protocol", AuditSinkConfig{Type: "syslog", Host: "siem.local", Protocol: "http"}, "unsupported syslog protocol"},
+		{"s3 without bucket", AuditSinkConfig{Type: "s3", Endpoint: "https://s3.example.com"}, "requires endpoint and bucket"},
+		{"negative size", AuditSinkConfig{Type: "file", Path: "/tmp/a", MaxSizeMB: -1}, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				DataDir: t.TempDir(),
+				Audit:   AuditConfig{Enable: true, Sinks: []AuditSinkConfig{tt.sink}},
+			}
+			err := validate(cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "audit.sinks[0]")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidate_TrustedNetworks(t *testing.T) {
+	token := "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name    string
+		tls     bool
+		tn      TrustedNetworksConfig
+		wantErr string
+	}{
+		{"disabled", false, TrustedNetworksConfig{}, ""},
+		{"bearer token", false, TrustedNetworksConfig{CIDRs: []string{"10.0.0.0/8"},
+			Identities: []TrustedIdentity{{AccessKey: "AK1", BearerToken: token}}}, ""},
+		{"cert subject", true, TrustedNetworksConfig{CIDRs: []string{"10.0.0.0/8", "fd00::/8"}, ClientCAFile: "/etc/maxiofs/ca.pem",
+			Identities: []TrustedIdentity{{AccessKey: "AK1", CertSubject: "backup-agent"}}}, ""},
+		{"identities without cidrs", false, TrustedNetworksConfig{
+			Identities: []TrustedIdentity{{AccessKey: "AK1", BearerToken: token}}}, "needs cidrs"},
+		{"bad cidr", false, TrustedNetworksConfig{CIDRs: []string{"10.0.0.1"}}, "invalid CIDR"},
+		{"client ca without tls", false, TrustedNetworksConfig{CIDRs: []string{"10.0.0.0/8"}, ClientCAFile: "/ca.pem"}, "requires enable_tls"},
+		{"no access key", false, TrustedNetworksConfig{CIDRs: []string{"10.0.0.0/8"},
+			Identities: []TrustedIdentity{{BearerToken: token}}}, "access_key is required"},
+		{"both credentials", true, TrustedNetworksConfig{CIDRs: []string{"10.0.0.0/8"}, ClientCAFile: "/ca.pem",
+			Identities: []TrustedIdentity{{AccessKey: "AK1", BearerToken: token, CertSubject: "agent"}}}, "exactly one"},
+		{"cert subject without ca", true, TrustedNetworksConfig{CIDRs: []string{"10.0.0.0/8"},
+			Identities: []TrustedIdentity{{AccessKey: "AK1", CertSubject: "agent"}}}, "requires client_ca_file"},
+		{"short token", false, TrustedNetworksConfig{CIDRs: []string{"10.0.0.0/8"},
+			Identities: []TrustedIdentity{{AccessKey: "AK1", BearerToken: "secret"}}}, "at least 32"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{EnableTLS: tt.tls, Auth: AuthConfig{TrustedNetworks: tt.tn}}
+			err := validateTrustedNetworks(cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoad_PerListenerTLS(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		wantS3      ListenerTLS
+		wantConsole ListenerTLS
+	}{
+		{
+			name: "S3 over TLS, console in plaintext on localhost",
+			yaml: "console_listen: \"127.0.0.1:8081\"\n" +
+				"s3_tls:\n  enable: true\n  cert_file: /etc/maxiofs/s3.crt\n  key_file: /etc/maxiofs/s3.key\n",
+			wantS3:      ListenerTLS{Enabled: true, CertFile: "/etc/maxiofs/s3.crt", KeyFile: "/etc/maxiofs/s3.key"},
+			wantConsole: ListenerTLS{},
+		},
+		{
+			name: "global TLS with the console opted out",
+			yaml: "enable_tls: true\ncert_file: /etc/maxiofs/tls.crt\nkey_file: /etc/maxiofs/tls.key\n" +
+				"console_tls:\n  enable: false\n",
+			wantS3:      ListenerTLS{Enabled: true, CertFile: "/etc/maxiofs/tls.crt", KeyFile: "/etc/maxiofs/tls.key"},
+			wantConsole: ListenerTLS{Enabled: false, CertFile: "/etc/maxiofs/tls.crt", KeyFile: "/etc/maxiofs/tls.key"},
+		},
+		{
+			name: "console over TLS with its own certificate, S3 in plaintext",
+			yaml: "console_tls:\n  enable: true\n  cert_file: /etc/maxiofs/console.crt\n  key_file: /etc/maxiofs/console.key\n",
+			wantS3:      ListenerTLS{},
+			wantConsole: ListenerTLS{Enabled: true, CertFile: "/etc/maxiofs/console.crt", KeyFile: "/etc/maxiofs/console.key"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			configFile := filepath.Join(tempDir, "config.yaml")
+			content := "data_dir: \"" + filepath.ToSlash(tempDir) + "\"\n" + tt.yaml
+			require.NoError(t, os.WriteFile(configFile, []byte(content), 0644))
+
+			cmd := &cobra.Command{}
+			cmd.Flags().String("listen", ":8080", "listen address")
+			cmd.Flags().String("console-listen", ":8081", "console listen address")
+			cmd.Flags().String("data-dir", "", "data directory")
+			cmd.Flags().String("log-level", "info", "log level")
+			cmd.Flags().String("tls-cert", "", "TLS certificate file")
+			cmd.Flags().String("tls-key", "", "TLS key file")
+			cmd.Flags().String("config", configFile, "config file")
+			require.NoError(t, cmd.Flags().Set("config", configFile))
+
+			cfg, err := Load(cmd)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantS3, cfg.S3ListenerTLS())
+			assert.Equal(t, tt.wantConsole, cfg.ConsoleListenerTLS())
+		})
+	}
+}
+
+func TestValidateListenerTLS(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"plaintext", Config{}, ""},
+		{"global without certificate", Config{EnableTLS: true}, "s3_tls: TLS enabled but cert-file or key-file not specified"},
+		{"S3 only with its own certificate", Config{S3TLS: ListenerTLSConfig{Enable: &on, CertFile: "s3.crt", KeyFile: "s3.key"}}, ""},
+		{"S3 only without key", Config{S3TLS: ListenerTLSConfig{Enable: &on, CertFile: "s3.crt"}}, "s3_tls:"},
+		{"console only without certificate", Config{ConsoleTLS: ListenerTLSConfig{Enable: &on}}, "console_tls:"},
+		{"global certificate reused by one listener", Config{CertFile: "tls.crt", KeyFile: "tls.key",
+			ConsoleTLS: ListenerTLSConfig{Enable: &on}}, ""},
+		{"global TLS, console opted out", Config{EnableTLS: true, CertFile: "tls.crt", KeyFile: "tls.key",
+			ConsoleTLS: ListenerTLSConfig{Enable: &off}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateListenerTLS(&tt.cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -25,6 +25,123 @@ func getAllMigrations() []Migration {
 		migration15_v150_TenantBandwidth(),
 		migration16_v150_EncryptionKeys(),
 		migration17_v150_ClusterSharedKEK(),
+		migration18_v150_TenantLockoutPolicy(),
+		migration19_v150_TenantEncryptionKeys(),
+		migration20_v150_UserPasswordChangedAt(),
+		migration21_v150_AccessKeyInfo(),
+		migration22_v150_TOTPLastStep(),
+	}
+}
+
+// migration22_v150_TOTPLastStep records the last TOTP time step each user
+// authenticated with. Corresponds to MaxIOFS v1.5.0 - TOTP replay protection:
+// a code is only accepted for a step later than this one.
+func migration22_v150_TOTPLastStep() Migration {
+	return Migration{
+		Version:     22,
+		Description: "v1.5.0 - Add totp_last_step to users (TOTP replay protection)",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE users ADD COLUMN totp_last_step INTEGER NOT NULL DEFAULT 0`); err != nil {
+				return err
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			return nil
+		},
+	}
+}
+
+// migration21_v150_AccessKeyInfo adds operator-supplied labels to access keys.
+// Corresponds to MaxIOFS v1.5.0 - Access key naming: tags is a JSON object,
+// empty when the key has none.
+func migration21_v150_AccessKeyInfo() Migration {
+	return Migration{
+		Version:     21,
+		Description: "v1.5.0 - Add name, description and tags to access_keys",
+		Up: func(tx *sql.Tx) error {
+			for _, column := range []string{"name", "description", "tags"} {
+				if _, err := tx.Exec(`ALTER TABLE access_keys ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			return nil
+		},
+	}
+}
+
+// migration20_v150_UserPasswordChangedAt records when each user's password
+// was last set. Corresponds to MaxIOFS v1.5.0 - Password maximum age: 0 means
+// the password predates tracking and its age counts from created_at.
+func migration20_v150_UserPasswordChangedAt() Migration {
+	return Migration{
+		Version:     20,
+		Description: "v1.5.0 - Add password_changed_at to users (password maximum age)",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE users ADD COLUMN password_changed_at INTEGER NOT NULL DEFAULT 0`); err != nil {
+				return err
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			return nil
+		},
+	}
+}
+
+// migration19_v150_TenantEncryptionKeys creates the per-tenant key table.
+// Corresponds to MaxIOFS v1.5.0 - Per-tenant encryption keys: each tenant's
+// DEKs are wrapped by the tenant's own key, stored here wrapped by the KEK
+// version recorded in kek_version. Exactly one row per tenant has
+// is_current=1.
+func migration19_v150_TenantEncryptionKeys() Migration {
+	return Migration{
+		Version:     19,
+		Description: "v1.5.0 - Create tenant_encryption_keys table (per-tenant keys wrapped by the KEK)",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS tenant_encryption_keys (
+					tenant_id TEXT NOT NULL,
+					version INTEGER NOT NULL,
+					wrapped_key_hex TEXT NOT NULL,
+					kek_version INTEGER NOT NULL,
+					is_current INTEGER NOT NULL DEFAULT 0,
+					created_at INTEGER NOT NULL,
+					PRIMARY KEY (tenant_id, version)
+				)
+			`); err != nil {
+				return err
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			return nil
+		},
+	}
+}
+
+// migration18_v150_TenantLockoutPolicy adds per-tenant overrides for the
+// account lockout threshold and duration.
+// Corresponds to MaxIOFS v1.5.0 - Per-tenant account lockout policy.
+func migration18_v150_TenantLockoutPolicy() Migration {
+	return Migration{
+		Version:     18,
+		Description: "v1.5.0 - Add max_failed_login_attempts and lockout_duration_seconds to tenants (0 = global setting)",
+		Up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`ALTER TABLE tenants ADD COLUMN max_failed_login_attempts INTEGER NOT NULL DEFAULT 0`); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(`ALTER TABLE tenants ADD COLUMN lockout_duration_seconds INTEGER NOT NULL DEFAULT 0`); err != nil {
+				return err
+			}
+			return nil
+		},
+		Down: func(tx *sql.Tx) error {
+			return nil
+		},
 	}
 }
 
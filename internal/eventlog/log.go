@@ -0,0 +1,309 @@
+package eventlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRetentionHours is how long events are kept when the
+	// storage.event_log_retention_hours setting is unavailable.
+	DefaultRetentionHours = 24
+
+	// DefaultMaxEventsPerBucket caps each bucket's log when the
+	// storage.event_log_max_per_bucket setting is unavailable.
+	DefaultMaxEventsPerBucket = 10000
+
+	// DefaultListLimit and MaxListLimit bound the events returned by one List call.
+	DefaultListLimit = 100
+	MaxListLimit     = 1000
+
+	keyPrefix = "eventlog:"
+)
+
+// ErrInvalidCursor is returned by List for a cursor it didn't hand out.
+var ErrInvalidCursor = errors.New("invalid event cursor")
+
+// SettingsManager is the subset of settings.Manager used by the event log.
+type SettingsManager interface {
+	GetInt(key string) (int, error)
+}
+
+// Event is one entry of a bucket's event log.
+type Event struct {
+	Cursor    string    `json:"cursor"`
+	EventName string    `json:"eventName"`
+	Key       string    `json:"key"`
+	VersionID string    `json:"versionId,omitempty"`
+	Size      int64     `json:"size,omitempty"`
+	ETag      string    `json:"etag,omitempty"`
+	EventTime time.Time `json:"eventTime"`
+}
+
+// Log is an append-only, per-bucket log of object events kept in the metadata
+// store, which clients poll with a cursor. Events expire after the retention
+// period and each bucket keeps at most the configured number of events.
+//
+// An event's cursor is its sequence number: a Unix nanosecond timestamp,
+// bumped when needed so it strictly increases, which keeps cursors valid
+// across restarts.
+type Log struct {
+	kv              metadata.RawKVStore
+	settingsManager SettingsManager
+	now             func() time.Time
+
+	mu      sync.Mutex
+	lastSeq uint64
+	// pending counts events appended per bucket since it was last trimmed
+	pending map[string]int
+}
+
+// New creates an event log stored in kv.
+func New(kv metadata.RawKVStore) *Log {
+	return &Log{
+		kv:      kv,
+		now:     time.Now,
+		pending: make(map[string]int),
+	}
+}
+
+// SetSettingsManager wires the settings manager for the retention settings.
+func (l *Log) SetSettingsManager(sm SettingsManager) {
+	l.settingsManager = sm
+}
+
+func eventKey(bucketPath string, seq uint64) string {
+	return fmt.Sprintf("%s%s:%020d", keyPrefix, bucketPath, seq)
+}
+
+func bucketPrefix(bucketPath string) string {
+	return keyPrefix + bucketPath + ":"
+}
+
+// parseEventKey splits an event key into its bucket path and sequence number.
+func parseEventKey(key string) (string, uint64, bool) {
+	rest := strings.TrimPrefix(key, keyPrefix)
+	i := strings.LastIndex(rest, ":")
+	if i < 0 {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseUint(rest[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return rest[:i], seq, true
+}
+
+func (l *Log) retention() time.Duration {
+	hours := DefaultRetentionHours
+	if l.settingsManager != nil {
+		if v, err := l.settingsManager.GetInt("storage.event_log_retention_hours"); err == nil && v > 0 {
+			hours = v
+		}
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+func (l *Log) maxEvents() int {
+	max := DefaultMaxEventsPerBucket
+	if l.settingsManager != nil {
+		if v, err := l.settingsManager.GetInt("storage.event_log_max_per_bucket"); err == nil && v > 0 {
+			max = v
+		}
+	}
+	return max
+}
+
+// Append adds ev to the log of bucketPath, setting its cursor and, if unset,
+// its time.
+//
+// The sequence number is allocated and the event written under one lock, so
+// events become visible in sequence order: a poller that reads seq N+1 can
+// never see seq N committed later behind its cursor.
+func (l *Log) Append(ctx context.Context, bucketPath string, ev Event) error {
+	now := l.now()
+	if ev.EventTime.IsZero() {
+		ev.EventTime = now.UTC()
+	}
+
+	max := l.maxEvents()
+	l.mu.Lock()
+	seq := uint64(now.UnixNano())
+	if seq <= l.lastSeq {
+		seq = l.lastSeq + 1
+	}
+	ev.Cursor = strconv.FormatUint(seq, 10)
+	data, err := json.Marshal(ev)
+	if err != nil {
+		l.mu.Unlock()
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err := l.kv.PutRaw(ctx, eventKey(bucketPath, seq), data); err != nil {
+		l.mu.Unlock()
+		return fmt.Errorf("failed to store event: %w", err)
+	}
+	l.lastSeq = seq
+	l.pending[bucketPath]++
+	// Trim once a tenth of the cap has been appended, so a busy bucket
+	// stays within about 110% of it between the periodic prunes
+	trim := l.pending[bucketPath] > max/10
+	if trim {
+		l.pending[bucketPath] = 0
+	}
+	l.mu.Unlock()
+
+	if trim {
+		if _, err := l.trim(ctx, bucketPrefix(bucketPath)); err != nil {
+			logrus.WithError(err).WithField("bucket", bucketPath).Warn("Failed to trim bucket event log")
+		}
+	}
+	return nil
+}
+
+// List returns up to limit events of bucketPath that come after cursor, oldest
+// first; an empty cursor starts at the oldest retained event. It also returns
+// the cursor to poll from next and whether more events are waiting. Without
+// new events the next cursor is cursor itself or, on a first poll of an empty
+// log, the current position.
+func (l *Log) List(ctx context.Context, bucketPath, cursor string, limit int) ([]Event, string, bool, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	startKey := ""
+	if cursor != "" {
+		seq, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil {
+			return nil, "", false, ErrInvalidCursor
+		}
+		startKey = eventKey(bucketPath, seq+1)
+	}
+
+	events := []Event{}
+	truncated := false
+	var decodeErr error
+	err := l.kv.RawScan(ctx, bucketPrefix(bucketPath), startKey, func(key string, val []byte) bool {
+		if len(events) == limit {
+			truncated = true
+			return false
+		}
+		var ev Event
+		if err := json.Unmarshal(val, &ev); err != nil {
+			decodeErr = fmt.Errorf("failed to decode event %s: %w", key, err)
+			return false
+		}
+		events = append(events, ev)
+		return true
+	})
+	if err != nil {
+		return nil, "", false, err
+	}
+	if decodeErr != nil {
+		return nil, "", false, decodeErr
+	}
+
+	next := cursor
+	if len(events) > 0 {
+		next = events[len(events)-1].Cursor
+	} else if next == "" {
+		next = strconv.FormatUint(l.currentSeq(), 10)
+	}
+	return events, next, truncated, nil
+}
+
+// currentSeq is the highest sequence number written so far or the current
+// time, whichever is later; every later event sorts after it.
+func (l *Log) currentSeq() uint64 {
+	seq := uint64(l.now().UnixNano())
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.lastSeq > seq {
+		seq = l.lastSeq
+	}
+	return seq
+}
+
+// Prune deletes expired events from every bucket's log and trims each log to
+// the configured size. It returns the number of events removed.
+func (l *Log) Prune(ctx context.Context) (int, error) {
+	return l.trim(ctx, keyPrefix)
+}
+
+// trim removes events under prefix, which covers one bucket or all of them,
+// that are older than the retention period or beyond a bucket's cap.
+func (l *Log) trim(ctx context.Context, prefix string) (int, error) {
+	cutoff := uint64(l.now().Add(-l.retention()).UnixNano())
+	max := l.maxEvents()
+
+	var deletes []string
+	var bucket string
+	var kept []string
+	flush := func() {
+		if len(kept) > max {
+			deletes = append(deletes, kept[:len(kept)-max]...)
+		}
+		kept = kept[:0]
+	}
+
+	err := l.kv.RawScan(ctx, prefix, "", func(key string, _ []byte) bool {
+		b, seq, ok := parseEventKey(key)
+		if !ok {
+			return true
+		}
+		if b != bucket {
+			flush()
+			bucket = b
+		}
+		if seq < cutoff {
+			deletes = append(deletes, key)
+		} else {
+			kept = append(kept, key)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	flush()
+
+	if len(deletes) == 0 {
+		return 0, nil
+	}
+	if err := l.kv.RawBatch(ctx, nil, deletes); err != nil {
+		return 0, err
+	}
+	return len(deletes), nil
+}
+
+// Start prunes the log every interval until ctx is cancelled.
+func (l *Log) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := l.Prune(ctx)
+				if err != nil {
+					logrus.WithError(err).Warn("Failed to prune bucket event log")
+				} else if n > 0 {
+					logrus.WithField("deleted", n).Debug("Pruned bucket event log")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,174 @@
+package eventlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSettings map[string]int
+
+func (s fakeSettings) GetInt(key string) (int, error) {
+	v, ok := s[key]
+	if !ok {
+		return 0, fmt.Errorf("setting %s not found", key)
+	}
+	return v, nil
+}
+
+func newTestLog(t *testing.T) *Log {
+	store, err := metadata.NewPebbleStore(metadata.PebbleOptions{DataDir: t.TempDir()})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return New(store)
+}
+
+func eventKeys(events []Event) []string {
+	keys := make([]string, len(events))
+	for i, ev := range events {
+		keys[i] = ev.Key
+	}
+	return keys
+}
+
+func TestLogAppendAndPoll(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLog(t)
+
+	// A first poll of an empty log returns a cursor that only sees later events
+	events, cursor, truncated, err := l.List(ctx, "tenant/bucket", "", 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.False(t, truncated)
+	require.NotEmpty(t, cursor)
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, l.Append(ctx, "tenant/bucket", Event{EventName: "s3:ObjectCreated:Put", Key: key}))
+	}
+	require.NoError(t, l.Append(ctx, "tenant/other", Event{EventName: "s3:ObjectCreated:Put", Key: "x"}))
+
+	events, next, truncated, err := l.List(ctx, "tenant/bucket", cursor, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, eventKeys(events))
+	assert.True(t, truncated)
+	assert.Equal(t, events[1].Cursor, next)
+	assert.False(t, events[0].EventTime.IsZero())
+
+	events, next, truncated, err = l.List(ctx, "tenant/bucket", next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, eventKeys(events))
+	assert.False(t, truncated)
+
+	// Nothing new: the cursor stays put
+	events, again, _, err := l.List(ctx, "tenant/bucket", next, 2)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, next, again)
+
+	_, _, _, err = l.List(ctx, "tenant/bucket", "not-a-cursor", 0)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestLogPruneExpiresAndBoundsEvents(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLog(t)
+	l.SetSettingsManager(fakeSettings{
+		"storage.event_log_retention_hours": 1,
+		"storage.event_log_max_per_bucket":  100,
+	})
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	require.NoError(t, l.Append(ctx, "old", Event{Key: "expired"}))
+	now = now.Add(2 * time.Hour)
+
+	// Appending 150 events trims the bucket once it passes the cap by a tenth
+	for i := 0; i < 150; i++ {
+		require.NoError(t, l.Append(ctx, "busy", Event{Key: fmt.Sprintf("k%03d", i)}))
+	}
+	events, _, _, err := l.List(ctx, "busy", "", MaxListLimit)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(events), 110)
+
+	n, err := l.Prune(ctx)
+	require.NoError(t, err)
+	assert.Greater(t, n, 0)
+
+	events, _, _, err = l.List(ctx, "busy", "", MaxListLimit)
+	require.NoError(t, err)
+	require.Len(t, events, 100)
+	assert.Equal(t, "k050", events[0].Key, "the oldest events are dropped")
+	assert.Equal(t, "k149", events[99].Key)
+
+	events, _, _, err = l.List(ctx, "old", "", 0)
+	require.NoError(t, err)
+	assert.Empty(t, events, "events past the retention period expire")
+}
+
+// stallingKV holds the write of the first event whose key is stall until
+// release is closed.
+type stallingKV struct {
+	metadata.RawKVStore
+	stall   string
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (kv *stallingKV) PutRaw(ctx context.Context, key string, value []byte) error {
+	if strings.Contains(string(value), `"key":"`+kv.stall+`"`) {
+		kv.once.Do(func() {
+			close(kv.entered)
+			<-kv.release
+		})
+	}
+	return kv.RawKVStore.PutRaw(ctx, key, value)
+}
+
+// TestLogAppendVisibleInSequenceOrder stalls the write of one event while a
+// later one is appended, and checks a poller in between can't skip the
+// stalled event.
+func TestLogAppendVisibleInSequenceOrder(t *testing.T) {
+	ctx := context.Background()
+	store, err := metadata.NewPebbleStore(metadata.PebbleOptions{DataDir: t.TempDir()})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	kv := &stallingKV{RawKVStore: store, stall: "first", entered: make(chan struct{}), release: make(chan struct{})}
+	l := New(kv)
+
+	_, cursor, _, err := l.List(ctx, "tenant/bucket", "", 0)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, l.Append(ctx, "tenant/bucket", Event{EventName: "s3:ObjectCreated:Put", Key: "first"}))
+	}()
+	<-kv.entered
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, l.Append(ctx, "tenant/bucket", Event{EventName: "s3:ObjectCreated:Put", Key: "second"}))
+	}()
+
+	// Give the second append time to commit if nothing holds it back
+	time.Sleep(50 * time.Millisecond)
+	events, cursor, _, err := l.List(ctx, "tenant/bucket", cursor, 0)
+	require.NoError(t, err)
+	seen := eventKeys(events)
+
+	close(kv.release)
+	wg.Wait()
+	events, _, _, err = l.List(ctx, "tenant/bucket", cursor, 0)
+	require.NoError(t, err)
+	seen = append(seen, eventKeys(events)...)
+	assert.Equal(t, []string{"first", "second"}, seen)
+}
@@ -3,39 +3,47 @@ package inventory
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/maxiofs/maxiofs/internal/bucket"
 	"github.com/maxiofs/maxiofs/internal/metadata"
-	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/sirupsen/logrus"
 )
 
+// ObjectWriter stores generated reports as objects; object.Manager satisfies
+// it. Going through the object manager keeps reports visible to GET and LIST
+// whatever the on-disk key layout.
+type ObjectWriter interface {
+	PutObject(ctx context.Context, bucket, key string, data io.Reader, headers http.Header) (*object.Object, error)
+}
+
 // ReportGenerator generates inventory reports
 type ReportGenerator struct {
-	bucketManager  bucket.Manager
-	metadataStore  metadata.Store
-	storageBackend storage.Backend
-	log            *logrus.Entry
+	bucketManager bucket.Manager
+	metadataStore metadata.Store
+	objects       ObjectWriter
+	log           *logrus.Entry
 }
 
 // NewReportGenerator creates a new report generator
 func NewReportGenerator(
 	bucketManager bucket.Manager,
 	metadataStore metadata.Store,
-	storageBackend storage.Backend,
+	objects ObjectWriter,
 ) *ReportGenerator {
 	return &ReportGenerator{
-		bucketManager:  bucketManager,
-		metadataStore:  metadataStore,
-		storageBackend: storageBackend,
-		log:            logrus.WithField("component", "inventory_generator"),
+		bucketManager: bucketManager,
+		metadataStore: metadataStore,
+		objects:       objects,
+		log:           logrus.WithField("component", "inventory_generator"),
 	}
 }
 
@@ -320,40 +328,16 @@ func (g *ReportGenerator) uploadReport(ctx context.Context, config *InventoryCon
 		return fmt.Errorf("destination bucket not found: %w", err)
 	}
 
-	// Create object metadata
-	objMetadata := make(map[string]string)
-	objMetadata["x-amz-meta-generated-by"] = "maxiofs-inventory"
-	objMetadata["x-amz-meta-source-bucket"] = config.BucketName
+	headers := make(http.Header)
+	headers.Set("Content-Type", g.getContentType(config.Format))
+	headers.Set("Content-Length", strconv.Itoa(len(content)))
+	headers.Set("X-Amz-Meta-Generated-By", "maxiofs-inventory")
+	headers.Set("X-Amz-Meta-Source-Bucket", config.BucketName)
 
 	destinationBucketPath := inventoryBucketPath(config.TenantID, config.DestinationBucket)
-	obj := &metadata.ObjectMetadata{
-		Bucket:       destinationBucketPath,
-		Key:          reportPath,
-		Size:         int64(len(content)),
-		ContentType:  g.getContentType(config.Format),
-		LastModified: time.Now().UTC(),
-		ETag:         fmt.Sprintf("%x", md5.Sum(content)),
-		StorageClass: "STANDARD",
-		Metadata:     objMetadata,
-	}
-
-	// Upload to storage
-	storagePath := fmt.Sprintf("%s/%s", destinationBucketPath, reportPath)
-	reader := bytes.NewReader(content)
-
-	if err := g.storageBackend.Put(ctx, storagePath, reader, objMetadata); err != nil {
-		return fmt.Errorf("failed to upload report to storage: %w", err)
+	if _, err := g.objects.PutObject(ctx, destinationBucketPath, reportPath, bytes.NewReader(content), headers); err != nil {
+		return fmt.Errorf("failed to upload report: %w", err)
 	}
-
-	// Save metadata after the object is durable. If metadata persistence fails,
-	// remove the uploaded object to avoid orphaned inventory reports.
-	if err := g.metadataStore.PutObject(ctx, obj); err != nil {
-		if deleteErr := g.storageBackend.Delete(ctx, storagePath); deleteErr != nil {
-			g.log.WithError(deleteErr).WithField("path", storagePath).Warn("Failed to remove uploaded inventory report after metadata error")
-		}
-		return fmt.Errorf("failed to save report metadata: %w", err)
-	}
-
 	return nil
 }
 
@@ -368,6 +352,3 @@ func (g *ReportGenerator) getContentType(format string) string {
 		return "application/octet-stream"
 	}
 }
-
-// Helper to read from io.ReadCloser (required for storage.Backend.Put signature)
-var _ io.Reader = (*bytes.Reader)(nil)
@@ -6,13 +6,14 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/maxiofs/maxiofs/internal/bucket"
 	"github.com/maxiofs/maxiofs/internal/metadata"
-	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -186,6 +187,36 @@ func (m *MockBucketManager) DeleteQuota(ctx context.Context, tenantID, name stri
 	return args.Error(0)
 }
 
+func (m *MockBucketManager) SetDefaultWriteLock(ctx context.Context, tenantID, name string, days int) error {
+	args := m.Called(ctx, tenantID, name, days)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetMaxVersionsPerObject(ctx context.Context, tenantID, name string, max int) error {
+	args := m.Called(ctx, tenantID, name, max)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetCacheDefaults(ctx context.Context, tenantID, name string, defaults *metadata.BucketCacheDefaults) error {
+	args := m.Called(ctx, tenantID, name, defaults)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetDefaultContentType(ctx context.Context, tenantID, name, contentType string) error {
+	args := m.Called(ctx, tenantID, name, contentType)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetScanUploads(ctx context.Context, tenantID, name string, enabled bool) error {
+	args := m.Called(ctx, tenantID, name, enabled)
+	return args.Error(0)
+}
+
+func (m *MockBucketManager) SetNoOverwrite(ctx context.Context, tenantID, name string, enabled bool) error {
+	args := m.Called(ctx, tenantID, name, enabled)
+	return args.Error(0)
+}
+
 func (m *MockBucketManager) GetBucketACL(ctx context.Context, tenantID, name string) (interface{}, error) {
 	args := m.Called(ctx, tenantID, name)
 	return args.Get(0), args.Error(1)
@@ -348,6 +379,14 @@ func (m *MockMetadataStore) ListObjects(ctx context.Context, bucket, prefix, mar
 	return args.Get(0).([]*metadata.ObjectMetadata), args.String(1), args.Error(2)
 }
 
+func (m *MockMetadataStore) ListObjectsReverse(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*metadata.DelimitedListResult, error) {
+	args := m.Called(ctx, bucket, prefix, delimiter, marker, maxKeys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*metadata.DelimitedListResult), args.Error(1)
+}
+
 func (m *MockMetadataStore) ListObjectsDelimited(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*metadata.DelimitedListResult, error) {
 	args := m.Called(ctx, bucket, prefix, delimiter, marker, maxKeys)
 	if args.Get(0) == nil {
@@ -361,6 +400,16 @@ func (m *MockMetadataStore) ObjectExists(ctx context.Context, bucket, key string
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockMetadataStore) CountMultipartUploads(ctx context.Context, tenantID, bucket string) (int, error) {
+	args := m.Called(ctx, tenantID, bucket)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMetadataStore) RenameObject(ctx context.Context, srcKey string, dst, srcMarker *metadata.ObjectMetadata) error {
+	args := m.Called(ctx, srcKey, dst, srcMarker)
+	return args.Error(0)
+}
+
 func (m *MockMetadataStore) PutObjectVersion(ctx context.Context, obj *metadata.ObjectMetadata, version *metadata.ObjectVersion) error {
 	args := m.Called(ctx, obj, version)
 	return args.Error(0)
@@ -475,6 +524,11 @@ func (m *MockMetadataStore) GetBucketStats(ctx context.Context, tenantID, bucket
 	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockMetadataStore) GetBucketSequence(ctx context.Context, bucket string) (uint64, error) {
+	args := m.Called(ctx, bucket)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
 func (m *MockMetadataStore) RecalculateBucketStats(ctx context.Context, tenantID, bucket string) error {
 	args := m.Called(ctx, tenantID, bucket)
 	return args.Error(0)
@@ -513,58 +567,17 @@ func (m *MockMetadataStore) HasActiveComplianceRetention(ctx context.Context, bu
 	return args.Bool(0), args.Error(1)
 }
 
-// Mock StorageBackend
-type MockStorageBackend struct {
+// Mock ObjectWriter
+type MockObjectWriter struct {
 	mock.Mock
 }
 
-func (m *MockStorageBackend) Put(ctx context.Context, path string, data io.Reader, meta map[string]string) error {
-	args := m.Called(ctx, path, data, meta)
-	return args.Error(0)
-}
-
-func (m *MockStorageBackend) Get(ctx context.Context, path string) (io.ReadCloser, map[string]string, error) {
-	args := m.Called(ctx, path)
-	if args.Get(0) == nil {
-		return nil, nil, args.Error(2)
-	}
-	return args.Get(0).(io.ReadCloser), args.Get(1).(map[string]string), args.Error(2)
-}
-
-func (m *MockStorageBackend) Delete(ctx context.Context, path string) error {
-	args := m.Called(ctx, path)
-	return args.Error(0)
-}
-
-func (m *MockStorageBackend) Exists(ctx context.Context, path string) (bool, error) {
-	args := m.Called(ctx, path)
-	return args.Bool(0), args.Error(1)
-}
-
-func (m *MockStorageBackend) List(ctx context.Context, prefix string, recursive bool) ([]storage.ObjectInfo, error) {
-	args := m.Called(ctx, prefix, recursive)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).([]storage.ObjectInfo), args.Error(1)
-}
-
-func (m *MockStorageBackend) GetMetadata(ctx context.Context, path string) (map[string]string, error) {
-	args := m.Called(ctx, path)
+func (m *MockObjectWriter) PutObject(ctx context.Context, bucket, key string, data io.Reader, headers http.Header) (*object.Object, error) {
+	args := m.Called(ctx, bucket, key, data, headers)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(map[string]string), args.Error(1)
-}
-
-func (m *MockStorageBackend) SetMetadata(ctx context.Context, path string, meta map[string]string) error {
-	args := m.Called(ctx, path, meta)
-	return args.Error(0)
-}
-
-func (m *MockStorageBackend) Close() error {
-	args := m.Called()
-	return args.Error(0)
+	return args.Get(0).(*object.Object), args.Error(1)
 }
 
 func init() {
@@ -576,9 +589,9 @@ func init() {
 func TestGenerateReport_DestinationBucketNotFound(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-1",
@@ -632,9 +645,9 @@ func TestGenerateReport_DestinationBucketNotFound(t *testing.T) {
 func TestGenerateReport_DestinationBucketNoWritePermission(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-2",
@@ -674,8 +687,8 @@ func TestGenerateReport_DestinationBucketNoWritePermission(t *testing.T) {
 	}, nil).Once()
 
 	// Mock write permission denied (storage backend returns permission error)
-	mockStorage.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("map[string]string")).Return(
-		errors.New("permission denied: write access forbidden"),
+	mockObjects.On("PutObject", ctx, "tenant1/readonly-bucket", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("http.Header")).Return(
+		nil, errors.New("permission denied: write access forbidden"),
 	).Once()
 
 	// Execute
@@ -688,16 +701,16 @@ func TestGenerateReport_DestinationBucketNoWritePermission(t *testing.T) {
 
 	mockBucketMgr.AssertExpectations(t)
 	mockMetadata.AssertExpectations(t)
-	mockStorage.AssertExpectations(t)
+	mockObjects.AssertExpectations(t)
 }
 
 // TestGenerateReport_CSV_Success tests successful CSV report generation
 func TestGenerateReport_CSV_Success(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-3",
@@ -744,16 +757,13 @@ func TestGenerateReport_CSV_Success(t *testing.T) {
 		TenantID: "tenant1",
 	}, nil).Once()
 
-	// Mock metadata update
-	mockMetadata.On("PutObject", ctx, mock.AnythingOfType("*metadata.ObjectMetadata")).Return(nil).Once()
-
 	// Mock successful upload
 	var capturedContent []byte
-	mockStorage.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("map[string]string")).
+	mockObjects.On("PutObject", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("http.Header")).
 		Run(func(args mock.Arguments) {
-			reader := args.Get(2).(io.Reader)
+			reader := args.Get(3).(io.Reader)
 			capturedContent, _ = io.ReadAll(reader)
-		}).Return(nil).Once()
+		}).Return(&object.Object{}, nil).Once()
 
 	// Execute
 	report, err := generator.GenerateReport(ctx, config)
@@ -782,16 +792,16 @@ func TestGenerateReport_CSV_Success(t *testing.T) {
 
 	mockBucketMgr.AssertExpectations(t)
 	mockMetadata.AssertExpectations(t)
-	mockStorage.AssertExpectations(t)
+	mockObjects.AssertExpectations(t)
 }
 
 // TestGenerateReport_JSON_Success tests successful JSON report generation
 func TestGenerateReport_JSON_Success(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-4",
@@ -830,16 +840,13 @@ func TestGenerateReport_JSON_Success(t *testing.T) {
 		TenantID: "tenant1",
 	}, nil).Once()
 
-	// Mock metadata update
-	mockMetadata.On("PutObject", ctx, mock.AnythingOfType("*metadata.ObjectMetadata")).Return(nil).Once()
-
 	// Mock successful upload
 	var capturedContent []byte
-	mockStorage.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("map[string]string")).
+	mockObjects.On("PutObject", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("http.Header")).
 		Run(func(args mock.Arguments) {
-			reader := args.Get(2).(io.Reader)
+			reader := args.Get(3).(io.Reader)
 			capturedContent, _ = io.ReadAll(reader)
-		}).Return(nil).Once()
+		}).Return(&object.Object{}, nil).Once()
 
 	// Execute
 	report, err := generator.GenerateReport(ctx, config)
@@ -864,16 +871,16 @@ func TestGenerateReport_JSON_Success(t *testing.T) {
 
 	mockBucketMgr.AssertExpectations(t)
 	mockMetadata.AssertExpectations(t)
-	mockStorage.AssertExpectations(t)
+	mockObjects.AssertExpectations(t)
 }
 
 // TestGenerateReport_EmptyBucket tests report generation for empty bucket
 func TestGenerateReport_EmptyBucket(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-5",
@@ -902,16 +909,13 @@ func TestGenerateReport_EmptyBucket(t *testing.T) {
 		TenantID: "tenant1",
 	}, nil).Once()
 
-	// Mock metadata update
-	mockMetadata.On("PutObject", ctx, mock.AnythingOfType("*metadata.ObjectMetadata")).Return(nil).Once()
-
 	// Mock successful upload (empty report)
 	var capturedContent []byte
-	mockStorage.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("map[string]string")).
+	mockObjects.On("PutObject", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("http.Header")).
 		Run(func(args mock.Arguments) {
-			reader := args.Get(2).(io.Reader)
+			reader := args.Get(3).(io.Reader)
 			capturedContent, _ = io.ReadAll(reader)
-		}).Return(nil).Once()
+		}).Return(&object.Object{}, nil).Once()
 
 	// Execute
 	report, err := generator.GenerateReport(ctx, config)
@@ -930,16 +934,16 @@ func TestGenerateReport_EmptyBucket(t *testing.T) {
 
 	mockBucketMgr.AssertExpectations(t)
 	mockMetadata.AssertExpectations(t)
-	mockStorage.AssertExpectations(t)
+	mockObjects.AssertExpectations(t)
 }
 
 // TestGenerateReport_InvalidFormat tests error handling for invalid format
 func TestGenerateReport_InvalidFormat(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-6",
@@ -984,9 +988,9 @@ func TestGenerateReport_InvalidFormat(t *testing.T) {
 func TestGenerateReport_WithEncryptionStatus(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-7",
@@ -1030,16 +1034,13 @@ func TestGenerateReport_WithEncryptionStatus(t *testing.T) {
 		TenantID: "tenant1",
 	}, nil).Once()
 
-	// Mock metadata update
-	mockMetadata.On("PutObject", ctx, mock.AnythingOfType("*metadata.ObjectMetadata")).Return(nil).Once()
-
 	// Mock successful upload
 	var capturedContent []byte
-	mockStorage.On("Put", ctx, mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("map[string]string")).
+	mockObjects.On("PutObject", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("http.Header")).
 		Run(func(args mock.Arguments) {
-			reader := args.Get(2).(io.Reader)
+			reader := args.Get(3).(io.Reader)
 			capturedContent, _ = io.ReadAll(reader)
-		}).Return(nil).Once()
+		}).Return(&object.Object{}, nil).Once()
 
 	// Execute
 	report, err := generator.GenerateReport(ctx, config)
@@ -1064,16 +1065,16 @@ func TestGenerateReport_WithEncryptionStatus(t *testing.T) {
 
 	mockBucketMgr.AssertExpectations(t)
 	mockMetadata.AssertExpectations(t)
-	mockStorage.AssertExpectations(t)
+	mockObjects.AssertExpectations(t)
 }
 
-// TestGenerateReport_MetadataUpdateFailure tests handling of metadata update failures
-func TestGenerateReport_MetadataUpdateFailure(t *testing.T) {
+// TestGenerateReport_ObjectWriteFailure tests handling of object write failures
+func TestGenerateReport_ObjectWriteFailure(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-8",
@@ -1108,39 +1109,28 @@ func TestGenerateReport_MetadataUpdateFailure(t *testing.T) {
 		TenantID: "tenant1",
 	}, nil).Once()
 
-	mockStorage.On("Put", ctx, mock.MatchedBy(func(path string) bool {
-		return strings.HasPrefix(path, "tenant1/dest-bucket/reports/inventory-") && strings.HasSuffix(path, ".csv")
-	}), mock.Anything, mock.AnythingOfType("map[string]string")).Return(nil).Once()
-
-	mockMetadata.On("PutObject", ctx, mock.MatchedBy(func(obj *metadata.ObjectMetadata) bool {
-		return obj != nil &&
-			obj.Bucket == "tenant1/dest-bucket" &&
-			strings.HasPrefix(obj.Key, "reports/inventory-") &&
-			strings.HasSuffix(obj.Key, ".csv")
-	})).Return(errors.New("database error")).Once()
-
-	mockStorage.On("Delete", ctx, mock.MatchedBy(func(path string) bool {
-		return strings.HasPrefix(path, "tenant1/dest-bucket/reports/inventory-") && strings.HasSuffix(path, ".csv")
-	})).Return(nil).Once()
+	mockObjects.On("PutObject", ctx, "tenant1/dest-bucket", mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "reports/inventory-") && strings.HasSuffix(key, ".csv")
+	}), mock.Anything, mock.AnythingOfType("http.Header")).Return(nil, errors.New("database error")).Once()
 
 	// Execute
 	_, err := generator.GenerateReport(ctx, config)
 
-	// Verify - should fail because metadata update failed
+	// Verify - should fail because the report could not be stored
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "database error")
 
 	mockBucketMgr.AssertExpectations(t)
 	mockMetadata.AssertExpectations(t)
-	mockStorage.AssertExpectations(t)
+	mockObjects.AssertExpectations(t)
 }
 
 func TestGenerateReport_UsesTenantScopedPaths(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-tenant-paths",
@@ -1169,16 +1159,9 @@ func TestGenerateReport_UsesTenantScopedPaths(t *testing.T) {
 		TenantID: "tenant1",
 	}, nil).Once()
 
-	mockStorage.On("Put", ctx, mock.MatchedBy(func(path string) bool {
-		return strings.HasPrefix(path, "tenant1/dest-bucket/reports/inventory-") && strings.HasSuffix(path, ".csv")
-	}), mock.Anything, mock.AnythingOfType("map[string]string")).Return(nil).Once()
-
-	mockMetadata.On("PutObject", ctx, mock.MatchedBy(func(obj *metadata.ObjectMetadata) bool {
-		return obj != nil &&
-			obj.Bucket == "tenant1/dest-bucket" &&
-			strings.HasPrefix(obj.Key, "reports/inventory-") &&
-			strings.HasSuffix(obj.Key, ".csv")
-	})).Return(nil).Once()
+	mockObjects.On("PutObject", ctx, "tenant1/dest-bucket", mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "reports/inventory-") && strings.HasSuffix(key, ".csv")
+	}), mock.Anything, mock.AnythingOfType("http.Header")).Return(&object.Object{}, nil).Once()
 
 	report, err := generator.GenerateReport(ctx, config)
 
@@ -1187,15 +1170,15 @@ func TestGenerateReport_UsesTenantScopedPaths(t *testing.T) {
 
 	mockBucketMgr.AssertExpectations(t)
 	mockMetadata.AssertExpectations(t)
-	mockStorage.AssertExpectations(t)
+	mockObjects.AssertExpectations(t)
 }
 
 func TestGenerateReport_GlobalBucketPathsRemainUnscoped(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		ID:                "test-inventory-global-paths",
@@ -1223,16 +1206,9 @@ func TestGenerateReport_GlobalBucketPathsRemainUnscoped(t *testing.T) {
 		Name: "dest-bucket",
 	}, nil).Once()
 
-	mockStorage.On("Put", ctx, mock.MatchedBy(func(path string) bool {
-		return strings.HasPrefix(path, "dest-bucket/reports/inventory-") && strings.HasSuffix(path, ".csv")
-	}), mock.Anything, mock.AnythingOfType("map[string]string")).Return(nil).Once()
-
-	mockMetadata.On("PutObject", ctx, mock.MatchedBy(func(obj *metadata.ObjectMetadata) bool {
-		return obj != nil &&
-			obj.Bucket == "dest-bucket" &&
-			strings.HasPrefix(obj.Key, "reports/inventory-") &&
-			strings.HasSuffix(obj.Key, ".csv")
-	})).Return(nil).Once()
+	mockObjects.On("PutObject", ctx, "dest-bucket", mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "reports/inventory-") && strings.HasSuffix(key, ".csv")
+	}), mock.Anything, mock.AnythingOfType("http.Header")).Return(&object.Object{}, nil).Once()
 
 	report, err := generator.GenerateReport(ctx, config)
 
@@ -1241,16 +1217,16 @@ func TestGenerateReport_GlobalBucketPathsRemainUnscoped(t *testing.T) {
 
 	mockBucketMgr.AssertExpectations(t)
 	mockMetadata.AssertExpectations(t)
-	mockStorage.AssertExpectations(t)
+	mockObjects.AssertExpectations(t)
 }
 
 // TestCollectInventoryItems_Pagination tests object collection with pagination
 func TestCollectInventoryItems_Pagination(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		BucketName: "large-bucket",
@@ -1289,9 +1265,9 @@ func TestCollectInventoryItems_Pagination(t *testing.T) {
 func TestCollectInventoryItems_ListError(t *testing.T) {
 	mockBucketMgr := new(MockBucketManager)
 	mockMetadata := new(MockMetadataStore)
-	mockStorage := new(MockStorageBackend)
+	mockObjects := new(MockObjectWriter)
 
-	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockStorage)
+	generator := NewReportGenerator(mockBucketMgr, mockMetadata, mockObjects)
 
 	config := &InventoryConfig{
 		BucketName: "error-bucket",
@@ -7,7 +7,6 @@ import (
 
 	"github.com/maxiofs/maxiofs/internal/bucket"
 	"github.com/maxiofs/maxiofs/internal/metadata"
-	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -26,11 +25,11 @@ func NewWorker(
 	manager *Manager,
 	bucketManager bucket.Manager,
 	metadataStore metadata.Store,
-	storageBackend storage.Backend,
+	objects ObjectWriter,
 ) *Worker {
 	return &Worker{
 		manager:       manager,
-		generator:     NewReportGenerator(bucketManager, metadataStore, storageBackend),
+		generator:     NewReportGenerator(bucketManager, metadataStore, objects),
 		bucketManager: bucketManager,
 		stopChan:      make(chan struct{}),
 		log:           logrus.WithField("component", "inventory_worker"),
@@ -57,6 +57,9 @@ type Store struct {
 	keys          map[int][]byte
 	clusterShared map[int]bool
 	current       int
+	// tenantKeys caches each tenant's unwrapped keys, loaded on first use
+	// (see tenant_keys.go).
+	tenantKeys map[string]*tenantKeyring
 	// writeMu serialises multi-step mutations (Rotate, EnsureClusterKey,
 	// AdoptClusterKeys): two concurrent rotations would otherwise compute the
 	// same next version and collide on the UNIQUE constraint with a cryptic
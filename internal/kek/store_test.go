@@ -305,3 +305,56 @@ func TestConcurrentRotations(t *testing.T) {
 	_, current := store.CurrentKEK()
 	assert.True(t, seen[current], "current must be one of the rotated versions")
 }
+
+func TestTenantKeys(t *testing.T) {
+	db := createTestDB(t)
+	_, err := db.Exec(`
+		CREATE TABLE tenant_encryption_keys (
+			tenant_id TEXT NOT NULL, version INTEGER NOT NULL,
+			wrapped_key_hex TEXT NOT NULL, kek_version INTEGER NOT NULL,
+			is_current INTEGER NOT NULL DEFAULT 0, created_at INTEGER NOT NULL,
+			PRIMARY KEY (tenant_id, version)
+		)
+	`)
+	require.NoError(t, err)
+	store, err := Bootstrap(db, "")
+	require.NoError(t, err)
+
+	keyA, versionA, err := store.CurrentTenantKey("tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, versionA)
+	assert.Len(t, keyA, 32)
+	keyB, _, err := store.CurrentTenantKey("tenant-b")
+	require.NoError(t, err)
+	assert.NotEqual(t, keyA, keyB, "tenants must not share a key")
+
+	// Stored wrapped by the KEK, never in the clear.
+	var wrappedHex string
+	require.NoError(t, db.QueryRow(`SELECT wrapped_key_hex FROM tenant_encryption_keys WHERE tenant_id = 'tenant-a'`).Scan(&wrappedHex))
+	assert.NotContains(t, wrappedHex, hex.EncodeToString(keyA))
+
+	// A KEK rotation does not strand tenant keys wrapped by the old version.
+	_, err = store.Rotate(false)
+	require.NoError(t, err)
+
+	next, err := store.RotateTenantKey("tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, next)
+
+	// Restart: both versions load, v2 is current, tenant B is untouched.
+	store2, err := Bootstrap(db, "")
+	require.NoError(t, err)
+	_, current, err := store2.CurrentTenantKey("tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, current)
+	old, err := store2.TenantKeyByVersion("tenant-a", 1)
+	require.NoError(t, err)
+	assert.Equal(t, keyA, old)
+	reloadedB, versionB, err := store2.CurrentTenantKey("tenant-b")
+	require.NoError(t, err)
+	assert.Equal(t, 1, versionB)
+	assert.Equal(t, keyB, reloadedB)
+
+	_, err = store2.TenantKeyByVersion("tenant-b", 2)
+	assert.ErrorIs(t, err, ErrTenantKeyNotFound)
+}
@@ -0,0 +1,326 @@
+package kek
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrTenantKeyNotFound marks a tenant key version this node does not hold.
+var ErrTenantKeyNotFound = errors.New("tenant encryption key not found")
+
+// TenantKeyProvider supplies per-tenant keys. Objects in a tenant's buckets
+// have their DEK wrapped by the tenant's key rather than by the KEK directly,
+// so the key of one tenant never opens another tenant's objects. Tenant keys
+// are themselves stored wrapped by the KEK (tenant_encryption_keys table,
+// created by migration 19).
+type TenantKeyProvider interface {
+	// CurrentTenantKey returns the key used to wrap DEKs for the tenant's new
+	// objects, creating version 1 the first time the tenant writes.
+	CurrentTenantKey(tenantID string) (key []byte, version int, err error)
+	// TenantKeyByVersion returns a specific version of a tenant's key.
+	TenantKeyByVersion(tenantID string, version int) ([]byte, error)
+	// RotateTenantKey creates the tenant's next key version and makes it
+	// current. Older versions are kept so existing objects stay readable.
+	RotateTenantKey(tenantID string) (int, error)
+}
+
+// tenantKeyring holds the unwrapped versions of one tenant's key.
+type tenantKeyring struct {
+	keys    map[int][]byte
+	current int
+}
+
+// CurrentTenantKey returns the tenant's current key, creating it on first use.
+func (s *Store) CurrentTenantKey(tenantID string) ([]byte, int, error) {
+	if tenantID == "" {
+		return nil, 0, fmt.Errorf("tenant ID is required")
+	}
+	s.mu.RLock()
+	ring, ok := s.tenantKeys[tenantID]
+	if ok && ring.current != 0 {
+		key, version := ring.keys[ring.current], ring.current
+		s.mu.RUnlock()
+		return key, version, nil
+	}
+	s.mu.RUnlock()
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	ring, err := s.loadTenantKeys(tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ring.current == 0 {
+		if _, err := s.insertTenantKey(tenantID, 1, ring); err != nil {
+			return nil, 0, fmt.Errorf("failed to create encryption key for tenant %s: %w", tenantID, err)
+		}
+		logrus.WithField("tenant_id", tenantID).Info("Tenant encryption key created")
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return ring.keys[ring.current], ring.current, nil
+}
+
+// TenantKeyByVersion returns a specific version of a tenant's key.
+func (s *Store) TenantKeyByVersion(tenantID string, version int) ([]byte, error) {
+	s.mu.RLock()
+	if ring, ok := s.tenantKeys[tenantID]; ok {
+		key, found := ring.keys[version]
+		s.mu.RUnlock()
+		if !found {
+			return nil, fmt.Errorf("%w: tenant %s version %d", ErrTenantKeyNotFound, tenantID, version)
+		}
+		return key, nil
+	}
+	s.mu.RUnlock()
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	ring, err := s.loadTenantKeys(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, found := ring.keys[version]
+	if !found {
+		return nil, fmt.Errorf("%w: tenant %s version %d", ErrTenantKeyNotFound, tenantID, version)
+	}
+	return key, nil
+}
+
+// RotateTenantKey creates the tenant's next key version and makes it current.
+// Like Rotate, old versions are never deleted: the background worker
+// re-wraps the tenant's DEKs to the new version over time.
+func (s *Store) RotateTenantKey(tenantID string) (int, error) {
+	if tenantID == "" {
+		return 0, fmt.Errorf("tenant ID is required")
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	ring, err := s.loadTenantKeys(tenantID)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.RLock()
+	next := 1
+	for version := range ring.keys {
+		if version >= next {
+			next = version + 1
+		}
+	}
+	s.mu.RUnlock()
+
+	if _, err := s.insertTenantKey(tenantID, next, ring); err != nil {
+		return 0, fmt.Errorf("failed to rotate encryption key for tenant %s: %w", tenantID, err)
+	}
+	logrus.WithFields(logrus.Fields{"tenant_id": tenantID, "tenant_key_version": next}).
+		Info("✅ Tenant encryption key rotated — the worker re-wraps the tenant's existing DEKs")
+	return next, nil
+}
+
+// loadTenantKeys returns the tenant's keyring, reading it from the DB the
+// first time. Caller holds writeMu.
+func (s *Store) loadTenantKeys(tenantID string) (*tenantKeyring, error) {
+	s.mu.RLock()
+	ring, ok := s.tenantKeys[tenantID]
+	s.mu.RUnlock()
+	if ok {
+		return ring, nil
+	}
+
+	rows, err := s.db.Query(
+		`SELECT version, wrapped_key_hex, kek_version, is_current FROM tenant_encryption_keys WHERE tenant_id = ?`,
+		tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant encryption keys: %w", err)
+	}
+	defer rows.Close()
+
+	ring = &tenantKeyring{keys: make(map[int][]byte)}
+	for rows.Next() {
+		var version, kekVersion, isCurrent int
+		var wrappedHex string
+		if err := rows.Scan(&version, &wrappedHex, &kekVersion, &isCurrent); err != nil {
+			return nil, err
+		}
+		kekKey, err := s.KEKByVersion(kekVersion)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s key version %d: %w", tenantID, version, err)
+		}
+		key, err := unwrapKey(wrappedHex, kekKey)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s key version %d is corrupt: %w", tenantID, version, err)
+		}
+		ring.keys[version] = key
+		if isCurrent == 1 {
+			ring.current = version
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.tenantKeys == nil {
+		s.tenantKeys = make(map[string]*tenantKeyring)
+	}
+	s.tenantKeys[tenantID] = ring
+	s.mu.Unlock()
+	return ring, nil
+}
+
+// insertTenantKey generates a key, persists it wrapped by the current KEK as
+// the tenant's current version and registers it in ring. Caller holds writeMu.
+func (s *Store) insertTenantKey(tenantID string, version int, ring *tenantKeyring) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate tenant key: %w", err)
+	}
+	kekKey, kekVersion := s.CurrentKEK()
+	wrappedHex, err := wrapKey(key, kekKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE tenant_encryption_keys SET is_current = 0 WHERE tenant_id = ?`, tenantID); err != nil {
+		tx.Rollback() //nolint:errcheck
+		return nil, err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO tenant_encryption_keys (tenant_id, version, wrapped_key_hex, kek_version, is_current, created_at) VALUES (?, ?, ?, ?, 1, ?)`,
+		tenantID, version, wrappedHex, kekVersion, time.Now().Unix(),
+	); err != nil {
+		tx.Rollback() //nolint:errcheck
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	ring.keys[version] = key
+	ring.current = version
+	s.mu.Unlock()
+	return key, nil
+}
+
+// wrapKey seals key with AES-256-GCM under kekKey and returns nonce||sealed
+// as hex.
+func wrapKey(key, kekKey []byte) (string, error) {
+	gcm, err := newGCM(kekKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(gcm.Seal(nonce, nonce, key, nil)), nil
+}
+
+// unwrapKey reverses wrapKey.
+func unwrapKey(wrappedHex string, kekKey []byte) ([]byte, error) {
+	wrapped, err := hex.DecodeString(wrappedHex)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(kekKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+	nonce, sealed := wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EphemeralTenantKeys returns an in-memory TenantKeyProvider generating a
+// random key per tenant. Used by tests; keys do not survive a restart.
+func EphemeralTenantKeys() TenantKeyProvider {
+	return &ephemeralTenantKeys{rings: make(map[string]*tenantKeyring)}
+}
+
+type ephemeralTenantKeys struct {
+	mu    sync.Mutex
+	rings map[string]*tenantKeyring
+}
+
+func (p *ephemeralTenantKeys) CurrentTenantKey(tenantID string) ([]byte, int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ring, ok := p.rings[tenantID]
+	if !ok {
+		ring = &tenantKeyring{keys: make(map[int][]byte)}
+		p.rings[tenantID] = ring
+	}
+	if ring.current == 0 {
+		if err := p.addVersion(ring, 1); err != nil {
+			return nil, 0, err
+		}
+	}
+	return ring.keys[ring.current], ring.current, nil
+}
+
+func (p *ephemeralTenantKeys) TenantKeyByVersion(tenantID string, version int) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ring, ok := p.rings[tenantID]; ok {
+		if key, found := ring.keys[version]; found {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: tenant %s version %d", ErrTenantKeyNotFound, tenantID, version)
+}
+
+func (p *ephemeralTenantKeys) RotateTenantKey(tenantID string) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ring, ok := p.rings[tenantID]
+	if !ok {
+		ring = &tenantKeyring{keys: make(map[int][]byte)}
+		p.rings[tenantID] = ring
+	}
+	next := ring.current + 1
+	if err := p.addVersion(ring, next); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+func (p *ephemeralTenantKeys) addVersion(ring *tenantKeyring, version int) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	ring.keys[version] = key
+	ring.current = version
+	return nil
+}
+
+// Compile-time check: the DB-backed store serves tenant keys.
+var _ TenantKeyProvider = (*Store)(nil)
@@ -282,12 +282,12 @@ func TestAbortIncompleteMultipart_AllFresh(t *testing.T) {
 }
 
 // ============================================================
-// processLifecycleRule integration: all sub-rules in one rule
+// processBucket integration: all sub-rules in one rule
 // ============================================================
 
-// TestProcessLifecycleRule_ExpirationAndAbort tests that a rule with both
+// TestProcessBucket_ExpirationAndAbort tests that a rule with both
 // expiration and abort incomplete multipart triggers both handlers.
-func TestProcessLifecycleRule_ExpirationAndAbort(t *testing.T) {
+func TestProcessBucket_ExpirationAndAbort(t *testing.T) {
 	days := 5
 	staleObj := time.Now().UTC().AddDate(0, 0, -10)
 	staleUpload := time.Now().UTC().AddDate(0, 0, -10)
@@ -304,12 +304,8 @@ func TestProcessLifecycleRule_ExpirationAndAbort(t *testing.T) {
 			{UploadID: "stale-mp", Initiated: staleUpload},
 		},
 	}
-	bucketMgr := &mockBucketMgr{}
 	metaStore := &mockMetaStore{}
 
-	worker := NewWorker(bucketMgr, objMgr, metaStore)
-	ctx := context.Background()
-
 	rule := bucket.LifecycleRule{
 		ID:     "combined-rule",
 		Status: "Enabled",
@@ -321,8 +317,115 @@ func TestProcessLifecycleRule_ExpirationAndAbort(t *testing.T) {
 		},
 	}
 
-	worker.processLifecycleRule(ctx, "", "test-bucket", rule)
+	bucketMgr := &mockBucketMgr{getBucket: &bucket.Bucket{
+		Name:      "test-bucket",
+		Lifecycle: &bucket.LifecycleConfig{Rules: []bucket.LifecycleRule{rule}},
+	}}
+	worker := NewWorker(bucketMgr, objMgr, metaStore)
+	worker.processBucket(context.Background(), bucket.Bucket{Name: "test-bucket"})
 
 	assert.Equal(t, 1, objMgr.deleteCount, "Expired object should be deleted")
 	assert.Equal(t, []string{"stale-mp"}, objMgr.abortedIDs, "Stale multipart upload should be aborted")
 }
+
+// mockObjectMgrWithTags extends mockObjectMgr with per-object tags and records
+// which keys were deleted.
+type mockObjectMgrWithTags struct {
+	mockObjectMgr
+	tags        map[string][]object.Tag
+	deletedKeys []string
+}
+
+func (m *mockObjectMgrWithTags) GetObjectTagging(ctx context.Context, bucketPath, key string, versionID ...string) (*object.TagSet, error) {
+	return &object.TagSet{Tags: m.tags[key]}, nil
+}
+
+func (m *mockObjectMgrWithTags) DeleteObject(ctx context.Context, bucketPath, key string, bypassGovernance bool, versionID ...string) (string, error) {
+	m.deletedKeys = append(m.deletedKeys, key)
+	return m.mockObjectMgr.DeleteObject(ctx, bucketPath, key, bypassGovernance, versionID...)
+}
+
+// TestProcessObjectExpiration_TagFilter verifies that single-tag and And filters
+// only expire objects carrying every required tag.
+func TestProcessObjectExpiration_TagFilter(t *testing.T) {
+	days := 1
+	old := time.Now().UTC().AddDate(0, 0, -5)
+
+	newObjMgr := func() *mockObjectMgrWithTags {
+		return &mockObjectMgrWithTags{
+			mockObjectMgr: mockObjectMgr{
+				listResult: &object.ListObjectsResult{
+					Objects: []object.Object{
+						{Key: "tmp/tagged.txt", LastModified: old},
+						{Key: "tmp/tagged-other-team.txt", LastModified: old},
+						{Key: "tmp/wrong-value.txt", LastModified: old},
+						{Key: "tmp/untagged.txt", LastModified: old},
+					},
+				},
+			},
+			tags: map[string][]object.Tag{
+				"tmp/tagged.txt":            {{Key: "temp", Value: "true"}, {Key: "team", Value: "data"}},
+				"tmp/tagged-other-team.txt": {{Key: "temp", Value: "true"}, {Key: "team", Value: "web"}},
+				"tmp/wrong-value.txt":       {{Key: "temp", Value: "false"}, {Key: "team", Value: "data"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		filter   bucket.LifecycleFilter
+		expected []string
+	}{
+		{
+			name:     "single tag",
+			filter:   bucket.LifecycleFilter{Tag: &bucket.Tag{Key: "temp", Value: "true"}},
+			expected: []string{"tmp/tagged.txt", "tmp/tagged-other-team.txt"},
+		},
+		{
+			name: "prefix and tags",
+			filter: bucket.LifecycleFilter{And: &bucket.LifecycleFilterAnd{
+				Prefix: "tmp/",
+				Tags:   []bucket.Tag{{Key: "temp", Value: "true"}, {Key: "team", Value: "data"}},
+			}},
+			expected: []string{"tmp/tagged.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objMgr := newObjMgr()
+			worker := NewWorker(&mockBucketMgr{}, objMgr, &mockMetaStore{})
+
+			rule := bucket.LifecycleRule{
+				ID:         "expire-temp",
+				Status:     "Enabled",
+				Filter:     tt.filter,
+				Expiration: &bucket.LifecycleExpiration{Days: &days},
+			}
+			worker.processObjectExpiration(context.Background(), "test-bucket", rule)
+
+			assert.ElementsMatch(t, tt.expected, objMgr.deletedKeys)
+		})
+	}
+}
+
+// TestProcessAbortIncompleteMultipartUploads_SkipsTagFilter ensures a tag-filtered
+// rule never aborts uploads, which have no tags to match.
+func TestProcessAbortIncompleteMultipartUploads_SkipsTagFilter(t *testing.T) {
+	objMgr := &mockObjectMgrWithMultipart{
+		uploads: []object.MultipartUpload{
+			{UploadID: "stale", Key: "tmp/big.bin", Initiated: time.Now().UTC().AddDate(0, 0, -30)},
+		},
+	}
+	worker := NewWorker(&mockBucketMgr{}, objMgr, &mockMetaStore{})
+
+	rule := bucket.LifecycleRule{
+		ID:                             "abort-temp",
+		Status:                         "Enabled",
+		Filter:                         bucket.LifecycleFilter{Tag: &bucket.Tag{Key: "temp", Value: "true"}},
+		AbortIncompleteMultipartUpload: &bucket.LifecycleAbortIncompleteMultipartUpload{DaysAfterInitiation: 7},
+	}
+	worker.processAbortIncompleteMultipartUploads(context.Background(), "test-bucket", rule)
+
+	assert.Empty(t, objMgr.abortedIDs)
+}
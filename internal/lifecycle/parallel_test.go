@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProcessLifecyclePolicies_ExpiresAllBucketsInOneRun seeds expirable
+// objects across many buckets, some by TTL and some by a Days rule, and checks
+// that a single run with a small worker pool and several scan pages per bucket
+// deletes every one of them and records per-bucket statistics.
+func TestProcessLifecyclePolicies_ExpiresAllBucketsInOneRun(t *testing.T) {
+	_, _, bucketMgr, objectMgr := newTestStack(t)
+	ctx := context.Background()
+
+	const (
+		bucketCount = 8
+		perBucket   = 60
+	)
+	days := 1
+	oldCtx := object.WithReplicatedLastModified(ctx, time.Now().AddDate(0, 0, -10))
+	ttlHeaders := http.Header{}
+	ttlHeaders.Set(object.ExpiresAfterHeader, "1")
+
+	for b := 0; b < bucketCount; b++ {
+		name := fmt.Sprintf("bucket-%d", b)
+		require.NoError(t, bucketMgr.CreateBucket(ctx, "", name, ""))
+		require.NoError(t, bucketMgr.SetLifecycle(ctx, "", name, &bucket.LifecycleConfig{
+			Rules: []bucket.LifecycleRule{{
+				ID:         "expire-old",
+				Status:     "Enabled",
+				Filter:     bucket.LifecycleFilter{Prefix: "old/"},
+				Expiration: &bucket.LifecycleExpiration{Days: &days},
+			}},
+		}))
+		for i := 0; i < perBucket; i++ {
+			body := bytes.NewReader([]byte("data"))
+			var err error
+			if i%2 == 0 {
+				_, err = objectMgr.PutObject(ctx, name, fmt.Sprintf("tmp/%03d", i), body, ttlHeaders)
+			} else {
+				_, err = objectMgr.PutObject(oldCtx, name, fmt.Sprintf("old/%03d", i), body, http.Header{})
+			}
+			require.NoError(t, err)
+		}
+		_, err := objectMgr.PutObject(ctx, name, "keep/current", bytes.NewReader([]byte("data")), http.Header{})
+		require.NoError(t, err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+
+	worker := NewWorker(bucketMgr, objectMgr, nil)
+	worker.SetOptions(Options{Workers: 3})
+	worker.scanPageSize = 16
+	worker.processLifecyclePolicies(ctx)
+
+	stats := worker.BucketStats()
+	require.Len(t, stats, bucketCount)
+	for b := 0; b < bucketCount; b++ {
+		name := fmt.Sprintf("bucket-%d", b)
+		result, err := objectMgr.ListObjects(ctx, name, "", "", "", 1000)
+		require.NoError(t, err)
+		require.Len(t, result.Objects, 1, name)
+		assert.Equal(t, "keep/current", result.Objects[0].Key)
+
+		assert.Equal(t, int64(perBucket), stats[name].ObjectsExpired, name)
+		assert.Equal(t, int64(perBucket), stats[name].ExpiredTotal, name)
+		assert.False(t, stats[name].LastRun.IsZero(), name)
+	}
+
+	// A second run finds nothing left; the totals keep the first run's count
+	worker.processLifecyclePolicies(ctx)
+	stats = worker.BucketStats()
+	assert.Equal(t, int64(0), stats["bucket-0"].ObjectsExpired)
+	assert.Equal(t, int64(perBucket), stats["bucket-0"].ExpiredTotal)
+}
+
+func TestWorkerSetOptions(t *testing.T) {
+	worker := NewWorker(&mockBucketMgr{}, &mockObjectMgr{}, &mockMetaStore{})
+	assert.Equal(t, defaultWorkers, worker.workers)
+	assert.Nil(t, worker.scanLimiter)
+
+	worker.SetOptions(Options{Workers: 8, ScanRate: 200})
+	assert.Equal(t, 8, worker.workers)
+	require.NotNil(t, worker.scanLimiter)
+	assert.Equal(t, 200, worker.scanPageSize, "a page never exceeds one second of scanning")
+
+	// Scanning more than the burst waits for the rate
+	start := time.Now()
+	require.NoError(t, worker.throttle(context.Background(), 300))
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	worker.SetOptions(Options{})
+	assert.Equal(t, defaultWorkers, worker.workers)
+	assert.Nil(t, worker.scanLimiter)
+	assert.Equal(t, defaultScanPageSize, worker.scanPageSize)
+}
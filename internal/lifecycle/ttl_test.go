@@ -0,0 +1,118 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStack wires real bucket and object managers to a filesystem backend
+// and a Pebble metadata store in a temporary directory.
+func newTestStack(t *testing.T) (storage.Backend, metadata.Store, bucket.Manager, object.Manager) {
+	t.Helper()
+	root := t.TempDir()
+	backend, err := storage.NewFilesystemBackend(storage.Config{Root: root})
+	require.NoError(t, err)
+	metaStore, err := metadata.NewPebbleStore(metadata.PebbleOptions{
+		DataDir: filepath.Join(root, "metadata"),
+		Logger:  logrus.StandardLogger(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { metaStore.Close() })
+
+	bucketMgr := bucket.NewManager(backend, metaStore)
+	objectMgr := object.NewManager(backend, metaStore, config.StorageConfig{Backend: "filesystem", Root: root})
+	return backend, metaStore, bucketMgr, objectMgr
+}
+
+// TestProcessObjectTTLs_ExpiresAndSweeps stores objects on a real filesystem
+// backend and checks that one with a short TTL reads as missing once it
+// expires and is then deleted from disk by the worker.
+func TestProcessObjectTTLs_ExpiresAndSweeps(t *testing.T) {
+	backend, metaStore, bucketMgr, objectMgr := newTestStack(t)
+	ctx := context.Background()
+	require.NoError(t, bucketMgr.CreateBucket(ctx, "", "cache", ""))
+
+	headers := http.Header{}
+	headers.Set(object.ExpiresAfterHeader, "1")
+	_, err := objectMgr.PutObject(ctx, "cache", "session.tmp", bytes.NewReader([]byte("short-lived")), headers)
+	require.NoError(t, err)
+	_, err = objectMgr.PutObject(ctx, "cache", "keep.txt", bytes.NewReader([]byte("kept")), http.Header{})
+	require.NoError(t, err)
+
+	obj, err := objectMgr.GetObjectMetadata(ctx, "cache", "session.tmp")
+	require.NoError(t, err)
+	require.NotNil(t, obj.ExpiresAt)
+
+	time.Sleep(time.Until(*obj.ExpiresAt) + 50*time.Millisecond)
+
+	// Reads fail before the sweep has run, though the data is still stored
+	_, _, err = objectMgr.GetObject(ctx, "cache", "session.tmp")
+	assert.ErrorIs(t, err, object.ErrObjectNotFound)
+	_, err = objectMgr.GetObjectMetadata(ctx, "cache", "session.tmp")
+	assert.ErrorIs(t, err, object.ErrObjectNotFound)
+	onDisk, err := backend.Exists(ctx, "cache/session.tmp")
+	require.NoError(t, err)
+	assert.True(t, onDisk)
+
+	NewWorker(bucketMgr, objectMgr, metaStore).processLifecyclePolicies(ctx)
+
+	onDisk, err = backend.Exists(ctx, "cache/session.tmp")
+	require.NoError(t, err)
+	assert.False(t, onDisk, "expired object is removed from storage")
+	_, err = metaStore.GetObject(ctx, "cache", "session.tmp")
+	assert.ErrorIs(t, err, metadata.ErrObjectNotFound)
+
+	_, reader, err := objectMgr.GetObject(ctx, "cache", "keep.txt")
+	require.NoError(t, err, "objects without a TTL are untouched")
+	reader.Close()
+}
+
+// TestProcessObjectTTLs_VersionedBucketWritesDeleteMarker checks that expiring
+// the current version of a versioned bucket leaves a delete marker, so the
+// version written before it does not become current again.
+func TestProcessObjectTTLs_VersionedBucketWritesDeleteMarker(t *testing.T) {
+	_, metaStore, bucketMgr, objectMgr := newTestStack(t)
+	ctx := context.Background()
+	require.NoError(t, bucketMgr.CreateBucket(ctx, "", "cache", ""))
+	require.NoError(t, bucketMgr.SetVersioning(ctx, "", "cache", &bucket.VersioningConfig{Status: "Enabled"}))
+
+	_, err := objectMgr.PutObject(ctx, "cache", "session.tmp", bytes.NewReader([]byte("old")), http.Header{})
+	require.NoError(t, err)
+	headers := http.Header{}
+	headers.Set(object.ExpiresAfterHeader, "1")
+	obj, err := objectMgr.PutObject(ctx, "cache", "session.tmp", bytes.NewReader([]byte("short-lived")), headers)
+	require.NoError(t, err)
+	require.NotNil(t, obj.ExpiresAt)
+
+	time.Sleep(time.Until(*obj.ExpiresAt) + 50*time.Millisecond)
+
+	NewWorker(bucketMgr, objectMgr, metaStore).processLifecyclePolicies(ctx)
+
+	_, _, err = objectMgr.GetObject(ctx, "cache", "session.tmp")
+	assert.ErrorIs(t, err, object.ErrObjectNotFound, "the previous version must not reappear")
+
+	versions, err := objectMgr.GetObjectVersions(ctx, "cache", "session.tmp")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	markers := 0
+	for _, v := range versions {
+		if v.IsDeleteMarker {
+			markers++
+			assert.True(t, v.IsLatest, "the delete marker is the current version")
+		}
+	}
+	assert.Equal(t, 1, markers)
+}
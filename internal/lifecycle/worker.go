@@ -2,6 +2,7 @@ package lifecycle
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -10,8 +11,31 @@ import (
 	"github.com/maxiofs/maxiofs/internal/metadata"
 	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
+// Default tuning of a lifecycle run
+const (
+	defaultWorkers      = 4
+	defaultScanPageSize = 1000
+)
+
+// Options tunes how hard a lifecycle run works. Workers is how many buckets
+// are processed at once (default 4). ScanRate caps how many objects per
+// second all workers together read while looking for expired objects; 0 means
+// unlimited.
+type Options struct {
+	Workers  int
+	ScanRate int
+}
+
+// BucketRunStats describes lifecycle processing of one bucket
+type BucketRunStats struct {
+	LastRun        time.Time // when the last run over the bucket finished
+	ObjectsExpired int64     // objects and versions deleted by the last run
+	ExpiredTotal   int64     // objects and versions deleted since start
+}
+
 // Worker handles lifecycle policy execution
 type Worker struct {
 	bucketManager bucket.Manager
@@ -20,6 +44,13 @@ type Worker struct {
 	ticker        *time.Ticker
 	stopChan      chan struct{}
 	stopOnce      sync.Once
+
+	workers      int
+	scanLimiter  *rate.Limiter // nil when the scan rate is unlimited
+	scanPageSize int
+
+	statsMu sync.Mutex
+	stats   map[string]*BucketRunStats // keyed by tenant-prefixed bucket path
 }
 
 // NewWorker creates a new lifecycle worker
@@ -29,9 +60,71 @@ func NewWorker(bucketManager bucket.Manager, objectManager object.Manager, metad
 		objectManager: objectManager,
 		metadataStore: metadataStore,
 		stopChan:      make(chan struct{}),
+		workers:       defaultWorkers,
+		scanPageSize:  defaultScanPageSize,
+		stats:         make(map[string]*BucketRunStats),
+	}
+}
+
+// SetOptions applies the worker pool size and scan rate. It must be called
+// before Start.
+func (w *Worker) SetOptions(opts Options) {
+	w.workers = opts.Workers
+	if w.workers <= 0 {
+		w.workers = defaultWorkers
+	}
+	w.scanLimiter = nil
+	w.scanPageSize = defaultScanPageSize
+	if opts.ScanRate > 0 {
+		// A page is paid for in one go, so it can't exceed the burst
+		w.scanLimiter = rate.NewLimiter(rate.Limit(opts.ScanRate), opts.ScanRate)
+		if opts.ScanRate < w.scanPageSize {
+			w.scanPageSize = opts.ScanRate
+		}
 	}
 }
 
+// BucketStats returns the last-run time and expired-object counts of every
+// bucket processed so far, keyed by tenant-prefixed bucket path.
+func (w *Worker) BucketStats() map[string]BucketRunStats {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+	stats := make(map[string]BucketRunStats, len(w.stats))
+	for bucketPath, s := range w.stats {
+		stats[bucketPath] = *s
+	}
+	return stats
+}
+
+func (w *Worker) recordBucketRun(bucketPath string, expired int) {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+	s, ok := w.stats[bucketPath]
+	if !ok {
+		s = &BucketRunStats{}
+		w.stats[bucketPath] = s
+	}
+	s.LastRun = time.Now()
+	s.ObjectsExpired = int64(expired)
+	s.ExpiredTotal += int64(expired)
+}
+
+// throttle waits until n more objects may be scanned under the scan rate.
+func (w *Worker) throttle(ctx context.Context, n int) error {
+	if w.scanLimiter == nil {
+		return nil
+	}
+	burst := w.scanLimiter.Burst()
+	for n > 0 {
+		step := min(n, burst)
+		if err := w.scanLimiter.WaitN(ctx, step); err != nil {
+			return err
+		}
+		n -= step
+	}
+	return nil
+}
+
 // Start begins the lifecycle worker
 func (w *Worker) Start(ctx context.Context, interval time.Duration) {
 	w.ticker = time.NewTicker(interval)
@@ -61,9 +154,11 @@ func (w *Worker) Stop() {
 	w.stopOnce.Do(func() { close(w.stopChan) })
 }
 
-// processLifecyclePolicies processes all lifecycle policies for all buckets
+// processLifecyclePolicies processes all lifecycle policies for all buckets,
+// up to w.workers buckets at a time
 func (w *Worker) processLifecyclePolicies(ctx context.Context) {
 	logrus.Debug("Processing lifecycle policies...")
+	start := time.Now()
 
 	// Get all buckets
 	buckets, err := w.bucketManager.ListBuckets(ctx, "") // Empty tenantID lists all buckets
@@ -72,96 +167,140 @@ func (w *Worker) processLifecyclePolicies(ctx context.Context) {
 		return
 	}
 
+	jobs := make(chan bucket.Bucket)
+	var wg sync.WaitGroup
+	for i := 0; i < min(w.workers, len(buckets)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for bkt := range jobs {
+				w.processBucket(ctx, bkt)
+			}
+		}()
+	}
+feed:
 	for _, bkt := range buckets {
-		// Get bucket details to check for lifecycle config
-		bucketInfo, err := w.bucketManager.GetBucketInfo(ctx, bkt.TenantID, bkt.Name)
-		if err != nil {
-			logrus.WithError(err).WithField("bucket", bkt.Name).Warn("Failed to get bucket info")
-			continue
+		select {
+		case jobs <- bkt:
+		case <-ctx.Done():
+			break feed
 		}
+	}
+	close(jobs)
+	wg.Wait()
 
-		// Skip if no lifecycle configuration
-		if bucketInfo.Lifecycle == nil || len(bucketInfo.Lifecycle.Rules) == 0 {
-			continue
-		}
+	logrus.WithFields(logrus.Fields{
+		"buckets":  len(buckets),
+		"duration": time.Since(start),
+	}).Debug("Lifecycle policy processing completed")
+}
+
+// processBucket applies object TTLs and the bucket's enabled lifecycle rules.
+// Current objects are read in a single ordered scan shared by the TTL check
+// and every Days/Date expiration rule.
+func (w *Worker) processBucket(ctx context.Context, bkt bucket.Bucket) {
+	bucketPath := bkt.Name
+	if bkt.TenantID != "" {
+		bucketPath = bkt.TenantID + "/" + bkt.Name
+	}
 
-		// Process each lifecycle rule
+	var rules []bucket.LifecycleRule
+	bucketInfo, err := w.bucketManager.GetBucketInfo(ctx, bkt.TenantID, bkt.Name)
+	if err != nil {
+		logrus.WithError(err).WithField("bucket", bkt.Name).Warn("Failed to get bucket info")
+	} else if bucketInfo.Lifecycle != nil {
 		for _, rule := range bucketInfo.Lifecycle.Rules {
-			if rule.Status != "Enabled" {
-				continue
+			if rule.Status == "Enabled" {
+				rules = append(rules, rule)
 			}
-
-			w.processLifecycleRule(ctx, bkt.TenantID, bkt.Name, rule)
 		}
 	}
 
-	logrus.Debug("Lifecycle policy processing completed")
+	// Objects written with a TTL expire whether or not the bucket has a
+	// lifecycle configuration
+	expired := w.scanCurrentObjects(ctx, bucketPath, "", rules, true)
+	for _, rule := range rules {
+		expired += w.processVersionRules(ctx, bucketPath, rule)
+	}
+	if ctx.Err() == nil {
+		w.recordBucketRun(bucketPath, expired)
+	}
 }
 
-// processLifecycleRule processes a single lifecycle rule for a bucket
-func (w *Worker) processLifecycleRule(ctx context.Context, tenantID, bucketName string, rule bucket.LifecycleRule) {
-	logrus.WithFields(logrus.Fields{
-		"bucket": bucketName,
-		"rule":   rule.ID,
-	}).Debug("Processing lifecycle rule")
-
-	// Construct bucket path
-	bucketPath := bucketName
-	if tenantID != "" {
-		bucketPath = tenantID + "/" + bucketName
-	}
+// processVersionRules applies the parts of a rule that don't act on current
+// objects: noncurrent version expiration, expired delete marker removal and
+// aborting stale multipart uploads. It returns the versions deleted.
+func (w *Worker) processVersionRules(ctx context.Context, bucketPath string, rule bucket.LifecycleRule) int {
+	expired := 0
 
 	// Process NoncurrentVersionExpiration
-	if rule.NoncurrentVersionExpiration != nil && rule.NoncurrentVersionExpiration.NoncurrentDays > 0 {
-		w.processNoncurrentVersionExpiration(ctx, bucketPath, rule)
+	if nve := rule.NoncurrentVersionExpiration; nve != nil && (nve.NoncurrentDays > 0 || nve.NewerNoncurrentVersions > 0) {
+		expired += w.processNoncurrentVersionExpiration(ctx, bucketPath, rule)
 	}
 
 	// Process ExpiredObjectDeleteMarker
 	if rule.Expiration != nil && rule.Expiration.ExpiredObjectDeleteMarker != nil && *rule.Expiration.ExpiredObjectDeleteMarker {
-		w.processExpiredDeleteMarkers(ctx, bucketPath, rule)
-	}
-
-	// Process object expiration by age (Days) or fixed date
-	if rule.Expiration != nil && (rule.Expiration.Days != nil || rule.Expiration.Date != nil) {
-		w.processObjectExpiration(ctx, bucketPath, rule)
+		expired += w.processExpiredDeleteMarkers(ctx, bucketPath, rule)
 	}
 
 	// Process abort of incomplete multipart uploads
 	if rule.AbortIncompleteMultipartUpload != nil && rule.AbortIncompleteMultipartUpload.DaysAfterInitiation > 0 {
 		w.processAbortIncompleteMultipartUploads(ctx, bucketPath, rule)
 	}
+	return expired
 }
 
-// processNoncurrentVersionExpiration deletes noncurrent versions older than specified days
-func (w *Worker) processNoncurrentVersionExpiration(ctx context.Context, bucketPath string, rule bucket.LifecycleRule) {
+// processNoncurrentVersionExpiration deletes noncurrent versions that are
+// older than NoncurrentDays or that fall outside the NewerNoncurrentVersions
+// newest noncurrent versions of their key. Either limit alone is enough to
+// expire a version. It returns the number of versions deleted.
+func (w *Worker) processNoncurrentVersionExpiration(ctx context.Context, bucketPath string, rule bucket.LifecycleRule) int {
 	noncurrentDays := rule.NoncurrentVersionExpiration.NoncurrentDays
-	cutoffTime := time.Now().AddDate(0, 0, -noncurrentDays)
+	keepNewer := rule.NoncurrentVersionExpiration.NewerNoncurrentVersions
+	var cutoffTime time.Time
+	if noncurrentDays > 0 {
+		cutoffTime = time.Now().AddDate(0, 0, -noncurrentDays)
+	}
 
 	logrus.WithFields(logrus.Fields{
-		"bucket":         bucketPath,
-		"rule":           rule.ID,
-		"noncurrentDays": noncurrentDays,
-		"cutoffTime":     cutoffTime,
+		"bucket":                  bucketPath,
+		"rule":                    rule.ID,
+		"noncurrentDays":          noncurrentDays,
+		"newerNoncurrentVersions": keepNewer,
+		"cutoffTime":              cutoffTime,
 	}).Debug("Processing noncurrent version expiration")
 
-	versionsByKey, err := w.listLifecycleVersionsByKey(ctx, bucketPath, rule.Filter.Prefix)
+	versionsByKey, err := w.listLifecycleVersionsByKey(ctx, bucketPath, lifecycleFilterPrefix(rule.Filter))
 	if err != nil {
 		logrus.WithError(err).Error("Failed to list object versions for lifecycle")
-		return
+		return 0
 	}
+	requiredTags := lifecycleFilterTags(rule.Filter)
 
 	deletedCount := 0
 
 	for key, versions := range versionsByKey {
-		// Delete noncurrent versions older than cutoff
+		// Newest first, so the position among noncurrent versions is the
+		// number of newer noncurrent versions
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].LastModified.After(versions[j].LastModified)
+		})
+
+		noncurrentIndex := 0
 		for _, version := range versions {
 			// Skip latest version
 			if version.IsLatest {
 				continue
 			}
+			newerNoncurrent := noncurrentIndex
+			noncurrentIndex++
 
-			// Skip if not old enough
-			if version.LastModified.After(cutoffTime) {
+			tooOld := noncurrentDays > 0 && !version.LastModified.After(cutoffTime)
+			beyondKept := keepNewer > 0 && newerNoncurrent >= keepNewer
+			if !tooOld && !beyondKept {
+				continue
+			}
+			if len(requiredTags) > 0 && !w.objectHasTags(ctx, bucketPath, key, version.VersionID, requiredTags) {
 				continue
 			}
 
@@ -176,9 +315,10 @@ func (w *Worker) processNoncurrentVersionExpiration(ctx context.Context, bucketP
 			} else {
 				deletedCount++
 				logrus.WithFields(logrus.Fields{
-					"key":       key,
-					"versionID": version.VersionID,
-					"age":       time.Since(version.LastModified).Hours() / 24,
+					"key":             key,
+					"versionID":       version.VersionID,
+					"age":             time.Since(version.LastModified).Hours() / 24,
+					"newerNoncurrent": newerNoncurrent,
 				}).Debug("Deleted noncurrent version")
 			}
 		}
@@ -191,20 +331,27 @@ func (w *Worker) processNoncurrentVersionExpiration(ctx context.Context, bucketP
 			"deletedCount": deletedCount,
 		}).Info("Lifecycle policy deleted noncurrent versions")
 	}
+	return deletedCount
 }
 
 // processExpiredDeleteMarkers removes expired delete markers
-// An expired delete marker is a delete marker that is the only remaining version of an object
-func (w *Worker) processExpiredDeleteMarkers(ctx context.Context, bucketPath string, rule bucket.LifecycleRule) {
+// An expired delete marker is a delete marker that is the only remaining version of an object.
+// It returns the number of delete markers removed.
+func (w *Worker) processExpiredDeleteMarkers(ctx context.Context, bucketPath string, rule bucket.LifecycleRule) int {
 	logrus.WithFields(logrus.Fields{
 		"bucket": bucketPath,
 		"rule":   rule.ID,
 	}).Debug("Processing expired delete markers")
 
-	versionsByKey, err := w.listLifecycleVersionsByKey(ctx, bucketPath, rule.Filter.Prefix)
+	// Delete markers carry no tags, so a tag-filtered rule never matches one
+	if len(lifecycleFilterTags(rule.Filter)) > 0 {
+		return 0
+	}
+
+	versionsByKey, err := w.listLifecycleVersionsByKey(ctx, bucketPath, lifecycleFilterPrefix(rule.Filter))
 	if err != nil {
 		logrus.WithError(err).Error("Failed to list object versions for expired delete marker cleanup")
-		return
+		return 0
 	}
 
 	deletedCount := 0
@@ -241,6 +388,7 @@ func (w *Worker) processExpiredDeleteMarkers(ctx context.Context, bucketPath str
 			"deletedCount": deletedCount,
 		}).Info("Lifecycle policy deleted expired delete markers")
 	}
+	return deletedCount
 }
 
 func (w *Worker) listLifecycleVersionsByKey(ctx context.Context, bucketPath, prefix string) (map[string][]*metadata.ObjectVersion, error) {
@@ -248,6 +396,9 @@ func (w *Worker) listLifecycleVersionsByKey(ctx context.Context, bucketPath, pre
 	if err != nil {
 		return nil, err
 	}
+	if err := w.throttle(ctx, len(versions)); err != nil {
+		return nil, err
+	}
 
 	byKey := make(map[string][]*metadata.ObjectVersion)
 	for _, version := range versions {
@@ -259,53 +410,150 @@ func (w *Worker) listLifecycleVersionsByKey(ctx context.Context, bucketPath, pre
 	return byKey, nil
 }
 
+// lifecycleFilterPrefix returns the key prefix a rule filter selects, whether
+// it is set directly or inside an And filter.
+func lifecycleFilterPrefix(filter bucket.LifecycleFilter) string {
+	if filter.And != nil {
+		return filter.And.Prefix
+	}
+	return filter.Prefix
+}
+
+// lifecycleFilterTags returns the tags an object must carry to match a rule
+// filter, or nil when the filter selects by prefix only.
+func lifecycleFilterTags(filter bucket.LifecycleFilter) []bucket.Tag {
+	if filter.Tag != nil {
+		return []bucket.Tag{*filter.Tag}
+	}
+	if filter.And != nil {
+		return filter.And.Tags
+	}
+	return nil
+}
+
+// objectHasTags reports whether the object (or the given version of it) has
+// every one of the required tags. Objects whose tags can't be read don't match,
+// so a lookup failure never expires data.
+func (w *Worker) objectHasTags(ctx context.Context, bucketPath, key, versionID string, required []bucket.Tag) bool {
+	var versionArgs []string
+	if versionID != "" {
+		versionArgs = append(versionArgs, versionID)
+	}
+	tagSet, err := w.objectManager.GetObjectTagging(ctx, bucketPath, key, versionArgs...)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"bucket":    bucketPath,
+			"key":       key,
+			"versionID": versionID,
+		}).Debug("Failed to read object tags for lifecycle filter")
+		return false
+	}
+
+	tags := make(map[string]string, len(tagSet.Tags))
+	for _, tag := range tagSet.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	for _, want := range required {
+		if value, ok := tags[want.Key]; !ok || value != want.Value {
+			return false
+		}
+	}
+	return true
+}
+
 func isLifecycleDeleteMarker(version *metadata.ObjectVersion) bool {
 	return version != nil && version.VersionID != "" && version.Size == 0 && version.ETag == ""
 }
 
-// processObjectExpiration deletes objects that have exceeded their expiration age or date.
+// processObjectExpiration deletes objects that have exceeded the rule's
+// expiration age or date and returns how many it deleted.
 // On versioned buckets, DeleteObject without a versionId creates a delete marker (correct S3 behavior).
-func (w *Worker) processObjectExpiration(ctx context.Context, bucketPath string, rule bucket.LifecycleRule) {
-	var cutoff time.Time
+func (w *Worker) processObjectExpiration(ctx context.Context, bucketPath string, rule bucket.LifecycleRule) int {
+	return w.scanCurrentObjects(ctx, bucketPath, lifecycleFilterPrefix(rule.Filter), []bucket.LifecycleRule{rule}, false)
+}
+
+// expirationCutoff returns the time before which objects expire under a rule
+// with a Days or Date expiration, and false for any other rule.
+func expirationCutoff(rule bucket.LifecycleRule, now time.Time) (time.Time, bool) {
+	if rule.Expiration == nil {
+		return time.Time{}, false
+	}
 	if rule.Expiration.Days != nil {
-		cutoff = time.Now().UTC().AddDate(0, 0, -*rule.Expiration.Days)
-	} else {
-		cutoff = rule.Expiration.Date.UTC()
+		return now.UTC().AddDate(0, 0, -*rule.Expiration.Days), true
+	}
+	if rule.Expiration.Date != nil {
+		return rule.Expiration.Date.UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// scanCurrentObjects walks the current objects under prefix once, in key
+// order and a page at a time, and deletes those past their TTL (when withTTL
+// is set) or expired by any of the rules' Days/Date expiration. A page's
+// deletions are issued together once it has been read. It returns the number
+// of objects deleted.
+func (w *Worker) scanCurrentObjects(ctx context.Context, bucketPath, prefix string, rules []bucket.LifecycleRule, withTTL bool) int {
+	now := time.Now()
+	type expirationRule struct {
+		prefix string
+		tags   []bucket.Tag
+		cutoff time.Time
+	}
+	var expirations []expirationRule
+	for _, rule := range rules {
+		if cutoff, ok := expirationCutoff(rule, now); ok {
+			expirations = append(expirations, expirationRule{
+				prefix: lifecycleFilterPrefix(rule.Filter),
+				tags:   lifecycleFilterTags(rule.Filter),
+				cutoff: cutoff,
+			})
+		}
+	}
+	if len(expirations) == 0 && !withTTL {
+		return 0
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"bucket": bucketPath,
-		"rule":   rule.ID,
-		"cutoff": cutoff,
-	}).Debug("Processing object expiration")
-
-	prefix := rule.Filter.Prefix
+		"prefix": prefix,
+		"rules":  len(expirations),
+		"ttl":    withTTL,
+	}).Debug("Scanning objects for expiration")
 
 	deletedCount := 0
 	marker := ""
 	for {
 		if err := ctx.Err(); err != nil {
-			return
+			return deletedCount
 		}
 
-		result, err := w.objectManager.ListObjects(ctx, bucketPath, prefix, "", marker, 1000)
+		result, err := w.objectManager.ListObjects(ctx, bucketPath, prefix, "", marker, w.scanPageSize)
 		if err != nil {
 			logrus.WithError(err).WithField("bucket", bucketPath).Error("Failed to list objects for expiration")
-			return
+			return deletedCount
+		}
+		if err := w.throttle(ctx, len(result.Objects)); err != nil {
+			return deletedCount
 		}
 
+		var batch []string
 		for _, obj := range result.Objects {
-			if obj.LastModified.UTC().Before(cutoff) {
-				if _, err := w.objectManager.DeleteObject(ctx, bucketPath, obj.Key, false); err != nil {
-					logrus.WithError(err).WithFields(logrus.Fields{
-						"bucket": bucketPath,
-						"key":    obj.Key,
-					}).Warn("Failed to expire object")
-				} else {
-					deletedCount++
+			if withTTL && obj.IsExpired(now) {
+				batch = append(batch, obj.Key)
+				continue
+			}
+			for _, rule := range expirations {
+				if !strings.HasPrefix(obj.Key, rule.prefix) || !obj.LastModified.UTC().Before(rule.cutoff) {
+					continue
+				}
+				if len(rule.tags) > 0 && !w.objectHasTags(ctx, bucketPath, obj.Key, "", rule.tags) {
+					continue
 				}
+				batch = append(batch, obj.Key)
+				break
 			}
 		}
+		deletedCount += w.deleteExpired(ctx, bucketPath, batch)
 
 		if !result.IsTruncated {
 			break
@@ -319,10 +567,30 @@ func (w *Worker) processObjectExpiration(ctx context.Context, bucketPath string,
 	if deletedCount > 0 {
 		logrus.WithFields(logrus.Fields{
 			"bucket":       bucketPath,
-			"rule":         rule.ID,
 			"deletedCount": deletedCount,
-		}).Info("Lifecycle policy expired objects")
+		}).Info("Lifecycle expired objects")
+	}
+	return deletedCount
+}
+
+// deleteExpired deletes the expired objects found on one page, one
+// DeleteObject call each, and returns how many were deleted. The deletes name
+// no version, so in versioned and suspended buckets they write a delete
+// marker instead of removing the current version and exposing the one before
+// it. Lifecycle deletes never bypass governance retention.
+func (w *Worker) deleteExpired(ctx context.Context, bucketPath string, keys []string) int {
+	deleted := 0
+	for _, key := range keys {
+		if _, err := w.objectManager.DeleteObject(ctx, bucketPath, key, false); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"bucket": bucketPath,
+				"key":    key,
+			}).Warn("Failed to expire object")
+			continue
+		}
+		deleted++
 	}
+	return deleted
 }
 
 // processAbortIncompleteMultipartUploads aborts multipart uploads that have been
@@ -342,7 +610,12 @@ func (w *Worker) processAbortIncompleteMultipartUploads(ctx context.Context, buc
 		return
 	}
 
-	prefix := rule.Filter.Prefix
+	// In-progress uploads have no tags yet, so a tag-filtered rule never matches one
+	if len(lifecycleFilterTags(rule.Filter)) > 0 {
+		return
+	}
+
+	prefix := lifecycleFilterPrefix(rule.Filter)
 	abortedCount := 0
 	for _, upload := range uploads {
 		if prefix != "" && !strings.HasPrefix(upload.Key, prefix) {
@@ -2,6 +2,7 @@ package lifecycle
 
 import (
 	"context"
+	"fmt"
 	"errors"
 	"testing"
 	"time"
@@ -308,6 +309,84 @@ func TestProcessNoncurrentVersionExpiration_HandleError(t *testing.T) {
 	worker.processNoncurrentVersionExpiration(ctx, "test-bucket", rule)
 }
 
+// tenVersions returns ten versions of file.txt, v10 (latest) to v1, one day
+// apart, in scrambled order to make sure the worker sorts them itself.
+func tenVersions() []*metadata.ObjectVersion {
+	now := time.Now()
+	var versions []*metadata.ObjectVersion
+	for _, i := range []int{3, 10, 1, 7, 5, 9, 2, 8, 4, 6} {
+		versions = append(versions, &metadata.ObjectVersion{
+			Key:          "file.txt",
+			VersionID:    fmt.Sprintf("v%d", i),
+			LastModified: now.AddDate(0, 0, -(10 - i)),
+			IsLatest:     i == 10,
+		})
+	}
+	return versions
+}
+
+// TestProcessNoncurrentVersionExpiration_NewerNoncurrentVersions keeps the
+// current version plus the three newest noncurrent versions.
+func TestProcessNoncurrentVersionExpiration_NewerNoncurrentVersions(t *testing.T) {
+	objMgr := &mockObjectMgr{}
+	worker := NewWorker(&mockBucketMgr{}, objMgr, &mockMetaStore{versions: tenVersions()})
+
+	rule := bucket.LifecycleRule{
+		ID:     "keep-3",
+		Status: "Enabled",
+		NoncurrentVersionExpiration: &bucket.NoncurrentVersionExpiration{
+			NewerNoncurrentVersions: 3,
+		},
+	}
+	worker.processNoncurrentVersionExpiration(context.Background(), "test-bucket", rule)
+
+	// v10 is current; v9, v8, v7 are the three kept noncurrent versions
+	assert.ElementsMatch(t, []string{"v1", "v2", "v3", "v4", "v5", "v6"}, objMgr.deletedVersionIDs)
+}
+
+// TestProcessNoncurrentVersionExpiration_CountOrDays expires a noncurrent
+// version when it exceeds either the count or the age limit.
+func TestProcessNoncurrentVersionExpiration_CountOrDays(t *testing.T) {
+	objMgr := &mockObjectMgr{}
+	worker := NewWorker(&mockBucketMgr{}, objMgr, &mockMetaStore{versions: tenVersions()})
+
+	rule := bucket.LifecycleRule{
+		ID:     "keep-3-or-2-days",
+		Status: "Enabled",
+		NoncurrentVersionExpiration: &bucket.NoncurrentVersionExpiration{
+			NoncurrentDays:          2,
+			NewerNoncurrentVersions: 3,
+		},
+	}
+	worker.processNoncurrentVersionExpiration(context.Background(), "test-bucket", rule)
+
+	// v9 (1 day old) survives both limits; v8 and v7 are within the count but
+	// at least 2 days old; v6..v1 exceed the count
+	assert.ElementsMatch(t, []string{"v1", "v2", "v3", "v4", "v5", "v6", "v7", "v8"}, objMgr.deletedVersionIDs)
+}
+
+// TestProcessBucket_NewerNoncurrentVersionsOnly runs a count-only rule
+// through processBucket, which previously required NoncurrentDays.
+func TestProcessBucket_NewerNoncurrentVersionsOnly(t *testing.T) {
+	objMgr := &mockObjectMgr{}
+	bucketMgr := &mockBucketMgr{getBucket: &bucket.Bucket{
+		Name:     "test-bucket",
+		TenantID: "tenant-1",
+		Lifecycle: &bucket.LifecycleConfig{Rules: []bucket.LifecycleRule{{
+			ID:     "keep-3",
+			Status: "Enabled",
+			NoncurrentVersionExpiration: &bucket.NoncurrentVersionExpiration{
+				NewerNoncurrentVersions: 3,
+			},
+		}}},
+	}}
+	worker := NewWorker(bucketMgr, objMgr, &mockMetaStore{versions: tenVersions()})
+
+	worker.processBucket(context.Background(), bucket.Bucket{Name: "test-bucket", TenantID: "tenant-1"})
+
+	assert.Equal(t, 6, objMgr.deleteCount)
+}
+
 // TestProcessExpiredDeleteMarkers_DeleteExpiredMarker tests deletion of expired delete markers
 func TestProcessExpiredDeleteMarkers_DeleteExpiredMarker(t *testing.T) {
 	bucketMgr := &mockBucketMgr{}
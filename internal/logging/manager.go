@@ -18,6 +18,7 @@ type Manager struct {
 	dispatchHook    *DispatchHook            // single hook registered with logrus
 	mu              sync.RWMutex
 	logger          *logrus.Logger
+	formatOverride  string // log_format from config.yaml; wins over the logging.format setting
 }
 
 // SettingsManager interface for accessing dynamic settings
@@ -44,6 +45,14 @@ func NewManager(logger *logrus.Logger) *Manager {
 	return m
 }
 
+// SetFormatOverride pins the log format ("json" or "text") regardless of the
+// logging.format setting. An empty format lets the setting decide again.
+func (m *Manager) SetFormatOverride(format string) {
+	m.mu.Lock()
+	m.formatOverride = format
+	m.mu.Unlock()
+}
+
 // SetSettingsManager sets the settings manager and reconfigures logging
 func (m *Manager) SetSettingsManager(sm SettingsManager) {
 	m.mu.Lock()
@@ -112,11 +121,14 @@ func (m *Manager) Reconfigure() {
 		return
 	}
 
-	// Apply log format
+	// Apply log format (config.yaml log_format takes precedence)
 	format, err := m.settingsManager.Get("logging.format")
 	if err != nil {
 		format = "json" // default
 	}
+	if m.formatOverride != "" {
+		format = m.formatOverride
+	}
 
 	if format == "json" {
 		m.logger.SetFormatter(&logrus.JSONFormatter{
@@ -102,6 +102,25 @@ func TestReconfigureLogFormat(t *testing.T) {
 	}
 }
 
+func TestReconfigureFormatOverride(t *testing.T) {
+	logger := logrus.New()
+	manager := NewManager(logger)
+	manager.SetFormatOverride("text")
+
+	manager.SetSettingsManager(&mockSettingsManager{
+		settings: map[string]string{
+			"logging.format":         "json",
+			"logging.level":          "info",
+			"logging.include_caller": "false",
+		},
+	})
+	assert.IsType(t, &logrus.TextFormatter{}, logger.Formatter)
+
+	manager.SetFormatOverride("")
+	manager.Reconfigure()
+	assert.IsType(t, &logrus.JSONFormatter{}, logger.Formatter)
+}
+
 func TestReconfigureLogLevel(t *testing.T) {
 	logger := logrus.New()
 	manager := NewManager(logger)
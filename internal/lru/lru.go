@@ -0,0 +1,203 @@
+// Package lru provides the bounded, least-recently-used cache behind the
+// server's in-memory caches and rate-limiter state. Every cache counts what
+// it drops, so its size and churn can be exported as metrics, and can be
+// shrunk on demand when the process is under memory pressure.
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats describes a cache at one point in time. The eviction counters only
+// grow.
+type Stats struct {
+	Entries    int
+	MaxEntries int   // 0 means unbounded
+	Evictions  int64 // dropped to stay within MaxEntries
+	Expired    int64 // dropped as idle or expired
+	Shrunk     int64 // dropped by Shrink, i.e. under memory pressure
+}
+
+// Shrinkable is a cache as the memory-pressure monitor and metrics see it.
+type Shrinkable interface {
+	Stats() Stats
+	// Shrink drops the least recently used fraction (0-1) of the entries and
+	// returns how many it dropped.
+	Shrink(fraction float64) int
+}
+
+// Cache is a goroutine-safe LRU cache holding at most maxEntries entries.
+// Put evicts the least recently used entry once the cache is full; Get and
+// Put mark an entry as used, Peek does not.
+type Cache[K comparable, V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front = most recently used
+	entries    map[K]*list.Element
+	evictions  int64
+	expired    int64
+	shrunk     int64
+}
+
+type entry[K comparable, V any] struct {
+	key      K
+	value    V
+	lastUsed time.Time
+}
+
+// New returns a cache holding at most maxEntries entries; 0 or less means
+// unbounded.
+func New[K comparable, V any](maxEntries int) *Cache[K, V] {
+	if maxEntries < 0 {
+		maxEntries = 0
+	}
+	return &Cache[K, V]{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value stored under key and marks it as used.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	e := elem.Value.(*entry[K, V])
+	e.lastUsed = time.Now()
+	c.order.MoveToFront(elem)
+	return e.value, true
+}
+
+// Peek returns the value stored under key without marking it as used.
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return elem.Value.(*entry[K, V]).value, true
+}
+
+// Put stores value under key, marks it as used and evicts the least
+// recently used entries beyond the cap.
+func (c *Cache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value = value
+		e.lastUsed = now
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, lastUsed: now})
+	for c.maxEntries > 0 && len(c.entries) > c.maxEntries {
+		c.removeOldest()
+		c.evictions++
+	}
+}
+
+// Delete removes key. It is not counted as an eviction.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns the number of entries.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// RemoveIdle drops the entries not used for longer than maxIdle and returns
+// how many it dropped.
+func (c *Cache[K, V]) RemoveIdle(maxIdle time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cutoff := time.Now().Add(-maxIdle)
+	removed := 0
+	// Least recently used entries are at the back; stop at the first one
+	// still in use.
+	for elem := c.order.Back(); elem != nil; elem = c.order.Back() {
+		if !elem.Value.(*entry[K, V]).lastUsed.Before(cutoff) {
+			break
+		}
+		c.removeOldest()
+		removed++
+	}
+	c.expired += int64(removed)
+	return removed
+}
+
+// RemoveFunc drops the entries for which expired returns true, counting them
+// as expired, and returns how many it dropped. expired runs with the cache
+// locked and must not call back into it.
+func (c *Cache[K, V]) RemoveFunc(expired func(key K, value V) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for elem := c.order.Back(); elem != nil; {
+		prev := elem.Prev()
+		e := elem.Value.(*entry[K, V])
+		if expired(e.key, e.value) {
+			c.order.Remove(elem)
+			delete(c.entries, e.key)
+			removed++
+		}
+		elem = prev
+	}
+	c.expired += int64(removed)
+	return removed
+}
+
+// Shrink drops the least recently used fraction (0-1) of the entries.
+func (c *Cache[K, V]) Shrink(fraction float64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if fraction > 1 {
+		fraction = 1
+	}
+	n := int(float64(len(c.entries)) * fraction)
+	for i := 0; i < n; i++ {
+		c.removeOldest()
+	}
+	c.shrunk += int64(n)
+	return n
+}
+
+// Stats returns the current size and eviction counters.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		Entries:    len(c.entries),
+		MaxEntries: c.maxEntries,
+		Evictions:  c.evictions,
+		Expired:    c.expired,
+		Shrunk:     c.shrunk,
+	}
+}
+
+func (c *Cache[K, V]) removeOldest() {
+	elem := c.order.Back()
+	if elem == nil {
+		return
+	}
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,83 @@
+package lru
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheEvictsOldestPastCapKeepingHotEntries(t *testing.T) {
+	c := New[string, int](10)
+	for i := 0; i < 10; i++ {
+		c.Put(fmt.Sprintf("k%d", i), i)
+	}
+
+	// k0 and k1 are read again, so they are the hot entries
+	for _, hot := range []string{"k0", "k1"} {
+		_, ok := c.Get(hot)
+		require.True(t, ok)
+	}
+
+	for i := 10; i < 15; i++ {
+		c.Put(fmt.Sprintf("k%d", i), i)
+	}
+
+	assert.Equal(t, 10, c.Len())
+	for _, hot := range []string{"k0", "k1"} {
+		_, ok := c.Peek(hot)
+		assert.True(t, ok, "hot entry %s was evicted", hot)
+	}
+	for i := 2; i < 7; i++ {
+		_, ok := c.Peek(fmt.Sprintf("k%d", i))
+		assert.False(t, ok, "oldest entry k%d was kept", i)
+	}
+	for i := 7; i < 15; i++ {
+		_, ok := c.Peek(fmt.Sprintf("k%d", i))
+		assert.True(t, ok, "recent entry k%d was evicted", i)
+	}
+
+	stats := c.Stats()
+	assert.Equal(t, 10, stats.Entries)
+	assert.Equal(t, 10, stats.MaxEntries)
+	assert.Equal(t, int64(5), stats.Evictions)
+}
+
+func TestCacheRemoveIdleAndShrink(t *testing.T) {
+	c := New[string, int](0)
+	c.Put("idle", 1)
+	c.Put("other-idle", 2)
+	time.Sleep(20 * time.Millisecond)
+	c.Put("fresh", 3)
+
+	assert.Equal(t, 2, c.RemoveIdle(10*time.Millisecond))
+	_, ok := c.Peek("fresh")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), c.Stats().Expired)
+
+	for i := 0; i < 9; i++ {
+		c.Put(fmt.Sprintf("k%d", i), i)
+	}
+	assert.Equal(t, 5, c.Shrink(0.5))
+	assert.Equal(t, 5, c.Len())
+	_, ok = c.Peek("fresh")
+	assert.False(t, ok, "shrinking drops the least recently used entries first")
+	_, ok = c.Peek("k8")
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), c.Stats().Shrunk)
+	assert.Zero(t, c.Stats().Evictions, "an unbounded cache never evicts on Put")
+}
+
+func TestCacheRemoveFunc(t *testing.T) {
+	c := New[string, int](0)
+	for i := 0; i < 6; i++ {
+		c.Put(fmt.Sprintf("k%d", i), i)
+	}
+	removed := c.RemoveFunc(func(_ string, v int) bool { return v%2 == 0 })
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, 3, c.Len())
+	_, ok := c.Peek("k1")
+	assert.True(t, ok)
+}
@@ -17,6 +17,17 @@ func bucketListPrefix(tenantID string) []byte {
 	return []byte(fmt.Sprintf("bucket:%s:", tenantID))
 }
 
+// bucketNameIndexKey records that tenantID owns a bucket called name, so a
+// bucket name resolves to its tenant without scanning every bucket. Bucket
+// names cannot contain ':', so the name prefix is unambiguous.
+func bucketNameIndexKey(name, tenantID string) []byte {
+	return []byte(fmt.Sprintf("bucket_name_idx:%s:%s", name, tenantID))
+}
+
+func bucketNameIndexPrefix(name string) []byte {
+	return []byte(fmt.Sprintf("bucket_name_idx:%s:", name))
+}
+
 func objectKey(bucket, key string) []byte {
 	return []byte(fmt.Sprintf("obj:%s:%s", bucket, key))
 }
@@ -41,6 +52,12 @@ func multipartListPrefix(bucket string) []byte {
 	return []byte(fmt.Sprintf("multipart_idx:%s:", bucket))
 }
 
+// multipartTenantPrefix starts the upload index keys of every bucket of a
+// tenant: tenant bucket paths are "{tenantID}/{bucket}".
+func multipartTenantPrefix(tenantID string) []byte {
+	return []byte(fmt.Sprintf("multipart_idx:%s/", tenantID))
+}
+
 func multipartIndexKey(bucket, uploadID string) []byte {
 	return []byte(fmt.Sprintf("multipart_idx:%s:%s", bucket, uploadID))
 }
@@ -61,6 +78,13 @@ func tagIndexPrefix(bucket, tagKey, tagValue string) []byte {
 	return []byte(fmt.Sprintf("tag_idx:%s:%s:%s:", bucket, tagKey, tagValue))
 }
 
+// bucketSequenceKey holds the bucket's modification sequence. It is kept when
+// the bucket is deleted, so a bucket recreated under the same name continues
+// the sequence instead of reusing values a client may have cached.
+func bucketSequenceKey(bucket string) []byte {
+	return []byte(fmt.Sprintf("bucket_seq:%s", bucket))
+}
+
 // extractObjectKeyFromKey extracts the object name from a metadata key.
 func extractObjectKeyFromKey(key string) string {
 	parts := strings.SplitN(key, ":", 3)
@@ -392,6 +392,44 @@ func TestBucketMetrics(t *testing.T) {
 	})
 }
 
+// TestBucketSequence tests that object writes and deletes advance the bucket's
+// modification sequence and reads don't
+func TestBucketSequence(t *testing.T) {
+	store, cleanup := setupObjectTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "seq-bucket", TenantID: "tenant-1"}))
+	bucketPath := "tenant-1/seq-bucket"
+
+	seq, err := store.GetBucketSequence(ctx, bucketPath)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), seq)
+
+	require.NoError(t, store.PutObject(ctx, &ObjectMetadata{Bucket: bucketPath, Key: "a.txt", Size: 1, ETag: "e1"}))
+	seq, err = store.GetBucketSequence(ctx, bucketPath)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), seq)
+
+	_, err = store.GetObject(ctx, bucketPath, "a.txt")
+	require.NoError(t, err)
+	_, _, err = store.ListObjects(ctx, bucketPath, "", "", 100)
+	require.NoError(t, err)
+	seq, err = store.GetBucketSequence(ctx, bucketPath)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), seq, "reads must not advance the sequence")
+
+	require.NoError(t, store.DeleteObject(ctx, bucketPath, "a.txt"))
+	seq, err = store.GetBucketSequence(ctx, bucketPath)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), seq)
+
+	require.NoError(t, store.DeleteBucket(ctx, "tenant-1", "seq-bucket"))
+	seq, err = store.GetBucketSequence(ctx, bucketPath)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), seq, "a deleted bucket keeps advancing")
+}
+
 // TestRecalculateBucketStats tests bucket statistics recalculation
 func TestRecalculateBucketStats(t *testing.T) {
 	store, cleanup := setupObjectTestStore(t)
@@ -0,0 +1,86 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cockroachdb/pebble/v2"
+)
+
+// bucketNameIndexAll covers every bucket name index entry.
+var bucketNameIndexAll = []byte("bucket_name_idx:")
+
+// rebuildBucketNameIndex rewrites the bucket name index from the bucket
+// records. It runs on every open, so stores written before the index existed
+// get one and a store can never start with a stale index.
+func (s *PebbleStore) rebuildBucketNameIndex() error {
+	batch := s.db.NewBatch()
+	defer batch.Close() //nolint:errcheck
+
+	if err := batch.DeleteRange(bucketNameIndexAll, prefixEnd(bucketNameIndexAll), nil); err != nil {
+		return err
+	}
+
+	iter, err := s.pebbleIter([]byte("bucket:"))
+	if err != nil {
+		return err
+	}
+	for iter.First(); iter.Valid(); iter.Next() {
+		var bucket BucketMetadata
+		if err := json.Unmarshal(iter.Value(), &bucket); err != nil {
+			continue
+		}
+		if err := batch.Set(bucketNameIndexKey(bucket.Name, bucket.TenantID), nil, nil); err != nil {
+			_ = iter.Close()
+			return err
+		}
+	}
+	iterErr := iter.Error()
+	_ = iter.Close()
+	if iterErr != nil {
+		return fmt.Errorf("failed during bucket scan: %w", iterErr)
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// deleteBucketRecord removes a bucket record together with its name index
+// entry. The caller holds the bucket mutation mutex.
+func (s *PebbleStore) deleteBucketRecord(tenantID, name string) error {
+	batch := s.db.NewBatch()
+	defer batch.Close() //nolint:errcheck
+	if err := batch.Delete(bucketKey(tenantID, name), nil); err != nil {
+		return fmt.Errorf("failed to delete bucket: %w", err)
+	}
+	if err := batch.Delete(bucketNameIndexKey(name, tenantID), nil); err != nil {
+		return fmt.Errorf("failed to delete bucket name index: %w", err)
+	}
+	// A listing cached before the deletion must not validate afterwards
+	if err := s.bumpBucketSequence(batch, bucketPathForMutation(tenantID, name)); err != nil {
+		return err
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return fmt.Errorf("failed to delete bucket: %w", err)
+	}
+	return nil
+}
+
+// bucketNameOwners returns the tenants owning a bucket called name, the
+// global (empty) tenant first. With a global bucket namespace there is at
+// most one.
+func (s *PebbleStore) bucketNameOwners(name string) ([]string, error) {
+	prefix := bucketNameIndexPrefix(name)
+	iter, err := s.pebbleIter(prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close() //nolint:errcheck
+
+	var tenants []string
+	for iter.First(); iter.Valid(); iter.Next() {
+		tenants = append(tenants, string(iter.Key()[len(prefix):]))
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed during bucket name lookup: %w", err)
+	}
+	return tenants, nil
+}
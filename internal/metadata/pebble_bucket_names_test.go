@@ -0,0 +1,98 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openBucketNameTestStore(t *testing.T, dir string, tenantScoped bool) *PebbleStore {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	store, err := NewPebbleStore(PebbleOptions{DataDir: dir, Logger: logger, TenantBucketNames: tenantScoped})
+	require.NoError(t, err)
+	return store
+}
+
+func TestPebbleStoreGlobalBucketNamespace(t *testing.T) {
+	store, cleanup := setupPebbleTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared", TenantID: "tenant-a"}))
+	assert.ErrorIs(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared", TenantID: "tenant-b"}), ErrBucketAlreadyExists)
+	assert.ErrorIs(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared"}), ErrBucketAlreadyExists)
+
+	got, err := store.GetBucketByName(ctx, "shared")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", got.TenantID)
+
+	// Deleting the bucket frees the name for other tenants
+	require.NoError(t, store.DeleteBucket(ctx, "tenant-a", "shared"))
+	_, err = store.GetBucketByName(ctx, "shared")
+	assert.ErrorIs(t, err, ErrBucketNotFound)
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared", TenantID: "tenant-b"}))
+
+	require.NoError(t, store.DeleteBucketIfEmpty(ctx, "tenant-b", "shared"))
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared", TenantID: "tenant-a"}))
+}
+
+func TestPebbleStoreTenantBucketNames(t *testing.T) {
+	dir, err := os.MkdirTemp("", "pebble-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	store := openBucketNameTestStore(t, dir, true)
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared", TenantID: "tenant-b"}))
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared", TenantID: "tenant-a"}))
+	assert.ErrorIs(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared", TenantID: "tenant-a"}), ErrBucketAlreadyExists)
+
+	got, err := store.GetBucketByName(ctx, "shared")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", got.TenantID, "the first owner in key order wins")
+
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared"}))
+	got, err = store.GetBucketByName(ctx, "shared")
+	require.NoError(t, err)
+	assert.Empty(t, got.TenantID, "the global bucket wins")
+	require.NoError(t, store.Close())
+
+	// Reopening in global mode rebuilds the index from the bucket records
+	// and refuses further duplicates
+	store = openBucketNameTestStore(t, dir, false)
+	defer store.Close()
+	owners, err := store.bucketNameOwners("shared")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "tenant-a", "tenant-b"}, owners)
+	assert.ErrorIs(t, store.CreateBucket(ctx, &BucketMetadata{Name: "shared", TenantID: "tenant-c"}), ErrBucketAlreadyExists)
+}
+
+func TestPebbleStoreBucketNameIndexRebuild(t *testing.T) {
+	dir, err := os.MkdirTemp("", "pebble-test-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+
+	store := openBucketNameTestStore(t, dir, false)
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "kept", TenantID: "tenant-a"}))
+	// Simulate a store written before the index existed plus a stale entry
+	require.NoError(t, store.db.Delete(bucketNameIndexKey("kept", "tenant-a"), nil))
+	require.NoError(t, store.db.Set(bucketNameIndexKey("gone", "tenant-a"), nil, nil))
+	require.NoError(t, store.Close())
+
+	store = openBucketNameTestStore(t, dir, false)
+	defer store.Close()
+	got, err := store.GetBucketByName(ctx, "kept")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", got.TenantID)
+	owners, err := store.bucketNameOwners("gone")
+	require.NoError(t, err)
+	assert.Empty(t, owners)
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "gone", TenantID: "tenant-b"}))
+}
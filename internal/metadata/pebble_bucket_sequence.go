@@ -0,0 +1,42 @@
+package metadata
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/cockroachdb/pebble/v2"
+)
+
+// GetBucketSequence returns the bucket's modification sequence, 0 for a
+// bucket whose objects were never written.
+func (s *PebbleStore) GetBucketSequence(ctx context.Context, bucket string) (uint64, error) {
+	data, err := s.pebbleGet(bucketSequenceKey(bucket))
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get bucket sequence: %w", err)
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("corrupt bucket sequence for %s", bucket)
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// bumpBucketSequence adds the increment of the bucket's modification
+// sequence to batch, so it commits together with the object change. The
+// caller holds the bucket mutation mutex, which keeps the read-increment
+// from racing another writer of the bucket.
+func (s *PebbleStore) bumpBucketSequence(batch *pebble.Batch, bucket string) error {
+	seq, err := s.GetBucketSequence(context.Background(), bucket)
+	if err != nil {
+		return err
+	}
+	var data [8]byte
+	binary.BigEndian.PutUint64(data[:], seq+1)
+	if err := batch.Set(bucketSequenceKey(bucket), data[:], nil); err != nil {
+		return fmt.Errorf("failed to set bucket sequence in batch: %w", err)
+	}
+	return nil
+}
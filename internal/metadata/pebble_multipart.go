@@ -123,6 +123,32 @@ func (s *PebbleStore) ListMultipartUploads(ctx context.Context, bucket, prefix s
 	return uploads, nil
 }
 
+// CountMultipartUploads counts in-progress uploads from the upload index
+// alone, without reading the uploads.
+func (s *PebbleStore) CountMultipartUploads(ctx context.Context, tenantID, bucket string) (int, error) {
+	prefix := multipartListPrefix(bucket)
+	if bucket == "" {
+		prefix = multipartTenantPrefix(tenantID)
+	}
+	iter, err := s.pebbleIter(prefix)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close() //nolint:errcheck
+
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		if err := scanCanceled(ctx, count); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		return 0, fmt.Errorf("failed during multipart count: %w", err)
+	}
+	return count, nil
+}
+
 // AbortMultipartUpload cancels a multipart upload and removes all its parts.
 func (s *PebbleStore) AbortMultipartUpload(ctx context.Context, uploadID string) error {
 	// Read the upload to get its bucket (needed for index key)
@@ -251,6 +277,9 @@ func (s *PebbleStore) CompleteMultipartUpload(ctx context.Context, uploadID stri
 	if err := batch.Delete(uploadKey, nil); err != nil {
 		return fmt.Errorf("failed to delete multipart upload in batch: %w", err)
 	}
+	if err := s.bumpBucketSequence(batch, obj.Bucket); err != nil {
+		return err
+	}
 
 	// Synced: completing an upload is the durability point the client paid
 	// for — the assembled object must survive a hard kill, and the upload
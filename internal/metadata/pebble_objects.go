@@ -93,6 +93,9 @@ func (s *PebbleStore) PutObject(ctx context.Context, obj *ObjectMetadata) error
 			return fmt.Errorf("failed to set tag index in batch: %w", err)
 		}
 	}
+	if err := s.bumpBucketSequence(batch, obj.Bucket); err != nil {
+		return err
+	}
 
 	if err := s.commitNoSync(batch); err != nil {
 		return fmt.Errorf("failed to commit object: %w", err)
@@ -119,8 +122,12 @@ func (s *PebbleStore) GetObject(ctx context.Context, bucket, key string, version
 	} else {
 		objKey = objectKey(bucket, key)
 	}
+	return getObjectFrom(s.db, objKey, bucket, key)
+}
 
-	data, err := s.pebbleGet(objKey)
+// getObjectFrom decodes the object metadata stored at objKey in r.
+func getObjectFrom(r pebble.Reader, objKey []byte, bucket, key string) (*ObjectMetadata, error) {
+	data, err := readerGet(r, objKey)
 	if err == pebble.ErrNotFound {
 		return nil, ErrObjectNotFound
 	}
@@ -149,6 +156,10 @@ func (s *PebbleStore) DeleteObject(ctx context.Context, bucket, key string, vers
 		return ErrInvalidKey
 	}
 
+	mu := s.getBucketMutationMutex(bucket)
+	mu.Lock()
+	defer mu.Unlock()
+
 	var objKey []byte
 	if len(versionID) > 0 && versionID[0] != "" {
 		objKey = objectVersionKey(bucket, key, versionID[0])
@@ -183,6 +194,9 @@ func (s *PebbleStore) DeleteObject(ctx context.Context, bucket, key string, vers
 	if err := batch.Delete(objKey, nil); err != nil {
 		return fmt.Errorf("failed to delete object in batch: %w", err)
 	}
+	if err := s.bumpBucketSequence(batch, bucket); err != nil {
+		return err
+	}
 
 	// Deletes are synced: the physical file is removed right after this
 	// commit, so losing the tombstone on a hard kill would leave a ghost
@@ -238,7 +252,13 @@ func (s *PebbleStore) ListObjects(ctx context.Context, bucket, prefix, marker st
 	// marker key) — returning the first key of the NEXT page instead would
 	// make marker-loop clients silently lose one object per page boundary.
 	var lastKey string
+	visited := 0
 	for ; valid; valid = iter.Next() {
+		if err := scanCanceled(ctx, visited); err != nil {
+			return nil, "", err
+		}
+		visited++
+
 		objKeyStr := extractObjectKeyFromKey(string(iter.Key()))
 
 		if !started {
@@ -278,6 +298,98 @@ func (s *PebbleStore) ListObjects(ctx context.Context, bucket, prefix, marker st
 	return objects, nextMarker, nil
 }
 
+// ListObjectsReverse lists objects in descending key order. It iterates the
+// key space backwards from the marker (exclusive) or the end of the prefix;
+// with a delimiter, keys inside a folder produce one common prefix and SeekLT
+// jumps below the whole folder, as ListObjectsDelimited does going forward.
+// NextMarker is the last object key or common prefix returned.
+func (s *PebbleStore) ListObjectsReverse(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*DelimitedListResult, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket name is required")
+	}
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+
+	var lower []byte
+	if prefix != "" {
+		lower = objectPrefixKey(bucket, prefix)
+	} else {
+		lower = objectListPrefix(bucket)
+	}
+
+	iter, err := s.pebbleIter(lower)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close() //nolint:errcheck
+
+	result := &DelimitedListResult{}
+	count := 0
+	var lastItem string
+
+	var valid bool
+	switch {
+	case marker == "":
+		valid = iter.Last()
+	case delimiter != "" && strings.HasSuffix(marker, delimiter):
+		// The marker is a common prefix returned by a previous page: resume
+		// below every key in it
+		valid = iter.SeekLT(objectPrefixKey(bucket, marker))
+	default:
+		valid = iter.SeekLT(objectKey(bucket, marker))
+	}
+
+	visited := 0
+	for valid {
+		if err := scanCanceled(ctx, visited); err != nil {
+			return nil, err
+		}
+		visited++
+
+		objKeyStr := extractObjectKeyFromKey(string(iter.Key()))
+
+		if delimiter != "" && len(objKeyStr) > len(prefix) {
+			remaining := objKeyStr[len(prefix):]
+			if delimIdx := strings.Index(remaining, delimiter); delimIdx >= 0 {
+				commonPrefix := prefix + remaining[:delimIdx+len(delimiter)]
+				if count >= maxKeys {
+					result.IsTruncated = true
+					result.NextMarker = lastItem
+					break
+				}
+				result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix)
+				lastItem = commonPrefix
+				count++
+				valid = iter.SeekLT(objectPrefixKey(bucket, commonPrefix))
+				continue
+			}
+		}
+
+		if count >= maxKeys {
+			result.IsTruncated = true
+			result.NextMarker = lastItem
+			break
+		}
+
+		var obj ObjectMetadata
+		if err := json.Unmarshal(iter.Value(), &obj); err != nil {
+			s.logger.WithError(err).Warn("Failed to unmarshal object metadata")
+			valid = iter.Prev()
+			continue
+		}
+		result.Objects = append(result.Objects, &obj)
+		lastItem = objKeyStr
+		count++
+		valid = iter.Prev()
+	}
+
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed during reverse object list: %w", err)
+	}
+	return result, nil
+}
+
 // ListObjectsDelimited lists objects with delimiter support using SeekGE to skip
 // entire common prefixes. When a key belongs to a "folder" (contains the delimiter
 // after the listing prefix), the iterator jumps past all keys sharing that common
@@ -332,6 +444,7 @@ func (s *PebbleStore) ListObjectsDelimited(ctx context.Context, bucket, prefix,
 		valid = iter.First()
 	}
 
+	visited := 0
 	for ; valid; valid = iter.Next() {
 		objKeyStr := extractObjectKeyFromKey(string(iter.Key()))
 
@@ -379,6 +492,12 @@ func (s *PebbleStore) ListObjectsDelimited(ctx context.Context, bucket, prefix,
 		}
 
 	processKey:
+		// Every visited key and every prefix skip passes through here
+		if err := scanCanceled(ctx, visited); err != nil {
+			return nil, err
+		}
+		visited++
+
 		// Re-extract key after potential SeekGE
 		objKeyStr = extractObjectKeyFromKey(string(iter.Key()))
 
@@ -474,51 +593,9 @@ func (s *PebbleStore) PutObjectVersion(ctx context.Context, obj *ObjectMetadata,
 	defer batch.Close() //nolint:errcheck
 
 	if version.IsLatest {
-		// Read existing versions and mark them as not-latest
-		prefix := []byte(fmt.Sprintf("version:%s:%s:", obj.Bucket, obj.Key))
-		iter, err := s.pebbleIter(prefix)
-		if err != nil {
+		if err := s.demoteLatestVersions(batch, obj.Bucket, obj.Key); err != nil {
 			return err
 		}
-
-		type versionUpdate struct {
-			key  []byte
-			data []byte
-		}
-		var updates []versionUpdate
-
-		for iter.First(); iter.Valid(); iter.Next() {
-			// Version entries are stored as ObjectMetadata (may be legacy ObjectVersion
-			// for older data — both formats share is_latest so unmarshal works for both).
-			var existing ObjectMetadata
-			if err := json.Unmarshal(iter.Value(), &existing); err != nil {
-				continue
-			}
-			if existing.Key != obj.Key {
-				continue
-			}
-			if existing.IsLatest {
-				existing.IsLatest = false
-				updatedData, err := json.Marshal(&existing)
-				if err != nil {
-					continue
-				}
-				keyCopy := make([]byte, len(iter.Key()))
-				copy(keyCopy, iter.Key())
-				updates = append(updates, versionUpdate{key: keyCopy, data: updatedData})
-			}
-		}
-		iterErr := iter.Error()
-		_ = iter.Close()
-		if iterErr != nil {
-			return fmt.Errorf("failed iterating versions: %w", iterErr)
-		}
-
-		for _, u := range updates {
-			if err := batch.Set(u.key, u.data, nil); err != nil {
-				s.logger.WithError(err).Warn("Failed to update existing version in batch")
-			}
-		}
 	}
 
 	// Store the new version as full ObjectMetadata (not ObjectVersion) so that
@@ -542,10 +619,63 @@ func (s *PebbleStore) PutObjectVersion(ctx context.Context, obj *ObjectMetadata,
 			return fmt.Errorf("failed to set object in batch: %w", err)
 		}
 	}
+	if err := s.bumpBucketSequence(batch, obj.Bucket); err != nil {
+		return err
+	}
 
 	return s.commitNoSync(batch)
 }
 
+// demoteLatestVersions adds to batch the updates marking the key's current
+// latest versions as not-latest. Caller holds the bucket mutation mutex.
+func (s *PebbleStore) demoteLatestVersions(batch *pebble.Batch, bucket, key string) error {
+	prefix := []byte(fmt.Sprintf("version:%s:%s:", bucket, key))
+	iter, err := s.pebbleIter(prefix)
+	if err != nil {
+		return err
+	}
+
+	type versionUpdate struct {
+		key  []byte
+		data []byte
+	}
+	var updates []versionUpdate
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		// Version entries are stored as ObjectMetadata (may be legacy ObjectVersion
+		// for older data — both formats share is_latest so unmarshal works for both).
+		var existing ObjectMetadata
+		if err := json.Unmarshal(iter.Value(), &existing); err != nil {
+			continue
+		}
+		if existing.Key != key {
+			continue
+		}
+		if existing.IsLatest {
+			existing.IsLatest = false
+			updatedData, err := json.Marshal(&existing)
+			if err != nil {
+				continue
+			}
+			keyCopy := make([]byte, len(iter.Key()))
+			copy(keyCopy, iter.Key())
+			updates = append(updates, versionUpdate{key: keyCopy, data: updatedData})
+		}
+	}
+	iterErr := iter.Error()
+	_ = iter.Close()
+	if iterErr != nil {
+		return fmt.Errorf("failed iterating versions: %w", iterErr)
+	}
+
+	for _, u := range updates {
+		if err := batch.Set(u.key, u.data, nil); err != nil {
+			s.logger.WithError(err).Warn("Failed to update existing version in batch")
+		}
+	}
+	return nil
+}
+
 // GetObjectVersions retrieves all versions of an object sorted newest-first.
 func (s *PebbleStore) GetObjectVersions(ctx context.Context, bucket, key string) ([]*ObjectVersion, error) {
 	prefix := []byte(fmt.Sprintf("version:%s:%s:", bucket, key))
@@ -556,7 +686,13 @@ func (s *PebbleStore) GetObjectVersions(ctx context.Context, bucket, key string)
 	defer iter.Close() //nolint:errcheck
 
 	var versions []*ObjectVersion
+	visited := 0
 	for iter.First(); iter.Valid(); iter.Next() {
+		if err := scanCanceled(ctx, visited); err != nil {
+			return nil, err
+		}
+		visited++
+
 		val := iter.Value()
 		valCopy := make([]byte, len(val))
 		copy(valCopy, val)
@@ -592,18 +728,30 @@ func (s *PebbleStore) GetObjectVersions(ctx context.Context, bucket, key string)
 	return versions, nil
 }
 
-// ListAllObjectVersions lists all versions of all objects in a bucket.
+// ListAllObjectVersions lists all versions of all objects in a bucket. Both
+// passes read the same snapshot, so an overwrite that moves an object into a
+// version entry between them can't list the object twice or not at all.
 func (s *PebbleStore) ListAllObjectVersions(ctx context.Context, bucket, prefix string, maxKeys int) ([]*ObjectVersion, error) {
 	var allVersions []*ObjectVersion
 	keysWithVersions := make(map[string]bool)
 
+	snap := s.listSnapshot()
+	defer snap.Close() //nolint:errcheck
+
 	// First pass: collect versioned entries
 	versionPrefix := []byte(fmt.Sprintf("version:%s:", bucket))
-	vIter, err := s.pebbleIter(versionPrefix)
+	vIter, err := readerIter(snap, versionPrefix)
 	if err != nil {
 		return nil, err
 	}
+	visited := 0
 	for vIter.First(); vIter.Valid(); vIter.Next() {
+		if err := scanCanceled(ctx, visited); err != nil {
+			_ = vIter.Close()
+			return nil, err
+		}
+		visited++
+
 		val := vIter.Value()
 		valCopy := make([]byte, len(val))
 		copy(valCopy, val)
@@ -641,11 +789,17 @@ func (s *PebbleStore) ListAllObjectVersions(ctx context.Context, bucket, prefix
 	// Second pass: non-versioned objects (no version entry, just an obj: entry)
 	if maxKeys <= 0 || len(allVersions) < maxKeys {
 		objectPrefix := []byte(fmt.Sprintf("obj:%s:", bucket))
-		oIter, err := s.pebbleIter(objectPrefix)
+		oIter, err := readerIter(snap, objectPrefix)
 		if err != nil {
 			return nil, err
 		}
 		for oIter.First(); oIter.Valid(); oIter.Next() {
+			if err := scanCanceled(ctx, visited); err != nil {
+				_ = oIter.Close()
+				return nil, err
+			}
+			visited++
+
 			val := oIter.Value()
 			valCopy := make([]byte, len(val))
 			copy(valCopy, val)
@@ -760,6 +914,10 @@ func (s *PebbleStore) HasActiveComplianceRetention(ctx context.Context, bucket s
 
 // DeleteObjectVersion removes a specific version of an object.
 func (s *PebbleStore) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	mu := s.getBucketMutationMutex(bucket)
+	mu.Lock()
+	defer mu.Unlock()
+
 	versionKey := objectVersionKey(bucket, key, versionID)
 
 	if _, closer, err := s.db.Get(versionKey); err == pebble.ErrNotFound {
@@ -770,7 +928,98 @@ func (s *PebbleStore) DeleteObjectVersion(ctx context.Context, bucket, key, vers
 		_ = closer.Close()
 	}
 
-	return s.db.Delete(versionKey, pebble.Sync)
+	batch := s.db.NewBatch()
+	defer batch.Close() //nolint:errcheck
+
+	if err := batch.Delete(versionKey, nil); err != nil {
+		return fmt.Errorf("failed to delete version in batch: %w", err)
+	}
+
+	// When this is the current version, move the object entry in the same
+	// batch: readers resolve GETs without a version ID through it, so it must
+	// never point at a version that is already gone.
+	objKey := objectKey(bucket, key)
+	currentData, err := s.pebbleGet(objKey)
+	if err != nil && err != pebble.ErrNotFound {
+		return fmt.Errorf("failed to get object: %w", err)
+	}
+	var current ObjectMetadata
+	if err == nil {
+		if err := json.Unmarshal(currentData, &current); err != nil {
+			return fmt.Errorf("failed to unmarshal object: %w", err)
+		}
+	}
+	if err == nil && current.VersionID == versionID {
+		next, err := s.nextLatestVersion(bucket, key, versionID)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			for tagKey, tagValue := range current.Tags {
+				if err := batch.Delete(tagIndexKey(bucket, tagKey, tagValue, key), nil); err != nil {
+					s.logger.WithError(err).Warn("Failed to delete tag index in batch")
+				}
+			}
+			if err := batch.Delete(objKey, nil); err != nil {
+				return fmt.Errorf("failed to delete object in batch: %w", err)
+			}
+		} else {
+			next.IsLatest = true
+			nextData, err := json.Marshal(next)
+			if err != nil {
+				return fmt.Errorf("failed to marshal version: %w", err)
+			}
+			if err := batch.Set(objectVersionKey(bucket, key, next.VersionID), nextData, nil); err != nil {
+				return fmt.Errorf("failed to set version in batch: %w", err)
+			}
+			if err := batch.Set(objKey, nextData, nil); err != nil {
+				return fmt.Errorf("failed to set object in batch: %w", err)
+			}
+		}
+	}
+	if err := s.bumpBucketSequence(batch, bucket); err != nil {
+		return err
+	}
+
+	return batch.Commit(pebble.Sync)
+}
+
+// nextLatestVersion returns the most recent version of key other than
+// excludeVersionID, or nil when there is none. Caller holds the bucket
+// mutation mutex.
+func (s *PebbleStore) nextLatestVersion(bucket, key, excludeVersionID string) (*ObjectMetadata, error) {
+	iter, err := s.pebbleIter([]byte(fmt.Sprintf("version:%s:%s:", bucket, key)))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close() //nolint:errcheck
+
+	var next *ObjectMetadata
+	for iter.First(); iter.Valid(); iter.Next() {
+		var obj ObjectMetadata
+		if err := json.Unmarshal(iter.Value(), &obj); err != nil {
+			continue
+		}
+		if obj.Key != key || obj.VersionID == excludeVersionID {
+			continue
+		}
+		if next == nil || obj.LastModified.After(next.LastModified) {
+			candidate := obj
+			next = &candidate
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed iterating versions: %w", err)
+	}
+	if next != nil {
+		if next.Bucket == "" {
+			next.Bucket = bucket
+		}
+		if next.Key == "" {
+			next.Key = key
+		}
+	}
+	return next, nil
 }
 
 // ==================== Tags ====================
@@ -859,9 +1108,14 @@ func (s *PebbleStore) ListObjectsByTags(ctx context.Context, bucket string, tags
 		break
 	}
 
+	// The index scan and the object lookups share one snapshot, so a candidate
+	// is never resolved against a write made after the index was read.
+	snap := s.listSnapshot()
+	defer snap.Close() //nolint:errcheck
+
 	idxPrefix := tagIndexPrefix(bucket, firstTagKey, firstTagValue)
 	idxPrefixStr := string(idxPrefix)
-	iter, err := s.pebbleIter(idxPrefix)
+	iter, err := readerIter(snap, idxPrefix)
 	if err != nil {
 		return nil, err
 	}
@@ -881,7 +1135,7 @@ func (s *PebbleStore) ListObjectsByTags(ctx context.Context, bucket string, tags
 
 	var objects []*ObjectMetadata
 	for _, objKey := range candidateKeys {
-		obj, err := s.GetObject(ctx, bucket, objKey)
+		obj, err := getObjectFrom(snap, objectKey(bucket, objKey), bucket, objKey)
 		if err != nil {
 			continue
 		}
@@ -949,6 +1203,9 @@ func (s *PebbleStore) searchObjectsByScan(ctx context.Context, bucket, prefix, m
 			nextMarker = lastKey
 			break
 		}
+		if err := scanCanceled(ctx, scanned); err != nil {
+			return nil, "", err
+		}
 		scanned++
 
 		objKeyStr := extractObjectKeyFromKey(string(iter.Key()))
@@ -997,9 +1254,12 @@ func (s *PebbleStore) searchObjectsWithTags(ctx context.Context, bucket, prefix,
 		break
 	}
 
+	snap := s.listSnapshot()
+	defer snap.Close() //nolint:errcheck
+
 	idxPrefix := tagIndexPrefix(bucket, firstTagKey, firstTagValue)
 	idxPrefixStr := string(idxPrefix)
-	iter, err := s.pebbleIter(idxPrefix)
+	iter, err := readerIter(snap, idxPrefix)
 	if err != nil {
 		return nil, "", err
 	}
@@ -1033,7 +1293,7 @@ func (s *PebbleStore) searchObjectsWithTags(ctx context.Context, bucket, prefix,
 			nextMarker = objKey
 			break
 		}
-		obj, err := s.GetObject(ctx, bucket, objKey)
+		obj, err := getObjectFrom(snap, objectKey(bucket, objKey), bucket, objKey)
 		if err != nil {
 			continue
 		}
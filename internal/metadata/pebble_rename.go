@@ -0,0 +1,131 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/pebble/v2"
+)
+
+// RenameObject stores dst as the current object of its key and retires
+// srcKey in one batch, so no reader sees the object under both keys or under
+// neither. With srcMarker (a versioned bucket) dst becomes the latest version
+// of its key and srcMarker, a delete marker, the latest version of srcKey:
+// the history of both keys is kept. Without srcMarker, srcKey's entry is
+// deleted.
+func (s *PebbleStore) RenameObject(ctx context.Context, srcKey string, dst, srcMarker *ObjectMetadata) error {
+	if dst == nil || dst.Bucket == "" || dst.Key == "" || srcKey == "" || srcKey == dst.Key {
+		return ErrInvalidKey
+	}
+	bucket := dst.Bucket
+	if srcMarker != nil && (srcMarker.Bucket != bucket || srcMarker.Key != srcKey || srcMarker.VersionID == "") {
+		return ErrInvalidKey
+	}
+	if srcMarker != nil && dst.VersionID == "" {
+		return fmt.Errorf("versioned rename needs a version ID for %s", dst.Key)
+	}
+
+	mu := s.getBucketMutationMutex(bucket)
+	mu.Lock()
+	defer mu.Unlock()
+	if err := s.rejectWriteToDeletedBucket(bucket); err != nil {
+		return err
+	}
+
+	srcData, err := s.pebbleGet(objectKey(bucket, srcKey))
+	if err == pebble.ErrNotFound {
+		return ErrObjectNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get object: %w", err)
+	}
+	var src ObjectMetadata
+	if err := json.Unmarshal(srcData, &src); err != nil {
+		return fmt.Errorf("failed to unmarshal object: %w", err)
+	}
+
+	now := time.Now()
+	dst.CreatedAt = now
+	dst.UpdatedAt = now
+	if dst.LastModified.IsZero() {
+		dst.LastModified = now
+	}
+
+	batch := s.db.NewBatch()
+	defer batch.Close() //nolint:errcheck
+
+	// Both current entries are replaced, and their tag indices with them
+	for tagKey, tagValue := range src.Tags {
+		if err := batch.Delete(tagIndexKey(bucket, tagKey, tagValue, srcKey), nil); err != nil {
+			return fmt.Errorf("failed to delete old tag index: %w", err)
+		}
+	}
+	if existingData, err := s.pebbleGet(objectKey(bucket, dst.Key)); err == nil {
+		var existing ObjectMetadata
+		if err := json.Unmarshal(existingData, &existing); err == nil {
+			for tagKey, tagValue := range existing.Tags {
+				if err := batch.Delete(tagIndexKey(bucket, tagKey, tagValue, dst.Key), nil); err != nil {
+					return fmt.Errorf("failed to delete old tag index: %w", err)
+				}
+			}
+		}
+	} else if err != pebble.ErrNotFound {
+		return fmt.Errorf("failed to get existing object: %w", err)
+	}
+
+	if srcMarker != nil {
+		for _, latest := range []*ObjectMetadata{srcMarker, dst} {
+			if err := s.setLatestVersion(batch, latest); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := batch.Delete(objectKey(bucket, srcKey), nil); err != nil {
+			return fmt.Errorf("failed to delete object in batch: %w", err)
+		}
+		data, err := json.Marshal(dst)
+		if err != nil {
+			return fmt.Errorf("failed to marshal object: %w", err)
+		}
+		if err := batch.Set(objectKey(bucket, dst.Key), data, nil); err != nil {
+			return fmt.Errorf("failed to set object in batch: %w", err)
+		}
+	}
+
+	for tagKey, tagValue := range dst.Tags {
+		if err := batch.Set(tagIndexKey(bucket, tagKey, tagValue, dst.Key), []byte{}, nil); err != nil {
+			return fmt.Errorf("failed to set tag index in batch: %w", err)
+		}
+	}
+	if err := s.bumpBucketSequence(batch, bucket); err != nil {
+		return err
+	}
+
+	// Synced like a delete: the source data may be removed right after
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return fmt.Errorf("failed to commit rename: %w", err)
+	}
+	return nil
+}
+
+// setLatestVersion adds to batch the writes making obj the latest version of
+// its key. Caller holds the bucket mutation mutex.
+func (s *PebbleStore) setLatestVersion(batch *pebble.Batch, obj *ObjectMetadata) error {
+	if err := s.demoteLatestVersions(batch, obj.Bucket, obj.Key); err != nil {
+		return err
+	}
+	obj.IsLatest = true
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal version: %w", err)
+	}
+	if err := batch.Set(objectVersionKey(obj.Bucket, obj.Key, obj.VersionID), data, nil); err != nil {
+		return fmt.Errorf("failed to set version in batch: %w", err)
+	}
+	if err := batch.Set(objectKey(obj.Bucket, obj.Key), data, nil); err != nil {
+		return fmt.Errorf("failed to set object in batch: %w", err)
+	}
+	return nil
+}
@@ -26,18 +26,19 @@ import (
 // a delete tombstone would leave a ghost listing entry pointing at a removed
 // file, which no fallback can serve.
 type PebbleStore struct {
-	db               *pebble.DB
-	ready            atomic.Bool
-	logger           *logrus.Logger
-	bucketMetricsMu  sync.Map   // map[string]*sync.Mutex — one per bucket key
-	bucketMutationMu sync.Map   // map[string]*sync.Mutex — serializes object writes with bucket deletion
-	deletedBuckets   sync.Map   // map[string]struct{} — buckets deleted during this process lifetime
-	bucketCreateMu   sync.Mutex // serializes bucket creation for global uniqueness check
-	stopCh           chan struct{}
-	dbPath           string
-	walDirty         atomic.Bool // unsynced NoSync writes since the last WAL fsync
-	walSyncWG        sync.WaitGroup
-	wasCleanShutdown bool
+	db                *pebble.DB
+	ready             atomic.Bool
+	logger            *logrus.Logger
+	bucketMetricsMu   sync.Map   // map[string]*sync.Mutex — one per bucket key
+	bucketMutationMu  sync.Map   // map[string]*sync.Mutex — serializes object writes with bucket deletion
+	deletedBuckets    sync.Map   // map[string]struct{} — buckets deleted during this process lifetime
+	bucketCreateMu    sync.Mutex // serializes bucket creation for global uniqueness check
+	tenantBucketNames bool       // bucket names only need to be unique within a tenant
+	stopCh            chan struct{}
+	dbPath            string
+	walDirty          atomic.Bool // unsynced NoSync writes since the last WAL fsync
+	walSyncWG         sync.WaitGroup
+	wasCleanShutdown  bool
 }
 
 // PebbleOptions contains configuration options for PebbleStore
@@ -49,6 +50,10 @@ type PebbleOptions struct {
 	// bounding metadata loss on a hard kill. 0 uses the 1s default; a
 	// negative value disables the loop (tests).
 	WALSyncInterval time.Duration
+	// TenantBucketNames lets different tenants create buckets with the same
+	// name. By default bucket names are unique across all tenants (one global
+	// namespace, as in AWS).
+	TenantBucketNames bool
 }
 
 // defaultWALSyncInterval bounds hard-kill metadata loss to ~1s at the cost of
@@ -127,11 +132,16 @@ func NewPebbleStore(opts PebbleOptions) (*PebbleStore, error) {
 	}
 
 	store := &PebbleStore{
-		db:               db,
-		logger:           opts.Logger,
-		stopCh:           make(chan struct{}),
-		dbPath:           dbPath,
-		wasCleanShutdown: wasClean,
+		db:                db,
+		logger:            opts.Logger,
+		stopCh:            make(chan struct{}),
+		dbPath:            dbPath,
+		wasCleanShutdown:  wasClean,
+		tenantBucketNames: opts.TenantBucketNames,
+	}
+	if err := store.rebuildBucketNameIndex(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to build bucket name index: %w", err)
 	}
 	store.ready.Store(true)
 
@@ -202,6 +212,18 @@ func (s *PebbleStore) setNoSync(key, value []byte) error {
 	return err
 }
 
+// SyncWAL makes every write committed so far durable, including the ones
+// made through the NoSync helpers. Callers that must not lose a write to a
+// crash (e.g. a secret files are named with) call it after the write.
+func (s *PebbleStore) SyncWAL() error {
+	s.walDirty.Store(false)
+	if err := s.db.LogData(nil, pebble.Sync); err != nil {
+		s.walDirty.Store(true)
+		return err
+	}
+	return nil
+}
+
 func (s *PebbleStore) commitNoSync(batch *pebble.Batch) error {
 	err := batch.Commit(pebble.NoSync)
 	if err == nil {
@@ -228,7 +250,27 @@ func prefixEnd(prefix []byte) []byte {
 
 // pebbleGet reads a single key from Pebble and returns a safe copy of the value.
 func (s *PebbleStore) pebbleGet(key []byte) ([]byte, error) {
-	val, closer, err := s.db.Get(key)
+	return readerGet(s.db, key)
+}
+
+// pebbleIter creates a prefix-bounded iterator over [lower, prefixEnd(lower)).
+// A single iterator reads at the sequence number current when it was created,
+// so one scan never observes writes committed while it runs.
+func (s *PebbleStore) pebbleIter(lower []byte) (*pebble.Iterator, error) {
+	return readerIter(s.db, lower)
+}
+
+// listSnapshot pins the current state of the store for a listing that needs
+// more than one read — several iterators, or an index scan followed by point
+// lookups. Reads through the snapshot all see the same point in time; the
+// caller must Close it.
+func (s *PebbleStore) listSnapshot() *pebble.Snapshot {
+	return s.db.NewSnapshot()
+}
+
+// readerGet is pebbleGet against an arbitrary reader (the DB or a snapshot).
+func readerGet(r pebble.Reader, key []byte) ([]byte, error) {
+	val, closer, err := r.Get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -238,10 +280,10 @@ func (s *PebbleStore) pebbleGet(key []byte) ([]byte, error) {
 	return data, nil
 }
 
-// pebbleIter creates a prefix-bounded iterator over [lower, prefixEnd(lower)).
-func (s *PebbleStore) pebbleIter(lower []byte) (*pebble.Iterator, error) {
+// readerIter is pebbleIter against an arbitrary reader (the DB or a snapshot).
+func readerIter(r pebble.Reader, lower []byte) (*pebble.Iterator, error) {
 	upper := prefixEnd(lower)
-	iter, err := s.db.NewIter(&pebble.IterOptions{
+	iter, err := r.NewIter(&pebble.IterOptions{
 		LowerBound: lower,
 		UpperBound: upper,
 	})
@@ -251,9 +293,25 @@ func (s *PebbleStore) pebbleIter(lower []byte) (*pebble.Iterator, error) {
 	return iter, nil
 }
 
+// scanCancelCheckInterval is how many keys a scan visits between checks of its
+// context. Checking every key would cost more than the keys themselves; every
+// few hundred is enough for a disconnected client or an expired request
+// deadline to stop a long listing promptly.
+const scanCancelCheckInterval = 256
+
+// scanCanceled returns ctx's error once visited reaches a multiple of
+// scanCancelCheckInterval (including 0) and the context is done.
+func scanCanceled(ctx context.Context, visited int) error {
+	if visited%scanCancelCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
+
 // ==================== Bucket Operations ====================
 
-// CreateBucket creates a new bucket with global name uniqueness enforced.
+// CreateBucket creates a new bucket. Bucket names are unique across all
+// tenants unless the store was opened with TenantBucketNames.
 func (s *PebbleStore) CreateBucket(ctx context.Context, bucket *BucketMetadata) error {
 	if bucket == nil {
 		return fmt.Errorf("bucket metadata cannot be nil")
@@ -273,23 +331,14 @@ func (s *PebbleStore) CreateBucket(ctx context.Context, bucket *BucketMetadata)
 	}
 
 	// Check global uniqueness — bucket names must be unique across all tenants
-	prefix := []byte("bucket:")
-	iter, err := s.pebbleIter(prefix)
-	if err != nil {
-		return err
-	}
-	defer iter.Close() //nolint:errcheck
-
-	for iter.First(); iter.Valid(); iter.Next() {
-		var existing BucketMetadata
-		if jsonErr := json.Unmarshal(iter.Value(), &existing); jsonErr == nil {
-			if existing.Name == bucket.Name {
-				return ErrBucketAlreadyExists
-			}
+	if !s.tenantBucketNames {
+		owners, err := s.bucketNameOwners(bucket.Name)
+		if err != nil {
+			return err
+		}
+		if len(owners) > 0 {
+			return ErrBucketAlreadyExists
 		}
-	}
-	if err := iter.Error(); err != nil {
-		return fmt.Errorf("failed during bucket uniqueness check: %w", err)
 	}
 
 	now := time.Now()
@@ -303,7 +352,15 @@ func (s *PebbleStore) CreateBucket(ctx context.Context, bucket *BucketMetadata)
 		return fmt.Errorf("failed to marshal bucket: %w", err)
 	}
 
-	if err := s.db.Set(key, data, pebble.Sync); err != nil {
+	batch := s.db.NewBatch()
+	defer batch.Close() //nolint:errcheck
+	if err := batch.Set(key, data, nil); err != nil {
+		return fmt.Errorf("failed to store bucket: %w", err)
+	}
+	if err := batch.Set(bucketNameIndexKey(bucket.Name, bucket.TenantID), nil, nil); err != nil {
+		return fmt.Errorf("failed to index bucket name: %w", err)
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
 		return fmt.Errorf("failed to store bucket: %w", err)
 	}
 	s.deletedBuckets.Delete(bucketPathForMutation(bucket.TenantID, bucket.Name))
@@ -341,16 +398,33 @@ func (s *PebbleStore) UpdateBucket(ctx context.Context, bucket *BucketMetadata)
 	}
 
 	key := bucketKey(bucket.TenantID, bucket.Name)
-	if _, closer, err := s.db.Get(key); err == pebble.ErrNotFound {
+
+	// Serialized with UpdateBucketMetrics and other updates so the revision
+	// check and bump are atomic
+	mu := s.getBucketMetricsMutex(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := s.pebbleGet(key)
+	if err == pebble.ErrNotFound {
 		return ErrBucketNotFound
-	} else if err != nil {
+	}
+	if err != nil {
 		return fmt.Errorf("failed to check bucket existence: %w", err)
-	} else {
-		_ = closer.Close()
+	}
+	var current struct {
+		Revision int64 `json:"revision"`
+	}
+	if err := json.Unmarshal(data, &current); err != nil {
+		return fmt.Errorf("failed to unmarshal bucket: %w", err)
+	}
+	if expected, ok := ExpectedBucketRevision(ctx); ok && expected != current.Revision {
+		return ErrBucketModified
 	}
 
+	bucket.Revision = current.Revision + 1
 	bucket.UpdatedAt = time.Now()
-	data, err := json.Marshal(bucket)
+	data, err = json.Marshal(bucket)
 	if err != nil {
 		return fmt.Errorf("failed to marshal bucket: %w", err)
 	}
@@ -378,8 +452,8 @@ func (s *PebbleStore) DeleteBucket(ctx context.Context, tenantID, name string) e
 		_ = closer.Close()
 	}
 
-	if err := s.db.Delete(key, pebble.Sync); err != nil {
-		return fmt.Errorf("failed to delete bucket: %w", err)
+	if err := s.deleteBucketRecord(tenantID, name); err != nil {
+		return err
 	}
 	s.deletedBuckets.Store(bucketPath, struct{}{})
 
@@ -431,8 +505,8 @@ func (s *PebbleStore) DeleteBucketIfEmpty(ctx context.Context, tenantID, name st
 		return ErrBucketNotEmpty
 	}
 
-	if err := s.db.Delete(key, pebble.Sync); err != nil {
-		return fmt.Errorf("failed to delete bucket: %w", err)
+	if err := s.deleteBucketRecord(tenantID, name); err != nil {
+		return err
 	}
 	s.deletedBuckets.Store(bucketPath, struct{}{})
 
@@ -474,24 +548,20 @@ func (s *PebbleStore) ListBuckets(ctx context.Context, tenantID string) ([]*Buck
 	return buckets, nil
 }
 
-// GetBucketByName finds a bucket by name across all tenants.
+// GetBucketByName finds a bucket by name across all tenants. When bucket
+// names are tenant-scoped and several tenants share a name, the global
+// bucket wins, then the first tenant in key order.
 func (s *PebbleStore) GetBucketByName(ctx context.Context, name string) (*BucketMetadata, error) {
-	iter, err := s.pebbleIter([]byte("bucket:"))
+	owners, err := s.bucketNameOwners(name)
 	if err != nil {
 		return nil, err
 	}
-	defer iter.Close() //nolint:errcheck
-
-	for iter.First(); iter.Valid(); iter.Next() {
-		var bucket BucketMetadata
-		if err := json.Unmarshal(iter.Value(), &bucket); err == nil {
-			if bucket.Name == name {
-				return &bucket, nil
-			}
+	for _, tenantID := range owners {
+		bucket, err := s.GetBucket(ctx, tenantID, name)
+		if err == ErrBucketNotFound {
+			continue
 		}
-	}
-	if err := iter.Error(); err != nil {
-		return nil, fmt.Errorf("failed during bucket scan: %w", err)
+		return bucket, err
 	}
 	return nil, ErrBucketNotFound
 }
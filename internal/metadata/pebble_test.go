@@ -81,6 +81,33 @@ func TestPebbleStoreBucketOperations(t *testing.T) {
 		assert.Equal(t, int64(512), bkt.TotalSize)
 	})
 
+	t.Run("ConditionalUpdateBucket", func(t *testing.T) {
+		bkt, err := store.GetBucket(ctx, "tenant1", "pebble-bucket")
+		require.NoError(t, err)
+		revision := bkt.Revision
+
+		bkt.Tags = map[string]string{"env": "prod"}
+		require.NoError(t, store.UpdateBucket(WithExpectedBucketRevision(ctx, revision), bkt))
+		assert.Equal(t, revision+1, bkt.Revision)
+
+		// A second writer that read the same revision is refused
+		stale, err := store.GetBucket(ctx, "tenant1", "pebble-bucket")
+		require.NoError(t, err)
+		stale.Tags = map[string]string{"env": "dev"}
+		assert.ErrorIs(t, store.UpdateBucket(WithExpectedBucketRevision(ctx, revision), stale), ErrBucketModified)
+
+		// Metric updates don't change the revision
+		require.NoError(t, store.UpdateBucketMetrics(ctx, "tenant1", "pebble-bucket", 1, 1))
+		got, err := store.GetBucket(ctx, "tenant1", "pebble-bucket")
+		require.NoError(t, err)
+		assert.Equal(t, revision+1, got.Revision)
+		assert.Equal(t, "prod", got.Tags["env"])
+
+		// Unconditional updates always apply
+		require.NoError(t, store.UpdateBucket(ctx, stale))
+		assert.Equal(t, revision+2, stale.Revision)
+	})
+
 	t.Run("ListBuckets", func(t *testing.T) {
 		bkt2 := &BucketMetadata{Name: "pebble-bucket-2", TenantID: "tenant1", OwnerID: "u", OwnerType: "user"}
 		require.NoError(t, store.CreateBucket(ctx, bkt2))
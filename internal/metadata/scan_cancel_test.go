@@ -0,0 +1,108 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// cancelAfterChecks is a context that cancels itself once Err has been
+// called a given number of times, i.e. partway through a scan.
+type cancelAfterChecks struct {
+	context.Context
+	cancel    context.CancelFunc
+	remaining int
+}
+
+func newCancelAfterChecks(checks int) *cancelAfterChecks {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &cancelAfterChecks{Context: ctx, cancel: cancel, remaining: checks}
+}
+
+func (c *cancelAfterChecks) Err() error {
+	if c.remaining == 0 {
+		c.cancel()
+	}
+	c.remaining--
+	return c.Context.Err()
+}
+
+func setupScanCancelStore(t *testing.T, objects int) *PebbleStore {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "pebble-scan-cancel-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewPebbleStore(PebbleOptions{DataDir: dir, WALSyncInterval: -1})
+	if err != nil {
+		os.RemoveAll(dir) //nolint:errcheck
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		store.Close()     //nolint:errcheck
+		os.RemoveAll(dir) //nolint:errcheck
+	})
+
+	ctx := context.Background()
+	if err := store.CreateBucket(ctx, &BucketMetadata{Name: "scanbkt"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < objects; i++ {
+		key := fmt.Sprintf("dir-%02d/obj-%05d", i%20, i)
+		if err := store.PutObject(ctx, &ObjectMetadata{Bucket: "scanbkt", Key: key, Size: 1, ETag: "e"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+// TestListObjectsStopsWhenContextCancelled cancels the context while the scan
+// is underway and checks the listing stops early with the context error
+// instead of filling the page.
+func TestListObjectsStopsWhenContextCancelled(t *testing.T) {
+	store := setupScanCancelStore(t, 2000)
+
+	// The first two checks (keys 0 and 256) pass, the third (key 512) fails
+	ctx := newCancelAfterChecks(2)
+	objects, nextMarker, err := store.ListObjects(ctx, "scanbkt", "", "", 1000)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if objects != nil || nextMarker != "" {
+		t.Fatalf("cancelled listing returned %d objects, marker %q", len(objects), nextMarker)
+	}
+	if ctx.remaining != -1 {
+		t.Fatalf("scan kept checking the context after cancellation (%d checks left)", ctx.remaining)
+	}
+}
+
+func TestScansReturnContextErrorWhenCancelled(t *testing.T) {
+	store := setupScanCancelStore(t, 300)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := store.ListObjectsDelimited(ctx, "scanbkt", "", "/", "", 1000); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListObjectsDelimited: expected context.Canceled, got %v", err)
+	}
+	if _, err := store.ListAllObjectVersions(ctx, "scanbkt", "", 1000); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListAllObjectVersions: expected context.Canceled, got %v", err)
+	}
+	if _, _, err := store.SearchObjects(ctx, "scanbkt", "", "", 1000, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("SearchObjects: expected context.Canceled, got %v", err)
+	}
+
+	// An expired deadline stops a scan the same way
+	deadlineCtx, cancelDeadline := context.WithTimeout(context.Background(), 0)
+	defer cancelDeadline()
+	if _, _, err := store.ListObjects(deadlineCtx, "scanbkt", "", "", 1000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListObjects: expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// A live context still lists everything
+	objects, _, err := store.ListObjects(context.Background(), "scanbkt", "", "", 1000)
+	if err != nil || len(objects) != 300 {
+		t.Fatalf("expected 300 objects, got %d (err %v)", len(objects), err)
+	}
+}
@@ -18,8 +18,25 @@ var (
 	ErrUploadNotFound      = errors.New("multipart upload not found")
 	ErrPartNotFound        = errors.New("part not found")
 	ErrVersionNotFound     = errors.New("version not found")
+	ErrBucketModified      = errors.New("bucket was modified concurrently")
 )
 
+type expectedBucketRevisionKey struct{}
+
+// WithExpectedBucketRevision makes the next UpdateBucket conditional on the
+// bucket still being at revision: if it was updated since, the write is
+// refused with ErrBucketModified instead of overwriting that update.
+func WithExpectedBucketRevision(ctx context.Context, revision int64) context.Context {
+	return context.WithValue(ctx, expectedBucketRevisionKey{}, revision)
+}
+
+// ExpectedBucketRevision returns the revision set by WithExpectedBucketRevision,
+// if any.
+func ExpectedBucketRevision(ctx context.Context) (int64, bool) {
+	revision, ok := ctx.Value(expectedBucketRevisionKey{}).(int64)
+	return revision, ok
+}
+
 // DelimitedListResult holds the result of a delimiter-aware listing.
 type DelimitedListResult struct {
 	Objects        []*ObjectMetadata
@@ -61,6 +78,10 @@ type Store interface {
 	// UpdateBucketMetrics atomically updates bucket metrics (object count, total size)
 	UpdateBucketMetrics(ctx context.Context, tenantID, bucketName string, objectCountDelta, sizeDelta int64) error
 
+	// GetBucketSequence returns the bucket's modification sequence, which every
+	// object or version write and delete in the bucket increments
+	GetBucketSequence(ctx context.Context, bucket string) (uint64, error)
+
 	// ==================== Object Operations ====================
 
 	// PutObject stores metadata for an object (creates or updates)
@@ -72,7 +93,9 @@ type Store interface {
 	// DeleteObject deletes an object's metadata
 	DeleteObject(ctx context.Context, bucket, key string, versionID ...string) error
 
-	// ListObjects lists objects in a bucket with optional prefix and pagination
+	// ListObjects lists objects in a bucket with optional prefix and pagination.
+	// Each call reads one point-in-time view of the bucket; successive pages
+	// are separate reads, so a marker loop sees writes made between calls.
 	ListObjects(ctx context.Context, bucket, prefix, marker string, maxKeys int) ([]*ObjectMetadata, string, error)
 
 	// ListObjectsDelimited lists objects with delimiter support, returning objects at the
@@ -80,12 +103,24 @@ type Store interface {
 	// entire common prefixes for O(results) instead of O(total objects) performance.
 	ListObjectsDelimited(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*DelimitedListResult, error)
 
+	// ListObjectsReverse lists objects in descending key order, with optional
+	// delimiter grouping. marker is an exclusive upper bound: the next page
+	// starts right below it.
+	ListObjectsReverse(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*DelimitedListResult, error)
+
 	// SearchObjects searches objects with filters, returning matching objects with pagination
 	SearchObjects(ctx context.Context, bucket, prefix, marker string, maxKeys int, filter *ObjectFilter) ([]*ObjectMetadata, string, error)
 
 	// ObjectExists checks if an object exists
 	ObjectExists(ctx context.Context, bucket, key string) (bool, error)
 
+	// RenameObject atomically makes dst the current object of its key and
+	// retires srcKey in dst's bucket: srcMarker, when given, becomes srcKey's
+	// latest version (a delete marker) and dst the latest version of its key;
+	// otherwise srcKey's entry is deleted. Returns ErrObjectNotFound when
+	// srcKey has no current entry.
+	RenameObject(ctx context.Context, srcKey string, dst, srcMarker *ObjectMetadata) error
+
 	// ==================== Object Versioning ====================
 
 	// PutObjectVersion stores a new version of an object
@@ -102,7 +137,9 @@ type Store interface {
 	// This is used to prevent bucket deletion when immutable data is present.
 	HasActiveComplianceRetention(ctx context.Context, bucket string) (bool, error)
 
-	// DeleteObjectVersion deletes a specific version of an object
+	// DeleteObjectVersion deletes a specific version of an object. Deleting
+	// the current version moves the object entry to the next most recent
+	// version, or removes it with the last one, atomically with the delete.
 	DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error
 
 	// ==================== Multipart Uploads ====================
@@ -116,6 +153,10 @@ type Store interface {
 	// ListMultipartUploads lists all in-progress multipart uploads for a bucket
 	ListMultipartUploads(ctx context.Context, bucket, prefix string, maxUploads int) ([]*MultipartUploadMetadata, error)
 
+	// CountMultipartUploads counts the in-progress multipart uploads of bucket
+	// (a bucket path), or of every bucket of tenantID when bucket is empty
+	CountMultipartUploads(ctx context.Context, tenantID, bucket string) (int, error)
+
 	// AbortMultipartUpload cancels a multipart upload and cleans up parts
 	AbortMultipartUpload(ctx context.Context, uploadID string) error
 
@@ -40,6 +40,12 @@ type ObjectMetadata struct {
 	RestoreStatus    string     `json:"restore_status,omitempty"`     // "ongoing" | "restored"
 	RestoreExpiresAt *time.Time `json:"restore_expires_at,omitempty"` // when the restore copy expires
 
+	// TTL (x-amz-expires-after-seconds): the object is deleted after this time
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Read repair: set when a GET found no data on storage for this object
+	DataMissingAt *time.Time `json:"data_missing_at,omitempty"`
+
 	// Encryption
 	SSEAlgorithm string `json:"sse_algorithm,omitempty"`
 	SSEKeyID     string `json:"sse_key_id,omitempty"`
@@ -91,8 +97,41 @@ type BucketMetadata struct {
 	// and is enforced independently of (and in addition to) any tenant quota.
 	Quota *BucketQuota `json:"quota,omitempty"`
 
+	// DefaultWriteLockDays, when positive, gives every newly written object a
+	// GOVERNANCE retention of that many days without enabling full Object Lock
+	// (and therefore without requiring versioning). 0 disables it.
+	DefaultWriteLockDays int `json:"default_write_lock_days,omitempty"`
+
+	// NoOverwrite makes the bucket write-once: a PUT, copy or multipart
+	// completion onto a key that already has a current object is rejected.
+	NoOverwrite bool `json:"no_overwrite,omitempty"`
+
+	// MaxVersionsPerObject caps how many versions a key may keep in a
+	// versioned bucket. A PUT that goes over it expires the oldest unlocked
+	// noncurrent versions. 0 means unlimited.
+	MaxVersionsPerObject int `json:"max_versions_per_object,omitempty"`
+
+	// CacheDefaults sets the caching headers of GET/HEAD responses for
+	// objects stored without their own Cache-Control — nil means none.
+	CacheDefaults *BucketCacheDefaults `json:"cache_defaults,omitempty"`
+
+	// DefaultContentType is stored on objects uploaded without a
+	// Content-Type whose type sniffing is off or inconclusive. Empty means
+	// application/octet-stream.
+	DefaultContentType string `json:"default_content_type,omitempty"`
+
+	// ScanUploads sends every upload to the configured upload scanner
+	// (storage.upload_scan) before it becomes visible; a rejected upload is
+	// discarded.
+	ScanUploads bool `json:"scan_uploads,omitempty"`
+
 	// HA replication — nil means factor 1 (no HA, single node)
 	HA *BucketHA `json:"ha,omitempty"`
+
+	// Revision counts the updates to the bucket's configuration; it is bumped
+	// by UpdateBucket and is what conditional config writes are checked
+	// against (see WithExpectedBucketRevision). Metric updates leave it alone.
+	Revision int64 `json:"revision,omitempty"`
 }
 
 // BucketQuota defines optional storage limits for a single bucket. A zero value
@@ -104,6 +143,15 @@ type BucketQuota struct {
 	MaxObjectCount int64 `json:"max_object_count,omitempty"` // hard cap on object count (0 = unlimited)
 }
 
+// BucketCacheDefaults holds a bucket's default response caching policy, meant
+// for fronting the bucket with a CDN. An object uploaded with its own
+// Cache-Control keeps it and gets none of these defaults.
+type BucketCacheDefaults struct {
+	CacheControl      string `json:"cache_control,omitempty"`      // Cache-Control sent for objects without one
+	ExpiresSeconds    int64  `json:"expires_seconds,omitempty"`    // Expires = response time + this (0 = no Expires header)
+	ImmutableVersions bool   `json:"immutable_versions,omitempty"` // requests naming a version ID are cacheable for a year, "immutable"
+}
+
 // BucketHA holds the high-availability replication state for a bucket.
 // The bucket always appears once in listings regardless of how many nodes
 // hold a copy — only the PrimaryNodeID node publishes it in the aggregator.
@@ -272,7 +320,8 @@ type LifecycleTransition struct {
 
 // NoncurrentExpiration represents noncurrent version expiration
 type NoncurrentExpiration struct {
-	NoncurrentDays int `json:"noncurrent_days"`
+	NoncurrentDays          int `json:"noncurrent_days"`
+	NewerNoncurrentVersions int `json:"newer_noncurrent_versions,omitempty"`
 }
 
 // NoncurrentTransition represents noncurrent version transition
@@ -373,6 +422,10 @@ type PartMetadata struct {
 	Size         int64     `json:"size"`
 	ETag         string    `json:"etag"`
 	LastModified time.Time `json:"last_modified"`
+
+	// Checksum (S3 additional integrity algorithms) of the part's data
+	ChecksumAlgorithm string `json:"checksum_algorithm,omitempty"`
+	ChecksumValue     string `json:"checksum_value,omitempty"`
 }
 
 // ObjectVersion represents a version of an object
@@ -459,6 +459,49 @@ func TestDeleteObjectVersion_Success(t *testing.T) {
 	assert.Empty(t, versions)
 }
 
+func TestDeleteObjectVersion_CurrentVersionMovesObjectEntry(t *testing.T) {
+	store, cleanup := setupVersioningTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "current-version-bucket", OwnerID: "user-1", OwnerType: "user"}))
+	base := time.Now().Add(-time.Hour)
+	for i, versionID := range []string{"v1", "v2"} {
+		modified := base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, store.PutObjectVersion(ctx, &ObjectMetadata{
+			Bucket:       "current-version-bucket",
+			Key:          "doc.txt",
+			Size:         int64(100 + i),
+			LastModified: modified,
+		}, &ObjectVersion{VersionID: versionID, Key: "doc.txt", IsLatest: true, LastModified: modified}))
+	}
+
+	// Deleting a non-current version leaves the object entry alone
+	require.NoError(t, store.PutObjectVersion(ctx, &ObjectMetadata{
+		Bucket: "current-version-bucket", Key: "doc.txt", Size: 102, LastModified: base.Add(2 * time.Minute),
+	}, &ObjectVersion{VersionID: "v3", Key: "doc.txt", IsLatest: true, LastModified: base.Add(2 * time.Minute)}))
+	require.NoError(t, store.DeleteObjectVersion(ctx, "current-version-bucket", "doc.txt", "v2"))
+	current, err := store.GetObject(ctx, "current-version-bucket", "doc.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", current.VersionID)
+
+	// Deleting the current version repoints the entry to the next most recent
+	require.NoError(t, store.DeleteObjectVersion(ctx, "current-version-bucket", "doc.txt", "v3"))
+	current, err = store.GetObject(ctx, "current-version-bucket", "doc.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", current.VersionID)
+	assert.Equal(t, int64(100), current.Size)
+	versions, err := store.GetObjectVersions(ctx, "current-version-bucket", "doc.txt")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.True(t, versions[0].IsLatest)
+
+	// Deleting the last version removes the entry
+	require.NoError(t, store.DeleteObjectVersion(ctx, "current-version-bucket", "doc.txt", "v1"))
+	_, err = store.GetObject(ctx, "current-version-bucket", "doc.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
 func TestDeleteObjectVersion_NotFound(t *testing.T) {
 	store, cleanup := setupVersioningTestStore(t)
 	defer cleanup()
@@ -468,6 +511,59 @@ func TestDeleteObjectVersion_NotFound(t *testing.T) {
 	assert.ErrorIs(t, err, ErrVersionNotFound)
 }
 
+func TestRenameObject_Versioned(t *testing.T) {
+	store, cleanup := setupVersioningTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, store.CreateBucket(ctx, &BucketMetadata{Name: "rename-bucket", OwnerID: "user-1", OwnerType: "user"}))
+	base := time.Now().Add(-time.Hour)
+	require.NoError(t, store.PutObjectVersion(ctx, &ObjectMetadata{
+		Bucket: "rename-bucket", Key: "a.txt", Size: 10, ETag: "etag-a", LastModified: base,
+		Tags: map[string]string{"env": "prod"},
+	}, &ObjectVersion{VersionID: "a1", Key: "a.txt", IsLatest: true, LastModified: base}))
+	require.NoError(t, store.PutObjectVersion(ctx, &ObjectMetadata{
+		Bucket: "rename-bucket", Key: "b.txt", Size: 20, ETag: "etag-b", LastModified: base,
+	}, &ObjectVersion{VersionID: "b1", Key: "b.txt", IsLatest: true, LastModified: base}))
+
+	dst := &ObjectMetadata{
+		Bucket: "rename-bucket", Key: "b.txt", VersionID: "b2", Size: 10, ETag: "etag-a",
+		Tags: map[string]string{"env": "prod"},
+	}
+	marker := &ObjectMetadata{Bucket: "rename-bucket", Key: "a.txt", VersionID: "a2", LastModified: time.Now()}
+	require.NoError(t, store.RenameObject(ctx, "a.txt", dst, marker))
+
+	current, err := store.GetObject(ctx, "rename-bucket", "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a2", current.VersionID)
+	assert.Empty(t, current.ETag)
+	versions, err := store.GetObjectVersions(ctx, "rename-bucket", "a.txt")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "a2", versions[0].VersionID)
+	assert.True(t, versions[0].IsLatest)
+	assert.False(t, versions[1].IsLatest)
+
+	current, err = store.GetObject(ctx, "rename-bucket", "b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "b2", current.VersionID)
+	assert.Equal(t, "etag-a", current.ETag)
+	versions, err = store.GetObjectVersions(ctx, "rename-bucket", "b.txt")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	for _, v := range versions {
+		assert.Equal(t, v.VersionID == "b2", v.IsLatest)
+	}
+
+	tagged, err := store.ListObjectsByTags(ctx, "rename-bucket", map[string]string{"env": "prod"})
+	require.NoError(t, err)
+	require.Len(t, tagged, 1)
+	assert.Equal(t, "b.txt", tagged[0].Key)
+
+	// A key without a current object has nothing to rename
+	assert.ErrorIs(t, store.RenameObject(ctx, "missing.txt", &ObjectMetadata{Bucket: "rename-bucket", Key: "c.txt"}, nil), ErrObjectNotFound)
+}
+
 // ============================================================================
 // GetObject with VersionID Tests
 // ============================================================================
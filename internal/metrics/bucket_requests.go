@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// hotKeyCapacity is how many keys the Space-Saving summary of a bucket
+	// keeps. It bounds memory per bucket no matter how many distinct keys are
+	// accessed; counts for keys ranked well inside it are accurate.
+	hotKeyCapacity = 100
+
+	// DefaultHotKeys is how many hot keys GetBucketRequestMetrics returns
+	// when the caller doesn't ask for a specific number.
+	DefaultHotKeys = 10
+
+	// bucketRateWindow is the period request rates are averaged over, kept
+	// as one-second slots.
+	bucketRateWindow = 60
+)
+
+// bucketRequestOps are the operations counted per bucket; any other method
+// is counted as OTHER.
+var bucketRequestOps = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPost, http.MethodDelete}
+
+// BucketRequestMetrics is the request activity of one bucket since the
+// server started.
+type BucketRequestMetrics struct {
+	Bucket        string             `json:"bucket"`
+	Since         time.Time          `json:"since"`
+	Totals        map[string]uint64  `json:"totals"`
+	RatePerSecond map[string]float64 `json:"ratePerSecond"`
+	HotKeys       []HotKey           `json:"hotKeys"`
+}
+
+// HotKey is an object key with its approximate access count. The true count
+// lies between Count-MaxError and Count.
+type HotKey struct {
+	Key      string `json:"key"`
+	Count    uint64 `json:"count"`
+	MaxError uint64 `json:"maxError"`
+}
+
+// rateSlot counts one second of requests per operation.
+type rateSlot struct {
+	second int64
+	counts map[string]uint64
+}
+
+type hotKeyCounter struct {
+	count    uint64
+	maxError uint64
+}
+
+// bucketRequestStats is the per-bucket state of a BucketRequestTracker.
+type bucketRequestStats struct {
+	since  time.Time
+	totals map[string]uint64
+	slots  [bucketRateWindow]rateSlot
+	keys   map[string]*hotKeyCounter
+}
+
+// BucketRequestTracker counts requests per bucket and operation and keeps an
+// approximate list of the most-accessed keys of each bucket using the
+// Space-Saving algorithm: once the summary is full, a new key replaces the
+// least-counted one and inherits its count as error bound.
+type BucketRequestTracker struct {
+	mu      sync.Mutex
+	buckets map[string]*bucketRequestStats
+	now     func() time.Time
+}
+
+// NewBucketRequestTracker creates an empty tracker.
+func NewBucketRequestTracker() *BucketRequestTracker {
+	return &BucketRequestTracker{
+		buckets: make(map[string]*bucketRequestStats),
+		now:     time.Now,
+	}
+}
+
+// Record counts one request against bucket. key is empty for bucket-level
+// requests, which don't take part in hot-key tracking.
+func (t *BucketRequestTracker) Record(bucket, key, method string) {
+	if bucket == "" {
+		return
+	}
+	op := bucketRequestOp(method)
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.buckets[bucket]
+	if !ok {
+		stats = &bucketRequestStats{
+			since:  now,
+			totals: make(map[string]uint64),
+			keys:   make(map[string]*hotKeyCounter),
+		}
+		t.buckets[bucket] = stats
+	}
+
+	stats.totals[op]++
+
+	second := now.Unix()
+	slot := &stats.slots[second%bucketRateWindow]
+	if slot.second != second || slot.counts == nil {
+		slot.second = second
+		slot.counts = make(map[string]uint64)
+	}
+	slot.counts[op]++
+
+	if key != "" {
+		stats.recordKey(key)
+	}
+}
+
+// recordKey applies the Space-Saving update for key.
+func (s *bucketRequestStats) recordKey(key string) {
+	if c, ok := s.keys[key]; ok {
+		c.count++
+		return
+	}
+	if len(s.keys) < hotKeyCapacity {
+		s.keys[key] = &hotKeyCounter{count: 1}
+		return
+	}
+
+	var minKey string
+	var minCounter *hotKeyCounter
+	for k, c := range s.keys {
+		if minCounter == nil || c.count < minCounter.count {
+			minKey, minCounter = k, c
+		}
+	}
+	delete(s.keys, minKey)
+	s.keys[key] = &hotKeyCounter{count: minCounter.count + 1, maxError: minCounter.count}
+}
+
+// Get returns the request metrics of bucket with its topN hot keys, or nil
+// when no request for the bucket has been seen.
+func (t *BucketRequestTracker) Get(bucket string, topN int) *BucketRequestMetrics {
+	if topN <= 0 {
+		topN = DefaultHotKeys
+	}
+	if topN > hotKeyCapacity {
+		topN = hotKeyCapacity
+	}
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.buckets[bucket]
+	if !ok {
+		return nil
+	}
+
+	result := &BucketRequestMetrics{
+		Bucket:        bucket,
+		Since:         stats.since,
+		Totals:        make(map[string]uint64, len(stats.totals)),
+		RatePerSecond: make(map[string]float64),
+		HotKeys:       make([]HotKey, 0, topN),
+	}
+	for op, n := range stats.totals {
+		result.Totals[op] = n
+	}
+
+	// Average over the window, or over the tracked time if that is shorter
+	window := float64(bucketRateWindow)
+	if elapsed := now.Sub(stats.since).Seconds(); elapsed < window {
+		window = elapsed
+	}
+	if window < 1 {
+		window = 1
+	}
+	oldest := now.Unix() - bucketRateWindow
+	for _, slot := range stats.slots {
+		if slot.second <= oldest {
+			continue
+		}
+		for op, n := range slot.counts {
+			result.RatePerSecond[op] += float64(n) / window
+		}
+	}
+
+	for k, c := range stats.keys {
+		result.HotKeys = append(result.HotKeys, HotKey{Key: k, Count: c.count, MaxError: c.maxError})
+	}
+	sort.Slice(result.HotKeys, func(i, j int) bool {
+		if result.HotKeys[i].Count != result.HotKeys[j].Count {
+			return result.HotKeys[i].Count > result.HotKeys[j].Count
+		}
+		return result.HotKeys[i].Key < result.HotKeys[j].Key
+	})
+	if len(result.HotKeys) > topN {
+		result.HotKeys = result.HotKeys[:topN]
+	}
+	return result
+}
+
+func bucketRequestOp(method string) string {
+	for _, op := range bucketRequestOps {
+		if method == op {
+			return op
+		}
+	}
+	return "OTHER"
+}
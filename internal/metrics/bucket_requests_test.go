@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketRequestTracker_CountsPerOperation(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewBucketRequestTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Record("tenant-a/photos", "cat.jpg", "GET")
+	tracker.Record("tenant-a/photos", "cat.jpg", "GET")
+	tracker.Record("tenant-a/photos", "dog.jpg", "PUT")
+	tracker.Record("tenant-a/photos", "dog.jpg", "DELETE")
+	tracker.Record("tenant-a/photos", "", "GET") // ListObjects
+	tracker.Record("tenant-a/photos", "", "PATCH")
+	tracker.Record("tenant-b/photos", "cat.jpg", "GET")
+
+	now = now.Add(10 * time.Second)
+	m := tracker.Get("tenant-a/photos", 0)
+	require.NotNil(t, m)
+	assert.Equal(t, uint64(3), m.Totals["GET"])
+	assert.Equal(t, uint64(1), m.Totals["PUT"])
+	assert.Equal(t, uint64(1), m.Totals["DELETE"])
+	assert.Equal(t, uint64(1), m.Totals["OTHER"])
+	assert.InDelta(t, 0.3, m.RatePerSecond["GET"], 0.001, "3 GETs over the 10s tracked so far")
+
+	// Requests older than the rate window no longer count towards the rate
+	now = now.Add(2 * time.Minute)
+	m = tracker.Get("tenant-a/photos", 0)
+	assert.Equal(t, uint64(3), m.Totals["GET"])
+	assert.Zero(t, m.RatePerSecond["GET"])
+
+	other := tracker.Get("tenant-b/photos", 0)
+	require.NotNil(t, other)
+	assert.Equal(t, uint64(1), other.Totals["GET"])
+
+	assert.Nil(t, tracker.Get("tenant-a/unknown", 0))
+}
+
+func TestBucketRequestTracker_HotKeys(t *testing.T) {
+	tracker := NewBucketRequestTracker()
+
+	// A long tail of keys read once, mixed with a few popular ones
+	for i := 0; i < 5000; i++ {
+		tracker.Record("logs", fmt.Sprintf("tail/%05d", i), "GET")
+		if i%10 == 0 {
+			tracker.Record("logs", "hot/index.html", "GET")
+		}
+		if i%25 == 0 {
+			tracker.Record("logs", "hot/app.js", "GET")
+		}
+	}
+
+	m := tracker.Get("logs", 2)
+	require.Len(t, m.HotKeys, 2)
+	assert.Equal(t, "hot/index.html", m.HotKeys[0].Key)
+	assert.Equal(t, "hot/app.js", m.HotKeys[1].Key)
+	assert.GreaterOrEqual(t, m.HotKeys[0].Count, uint64(500))
+	assert.LessOrEqual(t, m.HotKeys[0].Count-m.HotKeys[0].MaxError, uint64(500))
+
+	// Memory stays bounded regardless of how many distinct keys were seen
+	tracker.mu.Lock()
+	assert.LessOrEqual(t, len(tracker.buckets["logs"].keys), hotKeyCapacity)
+	tracker.mu.Unlock()
+
+	assert.Len(t, tracker.Get("logs", 1000).HotKeys, hotKeyCapacity)
+}
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ListenerStats counts the connections, requests and bytes of one HTTP
+// listener (the S3 API or the console), so the load on each can be told
+// apart. All counters are atomics; hook it into a server with ConnState,
+// Listener and Handler.
+type ListenerStats struct {
+	name string
+
+	activeConnections atomic.Int64
+	totalConnections  atomic.Uint64
+	requests          atomic.Uint64
+	bytesIn           atomic.Uint64
+	bytesOut          atomic.Uint64
+}
+
+// ListenerSnapshot is a point-in-time copy of a ListenerStats.
+type ListenerSnapshot struct {
+	Name              string `json:"name"`
+	ActiveConnections int64  `json:"activeConnections"`
+	TotalConnections  uint64 `json:"totalConnections"`
+	Requests          uint64 `json:"requests"`
+	BytesIn           uint64 `json:"bytesIn"`
+	BytesOut          uint64 `json:"bytesOut"`
+}
+
+// NewListenerStats creates the counters of the listener called name.
+func NewListenerStats(name string) *ListenerStats {
+	return &ListenerStats{name: name}
+}
+
+// Snapshot returns the current counter values.
+func (l *ListenerStats) Snapshot() ListenerSnapshot {
+	return ListenerSnapshot{
+		Name:              l.name,
+		ActiveConnections: l.activeConnections.Load(),
+		TotalConnections:  l.totalConnections.Load(),
+		Requests:          l.requests.Load(),
+		BytesIn:           l.bytesIn.Load(),
+		BytesOut:          l.bytesOut.Load(),
+	}
+}
+
+// ConnState is an http.Server ConnState hook that tracks open connections.
+// Hijacked connections (WebSockets) leave the server's hands and stop
+// counting as active.
+func (l *ListenerStats) ConnState(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		l.totalConnections.Add(1)
+		l.activeConnections.Add(1)
+	case http.StateClosed, http.StateHijacked:
+		l.activeConnections.Add(-1)
+	}
+}
+
+// Listener wraps ln so the bytes read from and written to its connections
+// are counted. With TLS the counts are of the encrypted stream.
+func (l *ListenerStats) Listener(ln net.Listener) net.Listener {
+	return &countingListener{Listener: ln, stats: l}
+}
+
+// Handler counts the requests served by next. On a nil ListenerStats it
+// returns next unchanged.
+func (l *ListenerStats) Handler(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.requests.Add(1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+type countingListener struct {
+	net.Listener
+	stats *ListenerStats
+}
+
+func (cl *countingListener) Accept() (net.Conn, error) {
+	conn, err := cl.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, stats: cl.stats}, nil
+}
+
+type countingConn struct {
+	net.Conn
+	stats *ListenerStats
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.stats.bytesIn.Add(uint64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.stats.bytesOut.Add(uint64(n))
+	return n, err
+}
+
+// SetListenerStats sets the listeners exported as maxiofs_listener_* metrics
+// and in the system snapshot.
+func (m *metricsManager) SetListenerStats(listeners ...*ListenerStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = listeners
+}
+
+// listenerSnapshots returns a snapshot of every listener set with
+// SetListenerStats.
+func (m *metricsManager) listenerSnapshots() []ListenerSnapshot {
+	m.mu.RLock()
+	listeners := m.listeners
+	m.mu.RUnlock()
+	snapshots := make([]ListenerSnapshot, 0, len(listeners))
+	for _, l := range listeners {
+		snapshots = append(snapshots, l.Snapshot())
+	}
+	return snapshots
+}
+
+// listenerCollector exports the listener counters at scrape time.
+type listenerCollector struct {
+	m                 *metricsManager
+	activeConnections *prometheus.Desc
+	connectionsTotal  *prometheus.Desc
+	requestsTotal     *prometheus.Desc
+	bytesInTotal      *prometheus.Desc
+	bytesOutTotal     *prometheus.Desc
+}
+
+func newListenerCollector(m *metricsManager, namespace string) *listenerCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "listener", name), help, []string{"listener"}, nil)
+	}
+	return &listenerCollector{
+		m:                 m,
+		activeConnections: desc("active_connections", "Open client connections per listener"),
+		connectionsTotal:  desc("connections_total", "Client connections accepted per listener"),
+		requestsTotal:     desc("requests_total", "HTTP requests served per listener"),
+		bytesInTotal:      desc("received_bytes_total", "Bytes read from client connections per listener"),
+		bytesOutTotal:     desc("sent_bytes_total", "Bytes written to client connections per listener"),
+	}
+}
+
+func (c *listenerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeConnections
+	ch <- c.connectionsTotal
+	ch <- c.requestsTotal
+	ch <- c.bytesInTotal
+	ch <- c.bytesOutTotal
+}
+
+func (c *listenerCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.m.listenerSnapshots() {
+		ch <- prometheus.MustNewConstMetric(c.activeConnections, prometheus.GaugeValue, float64(s.ActiveConnections), s.Name)
+		ch <- prometheus.MustNewConstMetric(c.connectionsTotal, prometheus.CounterValue, float64(s.TotalConnections), s.Name)
+		ch <- prometheus.MustNewConstMetric(c.requestsTotal, prometheus.CounterValue, float64(s.Requests), s.Name)
+		ch <- prometheus.MustNewConstMetric(c.bytesInTotal, prometheus.CounterValue, float64(s.BytesIn), s.Name)
+		ch <- prometheus.MustNewConstMetric(c.bytesOutTotal, prometheus.CounterValue, float64(s.BytesOut), s.Name)
+	}
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startCountedServer serves handler with stats hooked in the way the server
+// hooks its listeners.
+func startCountedServer(t *testing.T, stats *ListenerStats, handler http.Handler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(stats.Handler(handler))
+	srv.Listener = stats.Listener(srv.Listener)
+	srv.Config.ConnState = stats.ConnState
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestListenerStats_ActiveConnections(t *testing.T) {
+	stats := NewListenerStats("s3")
+	srv := startCountedServer(t, stats, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+
+	// get sends one keep-alive request on conn and reads the reply
+	get := func(conn net.Conn) {
+		t.Helper()
+		_, err := fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: test\r\n\r\n")
+		require.NoError(t, err)
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.Equal(t, "hello", string(body))
+	}
+
+	var conns []net.Conn
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+		require.NoError(t, err)
+		conns = append(conns, conn)
+		get(conn)
+	}
+	get(conns[0])
+
+	snap := stats.Snapshot()
+	assert.Equal(t, "s3", snap.Name)
+	assert.Equal(t, int64(3), snap.ActiveConnections)
+	assert.Equal(t, uint64(3), snap.TotalConnections)
+	assert.Equal(t, uint64(4), snap.Requests)
+	assert.Greater(t, snap.BytesIn, uint64(4*len("GET / HTTP/1.1\r\n")))
+	assert.Greater(t, snap.BytesOut, uint64(4*len("hello")))
+
+	conns[0].Close()
+	conns[1].Close()
+	assert.Eventually(t, func() bool { return stats.Snapshot().ActiveConnections == 1 },
+		5*time.Second, 10*time.Millisecond, "closing connections lowers the gauge")
+
+	conns[2].Close()
+	assert.Eventually(t, func() bool { return stats.Snapshot().ActiveConnections == 0 },
+		5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(3), stats.Snapshot().TotalConnections)
+}
+
+func TestListenerStats_PrometheusExport(t *testing.T) {
+	manager := NewManagerWithStore(config.MetricsConfig{Enable: true, Interval: 10}, "", nil).(*metricsManager)
+	s3, console := NewListenerStats("s3"), NewListenerStats("console")
+	manager.SetListenerStats(s3, console)
+
+	s3.ConnState(nil, http.StateNew)
+	s3.ConnState(nil, http.StateNew)
+	s3.ConnState(nil, http.StateClosed)
+	console.ConnState(nil, http.StateNew)
+	console.ConnState(nil, http.StateHijacked)
+	s3.Handler(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	rr := httptest.NewRecorder()
+	manager.GetMetricsHandler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	body := rr.Body.String()
+	assert.Contains(t, body, `maxiofs_listener_active_connections{listener="s3"} 1`)
+	assert.Contains(t, body, `maxiofs_listener_connections_total{listener="s3"} 2`)
+	assert.Contains(t, body, `maxiofs_listener_requests_total{listener="s3"} 1`)
+	assert.Contains(t, body, `maxiofs_listener_active_connections{listener="console"} 0`)
+	assert.Contains(t, body, `maxiofs_listener_connections_total{listener="console"} 1`)
+	assert.Contains(t, body, `maxiofs_listener_received_bytes_total{listener="console"} 0`)
+}
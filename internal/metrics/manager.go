@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/lru"
 	"github.com/maxiofs/maxiofs/internal/metadata"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -44,6 +45,10 @@ type Manager interface {
 	UpdateBucketMetrics(bucket string, objects, bytes int64)
 	RecordBucketOperation(operation, bucket string, success bool)
 
+	// Bucket Request Metrics (bucket is the tenant-prefixed bucket path)
+	RecordBucketRequest(bucket, key, method string)
+	GetBucketRequestMetrics(bucket string, topN int) *BucketRequestMetrics
+
 	// Object Lock Metrics
 	RecordObjectLockOperation(operation, bucket string, success bool)
 	UpdateRetentionMetrics(bucket string, governanceObjects, complianceObjects int64)
@@ -74,6 +79,25 @@ type Manager interface {
 // StorageMetricsProvider is a function that returns current storage metrics
 type StorageMetricsProvider func() (totalBuckets, totalObjects, totalSize int64)
 
+// InFlightProvider is a function that returns the number of S3 requests in
+// flight per operation class
+type InFlightProvider func() map[string]int
+
+// CacheStatsProvider is a function that returns the size and eviction
+// counters of the server's in-memory caches, by cache name
+type CacheStatsProvider func() map[string]lru.Stats
+
+// LifecycleBucketStats describes the lifecycle worker's last run over a bucket
+type LifecycleBucketStats struct {
+	LastRun        time.Time
+	ObjectsExpired int64 // deleted by the last run
+	ExpiredTotal   int64 // deleted since the server started
+}
+
+// LifecycleStatsProvider is a function that returns lifecycle run statistics
+// per tenant-prefixed bucket path
+type LifecycleStatsProvider func() map[string]LifecycleBucketStats
+
 // metricsManager implements the Manager interface using Prometheus
 type metricsManager struct {
 	// Configuration
@@ -92,6 +116,7 @@ type metricsManager struct {
 	s3OperationsTotal   *prometheus.CounterVec
 	s3OperationDuration *prometheus.HistogramVec
 	s3ErrorsTotal       *prometheus.CounterVec
+	s3InFlight          *inFlightCollector
 
 	// Storage Metrics
 	storageOperationsTotal   *prometheus.CounterVec
@@ -114,12 +139,21 @@ type metricsManager struct {
 	systemDiskUsagePercent prometheus.Gauge
 	systemDiskUsedBytes    prometheus.Gauge
 	systemDiskTotalBytes   prometheus.Gauge
+	systemMaintenanceMode  prometheus.GaugeFunc
 	systemEventsTotal      *prometheus.CounterVec
 
 	// Bucket Metrics
 	bucketObjectsTotal *prometheus.GaugeVec
 	bucketBytesTotal   *prometheus.GaugeVec
 	bucketOpsTotal     *prometheus.CounterVec
+	bucketLifecycle    *lifecycleCollector
+
+	// Per-bucket request counts and hot keys
+	bucketRequests *BucketRequestTracker
+
+	// Connection and request counters per HTTP listener
+	listeners         []*ListenerStats
+	listenerCollector *listenerCollector
 
 	// Object Lock Metrics
 	objectLockOpsTotal    *prometheus.CounterVec
@@ -130,6 +164,7 @@ type metricsManager struct {
 	backgroundTaskDuration *prometheus.HistogramVec
 	cacheHitRate           prometheus.Gauge
 	cacheSizeBytes         prometheus.Gauge
+	caches                 *cacheCollector
 
 	// Operation Latency Metrics (from PerformanceCollector)
 	operationLatencyP50  *prometheus.GaugeVec
@@ -162,6 +197,15 @@ type metricsManager struct {
 	// Storage metrics provider
 	storageMetricsProvider StorageMetricsProvider
 
+	// In-flight S3 requests provider (concurrency limiter)
+	inFlightProvider InFlightProvider
+
+	// In-memory cache statistics provider
+	cacheStatsProvider CacheStatsProvider
+
+	// Lifecycle worker statistics provider
+	lifecycleStatsProvider LifecycleStatsProvider
+
 	// Dynamic settings
 	settingsManager interface {
 		GetInt(key string) (int, error)
@@ -218,6 +262,7 @@ func NewManagerWithStore(cfg config.MetricsConfig, dataDir string, metadataStore
 		requestsStartTime: time.Now(),
 		serverStartTime:   time.Now(), // Will be updated from persisted value if available
 		dataDir:           dataDir,
+		bucketRequests:    NewBucketRequestTracker(),
 	}
 
 	// Initialize metadata-backed history store if metadata store is provided.
@@ -336,6 +381,17 @@ func (m *metricsManager) initializeMetrics() {
 		[]string{"operation", "bucket", "error_type"},
 	)
 
+	m.s3InFlight = &inFlightCollector{
+		m: m,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "s3", "inflight_requests"),
+			"S3 object requests currently holding a concurrency slot",
+			[]string{"operation"}, nil,
+		),
+	}
+
+	m.listenerCollector = newListenerCollector(m, namespace)
+
 	// Storage Metrics
 	m.storageOperationsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -478,6 +534,16 @@ func (m *metricsManager) initializeMetrics() {
 		},
 	)
 
+	m.systemMaintenanceMode = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "system",
+			Name:      "maintenance_mode",
+			Help:      "1 while read-only maintenance mode is on, 0 otherwise",
+		},
+		m.maintenanceModeValue,
+	)
+
 	m.systemEventsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: namespace,
@@ -519,6 +585,25 @@ func (m *metricsManager) initializeMetrics() {
 		[]string{"operation", "bucket", "status"},
 	)
 
+	m.bucketLifecycle = &lifecycleCollector{
+		m: m,
+		lastRunDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "lifecycle", "bucket_last_run_timestamp_seconds"),
+			"When the lifecycle worker last finished processing the bucket",
+			[]string{"bucket"}, nil,
+		),
+		expiredDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "lifecycle", "bucket_objects_expired"),
+			"Objects and versions deleted from the bucket by the last lifecycle run",
+			[]string{"bucket"}, nil,
+		),
+		expiredTotalDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "lifecycle", "bucket_objects_expired_total"),
+			"Objects and versions deleted from the bucket by lifecycle runs since start",
+			[]string{"bucket"}, nil,
+		),
+	}
+
 	// Object Lock Metrics
 	m.objectLockOpsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -580,6 +665,25 @@ func (m *metricsManager) initializeMetrics() {
 		},
 	)
 
+	m.caches = &cacheCollector{
+		m: m,
+		entriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cache", "entries"),
+			"Entries held by the in-memory cache",
+			[]string{"cache"}, nil,
+		),
+		maxEntriesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cache", "max_entries"),
+			"Entry cap of the in-memory cache (0 = unbounded)",
+			[]string{"cache"}, nil,
+		),
+		evictionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cache", "evictions_total"),
+			"Entries dropped from the in-memory cache, by reason: capacity, idle or pressure",
+			[]string{"cache", "reason"}, nil,
+		),
+	}
+
 	// Operation Latency Metrics (from PerformanceCollector)
 	m.operationLatencyP50 = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -686,6 +790,8 @@ func (m *metricsManager) registerMetrics() {
 		m.s3OperationsTotal,
 		m.s3OperationDuration,
 		m.s3ErrorsTotal,
+		m.s3InFlight,
+		m.listenerCollector,
 
 		// Storage
 		m.storageOperationsTotal,
@@ -708,12 +814,14 @@ func (m *metricsManager) registerMetrics() {
 		m.systemDiskUsagePercent,
 		m.systemDiskUsedBytes,
 		m.systemDiskTotalBytes,
+		m.systemMaintenanceMode,
 		m.systemEventsTotal,
 
 		// Bucket
 		m.bucketObjectsTotal,
 		m.bucketBytesTotal,
 		m.bucketOpsTotal,
+		m.bucketLifecycle,
 
 		// Object Lock
 		m.objectLockOpsTotal,
@@ -724,6 +832,7 @@ func (m *metricsManager) registerMetrics() {
 		m.backgroundTaskDuration,
 		m.cacheHitRate,
 		m.cacheSizeBytes,
+		m.caches,
 
 		// Operation Latency (from PerformanceCollector)
 		m.operationLatencyP50,
@@ -847,6 +956,14 @@ func (m *metricsManager) RecordBucketOperation(operation, bucket string, success
 	m.bucketOpsTotal.WithLabelValues(operation, bucket, status).Inc()
 }
 
+func (m *metricsManager) RecordBucketRequest(bucket, key, method string) {
+	m.bucketRequests.Record(bucket, key, method)
+}
+
+func (m *metricsManager) GetBucketRequestMetrics(bucket string, topN int) *BucketRequestMetrics {
+	return m.bucketRequests.Get(bucket, topN)
+}
+
 // Object Lock Metrics Implementation
 
 func (m *metricsManager) RecordObjectLockOperation(operation, bucket string, success bool) {
@@ -1147,6 +1264,21 @@ func (m *metricsManager) SetSettingsManager(sm interface {
 	m.mu.Unlock()
 }
 
+// maintenanceModeValue reads the system.maintenance_mode setting at scrape
+// time, so the gauge follows the mode without being updated explicitly.
+func (m *metricsManager) maintenanceModeValue() float64 {
+	m.mu.RLock()
+	sm := m.settingsManager
+	m.mu.RUnlock()
+	if sm == nil {
+		return 0
+	}
+	if enabled, err := sm.GetBool("system.maintenance_mode"); err == nil && enabled {
+		return 1
+	}
+	return 0
+}
+
 func (m *metricsManager) Start(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -1344,6 +1476,110 @@ func (m *metricsManager) SetStorageMetricsProvider(provider StorageMetricsProvid
 	m.storageMetricsProvider = provider
 }
 
+// SetInFlightProvider sets a function that reports in-flight S3 requests
+func (m *metricsManager) SetInFlightProvider(provider InFlightProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlightProvider = provider
+}
+
+// inFlightCollector exports the in-flight counts at scrape time, so they are
+// always current without the limiter having to update a gauge.
+type inFlightCollector struct {
+	m    *metricsManager
+	desc *prometheus.Desc
+}
+
+func (c *inFlightCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *inFlightCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.mu.RLock()
+	provider := c.m.inFlightProvider
+	c.m.mu.RUnlock()
+	if provider == nil {
+		return
+	}
+	for op, n := range provider() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), op)
+	}
+}
+
+// SetCacheStatsProvider sets a function that reports the in-memory caches
+func (m *metricsManager) SetCacheStatsProvider(provider CacheStatsProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cacheStatsProvider = provider
+}
+
+// cacheCollector exports the in-memory cache sizes and eviction counters at
+// scrape time.
+type cacheCollector struct {
+	m              *metricsManager
+	entriesDesc    *prometheus.Desc
+	maxEntriesDesc *prometheus.Desc
+	evictionsDesc  *prometheus.Desc
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.entriesDesc
+	ch <- c.maxEntriesDesc
+	ch <- c.evictionsDesc
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.mu.RLock()
+	provider := c.m.cacheStatsProvider
+	c.m.mu.RUnlock()
+	if provider == nil {
+		return
+	}
+	for name, s := range provider() {
+		ch <- prometheus.MustNewConstMetric(c.entriesDesc, prometheus.GaugeValue, float64(s.Entries), name)
+		ch <- prometheus.MustNewConstMetric(c.maxEntriesDesc, prometheus.GaugeValue, float64(s.MaxEntries), name)
+		ch <- prometheus.MustNewConstMetric(c.evictionsDesc, prometheus.CounterValue, float64(s.Evictions), name, "capacity")
+		ch <- prometheus.MustNewConstMetric(c.evictionsDesc, prometheus.CounterValue, float64(s.Expired), name, "idle")
+		ch <- prometheus.MustNewConstMetric(c.evictionsDesc, prometheus.CounterValue, float64(s.Shrunk), name, "pressure")
+	}
+}
+
+// SetLifecycleStatsProvider sets a function that reports lifecycle runs per bucket
+func (m *metricsManager) SetLifecycleStatsProvider(provider LifecycleStatsProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lifecycleStatsProvider = provider
+}
+
+// lifecycleCollector exports the lifecycle worker's per-bucket statistics at
+// scrape time.
+type lifecycleCollector struct {
+	m                *metricsManager
+	lastRunDesc      *prometheus.Desc
+	expiredDesc      *prometheus.Desc
+	expiredTotalDesc *prometheus.Desc
+}
+
+func (c *lifecycleCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lastRunDesc
+	ch <- c.expiredDesc
+	ch <- c.expiredTotalDesc
+}
+
+func (c *lifecycleCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.mu.RLock()
+	provider := c.m.lifecycleStatsProvider
+	c.m.mu.RUnlock()
+	if provider == nil {
+		return
+	}
+	for bucket, s := range provider() {
+		ch <- prometheus.MustNewConstMetric(c.lastRunDesc, prometheus.GaugeValue, float64(s.LastRun.Unix()), bucket)
+		ch <- prometheus.MustNewConstMetric(c.expiredDesc, prometheus.GaugeValue, float64(s.ObjectsExpired), bucket)
+		ch <- prometheus.MustNewConstMetric(c.expiredTotalDesc, prometheus.CounterValue, float64(s.ExpiredTotal), bucket)
+	}
+}
+
 // responseWriterWrapper wraps http.ResponseWriter to capture status code
 type responseWriterWrapper struct {
 	http.ResponseWriter
@@ -1414,3 +1650,8 @@ func (n *noopManager) Middleware() func(http.Handler) http.Handler {
 }
 func (n *noopManager) Start(ctx context.Context) error { return nil }
 func (n *noopManager) Stop() error                     { return nil }
+
+func (n *noopManager) RecordBucketRequest(bucket, key, method string) {}
+func (n *noopManager) GetBucketRequestMetrics(bucket string, topN int) *BucketRequestMetrics {
+	return nil
+}
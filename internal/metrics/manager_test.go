@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -300,6 +301,33 @@ func TestGetMetricsHandler(t *testing.T) {
 	assert.NotNil(t, handler)
 }
 
+func TestInFlightRequestsGauge(t *testing.T) {
+	manager := NewManagerWithStore(config.MetricsConfig{Enable: true, Interval: 10}, "", nil).(*metricsManager)
+	manager.SetInFlightProvider(func() map[string]int {
+		return map[string]int{"put": 3, "get": 0}
+	})
+
+	rr := httptest.NewRecorder()
+	manager.GetMetricsHandler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `maxiofs_s3_inflight_requests{operation="put"} 3`)
+	assert.Contains(t, rr.Body.String(), `maxiofs_s3_inflight_requests{operation="get"} 0`)
+}
+
+func TestLifecycleBucketMetrics(t *testing.T) {
+	manager := NewManagerWithStore(config.MetricsConfig{Enable: true, Interval: 10}, "", nil).(*metricsManager)
+	manager.SetLifecycleStatsProvider(func() map[string]LifecycleBucketStats {
+		return map[string]LifecycleBucketStats{
+			"tenant-1/logs": {LastRun: time.Unix(1700000000, 0), ObjectsExpired: 12, ExpiredTotal: 40},
+		}
+	})
+
+	rr := httptest.NewRecorder()
+	manager.GetMetricsHandler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `maxiofs_lifecycle_bucket_last_run_timestamp_seconds{bucket="tenant-1/logs"} 1.7e+09`)
+	assert.Contains(t, rr.Body.String(), `maxiofs_lifecycle_bucket_objects_expired{bucket="tenant-1/logs"} 12`)
+	assert.Contains(t, rr.Body.String(), `maxiofs_lifecycle_bucket_objects_expired_total{bucket="tenant-1/logs"} 40`)
+}
+
 func TestGetMetricsSnapshot(t *testing.T) {
 	cfg := config.MetricsConfig{
 		Enable:   true,
@@ -0,0 +1,396 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/sirupsen/logrus"
+)
+
+// Usage granularities accepted by UsageTracker.GetTenantUsage.
+const (
+	UsageGranularityHourly = "hourly"
+	UsageGranularityDaily  = "daily"
+)
+
+// maxUsageBackfillHours caps how many missing hours are filled in for a tenant
+// after a long outage, so a node that was down for months doesn't write a
+// huge batch on boot.
+const maxUsageBackfillHours = 90 * 24
+
+// TenantUsageSample is the storage footprint of one tenant at sampling time.
+type TenantUsageSample struct {
+	TenantID    string
+	BytesStored int64
+	ObjectCount int64
+}
+
+// TenantUsageProvider returns the current storage footprint of every tenant.
+type TenantUsageProvider func(ctx context.Context) ([]TenantUsageSample, error)
+
+// UsagePoint is one time bucket of a tenant's usage series.
+//
+// For hourly points BytesStored and ObjectCount are the last values sampled in
+// that hour. For daily points BytesStored is the average of the day's hourly
+// values (byte-hours / hours) and ObjectCount is the last one. Requests is the
+// number of S3 requests made by the tenant's users in the bucket.
+type UsagePoint struct {
+	Timestamp   time.Time `json:"timestamp"`
+	BytesStored int64     `json:"bytesStored"`
+	ObjectCount int64     `json:"objectCount"`
+	Requests    uint64    `json:"requests"`
+}
+
+// Key formats:
+//   - Hourly usage: "usage:tenant:{tenant_id}:{hour_unix_timestamp}"
+//   - Latest point: "usage:latest:{tenant_id}"
+
+func (b *BadgerHistoryStore) usageKey(tenantID string, hour time.Time) string {
+	return fmt.Sprintf("usage:tenant:%s:%d", tenantID, hour.Truncate(time.Hour).Unix())
+}
+
+func (b *BadgerHistoryStore) usagePrefix(tenantID string) string {
+	return fmt.Sprintf("usage:tenant:%s:", tenantID)
+}
+
+func (b *BadgerHistoryStore) usageLatestKey(tenantID string) string {
+	return fmt.Sprintf("usage:latest:%s", tenantID)
+}
+
+// GetUsagePoint returns the hourly usage point of a tenant, or nil if none was recorded.
+func (b *BadgerHistoryStore) GetUsagePoint(tenantID string, hour time.Time) (*UsagePoint, error) {
+	return b.getUsage(b.usageKey(tenantID, hour))
+}
+
+// GetLatestUsagePoint returns the most recently written hourly point of a tenant, or nil.
+func (b *BadgerHistoryStore) GetLatestUsagePoint(tenantID string) (*UsagePoint, error) {
+	return b.getUsage(b.usageLatestKey(tenantID))
+}
+
+func (b *BadgerHistoryStore) getUsage(key string) (*UsagePoint, error) {
+	data, err := b.kvStore.GetRaw(context.Background(), key)
+	if err == metadata.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var point UsagePoint
+	if err := json.Unmarshal(data, &point); err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+// SaveUsagePoints writes hourly usage points of a tenant atomically and moves
+// the latest pointer to the newest of them.
+func (b *BadgerHistoryStore) SaveUsagePoints(tenantID string, points []UsagePoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	sets := make(map[string][]byte, len(points)+1)
+	var latest []byte
+	var latestTime time.Time
+	for _, p := range points {
+		p.Timestamp = p.Timestamp.Truncate(time.Hour).UTC()
+		data, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to marshal usage point: %w", err)
+		}
+		sets[b.usageKey(tenantID, p.Timestamp)] = data
+		if latest == nil || !p.Timestamp.Before(latestTime) {
+			latest, latestTime = data, p.Timestamp
+		}
+	}
+
+	// Never move the pointer backwards (e.g. when an older hour is rewritten)
+	if cur, err := b.GetLatestUsagePoint(tenantID); err == nil && cur != nil && cur.Timestamp.After(latestTime) {
+		latest = nil
+	}
+	if latest != nil {
+		sets[b.usageLatestKey(tenantID)] = latest
+	}
+	return b.kvStore.RawBatch(context.Background(), sets, nil)
+}
+
+// GetUsagePoints returns the hourly usage points of a tenant in [start, end], oldest first.
+func (b *BadgerHistoryStore) GetUsagePoints(ctx context.Context, tenantID string, start, end time.Time) ([]UsagePoint, error) {
+	startKey := b.usageKey(tenantID, start)
+	endKey := b.usageKey(tenantID, end)
+
+	var points []UsagePoint
+	err := b.kvStore.RawScan(ctx, b.usagePrefix(tenantID), startKey, func(key string, val []byte) bool {
+		if key > endKey {
+			return false
+		}
+		var p UsagePoint
+		if err := json.Unmarshal(val, &p); err != nil {
+			logrus.WithError(err).Error("Failed to unmarshal usage point")
+			return true
+		}
+		points = append(points, p)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Keys compare as strings; keep the series chronological regardless
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	return points, nil
+}
+
+// CleanupOldUsage removes hourly usage points older than the retention period.
+func (b *BadgerHistoryStore) CleanupOldUsage() error {
+	cutoff := time.Now().Add(-time.Duration(b.retentionDays) * 24 * time.Hour).Unix()
+	ctx := context.Background()
+
+	var deletes []string
+	err := b.kvStore.RawScan(ctx, "usage:tenant:", "", func(key string, val []byte) bool {
+		var p UsagePoint
+		if err := json.Unmarshal(val, &p); err == nil && p.Timestamp.Unix() < cutoff {
+			deletes = append(deletes, key)
+		}
+		return true
+	})
+	if err != nil || len(deletes) == 0 {
+		return err
+	}
+	return b.kvStore.RawBatch(ctx, nil, deletes)
+}
+
+// UsageTracker samples per-tenant storage and S3 request counts into hourly
+// usage points in the metrics history store.
+//
+// Request counts are kept in memory between samples; each sample adds them to
+// the point of the current hour and overwrites its storage figures, so a
+// sample costs one read and one batch write per tenant. Hours during which
+// the server was down are backfilled on start with the last known storage
+// figures and zero requests, so billing series have no holes.
+type UsageTracker struct {
+	history  *BadgerHistoryStore
+	provider TenantUsageProvider
+	interval time.Duration
+
+	mu       sync.Mutex
+	requests map[string]uint64 // per tenant, since the last sample
+
+	now func() time.Time
+}
+
+// NewUsageTracker creates a usage tracker on top of a metrics history store.
+// interval defaults to 5 minutes.
+func NewUsageTracker(history *BadgerHistoryStore, provider TenantUsageProvider, interval time.Duration) *UsageTracker {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &UsageTracker{
+		history:  history,
+		provider: provider,
+		interval: interval,
+		requests: make(map[string]uint64),
+		now:      time.Now,
+	}
+}
+
+// RecordRequest counts one S3 request made on behalf of a tenant.
+// Requests without a tenant (global users) are not billed and are ignored.
+func (u *UsageTracker) RecordRequest(tenantID string) {
+	if tenantID == "" {
+		return
+	}
+	u.mu.Lock()
+	u.requests[tenantID]++
+	u.mu.Unlock()
+}
+
+// Start backfills hours missed while the server was down, then samples every
+// interval until ctx is cancelled. A final sample is taken on shutdown so
+// pending request counts are not lost.
+func (u *UsageTracker) Start(ctx context.Context) {
+	go func() {
+		if err := u.Backfill(ctx); err != nil {
+			logrus.WithError(err).Warn("Failed to backfill tenant usage")
+		}
+		u.sampleAndLog(ctx)
+
+		ticker := time.NewTicker(u.interval)
+		defer ticker.Stop()
+		cleanup := time.NewTicker(24 * time.Hour)
+		defer cleanup.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				u.sampleAndLog(context.Background())
+				return
+			case <-ticker.C:
+				u.sampleAndLog(ctx)
+			case <-cleanup.C:
+				if err := u.history.CleanupOldUsage(); err != nil {
+					logrus.WithError(err).Debug("Failed to clean up old tenant usage")
+				}
+			}
+		}
+	}()
+}
+
+func (u *UsageTracker) sampleAndLog(ctx context.Context) {
+	if err := u.Sample(ctx); err != nil {
+		logrus.WithError(err).Debug("Failed to sample tenant usage")
+	}
+}
+
+// Sample records the current storage of every tenant and the requests counted
+// since the previous sample into the current hour's usage point.
+func (u *UsageTracker) Sample(ctx context.Context) error {
+	samples, err := u.provider(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read tenant usage: %w", err)
+	}
+
+	u.mu.Lock()
+	pending := u.requests
+	u.requests = make(map[string]uint64)
+	u.mu.Unlock()
+
+	hour := u.now().UTC().Truncate(time.Hour)
+	seen := make(map[string]bool, len(samples))
+	var firstErr error
+	record := func(tenantID string, sample *TenantUsageSample) {
+		point, err := u.history.GetUsagePoint(tenantID, hour)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		if point == nil {
+			point = &UsagePoint{Timestamp: hour}
+			if sample == nil {
+				// Requests from a tenant the provider doesn't know (yet):
+				// carry its storage forward from the previous point.
+				if prev, _ := u.history.GetLatestUsagePoint(tenantID); prev != nil {
+					point.BytesStored, point.ObjectCount = prev.BytesStored, prev.ObjectCount
+				}
+			}
+		}
+		if sample != nil {
+			point.BytesStored, point.ObjectCount = sample.BytesStored, sample.ObjectCount
+		}
+		point.Requests += pending[tenantID]
+		if err := u.history.SaveUsagePoints(tenantID, []UsagePoint{*point}); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			// Keep the counts for the next sample rather than dropping them
+			u.mu.Lock()
+			u.requests[tenantID] += pending[tenantID]
+			u.mu.Unlock()
+		}
+	}
+
+	for i := range samples {
+		if samples[i].TenantID == "" {
+			continue
+		}
+		seen[samples[i].TenantID] = true
+		record(samples[i].TenantID, &samples[i])
+	}
+	for tenantID := range pending {
+		if !seen[tenantID] {
+			record(tenantID, nil)
+		}
+	}
+	return firstErr
+}
+
+// Backfill fills every hour between each tenant's last recorded point and the
+// current hour with the last known storage figures and zero requests.
+func (u *UsageTracker) Backfill(ctx context.Context) error {
+	samples, err := u.provider(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read tenant usage: %w", err)
+	}
+
+	current := u.now().UTC().Truncate(time.Hour)
+	for _, s := range samples {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if s.TenantID == "" {
+			continue
+		}
+		last, err := u.history.GetLatestUsagePoint(s.TenantID)
+		if err != nil {
+			return err
+		}
+		if last == nil {
+			continue
+		}
+
+		from := last.Timestamp.UTC().Truncate(time.Hour).Add(time.Hour)
+		if earliest := current.Add(-maxUsageBackfillHours * time.Hour); from.Before(earliest) {
+			from = earliest
+		}
+		var gap []UsagePoint
+		for h := from; h.Before(current); h = h.Add(time.Hour) {
+			gap = append(gap, UsagePoint{Timestamp: h, BytesStored: last.BytesStored, ObjectCount: last.ObjectCount})
+		}
+		if len(gap) == 0 {
+			continue
+		}
+		if err := u.history.SaveUsagePoints(s.TenantID, gap); err != nil {
+			return err
+		}
+		logrus.WithFields(logrus.Fields{
+			"tenant_id": s.TenantID,
+			"hours":     len(gap),
+		}).Info("Backfilled tenant usage gap")
+	}
+	return nil
+}
+
+// GetTenantUsage returns the usage series of a tenant in [start, end] bucketed
+// by granularity (UsageGranularityHourly or UsageGranularityDaily, in UTC).
+// Only buckets with recorded points are returned.
+func (u *UsageTracker) GetTenantUsage(ctx context.Context, tenantID string, start, end time.Time, granularity string) ([]UsagePoint, error) {
+	var bucketSize time.Duration
+	switch granularity {
+	case UsageGranularityHourly:
+		bucketSize = time.Hour
+	case UsageGranularityDaily:
+		bucketSize = 24 * time.Hour
+	default:
+		return nil, fmt.Errorf("unsupported granularity %q", granularity)
+	}
+
+	hourly, err := u.history.GetUsagePoints(ctx, tenantID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if bucketSize == time.Hour {
+		return hourly, nil
+	}
+
+	var series []UsagePoint
+	var byteHours, hours int64
+	for _, p := range hourly {
+		bucket := p.Timestamp.UTC().Truncate(bucketSize)
+		if len(series) == 0 || !series[len(series)-1].Timestamp.Equal(bucket) {
+			byteHours, hours = 0, 0
+			series = append(series, UsagePoint{Timestamp: bucket})
+		}
+		cur := &series[len(series)-1]
+		byteHours += p.BytesStored
+		hours++
+		cur.BytesStored = byteHours / hours
+		cur.ObjectCount = p.ObjectCount
+		cur.Requests += p.Requests
+	}
+	return series, nil
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUsageTracker(t *testing.T, samples *[]TenantUsageSample, now *time.Time) *UsageTracker {
+	t.Helper()
+	history, err := NewBadgerHistoryStore(createTestPebbleStore(t), 365)
+	require.NoError(t, err)
+
+	tracker := NewUsageTracker(history, func(context.Context) ([]TenantUsageSample, error) {
+		return *samples, nil
+	}, time.Minute)
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+func TestUsageTracker_SampleRecordsPoints(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 10, 15, 0, 0, time.UTC)
+	samples := []TenantUsageSample{{TenantID: "tenant-a", BytesStored: 1000, ObjectCount: 3}}
+	tracker := newTestUsageTracker(t, &samples, &now)
+
+	tracker.RecordRequest("tenant-a")
+	tracker.RecordRequest("tenant-a")
+	tracker.RecordRequest("tenant-b") // unknown to the provider
+	tracker.RecordRequest("")         // global user, not billed
+	require.NoError(t, tracker.Sample(ctx))
+
+	// A second sample in the same hour adds requests and refreshes storage
+	now = now.Add(20 * time.Minute)
+	samples[0].BytesStored = 1500
+	tracker.RecordRequest("tenant-a")
+	require.NoError(t, tracker.Sample(ctx))
+
+	hour := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	point, err := tracker.history.GetUsagePoint("tenant-a", hour)
+	require.NoError(t, err)
+	require.NotNil(t, point)
+	assert.True(t, point.Timestamp.Equal(hour))
+	assert.Equal(t, int64(1500), point.BytesStored)
+	assert.Equal(t, int64(3), point.ObjectCount)
+	assert.Equal(t, uint64(3), point.Requests)
+
+	other, err := tracker.history.GetUsagePoint("tenant-b", hour)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	assert.Equal(t, uint64(1), other.Requests)
+
+	global, err := tracker.history.GetUsagePoint("", hour)
+	require.NoError(t, err)
+	assert.Nil(t, global)
+}
+
+func TestUsageTracker_BackfillsGapOnRestart(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 10, 5, 0, 0, time.UTC)
+	samples := []TenantUsageSample{{TenantID: "tenant-a", BytesStored: 4096, ObjectCount: 2}}
+	tracker := newTestUsageTracker(t, &samples, &now)
+
+	tracker.RecordRequest("tenant-a")
+	require.NoError(t, tracker.Sample(ctx))
+
+	// Server was down from 10:05 until 14:30
+	now = time.Date(2026, 5, 1, 14, 30, 0, 0, time.UTC)
+	require.NoError(t, tracker.Backfill(ctx))
+
+	points, err := tracker.GetTenantUsage(ctx, "tenant-a", time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), now, UsageGranularityHourly)
+	require.NoError(t, err)
+	require.Len(t, points, 4, "10:00 plus backfilled 11:00, 12:00 and 13:00")
+	for i, p := range points {
+		assert.True(t, p.Timestamp.Equal(time.Date(2026, 5, 1, 10+i, 0, 0, 0, time.UTC)), "point %d at %s", i, p.Timestamp)
+		assert.Equal(t, int64(4096), p.BytesStored)
+	}
+	assert.Equal(t, uint64(1), points[0].Requests)
+	assert.Equal(t, uint64(0), points[3].Requests)
+
+	// Running it again has nothing left to fill
+	require.NoError(t, tracker.Backfill(ctx))
+	points, err = tracker.GetTenantUsage(ctx, "tenant-a", time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), now, UsageGranularityHourly)
+	require.NoError(t, err)
+	assert.Len(t, points, 4)
+}
+
+func TestUsageTracker_DailyBuckets(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	samples := []TenantUsageSample{}
+	tracker := newTestUsageTracker(t, &samples, &now)
+
+	day1 := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	require.NoError(t, tracker.history.SaveUsagePoints("tenant-a", []UsagePoint{
+		{Timestamp: day1.Add(1 * time.Hour), BytesStored: 100, ObjectCount: 1, Requests: 5},
+		{Timestamp: day1.Add(2 * time.Hour), BytesStored: 300, ObjectCount: 2, Requests: 7},
+		{Timestamp: day2.Add(23 * time.Hour), BytesStored: 1000, ObjectCount: 4, Requests: 1},
+		{Timestamp: day2.Add(24 * time.Hour), BytesStored: 9999, ObjectCount: 9, Requests: 100}, // outside range
+	}))
+
+	points, err := tracker.GetTenantUsage(ctx, "tenant-a", day1, day2.Add(23*time.Hour+59*time.Minute), UsageGranularityDaily)
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+
+	assert.True(t, points[0].Timestamp.Equal(day1))
+	assert.Equal(t, int64(200), points[0].BytesStored, "average of the day's hourly values")
+	assert.Equal(t, int64(2), points[0].ObjectCount)
+	assert.Equal(t, uint64(12), points[0].Requests)
+
+	assert.True(t, points[1].Timestamp.Equal(day2))
+	assert.Equal(t, int64(1000), points[1].BytesStored)
+	assert.Equal(t, uint64(1), points[1].Requests)
+
+	_, err = tracker.GetTenantUsage(ctx, "tenant-a", day1, day2, "weekly")
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// privateIPNets contains the CIDR ranges that are always considered trusted
+// (loopback, RFC-1918 private, and IPv6 equivalents).
+var privateIPNets = func() []*net.IPNet {
+	ranges := []string{
+		"127.0.0.0/8",    // IPv4 loopback
+		"10.0.0.0/8",     // RFC-1918
+		"172.16.0.0/12",  // RFC-1918
+		"192.168.0.0/16", // RFC-1918
+		"::1/128",        // IPv6 loopback
+		"fc00::/7",       // IPv6 unique local
+	}
+	nets := make([]*net.IPNet, 0, len(ranges))
+	for _, cidr := range ranges {
+		_, n, err := net.ParseCIDR(cidr)
+		if err == nil {
+			nets = append(nets, n)
+		}
+	}
+	return nets
+}()
+
+// IsTrustedProxy returns true if ip is in a private/loopback range or in the
+// user-supplied trustedProxies list (IPs or CIDRs).
+func IsTrustedProxy(ip net.IP, trustedProxies []string) bool {
+	for _, n := range privateIPNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return isConfiguredProxy(ip, trustedProxies)
+}
+
+// isConfiguredProxy returns true if ip is in the user-supplied trustedProxies
+// list (IPs or CIDRs), without the implicit private/loopback ranges.
+func isConfiguredProxy(ip net.IP, trustedProxies []string) bool {
+	for _, entry := range trustedProxies {
+		if strings.Contains(entry, "/") {
+			_, cidr, err := net.ParseCIDR(entry)
+			if err == nil && cidr.Contains(ip) {
+				return true
+			}
+		} else {
+			if net.ParseIP(entry).Equal(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upstreamIP returns the host part of RemoteAddr and its parsed IP (nil if unparseable).
+func upstreamIP(r *http.Request) (string, net.IP) {
+	remoteHost := r.RemoteAddr
+	if idx := strings.LastIndex(remoteHost, ":"); idx != -1 {
+		remoteHost = remoteHost[:idx]
+	}
+	// Strip IPv6 brackets if present
+	remoteHost = strings.Trim(remoteHost, "[]")
+	return remoteHost, net.ParseIP(remoteHost)
+}
+
+// ClientIP extracts the real client IP address from the request.
+// It only trusts X-Forwarded-For / X-Real-IP headers when the direct
+// connection (RemoteAddr) comes from a private/loopback network or from
+// an address in trustedProxies, preventing IP spoofing by external clients.
+func ClientIP(r *http.Request, trustedProxies []string) string {
+	return clientIP(r, trustedProxies, IsTrustedProxy)
+}
+
+// PolicyClientIP is ClientIP for access decisions such as the aws:SourceIp
+// bucket policy condition. Proxy headers are only honoured when the peer is
+// listed in trustedProxies: any host on a private network could otherwise
+// claim an address the policy allows.
+func PolicyClientIP(r *http.Request, trustedProxies []string) string {
+	return clientIP(r, trustedProxies, isConfiguredProxy)
+}
+
+func clientIP(r *http.Request, trustedProxies []string, trusted func(net.IP, []string) bool) string {
+	remoteHost, peer := upstreamIP(r)
+
+	// Only honour proxy headers when the direct peer is trusted
+	if peer != nil && trusted(peer, trustedProxies) {
+		// X-Forwarded-For may contain a comma-separated chain; the leftmost
+		// entry is the original client IP as reported by the first proxy.
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			ips := strings.Split(forwarded, ",")
+			if candidate := strings.TrimSpace(ips[0]); candidate != "" {
+				return candidate
+			}
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return realIP
+		}
+	}
+
+	// Untrusted peer or no proxy headers — use RemoteAddr directly
+	return remoteHost
+}
+
+// IsSecureTransport reports whether the client reached us over TLS: either the
+// connection itself is TLS, or a trusted reverse proxy terminated TLS and set
+// X-Forwarded-Proto: https. The header is ignored from untrusted peers.
+func IsSecureTransport(r *http.Request, trustedProxies []string) bool {
+	return secureTransport(r, trustedProxies, IsTrustedProxy)
+}
+
+// PolicySecureTransport is IsSecureTransport for the aws:SecureTransport
+// bucket policy condition; like PolicyClientIP it only honours
+// X-Forwarded-Proto from peers listed in trustedProxies.
+func PolicySecureTransport(r *http.Request, trustedProxies []string) bool {
+	return secureTransport(r, trustedProxies, isConfiguredProxy)
+}
+
+func secureTransport(r *http.Request, trustedProxies []string, trusted func(net.IP, []string) bool) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if _, peer := upstreamIP(r); peer != nil && trusted(peer, trustedProxies) {
+		return strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+	}
+	return false
+}
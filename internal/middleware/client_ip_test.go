@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	t.Run("untrusted peer ignores X-Forwarded-For", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "203.0.113.9:4000"
+		req.Header.Set("X-Forwarded-For", "10.1.1.1")
+		assert.Equal(t, "203.0.113.9", ClientIP(req, nil))
+	})
+
+	t.Run("private peer is trusted", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.2:4000"
+		req.Header.Set("X-Forwarded-For", "198.51.100.4, 10.0.0.2")
+		assert.Equal(t, "198.51.100.4", ClientIP(req, nil))
+	})
+
+	t.Run("configured proxy is trusted", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "203.0.113.9:4000"
+		req.Header.Set("X-Real-IP", "198.51.100.4")
+		assert.Equal(t, "198.51.100.4", ClientIP(req, []string{"203.0.113.0/24"}))
+	})
+
+	t.Run("IPv6 remote address", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "[2001:db8::1]:4000"
+		assert.Equal(t, "2001:db8::1", ClientIP(req, nil))
+	})
+}
+
+func TestPolicyClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.2:4000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.4")
+	assert.Equal(t, "10.0.0.2", PolicyClientIP(req, nil), "a private peer is not trusted implicitly")
+	assert.Equal(t, "198.51.100.4", PolicyClientIP(req, []string{"10.0.0.0/24"}))
+
+	req.Header.Set("X-Forwarded-Proto", "https")
+	assert.False(t, PolicySecureTransport(req, nil))
+	assert.True(t, PolicySecureTransport(req, []string{"10.0.0.2"}))
+}
+
+func TestIsSecureTransport(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.9:4000"
+	assert.False(t, IsSecureTransport(req, nil))
+
+	req.Header.Set("X-Forwarded-Proto", "https")
+	assert.False(t, IsSecureTransport(req, nil), "header from an untrusted peer is ignored")
+	assert.True(t, IsSecureTransport(req, []string{"203.0.113.9"}))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	assert.True(t, IsSecureTransport(req, nil))
+}
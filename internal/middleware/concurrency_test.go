@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiter_PutLimit(t *testing.T) {
+	limiter := NewConcurrencyLimiter(2, 0, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := limiter.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.RawQuery == "" {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader("data")))
+		return rr
+	}
+
+	// Fill both PUT slots with uploads that block in the handler
+	done := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- serve("PUT", "/photos/big.iso").Code }()
+		<-started
+	}
+	assert.Equal(t, 2, limiter.InFlight()[ConcurrencyOpPut])
+
+	rr := serve("PUT", "/photos/third.iso")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), "<Code>SlowDown</Code>")
+
+	// Other operation classes and object subresources are unaffected
+	assert.Equal(t, http.StatusOK, serve("GET", "/photos/big.iso").Code)
+	assert.Equal(t, http.StatusOK, serve("PUT", "/photos/big.iso?tagging").Code)
+	assert.Equal(t, http.StatusOK, serve("PUT", "/photos/big.iso?partNumber=1&uploadId=abc").Code)
+
+	// Completing an upload frees its slot
+	release <- struct{}{}
+	require.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, 1, limiter.InFlight()[ConcurrencyOpPut])
+
+	go func() { done <- serve("PUT", "/photos/third.iso").Code }()
+	<-started
+	assert.Equal(t, 2, limiter.InFlight()[ConcurrencyOpPut])
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, 0, limiter.InFlight()[ConcurrencyOpPut])
+}
+
+func TestS3ConcurrencyOp(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		want   string
+	}{
+		{"PUT", "/bucket/key", ConcurrencyOpPut},
+		{"PUT", "/bucket/dir/key?versionId=1", ConcurrencyOpPut},
+		{"GET", "/bucket/key", ConcurrencyOpGet},
+		{"POST", "/bucket/key?uploads", ConcurrencyOpMultipart},
+		{"PUT", "/bucket/key?partNumber=2&uploadId=u", ConcurrencyOpMultipart},
+		{"POST", "/bucket/key?uploadId=u", ConcurrencyOpMultipart},
+		{"GET", "/bucket/key?uploadId=u", ConcurrencyOpMultipart},
+		{"HEAD", "/bucket/key", ""},
+		{"DELETE", "/bucket/key", ""},
+		{"GET", "/bucket/key?tagging", ""},
+		{"PUT", "/bucket/key?retention", ""},
+		{"GET", "/bucket", ""},
+		{"GET", "/bucket/", ""},
+		{"PUT", "/bucket", ""},
+		{"GET", "/", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		assert.Equal(t, tt.want, s3ConcurrencyOp(req), "%s %s", tt.method, tt.target)
+	}
+}
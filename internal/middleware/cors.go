@@ -79,6 +79,7 @@ func DefaultCORSConfig() *CORSConfig {
 			"X-Amz-Expected-Bucket-Owner",
 			"X-Amz-Sdk-Checksum-Algorithm",
 			"X-Requested-With",
+			"X-Request-Id",
 			"Cache-Control",
 			"Expires",
 			"If-Match",
@@ -92,6 +93,7 @@ func DefaultCORSConfig() *CORSConfig {
 			// AWS S3 response headers — listed explicitly (BUG-07)
 			"x-amz-request-id",
 			"x-amz-id-2",
+			"X-Request-Id",
 			"x-amz-version-id",
 			"x-amz-delete-marker",
 			"x-amz-storage-class",
@@ -135,8 +137,20 @@ func CORSWithConfig(config *CORSConfig) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
 
-			// Check if origin is allowed
-			if origin != "" && config.isOriginAllowed(origin) {
+			if origin != "" {
+				// The answer depends on the request origin, so caches must key on it
+				w.Header().Add("Vary", "Origin")
+
+				// A disallowed origin gets no CORS headers at all, so the
+				// browser blocks the response
+				if !config.isOriginAllowed(origin) {
+					if r.Method == "OPTIONS" {
+						w.WriteHeader(http.StatusOK)
+						return
+					}
+					next.ServeHTTP(w, r)
+					return
+				}
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 			} else if config.hasWildcardOrigin() {
 				w.Header().Set("Access-Control-Allow-Origin", "*")
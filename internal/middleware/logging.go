@@ -272,6 +272,10 @@ func getRemoteAddr(r *http.Request) string {
 }
 
 func getRequestID(r *http.Request) string {
+	// Prefer the correlation ID assigned by the RequestID middleware
+	if rid := GetRequestID(r.Context()); rid != "" {
+		return rid
+	}
 	// Check common request ID headers
 	if rid := r.Header.Get("X-Request-ID"); rid != "" {
 		return rid
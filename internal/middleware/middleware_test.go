@@ -357,7 +357,9 @@ func TestCORSWithConfig(t *testing.T) {
 
 	t.Run("Disallowed origin", func(t *testing.T) {
 		config := &CORSConfig{
-			AllowedOrigins: []string{"http://example.com"},
+			AllowedOrigins:   []string{"http://example.com"},
+			AllowedMethods:   []string{"GET", "POST"},
+			AllowCredentials: true,
 		}
 
 		handler := CORSWithConfig(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -372,6 +374,9 @@ func TestCORSWithConfig(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "Origin", rec.Header().Get("Vary"))
 	})
 
 	t.Run("Preflight request", func(t *testing.T) {
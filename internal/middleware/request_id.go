@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader carries the per-request correlation ID, both inbound (set by
+// a client or an upstream proxy) and echoed back on every response.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestIDKey is the context key holding the request's correlation ID.
+const RequestIDKey contextKey = "request_id"
+
+// S3 tracing headers: every S3 response carries the request's ID in
+// X-Amz-Request-Id and a host ID in X-Amz-Id-2, and an incoming W3C
+// traceparent is attached to the log entries written with its context.
+const (
+	AmzRequestIDHeader = "X-Amz-Request-Id"
+	AmzID2Header       = "X-Amz-Id-2"
+	TraceparentHeader  = "Traceparent"
+)
+
+// TraceparentKey is the context key holding the request's W3C traceparent.
+const TraceparentKey contextKey = "traceparent"
+
+// maxRequestIDLength bounds a client-supplied correlation ID. Longer values,
+// or values with characters outside [A-Za-z0-9._:-], are replaced with a
+// generated ID so they can't bloat or forge log lines.
+const maxRequestIDLength = 128
+
+// RequestID returns a middleware that assigns every request a correlation ID.
+// A valid incoming X-Request-Id is kept so a request can be traced across a
+// proxy chain; otherwise a new one is generated. The ID is stored in the
+// request context (see GetRequestID) and echoed in the response header.
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = generateRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+		})
+	}
+}
+
+// S3RequestID returns the S3 router's variant of RequestID: the correlation ID
+// doubles as the S3 request ID, echoed in X-Amz-Request-Id as well as
+// X-Request-Id, along with a host ID in X-Amz-Id-2 (see AmzRequestIDs). With
+// honorIncoming, a valid X-Amz-Request-Id or X-Request-Id from the client is
+// kept; otherwise every request gets a new ID. A valid traceparent header is
+// stored in the context for the log entries written with it.
+func S3RequestID(honorIncoming bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := ""
+			if honorIncoming {
+				for _, name := range []string{AmzRequestIDHeader, RequestIDHeader} {
+					if v := r.Header.Get(name); validRequestID(v) {
+						id = v
+						break
+					}
+				}
+			}
+			if id == "" {
+				id = generateRequestID()
+			}
+			w.Header().Set(AmzRequestIDHeader, id)
+			w.Header().Set(AmzID2Header, generateHostID())
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := WithRequestID(r.Context(), id)
+			if tp := r.Header.Get(TraceparentHeader); validTraceparent(tp) {
+				ctx = context.WithValue(ctx, TraceparentKey, tp)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// AmzRequestIDs returns the X-Amz-Request-Id and X-Amz-Id-2 of the response
+// being written to w, setting new ones first if S3RequestID hasn't, so an
+// S3 error body quotes the same IDs as the response headers.
+func AmzRequestIDs(w http.ResponseWriter) (requestID, hostID string) {
+	requestID = w.Header().Get(AmzRequestIDHeader)
+	if requestID == "" {
+		requestID = generateRequestID()
+		w.Header().Set(AmzRequestIDHeader, requestID)
+	}
+	hostID = w.Header().Get(AmzID2Header)
+	if hostID == "" {
+		hostID = generateHostID()
+		w.Header().Set(AmzID2Header, hostID)
+	}
+	return requestID, hostID
+}
+
+// GetTraceparent returns the W3C traceparent stored in ctx, or "" if none.
+func GetTraceparent(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	tp, _ := ctx.Value(TraceparentKey).(string)
+	return tp
+}
+
+// validTraceparent reports whether tp is a W3C trace context traceparent:
+// version-traceid-parentid-flags in lowercase hex, with non-zero IDs.
+func validTraceparent(tp string) bool {
+	parts := strings.Split(tp, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return false
+	}
+	// Version 00 has exactly four fields; later versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return false
+	}
+	for i, n := range []int{2, 32, 16, 2} {
+		if len(parts[i]) != n || strings.Trim(parts[i], "0123456789abcdef") != "" {
+			return false
+		}
+	}
+	return strings.Trim(parts[1], "0") != "" && strings.Trim(parts[2], "0") != ""
+}
+
+// WithRequestID returns a copy of ctx carrying the given correlation ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, id)
+}
+
+// GetRequestID returns the correlation ID stored in ctx, or "" if none.
+func GetRequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RequestIDHook is a logrus hook that adds a "request_id" field to every
+// entry logged with a request context (logrus.WithContext(r.Context())).
+// Entries without a context, or without an ID in it, are left untouched, so
+// handler log lines only carry the ID when they are logged that way.
+type RequestIDHook struct{}
+
+// Levels returns all log levels this hook handles
+func (RequestIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the correlation ID and traceparent from the entry's context,
+// unless the entry already sets request_id or traceparent explicitly.
+func (RequestIDHook) Fire(entry *logrus.Entry) error {
+	if id := GetRequestID(entry.Context); id != "" {
+		if _, ok := entry.Data[string(RequestIDKey)]; !ok {
+			entry.Data[string(RequestIDKey)] = id
+		}
+	}
+	if tp := GetTraceparent(entry.Context); tp != "" {
+		if _, ok := entry.Data[string(TraceparentKey)]; !ok {
+			entry.Data[string(TraceparentKey)] = tp
+		}
+	}
+	return nil
+}
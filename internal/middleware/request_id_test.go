@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer serialises writes from concurrent requests into one log stream.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func TestRequestID_ConcurrentRequestsGetDistinctLogIDs(t *testing.T) {
+	out := &syncBuffer{}
+	logger := logrus.New()
+	logger.SetOutput(out)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(RequestIDHook{})
+
+	// Both requests are inside the handler before either logs its second
+	// line, so their log lines interleave.
+	var inside sync.WaitGroup
+	inside.Add(2)
+	handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.WithContext(r.Context()).WithField("path", r.URL.Path).Info("request started")
+		inside.Done()
+		inside.Wait()
+		logger.WithContext(r.Context()).WithField("path", r.URL.Path).Info("request finished")
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	paths := []string{"/bucket-a/obj", "/bucket-b/obj"}
+	responseIDs := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+			mu.Lock()
+			responseIDs[path] = rr.Header().Get(RequestIDHeader)
+			mu.Unlock()
+		}(path)
+	}
+	wg.Wait()
+
+	logIDs := make(map[string][]string)
+	scanner := bufio.NewScanner(strings.NewReader(out.buf.String()))
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		id, _ := line["request_id"].(string)
+		require.NotEmpty(t, id, "log line without request_id: %s", scanner.Text())
+		path := line["path"].(string)
+		logIDs[path] = append(logIDs[path], id)
+	}
+
+	for _, path := range paths {
+		require.Len(t, logIDs[path], 2, path)
+		assert.NotEmpty(t, responseIDs[path])
+		for _, id := range logIDs[path] {
+			assert.Equal(t, responseIDs[path], id, "log line for %s carries another request's id", path)
+		}
+	}
+	assert.NotEqual(t, responseIDs[paths[0]], responseIDs[paths[1]])
+}
+
+func TestRequestID_PropagatesIncomingHeader(t *testing.T) {
+	var seen string
+	handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = GetRequestID(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"valid upstream id", "proxy-7f3a.42:1", true},
+		{"absent", "", false},
+		{"forged log content", "abc\n{\"level\":\"error\"}", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.NotEmpty(t, seen)
+			assert.Equal(t, seen, rr.Header().Get(RequestIDHeader))
+			if tt.keep {
+				assert.Equal(t, tt.incoming, seen)
+			} else {
+				assert.NotEqual(t, tt.incoming, seen)
+			}
+		})
+	}
+}
+
+func TestRequestIDHook_LeavesContextlessEntriesAlone(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(RequestIDHook{})
+
+	logger.Info("background work")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.NotContains(t, line, "request_id")
+}
+
+func TestS3RequestID_EchoesIDsAndTraceparent(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(RequestIDHook{})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.WithContext(r.Context()).Info("handled")
+	})
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	serve := func(honor bool, header map[string]string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		buf.Reset()
+		req := httptest.NewRequest("GET", "/bucket/key", nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		S3RequestID(honor)(next).ServeHTTP(rr, req)
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		return rr, line
+	}
+
+	rr, line := serve(true, map[string]string{AmzRequestIDHeader: "client-req-1", TraceparentHeader: traceparent})
+	assert.Equal(t, "client-req-1", rr.Header().Get(AmzRequestIDHeader))
+	assert.Equal(t, "client-req-1", rr.Header().Get(RequestIDHeader))
+	assert.NotEmpty(t, rr.Header().Get(AmzID2Header))
+	assert.Equal(t, "client-req-1", line["request_id"])
+	assert.Equal(t, traceparent, line["traceparent"])
+
+	rr, _ = serve(true, map[string]string{RequestIDHeader: "proxy-req-2"})
+	assert.Equal(t, "proxy-req-2", rr.Header().Get(AmzRequestIDHeader))
+
+	rr, line = serve(false, map[string]string{AmzRequestIDHeader: "client-req-1"})
+	id := rr.Header().Get(AmzRequestIDHeader)
+	assert.Len(t, id, 16, "generated ids are 16 hex characters")
+	assert.NotEqual(t, "client-req-1", id)
+	assert.Equal(t, id, line["request_id"])
+	assert.NotContains(t, line, "traceparent")
+
+	for _, bad := range []string{
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, line = serve(true, map[string]string{TraceparentHeader: bad})
+		assert.NotContains(t, line, "traceparent", bad)
+	}
+}
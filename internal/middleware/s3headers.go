@@ -57,17 +57,15 @@ func S3Headers() func(http.Handler) http.Handler {
 
 // addS3Headers adds all S3-compatible response headers
 func addS3Headers(w http.ResponseWriter) {
-	// X-Amz-Request-Id: 16 character hex string (like MinIO)
-	w.Header().Set("X-Amz-Request-Id", generateRequestID())
-
-	// X-Amz-Id-2: 64 character hex string (host ID)
-	w.Header().Set("X-Amz-Id-2", generateHostID())
+	// X-Amz-Request-Id (16 character hex string, like MinIO) and X-Amz-Id-2
+	// (host ID), unless S3RequestID has assigned them already
+	AmzRequestIDs(w)
 
 	// Server header - identify as MaxIOFS
 	w.Header().Set("Server", "MaxIOFS")
 
-	// Accept ranges for partial content support
-	w.Header().Set("Accept-Ranges", "bytes")
+	// Accept-Ranges is set by the object handlers, and only for objects that
+	// can actually be served in ranges
 
 	// Security headers (same as MinIO)
 	w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
@@ -45,7 +45,7 @@ func TracingMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Log request start
-		logrus.WithFields(logrus.Fields{
+		logrus.WithContext(ctx).WithFields(logrus.Fields{
 			"trace_id": traceID,
 			"method":   r.Method,
 			"path":     r.URL.Path,
@@ -84,7 +84,7 @@ func TracingMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Log request completion
-		logrus.WithFields(logrus.Fields{
+		logrus.WithContext(ctx).WithFields(logrus.Fields{
 			"trace_id":    traceID,
 			"method":      r.Method,
 			"path":        r.URL.Path,
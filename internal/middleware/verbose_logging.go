@@ -14,7 +14,7 @@ import (
 // Enable debug logging to see individual S3 API requests.
 func S3RequestLog(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logrus.WithFields(logrus.Fields{
+		logrus.WithContext(r.Context()).WithFields(logrus.Fields{
 			"method": r.Method,
 			"path":   r.URL.Path,
 			"host":   r.Host,
@@ -32,7 +32,7 @@ func VerboseLogging() func(http.Handler) http.Handler {
 			start := time.Now()
 
 			// Log incoming request with ALL details (only in DEBUG mode)
-			logrus.WithFields(logrus.Fields{
+			logrus.WithContext(r.Context()).WithFields(logrus.Fields{
 				"method":         r.Method,
 				"url":            r.URL.String(),
 				"path":           r.URL.Path,
@@ -84,7 +84,7 @@ func VerboseLogging() func(http.Handler) http.Handler {
 			duration := time.Since(start)
 
 			// Log response (only in DEBUG mode)
-			logrus.WithFields(logrus.Fields{
+			logrus.WithContext(r.Context()).WithFields(logrus.Fields{
 				"status":      rw.statusCode,
 				"size":        rw.size,
 				"duration_ms": duration.Milliseconds(),
@@ -28,6 +28,8 @@ func toMetadataObject(o *Object) *metadata.ObjectMetadata {
 		ChecksumAlgorithm:  o.ChecksumAlgorithm,
 		ChecksumValue:      o.ChecksumValue,
 		SSEAlgorithm:       o.SSEAlgorithm,
+		ExpiresAt:          o.ExpiresAt,
+		DataMissingAt:      o.DataMissingAt,
 	}
 
 	// Object Lock - Retention
@@ -86,6 +88,8 @@ func fromMetadataObject(mo *metadata.ObjectMetadata) *Object {
 		SSEAlgorithm:       mo.SSEAlgorithm,
 		RestoreStatus:      mo.RestoreStatus,
 		RestoreExpiresAt:   mo.RestoreExpiresAt,
+		ExpiresAt:          mo.ExpiresAt,
+		DataMissingAt:      mo.DataMissingAt,
 	}
 
 	// Object Lock - Retention
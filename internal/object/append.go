@@ -0,0 +1,125 @@
+package object
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+)
+
+type keyLockHeldKey struct{}
+
+// withKeyLockHeld tells PutObject, GetObject and deletePermanently that the
+// caller already holds the key's shard lock, which is not reentrant.
+func withKeyLockHeld(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keyLockHeldKey{}, true)
+}
+
+func keyLockHeld(ctx context.Context) bool {
+	v, _ := ctx.Value(keyLockHeldKey{}).(bool)
+	return v
+}
+
+// AppendObject adds data to the end of the current object at key, creating
+// the object when it does not exist. When writeOffset is not negative it must
+// equal the current size, otherwise ErrInvalidWriteOffset is returned and
+// nothing is written; a missing object has size 0.
+//
+// Objects are encrypted as one stream, so the current data and the appended
+// data are rewritten together as a new object. The key lock is held for the
+// whole operation, which makes concurrent appends to one key apply one after
+// the other. headers only apply when the object is created; an existing
+// object keeps its content headers, user metadata, tags, ACL and expiry
+// time. An object whose TTL has run out reads as missing, so appending to it
+// starts a new one.
+//
+// Appending is refused in versioned buckets (every append would become a new
+// version) and on objects under retention or legal hold.
+func (om *objectManager) AppendObject(ctx context.Context, bucket, key string, data io.Reader, headers http.Header, writeOffset int64) (*Object, error) {
+	if err := om.validateObjectName(key); err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(key, "/") || om.isBucketVersioningEnabled(ctx, bucket) {
+		return nil, ErrAppendNotSupported
+	}
+
+	defer om.lockKey(bucket, key)()
+	ctx = withKeyLockHeld(ctx)
+
+	existing, err := om.metadataStore.GetObject(ctx, bucket, key)
+	if err != nil && err != metadata.ErrObjectNotFound {
+		return nil, err
+	}
+	if existing == nil || isMetadataDeleteMarker(existing) || isMetadataExpired(existing, time.Now()) {
+		if writeOffset > 0 {
+			return nil, ErrInvalidWriteOffset
+		}
+		return om.PutObject(ctx, bucket, key, data, headers)
+	}
+
+	if writeOffset >= 0 && writeOffset != existing.Size {
+		return nil, ErrInvalidWriteOffset
+	}
+	if existing.LegalHold {
+		return nil, ErrObjectUnderLegalHold
+	}
+	if err := om.checkOverwriteRetention(ctx, bucket, key); err != nil {
+		return nil, err
+	}
+
+	// Content-MD5 covers the appended bytes only, not the whole object
+	data, err = NewContentMD5Reader(data, headers.Get("Content-MD5"))
+	if err != nil {
+		return nil, err
+	}
+
+	_, current, err := om.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer current.Close()
+
+	obj, err := om.PutObject(ctx, bucket, key, io.MultiReader(current, data), appendHeaders(existing))
+	if err != nil {
+		return nil, err
+	}
+
+	// PutObject writes fresh metadata; carry over what belongs to the object
+	// rather than to its data. The TTL counts from the first write, so the
+	// expiry time is kept as is.
+	if len(existing.Tags) > 0 || existing.ACL != nil || existing.ExpiresAt != nil {
+		if updated, err := om.metadataStore.GetObject(ctx, bucket, key); err == nil {
+			updated.Tags = existing.Tags
+			updated.ACL = existing.ACL
+			updated.ExpiresAt = existing.ExpiresAt
+			if err := om.metadataStore.PutObject(ctx, updated); err != nil {
+				return nil, err
+			}
+		}
+	}
+	obj.ExpiresAt = existing.ExpiresAt
+	return obj, nil
+}
+
+// appendHeaders rebuilds the upload headers of an existing object so an
+// append keeps its content headers, storage class and user metadata.
+func appendHeaders(existing *metadata.ObjectMetadata) http.Header {
+	h := http.Header{}
+	for name, value := range map[string]string{
+		"Content-Type":        existing.ContentType,
+		"Content-Disposition": existing.ContentDisposition,
+		"Content-Encoding":    existing.ContentEncoding,
+		"Cache-Control":       existing.CacheControl,
+		"Content-Language":    existing.ContentLanguage,
+		"x-amz-storage-class": existing.StorageClass,
+	} {
+		if value != "" {
+			h.Set(name, value)
+		}
+	}
+	SetUserMetadataHeaders(h, existing.Metadata)
+	return h
+}
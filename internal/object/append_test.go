@@ -0,0 +1,175 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAppendTest(t *testing.T) (*objectManager, metadata.Store, context.Context) {
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "logs", TenantID: "tenant-1", OwnerID: "user-1"}))
+	return om, metaStore, ctx
+}
+
+func readObject(t *testing.T, om *objectManager, bucket, key string) string {
+	t.Helper()
+	_, reader, err := om.GetObject(context.Background(), bucket, key)
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestAppendObject_Sequential(t *testing.T) {
+	om, _, ctx := setupAppendTest(t)
+	bucket := "tenant-1/logs"
+
+	// Appending to a missing object creates it
+	obj, err := om.AppendObject(ctx, bucket, "device.log", bytes.NewReader([]byte("line 1\n")),
+		http.Header{"Content-Type": []string{"text/plain"}, "X-Amz-Meta-Device": []string{"sensor-7"}}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), obj.Size)
+	require.NoError(t, om.SetObjectTagging(ctx, bucket, "device.log", &TagSet{Tags: []Tag{{Key: "site", Value: "north"}}}))
+
+	obj, err = om.AppendObject(ctx, bucket, "device.log", bytes.NewReader([]byte("line 2\n")), http.Header{}, 7)
+	require.NoError(t, err)
+	obj, err = om.AppendObject(ctx, bucket, "device.log", bytes.NewReader([]byte("line 3\n")), http.Header{}, -1)
+	require.NoError(t, err)
+
+	want := "line 1\nline 2\nline 3\n"
+	sum := md5.Sum([]byte(want))
+	assert.Equal(t, int64(len(want)), obj.Size)
+	assert.Equal(t, hex.EncodeToString(sum[:]), obj.ETag)
+	assert.Equal(t, want, readObject(t, om, bucket, "device.log"))
+
+	stored, err := om.GetObjectMetadata(ctx, bucket, "device.log")
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", stored.ContentType)
+	assert.Equal(t, "sensor-7", stored.Metadata["device"])
+	tags, err := om.GetObjectTagging(ctx, bucket, "device.log")
+	require.NoError(t, err)
+	assert.Equal(t, []Tag{{Key: "site", Value: "north"}}, tags.Tags)
+
+	// A stale offset is refused and leaves the object as it was
+	_, err = om.AppendObject(ctx, bucket, "device.log", bytes.NewReader([]byte("dup\n")), http.Header{}, 7)
+	assert.ErrorIs(t, err, ErrInvalidWriteOffset)
+	_, err = om.AppendObject(ctx, bucket, "missing.log", bytes.NewReader([]byte("x")), http.Header{}, 3)
+	assert.ErrorIs(t, err, ErrInvalidWriteOffset)
+	assert.Equal(t, want, readObject(t, om, bucket, "device.log"))
+}
+
+func TestAppendObject_ConcurrentAppends(t *testing.T) {
+	om, _, ctx := setupAppendTest(t)
+	bucket := "tenant-1/logs"
+	_, err := om.PutObject(ctx, bucket, "device.log", bytes.NewReader([]byte("start\n")), http.Header{})
+	require.NoError(t, err)
+
+	// Two writers that both saw a 6-byte object: only one may append
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = om.AppendObject(ctx, bucket, "device.log", bytes.NewReader([]byte(fmt.Sprintf("writer %d\n", i))), http.Header{}, 6)
+		}(i)
+	}
+	wg.Wait()
+	conflicts := 0
+	for _, err := range errs {
+		if err != nil {
+			assert.ErrorIs(t, err, ErrInvalidWriteOffset)
+			conflicts++
+		}
+	}
+	assert.Equal(t, 1, conflicts)
+	assert.Len(t, readObject(t, om, bucket, "device.log"), len("start\nwriter 0\n"))
+
+	// Appends without an offset are serialised and none is lost
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := om.AppendObject(ctx, bucket, "device.log", bytes.NewReader([]byte("x\n")), http.Header{}, -1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	obj, err := om.GetObjectMetadata(ctx, bucket, "device.log")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("start\nwriter 0\n")+5*len("x\n")), obj.Size)
+}
+
+func TestAppendObject_Refused(t *testing.T) {
+	om, metaStore, ctx := setupAppendTest(t)
+	bucket := "tenant-1/logs"
+
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{
+		Name: "versioned", TenantID: "tenant-1", OwnerID: "user-1",
+		Versioning: &metadata.VersioningMetadata{Enabled: true, Status: "Enabled"},
+	}))
+	_, err := om.AppendObject(ctx, "tenant-1/versioned", "a.log", bytes.NewReader([]byte("x")), http.Header{}, -1)
+	assert.ErrorIs(t, err, ErrAppendNotSupported)
+
+	_, err = om.PutObject(ctx, bucket, "held.log", bytes.NewReader([]byte("x")), http.Header{})
+	require.NoError(t, err)
+	require.NoError(t, om.SetObjectLegalHold(ctx, bucket, "held.log", &LegalHoldConfig{Status: LegalHoldStatusOn}))
+	_, err = om.AppendObject(ctx, bucket, "held.log", bytes.NewReader([]byte("y")), http.Header{}, -1)
+	assert.ErrorIs(t, err, ErrObjectUnderLegalHold)
+
+	_, err = om.PutObject(ctx, bucket, "retained.log", bytes.NewReader([]byte("x")), http.Header{})
+	require.NoError(t, err)
+	require.NoError(t, om.SetObjectRetention(ctx, bucket, "retained.log", &RetentionConfig{Mode: RetentionModeGovernance, RetainUntilDate: time.Now().Add(time.Hour)}))
+	_, err = om.AppendObject(ctx, bucket, "retained.log", bytes.NewReader([]byte("y")), http.Header{}, -1)
+	var retErr *RetentionError
+	assert.ErrorAs(t, err, &retErr)
+	assert.Equal(t, "x", readObject(t, om, bucket, "retained.log"))
+}
+
+func TestAppendObject_KeepsExpiry(t *testing.T) {
+	om, metaStore, ctx := setupAppendTest(t)
+	bucket := "tenant-1/logs"
+
+	headers := http.Header{}
+	headers.Set(ExpiresAfterHeader, "3600")
+	created, err := om.AppendObject(ctx, bucket, "session.log", bytes.NewReader([]byte("start\n")), headers, 0)
+	require.NoError(t, err)
+	require.NotNil(t, created.ExpiresAt)
+	expiresAt := *created.ExpiresAt
+
+	obj, err := om.AppendObject(ctx, bucket, "session.log", bytes.NewReader([]byte("more\n")), http.Header{}, -1)
+	require.NoError(t, err)
+	require.NotNil(t, obj.ExpiresAt, "an append must not drop the object's TTL")
+	assert.True(t, expiresAt.Equal(*obj.ExpiresAt), "the TTL counts from the first write")
+
+	stored, err := om.GetObjectMetadata(ctx, bucket, "session.log")
+	require.NoError(t, err)
+	require.NotNil(t, stored.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*stored.ExpiresAt))
+
+	// Once expired the object reads as missing, and an append starts over
+	meta, err := metaStore.GetObject(ctx, bucket, "session.log")
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+	meta.ExpiresAt = &past
+	require.NoError(t, metaStore.PutObject(ctx, meta))
+	obj, err = om.AppendObject(ctx, bucket, "session.log", bytes.NewReader([]byte("fresh\n")), http.Header{}, 0)
+	require.NoError(t, err)
+	assert.Nil(t, obj.ExpiresAt)
+	assert.Equal(t, "fresh\n", readObject(t, om, bucket, "session.log"))
+}
@@ -0,0 +1,85 @@
+package object
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"net/http"
+	"strings"
+)
+
+// ErrBadChecksum is returned when data does not match the x-amz-checksum-*
+// value sent with it.
+var ErrBadChecksum = errors.New("BadDigest: the checksum you specified did not match what was received")
+
+// ErrInvalidChecksumAlgorithm is returned for an unknown checksum algorithm,
+// or a part checksum of another algorithm than its upload was created with.
+var ErrInvalidChecksumAlgorithm = errors.New("invalid checksum algorithm")
+
+// checksumAlgorithms are the S3 additional checksum algorithms, in the order
+// their x-amz-checksum-* headers are looked for.
+var checksumAlgorithms = []string{"CRC32", "CRC32C", "SHA1", "SHA256"}
+
+// newChecksumHasher returns the hash for an S3 additional checksum
+// algorithm, or nil when algo is empty or not supported.
+func newChecksumHasher(algo string) hash.Hash {
+	switch strings.ToUpper(algo) {
+	case "CRC32":
+		return crc32.NewIEEE()
+	case "CRC32C":
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case "SHA1":
+		return sha1.New()
+	case "SHA256":
+		return sha256.New()
+	}
+	return nil
+}
+
+// ChecksumHeader returns the header carrying a checksum of the given
+// algorithm, e.g. x-amz-checksum-crc32c.
+func ChecksumHeader(algo string) string {
+	return "x-amz-checksum-" + strings.ToLower(algo)
+}
+
+// requestChecksum returns the checksum algorithm a request asks for and the
+// value it sent, if any. An explicit x-amz-sdk-checksum-algorithm (or
+// x-amz-checksum-algorithm) wins; otherwise the first x-amz-checksum-*
+// value header present decides.
+func requestChecksum(headers http.Header) (algo, value string) {
+	algo = strings.ToUpper(headers.Get("x-amz-sdk-checksum-algorithm"))
+	if algo == "" {
+		algo = strings.ToUpper(headers.Get("x-amz-checksum-algorithm"))
+	}
+	if algo != "" {
+		return algo, headers.Get(ChecksumHeader(algo))
+	}
+	for _, candidate := range checksumAlgorithms {
+		if v := headers.Get(ChecksumHeader(candidate)); v != "" {
+			return candidate, v
+		}
+	}
+	return "", ""
+}
+
+// CompositeChecksum computes the checksum of a multipart object the way S3
+// does: the algorithm applied to the concatenated binary checksums of the
+// parts, base64-encoded, followed by "-" and the number of parts.
+func CompositeChecksum(algo string, partChecksums []string) (string, error) {
+	hasher := newChecksumHasher(algo)
+	if hasher == nil {
+		return "", fmt.Errorf("unsupported checksum algorithm %q", algo)
+	}
+	for i, value := range partChecksums {
+		raw, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(raw) != hasher.Size() {
+			return "", fmt.Errorf("invalid %s checksum for part %d", algo, i+1)
+		}
+		hasher.Write(raw)
+	}
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(hasher.Sum(nil)), len(partChecksums)), nil
+}
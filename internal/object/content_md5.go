@@ -0,0 +1,66 @@
+package object
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"hash"
+	"io"
+)
+
+// ParseContentMD5 decodes a base64 Content-MD5 header value. It returns nil
+// for an empty value and ErrInvalidDigest for anything that is not a
+// base64-encoded 16-byte digest.
+func ParseContentMD5(value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	digest, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(digest) != md5.Size {
+		return nil, ErrInvalidDigest
+	}
+	return digest, nil
+}
+
+// VerifyContentMD5 checks data against a Content-MD5 header value. An empty
+// value always passes.
+func VerifyContentMD5(value string, data []byte) error {
+	expected, err := ParseContentMD5(value)
+	if err != nil || expected == nil {
+		return err
+	}
+	sum := md5.Sum(data)
+	if !bytes.Equal(sum[:], expected) {
+		return ErrBadDigest
+	}
+	return nil
+}
+
+// contentMD5Reader hashes a stream as it is read and fails the final read
+// with ErrBadDigest when the digest does not match, so a writer copying from
+// it never commits a corrupted upload.
+type contentMD5Reader struct {
+	r        io.Reader
+	hash     hash.Hash
+	expected []byte
+}
+
+// NewContentMD5Reader wraps r to verify it against a Content-MD5 header
+// value. An empty value returns r unchanged; a malformed one returns
+// ErrInvalidDigest.
+func NewContentMD5Reader(r io.Reader, value string) (io.Reader, error) {
+	expected, err := ParseContentMD5(value)
+	if err != nil || expected == nil {
+		return r, err
+	}
+	return &contentMD5Reader{r: r, hash: md5.New(), expected: expected}, nil
+}
+
+func (c *contentMD5Reader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.hash.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(c.hash.Sum(nil), c.expected) {
+		return n, ErrBadDigest
+	}
+	return n, err
+}
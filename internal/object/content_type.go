@@ -0,0 +1,44 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// contentSniffLen is the number of leading bytes http.DetectContentType
+// considers.
+const contentSniffLen = 512
+
+// sniffContentType detects the content type of an upload that did not declare
+// one. The first bytes of data are sniffed; when that is inconclusive (binary
+// or plain text) the key's file extension decides, since "report.json" or
+// "app.css" sniff as plain text. Empty uploads with no known extension stay
+// application/octet-stream.
+//
+// The returned reader replays the sniffed bytes followed by the rest of data
+// and must be used in place of data.
+func sniffContentType(data io.Reader, key string) (io.Reader, string, error) {
+	head := make([]byte, contentSniffLen)
+	n, err := io.ReadFull(data, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, "", fmt.Errorf("failed to read upload for content type detection: %w", err)
+	}
+	head = head[:n]
+
+	contentType := "application/octet-stream"
+	if n > 0 {
+		contentType = http.DetectContentType(head)
+	}
+	if contentType == "application/octet-stream" || strings.HasPrefix(contentType, "text/plain") {
+		if byExt := mime.TypeByExtension(path.Ext(key)); byExt != "" {
+			contentType = byExt
+		}
+	}
+
+	return io.MultiReader(bytes.NewReader(head), data), contentType, nil
+}
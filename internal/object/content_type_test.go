@@ -0,0 +1,126 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 1x1 transparent PNG
+var tinyPNG, _ = base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==")
+
+func createSniffBucket(t *testing.T, metaStore metadata.Store) {
+	t.Helper()
+	require.NoError(t, metaStore.CreateBucket(context.Background(), &metadata.BucketMetadata{Name: "site", OwnerID: "user-1"}))
+}
+
+func TestPutObject_SniffsContentType(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createSniffBucket(t, metaStore)
+
+	tests := []struct {
+		key      string
+		data     []byte
+		expected string
+	}{
+		{"logo", tinyPNG, "image/png"},
+		{"index", []byte("<!DOCTYPE html><html><body>hello</body></html>"), "text/html; charset=utf-8"},
+		// Plain text is inconclusive, so the extension decides
+		{"data/report.json", []byte(`{"total": 42}`), "application/json"},
+		{"blob", []byte{0x00, 0x01, 0x02, 0x03}, "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			obj, err := om.PutObject(ctx, "site", tt.key, bytes.NewReader(tt.data), http.Header{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, obj.ContentType)
+
+			stored, reader, err := om.GetObject(ctx, "site", tt.key)
+			require.NoError(t, err)
+			defer reader.Close()
+			assert.Equal(t, tt.expected, stored.ContentType)
+
+			// The sniffed bytes must still be part of the stored object
+			readBack, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.data, readBack)
+		})
+	}
+}
+
+func TestPutObject_ExplicitContentTypeWins(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createSniffBucket(t, metaStore)
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/x-custom")
+	obj, err := om.PutObject(ctx, "site", "logo.png", bytes.NewReader(tinyPNG), headers)
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-custom", obj.ContentType)
+}
+
+func TestPutObject_SniffingDisabled(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	om.config.DisableContentTypeSniffing = true
+	createSniffBucket(t, metaStore)
+
+	obj, err := om.PutObject(ctx, "site", "logo.png", bytes.NewReader(tinyPNG), http.Header{})
+	require.NoError(t, err)
+	assert.Equal(t, "application/octet-stream", obj.ContentType)
+}
+
+func TestPutObject_BucketDefaultContentType(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{
+		Name:               "lake",
+		OwnerID:            "user-1",
+		DefaultContentType: "application/vnd.apache.parquet",
+	}))
+	parquet := append([]byte("PAR1"), 0x15, 0x00, 0x15, 0x1c)
+
+	t.Run("inconclusive sniff uses default", func(t *testing.T) {
+		obj, err := om.PutObject(ctx, "lake", "part-0000", bytes.NewReader(parquet), http.Header{})
+		require.NoError(t, err)
+		assert.Equal(t, "application/vnd.apache.parquet", obj.ContentType)
+
+		stored, reader, err := om.GetObject(ctx, "lake", "part-0000")
+		require.NoError(t, err)
+		reader.Close()
+		assert.Equal(t, "application/vnd.apache.parquet", stored.ContentType)
+	})
+
+	t.Run("conclusive sniff wins", func(t *testing.T) {
+		obj, err := om.PutObject(ctx, "lake", "logo", bytes.NewReader(tinyPNG), http.Header{})
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", obj.ContentType)
+	})
+
+	t.Run("explicit header wins", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("Content-Type", "application/x-custom")
+		obj, err := om.PutObject(ctx, "lake", "part-0001", bytes.NewReader(parquet), headers)
+		require.NoError(t, err)
+		assert.Equal(t, "application/x-custom", obj.ContentType)
+	})
+
+	t.Run("sniffing disabled uses default", func(t *testing.T) {
+		om.config.DisableContentTypeSniffing = true
+		defer func() { om.config.DisableContentTypeSniffing = false }()
+
+		obj, err := om.PutObject(ctx, "lake", "logo.png", bytes.NewReader(tinyPNG), http.Header{})
+		require.NoError(t, err)
+		assert.Equal(t, "application/vnd.apache.parquet", obj.ContentType)
+	})
+}
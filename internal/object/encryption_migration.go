@@ -97,7 +97,7 @@ func (om *objectManager) convertPathToEnvelope(ctx context.Context, bucket, key,
 	if meta["content-type"] == "application/x-directory" {
 		return false, nil
 	}
-	if action := om.migrationActionFor(meta); action == migrationSkip {
+	if action := om.migrationActionFor(bucket, meta); action == migrationSkip {
 		return false, nil
 	}
 
@@ -106,17 +106,17 @@ func (om *objectManager) convertPathToEnvelope(ctx context.Context, bucket, key,
 	defer om.lockKey(bucket, key)()
 
 	// Re-check under the lock — a client PUT may have replaced the object
-	// (new writes are always current-KEK envelope).
+	// (new writes are always current-key envelope).
 	meta, err = om.storage.GetMetadata(ctx, path)
 	if err != nil {
 		return false, nil
 	}
-	switch om.migrationActionFor(meta) {
+	switch om.migrationActionFor(bucket, meta) {
 	case migrationSkip:
 		return false, nil
 	case migrationRewrap:
-		// Envelope wrapped with an old KEK version: only the wrapped DEK
-		// changes — object data is never touched.
+		// Envelope wrapped with an old KEK or tenant key version: only the
+		// wrapped DEK changes — object data is never touched.
 		return om.rewrapPathDEK(ctx, bucket, key, path, meta)
 	}
 	// migrationEncrypt: plaintext or legacy direct-encrypted → full envelope
@@ -204,7 +204,13 @@ func (om *objectManager) convertPathToEnvelope(ctx context.Context, bucket, key,
 		metaCopy[k] = v
 	}
 
-	if err := om.storeEncryptedObject(ctx, path, tempPath, metaCopy, stagedSize, originalETag); err != nil {
+	staged, err := os.Open(tempPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open staging file: %w", err)
+	}
+	err = om.storeEncryptedObject(ctx, bucket, path, staged, metaCopy, stagedSize, originalETag)
+	staged.Close()
+	if err != nil {
 		return false, fmt.Errorf("failed to rewrite object encrypted: %w", err)
 	}
 	wroteDEK := metaCopy["wrapped-dek"]
@@ -246,19 +252,47 @@ func (om *objectManager) convertPathToEnvelope(ctx context.Context, bucket, key,
 type migrationAction int
 
 const (
-	migrationSkip    migrationAction = iota // already current-KEK envelope
+	migrationSkip    migrationAction = iota // already current-key envelope
 	migrationEncrypt                        // plaintext or legacy direct-encrypted → full envelope rewrite
-	migrationRewrap                         // envelope with an old KEK version → re-wrap DEK only
+	migrationRewrap                         // envelope with an old or foreign key → re-wrap DEK only
 )
 
-// migrationActionFor inspects a sidecar and returns the required action.
-func (om *objectManager) migrationActionFor(meta map[string]string) migrationAction {
+// migrationActionFor inspects the sidecar of an object in bucket and returns
+// the required action.
+func (om *objectManager) migrationActionFor(bucket string, meta map[string]string) migrationAction {
 	if meta["encrypted"] != "true" {
 		return migrationEncrypt // plaintext
 	}
 	if meta["wrapped-dek"] == "" {
 		return migrationEncrypt // legacy direct-encrypted (no DEK)
 	}
+
+	// In a tenant bucket the DEK must be wrapped by the tenant's current key
+	// (objects written before tenant keys existed are still KEK-wrapped).
+	if tenantID, _ := om.parseBucketPath(bucket); tenantID != "" && om.tenantKeys != nil {
+		if meta["tenant-key-id"] != tenantID {
+			return migrationRewrap
+		}
+		version, err := strconv.Atoi(meta["tenant-key-version"])
+		kekVersion, kekErr := strconv.Atoi(meta["tenant-key-kek-version"])
+		if err != nil || kekErr != nil {
+			return migrationSkip // corrupt marker — leave for the integrity tooling
+		}
+		currentKey, current, err := om.tenantKeys.CurrentTenantKey(tenantID)
+		if err != nil {
+			return migrationSkip
+		}
+		// A key regenerated after losing the tenant keys reuses the version
+		if fp := meta["tenant-key-fingerprint"]; fp != "" && fp != tenantKeyFingerprint(tenantID, currentKey) {
+			return migrationRewrap
+		}
+		_, currentKEK := om.kekProvider.CurrentKEK()
+		if version == current && kekVersion == currentKEK {
+			return migrationSkip
+		}
+		return migrationRewrap
+	}
+
 	version, err := strconv.Atoi(meta["kek-version"])
 	if err != nil {
 		return migrationSkip // corrupt marker — leave for the integrity tooling
@@ -270,7 +304,8 @@ func (om *objectManager) migrationActionFor(meta map[string]string) migrationAct
 	return migrationRewrap
 }
 
-// rewrapPathDEK re-wraps an envelope object's DEK with the current KEK.
+// rewrapPathDEK re-wraps an envelope object's DEK with the current key for its
+// bucket (the owning tenant's key, or the KEK in a global bucket).
 // Object data is never rewritten — only the sidecar changes, atomically
 // (temp+rename), and either sidecar state decrypts correctly.
 // Caller holds the per-key lock and passes the sidecar read under it.
@@ -280,25 +315,26 @@ func (om *objectManager) rewrapPathDEK(ctx context.Context, bucket, key, path st
 		return false, fmt.Errorf("failed to unwrap DEK with old KEK: %w", err)
 	}
 
-	kekKey, kekVersion := om.kekProvider.CurrentKEK()
-	wrapped, err := om.encryptor.Encrypt(dek, kekKey)
+	envelopeMeta, err := om.wrapDEK(bucket, dek)
 	if err != nil {
 		return false, fmt.Errorf("failed to re-wrap DEK: %w", err)
 	}
 
 	metaCopy := make(map[string]string, len(meta))
 	for k, v := range meta {
+		if !isEnvelopeMetadataKey(k) {
+			metaCopy[k] = v
+		}
+	}
+	for k, v := range envelopeMeta {
 		metaCopy[k] = v
 	}
-	metaCopy["wrapped-dek"] = hex.EncodeToString(wrapped.Data)
-	metaCopy["wrapped-dek-iv"] = hex.EncodeToString(wrapped.IV)
-	metaCopy["kek-version"] = strconv.Itoa(kekVersion)
 
 	if err := om.storage.SetMetadata(ctx, path, metaCopy); err != nil {
 		return false, fmt.Errorf("failed to update sidecar with re-wrapped DEK: %w", err)
 	}
 
-	// Verify: re-read the sidecar and unwrap with the current KEK — the DEK
+	// Verify: re-read the sidecar and unwrap with the current key — the DEK
 	// must be byte-identical (the data was never touched).
 	verifyMeta, err := om.storage.GetMetadata(ctx, path)
 	if err != nil {
@@ -312,8 +348,8 @@ func (om *objectManager) rewrapPathDEK(ctx context.Context, bucket, key, path st
 		return false, fmt.Errorf("re-wrap verification failed: DEK mismatch after sidecar update")
 	}
 
-	logrus.WithFields(logrus.Fields{"bucket": bucket, "key": key, "kek_version": kekVersion}).
-		Debug("Encryption migration: DEK re-wrapped to current KEK")
+	logrus.WithFields(logrus.Fields{"bucket": bucket, "key": key, "tenant_key_id": envelopeMeta["tenant-key-id"]}).
+		Debug("Encryption migration: DEK re-wrapped to current key")
 	return true, nil
 }
 
@@ -23,6 +23,7 @@ var (
 	ErrPartNotFound       = errors.New("part not found")
 	ErrInvalidPart        = errors.New("invalid part")
 	ErrInvalidPartOrder   = errors.New("invalid part order")
+	ErrInvalidPartNumber  = errors.New("invalid part number")
 	ErrTooManyParts       = errors.New("too many parts")
 	ErrPartTooSmall       = errors.New("part too small")
 	ErrEntityTooLarge     = errors.New("entity too large")
@@ -31,6 +32,14 @@ var (
 	ErrTooManyTags        = errors.New("too many tags")
 	ErrAccessDenied       = errors.New("access denied")
 	ErrBucketQuotaExceeded = errors.New("bucket storage quota exceeded")
+	ErrInvalidStorageClass = errors.New("invalid storage class")
+	ErrInvalidWriteOffset  = errors.New("write offset does not match the current object size")
+	ErrAppendNotSupported  = errors.New("append is not supported for this object")
+	ErrInvalidDigest       = errors.New("the Content-MD5 you specified is not valid")
+	ErrBadDigest           = errors.New("BadDigest: the Content-MD5 you specified did not match what was received")
+	ErrObjectDataMissing   = errors.New("the object's metadata exists but its data is missing from storage")
+	ErrReservedKeyPrefix   = errors.New("object key is in a reserved system namespace")
+	ErrTooManyMultipartUploads = errors.New("too many multipart uploads in progress")
 
 	// Object Lock errors (simple)
 	ErrObjectUnderLegalHold     = errors.New("object is under legal hold")
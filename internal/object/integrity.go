@@ -3,6 +3,7 @@ package object
 import (
 	"context"
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -30,6 +31,8 @@ type IntegrityResult struct {
 	ActualSize   int64           `json:"actualSize,omitempty"`
 	Reason       string          `json:"reason,omitempty"`
 	Error        string          `json:"error,omitempty"`
+	// DataMissingAt is when read repair flagged the object's data as missing
+	DataMissingAt *time.Time `json:"dataMissingAt,omitempty"`
 }
 
 // BucketIntegrityReport summarises an integrity check over a bucket (or a page of it)
@@ -94,6 +97,20 @@ func (om *objectManager) VerifyObjectIntegrity(ctx context.Context, bucket, key
 	_, reader, err := om.GetObject(ctx, bucket, key)
 	if err != nil {
 		// Distinguish a missing file from other errors
+		if errors.Is(err, ErrObjectDataMissing) {
+			result := &IntegrityResult{
+				Key:          key,
+				Status:       IntegrityMissing,
+				StoredETag:   storedETag,
+				ExpectedSize: meta.Size,
+				Reason:       "object data not found on storage",
+			}
+			// GetObject has flagged it unless read repair is off
+			if flagged, err := om.metadataStore.GetObject(ctx, bucket, key); err == nil {
+				result.DataMissingAt = flagged.DataMissingAt
+			}
+			return result, nil
+		}
 		errStr := err.Error()
 		if strings.Contains(errStr, "not found") ||
 			strings.Contains(errStr, "no such file") ||
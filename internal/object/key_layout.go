@@ -0,0 +1,222 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// keyLayoutKey is the raw metadata key recording the object key layout the
+// storage was written with (see storage.KeyPaths).
+const keyLayoutKey = "storage:key_layout"
+
+type keyLayoutRecord struct {
+	Layout string `json:"layout"`
+	// Secret is the hex HMAC key opaque object ids are derived with. Losing
+	// it makes every object file unreachable, so it is only ever created.
+	Secret string `json:"secret"`
+	// Migrated is set once the files of objects written with the path layout
+	// have been moved to their opaque paths.
+	Migrated bool `json:"migrated"`
+}
+
+// PrepareKeyLayout returns the key → path mapping for the configured
+// storage.object_key_layout, to be passed to NewManager with WithKeyPaths.
+// The first start with the opaque layout creates its secret and moves the
+// files of existing objects away from their key-named paths; an interrupted
+// move resumes on the next start. Going back from the opaque layout to the
+// path layout is not supported. Returns the number of files moved.
+func PrepareKeyLayout(ctx context.Context, backend storage.Backend, store metadata.Store, layout string) (*storage.KeyPaths, int, error) {
+	kv, ok := store.(metadata.RawKVStore)
+	if !ok {
+		if layout == storage.KeyLayoutOpaque {
+			return nil, 0, fmt.Errorf("the opaque object key layout requires a metadata store with raw key access")
+		}
+		return nil, 0, nil
+	}
+
+	var record keyLayoutRecord
+	data, err := kv.GetRaw(ctx, keyLayoutKey)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse object key layout record: %w", err)
+		}
+	case !errors.Is(err, metadata.ErrNotFound):
+		return nil, 0, fmt.Errorf("failed to read object key layout record: %w", err)
+	}
+
+	if layout != storage.KeyLayoutOpaque {
+		if record.Layout == storage.KeyLayoutOpaque {
+			return nil, 0, fmt.Errorf("objects are stored with the opaque key layout; changing storage.object_key_layout back to %q is not supported", layout)
+		}
+		return nil, 0, nil
+	}
+
+	if record.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, 0, fmt.Errorf("failed to generate object key layout secret: %w", err)
+		}
+		record = keyLayoutRecord{Layout: storage.KeyLayoutOpaque, Secret: hex.EncodeToString(secret)}
+		if err := saveKeyLayoutRecord(ctx, kv, &record); err != nil {
+			return nil, 0, err
+		}
+		// Files are about to be named with the secret: it must survive a crash
+		if syncer, ok := kv.(interface{ SyncWAL() error }); ok {
+			if err := syncer.SyncWAL(); err != nil {
+				return nil, 0, fmt.Errorf("failed to persist object key layout secret: %w", err)
+			}
+		}
+	}
+	secret, err := hex.DecodeString(record.Secret)
+	if err != nil || len(secret) == 0 {
+		return nil, 0, fmt.Errorf("invalid object key layout secret in the metadata store")
+	}
+	paths := storage.NewOpaqueKeyPaths(secret)
+	if record.Migrated {
+		return paths, 0, nil
+	}
+
+	moved, err := migrateToOpaqueKeyPaths(ctx, backend, store, paths)
+	if err != nil {
+		return nil, moved, err
+	}
+	record.Migrated = true
+	if err := saveKeyLayoutRecord(ctx, kv, &record); err != nil {
+		return nil, moved, err
+	}
+	return paths, moved, nil
+}
+
+func saveKeyLayoutRecord(ctx context.Context, kv metadata.RawKVStore, record *keyLayoutRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal object key layout record: %w", err)
+	}
+	if err := kv.PutRaw(ctx, keyLayoutKey, data); err != nil {
+		return fmt.Errorf("failed to save object key layout record: %w", err)
+	}
+	return nil
+}
+
+// migrateToOpaqueKeyPaths moves the file of every object and version listed
+// in the metadata store from its path-layout path to its opaque path. Files
+// already moved are skipped, so it can be re-run after an interruption.
+func migrateToOpaqueKeyPaths(ctx context.Context, backend storage.Backend, store metadata.Store, paths *storage.KeyPaths) (int, error) {
+	buckets, err := store.ListBuckets(ctx, "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list buckets: %w", err)
+	}
+	var plain *storage.KeyPaths
+	fsBackend, _ := backend.(*storage.FilesystemBackend)
+
+	moved := 0
+	for _, bkt := range buckets {
+		bucketPath := bkt.Name
+		if bkt.TenantID != "" {
+			bucketPath = bkt.TenantID + "/" + bkt.Name
+		}
+
+		marker := ""
+		for {
+			objects, next, err := store.ListObjects(ctx, bucketPath, "", marker, 1000)
+			if err != nil {
+				return moved, fmt.Errorf("failed to list objects of %s: %w", bucketPath, err)
+			}
+			for _, obj := range objects {
+				if err := ctx.Err(); err != nil {
+					return moved, err
+				}
+				moves := map[string]string{plain.ObjectPath(bucketPath, obj.Key): paths.ObjectPath(bucketPath, obj.Key)}
+				if versions, err := store.GetObjectVersions(ctx, bucketPath, obj.Key); err == nil {
+					for _, v := range versions {
+						if v.VersionID != "" && v.VersionID != "null" {
+							moves[plain.VersionPath(bucketPath, obj.Key, v.VersionID)] = paths.VersionPath(bucketPath, obj.Key, v.VersionID)
+						}
+					}
+				}
+				for src, dst := range moves {
+					ok, err := moveStoredFile(ctx, backend, fsBackend, src, dst)
+					if err != nil {
+						return moved, fmt.Errorf("failed to move %s/%s: %w", bucketPath, obj.Key, err)
+					}
+					if ok {
+						moved++
+					}
+				}
+			}
+			if next == "" {
+				break
+			}
+			marker = next
+		}
+
+		// Directories named after key prefixes are left behind empty
+		if fsBackend != nil {
+			if _, err := fsBackend.PruneEmptyDirectories(bucketPath); err != nil {
+				logrus.WithError(err).WithField("bucket", bucketPath).Warn("Failed to remove key directories after the key layout migration")
+			}
+		}
+	}
+	return moved, nil
+}
+
+// moveStoredFile moves one stored file from src to dst and reports whether
+// anything was moved. A filesystem file is renamed; other backends copy the
+// stored bytes and sidecar as they are (the encryption doesn't depend on the
+// path) and delete the original. Folder markers are rewritten as empty files.
+func moveStoredFile(ctx context.Context, backend storage.Backend, fsBackend *storage.FilesystemBackend, src, dst string) (bool, error) {
+	exists, err := backend.Exists(ctx, src)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	if strings.HasSuffix(src, "/") {
+		meta, err := backend.GetMetadata(ctx, src)
+		if err != nil {
+			return false, err
+		}
+		if err := backend.Put(ctx, dst, bytes.NewReader(nil), meta); err != nil {
+			return false, err
+		}
+		// A filesystem folder marker is a directory that may still hold
+		// other objects: PruneEmptyDirectories removes it once they moved.
+		if fsBackend == nil {
+			if err := backend.Delete(ctx, src); err != nil && err != storage.ErrObjectNotFound {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	if fsBackend != nil {
+		if err := fsBackend.MoveObject(ctx, src, dst); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	reader, meta, err := backend.Get(ctx, src)
+	if err != nil {
+		return false, err
+	}
+	err = backend.Put(ctx, dst, reader, meta)
+	reader.Close()
+	if err != nil {
+		return false, err
+	}
+	if err := backend.Delete(ctx, src); err != nil && err != storage.ErrObjectNotFound {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,187 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	secretKey    = "patients/jane.doe@example.com/scan.pdf"
+	secretFolder = "patients/john.roe@example.com/"
+)
+
+// keyLayoutEnv keeps object files and the metadata store in separate
+// directories, so the object root can be searched for leaked keys.
+type keyLayoutEnv struct {
+	root      string
+	backend   storage.Backend
+	metaStore metadata.Store
+}
+
+func newKeyLayoutEnv(t *testing.T) *keyLayoutEnv {
+	root := t.TempDir()
+	backend, err := storage.NewFilesystemBackend(storage.Config{Root: root})
+	require.NoError(t, err)
+	metaStore, err := metadata.NewPebbleStore(metadata.PebbleOptions{DataDir: t.TempDir(), Logger: logrus.StandardLogger()})
+	require.NoError(t, err)
+	t.Cleanup(func() { metaStore.Close() })
+
+	require.NoError(t, metaStore.CreateBucket(context.Background(), &metadata.BucketMetadata{Name: "plain", TenantID: "t1", OwnerID: "u1"}))
+	require.NoError(t, metaStore.CreateBucket(context.Background(), &metadata.BucketMetadata{
+		Name: "versioned", TenantID: "t1", OwnerID: "u1",
+		Versioning: &metadata.VersioningMetadata{Enabled: true, Status: "Enabled"},
+	}))
+	return &keyLayoutEnv{root: root, backend: backend, metaStore: metaStore}
+}
+
+func (env *keyLayoutEnv) manager(t *testing.T, layout string) *objectManager {
+	t.Helper()
+	paths, _, err := PrepareKeyLayout(context.Background(), env.backend, env.metaStore, layout)
+	require.NoError(t, err)
+	return NewManager(env.backend, env.metaStore, env.config(), WithKeyPaths(paths)).(*objectManager)
+}
+
+// config shares one KEK between the managers of a test, so objects written
+// before a migration can still be decrypted after it.
+func (env *keyLayoutEnv) config() config.StorageConfig {
+	return config.StorageConfig{Root: env.root, EncryptionKey: persistenceTestKEK}
+}
+
+// assertNoKeyOnDisk fails when any path or file content under the object
+// root contains a part of the secret keys.
+func (env *keyLayoutEnv) assertNoKeyOnDisk(t *testing.T) {
+	t.Helper()
+	needles := []string{"patients", "jane.doe", "john.roe", "scan.pdf"}
+	err := filepath.WalkDir(env.root, func(path string, d fs.DirEntry, err error) error {
+		require.NoError(t, err)
+		rel, _ := filepath.Rel(env.root, path)
+		for _, needle := range needles {
+			assert.NotContains(t, rel, needle, "key in a path")
+		}
+		if !d.IsDir() {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			for _, needle := range needles {
+				assert.NotContains(t, string(data), needle, "key in %s", rel)
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func readLayoutObject(t *testing.T, om *objectManager, bucket, key string, versionID ...string) string {
+	t.Helper()
+	_, reader, err := om.GetObject(context.Background(), bucket, key, versionID...)
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestOpaqueKeyLayout_ObjectsWorkWithoutKeysOnDisk(t *testing.T) {
+	ctx := context.Background()
+	env := newKeyLayoutEnv(t)
+	om := env.manager(t, storage.KeyLayoutOpaque)
+
+	_, err := om.PutObject(ctx, "t1/plain", secretKey, bytes.NewReader([]byte("v1")), http.Header{})
+	require.NoError(t, err)
+	_, err = om.PutObject(ctx, "t1/plain", secretKey, bytes.NewReader([]byte("v2")), http.Header{})
+	require.NoError(t, err)
+	_, err = om.PutObject(ctx, "t1/plain", secretFolder, bytes.NewReader(nil), http.Header{})
+	require.NoError(t, err)
+	first, err := om.PutObject(ctx, "t1/versioned", secretKey, bytes.NewReader([]byte("old")), http.Header{})
+	require.NoError(t, err)
+	_, err = om.PutObject(ctx, "t1/versioned", secretKey, bytes.NewReader([]byte("new")), http.Header{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "v2", readLayoutObject(t, om, "t1/plain", secretKey))
+	assert.Equal(t, "new", readLayoutObject(t, om, "t1/versioned", secretKey))
+	assert.Equal(t, "old", readLayoutObject(t, om, "t1/versioned", secretKey, first.VersionID))
+
+	result, err := om.ListObjects(ctx, "t1/plain", "patients/", "", "", 100)
+	require.NoError(t, err)
+	var keys []string
+	for _, obj := range result.Objects {
+		keys = append(keys, obj.Key)
+	}
+	assert.Contains(t, keys, secretKey)
+	assert.Contains(t, keys, secretFolder)
+
+	env.assertNoKeyOnDisk(t)
+
+	_, err = om.DeleteObject(ctx, "t1/plain", secretKey, false)
+	require.NoError(t, err)
+	_, _, err = om.GetObject(ctx, "t1/plain", secretKey)
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestOpaqueKeyLayout_MigratesPathLayout(t *testing.T) {
+	ctx := context.Background()
+	env := newKeyLayoutEnv(t)
+
+	om := env.manager(t, storage.KeyLayoutPath)
+	_, err := om.PutObject(ctx, "t1/plain", secretKey, bytes.NewReader([]byte("plain data")), http.Header{})
+	require.NoError(t, err)
+	_, err = om.PutObject(ctx, "t1/plain", secretFolder, bytes.NewReader(nil), http.Header{})
+	require.NoError(t, err)
+	first, err := om.PutObject(ctx, "t1/versioned", secretKey, bytes.NewReader([]byte("old")), http.Header{})
+	require.NoError(t, err)
+	_, err = om.PutObject(ctx, "t1/versioned", secretKey, bytes.NewReader([]byte("new")), http.Header{})
+	require.NoError(t, err)
+
+	// The path layout names files after their keys
+	_, err = os.Stat(filepath.Join(env.root, "t1", "plain", "patients"))
+	require.NoError(t, err)
+
+	paths, moved, err := PrepareKeyLayout(ctx, env.backend, env.metaStore, storage.KeyLayoutOpaque)
+	require.NoError(t, err)
+	assert.Equal(t, 6, moved, "the object, three folder markers and both versions")
+	require.True(t, paths.Opaque())
+
+	om = NewManager(env.backend, env.metaStore, env.config(), WithKeyPaths(paths)).(*objectManager)
+	assert.Equal(t, "plain data", readLayoutObject(t, om, "t1/plain", secretKey))
+	assert.Equal(t, "new", readLayoutObject(t, om, "t1/versioned", secretKey))
+	assert.Equal(t, "old", readLayoutObject(t, om, "t1/versioned", secretKey, first.VersionID))
+	env.assertNoKeyOnDisk(t)
+
+	// Later starts reuse the secret and don't migrate again
+	again, moved, err := PrepareKeyLayout(ctx, env.backend, env.metaStore, storage.KeyLayoutOpaque)
+	require.NoError(t, err)
+	assert.Zero(t, moved)
+	assert.Equal(t, paths.ObjectPath("t1/plain", secretKey), again.ObjectPath("t1/plain", secretKey))
+
+	// Going back would lose every object
+	_, _, err = PrepareKeyLayout(ctx, env.backend, env.metaStore, storage.KeyLayoutPath)
+	assert.Error(t, err)
+}
+
+func TestKeyPaths(t *testing.T) {
+	var plain *storage.KeyPaths
+	assert.False(t, plain.Opaque())
+	assert.Equal(t, "t1/b/a/b.txt", plain.ObjectPath("t1/b", "a/b.txt"))
+	assert.Equal(t, "t1/b/.versions/a/b.txt/v1", plain.VersionPath("t1/b", "a/b.txt", "v1"))
+
+	opaque := storage.NewOpaqueKeyPaths([]byte("secret"))
+	path := opaque.ObjectPath("t1/b", "a/b.txt")
+	assert.True(t, strings.HasPrefix(path, "t1/b/"+storage.OpaqueObjectDir+"/"))
+	assert.NotContains(t, path, "b.txt")
+	assert.Equal(t, path+".versions/v1", opaque.VersionPath("t1/b", "a/b.txt", "v1"))
+	assert.NotEqual(t, path, opaque.ObjectPath("t1/other", "a/b.txt"), "ids differ per bucket")
+	assert.NotEqual(t, path, storage.NewOpaqueKeyPaths([]byte("other")).ObjectPath("t1/b", "a/b.txt"), "ids depend on the secret")
+}
@@ -0,0 +1,132 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/audit"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLockAuditTest(t *testing.T) (*objectManager, *audit.Manager, *audit.SQLiteStore, context.Context) {
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	t.Cleanup(cleanup)
+
+	store, err := audit.NewSQLiteStore(filepath.Join(t.TempDir(), "audit.db"), logrus.StandardLogger())
+	require.NoError(t, err)
+	auditMgr := audit.NewManager(store, logrus.StandardLogger())
+	t.Cleanup(func() { auditMgr.Close() })
+	om.SetAuditManager(auditMgr)
+
+	ctx := context.Background()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "locks", TenantID: "tenant-1", OwnerID: "user-1"}))
+	ctx = context.WithValue(ctx, "user", &auth.User{ID: "user-1", Username: "auditor"})
+	return om, auditMgr, store, ctx
+}
+
+func objectLockHistory(t *testing.T, auditMgr *audit.Manager, resourceID string) []*audit.AuditLog {
+	auditMgr.Flush()
+	logs, _, err := auditMgr.GetLogs(context.Background(), &audit.AuditLogFilters{
+		ResourceType: audit.ResourceTypeObject,
+		ResourceID:   resourceID,
+		Page:         1,
+		PageSize:     50,
+	})
+	require.NoError(t, err)
+	// Oldest first; events logged within the same second tie on timestamp
+	sort.Slice(logs, func(i, j int) bool { return logs[i].ID < logs[j].ID })
+	return logs
+}
+
+func TestObjectLockChangesAreAudited(t *testing.T) {
+	om, auditMgr, _, ctx := setupLockAuditTest(t)
+
+	_, err := om.PutObject(ctx, "tenant-1/locks", "report.pdf", bytes.NewReader([]byte("pdf")), http.Header{})
+	require.NoError(t, err)
+
+	first := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	extended := first.Add(24 * time.Hour)
+	require.NoError(t, om.SetObjectRetention(ctx, "tenant-1/locks", "report.pdf", &RetentionConfig{Mode: RetentionModeGovernance, RetainUntilDate: first}))
+	require.NoError(t, om.SetObjectRetention(ctx, "tenant-1/locks", "report.pdf", &RetentionConfig{Mode: RetentionModeGovernance, RetainUntilDate: extended}))
+	assert.ErrorIs(t, om.SetObjectRetention(ctx, "tenant-1/locks", "report.pdf", &RetentionConfig{Mode: RetentionModeGovernance, RetainUntilDate: first}), ErrCannotShortenGovernance)
+	require.NoError(t, om.SetObjectLegalHold(ctx, "tenant-1/locks", "report.pdf", &LegalHoldConfig{Status: "ON"}))
+
+	logs := objectLockHistory(t, auditMgr, "locks/report.pdf")
+	require.Len(t, logs, 4)
+
+	set := logs[0]
+	assert.Equal(t, audit.EventTypeObjectRetentionSet, set.EventType)
+	assert.Equal(t, "tenant-1", set.TenantID)
+	assert.Equal(t, "user-1", set.UserID)
+	assert.Equal(t, "auditor", set.Username)
+	assert.Equal(t, audit.StatusSuccess, set.Status)
+	assert.Equal(t, first.Format(time.RFC3339), set.Details["retain_until"])
+	assert.Equal(t, RetentionModeGovernance, set.Details["mode"])
+	assert.NotContains(t, set.Details, "previous_retain_until")
+	assert.Contains(t, set.Details, "version_id")
+
+	extend := logs[1]
+	assert.Equal(t, audit.EventTypeObjectRetentionExtended, extend.EventType)
+	assert.Equal(t, first.Format(time.RFC3339), extend.Details["previous_retain_until"])
+	assert.Equal(t, extended.Format(time.RFC3339), extend.Details["retain_until"])
+
+	refused := logs[2]
+	assert.Equal(t, audit.EventTypeObjectRetentionSet, refused.EventType)
+	assert.Equal(t, audit.StatusFailed, refused.Status)
+	assert.Equal(t, extended.Format(time.RFC3339), refused.Details["previous_retain_until"])
+
+	hold := logs[3]
+	assert.Equal(t, audit.EventTypeObjectLegalHoldSet, hold.EventType)
+	assert.Equal(t, "OFF", hold.Details["previous_status"])
+	assert.Equal(t, "ON", hold.Details["status"])
+}
+
+func TestObjectLockHistoryOutlivesAuditRetention(t *testing.T) {
+	om, auditMgr, store, ctx := setupLockAuditTest(t)
+
+	for _, key := range []string{"locked.pdf", "expired.pdf"} {
+		_, err := om.PutObject(ctx, "tenant-1/locks", key, bytes.NewReader([]byte("pdf")), http.Header{})
+		require.NoError(t, err)
+	}
+	require.NoError(t, om.SetObjectRetention(ctx, "tenant-1/locks", "locked.pdf", &RetentionConfig{Mode: RetentionModeCompliance, RetainUntilDate: time.Now().Add(time.Hour)}))
+	require.NoError(t, om.SetObjectRetention(ctx, "tenant-1/locks", "expired.pdf", &RetentionConfig{Mode: RetentionModeGovernance, RetainUntilDate: time.Now().Add(-time.Hour)}))
+	require.NoError(t, auditMgr.LogEvent(ctx, &audit.AuditEvent{
+		UserID: "user-1", EventType: audit.EventTypeObjectUploaded, ResourceType: audit.ResourceTypeObject,
+		ResourceID: "locks/locked.pdf", Action: audit.ActionUpload, Status: audit.StatusSuccess,
+	}))
+	auditMgr.Flush()
+
+	// A negative age puts every record past the retention cutoff
+	_, err := store.PurgeLogs(context.Background(), -1)
+	require.NoError(t, err)
+
+	logs := objectLockHistory(t, auditMgr, "locks/locked.pdf")
+	require.Len(t, logs, 1, "only the lock history of a locked object survives")
+	assert.Equal(t, audit.EventTypeObjectRetentionSet, logs[0].EventType)
+	assert.Empty(t, objectLockHistory(t, auditMgr, "locks/expired.pdf"))
+
+	// A legal hold keeps the history even without retention
+	_, err = om.PutObject(ctx, "tenant-1/locks", "held.pdf", bytes.NewReader([]byte("pdf")), http.Header{})
+	require.NoError(t, err)
+	require.NoError(t, om.SetObjectLegalHold(ctx, "tenant-1/locks", "held.pdf", &LegalHoldConfig{Status: "ON"}))
+	auditMgr.Flush()
+	_, err = store.PurgeLogs(context.Background(), -1)
+	require.NoError(t, err)
+	assert.Len(t, objectLockHistory(t, auditMgr, "locks/held.pdf"), 1)
+
+	// Releasing the hold makes the whole history purgeable again
+	require.NoError(t, om.SetObjectLegalHold(ctx, "tenant-1/locks", "held.pdf", &LegalHoldConfig{Status: "OFF"}))
+	auditMgr.Flush()
+	_, err = store.PurgeLogs(context.Background(), -1)
+	require.NoError(t, err)
+	assert.Empty(t, objectLockHistory(t, auditMgr, "locks/held.pdf"))
+}
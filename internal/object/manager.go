@@ -1,16 +1,15 @@
 package object
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"crypto/rand"
-	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"hash"
-	"hash/crc32"
 	"io"
 	"net/http"
 	"os"
@@ -22,6 +21,7 @@ import (
 	"time"
 
 	"github.com/maxiofs/maxiofs/internal/acl"
+	"github.com/maxiofs/maxiofs/internal/audit"
 	"github.com/maxiofs/maxiofs/internal/config"
 	"github.com/maxiofs/maxiofs/internal/kek"
 	"github.com/maxiofs/maxiofs/internal/metadata"
@@ -35,12 +35,28 @@ type Manager interface {
 	// Basic object operations
 	GetObject(ctx context.Context, bucket, key string, versionID ...string) (*Object, io.ReadCloser, error)
 	PutObject(ctx context.Context, bucket, key string, data io.Reader, headers http.Header) (*Object, error)
+	// AppendObject adds data to the end of an object; writeOffset < 0 skips the size check
+	AppendObject(ctx context.Context, bucket, key string, data io.Reader, headers http.Header, writeOffset int64) (*Object, error)
+	// RenameObject moves the current object at srcKey to dstKey. In a versioned
+	// bucket dstKey gets a new version and srcKey a delete marker; each key
+	// keeps its history.
+	RenameObject(ctx context.Context, bucket, srcKey, dstKey string) (*Object, error)
 	DeleteObject(ctx context.Context, bucket, key string, bypassGovernance bool, versionID ...string) (deleteMarkerVersionID string, err error)
 	ListObjects(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*ListObjectsResult, error)
 	SearchObjects(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int, filter *metadata.ObjectFilter) (*ListObjectsResult, error)
+	// ListObjectsReverse lists like ListObjects in descending key order; the
+	// marker is an exclusive upper bound. Console-only, S3 listings are always
+	// ascending.
+	ListObjectsReverse(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*ListObjectsResult, error)
+	// ListETag returns an entity tag for the bucket's listings. It changes on
+	// every object or version write and delete in the bucket, and only then.
+	ListETag(ctx context.Context, bucket string) (string, error)
 
 	// Metadata operations
 	GetObjectMetadata(ctx context.Context, bucket, key string) (*Object, error)
+	// ObjectExists reports whether key has a current version that is not a
+	// delete marker. It is a single point lookup of the latest-version entry.
+	ObjectExists(ctx context.Context, bucket, key string) (bool, error)
 	UpdateObjectMetadata(ctx context.Context, bucket, key string, metadata map[string]string) error
 
 	// Object Lock operations
@@ -67,7 +83,7 @@ type Manager interface {
 
 	// Multipart upload operations
 	CreateMultipartUpload(ctx context.Context, bucket, key string, headers http.Header) (*MultipartUpload, error)
-	UploadPart(ctx context.Context, uploadID string, partNumber int, data io.Reader) (*Part, error)
+	UploadPart(ctx context.Context, uploadID string, partNumber int, data io.Reader, headers ...http.Header) (*Part, error)
 	ListParts(ctx context.Context, uploadID string) ([]Part, error)
 	CompleteMultipartUpload(ctx context.Context, uploadID string, parts []Part) (*Object, error)
 	AbortMultipartUpload(ctx context.Context, uploadID string) error
@@ -119,6 +135,12 @@ type Object struct {
 	RestoreStatus    string     `json:"restore_status,omitempty"`     // "ongoing" | "restored"
 	RestoreExpiresAt *time.Time `json:"restore_expires_at,omitempty"` // when the restored copy expires
 
+	// TTL (x-amz-expires-after-seconds)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Read repair: when a GET found the object's data missing from storage
+	DataMissingAt *time.Time `json:"data_missing_at,omitempty"`
+
 	// Encryption
 	SSEAlgorithm string `json:"sse_algorithm,omitempty"` // "AES256" when server-side encrypted
 }
@@ -140,8 +162,13 @@ type objectManager struct {
 	// encryptor performs the raw AES-256-GCM stream/block operations.
 	// Key selection is envelope-based: each object has its own DEK wrapped
 	// by a KEK obtained from kekProvider.
-	encryptor     encryption.Encryptor
-	kekProvider   kek.Provider
+	encryptor   encryption.Encryptor
+	kekProvider kek.Provider
+	// tenantKeys, when set, wraps the DEKs of objects in tenant buckets with
+	// the owning tenant's key instead of the KEK (global buckets keep using
+	// the KEK). Nil keeps every object on the KEK.
+	tenantKeys    kek.TenantKeyProvider
+	keyPaths      *storage.KeyPaths // key → storage path mapping; nil uses the keys as paths
 	bucketManager interface {
 		IncrementObjectCount(ctx context.Context, tenantID, name string, sizeBytes int64) error
 		DecrementObjectCount(ctx context.Context, tenantID, name string, sizeBytes int64) error
@@ -152,12 +179,20 @@ type objectManager struct {
 		DecrementTenantStorage(ctx context.Context, tenantID string, bytes int64) error
 		CheckTenantStorageQuota(ctx context.Context, tenantID string, additionalBytes int64) error
 	}
+	auditManager *audit.Manager // Records object lock changes; nil disables
+	// uploadScanner judges uploads to buckets with ScanUploads; nil when
+	// none is configured
+	uploadScanner UploadScanner
 
 	// RACE-02: 256-shard per-key write mutex. Each shard protects all keys that
 	// hash to that shard, serialising the read-existingObj / write-metadata /
 	// update-metrics sequence for concurrent writers to the same key.
 	muShards [256]sync.Mutex
 
+	// Serialises the count and the creation of new multipart uploads, so
+	// concurrent initiations can't overshoot the caps together
+	multipartLimitMu sync.Mutex
+
 	// Deduplication for concurrent CompleteMultipartUpload calls with the same uploadID
 	completionMu sync.Mutex
 	completions  map[string]*completionFuture
@@ -166,14 +201,20 @@ type objectManager struct {
 // lockKey locks the shard associated with bucket+key and returns the unlock function.
 // Use as: defer om.lockKey(bucket, key)()
 func (om *objectManager) lockKey(bucket, key string) func() {
+	h := keyShard(bucket, key)
+	om.muShards[h].Lock()
+	return om.muShards[h].Unlock
+}
+
+// keyShard returns the index of the muShards entry guarding bucket+key.
+func keyShard(bucket, key string) uint8 {
 	// FNV-1a hash for fast, uniform shard selection.
 	h := uint8(0)
 	for _, c := range bucket + "/" + key {
 		h ^= uint8(c)
 		h = (h << 3) | (h >> 5) // rotate
 	}
-	om.muShards[h].Lock()
-	return om.muShards[h].Unlock
+	return h
 }
 
 // Option configures the object manager at construction time.
@@ -185,6 +226,18 @@ func WithKEKProvider(p kek.Provider) Option {
 	return func(om *objectManager) { om.kekProvider = p }
 }
 
+// WithTenantKeyProvider supplies the per-tenant keys that wrap the DEKs of
+// objects in tenant buckets (normally the same kek.Store as the KEK).
+func WithTenantKeyProvider(p kek.TenantKeyProvider) Option {
+	return func(om *objectManager) { om.tenantKeys = p }
+}
+
+// WithKeyPaths sets how object keys map to storage paths (see
+// PrepareKeyLayout). Without it keys are used as paths.
+func WithKeyPaths(p *storage.KeyPaths) Option {
+	return func(om *objectManager) { om.keyPaths = p }
+}
+
 // NewManager creates a new object manager.
 //
 // Encryption is always on: every new object is envelope-encrypted with a
@@ -212,6 +265,9 @@ func NewManager(storage storage.Backend, metadataStore metadata.Store, config co
 	for _, opt := range opts {
 		opt(om)
 	}
+	if om.uploadScanner == nil {
+		om.uploadScanner = NewHTTPUploadScanner(config.UploadScan)
+	}
 
 	if om.kekProvider == nil {
 		if config.EncryptionKey != "" {
@@ -271,6 +327,11 @@ func (om *objectManager) SetAuthManager(am interface {
 	om.authManager = am
 }
 
+// SetAuditManager sets the audit manager object lock changes are recorded in
+func (om *objectManager) SetAuditManager(auditMgr *audit.Manager) {
+	om.auditManager = auditMgr
+}
+
 // parseBucketPath extracts tenantID and bucketName from a bucket path
 // Formats: "tenantID/bucketName" or "bucketName" (for global buckets)
 func (om *objectManager) parseBucketPath(bucketPath string) (tenantID, bucketName string) {
@@ -326,6 +387,16 @@ func isBypassQuotaEnforcement(ctx context.Context) bool {
 	return v
 }
 
+// checkFreeSpace fails with storage.ErrInsufficientStorage when the storage
+// backend's disk is below its reserved headroom, before an upload body is
+// spooled to disk.
+func (om *objectManager) checkFreeSpace() error {
+	if sr, ok := om.storage.(storage.SpaceReporter); ok {
+		return sr.CheckFreeSpace()
+	}
+	return nil
+}
+
 // WithReplicatedVersionID pins the next versioned write/delete marker to an
 // existing version ID received through trusted internal replication paths.
 func WithReplicatedVersionID(ctx context.Context, versionID string) context.Context {
@@ -350,6 +421,22 @@ func validateReplicatedVersionID(versionID string) error {
 	return nil
 }
 
+type deleteIfMatchKey struct{}
+
+// WithDeleteIfMatch makes the next DeleteObject conditional on the target's
+// current ETag (S3 If-Match on DELETE). "*" matches any existing object.
+func WithDeleteIfMatch(ctx context.Context, etag string) context.Context {
+	return context.WithValue(ctx, deleteIfMatchKey{}, etag)
+}
+
+func deleteIfMatchFromContext(ctx context.Context) (string, bool) {
+	etag, ok := ctx.Value(deleteIfMatchKey{}).(string)
+	if !ok || etag == "" {
+		return "", false
+	}
+	return etag, true
+}
+
 type replicatedLastModifiedKey struct{}
 
 // WithReplicatedLastModified pins the next write's LastModified to the
@@ -380,7 +467,20 @@ func (om *objectManager) GetObject(ctx context.Context, bucket, key string, vers
 	var requestedVersionID string
 	var err error
 
-	if len(versionID) > 0 && versionID[0] != "" {
+	if len(versionID) > 0 && versionID[0] == NullVersionID {
+		// The null version is the unversioned object, served only while it
+		// is still the latest one.
+		metaObj, err = om.metadataStore.GetObject(ctx, bucket, key)
+		if err != nil {
+			if err == metadata.ErrObjectNotFound {
+				return nil, nil, ErrObjectNotFound
+			}
+			return nil, nil, fmt.Errorf("failed to get object metadata: %w", err)
+		}
+		if metaObj.VersionID != "" {
+			return nil, nil, ErrObjectNotFound
+		}
+	} else if len(versionID) > 0 && versionID[0] != "" {
 		requestedVersionID = versionID[0]
 		// Get specific version metadata
 		metaObj, err = om.metadataStore.GetObject(ctx, bucket, key, requestedVersionID)
@@ -400,6 +500,25 @@ func (om *objectManager) GetObject(ctx context.Context, bucket, key string, vers
 		if metaObj != nil && metaObj.VersionID != "" {
 			requestedVersionID = metaObj.VersionID
 		}
+		// Without metadata the file alone is served (objects predating the
+		// metadata store). A delete removes the metadata first and holds the
+		// key lock until the file is gone too, so waiting for the lock here
+		// keeps a GET racing an acknowledged DELETE from serving the file.
+		if metaObj == nil && !keyLockHeld(ctx) {
+			om.lockKey(bucket, key)()
+		}
+	}
+
+	// A delete marker has no data: the latest one hides the key, and an
+	// explicitly requested one is not an object either. Answer before any
+	// storage read so an older file can never be served in its place.
+	if metaObj != nil && isMetadataDeleteMarker(metaObj) {
+		return nil, nil, ErrObjectNotFound
+	}
+	// An object past its TTL is gone for readers even before the lifecycle
+	// worker deletes it.
+	if metaObj != nil && isMetadataExpired(metaObj, time.Now()) {
+		return nil, nil, ErrObjectNotFound
 	}
 
 	// Determine the correct object path
@@ -416,6 +535,10 @@ func (om *objectManager) GetObject(ctx context.Context, bucket, key string, vers
 	encryptedReader, storageMetadata, err := om.storage.Get(ctx, objectPath)
 	if err != nil {
 		if err == storage.ErrObjectNotFound {
+			// Folder markers have no data of their own
+			if metaObj != nil && !strings.HasSuffix(key, "/") {
+				return nil, nil, om.objectDataMissing(ctx, bucket, key, metaObj, objectPath)
+			}
 			return nil, nil, ErrObjectNotFound
 		}
 		return nil, nil, fmt.Errorf("failed to get object: %w", err)
@@ -424,45 +547,15 @@ func (om *objectManager) GetObject(ctx context.Context, bucket, key string, vers
 	var object *Object
 	if metaObj != nil {
 		object = fromMetadataObject(metaObj)
-
-		// Check if this is a delete marker (Size==0, ETag=="")
-		// Delete markers should return 404
-		if object.Size == 0 && object.ETag == "" && requestedVersionID == "" {
-			// Latest version is a delete marker
-			encryptedReader.Close()
-			return nil, nil, ErrObjectNotFound
+		if metaObj.DataMissingAt != nil {
+			om.clearDataMissing(ctx, bucket, key, metaObj)
+			object.DataMissingAt = nil
 		}
 	} else {
 		// If metadata doesn't exist in the metadata store, use storage metadata.
-		// Check if file is encrypted
-		var size int64
-		var etag string
-
-		if storageMetadata["encrypted"] == "true" {
-			// Use original metadata (before encryption)
-			size, _ = strconv.ParseInt(storageMetadata["original-size"], 10, 64)
-			etag = storageMetadata["original-etag"]
-		} else {
-			// Unencrypted file (legacy or multipart)
-			size, _ = strconv.ParseInt(storageMetadata["size"], 10, 64)
-			etag = storageMetadata["etag"]
-		}
-
-		lastModified, _ := strconv.ParseInt(storageMetadata["last_modified"], 10, 64)
-
-		object = &Object{
-			Key:                key,
-			Bucket:             bucket,
-			Size:               size,
-			LastModified:       time.Unix(lastModified, 0),
-			ETag:               etag,
-			ContentType:        storageMetadata["content-type"],
-			ContentDisposition: storageMetadata["content-disposition"],
-			ContentEncoding:    storageMetadata["content-encoding"],
-			CacheControl:       storageMetadata["cache-control"],
-			ContentLanguage:    storageMetadata["content-language"],
-			Metadata:           nil, // User metadata not available in sidecar path
-			StorageClass:       StorageClassStandard,
+		object = objectFromStorageMetadata(bucket, key, storageMetadata)
+		if om.readRepairMode() == ReadRepairRebuild {
+			om.rebuildObjectMetadata(ctx, bucket, key, object, objectPath)
 		}
 	}
 
@@ -535,19 +628,106 @@ func (om *objectManager) GetObject(ctx context.Context, bucket, key string, vers
 	}
 }
 
+// objectFromStorageMetadata builds the object info of a file that has no
+// metadata store entry from its storage sidecar.
+func objectFromStorageMetadata(bucket, key string, storageMetadata map[string]string) *Object {
+	var size int64
+	var etag string
+
+	if storageMetadata["encrypted"] == "true" {
+		// Use original metadata (before encryption)
+		size, _ = strconv.ParseInt(storageMetadata["original-size"], 10, 64)
+		etag = storageMetadata["original-etag"]
+	} else {
+		// Unencrypted file (legacy or multipart)
+		size, _ = strconv.ParseInt(storageMetadata["size"], 10, 64)
+		etag = storageMetadata["etag"]
+	}
+
+	lastModified, _ := strconv.ParseInt(storageMetadata["last_modified"], 10, 64)
+
+	object := &Object{
+		Key:                key,
+		Bucket:             bucket,
+		Size:               size,
+		LastModified:       time.Unix(lastModified, 0),
+		ETag:               etag,
+		ContentType:        storageMetadata["content-type"],
+		ContentDisposition: storageMetadata["content-disposition"],
+		ContentEncoding:    storageMetadata["content-encoding"],
+		CacheControl:       storageMetadata["cache-control"],
+		ContentLanguage:    storageMetadata["content-language"],
+		Metadata:           nil, // User metadata not available in sidecar path
+		StorageClass:       StorageClassStandard,
+	}
+	if storageMetadata["encrypted"] == "true" {
+		object.SSEAlgorithm = "AES256"
+	}
+	return object
+}
+
 // PutObject stores an object
 func (om *objectManager) PutObject(ctx context.Context, bucket, key string, data io.Reader, headers http.Header) (*Object, error) {
 	if err := om.validateObjectName(key); err != nil {
 		return nil, err
 	}
+	if err := om.checkReservedKey(ctx, key); err != nil {
+		return nil, err
+	}
+	if err := ValidateStorageClass(headers.Get("x-amz-storage-class")); err != nil {
+		return nil, err
+	}
+	contentMD5, err := ParseContentMD5(headers.Get("Content-MD5"))
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := ParseExpiresAfter(headers.Get(ExpiresAfterHeader), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := om.checkFreeSpace(); err != nil {
+		return nil, err
+	}
 
 	// Extract metadata from headers using helper function
 	storageMetadata, userMetadata := om.extractMetadataFromHeaders(headers)
 
+	// Uploads without a Content-Type would otherwise be served as
+	// application/octet-stream; an explicit client value is always kept.
+	// The bucket's default covers what sniffing can't tell.
+	if headers.Get("Content-Type") == "" && !strings.HasSuffix(key, "/") {
+		contentType := ""
+		if !om.config.DisableContentTypeSniffing {
+			sniffed, sniffedType, err := sniffContentType(data, key)
+			if err != nil {
+				return nil, err
+			}
+			data = sniffed
+			contentType = sniffedType
+		}
+		if contentType == "" || contentType == "application/octet-stream" {
+			if defaultType := om.bucketDefaultContentType(ctx, bucket); defaultType != "" {
+				contentType = defaultType
+			}
+		}
+		if contentType != "" {
+			storageMetadata["content-type"] = contentType
+		}
+	}
+
 	// Check if versioning is enabled for this bucket
 	tenantID, bucketName := om.parseBucketPath(bucket)
 	versioningEnabled := om.isBucketVersioningEnabled(ctx, bucket)
 
+	// Write-once bucket: fail fast before streaming the body. The check is
+	// repeated under the key lock below, right before the data is stored.
+	noOverwrite := om.isBucketNoOverwrite(ctx, bucket)
+	if noOverwrite {
+		if err := om.checkNoOverwrite(ctx, bucket, key); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate versionID if versioning is enabled
 	var versionID string
 	var objectPath string
@@ -565,48 +745,32 @@ func (om *objectManager) PutObject(ctx context.Context, bucket, key string, data
 		objectPath = om.getObjectPath(bucket, key)
 	}
 
-	// Step 1: Stream data to temporary file while calculating hash and size
-	// This avoids loading entire file into memory.
-	// Use Root as temp location to guarantee same filesystem as the object store,
-	// preventing cross-device rename failures when /tmp is on a separate mount (BUG-05).
-	tempFile, err := os.CreateTemp(om.config.Root, "maxiofs-upload-*")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
-	}
-	tempPath := tempFile.Name()
-	defer os.Remove(tempPath) // Clean up temp file when done
-	defer tempFile.Close()    // Ensure handle is closed on panic
-
+	// Step 1: Spool the body while calculating hash and size. Small bodies
+	// stay in memory; anything over storage.upload_spill_threshold goes to a
+	// temp file (in the storage root unless storage.upload_temp_dir is set),
+	// so memory stays bounded regardless of object size.
 	// Extract checksum algorithm requested by client (AWS SDK v3 sends x-amz-checksum-algorithm)
 	checksumAlgo := strings.ToUpper(headers.Get("x-amz-checksum-algorithm"))
-	var checksumHasher hash.Hash
-	switch checksumAlgo {
-	case "CRC32":
-		checksumHasher = crc32.NewIEEE()
-	case "CRC32C":
-		checksumHasher = crc32.New(crc32.MakeTable(crc32.Castagnoli))
-	case "SHA1":
-		checksumHasher = sha1.New()
-	case "SHA256":
-		checksumHasher = sha256.New()
-	}
-
-	// Write to temp file while calculating MD5 hash (and optional additional checksum)
+	checksumHasher := newChecksumHasher(checksumAlgo)
+
 	hasher := md5.New()
-	var multiWriter io.Writer
+	var hashWriter io.Writer = hasher
 	if checksumHasher != nil {
-		multiWriter = io.MultiWriter(tempFile, hasher, checksumHasher)
-	} else {
-		multiWriter = io.MultiWriter(tempFile, hasher)
+		hashWriter = io.MultiWriter(hasher, checksumHasher)
 	}
-	originalSize, err := io.Copy(multiWriter, data)
+	spool, originalSize, err := om.spoolUpload(io.TeeReader(data, hashWriter))
 	if err != nil {
-		return nil, fmt.Errorf("failed to write to temp file: %w", err)
+		return nil, err
 	}
-	tempFile.Close()
+	defer spool.Close() // Removes the temp file on every return path
 
-	// Calculate original ETag (MD5 hash)
-	originalETag := hex.EncodeToString(hasher.Sum(nil))
+	// Calculate original ETag (MD5 hash). A Content-MD5 mismatch rejects the
+	// upload before anything but the temp file was written.
+	md5Sum := hasher.Sum(nil)
+	if contentMD5 != nil && !bytes.Equal(md5Sum, contentMD5) {
+		return nil, ErrBadDigest
+	}
+	originalETag := hex.EncodeToString(md5Sum)
 
 	// Compute additional checksum if requested
 	var checksumValue string
@@ -666,18 +830,59 @@ func (om *objectManager) PutObject(ctx context.Context, bucket, key string, data
 		}
 	}
 
+	// A non-versioned overwrite destroys the current object, so it is refused
+	// while that object is under retention, exactly like a delete would be.
+	if !versioningEnabled {
+		if err := om.checkOverwriteRetention(ctx, bucket, key); err != nil {
+			return nil, err
+		}
+	}
+
+	// The scanner sees the spooled copy, so a rejected upload never reaches
+	// the object path (or replaces the object there) and is discarded with
+	// the spool.
+	if err := om.scanUpload(ctx, bucket, key, storageMetadata["content-type"], originalSize, func() (io.ReadCloser, error) {
+		body, err := spool.Reader()
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(body), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// In a write-once bucket two concurrent PUTs of a new key must not both
+	// win, so the key lock is taken before the data is stored and the
+	// existence check is repeated under it.
+	keyLocked := keyLockHeld(ctx)
+	if noOverwrite {
+		if !keyLocked {
+			defer om.lockKey(bucket, key)()
+			keyLocked = true
+		}
+		if err := om.checkNoOverwrite(ctx, bucket, key); err != nil {
+			return nil, err
+		}
+	}
+
+	body, err := spool.Reader()
+	if err != nil {
+		return nil, err
+	}
+
 	// Store object data. Encryption is always on: every object is envelope-
-	// encrypted with its own DEK wrapped by the current KEK. Folder markers
+	// encrypted with its own DEK, wrapped by the owning tenant's key (or by
+	// the current KEK in a global bucket). Folder markers
 	// (keys ending in "/") carry no data — the filesystem backend never reads
 	// the data stream for them, so encrypting would leave the encryption pipe
 	// blocked forever; they are stored as plain directory markers instead.
 	isFolderMarker := strings.HasSuffix(key, "/")
 	if isFolderMarker {
-		if err := om.storeUnencryptedObject(ctx, objectPath, tempPath, storageMetadata, originalSize, originalETag); err != nil {
+		if err := om.storeUnencryptedObject(ctx, objectPath, body, storageMetadata, originalSize, originalETag); err != nil {
 			return nil, err
 		}
 	} else {
-		if err := om.storeEncryptedObject(ctx, objectPath, tempPath, storageMetadata, originalSize, originalETag); err != nil {
+		if err := om.storeEncryptedObject(ctx, bucket, objectPath, body, storageMetadata, originalSize, originalETag); err != nil {
 			return nil, err
 		}
 	}
@@ -714,6 +919,7 @@ func (om *objectManager) PutObject(ctx context.Context, bucket, key string, data
 		VersionID:          versionID, // Set versionID (empty string if versioning disabled)
 		ChecksumAlgorithm:  checksumAlgo,
 		ChecksumValue:      checksumValue,
+		ExpiresAt:          expiresAt,
 	}
 	if !isFolderMarker {
 		object.SSEAlgorithm = "AES256"
@@ -723,12 +929,15 @@ func (om *objectManager) PutObject(ctx context.Context, bucket, key string, data
 	if err := om.applyDefaultRetention(ctx, object); err != nil {
 		logrus.WithError(err).Debug("Failed to apply default retention")
 	}
+	om.applyDefaultWriteLock(ctx, object)
 
 	// RACE-02: hold the per-key shard lock for the entire read-existing /
 	// write-metadata / update-metrics sequence. Two concurrent writers to the
 	// same key would otherwise both read the same existingObjBeforeSave, then
 	// both apply the same size delta, permanently corrupting bucket metrics.
-	defer om.lockKey(bucket, key)()
+	if !keyLocked {
+		defer om.lockKey(bucket, key)()
+	}
 
 	// CRITICAL: Get existing object BEFORE overwriting in metadata store
 	// This is needed for correct size calculations in metrics and quotas
@@ -780,6 +989,11 @@ func (om *objectManager) PutObject(ctx context.Context, bucket, key string, data
 	// Update tenant storage quota using helper function
 	om.updateTenantQuotaAfterPut(ctx, tenantID, key, size, versioningEnabled, existingObjBeforeSave)
 
+	// Still under the key lock, so the cap holds against concurrent writers
+	if versioningEnabled {
+		om.trimExcessVersions(ctx, bucket, key)
+	}
+
 	return object, nil
 }
 
@@ -801,6 +1015,16 @@ func (om *objectManager) DeleteObject(ctx context.Context, bucket, key string, b
 		specificVersionID = versionID[0]
 	}
 
+	// Conditional delete: hold the per-key shard lock across the ETag check and
+	// the delete so a concurrent PutObject cannot slip a new version in between.
+	if ifMatch, ok := deleteIfMatchFromContext(ctx); ok {
+		defer om.lockKey(bucket, key)()
+		ctx = withKeyLockHeld(ctx)
+		if err := om.checkDeleteIfMatch(ctx, bucket, key, specificVersionID, ifMatch); err != nil {
+			return "", err
+		}
+	}
+
 	if specificVersionID != "" {
 		// DELETE with versionId → Permanent deletion of specific version
 		return "", om.deleteSpecificVersion(ctx, bucket, key, specificVersionID, bypassGovernance)
@@ -813,6 +1037,35 @@ func (om *objectManager) DeleteObject(ctx context.Context, bucket, key string, b
 	}
 }
 
+// checkDeleteIfMatch compares ifMatch against the ETag of the version a
+// DELETE would act on: the given version, or the latest one when versionID is
+// empty. A missing object or a delete marker has no current representation,
+// so the condition fails (RFC 7232 §3.1).
+func (om *objectManager) checkDeleteIfMatch(ctx context.Context, bucket, key, versionID, ifMatch string) error {
+	var (
+		current *metadata.ObjectMetadata
+		err     error
+	)
+	if versionID != "" {
+		current, err = om.metadataStore.GetObject(ctx, bucket, key, versionID)
+	} else {
+		current, err = om.metadataStore.GetObject(ctx, bucket, key)
+	}
+	if err != nil {
+		if err == metadata.ErrObjectNotFound {
+			return ErrPreconditionFailed
+		}
+		return fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	if isMetadataDeleteMarker(current) {
+		return ErrPreconditionFailed
+	}
+	if ifMatch != "*" && strings.Trim(current.ETag, "\"") != strings.Trim(ifMatch, "\"") {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
 func (om *objectManager) resolveFolderDeleteKey(ctx context.Context, bucket, key string) string {
 	if strings.HasSuffix(key, "/") {
 		return key
@@ -843,6 +1096,10 @@ func (om *objectManager) createDeleteMarker(ctx context.Context, bucket, key str
 		}
 		deleteMarkerVersionID = replicatedVersionID
 	}
+	lastModified := time.Now()
+	if replicatedLM, ok := replicatedLastModifiedFromContext(ctx); ok {
+		lastModified = replicatedLM
+	}
 
 	// Create delete marker version entry
 	deleteMarker := &metadata.ObjectVersion{
@@ -851,7 +1108,7 @@ func (om *objectManager) createDeleteMarker(ctx context.Context, bucket, key str
 		Key:          key,
 		Size:         0,
 		ETag:         "",
-		LastModified: time.Now(),
+		LastModified: lastModified,
 		StorageClass: StorageClassStandard,
 	}
 
@@ -861,7 +1118,7 @@ func (om *objectManager) createDeleteMarker(ctx context.Context, bucket, key str
 		Key:          key,
 		VersionID:    deleteMarkerVersionID,
 		Size:         0,
-		LastModified: time.Now(),
+		LastModified: lastModified,
 		ETag:         "",
 		ContentType:  "",
 		StorageClass: StorageClassStandard,
@@ -954,7 +1211,10 @@ func (om *objectManager) deleteSpecificVersion(ctx context.Context, bucket, key,
 		}
 	}
 
-	// Delete version metadata from the metadata store.
+	// Delete version metadata from the metadata store. When it is the latest
+	// version the store moves the current-version pointer in the same
+	// transaction, so a GET without a version ID never resolves to it again
+	// — even before the file below is removed.
 	if err := om.metadataStore.DeleteObjectVersion(ctx, bucket, key, versionID); err != nil {
 		return fmt.Errorf("failed to delete version metadata: %w", err)
 	}
@@ -970,64 +1230,6 @@ func (om *objectManager) deleteSpecificVersion(ctx context.Context, bucket, key,
 		}
 	}
 
-	// If we deleted the latest version, handle next version or delete object entry
-	if deletingLatest {
-		// RACE-03: re-query versions AFTER the delete to pick up any concurrent
-		// PutObject that inserted a new version between our initial snapshot and now.
-		// Using the stale `allVersions` snapshot could mark an older version as
-		// "latest", hiding the concurrently-written newer version from subsequent GETs.
-		freshVersions, freshErr := om.metadataStore.GetObjectVersions(ctx, bucket, key)
-		if freshErr != nil {
-			logrus.WithError(freshErr).Warn("RACE-03: failed to refresh versions after delete; falling back to stale snapshot")
-			freshVersions = allVersions
-		}
-
-		if len(freshVersions) > 0 {
-			// Find the next most recent version (the target was already deleted from the store)
-			var nextLatest *metadata.ObjectVersion
-			for _, ver := range freshVersions {
-				if ver.VersionID != versionID {
-					if nextLatest == nil || ver.LastModified.After(nextLatest.LastModified) {
-						nextLatest = ver
-					}
-				}
-			}
-
-			if nextLatest != nil {
-				// Mark as latest
-				nextLatest.IsLatest = true
-
-				// Get full object metadata and update
-				nextMetaObj, err := om.metadataStore.GetObject(ctx, bucket, key, nextLatest.VersionID)
-				if err != nil {
-					logrus.WithError(err).Warn("Failed to get object metadata for next latest")
-				} else {
-					// Ensure bucket and key are set correctly (they might be empty from version metadata)
-					if nextMetaObj.Bucket == "" {
-						nextMetaObj.Bucket = bucket
-					}
-					if nextMetaObj.Key == "" {
-						nextMetaObj.Key = key
-					}
-
-					err = om.metadataStore.PutObjectVersion(ctx, nextMetaObj, nextLatest)
-					if err != nil {
-						logrus.WithError(err).Warn("Failed to mark next version as latest")
-					}
-				}
-			}
-		} else {
-			// No remaining versions after the delete — remove the main object entry.
-			if err := om.metadataStore.DeleteObject(ctx, bucket, key); err != nil {
-				logrus.WithError(err).Warn("Failed to delete main object entry")
-			}
-			logrus.WithFields(logrus.Fields{
-				"bucket": bucket,
-				"key":    key,
-			}).Info("Deleted main object entry - no versions remaining")
-		}
-	}
-
 	// Adjust object count based on what we deleted and the new latest version.
 	// ObjectCount tracks only visible (non-delete-marker) objects, mirroring S3:
 	//   • Deleted a delete marker (latest) + real object resurfaces  → IncrementObjectCount
@@ -1094,6 +1296,13 @@ func (om *objectManager) deleteSpecificVersion(ctx context.Context, bucket, key,
 func (om *objectManager) deletePermanently(ctx context.Context, bucket, key string, bypassGovernance bool) error {
 	objectPath := om.getObjectPath(bucket, key)
 
+	// Held until the file is gone: GetObject serves a file without metadata
+	// only after taking this lock, so it never sees the window between the
+	// two steps below.
+	if !keyLockHeld(ctx) {
+		defer om.lockKey(bucket, key)()
+	}
+
 	// Get metadata
 	metaObj, err := om.metadataStore.GetObject(ctx, bucket, key)
 	var objectSize int64
@@ -1289,6 +1498,80 @@ func (om *objectManager) ListObjects(ctx context.Context, bucket, prefix, delimi
 	return result, nil
 }
 
+// ListETag returns an entity tag for the bucket's listings, derived from the
+// bucket's modification sequence. Read it before listing: a write landing
+// during the listing then changes the tag the next request sees.
+func (om *objectManager) ListETag(ctx context.Context, bucket string) (string, error) {
+	seq, err := om.metadataStore.GetBucketSequence(ctx, bucket)
+	if err != nil {
+		return "", err
+	}
+	sum := md5.Sum([]byte(bucket + ":" + strconv.FormatUint(seq, 10)))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ListObjectsReverse lists objects in descending key order
+func (om *objectManager) ListObjectsReverse(ctx context.Context, bucket, prefix, delimiter, marker string, maxKeys int) (*ListObjectsResult, error) {
+	if maxKeys <= 0 {
+		maxKeys = 1000 // Default max keys
+	}
+
+	tenantID, bucketName := om.parseBucketPath(bucket)
+	exists, err := om.metadataStore.BucketExists(ctx, tenantID, bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket existence: %w", err)
+	}
+	if !exists {
+		return nil, ErrBucketNotFound
+	}
+
+	dlResult, err := om.metadataStore.ListObjectsReverse(ctx, bucket, prefix, delimiter, marker, maxKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects in reverse: %w", err)
+	}
+
+	var objects []Object
+	for _, metaObj := range dlResult.Objects {
+		key := metaObj.Key
+
+		// Skip internal MaxIOFS files
+		if strings.HasPrefix(key, ".maxiofs-") || strings.Contains(key, "/.maxiofs-") {
+			continue
+		}
+
+		// Implicit folder markers: a flat listing skips them all, a delimited
+		// one only the marker of the listed folder itself (as ListObjects)
+		if metaObj.Metadata != nil && metaObj.Metadata["x-maxiofs-implicit-folder"] == "true" {
+			if delimiter == "" || key == prefix {
+				continue
+			}
+		}
+
+		// Skip Delete Markers
+		if metaObj.Size == 0 && metaObj.ETag == "" {
+			continue
+		}
+
+		objects = append(objects, *fromMetadataObject(metaObj))
+	}
+
+	var commonPrefixes []CommonPrefix
+	for _, cp := range dlResult.CommonPrefixes {
+		commonPrefixes = append(commonPrefixes, CommonPrefix{Prefix: cp})
+	}
+
+	return &ListObjectsResult{
+		Objects:        objects,
+		CommonPrefixes: commonPrefixes,
+		IsTruncated:    dlResult.IsTruncated,
+		NextMarker:     dlResult.NextMarker,
+		MaxKeys:        maxKeys,
+		Prefix:         prefix,
+		Delimiter:      delimiter,
+		Marker:         marker,
+	}, nil
+}
+
 // listObjectsDelimited handles hierarchical listing with delimiter. It delegates
 // to the store's ListObjectsDelimited which uses SeekGE to skip entire common
 // prefixes, making it O(results) instead of O(total objects).
@@ -1499,62 +1782,102 @@ func (om *objectManager) SearchObjects(ctx context.Context, bucket, prefix, deli
 	return result, nil
 }
 
+// currentObjectMetadata looks up the latest-version entry for key with a
+// single metadata point lookup. A missing entry, a delete marker or an object
+// past its TTL returns ErrObjectNotFound without touching storage or
+// enumerating versions, which keeps conditional-write probes cheap. Files
+// without a metadata entry are not seen; GetObjectMetadata covers those.
+func (om *objectManager) currentObjectMetadata(ctx context.Context, bucket, key string) (*metadata.ObjectMetadata, error) {
+	metaObj, err := om.metadataStore.GetObject(ctx, bucket, key)
+	if err == metadata.ErrObjectNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	if isMetadataDeleteMarker(metaObj) || isMetadataExpired(metaObj, time.Now()) {
+		return nil, ErrObjectNotFound
+	}
+	return metaObj, nil
+}
+
+// ObjectExists reports whether key has a current, non-delete-marker version
+func (om *objectManager) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	if err := om.validateObjectName(key); err != nil {
+		return false, err
+	}
+
+	if _, err := om.currentObjectMetadata(ctx, bucket, key); err != nil {
+		if err == ErrObjectNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // GetObjectMetadata retrieves object metadata
 func (om *objectManager) GetObjectMetadata(ctx context.Context, bucket, key string) (*Object, error) {
 	if err := om.validateObjectName(key); err != nil {
 		return nil, err
 	}
 
-	objectPath := om.getObjectPath(bucket, key)
-
-	// Try to load metadata from store first — covers versioned objects where
-	// the physical file is at the versioned path, not the plain path.
-	metaObj, metaErr := om.metadataStore.GetObject(ctx, bucket, key)
-	if metaErr == nil && metaObj != nil {
-		// Verify the physical file exists at the correct path (versioned or plain)
-		checkPath := objectPath
-		if metaObj.VersionID != "" {
-			checkPath = om.getVersionedObjectPath(bucket, key, metaObj.VersionID)
-		}
-		exists, err := om.storage.Exists(ctx, checkPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check object existence: %w", err)
-		}
-		if !exists {
-			return nil, ErrObjectNotFound
-		}
-		return fromMetadataObject(metaObj), nil
+	metaObj, err := om.metadataStore.GetObject(ctx, bucket, key)
+	if err == metadata.ErrObjectNotFound {
+		// Like GetObject, serve a file that has no metadata entry (objects
+		// predating the metadata store) from its sidecar, so HEAD and GET
+		// agree on whether it exists.
+		return om.storageObjectMetadata(ctx, bucket, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	if isMetadataDeleteMarker(metaObj) || isMetadataExpired(metaObj, time.Now()) {
+		return nil, ErrObjectNotFound
 	}
 
-	// Check if non-versioned file exists in storage (legacy / non-versioned objects)
-	exists, err := om.storage.Exists(ctx, objectPath)
+	// Verify the physical file exists at the correct path (versioned or plain)
+	checkPath := om.getObjectPath(bucket, key)
+	if metaObj.VersionID != "" {
+		checkPath = om.getVersionedObjectPath(bucket, key, metaObj.VersionID)
+	}
+	exists, err := om.storage.Exists(ctx, checkPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check object existence: %w", err)
 	}
 	if !exists {
 		return nil, ErrObjectNotFound
 	}
+	return fromMetadataObject(metaObj), nil
+}
 
-	// Fallback: create basic object info from storage metadata
-	storageMetadata, err := om.storage.GetMetadata(ctx, objectPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get storage metadata: %w", err)
+// storageObjectMetadata answers GetObjectMetadata for a key without a
+// metadata entry from the file at its plain path, rebuilding the entry when
+// read repair is set to rebuild.
+func (om *objectManager) storageObjectMetadata(ctx context.Context, bucket, key string) (*Object, error) {
+	// A delete removes the metadata first and holds the key lock until the
+	// file is gone too; waiting for the lock keeps a HEAD racing an
+	// acknowledged DELETE from reporting the file.
+	if !keyLockHeld(ctx) {
+		om.lockKey(bucket, key)()
 	}
-
-	size, _ := strconv.ParseInt(storageMetadata["size"], 10, 64)
-	lastModified, _ := strconv.ParseInt(storageMetadata["last_modified"], 10, 64)
-
-	object := &Object{
-		Key:          key,
-		Bucket:       bucket,
-		Size:         size,
-		LastModified: time.Unix(lastModified, 0),
-		ETag:         storageMetadata["etag"],
-		ContentType:  storageMetadata["content-type"],
-		Metadata:     storageMetadata,
-		StorageClass: StorageClassStandard,
+	objectPath := om.getObjectPath(bucket, key)
+	exists, err := om.storage.Exists(ctx, objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check object existence: %w", err)
+	}
+	if !exists {
+		return nil, ErrObjectNotFound
+	}
+	storageMetadata, err := om.storage.GetMetadata(ctx, objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage metadata: %w", err)
 	}
 
+	object := objectFromStorageMetadata(bucket, key, storageMetadata)
+	if om.readRepairMode() == ReadRepairRebuild {
+		om.rebuildObjectMetadata(ctx, bucket, key, object, objectPath)
+	}
 	return object, nil
 }
 
@@ -1664,9 +1987,11 @@ func (om *objectManager) SetObjectRetention(ctx context.Context, bucket, key str
 	}
 
 	// Check if object is locked and retention is being shortened
+	previous := obj.Retention
 	if obj.Retention != nil {
 		retentionActive := obj.Retention.RetainUntilDate.After(time.Now())
 		if retentionActive && (config == nil || config.RetainUntilDate.Before(obj.Retention.RetainUntilDate)) {
+			om.logRetentionChange(ctx, bucket, obj, previous, config, audit.StatusFailed)
 			// Cannot shorten retention
 			if obj.Retention.Mode == "COMPLIANCE" {
 				return ErrCannotShortenCompliance
@@ -1681,7 +2006,11 @@ func (om *objectManager) SetObjectRetention(ctx context.Context, bucket, key str
 
 	// Save updated metadata to the metadata store.
 	metaObj := toMetadataObject(obj)
-	return om.metadataStore.PutObject(ctx, metaObj)
+	if err := om.metadataStore.PutObject(ctx, metaObj); err != nil {
+		return err
+	}
+	om.logRetentionChange(ctx, bucket, obj, previous, config, audit.StatusSuccess)
+	return nil
 }
 
 func (om *objectManager) GetObjectLegalHold(ctx context.Context, bucket, key string, versionID ...string) (*LegalHoldConfig, error) {
@@ -1719,11 +2048,16 @@ func (om *objectManager) SetObjectLegalHold(ctx context.Context, bucket, key str
 	}
 
 	// Update legal hold
+	previous := obj.LegalHold
 	obj.LegalHold = config
 
 	// Save updated metadata to the metadata store.
 	metaObj := toMetadataObject(obj)
-	return om.metadataStore.PutObject(ctx, metaObj)
+	if err := om.metadataStore.PutObject(ctx, metaObj); err != nil {
+		return err
+	}
+	om.logLegalHoldChange(ctx, bucket, obj, previous)
+	return nil
 }
 
 func (om *objectManager) getObjectMetadataForVersion(ctx context.Context, bucket, key string, versionID ...string) (*Object, error) {
@@ -1965,6 +2299,12 @@ func (om *objectManager) CreateMultipartUpload(ctx context.Context, bucket, key
 	if err := om.validateObjectName(key); err != nil {
 		return nil, err
 	}
+	if err := om.checkReservedKey(ctx, key); err != nil {
+		return nil, err
+	}
+	if err := ValidateStorageClass(headers.Get("x-amz-storage-class")); err != nil {
+		return nil, err
+	}
 
 	// Generate unique upload ID
 	uploadID, err := om.generateUploadID()
@@ -1986,6 +2326,18 @@ func (om *objectManager) CreateMultipartUpload(ctx context.Context, bucket, key
 	if acl := headers.Get("x-amz-acl"); acl != "" {
 		metadata["x-amz-acl"] = acl
 	}
+	// Every part of an upload created with a checksum algorithm gets a
+	// checksum of that algorithm, from which CompleteMultipartUpload builds
+	// the object's composite checksum.
+	if algo := strings.ToUpper(headers.Get("x-amz-checksum-algorithm")); algo != "" {
+		if newChecksumHasher(algo) == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidChecksumAlgorithm, algo)
+		}
+		metadata["x-amz-checksum-algorithm"] = algo
+	}
+	// Encryption is always on: record the SSE the completed object gets so the
+	// part and completion responses, and the object itself, report it.
+	metadata["x-amz-server-side-encryption"] = "AES256"
 
 	// Create multipart upload metadata
 	multipart := &MultipartUpload{
@@ -1998,6 +2350,12 @@ func (om *objectManager) CreateMultipartUpload(ctx context.Context, bucket, key
 		Parts:        []Part{},
 	}
 
+	om.multipartLimitMu.Lock()
+	defer om.multipartLimitMu.Unlock()
+	if err := om.checkMultipartUploadLimits(ctx, bucket); err != nil {
+		return nil, err
+	}
+
 	// Save multipart upload metadata to the metadata store.
 	metaMU := toMetadataMultipartUpload(multipart)
 	if err := om.metadataStore.CreateMultipartUpload(ctx, metaMU); err != nil {
@@ -2007,46 +2365,113 @@ func (om *objectManager) CreateMultipartUpload(ctx context.Context, bucket, key
 	return multipart, nil
 }
 
-func (om *objectManager) UploadPart(ctx context.Context, uploadID string, partNumber int, data io.Reader) (*Part, error) {
-	if partNumber < 1 || partNumber > 10000 {
-		return nil, fmt.Errorf("part number must be between 1 and 10000")
+// UploadPart stores one part of a multipart upload. headers, when given, are
+// the UploadPart request headers: an x-amz-checksum-* value in them is
+// verified against the part data. Parts of an upload created with a checksum
+// algorithm always get a checksum of that algorithm.
+func (om *objectManager) UploadPart(ctx context.Context, uploadID string, partNumber int, data io.Reader, headers ...http.Header) (*Part, error) {
+	if partNumber < 1 || partNumber > MaxMultipartParts {
+		return nil, fmt.Errorf("%w: part number must be between 1 and %d", ErrInvalidPartNumber, MaxMultipartParts)
 	}
-	if _, err := om.metadataStore.GetMultipartUpload(ctx, uploadID); err != nil {
+	// Part numbers are capped at the part limit, so an upload can never hold
+	// more parts than that no matter how it is driven.
+	if maxParts := om.multipartMaxParts(); partNumber > maxParts {
+		return nil, fmt.Errorf("%w: this server accepts at most %d parts per upload", ErrTooManyParts, maxParts)
+	}
+	if err := om.checkFreeSpace(); err != nil {
+		return nil, err
+	}
+	upload, err := om.metadataStore.GetMultipartUpload(ctx, uploadID)
+	if err != nil {
 		if err == metadata.ErrUploadNotFound {
 			return nil, ErrUploadNotFound
 		}
 		return nil, err
 	}
 
+	// A bucket already at its object-count cap cannot take the object this
+	// upload would create; refuse the part before storing it.
+	enforceQuota := !isBypassQuotaEnforcement(ctx)
+	var existingObj *metadata.ObjectMetadata
+	if enforceQuota {
+		existingObj, _ = om.metadataStore.GetObject(ctx, upload.Bucket, upload.Key)
+		isNewObject := existingObj == nil || isMetadataDeleteMarker(existingObj)
+		if err := om.checkBucketStorageQuota(ctx, upload.Bucket, 0, isNewObject); err != nil {
+			return nil, err
+		}
+	}
+
+	var requestHeaders http.Header
+	if len(headers) > 0 {
+		requestHeaders = headers[0]
+	}
+	checksumAlgo, expectedChecksum := requestChecksum(requestHeaders)
+	if uploadAlgo := upload.Metadata["x-amz-checksum-algorithm"]; uploadAlgo != "" {
+		if checksumAlgo != "" && checksumAlgo != uploadAlgo {
+			return nil, fmt.Errorf("%w: the upload was created with %s, the part uses %s", ErrInvalidChecksumAlgorithm, uploadAlgo, checksumAlgo)
+		}
+		checksumAlgo = uploadAlgo
+	}
+	checksumHasher := newChecksumHasher(checksumAlgo)
+	if checksumAlgo != "" && checksumHasher == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidChecksumAlgorithm, checksumAlgo)
+	}
+	if checksumHasher != nil {
+		data = io.TeeReader(data, checksumHasher)
+	}
+
 	// Create part path
 	partPath := om.getMultipartPartPath(uploadID, partNumber)
 
-	// Store part data
+	// Store part data, encrypted like the object it becomes part of. The
+	// sidecar's size and etag describe the ciphertext; the part's ETag is the
+	// MD5 of the plaintext, as with AWS SSE-S3.
 	partMetadata := map[string]string{
 		"upload-id":    uploadID,
 		"part-number":  strconv.Itoa(partNumber),
 		"content-type": "application/octet-stream",
 	}
+	size, etag, err := om.storeEncryptedPart(ctx, upload.Bucket, partPath, data, partMetadata)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := om.storage.Put(ctx, partPath, data, partMetadata); err != nil {
-		return nil, fmt.Errorf("failed to store part: %w", err)
+	var checksumValue string
+	if checksumHasher != nil {
+		checksumValue = base64.StdEncoding.EncodeToString(checksumHasher.Sum(nil))
+		if expectedChecksum != "" && expectedChecksum != checksumValue {
+			_ = om.storage.Delete(ctx, partPath)
+			return nil, ErrBadChecksum
+		}
 	}
 
-	// Get part metadata to get size and etag
 	storageMetadata, err := om.storage.GetMetadata(ctx, partPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get part metadata: %w", err)
 	}
-
-	size, _ := strconv.ParseInt(storageMetadata["size"], 10, 64)
 	lastModified, _ := strconv.ParseInt(storageMetadata["last_modified"], 10, 64)
 
+	// A part that alone overflows the bucket's size cap can never complete.
+	// The assembled object is checked again in CompleteMultipartUpload.
+	if enforceQuota {
+		sizeIncrement := size
+		if existingObj != nil && !om.isBucketVersioningEnabled(ctx, upload.Bucket) {
+			sizeIncrement -= existingObj.Size
+		}
+		if err := om.checkBucketStorageQuota(ctx, upload.Bucket, sizeIncrement, false); err != nil {
+			_ = om.storage.Delete(ctx, partPath)
+			return nil, err
+		}
+	}
+
 	partMeta := &metadata.PartMetadata{
-		UploadID:     uploadID,
-		PartNumber:   partNumber,
-		ETag:         storageMetadata["etag"],
-		Size:         size,
-		LastModified: time.Unix(lastModified, 0),
+		UploadID:          uploadID,
+		PartNumber:        partNumber,
+		ETag:              etag,
+		Size:              size,
+		LastModified:      time.Unix(lastModified, 0),
+		ChecksumAlgorithm: checksumAlgo,
+		ChecksumValue:     checksumValue,
 	}
 
 	// Store part metadata in the metadata store.
@@ -2059,10 +2484,13 @@ func (om *objectManager) UploadPart(ctx context.Context, uploadID string, partNu
 	}
 
 	part := &Part{
-		PartNumber:   partNumber,
-		ETag:         storageMetadata["etag"],
-		Size:         size,
-		LastModified: time.Unix(lastModified, 0),
+		PartNumber:        partNumber,
+		ETag:              etag,
+		Size:              size,
+		LastModified:      time.Unix(lastModified, 0),
+		SSEAlgorithm:      upload.Metadata["x-amz-server-side-encryption"],
+		ChecksumAlgorithm: checksumAlgo,
+		ChecksumValue:     checksumValue,
 	}
 
 	return part, nil
@@ -2089,30 +2517,40 @@ func (om *objectManager) ListParts(ctx context.Context, uploadID string) ([]Part
 	parts := make([]Part, len(metaParts))
 	for i, mp := range metaParts {
 		parts[i] = Part{
-			PartNumber:   mp.PartNumber,
-			ETag:         mp.ETag,
-			Size:         mp.Size,
-			LastModified: mp.LastModified,
+			PartNumber:        mp.PartNumber,
+			ETag:              mp.ETag,
+			Size:              mp.Size,
+			LastModified:      mp.LastModified,
+			ChecksumAlgorithm: mp.ChecksumAlgorithm,
+			ChecksumValue:     mp.ChecksumValue,
 		}
 	}
 
 	return parts, nil
 }
 
-// CompleteMultipartUpload deduplicates concurrent requests for the same uploadID.
-// If a completion for this uploadID is already in progress, the caller waits and
-// receives the same result — preventing race conditions on the filesystem.
+// CompleteMultipartUpload serialises concurrent requests for the same uploadID.
+// A caller arriving while a completion is in progress waits for it: if the
+// first completion wins, the upload no longer exists and the caller gets
+// ErrInvalidUploadID (NoSuchUpload); if it failed, the upload is still open
+// and the caller makes its own attempt.
 func (om *objectManager) CompleteMultipartUpload(ctx context.Context, uploadID string, parts []Part) (*Object, error) {
 	om.completionMu.Lock()
-	if f, ok := om.completions[uploadID]; ok {
+	for {
+		f, ok := om.completions[uploadID]
+		if !ok {
+			break
+		}
 		om.completionMu.Unlock()
-		// Another goroutine is already completing this upload — wait for it.
 		select {
 		case <-f.done:
-			return f.obj, f.err
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		}
+		if f.err == nil {
+			return nil, ErrInvalidUploadID
+		}
+		om.completionMu.Lock()
 	}
 	f := &completionFuture{done: make(chan struct{})}
 	om.completions[uploadID] = f
@@ -2139,6 +2577,10 @@ func (om *objectManager) doCompleteMultipartUpload(ctx context.Context, uploadID
 		return nil, err
 	}
 	multipart := fromMetadataMultipartUpload(metaMU)
+	// Uploads started before the prefix was reserved can't complete either
+	if err := om.checkReservedKey(ctx, multipart.Key); err != nil {
+		return nil, err
+	}
 	versioningEnabled := om.isBucketVersioningEnabled(ctx, multipart.Bucket)
 
 	// Validate parts list and calculate total size
@@ -2147,35 +2589,98 @@ func (om *objectManager) doCompleteMultipartUpload(ctx context.Context, uploadID
 		return nil, err
 	}
 
-	// Check if this overwrites an existing object (before combining parts)
+	// Check if this overwrites an existing object (before combining parts).
+	// Read again under the key lock before the metrics are updated.
 	existingObj, _ := om.metadataStore.GetObject(ctx, multipart.Bucket, multipart.Key)
-	isNewObject := existingObj == nil
+
+	if !versioningEnabled {
+		if err := om.checkOverwriteRetention(ctx, multipart.Bucket, multipart.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	// Write-once bucket: completing onto an existing key fails like a PUT
+	// would. Checked here to fail before any work, and again under the key
+	// lock right before the object is published.
+	noOverwrite := om.isBucketNoOverwrite(ctx, multipart.Bucket)
+	if noOverwrite {
+		if err := om.checkNoOverwrite(ctx, multipart.Bucket, multipart.Key); err != nil {
+			return nil, err
+		}
+	}
+	keyLocked := false
 
 	// Validate tenant storage quota BEFORE combining parts (early rejection to avoid wasted work)
 	if err := om.checkMultipartQuotaBeforeComplete(ctx, multipart.Bucket, uploadID, totalSize, existingObj, versioningEnabled); err != nil {
 		return nil, err
 	}
 
+	// The scanner reads the parts before they are assembled, so a rejected
+	// upload never reaches the object path. Its parts are deleted with it.
+	if err := om.scanUpload(ctx, multipart.Bucket, multipart.Key, multipart.Metadata["content-type"], totalSize, func() (io.ReadCloser, error) {
+		return om.newMultipartPartsReader(ctx, uploadID, parts), nil
+	}); err != nil {
+		var rejected *UploadRejectedError
+		if errors.As(err, &rejected) {
+			om.abortMultipartUpload(ctx, uploadID, false)
+		}
+		return nil, err
+	}
+
 	// Compute the S3-spec multipart ETag: MD5 of the concatenated binary MD5 digests
 	// of each part, formatted as "<hex>-<partCount>".
 	multipartETag, err := om.computeMultipartETag(ctx, uploadID, parts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compute multipart ETag: %w", err)
 	}
+	checksumAlgo := multipart.Metadata["x-amz-checksum-algorithm"]
+	checksumValue, err := om.computeMultipartChecksum(ctx, uploadID, checksumAlgo, parts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute multipart checksum: %w", err)
+	}
+	if checksumValue == "" {
+		checksumAlgo = ""
+	}
 
-	// Combine parts into final object.
-	// storage.Put inside combineMultipartParts already computes etag+size and writes them
-	// to the .metadata sidecar — no need to re-read the data file for MD5.
+	// Assemble the final object: the parts are decrypted one after the other
+	// and streamed through a fresh envelope straight into assembledPath, so
+	// the plaintext never touches the disk. A versioned completion writes its
+	// own version file. Without versioning the object replaces the current
+	// one at objectPath: it is assembled at a path of its own and moved into
+	// place under the key lock, so the lock isn't held while a large object
+	// is written. Backends that can't move an object assemble it in place
+	// under the lock.
 	var versionID string
 	var objectPath string
+	var assembledPath string
+	committer, canCommit := om.storage.(objectCommitter)
 	if versioningEnabled {
 		versionID = generateVersionID()
 		objectPath = om.getVersionedObjectPath(multipart.Bucket, multipart.Key, versionID)
+		assembledPath = objectPath
 	} else {
 		objectPath = om.getObjectPath(multipart.Bucket, multipart.Key)
+		if canCommit {
+			assembledPath = om.getMultipartAssemblyPath(uploadID)
+		} else {
+			assembledPath = objectPath
+			defer om.lockKey(multipart.Bucket, multipart.Key)()
+			keyLocked = true
+			if err := om.checkUploadOpen(ctx, uploadID); err != nil {
+				return nil, err
+			}
+			if noOverwrite {
+				if err := om.checkNoOverwrite(ctx, multipart.Bucket, multipart.Key); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
-	if err := om.combineMultipartParts(ctx, uploadID, parts, objectPath); err != nil {
-		return nil, fmt.Errorf("failed to combine parts: %w", err)
+	partsReader := om.newMultipartPartsReader(ctx, uploadID, parts)
+	err = om.storeEncryptedMultipartObject(ctx, assembledPath, partsReader, uploadID, multipart, totalSize, multipartETag)
+	partsReader.Close()
+	if err != nil {
+		return nil, err
 	}
 
 	// Clean up the combined file on any error between here and the metadata write.
@@ -2183,40 +2688,20 @@ func (om *objectManager) doCompleteMultipartUpload(ctx context.Context, uploadID
 	needsCombinedFileCleanup := true
 	defer func() {
 		if needsCombinedFileCleanup {
-			if delErr := om.storage.Delete(ctx, objectPath); delErr != nil {
-				logrus.WithError(delErr).WithField("path", objectPath).Warn("Failed to remove orphaned combined object after error")
+			if delErr := om.storage.Delete(ctx, assembledPath); delErr != nil {
+				logrus.WithError(delErr).WithField("path", assembledPath).Warn("Failed to remove orphaned combined object after error")
 			}
 		}
 	}()
 
-	// Retrieve etag+size+last_modified already written by combineMultipartParts.
-	// This reads only the tiny .metadata sidecar file, not the data.
-	storageMetadata, err := om.storage.GetMetadata(ctx, objectPath)
+	// Only the tiny .metadata sidecar is read here, not the data.
+	storageMetadata, err := om.storage.GetMetadata(ctx, assembledPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get object metadata after combining parts: %w", err)
 	}
-	originalETag := storageMetadata["etag"]
-	originalSize, _ := strconv.ParseInt(storageMetadata["size"], 10, 64)
+	originalSize := totalSize
 	lastModified, _ := strconv.ParseInt(storageMetadata["last_modified"], 10, 64)
 
-	// Encryption is always on: re-encrypt the combined object (envelope)
-	// by reading the assembled plaintext → temp → encrypt → write back.
-	{
-		// Buffer plaintext to a temp file so objectPath can be safely overwritten on Windows.
-		tempPath, stageErr := om.stagePlaintextToTemp(ctx, objectPath)
-		if stageErr != nil {
-			return nil, stageErr
-		}
-		defer os.Remove(tempPath)
-		if err := om.storeEncryptedMultipartObject(ctx, objectPath, tempPath, uploadID, multipart, originalSize, originalETag); err != nil {
-			return nil, err
-		}
-		// Re-read metadata after encryption (encrypted size differs from plaintext size).
-		if sm, err2 := om.storage.GetMetadata(ctx, objectPath); err2 == nil {
-			lastModified, _ = strconv.ParseInt(sm["last_modified"], 10, 64)
-		}
-	}
-
 	contentType := multipart.Metadata["content-type"]
 	if contentType == "" {
 		contentType = "application/octet-stream"
@@ -2231,7 +2716,42 @@ func (om *objectManager) doCompleteMultipartUpload(ctx context.Context, uploadID
 		Metadata:     filterStorageMetadataKeys(multipart.Metadata),
 		StorageClass: multipart.StorageClass,
 		VersionID:    versionID,
+		SSEAlgorithm: multipart.Metadata["x-amz-server-side-encryption"],
+
+		ChecksumAlgorithm: checksumAlgo,
+		ChecksumValue:     checksumValue,
+	}
+	if object.SSEAlgorithm == "" {
+		// Upload created before the SSE status was recorded on it
+		object.SSEAlgorithm = "AES256"
+	}
+	om.applyDefaultWriteLock(ctx, object)
+
+	// The key lock is only taken to publish the object. Re-checking the
+	// upload under it means only one completion of an upload ID ever
+	// commits. It doesn't order a completion against a PUT of the same key:
+	// the PUT stores its data before it takes the lock, so as between two
+	// PUTs, timing decides which data ends up at objectPath and which
+	// metadata is recorded last.
+	if !keyLocked {
+		defer om.lockKey(multipart.Bucket, multipart.Key)()
+	}
+	if err := om.checkUploadOpen(ctx, uploadID); err != nil {
+		return nil, err
+	}
+	if noOverwrite {
+		if err := om.checkNoOverwrite(ctx, multipart.Bucket, multipart.Key); err != nil {
+			return nil, err
+		}
+	}
+	if assembledPath != objectPath {
+		if err := committer.CommitObject(ctx, assembledPath, objectPath); err != nil {
+			return nil, fmt.Errorf("failed to move assembled object into place: %w", err)
+		}
+		assembledPath = objectPath
 	}
+	existingObj, _ = om.metadataStore.GetObject(ctx, multipart.Bucket, multipart.Key)
+	isNewObject := existingObj == nil
 
 	// From this point on PutObjectVersion/PutObject handle cleanup on failure.
 	needsCombinedFileCleanup = false
@@ -2266,38 +2786,31 @@ func (om *objectManager) doCompleteMultipartUpload(ctx context.Context, uploadID
 	logrus.WithFields(logrus.Fields{
 		"uploadID": uploadID,
 		"size":     originalSize,
-		"etag":     originalETag,
+		"etag":     multipartETag,
 	}).Info("Multipart upload completed successfully")
 
-	// Update bucket metrics and clean up multipart data
+	// Update bucket metrics and clean up multipart data. Still under the key
+	// lock, so the upload ID is invalidated exactly once.
 	om.updateMetricsAndCleanupMultipart(ctx, multipart.Bucket, uploadID, originalSize, isNewObject, existingObj, parts, versioningEnabled)
 
+	if versioningEnabled {
+		om.trimExcessVersions(ctx, multipart.Bucket, multipart.Key)
+	}
+
 	return object, nil
 }
 
-// stagePlaintextToTemp copies the combined object at objectPath to a temporary file
-// so that objectPath can be safely overwritten during encryption on Windows
-// (os.Open does not set FILE_SHARE_DELETE, preventing os.Rename to an open path).
-func (om *objectManager) stagePlaintextToTemp(ctx context.Context, objectPath string) (string, error) {
-	reader, _, err := om.storage.Get(ctx, objectPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open combined object for encryption staging: %w", err)
-	}
-	defer reader.Close()
-
-	// Use DataDir to stay on the same filesystem as the final object path (BUG-05).
-	tempFile, err := os.CreateTemp(om.config.Root, "maxiofs-multipart-*")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file for encryption staging: %w", err)
-	}
-	tempPath := tempFile.Name()
-	if _, err := io.Copy(tempFile, reader); err != nil {
-		tempFile.Close()
-		os.Remove(tempPath)
-		return "", fmt.Errorf("failed to stage plaintext to temp file: %w", err)
+// checkUploadOpen reports ErrInvalidUploadID when the upload has been
+// completed or aborted in the meantime. Called under the key lock right
+// before a completion commits.
+func (om *objectManager) checkUploadOpen(ctx context.Context, uploadID string) error {
+	if _, err := om.metadataStore.GetMultipartUpload(ctx, uploadID); err != nil {
+		if errors.Is(err, metadata.ErrUploadNotFound) {
+			return ErrInvalidUploadID
+		}
+		return err
 	}
-	tempFile.Close()
-	return tempPath, nil
+	return nil
 }
 
 func (om *objectManager) AbortMultipartUpload(ctx context.Context, uploadID string) error {
@@ -2392,14 +2905,15 @@ func (om *objectManager) validateObjectName(key string) error {
 }
 
 // getObjectPath returns the storage path for an object
+// Format: bucket/key, or an opaque id in the opaque key layout
 func (om *objectManager) getObjectPath(bucket, key string) string {
-	return fmt.Sprintf("%s/%s", bucket, key)
+	return om.keyPaths.ObjectPath(bucket, key)
 }
 
 // getVersionedObjectPath returns the storage path for a versioned object
-// Format: bucket/.versions/key/versionID
+// Format: bucket/.versions/key/versionID, or an opaque id in the opaque key layout
 func (om *objectManager) getVersionedObjectPath(bucket, key, versionID string) string {
-	return fmt.Sprintf("%s/.versions/%s/%s", bucket, key, versionID)
+	return om.keyPaths.VersionPath(bucket, key, versionID)
 }
 
 // Removed: getObjectMetadataPath, saveObjectMetadata, loadObjectMetadata
@@ -2452,80 +2966,107 @@ func (om *objectManager) applyDefaultRetention(ctx context.Context, object *Obje
 	return nil
 }
 
-// Multipart upload helper methods
+// applyDefaultWriteLock gives a new object the bucket's default write lock: a
+// GOVERNANCE retention of DefaultWriteLockDays from now. It is independent of
+// Object Lock and never replaces a retention that is already set. Folder
+// markers carry no data and are left unlocked.
+func (om *objectManager) applyDefaultWriteLock(ctx context.Context, object *Object) {
+	if object.Retention != nil || strings.HasSuffix(object.Key, "/") {
+		return
+	}
 
-// generateUploadID generates a unique upload ID
-func (om *objectManager) generateUploadID() (string, error) {
-	bytes := make([]byte, 16)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
+	bucketMeta, err := om.loadBucketMetadata(ctx, object.Bucket)
+	if err != nil || bucketMeta.DefaultWriteLockDays <= 0 {
+		return
 	}
-	return hex.EncodeToString(bytes), nil
-}
 
-// getMultipartPartPath returns the path for a multipart part in storage
-func (om *objectManager) getMultipartPartPath(uploadID string, partNumber int) string {
-	return fmt.Sprintf(".maxiofs/multipart/parts/%s/%05d", uploadID, partNumber)
+	object.Retention = &RetentionConfig{
+		Mode:            RetentionModeGovernance,
+		RetainUntilDate: time.Now().AddDate(0, 0, bucketMeta.DefaultWriteLockDays),
+	}
 }
 
-// Removed: getMultipartUploadPath, saveMultipartUpload, loadMultipartUpload, updatePartsList
-// These functions are now backed by metadataStore operations.
-
-// combineMultipartParts combines all parts into the final object
-func (om *objectManager) combineMultipartParts(ctx context.Context, uploadID string, parts []Part, finalPath string) error {
-	// Create a combined metadata
-	combinedMetadata := map[string]string{
-		"content-type": "application/octet-stream",
+// checkOverwriteRetention returns a RetentionError when the current object at
+// key is still under retention. Used before non-versioned overwrites, which
+// would otherwise discard a locked object's data.
+func (om *objectManager) checkOverwriteRetention(ctx context.Context, bucket, key string) error {
+	existing, err := om.metadataStore.GetObject(ctx, bucket, key)
+	if err != nil || existing == nil || existing.Retention == nil {
+		return nil
+	}
+	if !time.Now().Before(existing.Retention.RetainUntilDate) {
+		return nil
+	}
+	if existing.Retention.Mode == RetentionModeCompliance {
+		return NewComplianceRetentionError(existing.Retention.RetainUntilDate)
 	}
+	return NewGovernanceRetentionError(existing.Retention.RetainUntilDate)
+}
 
-	if len(parts) == 0 {
-		return fmt.Errorf("no parts to combine")
+// isBucketNoOverwrite reports whether the bucket is in write-once mode. HA
+// replica writes are never refused: the primary already applied the check.
+func (om *objectManager) isBucketNoOverwrite(ctx context.Context, bucket string) bool {
+	if isBypassQuotaEnforcement(ctx) {
+		return false
 	}
+	bucketMeta, err := om.loadBucketMetadata(ctx, bucket)
+	return err == nil && bucketMeta.NoOverwrite
+}
 
-	// Get content type from first part if available
-	if len(parts) > 0 {
-		firstPartPath := om.getMultipartPartPath(uploadID, parts[0].PartNumber)
-		metadata, err := om.storage.GetMetadata(ctx, firstPartPath)
-		if err == nil {
-			if contentType, exists := metadata["content-type"]; exists {
-				combinedMetadata["content-type"] = contentType
-			}
-		}
+// bucketDefaultContentType returns the Content-Type the bucket gives uploads
+// that declare none, or "" when it has no default.
+func (om *objectManager) bucketDefaultContentType(ctx context.Context, bucket string) string {
+	bucketMeta, err := om.loadBucketMetadata(ctx, bucket)
+	if err != nil {
+		return ""
 	}
+	return bucketMeta.DefaultContentType
+}
 
-	// Create a MultiReader that concatenates all parts in order
-	readers := make([]io.Reader, len(parts))
-	for i, part := range parts {
-		partPath := om.getMultipartPartPath(uploadID, part.PartNumber)
-		reader, _, err := om.storage.Get(ctx, partPath)
-		if err != nil {
-			// Close all previously opened readers
-			for j := 0; j < i; j++ {
-				if closer, ok := readers[j].(io.Closer); ok {
-					closer.Close()
-				}
-			}
-			return fmt.Errorf("failed to read part %d: %w", part.PartNumber, err)
+// checkNoOverwrite returns ErrObjectExists when key already has a current
+// object. A delete marker on top doesn't count: the key was deleted.
+func (om *objectManager) checkNoOverwrite(ctx context.Context, bucket, key string) error {
+	if _, err := om.currentObjectMetadata(ctx, bucket, key); err != nil {
+		if err == ErrObjectNotFound {
+			return nil
 		}
-		readers[i] = reader
+		return err
 	}
+	return ErrObjectExists
+}
 
-	// Create a combined reader that reads all parts sequentially
-	combinedReader := io.MultiReader(readers...)
-
-	// Store the combined object
-	err := om.storage.Put(ctx, finalPath, combinedReader, combinedMetadata)
+// Multipart upload helper methods
 
-	// Close all readers after Put completes
-	for _, reader := range readers {
-		if closer, ok := reader.(io.Closer); ok {
-			closer.Close()
-		}
+// generateUploadID generates a unique upload ID
+func (om *objectManager) generateUploadID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// getMultipartPartPath returns the path for a multipart part in storage
+func (om *objectManager) getMultipartPartPath(uploadID string, partNumber int) string {
+	return fmt.Sprintf(".maxiofs/multipart/parts/%s/%05d", uploadID, partNumber)
+}
 
-	return err
+// getMultipartAssemblyPath returns a fresh path, next to the upload's parts,
+// to assemble the completed object at before it is moved into place. Every
+// completion attempt gets its own, so concurrent attempts don't share one.
+func (om *objectManager) getMultipartAssemblyPath(uploadID string) string {
+	return fmt.Sprintf(".maxiofs/multipart/parts/%s/assembled-%s", uploadID, generateVersionID())
 }
 
+// objectCommitter is implemented by storage backends that can move a fully
+// written object over another one (storage.FilesystemBackend).
+type objectCommitter interface {
+	CommitObject(ctx context.Context, src, dst string) error
+}
+
+// Removed: getMultipartUploadPath, saveMultipartUpload, loadMultipartUpload, updatePartsList
+// These functions are now backed by metadataStore operations.
+
 // abortMultipartUpload cleans up a multipart upload
 func (om *objectManager) abortMultipartUpload(ctx context.Context, uploadID string, returnError bool) error {
 	if _, err := om.metadataStore.GetMultipartUpload(ctx, uploadID); err != nil {
@@ -2701,7 +3242,6 @@ func (om *objectManager) cleanupEmptyDirectories(bucket, key string) {
 // extractMetadataFromHeaders extracts storage and user metadata from HTTP headers
 func (om *objectManager) extractMetadataFromHeaders(headers http.Header) (storageMetadata, userMetadata map[string]string) {
 	storageMetadata = make(map[string]string)
-	userMetadata = make(map[string]string)
 
 	// Extract Content-Type
 	if contentType := headers.Get("Content-Type"); contentType != "" {
@@ -2723,16 +3263,7 @@ func (om *objectManager) extractMetadataFromHeaders(headers http.Header) (storag
 	}
 
 	// Extract user-defined metadata (x-amz-meta-* headers)
-	for headerKey, values := range headers {
-		if len(values) > 0 {
-			lowerKey := strings.ToLower(headerKey)
-			// Only store x-amz-meta-* headers as user metadata
-			if strings.HasPrefix(lowerKey, "x-amz-meta-") {
-				metaKey := strings.TrimPrefix(lowerKey, "x-amz-meta-")
-				userMetadata[metaKey] = values[0]
-			}
-		}
-	}
+	userMetadata = UserMetadataFromHeaders(headers)
 
 	return storageMetadata, userMetadata
 }
@@ -2752,7 +3283,8 @@ func filterStorageMetadataKeys(m map[string]string) map[string]string {
 		"content-type": true, "content-disposition": true,
 		"content-encoding": true, "cache-control": true,
 		"content-language": true, "storage-class": true,
-		"x-amz-acl": true,
+		"x-amz-acl": true, "x-amz-server-side-encryption": true,
+		"x-amz-checksum-algorithm": true,
 	}
 	out := make(map[string]string, len(m))
 	for k, v := range m {
@@ -2763,38 +3295,76 @@ func filterStorageMetadataKeys(m map[string]string) map[string]string {
 	return out
 }
 
-// newEnvelope generates a fresh per-object DEK and returns it together with
-// the sidecar metadata entries that make the object decryptable later:
+// newEnvelope generates a fresh per-object DEK for an object in bucket and
+// returns it together with the sidecar metadata entries that make the object
+// decryptable later (see wrapDEK).
+func (om *objectManager) newEnvelope(bucket string) ([]byte, map[string]string, error) {
+	dek, err := om.encryptor.GenerateKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate DEK: %w", err)
+	}
+	envelopeMeta, err := om.wrapDEK(bucket, dek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dek, envelopeMeta, nil
+}
+
+// wrapDEK returns the sidecar entries for dek. Objects in global buckets get
 // the DEK wrapped (AES-256-GCM) with the current KEK, the wrap IV, and the
-// KEK version. These entries live in the on-disk .metadata sidecar so the
+// KEK version. Objects in a tenant bucket get the DEK wrapped with the
+// owning tenant's current key instead, recorded as tenant-key-id,
+// tenant-key-version and tenant-key-fingerprint, plus a copy of that tenant
+// key wrapped with the current KEK. These entries live in the on-disk .metadata sidecar so the
 // object is recoverable from the filesystem alone (given a KEK backup),
 // even if the metadata database is lost.
-func (om *objectManager) newEnvelope() ([]byte, map[string]string, error) {
+func (om *objectManager) wrapDEK(bucket string, dek []byte) (map[string]string, error) {
 	kekKey, kekVersion := om.kekProvider.CurrentKEK()
 	if len(kekKey) == 0 {
-		return nil, nil, fmt.Errorf("no current KEK available")
+		return nil, fmt.Errorf("no current KEK available")
 	}
 
-	dek, err := om.encryptor.GenerateKey()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate DEK: %w", err)
+	tenantID, _ := om.parseBucketPath(bucket)
+	if tenantID == "" || om.tenantKeys == nil {
+		wrapped, err := om.encryptor.Encrypt(dek, kekKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap DEK: %w", err)
+		}
+		return map[string]string{
+			"wrapped-dek":    hex.EncodeToString(wrapped.Data),
+			"wrapped-dek-iv": hex.EncodeToString(wrapped.IV),
+			"kek-version":    strconv.Itoa(kekVersion),
+		}, nil
 	}
 
-	wrapped, err := om.encryptor.Encrypt(dek, kekKey)
+	tenantKey, tenantKeyVersion, err := om.tenantKeys.CurrentTenantKey(tenantID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to wrap DEK: %w", err)
+		return nil, fmt.Errorf("failed to resolve encryption key for tenant %s: %w", tenantID, err)
 	}
-
-	return dek, map[string]string{
-		"wrapped-dek":    hex.EncodeToString(wrapped.Data),
-		"wrapped-dek-iv": hex.EncodeToString(wrapped.IV),
-		"kek-version":    strconv.Itoa(kekVersion),
+	wrapped, err := om.encryptor.Encrypt(dek, tenantKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap DEK: %w", err)
+	}
+	wrappedTenantKey, err := om.encryptor.Encrypt(tenantKey, kekKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap tenant key: %w", err)
+	}
+	return map[string]string{
+		"wrapped-dek":            hex.EncodeToString(wrapped.Data),
+		"wrapped-dek-iv":         hex.EncodeToString(wrapped.IV),
+		"tenant-key-id":          tenantID,
+		"tenant-key-version":     strconv.Itoa(tenantKeyVersion),
+		"wrapped-tenant-key":     hex.EncodeToString(wrappedTenantKey.Data),
+		"wrapped-tenant-key-iv":  hex.EncodeToString(wrappedTenantKey.IV),
+		"tenant-key-kek-version": strconv.Itoa(kekVersion),
+		"tenant-key-fingerprint": tenantKeyFingerprint(tenantID, tenantKey),
 	}, nil
 }
 
 // decryptionKeyFor resolves the key that decrypts an encrypted object from
 // its sidecar metadata. Two encrypted formats coexist:
-//   - envelope (wrapped-dek present): unwrap the DEK with the recorded KEK version
+//   - envelope (wrapped-dek present): unwrap the DEK with the recorded KEK
+//     version, or with the recorded tenant key when tenant-key-id is set
 //   - legacy direct (no wrapped-dek): the object bytes were encrypted directly
 //     with KEK version 1 (the former config.yaml master key)
 func (om *objectManager) decryptionKeyFor(storageMetadata map[string]string) ([]byte, error) {
@@ -2812,6 +3382,10 @@ func (om *objectManager) decryptionKeyFor(storageMetadata map[string]string) ([]
 		return nil, fmt.Errorf("corrupt wrapped-dek-iv metadata: %w", err)
 	}
 
+	if storageMetadata["tenant-key-id"] != "" {
+		return om.unwrapTenantDEK(storageMetadata, &encryption.EncryptedData{Data: wrapped, IV: iv})
+	}
+
 	kekVersion := 1
 	if v := storageMetadata["kek-version"]; v != "" {
 		kekVersion, err = strconv.Atoi(v)
@@ -2831,13 +3405,95 @@ func (om *objectManager) decryptionKeyFor(storageMetadata map[string]string) ([]
 	return dek, nil
 }
 
+// unwrapTenantDEK unwraps a DEK wrapped with the tenant key recorded in a
+// sidecar. The key comes from the tenant key provider; the KEK-wrapped copy in
+// the sidecar is used when this node does not hold that tenant key version
+// (metadata database lost or restored from an older backup). Tenant keys
+// regenerated after such a loss reuse version numbers, so a key is only
+// trusted when it matches the fingerprint recorded with the object; sidecars
+// written before fingerprints were recorded trust the version.
+func (om *objectManager) unwrapTenantDEK(storageMetadata map[string]string, wrappedDEK *encryption.EncryptedData) ([]byte, error) {
+	tenantID := storageMetadata["tenant-key-id"]
+	version, err := strconv.Atoi(storageMetadata["tenant-key-version"])
+	if err != nil {
+		return nil, fmt.Errorf("corrupt tenant-key-version metadata: %w", err)
+	}
+	fingerprint := storageMetadata["tenant-key-fingerprint"]
+	matches := func(key []byte) bool {
+		return fingerprint == "" || tenantKeyFingerprint(tenantID, key) == fingerprint
+	}
+
+	var key []byte
+	err = kek.ErrTenantKeyNotFound
+	if om.tenantKeys != nil {
+		key, err = om.tenantKeys.TenantKeyByVersion(tenantID, version)
+		if err != nil && !errors.Is(err, kek.ErrTenantKeyNotFound) {
+			return nil, err
+		}
+	}
+	if err != nil || !matches(key) {
+		key, err = om.sidecarTenantKey(storageMetadata, tenantID, version)
+		if err != nil {
+			return nil, err
+		}
+		if !matches(key) {
+			return nil, fmt.Errorf("encryption key version %d of tenant %s not found", version, tenantID)
+		}
+	}
+
+	dek, err := om.encryptor.Decrypt(wrappedDEK, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap DEK: %w", err)
+	}
+	return dek, nil
+}
+
+// tenantKeyFingerprint identifies a tenant key in a sidecar without revealing
+// it. The tenant ID is mixed in, so a sidecar pointed at another tenant never
+// matches that tenant's keys.
+func tenantKeyFingerprint(tenantID string, key []byte) string {
+	h := sha256.New()
+	h.Write([]byte(tenantID))
+	h.Write([]byte{0})
+	h.Write(key)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// sidecarTenantKey unwraps the copy of the tenant key recorded, wrapped with
+// a KEK, in a sidecar.
+func (om *objectManager) sidecarTenantKey(storageMetadata map[string]string, tenantID string, version int) ([]byte, error) {
+	wrapped, err := hex.DecodeString(storageMetadata["wrapped-tenant-key"])
+	if err != nil || len(wrapped) == 0 {
+		return nil, fmt.Errorf("encryption key version %d of tenant %s not found", version, tenantID)
+	}
+	iv, err := hex.DecodeString(storageMetadata["wrapped-tenant-key-iv"])
+	if err != nil {
+		return nil, fmt.Errorf("corrupt wrapped-tenant-key-iv metadata: %w", err)
+	}
+	kekVersion, err := strconv.Atoi(storageMetadata["tenant-key-kek-version"])
+	if err != nil {
+		return nil, fmt.Errorf("corrupt tenant-key-kek-version metadata: %w", err)
+	}
+	kekKey, err := om.kekProvider.KEKByVersion(kekVersion)
+	if err != nil {
+		return nil, err
+	}
+	key, err := om.encryptor.Decrypt(&encryption.EncryptedData{Data: wrapped, IV: iv}, kekKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap tenant key: %w", err)
+	}
+	return key, nil
+}
+
 // isProtectedStorageMetadataKey reports whether a sidecar metadata key is
 // system-managed and must never be overridden through UpdateObjectMetadata.
 // Losing the encryption entries would make the object undecryptable.
 func isProtectedStorageMetadataKey(key string) bool {
+	if isEnvelopeMetadataKey(key) {
+		return true
+	}
 	switch key {
 	case "encrypted", "original-size", "original-etag",
-		"wrapped-dek", "wrapped-dek-iv", "kek-version",
 		"size", "etag", "last_modified",
 		"x-amz-server-side-encryption", "x-amz-server-side-encryption-algorithm":
 		return true
@@ -2845,11 +3501,23 @@ func isProtectedStorageMetadataKey(key string) bool {
 	return false
 }
 
+// isEnvelopeMetadataKey reports whether a sidecar key describes how the DEK
+// is wrapped; re-wrapping replaces all of them.
+func isEnvelopeMetadataKey(key string) bool {
+	switch key {
+	case "wrapped-dek", "wrapped-dek-iv", "kek-version",
+		"tenant-key-id", "tenant-key-version", "wrapped-tenant-key",
+		"wrapped-tenant-key-iv", "tenant-key-kek-version", "tenant-key-fingerprint":
+		return true
+	}
+	return false
+}
+
 // storeEncryptedObject envelope-encrypts and stores an object: a fresh DEK
 // encrypts the data stream; the DEK, wrapped with the current KEK, is stored
 // in the sidecar metadata alongside the original (plaintext) size and ETag.
-func (om *objectManager) storeEncryptedObject(ctx context.Context, objectPath, tempPath string, storageMetadata map[string]string, originalSize int64, originalETag string) error {
-	dek, envelopeMeta, err := om.newEnvelope()
+func (om *objectManager) storeEncryptedObject(ctx context.Context, bucket, objectPath string, plaintext io.Reader, storageMetadata map[string]string, originalSize int64, originalETag string) error {
+	dek, envelopeMeta, err := om.newEnvelope(bucket)
 	if err != nil {
 		return err
 	}
@@ -2864,20 +3532,13 @@ func (om *objectManager) storeEncryptedObject(ctx context.Context, objectPath, t
 		storageMetadata[k] = v
 	}
 
-	// Open temp file for reading and encrypt while streaming to storage
-	tempFileRead, err := os.Open(tempPath)
-	if err != nil {
-		return fmt.Errorf("failed to open temp file for encryption: %w", err)
-	}
-	defer tempFileRead.Close()
-
 	// Create a pipe for streaming encryption
 	pipeReader, pipeWriter := io.Pipe()
 
 	// Encrypt in background goroutine
 	go func() {
 		defer pipeWriter.Close()
-		if _, err := om.encryptor.EncryptStream(tempFileRead, pipeWriter, dek); err != nil {
+		if _, err := om.encryptor.EncryptStream(plaintext, pipeWriter, dek); err != nil {
 			logrus.WithError(err).Error("Failed to encrypt object during upload")
 			pipeWriter.CloseWithError(fmt.Errorf("encryption failed: %w", err))
 		}
@@ -2892,21 +3553,14 @@ func (om *objectManager) storeEncryptedObject(ctx context.Context, objectPath, t
 }
 
 // storeUnencryptedObject stores an object without encryption
-func (om *objectManager) storeUnencryptedObject(ctx context.Context, objectPath, tempPath string, storageMetadata map[string]string, originalSize int64, originalETag string) error {
+func (om *objectManager) storeUnencryptedObject(ctx context.Context, objectPath string, plaintext io.Reader, storageMetadata map[string]string, originalSize int64, originalETag string) error {
 	// Use original size and ETag directly
 	storageMetadata["size"] = fmt.Sprintf("%d", originalSize)
 	storageMetadata["etag"] = originalETag
 	// Do NOT set "encrypted" = "true"
 
-	// Open temp file for reading
-	tempFileRead, err := os.Open(tempPath)
-	if err != nil {
-		return fmt.Errorf("failed to open temp file: %w", err)
-	}
-	defer tempFileRead.Close()
-
 	// Store unencrypted data directly
-	if err := om.storage.Put(ctx, objectPath, tempFileRead, storageMetadata); err != nil {
+	if err := om.storage.Put(ctx, objectPath, plaintext, storageMetadata); err != nil {
 		return fmt.Errorf("failed to store object: %w", err)
 	}
 
@@ -3027,11 +3681,14 @@ func (om *objectManager) validateAndCalculatePartsSize(ctx context.Context, uplo
 	if len(parts) == 0 {
 		return 0, fmt.Errorf("no parts provided")
 	}
+	if maxParts := om.multipartMaxParts(); len(parts) > maxParts {
+		return 0, fmt.Errorf("%w: %d parts given, at most %d allowed", ErrTooManyParts, len(parts), maxParts)
+	}
 
-	// Validate requested order, part metadata, ETags, and storage presence.
+	// Validate requested order, part metadata, ETags, sizes and storage presence.
 	var totalSize int64
 	previousPartNumber := 0
-	for _, part := range parts {
+	for i, part := range parts {
 		if part.PartNumber <= previousPartNumber {
 			return 0, ErrInvalidPartOrder
 		}
@@ -3047,6 +3704,16 @@ func (om *objectManager) validateAndCalculatePartsSize(ctx context.Context, uplo
 		if part.ETag != "" && strings.Trim(part.ETag, "\"") != strings.Trim(partMeta.ETag, "\"") {
 			return 0, ErrInvalidPart
 		}
+		// A part checksum named in the request must be the one recorded
+		// when the part was uploaded
+		if part.ChecksumValue != "" &&
+			(!strings.EqualFold(part.ChecksumAlgorithm, partMeta.ChecksumAlgorithm) || part.ChecksumValue != partMeta.ChecksumValue) {
+			return 0, ErrInvalidPart
+		}
+		if minSize := om.config.MultipartMinPartSize; i < len(parts)-1 && partMeta.Size < minSize {
+			return 0, fmt.Errorf("%w: part %d is %d bytes, every part but the last must be at least %d bytes",
+				ErrPartTooSmall, part.PartNumber, partMeta.Size, minSize)
+		}
 
 		partPath := om.getMultipartPartPath(uploadID, part.PartNumber)
 		exists, err := om.storage.Exists(ctx, partPath)
@@ -3062,6 +3729,15 @@ func (om *objectManager) validateAndCalculatePartsSize(ctx context.Context, uplo
 	return totalSize, nil
 }
 
+// multipartMaxParts returns the configured part limit, MaxMultipartParts
+// when unset.
+func (om *objectManager) multipartMaxParts() int {
+	if n := om.config.MultipartMaxParts; n > 0 && n < MaxMultipartParts {
+		return n
+	}
+	return MaxMultipartParts
+}
+
 // computeMultipartETag computes the S3-spec ETag for a completed multipart upload.
 // Format: hex(MD5(MD5(part1) || MD5(part2) || ... || MD5(partN)))-N
 // where each MD5(partX) is the raw 16-byte binary digest of the part data.
@@ -3088,6 +3764,28 @@ func (om *objectManager) computeMultipartETag(ctx context.Context, uploadID stri
 	return fmt.Sprintf("%s-%d", hex.EncodeToString(digest[:]), len(parts)), nil
 }
 
+// computeMultipartChecksum returns the composite checksum of a completed
+// upload created with a checksum algorithm. An upload without one, or with a
+// part stored without a checksum (by a server version that didn't record
+// them), gets none.
+func (om *objectManager) computeMultipartChecksum(ctx context.Context, uploadID, algo string, parts []Part) (string, error) {
+	if algo == "" {
+		return "", nil
+	}
+	checksums := make([]string, 0, len(parts))
+	for _, part := range parts {
+		partMeta, err := om.metadataStore.GetPart(ctx, uploadID, part.PartNumber)
+		if err != nil {
+			return "", fmt.Errorf("failed to get part %d metadata for checksum: %w", part.PartNumber, err)
+		}
+		if partMeta.ChecksumAlgorithm != algo || partMeta.ChecksumValue == "" {
+			return "", nil
+		}
+		checksums = append(checksums, partMeta.ChecksumValue)
+	}
+	return CompositeChecksum(algo, checksums)
+}
+
 // checkMultipartQuotaBeforeComplete validates tenant quota before combining parts
 func (om *objectManager) checkMultipartQuotaBeforeComplete(ctx context.Context, bucket, uploadID string, totalSize int64, existingObj *metadata.ObjectMetadata, versioningEnabled bool) error {
 	var sizeIncrement int64
@@ -3179,58 +3877,55 @@ func (om *objectManager) checkBucketStorageQuota(ctx context.Context, bucket str
 
 // storeEncryptedMultipartObject envelope-encrypts and stores the assembled
 // multipart object (fresh DEK wrapped by the current KEK, same format as
-// storeEncryptedObject).
-func (om *objectManager) storeEncryptedMultipartObject(ctx context.Context, objectPath, tempPath string, uploadID string, multipart *MultipartUpload, originalSize int64, originalETag string) error {
-	dek, envelopeMeta, err := om.newEnvelope()
+// storeEncryptedObject). plaintext must yield exactly originalSize bytes;
+// originalETag is the multipart ETag the object is served with.
+func (om *objectManager) storeEncryptedMultipartObject(ctx context.Context, objectPath string, plaintext io.Reader, uploadID string, multipart *MultipartUpload, originalSize int64, originalETag string) error {
+	dek, envelopeMeta, err := om.newEnvelope(multipart.Bucket)
 	if err != nil {
 		return err
 	}
 
-	tempFileRead, err := os.Open(tempPath)
-	if err != nil {
-		return fmt.Errorf("failed to open temp file for encryption: %w", err)
-	}
-	defer tempFileRead.Close()
-
 	// Create a pipe for streaming encryption
 	pipeReader, pipeWriter := io.Pipe()
+	defer pipeReader.Close()
 
 	// Encrypt in background goroutine
+	var plaintextSize int64
 	go func() {
 		defer pipeWriter.Close()
-		if _, err := om.encryptor.EncryptStream(tempFileRead, pipeWriter, dek); err != nil {
+		counted := io.TeeReader(plaintext, &countingWriter{w: io.Discard, n: &plaintextSize})
+		if _, err := om.encryptor.EncryptStream(counted, pipeWriter, dek); err != nil {
 			logrus.WithError(err).Error("Failed to encrypt multipart object")
 			pipeWriter.CloseWithError(fmt.Errorf("encryption failed: %w", err))
 		}
 	}()
 
-	encryptedContentType := multipart.Metadata["content-type"]
-	if encryptedContentType == "" {
-		encryptedContentType = "application/octet-stream"
+	// Copy any user metadata from multipart upload
+	encryptionMetadata := make(map[string]string, len(multipart.Metadata)+9)
+	for k, v := range multipart.Metadata {
+		encryptionMetadata[k] = v
 	}
-	// Store encryption markers in storage metadata
-	encryptionMetadata := map[string]string{
-		"original-size":                          fmt.Sprintf("%d", originalSize),
-		"original-etag":                          originalETag,
-		"encrypted":                              "true",
-		"x-amz-server-side-encryption":           "AES256",
-		"x-amz-server-side-encryption-algorithm": "AES-256-GCM-STREAM",
-		"content-type":                           encryptedContentType,
+	if encryptionMetadata["content-type"] == "" {
+		encryptionMetadata["content-type"] = "application/octet-stream"
 	}
+	// Store encryption markers in storage metadata
+	encryptionMetadata["original-size"] = fmt.Sprintf("%d", originalSize)
+	encryptionMetadata["original-etag"] = originalETag
+	encryptionMetadata["encrypted"] = "true"
+	encryptionMetadata["x-amz-server-side-encryption"] = "AES256"
+	encryptionMetadata["x-amz-server-side-encryption-algorithm"] = "AES-256-GCM-STREAM"
 	for k, v := range envelopeMeta {
 		encryptionMetadata[k] = v
 	}
 
-	// Copy any user metadata from multipart upload
-	for k, v := range multipart.Metadata {
-		if k != "content-type" {
-			encryptionMetadata[k] = v
-		}
-	}
-
 	if err := om.storage.Put(ctx, objectPath, pipeReader, encryptionMetadata); err != nil {
 		return fmt.Errorf("failed to store encrypted multipart object: %w", err)
 	}
+	// Put has drained the pipe, so the encrypting goroutine is done counting
+	if plaintextSize != originalSize {
+		_ = om.storage.Delete(ctx, objectPath)
+		return fmt.Errorf("assembled multipart object is %d bytes, parts add up to %d", plaintextSize, originalSize)
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"uploadID": uploadID,
@@ -3241,6 +3936,116 @@ func (om *objectManager) storeEncryptedMultipartObject(ctx context.Context, obje
 	return nil
 }
 
+// storeEncryptedPart envelope-encrypts one multipart part into partPath and
+// returns the size and MD5 (hex) of its plaintext.
+func (om *objectManager) storeEncryptedPart(ctx context.Context, bucket, partPath string, data io.Reader, partMetadata map[string]string) (int64, string, error) {
+	dek, envelopeMeta, err := om.newEnvelope(bucket)
+	if err != nil {
+		return 0, "", err
+	}
+	partMetadata["encrypted"] = "true"
+	partMetadata["x-amz-server-side-encryption"] = "AES256"
+	partMetadata["x-amz-server-side-encryption-algorithm"] = "AES-256-GCM-STREAM"
+	for k, v := range envelopeMeta {
+		partMetadata[k] = v
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeReader.Close()
+
+	var size int64
+	hasher := md5.New()
+	go func() {
+		defer pipeWriter.Close()
+		plain := io.TeeReader(data, &countingWriter{w: hasher, n: &size})
+		if _, err := om.encryptor.EncryptStream(plain, pipeWriter, dek); err != nil {
+			pipeWriter.CloseWithError(fmt.Errorf("encryption failed: %w", err))
+		}
+	}()
+
+	if err := om.storage.Put(ctx, partPath, pipeReader, partMetadata); err != nil {
+		return 0, "", fmt.Errorf("failed to store part: %w", err)
+	}
+	return size, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// multipartPartsReader streams the plaintext of a multipart upload's parts in
+// order, opening (and decrypting) one part at a time. Parts stored before
+// parts were encrypted are read as they are.
+type multipartPartsReader struct {
+	om       *objectManager
+	ctx      context.Context
+	uploadID string
+	parts    []Part
+	next     int
+	current  io.ReadCloser
+}
+
+func (om *objectManager) newMultipartPartsReader(ctx context.Context, uploadID string, parts []Part) *multipartPartsReader {
+	return &multipartPartsReader{om: om, ctx: ctx, uploadID: uploadID, parts: parts}
+}
+
+func (r *multipartPartsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.next == len(r.parts) {
+				return 0, io.EOF
+			}
+			part, err := r.om.openPartPlaintext(r.ctx, r.uploadID, r.parts[r.next].PartNumber)
+			if err != nil {
+				return 0, err
+			}
+			r.current = part
+			r.next++
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *multipartPartsReader) Close() error {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+	return nil
+}
+
+// openPartPlaintext opens a stored multipart part for reading its plaintext.
+func (om *objectManager) openPartPlaintext(ctx context.Context, uploadID string, partNumber int) (io.ReadCloser, error) {
+	reader, partMetadata, err := om.storage.Get(ctx, om.getMultipartPartPath(uploadID, partNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read part %d: %w", partNumber, err)
+	}
+	if partMetadata["encrypted"] != "true" {
+		return reader, nil
+	}
+
+	key, err := om.decryptionKeyFor(partMetadata)
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("failed to resolve decryption key for part %d: %w", partNumber, err)
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		defer reader.Close()
+		if err := om.encryptor.DecryptStream(reader, pipeWriter, key, decryptMetaFor(partMetadata)); err != nil {
+			pipeWriter.CloseWithError(fmt.Errorf("failed to decrypt part %d: %w", partNumber, err))
+			return
+		}
+		pipeWriter.Close()
+	}()
+	return pipeReader, nil
+}
+
 // updateMetricsAndCleanupMultipart updates bucket/tenant metrics and cleans up multipart data
 func (om *objectManager) updateMetricsAndCleanupMultipart(ctx context.Context, bucket, uploadID string, originalSize int64, isNewObject bool, existingObj *metadata.ObjectMetadata, parts []Part, versioningEnabledArg ...bool) {
 	versioningEnabled := len(versioningEnabledArg) > 0 && versioningEnabledArg[0]
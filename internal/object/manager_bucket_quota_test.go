@@ -0,0 +1,69 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createQuotaBucket creates a bucket whose cached usage already stands at
+// objectCount objects / totalSize bytes; the manager under test has no bucket
+// manager wired in, so usage is seeded rather than accumulated.
+func createQuotaBucket(t *testing.T, metaStore metadata.Store, name string, objectCount, totalSize int64, quota *metadata.BucketQuota) {
+	t.Helper()
+	require.NoError(t, metaStore.CreateBucket(context.Background(), &metadata.BucketMetadata{
+		Name:        name,
+		OwnerID:     "user-1",
+		ObjectCount: objectCount,
+		TotalSize:   totalSize,
+		Quota:       quota,
+	}))
+}
+
+func TestBucketObjectCountQuota_RejectsNewObjectOnlyInFullBucket(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createQuotaBucket(t, metaStore, "capped", 3, 0, &metadata.BucketQuota{MaxObjectCount: 3})
+	createQuotaBucket(t, metaStore, "roomy", 3, 0, nil)
+
+	_, err := om.PutObject(ctx, "capped", "fourth.txt", bytes.NewReader([]byte("x")), http.Header{})
+	assert.ErrorIs(t, err, ErrBucketQuotaExceeded)
+
+	_, err = om.PutObject(ctx, "roomy", "fourth.txt", bytes.NewReader([]byte("x")), http.Header{})
+	assert.NoError(t, err, "a bucket without a quota must stay writable")
+}
+
+func TestBucketQuota_UploadPart(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+
+	t.Run("object count cap rejects the first part", func(t *testing.T) {
+		createQuotaBucket(t, metaStore, "count-capped", 1, 0, &metadata.BucketQuota{MaxObjectCount: 1})
+		upload, err := om.CreateMultipartUpload(ctx, "count-capped", "big.bin", http.Header{})
+		require.NoError(t, err)
+
+		_, err = om.UploadPart(ctx, upload.UploadID, 1, bytes.NewReader([]byte("part")))
+		assert.ErrorIs(t, err, ErrBucketQuotaExceeded)
+	})
+
+	t.Run("part larger than remaining size is rejected", func(t *testing.T) {
+		createQuotaBucket(t, metaStore, "size-capped", 0, 90, &metadata.BucketQuota{MaxSizeBytes: 100})
+		upload, err := om.CreateMultipartUpload(ctx, "size-capped", "big.bin", http.Header{})
+		require.NoError(t, err)
+
+		_, err = om.UploadPart(ctx, upload.UploadID, 1, bytes.NewReader(bytes.Repeat([]byte("x"), 50)))
+		assert.ErrorIs(t, err, ErrBucketQuotaExceeded)
+
+		parts, err := om.ListParts(ctx, upload.UploadID)
+		require.NoError(t, err)
+		assert.Empty(t, parts, "rejected part must not be recorded")
+
+		_, err = om.UploadPart(ctx, upload.UploadID, 1, bytes.NewReader(bytes.Repeat([]byte("x"), 5)))
+		assert.NoError(t, err)
+	})
+}
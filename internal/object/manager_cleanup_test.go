@@ -26,6 +26,12 @@ func (n *nonFSBackend) Get(_ context.Context, _ string) (io.ReadCloser, map[stri
 }
 func (n *nonFSBackend) Delete(_ context.Context, _ string) error         { return nil }
 func (n *nonFSBackend) Exists(_ context.Context, _ string) (bool, error) { return false, nil }
+func (n *nonFSBackend) Stat(_ context.Context, _ string) (*storage.ObjectInfo, error) {
+	return nil, nil
+}
+func (n *nonFSBackend) GetRange(_ context.Context, _ string, _, _ int64) (io.ReadCloser, error) {
+	return nil, nil
+}
 func (n *nonFSBackend) List(_ context.Context, _ string, _ bool) ([]storage.ObjectInfo, error) {
 	return nil, nil
 }
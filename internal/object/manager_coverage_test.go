@@ -3,6 +3,7 @@ package object
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
@@ -333,8 +334,8 @@ func TestCompleteMultipartUpload_Success(t *testing.T) {
 }
 
 // TestCompleteMultipartUpload_ConcurrentSameID verifies that two concurrent
-// CompleteMultipartUpload calls for the same uploadID do not race: the second
-// waits for the first and gets the same result without causing a 500 error.
+// CompleteMultipartUpload calls for the same uploadID do not race: exactly one
+// commits the object and the other gets ErrInvalidUploadID (NoSuchUpload).
 func TestCompleteMultipartUpload_ConcurrentSameID(t *testing.T) {
 	ctx := context.Background()
 	om, metaStore, cleanup := setupTestManagerWithStore(t)
@@ -372,16 +373,26 @@ func TestCompleteMultipartUpload_ConcurrentSameID(t *testing.T) {
 		}()
 	}
 
-	r1 := <-ch
-	r2 := <-ch
+	results := []result{<-ch, <-ch}
+	var succeeded, noSuchUpload int
+	for _, r := range results {
+		switch {
+		case r.err == nil:
+			succeeded++
+			assert.Equal(t, int64(len(part1Data)), r.obj.Size)
+		case errors.Is(r.err, ErrInvalidUploadID):
+			noSuchUpload++
+		default:
+			t.Errorf("unexpected completion error: %v", r.err)
+		}
+	}
+	assert.Equal(t, 1, succeeded, "exactly one completion must win")
+	assert.Equal(t, 1, noSuchUpload, "the other completion must see the upload as gone")
 
-	// Both must succeed (no 500) — one waits for the other
-	assert.NoError(t, r1.err, "first concurrent completion must not error")
-	assert.NoError(t, r2.err, "second concurrent completion must not error")
-	assert.NotNil(t, r1.obj)
-	assert.NotNil(t, r2.obj)
-	// Both should return the same ETag
-	assert.Equal(t, r1.obj.ETag, r2.obj.ETag, "both completions should return the same ETag")
+	// The upload ID is gone for good
+	_, err = om.CompleteMultipartUpload(ctx, upload.UploadID, parts)
+	assert.ErrorIs(t, err, ErrInvalidUploadID)
+	assert.Equal(t, string(part1Data), readObject(t, om, bucket, key))
 }
 
 // TestListParts_Success tests listing parts of multipart upload
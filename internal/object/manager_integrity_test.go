@@ -148,7 +148,7 @@ func TestVerifyObjectIntegrity_Corrupted(t *testing.T) {
 	assert.NotEqual(t, result.StoredETag, result.ComputedETag)
 }
 
-func TestGetObjectMetadata_MissingPhysicalDataReturnsNotFound(t *testing.T) {
+func TestGetObjectMetadata_MissingPhysicalData(t *testing.T) {
 	om, metaStore, cleanup := setupTestManagerWithStore(t)
 	defer cleanup()
 	ctx := context.Background()
@@ -168,7 +168,7 @@ func TestGetObjectMetadata_MissingPhysicalDataReturnsNotFound(t *testing.T) {
 	}))
 
 	_, _, err := om.GetObject(ctx, bucket, key)
-	assert.Equal(t, ErrObjectNotFound, err)
+	assert.Equal(t, ErrObjectDataMissing, err)
 	_, err = om.GetObjectMetadata(ctx, bucket, key)
 	assert.Equal(t, ErrObjectNotFound, err)
 
@@ -176,6 +176,7 @@ func TestGetObjectMetadata_MissingPhysicalDataReturnsNotFound(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, IntegrityMissing, result.Status)
 	assert.Equal(t, "object data not found on storage", result.Reason)
+	assert.NotNil(t, result.DataMissingAt)
 }
 
 func TestGetObjectMetadata_VersionedEntryDoesNotFallbackToPlainPath(t *testing.T) {
@@ -219,7 +220,7 @@ func TestGetObjectMetadata_VersionedEntryDoesNotFallbackToPlainPath(t *testing.T
 	))
 
 	_, _, err := om.GetObject(ctx, bucket, key)
-	assert.Equal(t, ErrObjectNotFound, err)
+	assert.Equal(t, ErrObjectDataMissing, err)
 	_, err = om.GetObjectMetadata(ctx, bucket, key)
 	assert.Equal(t, ErrObjectNotFound, err)
 }
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
 	"path/filepath"
 	"testing"
 
@@ -127,12 +126,7 @@ func TestStoreEncryptedObject(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	// Create a temporary file with test content
-	tempDir := t.TempDir()
-	tempPath := filepath.Join(tempDir, "temp-object.txt")
 	testContent := []byte("sensitive data to encrypt")
-	err = os.WriteFile(tempPath, testContent, 0644)
-	require.NoError(t, err)
 
 	// Prepare parameters for storeEncryptedObject
 	objectPath := filepath.Join(bucket, key)
@@ -143,7 +137,7 @@ func TestStoreEncryptedObject(t *testing.T) {
 	originalETag := "test-etag-12345"
 
 	// Call storeEncryptedObject
-	err = om.storeEncryptedObject(ctx, objectPath, tempPath, storageMetadata, originalSize, originalETag)
+	err = om.storeEncryptedObject(ctx, bucket, objectPath, bytes.NewReader(testContent), storageMetadata, originalSize, originalETag)
 
 	// Should either succeed (if encryption configured) or fail gracefully
 	if err != nil {
@@ -184,21 +178,16 @@ func TestStoreEncryptedMultipartObject(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, upload)
 
-	// Create a temporary file with multipart content
-	tempDir := t.TempDir()
-	tempPath := filepath.Join(tempDir, "temp-multipart.txt")
 	testContent := []byte("multipart content for encryption")
-	err = os.WriteFile(tempPath, testContent, 0644)
-	require.NoError(t, err)
 
 	// Prepare parameters for storeEncryptedMultipartObject
-	// Signature: storeEncryptedMultipartObject(ctx, objectPath, tempPath, uploadID, multipart, originalSize, originalETag)
+	// Signature: storeEncryptedMultipartObject(ctx, objectPath, plaintext, uploadID, multipart, originalSize, originalETag)
 	objectPath := filepath.Join(bucket, key)
 	originalSize := int64(len(testContent))
 	originalETag := "multipart-etag-12345"
 
 	// Call storeEncryptedMultipartObject
-	err = om.storeEncryptedMultipartObject(ctx, objectPath, tempPath, upload.UploadID, upload, originalSize, originalETag)
+	err = om.storeEncryptedMultipartObject(ctx, objectPath, bytes.NewReader(testContent), upload.UploadID, upload, originalSize, originalETag)
 
 	// Should either succeed (if encryption configured) or fail gracefully
 	if err != nil {
@@ -0,0 +1,100 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadPart_PartNumberRange(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "parts", OwnerID: "user-1"}))
+
+	upload, err := om.CreateMultipartUpload(ctx, "parts", "big.bin", http.Header{})
+	require.NoError(t, err)
+
+	for _, n := range []int{0, -1, MaxMultipartParts + 1} {
+		_, err := om.UploadPart(ctx, upload.UploadID, n, bytes.NewReader([]byte("data")))
+		assert.ErrorIs(t, err, ErrInvalidPartNumber, "part number %d", n)
+	}
+
+	_, err = om.UploadPart(ctx, upload.UploadID, MaxMultipartParts, bytes.NewReader([]byte("data")))
+	assert.NoError(t, err)
+}
+
+func TestUploadPart_ConfiguredPartCap(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	om.config.MultipartMaxParts = 3
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "parts", OwnerID: "user-1"}))
+
+	upload, err := om.CreateMultipartUpload(ctx, "parts", "big.bin", http.Header{})
+	require.NoError(t, err)
+
+	var parts []Part
+	for n := 1; n <= 3; n++ {
+		part, err := om.UploadPart(ctx, upload.UploadID, n, bytes.NewReader([]byte("data")))
+		require.NoError(t, err)
+		parts = append(parts, Part{PartNumber: n, ETag: part.ETag})
+	}
+
+	_, err = om.UploadPart(ctx, upload.UploadID, 4, bytes.NewReader([]byte("data")))
+	assert.ErrorIs(t, err, ErrTooManyParts)
+
+	// Re-uploading an existing part number is still allowed
+	_, err = om.UploadPart(ctx, upload.UploadID, 2, bytes.NewReader([]byte("data")))
+	require.NoError(t, err)
+
+	_, err = om.CompleteMultipartUpload(ctx, upload.UploadID, append(parts, Part{PartNumber: 4}))
+	assert.ErrorIs(t, err, ErrTooManyParts)
+
+	_, err = om.CompleteMultipartUpload(ctx, upload.UploadID, parts)
+	assert.NoError(t, err)
+}
+
+func TestCompleteMultipartUpload_MinPartSize(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	om.config.MultipartMinPartSize = 8
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "parts", OwnerID: "user-1"}))
+
+	upload := func(sizes ...int) (string, []Part) {
+		u, err := om.CreateMultipartUpload(ctx, "parts", "big.bin", http.Header{})
+		require.NoError(t, err)
+		var parts []Part
+		for i, size := range sizes {
+			part, err := om.UploadPart(ctx, u.UploadID, i+1, bytes.NewReader(bytes.Repeat([]byte("x"), size)))
+			require.NoError(t, err)
+			parts = append(parts, Part{PartNumber: i + 1, ETag: part.ETag})
+		}
+		return u.UploadID, parts
+	}
+
+	t.Run("small middle part rejected", func(t *testing.T) {
+		uploadID, parts := upload(8, 4, 8)
+		_, err := om.CompleteMultipartUpload(ctx, uploadID, parts)
+		assert.ErrorIs(t, err, ErrPartTooSmall)
+	})
+
+	t.Run("small last part allowed", func(t *testing.T) {
+		uploadID, parts := upload(8, 8, 1)
+		obj, err := om.CompleteMultipartUpload(ctx, uploadID, parts)
+		require.NoError(t, err)
+		assert.Equal(t, int64(17), obj.Size)
+	})
+
+	t.Run("single small part allowed", func(t *testing.T) {
+		uploadID, parts := upload(3)
+		_, err := om.CompleteMultipartUpload(ctx, uploadID, parts)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,111 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createNoOverwriteBucket(t *testing.T, metaStore metadata.Store, name string) {
+	t.Helper()
+	require.NoError(t, metaStore.CreateBucket(context.Background(), &metadata.BucketMetadata{
+		Name:        name,
+		OwnerID:     "user-1",
+		NoOverwrite: true,
+	}))
+}
+
+func TestNoOverwrite_SecondPutRejected(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createNoOverwriteBucket(t, metaStore, "ingest")
+
+	original := []byte("first write")
+	_, err := om.PutObject(ctx, "ingest", "batch-001.json", bytes.NewReader(original), http.Header{})
+	require.NoError(t, err)
+
+	_, err = om.PutObject(ctx, "ingest", "batch-001.json", bytes.NewReader([]byte("second write")), http.Header{})
+	assert.ErrorIs(t, err, ErrObjectExists)
+
+	_, reader, err := om.GetObject(ctx, "ingest", "batch-001.json")
+	require.NoError(t, err)
+	defer reader.Close()
+	readBack, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, original, readBack, "the original object must be left untouched")
+
+	// A new key is accepted
+	_, err = om.PutObject(ctx, "ingest", "batch-002.json", bytes.NewReader([]byte("another")), http.Header{})
+	assert.NoError(t, err)
+}
+
+func TestNoOverwrite_KeyCanBeRewrittenAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createNoOverwriteBucket(t, metaStore, "ingest")
+
+	_, err := om.PutObject(ctx, "ingest", "k", bytes.NewReader([]byte("v1")), http.Header{})
+	require.NoError(t, err)
+	_, err = om.DeleteObject(ctx, "ingest", "k", false)
+	require.NoError(t, err)
+
+	_, err = om.PutObject(ctx, "ingest", "k", bytes.NewReader([]byte("v2")), http.Header{})
+	assert.NoError(t, err)
+}
+
+func TestNoOverwrite_ConcurrentPutsOnlyOneWins(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createNoOverwriteBucket(t, metaStore, "ingest")
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = om.PutObject(ctx, "ingest", "race.bin", bytes.NewReader([]byte{byte(i)}), http.Header{})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, ErrObjectExists)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+}
+
+func TestNoOverwrite_MultipartCompletionOntoExistingKeyRejected(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createNoOverwriteBucket(t, metaStore, "ingest")
+
+	upload, err := om.CreateMultipartUpload(ctx, "ingest", "archive.tar", http.Header{})
+	require.NoError(t, err)
+	part, err := om.UploadPart(ctx, upload.UploadID, 1, bytes.NewReader([]byte("part data")))
+	require.NoError(t, err)
+
+	// The key is taken by a plain PUT while the upload is in progress
+	_, err = om.PutObject(ctx, "ingest", "archive.tar", bytes.NewReader([]byte("winner")), http.Header{})
+	require.NoError(t, err)
+
+	_, err = om.CompleteMultipartUpload(ctx, upload.UploadID, []Part{*part})
+	assert.ErrorIs(t, err, ErrObjectExists)
+
+	obj, err := om.GetObjectMetadata(ctx, "ingest", "archive.tar")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("winner")), obj.Size)
+}
@@ -0,0 +1,115 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createWriteLockBucket(t *testing.T, metaStore metadata.Store, name string, days int) {
+	t.Helper()
+	require.NoError(t, metaStore.CreateBucket(context.Background(), &metadata.BucketMetadata{
+		Name:                 name,
+		OwnerID:              "user-1",
+		DefaultWriteLockDays: days,
+	}))
+}
+
+func TestDefaultWriteLock_UploadGetsGovernanceRetention(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createWriteLockBucket(t, metaStore, "app-logs", 7)
+
+	before := time.Now()
+	_, err := om.PutObject(ctx, "app-logs", "2026/10/16/app.log", bytes.NewReader([]byte("line 1\n")), http.Header{})
+	require.NoError(t, err)
+
+	retention, err := om.GetObjectRetention(ctx, "app-logs", "2026/10/16/app.log")
+	require.NoError(t, err)
+	assert.Equal(t, RetentionModeGovernance, retention.Mode)
+	assert.WithinDuration(t, before.AddDate(0, 0, 7), retention.RetainUntilDate, time.Minute)
+}
+
+func TestDefaultWriteLock_DeleteWithinWindowRejected(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createWriteLockBucket(t, metaStore, "app-logs", 30)
+
+	_, err := om.PutObject(ctx, "app-logs", "app.log", bytes.NewReader([]byte("line 1\n")), http.Header{})
+	require.NoError(t, err)
+
+	_, err = om.DeleteObject(ctx, "app-logs", "app.log", false)
+	var retErr *RetentionError
+	require.True(t, errors.As(err, &retErr), "expected retention error, got %v", err)
+	assert.Equal(t, "GOVERNANCE", retErr.Mode)
+
+	_, reader, err := om.GetObject(ctx, "app-logs", "app.log")
+	require.NoError(t, err, "object must survive the rejected delete")
+	reader.Close()
+
+	// GOVERNANCE retention can still be bypassed by an authorised caller
+	_, err = om.DeleteObject(ctx, "app-logs", "app.log", true)
+	require.NoError(t, err)
+}
+
+func TestDefaultWriteLock_OverwriteWithinWindowRejected(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createWriteLockBucket(t, metaStore, "app-logs", 30)
+
+	original := []byte("append-only record")
+	_, err := om.PutObject(ctx, "app-logs", "app.log", bytes.NewReader(original), http.Header{})
+	require.NoError(t, err)
+
+	_, err = om.PutObject(ctx, "app-logs", "app.log", bytes.NewReader([]byte("tampered")), http.Header{})
+	var retErr *RetentionError
+	require.True(t, errors.As(err, &retErr), "expected retention error, got %v", err)
+
+	_, reader, err := om.GetObject(ctx, "app-logs", "app.log")
+	require.NoError(t, err)
+	defer reader.Close()
+	readBack, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, original, readBack)
+}
+
+func TestDefaultWriteLock_DisabledLeavesObjectsUnlocked(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createWriteLockBucket(t, metaStore, "scratch", 0)
+
+	_, err := om.PutObject(ctx, "scratch", "tmp.txt", bytes.NewReader([]byte("x")), http.Header{})
+	require.NoError(t, err)
+
+	_, err = om.GetObjectRetention(ctx, "scratch", "tmp.txt")
+	assert.ErrorIs(t, err, ErrNoRetentionConfiguration)
+
+	_, err = om.DeleteObject(ctx, "scratch", "tmp.txt", false)
+	assert.NoError(t, err)
+}
+
+func TestDefaultWriteLock_MultipartUploadGetsRetention(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	createWriteLockBucket(t, metaStore, "app-logs", 7)
+
+	upload, err := om.CreateMultipartUpload(ctx, "app-logs", "archive.tar", http.Header{})
+	require.NoError(t, err)
+	part, err := om.UploadPart(ctx, upload.UploadID, 1, bytes.NewReader([]byte("single small part")))
+	require.NoError(t, err)
+	_, err = om.CompleteMultipartUpload(ctx, upload.UploadID, []Part{*part})
+	require.NoError(t, err)
+
+	retention, err := om.GetObjectRetention(ctx, "app-logs", "archive.tar")
+	require.NoError(t, err)
+	assert.Equal(t, RetentionModeGovernance, retention.Mode)
+	assert.True(t, retention.RetainUntilDate.After(time.Now().AddDate(0, 0, 6)))
+}
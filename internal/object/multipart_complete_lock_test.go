@@ -0,0 +1,98 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompleteMultipartUpload_ConcurrentPut checks that a non-versioned
+// completion assembles its object without the key lock and only takes it to
+// move the object into place, racing a PUT of the same key.
+func TestCompleteMultipartUpload_ConcurrentPut(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	storageBackend, err := storage.NewBackend(config.StorageConfig{
+		Backend: "filesystem",
+		Root:    tmpDir + "/objects",
+	})
+	require.NoError(t, err)
+
+	metadataStore, err := metadata.NewPebbleStore(metadata.PebbleOptions{
+		DataDir: tmpDir + "/metadata",
+		Logger:  logrus.StandardLogger(),
+	})
+	require.NoError(t, err)
+	defer metadataStore.Close()
+
+	om := NewManager(storageBackend, metadataStore, config.StorageConfig{}).(*objectManager)
+
+	ctx := context.Background()
+	bucket := "test-bucket"
+	key := "contended.bin"
+	multipartBody := bytes.Repeat([]byte("m"), 64*1024)
+	putBody := bytes.Repeat([]byte("p"), len(multipartBody))
+
+	upload, err := om.CreateMultipartUpload(ctx, bucket, key, nil)
+	require.NoError(t, err)
+	part, err := om.UploadPart(ctx, upload.UploadID, 1, bytes.NewReader(multipartBody))
+	require.NoError(t, err)
+
+	// Hold the key lock: the completion must still get its object assembled
+	unlock := om.lockKey(bucket, key)
+	completed := make(chan error, 1)
+	go func() {
+		_, err := om.CompleteMultipartUpload(ctx, upload.UploadID, []Part{{PartNumber: 1, ETag: part.ETag}})
+		completed <- err
+	}()
+
+	partsDir := tmpDir + "/objects/.maxiofs/multipart/parts/" + upload.UploadID
+	assembled := func() bool {
+		matches, _ := filepath.Glob(partsDir + "/assembled-*.metadata")
+		return len(matches) > 0
+	}
+	require.Eventually(t, assembled, 5*time.Second, 10*time.Millisecond,
+		"the object must be assembled while another writer holds the key lock")
+	select {
+	case err := <-completed:
+		unlock()
+		t.Fatalf("completion returned before it could take the key lock: %v", err)
+	default:
+	}
+
+	var wg sync.WaitGroup
+	var putErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, putErr = om.PutObject(ctx, bucket, key, bytes.NewReader(putBody), http.Header{})
+	}()
+	unlock()
+	wg.Wait()
+	require.NoError(t, putErr)
+	require.NoError(t, <-completed)
+
+	// Whichever writer won, the key reads back whole and decrypts
+	_, reader, err := om.GetObject(ctx, bucket, key)
+	require.NoError(t, err)
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(got, multipartBody) || bytes.Equal(got, putBody),
+		"object must hold the data of one of the two writers")
+
+	matches, _ := filepath.Glob(partsDir + "/assembled-*")
+	assert.Empty(t, matches, "no assembly file may be left behind")
+}
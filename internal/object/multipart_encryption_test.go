@@ -0,0 +1,95 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartUploadEncryptsPartsAndReportsSSE(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+
+	bucket := "sse-multipart-bucket"
+	key := "encrypted.bin"
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{
+		Name:     bucket,
+		TenantID: "tenant-1",
+		OwnerID:  "user-1",
+	}))
+
+	upload, err := om.CreateMultipartUpload(ctx, bucket, key, http.Header{
+		"Content-Type":                 []string{"application/x-test"},
+		"X-Amz-Server-Side-Encryption": []string{"AES256"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "AES256", upload.Metadata["x-amz-server-side-encryption"])
+
+	bodies := [][]byte{
+		bytes.Repeat([]byte("first part plaintext "), 4096),
+		bytes.Repeat([]byte("second part plaintext "), 512),
+	}
+	var parts []Part
+	var digests []byte
+	for i, body := range bodies {
+		part, err := om.UploadPart(ctx, upload.UploadID, i+1, bytes.NewReader(body))
+		require.NoError(t, err)
+
+		sum := md5.Sum(body)
+		assert.Equal(t, hex.EncodeToString(sum[:]), part.ETag, "part ETag is the MD5 of the plaintext")
+		assert.Equal(t, int64(len(body)), part.Size)
+		assert.Equal(t, "AES256", part.SSEAlgorithm)
+		digests = append(digests, sum[:]...)
+
+		// The part is encrypted at rest
+		reader, partMeta, err := om.storage.Get(ctx, om.getMultipartPartPath(upload.UploadID, i+1))
+		require.NoError(t, err)
+		raw, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "true", partMeta["encrypted"])
+		assert.False(t, bytes.Contains(raw, body[:64]), "part %d stored as plaintext", i+1)
+
+		parts = append(parts, Part{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+
+	obj, err := om.CompleteMultipartUpload(ctx, upload.UploadID, parts)
+	require.NoError(t, err)
+	assert.Equal(t, "AES256", obj.SSEAlgorithm)
+	assert.NotContains(t, obj.Metadata, "x-amz-server-side-encryption", "SSE status is not user metadata")
+
+	// The ETag is still the AWS multipart scheme over the plaintext parts
+	combined := md5.Sum(digests)
+	assert.Equal(t, hex.EncodeToString(combined[:])+"-2", obj.ETag)
+
+	head, err := om.GetObjectMetadata(ctx, bucket, key)
+	require.NoError(t, err)
+	assert.Equal(t, "AES256", head.SSEAlgorithm)
+	assert.Equal(t, obj.ETag, head.ETag)
+
+	got, reader, err := om.GetObject(ctx, bucket, key)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "AES256", got.SSEAlgorithm)
+	assert.Equal(t, append(append([]byte{}, bodies[0]...), bodies[1]...), data)
+
+	raw, storageMeta, err := om.storage.Get(ctx, om.getObjectPath(bucket, key))
+	require.NoError(t, err)
+	rawData, err := io.ReadAll(raw)
+	raw.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "true", storageMeta["encrypted"])
+	assert.Equal(t, "application/x-test", storageMeta["content-type"])
+	assert.False(t, bytes.Contains(rawData, bodies[0][:64]), "completed object stored as plaintext")
+}
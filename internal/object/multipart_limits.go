@@ -0,0 +1,40 @@
+package object
+
+import (
+	"context"
+	"fmt"
+)
+
+// checkMultipartUploadLimits returns ErrTooManyMultipartUploads when the
+// bucket, or the tenant owning it, already has as many multipart uploads in
+// progress as storage.max_multipart_uploads_per_bucket or _per_tenant
+// allow. Completed and aborted uploads, including those the stale-upload
+// cleanup aborts, no longer count. The caller holds multipartLimitMu.
+func (om *objectManager) checkMultipartUploadLimits(ctx context.Context, bucket string) error {
+	// Replica writes follow the primary, which applied the caps
+	if isBypassQuotaEnforcement(ctx) {
+		return nil
+	}
+
+	if limit := om.config.MaxMultipartUploadsPerBucket; limit > 0 {
+		count, err := om.metadataStore.CountMultipartUploads(ctx, "", bucket)
+		if err != nil {
+			return fmt.Errorf("failed to count multipart uploads: %w", err)
+		}
+		if count >= limit {
+			return fmt.Errorf("%w: the bucket has %d (limit %d)", ErrTooManyMultipartUploads, count, limit)
+		}
+	}
+
+	tenantID, _ := om.parseBucketPath(bucket)
+	if limit := om.config.MaxMultipartUploadsPerTenant; limit > 0 && tenantID != "" {
+		count, err := om.metadataStore.CountMultipartUploads(ctx, tenantID, "")
+		if err != nil {
+			return fmt.Errorf("failed to count multipart uploads: %w", err)
+		}
+		if count >= limit {
+			return fmt.Errorf("%w: the tenant has %d (limit %d)", ErrTooManyMultipartUploads, count, limit)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartUploadLimits(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	om.config.MaxMultipartUploadsPerBucket = 2
+	om.config.MaxMultipartUploadsPerTenant = 3
+	for _, name := range []string{"a", "b"} {
+		require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: name, TenantID: "tenant-1", OwnerID: "user-1"}))
+	}
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "global", OwnerID: "user-1"}))
+
+	create := func(bucket, key string) (*MultipartUpload, error) {
+		return om.CreateMultipartUpload(ctx, bucket, key, http.Header{})
+	}
+
+	first, err := create("tenant-1/a", "one.bin")
+	require.NoError(t, err)
+	second, err := create("tenant-1/a", "two.bin")
+	require.NoError(t, err)
+	_, err = create("tenant-1/a", "three.bin")
+	assert.ErrorIs(t, err, ErrTooManyMultipartUploads, "bucket cap")
+
+	_, err = create("tenant-1/b", "one.bin")
+	require.NoError(t, err)
+	_, err = create("tenant-1/b", "two.bin")
+	assert.ErrorIs(t, err, ErrTooManyMultipartUploads, "tenant cap")
+
+	// Global buckets only have the bucket cap
+	for _, key := range []string{"one.bin", "two.bin"} {
+		_, err = create("global", key)
+		require.NoError(t, err)
+	}
+	_, err = create("global", "three.bin")
+	assert.ErrorIs(t, err, ErrTooManyMultipartUploads)
+
+	// Aborting frees a slot of the tenant
+	require.NoError(t, om.AbortMultipartUpload(ctx, first.UploadID))
+	_, err = create("tenant-1/b", "two.bin")
+	require.NoError(t, err)
+	_, err = create("tenant-1/a", "three.bin")
+	assert.ErrorIs(t, err, ErrTooManyMultipartUploads)
+
+	// So does completing
+	part, err := om.UploadPart(ctx, second.UploadID, 1, bytes.NewReader([]byte("data")))
+	require.NoError(t, err)
+	_, err = om.CompleteMultipartUpload(ctx, second.UploadID, []Part{*part})
+	require.NoError(t, err)
+	_, err = create("tenant-1/a", "three.bin")
+	require.NoError(t, err)
+
+	count, err := metaStore.CountMultipartUploads(ctx, "tenant-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	count, err = metaStore.CountMultipartUploads(ctx, "", "tenant-1/a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
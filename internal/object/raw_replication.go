@@ -34,9 +34,9 @@ type RawObjectAccessor interface {
 }
 
 // CanReplicateRaw: the object must be envelope-encrypted and its wrapping KEK
-// version must be cluster-shared. Plaintext and legacy/local-KEK objects fall
-// back to the decrypt/re-encrypt replication path (they converge to envelope
-// via the rotation worker).
+// version must be cluster-shared. Plaintext, legacy/local-KEK and tenant-key
+// objects (tenant keys are per node) fall back to the decrypt/re-encrypt
+// replication path (they converge to envelope via the rotation worker).
 func (om *objectManager) CanReplicateRaw(sidecar map[string]string) bool {
 	if sidecar["encrypted"] != "true" || sidecar["wrapped-dek"] == "" {
 		return false
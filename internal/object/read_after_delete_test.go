@@ -0,0 +1,124 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hammerGets reads key in a loop from several goroutines until stop is
+// closed, while the caller deletes. Each read must return either before or
+// after ("" meaning not found), and once any read has returned after — or
+// the delete has been acknowledged (deleted) — no read started later may
+// return before again.
+func hammerGets(t *testing.T, om *objectManager, bucket, key, before, after string, deleted *atomic.Bool, stop <-chan struct{}) *sync.WaitGroup {
+	t.Helper()
+	var observedAfter atomic.Bool
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				mustBeAfter := deleted.Load() || observedAfter.Load()
+				_, reader, err := om.GetObject(context.Background(), bucket, key)
+				got := ""
+				if err == nil {
+					body, readErr := io.ReadAll(reader)
+					reader.Close()
+					if !assert.NoError(t, readErr) {
+						return
+					}
+					got = string(body)
+				} else if !assert.ErrorIs(t, err, ErrObjectNotFound) {
+					return
+				}
+				switch {
+				case got == after:
+					observedAfter.Store(true)
+				case got != before:
+					t.Errorf("GET during delete returned %q, want %q or %q", got, before, after)
+					return
+				case mustBeAfter:
+					t.Errorf("GET returned the deleted state %q after the delete was observed", got)
+					return
+				}
+			}
+		}()
+	}
+	return &wg
+}
+
+func TestReadAfterDelete_Unversioned(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "read-after-delete", TenantID: "tenant-1", OwnerID: "user-1"}))
+	bucket := "tenant-1/read-after-delete"
+
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("doc-%d.txt", i)
+		content := strings.Repeat("x", 64<<10)
+		_, err := om.PutObject(ctx, bucket, key, strings.NewReader(content), http.Header{})
+		require.NoError(t, err)
+
+		var deleted atomic.Bool
+		stop := make(chan struct{})
+		wg := hammerGets(t, om, bucket, key, content, "", &deleted, stop)
+		_, err = om.DeleteObject(ctx, bucket, key, false)
+		require.NoError(t, err)
+		deleted.Store(true)
+		for j := 0; j < 20; j++ {
+			_, _, err := om.GetObject(ctx, bucket, key)
+			require.ErrorIs(t, err, ErrObjectNotFound)
+		}
+		close(stop)
+		wg.Wait()
+	}
+}
+
+func TestReadAfterDelete_LatestVersion(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{
+		Name: "read-after-version-delete", TenantID: "tenant-1", OwnerID: "user-1",
+		Versioning: &metadata.VersioningMetadata{Enabled: true, Status: "Enabled"},
+	}))
+	bucket := "tenant-1/read-after-version-delete"
+
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("doc-%d.txt", i)
+		_, err := om.PutObject(ctx, bucket, key, strings.NewReader("previous version"), http.Header{})
+		require.NoError(t, err)
+		latest, err := om.PutObject(ctx, bucket, key, strings.NewReader("latest version"), http.Header{})
+		require.NoError(t, err)
+
+		// Deleting the latest version by ID: reads switch from it to the
+		// previous version and never see a 404 in between.
+		var deleted atomic.Bool
+		stop := make(chan struct{})
+		wg := hammerGets(t, om, bucket, key, "latest version", "previous version", &deleted, stop)
+		_, err = om.DeleteObject(ctx, bucket, key, false, latest.VersionID)
+		require.NoError(t, err)
+		deleted.Store(true)
+		_, _, err = om.GetObject(ctx, bucket, key, latest.VersionID)
+		require.ErrorIs(t, err, ErrObjectNotFound)
+		close(stop)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,142 @@
+package object
+
+import (
+	"context"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/sirupsen/logrus"
+)
+
+// Read-repair modes (storage.read_repair): what GetObject does when the
+// metadata store and the stored data disagree.
+const (
+	ReadRepairOff     = "off"     // missing data reads as a missing object
+	ReadRepairFlag    = "flag"    // missing data is an error and flags the object
+	ReadRepairRebuild = "rebuild" // "flag", and data without metadata gets it back
+)
+
+// readRepairMode returns the configured read-repair mode, "flag" by default.
+func (om *objectManager) readRepairMode() string {
+	if om.config.ReadRepair == "" {
+		return ReadRepairFlag
+	}
+	return om.config.ReadRepair
+}
+
+// objectDataMissing answers a read of metaObj whose data was not found at
+// objectPath. Unless read repair is off, the object is flagged with
+// DataMissingAt, which the integrity report lists, and ErrObjectDataMissing
+// is returned.
+//
+// The flag is written under the key lock, and only while the metadata is
+// unchanged and the data still missing: DeleteObject removes the metadata
+// before the data, so a read racing it must not bring the metadata back.
+func (om *objectManager) objectDataMissing(ctx context.Context, bucket, key string, metaObj *metadata.ObjectMetadata, objectPath string) error {
+	if om.readRepairMode() == ReadRepairOff {
+		return ErrObjectNotFound
+	}
+
+	if !keyLockHeld(ctx) {
+		defer om.lockKey(bucket, key)()
+	}
+	current, err := om.metadataStore.GetObject(ctx, bucket, key, metaObj.VersionID)
+	if err != nil || isMetadataDeleteMarker(current) {
+		// Deleted meanwhile
+		return ErrObjectNotFound
+	}
+	if current.ETag != metaObj.ETag || !current.LastModified.Equal(metaObj.LastModified) {
+		// Overwritten meanwhile; the caller may read the new object
+		return ErrObjectDataMissing
+	}
+	if exists, err := om.storage.Exists(ctx, objectPath); err != nil || exists {
+		return ErrObjectDataMissing
+	}
+
+	if current.DataMissingAt == nil {
+		now := time.Now().UTC()
+		current.DataMissingAt = &now
+		if err := om.metadataStore.PutObject(ctx, current); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"bucket": bucket,
+				"key":    key,
+			}).Warn("Failed to flag object with missing data")
+		} else {
+			logrus.WithFields(logrus.Fields{
+				"bucket":    bucket,
+				"key":       key,
+				"versionID": metaObj.VersionID,
+				"path":      objectPath,
+			}).Error("Object data is missing from storage; object flagged for the integrity report")
+		}
+	}
+	return ErrObjectDataMissing
+}
+
+// clearDataMissing removes the DataMissingAt flag of an object whose data
+// can be read again, e.g. after it was restored from a backup.
+func (om *objectManager) clearDataMissing(ctx context.Context, bucket, key string, metaObj *metadata.ObjectMetadata) {
+	if !keyLockHeld(ctx) {
+		defer om.lockKey(bucket, key)()
+	}
+	current, err := om.metadataStore.GetObject(ctx, bucket, key, metaObj.VersionID)
+	if err != nil || current.DataMissingAt == nil || current.ETag != metaObj.ETag {
+		return
+	}
+	current.DataMissingAt = nil
+	if err := om.metadataStore.PutObject(ctx, current); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"bucket": bucket,
+			"key":    key,
+		}).Warn("Failed to clear missing-data flag of object")
+	}
+}
+
+// rebuildObjectMetadata stores minimal metadata for an object that has data
+// but no metadata, built by GetObject from what the storage backend keeps
+// with the data. User metadata, tags, ACL and lock settings are not kept
+// there and are lost. The object counts toward the bucket's usage again.
+//
+// Like objectDataMissing, the rebuild happens under the key lock and only
+// while the data at objectPath is still there: a DeleteObject that finished
+// after GetObject read the file must not bring the object back. Keys with
+// version history, delete markers included, are left alone; their latest
+// entry can't be told from the stored data.
+func (om *objectManager) rebuildObjectMetadata(ctx context.Context, bucket, key string, obj *Object, objectPath string) {
+	if !keyLockHeld(ctx) {
+		defer om.lockKey(bucket, key)()
+	}
+	if _, err := om.metadataStore.GetObject(ctx, bucket, key); err != metadata.ErrObjectNotFound {
+		// Written meanwhile, or the store can't tell
+		return
+	}
+	if versions, err := om.metadataStore.GetObjectVersions(ctx, bucket, key); err != nil || len(versions) > 0 {
+		return
+	}
+	if exists, err := om.storage.Exists(ctx, objectPath); err != nil || !exists {
+		// Deleted meanwhile
+		return
+	}
+
+	metaObj := toMetadataObject(obj)
+	metaObj.IsLatest = true
+	if err := om.metadataStore.PutObject(ctx, metaObj); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"bucket": bucket,
+			"key":    key,
+		}).Warn("Failed to rebuild metadata of object")
+		return
+	}
+
+	if om.bucketManager != nil {
+		tenantID, bucketName := om.parseBucketPath(bucket)
+		if err := om.bucketManager.IncrementObjectCount(ctx, tenantID, bucketName, obj.Size); err != nil {
+			logrus.WithError(err).Warn("Failed to count object with rebuilt metadata")
+		}
+	}
+	logrus.WithFields(logrus.Fields{
+		"bucket": bucket,
+		"key":    key,
+		"size":   obj.Size,
+	}).Warn("Rebuilt missing metadata of object from its stored data")
+}
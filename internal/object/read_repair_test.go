@@ -0,0 +1,182 @@
+package object
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRepair_MissingData(t *testing.T) {
+	ctx := context.Background()
+	bucket := "repair-bucket"
+
+	t.Run("flag", func(t *testing.T) {
+		om, metaStore, cleanup := setupTestManagerWithStore(t)
+		defer cleanup()
+
+		putTestObject(t, om, metaStore, bucket, "lost.bin", []byte("gone soon"))
+		require.NoError(t, om.storage.Delete(ctx, om.getObjectPath(bucket, "lost.bin")))
+
+		_, _, err := om.GetObject(ctx, bucket, "lost.bin")
+		assert.ErrorIs(t, err, ErrObjectDataMissing)
+
+		meta, err := metaStore.GetObject(ctx, bucket, "lost.bin")
+		require.NoError(t, err)
+		require.NotNil(t, meta.DataMissingAt, "object must be flagged")
+
+		report, err := om.VerifyBucketIntegrity(ctx, bucket, "", "", 100)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Corrupted)
+		require.Len(t, report.Issues, 1)
+		assert.Equal(t, IntegrityMissing, report.Issues[0].Status)
+		assert.NotNil(t, report.Issues[0].DataMissingAt)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		om, metaStore, cleanup := setupTestManagerWithStore(t)
+		defer cleanup()
+		om.config.ReadRepair = ReadRepairOff
+
+		putTestObject(t, om, metaStore, bucket, "lost.bin", []byte("gone soon"))
+		require.NoError(t, om.storage.Delete(ctx, om.getObjectPath(bucket, "lost.bin")))
+
+		_, _, err := om.GetObject(ctx, bucket, "lost.bin")
+		assert.Equal(t, ErrObjectNotFound, err)
+
+		meta, err := metaStore.GetObject(ctx, bucket, "lost.bin")
+		require.NoError(t, err)
+		assert.Nil(t, meta.DataMissingAt)
+	})
+}
+
+func TestReadRepair_MissingMetadata(t *testing.T) {
+	ctx := context.Background()
+	bucket := "repair-bucket"
+	body := []byte("orphaned data")
+
+	for _, mode := range []string{ReadRepairFlag, ReadRepairRebuild} {
+		t.Run(mode, func(t *testing.T) {
+			om, metaStore, cleanup := setupTestManagerWithStore(t)
+			defer cleanup()
+			om.config.ReadRepair = mode
+
+			etag := putTestObject(t, om, metaStore, bucket, "orphan.txt", body)
+			require.NoError(t, metaStore.DeleteObject(ctx, bucket, "orphan.txt"))
+
+			obj, reader, err := om.GetObject(ctx, bucket, "orphan.txt")
+			require.NoError(t, err)
+			data, err := io.ReadAll(reader)
+			reader.Close()
+			require.NoError(t, err)
+			assert.Equal(t, body, data)
+			assert.Equal(t, etag, obj.ETag)
+
+			meta, err := metaStore.GetObject(ctx, bucket, "orphan.txt")
+			if mode != ReadRepairRebuild {
+				assert.Equal(t, metadata.ErrObjectNotFound, err)
+				return
+			}
+			require.NoError(t, err, "metadata must be rebuilt")
+			assert.Equal(t, etag, meta.ETag)
+			assert.Equal(t, int64(len(body)), meta.Size)
+			assert.Equal(t, "AES256", meta.SSEAlgorithm)
+
+			result, err := om.VerifyObjectIntegrity(ctx, bucket, "orphan.txt")
+			require.NoError(t, err)
+			assert.Equal(t, IntegrityOK, result.Status)
+		})
+	}
+}
+
+func TestGetObjectMetadata_MissingMetadata(t *testing.T) {
+	ctx := context.Background()
+	bucket := "repair-bucket"
+	body := []byte("orphaned data")
+
+	for _, mode := range []string{ReadRepairFlag, ReadRepairRebuild} {
+		t.Run(mode, func(t *testing.T) {
+			om, metaStore, cleanup := setupTestManagerWithStore(t)
+			defer cleanup()
+			om.config.ReadRepair = mode
+
+			etag := putTestObject(t, om, metaStore, bucket, "orphan.txt", body)
+			require.NoError(t, metaStore.DeleteObject(ctx, bucket, "orphan.txt"))
+
+			// HEAD agrees with GET: the data on disk is the object
+			obj, err := om.GetObjectMetadata(ctx, bucket, "orphan.txt")
+			require.NoError(t, err)
+			assert.Equal(t, etag, obj.ETag)
+			assert.Equal(t, int64(len(body)), obj.Size)
+
+			_, err = metaStore.GetObject(ctx, bucket, "orphan.txt")
+			if mode == ReadRepairRebuild {
+				assert.NoError(t, err, "metadata must be rebuilt")
+			} else {
+				assert.Equal(t, metadata.ErrObjectNotFound, err)
+			}
+
+			// The conditional-write probe stays metadata-only
+			exists, err := om.ObjectExists(ctx, bucket, "orphan.txt")
+			require.NoError(t, err)
+			assert.Equal(t, mode == ReadRepairRebuild, exists)
+
+			_, err = om.GetObjectMetadata(ctx, bucket, "never-written.txt")
+			assert.Equal(t, ErrObjectNotFound, err)
+		})
+	}
+}
+
+func TestReadRepair_RebuildSkipsDeletedObjects(t *testing.T) {
+	ctx := context.Background()
+	bucket := "repair-bucket"
+
+	t.Run("data removed before the rebuild", func(t *testing.T) {
+		om, metaStore, cleanup := setupTestManagerWithStore(t)
+		defer cleanup()
+		om.config.ReadRepair = ReadRepairRebuild
+
+		putTestObject(t, om, metaStore, bucket, "raced.txt", []byte("deleted meanwhile"))
+		obj, err := om.GetObjectMetadata(ctx, bucket, "raced.txt")
+		require.NoError(t, err)
+
+		// A DeleteObject finished between the read and the rebuild
+		objectPath := om.getObjectPath(bucket, "raced.txt")
+		require.NoError(t, metaStore.DeleteObject(ctx, bucket, "raced.txt"))
+		require.NoError(t, om.storage.Delete(ctx, objectPath))
+
+		om.rebuildObjectMetadata(ctx, bucket, "raced.txt", obj, objectPath)
+		_, err = metaStore.GetObject(ctx, bucket, "raced.txt")
+		assert.Equal(t, metadata.ErrObjectNotFound, err)
+	})
+
+	t.Run("key with a delete marker", func(t *testing.T) {
+		om, metaStore, cleanup := setupTestManagerWithStore(t)
+		defer cleanup()
+		om.config.ReadRepair = ReadRepairRebuild
+
+		putTestObject(t, om, metaStore, bucket, "tombstoned.txt", []byte("still on disk"))
+		require.NoError(t, metaStore.DeleteObject(ctx, bucket, "tombstoned.txt"))
+		marker := &metadata.ObjectMetadata{Bucket: bucket, Key: "tombstoned.txt", VersionID: "marker-1", IsLatest: true}
+		require.NoError(t, metaStore.PutObjectVersion(ctx, marker, &metadata.ObjectVersion{
+			VersionID: "marker-1",
+			IsLatest:  true,
+			Key:       "tombstoned.txt",
+		}))
+		_ = metaStore.DeleteObject(ctx, bucket, "tombstoned.txt")
+
+		_, reader, err := om.GetObject(ctx, bucket, "tombstoned.txt")
+		if err == nil {
+			reader.Close()
+		}
+		_, err = metaStore.GetObject(ctx, bucket, "tombstoned.txt")
+		assert.Equal(t, metadata.ErrObjectNotFound, err, "a tombstoned key must not be rebuilt")
+		versions, err := metaStore.GetObjectVersions(ctx, bucket, "tombstoned.txt")
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		assert.Equal(t, "marker-1", versions[0].VersionID)
+	})
+}
@@ -0,0 +1,224 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// RenameObject moves the current object at srcKey to dstKey within bucket
+// and returns it at its new key. Content headers, user metadata, tags and
+// ACL move with it.
+//
+// In a versioned bucket a rename is a copy followed by a delete, as S3
+// clients would do it: dstKey gets a new version holding the source's
+// current content, and srcKey gets a delete marker. Versions stay with the
+// key they were written under, so srcKey's history, including the version
+// that was renamed, remains readable by version ID below the marker, and
+// dstKey's earlier versions remain below the new one. Without versioning,
+// dstKey is replaced and srcKey removed.
+//
+// The data is copied first; the metadata of both keys then changes in one
+// transaction, so the object is never visible under both keys or under
+// neither. Objects under legal hold or unexpired retention are not renamed,
+// and the write to dstKey obeys the bucket's write-once mode and the
+// retention of an object it would replace. Folder markers are not renamed:
+// the objects under them would stay behind.
+func (om *objectManager) RenameObject(ctx context.Context, bucket, srcKey, dstKey string) (*Object, error) {
+	for _, key := range []string{srcKey, dstKey} {
+		if err := om.validateObjectName(key); err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(key, "/") {
+			return nil, ErrInvalidObjectName
+		}
+	}
+	if srcKey == dstKey {
+		return nil, ErrInvalidObjectName
+	}
+	if err := om.checkReservedKey(ctx, dstKey); err != nil {
+		return nil, err
+	}
+	if err := om.checkFreeSpace(); err != nil {
+		return nil, err
+	}
+
+	defer om.lockKeyPair(bucket, srcKey, dstKey)()
+	ctx = withKeyLockHeld(ctx)
+
+	src, err := om.currentObjectMetadata(ctx, bucket, srcKey)
+	if err != nil {
+		return nil, err
+	}
+	if src.LegalHold {
+		return nil, ErrObjectUnderLegalHold
+	}
+	if err := om.checkOverwriteRetention(ctx, bucket, srcKey); err != nil {
+		return nil, err
+	}
+
+	versioningEnabled := om.isBucketVersioningEnabled(ctx, bucket)
+	if om.isBucketNoOverwrite(ctx, bucket) {
+		if err := om.checkNoOverwrite(ctx, bucket, dstKey); err != nil {
+			return nil, err
+		}
+	}
+	if !versioningEnabled {
+		if err := om.checkOverwriteRetention(ctx, bucket, dstKey); err != nil {
+			return nil, err
+		}
+	}
+	existingDst, err := om.metadataStore.GetObject(ctx, bucket, dstKey)
+	if err != nil && err != metadata.ErrObjectNotFound {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+
+	tenantID, bucketName := om.parseBucketPath(bucket)
+	// Only a versioned rename grows the bucket: the source version stays
+	if versioningEnabled && !isBypassQuotaEnforcement(ctx) {
+		if om.authManager != nil && tenantID != "" && src.Size > 0 {
+			if err := om.authManager.CheckTenantStorageQuota(ctx, tenantID, src.Size); err != nil {
+				return nil, fmt.Errorf("storage quota exceeded: %w", err)
+			}
+		}
+		if err := om.checkBucketStorageQuota(ctx, bucket, src.Size, false); err != nil {
+			return nil, err
+		}
+	}
+
+	var versionID, dstPath string
+	if versioningEnabled {
+		versionID = generateVersionID()
+		dstPath = om.getVersionedObjectPath(bucket, dstKey, versionID)
+	} else {
+		dstPath = om.getObjectPath(bucket, dstKey)
+	}
+
+	_, reader, err := om.GetObject(ctx, bucket, srcKey)
+	if err != nil {
+		return nil, err
+	}
+	storageMetadata, _ := om.extractMetadataFromHeaders(appendHeaders(src))
+	err = om.storeEncryptedObject(ctx, bucket, dstPath, reader, storageMetadata, src.Size, src.ETag)
+	reader.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	dst := *src
+	dst.Key = dstKey
+	dst.VersionID = versionID
+	dst.IsLatest = versioningEnabled
+	dst.LastModified = time.Time{}
+	dst.Retention = nil
+	dst.DataMissingAt = nil
+	dst.UploadID = ""
+	// The new object gets the bucket's default locks like any other write
+	locked := &Object{Key: dstKey, Bucket: bucket}
+	if err := om.applyDefaultRetention(ctx, locked); err != nil {
+		logrus.WithError(err).Debug("Failed to apply default retention")
+	}
+	om.applyDefaultWriteLock(ctx, locked)
+	dst.Retention = toMetadataObject(locked).Retention
+
+	var srcMarker *metadata.ObjectMetadata
+	if versioningEnabled {
+		markerTime := time.Now()
+		srcMarker = &metadata.ObjectMetadata{
+			Bucket:       bucket,
+			Key:          srcKey,
+			VersionID:    generateVersionID(),
+			LastModified: markerTime,
+			StorageClass: StorageClassStandard,
+			CreatedAt:    markerTime,
+			UpdatedAt:    markerTime,
+		}
+	}
+
+	if err := om.metadataStore.RenameObject(ctx, srcKey, &dst, srcMarker); err != nil {
+		// A new version's file is unreferenced; a non-versioned dstKey file
+		// may be the only copy of the data and is kept, as PutObject does
+		if versioningEnabled {
+			if delErr := om.storage.Delete(ctx, dstPath); delErr != nil && delErr != storage.ErrObjectNotFound {
+				logrus.WithError(delErr).WithField("path", dstPath).Warn("Failed to remove data of failed rename")
+			}
+		}
+		if err == metadata.ErrObjectNotFound {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to rename object metadata: %w", err)
+	}
+
+	if !versioningEnabled {
+		srcPath := om.getObjectPath(bucket, srcKey)
+		if err := om.storage.Delete(ctx, srcPath); err != nil && err != storage.ErrObjectNotFound {
+			logrus.WithError(err).WithField("path", srcPath).Warn("Failed to delete renamed object's old data; file is now an orphan")
+		}
+		om.cleanupEmptyDirectories(bucket, srcKey)
+	}
+	if dst.ACL != nil && om.aclManager != nil {
+		aclData := om.convertToACLManagerType(fromMetadataObject(&dst).ACL)
+		if err := om.aclManager.SetObjectACL(ctx, tenantID, bucketName, dstKey, aclData); err != nil {
+			logrus.WithError(err).WithField("key", dstKey).Warn("Failed to copy ACL of renamed object")
+		}
+	}
+	om.ensureImplicitFolders(ctx, bucket, dstKey)
+
+	// Account for the write at dstKey, then for srcKey going away: hidden
+	// behind a marker (its bytes stay), or deleted
+	om.updateBucketMetricsAfterPut(ctx, tenantID, bucketName, bucket, dstKey, src.Size, versioningEnabled, existingDst)
+	om.updateTenantQuotaAfterPut(ctx, tenantID, dstKey, src.Size, versioningEnabled, existingDst)
+	var freed int64
+	if !versioningEnabled {
+		freed = src.Size
+	}
+	if om.bucketManager != nil {
+		if err := om.bucketManager.DecrementObjectCount(ctx, tenantID, bucketName, freed); err != nil {
+			logrus.WithError(err).WithField("key", srcKey).Warn("Failed to update bucket metrics after rename")
+		}
+	}
+	if om.authManager != nil && tenantID != "" && freed > 0 {
+		if err := om.authManager.DecrementTenantStorage(ctx, tenantID, freed); err != nil {
+			logrus.WithError(err).WithField("tenant_id", tenantID).Warn("Failed to decrement tenant storage quota")
+		}
+	}
+
+	if versioningEnabled {
+		om.trimExcessVersions(ctx, bucket, dstKey)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"bucket":    bucket,
+		"from":      srcKey,
+		"to":        dstKey,
+		"versionID": versionID,
+	}).Info("Object renamed")
+
+	return fromMetadataObject(&dst), nil
+}
+
+// lockKeyPair locks the shards of two keys of bucket and returns the unlock
+// function. Shards are locked in index order, so two renames between the
+// same keys in opposite directions can't deadlock; keys sharing a shard lock
+// it once.
+func (om *objectManager) lockKeyPair(bucket, key1, key2 string) func() {
+	a, b := keyShard(bucket, key1), keyShard(bucket, key2)
+	if a == b {
+		om.muShards[a].Lock()
+		return om.muShards[a].Unlock
+	}
+	if a > b {
+		a, b = b, a
+	}
+	om.muShards[a].Lock()
+	om.muShards[b].Lock()
+	return func() {
+		om.muShards[b].Unlock()
+		om.muShards[a].Unlock()
+	}
+}
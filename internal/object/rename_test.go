@@ -0,0 +1,155 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readObjectVersion(t *testing.T, om *objectManager, bucket, key string, versionID ...string) string {
+	t.Helper()
+	_, reader, err := om.GetObject(context.Background(), bucket, key, versionID...)
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRenameObject_VersionedKeepsHistoryWithEachKey(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{
+		Name:       "docs",
+		TenantID:   "tenant-1",
+		OwnerID:    "user-1",
+		Versioning: &metadata.VersioningMetadata{Enabled: true, Status: "Enabled"},
+	}))
+	bucket := "tenant-1/docs"
+
+	srcV1, err := om.PutObject(ctx, bucket, "draft.txt", bytes.NewReader([]byte("one")), http.Header{})
+	require.NoError(t, err)
+	srcV2, err := om.PutObject(ctx, bucket, "draft.txt", bytes.NewReader([]byte("two")), http.Header{
+		"Content-Type":    []string{"text/plain"},
+		"X-Amz-Meta-Team": []string{"docs"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, om.SetObjectTagging(ctx, bucket, "draft.txt", &TagSet{Tags: []Tag{{Key: "stage", Value: "review"}}}))
+	dstOld, err := om.PutObject(ctx, bucket, "final.txt", bytes.NewReader([]byte("previous final")), http.Header{})
+	require.NoError(t, err)
+
+	renamed, err := om.RenameObject(ctx, bucket, "draft.txt", "final.txt")
+	require.NoError(t, err)
+	require.NotEmpty(t, renamed.VersionID)
+	assert.NotContains(t, []string{srcV1.VersionID, srcV2.VersionID, dstOld.VersionID}, renamed.VersionID)
+
+	// Source: a delete marker on top of its untouched versions
+	_, err = om.GetObjectMetadata(ctx, bucket, "draft.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	srcVersions, err := om.GetObjectVersions(ctx, bucket, "draft.txt")
+	require.NoError(t, err)
+	require.Len(t, srcVersions, 3)
+	byID := make(map[string]ObjectVersion)
+	for _, v := range srcVersions {
+		byID[v.VersionID] = v
+	}
+	require.Contains(t, byID, srcV1.VersionID)
+	require.Contains(t, byID, srcV2.VersionID)
+	for _, v := range srcVersions {
+		if v.VersionID == srcV1.VersionID || v.VersionID == srcV2.VersionID {
+			assert.False(t, v.IsLatest)
+			assert.False(t, v.IsDeleteMarker)
+		} else {
+			assert.True(t, v.IsLatest)
+			assert.True(t, v.IsDeleteMarker)
+		}
+	}
+	assert.Equal(t, "two", readObjectVersion(t, om, bucket, "draft.txt", srcV2.VersionID))
+	assert.Equal(t, "one", readObjectVersion(t, om, bucket, "draft.txt", srcV1.VersionID))
+
+	// Destination: the renamed content as a new latest version above its own history
+	dstVersions, err := om.GetObjectVersions(ctx, bucket, "final.txt")
+	require.NoError(t, err)
+	require.Len(t, dstVersions, 2)
+	for _, v := range dstVersions {
+		assert.Equal(t, v.VersionID == renamed.VersionID, v.IsLatest)
+		assert.Contains(t, []string{renamed.VersionID, dstOld.VersionID}, v.VersionID)
+	}
+	assert.Equal(t, "two", readObjectVersion(t, om, bucket, "final.txt"))
+	assert.Equal(t, "previous final", readObjectVersion(t, om, bucket, "final.txt", dstOld.VersionID))
+
+	current, err := om.GetObjectMetadata(ctx, bucket, "final.txt")
+	require.NoError(t, err)
+	assert.Equal(t, renamed.VersionID, current.VersionID)
+	assert.Equal(t, srcV2.ETag, current.ETag)
+	assert.Equal(t, "text/plain", current.ContentType)
+	assert.Equal(t, "docs", current.Metadata["team"])
+	tags, err := om.GetObjectTagging(ctx, bucket, "final.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []Tag{{Key: "stage", Value: "review"}}, tags.Tags)
+
+	tagged, err := metaStore.ListObjectsByTags(ctx, bucket, map[string]string{"stage": "review"})
+	require.NoError(t, err)
+	require.Len(t, tagged, 1)
+	assert.Equal(t, "final.txt", tagged[0].Key)
+}
+
+func TestRenameObject_Unversioned(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "plain", OwnerID: "user-1"}))
+
+	_, err := om.PutObject(ctx, "plain", "old/name.txt", bytes.NewReader([]byte("payload")), http.Header{})
+	require.NoError(t, err)
+
+	renamed, err := om.RenameObject(ctx, "plain", "old/name.txt", "new/name.txt")
+	require.NoError(t, err)
+	assert.Empty(t, renamed.VersionID)
+
+	_, err = om.GetObjectMetadata(ctx, "plain", "old/name.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	exists, err := om.storage.Exists(ctx, om.getObjectPath("plain", "old/name.txt"))
+	require.NoError(t, err)
+	assert.False(t, exists, "source data should be removed")
+	assert.Equal(t, "payload", readObjectVersion(t, om, "plain", "new/name.txt"))
+
+	_, err = om.RenameObject(ctx, "plain", "old/name.txt", "other.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestRenameObject_Refused(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "vault", OwnerID: "user-1", NoOverwrite: true}))
+
+	_, err := om.PutObject(ctx, "vault", "a.txt", bytes.NewReader([]byte("a")), http.Header{})
+	require.NoError(t, err)
+	_, err = om.PutObject(ctx, "vault", "b.txt", bytes.NewReader([]byte("b")), http.Header{})
+	require.NoError(t, err)
+
+	_, err = om.RenameObject(ctx, "vault", "a.txt", "b.txt")
+	assert.ErrorIs(t, err, ErrObjectExists)
+	_, err = om.RenameObject(ctx, "vault", "a.txt", "a.txt")
+	assert.ErrorIs(t, err, ErrInvalidObjectName)
+
+	require.NoError(t, om.SetObjectRetention(ctx, "vault", "a.txt", &RetentionConfig{
+		Mode:            RetentionModeGovernance,
+		RetainUntilDate: time.Now().Add(time.Hour),
+	}))
+	_, err = om.RenameObject(ctx, "vault", "a.txt", "c.txt")
+	var retErr *RetentionError
+	assert.ErrorAs(t, err, &retErr)
+
+	assert.Equal(t, "a", readObjectVersion(t, om, "vault", "a.txt"))
+	assert.Equal(t, "b", readObjectVersion(t, om, "vault", "b.txt"))
+}
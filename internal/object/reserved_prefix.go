@@ -0,0 +1,49 @@
+package object
+
+import (
+	"context"
+	"strings"
+)
+
+// systemWriteKey marks a context whose writes come from MaxIOFS itself.
+type systemWriteKey struct{}
+
+// WithSystemWrite returns a context whose writes may use the reserved key
+// prefixes (storage.reserved_key_prefixes) that client writes are refused.
+func WithSystemWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemWriteKey{}, true)
+}
+
+func isSystemWrite(ctx context.Context) bool {
+	v, _ := ctx.Value(systemWriteKey{}).(bool)
+	return v
+}
+
+// checkReservedKey returns ErrReservedKeyPrefix when key has one of the
+// reserved prefixes at the start of any path segment and the write is a
+// client's. SOSAPI readers recognize their files under any folder, so
+// "folder/<prefix>..." is reserved too. System writes, HA replicas and tenant
+// imports carry what a primary already accepted and are let through.
+func (om *objectManager) checkReservedKey(ctx context.Context, key string) error {
+	if isSystemWrite(ctx) || isBypassQuotaEnforcement(ctx) {
+		return nil
+	}
+	if _, replicated := replicatedLastModifiedFromContext(ctx); replicated {
+		return nil
+	}
+	for _, prefix := range om.config.ReservedKeyPrefixes {
+		if hasReservedSegment(key, prefix) {
+			return ErrReservedKeyPrefix
+		}
+	}
+	return nil
+}
+
+// hasReservedSegment reports whether prefix starts key or one of its
+// "/"-separated segments.
+func hasReservedSegment(key, prefix string) bool {
+	if prefix == "" {
+		return false
+	}
+	return strings.HasPrefix(key, prefix) || strings.Contains(key, "/"+prefix)
+}
@@ -0,0 +1,61 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservedKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	bucket := "reserved-bucket"
+	systemKey := ".system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c/system.xml"
+	headers := http.Header{"Content-Type": []string{"application/xml"}}
+
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	om.config.ReservedKeyPrefixes = []string{".system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c/"}
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: bucket, TenantID: "tenant1", OwnerID: "user1"}))
+
+	t.Run("client put is rejected", func(t *testing.T) {
+		_, err := om.PutObject(ctx, bucket, systemKey, bytes.NewReader([]byte("<xml/>")), headers)
+		assert.ErrorIs(t, err, ErrReservedKeyPrefix)
+		_, err = metaStore.GetObject(ctx, bucket, systemKey)
+		assert.ErrorIs(t, err, metadata.ErrObjectNotFound)
+	})
+
+	t.Run("client multipart is rejected", func(t *testing.T) {
+		_, err := om.CreateMultipartUpload(ctx, bucket, systemKey, headers)
+		assert.ErrorIs(t, err, ErrReservedKeyPrefix)
+	})
+
+	t.Run("system write succeeds", func(t *testing.T) {
+		obj, err := om.PutObject(WithSystemWrite(ctx), bucket, systemKey, bytes.NewReader([]byte("<xml/>")), headers)
+		require.NoError(t, err)
+		assert.Equal(t, systemKey, obj.Key)
+	})
+
+	t.Run("nested reserved segment is rejected", func(t *testing.T) {
+		nested := "backups/.system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c/system.xml"
+		_, err := om.PutObject(ctx, bucket, nested, bytes.NewReader([]byte("<xml/>")), headers)
+		assert.ErrorIs(t, err, ErrReservedKeyPrefix)
+		_, err = metaStore.GetObject(ctx, bucket, nested)
+		assert.ErrorIs(t, err, metadata.ErrObjectNotFound)
+	})
+
+	t.Run("other keys are unaffected", func(t *testing.T) {
+		_, err := om.PutObject(ctx, bucket, "backups/x.system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c", bytes.NewReader([]byte("x")), headers)
+		assert.NoError(t, err)
+	})
+
+	t.Run("no reserved prefixes", func(t *testing.T) {
+		om.config.ReservedKeyPrefixes = nil
+		_, err := om.PutObject(ctx, bucket, systemKey, bytes.NewReader([]byte("<xml/>")), headers)
+		assert.NoError(t, err)
+	})
+}
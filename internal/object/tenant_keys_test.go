@@ -0,0 +1,228 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/kek"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
+	"github.com/maxiofs/maxiofs/pkg/encryption"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupManagerWithTenantKeys builds a manager with per-tenant keys and two
+// tenant buckets ("tenant-a/tenant-a-data", "tenant-b/tenant-b-data") plus a
+// global bucket ("shared").
+func setupManagerWithTenantKeys(t *testing.T) (*objectManager, storage.Backend, kek.TenantKeyProvider) {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	backend, err := storage.NewFilesystemBackend(storage.Config{Root: tempDir})
+	require.NoError(t, err)
+	metaStore, err := metadata.NewPebbleStore(metadata.PebbleOptions{
+		DataDir: filepath.Join(tempDir, "metadata"),
+		Logger:  logrus.StandardLogger(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { metaStore.Close() })
+
+	tenantKeys := kek.EphemeralTenantKeys()
+	om := NewManager(backend, metaStore, config.StorageConfig{
+		Backend:       "filesystem",
+		Root:          tempDir,
+		EncryptionKey: envelopeTestKey,
+	}, WithTenantKeyProvider(tenantKeys)).(*objectManager)
+
+	ctx := context.Background()
+	for _, tenantID := range []string{"tenant-a", "tenant-b"} {
+		require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: tenantID + "-data", TenantID: tenantID, OwnerID: "user-1"}))
+	}
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "shared", OwnerID: "admin"}))
+	return om, backend, tenantKeys
+}
+
+// unwrapDEKWith unwraps the DEK recorded in a sidecar with the given key.
+func unwrapDEKWith(om *objectManager, sidecar map[string]string, key []byte) ([]byte, error) {
+	wrapped, _ := hex.DecodeString(sidecar["wrapped-dek"])
+	iv, _ := hex.DecodeString(sidecar["wrapped-dek-iv"])
+	return om.encryptor.Decrypt(&encryption.EncryptedData{Data: wrapped, IV: iv}, key)
+}
+
+func TestTenantKeys_ObjectsUseOwningTenantKey(t *testing.T) {
+	ctx := context.Background()
+	om, backend, tenantKeys := setupManagerWithTenantKeys(t)
+	content := []byte("tenant data that must stay isolated")
+
+	for _, bucket := range []string{"tenant-a/tenant-a-data", "tenant-b/tenant-b-data", "shared"} {
+		_, err := om.PutObject(ctx, bucket, "doc.txt", bytes.NewReader(content), http.Header{})
+		require.NoError(t, err)
+		assert.Equal(t, string(content), readObject(t, om, bucket, "doc.txt"))
+	}
+
+	sidecarA, err := backend.GetMetadata(ctx, om.getObjectPath("tenant-a/tenant-a-data", "doc.txt"))
+	require.NoError(t, err)
+	sidecarB, err := backend.GetMetadata(ctx, om.getObjectPath("tenant-b/tenant-b-data", "doc.txt"))
+	require.NoError(t, err)
+	sidecarGlobal, err := backend.GetMetadata(ctx, om.getObjectPath("shared", "doc.txt"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "tenant-a", sidecarA["tenant-key-id"])
+	assert.Equal(t, "tenant-b", sidecarB["tenant-key-id"])
+	assert.Empty(t, sidecarA["kek-version"], "tenant objects are not wrapped by the KEK directly")
+	assert.Empty(t, sidecarGlobal["tenant-key-id"], "global buckets keep using the KEK")
+	assert.Equal(t, "1", sidecarGlobal["kek-version"])
+
+	keyA, _, err := tenantKeys.CurrentTenantKey("tenant-a")
+	require.NoError(t, err)
+	keyB, _, err := tenantKeys.CurrentTenantKey("tenant-b")
+	require.NoError(t, err)
+	assert.NotEqual(t, keyA, keyB, "each tenant must have its own key")
+
+	_, err = unwrapDEKWith(om, sidecarA, keyA)
+	require.NoError(t, err)
+	_, err = unwrapDEKWith(om, sidecarA, keyB)
+	assert.Error(t, err, "tenant B's key must not unwrap tenant A's DEK")
+	_, err = unwrapDEKWith(om, sidecarB, keyA)
+	assert.Error(t, err, "tenant A's key must not unwrap tenant B's DEK")
+
+	// Pointing tenant A's object at tenant B's key must fail to decrypt.
+	sidecarA["tenant-key-id"] = "tenant-b"
+	require.NoError(t, backend.SetMetadata(ctx, om.getObjectPath("tenant-a/tenant-a-data", "doc.txt"), sidecarA))
+	_, reader, err := om.GetObject(ctx, "tenant-a/tenant-a-data", "doc.txt")
+	if err == nil {
+		_, err = io.ReadAll(reader)
+		reader.Close()
+	}
+	assert.Error(t, err, "decrypting with another tenant's key must fail")
+}
+
+func TestTenantKeys_RotationRewrapsOnlyThatTenant(t *testing.T) {
+	ctx := context.Background()
+	om, backend, tenantKeys := setupManagerWithTenantKeys(t)
+	content := []byte("written before the tenant key rotation")
+
+	for _, bucket := range []string{"tenant-a/tenant-a-data", "tenant-b/tenant-b-data"} {
+		_, err := om.PutObject(ctx, bucket, "doc.txt", bytes.NewReader(content), http.Header{})
+		require.NoError(t, err)
+	}
+
+	version, err := tenantKeys.RotateTenantKey("tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	// Still readable before the worker runs (old version is kept).
+	assert.Equal(t, string(content), readObject(t, om, "tenant-a/tenant-a-data", "doc.txt"))
+
+	converted, _, err := om.EncryptExistingObject(ctx, "tenant-a/tenant-a-data", "doc.txt")
+	require.NoError(t, err)
+	assert.Equal(t, 1, converted, "tenant A's DEK must be re-wrapped to the new version")
+	converted, _, err = om.EncryptExistingObject(ctx, "tenant-b/tenant-b-data", "doc.txt")
+	require.NoError(t, err)
+	assert.Equal(t, 0, converted, "tenant B is unaffected by tenant A's rotation")
+
+	sidecarA, err := backend.GetMetadata(ctx, om.getObjectPath("tenant-a/tenant-a-data", "doc.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "2", sidecarA["tenant-key-version"])
+	sidecarB, err := backend.GetMetadata(ctx, om.getObjectPath("tenant-b/tenant-b-data", "doc.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", sidecarB["tenant-key-version"])
+
+	assert.Equal(t, string(content), readObject(t, om, "tenant-a/tenant-a-data", "doc.txt"))
+}
+
+func TestTenantKeys_KEKWrappedObjectsMoveToTenantKey(t *testing.T) {
+	ctx := context.Background()
+	om, backend, _ := setupManagerWithTenantKeys(t)
+	content := []byte("written before tenant keys existed")
+
+	// Simulate an object written before tenant keys: KEK-wrapped DEK.
+	tenantKeys := om.tenantKeys
+	om.tenantKeys = nil
+	_, err := om.PutObject(ctx, "tenant-a/tenant-a-data", "old.txt", bytes.NewReader(content), http.Header{})
+	require.NoError(t, err)
+	om.tenantKeys = tenantKeys
+
+	path := om.getObjectPath("tenant-a/tenant-a-data", "old.txt")
+	sidecar, err := backend.GetMetadata(ctx, path)
+	require.NoError(t, err)
+	require.Empty(t, sidecar["tenant-key-id"])
+
+	converted, _, err := om.EncryptExistingObject(ctx, "tenant-a/tenant-a-data", "old.txt")
+	require.NoError(t, err)
+	assert.Equal(t, 1, converted)
+
+	sidecar, err = backend.GetMetadata(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a", sidecar["tenant-key-id"])
+	assert.Empty(t, sidecar["kek-version"], "the KEK-wrapped DEK entries must be replaced")
+	assert.Equal(t, string(content), readObject(t, om, "tenant-a/tenant-a-data", "old.txt"))
+}
+
+// A node that lost its tenant keys (metadata database gone) still decrypts
+// tenant objects through the KEK-wrapped tenant key copy in the sidecar.
+func TestTenantKeys_SidecarCopyDecryptsWithoutKeyring(t *testing.T) {
+	ctx := context.Background()
+	om, backend, _ := setupManagerWithTenantKeys(t)
+	content := []byte("recoverable from the filesystem and the KEK")
+
+	_, err := om.PutObject(ctx, "tenant-a/tenant-a-data", "doc.txt", bytes.NewReader(content), http.Header{})
+	require.NoError(t, err)
+
+	restarted := NewManager(backend, om.metadataStore, config.StorageConfig{
+		Backend:       "filesystem",
+		Root:          om.config.Root,
+		EncryptionKey: envelopeTestKey,
+	}, WithTenantKeyProvider(kek.EphemeralTenantKeys())).(*objectManager)
+	assert.Equal(t, string(content), readObject(t, restarted, "tenant-a/tenant-a-data", "doc.txt"))
+}
+
+// A node whose tenant keys were regenerated holds a different key under the
+// same version number; the sidecar copy still decrypts the object.
+func TestTenantKeys_SidecarCopyDecryptsWithRegeneratedKey(t *testing.T) {
+	ctx := context.Background()
+	om, backend, tenantKeys := setupManagerWithTenantKeys(t)
+	content := []byte("written before the tenant keys were lost")
+
+	_, err := om.PutObject(ctx, "tenant-a/tenant-a-data", "doc.txt", bytes.NewReader(content), http.Header{})
+	require.NoError(t, err)
+	_, version, err := tenantKeys.CurrentTenantKey("tenant-a")
+	require.NoError(t, err)
+
+	regenerated := kek.EphemeralTenantKeys()
+	newKey, newVersion, err := regenerated.CurrentTenantKey("tenant-a")
+	require.NoError(t, err)
+	require.Equal(t, version, newVersion, "the regenerated key must reuse the version number")
+	oldKey, err := tenantKeys.TenantKeyByVersion("tenant-a", version)
+	require.NoError(t, err)
+	require.NotEqual(t, oldKey, newKey)
+
+	restarted := NewManager(backend, om.metadataStore, config.StorageConfig{
+		Backend:       "filesystem",
+		Root:          om.config.Root,
+		EncryptionKey: envelopeTestKey,
+	}, WithTenantKeyProvider(regenerated)).(*objectManager)
+	assert.Equal(t, string(content), readObject(t, restarted, "tenant-a/tenant-a-data", "doc.txt"))
+
+	// The background worker moves the object onto the regenerated key
+	path := om.getObjectPath("tenant-a/tenant-a-data", "doc.txt")
+	sidecar, err := backend.GetMetadata(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, tenantKeyFingerprint("tenant-a", oldKey), sidecar["tenant-key-fingerprint"])
+	assert.Equal(t, migrationRewrap, restarted.migrationActionFor("tenant-a/tenant-a-data", sidecar))
+	_, err = restarted.rewrapPathDEK(ctx, "tenant-a/tenant-a-data", "doc.txt", path, sidecar)
+	require.NoError(t, err)
+	sidecar, err = backend.GetMetadata(ctx, path)
+	require.NoError(t, err)
+	_, err = unwrapDEKWith(restarted, sidecar, newKey)
+	assert.NoError(t, err)
+	assert.Equal(t, string(content), readObject(t, restarted, "tenant-a/tenant-a-data", "doc.txt"))
+}
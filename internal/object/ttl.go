@@ -0,0 +1,49 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+)
+
+// ExpiresAfterHeader sets a time-to-live on PutObject: the object is deleted
+// this many seconds after it was written, without a lifecycle rule.
+const ExpiresAfterHeader = "x-amz-expires-after-seconds"
+
+// ErrInvalidExpiresAfter is returned for an x-amz-expires-after-seconds value
+// that is not a positive whole number of seconds.
+var ErrInvalidExpiresAfter = errors.New("invalid x-amz-expires-after-seconds")
+
+// ParseExpiresAfter returns the absolute expiry for an
+// x-amz-expires-after-seconds value counted from now, or nil when the value
+// is empty.
+func ParseExpiresAfter(value string, now time.Time) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return nil, fmt.Errorf("%w: %q must be a positive number of seconds", ErrInvalidExpiresAfter, value)
+	}
+	// Cap at 100 years so the addition can't overflow time.Duration
+	if seconds > 100*365*24*3600 {
+		return nil, fmt.Errorf("%w: %q is too large", ErrInvalidExpiresAfter, value)
+	}
+	expiresAt := now.Add(time.Duration(seconds) * time.Second).UTC()
+	return &expiresAt, nil
+}
+
+// IsExpired reports whether the object had a TTL that has run out. Expired
+// objects read as missing until the lifecycle worker deletes them.
+func (o *Object) IsExpired(now time.Time) bool {
+	return o.ExpiresAt != nil && !now.Before(*o.ExpiresAt)
+}
+
+func isMetadataExpired(mo *metadata.ObjectMetadata, now time.Time) bool {
+	return mo.ExpiresAt != nil && !now.Before(*mo.ExpiresAt)
+}
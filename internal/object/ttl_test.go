@@ -0,0 +1,30 @@
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiresAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	expiresAt, err := ParseExpiresAfter("", now)
+	require.NoError(t, err)
+	assert.Nil(t, expiresAt)
+
+	expiresAt, err = ParseExpiresAfter("3600", now)
+	require.NoError(t, err)
+	require.NotNil(t, expiresAt)
+	assert.Equal(t, now.Add(time.Hour), *expiresAt)
+	assert.False(t, (&Object{ExpiresAt: expiresAt}).IsExpired(now))
+	assert.True(t, (&Object{ExpiresAt: expiresAt}).IsExpired(now.Add(time.Hour)))
+	assert.False(t, (&Object{}).IsExpired(now))
+
+	for _, value := range []string{"0", "-5", "1.5", "1h", "99999999999999"} {
+		_, err := ParseExpiresAfter(value, now)
+		assert.ErrorIs(t, err, ErrInvalidExpiresAfter, value)
+	}
+}
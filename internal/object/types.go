@@ -1,6 +1,7 @@
 package object
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -29,6 +30,12 @@ type Part struct {
 	ETag         string    `json:"etag"`
 	Size         int64     `json:"size"`
 	LastModified time.Time `json:"last_modified"`
+	SSEAlgorithm string    `json:"sse_algorithm,omitempty"` // "AES256" when the part is stored encrypted
+
+	// Additional checksum of the part's data. In a CompleteMultipartUpload
+	// request it is the value the client expects the part to have.
+	ChecksumAlgorithm string `json:"checksum_algorithm,omitempty"` // CRC32, CRC32C, SHA1, SHA256
+	ChecksumValue     string `json:"checksum_value,omitempty"`     // base64-encoded
 }
 
 // RetentionConfig represents object retention configuration for Object Lock
@@ -195,6 +202,55 @@ const (
 	StorageClassGlacierIR          = "GLACIER_IR"
 )
 
+// acceptedStorageClasses are the x-amz-storage-class values objects can be
+// stored with. Only STANDARD is physically distinct; the others are kept as
+// labels so clients and tooling round-trip them.
+var acceptedStorageClasses = map[string]bool{
+	StorageClassStandard:           true,
+	StorageClassReducedRedundancy:  true,
+	StorageClassStandardIA:         true,
+	StorageClassOnezoneIA:          true,
+	StorageClassIntelligentTiering: true,
+	StorageClassGlacier:            true,
+	StorageClassGlacierIR:          true,
+	StorageClassDeepArchive:        true,
+}
+
+// ValidateStorageClass returns ErrInvalidStorageClass unless sc is empty
+// (STANDARD) or an accepted storage class.
+func ValidateStorageClass(sc string) error {
+	if sc == "" || acceptedStorageClasses[sc] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is not supported", ErrInvalidStorageClass, sc)
+}
+
+// IsArchivedStorageClass reports whether objects of storage class sc must be
+// restored before they can be read, like GLACIER and DEEP_ARCHIVE objects in
+// S3. GLACIER_IR is instant retrieval and reads directly.
+func IsArchivedStorageClass(sc string) bool {
+	return sc == StorageClassGlacier || sc == StorageClassDeepArchive
+}
+
+// NeedsRestore reports whether the object is archived and has no restore
+// that is finished and not yet expired.
+func (o *Object) NeedsRestore(now time.Time) bool {
+	if !IsArchivedStorageClass(o.StorageClass) {
+		return false
+	}
+	return o.RestoreStatus != "restored" || o.RestoreExpiresAt == nil || !o.RestoreExpiresAt.After(now)
+}
+
+// Multipart upload limits. Part numbers run from 1 to MaxMultipartParts as in
+// S3; storage.multipart_max_parts can lower the cap.
+const (
+	MaxMultipartParts = 10000
+)
+
+// NullVersionID is the S3 version ID of the object stored while a bucket's
+// versioning was never enabled or suspended; internally it has no version ID.
+const NullVersionID = "null"
+
 // Object Lock constants
 const (
 	ObjectLockModeGovernance = "GOVERNANCE"
@@ -0,0 +1,178 @@
+package object
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// UploadScanner judges an upload before it becomes visible, e.g. an
+// antivirus or a content validator. Scan reads body to the end.
+type UploadScanner interface {
+	Scan(ctx context.Context, upload ScanRequest, body io.Reader) (*ScanVerdict, error)
+}
+
+// ScanRequest describes the upload handed to an UploadScanner.
+type ScanRequest struct {
+	Bucket      string
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// ScanVerdict is an UploadScanner's answer. Reason explains a rejection and
+// is returned to the client.
+type ScanVerdict struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ErrUploadScannerUnavailable is returned for uploads the scanner couldn't
+// judge while storage.upload_scan.fail_open is off.
+var ErrUploadScannerUnavailable = errors.New("the upload scanner is unavailable")
+
+// UploadRejectedError is returned for an upload the scanner rejected. The
+// upload has been discarded.
+type UploadRejectedError struct {
+	Reason string
+}
+
+func (e *UploadRejectedError) Error() string {
+	if e.Reason == "" {
+		return "upload rejected by the upload scanner"
+	}
+	return "upload rejected by the upload scanner: " + e.Reason
+}
+
+// WithUploadScanner sets the scanner uploads to buckets with ScanUploads are
+// sent to, instead of the HTTP scanner configured in storage.upload_scan.
+func WithUploadScanner(s UploadScanner) Option {
+	return func(om *objectManager) { om.uploadScanner = s }
+}
+
+// httpUploadScanner POSTs each upload to an HTTP endpoint. The object
+// travels as the request body; bucket, key and content type as X-Maxiofs-*
+// headers. The endpoint answers 200 with a ScanVerdict in JSON; anything
+// else means the upload couldn't be judged. An ICAP scanner can be used
+// through an HTTP adapter.
+type httpUploadScanner struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPUploadScanner returns the scanner configured in cfg, or nil when
+// no endpoint is set.
+func NewHTTPUploadScanner(cfg config.UploadScanConfig) UploadScanner {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &httpUploadScanner{
+		endpoint: cfg.Endpoint,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *httpUploadScanner) Scan(ctx context.Context, upload ScanRequest, body io.Reader) (*ScanVerdict, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = upload.Size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Maxiofs-Bucket", upload.Bucket)
+	req.Header.Set("X-Maxiofs-Key", upload.Key)
+	req.Header.Set("X-Maxiofs-Content-Type", upload.ContentType)
+	req.Header.Set("X-Maxiofs-Size", strconv.FormatInt(upload.Size, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scanner answered %s", resp.Status)
+	}
+	var verdict ScanVerdict
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("invalid scanner answer: %w", err)
+	}
+	return &verdict, nil
+}
+
+// scanUpload sends an upload to the upload scanner when its bucket has
+// scanning on. It returns an *UploadRejectedError when the scanner rejects
+// it, and ErrUploadScannerUnavailable when the scanner can't judge it and
+// fail_open is off. open returns the upload's content from the start; it is
+// closed once scanned.
+//
+// System writes, HA replicas and tenant imports carry data a primary already
+// accepted and are not scanned again.
+func (om *objectManager) scanUpload(ctx context.Context, bucket, key, contentType string, size int64, open func() (io.ReadCloser, error)) error {
+	if isSystemWrite(ctx) || isBypassQuotaEnforcement(ctx) {
+		return nil
+	}
+	if _, replicated := replicatedLastModifiedFromContext(ctx); replicated {
+		return nil
+	}
+	// Folder markers carry no content
+	if strings.HasSuffix(key, "/") && size == 0 {
+		return nil
+	}
+	bucketMeta, err := om.loadBucketMetadata(ctx, bucket)
+	if err != nil || !bucketMeta.ScanUploads {
+		return nil
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"bucket": bucket,
+		"key":    key,
+		"size":   size,
+	})
+
+	verdict, err := om.runUploadScanner(ctx, ScanRequest{
+		Bucket:      bucket,
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+	}, open)
+	if err != nil {
+		if om.config.UploadScan.FailOpen {
+			logger.WithError(err).Warn("Upload scanner unavailable; accepting upload (fail_open)")
+			return nil
+		}
+		logger.WithError(err).Error("Upload scanner unavailable; rejecting upload")
+		return ErrUploadScannerUnavailable
+	}
+	if !verdict.Allowed {
+		logger.WithField("reason", verdict.Reason).Warn("Upload rejected by the upload scanner")
+		return &UploadRejectedError{Reason: verdict.Reason}
+	}
+	return nil
+}
+
+func (om *objectManager) runUploadScanner(ctx context.Context, upload ScanRequest, open func() (io.ReadCloser, error)) (*ScanVerdict, error) {
+	if om.uploadScanner == nil {
+		return nil, errors.New("no upload scanner is configured (storage.upload_scan.endpoint)")
+	}
+	body, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	// Hide Close: an HTTP client closes the request body it was given
+	return om.uploadScanner.Scan(ctx, upload, struct{ io.Reader }{body})
+}
@@ -0,0 +1,144 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestUploadScanner serves a scanner that rejects bodies containing the
+// EICAR marker and allows everything else.
+func newTestUploadScanner(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verdict := ScanVerdict{Allowed: true}
+		if bytes.Contains(body, []byte("EICAR")) {
+			verdict = ScanVerdict{Allowed: false, Reason: "Eicar-Test-Signature found in " + r.Header.Get("X-Maxiofs-Key")}
+		}
+		_ = json.NewEncoder(w).Encode(verdict)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func createScanBucket(t *testing.T, metaStore metadata.Store, name string) {
+	t.Helper()
+	require.NoError(t, metaStore.CreateBucket(context.Background(), &metadata.BucketMetadata{
+		Name:        name,
+		OwnerID:     "user-1",
+		ScanUploads: true,
+	}))
+}
+
+func TestUploadScan_RejectedObjectRemoved(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	om.uploadScanner = NewHTTPUploadScanner(config.UploadScanConfig{Endpoint: newTestUploadScanner(t).URL})
+	createScanBucket(t, metaStore, "inbox")
+
+	_, err := om.PutObject(ctx, "inbox", "clean.txt", bytes.NewReader([]byte("quarterly report")), http.Header{})
+	require.NoError(t, err)
+
+	_, err = om.PutObject(ctx, "inbox", "eicar.txt", bytes.NewReader([]byte("X5O!P%@AP EICAR-STANDARD-ANTIVIRUS-TEST-FILE")), http.Header{})
+	var rejected *UploadRejectedError
+	require.True(t, errors.As(err, &rejected), "got %v", err)
+	assert.Contains(t, rejected.Reason, "eicar.txt")
+
+	_, err = om.GetObjectMetadata(ctx, "inbox", "eicar.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	exists, err := om.storage.Exists(ctx, om.getObjectPath("inbox", "eicar.txt"))
+	require.NoError(t, err)
+	assert.False(t, exists, "rejected upload must not be stored")
+
+	_, reader, err := om.GetObject(ctx, "inbox", "clean.txt")
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "quarterly report", string(data))
+}
+
+func TestUploadScan_RejectedOverwriteKeepsObject(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	om.uploadScanner = NewHTTPUploadScanner(config.UploadScanConfig{Endpoint: newTestUploadScanner(t).URL})
+	createScanBucket(t, metaStore, "inbox")
+
+	_, err := om.PutObject(ctx, "inbox", "report.txt", bytes.NewReader([]byte("original")), http.Header{})
+	require.NoError(t, err)
+	_, err = om.PutObject(ctx, "inbox", "report.txt", bytes.NewReader([]byte("EICAR")), http.Header{})
+	var rejected *UploadRejectedError
+	require.True(t, errors.As(err, &rejected), "got %v", err)
+
+	_, reader, err := om.GetObject(ctx, "inbox", "report.txt")
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(data))
+}
+
+func TestUploadScan_BucketNotOptedIn(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	om.uploadScanner = NewHTTPUploadScanner(config.UploadScanConfig{Endpoint: newTestUploadScanner(t).URL})
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "plain", OwnerID: "user-1"}))
+
+	_, err := om.PutObject(ctx, "plain", "eicar.txt", bytes.NewReader([]byte("EICAR")), http.Header{})
+	assert.NoError(t, err)
+}
+
+func TestUploadScan_ScannerUnavailable(t *testing.T) {
+	ctx := context.Background()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	om.uploadScanner = NewHTTPUploadScanner(config.UploadScanConfig{Endpoint: down.URL})
+	createScanBucket(t, metaStore, "inbox")
+
+	_, err := om.PutObject(ctx, "inbox", "a.txt", bytes.NewReader([]byte("data")), http.Header{})
+	require.ErrorIs(t, err, ErrUploadScannerUnavailable)
+	_, err = om.GetObjectMetadata(ctx, "inbox", "a.txt")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	om.config.UploadScan.FailOpen = true
+	_, err = om.PutObject(ctx, "inbox", "a.txt", bytes.NewReader([]byte("data")), http.Header{})
+	assert.NoError(t, err)
+}
+
+func TestUploadScan_MultipartRejectedAborts(t *testing.T) {
+	ctx := context.Background()
+	om, _, metaStore := setupManagerWithConfigKey(t)
+	om.uploadScanner = NewHTTPUploadScanner(config.UploadScanConfig{Endpoint: newTestUploadScanner(t).URL})
+	createScanBucket(t, metaStore, "inbox")
+
+	upload, err := om.CreateMultipartUpload(ctx, "inbox", "archive.bin", http.Header{})
+	require.NoError(t, err)
+	part1, err := om.UploadPart(ctx, upload.UploadID, 1, bytes.NewReader([]byte("header ")))
+	require.NoError(t, err)
+	part2, err := om.UploadPart(ctx, upload.UploadID, 2, bytes.NewReader([]byte("EICAR payload")))
+	require.NoError(t, err)
+
+	_, err = om.CompleteMultipartUpload(ctx, upload.UploadID, []Part{*part1, *part2})
+	var rejected *UploadRejectedError
+	require.True(t, errors.As(err, &rejected), "got %v", err)
+
+	_, err = om.GetObjectMetadata(ctx, "inbox", "archive.bin")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	_, err = om.metadataStore.GetMultipartUpload(ctx, upload.UploadID)
+	assert.ErrorIs(t, err, metadata.ErrUploadNotFound, "rejected upload should be aborted")
+}
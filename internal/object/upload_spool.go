@@ -0,0 +1,77 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// uploadSpool holds a PutObject body between hashing it and storing it.
+// Bodies up to storage.upload_spill_threshold bytes stay in memory; larger
+// ones are written to a temp file so memory use does not grow with the size
+// of the object.
+type uploadSpool struct {
+	mem  []byte
+	file *os.File
+}
+
+// uploadTempDir is where spilled upload bodies are written: the configured
+// storage.upload_temp_dir, or the storage root.
+func (om *objectManager) uploadTempDir() string {
+	if om.config.UploadTempDir != "" {
+		return om.config.UploadTempDir
+	}
+	return om.config.Root
+}
+
+// spoolUpload reads data to the end into a new spool and returns it with the
+// number of bytes read. The caller must Close the spool; on error nothing is
+// left behind.
+func (om *objectManager) spoolUpload(data io.Reader) (*uploadSpool, int64, error) {
+	if threshold := om.config.UploadSpillThreshold; threshold > 0 {
+		var buf bytes.Buffer
+		n, err := io.CopyN(&buf, data, threshold+1)
+		if err == io.EOF {
+			return &uploadSpool{mem: buf.Bytes()}, n, nil
+		}
+		if err != nil {
+			return nil, n, fmt.Errorf("failed to read upload body: %w", err)
+		}
+		// Over the threshold: what was read so far goes to disk first
+		data = io.MultiReader(&buf, data)
+	}
+
+	f, err := os.CreateTemp(om.uploadTempDir(), "maxiofs-upload-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	spool := &uploadSpool{file: f}
+	n, err := io.Copy(f, data)
+	if err != nil {
+		spool.Close()
+		return nil, n, fmt.Errorf("failed to write to temp file: %w", err)
+	}
+	return spool, n, nil
+}
+
+// Reader returns the spooled body from its first byte.
+func (s *uploadSpool) Reader() (io.Reader, error) {
+	if s.file == nil {
+		return bytes.NewReader(s.mem), nil
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind temp file: %w", err)
+	}
+	return s.file, nil
+}
+
+// Close releases the spool and removes its temp file, if any.
+func (s *uploadSpool) Close() error {
+	if s.file == nil {
+		s.mem = nil
+		return nil
+	}
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
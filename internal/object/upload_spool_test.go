@@ -0,0 +1,111 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spoolFiles lists the upload temp files currently in dir.
+func spoolFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "maxiofs-upload-") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// probeReader yields size bytes of data and calls probe once half of them
+// have been read.
+type probeReader struct {
+	size, read int64
+	probe      func()
+}
+
+func (r *probeReader) Read(p []byte) (int, error) {
+	if r.read >= r.size {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.size-r.read {
+		p = p[:r.size-r.read]
+	}
+	for i := range p {
+		p[i] = byte('a' + (r.read+int64(i))%26)
+	}
+	if r.probe != nil && r.read+int64(len(p)) > r.size/2 {
+		r.probe()
+		r.probe = nil
+	}
+	r.read += int64(len(p))
+	return len(p), nil
+}
+
+func TestPutObject_UploadSpool(t *testing.T) {
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	ctx := context.Background()
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{Name: "uploads", TenantID: "tenant-1", OwnerID: "user-1"}))
+	bucket := "tenant-1/uploads"
+
+	spoolDir := t.TempDir()
+	om.config.UploadTempDir = spoolDir
+	om.config.UploadSpillThreshold = 64 * 1024
+
+	t.Run("small body stays in memory", func(t *testing.T) {
+		body := &probeReader{size: 16 * 1024}
+		body.probe = func() { assert.Empty(t, spoolFiles(t, spoolDir)) }
+		obj, err := om.PutObject(ctx, bucket, "small.bin", body, http.Header{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(16*1024), obj.Size)
+		assert.Empty(t, spoolFiles(t, spoolDir))
+	})
+
+	t.Run("large body spills to the temp dir", func(t *testing.T) {
+		const size = 4 * 1024 * 1024
+		spilled := false
+		body := &probeReader{size: size}
+		body.probe = func() { spilled = len(spoolFiles(t, spoolDir)) == 1 }
+		obj, err := om.PutObject(ctx, bucket, "large.bin", body, http.Header{})
+		require.NoError(t, err)
+		assert.True(t, spilled, "body over the threshold should be written to upload_temp_dir")
+		assert.Equal(t, int64(size), obj.Size)
+		assert.Empty(t, spoolFiles(t, spoolDir), "temp file must be removed after the upload")
+
+		var want bytes.Buffer
+		_, err = io.Copy(&want, &probeReader{size: size})
+		require.NoError(t, err)
+		assert.Equal(t, want.String(), readObject(t, om, bucket, "large.bin"))
+	})
+
+	t.Run("temp file removed when the upload is rejected", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("Content-MD5", "1B2M2Y8AsgTpgAmY7PhCfg==") // MD5 of the empty body
+		_, err := om.PutObject(ctx, bucket, "bad.bin", &probeReader{size: 1024 * 1024}, headers)
+		assert.ErrorIs(t, err, ErrBadDigest)
+		assert.Empty(t, spoolFiles(t, spoolDir))
+	})
+
+	t.Run("zero threshold always spills", func(t *testing.T) {
+		om.config.UploadSpillThreshold = 0
+		spilled := false
+		body := &probeReader{size: 1024}
+		body.probe = func() { spilled = len(spoolFiles(t, spoolDir)) == 1 }
+		_, err := om.PutObject(ctx, bucket, "tiny.bin", body, http.Header{})
+		require.NoError(t, err)
+		assert.True(t, spilled)
+		assert.Empty(t, spoolFiles(t, spoolDir))
+	})
+}
@@ -0,0 +1,49 @@
+package object
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// userMetadataPrefix starts every user-defined metadata header.
+const userMetadataPrefix = "x-amz-meta-"
+
+// UserMetadataFromHeaders returns the x-amz-meta-* headers as user metadata
+// keyed by the lowercased name after the prefix, which is how S3 stores them.
+// A name sent several times, in any case, keeps every value joined with ","
+// in the order they were sent, as S3 does.
+func UserMetadataFromHeaders(headers http.Header) map[string]string {
+	// Keys that only differ in case fold onto one name; visit them in a fixed
+	// order so the joined value doesn't depend on map iteration.
+	headerKeys := make([]string, 0, len(headers))
+	for headerKey := range headers {
+		if strings.HasPrefix(strings.ToLower(headerKey), userMetadataPrefix) {
+			headerKeys = append(headerKeys, headerKey)
+		}
+	}
+	sort.Strings(headerKeys)
+
+	values := make(map[string][]string)
+	for _, headerKey := range headerKeys {
+		name := strings.ToLower(headerKey)[len(userMetadataPrefix):]
+		values[name] = append(values[name], headers[headerKey]...)
+	}
+
+	userMetadata := make(map[string]string, len(values))
+	for name, vals := range values {
+		if len(vals) > 0 {
+			userMetadata[name] = strings.Join(vals, ",")
+		}
+	}
+	return userMetadata
+}
+
+// SetUserMetadataHeaders writes user metadata as x-amz-meta-* headers with
+// lowercase names, as S3 returns them. The names are stored in h as-is:
+// Header.Set would canonicalize x-amz-meta-mykey to X-Amz-Meta-Mykey.
+func SetUserMetadataHeaders(h http.Header, userMetadata map[string]string) {
+	for name, value := range userMetadata {
+		h[userMetadataPrefix+name] = []string{value}
+	}
+}
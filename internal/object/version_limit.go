@@ -0,0 +1,70 @@
+package object
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// trimExcessVersions enforces the bucket's MaxVersionsPerObject after a new
+// version of key was written. The oldest noncurrent versions are expired until
+// the key is back within the cap. Versions under retention or legal hold are
+// never expired but still count, so a key whose locked versions alone exceed
+// the cap keeps all of them. The caller must hold the key lock.
+func (om *objectManager) trimExcessVersions(ctx context.Context, bucket, key string) {
+	bucketMeta, err := om.loadBucketMetadata(ctx, bucket)
+	if err != nil || bucketMeta.MaxVersionsPerObject <= 0 {
+		return
+	}
+	limit := bucketMeta.MaxVersionsPerObject
+
+	versions, err := om.metadataStore.GetObjectVersions(ctx, bucket, key)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{"bucket": bucket, "key": key}).
+			Warn("Failed to list versions for version limit")
+		return
+	}
+	excess := len(versions) - limit
+	if excess <= 0 {
+		return
+	}
+
+	// Oldest first; version IDs start with a nanosecond timestamp and break ties
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].LastModified.Equal(versions[j].LastModified) {
+			return versions[i].VersionID < versions[j].VersionID
+		}
+		return versions[i].LastModified.Before(versions[j].LastModified)
+	})
+
+	expired, skipped := 0, 0
+	for _, ver := range versions {
+		if expired == excess {
+			break
+		}
+		if ver.IsLatest {
+			continue
+		}
+		// deleteSpecificVersion refuses versions under legal hold or retention
+		// (GOVERNANCE included, as nothing is bypassed here)
+		if err := om.deleteSpecificVersion(ctx, bucket, key, ver.VersionID, false); err != nil {
+			skipped++
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"bucket":    bucket,
+				"key":       key,
+				"versionID": ver.VersionID,
+			}).Debug("Version limit: keeping version that can't be expired")
+			continue
+		}
+		expired++
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"bucket":  bucket,
+		"key":     key,
+		"limit":   limit,
+		"expired": expired,
+		"kept":    skipped,
+	}).Info("Expired noncurrent versions over the bucket's version limit")
+}
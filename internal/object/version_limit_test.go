@@ -0,0 +1,103 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createVersionLimitBucket(t *testing.T, metaStore metadata.Store, limit int) string {
+	t.Helper()
+	require.NoError(t, metaStore.CreateBucket(context.Background(), &metadata.BucketMetadata{
+		Name:                 "capped",
+		TenantID:             "tenant-1",
+		OwnerID:              "user-1",
+		Versioning:           &metadata.VersioningMetadata{Enabled: true, Status: "Enabled"},
+		MaxVersionsPerObject: limit,
+	}))
+	return "tenant-1/capped"
+}
+
+func putVersions(t *testing.T, om *objectManager, bucket, key string, n int) []string {
+	t.Helper()
+	ids := make([]string, n)
+	for i := range ids {
+		obj, err := om.PutObject(context.Background(), bucket, key, bytes.NewReader([]byte(fmt.Sprintf("v%d", i))), http.Header{})
+		require.NoError(t, err)
+		ids[i] = obj.VersionID
+	}
+	return ids
+}
+
+func versionIDs(t *testing.T, metaStore metadata.Store, bucket, key string) map[string]bool {
+	t.Helper()
+	versions, err := metaStore.GetObjectVersions(context.Background(), bucket, key)
+	require.NoError(t, err)
+	ids := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		ids[v.VersionID] = true
+	}
+	return ids
+}
+
+func TestVersionLimit_TrimsOldestNoncurrentVersions(t *testing.T) {
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	bucket := createVersionLimitBucket(t, metaStore, 3)
+
+	ids := putVersions(t, om, bucket, "report.csv", 6)
+
+	kept := versionIDs(t, metaStore, bucket, "report.csv")
+	assert.Equal(t, map[string]bool{ids[3]: true, ids[4]: true, ids[5]: true}, kept)
+
+	obj, err := om.GetObjectMetadata(context.Background(), bucket, "report.csv")
+	require.NoError(t, err)
+	assert.Equal(t, ids[5], obj.VersionID, "the new version stays current")
+
+	// Other keys are counted separately
+	putVersions(t, om, bucket, "other.csv", 2)
+	assert.Len(t, versionIDs(t, metaStore, bucket, "other.csv"), 2)
+}
+
+func TestVersionLimit_LockedVersionsAreKeptAndCounted(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	bucket := createVersionLimitBucket(t, metaStore, 3)
+
+	ids := putVersions(t, om, bucket, "ledger.db", 2)
+	require.NoError(t, om.SetObjectLegalHold(ctx, bucket, "ledger.db", &LegalHoldConfig{Status: LegalHoldStatusOn}, ids[0]))
+	require.NoError(t, om.SetObjectRetention(ctx, bucket, "ledger.db", &RetentionConfig{
+		Mode:            RetentionModeGovernance,
+		RetainUntilDate: time.Now().Add(time.Hour),
+	}, ids[1]))
+
+	more := putVersions(t, om, bucket, "ledger.db", 3)
+
+	// Both locked versions survive and fill two of the three slots, so only
+	// the newest unlocked version fits beside them
+	kept := versionIDs(t, metaStore, bucket, "ledger.db")
+	assert.Equal(t, map[string]bool{ids[0]: true, ids[1]: true, more[2]: true}, kept)
+
+	// Locked versions alone over the cap: nothing is expired, the put still succeeds
+	require.NoError(t, om.SetObjectLegalHold(ctx, bucket, "ledger.db", &LegalHoldConfig{Status: LegalHoldStatusOn}, more[2]))
+	latest := putVersions(t, om, bucket, "ledger.db", 1)
+	kept = versionIDs(t, metaStore, bucket, "ledger.db")
+	assert.Equal(t, map[string]bool{ids[0]: true, ids[1]: true, more[2]: true, latest[0]: true}, kept)
+}
+
+func TestVersionLimit_UnlimitedByDefault(t *testing.T) {
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+	bucket := createVersionLimitBucket(t, metaStore, 0)
+
+	putVersions(t, om, bucket, "k", 5)
+	assert.Len(t, versionIDs(t, metaStore, bucket, "k"), 5)
+}
@@ -333,3 +333,41 @@ func TestDeleteSpecificVersion_EmptyVersionID(t *testing.T) {
 	assert.Error(t, err, "Should return error for empty version ID")
 	t.Logf("Delete with empty version ID returned error: %v", err)
 }
+
+// TestObjectExists_DeleteMarker verifies the point-lookup existence check treats
+// absent keys and keys whose latest version is a delete marker as missing.
+func TestObjectExists_DeleteMarker(t *testing.T) {
+	ctx := context.Background()
+	om, metaStore, cleanup := setupTestManagerWithStore(t)
+	defer cleanup()
+
+	bucket := "tenant-1/probe-bucket"
+	require.NoError(t, metaStore.CreateBucket(ctx, &metadata.BucketMetadata{
+		Name:       "probe-bucket",
+		TenantID:   "tenant-1",
+		OwnerID:    "user-1",
+		Versioning: &metadata.VersioningMetadata{Enabled: true, Status: "Enabled"},
+	}))
+
+	exists, err := om.ObjectExists(ctx, bucket, "never-written.txt")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = om.GetObjectMetadata(ctx, bucket, "never-written.txt")
+	assert.Equal(t, ErrObjectNotFound, err)
+
+	_, err = om.PutObject(ctx, bucket, "doc.txt", bytes.NewReader([]byte("hello")), http.Header{})
+	require.NoError(t, err)
+	exists, err = om.ObjectExists(ctx, bucket, "doc.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	markerID, err := om.DeleteObject(ctx, bucket, "doc.txt", false)
+	require.NoError(t, err)
+	require.NotEmpty(t, markerID)
+
+	exists, err = om.ObjectExists(ctx, bucket, "doc.txt")
+	require.NoError(t, err)
+	assert.False(t, exists, "a delete marker hides the object")
+	_, err = om.GetObjectMetadata(ctx, bucket, "doc.txt")
+	assert.Equal(t, ErrObjectNotFound, err)
+}
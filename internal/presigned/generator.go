@@ -15,17 +15,32 @@ const (
 	// AWS signature version
 	algorithm = "AWS4-HMAC-SHA256"
 
-	// Service and request type
-	service     = "s3"
 	requestType = "aws4_request"
+)
 
-	// Default region (configurable)
-	defaultRegion = "us-east-1"
+const (
+	// DefaultRegion is the region presigned URLs are scoped to when neither
+	// the bucket nor the configuration names one.
+	DefaultRegion = "us-east-1"
 
-	// Maximum expiration time (7 days)
-	maxExpiration = 604800
+	// DefaultService is the SigV4 signing service S3 clients use.
+	DefaultService = "s3"
 )
 
+// MaxExpiration is the longest lifetime SigV4 allows for a presigned URL
+// (7 days). Configured limits may be lower but never higher.
+const MaxExpiration int64 = 604800
+
+// EffectiveMaxExpiration returns the presigned URL lifetime cap to enforce for
+// a configured limit in seconds; 0 or anything above MaxExpiration yields
+// MaxExpiration.
+func EffectiveMaxExpiration(limit int64) int64 {
+	if limit <= 0 || limit > MaxExpiration {
+		return MaxExpiration
+	}
+	return limit
+}
+
 // PresignedURLParams contains parameters for generating a presigned URL
 type PresignedURLParams struct {
 	Endpoint        string            // Base URL (e.g., "http://localhost:8080")
@@ -35,8 +50,10 @@ type PresignedURLParams struct {
 	AccessKeyID     string            // AWS access key ID
 	SecretAccessKey string            // AWS secret access key
 	Method          string            // HTTP method (GET, PUT, etc.)
-	ExpiresIn       int64             // Expiration time in seconds (max 604800)
+	ExpiresIn       int64             // Expiration time in seconds (max MaxExpiresIn)
+	MaxExpiresIn    int64             // Expiration cap in seconds (default and ceiling: 604800)
 	Region          string            // AWS region (default: us-east-1)
+	Service         string            // Signing service (default: s3)
 	QueryParams     map[string]string // Additional query parameters (optional)
 }
 
@@ -52,14 +69,17 @@ func GeneratePresignedURL(params PresignedURLParams) (string, error) {
 	if params.ExpiresIn <= 0 {
 		params.ExpiresIn = 3600 // Default: 1 hour
 	}
-	if params.ExpiresIn > maxExpiration {
-		return "", fmt.Errorf("expiration time cannot exceed %d seconds (7 days)", maxExpiration)
+	if limit := EffectiveMaxExpiration(params.MaxExpiresIn); params.ExpiresIn > limit {
+		return "", fmt.Errorf("expiration time cannot exceed %d seconds", limit)
 	}
 	if params.Method == "" {
 		params.Method = "GET"
 	}
 	if params.Region == "" {
-		params.Region = defaultRegion
+		params.Region = DefaultRegion
+	}
+	if params.Service == "" {
+		params.Service = DefaultService
 	}
 
 	// Current timestamp
@@ -68,7 +88,7 @@ func GeneratePresignedURL(params PresignedURLParams) (string, error) {
 	amzDate := now.Format("20060102T150405Z")
 
 	// Build credential scope
-	credentialScope := fmt.Sprintf("%s/%s/%s/%s", dateStamp, params.Region, service, requestType)
+	credentialScope := fmt.Sprintf("%s/%s/%s/%s", dateStamp, params.Region, params.Service, requestType)
 	credential := fmt.Sprintf("%s/%s", params.AccessKeyID, credentialScope)
 
 	// Build URL path - bucket names are globally unique, no need for tenant prefix
@@ -114,7 +134,7 @@ func GeneratePresignedURL(params PresignedURLParams) (string, error) {
 	)
 
 	// Calculate signature
-	signingKey := getSignatureKey(params.SecretAccessKey, dateStamp, params.Region, service)
+	signingKey := getSignatureKey(params.SecretAccessKey, dateStamp, params.Region, params.Service)
 	signature := hex.EncodeToString(hmacSHA256(signingKey, []byte(stringToSign)))
 
 	// Add signature to query parameters
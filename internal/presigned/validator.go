@@ -36,6 +36,19 @@ func uriEncode(path string) string {
 // ValidatePresignedURL validates a presigned URL from an HTTP request
 // Returns true if valid, false otherwise
 func ValidatePresignedURL(r *http.Request, secretAccessKey string) (bool, error) {
+	return ValidatePresignedURLWithSkew(r, secretAccessKey, auth.DefaultClockSkew)
+}
+
+// ValidatePresignedURLWithSkew is ValidatePresignedURL with an explicit clock
+// skew window applied to X-Amz-Date and X-Amz-Expires.
+func ValidatePresignedURLWithSkew(r *http.Request, secretAccessKey string, skew time.Duration) (bool, error) {
+	return ValidatePresignedURLForService(r, secretAccessKey, skew, DefaultService)
+}
+
+// ValidatePresignedURLForService is ValidatePresignedURLWithSkew for a server
+// configured with its own signing service. URLs scoped to either that service
+// or s3 are accepted; the region is taken from the credential scope as is.
+func ValidatePresignedURLForService(r *http.Request, secretAccessKey string, skew time.Duration, service string) (bool, error) {
 	query := r.URL.Query()
 
 	// Check if this is a presigned URL request
@@ -68,7 +81,7 @@ func ValidatePresignedURL(r *http.Request, secretAccessKey string) (bool, error)
 	svc := credParts[3]
 	reqType := credParts[4]
 
-	if svc != "s3" || reqType != "aws4_request" {
+	if (svc != DefaultService && svc != service) || reqType != "aws4_request" {
 		return false, fmt.Errorf("invalid service or request type")
 	}
 
@@ -84,9 +97,14 @@ func ValidatePresignedURL(r *http.Request, secretAccessKey string) (bool, error)
 		return false, fmt.Errorf("invalid X-Amz-Expires: %w", err)
 	}
 
-	// Check if expired
+	// The skew only tolerates a client clock running ahead; the URL expires
+	// exactly X-Amz-Expires seconds after X-Amz-Date
+	now := time.Now().UTC()
+	if requestTime.After(now.Add(skew)) {
+		return false, auth.ErrTimestampSkew
+	}
 	expirationTime := requestTime.Add(time.Duration(expiresIn) * time.Second)
-	if time.Now().UTC().After(expirationTime) {
+	if now.After(expirationTime) {
 		logrus.WithFields(logrus.Fields{
 			"requestTime":    requestTime,
 			"expiresIn":      expiresIn,
@@ -36,6 +36,7 @@ import (
 	"time"
 
 	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -123,6 +124,9 @@ func reconcileBucket(ctx context.Context, bkt *bucketEntry, store metadata.Store
 			return cErr
 		}
 		if d.IsDir() {
+			if isOpaqueObjectDir(bkt.dirPath, path, d) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -145,7 +149,7 @@ func reconcileBucket(ctx context.Context, bkt *bucketEntry, store metadata.Store
 			return nil
 		}
 
-		key, versionID, ok := keyFromRelPath(bkt.dirPath, path)
+		key, versionID, ok := keyFromRelPath(bkt, path)
 		if !ok {
 			return nil
 		}
@@ -214,14 +218,21 @@ func reconcileBucket(ctx context.Context, bkt *bucketEntry, store metadata.Store
 	return changed, nil
 }
 
+// isOpaqueObjectDir reports whether a directory met walking a bucket root is
+// where the opaque key layout stores its objects. Their keys only live in the
+// metadata store, so the walks skip it rather than index files by their ids.
+func isOpaqueObjectDir(bucketDir, path string, d fs.DirEntry) bool {
+	return d.Name() == storage.OpaqueObjectDir && filepath.Dir(path) == bucketDir
+}
+
 // keyFromRelPath converts an absolute file path under a bucket root into an
 // object key (and version ID for files under .versions/).
-func keyFromRelPath(bucketDir, path string) (key, versionID string, ok bool) {
-	rel, err := filepath.Rel(bucketDir, path)
+func keyFromRelPath(bkt *bucketEntry, path string) (key, versionID string, ok bool) {
+	rel, err := filepath.Rel(bkt.dirPath, path)
 	if err != nil {
 		return "", "", false
 	}
-	rel = filepath.ToSlash(rel)
+	rel = storage.UnshardPath(filepath.ToSlash(rel), bkt.shardDepth)
 	if strings.HasPrefix(rel, ".versions/") {
 		trimmed := strings.TrimPrefix(rel, ".versions/")
 		slash := strings.LastIndex(trimmed, "/")
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -269,6 +270,62 @@ func TestReconcileRestoresVersion(t *testing.T) {
 	}
 }
 
+// TestReconcileShardedLayout checks that files stored in shard directories
+// (storage.shard_depth) are restored under their object key, not their path.
+func TestReconcileShardedLayout(t *testing.T) {
+	dataDir, store, cleanup := setupReconcileTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(dataDir, "objects", ".maxiofs-layout"), []byte(`{"shard_depth":2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	const versionID = "1700000000000000002"
+	writeObjectPair(t, dataDir, storage.ShardPath("reports/q3.csv", 2), "q3-bytes", 1700000000)
+	writeObjectPair(t, dataDir, storage.ShardPath(".versions/doc.txt/"+versionID, 2), "v2-bytes", 1700000000)
+
+	report, err := Reconcile(ctx, dataDir, store, logrus.StandardLogger())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if report.EntriesRestored != 1 || report.VersionsRestored != 1 {
+		t.Fatalf("restored %d entries / %d versions, want 1 / 1 (failures: %v)",
+			report.EntriesRestored, report.VersionsRestored, report.Failures)
+	}
+	if _, err := store.GetObject(ctx, "bkt", "reports/q3.csv"); err != nil {
+		t.Errorf("sharded object not restored under its key: %v", err)
+	}
+	if _, err := store.GetObject(ctx, "bkt", "doc.txt", versionID); err != nil {
+		t.Errorf("sharded version not restored under its key: %v", err)
+	}
+}
+
+// TestReconcileFlatFolderNamedLikeShard checks that in a flat root a folder
+// that happens to carry its file's shard hash is kept in the key.
+func TestReconcileFlatFolderNamedLikeShard(t *testing.T) {
+	dataDir, store, cleanup := setupReconcileTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// "logs/<hash>/app.log" is what app.log looks like one shard level down
+	key := storage.ShardPath("logs/app.log", 1)
+	writeObjectPair(t, dataDir, key, "log-bytes", 1700000000)
+
+	report, err := Reconcile(ctx, dataDir, store, logrus.StandardLogger())
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if report.EntriesRestored != 1 {
+		t.Fatalf("restored %d entries, want 1 (failures: %v)", report.EntriesRestored, report.Failures)
+	}
+	if _, err := store.GetObject(ctx, "bkt", key); err != nil {
+		t.Errorf("object not restored under its full key %q: %v", key, err)
+	}
+	if _, err := store.GetObject(ctx, "bkt", "logs/app.log"); err == nil {
+		t.Error("object restored under a key with its folder stripped")
+	}
+}
+
 func TestReconcileSkipsBucketMissingFromStore(t *testing.T) {
 	dataDir, store, cleanup := setupReconcileTest(t)
 	defer cleanup()
@@ -35,6 +35,7 @@ import (
 
 	"github.com/maxiofs/maxiofs/internal/kek"
 	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/maxiofs/maxiofs/pkg/encryption"
 	"github.com/sirupsen/logrus"
 	_ "modernc.org/sqlite"
@@ -84,6 +85,7 @@ type bucketEntry struct {
 	objects    []*metadata.ObjectMetadata
 	versions   map[string][]*metadata.ObjectMetadata // key → versions
 	totalSize  int64
+	shardDepth int // storage.shard_depth the objects root was written with
 }
 
 // Run executes the recovery.
@@ -167,6 +169,12 @@ func Run(opts Options) (*Report, error) {
 func discoverBuckets(objectsRoot string) ([]*bucketEntry, error) {
 	var buckets []*bucketEntry
 
+	// Object files sit this many shard directories below their key's path
+	shardDepth, err := storage.ReadShardDepth(objectsRoot)
+	if err != nil {
+		return nil, err
+	}
+
 	appendIfBucket := func(dirPath, tenantHint string) (bool, error) {
 		markerPath := filepath.Join(dirPath, ".maxiofs-bucket")
 		if _, err := os.Stat(markerPath); err != nil {
@@ -200,6 +208,7 @@ func discoverBuckets(objectsRoot string) ([]*bucketEntry, error) {
 			tenantID:   tenantID,
 			createdAt:  createdAt,
 			versions:   make(map[string][]*metadata.ObjectMetadata),
+			shardDepth: shardDepth,
 		})
 		return true, nil
 	}
@@ -245,6 +254,11 @@ func walkBucket(bkt *bucketEntry, encryptor encryption.Encryptor, keys map[int][
 			return nil
 		}
 		if d.IsDir() {
+			// The opaque key layout keeps keys out of paths: they can't be
+			// rebuilt from disk
+			if isOpaqueObjectDir(bkt.dirPath, path, d) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		name := d.Name()
@@ -258,7 +272,7 @@ func walkBucket(bkt *bucketEntry, encryptor encryption.Encryptor, keys map[int][
 		if err != nil {
 			return nil
 		}
-		rel = filepath.ToSlash(rel)
+		rel = storage.UnshardPath(filepath.ToSlash(rel), bkt.shardDepth)
 
 		var key, versionID string
 		if strings.HasPrefix(rel, ".versions/") {
@@ -399,7 +413,13 @@ func objectFromSidecar(path, bucketPath, key, versionID string, encryptor encryp
 	}
 
 	// Envelope: verify the DEK unwraps with the bundle keys when we have them.
-	version, vErr := strconv.Atoi(sidecar["kek-version"])
+	// Tenant-key objects wrap their DEK with the tenant key, whose KEK-wrapped
+	// copy travels in the sidecar: unwrap that first.
+	versionKey := "kek-version"
+	if sidecar["tenant-key-id"] != "" {
+		versionKey = "tenant-key-kek-version"
+	}
+	version, vErr := strconv.Atoi(sidecar[versionKey])
 	if vErr != nil || keys == nil {
 		return obj, classEncryptedUnverified, nil
 	}
@@ -407,6 +427,15 @@ func objectFromSidecar(path, bucketPath, key, versionID string, encryptor encryp
 	if !ok {
 		return obj, classEncryptedUnverified, nil
 	}
+	if sidecar["tenant-key-id"] != "" {
+		wrappedTenantKey, _ := hex.DecodeString(sidecar["wrapped-tenant-key"])
+		tenantKeyIV, _ := hex.DecodeString(sidecar["wrapped-tenant-key-iv"])
+		tenantKey, err := encryptor.Decrypt(&encryption.EncryptedData{Data: wrappedTenantKey, IV: tenantKeyIV}, kekKey)
+		if err != nil {
+			return obj, classEncryptedUnverified, fmt.Errorf("wrapped tenant key does not unwrap with bundle key v%d", version)
+		}
+		kekKey = tenantKey
+	}
 	wrapped, _ := hex.DecodeString(sidecar["wrapped-dek"])
 	iv, _ := hex.DecodeString(sidecar["wrapped-dek-iv"])
 	if _, err := encryptor.Decrypt(&encryption.EncryptedData{Data: wrapped, IV: iv}, kekKey); err != nil {
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessKeyInfoRoundTrip(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	admin, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+
+	owner := &auth.User{
+		ID:        "key-owner",
+		Username:  "key-owner",
+		Password:  "owner-password",
+		Status:    "active",
+		Roles:     []string{"user"},
+		CreatedAt: time.Now().Unix(),
+	}
+	require.NoError(t, server.authManager.CreateUser(ctx, owner))
+
+	call := func(handler http.HandlerFunc, method, path string, vars map[string]string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req = req.WithContext(context.WithValue(req.Context(), "user", admin))
+		req = mux.SetURLVars(req, vars)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	type keyResponse struct {
+		ID          string            `json:"id"`
+		Name        string            `json:"name"`
+		Description string            `json:"description"`
+		Tags        map[string]string `json:"tags"`
+	}
+	decode := func(rr *httptest.ResponseRecorder, into interface{}) {
+		t.Helper()
+		resp := APIResponse{Data: into}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	}
+	listKeys := func() []keyResponse {
+		t.Helper()
+		rr := call(server.handleListAccessKeys, "GET", "/api/v1/users/key-owner/access-keys", map[string]string{"user": owner.ID}, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var keys []keyResponse
+		decode(rr, &keys)
+		return keys
+	}
+
+	userVars := map[string]string{"user": owner.ID}
+	rr := call(server.handleCreateAccessKey, "POST", "/api/v1/users/key-owner/access-keys", userVars, auth.AccessKeyInfo{
+		Name:        "backup-job",
+		Description: "Nightly backups from the build host",
+		Tags:        map[string]string{"team": "ops", "env": "prod"},
+	})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var created keyResponse
+	decode(rr, &created)
+	assert.Equal(t, "backup-job", created.Name)
+	assert.Equal(t, map[string]string{"team": "ops", "env": "prod"}, created.Tags)
+
+	// A key created without a body has no labels
+	rr = call(server.handleCreateAccessKey, "POST", "/api/v1/users/key-owner/access-keys", userVars, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	keys := listKeys()
+	require.Len(t, keys, 2)
+	byID := map[string]keyResponse{}
+	for _, k := range keys {
+		byID[k.ID] = k
+	}
+	listed := byID[created.ID]
+	assert.Equal(t, "backup-job", listed.Name)
+	assert.Equal(t, "Nightly backups from the build host", listed.Description)
+	assert.Equal(t, map[string]string{"team": "ops", "env": "prod"}, listed.Tags)
+
+	keyVars := map[string]string{"user": owner.ID, "accessKey": created.ID}
+	rr = call(server.handleUpdateAccessKey, "PUT", "/api/v1/users/key-owner/access-keys/"+created.ID, keyVars, auth.AccessKeyInfo{
+		Name: "backup-job-v2",
+		Tags: map[string]string{"team": "storage"},
+	})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	stored, err := server.authManager.GetAccessKey(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "backup-job-v2", stored.Name)
+	assert.Empty(t, stored.Description)
+	assert.Equal(t, map[string]string{"team": "storage"}, stored.Tags)
+	assert.NotEmpty(t, stored.SecretAccessKey, "relabelling leaves the secret in place")
+
+	t.Run("rejects oversized labels", func(t *testing.T) {
+		rr := call(server.handleUpdateAccessKey, "PUT", "/api/v1/users/key-owner/access-keys/"+created.ID, keyVars, auth.AccessKeyInfo{
+			Name: strings.Repeat("n", auth.MaxAccessKeyNameLength+1),
+		})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = call(server.handleCreateAccessKey, "POST", "/api/v1/users/key-owner/access-keys", userVars, auth.AccessKeyInfo{
+			Tags: map[string]string{"": "empty key"},
+		})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("key must belong to the user in the path", func(t *testing.T) {
+		rr := call(server.handleUpdateAccessKey, "PUT", "/api/v1/users/someone-else/access-keys/"+created.ID,
+			map[string]string{"user": "someone-else", "accessKey": created.ID}, auth.AccessKeyInfo{Name: "hijack"})
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
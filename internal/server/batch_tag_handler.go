@@ -0,0 +1,261 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/sirupsen/logrus"
+)
+
+// batchTagPageSize is how many objects are read from the metadata store per
+// page; a progress line is streamed after each page, and memory use stays
+// bounded by it no matter how many objects match the prefix.
+const batchTagPageSize = 1000
+
+// Limits of an S3 object tag set.
+const (
+	maxObjectTags        = 10
+	maxObjectTagKeyLen   = 128
+	maxObjectTagValueLen = 256
+)
+
+type batchTagRequest struct {
+	Prefix string            `json:"prefix"`
+	Tags   map[string]string `json:"tags"`
+	// Replace drops the tags an object already has instead of merging into them.
+	Replace bool `json:"replace"`
+	// Cursor resumes an interrupted run after this key, as reported by the
+	// last progress line received.
+	Cursor string `json:"cursor"`
+	// BypassGovernance also tags objects under GOVERNANCE retention.
+	BypassGovernance bool `json:"bypassGovernance"`
+}
+
+// batchTagProgress is one NDJSON line of the batch-tag response. Counters are
+// cumulative for the request; the last line has Done set (or Error on failure).
+type batchTagProgress struct {
+	Processed int    `json:"processed"`
+	Tagged    int    `json:"tagged"`
+	Unchanged int    `json:"unchanged"`
+	Locked    int    `json:"locked"`
+	Failed    int    `json:"failed"`
+	Cursor    string `json:"cursor"`
+	Done      bool   `json:"done,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// handleBatchTagObjects applies a tag set to every current object under a
+// prefix (folder markers excluded), streaming NDJSON progress lines.
+// POST /buckets/{bucket}/batch-tag[?tenantId=...]
+// Body: {"prefix": "...", "tags": {"k": "v"}, "replace": false, "cursor": "", "bypassGovernance": false}
+//
+// Tags are merged into each object's tag set unless replace is set. Objects
+// that already carry the result are not rewritten, so repeating a run is
+// harmless, and a run cut short is resumed by sending the cursor of the last
+// progress line received. Objects under legal hold or retention are skipped
+// and counted as locked (GOVERNANCE retention only without bypassGovernance).
+// Only admins may run it.
+func (s *Server) handleBatchTagObjects(w http.ResponseWriter, r *http.Request) {
+	bucketName := mux.Vars(r)["bucket"]
+
+	// Object metadata lives on the bucket's owner node
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return
+	}
+
+	user, exists := auth.GetUserFromContext(r.Context())
+	if !exists {
+		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !auth.IsAdminUser(r.Context()) {
+		s.writeError(w, "Only administrators can tag objects in batch", http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	var req batchTagRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateBatchTags(req.Tags); err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tenantID := s.resolveTenantID(r)
+	if _, err := s.bucketManager.GetBucketInfo(r.Context(), tenantID, bucketName); err != nil {
+		if err == bucket.ErrBucketNotFound {
+			s.writeError(w, "Bucket not found", http.StatusNotFound)
+			return
+		}
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	bucketPath := buildBucketPath(tenantID, bucketName)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, canFlush := w.(http.Flusher)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	send := func(p *batchTagProgress) bool {
+		if err := enc.Encode(p); err != nil {
+			return false
+		}
+		if err := bw.Flush(); err != nil {
+			return false
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	progress := batchTagProgress{Cursor: req.Cursor}
+	marker := req.Cursor
+	for {
+		if r.Context().Err() != nil {
+			return
+		}
+
+		objects, nextMarker, err := s.metadataStore.ListObjects(r.Context(), bucketPath, req.Prefix, marker, batchTagPageSize)
+		if err != nil {
+			logrus.WithError(err).WithField("bucket", bucketName).Error("batch-tag: failed to list objects")
+			progress.Error = "failed to list objects"
+			send(&progress)
+			return
+		}
+
+		for _, obj := range objects {
+			progress.Processed++
+			progress.Cursor = obj.Key
+			s.batchTagObject(r, bucketPath, obj, &req, &progress)
+		}
+		if !send(&progress) {
+			return
+		}
+
+		if nextMarker == "" {
+			break
+		}
+		marker = nextMarker
+	}
+
+	progress.Done = true
+	send(&progress)
+
+	logrus.WithFields(logrus.Fields{
+		"bucket":    bucketName,
+		"prefix":    req.Prefix,
+		"processed": progress.Processed,
+		"tagged":    progress.Tagged,
+		"locked":    progress.Locked,
+		"failed":    progress.Failed,
+		"user":      user.Username,
+	}).Info("Batch tagging finished")
+}
+
+// batchTagObject applies the requested tags to one listed object and counts
+// the outcome in progress.
+func (s *Server) batchTagObject(r *http.Request, bucketPath string, obj *metadata.ObjectMetadata, req *batchTagRequest, progress *batchTagProgress) {
+	// Delete markers have neither data nor an ETag; folder markers hold no data
+	if (obj.Size == 0 && obj.ETag == "") || strings.HasSuffix(obj.Key, "/") {
+		progress.Unchanged++
+		return
+	}
+	if batchTagLocked(obj, req.BypassGovernance) {
+		progress.Locked++
+		return
+	}
+
+	tags := make(map[string]string, len(obj.Tags)+len(req.Tags))
+	if !req.Replace {
+		for k, v := range obj.Tags {
+			tags[k] = v
+		}
+	}
+	for k, v := range req.Tags {
+		tags[k] = v
+	}
+	if sameTags(tags, obj.Tags) {
+		progress.Unchanged++
+		return
+	}
+	if len(tags) > maxObjectTags {
+		progress.Failed++
+		return
+	}
+
+	tagSet := &object.TagSet{Tags: make([]object.Tag, 0, len(tags))}
+	for k, v := range tags {
+		tagSet.Tags = append(tagSet.Tags, object.Tag{Key: k, Value: v})
+	}
+	sort.Slice(tagSet.Tags, func(i, j int) bool { return tagSet.Tags[i].Key < tagSet.Tags[j].Key })
+
+	if err := s.objectManager.SetObjectTagging(r.Context(), bucketPath, obj.Key, tagSet); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{"bucket": bucketPath, "key": obj.Key}).Warn("batch-tag: failed to tag object")
+		progress.Failed++
+		return
+	}
+	progress.Tagged++
+}
+
+// batchTagLocked reports whether obj is protected by a legal hold or an
+// unexpired retention that the batch must not change.
+func batchTagLocked(obj *metadata.ObjectMetadata, bypassGovernance bool) bool {
+	if obj.LegalHold {
+		return true
+	}
+	if obj.Retention == nil || !time.Now().Before(obj.Retention.RetainUntilDate) {
+		return false
+	}
+	return obj.Retention.Mode != object.RetentionModeGovernance || !bypassGovernance
+}
+
+func validateBatchTags(tags map[string]string) error {
+	if len(tags) == 0 {
+		return fmt.Errorf("tags must not be empty")
+	}
+	if len(tags) > maxObjectTags {
+		return fmt.Errorf("at most %d tags are allowed", maxObjectTags)
+	}
+	for k, v := range tags {
+		if k == "" || len(k) > maxObjectTagKeyLen {
+			return fmt.Errorf("tag keys must be 1-%d characters", maxObjectTagKeyLen)
+		}
+		if len(v) > maxObjectTagValueLen {
+			return fmt.Errorf("tag values must be at most %d characters", maxObjectTagValueLen)
+		}
+	}
+	return nil
+}
+
+func sameTags(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchTagRequestFor(user *auth.User, bucketName string, body interface{}) *http.Request {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/api/v1/buckets/"+bucketName+"/batch-tag", bytes.NewReader(data))
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	return mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+}
+
+func readBatchTagProgress(t *testing.T, rr *httptest.ResponseRecorder) []batchTagProgress {
+	t.Helper()
+	var lines []batchTagProgress
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var p batchTagProgress
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &p), "line is not valid JSON: %q", scanner.Text())
+		lines = append(lines, p)
+	}
+	require.NotEmpty(t, lines)
+	return lines
+}
+
+func objectTags(t *testing.T, server *Server, bucketName, key string) map[string]string {
+	t.Helper()
+	tagSet, err := server.objectManager.GetObjectTagging(context.Background(), bucketName, key)
+	require.NoError(t, err)
+	tags := map[string]string{}
+	if tagSet != nil {
+		for _, tag := range tagSet.Tags {
+			tags[tag.Key] = tag.Value
+		}
+	}
+	return tags
+}
+
+func TestHandleBatchTagObjects(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	admin, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+
+	bucketName := "governance"
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", bucketName, admin.ID))
+	put := func(key string) {
+		_, err := server.objectManager.PutObject(ctx, bucketName, key, bytes.NewReader([]byte("data")), http.Header{})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 5; i++ {
+		put(fmt.Sprintf("records/2025/r%d.csv", i))
+		put(fmt.Sprintf("scratch/s%d.tmp", i))
+	}
+	require.NoError(t, server.objectManager.SetObjectTagging(ctx, bucketName, "records/2025/r0.csv",
+		&object.TagSet{Tags: []object.Tag{{Key: "owner", Value: "finance"}}}))
+	require.NoError(t, server.objectManager.SetObjectLegalHold(ctx, bucketName, "records/2025/r4.csv",
+		&object.LegalHoldConfig{Status: object.LegalHoldStatusOn}))
+
+	body := map[string]interface{}{"prefix": "records/", "tags": map[string]string{"class": "confidential"}}
+	rr := httptest.NewRecorder()
+	server.handleBatchTagObjects(rr, batchTagRequestFor(admin, bucketName, body))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+	lines := readBatchTagProgress(t, rr)
+	final := lines[len(lines)-1]
+	assert.True(t, final.Done)
+	assert.Equal(t, 7, final.Processed, "5 records and their 2 folder markers")
+	assert.Equal(t, 4, final.Tagged)
+	assert.Equal(t, 1, final.Locked)
+
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, "confidential", objectTags(t, server, bucketName, fmt.Sprintf("records/2025/r%d.csv", i))["class"])
+	}
+	assert.Equal(t, map[string]string{"owner": "finance", "class": "confidential"}, objectTags(t, server, bucketName, "records/2025/r0.csv"),
+		"existing tags are merged")
+	assert.Empty(t, objectTags(t, server, bucketName, "records/2025/r4.csv"), "objects under legal hold are skipped")
+	for i := 0; i < 5; i++ {
+		assert.Empty(t, objectTags(t, server, bucketName, fmt.Sprintf("scratch/s%d.tmp", i)), "objects outside the prefix are untouched")
+	}
+
+	// Running it again changes nothing
+	rr = httptest.NewRecorder()
+	server.handleBatchTagObjects(rr, batchTagRequestFor(admin, bucketName, body))
+	again := readBatchTagProgress(t, rr)
+	assert.Zero(t, again[len(again)-1].Tagged)
+
+	// A cursor resumes after the key it names
+	body["cursor"] = "records/2025/r2.csv"
+	body["tags"] = map[string]string{"class": "public"}
+	rr = httptest.NewRecorder()
+	server.handleBatchTagObjects(rr, batchTagRequestFor(admin, bucketName, body))
+	resumed := readBatchTagProgress(t, rr)
+	assert.Equal(t, 1, resumed[len(resumed)-1].Tagged)
+	assert.Equal(t, "confidential", objectTags(t, server, bucketName, "records/2025/r2.csv")["class"])
+	assert.Equal(t, "public", objectTags(t, server, bucketName, "records/2025/r3.csv")["class"])
+}
+
+func TestHandleBatchTagObjects_Validation(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	admin, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", "tagged", admin.ID))
+
+	rr := httptest.NewRecorder()
+	server.handleBatchTagObjects(rr, batchTagRequestFor(admin, "tagged", map[string]interface{}{"prefix": "a/"}))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "a tag set is required")
+
+	rr = httptest.NewRecorder()
+	server.handleBatchTagObjects(rr, batchTagRequestFor(admin, "missing", map[string]interface{}{"tags": map[string]string{"k": "v"}}))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	user := &auth.User{ID: "u1", Username: "alice", Roles: []string{"user"}}
+	rr = httptest.NewRecorder()
+	server.handleBatchTagObjects(rr, batchTagRequestFor(user, "tagged", map[string]interface{}{"tags": map[string]string{"k": "v"}}))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/sirupsen/logrus"
+)
+
+// maxCacheControlLength bounds the bucket default Cache-Control value.
+const maxCacheControlLength = 1024
+
+// bucketCacheDefaultsPayload is the console representation of
+// metadata.BucketCacheDefaults.
+type bucketCacheDefaultsPayload struct {
+	CacheControl      string `json:"cacheControl,omitempty"`
+	ExpiresSeconds    int64  `json:"expiresSeconds,omitempty"`
+	ImmutableVersions bool   `json:"immutableVersions,omitempty"`
+}
+
+// handlePutBucketCacheDefaults sets the caching headers GET and HEAD send for
+// the bucket's objects that were uploaded without their own Cache-Control,
+// for fronting the bucket with a CDN. An all-empty body removes them.
+// PUT /api/v1/buckets/{bucket}/cache-defaults
+// Body: {"cacheControl": "public, max-age=3600", "expiresSeconds": 3600, "immutableVersions": true}
+func (s *Server) handlePutBucketCacheDefaults(w http.ResponseWriter, r *http.Request) {
+	tenantID, bucketName, ok := s.bucketSettingTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req bucketCacheDefaultsPayload
+	if !s.decodeBucketSetting(w, r, &req, "Invalid request body") {
+		return
+	}
+	req.CacheControl = strings.TrimSpace(req.CacheControl)
+	if len(req.CacheControl) > maxCacheControlLength || strings.ContainsFunc(req.CacheControl, func(c rune) bool { return c < 0x20 || c == 0x7f }) {
+		s.writeError(w, "cacheControl must be a single-line header value of at most 1024 characters", http.StatusBadRequest)
+		return
+	}
+	if req.ExpiresSeconds < 0 {
+		s.writeError(w, "expiresSeconds must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	var defaults *metadata.BucketCacheDefaults
+	if req != (bucketCacheDefaultsPayload{}) {
+		defaults = &metadata.BucketCacheDefaults{
+			CacheControl:      req.CacheControl,
+			ExpiresSeconds:    req.ExpiresSeconds,
+			ImmutableVersions: req.ImmutableVersions,
+		}
+	}
+	if !s.setBucketCacheDefaults(w, r, tenantID, bucketName, defaults) {
+		return
+	}
+	s.writeJSON(w, req)
+}
+
+// handleDeleteBucketCacheDefaults removes the bucket's caching defaults.
+// DELETE /api/v1/buckets/{bucket}/cache-defaults
+func (s *Server) handleDeleteBucketCacheDefaults(w http.ResponseWriter, r *http.Request) {
+	tenantID, bucketName, ok := s.bucketSettingTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if !s.setBucketCacheDefaults(w, r, tenantID, bucketName, nil) {
+		return
+	}
+	s.writeJSON(w, map[string]interface{}{"success": true})
+}
+
+// setBucketCacheDefaults stores defaults (nil removes them), writing the
+// error response and returning false on failure.
+func (s *Server) setBucketCacheDefaults(w http.ResponseWriter, r *http.Request, tenantID, bucketName string, defaults *metadata.BucketCacheDefaults) bool {
+	set := func(ctx context.Context) error {
+		return s.bucketManager.SetCacheDefaults(ctx, tenantID, bucketName, defaults)
+	}
+	if defaults == nil {
+		return s.saveBucketSetting(w, r, tenantID, bucketName, set, "Bucket cache defaults removed", nil)
+	}
+	return s.saveBucketSetting(w, r, tenantID, bucketName, set, "Bucket cache defaults updated", logrus.Fields{
+		"cache_control":      defaults.CacheControl,
+		"expires_seconds":    defaults.ExpiresSeconds,
+		"immutable_versions": defaults.ImmutableVersions,
+	})
+}
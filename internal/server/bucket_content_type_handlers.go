@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"mime"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// handlePutBucketDefaultContentType sets the Content-Type stored on uploads
+// that declare none and whose type sniffing is off or inconclusive. An empty
+// value removes it. An explicit Content-Type on the upload always wins.
+// PUT /api/v1/buckets/{bucket}/default-content-type
+// Body: {"defaultContentType": "<media type>"}
+func (s *Server) handlePutBucketDefaultContentType(w http.ResponseWriter, r *http.Request) {
+	tenantID, bucketName, ok := s.bucketSettingTarget(w, r)
+	if !ok {
+		return
+	}
+
+	const invalidBody = "Body must be {\"defaultContentType\": <media type or empty string>}"
+	var req struct {
+		DefaultContentType *string `json:"defaultContentType"`
+	}
+	if !s.decodeBucketSetting(w, r, &req, invalidBody) {
+		return
+	}
+	if req.DefaultContentType == nil {
+		s.writeError(w, invalidBody, http.StatusBadRequest)
+		return
+	}
+	contentType := *req.DefaultContentType
+	if contentType != "" {
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			s.writeError(w, "Invalid media type: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if !s.saveBucketSetting(w, r, tenantID, bucketName, func(ctx context.Context) error {
+		return s.bucketManager.SetDefaultContentType(ctx, tenantID, bucketName, contentType)
+	}, "Bucket default content type updated", logrus.Fields{"default_content_type": contentType}) {
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{"defaultContentType": contentType})
+}
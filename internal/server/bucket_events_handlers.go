@@ -0,0 +1,89 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/eventlog"
+)
+
+// bucketEventsResponse is a page of a bucket's event log.
+type bucketEventsResponse struct {
+	Bucket      string           `json:"bucket"`
+	Events      []eventlog.Event `json:"events"`
+	NextCursor  string           `json:"nextCursor"`
+	IsTruncated bool             `json:"isTruncated"`
+}
+
+// handleGetBucketEvents returns the object events recorded for a bucket after
+// the given cursor, oldest first, for polling-based integrations.
+// GET /api/v1/buckets/{bucket}/events?since=<cursor>&limit=N
+//
+// Clients pass the returned nextCursor as since on their next poll; while
+// isTruncated is true more events are waiting.
+func (s *Server) handleGetBucketEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucketName := mux.Vars(r)["bucket"]
+
+	// Events are recorded by the node serving the bucket
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return
+	}
+
+	currentUser, ok := auth.GetUserFromContext(ctx)
+	if !ok {
+		s.writeError(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	if s.eventLog == nil {
+		s.writeError(w, "Event log is not available", http.StatusNotImplemented)
+		return
+	}
+
+	limit := eventlog.DefaultListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > eventlog.MaxListLimit {
+			s.writeError(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	tenantID := s.resolveBucketQuotaTenant(r, currentUser)
+	if _, err := s.bucketManager.GetBucketInfo(ctx, tenantID, bucketName); err != nil {
+		if err == bucket.ErrBucketNotFound {
+			s.writeError(w, "Bucket not found", http.StatusNotFound)
+			return
+		}
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bucketPath := bucketName
+	if tenantID != "" {
+		bucketPath = tenantID + "/" + bucketName
+	}
+
+	events, next, truncated, err := s.eventLog.List(ctx, bucketPath, r.URL.Query().Get("since"), limit)
+	if err != nil {
+		if errors.Is(err, eventlog.ErrInvalidCursor) {
+			s.writeError(w, "since must be a cursor returned by this endpoint", http.StatusBadRequest)
+			return
+		}
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, bucketEventsResponse{
+		Bucket:      bucketName,
+		Events:      events,
+		NextCursor:  next,
+		IsTruncated: truncated,
+	})
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/eventlog"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetBucketEvents(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+	server.eventLog = eventlog.New(server.metadataStore.(metadata.RawKVStore))
+
+	require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{ID: "events-tenant", Name: "events-tenant", Status: "active"}))
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "events-tenant", "feed", ""))
+	tenantUser := &auth.User{ID: "u1", TenantID: "events-tenant", Roles: []string{auth.RoleAdmin}}
+
+	get := func(user *auth.User, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/buckets/feed/events"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		req = mux.SetURLVars(req, map[string]string{"bucket": "feed"})
+		rr := httptest.NewRecorder()
+		server.handleGetBucketEvents(rr, req)
+		return rr
+	}
+	poll := func(query string) bucketEventsResponse {
+		rr := get(tenantUser, query)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var body struct {
+			Data bucketEventsResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return body.Data
+	}
+
+	first := poll("")
+	assert.Equal(t, "feed", first.Bucket)
+	assert.Empty(t, first.Events)
+	require.NotEmpty(t, first.NextCursor)
+
+	for _, key := range []string{"a.txt", "b.txt", "c.txt"} {
+		require.NoError(t, server.eventLog.Append(ctx, "events-tenant/feed", eventlog.Event{EventName: "s3:ObjectCreated:Put", Key: key}))
+	}
+
+	page := poll("?since=" + first.NextCursor + "&limit=2")
+	require.Len(t, page.Events, 2)
+	assert.Equal(t, "a.txt", page.Events[0].Key)
+	assert.True(t, page.IsTruncated)
+
+	page = poll("?since=" + page.NextCursor)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, "c.txt", page.Events[0].Key)
+	assert.False(t, page.IsTruncated)
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get(tenantUser, "?since=abc").Code)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get(tenantUser, "?limit=0").Code)
+	})
+
+	t.Run("other tenant's user", func(t *testing.T) {
+		other := &auth.User{ID: "u2", TenantID: "other-tenant", Roles: []string{auth.RoleAdmin}}
+		assert.Equal(t, http.StatusNotFound, get(other, "").Code)
+	})
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportRequest(user *auth.User, bucketName, query string) *http.Request {
+	req := httptest.NewRequest("GET", "/api/v1/buckets/"+bucketName+"/export"+query, nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	return mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+}
+
+func readExportLines(t *testing.T, rr *httptest.ResponseRecorder) []exportRecord {
+	t.Helper()
+	var records []exportRecord
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var rec exportRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec), "line is not valid JSON: %q", scanner.Text())
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestHandleExportBucket(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	admin, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+
+	bucketName := "catalog-export"
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", bucketName, admin.ID))
+
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		dir := "logs"
+		if i%4 == 0 {
+			dir = "images"
+		}
+		require.NoError(t, server.metadataStore.PutObject(ctx, &metadata.ObjectMetadata{
+			Bucket:       bucketName,
+			Key:          fmt.Sprintf("%s/obj-%04d", dir, i),
+			Size:         int64(100 + i),
+			ETag:         fmt.Sprintf("etag-%04d", i),
+			ContentType:  "text/plain",
+			LastModified: modified,
+			Metadata:     map[string]string{"index": fmt.Sprint(i)},
+		}))
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleExportBucket(rr, exportRequest(admin, bucketName, ""))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+	records := readExportLines(t, rr)
+	require.Len(t, records, 1000)
+
+	var sample *exportRecord
+	for i := range records {
+		if records[i].Key == "logs/obj-0007" {
+			sample = &records[i]
+		}
+	}
+	require.NotNil(t, sample, "expected logs/obj-0007 in export")
+	assert.Equal(t, int64(107), sample.Size)
+	assert.Equal(t, "etag-0007", sample.ETag)
+	assert.Equal(t, "text/plain", sample.ContentType)
+	assert.Equal(t, "2026-03-01T12:00:00Z", sample.LastModified)
+	assert.Equal(t, map[string]string{"index": "7"}, sample.Metadata)
+
+	t.Run("prefix filter", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		server.handleExportBucket(rr, exportRequest(admin, bucketName, "?prefix=images/"))
+		require.Equal(t, http.StatusOK, rr.Code)
+		records := readExportLines(t, rr)
+		assert.Len(t, records, 250)
+		for _, rec := range records {
+			assert.Contains(t, rec.Key, "images/")
+		}
+	})
+
+	t.Run("non-owner is forbidden", func(t *testing.T) {
+		other := &auth.User{ID: "other-user", Username: "other", Roles: []string{"user"}}
+		rr := httptest.NewRecorder()
+		server.handleExportBucket(rr, exportRequest(other, bucketName, ""))
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("missing bucket", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		server.handleExportBucket(rr, exportRequest(admin, "does-not-exist", ""))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
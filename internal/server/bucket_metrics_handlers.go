@@ -0,0 +1,132 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/metrics"
+)
+
+// maxBucketHotKeys caps the ?top= parameter of the bucket metrics endpoint.
+const maxBucketHotKeys = 100
+
+// bucketRequestMetricsMiddleware counts every S3 request that targets a bucket
+// against that bucket, and object requests against their key, for the
+// per-bucket request metrics.
+func (s *Server) bucketRequestMetricsMiddleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			vars := mux.Vars(r)
+			bucketName := vars["bucket"]
+			if bucketName == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			wrapped := &metricsResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+
+			if bucketPath := s.requestBucketPath(r, bucketName, wrapped.statusCode); bucketPath != "" {
+				s.metricsManager.RecordBucketRequest(bucketPath, vars["object"], r.Method)
+			}
+		})
+	}
+}
+
+// requestBucketPath returns the tenant-prefixed path of the bucket an S3
+// request addressed, or "" if the bucket doesn't exist. Only existing buckets
+// are tracked so requests for made-up names can't grow the metrics without
+// bound. The caller's own tenant is checked first; buckets of other tenants
+// (ACL grants, public access) are looked up by name, but only for successful
+// requests since that lookup scans all buckets.
+func (s *Server) requestBucketPath(r *http.Request, bucketName string, status int) string {
+	ctx := r.Context()
+	tenantID := ""
+	if user, ok := auth.GetUserFromContext(ctx); ok {
+		tenantID = user.TenantID
+	}
+
+	exists, err := s.metadataStore.BucketExists(ctx, tenantID, bucketName)
+	if err != nil {
+		return ""
+	}
+	if !exists {
+		if status >= http.StatusBadRequest {
+			return ""
+		}
+		meta, err := s.metadataStore.GetBucketByName(ctx, bucketName)
+		if err != nil {
+			return ""
+		}
+		tenantID = meta.TenantID
+	}
+
+	if tenantID == "" {
+		return bucketName
+	}
+	return tenantID + "/" + bucketName
+}
+
+// handleGetBucketMetrics returns the bucket's request counts per operation,
+// its request rates over the last minute and its most-accessed keys.
+// GET /api/v1/buckets/{bucket}/metrics?top=N
+//
+// Counts are kept in memory by the node serving the bucket and start over
+// when it restarts.
+func (s *Server) handleGetBucketMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucketName := mux.Vars(r)["bucket"]
+
+	// Requests for a bucket are served, and counted, by its owner node
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return
+	}
+
+	currentUser, ok := auth.GetUserFromContext(ctx)
+	if !ok {
+		s.writeError(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	topN := metrics.DefaultHotKeys
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxBucketHotKeys {
+			s.writeError(w, "top must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		topN = n
+	}
+
+	tenantID := s.resolveBucketQuotaTenant(r, currentUser)
+	if _, err := s.bucketManager.GetBucketInfo(ctx, tenantID, bucketName); err != nil {
+		if err == bucket.ErrBucketNotFound {
+			s.writeError(w, "Bucket not found", http.StatusNotFound)
+			return
+		}
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bucketPath := bucketName
+	if tenantID != "" {
+		bucketPath = tenantID + "/" + bucketName
+	}
+
+	result := s.metricsManager.GetBucketRequestMetrics(bucketPath, topN)
+	if result == nil {
+		// No requests seen yet, or metrics are disabled
+		result = &metrics.BucketRequestMetrics{
+			Totals:        map[string]uint64{},
+			RatePerSecond: map[string]float64{},
+			HotKeys:       []metrics.HotKey{},
+		}
+	}
+	// Report the name the console knows the bucket by
+	result.Bucket = bucketName
+
+	s.writeJSON(w, result)
+}
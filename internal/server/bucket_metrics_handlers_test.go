@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetBucketMetrics(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{ID: "metrics-tenant", Name: "metrics-tenant", Status: "active"}))
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "metrics-tenant", "assets", ""))
+	tenantUser := &auth.User{ID: "u1", TenantID: "metrics-tenant", Roles: []string{auth.RoleAdmin}}
+
+	// S3 routes as the API handler registers them, behind the metrics middleware
+	router := mux.NewRouter()
+	s3Router := router.PathPrefix("/").Subrouter()
+	s3Router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "user", tenantUser)))
+		})
+	})
+	s3Router.Use(server.bucketRequestMetricsMiddleware())
+	s3Router.HandleFunc("/{bucket}", func(w http.ResponseWriter, r *http.Request) {})
+	s3Router.HandleFunc("/{bucket}/{object:.+}", func(w http.ResponseWriter, r *http.Request) {})
+
+	send := func(method, path string) {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	}
+	for i := 0; i < 5; i++ {
+		send("GET", "/assets/css/site.css")
+	}
+	send("GET", "/assets/logo.png")
+	send("PUT", "/assets/logo.png")
+	send("DELETE", "/assets/old.txt")
+	send("GET", "/assets")
+	send("GET", "/no-such-bucket/key") // not tracked
+
+	get := func(user *auth.User, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/buckets/assets/metrics"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		req = mux.SetURLVars(req, map[string]string{"bucket": "assets"})
+		rr := httptest.NewRecorder()
+		server.handleGetBucketMetrics(rr, req)
+		return rr
+	}
+
+	rr := get(tenantUser, "?top=2")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var body struct {
+		Data metrics.BucketRequestMetrics `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	m := body.Data
+	assert.Equal(t, "assets", m.Bucket)
+	assert.Equal(t, uint64(7), m.Totals["GET"])
+	assert.Equal(t, uint64(1), m.Totals["PUT"])
+	assert.Equal(t, uint64(1), m.Totals["DELETE"])
+	assert.Greater(t, m.RatePerSecond["GET"], 0.0)
+	require.Len(t, m.HotKeys, 2)
+	assert.Equal(t, "css/site.css", m.HotKeys[0].Key)
+	assert.Equal(t, uint64(5), m.HotKeys[0].Count)
+	assert.Equal(t, "logo.png", m.HotKeys[1].Key)
+	assert.Equal(t, uint64(2), m.HotKeys[1].Count)
+
+	assert.Nil(t, server.metricsManager.GetBucketRequestMetrics("no-such-bucket", 10))
+	assert.Nil(t, server.metricsManager.GetBucketRequestMetrics("metrics-tenant/no-such-bucket", 10))
+
+	t.Run("invalid top", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get(tenantUser, "?top=0").Code)
+	})
+
+	t.Run("other tenant's user", func(t *testing.T) {
+		other := &auth.User{ID: "u2", TenantID: "other-tenant", Roles: []string{auth.RoleAdmin}}
+		assert.Equal(t, http.StatusNotFound, get(other, "").Code)
+	})
+}
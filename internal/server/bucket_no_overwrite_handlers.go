@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// handlePutBucketNoOverwrite turns the bucket's write-once mode on or off.
+// While it is on, a PUT, copy or multipart completion onto a key that already
+// has a current object fails with 412 PreconditionFailed.
+// PUT /api/v1/buckets/{bucket}/no-overwrite
+// Body: {"enabled": <bool>}
+func (s *Server) handlePutBucketNoOverwrite(w http.ResponseWriter, r *http.Request) {
+	tenantID, bucketName, ok := s.bucketSettingTarget(w, r)
+	if !ok {
+		return
+	}
+
+	const invalidBody = "Body must be {\"enabled\": true|false}"
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if !s.decodeBucketSetting(w, r, &req, invalidBody) {
+		return
+	}
+	if req.Enabled == nil {
+		s.writeError(w, invalidBody, http.StatusBadRequest)
+		return
+	}
+
+	if !s.saveBucketSetting(w, r, tenantID, bucketName, func(ctx context.Context) error {
+		return s.bucketManager.SetNoOverwrite(ctx, tenantID, bucketName, *req.Enabled)
+	}, "Bucket no-overwrite mode updated", logrus.Fields{"enabled": *req.Enabled}) {
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{"noOverwrite": *req.Enabled})
+}
@@ -1,8 +1,8 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 
@@ -45,6 +45,20 @@ func (s *Server) resolveBucketQuotaTenant(r *http.Request, currentUser *auth.Use
 	return tenantID
 }
 
+// checkBucketQuotaWithinTenant applies auth.CheckBucketQuotaWithinTenant with
+// the tenant that owns the bucket. Buckets not owned by a tenant are
+// unconstrained.
+func (s *Server) checkBucketQuotaWithinTenant(ctx context.Context, info *bucket.Bucket, maxSizeBytes int64) error {
+	if info.OwnerType != "tenant" || info.OwnerID == "" {
+		return nil
+	}
+	tenant, err := s.authManager.GetTenant(ctx, info.OwnerID)
+	if err != nil {
+		return nil
+	}
+	return auth.CheckBucketQuotaWithinTenant(tenant, maxSizeBytes)
+}
+
 // handleGetBucketQuota returns the per-bucket storage quota and current usage.
 // GET /api/v1/buckets/{bucket}/quota
 func (s *Server) handleGetBucketQuota(w http.ResponseWriter, r *http.Request) {
@@ -134,18 +148,11 @@ func (s *Server) handlePutBucketQuota(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// A bucket that belongs to a tenant may not be given a size quota larger than
-	// the space assigned to that tenant — the tenant quota is the hard ceiling.
 	if req.MaxSizeBytes > 0 {
-		if info, err := s.bucketManager.GetBucketInfo(ctx, tenantID, bucketName); err == nil && info != nil &&
-			info.OwnerType == "tenant" && info.OwnerID != "" {
-			if tenant, terr := s.authManager.GetTenant(ctx, info.OwnerID); terr == nil && tenant != nil && tenant.MaxStorageBytes > 0 {
-				if req.MaxSizeBytes > tenant.MaxStorageBytes {
-					s.writeError(w, fmt.Sprintf(
-						"Bucket quota (%d bytes) cannot exceed the tenant's storage quota (%d bytes)",
-						req.MaxSizeBytes, tenant.MaxStorageBytes), http.StatusBadRequest)
-					return
-				}
+		if info, err := s.bucketManager.GetBucketInfo(ctx, tenantID, bucketName); err == nil && info != nil {
+			if err := s.checkBucketQuotaWithinTenant(ctx, info, req.MaxSizeBytes); err != nil {
+				s.writeError(w, err.Error(), http.StatusBadRequest)
+				return
 			}
 		}
 	}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/sirupsen/logrus"
+)
+
+// The console endpoints that change a single per-bucket setting (write lock,
+// no-overwrite, cache defaults, default content type, upload scanning,
+// version limit) share the steps below; each handler keeps only its own body
+// validation.
+
+// bucketSettingTarget does what every per-bucket setting handler does before
+// touching the setting. The request is proxied to the bucket's owner node,
+// where uploads and reads take the setting from, and the caller must be
+// allowed to configure buckets. It returns the bucket and its tenant, or
+// ok=false once a response has been written.
+func (s *Server) bucketSettingTarget(w http.ResponseWriter, r *http.Request) (tenantID, bucketName string, ok bool) {
+	bucketName = mux.Vars(r)["bucket"]
+
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return "", "", false
+	}
+
+	currentUser, ok := auth.GetUserFromContext(r.Context())
+	if !ok {
+		s.writeError(w, "User not found in context", http.StatusUnauthorized)
+		return "", "", false
+	}
+	if !s.requireCapability(w, r, auth.CapBucketConfigure, "You do not have permission to configure buckets") {
+		return "", "", false
+	}
+
+	return s.resolveBucketQuotaTenant(r, currentUser), bucketName, true
+}
+
+// decodeBucketSetting reads the JSON request body into v, answering 400 with
+// invalidMessage when it doesn't parse. It returns false once a response has
+// been written.
+func (s *Server) decodeBucketSetting(w http.ResponseWriter, r *http.Request, v interface{}, invalidMessage string) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, "Failed to read request body", http.StatusBadRequest)
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		s.writeError(w, invalidMessage, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// saveBucketSetting stores a setting through set and logs the change with
+// fields. A missing bucket answers 404 and any other failure 500; it returns
+// false once such a response has been written.
+func (s *Server) saveBucketSetting(w http.ResponseWriter, r *http.Request, tenantID, bucketName string, set func(ctx context.Context) error, message string, fields logrus.Fields) bool {
+	if err := set(r.Context()); err != nil {
+		if err == bucket.ErrBucketNotFound {
+			s.writeError(w, "Bucket not found", http.StatusNotFound)
+			return false
+		}
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	logFields := logrus.Fields{"bucket": bucketName, "tenant_id": tenantID}
+	for k, v := range fields {
+		logFields[k] = v
+	}
+	logrus.WithFields(logFields).Info(message)
+	return true
+}
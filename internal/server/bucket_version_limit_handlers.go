@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// handlePutBucketMaxVersions sets how many versions each key of a versioned
+// bucket may keep. A write that goes over the cap expires the oldest
+// noncurrent versions; locked versions are kept and still count. 0 = unlimited.
+// PUT /api/v1/buckets/{bucket}/max-versions
+// Body: {"maxVersionsPerObject": <int>}
+func (s *Server) handlePutBucketMaxVersions(w http.ResponseWriter, r *http.Request) {
+	tenantID, bucketName, ok := s.bucketSettingTarget(w, r)
+	if !ok {
+		return
+	}
+
+	const invalidBody = "Body must be {\"maxVersionsPerObject\": <non-negative integer>}"
+	var req struct {
+		MaxVersionsPerObject *int `json:"maxVersionsPerObject"`
+	}
+	if !s.decodeBucketSetting(w, r, &req, invalidBody) {
+		return
+	}
+	if req.MaxVersionsPerObject == nil || *req.MaxVersionsPerObject < 0 {
+		s.writeError(w, invalidBody, http.StatusBadRequest)
+		return
+	}
+
+	if !s.saveBucketSetting(w, r, tenantID, bucketName, func(ctx context.Context) error {
+		return s.bucketManager.SetMaxVersionsPerObject(ctx, tenantID, bucketName, *req.MaxVersionsPerObject)
+	}, "Bucket version limit updated", logrus.Fields{"max_versions_per_object": *req.MaxVersionsPerObject}) {
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{"maxVersionsPerObject": *req.MaxVersionsPerObject})
+}
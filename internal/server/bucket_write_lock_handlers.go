@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxDefaultWriteLockDays caps the default write lock at 100 years, the same
+// ceiling S3 applies to Object Lock default retention.
+const maxDefaultWriteLockDays = 36500
+
+// handlePutBucketWriteLock sets the bucket's default write lock: every object
+// written afterwards gets a GOVERNANCE retention of that many days, without
+// enabling Object Lock or versioning.
+// PUT /api/v1/buckets/{bucket}/write-lock
+// Body: {"days": <int>}  (0 turns the write lock off)
+func (s *Server) handlePutBucketWriteLock(w http.ResponseWriter, r *http.Request) {
+	tenantID, bucketName, ok := s.bucketSettingTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Days int `json:"days"`
+	}
+	if !s.decodeBucketSetting(w, r, &req, "Invalid JSON body") {
+		return
+	}
+	if req.Days < 0 || req.Days > maxDefaultWriteLockDays {
+		s.writeError(w, "days must be between 0 and 36500", http.StatusBadRequest)
+		return
+	}
+
+	if !s.saveBucketSetting(w, r, tenantID, bucketName, func(ctx context.Context) error {
+		return s.bucketManager.SetDefaultWriteLock(ctx, tenantID, bucketName, req.Days)
+	}, "Bucket default write lock updated", logrus.Fields{"days": req.Days}) {
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{"defaultWriteLockDays": req.Days})
+}
+
+// handleDeleteBucketWriteLock turns the default write lock off. Objects that
+// were already written keep their retention.
+// DELETE /api/v1/buckets/{bucket}/write-lock
+func (s *Server) handleDeleteBucketWriteLock(w http.ResponseWriter, r *http.Request) {
+	tenantID, bucketName, ok := s.bucketSettingTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if !s.saveBucketSetting(w, r, tenantID, bucketName, func(ctx context.Context) error {
+		return s.bucketManager.SetDefaultWriteLock(ctx, tenantID, bucketName, 0)
+	}, "Bucket default write lock removed", nil) {
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{"success": true})
+}
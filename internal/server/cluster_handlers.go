@@ -114,6 +114,18 @@ func (s *Server) handleJoinCluster(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Adopt the master key for stored access key secrets, which are
+	// replicated encrypted. A node with a different configured key can't.
+	if pkg.SecretEncryptionKey != "" {
+		if adopter, ok := s.authManager.(interface{ AdoptSecretEncryptionKey(string) error }); ok {
+			if err := adopter.AdoptSecretEncryptionKey(pkg.SecretEncryptionKey); err != nil {
+				logrus.WithError(err).Error("Failed to adopt cluster secret encryption key")
+				s.writeError(w, "Failed to join cluster: "+err.Error(), http.StatusConflict)
+				return
+			}
+		}
+	}
+
 	// Synchronize the cluster JWT secret so cross-node sessions work immediately.
 	if pkg.JWTSecret != "" {
 		if setter, ok := s.authManager.(interface{ SetJWTSecret(string) }); ok {
@@ -404,6 +416,10 @@ func (s *Server) handleAddClusterNode(w http.ResponseWriter, r *http.Request) {
 
 	var jwtSecret string
 	_ = s.db.QueryRow(`SELECT value FROM system_settings WHERE key = ?`, "jwt_secret").Scan(&jwtSecret)
+	var secretEncryptionKey string
+	if km, ok := s.authManager.(interface{ SecretEncryptionKey() string }); ok {
+		secretEncryptionKey = km.SecretEncryptionKey()
+	}
 
 	nodes, err := s.clusterManager.ListNodes(r.Context())
 	if err != nil {
@@ -431,18 +447,19 @@ func (s *Server) handleAddClusterNode(w http.ResponseWriter, r *http.Request) {
 	// Node B generates its own cert+key using the CA once it receives this package.
 	// CAKeyPEM is included so Node B can sign a cert with its own IP in the SANs.
 	pkg := cluster.ClusterJoinPackage{
-		NodeID:         nodeID,
-		NodeName:       nodeName,
-		ClusterToken:   config.ClusterToken,
-		Region:         config.Region,
-		CACertPEM:      caCertPEM,
-		CAKeyPEM:       caKeyPEM,
-		JWTSecret:      jwtSecret,
-		SelfEndpoint:   remoteClusterURL,
-		NodeEndpoint:   localClusterEndpoint,
-		APIURL:         remoteAPIURL,
-		Nodes:          cluster.NodesToJoinPackage(nodes),
-		EncryptionKeys: clusterKeys,
+		NodeID:              nodeID,
+		NodeName:            nodeName,
+		ClusterToken:        config.ClusterToken,
+		Region:              config.Region,
+		CACertPEM:           caCertPEM,
+		CAKeyPEM:            caKeyPEM,
+		JWTSecret:           jwtSecret,
+		SecretEncryptionKey: secretEncryptionKey,
+		SelfEndpoint:        remoteClusterURL,
+		NodeEndpoint:        localClusterEndpoint,
+		APIURL:              remoteAPIURL,
+		Nodes:               cluster.NodesToJoinPackage(nodes),
+		EncryptionKeys:      clusterKeys,
 	}
 
 	// Step 4: Push the join package to Node B via 8081
@@ -106,7 +106,8 @@ func (s *Server) handleReceiveObjectReplication(w http.ResponseWriter, r *http.R
 			return
 		}
 
-		_, err := s.objectManager.PutObject(ctx, bucketPath, key, r.Body, headers)
+		// The source node already accepted the key, reserved prefix or not
+		_, err := s.objectManager.PutObject(object.WithSystemWrite(ctx), bucketPath, key, r.Body, headers)
 		if err != nil {
 			logrus.WithError(err).Error("Failed to store replicated object")
 			http.Error(w, fmt.Sprintf("Failed to store object: %v", err), http.StatusInternalServerError)
@@ -600,6 +601,9 @@ func (s *Server) handleReceiveAccessKeySync(w http.ResponseWriter, r *http.Reque
 		Status          string `json:"status"`
 		CreatedAt       int64  `json:"created_at"`
 		LastUsed        *int64 `json:"last_used,omitempty"`
+		Name            string `json:"name,omitempty"`
+		Description     string `json:"description,omitempty"`
+		Tags            string `json:"tags,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&accessKeyData); err != nil {
@@ -626,8 +630,8 @@ func (s *Server) handleReceiveAccessKeySync(w http.ResponseWriter, r *http.Reque
 	// Upsert access key in database (INSERT OR REPLACE)
 	query := `
 		INSERT OR REPLACE INTO access_keys
-		(access_key_id, secret_access_key, user_id, status, created_at, last_used)
-		VALUES (?, ?, ?, ?, ?, ?)
+		(access_key_id, secret_access_key, user_id, status, created_at, last_used, name, description, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := s.db.ExecContext(ctx, query,
@@ -637,6 +641,9 @@ func (s *Server) handleReceiveAccessKeySync(w http.ResponseWriter, r *http.Reque
 		accessKeyData.Status,
 		accessKeyData.CreatedAt,
 		accessKeyData.LastUsed,
+		accessKeyData.Name,
+		accessKeyData.Description,
+		accessKeyData.Tags,
 	)
 
 	if err != nil {
@@ -1941,6 +1948,9 @@ func (s *Server) handleGetLocalAuditLogs(w http.ResponseWriter, r *http.Request)
 	if v := q.Get("resource_type"); v != "" {
 		filters.ResourceType = v
 	}
+	if v := q.Get("resource_id"); v != "" {
+		filters.ResourceID = v
+	}
 	if v := q.Get("action"); v != "" {
 		filters.Action = v
 	}
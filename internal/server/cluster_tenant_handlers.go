@@ -36,6 +36,8 @@ func (s *Server) handleReceiveTenantSync(w http.ResponseWriter, r *http.Request)
 		MaxAccessKeys       int               `json:"max_access_keys"`
 		MaxStorageBytes     int64             `json:"max_storage_bytes"`
 		MaxBandwidthBytesPerSec int64         `json:"max_bandwidth_bytes_per_sec"`
+		MaxFailedLoginAttempts int           `json:"max_failed_login_attempts"`
+		LockoutDurationSeconds int64         `json:"lockout_duration_seconds"`
 		CurrentStorageBytes int64             `json:"current_storage_bytes"`
 		MaxBuckets          int               `json:"max_buckets"`
 		CurrentBuckets      int               `json:"current_buckets"`
@@ -94,6 +96,8 @@ func (s *Server) upsertTenant(ctx context.Context, tenant *struct {
 	MaxAccessKeys       int               `json:"max_access_keys"`
 	MaxStorageBytes     int64             `json:"max_storage_bytes"`
 	MaxBandwidthBytesPerSec int64         `json:"max_bandwidth_bytes_per_sec"`
+	MaxFailedLoginAttempts int           `json:"max_failed_login_attempts"`
+	LockoutDurationSeconds int64         `json:"lockout_duration_seconds"`
 	CurrentStorageBytes int64             `json:"current_storage_bytes"`
 	MaxBuckets          int               `json:"max_buckets"`
 	CurrentBuckets      int               `json:"current_buckets"`
@@ -135,6 +139,8 @@ func (s *Server) upsertTenant(ctx context.Context, tenant *struct {
 				max_access_keys = ?,
 				max_storage_bytes = ?,
 				max_bandwidth_bytes_per_sec = ?,
+				max_failed_login_attempts = ?,
+				lockout_duration_seconds = ?,
 				current_storage_bytes = ?,
 				max_buckets = ?,
 				current_buckets = ?,
@@ -149,6 +155,8 @@ func (s *Server) upsertTenant(ctx context.Context, tenant *struct {
 			tenant.MaxAccessKeys,
 			tenant.MaxStorageBytes,
 			tenant.MaxBandwidthBytesPerSec,
+			tenant.MaxFailedLoginAttempts,
+			tenant.LockoutDurationSeconds,
 			tenant.CurrentStorageBytes,
 			tenant.MaxBuckets,
 			tenant.CurrentBuckets,
@@ -166,9 +174,10 @@ func (s *Server) upsertTenant(ctx context.Context, tenant *struct {
 		_, err = s.db.ExecContext(ctx, `
 			INSERT INTO tenants (
 				id, name, display_name, description, status,
-				max_access_keys, max_storage_bytes, max_bandwidth_bytes_per_sec, current_storage_bytes,
+				max_access_keys, max_storage_bytes, max_bandwidth_bytes_per_sec,
+				max_failed_login_attempts, lockout_duration_seconds, current_storage_bytes,
 				max_buckets, current_buckets, metadata, created_at, updated_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`,
 			tenant.ID,
 			tenant.Name,
@@ -178,6 +187,8 @@ func (s *Server) upsertTenant(ctx context.Context, tenant *struct {
 			tenant.MaxAccessKeys,
 			tenant.MaxStorageBytes,
 			tenant.MaxBandwidthBytesPerSec,
+			tenant.MaxFailedLoginAttempts,
+			tenant.LockoutDurationSeconds,
 			tenant.CurrentStorageBytes,
 			tenant.MaxBuckets,
 			tenant.CurrentBuckets,
@@ -264,6 +275,7 @@ func (s *Server) handleReceiveUserSync(w http.ResponseWriter, r *http.Request) {
 		ExternalID          string                           `json:"external_id"`
 		CreatedAt           int64                            `json:"created_at"`
 		UpdatedAt           int64                            `json:"updated_at"`
+		PasswordChangedAt   int64                            `json:"password_changed_at"`
 		CapabilityOverrides []cluster.CapabilityOverrideData `json:"capability_overrides,omitempty"`
 	}
 
@@ -328,6 +340,7 @@ func (s *Server) upsertUser(ctx context.Context, user *struct {
 	ExternalID          string                           `json:"external_id"`
 	CreatedAt           int64                            `json:"created_at"`
 	UpdatedAt           int64                            `json:"updated_at"`
+	PasswordChangedAt   int64                            `json:"password_changed_at"`
 	CapabilityOverrides []cluster.CapabilityOverrideData `json:"capability_overrides,omitempty"`
 }) error {
 	// Handle NULL values for tenant_id
@@ -373,7 +386,8 @@ func (s *Server) upsertUser(ctx context.Context, user *struct {
 				language_preference = ?,
 				auth_provider = ?,
 				external_id = ?,
-				updated_at = ?
+				updated_at = ?,
+				password_changed_at = ?
 			WHERE id = ?
 		`,
 			user.Username,
@@ -393,6 +407,7 @@ func (s *Server) upsertUser(ctx context.Context, user *struct {
 			user.AuthProvider,
 			user.ExternalID,
 			user.UpdatedAt,
+			user.PasswordChangedAt,
 			user.ID,
 		)
 		if err != nil {
@@ -407,8 +422,8 @@ func (s *Server) upsertUser(ctx context.Context, user *struct {
 				id, username, password_hash, display_name, email, status, tenant_id,
 				roles, policies, metadata, failed_login_attempts, locked_until,
 				last_failed_login, theme_preference, language_preference,
-				auth_provider, external_id, created_at, updated_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				auth_provider, external_id, created_at, updated_at, password_changed_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`,
 			user.ID,
 			user.Username,
@@ -429,6 +444,7 @@ func (s *Server) upsertUser(ctx context.Context, user *struct {
 			user.ExternalID,
 			user.CreatedAt,
 			user.UpdatedAt,
+			user.PasswordChangedAt,
 		)
 		if err != nil {
 			// Handle username uniqueness conflict: incoming user has same username but
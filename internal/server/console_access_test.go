@@ -10,6 +10,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/config"
 	"github.com/maxiofs/maxiofs/internal/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -89,3 +90,45 @@ func TestRefreshTokenResponseIsWrappedConsoleAPIResponse(t *testing.T) {
 	assert.NotEmpty(t, response.Data["refresh_token"])
 	assert.NotEmpty(t, response.Data["token_type"])
 }
+
+func TestConsoleAPICORS(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	server.config.PublicConsoleURL = "https://console.example.com/ui"
+	server.config.ConsoleCORS = config.ConsoleCORSConfig{AllowCredentials: true}
+
+	preflight := func(origin string) http.Header {
+		router := mux.NewRouter()
+		server.setupConsoleAPIRoutes(router)
+		req := httptest.NewRequest("OPTIONS", "/auth/me", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Header()
+	}
+
+	// By default only the console's own origin, without its path, is allowed
+	allowed := preflight("https://console.example.com")
+	assert.Equal(t, "https://console.example.com", allowed.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", allowed.Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, allowed.Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Contains(t, allowed.Get("Access-Control-Allow-Methods"), "PATCH")
+
+	denied := preflight("https://evil.example.com")
+	assert.Empty(t, denied.Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, denied.Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, denied.Get("Access-Control-Allow-Headers"))
+	assert.Empty(t, denied.Get("Access-Control-Allow-Methods"))
+
+	// A configured allowlist replaces the defaults
+	server.config.ConsoleCORS = config.ConsoleCORSConfig{
+		AllowedOrigins: []string{"https://admin.example.com"},
+		AllowedMethods: []string{"GET"},
+	}
+	allowed = preflight("https://admin.example.com")
+	assert.Equal(t, "https://admin.example.com", allowed.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET", allowed.Get("Access-Control-Allow-Methods"))
+	assert.Empty(t, allowed.Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, preflight("https://console.example.com").Get("Access-Control-Allow-Origin"))
+}
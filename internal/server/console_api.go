@@ -12,11 +12,12 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
-	"unicode"
 
 	"github.com/gorilla/mux"
 	"github.com/maxiofs/maxiofs/internal/acl"
@@ -25,11 +26,14 @@ import (
 	"github.com/maxiofs/maxiofs/internal/bucket"
 	"github.com/maxiofs/maxiofs/internal/cluster"
 	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/metrics"
 	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/maxiofs/maxiofs/internal/notifications"
 	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/maxiofs/maxiofs/internal/presigned"
 	"github.com/maxiofs/maxiofs/internal/settings"
+	"github.com/maxiofs/maxiofs/internal/share"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -58,6 +62,20 @@ type BucketResponse struct {
 	Lifecycle           *bucket.LifecycleConfig   `json:"lifecycle,omitempty"`
 	Tags                map[string]string         `json:"tags,omitempty"`
 	Metadata            map[string]string         `json:"metadata,omitempty"`
+	// Days of GOVERNANCE retention applied to each new object (0 = off)
+	DefaultWriteLockDays int `json:"defaultWriteLockDays,omitempty"`
+	// Write-once mode: existing keys can't be overwritten
+	NoOverwrite bool `json:"noOverwrite,omitempty"`
+	// Versions kept per key in a versioned bucket (0 = unlimited)
+	MaxVersionsPerObject int `json:"maxVersionsPerObject,omitempty"`
+	// Default GET/HEAD caching headers for objects without their own
+	CacheDefaults *bucketCacheDefaultsPayload `json:"cacheDefaults,omitempty"`
+	// Content-Type for uploads that declare none and can't be sniffed
+	DefaultContentType string `json:"defaultContentType,omitempty"`
+	// Uploads pass the upload scanner before they become visible
+	ScanUploads bool `json:"scanUploads,omitempty"`
+	// Per-bucket limits; usage is ObjectCount and Size above
+	Quota *bucketQuotaPayload `json:"quota,omitempty"`
 	// Cluster-specific fields (only populated in multi-node cluster mode)
 	NodeID     string `json:"node_id,omitempty"`
 	NodeName   string `json:"node_name,omitempty"`
@@ -103,6 +121,10 @@ type MetricsResponse struct {
 
 const consoleJSONBodyLimitBytes = 1 << 20
 
+// listMetadataMaxKeys caps the page size of an object listing that embeds
+// user metadata (includeMetadata=true), bounding the response size.
+const listMetadataMaxKeys = 1000
+
 // metricsResponseWriter wraps http.ResponseWriter to capture status code
 type metricsResponseWriter struct {
 	http.ResponseWriter
@@ -152,7 +174,12 @@ func shouldLimitConsoleBody(r *http.Request) bool {
 		return false
 	}
 
-	return !isConsoleObjectUploadPath(r.Method, consoleAPIRelativePath(r.URL.Path))
+	relPath := consoleAPIRelativePath(r.URL.Path)
+	// Tenant imports stream an archive of the tenant's objects
+	if r.Method == http.MethodPost && relPath == "/tenants/import" {
+		return false
+	}
+	return !isConsoleObjectUploadPath(r.Method, relPath)
 }
 
 // setupConsoleAPIRoutes registers all console API routes
@@ -189,18 +216,7 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 	})
 
 	// Use the proper CORS middleware with origin validation instead of wildcard "*"
-	corsConfig := middleware.DefaultCORSConfig()
-	// Always allow direct local access, regardless of public_console_url.
-	if host, port, err := net.SplitHostPort(s.config.ConsoleListen); err == nil {
-		if host == "" || host == "0.0.0.0" || host == "::" {
-			host = "localhost"
-		}
-		corsConfig.AllowedOrigins = append(corsConfig.AllowedOrigins, "http://"+host+":"+port)
-	}
-	// Also allow the configured public URL (for reverse-proxy / remote browser access).
-	if s.config.PublicConsoleURL != "" {
-		corsConfig.AllowedOrigins = append(corsConfig.AllowedOrigins, s.config.PublicConsoleURL)
-	}
+	corsConfig := s.consoleCORSConfig()
 	router.Use(middleware.CORSWithConfig(corsConfig))
 
 	// Authentication middleware - validates JWT and adds user to context
@@ -294,6 +310,7 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 			//   /auth/*        — login, logout, 2FA, OAuth
 			//   /health        — health checks
 			//   /settings      — so the admin can turn maintenance mode off
+			//   /admin/maintenance — likewise
 			//   /api/internal/ — cluster sync must not be blocked
 			//   /notifications — SSE stream must stay open
 			// Exempt paths from maintenance mode.
@@ -317,6 +334,7 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 				exempt := strings.HasPrefix(mmRel, "/auth/") ||
 					mmRel == "/health" ||
 					strings.HasPrefix(mmRel, "/settings") ||
+					mmRel == "/admin/maintenance" ||
 					strings.HasPrefix(mmRel, "/notifications") ||
 					strings.HasPrefix(mmURLPath, "/api/internal/")
 				if exempt {
@@ -324,8 +342,7 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 					return
 				}
 			}
-			enabled, err := s.settingsManager.GetBool("system.maintenance_mode")
-			if err == nil && enabled {
+			if s.maintenanceEnabled() {
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusServiceUnavailable)
 				json.NewEncoder(w).Encode(map[string]interface{}{
@@ -365,6 +382,10 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 	router.HandleFunc("/buckets/{bucket}/integrity-status", s.handleGetIntegrityStatus).Methods("GET", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/integrity-status", s.handleSaveIntegrityStatus).Methods("POST", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/download-zip", s.handleDownloadZip).Methods("GET", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/export", s.handleExportBucket).Methods("GET", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/batch-tag", s.handleBatchTagObjects).Methods("POST", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/metrics", s.handleGetBucketMetrics).Methods("GET", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/events", s.handleGetBucketEvents).Methods("GET", "OPTIONS")
 
 	// Replication endpoints
 	router.HandleFunc("/buckets/{bucket}/replication/rules", s.handleListReplicationRules).Methods("GET", "OPTIONS")
@@ -383,6 +404,7 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 
 	// Share endpoints (MUST be registered BEFORE generic object endpoints to avoid route conflicts)
 	router.HandleFunc("/buckets/{bucket}/shares", s.handleListBucketShares).Methods("GET", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/shares/{id}/access-log", s.handleGetShareAccessLog).Methods("GET", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/share", s.handleShareObject).Methods("POST", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/share", s.handleDeleteShare).Methods("DELETE", "OPTIONS")
 
@@ -394,7 +416,10 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 
 	// Object extra endpoints (rename, tags, restore) — MUST be before generic {object:.*} GET/PUT/DELETE
 	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/rename", s.handleRenameObject).Methods("POST", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/move-to", s.handleMoveObject).Methods("POST", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/append", s.handleAppendObject).Methods("POST", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/restore", s.handleRestoreObjectVersion).Methods("POST", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/thumbnail", s.handleGetObjectThumbnail).Methods("GET", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/tags", s.handleGetObjectTags).Methods("GET", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/tags", s.handleSetObjectTags).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/objects/{object:.*}/acl", s.handleGetObjectACL).Methods("GET", "OPTIONS")
@@ -422,6 +447,7 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 	router.HandleFunc("/access-keys", s.handleListAllAccessKeys).Methods("GET", "OPTIONS")
 	router.HandleFunc("/users/{user}/access-keys", s.handleListAccessKeys).Methods("GET", "OPTIONS")
 	router.HandleFunc("/users/{user}/access-keys", s.handleCreateAccessKey).Methods("POST", "OPTIONS")
+	router.HandleFunc("/users/{user}/access-keys/{accessKey}", s.handleUpdateAccessKey).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/users/{user}/access-keys/{accessKey}", s.handleDeleteAccessKey).Methods("DELETE", "OPTIONS")
 
 	// Password management
@@ -460,10 +486,14 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 	// Tenant endpoints
 	router.HandleFunc("/tenants", s.handleListTenants).Methods("GET", "OPTIONS")
 	router.HandleFunc("/tenants", s.handleCreateTenant).Methods("POST", "OPTIONS")
+	router.HandleFunc("/tenants/import", s.handleImportTenant).Methods("POST", "OPTIONS")
 	router.HandleFunc("/tenants/{tenant}", s.handleGetTenant).Methods("GET", "OPTIONS")
 	router.HandleFunc("/tenants/{tenant}", s.handleUpdateTenant).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/tenants/{tenant}", s.handleDeleteTenant).Methods("DELETE", "OPTIONS")
 	router.HandleFunc("/tenants/{tenant}/users", s.handleListTenantUsers).Methods("GET", "OPTIONS")
+	router.HandleFunc("/tenants/{tenant}/usage", s.handleGetTenantUsage).Methods("GET", "OPTIONS")
+	router.HandleFunc("/tenants/{tenant}/export", s.handleExportTenant).Methods("GET", "OPTIONS")
+	router.HandleFunc("/tenants/{tenant}/encryption/rotate-key", s.handleRotateTenantKey).Methods("POST", "OPTIONS")
 
 	// Audit logs endpoints
 	router.HandleFunc("/audit-logs", s.handleListAuditLogs).Methods("GET", "OPTIONS")
@@ -482,6 +512,10 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 	router.HandleFunc("/settings/{key}", s.handleUpdateSetting).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/settings/bulk", s.handleBulkUpdateSettings).Methods("POST", "OPTIONS")
 
+	// Maintenance mode
+	router.HandleFunc("/admin/maintenance", s.handleGetMaintenance).Methods("GET", "OPTIONS")
+	router.HandleFunc("/admin/maintenance", s.handleSetMaintenance).Methods("POST", "OPTIONS")
+
 	// Logging endpoints
 	router.HandleFunc("/logs/frontend", s.handlePostFrontendLogs).Methods("POST", "OPTIONS")
 	router.HandleFunc("/logs/reconfigure", s.handleReconfigureLogging).Methods("POST", "OPTIONS")
@@ -524,10 +558,22 @@ func (s *Server) setupConsoleAPIRoutes(router *mux.Router) {
 	router.HandleFunc("/buckets/{bucket}/inventory", s.handleDeleteBucketInventory).Methods("DELETE", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/inventory/reports", s.handleListBucketInventoryReports).Methods("GET", "OPTIONS")
 
+	// In-progress multipart uploads
+	router.HandleFunc("/buckets/{bucket}/multipart-uploads", s.handleListBucketMultipartUploads).Methods("GET", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/multipart-uploads/{uploadId}", s.handleAbortBucketMultipartUpload).Methods("DELETE", "OPTIONS")
+
 	// Bucket storage quota endpoints
 	router.HandleFunc("/buckets/{bucket}/quota", s.handleGetBucketQuota).Methods("GET", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/quota", s.handlePutBucketQuota).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/buckets/{bucket}/quota", s.handleDeleteBucketQuota).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/write-lock", s.handlePutBucketWriteLock).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/write-lock", s.handleDeleteBucketWriteLock).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/no-overwrite", s.handlePutBucketNoOverwrite).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/max-versions", s.handlePutBucketMaxVersions).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/cache-defaults", s.handlePutBucketCacheDefaults).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/cache-defaults", s.handleDeleteBucketCacheDefaults).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/default-content-type", s.handlePutBucketDefaultContentType).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/buckets/{bucket}/upload-scan", s.handlePutBucketUploadScan).Methods("PUT", "OPTIONS")
 
 	// Bucket static website hosting endpoints
 	router.HandleFunc("/buckets/{bucket}/website", s.handleGetBucketWebsite).Methods("GET", "OPTIONS")
@@ -677,6 +723,10 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	var loginReq struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
+		// NewPassword replaces an expired password as part of the login
+		NewPassword string `json:"new_password,omitempty"`
+		// TOTPCode must accompany NewPassword when the user has 2FA enabled
+		TOTPCode string `json:"totp_code,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
@@ -685,7 +735,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get client IP address
-	clientIP := getClientIP(r, s.config.TrustedProxies)
+	clientIP := middleware.ClientIP(r, s.config.TrustedProxies)
 
 	// Log login attempt
 	logrus.WithFields(logrus.Fields{
@@ -742,12 +792,19 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 			"remaining_time": remainingTime.String(),
 		}).Warn("Login attempt on locked account")
 
+		// The lock lifts by itself at locked_until; tell the client when
+		retryAfter := lockedUntil - time.Now().Unix()
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
 		w.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"error":        "Account is locked due to multiple failed login attempts",
-			"locked_until": lockedUntil,
-			"locked":       true,
+			"error":               fmt.Sprintf("Account is locked due to multiple failed login attempts. It unlocks automatically at %s", time.Unix(lockedUntil, 0).UTC().Format(time.RFC3339)),
+			"locked_until":        lockedUntil,
+			"locked":              true,
+			"retry_after_seconds": retryAfter,
 		})
 		return
 	}
@@ -814,6 +871,23 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Step 5.1: An expired password must be replaced before a session is
+	// issued. With 2FA enabled the password alone must not be enough to
+	// change it, so the TOTP code comes in the same request and is verified
+	// first; the login then completes without a separate 2FA step.
+	twoFactorVerified := false
+	if s.passwordExpired(user) {
+		if twoFactorEnabled && loginReq.NewPassword != "" {
+			if !s.verifyLoginTOTP(w, r, user, loginReq.TOTPCode, clientIP) {
+				return
+			}
+			twoFactorVerified = true
+		}
+		if !s.rotateExpiredPassword(w, r, user, loginReq.Password, loginReq.NewPassword, twoFactorEnabled) {
+			return
+		}
+	}
+
 	// If require_2fa_admin is enabled and the user is an admin without 2FA → block login.
 	if !twoFactorEnabled {
 		require2FAAdmin, _ := s.settingsManager.GetBool("security.require_2fa_admin")
@@ -834,7 +908,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 	// If 2FA is enabled, don't record successful login yet
 	// Instead, return a response indicating 2FA is required
-	if twoFactorEnabled {
+	if twoFactorEnabled && !twoFactorVerified {
 		logrus.WithFields(logrus.Fields{
 			"user_id":  user.ID,
 			"username": user.Username,
@@ -917,6 +991,90 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// rotateExpiredPassword replaces the expired password of a user who has just
+// presented it (and, with 2FA enabled, a valid TOTP code). Without a new
+// password it answers 403 with password_expired set, and requires_2fa when
+// the client must send the TOTP code along. It returns true once the password
+// has been changed and the login may go on.
+func (s *Server) rotateExpiredPassword(w http.ResponseWriter, r *http.Request, user *auth.User, currentPassword, newPassword string, requires2FA bool) bool {
+	if newPassword == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":            "Your password has expired. Choose a new password to continue.",
+			"password_expired": true,
+			"requires_2fa":     requires2FA,
+		})
+		return false
+	}
+	if newPassword == currentPassword {
+		s.writeError(w, "The new password must differ from the expired one", http.StatusBadRequest)
+		return false
+	}
+	if msg := s.validatePasswordPolicy(newPassword); msg != "" {
+		s.writeError(w, msg, http.StatusBadRequest)
+		return false
+	}
+
+	hashedPassword, err := auth.HashPassword(newPassword)
+	if err != nil {
+		s.writeError(w, "Failed to hash new password", http.StatusInternalServerError)
+		return false
+	}
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now().Unix()
+	if err := s.authManager.UpdateUser(r.Context(), user); err != nil {
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	s.logAuditEvent(r.Context(), &audit.AuditEvent{
+		TenantID:     user.TenantID,
+		UserID:       user.ID,
+		Username:     user.Username,
+		EventType:    audit.EventTypePasswordChanged,
+		ResourceType: audit.ResourceTypeUser,
+		ResourceID:   user.ID,
+		ResourceName: user.Username,
+		Action:       audit.ActionUpdate,
+		Status:       audit.StatusSuccess,
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
+		UserAgent:    r.Header.Get("User-Agent"),
+		Details: map[string]interface{}{
+			"reason": "password_expired",
+		},
+	})
+	return true
+}
+
+// verifyLoginTOTP checks the TOTP code sent with a login request. A missing
+// code answers 403 with password_expired and requires_2fa set; a wrong one
+// counts as a failed login, so it is subject to the account lockout.
+func (s *Server) verifyLoginTOTP(w http.ResponseWriter, r *http.Request, user *auth.User, code, clientIP string) bool {
+	if code == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":            "Enter your two-factor authentication code to change the expired password.",
+			"password_expired": true,
+			"requires_2fa":     true,
+		})
+		return false
+	}
+	valid, err := s.authManager.Verify2FACode(r.Context(), user.ID, code)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to verify 2FA code")
+		s.writeError(w, "Failed to verify 2FA code", http.StatusInternalServerError)
+		return false
+	}
+	if !valid {
+		s.authManager.RecordFailedLogin(r.Context(), user.ID, clientIP)
+		s.writeError(w, "Invalid 2FA code", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
 func (s *Server) userHasConsoleAccess(ctx context.Context, user *auth.User) bool {
 	if user == nil || s.authManager == nil {
 		return false
@@ -972,50 +1130,6 @@ func (s *Server) handleRefreshToken(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// privateIPNets contains the CIDR ranges that are always considered trusted
-// (loopback, RFC-1918 private, and IPv6 equivalents).
-var privateIPNets = func() []*net.IPNet {
-	ranges := []string{
-		"127.0.0.0/8",    // IPv4 loopback
-		"10.0.0.0/8",     // RFC-1918
-		"172.16.0.0/12",  // RFC-1918
-		"192.168.0.0/16", // RFC-1918
-		"::1/128",        // IPv6 loopback
-		"fc00::/7",       // IPv6 unique local
-	}
-	nets := make([]*net.IPNet, 0, len(ranges))
-	for _, cidr := range ranges {
-		_, n, err := net.ParseCIDR(cidr)
-		if err == nil {
-			nets = append(nets, n)
-		}
-	}
-	return nets
-}()
-
-// isIPTrusted returns true if ip is in a private/loopback range or in the
-// user-supplied trustedProxies list (IPs or CIDRs).
-func isIPTrusted(ip net.IP, trustedProxies []string) bool {
-	for _, n := range privateIPNets {
-		if n.Contains(ip) {
-			return true
-		}
-	}
-	for _, entry := range trustedProxies {
-		if strings.Contains(entry, "/") {
-			_, cidr, err := net.ParseCIDR(entry)
-			if err == nil && cidr.Contains(ip) {
-				return true
-			}
-		} else {
-			if net.ParseIP(entry).Equal(ip) {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 // sanitizeFilename strips characters that could be used for HTTP header injection
 // (\r, \n) or break the Content-Disposition filename token (\", \).
 // This prevents response-header injection via attacker-controlled object keys.
@@ -1032,45 +1146,12 @@ func sanitizeFilename(name string) string {
 	return b.String()
 }
 
-// getClientIP extracts the real client IP address from the request.
-// It only trusts X-Forwarded-For / X-Real-IP headers when the direct
-// connection (RemoteAddr) comes from a private/loopback network or from
-// an address in trustedProxies, preventing IP spoofing by external clients.
-func getClientIP(r *http.Request, trustedProxies []string) string {
-	// Parse the direct upstream IP from RemoteAddr
-	remoteHost := r.RemoteAddr
-	if idx := strings.LastIndex(remoteHost, ":"); idx != -1 {
-		remoteHost = remoteHost[:idx]
-	}
-	// Strip IPv6 brackets if present
-	remoteHost = strings.Trim(remoteHost, "[]")
-	upstreamIP := net.ParseIP(remoteHost)
-
-	// Only honour proxy headers when the direct peer is trusted
-	if upstreamIP != nil && isIPTrusted(upstreamIP, trustedProxies) {
-		// X-Forwarded-For may contain a comma-separated chain; the leftmost
-		// entry is the original client IP as reported by the first proxy.
-		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-			ips := strings.Split(forwarded, ",")
-			if candidate := strings.TrimSpace(ips[0]); candidate != "" {
-				return candidate
-			}
-		}
-		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
-			return realIP
-		}
-	}
-
-	// Untrusted peer or no proxy headers — use RemoteAddr directly
-	return remoteHost
-}
-
 func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 	// Get user from context
 	user, userExists := auth.GetUserFromContext(r.Context())
 	if userExists {
 		// Log audit event for logout
-		clientIP := getClientIP(r, s.config.TrustedProxies)
+		clientIP := middleware.ClientIP(r, s.config.TrustedProxies)
 		s.logAuditEvent(r.Context(), &audit.AuditEvent{
 			TenantID:     user.TenantID,
 			UserID:       user.ID,
@@ -1416,6 +1497,8 @@ func (s *Server) handleCreateBucket(w http.ResponseWriter, r *http.Request) {
 		Lifecycle         *bucket.LifecycleConfig   `json:"lifecycle,omitempty"`
 		Tags              map[string]string         `json:"tags,omitempty"`
 		NodeID            string                    `json:"node_id,omitempty"`
+		// Optional per-bucket limits (0 = unlimited for that field)
+		Quota *bucketQuotaPayload `json:"quota,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -1536,6 +1619,11 @@ func (s *Server) handleCreateBucket(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if req.Quota != nil && (req.Quota.MaxSizeBytes < 0 || req.Quota.MaxObjectCount < 0) {
+		s.writeError(w, "Quota limits cannot be negative", http.StatusBadRequest)
+		return
+	}
+
 	// Extract tenant ID from user context
 	tenantID := user.TenantID
 
@@ -1641,9 +1729,25 @@ func (s *Server) handleCreateBucket(w http.ResponseWriter, r *http.Request) {
 		bucketInfo.Tags = req.Tags
 	}
 
-	// Aplicar región
+	// Aplicar región; without one the bucket lands in the configured default
 	if req.Region != "" {
 		bucketInfo.Region = req.Region
+	} else if s.config.Auth.DefaultRegion != "" {
+		bucketInfo.Region = s.config.Auth.DefaultRegion
+	}
+
+	// Apply the per-bucket quota; ownership is settled above, so the tenant
+	// ceiling can be checked now
+	if req.Quota != nil && (req.Quota.MaxSizeBytes > 0 || req.Quota.MaxObjectCount > 0) {
+		if err := s.checkBucketQuotaWithinTenant(r.Context(), bucketInfo, req.Quota.MaxSizeBytes); err != nil {
+			_ = s.bucketManager.DeleteBucket(r.Context(), tenantID, req.Name)
+			s.writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		bucketInfo.Quota = &metadata.BucketQuota{
+			MaxSizeBytes:   req.Quota.MaxSizeBytes,
+			MaxObjectCount: req.Quota.MaxObjectCount,
+		}
 	}
 
 	// Assign HA primary node — always set so bucket aggregator knows which node owns this bucket
@@ -1723,6 +1827,25 @@ func (s *Server) handleGetBucket(w http.ResponseWriter, r *http.Request) {
 		Lifecycle:         bucketInfo.Lifecycle,
 		Tags:              bucketInfo.Tags,
 		Metadata:          bucketInfo.Metadata,
+
+		DefaultWriteLockDays: bucketInfo.DefaultWriteLockDays,
+		NoOverwrite:          bucketInfo.NoOverwrite,
+		MaxVersionsPerObject: bucketInfo.MaxVersionsPerObject,
+		DefaultContentType:   bucketInfo.DefaultContentType,
+		ScanUploads:          bucketInfo.ScanUploads,
+	}
+	if bucketInfo.Quota != nil {
+		response.Quota = &bucketQuotaPayload{
+			MaxSizeBytes:   bucketInfo.Quota.MaxSizeBytes,
+			MaxObjectCount: bucketInfo.Quota.MaxObjectCount,
+		}
+	}
+	if bucketInfo.CacheDefaults != nil {
+		response.CacheDefaults = &bucketCacheDefaultsPayload{
+			CacheControl:      bucketInfo.CacheDefaults.CacheControl,
+			ExpiresSeconds:    bucketInfo.CacheDefaults.ExpiresSeconds,
+			ImmutableVersions: bucketInfo.CacheDefaults.ImmutableVersions,
+		}
 	}
 
 	s.writeJSON(w, response)
@@ -1945,7 +2068,70 @@ func (s *Server) handleListObjects(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	result, err := s.objectManager.ListObjects(r.Context(), bucketPath, prefix, delimiter, marker, maxKeys)
+	// order=desc lists keys in descending order (console-only; the marker is
+	// then the exclusive upper bound of the next page)
+	order := r.URL.Query().Get("order")
+	if order != "" && order != "asc" && order != "desc" {
+		s.writeError(w, "order must be 'asc' or 'desc'", http.StatusBadRequest)
+		return
+	}
+
+	// Folder navigation: hideFolderMarkers leaves the zero-byte "folder/"
+	// marker objects out of objects (their folders are already in
+	// commonPrefixes, or are the prefix being listed); foldersOnly returns
+	// just the folders, for a tree view
+	foldersOnly := r.URL.Query().Get("foldersOnly") == "true"
+	hideFolderMarkers := foldersOnly || r.URL.Query().Get("hideFolderMarkers") == "true"
+	if foldersOnly && delimiter == "" {
+		delimiter = "/"
+	}
+
+	// includeMetadata embeds each object's user metadata, saving a HEAD per
+	// object. Only offered for pages of up to listMetadataMaxKeys objects.
+	includeMetadata := r.URL.Query().Get("includeMetadata") == "true"
+	if includeMetadata && maxKeys > listMetadataMaxKeys {
+		s.writeError(w, fmt.Sprintf("includeMetadata allows at most %d keys per page", listMetadataMaxKeys), http.StatusBadRequest)
+		return
+	}
+
+	// pattern keeps only the objects whose key matches a glob (or, with
+	// patternType=regex, a regular expression); see listMatchingObjects
+	var keyPattern *regexp.Regexp
+	if pattern := r.URL.Query().Get("pattern"); pattern != "" {
+		if foldersOnly {
+			s.writeError(w, "pattern cannot be combined with foldersOnly", http.StatusBadRequest)
+			return
+		}
+		var err error
+		if keyPattern, err = compileKeyPattern(pattern, r.URL.Query().Get("patternType")); err != nil {
+			s.writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	list := func(marker string, maxKeys int) (*object.ListObjectsResult, error) {
+		if order == "desc" {
+			return s.objectManager.ListObjectsReverse(r.Context(), bucketPath, prefix, delimiter, marker, maxKeys)
+		}
+		return s.objectManager.ListObjects(r.Context(), bucketPath, prefix, delimiter, marker, maxKeys)
+	}
+	var result *object.ListObjectsResult
+	var err error
+	if keyPattern != nil {
+		result, err = listMatchingObjects(list, keyPattern, marker, maxKeys)
+	} else {
+		result, err = list(marker, maxKeys)
+	}
+	// Files count towards max_keys in a page, so keep reading until there
+	// are max_keys folders or none are left
+	for err == nil && foldersOnly && result.IsTruncated && len(result.CommonPrefixes) < maxKeys {
+		var next *object.ListObjectsResult
+		if next, err = list(result.NextMarker, maxKeys-len(result.CommonPrefixes)); err == nil {
+			result.CommonPrefixes = append(result.CommonPrefixes, next.CommonPrefixes...)
+			result.IsTruncated = next.IsTruncated
+			result.NextMarker = next.NextMarker
+		}
+	}
 	if err != nil {
 		if err == object.ErrBucketNotFound {
 			s.writeError(w, "Bucket not found", http.StatusNotFound)
@@ -1954,20 +2140,29 @@ func (s *Server) handleListObjects(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	if foldersOnly {
+		result.Objects = nil
+	}
 
 	// Convert objects to response format
-	objectsResponse := make([]ObjectResponse, len(result.Objects))
-	for i, obj := range result.Objects {
-		objectsResponse[i] = ObjectResponse{
+	objectsResponse := make([]ObjectResponse, 0, len(result.Objects))
+	for _, obj := range result.Objects {
+		if hideFolderMarkers && obj.Size == 0 && strings.HasSuffix(obj.Key, "/") {
+			continue
+		}
+		objResp := ObjectResponse{
 			Key:          obj.Key,
 			Size:         obj.Size,
 			LastModified: obj.LastModified.Format("2006-01-02T15:04:05Z"),
 			ETag:         obj.ETag,
 			ContentType:  obj.ContentType,
-			Metadata:     obj.Metadata,
 			Retention:    obj.Retention,
 			LegalHold:    obj.LegalHold,
 		}
+		if includeMetadata {
+			objResp.Metadata = obj.Metadata
+		}
+		objectsResponse = append(objectsResponse, objResp)
 	}
 
 	// Convert common prefixes to response format
@@ -2181,10 +2376,27 @@ func (s *Server) handleGetObject(w http.ResponseWriter, r *http.Request) {
 			LegalHold:    metadata.LegalHold,
 		}
 
+		etag := metadataETag(response)
+		setConsoleCacheHeaders(w, etag, metadata.LastModified)
+		if consoleNotModified(r, etag, metadata.LastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
 		s.writeJSON(w, response)
 		return
 	}
 
+	// Answer revalidation of a cached preview before opening the object stream
+	if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+		current, err := s.objectManager.GetObjectMetadata(r.Context(), bucketPath, objectKey)
+		if err == nil && consoleNotModified(r, quoteETag(current.ETag), current.LastModified) {
+			setConsoleCacheHeaders(w, quoteETag(current.ETag), current.LastModified)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
 	// Otherwise, return the actual file content
 	obj, reader, err := s.objectManager.GetObject(r.Context(), bucketPath, objectKey)
 	if err != nil {
@@ -2196,6 +2408,10 @@ func (s *Server) handleGetObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer reader.Close()
+	if s.archivedReadDenied(obj) {
+		s.writeError(w, archivedReadMessage, http.StatusForbidden)
+		return
+	}
 
 	s.logAuditEvent(r.Context(), &audit.AuditEvent{
 		TenantID:     tenantID,
@@ -2207,7 +2423,7 @@ func (s *Server) handleGetObject(w http.ResponseWriter, r *http.Request) {
 		ResourceName: objectKey,
 		Action:       audit.ActionDownload,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"bucket":       bucketName,
@@ -2220,11 +2436,10 @@ func (s *Server) handleGetObject(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", obj.ContentType)
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", obj.Size))
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", sanitizeFilename(filepath.Base(objectKey))))
-	w.Header().Set("ETag", obj.ETag)
-	w.Header().Set("Last-Modified", obj.LastModified.Format(http.TimeFormat))
+	setConsoleCacheHeaders(w, quoteETag(obj.ETag), obj.LastModified)
 
-	// Copy the object content to response
-	if _, err := io.Copy(w, reader); err != nil {
+	// Copy exactly the declared plaintext length to the response
+	if _, err := io.CopyN(w, reader, obj.Size); err != nil {
 		logrus.WithError(err).Debug("Error streaming object content")
 	}
 }
@@ -2297,8 +2512,10 @@ func (s *Server) handleUploadObject(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if err == object.ErrBucketNotFound {
 			s.writeError(w, "Bucket not found", http.StatusNotFound)
-		} else if errors.Is(err, object.ErrBucketQuotaExceeded) {
+		} else if errors.Is(err, object.ErrBucketQuotaExceeded) || errors.Is(err, object.ErrReservedKeyPrefix) {
 			s.writeError(w, err.Error(), http.StatusForbidden)
+		} else if status := uploadScanStatus(err); status != 0 {
+			s.writeError(w, err.Error(), status)
 		} else {
 			s.writeError(w, err.Error(), http.StatusInternalServerError)
 		}
@@ -2339,7 +2556,7 @@ func (s *Server) handleUploadObject(w http.ResponseWriter, r *http.Request) {
 		ResourceName: objectKey,
 		Action:       audit.ActionUpload,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"bucket":       bucketName,
@@ -2427,8 +2644,40 @@ func (s *Server) handleShareObject(w http.ResponseWriter, r *http.Request) {
 	// Use the bucket's tenant ID for the share
 	shareTenantID := bucketInfo.TenantID
 
+	// Parse request body for expiration time and response header overrides
+	var req share.ShareCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		// Default to 1 hour if no body provided
+		defaultExpiry := int64(3600)
+		req.ExpiresIn = &defaultExpiry
+	}
+	overrides := share.ResponseOverrides{
+		ContentDisposition: req.ContentDisposition,
+		ContentType:        req.ContentType,
+	}
+	if err := overrides.Validate(); err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	allowedReferers, err := share.NormalizeReferers(req.AllowedReferers)
+	if err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Check if object already has an active share
 	existingShare, err := s.shareManager.GetShareByObject(r.Context(), bucketName, objectKey, shareTenantID)
+	if err == nil && existingShare != nil &&
+		((overrides != share.ResponseOverrides{}) &&
+			(existingShare.ContentDisposition != overrides.ContentDisposition || existingShare.ContentType != overrides.ContentType) ||
+			len(allowedReferers) > 0 && !slices.Equal(existingShare.AllowedReferers, allowedReferers)) {
+		// Different overrides or referers were asked for: replace the share
+		if err := s.shareManager.DeleteShare(r.Context(), existingShare.ID); err != nil {
+			s.writeError(w, fmt.Sprintf("Failed to replace share: %v", err), http.StatusInternalServerError)
+			return
+		}
+		existingShare = nil
+	}
 	if err == nil && existingShare != nil {
 		// Return existing share
 		logrus.WithFields(logrus.Fields{
@@ -2462,12 +2711,16 @@ func (s *Server) handleShareObject(w http.ResponseWriter, r *http.Request) {
 		}).Info("Generated share URL for existing share")
 
 		s.writeJSON(w, map[string]interface{}{
-			"id":        existingShare.ID,
-			"url":       s3URL,
-			"expiresAt": existingShare.ExpiresAt,
-			"createdAt": existingShare.CreatedAt.Format(time.RFC3339),
-			"isExpired": false,
-			"existing":  true,
+			"id":                 existingShare.ID,
+			"url":                s3URL,
+			"expiresAt":          existingShare.ExpiresAt,
+			"createdAt":          existingShare.CreatedAt.Format(time.RFC3339),
+			"isExpired":          false,
+			"existing":           true,
+			"contentDisposition": existingShare.ContentDisposition,
+			"contentType":        existingShare.ContentType,
+			"allowedReferers":    existingShare.AllowedReferers,
+			"accessCount":        existingShare.AccessCount,
 		})
 		return
 	} else if err != nil {
@@ -2479,17 +2732,6 @@ func (s *Server) handleShareObject(w http.ResponseWriter, r *http.Request) {
 		}).Debug("No existing share found or error occurred")
 	}
 
-	// Parse request body for expiration time
-	var req struct {
-		ExpiresIn *int64 `json:"expiresIn"` // seconds, null = never expires
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		// Default to 1 hour if no body provided
-		defaultExpiry := int64(3600)
-		req.ExpiresIn = &defaultExpiry
-	}
-
 	// Get user's first access key
 	accessKeys, err := s.authManager.ListAccessKeys(r.Context(), user.ID)
 	if err != nil || len(accessKeys) == 0 {
@@ -2506,9 +2748,11 @@ func (s *Server) handleShareObject(w http.ResponseWriter, r *http.Request) {
 		objectKey,
 		shareTenantID,
 		accessKey.AccessKeyID,
-		accessKey.SecretAccessKey,
+		"", // shares are served without signing; the secret is not stored
 		user.ID,
 		req.ExpiresIn,
+		overrides,
+		allowedReferers,
 	)
 	if err != nil {
 		s.writeError(w, fmt.Sprintf("Failed to create share: %v", err), http.StatusInternalServerError)
@@ -2525,7 +2769,7 @@ func (s *Server) handleShareObject(w http.ResponseWriter, r *http.Request) {
 		ResourceName: objectKey,
 		Action:       audit.ActionShare,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"bucket":   bucketName,
@@ -2561,12 +2805,16 @@ func (s *Server) handleShareObject(w http.ResponseWriter, r *http.Request) {
 
 	// Return share response
 	s.writeJSON(w, map[string]interface{}{
-		"id":        share.ID,
-		"url":       s3URL,
-		"expiresAt": share.ExpiresAt,
-		"createdAt": share.CreatedAt.Format(time.RFC3339),
-		"isExpired": false,
-		"existing":  false,
+		"id":                 share.ID,
+		"url":                s3URL,
+		"expiresAt":          share.ExpiresAt,
+		"createdAt":          share.CreatedAt.Format(time.RFC3339),
+		"isExpired":          false,
+		"existing":           false,
+		"contentDisposition": share.ContentDisposition,
+		"contentType":        share.ContentType,
+		"allowedReferers":    share.AllowedReferers,
+		"accessCount":        share.AccessCount,
 	})
 }
 
@@ -2607,13 +2855,25 @@ func (s *Server) handleDeleteObject(w http.ResponseWriter, r *http.Request) {
 	// Check if versionId is provided (for deleting specific versions)
 	versionID := r.URL.Query().Get("versionId")
 
+	// MFA Delete: permanently deleting a version needs the user's 2FA code
+	if versionID != "" && !s.checkMFADelete(w, r, tenantID, bucketName, user.ID) {
+		return
+	}
+
+	// bypassGovernance=true deletes past GOVERNANCE retention; it needs the
+	// bypass permission, never just the admin role
+	bypassGovernance := r.URL.Query().Get("bypassGovernance") == "true"
+	if bypassGovernance && !s.canBypassGovernance(r, user, tenantID, bucketName, objectKey) {
+		s.writeError(w, "You do not have permission to bypass governance retention", http.StatusForbidden)
+		return
+	}
+
 	// Call DeleteObject with optional versionID
-	// Console API doesn't support bypass governance (use S3 API for that)
 	var err error
 	if versionID != "" {
-		_, err = s.objectManager.DeleteObject(r.Context(), bucketPath, objectKey, false, versionID)
+		_, err = s.objectManager.DeleteObject(r.Context(), bucketPath, objectKey, bypassGovernance, versionID)
 	} else {
-		_, err = s.objectManager.DeleteObject(r.Context(), bucketPath, objectKey, false)
+		_, err = s.objectManager.DeleteObject(r.Context(), bucketPath, objectKey, bypassGovernance)
 	}
 
 	if err != nil {
@@ -2652,7 +2912,7 @@ func (s *Server) handleDeleteObject(w http.ResponseWriter, r *http.Request) {
 		ResourceName: objectKey,
 		Action:       audit.ActionDelete,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details:      details,
 	})
@@ -3214,9 +3474,15 @@ func (s *Server) handleGetSystemMetrics(w http.ResponseWriter, r *http.Request)
 		"goroutines":         runtime.NumGoroutine(), // Active goroutines
 		"heapAllocBytes":     m.HeapAlloc,            // Bytes allocated in heap
 		"gcRuns":             m.NumGC,                // Number of GC runs
+		"maintenanceMode":    s.maintenanceMode(),    // "readonly" or "off"
 		"timestamp":          time.Now().Unix(),
 	}
 
+	// Connections, requests and bytes per listener (S3 API vs console)
+	if s.s3ListenerStats != nil && s.consoleListenerStats != nil {
+		response["listeners"] = []metrics.ListenerSnapshot{s.s3ListenerStats.Snapshot(), s.consoleListenerStats.Snapshot()}
+	}
+
 	// Populate CPU stats if available
 	if cpuStats != nil {
 		response["cpuUsagePercent"] = cpuStats.UsagePercent
@@ -3238,6 +3504,15 @@ func (s *Server) handleGetSystemMetrics(w http.ResponseWriter, r *http.Request)
 		response["diskUsagePercent"] = diskStats.UsedPercent
 		response["diskUsedBytes"] = diskStats.UsedBytes
 		response["diskTotalBytes"] = diskStats.TotalBytes
+		response["diskFreeBytes"] = diskStats.FreeBytes
+	}
+
+	// Writes are refused while the data disk is below its reserved headroom
+	if sr, ok := s.storageBackend.(storage.SpaceReporter); ok {
+		space := sr.SpaceStatus()
+		response["diskFreeBytes"] = space.FreeBytes
+		response["diskReservedBytes"] = space.ReservedBytes
+		response["storageWriteBlocked"] = space.WriteBlocked
 	}
 
 	// In cluster mode, aggregate capacity across all nodes.
@@ -3430,7 +3705,8 @@ func (s *Server) handleGetServerConfig(w http.ResponseWriter, r *http.Request) {
 			"dataDir":          s.config.DataDir,
 			"publicApiUrl":     s.config.PublicAPIURL,
 			"publicConsoleUrl": s.config.PublicConsoleURL,
-			"enableTls":        s.config.EnableTLS,
+			"enableTls":        s.config.S3ListenerTLS().Enabled,
+			"consoleTls":       s.config.ConsoleListenerTLS().Enabled,
 			"logLevel":         s.config.LogLevel,
 			"websiteHostname":  s.config.WebsiteHostname,
 		},
@@ -3465,8 +3741,7 @@ func (s *Server) handleGetServerConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Include maintenance mode state so the frontend can show a banner.
-	maintenanceMode, _ := s.settingsManager.GetBool("system.maintenance_mode")
-	config["maintenanceMode"] = maintenanceMode
+	config["maintenanceMode"] = s.maintenanceEnabled()
 
 	// Expose cluster mode so the frontend can adapt UI accordingly.
 	isClusterEnabledCfg := s.clusterManager != nil && s.clusterManager.IsClusterEnabled()
@@ -3477,6 +3752,40 @@ func (s *Server) handleGetServerConfig(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, config)
 }
 
+// consoleCORSConfig builds the console API CORS policy from console_cors.
+// Without configured origins only the console itself may call the API: the
+// frontend dev server, the console listen address and public_console_url.
+func (s *Server) consoleCORSConfig() *middleware.CORSConfig {
+	corsConfig := middleware.DefaultCORSConfig()
+	// The console also updates user preferences with PATCH
+	corsConfig.AllowedMethods = append(corsConfig.AllowedMethods, "PATCH")
+	corsConfig.AllowCredentials = s.config.ConsoleCORS.AllowCredentials
+
+	if len(s.config.ConsoleCORS.AllowedOrigins) > 0 {
+		corsConfig.AllowedOrigins = s.config.ConsoleCORS.AllowedOrigins
+	} else {
+		// Always allow direct local access, regardless of public_console_url.
+		if host, port, err := net.SplitHostPort(s.config.ConsoleListen); err == nil {
+			if host == "" || host == "0.0.0.0" || host == "::" {
+				host = "localhost"
+			}
+			corsConfig.AllowedOrigins = append(corsConfig.AllowedOrigins, "http://"+host+":"+port)
+		}
+		// Also allow the configured public URL (for reverse-proxy / remote browser
+		// access). Browsers send the bare origin, without any path prefix.
+		if u, err := url.Parse(s.config.PublicConsoleURL); err == nil && u.Scheme != "" && u.Host != "" {
+			corsConfig.AllowedOrigins = append(corsConfig.AllowedOrigins, u.Scheme+"://"+u.Host)
+		}
+	}
+	if len(s.config.ConsoleCORS.AllowedMethods) > 0 {
+		corsConfig.AllowedMethods = s.config.ConsoleCORS.AllowedMethods
+	}
+	if len(s.config.ConsoleCORS.AllowedHeaders) > 0 {
+		corsConfig.AllowedHeaders = s.config.ConsoleCORS.AllowedHeaders
+	}
+	return corsConfig
+}
+
 // handleVersionCheck proxies the version check to maxiofs.com to avoid CORS issues
 func (s *Server) handleVersionCheck(w http.ResponseWriter, r *http.Request) {
 	client := &http.Client{Timeout: 5 * time.Second}
@@ -3518,57 +3827,25 @@ func (s *Server) handleAPIHealth(w http.ResponseWriter, r *http.Request) {
 // validatePasswordPolicy checks a password against the settings-configured rules.
 // Returns a non-empty error message if validation fails, empty string if the password is valid.
 func (s *Server) validatePasswordPolicy(password string) string {
-	minLen := 8
-	if v, err := s.settingsManager.GetInt("security.password_min_length"); err == nil && v > 0 {
-		minLen = v
-	}
-	if len(password) < minLen {
-		return fmt.Sprintf("Password must be at least %d characters", minLen)
-	}
+	return auth.CheckPasswordPolicy(s.settingsManager, password)
+}
 
-	requireUpper, _ := s.settingsManager.GetBool("security.password_require_uppercase")
-	if requireUpper {
-		hasUpper := false
-		for _, r := range password {
-			if unicode.IsUpper(r) {
-				hasUpper = true
-				break
-			}
-		}
-		if !hasUpper {
-			return "Password must contain at least one uppercase letter"
-		}
+// passwordExpired reports whether a local user's password is older than
+// security.password_max_age_days. Passwords set before their age was tracked
+// count from the account's creation.
+func (s *Server) passwordExpired(user *auth.User) bool {
+	if user.AuthProvider != "" && user.AuthProvider != "local" {
+		return false
 	}
-
-	requireNumbers, _ := s.settingsManager.GetBool("security.password_require_numbers")
-	if requireNumbers {
-		hasDigit := false
-		for _, r := range password {
-			if unicode.IsDigit(r) {
-				hasDigit = true
-				break
-			}
-		}
-		if !hasDigit {
-			return "Password must contain at least one number"
-		}
+	maxAgeDays, err := s.settingsManager.GetInt("security.password_max_age_days")
+	if err != nil || maxAgeDays <= 0 {
+		return false
 	}
-
-	requireSpecial, _ := s.settingsManager.GetBool("security.password_require_special")
-	if requireSpecial {
-		hasSpecial := false
-		for _, r := range password {
-			if unicode.IsPunct(r) || unicode.IsSymbol(r) {
-				hasSpecial = true
-				break
-			}
-		}
-		if !hasSpecial {
-			return "Password must contain at least one special character"
-		}
+	changedAt := user.PasswordChangedAt
+	if changedAt == 0 {
+		changedAt = user.CreatedAt
 	}
-
-	return ""
+	return time.Since(time.Unix(changedAt, 0)) > time.Duration(maxAgeDays)*24*time.Hour
 }
 
 func (s *Server) writeJSON(w http.ResponseWriter, data interface{}) {
@@ -3637,11 +3914,14 @@ func (s *Server) handleListAllAccessKeys(w http.ResponseWriter, r *http.Request)
 
 	// Convert to response format (don't expose secret keys)
 	type AccessKeyResponse struct {
-		ID        string `json:"id"`
-		UserID    string `json:"userId"`
-		Status    string `json:"status"`
-		CreatedAt int64  `json:"createdAt"`
-		LastUsed  int64  `json:"lastUsed,omitempty"`
+		ID          string            `json:"id"`
+		UserID      string            `json:"userId"`
+		Status      string            `json:"status"`
+		CreatedAt   int64             `json:"createdAt"`
+		LastUsed    int64             `json:"lastUsed,omitempty"`
+		Name        string            `json:"name,omitempty"`
+		Description string            `json:"description,omitempty"`
+		Tags        map[string]string `json:"tags,omitempty"`
 	}
 
 	var allAccessKeys []AccessKeyResponse
@@ -3657,11 +3937,14 @@ func (s *Server) handleListAllAccessKeys(w http.ResponseWriter, r *http.Request)
 
 		for _, key := range accessKeys {
 			allAccessKeys = append(allAccessKeys, AccessKeyResponse{
-				ID:        key.AccessKeyID,
-				UserID:    key.UserID,
-				Status:    key.Status,
-				CreatedAt: key.CreatedAt,
-				LastUsed:  key.LastUsed,
+				ID:          key.AccessKeyID,
+				UserID:      key.UserID,
+				Status:      key.Status,
+				CreatedAt:   key.CreatedAt,
+				LastUsed:    key.LastUsed,
+				Name:        key.Name,
+				Description: key.Description,
+				Tags:        key.Tags,
 			})
 		}
 	}
@@ -3701,21 +3984,27 @@ func (s *Server) handleListAccessKeys(w http.ResponseWriter, r *http.Request) {
 
 	// Convert to response format (don't expose secret keys)
 	type AccessKeyResponse struct {
-		ID        string `json:"id"`
-		UserID    string `json:"userId"`
-		Status    string `json:"status"`
-		CreatedAt int64  `json:"createdAt"`
-		LastUsed  int64  `json:"lastUsed,omitempty"`
+		ID          string            `json:"id"`
+		UserID      string            `json:"userId"`
+		Status      string            `json:"status"`
+		CreatedAt   int64             `json:"createdAt"`
+		LastUsed    int64             `json:"lastUsed,omitempty"`
+		Name        string            `json:"name,omitempty"`
+		Description string            `json:"description,omitempty"`
+		Tags        map[string]string `json:"tags,omitempty"`
 	}
 
 	response := make([]AccessKeyResponse, len(accessKeys))
 	for i, key := range accessKeys {
 		response[i] = AccessKeyResponse{
-			ID:        key.AccessKeyID,
-			UserID:    key.UserID,
-			Status:    key.Status,
-			CreatedAt: key.CreatedAt,
-			LastUsed:  key.LastUsed,
+			ID:          key.AccessKeyID,
+			UserID:      key.UserID,
+			Status:      key.Status,
+			CreatedAt:   key.CreatedAt,
+			LastUsed:    key.LastUsed,
+			Name:        key.Name,
+			Description: key.Description,
+			Tags:        key.Tags,
 		}
 	}
 
@@ -3768,8 +4057,21 @@ func (s *Server) handleCreateAccessKey(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// The body is optional: a key may be created without labels
+	var info auth.AccessKeyInfo
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&info); err != nil && err != io.EOF {
+			s.writeError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := info.Validate(); err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Generate new access key
-	accessKey, err := s.authManager.GenerateAccessKey(r.Context(), userID)
+	accessKey, err := s.authManager.GenerateNamedAccessKey(r.Context(), userID, info)
 	if err != nil {
 		s.writeError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -3785,21 +4087,27 @@ func (s *Server) handleCreateAccessKey(w http.ResponseWriter, r *http.Request) {
 
 	// Return complete key with secret (only shown once)
 	type CreateAccessKeyResponse struct {
-		ID        string `json:"id"`
-		AccessKey string `json:"accessKey"`
-		SecretKey string `json:"secretKey"`
-		UserID    string `json:"userId"`
-		Status    string `json:"status"`
-		CreatedAt int64  `json:"createdAt"`
+		ID          string            `json:"id"`
+		AccessKey   string            `json:"accessKey"`
+		SecretKey   string            `json:"secretKey"`
+		UserID      string            `json:"userId"`
+		Status      string            `json:"status"`
+		CreatedAt   int64             `json:"createdAt"`
+		Name        string            `json:"name,omitempty"`
+		Description string            `json:"description,omitempty"`
+		Tags        map[string]string `json:"tags,omitempty"`
 	}
 
 	response := CreateAccessKeyResponse{
-		ID:        accessKey.AccessKeyID,
-		AccessKey: accessKey.AccessKeyID,
-		SecretKey: accessKey.SecretAccessKey,
-		UserID:    accessKey.UserID,
-		Status:    accessKey.Status,
-		CreatedAt: accessKey.CreatedAt,
+		ID:          accessKey.AccessKeyID,
+		AccessKey:   accessKey.AccessKeyID,
+		SecretKey:   accessKey.SecretAccessKey,
+		UserID:      accessKey.UserID,
+		Status:      accessKey.Status,
+		CreatedAt:   accessKey.CreatedAt,
+		Name:        accessKey.Name,
+		Description: accessKey.Description,
+		Tags:        accessKey.Tags,
 	}
 
 	// Log audit event for access key created
@@ -3810,7 +4118,7 @@ func (s *Server) handleCreateAccessKey(w http.ResponseWriter, r *http.Request) {
 		EventType:    audit.EventTypeAccessKeyCreated,
 		ResourceType: audit.ResourceTypeAccessKey,
 		ResourceID:   accessKey.AccessKeyID,
-		ResourceName: accessKey.AccessKeyID,
+		ResourceName: accessKeyDisplayName(accessKey),
 		Action:       audit.ActionCreate,
 		Status:       audit.StatusSuccess,
 		Details: map[string]interface{}{
@@ -3906,6 +4214,106 @@ func (s *Server) handleDeleteAccessKey(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, map[string]string{"message": "Access key deleted successfully"})
 }
 
+// handleUpdateAccessKey replaces the name, description and tags of an access
+// key. The secret and status are not touched.
+func (s *Server) handleUpdateAccessKey(w http.ResponseWriter, r *http.Request) {
+	currentUser := s.getAuthUser(r)
+	if currentUser == nil {
+		s.writeError(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	userID := vars["user"]
+	accessKeyID := vars["accessKey"]
+
+	accessKey, err := s.authManager.GetAccessKey(r.Context(), accessKeyID)
+	if err != nil || accessKey.UserID != userID {
+		s.writeError(w, "Access key not found", http.StatusNotFound)
+		return
+	}
+
+	isOwnKey := accessKey.UserID == currentUser.ID
+	if !s.isAdmin(currentUser) {
+		if !isOwnKey || !auth.CheckCapabilityInContext(r.Context(), s.authManager, auth.CapKeysManageOwn) {
+			s.writeError(w, "Access denied", http.StatusForbidden)
+			return
+		}
+	}
+
+	user, err := s.authManager.GetUser(r.Context(), accessKey.UserID)
+	if err != nil {
+		s.writeError(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	// Tenant admins can only label access keys of users in their own tenant
+	if s.isAdmin(currentUser) && !s.isGlobalAdmin(currentUser) && user.TenantID != currentUser.TenantID {
+		s.writeError(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	var info auth.AccessKeyInfo
+	if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+		s.writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := info.Validate(); err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.authManager.UpdateAccessKeyInfo(r.Context(), accessKeyID, info); err != nil {
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.touchLocalWriteAt(r.Context())
+	if s.accessKeySyncMgr != nil {
+		s.accessKeySyncMgr.TriggerSync(r.Context())
+	}
+
+	accessKey.Name = info.Name
+	accessKey.Description = info.Description
+	accessKey.Tags = info.Tags
+
+	s.logAuditEvent(r.Context(), &audit.AuditEvent{
+		TenantID:     user.TenantID,
+		UserID:       currentUser.ID,
+		Username:     currentUser.Username,
+		EventType:    audit.EventTypeAccessKeyUpdated,
+		ResourceType: audit.ResourceTypeAccessKey,
+		ResourceID:   accessKeyID,
+		ResourceName: accessKeyDisplayName(accessKey),
+		Action:       audit.ActionUpdate,
+		Status:       audit.StatusSuccess,
+		Details: map[string]interface{}{
+			"owner_user_id": accessKey.UserID,
+			"name":          info.Name,
+			"tags":          info.Tags,
+		},
+	})
+
+	s.writeJSON(w, map[string]interface{}{
+		"id":          accessKeyID,
+		"userId":      accessKey.UserID,
+		"status":      accessKey.Status,
+		"createdAt":   accessKey.CreatedAt,
+		"name":        info.Name,
+		"description": info.Description,
+		"tags":        info.Tags,
+	})
+}
+
+// accessKeyDisplayName is how audit entries name an access key: its label
+// when it has one, otherwise its ID.
+func accessKeyDisplayName(key *auth.AccessKey) string {
+	if key.Name != "" {
+		return fmt.Sprintf("%s (%s)", key.Name, key.AccessKeyID)
+	}
+	return key.AccessKeyID
+}
+
 // Password management handler
 func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -4376,6 +4784,8 @@ func (s *Server) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
 		MaxAccessKeys   int64             `json:"maxAccessKeys,omitempty"`
 		MaxStorageBytes int64             `json:"maxStorageBytes,omitempty"`
 		MaxBandwidthBytesPerSec int64     `json:"maxBandwidthBytesPerSec,omitempty"`
+		MaxFailedLoginAttempts int       `json:"maxFailedLoginAttempts,omitempty"`
+		LockoutDurationSeconds int64     `json:"lockoutDurationSeconds,omitempty"`
 		MaxBuckets      int64             `json:"maxBuckets,omitempty"`
 		Metadata        map[string]string `json:"metadata,omitempty"`
 	}
@@ -4393,6 +4803,10 @@ func (s *Server) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, "maxBandwidthBytesPerSec cannot be negative", http.StatusBadRequest)
 		return
 	}
+	if req.MaxFailedLoginAttempts < 0 || req.LockoutDurationSeconds < 0 {
+		s.writeError(w, "maxFailedLoginAttempts and lockoutDurationSeconds cannot be negative", http.StatusBadRequest)
+		return
+	}
 
 	tenant := &auth.Tenant{
 		ID:              auth.GenerateTenantID(),
@@ -4403,6 +4817,8 @@ func (s *Server) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
 		MaxAccessKeys:   req.MaxAccessKeys,
 		MaxStorageBytes: req.MaxStorageBytes,
 		MaxBandwidthBytesPerSec: req.MaxBandwidthBytesPerSec,
+		MaxFailedLoginAttempts:  req.MaxFailedLoginAttempts,
+		LockoutDurationSeconds:  req.LockoutDurationSeconds,
 		MaxBuckets:      req.MaxBuckets,
 		Metadata:        req.Metadata,
 		CreatedAt:       time.Now().Unix(),
@@ -4499,6 +4915,8 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 		MaxAccessKeys       *int64            `json:"maxAccessKeys,omitempty"`
 		MaxStorageBytes     *int64            `json:"maxStorageBytes,omitempty"`
 		MaxBandwidthBytesPerSec *int64        `json:"maxBandwidthBytesPerSec,omitempty"`
+		MaxFailedLoginAttempts *int          `json:"maxFailedLoginAttempts,omitempty"`
+		LockoutDurationSeconds *int64        `json:"lockoutDurationSeconds,omitempty"`
 		MaxBuckets          *int64            `json:"maxBuckets,omitempty"`
 		CurrentStorageBytes *int64            `json:"currentStorageBytes,omitempty"`
 		CurrentBuckets      *int64            `json:"currentBuckets,omitempty"`
@@ -4543,6 +4961,20 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 		}
 		tenant.MaxBandwidthBytesPerSec = *req.MaxBandwidthBytesPerSec
 	}
+	if req.MaxFailedLoginAttempts != nil {
+		if *req.MaxFailedLoginAttempts < 0 {
+			s.writeError(w, "maxFailedLoginAttempts cannot be negative", http.StatusBadRequest)
+			return
+		}
+		tenant.MaxFailedLoginAttempts = *req.MaxFailedLoginAttempts
+	}
+	if req.LockoutDurationSeconds != nil {
+		if *req.LockoutDurationSeconds < 0 {
+			s.writeError(w, "lockoutDurationSeconds cannot be negative", http.StatusBadRequest)
+			return
+		}
+		tenant.LockoutDurationSeconds = *req.LockoutDurationSeconds
+	}
 	if req.MaxBuckets != nil {
 		tenant.MaxBuckets = *req.MaxBuckets
 	}
@@ -5099,15 +5531,78 @@ func (s *Server) handleListBucketShares(w http.ResponseWriter, r *http.Request)
 	shareMap := make(map[string]interface{})
 	for _, share := range shares {
 		shareMap[share.ObjectKey] = map[string]interface{}{
-			"id":        share.ID,
-			"expiresAt": share.ExpiresAt,
-			"createdAt": share.CreatedAt.Format(time.RFC3339),
+			"id":              share.ID,
+			"expiresAt":       share.ExpiresAt,
+			"createdAt":       share.CreatedAt.Format(time.RFC3339),
+			"allowedReferers": share.AllowedReferers,
+			"accessCount":     share.AccessCount,
 		}
 	}
 
 	s.writeJSON(w, shareMap)
 }
 
+// handleGetShareAccessLog lists the anonymous downloads of a bucket's share,
+// newest first (?limit=, default 100, at most 1000)
+func (s *Server) handleGetShareAccessLog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	shareID := vars["id"]
+
+	user, ok := auth.GetUserFromContext(r.Context())
+	if !ok {
+		s.writeError(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// Only global admins can override tenant via query param
+	isGlobalAdmin := auth.IsAdminUser(r.Context()) && user.TenantID == ""
+	tenantID := user.TenantID
+	if queryTenantID := r.URL.Query().Get("tenantId"); queryTenantID != "" && isGlobalAdmin {
+		tenantID = queryTenantID
+	}
+
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			s.writeError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, share.MaxAccessLogEntries)
+	}
+
+	bucketInfo, err := s.bucketManager.GetBucketInfo(r.Context(), tenantID, bucketName)
+	if err != nil && isGlobalAdmin {
+		bucketInfo, err = s.bucketManager.GetBucketInfo(r.Context(), "", bucketName)
+	}
+	if err != nil {
+		s.writeError(w, "Bucket not found", http.StatusNotFound)
+		return
+	}
+
+	// The share must belong to this bucket, so a share ID from another
+	// tenant's bucket is not found
+	sh, err := s.shareManager.GetShare(r.Context(), shareID)
+	if err != nil || sh.BucketName != bucketName || sh.TenantID != bucketInfo.TenantID {
+		s.writeError(w, "Share not found", http.StatusNotFound)
+		return
+	}
+
+	accesses, err := s.shareManager.ListAccesses(r.Context(), sh.ID, limit)
+	if err != nil {
+		s.writeError(w, fmt.Sprintf("Failed to list share accesses: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"shareId":     sh.ID,
+		"objectKey":   sh.ObjectKey,
+		"accessCount": sh.AccessCount,
+		"accesses":    accesses,
+	})
+}
+
 // handleDeleteShare deletes a share for an object
 func (s *Server) handleDeleteShare(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -5232,6 +5727,11 @@ func (s *Server) handleGeneratePresignedURL(w http.ResponseWriter, r *http.Reque
 	if req.Method == "" {
 		req.Method = "GET"
 	}
+	maxExpiresIn := presigned.EffectiveMaxExpiration(int64(s.config.Auth.PresignedMaxExpirySeconds))
+	if req.ExpiresIn > maxExpiresIn {
+		s.writeError(w, fmt.Sprintf("expiresIn cannot exceed %d seconds", maxExpiresIn), http.StatusBadRequest)
+		return
+	}
 
 	// Get user's access keys
 	accessKeys, err := s.authManager.ListAccessKeys(r.Context(), user.ID)
@@ -5240,8 +5740,13 @@ func (s *Server) handleGeneratePresignedURL(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Use first active access key
-	accessKey := accessKeys[0]
+	// Use first active access key; listings carry no secret, so decrypt it
+	// on demand for signing
+	accessKey, err := s.authManager.GetAccessKey(r.Context(), accessKeys[0].AccessKeyID)
+	if err != nil {
+		s.writeError(w, "Failed to load access key", http.StatusInternalServerError)
+		return
+	}
 
 	// Determine tenant ID for bucket path
 	tenantID := user.TenantID
@@ -5291,6 +5796,13 @@ func (s *Server) handleGeneratePresignedURL(w http.ResponseWriter, r *http.Reque
 		endpoint = fmt.Sprintf("%s://%s", protocol, r.Host)
 	}
 
+	// Scope the URL to the bucket's region so clients that check it against
+	// the bucket accept it
+	region := bucketInfo.Region
+	if region == "" {
+		region = s.config.Auth.DefaultRegion
+	}
+
 	// Generate presigned URL
 	params := presigned.PresignedURLParams{
 		Endpoint:        endpoint,
@@ -5301,7 +5813,9 @@ func (s *Server) handleGeneratePresignedURL(w http.ResponseWriter, r *http.Reque
 		SecretAccessKey: accessKey.SecretAccessKey,
 		Method:          req.Method,
 		ExpiresIn:       req.ExpiresIn,
-		Region:          "us-east-1",
+		MaxExpiresIn:    maxExpiresIn,
+		Region:          region,
+		Service:         s.config.Auth.SigningService,
 	}
 
 	presignedURL, err := presigned.GeneratePresignedURL(params)
@@ -5483,7 +5997,8 @@ func (s *Server) handlePutBucketLifecycle(w http.ResponseWriter, r *http.Request
 			Status                      string `xml:"Status"`
 			Prefix                      string `xml:"Prefix"`
 			NoncurrentVersionExpiration *struct {
-				NoncurrentDays int `xml:"NoncurrentDays"`
+				NoncurrentDays          int `xml:"NoncurrentDays"`
+				NewerNoncurrentVersions int `xml:"NewerNoncurrentVersions"`
 			} `xml:"NoncurrentVersionExpiration"`
 			Expiration *struct {
 				Days                      int  `xml:"Days"`
@@ -5513,7 +6028,8 @@ func (s *Server) handlePutBucketLifecycle(w http.ResponseWriter, r *http.Request
 
 		if rule.NoncurrentVersionExpiration != nil {
 			internalRule.NoncurrentVersionExpiration = &bucket.NoncurrentVersionExpiration{
-				NoncurrentDays: rule.NoncurrentVersionExpiration.NoncurrentDays,
+				NoncurrentDays:          rule.NoncurrentVersionExpiration.NoncurrentDays,
+				NewerNoncurrentVersions: rule.NoncurrentVersionExpiration.NewerNoncurrentVersions,
 			}
 		}
 
@@ -6059,6 +6575,10 @@ func (s *Server) handlePutBucketACL(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, fmt.Sprintf("Invalid canned ACL: %v", err), http.StatusBadRequest)
 		return
 	}
+	if pab, err := s.bucketManager.GetPublicAccessBlock(r.Context(), tenantID, bucketName); err == nil && pab.BlockPublicAcls && aclData.HasPublicGrant() {
+		s.writeError(w, "Public ACLs are blocked by the bucket's public access block", http.StatusForbidden)
+		return
+	}
 
 	// Set bucket ACL
 	if err := s.bucketManager.SetBucketACL(r.Context(), tenantID, bucketName, aclData); err != nil {
@@ -6160,6 +6680,10 @@ func (s *Server) handlePutObjectACL(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, fmt.Sprintf("Invalid canned ACL: %v", err), http.StatusBadRequest)
 		return
 	}
+	if pab, err := s.bucketManager.GetPublicAccessBlock(r.Context(), tenantID, bucketName); err == nil && pab.BlockPublicAcls && internalACL.HasPublicGrant() {
+		s.writeError(w, "Public ACLs are blocked by the bucket's public access block", http.StatusForbidden)
+		return
+	}
 
 	objectACL := &object.ACL{
 		Owner: object.Owner{
@@ -6285,14 +6809,13 @@ func (s *Server) handlePutBucketPolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate policy structure
-	if policyDoc.Version == "" {
-		s.writeError(w, "Policy must contain a Version field", http.StatusBadRequest)
+	// Validate policy structure and every statement element
+	if err := bucket.ValidatePolicy(&policyDoc, bucketName); err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	if len(policyDoc.Statement) == 0 {
-		s.writeError(w, "Policy must contain at least one Statement", http.StatusBadRequest)
+	if pab, err := s.bucketManager.GetPublicAccessBlock(r.Context(), tenantID, bucketName); err == nil && pab.BlockPublicPolicy && bucket.IsPolicyPublic(&policyDoc) {
+		s.writeError(w, "Public bucket policies are blocked by the bucket's public access block", http.StatusForbidden)
 		return
 	}
 
@@ -6431,9 +6954,22 @@ func (s *Server) handlePutBucketVersioning(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	// Keep MFA Delete as configured; while it is enabled, changing the
+	// versioning state needs the caller's 2FA code in x-amz-mfa
+	mfaDelete, err := s.mfaDeleteEnabled(r.Context(), tenantID, bucketName)
+	if err != nil {
+		logrus.WithError(err).WithField("bucket", bucketName).Error("MFA Delete: failed to read bucket versioning")
+		s.writeError(w, "Failed to read the bucket versioning configuration", http.StatusInternalServerError)
+		return
+	}
+	if mfaDelete && !s.requireMFADelete(w, r, user.ID) {
+		return
+	}
+
 	// Create versioning config
 	versioningConfig := &bucket.VersioningConfig{
-		Status: req.Status,
+		Status:    req.Status,
+		MFADelete: mfaDelete,
 	}
 
 	// Set versioning configuration
@@ -6449,6 +6985,43 @@ func (s *Server) handlePutBucketVersioning(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 }
 
+// mfaDeleteEnabled reports whether MFA Delete is enabled on the bucket. A
+// missing bucket has nothing to protect; any other error is returned so
+// callers fail closed instead of skipping the MFA check.
+func (s *Server) mfaDeleteEnabled(ctx context.Context, tenantID, bucketName string) (bool, error) {
+	cfg, err := s.bucketManager.GetVersioning(ctx, tenantID, bucketName)
+	if err != nil {
+		if errors.Is(err, bucket.ErrBucketNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return cfg != nil && cfg.MFADelete, nil
+}
+
+// checkMFADelete enforces the bucket's MFA Delete setting for a console
+// request that permanently deletes a version. It writes the error response
+// and returns false when the request may not proceed.
+func (s *Server) checkMFADelete(w http.ResponseWriter, r *http.Request, tenantID, bucketName, userID string) bool {
+	enabled, err := s.mfaDeleteEnabled(r.Context(), tenantID, bucketName)
+	if err != nil {
+		logrus.WithError(err).WithField("bucket", bucketName).Error("MFA Delete: failed to read bucket versioning")
+		s.writeError(w, "Failed to read the bucket versioning configuration", http.StatusInternalServerError)
+		return false
+	}
+	return !enabled || s.requireMFADelete(w, r, userID)
+}
+
+// requireMFADelete checks the x-amz-mfa header of a console request against
+// the user's 2FA, for operations guarded by a bucket's MFA Delete setting.
+func (s *Server) requireMFADelete(w http.ResponseWriter, r *http.Request, userID string) bool {
+	if err := auth.VerifyMFAHeader(r.Context(), s.authManager, userID, r.Header.Get("x-amz-mfa"), middleware.ClientIP(r, s.config.TrustedProxies)); err != nil {
+		s.writeError(w, "MFA Delete is enabled on this bucket: "+err.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
 // handlePutObjectLockConfiguration handles PUT /buckets/{bucket}/object-lock
 func (s *Server) handlePutObjectLockConfiguration(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -7023,6 +7596,10 @@ func (s *Server) handleListAuditLogs(w http.ResponseWriter, r *http.Request) {
 		filters.ResourceType = resourceType
 	}
 
+	if resourceID := r.URL.Query().Get("resource_id"); resourceID != "" {
+		filters.ResourceID = resourceID
+	}
+
 	if action := r.URL.Query().Get("action"); action != "" {
 		filters.Action = action
 	}
@@ -7095,7 +7672,7 @@ func (s *Server) federateAuditLogs(ctx context.Context, queryParams url.Values,
 
 	// Build filter query string (reuse params already parsed by caller).
 	remoteQuery := url.Values{}
-	for _, key := range []string{"tenant_id", "user_id", "event_type", "resource_type", "action", "status", "start_date", "end_date"} {
+	for _, key := range []string{"tenant_id", "user_id", "event_type", "resource_type", "resource_id", "action", "status", "start_date", "end_date"} {
 		if v := queryParams.Get(key); v != "" {
 			remoteQuery.Set(key, v)
 		}
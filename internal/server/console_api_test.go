@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,6 +21,7 @@ import (
 	"github.com/maxiofs/maxiofs/internal/metadata"
 	"github.com/maxiofs/maxiofs/internal/metrics"
 	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/maxiofs/maxiofs/internal/presigned"
 	"github.com/maxiofs/maxiofs/internal/settings"
 	"github.com/maxiofs/maxiofs/internal/share"
 	"github.com/maxiofs/maxiofs/internal/storage"
@@ -407,6 +410,9 @@ func TestConsoleAPIBodyLimitExemptsObjectUploads(t *testing.T) {
 
 	req = httptest.NewRequest("PUT", "/api/v1/buckets/test/objects/large.bin/tags", nil)
 	assert.True(t, shouldLimitConsoleBody(req))
+
+	req = httptest.NewRequest("POST", "/api/v1/tenants/import", nil)
+	assert.False(t, shouldLimitConsoleBody(req))
 }
 
 // TestHandleCreateUser tests the POST /users endpoint
@@ -1324,6 +1330,252 @@ func TestHandleGetBucket(t *testing.T) {
 	assert.True(t, response.Success)
 }
 
+// TestHandleBucketWriteLock tests PUT/DELETE /buckets/{bucket}/write-lock and
+// that the setting is surfaced by GET /buckets/{bucket}
+func TestHandleBucketWriteLock(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	token := getAdminToken(t, server)
+	user, err := server.authManager.ValidateJWT(context.Background(), token)
+	require.NoError(t, err)
+
+	withUser := func(req *http.Request) *http.Request {
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		return mux.SetURLVars(req, map[string]string{"bucket": "audit-logs"})
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "audit-logs"})
+	createRR := httptest.NewRecorder()
+	server.handleCreateBucket(createRR, withUser(httptest.NewRequest("POST", "/api/v1/buckets", bytes.NewReader(body))))
+	require.Equal(t, http.StatusOK, createRR.Code)
+
+	getWriteLockDays := func() float64 {
+		rr := httptest.NewRecorder()
+		server.handleGetBucket(rr, withUser(httptest.NewRequest("GET", "/api/v1/buckets/audit-logs", nil)))
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		days, _ := response.Data["defaultWriteLockDays"].(float64)
+		return days
+	}
+
+	putRR := httptest.NewRecorder()
+	server.handlePutBucketWriteLock(putRR, withUser(httptest.NewRequest("PUT", "/api/v1/buckets/audit-logs/write-lock", bytes.NewReader([]byte(`{"days":14}`)))))
+	require.Equal(t, http.StatusOK, putRR.Code, putRR.Body.String())
+	assert.Equal(t, float64(14), getWriteLockDays())
+
+	badRR := httptest.NewRecorder()
+	server.handlePutBucketWriteLock(badRR, withUser(httptest.NewRequest("PUT", "/api/v1/buckets/audit-logs/write-lock", bytes.NewReader([]byte(`{"days":-1}`)))))
+	assert.Equal(t, http.StatusBadRequest, badRR.Code)
+
+	delRR := httptest.NewRecorder()
+	server.handleDeleteBucketWriteLock(delRR, withUser(httptest.NewRequest("DELETE", "/api/v1/buckets/audit-logs/write-lock", nil)))
+	require.Equal(t, http.StatusOK, delRR.Code)
+	assert.Equal(t, float64(0), getWriteLockDays())
+}
+
+// TestHandleBucketCacheDefaults tests PUT/DELETE /buckets/{bucket}/cache-defaults
+// and that the setting is surfaced by GET /buckets/{bucket}
+func TestHandleBucketCacheDefaults(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	user, err := server.authManager.ValidateJWT(context.Background(), getAdminToken(t, server))
+	require.NoError(t, err)
+
+	withUser := func(req *http.Request) *http.Request {
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		return mux.SetURLVars(req, map[string]string{"bucket": "cdn-origin"})
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "cdn-origin"})
+	createRR := httptest.NewRecorder()
+	server.handleCreateBucket(createRR, withUser(httptest.NewRequest("POST", "/api/v1/buckets", bytes.NewReader(body))))
+	require.Equal(t, http.StatusOK, createRR.Code)
+
+	getCacheDefaults := func() map[string]interface{} {
+		rr := httptest.NewRecorder()
+		server.handleGetBucket(rr, withUser(httptest.NewRequest("GET", "/api/v1/buckets/cdn-origin", nil)))
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response struct {
+			Data struct {
+				CacheDefaults map[string]interface{} `json:"cacheDefaults"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		return response.Data.CacheDefaults
+	}
+
+	putRR := httptest.NewRecorder()
+	server.handlePutBucketCacheDefaults(putRR, withUser(httptest.NewRequest("PUT", "/api/v1/buckets/cdn-origin/cache-defaults",
+		bytes.NewReader([]byte(`{"cacheControl":"public, max-age=600","expiresSeconds":600,"immutableVersions":true}`)))))
+	require.Equal(t, http.StatusOK, putRR.Code, putRR.Body.String())
+	assert.Equal(t, map[string]interface{}{
+		"cacheControl":      "public, max-age=600",
+		"expiresSeconds":    float64(600),
+		"immutableVersions": true,
+	}, getCacheDefaults())
+
+	for _, bad := range []string{`{"expiresSeconds":-1}`, `{"cacheControl":"public\r\nSet-Cookie: x=1"}`} {
+		badRR := httptest.NewRecorder()
+		server.handlePutBucketCacheDefaults(badRR, withUser(httptest.NewRequest("PUT", "/api/v1/buckets/cdn-origin/cache-defaults", bytes.NewReader([]byte(bad)))))
+		assert.Equal(t, http.StatusBadRequest, badRR.Code, bad)
+	}
+
+	delRR := httptest.NewRecorder()
+	server.handleDeleteBucketCacheDefaults(delRR, withUser(httptest.NewRequest("DELETE", "/api/v1/buckets/cdn-origin/cache-defaults", nil)))
+	require.Equal(t, http.StatusOK, delRR.Code)
+	assert.Nil(t, getCacheDefaults())
+}
+
+// TestHandleCreateBucketWithQuota tests that a quota given at creation is
+// stored and reported by GET /buckets/{bucket}
+func TestHandleCreateBucketWithQuota(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	user, err := server.authManager.ValidateJWT(context.Background(), getAdminToken(t, server))
+	require.NoError(t, err)
+
+	withUser := func(req *http.Request, bucketName string) *http.Request {
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		return mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":  "quota-at-create",
+		"quota": map[string]int64{"maxSizeBytes": 1 << 30, "maxObjectCount": 500},
+	})
+	createRR := httptest.NewRecorder()
+	server.handleCreateBucket(createRR, withUser(httptest.NewRequest("POST", "/api/v1/buckets", bytes.NewReader(body)), ""))
+	require.Equal(t, http.StatusOK, createRR.Code, createRR.Body.String())
+
+	rr := httptest.NewRecorder()
+	server.handleGetBucket(rr, withUser(httptest.NewRequest("GET", "/api/v1/buckets/quota-at-create", nil), "quota-at-create"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response struct {
+		Data BucketResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.NotNil(t, response.Data.Quota)
+	assert.Equal(t, int64(1<<30), response.Data.Quota.MaxSizeBytes)
+	assert.Equal(t, int64(500), response.Data.Quota.MaxObjectCount)
+	assert.Equal(t, int64(0), response.Data.ObjectCount)
+
+	body, _ = json.Marshal(map[string]interface{}{
+		"name":  "negative-quota",
+		"quota": map[string]int64{"maxObjectCount": -1},
+	})
+	badRR := httptest.NewRecorder()
+	server.handleCreateBucket(badRR, withUser(httptest.NewRequest("POST", "/api/v1/buckets", bytes.NewReader(body)), ""))
+	assert.Equal(t, http.StatusBadRequest, badRR.Code)
+}
+
+// TestHandleGeneratePresignedURL_MaxExpiry tests that presigned URL requests
+// above the configured maximum lifetime are rejected
+func TestHandleGeneratePresignedURL_MaxExpiry(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+	_, err = server.authManager.GenerateAccessKey(ctx, user.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", "presign-limits", user.ID))
+	_, err = server.objectManager.PutObject(ctx, "presign-limits", "report.pdf", bytes.NewReader([]byte("pdf")), http.Header{})
+	require.NoError(t, err)
+
+	presign := func(expiresIn int64) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"expiresIn": expiresIn})
+		req := httptest.NewRequest("POST", "/api/v1/buckets/presign-limits/objects/report.pdf/presigned-url", bytes.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		req = mux.SetURLVars(req, map[string]string{"bucket": "presign-limits", "object": "report.pdf"})
+		rr := httptest.NewRecorder()
+		server.handleGeneratePresignedURL(rr, req)
+		return rr
+	}
+
+	rr := presign(30 * 24 * 3600)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "604800")
+
+	rr = presign(6 * 24 * 3600)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), "X-Amz-Expires=518400")
+
+	// A lower configured limit is enforced too
+	server.config.Auth.PresignedMaxExpirySeconds = 3600
+	rr = presign(6 * 24 * 3600)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "3600")
+}
+
+// TestHandleGeneratePresignedURL_BucketRegion tests that presigned URLs are
+// scoped to the bucket's region and the configured signing service, and that
+// the S3 validator accepts them
+func TestHandleGeneratePresignedURL_BucketRegion(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+	key, err := server.authManager.GenerateAccessKey(ctx, user.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", "presign-region", user.ID))
+	bucketInfo, err := server.bucketManager.GetBucketInfo(ctx, "", "presign-region")
+	require.NoError(t, err)
+	bucketInfo.Region = "eu-west-1"
+	require.NoError(t, server.bucketManager.UpdateBucket(ctx, "", "presign-region", bucketInfo))
+	_, err = server.objectManager.PutObject(ctx, "presign-region", "report.pdf", bytes.NewReader([]byte("pdf")), http.Header{})
+	require.NoError(t, err)
+
+	presign := func() *url.URL {
+		req := httptest.NewRequest("POST", "/api/v1/buckets/presign-region/objects/report.pdf/presigned-url", strings.NewReader(`{"expiresIn": 600}`))
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		req = mux.SetURLVars(req, map[string]string{"bucket": "presign-region", "object": "report.pdf"})
+		rr := httptest.NewRecorder()
+		server.handleGeneratePresignedURL(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var response struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+		u, err := url.Parse(response.Data.URL)
+		require.NoError(t, err)
+		return u
+	}
+	validate := func(u *url.URL, service string) (bool, error) {
+		req := httptest.NewRequest("GET", u.String(), nil)
+		return presigned.ValidatePresignedURLForService(req, key.SecretAccessKey, auth.DefaultClockSkew, service)
+	}
+
+	u := presign()
+	assert.Contains(t, u.Query().Get("X-Amz-Credential"), "/eu-west-1/s3/aws4_request")
+	valid, err := validate(u, presigned.DefaultService)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// A configured signing service is used for signing and accepted back
+	server.config.Auth.SigningService = "objects"
+	u = presign()
+	assert.Contains(t, u.Query().Get("X-Amz-Credential"), "/eu-west-1/objects/aws4_request")
+	valid, err = validate(u, "objects")
+	require.NoError(t, err)
+	assert.True(t, valid)
+	_, err = validate(u, presigned.DefaultService)
+	assert.Error(t, err, "only s3 and the configured service are accepted")
+}
+
 // TestHandleDeleteBucket tests the DELETE /buckets/{bucket} endpoint
 func TestHandleDeleteBucket(t *testing.T) {
 	server, _, cleanup := setupTestServer(t)
@@ -1456,3 +1708,40 @@ func TestHandleListAuditLogs(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, response.Success)
 }
+
+// TestHandleListAuditLogs_ObjectLockHistory tests filtering audit logs down to
+// one object's lock history
+func TestHandleListAuditLogs_ObjectLockHistory(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+	server.objectManager.(interface{ SetAuditManager(*audit.Manager) }).SetAuditManager(server.auditManager)
+
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", "lock-history", user.ID))
+	for _, key := range []string{"a.pdf", "b.pdf"} {
+		_, err = server.objectManager.PutObject(ctx, "lock-history", key, bytes.NewReader([]byte("pdf")), http.Header{})
+		require.NoError(t, err)
+		require.NoError(t, server.objectManager.SetObjectLegalHold(ctx, "lock-history", key, &object.LegalHoldConfig{Status: "ON"}))
+	}
+	server.auditManager.Flush()
+
+	req := httptest.NewRequest("GET", "/api/v1/audit-logs?resource_type=object&resource_id=lock-history/a.pdf", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	rr := httptest.NewRecorder()
+	server.handleListAuditLogs(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response struct {
+		Data struct {
+			Logs  []*audit.AuditLog `json:"logs"`
+			Total int               `json:"total"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Equal(t, 1, response.Data.Total)
+	assert.Equal(t, audit.EventTypeObjectLegalHoldSet, response.Data.Logs[0].EventType)
+	assert.Equal(t, "lock-history/a.pdf", response.Data.Logs[0].ResourceID)
+}
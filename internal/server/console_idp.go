@@ -19,6 +19,7 @@ import (
 	"github.com/maxiofs/maxiofs/internal/auth"
 	"github.com/maxiofs/maxiofs/internal/cluster"
 	"github.com/maxiofs/maxiofs/internal/idp"
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/sirupsen/logrus"
 )
 
@@ -142,7 +143,7 @@ func (s *Server) handleCreateIDP(w http.ResponseWriter, r *http.Request) {
 		ResourceName: provider.Name,
 		Action:       "create_identity_provider",
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 	})
 
@@ -286,7 +287,7 @@ func (s *Server) handleDeleteIDP(w http.ResponseWriter, r *http.Request) {
 		ResourceName: existing.Name,
 		Action:       "delete_identity_provider",
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"linked_users": linkedCount,
@@ -529,7 +530,7 @@ func (s *Server) handleIDPImportUsers(w http.ResponseWriter, r *http.Request) {
 		ResourceName: provider.Name,
 		Action:       "import_users",
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"imported": imported,
@@ -1055,7 +1056,7 @@ func (s *Server) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 		ResourceName: user.Username,
 		Action:       audit.ActionLogin,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"method":      "oauth",
@@ -1274,7 +1275,7 @@ func (s *Server) tryAutoProvision(ctx context.Context, r *http.Request, external
 			ResourceName: newUser.Username,
 			Action:       "auto_provision_oauth",
 			Status:       audit.StatusSuccess,
-			IPAddress:    getClientIP(r, s.config.TrustedProxies),
+			IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 			UserAgent:    r.Header.Get("User-Agent"),
 			Details: map[string]interface{}{
 				"provider_id": provider.ID,
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// consoleObjectCacheControl lets the browser keep object previews but makes it
+// revalidate them with If-None-Match / If-Modified-Since on every use. The
+// console sits behind a session, so shared caches must not store them.
+const consoleObjectCacheControl = "private, no-cache"
+
+// quoteETag returns etag as an HTTP entity tag. Object ETags are stored as
+// bare hex digests; browsers only echo back properly quoted values.
+func quoteETag(etag string) string {
+	return `"` + strings.Trim(etag, `"`) + `"`
+}
+
+// metadataETag derives a weak entity tag for the JSON metadata view of an
+// object. Retention, legal hold and user metadata can change without the
+// object's content ETag changing, so the tag covers the whole response.
+func metadataETag(response interface{}) string {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// setConsoleCacheHeaders sets the validators and caching policy for a console
+// object response. Vary: Accept keeps the metadata and file representations,
+// which share a URL, apart in the browser cache.
+func setConsoleCacheHeaders(w http.ResponseWriter, etag string, lastModified time.Time) {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", consoleObjectCacheControl)
+	w.Header().Add("Vary", "Accept")
+}
+
+// consoleNotModified reports whether the client's cached copy is still current
+// per RFC 7232: If-None-Match is compared weakly against etag, and
+// If-Modified-Since is only consulted when If-None-Match is absent.
+func consoleNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etag == "" {
+			return false
+		}
+		current := weakETagValue(etag)
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakETagValue(candidate) == current {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ifModifiedSince); err == nil {
+			// HTTP dates have one-second resolution
+			return !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
+
+// weakETagValue strips the weak indicator and quotes so tags compare weakly.
+func weakETagValue(etag string) string {
+	return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+}
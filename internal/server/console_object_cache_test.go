@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetObject_ConditionalRequests(t *testing.T) {
+	server := getSharedServer()
+	ctx := context.Background()
+	tenantID := "test-tenant-get-cache"
+	bucketName := "test-bucket-get-cache"
+	objectKey := "preview.txt"
+
+	cleanupTestData(t, tenantID, bucketName)
+	require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{
+		ID: tenantID, Name: tenantID, Status: "active", MaxStorageBytes: 1000000000, MaxBuckets: 100, MaxAccessKeys: 10,
+	}))
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, tenantID, bucketName, ""))
+
+	put := func(content string) {
+		headers := http.Header{}
+		headers.Set("Content-Type", "text/plain")
+		_, err := server.objectManager.PutObject(ctx, tenantID+"/"+bucketName, objectKey, bytes.NewReader([]byte(content)), headers)
+		require.NoError(t, err)
+	}
+	get := func(accept string, headers map[string]string) *httptest.ResponseRecorder {
+		req := createAuthenticatedRequest("GET", "/api/v1/buckets/"+bucketName+"/objects/"+objectKey, nil, tenantID, "user-1", false)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		req = mux.SetURLVars(req, map[string]string{"bucket": bucketName, "object": objectKey})
+		rr := httptest.NewRecorder()
+		server.handleGetObject(rr, req)
+		return rr
+	}
+
+	put("first version")
+
+	for _, accept := range []string{"", "application/json"} {
+		name := "file download"
+		if accept != "" {
+			name = "JSON metadata"
+		}
+		t.Run(name, func(t *testing.T) {
+			first := get(accept, nil)
+			require.Equal(t, http.StatusOK, first.Code)
+			etag := first.Header().Get("ETag")
+			require.NotEmpty(t, etag)
+			assert.Equal(t, consoleObjectCacheControl, first.Header().Get("Cache-Control"))
+			assert.Contains(t, first.Header().Values("Vary"), "Accept")
+
+			cached := get(accept, map[string]string{"If-None-Match": etag})
+			assert.Equal(t, http.StatusNotModified, cached.Code)
+			assert.Empty(t, cached.Body.Bytes())
+			assert.Equal(t, etag, cached.Header().Get("ETag"))
+
+			lastModified := first.Header().Get("Last-Modified")
+			require.NotEmpty(t, lastModified)
+			assert.Equal(t, http.StatusNotModified, get(accept, map[string]string{"If-Modified-Since": lastModified}).Code)
+
+			stale := get(accept, map[string]string{"If-None-Match": `"0123456789abcdef"`})
+			assert.Equal(t, http.StatusOK, stale.Code)
+			assert.NotEmpty(t, stale.Body.Bytes())
+		})
+	}
+
+	t.Run("changed object returns 200", func(t *testing.T) {
+		fileETag := get("", nil).Header().Get("ETag")
+		jsonETag := get("application/json", nil).Header().Get("ETag")
+
+		put("second version, longer")
+
+		rr := get("", map[string]string{"If-None-Match": fileETag})
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "second version, longer", rr.Body.String())
+		assert.NotEqual(t, fileETag, rr.Header().Get("ETag"))
+
+		rr = get("application/json", map[string]string{"If-None-Match": jsonETag})
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotEqual(t, jsonETag, rr.Header().Get("ETag"))
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/maxiofs/maxiofs/internal/audit"
 	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/sirupsen/logrus"
 )
 
@@ -73,6 +74,10 @@ func (s *Server) handleDownloadZip(w http.ResponseWriter, r *http.Request) {
 			if strings.HasSuffix(obj.Key, "/") && obj.Size == 0 {
 				continue
 			}
+			if s.archivedReadDenied(&obj) {
+				s.writeError(w, fmt.Sprintf("%s is archived; restore it before downloading the folder", obj.Key), http.StatusForbidden)
+				return
+			}
 			entries = append(entries, zipEntry{key: obj.Key, size: obj.Size, modified: obj.LastModified})
 			totalSize += obj.Size
 		}
@@ -189,7 +194,7 @@ func (s *Server) handleDownloadZip(w http.ResponseWriter, r *http.Request) {
 		ResourceName: zipName + ".zip",
 		Action:       audit.ActionDownload,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"bucket":     bucketName,
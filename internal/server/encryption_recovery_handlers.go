@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/maxiofs/maxiofs/internal/auth"
 	"github.com/maxiofs/maxiofs/internal/kek"
 	"github.com/sirupsen/logrus"
@@ -153,6 +154,51 @@ func (s *Server) handleRotateKEK(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleRotateTenantKey creates a new current version of a tenant's
+// encryption key. The tenant's existing objects stay decryptable (old
+// versions are kept); the background worker re-wraps their DEKs to the new
+// version. Other tenants are unaffected.
+// POST /api/v1/tenants/{tenant}/encryption/rotate-key  (global admin only)
+func (s *Server) handleRotateTenantKey(w http.ResponseWriter, r *http.Request) {
+	user := s.requireGlobalAdmin(w, r)
+	if user == nil {
+		return
+	}
+	if s.kekStore == nil {
+		s.writeError(w, "Encryption key store is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenant"]
+	if _, err := s.authManager.GetTenant(r.Context(), tenantID); err != nil {
+		s.writeError(w, "Tenant not found", http.StatusNotFound)
+		return
+	}
+
+	newVersion, err := s.kekStore.RotateTenantKey(tenantID)
+	if err != nil {
+		logrus.WithError(err).WithField("tenant_id", tenantID).Error("Tenant key rotation failed")
+		s.writeError(w, "Failed to rotate tenant encryption key: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	logrus.WithFields(logrus.Fields{"user": user.Username, "tenant_id": tenantID, "tenant_key_version": newVersion}).
+		Info("Tenant encryption key rotated by admin")
+
+	// Kick the worker so the tenant's DEKs start re-wrapping immediately.
+	if !s.encWorkerRunning.Load() {
+		bg := s.serverCtx
+		if bg == nil {
+			bg = context.Background()
+		}
+		go s.runEncryptionPass(bg)
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"tenantId":   tenantID,
+		"newVersion": newVersion,
+	})
+}
+
 // handleDownloadRecoveryBundle exports the KEK as a passphrase-encrypted
 // bundle file and marks it as downloaded.
 // POST /api/v1/settings/encryption/recovery-bundle  body: {"passphrase": "..."}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/middleware"
+)
+
+// canBypassGovernance reports whether user may delete the object past its
+// GOVERNANCE retention, with the same rules as the S3 API: an explicit Deny
+// of s3:BypassGovernanceRetention in the bucket policy refuses it, an Allow
+// grants it unless RestrictPublicBuckets discards it for this caller, and
+// otherwise the user needs the object:bypass_governance capability, which the
+// admin role alone does not give.
+func (s *Server) canBypassGovernance(r *http.Request, user *auth.User, tenantID, bucketName, objectKey string) bool {
+	ctx := r.Context()
+	if policy, err := s.bucketManager.GetBucketPolicy(ctx, tenantID, bucketName); err == nil && policy != nil {
+		decision := bucket.EvaluatePolicy(ctx, policy, bucket.PolicyEvaluationRequest{
+			Principal:       user.ID,
+			Action:          auth.ActionBypassGovernanceRetention,
+			Resource:        fmt.Sprintf("arn:aws:s3:::%s/%s", bucketName, objectKey),
+			Bucket:          bucketName,
+			SourceIP:        middleware.PolicyClientIP(r, s.config.TrustedProxies),
+			SecureTransport: middleware.PolicySecureTransport(r, s.config.TrustedProxies),
+		})
+		if decision == bucket.DecisionAllow {
+			pab, _ := s.bucketManager.GetPublicAccessBlock(ctx, tenantID, bucketName)
+			if bucket.RestrictsPublicPolicy(ctx, pab, policy, tenantID) {
+				decision = bucket.DecisionDeny
+			}
+		}
+		switch decision {
+		case bucket.DecisionExplicitDeny:
+			return false
+		case bucket.DecisionAllow:
+			return true
+		}
+	}
+	allowed, err := s.authManager.HasCapability(ctx, user.ID, user.Roles, auth.CapObjectBypassGovernance)
+	return err == nil && allowed
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleDeleteGovernanceBypass(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	bucketName := "governed"
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", bucketName, "bypass-admin"))
+	require.NoError(t, server.bucketManager.SetDefaultWriteLock(ctx, "", bucketName, 1))
+
+	newUser := func(id string, roles ...string) *auth.User {
+		user := &auth.User{ID: id, Username: id, Password: id + "-password", Status: "active", Roles: roles, CreatedAt: time.Now().Unix()}
+		require.NoError(t, server.authManager.CreateUser(ctx, user))
+		return user
+	}
+	admin := newUser("bypass-admin", "admin")
+	delegate := newUser("bypass-delegate", "user")
+
+	putLocked := func(key string) {
+		t.Helper()
+		_, err := server.objectManager.PutObject(ctx, bucketName, key, bytes.NewReader([]byte("locked")), http.Header{})
+		require.NoError(t, err)
+	}
+	deleteAs := func(user *auth.User, key string, bypass bool) int {
+		path := "/api/v1/buckets/" + bucketName + "/objects/" + key
+		if bypass {
+			path += "?bypassGovernance=true"
+		}
+		req := httptest.NewRequest("DELETE", path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		req = mux.SetURLVars(req, map[string]string{"bucket": bucketName, "object": key})
+		rr := httptest.NewRecorder()
+		server.handleDeleteObject(rr, req)
+		return rr.Code
+	}
+
+	putLocked("report.txt")
+	assert.Equal(t, http.StatusForbidden, deleteAs(admin, "report.txt", false), "retention blocks a plain delete")
+	assert.Equal(t, http.StatusForbidden, deleteAs(admin, "report.txt", true), "the admin role alone does not allow the bypass")
+	assert.Equal(t, http.StatusForbidden, deleteAs(delegate, "report.txt", true))
+
+	require.NoError(t, server.authManager.SetCapabilityOverride(ctx, delegate.ID, auth.CapObjectBypassGovernance, admin.ID, true))
+	assert.Equal(t, http.StatusNoContent, deleteAs(delegate, "report.txt", true), "a user with the bypass capability can delete")
+	_, err := server.objectManager.GetObjectMetadata(ctx, bucketName, "report.txt")
+	assert.Error(t, err)
+}
+
+func TestConsoleGovernanceBypass_RestrictPublicBuckets(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	bucketName := "public-governed"
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", bucketName, "owner"))
+	require.NoError(t, server.bucketManager.SetBucketPolicy(ctx, "", bucketName, &bucket.Policy{
+		Version: "2012-10-17",
+		Statement: []bucket.Statement{{
+			Effect:    "Allow",
+			Principal: "*",
+			Action:    auth.ActionBypassGovernanceRetention,
+			Resource:  "arn:aws:s3:::" + bucketName + "/*",
+		}},
+	}))
+
+	outsider := &auth.User{ID: "outsider", TenantID: "other-tenant", Roles: []string{"user"}}
+	insider := &auth.User{ID: "insider", Roles: []string{"user"}}
+	canBypass := func(user *auth.User) bool {
+		req := httptest.NewRequest("DELETE", "/api/v1/buckets/"+bucketName+"/objects/doc.txt", nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		return server.canBypassGovernance(req, user, "", bucketName, "doc.txt")
+	}
+
+	assert.True(t, canBypass(outsider), "a public policy grants the bypass to anyone")
+
+	require.NoError(t, server.bucketManager.SetPublicAccessBlock(ctx, "", bucketName, &bucket.PublicAccessBlock{RestrictPublicBuckets: true}))
+	assert.False(t, canBypass(outsider), "RestrictPublicBuckets limits a public policy to the bucket's tenant")
+	assert.True(t, canBypass(insider))
+}
@@ -12,6 +12,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
 	"github.com/maxiofs/maxiofs/internal/config"
 	"github.com/maxiofs/maxiofs/internal/kek"
 	"github.com/maxiofs/maxiofs/internal/metadata"
@@ -206,3 +208,56 @@ func hexOf(b []byte) string {
 	}
 	return string(out)
 }
+
+// TestRotateTenantKeyEndpoint: only a global admin rotates a tenant's key,
+// and rotation moves that tenant alone to a new key version.
+func TestRotateTenantKeyEndpoint(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Occupy the worker slot so the handler does not start a background pass
+	// that would outlive the test server.
+	server.encWorkerRunning.Store(true)
+
+	db, ok := server.authManager.GetDB().(*sql.DB)
+	require.True(t, ok)
+	kekStore, err := kek.Bootstrap(db, "")
+	require.NoError(t, err)
+	server.kekStore = kekStore
+
+	for _, tenantID := range []string{"keys-tenant-a", "keys-tenant-b"} {
+		require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{ID: tenantID, Name: tenantID, Status: "active"}))
+	}
+	_, versionB, err := kekStore.CurrentTenantKey("keys-tenant-b")
+	require.NoError(t, err)
+
+	rotate := func(tenantID, userTenantID string, globalAdmin bool) *httptest.ResponseRecorder {
+		req := createAuthenticatedRequest("POST", "/api/v1/tenants/"+tenantID+"/encryption/rotate-key", nil, userTenantID, "admin-user", globalAdmin)
+		req = mux.SetURLVars(req, map[string]string{"tenant": tenantID})
+		w := httptest.NewRecorder()
+		server.handleRotateTenantKey(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, rotate("keys-tenant-a", "keys-tenant-a", true).Code, "tenant admins cannot rotate keys")
+	assert.Equal(t, http.StatusNotFound, rotate("no-such-tenant", "", true).Code)
+
+	w := rotate("keys-tenant-a", "", true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data struct {
+			TenantID   string `json:"tenantId"`
+			NewVersion int    `json:"newVersion"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "keys-tenant-a", resp.Data.TenantID)
+
+	_, currentA, err := server.kekStore.CurrentTenantKey("keys-tenant-a")
+	require.NoError(t, err)
+	assert.Equal(t, currentA, resp.Data.NewVersion)
+	_, currentB, err := server.kekStore.CurrentTenantKey("keys-tenant-b")
+	require.NoError(t, err)
+	assert.Equal(t, versionB, currentB, "rotating one tenant's key must not touch another's")
+}
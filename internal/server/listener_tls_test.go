@@ -0,0 +1,79 @@
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/cluster"
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair writes a fresh self-signed certificate and its key to dir.
+func writeKeyPair(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	certPEM, keyPEM, err := cluster.GenerateCA()
+	require.NoError(t, err)
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600))
+	return certFile, keyFile
+}
+
+func TestListenerTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	s3Cert, s3Key := writeKeyPair(t, dir, "s3")
+	_, otherKey := writeKeyPair(t, dir, "other")
+	on, off := true, false
+
+	t.Run("S3 over TLS with a plaintext console", func(t *testing.T) {
+		cfg := &config.Config{S3TLS: config.ListenerTLSConfig{Enable: &on, CertFile: s3Cert, KeyFile: s3Key}}
+
+		apiTLS, err := listenerTLSConfig("S3 API", cfg.S3ListenerTLS(), nil)
+		require.NoError(t, err)
+		require.NotNil(t, apiTLS)
+		assert.Len(t, apiTLS.Certificates, 1)
+		assert.Equal(t, uint16(tls.VersionTLS12), apiTLS.MinVersion)
+
+		consoleTLS, err := listenerTLSConfig("console", cfg.ConsoleListenerTLS(), nil)
+		require.NoError(t, err)
+		assert.Nil(t, consoleTLS)
+	})
+
+	t.Run("console over TLS with S3 opted out of the global setting", func(t *testing.T) {
+		cfg := &config.Config{EnableTLS: true, CertFile: s3Cert, KeyFile: s3Key,
+			S3TLS: config.ListenerTLSConfig{Enable: &off}}
+
+		apiTLS, err := listenerTLSConfig("S3 API", cfg.S3ListenerTLS(), nil)
+		require.NoError(t, err)
+		assert.Nil(t, apiTLS)
+
+		consoleTLS, err := listenerTLSConfig("console", cfg.ConsoleListenerTLS(), nil)
+		require.NoError(t, err)
+		require.NotNil(t, consoleTLS)
+		assert.Len(t, consoleTLS.Certificates, 1)
+	})
+
+	t.Run("the client CA settings are kept", func(t *testing.T) {
+		base := &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, MinVersion: tls.VersionTLS13}
+		apiTLS, err := listenerTLSConfig("S3 API", config.ListenerTLS{Enabled: true, CertFile: s3Cert, KeyFile: s3Key}, base)
+		require.NoError(t, err)
+		assert.Equal(t, tls.VerifyClientCertIfGiven, apiTLS.ClientAuth)
+		assert.Equal(t, uint16(tls.VersionTLS13), apiTLS.MinVersion)
+		assert.Empty(t, base.Certificates, "the base config must not be modified")
+	})
+
+	t.Run("a mismatched pair fails server startup", func(t *testing.T) {
+		cfg := &config.Config{
+			DataDir: t.TempDir(),
+			S3TLS:   config.ListenerTLSConfig{Enable: &on, CertFile: s3Cert, KeyFile: otherKey},
+		}
+		_, err := New(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "S3 API TLS")
+	})
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/maxiofs/maxiofs/internal/audit"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// Maintenance modes accepted by POST /admin/maintenance. The mode is stored
+// in the system.maintenance_mode setting, so it survives a restart until an
+// admin turns it off.
+const (
+	maintenanceModeReadOnly = "readonly"
+	maintenanceModeOff      = "off"
+)
+
+// maintenanceEnabled reports whether read-only maintenance mode is on.
+func (s *Server) maintenanceEnabled() bool {
+	if s.settingsManager == nil {
+		return false
+	}
+	enabled, _ := s.settingsManager.GetBool("system.maintenance_mode")
+	return enabled
+}
+
+func (s *Server) maintenanceMode() string {
+	if s.maintenanceEnabled() {
+		return maintenanceModeReadOnly
+	}
+	return maintenanceModeOff
+}
+
+// handleGetMaintenance returns the current maintenance mode.
+// GET /api/v1/admin/maintenance
+func (s *Server) handleGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if _, exists := auth.GetUserFromContext(r.Context()); !exists {
+		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	s.writeJSON(w, map[string]string{"mode": s.maintenanceMode()})
+}
+
+// handleSetMaintenance switches read-only maintenance mode on or off. While
+// it is on, S3 and console write requests get 503; reads keep working.
+// POST /api/v1/admin/maintenance {"mode": "readonly" | "off"}
+func (s *Server) handleSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	user, exists := auth.GetUserFromContext(r.Context())
+	if !exists {
+		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !auth.IsAdminUser(r.Context()) || user.TenantID != "" {
+		s.writeError(w, "Forbidden: only global admins can change maintenance mode", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Mode string `json:"mode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Mode != maintenanceModeReadOnly && req.Mode != maintenanceModeOff {
+		s.writeError(w, `mode must be "readonly" or "off"`, http.StatusBadRequest)
+		return
+	}
+
+	previous := s.maintenanceMode()
+	enabled := req.Mode == maintenanceModeReadOnly
+	if err := s.settingsManager.Set("system.maintenance_mode", strconv.FormatBool(enabled)); err != nil {
+		logrus.WithError(err).Error("Failed to update maintenance mode")
+		s.writeError(w, "Failed to update maintenance mode", http.StatusInternalServerError)
+		return
+	}
+	logrus.WithFields(logrus.Fields{"user": user.Username, "mode": req.Mode}).Info("Maintenance mode changed by admin")
+
+	s.logAuditEvent(r.Context(), &audit.AuditEvent{
+		EventType:    "setting_updated",
+		UserID:       user.ID,
+		Username:     user.Username,
+		TenantID:     user.TenantID,
+		ResourceType: "setting",
+		ResourceID:   "system.maintenance_mode",
+		Action:       "update",
+		Status:       "success",
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
+		UserAgent:    r.UserAgent(),
+		Details: map[string]interface{}{
+			"mode":          req.Mode,
+			"previous_mode": previous,
+		},
+	})
+
+	s.writeJSON(w, map[string]string{"mode": req.Mode})
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceModeEndpoint(t *testing.T) {
+	server, tmpDir, cleanup := setupTestServer(t)
+	defer cleanup()
+	server.systemMetrics = metrics.NewSystemMetrics(tmpDir)
+	token := getAdminToken(t, server)
+
+	router := mux.NewRouter()
+	server.setupConsoleAPIRoutes(router)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	mode := func(rr *httptest.ResponseRecorder) string {
+		var body struct {
+			Data struct {
+				Mode string `json:"mode"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return body.Data.Mode
+	}
+
+	rr := do("GET", "/admin/maintenance", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "off", mode(rr))
+
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/admin/maintenance", `{"mode":"writeonly"}`).Code)
+
+	rr = do("POST", "/admin/maintenance", `{"mode":"readonly"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "readonly", mode(rr))
+
+	// The mode is stored as a setting, so it survives a restart
+	enabled, err := server.settingsManager.GetBool("system.maintenance_mode")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	// Console writes are refused, reads are served
+	rr = do("POST", "/buckets", `{"name":"during-maintenance"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), "MAINTENANCE_MODE")
+	assert.Equal(t, http.StatusOK, do("GET", "/buckets", "").Code)
+
+	// Readiness and system metrics report the mode
+	server.apiRateLimiter = auth.NewAPIRateLimiter(0, 0)
+	server.anonRateLimiter = auth.NewAPIRateLimiter(0, 0)
+	require.NoError(t, server.setupRoutes())
+	readyRR := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(readyRR, httptest.NewRequest("GET", "/ready", nil))
+	require.Equal(t, http.StatusOK, readyRR.Code, readyRR.Body.String())
+	assert.Contains(t, readyRR.Body.String(), `"maintenance":"readonly"`)
+	rr = do("GET", "/metrics/system", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Contains(t, rr.Body.String(), `"maintenanceMode":"readonly"`)
+
+	// Maintenance mode can be turned off while it is on
+	rr = do("POST", "/admin/maintenance", `{"mode":"off"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "off", mode(rr))
+	assert.NotEqual(t, http.StatusServiceUnavailable, do("POST", "/buckets", `{"name":"after-maintenance"}`).Code)
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"runtime/debug"
+	rtmetrics "runtime/metrics"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/lru"
+	"github.com/maxiofs/maxiofs/internal/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// memoryCheckInterval is how often the heap is compared with
+	// memory.soft_limit_mb.
+	memoryCheckInterval = 10 * time.Second
+
+	// memoryPressureShrink is the fraction of every cache dropped when the
+	// heap is over the soft limit.
+	memoryPressureShrink = 0.5
+
+	heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+)
+
+// memoryCaches returns the in-memory caches and rate-limiter tables by the
+// name they are exported under in maxiofs_cache_* metrics.
+func (s *Server) memoryCaches() map[string]lru.Shrinkable {
+	caches := make(map[string]lru.Shrinkable)
+	if bm, ok := s.bucketManager.(interface{ BucketInfoCache() lru.Shrinkable }); ok {
+		if c := bm.BucketInfoCache(); c != nil {
+			caches["bucket_info"] = c
+		}
+	}
+	if am, ok := s.authManager.(interface{ LoginRateLimitCache() lru.Shrinkable }); ok {
+		caches["login_rate_limiter"] = am.LoginRateLimitCache()
+	}
+	if s.apiRateLimiter != nil {
+		caches["api_rate_limiter"] = s.apiRateLimiter.Cache()
+	}
+	if s.anonRateLimiter != nil {
+		caches["anonymous_rate_limiter"] = s.anonRateLimiter.Cache()
+	}
+	if s.thumbnails != nil {
+		caches["thumbnails"] = s.thumbnails
+	}
+	return caches
+}
+
+// startMemoryMonitor exports the cache statistics and, with
+// memory.soft_limit_mb set, hands the limit to the Go runtime and checks the
+// heap every memoryCheckInterval, shrinking the caches when it is over.
+func (s *Server) startMemoryMonitor(ctx context.Context) {
+	if mm, ok := s.metricsManager.(interface {
+		SetCacheStatsProvider(metrics.CacheStatsProvider)
+	}); ok {
+		mm.SetCacheStatsProvider(func() map[string]lru.Stats {
+			stats := make(map[string]lru.Stats)
+			for name, c := range s.memoryCaches() {
+				stats[name] = c.Stats()
+			}
+			return stats
+		})
+	}
+
+	limit := int64(s.config.Memory.SoftLimitMB) << 20
+	if limit <= 0 {
+		return
+	}
+	debug.SetMemoryLimit(limit)
+
+	go func() {
+		sample := []rtmetrics.Sample{{Name: heapObjectsMetric}}
+		ticker := time.NewTicker(memoryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rtmetrics.Read(sample)
+				if heap := int64(sample[0].Value.Uint64()); heap > limit {
+					s.relieveMemoryPressure(heap, limit)
+				}
+			}
+		}
+	}()
+}
+
+// relieveMemoryPressure drops the least recently used part of every cache
+// and returns the freed memory to the OS.
+func (s *Server) relieveMemoryPressure(heap, limit int64) {
+	dropped := 0
+	for _, c := range s.memoryCaches() {
+		dropped += c.Shrink(memoryPressureShrink)
+	}
+	debug.FreeOSMemory()
+	logrus.WithFields(logrus.Fields{
+		"heap_mb":       heap >> 20,
+		"soft_limit_mb": limit >> 20,
+		"dropped":       dropped,
+	}).Warn("Heap over memory.soft_limit_mb, shrank in-memory caches")
+}
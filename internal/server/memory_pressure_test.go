@@ -0,0 +1,39 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelieveMemoryPressureShrinksCaches(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	server.thumbnails = newThumbnailCache(defaultThumbnailCacheMB << 20)
+	server.apiRateLimiter = auth.NewAPIRateLimiter(0, 0)
+	for i := 0; i < 10; i++ {
+		server.thumbnails.put(&thumbnail{key: fmt.Sprintf("t%d", i), data: []byte("x")})
+		server.apiRateLimiter.Allow(fmt.Sprintf("key%d", i), 100)
+	}
+
+	caches := server.memoryCaches()
+	require.Contains(t, caches, "thumbnails")
+	require.Contains(t, caches, "api_rate_limiter")
+	require.Contains(t, caches, "login_rate_limiter")
+
+	server.relieveMemoryPressure(2<<20, 1<<20)
+
+	thumbs := caches["thumbnails"].Stats()
+	assert.Equal(t, 5, thumbs.Entries)
+	assert.Equal(t, int64(5), thumbs.Shrunk)
+	_, ok := server.thumbnails.get("t9")
+	assert.True(t, ok, "the most recently used thumbnail survives")
+
+	limiter := caches["api_rate_limiter"].Stats()
+	assert.Equal(t, 5, limiter.Entries)
+	assert.Equal(t, int64(5), limiter.Shrunk)
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/audit"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/middleware"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/sirupsen/logrus"
+)
+
+// multipartUploadPayload is the console representation of an in-progress
+// multipart upload.
+type multipartUploadPayload struct {
+	Key          string    `json:"key"`
+	UploadID     string    `json:"uploadId"`
+	Initiated    time.Time `json:"initiated"`
+	StorageClass string    `json:"storageClass,omitempty"`
+	Parts        int       `json:"parts"`
+	Size         int64     `json:"size"` // bytes uploaded so far
+}
+
+// handleListBucketMultipartUploads lists the bucket's in-progress multipart
+// uploads with the parts and bytes each holds, ordered by key then upload ID.
+// GET /api/v1/buckets/{bucket}/multipart-uploads?prefix=&keyMarker=&uploadIdMarker=&maxUploads=
+func (s *Server) handleListBucketMultipartUploads(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucketName := mux.Vars(r)["bucket"]
+
+	// Uploads live on the node that owns the bucket
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return
+	}
+	if _, ok := auth.GetUserFromContext(ctx); !ok {
+		s.writeError(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	keyMarker := query.Get("keyMarker")
+	uploadIDMarker := query.Get("uploadIdMarker")
+	maxUploads := 100
+	if v := query.Get("maxUploads"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			s.writeError(w, "maxUploads must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		maxUploads = min(parsed, 1000)
+	}
+
+	tenantID := s.resolveTenantID(r)
+	if _, err := s.bucketManager.GetBucketInfo(ctx, tenantID, bucketName); err != nil {
+		s.writeError(w, "Bucket not found", http.StatusNotFound)
+		return
+	}
+	bucketPath := buildBucketPath(tenantID, bucketName)
+
+	uploads, err := s.objectManager.ListMultipartUploads(ctx, bucketPath)
+	if err != nil {
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].Key == uploads[j].Key {
+			return uploads[i].UploadID < uploads[j].UploadID
+		}
+		return uploads[i].Key < uploads[j].Key
+	})
+
+	page := make([]multipartUploadPayload, 0)
+	isTruncated := false
+	for _, upload := range uploads {
+		if !strings.HasPrefix(upload.Key, prefix) {
+			continue
+		}
+		if upload.Key < keyMarker || (upload.Key == keyMarker && upload.UploadID <= uploadIDMarker) {
+			continue
+		}
+		if len(page) == maxUploads {
+			isTruncated = true
+			break
+		}
+
+		entry := multipartUploadPayload{
+			Key:          upload.Key,
+			UploadID:     upload.UploadID,
+			Initiated:    upload.Initiated,
+			StorageClass: upload.StorageClass,
+		}
+		if parts, err := s.objectManager.ListParts(ctx, upload.UploadID); err == nil {
+			entry.Parts = len(parts)
+			for _, part := range parts {
+				entry.Size += part.Size
+			}
+		}
+		page = append(page, entry)
+	}
+
+	response := map[string]interface{}{
+		"uploads":     page,
+		"isTruncated": isTruncated,
+	}
+	if isTruncated {
+		last := page[len(page)-1]
+		response["nextKeyMarker"] = last.Key
+		response["nextUploadIdMarker"] = last.UploadID
+	}
+	s.writeJSON(w, response)
+}
+
+// handleAbortBucketMultipartUpload aborts one in-progress multipart upload of
+// the bucket and deletes the parts it has stored.
+// DELETE /api/v1/buckets/{bucket}/multipart-uploads/{uploadId}
+func (s *Server) handleAbortBucketMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	uploadID := vars["uploadId"]
+
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return
+	}
+	user, ok := auth.GetUserFromContext(ctx)
+	if !ok {
+		s.writeError(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+	if !s.requireCapability(w, r, auth.CapObjectDelete, "You do not have permission to delete objects") {
+		return
+	}
+
+	tenantID := s.resolveTenantID(r)
+	if _, err := s.bucketManager.GetBucketInfo(ctx, tenantID, bucketName); err != nil {
+		s.writeError(w, "Bucket not found", http.StatusNotFound)
+		return
+	}
+	bucketPath := buildBucketPath(tenantID, bucketName)
+
+	// The upload ID alone would reach any bucket's uploads
+	upload, err := s.metadataStore.GetMultipartUpload(ctx, uploadID)
+	if err != nil || upload.Bucket != bucketPath {
+		if err != nil && err != metadata.ErrUploadNotFound {
+			s.writeError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.writeError(w, "Multipart upload not found", http.StatusNotFound)
+		return
+	}
+
+	var freed int64
+	if parts, err := s.objectManager.ListParts(ctx, uploadID); err == nil {
+		for _, part := range parts {
+			freed += part.Size
+		}
+	}
+
+	if err := s.objectManager.AbortMultipartUpload(ctx, uploadID); err != nil {
+		if err == object.ErrUploadNotFound {
+			s.writeError(w, "Multipart upload not found", http.StatusNotFound)
+			return
+		}
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"bucket":   bucketName,
+		"key":      upload.Key,
+		"uploadId": uploadID,
+		"freed":    freed,
+	}).Info("Multipart upload aborted from console")
+
+	s.logAuditEvent(ctx, &audit.AuditEvent{
+		TenantID:     tenantID,
+		UserID:       user.ID,
+		Username:     user.Username,
+		EventType:    audit.EventTypeObjectDeleted,
+		ResourceType: audit.ResourceTypeObject,
+		ResourceID:   upload.Key,
+		ResourceName: upload.Key,
+		Action:       audit.ActionDelete,
+		Status:       audit.StatusSuccess,
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
+		UserAgent:    r.Header.Get("User-Agent"),
+		Details: map[string]interface{}{
+			"bucket":      bucketName,
+			"key":         upload.Key,
+			"upload_id":   uploadID,
+			"freed_bytes": freed,
+		},
+	})
+
+	s.writeJSON(w, map[string]interface{}{
+		"uploadId":   uploadID,
+		"key":        upload.Key,
+		"freedBytes": freed,
+	})
+}
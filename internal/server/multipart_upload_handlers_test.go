@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleBucketMultipartUploads(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{ID: "acme", Name: "acme", Status: "active"}))
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "acme", "backups", ""))
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "acme", "other", ""))
+	user := &auth.User{ID: "ops", TenantID: "acme", Roles: []string{auth.RoleAdmin}}
+
+	upload, err := server.objectManager.CreateMultipartUpload(ctx, "acme/backups", "db/dump.tar", http.Header{})
+	require.NoError(t, err)
+	_, err = server.objectManager.UploadPart(ctx, upload.UploadID, 1, strings.NewReader(strings.Repeat("x", 1000)))
+	require.NoError(t, err)
+	_, err = server.objectManager.UploadPart(ctx, upload.UploadID, 2, strings.NewReader("tail"))
+	require.NoError(t, err)
+	second, err := server.objectManager.CreateMultipartUpload(ctx, "acme/backups", "logs/app.log", http.Header{})
+	require.NoError(t, err)
+
+	call := func(method, bucket, target string, vars map[string]string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		vars["bucket"] = bucket
+		req = mux.SetURLVars(req, vars)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	type listResponse struct {
+		Data struct {
+			Uploads            []multipartUploadPayload `json:"uploads"`
+			IsTruncated        bool                     `json:"isTruncated"`
+			NextKeyMarker      string                   `json:"nextKeyMarker"`
+			NextUploadIDMarker string                   `json:"nextUploadIdMarker"`
+		} `json:"data"`
+	}
+	list := func(query string) listResponse {
+		rr := call("GET", "backups", "/api/v1/buckets/backups/multipart-uploads"+query, map[string]string{}, server.handleListBucketMultipartUploads)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var resp listResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+
+	t.Run("list", func(t *testing.T) {
+		resp := list("")
+		require.Len(t, resp.Data.Uploads, 2)
+		assert.False(t, resp.Data.IsTruncated)
+		assert.Equal(t, "db/dump.tar", resp.Data.Uploads[0].Key)
+		assert.Equal(t, upload.UploadID, resp.Data.Uploads[0].UploadID)
+		assert.Equal(t, 2, resp.Data.Uploads[0].Parts)
+		assert.Equal(t, int64(1004), resp.Data.Uploads[0].Size)
+		assert.False(t, resp.Data.Uploads[0].Initiated.IsZero())
+		assert.Equal(t, 0, resp.Data.Uploads[1].Parts)
+	})
+
+	t.Run("paginate", func(t *testing.T) {
+		resp := list("?maxUploads=1")
+		require.Len(t, resp.Data.Uploads, 1)
+		assert.True(t, resp.Data.IsTruncated)
+
+		resp = list(fmt.Sprintf("?maxUploads=1&keyMarker=%s&uploadIdMarker=%s", resp.Data.NextKeyMarker, resp.Data.NextUploadIDMarker))
+		require.Len(t, resp.Data.Uploads, 1)
+		assert.Equal(t, second.UploadID, resp.Data.Uploads[0].UploadID)
+		assert.False(t, resp.Data.IsTruncated)
+
+		assert.Len(t, list("?prefix=logs/").Data.Uploads, 1)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		abort := func(bucket, uploadID string) *httptest.ResponseRecorder {
+			return call("DELETE", bucket, "/api/v1/buckets/"+bucket+"/multipart-uploads/"+uploadID,
+				map[string]string{"uploadId": uploadID}, server.handleAbortBucketMultipartUpload)
+		}
+
+		// The upload belongs to another bucket
+		assert.Equal(t, http.StatusNotFound, abort("other", upload.UploadID).Code)
+		assert.Equal(t, http.StatusNotFound, abort("backups", "no-such-upload").Code)
+
+		partPath := fmt.Sprintf(".maxiofs/multipart/parts/%s/%05d", upload.UploadID, 1)
+		exists, err := server.storageBackend.Exists(ctx, partPath)
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		rr := abort("backups", upload.UploadID)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Contains(t, rr.Body.String(), `"freedBytes":1004`)
+
+		exists, err = server.storageBackend.Exists(ctx, partPath)
+		require.NoError(t, err)
+		assert.False(t, exists, "part data must be deleted")
+		_, err = server.objectManager.ListParts(ctx, upload.UploadID)
+		assert.ErrorIs(t, err, object.ErrUploadNotFound)
+
+		resp := list("")
+		require.Len(t, resp.Data.Uploads, 1)
+		assert.Equal(t, second.UploadID, resp.Data.Uploads[0].UploadID)
+	})
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/maxiofs/maxiofs/internal/audit"
 	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/sirupsen/logrus"
 )
@@ -20,9 +22,9 @@ import (
 // handleRenameObject implements POST /buckets/{bucket}/objects/{object:.*}/rename
 // Body: { "newKey": "path/to/new-name.txt" }
 //
-// Rename is implemented as: copy the object data + metadata to the new key,
-// then delete the original.  Tags are also copied (best-effort).
-// Renaming is blocked for objects under COMPLIANCE retention or active Legal Hold.
+// See object.Manager.RenameObject: in a versioned bucket the new key gets a
+// new version and the old key a delete marker, and both keep their history.
+// Renaming is blocked for objects under retention or active Legal Hold.
 func (s *Server) handleRenameObject(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bucketName := vars["bucket"]
@@ -62,8 +64,167 @@ func (s *Server) handleRenameObject(w http.ResponseWriter, r *http.Request) {
 		bucketPath = tenantID + "/" + bucketName
 	}
 
-	// 1. Fetch source metadata + open data stream
-	srcObj, reader, err := s.objectManager.GetObject(r.Context(), bucketPath, objectKey)
+	renamed, err := s.objectManager.RenameObject(r.Context(), bucketPath, objectKey, req.NewKey)
+	if err != nil {
+		var retErr *object.RetentionError
+		switch {
+		case err == object.ErrObjectNotFound:
+			s.writeError(w, "Object not found", http.StatusNotFound)
+		case err == object.ErrInvalidObjectName:
+			s.writeError(w, "Invalid key: folders can't be renamed", http.StatusBadRequest)
+		case err == object.ErrObjectUnderLegalHold:
+			s.writeError(w, "Cannot rename: object has an active Legal Hold", http.StatusForbidden)
+		case errors.As(err, &retErr):
+			s.writeError(w, "Cannot rename: "+retErr.Error(), http.StatusForbidden)
+		case err == object.ErrObjectExists:
+			s.writeError(w, "The bucket does not allow overwriting existing objects", http.StatusConflict)
+		case errors.Is(err, object.ErrBucketQuotaExceeded), strings.Contains(err.Error(), "quota exceeded"):
+			s.writeError(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, object.ErrReservedKeyPrefix):
+			s.writeError(w, err.Error(), http.StatusForbidden)
+		default:
+			s.writeError(w, fmt.Sprintf("Failed to rename object: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	s.logAuditEvent(r.Context(), &audit.AuditEvent{
+		TenantID:     tenantID,
+		UserID:       user.ID,
+		Username:     user.Username,
+		EventType:    audit.EventTypeObjectUploaded,
+		ResourceType: audit.ResourceTypeObject,
+		ResourceID:   req.NewKey,
+		ResourceName: req.NewKey,
+		Action:       audit.ActionUpdate,
+		Status:       audit.StatusSuccess,
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
+		UserAgent:    r.Header.Get("User-Agent"),
+		Details: map[string]interface{}{
+			"bucket":     bucketName,
+			"old_key":    objectKey,
+			"new_key":    req.NewKey,
+			"version_id": renamed.VersionID,
+		},
+	})
+
+	response := map[string]string{"newKey": req.NewKey}
+	if renamed.VersionID != "" {
+		response["versionId"] = renamed.VersionID
+	}
+	s.writeJSON(w, response)
+}
+
+// objectPutHeaders rebuilds the request headers PutObject needs to write a
+// copy of obj with the same content headers and user metadata.
+func objectPutHeaders(obj *object.Object) http.Header {
+	headers := make(http.Header)
+	ct := obj.ContentType
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	headers.Set("Content-Type", ct)
+	headers.Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+	if obj.ContentDisposition != "" {
+		headers.Set("Content-Disposition", obj.ContentDisposition)
+	}
+	if obj.ContentEncoding != "" {
+		headers.Set("Content-Encoding", obj.ContentEncoding)
+	}
+	if obj.CacheControl != "" {
+		headers.Set("Cache-Control", obj.CacheControl)
+	}
+	if obj.ContentLanguage != "" {
+		headers.Set("Content-Language", obj.ContentLanguage)
+	}
+	for k, v := range obj.Metadata {
+		headers.Set("X-Amz-Meta-"+k, v)
+	}
+	return headers
+}
+
+// ── Move ──────────────────────────────────────────────────────────────────────
+
+// handleMoveObject implements POST /buckets/{bucket}/objects/{object:.*}/move-to
+// Body: {"destBucket": "...", "destKey": "..."}; destKey defaults to the
+// source key. Both buckets must belong to the caller's tenant and, in a
+// cluster, live on the same node. The object is streamed to the destination
+// with its metadata and tags, then the source is deleted; if that delete fails
+// the new copy is removed again so the object never ends up in both places.
+// Unless the destination bucket is versioned, an existing destination object
+// is not overwritten: that undo could not bring it back.
+func (s *Server) handleMoveObject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectKey := vars["object"]
+
+	// Cluster routing: proxy to the node that owns the source bucket if not local
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return
+	}
+
+	user, exists := auth.GetUserFromContext(r.Context())
+	if !exists {
+		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !s.requireCapability(w, r, auth.CapObjectUpload, "You do not have permission to upload objects") {
+		return
+	}
+	if !s.requireCapability(w, r, auth.CapObjectDelete, "You do not have permission to delete objects") {
+		return
+	}
+
+	var req struct {
+		DestBucket string `json:"destBucket"`
+		DestKey    string `json:"destKey"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.DestBucket) == "" {
+		s.writeError(w, "Invalid request: destBucket is required", http.StatusBadRequest)
+		return
+	}
+	req.DestBucket = strings.TrimSpace(req.DestBucket)
+	req.DestKey = strings.TrimSpace(req.DestKey)
+	if req.DestKey == "" {
+		req.DestKey = objectKey
+	}
+	if req.DestBucket == bucketName && req.DestKey == objectKey {
+		s.writeError(w, "Destination is the same as the source", http.StatusBadRequest)
+		return
+	}
+
+	tenantID := s.resolveTenantID(r)
+	if _, err := s.bucketManager.GetBucketInfo(r.Context(), tenantID, bucketName); err != nil {
+		s.writeError(w, "Bucket not found", http.StatusNotFound)
+		return
+	}
+	destBucket, err := s.bucketManager.GetBucketInfo(r.Context(), tenantID, req.DestBucket)
+	if err != nil {
+		s.writeError(w, "Destination bucket not found", http.StatusNotFound)
+		return
+	}
+	// The source was routed here; a destination owned by another node
+	// cannot be written from this one.
+	if s.clusterRouter != nil && s.clusterManager != nil {
+		if node, isLocal, routeErr := s.clusterRouter.RouteRequest(r.Context(), req.DestBucket); routeErr == nil && !isLocal && node != nil {
+			s.writeError(w, "Cannot move: the destination bucket is on another cluster node", http.StatusConflict)
+			return
+		}
+	}
+	srcPath := buildBucketPath(tenantID, bucketName)
+	destPath := buildBucketPath(tenantID, req.DestBucket)
+
+	if destBucket.Versioning == nil || destBucket.Versioning.Status != "Enabled" {
+		if _, err := s.objectManager.GetObjectMetadata(r.Context(), destPath, req.DestKey); err == nil {
+			s.writeError(w, "Cannot move: the destination object already exists and the destination bucket is not versioned", http.StatusConflict)
+			return
+		} else if err != object.ErrObjectNotFound {
+			s.writeError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	srcObj, reader, err := s.objectManager.GetObject(r.Context(), srcPath, objectKey)
 	if err != nil {
 		if err == object.ErrObjectNotFound {
 			s.writeError(w, "Object not found", http.StatusNotFound)
@@ -74,55 +235,148 @@ func (s *Server) handleRenameObject(w http.ResponseWriter, r *http.Request) {
 	}
 	defer reader.Close()
 
-	// 2. Block rename if the object is protected
+	// The source is deleted without a governance bypass, so refuse up front
+	// rather than leave a copy behind when the delete is rejected.
 	if srcObj.LegalHold != nil && srcObj.LegalHold.Status == "ON" {
-		s.writeError(w, "Cannot rename: object has an active Legal Hold", http.StatusForbidden)
+		s.writeError(w, "Cannot move: object has an active Legal Hold", http.StatusForbidden)
 		return
 	}
-	if srcObj.Retention != nil && srcObj.Retention.Mode == "COMPLIANCE" && srcObj.Retention.RetainUntilDate.After(time.Now()) {
-		s.writeError(w, "Cannot rename: object is under COMPLIANCE retention that has not expired", http.StatusForbidden)
+	if srcObj.Retention != nil && srcObj.Retention.RetainUntilDate.After(time.Now()) {
+		s.writeError(w, fmt.Sprintf("Cannot move: object is under %s retention that has not expired", srcObj.Retention.Mode), http.StatusForbidden)
 		return
 	}
 
-	// 3. Reconstruct the original HTTP headers so PutObject preserves all metadata
-	headers := make(http.Header)
-	ct := srcObj.ContentType
-	if ct == "" {
-		ct = "application/octet-stream"
+	destObj, err := s.objectManager.PutObject(r.Context(), destPath, req.DestKey, reader, objectPutHeaders(srcObj))
+	if err != nil {
+		var retErr *object.RetentionError
+		switch {
+		case err == object.ErrBucketNotFound:
+			s.writeError(w, "Destination bucket not found", http.StatusNotFound)
+		case err == object.ErrObjectExists:
+			s.writeError(w, "The destination bucket does not allow overwriting existing objects", http.StatusConflict)
+		case errors.Is(err, object.ErrBucketQuotaExceeded), strings.Contains(err.Error(), "quota exceeded"):
+			s.writeError(w, err.Error(), http.StatusForbidden)
+		case err == object.ErrObjectUnderLegalHold, errors.As(err, &retErr), errors.Is(err, object.ErrReservedKeyPrefix):
+			s.writeError(w, err.Error(), http.StatusForbidden)
+		case uploadScanStatus(err) != 0:
+			s.writeError(w, err.Error(), uploadScanStatus(err))
+		default:
+			s.writeError(w, fmt.Sprintf("Failed to write object to destination: %v", err), http.StatusInternalServerError)
+		}
+		return
 	}
-	headers.Set("Content-Type", ct)
-	headers.Set("Content-Length", strconv.FormatInt(srcObj.Size, 10))
-	if srcObj.ContentDisposition != "" {
-		headers.Set("Content-Disposition", srcObj.ContentDisposition)
+
+	// Tags are best-effort, as for rename
+	if tags, tagErr := s.objectManager.GetObjectTagging(r.Context(), srcPath, objectKey); tagErr == nil && tags != nil && len(tags.Tags) > 0 {
+		_ = s.objectManager.SetObjectTagging(r.Context(), destPath, req.DestKey, tags)
 	}
-	if srcObj.ContentEncoding != "" {
-		headers.Set("Content-Encoding", srcObj.ContentEncoding)
+
+	if _, err = s.objectManager.DeleteObject(r.Context(), srcPath, objectKey, false); err != nil {
+		var undoErr error
+		if destObj.VersionID != "" {
+			_, undoErr = s.objectManager.DeleteObject(r.Context(), destPath, req.DestKey, false, destObj.VersionID)
+		} else {
+			_, undoErr = s.objectManager.DeleteObject(r.Context(), destPath, req.DestKey, false)
+		}
+		if undoErr != nil {
+			logrus.WithError(undoErr).WithFields(logrus.Fields{
+				"bucket": req.DestBucket,
+				"key":    req.DestKey,
+			}).Error("move: failed to remove destination copy after source delete failed")
+		}
+		s.writeError(w, fmt.Sprintf("Failed to delete source object, move was undone: %v", err), http.StatusInternalServerError)
+		return
 	}
-	if srcObj.CacheControl != "" {
-		headers.Set("Cache-Control", srcObj.CacheControl)
+
+	s.logAuditEvent(r.Context(), &audit.AuditEvent{
+		TenantID:     tenantID,
+		UserID:       user.ID,
+		Username:     user.Username,
+		EventType:    audit.EventTypeObjectUploaded,
+		ResourceType: audit.ResourceTypeObject,
+		ResourceID:   req.DestKey,
+		ResourceName: req.DestKey,
+		Action:       audit.ActionUpdate,
+		Status:       audit.StatusSuccess,
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
+		UserAgent:    r.Header.Get("User-Agent"),
+		Details: map[string]interface{}{
+			"source_bucket": bucketName,
+			"source_key":    objectKey,
+			"dest_bucket":   req.DestBucket,
+			"dest_key":      req.DestKey,
+		},
+	})
+
+	s.writeJSON(w, map[string]string{
+		"bucket": req.DestBucket,
+		"key":    req.DestKey,
+	})
+}
+
+// ── Append ────────────────────────────────────────────────────────────────────
+
+// handleAppendObject implements POST /buckets/{bucket}/objects/{object:.*}/append
+// The request body is added to the end of the object, which is created when
+// it does not exist. The optional ?offset= query parameter must equal the
+// current object size, so a client that lost track of what was written gets
+// 409 instead of duplicating data.
+func (s *Server) handleAppendObject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectKey := vars["object"]
+
+	// Cluster routing: proxy to the node that owns this bucket if not local
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return
 	}
-	if srcObj.ContentLanguage != "" {
-		headers.Set("Content-Language", srcObj.ContentLanguage)
+
+	user, exists := auth.GetUserFromContext(r.Context())
+	if !exists {
+		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
-	for k, v := range srcObj.Metadata {
-		headers.Set("X-Amz-Meta-"+k, v)
+	if !s.requireCapability(w, r, auth.CapObjectUpload, "You do not have permission to upload objects") {
+		return
 	}
 
-	// 4. Write to the new key
-	if _, err = s.objectManager.PutObject(r.Context(), bucketPath, req.NewKey, reader, headers); err != nil {
-		s.writeError(w, fmt.Sprintf("Failed to write object at new key: %v", err), http.StatusInternalServerError)
-		return
+	writeOffset := int64(-1)
+	if q := r.URL.Query().Get("offset"); q != "" {
+		var err error
+		writeOffset, err = strconv.ParseInt(q, 10, 64)
+		if err != nil || writeOffset < 0 {
+			s.writeError(w, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
 	}
 
-	// 5. Copy tags (best-effort; failure does not abort the rename)
-	if tags, tagErr := s.objectManager.GetObjectTagging(r.Context(), bucketPath, objectKey); tagErr == nil && tags != nil && len(tags.Tags) > 0 {
-		_ = s.objectManager.SetObjectTagging(r.Context(), bucketPath, req.NewKey, tags)
+	tenantID := s.resolveTenantID(r)
+	if _, err := s.bucketManager.GetBucketInfo(r.Context(), tenantID, bucketName); err != nil {
+		s.writeError(w, "Bucket not found", http.StatusNotFound)
+		return
 	}
+	bucketPath := buildBucketPath(tenantID, bucketName)
 
-	// 6. Delete the source key
-	if _, err = s.objectManager.DeleteObject(r.Context(), bucketPath, objectKey, false); err != nil {
-		// Data is already at the new key; log but do not return an error to the client
-		logrus.WithError(err).WithField("key", objectKey).Warn("rename: failed to delete source object after successful copy")
+	obj, err := s.objectManager.AppendObject(r.Context(), bucketPath, objectKey, r.Body, r.Header, writeOffset)
+	if err != nil {
+		var retErr *object.RetentionError
+		switch {
+		case err == object.ErrInvalidWriteOffset:
+			s.writeError(w, "offset does not match the current object size", http.StatusConflict)
+		case err == object.ErrAppendNotSupported:
+			s.writeError(w, "Appending is not supported in versioned buckets or for folders", http.StatusBadRequest)
+		case err == object.ErrObjectUnderLegalHold:
+			s.writeError(w, "Cannot append: object has an active Legal Hold", http.StatusForbidden)
+		case errors.As(err, &retErr):
+			s.writeError(w, retErr.Error(), http.StatusForbidden)
+		case err == object.ErrObjectExists:
+			s.writeError(w, "The bucket does not allow overwriting existing objects", http.StatusConflict)
+		case errors.Is(err, object.ErrBucketQuotaExceeded):
+			s.writeError(w, err.Error(), http.StatusForbidden)
+		default:
+			s.writeError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
 	}
 
 	s.logAuditEvent(r.Context(), &audit.AuditEvent{
@@ -131,20 +385,27 @@ func (s *Server) handleRenameObject(w http.ResponseWriter, r *http.Request) {
 		Username:     user.Username,
 		EventType:    audit.EventTypeObjectUploaded,
 		ResourceType: audit.ResourceTypeObject,
-		ResourceID:   req.NewKey,
-		ResourceName: req.NewKey,
+		ResourceID:   objectKey,
+		ResourceName: objectKey,
 		Action:       audit.ActionUpdate,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
-			"bucket":  bucketName,
-			"old_key": objectKey,
-			"new_key": req.NewKey,
+			"bucket": bucketName,
+			"append": true,
+			"size":   obj.Size,
+			"etag":   obj.ETag,
 		},
 	})
 
-	s.writeJSON(w, map[string]string{"newKey": req.NewKey})
+	s.writeJSON(w, ObjectResponse{
+		Key:          obj.Key,
+		Size:         obj.Size,
+		LastModified: obj.LastModified.Format("2006-01-02T15:04:05Z"),
+		ETag:         obj.ETag,
+		ContentType:  obj.ContentType,
+	})
 }
 
 // ── Object Tags ───────────────────────────────────────────────────────────────
@@ -225,7 +486,7 @@ func (s *Server) handleSetObjectTags(w http.ResponseWriter, r *http.Request) {
 		ResourceName: objectKey,
 		Action:       audit.ActionUpdate,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"bucket":    bucketName,
@@ -380,6 +641,11 @@ func (s *Server) handleRestoreObjectVersion(w http.ResponseWriter, r *http.Reque
 	bucketPath := buildBucketPath(tenantID, bucketName)
 
 	if req.IsDeleteMarker {
+		// Removing a delete marker permanently deletes that version, which
+		// MFA Delete guards like any other version delete
+		if !s.checkMFADelete(w, r, tenantID, bucketName, user.ID) {
+			return
+		}
 		// Removing a delete marker exposes the previous real version as the latest
 		if err := s.objectManager.DeleteObjectVersion(r.Context(), bucketPath, objectKey, req.VersionID); err != nil {
 			s.writeError(w, fmt.Sprintf("Failed to remove delete marker: %v", err), http.StatusInternalServerError)
@@ -395,7 +661,7 @@ func (s *Server) handleRestoreObjectVersion(w http.ResponseWriter, r *http.Reque
 			ResourceName: objectKey,
 			Action:       audit.ActionDelete,
 			Status:       audit.StatusSuccess,
-			IPAddress:    getClientIP(r, s.config.TrustedProxies),
+			IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 			UserAgent:    r.Header.Get("User-Agent"),
 			Details: map[string]interface{}{
 				"bucket":         bucketName,
@@ -443,6 +709,14 @@ func (s *Server) handleRestoreObjectVersion(w http.ResponseWriter, r *http.Reque
 	}
 
 	if _, err = s.objectManager.PutObject(r.Context(), bucketPath, objectKey, reader, headers); err != nil {
+		if errors.Is(err, object.ErrReservedKeyPrefix) {
+			s.writeError(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if status := uploadScanStatus(err); status != 0 {
+			s.writeError(w, err.Error(), status)
+			return
+		}
 		s.writeError(w, fmt.Sprintf("Failed to restore version: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -457,7 +731,7 @@ func (s *Server) handleRestoreObjectVersion(w http.ResponseWriter, r *http.Reque
 		ResourceName: objectKey,
 		Action:       audit.ActionUpdate,
 		Status:       audit.StatusSuccess,
-		IPAddress:    getClientIP(r, s.config.TrustedProxies),
+		IPAddress:    middleware.ClientIP(r, s.config.TrustedProxies),
 		UserAgent:    r.Header.Get("User-Agent"),
 		Details: map[string]interface{}{
 			"bucket":           bucketName,
@@ -471,6 +745,18 @@ func (s *Server) handleRestoreObjectVersion(w http.ResponseWriter, r *http.Reque
 
 // ── Helpers ───────────────────────────────────────────────────────────────────
 
+// archivedReadDenied reports whether the console must refuse to read obj: it
+// is archived and not restored while storage.cold_reads isn't "allow". The
+// console can't hold a download open for a thaw, so "wait" refuses here too
+// and the object is restored through RestoreObject first.
+func (s *Server) archivedReadDenied(obj *object.Object) bool {
+	mode := s.config.Storage.ColdReads
+	return mode != "" && mode != "allow" && obj.NeedsRestore(time.Now())
+}
+
+// archivedReadMessage is the console error for an object archivedReadDenied refuses.
+const archivedReadMessage = "The object is archived; restore it before reading it"
+
 // resolveTenantID returns the effective tenant ID for the request.
 // Global admins may override it via the tenantId query parameter.
 func (s *Server) resolveTenantID(r *http.Request) string {
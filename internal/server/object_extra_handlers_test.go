@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleAppendObject(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{ID: "iot", Name: "iot", Status: "active"}))
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "iot", "telemetry", ""))
+	user := &auth.User{ID: "device-owner", TenantID: "iot", Roles: []string{auth.RoleAdmin}}
+
+	appendLine := func(query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/buckets/telemetry/objects/2026/device.log/append"+query, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		req = mux.SetURLVars(req, map[string]string{"bucket": "telemetry", "object": "2026/device.log"})
+		rr := httptest.NewRecorder()
+		server.handleAppendObject(rr, req)
+		return rr
+	}
+	size := func(rr *httptest.ResponseRecorder) int64 {
+		var body struct {
+			Data ObjectResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return body.Data.Size
+	}
+
+	rr := appendLine("", "t=20.1\n")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, int64(7), size(rr))
+
+	rr = appendLine("?offset=7", "t=20.4\n")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, int64(14), size(rr))
+
+	assert.Equal(t, http.StatusConflict, appendLine("?offset=7", "t=20.9\n").Code)
+	assert.Equal(t, http.StatusBadRequest, appendLine("?offset=abc", "x").Code)
+}
+
+func TestHandleMoveObject(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{ID: "acme", Name: "acme", Status: "active"}))
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "acme", "inbox", ""))
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "acme", "archive", ""))
+	user := &auth.User{ID: "clerk", TenantID: "acme", Roles: []string{auth.RoleAdmin}}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "text/csv")
+	headers.Set("X-Amz-Meta-Source", "scanner-3")
+	_, err := server.objectManager.PutObject(ctx, "acme/inbox", "2026/report.csv", strings.NewReader("a,b\n1,2\n"), headers)
+	require.NoError(t, err)
+	require.NoError(t, server.objectManager.SetObjectTagging(ctx, "acme/inbox", "2026/report.csv",
+		&object.TagSet{Tags: []object.Tag{{Key: "dept", Value: "finance"}}}))
+
+	move := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/buckets/inbox/objects/"+key+"/move-to", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+		req = mux.SetURLVars(req, map[string]string{"bucket": "inbox", "object": key})
+		rr := httptest.NewRecorder()
+		server.handleMoveObject(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusNotFound, move("2026/report.csv", `{"destBucket":"missing"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, move("2026/report.csv", `{"destBucket":"inbox"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, move("2026/report.csv", `{}`).Code)
+
+	rr := move("2026/report.csv", `{"destBucket":"archive","destKey":"done/report.csv"}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	_, _, err = server.objectManager.GetObject(ctx, "acme/inbox", "2026/report.csv")
+	assert.Equal(t, object.ErrObjectNotFound, err)
+
+	obj, reader, err := server.objectManager.GetObject(ctx, "acme/archive", "done/report.csv")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", string(data))
+	assert.Equal(t, "text/csv", obj.ContentType)
+	assert.Equal(t, "scanner-3", obj.Metadata["source"])
+
+	tags, err := server.objectManager.GetObjectTagging(ctx, "acme/archive", "done/report.csv")
+	require.NoError(t, err)
+	require.Len(t, tags.Tags, 1)
+	assert.Equal(t, "finance", tags.Tags[0].Value)
+
+	assert.Equal(t, http.StatusNotFound, move("2026/report.csv", `{"destBucket":"archive"}`).Code)
+
+	// An existing destination in an unversioned bucket is left alone
+	_, err = server.objectManager.PutObject(ctx, "acme/inbox", "2026/summary.csv", strings.NewReader("new\n"), headers)
+	require.NoError(t, err)
+	_, err = server.objectManager.PutObject(ctx, "acme/archive", "2026/summary.csv", strings.NewReader("old\n"), headers)
+	require.NoError(t, err)
+	rr = move("2026/summary.csv", `{"destBucket":"archive"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+	_, reader, err = server.objectManager.GetObject(ctx, "acme/archive", "2026/summary.csv")
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "old\n", string(data))
+	_, err = server.objectManager.GetObjectMetadata(ctx, "acme/inbox", "2026/summary.csv")
+	assert.NoError(t, err, "source must stay in place")
+}
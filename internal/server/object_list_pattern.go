@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/maxiofs/maxiofs/internal/object"
+)
+
+// maxListPatternLength bounds the pattern query parameter of object listings.
+const maxListPatternLength = 256
+
+// listPatternScanLimit caps how many keys one filtered listing request reads.
+// A pattern that matches little would otherwise walk the whole bucket; at the
+// cap the page is returned with what matched so far and a marker to resume.
+var listPatternScanLimit = 10000
+
+// compileKeyPattern compiles the pattern of a filtered object listing. With
+// patternType "glob" (the default) "*" matches any run of characters,
+// including "/", "?" matches one character and "[...]" a character class;
+// with "regex" the pattern is an RE2 expression. Both are anchored, so they
+// must match the whole key.
+func compileKeyPattern(pattern, patternType string) (*regexp.Regexp, error) {
+	if len(pattern) > maxListPatternLength {
+		return nil, fmt.Errorf("pattern must be at most %d characters", maxListPatternLength)
+	}
+	var expr string
+	switch patternType {
+	case "", "glob":
+		expr = globToRegexp(pattern)
+	case "regex":
+		expr = pattern
+	default:
+		return nil, fmt.Errorf("patternType must be 'glob' or 'regex'")
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+	return re, nil
+}
+
+// globToRegexp translates a key glob into a regular expression.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// listMatchingObjects reads pages through list from marker and keeps the
+// objects whose key matches re, until maxKeys have matched, the listing ends
+// or listPatternScanLimit keys were read. Folders (common prefixes) are kept
+// unfiltered so the listing stays navigable. The result is truncated, with
+// NextMarker set, whenever keys are left to scan.
+func listMatchingObjects(list func(marker string, maxKeys int) (*object.ListObjectsResult, error), re *regexp.Regexp, marker string, maxKeys int) (*object.ListObjectsResult, error) {
+	filtered := &object.ListObjectsResult{MaxKeys: maxKeys, Marker: marker}
+	scanned := 0
+	for {
+		page, err := list(marker, min(maxKeys-len(filtered.Objects), listPatternScanLimit-scanned))
+		if err != nil {
+			return nil, err
+		}
+		filtered.Prefix, filtered.Delimiter = page.Prefix, page.Delimiter
+		for _, obj := range page.Objects {
+			if re.MatchString(obj.Key) {
+				filtered.Objects = append(filtered.Objects, obj)
+			}
+		}
+		filtered.CommonPrefixes = append(filtered.CommonPrefixes, page.CommonPrefixes...)
+		filtered.IsTruncated, filtered.NextMarker = page.IsTruncated, page.NextMarker
+
+		read := len(page.Objects) + len(page.CommonPrefixes)
+		scanned += read
+		if !page.IsTruncated || read == 0 || len(filtered.Objects) >= maxKeys || scanned >= listPatternScanLimit {
+			return filtered, nil
+		}
+		marker = page.NextMarker
+	}
+}
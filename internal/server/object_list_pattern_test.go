@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleListObjects_Pattern(t *testing.T) {
+	server := getSharedServer()
+
+	testCtx := context.Background()
+	tenantID := "test-tenant-pattern"
+	bucketName := "test-bucket-pattern"
+	cleanupTestData(t, tenantID, bucketName)
+
+	require.NoError(t, server.authManager.CreateTenant(testCtx, &auth.Tenant{
+		ID:              tenantID,
+		Name:            "Test Tenant Pattern",
+		Status:          "active",
+		MaxStorageBytes: 1000000000,
+		MaxBuckets:      100,
+		MaxAccessKeys:   10,
+	}))
+	require.NoError(t, server.bucketManager.CreateBucket(testCtx, tenantID, bucketName, ""))
+
+	// 40 keys, every fourth one a .log file
+	var wantLogs []string
+	for i := 0; i < 40; i++ {
+		key := fmt.Sprintf("app/%02d.txt", i)
+		if i%4 == 0 {
+			key = fmt.Sprintf("app/%02d.log", i)
+			wantLogs = append(wantLogs, key)
+		}
+		_, err := server.objectManager.PutObject(testCtx, tenantID+"/"+bucketName, key, bytes.NewReader([]byte("x")), http.Header{})
+		require.NoError(t, err)
+	}
+	for _, key := range []string{"2024-01-backup", "2024-02-backup", "2024-02-restore", "2023-12-backup"} {
+		_, err := server.objectManager.PutObject(testCtx, tenantID+"/"+bucketName, key, bytes.NewReader([]byte("x")), http.Header{})
+		require.NoError(t, err)
+	}
+
+	type listing struct {
+		Data struct {
+			Objects     []ObjectResponse `json:"objects"`
+			IsTruncated bool             `json:"isTruncated"`
+			NextMarker  string           `json:"nextMarker"`
+		} `json:"data"`
+	}
+	list := func(t *testing.T, query string) (int, listing) {
+		t.Helper()
+		req := createAuthenticatedRequest("GET", "/api/v1/buckets/"+bucketName+"/objects?"+query, nil, tenantID, "user-1", false)
+		req = mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+		rr := httptest.NewRecorder()
+		server.handleListObjects(rr, req)
+		var response listing
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr.Code, response
+	}
+	// collect pages through a filtered listing, checking each page's size
+	collect := func(t *testing.T, query string, pageSize int) []string {
+		t.Helper()
+		var keys []string
+		marker := ""
+		for page := 0; page < 100; page++ {
+			code, resp := list(t, fmt.Sprintf("%s&max_keys=%d&marker=%s", query, pageSize, url.QueryEscape(marker)))
+			require.Equal(t, http.StatusOK, code)
+			assert.LessOrEqual(t, len(resp.Data.Objects), pageSize)
+			for _, obj := range resp.Data.Objects {
+				keys = append(keys, obj.Key)
+			}
+			if !resp.Data.IsTruncated {
+				return keys
+			}
+			require.NotEmpty(t, resp.Data.NextMarker)
+			marker = resp.Data.NextMarker
+		}
+		t.Fatal("listing did not end")
+		return nil
+	}
+
+	t.Run("glob keeps only .log files", func(t *testing.T) {
+		code, resp := list(t, "pattern="+url.QueryEscape("*.log"))
+		require.Equal(t, http.StatusOK, code)
+		var keys []string
+		for _, obj := range resp.Data.Objects {
+			keys = append(keys, obj.Key)
+		}
+		assert.Equal(t, wantLogs, keys, "* spans folders")
+		assert.False(t, resp.Data.IsTruncated)
+	})
+
+	t.Run("pagination over the filtered set", func(t *testing.T) {
+		assert.Equal(t, wantLogs, collect(t, "pattern="+url.QueryEscape("*.log"), 3))
+	})
+
+	t.Run("a capped scan returns a marker to resume", func(t *testing.T) {
+		saved := listPatternScanLimit
+		listPatternScanLimit = 7
+		defer func() { listPatternScanLimit = saved }()
+
+		code, first := list(t, "pattern="+url.QueryEscape("*.log")+"&max_keys=100")
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, first.Data.IsTruncated, "the scan stops at the cap")
+		// The first 7 keys are the four dated ones and app/00 to app/02
+		require.Len(t, first.Data.Objects, 1)
+		assert.Equal(t, "app/00.log", first.Data.Objects[0].Key)
+		assert.Equal(t, "app/02.txt", first.Data.NextMarker)
+
+		assert.Equal(t, wantLogs, collect(t, "pattern="+url.QueryEscape("*.log"), 100))
+	})
+
+	t.Run("glob with a character class", func(t *testing.T) {
+		assert.Equal(t, []string{"2024-01-backup", "2024-02-backup"}, collect(t, "pattern="+url.QueryEscape("2024-*-backup"), 10))
+		assert.Equal(t, []string{"2024-02-backup", "2024-02-restore"}, collect(t, "pattern="+url.QueryEscape("202[!3]-02-*"), 10))
+	})
+
+	t.Run("anchored regex", func(t *testing.T) {
+		keys := collect(t, "patternType=regex&pattern="+url.QueryEscape(`20\d\d-\d\d-backup`), 10)
+		assert.Equal(t, []string{"2023-12-backup", "2024-01-backup", "2024-02-backup"}, keys)
+		assert.Empty(t, collect(t, "patternType=regex&pattern=backup", 10), "the regex must match the whole key")
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, query := range []string{
+			"patternType=regex&pattern=" + url.QueryEscape("(unclosed"),
+			"patternType=wildcard&pattern=x",
+			"foldersOnly=true&pattern=x",
+		} {
+			code, _ := list(t, query)
+			assert.Equal(t, http.StatusBadRequest, code, query)
+		}
+	})
+}
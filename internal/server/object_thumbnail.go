@@ -0,0 +1,344 @@
+package server
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // GIF sources for image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/lru"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultThumbnailSize = 256
+	minThumbnailSize     = 16
+	maxThumbnailSize     = 1024
+
+	// maxThumbnailSourceBytes and maxThumbnailSourcePixels bound the work a
+	// single thumbnail request can cause; larger images get no preview.
+	maxThumbnailSourceBytes  = 32 << 20
+	maxThumbnailSourcePixels = 40_000_000
+
+	// defaultThumbnailCacheMB bounds the memory held by cached thumbnails
+	// when memory.thumbnail_cache_mb is unset.
+	defaultThumbnailCacheMB = 64
+)
+
+// errBadThumbnailSource marks images a thumbnail can't be made from.
+var errBadThumbnailSource = errors.New("unsupported image")
+
+// thumbnailSourceTypes are the content types a thumbnail can be made from.
+var thumbnailSourceTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+type thumbnail struct {
+	key         string
+	contentType string
+	data        []byte
+}
+
+// thumbnailCache keeps generated thumbnails in memory, least recently used
+// first out once maxBytes is reached. Entries are keyed by the source ETag,
+// so a changed object never gets a stale preview. Thumbnails are not written
+// to storage: they would sit there unencrypted next to the encrypted objects.
+// A nil cache caches nothing.
+type thumbnailCache struct {
+	mu        sync.Mutex
+	maxBytes  int
+	used      int
+	order     *list.List // front = most recently used
+	entries   map[string]*list.Element
+	hits      int64
+	misses    int64
+	evictions int64
+	shrunk    int64
+}
+
+func newThumbnailCache(maxBytes int) *thumbnailCache {
+	return &thumbnailCache{maxBytes: maxBytes, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Stats implements lru.Shrinkable. The cache is bounded by bytes, not
+// entries, so MaxEntries is 0.
+func (c *thumbnailCache) Stats() lru.Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return lru.Stats{Entries: len(c.entries), Evictions: c.evictions, Shrunk: c.shrunk}
+}
+
+// Shrink implements lru.Shrinkable.
+func (c *thumbnailCache) Shrink(fraction float64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := int(float64(len(c.entries)) * min(fraction, 1))
+	for i := 0; i < n; i++ {
+		c.removeOldest()
+	}
+	c.shrunk += int64(n)
+	return n
+}
+
+func (c *thumbnailCache) removeOldest() {
+	oldest := c.order.Back()
+	evicted := oldest.Value.(*thumbnail)
+	c.order.Remove(oldest)
+	delete(c.entries, evicted.key)
+	c.used -= len(evicted.data)
+}
+
+func thumbnailCacheKey(bucketPath, objectKey, etag string, size int) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d", bucketPath, objectKey, etag, size)
+}
+
+func (c *thumbnailCache) get(key string) (*thumbnail, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*thumbnail), true
+}
+
+func (c *thumbnailCache) put(t *thumbnail) {
+	if c == nil || len(t.data) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[t.key]; ok {
+		c.used -= len(elem.Value.(*thumbnail).data)
+		c.order.Remove(elem)
+	}
+	c.entries[t.key] = c.order.PushFront(t)
+	c.used += len(t.data)
+	for c.used > c.maxBytes {
+		c.removeOldest()
+		c.evictions++
+	}
+}
+
+// handleGetObjectThumbnail returns a downscaled preview of an image object.
+// GET /buckets/{bucket}/objects/{object}/thumbnail[?size=256][&tenantId=...]
+//
+// The longest side of the thumbnail is size pixels (16-1024); smaller images
+// keep their dimensions. PNG sources give a PNG (transparency is kept), JPEG
+// and GIF sources a JPEG. Only the thumbnail is sent, never the full object.
+// Objects that aren't JPEG, PNG or GIF images get 400.
+func (s *Server) handleGetObjectThumbnail(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectKey := vars["object"]
+
+	// Cluster routing: proxy to the node that owns this bucket if not local
+	if s.proxyConsoleRequest(w, r, bucketName) {
+		return
+	}
+
+	if _, exists := auth.GetUserFromContext(r.Context()); !exists {
+		s.writeError(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	size := defaultThumbnailSize
+	if v := r.URL.Query().Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < minThumbnailSize || n > maxThumbnailSize {
+			s.writeError(w, fmt.Sprintf("size must be between %d and %d", minThumbnailSize, maxThumbnailSize), http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+
+	bucketPath := buildBucketPath(s.resolveTenantID(r), bucketName)
+
+	meta, err := s.objectManager.GetObjectMetadata(r.Context(), bucketPath, objectKey)
+	if err != nil {
+		if err == object.ErrObjectNotFound {
+			s.writeError(w, "Object not found", http.StatusNotFound)
+		} else {
+			s.writeError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	contentType := strings.ToLower(strings.TrimSpace(strings.SplitN(meta.ContentType, ";", 2)[0]))
+	if !thumbnailSourceTypes[contentType] {
+		s.writeError(w, "Thumbnails are only available for JPEG, PNG and GIF images", http.StatusBadRequest)
+		return
+	}
+	if meta.Size > maxThumbnailSourceBytes {
+		s.writeError(w, "Image is too large for a thumbnail", http.StatusBadRequest)
+		return
+	}
+	if s.archivedReadDenied(meta) {
+		s.writeError(w, archivedReadMessage, http.StatusForbidden)
+		return
+	}
+
+	// The preview changes exactly when the object does
+	etag := fmt.Sprintf(`"%s-%d"`, strings.Trim(meta.ETag, `"`), size)
+	if consoleNotModified(r, etag, meta.LastModified) {
+		setConsoleCacheHeaders(w, etag, meta.LastModified)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	key := thumbnailCacheKey(bucketPath, objectKey, meta.ETag, size)
+	thumb, ok := s.thumbnails.get(key)
+	if !ok {
+		thumb, err = s.generateThumbnail(r, bucketPath, objectKey, meta.ETag, size)
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{"bucket": bucketPath, "key": objectKey}).Debug("Thumbnail generation failed")
+			switch {
+			case errors.Is(err, errBadThumbnailSource):
+				s.writeError(w, err.Error(), http.StatusBadRequest)
+			case err == object.ErrObjectNotFound:
+				s.writeError(w, "Object not found", http.StatusNotFound)
+			default:
+				s.writeError(w, "Failed to generate thumbnail: "+err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		thumb.key = key
+		s.thumbnails.put(thumb)
+	}
+
+	setConsoleCacheHeaders(w, etag, meta.LastModified)
+	w.Header().Set("Content-Type", thumb.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(thumb.data)))
+	w.Write(thumb.data) //nolint:errcheck
+}
+
+// generateThumbnail decodes the object and scales it down. The object read
+// is pinned to etag: an overwrite in between fails instead of caching the
+// new content under the old ETag.
+func (s *Server) generateThumbnail(r *http.Request, bucketPath, objectKey, etag string, size int) (*thumbnail, error) {
+	obj, reader, err := s.objectManager.GetObject(r.Context(), bucketPath, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	if obj.ETag != etag {
+		return nil, fmt.Errorf("object changed while generating its thumbnail")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxThumbnailSourceBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxThumbnailSourceBytes {
+		return nil, fmt.Errorf("%w: image is too large", errBadThumbnailSource)
+	}
+
+	// Check the dimensions before decoding so a small file can't claim a
+	// huge canvas and exhaust memory
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadThumbnailSource, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxThumbnailSourcePixels {
+		return nil, fmt.Errorf("%w: image dimensions %dx%d are not supported", errBadThumbnailSource, cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadThumbnailSource, err)
+	}
+
+	scaled := scaleImage(src, size)
+	var out bytes.Buffer
+	thumb := &thumbnail{}
+	if format == "png" {
+		thumb.contentType = "image/png"
+		err = png.Encode(&out, scaled)
+	} else {
+		thumb.contentType = "image/jpeg"
+		err = jpeg.Encode(&out, scaled, &jpeg.Options{Quality: 80})
+	}
+	if err != nil {
+		return nil, err
+	}
+	thumb.data = out.Bytes()
+	return thumb, nil
+}
+
+// scaleImage shrinks src so its longest side is at most maxSide, averaging
+// every source pixel into the destination pixel it falls on (a box filter,
+// which doesn't alias the way nearest-neighbour sampling does).
+func scaleImage(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := sw, sh
+	if sw > maxSide || sh > maxSide {
+		if sw >= sh {
+			dw, dh = maxSide, max(1, sh*maxSide/sw)
+		} else {
+			dw, dh = max(1, sw*maxSide/sh), maxSide
+		}
+	}
+
+	// Premultiplied RGBA averages correctly across transparent pixels;
+	// draw.Draw has fast paths from the decoders' native formats
+	rgba := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	if dw == sw && dh == sh {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	sums := make([]uint64, dw*4)
+	counts := make([]uint64, dw)
+	for dy := 0; dy < dh; dy++ {
+		for i := range sums {
+			sums[i] = 0
+		}
+		for i := range counts {
+			counts[i] = 0
+		}
+		for sy := dy * sh / dh; sy < (dy+1)*sh/dh; sy++ {
+			row := rgba.Pix[sy*rgba.Stride:]
+			for sx := 0; sx < sw; sx++ {
+				dx := sx * dw / sw
+				p := row[sx*4 : sx*4+4]
+				sums[dx*4] += uint64(p[0])
+				sums[dx*4+1] += uint64(p[1])
+				sums[dx*4+2] += uint64(p[2])
+				sums[dx*4+3] += uint64(p[3])
+				counts[dx]++
+			}
+		}
+		out := dst.Pix[dy*dst.Stride:]
+		for dx := 0; dx < dw; dx++ {
+			n := counts[dx]
+			if n == 0 {
+				continue
+			}
+			for c := 0; c < 4; c++ {
+				out[dx*4+c] = uint8(sums[dx*4+c] / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func thumbnailRequest(user *auth.User, bucketName, key, query string) *http.Request {
+	req := httptest.NewRequest("GET", "/api/v1/buckets/"+bucketName+"/objects/"+key+"/thumbnail"+query, nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	return mux.SetURLVars(req, map[string]string{"bucket": bucketName, "object": key})
+}
+
+func testPNG(t *testing.T, w, h int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestHandleGetObjectThumbnail(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	server.thumbnails = newThumbnailCache(defaultThumbnailCacheMB << 20)
+	ctx := context.Background()
+
+	admin, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+	bucketName := "photos"
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", bucketName, admin.ID))
+
+	put := func(key, contentType string, data []byte) {
+		headers := http.Header{}
+		headers.Set("Content-Type", contentType)
+		_, err := server.objectManager.PutObject(ctx, bucketName, key, bytes.NewReader(data), headers)
+		require.NoError(t, err)
+	}
+	source := testPNG(t, 600, 300, color.NRGBA{R: 200, G: 40, B: 40, A: 255})
+	put("cat.png", "image/png", source)
+
+	rr := httptest.NewRecorder()
+	server.handleGetObjectThumbnail(rr, thumbnailRequest(admin, bucketName, "cat.png", "?size=128"))
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+	assert.Less(t, rr.Body.Len(), len(source), "only the thumbnail is sent")
+
+	thumb, err := png.Decode(bytes.NewReader(rr.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 128, 64), thumb.Bounds(), "the aspect ratio is kept")
+	r, g, b, _ := thumb.At(64, 32).RGBA()
+	assert.Equal(t, []uint32{200, 40, 40}, []uint32{r >> 8, g >> 8, b >> 8})
+	assert.Equal(t, int64(0), server.thumbnails.hits)
+
+	// A repeat request is served from the cache
+	rr = httptest.NewRecorder()
+	server.handleGetObjectThumbnail(rr, thumbnailRequest(admin, bucketName, "cat.png", "?size=128"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(1), server.thumbnails.hits)
+	etag := rr.Header().Get("ETag")
+
+	// The browser revalidates with the thumbnail's ETag
+	req := thumbnailRequest(admin, bucketName, "cat.png", "?size=128")
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	server.handleGetObjectThumbnail(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	// Overwriting the object invalidates its thumbnail
+	put("cat.png", "image/png", testPNG(t, 100, 200, color.NRGBA{B: 255, A: 255}))
+	rr = httptest.NewRecorder()
+	server.handleGetObjectThumbnail(rr, thumbnailRequest(admin, bucketName, "cat.png", "?size=128"))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(1), server.thumbnails.hits)
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+	thumb, err = png.Decode(bytes.NewReader(rr.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 64, 128), thumb.Bounds())
+}
+
+func TestHandleGetObjectThumbnail_Rejects(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	admin, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+	bucketName := "documents"
+	require.NoError(t, server.bucketManager.CreateBucket(ctx, "", bucketName, admin.ID))
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "text/plain")
+	_, err = server.objectManager.PutObject(ctx, bucketName, "notes.txt", bytes.NewReader([]byte("hello")), headers)
+	require.NoError(t, err)
+	headers.Set("Content-Type", "image/png")
+	_, err = server.objectManager.PutObject(ctx, bucketName, "broken.png", bytes.NewReader([]byte("not a png")), headers)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		key, query string
+		status     int
+	}{
+		{"notes.txt", "", http.StatusBadRequest},
+		{"broken.png", "", http.StatusBadRequest},
+		{"broken.png", "?size=5000", http.StatusBadRequest},
+		{"missing.png", "", http.StatusNotFound},
+	} {
+		rr := httptest.NewRecorder()
+		server.handleGetObjectThumbnail(rr, thumbnailRequest(admin, bucketName, tc.key, tc.query))
+		assert.Equal(t, tc.status, rr.Code, "%s%s", tc.key, tc.query)
+	}
+}
+
+func TestScaleImage(t *testing.T) {
+	// Alternating black and white columns average to grey
+	src := image.NewGray(image.Rect(0, 0, 400, 100))
+	for x := 0; x < 400; x += 2 {
+		for y := 0; y < 100; y++ {
+			src.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	dst := scaleImage(src, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 25), dst.Bounds())
+	r, _, _, _ := dst.At(50, 10).RGBA()
+	assert.InDelta(t, 127, r>>8, 1)
+
+	// Images already within the size keep their dimensions
+	assert.Equal(t, image.Rect(0, 0, 400, 100), scaleImage(src, 1024).Bounds())
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setPasswordPolicy turns every password rule off, then applies rules.
+func setPasswordPolicy(t *testing.T, server *Server, rules map[string]string) {
+	base := map[string]string{
+		"security.password_min_length":        "1",
+		"security.password_require_uppercase": "false",
+		"security.password_require_lowercase": "false",
+		"security.password_require_numbers":   "false",
+		"security.password_require_special":   "false",
+		"security.password_block_common":      "false",
+	}
+	for key, value := range rules {
+		base[key] = value
+	}
+	require.NoError(t, server.settingsManager.BulkUpdate(base))
+}
+
+func TestPasswordPolicyRules(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	admin, err := server.authManager.ValidateJWT(context.Background(), getAdminToken(t, server))
+	require.NoError(t, err)
+
+	createUser := func(username, password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"username": username,
+			"password": password,
+			"roles":    []string{"user"},
+		})
+		req := httptest.NewRequest("POST", "/api/v1/users", bytes.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), "user", admin))
+		rr := httptest.NewRecorder()
+		server.handleCreateUser(rr, req)
+		return rr
+	}
+
+	cases := []struct {
+		name    string
+		rules   map[string]string
+		weak    string
+		strong  string
+		message string
+	}{
+		{"min length", map[string]string{"security.password_min_length": "12"}, "short-pass", "long-enough-pass", "at least 12 characters"},
+		{"uppercase", map[string]string{"security.password_require_uppercase": "true"}, "no-upper-case", "Has-Upper-Case", "uppercase letter"},
+		{"lowercase", map[string]string{"security.password_require_lowercase": "true"}, "NO-LOWER-CASE", "HAS-lower-CASE", "lowercase letter"},
+		{"digit", map[string]string{"security.password_require_numbers": "true"}, "no-digits-here", "digits-4-here", "one number"},
+		{"symbol", map[string]string{"security.password_require_special": "true"}, "NoSymbolsHere", "Symbols#Here", "special character"},
+		{"common", map[string]string{"security.password_block_common": "true"}, "Password123", "violet-harbor-kettle", "too common"},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setPasswordPolicy(t, server, tc.rules)
+
+			rr := createUser(fmt.Sprintf("weak-%d", i), tc.weak)
+			require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+			var resp APIResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+			assert.Contains(t, resp.Error, tc.message)
+
+			rr = createUser(fmt.Sprintf("strong-%d", i), tc.strong)
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		})
+	}
+
+	t.Run("admin-set password", func(t *testing.T) {
+		setPasswordPolicy(t, server, map[string]string{"security.password_block_common": "true"})
+		require.Equal(t, http.StatusOK, createUser("reset-target", "first-Password-9").Code)
+		target, err := server.authManager.GetUser(context.Background(), "reset-target")
+		require.NoError(t, err)
+
+		setPassword := func(password string) *httptest.ResponseRecorder {
+			body, _ := json.Marshal(map[string]string{"newPassword": password})
+			req := httptest.NewRequest("PUT", "/api/v1/users/"+target.ID+"/password", bytes.NewReader(body))
+			req = req.WithContext(context.WithValue(req.Context(), "user", admin))
+			req = mux.SetURLVars(req, map[string]string{"user": target.ID})
+			rr := httptest.NewRecorder()
+			server.handleChangePassword(rr, req)
+			return rr
+		}
+		rr := setPassword("qwerty123")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "too common")
+		assert.Equal(t, http.StatusOK, setPassword("second-Password-9").Code)
+	})
+}
+
+func TestLoginRotatesExpiredPassword(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	setPasswordPolicy(t, server, map[string]string{
+		"security.password_min_length":   "10",
+		"security.password_max_age_days": "30",
+	})
+
+	ctx := context.Background()
+	user := &auth.User{
+		ID:        "expiring-user",
+		Username:  "expiring-user",
+		Password:  "original-pass",
+		Status:    "active",
+		Roles:     []string{"admin"},
+		CreatedAt: time.Now().Unix(),
+	}
+	require.NoError(t, server.authManager.CreateUser(ctx, user))
+
+	attempts := 0
+	login := func(password, newPassword string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{
+			"username":     user.Username,
+			"password":     password,
+			"new_password": newPassword,
+		})
+		req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewReader(body))
+		// One address per attempt, clear of the per-IP login rate limit
+		attempts++
+		req.RemoteAddr = fmt.Sprintf("192.0.2.%d:1234", attempts)
+		rr := httptest.NewRecorder()
+		server.handleLogin(rr, req)
+		return rr
+	}
+
+	require.Equal(t, http.StatusOK, login("original-pass", "").Code, "a fresh password logs in")
+
+	db := server.authManager.GetDB().(*sql.DB)
+	_, err := db.Exec(`UPDATE users SET password_changed_at = ? WHERE id = ?`, time.Now().AddDate(0, 0, -31).Unix(), user.ID)
+	require.NoError(t, err)
+
+	rr := login("original-pass", "")
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	var expired map[string]interface{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&expired))
+	assert.Equal(t, true, expired["password_expired"])
+	assert.Nil(t, expired["token"])
+
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-password", "replacement-pass").Code)
+	assert.Equal(t, http.StatusBadRequest, login("original-pass", "short").Code, "the new password must pass the policy")
+	assert.Equal(t, http.StatusBadRequest, login("original-pass", "original-pass").Code)
+
+	rr = login("original-pass", "replacement-pass")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp LoginResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.NotEmpty(t, resp.Token)
+
+	assert.Equal(t, http.StatusOK, login("replacement-pass", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, login("original-pass", "").Code)
+}
+
+func TestLoginExpiredPasswordWith2FA(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	setPasswordPolicy(t, server, map[string]string{
+		"security.password_min_length":   "10",
+		"security.password_max_age_days": "30",
+	})
+
+	ctx := context.Background()
+	user := &auth.User{
+		ID:        "expiring-2fa-user",
+		Username:  "expiring-2fa-user",
+		Password:  "original-pass",
+		Status:    "active",
+		Roles:     []string{"user"},
+		CreatedAt: time.Now().Unix(),
+	}
+	require.NoError(t, server.authManager.CreateUser(ctx, user))
+	setup, err := server.authManager.Setup2FA(ctx, user.ID)
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(setup.Secret, time.Now().Add(-30*time.Second))
+	require.NoError(t, err)
+	_, err = server.authManager.Enable2FA(ctx, user.ID, code, setup.Secret)
+	require.NoError(t, err)
+
+	db := server.authManager.GetDB().(*sql.DB)
+	_, err = db.Exec(`UPDATE users SET password_changed_at = ? WHERE id = ?`, time.Now().AddDate(0, 0, -31).Unix(), user.ID)
+	require.NoError(t, err)
+
+	attempts := 0
+	login := func(newPassword, totpCode string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]string{
+			"username":     user.Username,
+			"password":     "original-pass",
+			"new_password": newPassword,
+			"totp_code":    totpCode,
+		})
+		req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewReader(body))
+		attempts++
+		req.RemoteAddr = fmt.Sprintf("192.0.2.%d:1234", attempts)
+		rr := httptest.NewRecorder()
+		server.handleLogin(rr, req)
+		var resp map[string]interface{}
+		_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+		return rr, resp
+	}
+	stored, err := server.authManager.GetUser(ctx, user.ID)
+	require.NoError(t, err)
+	passwordUnchanged := func() {
+		t.Helper()
+		current, err := server.authManager.GetUser(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, stored.Password, current.Password, "the expired password is still in place")
+	}
+
+	rr, resp := login("", "")
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, true, resp["password_expired"])
+	assert.Equal(t, true, resp["requires_2fa"], "the client is told to send the TOTP code along")
+
+	// The password alone can't replace the expired one
+	rr, resp = login("replacement-pass", "")
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, true, resp["requires_2fa"])
+	assert.Nil(t, resp["token"])
+	passwordUnchanged()
+
+	rr, _ = login("replacement-pass", "000000")
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	passwordUnchanged()
+	var failedAttempts int
+	require.NoError(t, db.QueryRow(`SELECT failed_login_attempts FROM users WHERE id = ?`, user.ID).Scan(&failedAttempts))
+	assert.Equal(t, 1, failedAttempts, "a wrong code counts as a failed login")
+
+	code, err = totp.GenerateCode(setup.Secret, time.Now())
+	require.NoError(t, err)
+	rr, resp = login("replacement-pass", code)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.NotEmpty(t, resp["token"], "the verified code completes the login")
+	_, err = server.authManager.ValidateConsoleCredentials(ctx, user.Username, "replacement-pass")
+	assert.NoError(t, err)
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"encoding/xml"
 	"errors"
@@ -12,6 +13,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"runtime"
 	"strings"
@@ -28,6 +30,7 @@ import (
 	"github.com/maxiofs/maxiofs/internal/bucket"
 	"github.com/maxiofs/maxiofs/internal/cluster"
 	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/eventlog"
 	idpkg "github.com/maxiofs/maxiofs/internal/idp"
 	_ "github.com/maxiofs/maxiofs/internal/idp/ldap"  // Register LDAP provider
 	_ "github.com/maxiofs/maxiofs/internal/idp/oauth" // Register OAuth provider
@@ -71,7 +74,9 @@ type Server struct {
 	clusterRouter           *cluster.Router
 	bucketAggregator        *cluster.BucketAggregator
 	quotaAggregator         *cluster.QuotaAggregator
-	apiRateLimiter          *auth.APIRateLimiter // per-user S3 API rate limiter
+	apiRateLimiter          *auth.APIRateLimiter           // per-user S3 API rate limiter
+	anonRateLimiter         *auth.APIRateLimiter           // per-IP limiter for unauthenticated S3 requests
+	concurrencyLimiter      *middleware.ConcurrencyLimiter // per-operation S3 in-flight caps
 	tenantSyncMgr           *cluster.TenantSyncManager
 	userSyncMgr             *cluster.UserSyncManager
 	accessKeySyncMgr        *cluster.AccessKeySyncManager
@@ -89,9 +94,14 @@ type Server struct {
 	quotaAlerts             *quotaAlertTracker
 	bucketQuotaAlerts       *bucketQuotaAlertTracker
 	systemMetrics           *metrics.SystemMetricsTracker
+	usageTracker            *metrics.UsageTracker  // per-tenant usage series for billing
+	s3ListenerStats         *metrics.ListenerStats // connections, requests and bytes of httpServer
+	consoleListenerStats    *metrics.ListenerStats // same for consoleServer
 	lifecycleWorker         *lifecycle.Worker
 	inventoryManager        *inventory.Manager
 	inventoryWorker         *inventory.Worker
+	eventLog                *eventlog.Log   // per-bucket object event log for polling clients
+	thumbnails              *thumbnailCache // console image previews, bounded in memory
 	accessLogger            *BucketAccessLogger
 	idpManager              *idpkg.Manager
 	startTime               time.Time       // Server start time for uptime calculation
@@ -106,12 +116,26 @@ type Server struct {
 
 // New creates a new MaxIOFS server
 func New(cfg *config.Config) (*Server, error) {
-	// Initialize storage backend
-	storageBackend, err := storage.NewBackend(cfg.Storage)
-	if err != nil {
+	// Reject an unknown storage backend before opening any store
+	if err := storage.ValidateBackend(cfg.Storage.Backend); err != nil {
 		return nil, fmt.Errorf("failed to create storage backend: %w", err)
 	}
 
+	apiTLSConfig, err := apiClientCertTLSConfig(cfg.Auth.TrustedNetworks.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	// Load each listener's certificate up front, so a mismatched pair fails
+	// startup instead of the listener
+	apiTLSConfig, err = listenerTLSConfig("S3 API", cfg.S3ListenerTLS(), apiTLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	consoleTLSConfig, err := listenerTLSConfig("console", cfg.ConsoleListenerTLS(), nil)
+	if err != nil {
+		return nil, err
+	}
+
 	// Migrate Pebble v1 → Pebble v2 if the on-disk format is from an older release
 	if err := metadata.MigrateFromPebbleV1IfNeeded(cfg.DataDir, logrus.StandardLogger()); err != nil {
 		return nil, fmt.Errorf("pebble v1→v2 migration failed: %w", err)
@@ -119,16 +143,42 @@ func New(cfg *config.Config) (*Server, error) {
 
 	// Initialize metadata store (Pebble v2)
 	metadataStore, err := metadata.NewPebbleStore(metadata.PebbleOptions{
-		DataDir:     cfg.DataDir,
-		Logger:      logrus.StandardLogger(),
-		CacheSizeMB: cfg.Storage.MetadataCacheSizeMB,
+		DataDir:           cfg.DataDir,
+		Logger:            logrus.StandardLogger(),
+		CacheSizeMB:       cfg.Storage.MetadataCacheSizeMB,
+		TenantBucketNames: !cfg.GlobalBucketNamespace,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metadata store: %w", err)
 	}
 
+	// Initialize storage backend. The s3 backend keeps object metadata in
+	// the metadata store, so it is created first.
+	storageBackend, err := storage.NewBackendWithMetadataStore(cfg.Storage, metadataStore)
+	if err != nil {
+		metadataStore.Close()
+		return nil, fmt.Errorf("failed to create storage backend: %w", err)
+	}
+
+	// Move a flat object tree into shard directories before serving requests
+	if fsBackend, ok := storageBackend.(*storage.FilesystemBackend); ok && fsBackend.NeedsLayoutMigration() {
+		logrus.WithField("shard_depth", cfg.Storage.ShardDepth).Info("Migrating object files to the sharded storage layout")
+		moved, err := fsBackend.MigrateFlatLayout(context.Background())
+		if err != nil {
+			metadataStore.Close()
+			return nil, fmt.Errorf("storage layout migration failed after %d files: %w", moved, err)
+		}
+		logrus.WithField("moved", moved).Info("Storage layout migration completed")
+	}
+
 	// Initialize managers
 	bucketManager := bucket.NewManager(storageBackend, metadataStore)
+	if bm, ok := bucketManager.(interface {
+		SetBucketInfoCache(ttl time.Duration, consistency string, maxEntries int)
+	}); ok {
+		bm.SetBucketInfoCache(time.Duration(cfg.Storage.BucketCacheTTLSeconds)*time.Second, cfg.Storage.BucketCacheConsistency,
+			cfg.Memory.BucketCacheMaxEntries)
+	}
 
 	// Auth manager first: it owns the SQLite DB, which the KEK bootstrap
 	// (below) needs before the object manager can be created.
@@ -147,7 +197,17 @@ func New(cfg *config.Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to bootstrap encryption KEK: %w", err)
 	}
 
-	objectManager := object.NewManager(storageBackend, metadataStore, cfg.Storage, object.WithKEKProvider(kekStore))
+	// Opaque key layout: move existing object files off their key-named
+	// paths before serving requests
+	keyPaths, moved, err := object.PrepareKeyLayout(context.Background(), storageBackend, metadataStore, cfg.Storage.ObjectKeyLayout)
+	if err != nil {
+		return nil, fmt.Errorf("object key layout setup failed after %d files: %w", moved, err)
+	}
+	if moved > 0 {
+		logrus.WithField("moved", moved).Info("Moved object files to the opaque key layout")
+	}
+
+	objectManager := object.NewManager(storageBackend, metadataStore, cfg.Storage, object.WithKEKProvider(kekStore), object.WithTenantKeyProvider(kekStore), object.WithKeyPaths(keyPaths))
 
 	// Connect object manager to bucket manager for metrics updates
 	if om, ok := objectManager.(interface {
@@ -166,6 +226,7 @@ func New(cfg *config.Config) (*Server, error) {
 
 	// Initialize logging manager
 	loggingManager := logging.NewManager(logrus.StandardLogger())
+	loggingManager.SetFormatOverride(cfg.LogFormat)
 	loggingManager.SetSettingsManager(settingsManager)
 
 	// Initialize logging target store (database-backed multiple targets)
@@ -181,6 +242,20 @@ func New(cfg *config.Config) (*Server, error) {
 			return nil, fmt.Errorf("failed to create audit store: %w", err)
 		}
 		auditManager = audit.NewManager(auditStore, logrus.StandardLogger())
+
+		// External sinks are best-effort: one that cannot be set up is
+		// logged and skipped, the local store keeps recording.
+		var sinks []audit.Sink
+		for _, sinkCfg := range cfg.Audit.Sinks {
+			sink, err := audit.NewSink(sinkCfg)
+			if err != nil {
+				logrus.WithError(err).WithField("type", sinkCfg.Type).Error("Failed to initialize audit sink")
+				continue
+			}
+			logrus.WithField("sink", sink.Name()).Info("Audit sink enabled")
+			sinks = append(sinks, sink)
+		}
+		auditManager.SetSinks(sinks)
 	}
 
 	// Connect audit manager to auth manager
@@ -208,6 +283,11 @@ func New(cfg *config.Config) (*Server, error) {
 		bm.SetAuditManager(auditManager)
 	}
 
+	// Connect audit manager to object manager for the object lock history
+	if om, ok := objectManager.(interface{ SetAuditManager(*audit.Manager) }); ok && auditManager != nil {
+		om.SetAuditManager(auditManager)
+	}
+
 	// Connect object manager to auth manager for tenant quota updates
 	if om, ok := objectManager.(interface {
 		SetAuthManager(interface {
@@ -289,10 +369,34 @@ func New(cfg *config.Config) (*Server, error) {
 
 	// Initialize lifecycle worker
 	lifecycleWorker := lifecycle.NewWorker(bucketManager, objectManager, metadataStore)
+	lifecycleWorker.SetOptions(lifecycle.Options{
+		Workers:  cfg.Lifecycle.Workers,
+		ScanRate: cfg.Lifecycle.ScanRate,
+	})
+	if mm, ok := metricsManager.(interface {
+		SetLifecycleStatsProvider(metrics.LifecycleStatsProvider)
+	}); ok {
+		mm.SetLifecycleStatsProvider(func() map[string]metrics.LifecycleBucketStats {
+			runs := lifecycleWorker.BucketStats()
+			stats := make(map[string]metrics.LifecycleBucketStats, len(runs))
+			for bucketPath, run := range runs {
+				stats[bucketPath] = metrics.LifecycleBucketStats{
+					LastRun:        run.LastRun,
+					ObjectsExpired: run.ObjectsExpired,
+					ExpiredTotal:   run.ExpiredTotal,
+				}
+			}
+			return stats
+		})
+	}
+
+	// Initialize the bucket event log (kept in the metadata store)
+	eventLog := eventlog.New(metadataStore)
+	eventLog.SetSettingsManager(settingsManager)
 
 	// Initialize inventory manager and worker
 	inventoryManager := inventory.NewManager(db)
-	inventoryWorker := inventory.NewWorker(inventoryManager, bucketManager, metadataStore, storageBackend)
+	inventoryWorker := inventory.NewWorker(inventoryManager, bucketManager, metadataStore, objectManager)
 
 	// Initialize IDP manager
 	idpStore := idpkg.NewStore(db)
@@ -352,6 +456,7 @@ func New(cfg *config.Config) (*Server, error) {
 
 	// Set storage backend and ACL manager for cluster operations (migrations)
 	clusterManager.SetStorage(storageBackend)
+	clusterManager.SetKeyPaths(keyPaths)
 
 	// Get ACL manager from bucket manager
 	aclMgrInterface := bucketManager.GetACLManager()
@@ -448,16 +553,27 @@ func New(cfg *config.Config) (*Server, error) {
 	var deadNodeReconciler *cluster.DeadNodeReconciler
 
 	// Create HTTP servers
+	s3ListenerStats := metrics.NewListenerStats("s3")
 	httpServer := &http.Server{
 		Addr:              cfg.Listen,
+		TLSConfig:         apiTLSConfig,
 		ReadHeaderTimeout: 30 * time.Second,
 		IdleTimeout:       120 * time.Second,
+		ConnState:         s3ListenerStats.ConnState,
 	}
 
+	consoleListenerStats := metrics.NewListenerStats("console")
 	consoleServer := &http.Server{
 		Addr:              cfg.ConsoleListen,
+		TLSConfig:         consoleTLSConfig,
 		ReadHeaderTimeout: 30 * time.Second,
 		IdleTimeout:       120 * time.Second,
+		ConnState:         consoleListenerStats.ConnState,
+	}
+	if mm, ok := metricsManager.(interface {
+		SetListenerStats(...*metrics.ListenerStats)
+	}); ok {
+		mm.SetListenerStats(s3ListenerStats, consoleListenerStats)
 	}
 
 	clusterListen := cfg.ClusterListen
@@ -470,10 +586,18 @@ func New(cfg *config.Config) (*Server, error) {
 		IdleTimeout:       120 * time.Second,
 	}
 
+	rateLimiterIdle := time.Duration(cfg.Memory.RateLimiterIdleSeconds) * time.Second
+	thumbnailCacheMB := cfg.Memory.ThumbnailCacheMB
+	if thumbnailCacheMB <= 0 {
+		thumbnailCacheMB = defaultThumbnailCacheMB
+	}
+
 	server := &Server{
 		config:                  cfg,
 		httpServer:              httpServer,
 		consoleServer:           consoleServer,
+		s3ListenerStats:         s3ListenerStats,
+		consoleListenerStats:    consoleListenerStats,
 		storageBackend:          storageBackend,
 		metadataStore:           metadataStore,
 		bucketManager:           bucketManager,
@@ -496,7 +620,9 @@ func New(cfg *config.Config) (*Server, error) {
 		deadNodeReconciler:      deadNodeReconciler,
 		bucketAggregator:        bucketAggregator,
 		quotaAggregator:         quotaAggregator,
-		apiRateLimiter:          auth.NewAPIRateLimiter(),
+		apiRateLimiter:          auth.NewAPIRateLimiter(cfg.Memory.RateLimiterMaxEntries, rateLimiterIdle),
+		anonRateLimiter:         auth.NewAPIRateLimiter(cfg.Memory.RateLimiterMaxEntries, rateLimiterIdle),
+		concurrencyLimiter:      middleware.NewConcurrencyLimiter(cfg.Concurrency.MaxPut, cfg.Concurrency.MaxGet, cfg.Concurrency.MaxMultipart),
 		tenantSyncMgr:           tenantSyncMgr,
 		userSyncMgr:             userSyncMgr,
 		accessKeySyncMgr:        accessKeySyncMgr,
@@ -514,6 +640,8 @@ func New(cfg *config.Config) (*Server, error) {
 		lifecycleWorker:         lifecycleWorker,
 		inventoryManager:        inventoryManager,
 		inventoryWorker:         inventoryWorker,
+		eventLog:                eventLog,
+		thumbnails:              newThumbnailCache(thumbnailCacheMB << 20),
 		idpManager:              idpManager,
 		startTime:               time.Now(), // Record server start time
 	}
@@ -558,6 +686,14 @@ func New(cfg *config.Config) (*Server, error) {
 		server.notificationHub.SendNotification(notification)
 	})
 
+	// Sample per-tenant storage and request counts into the metrics history
+	// store so billing can read usage series per tenant
+	if usageHistory, err := metrics.NewBadgerHistoryStore(metadataStore, 365); err == nil {
+		server.usageTracker = metrics.NewUsageTracker(usageHistory, server.newTenantUsageProvider(), 5*time.Minute)
+	} else {
+		logrus.WithError(err).Warn("Tenant usage reporting disabled")
+	}
+
 	// Connect storage quota alert callback to send SSE + email notifications
 	authManager.SetStorageQuotaAlertCallback(func(tenantID string, currentBytes, maxBytes int64) {
 		server.checkQuotaAlert(tenantID, currentBytes, maxBytes)
@@ -609,6 +745,11 @@ func (s *Server) Start(ctx context.Context) error {
 		s.metricsManager.Start(ctx)
 	}
 
+	// Start tenant usage sampling (backfills hours missed while down)
+	if s.usageTracker != nil {
+		s.usageTracker.Start(ctx)
+	}
+
 	// Start audit log retention job
 	if s.config.Audit.Enable && s.auditManager != nil {
 		s.auditManager.StartRetentionJob(ctx, s.config.Audit.RetentionDays)
@@ -621,10 +762,19 @@ func (s *Server) Start(ctx context.Context) error {
 	s.inventoryWorker.Start(ctx, 1*time.Hour)
 	logrus.Info("Inventory worker started")
 
+	// Expire and trim bucket event logs (every 10 minutes)
+	if s.eventLog != nil {
+		s.eventLog.Start(ctx, 10*time.Minute)
+	}
+
 	// Start bucket stats reconciler (runs every 15 minutes)
 	go s.startStatsReconciler(ctx, 15*time.Minute)
 	logrus.Info("Bucket stats reconciler started")
 
+	// Export cache statistics and, with memory.soft_limit_mb set, shrink the
+	// caches when the heap goes over it (checks every 10 seconds)
+	s.startMemoryMonitor(ctx)
+
 	// Start disk space alert monitor (checks every 5 minutes)
 	s.startDiskAlertMonitor(ctx)
 	logrus.Info("Disk alert monitor started")
@@ -737,23 +887,76 @@ func (s *Server) reconcileBucketStats(ctx context.Context) {
 	logrus.WithField("buckets", len(buckets)).Debug("Stats reconciler: completed pass")
 }
 
+// apiClientCertTLSConfig returns the S3 API TLS settings for trusted network
+// client certificates: clients are asked for a certificate signed by one of
+// the CAs in caFile, and connections without one are still accepted. It
+// returns nil when caFile is empty.
+func apiClientCertTLSConfig(caFile string) (*tls.Config, error) {
+	if caFile == "" {
+		return nil, nil
+	}
+	pemData, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth.trusted_networks.client_ca_file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("auth.trusted_networks.client_ca_file %s contains no PEM certificates", caFile)
+	}
+	return &tls.Config{
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		ClientCAs:  pool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// listenerTLSConfig returns the TLS settings of a listener serving
+// listenerTLS, built on base (which may be nil) with its certificate loaded,
+// or base unchanged when the listener serves plain HTTP.
+func listenerTLSConfig(name string, listenerTLS config.ListenerTLS, base *tls.Config) (*tls.Config, error) {
+	if !listenerTLS.Enabled {
+		return base, nil
+	}
+	cert, err := tls.LoadX509KeyPair(listenerTLS.CertFile, listenerTLS.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s TLS: failed to load certificate %s and key %s: %w", name, listenerTLS.CertFile, listenerTLS.KeyFile, err)
+	}
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		tlsCfg = base.Clone()
+	}
+	tlsCfg.Certificates = []tls.Certificate{cert}
+	return tlsCfg, nil
+}
+
 func (s *Server) startAPIServer() error {
 	logrus.WithField("address", s.config.Listen).Info("Starting API server")
 
-	if s.config.EnableTLS {
-		return s.httpServer.ListenAndServeTLS(s.config.CertFile, s.config.KeyFile)
-	}
-	return s.httpServer.ListenAndServe()
+	return serveCounted(s.httpServer, s.s3ListenerStats, s.config.S3ListenerTLS().Enabled)
 }
 
 func (s *Server) startConsoleServer() error {
 	logrus.WithField("address", s.config.ConsoleListen).Info("Starting console server")
 
-	if s.config.EnableTLS {
+	if s.config.ConsoleListenerTLS().Enabled {
 		logrus.Info("Console server using TLS")
-		return s.consoleServer.ListenAndServeTLS(s.config.CertFile, s.config.KeyFile)
 	}
-	return s.consoleServer.ListenAndServe()
+	return serveCounted(s.consoleServer, s.consoleListenerStats, s.config.ConsoleListenerTLS().Enabled)
+}
+
+// serveCounted is ListenAndServe(TLS) with the connection bytes counted in
+// stats. With useTLS the certificate must already be in srv.TLSConfig (New
+// loads it).
+func serveCounted(srv *http.Server, stats *metrics.ListenerStats, useTLS bool) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	ln = stats.Listener(ln)
+	if useTLS {
+		return srv.ServeTLS(ln, "", "")
+	}
+	return srv.Serve(ln)
 }
 
 func (s *Server) startClusterServer() error {
@@ -1006,6 +1209,10 @@ func (sma *shareManagerAdapter) GetShareByObject(ctx context.Context, bucketName
 	return sma.mgr.GetShareByObject(ctx, bucketName, objectKey, tenantID)
 }
 
+func (sma *shareManagerAdapter) RecordAccess(ctx context.Context, access *share.Access) error {
+	return sma.mgr.RecordAccess(ctx, access)
+}
+
 // clusterBucketManagerAdapter adapts bucket.Manager to cluster.BucketManager interface
 type clusterBucketManagerAdapter struct {
 	mgr       bucket.Manager
@@ -1154,14 +1361,31 @@ func (s *Server) setupRoutes() error {
 	if s.replicationManager != nil {
 		apiHandler.SetReplicationManager(s.replicationManager)
 	}
+	if s.eventLog != nil {
+		apiHandler.SetEventLog(s.eventLog)
+	}
 	if s.clusterRouter != nil {
 		apiHandler.SetClusterRouter(s.clusterRouter)
 	}
+	apiHandler.SetClockSkew(time.Duration(s.config.Auth.ClockSkewSeconds) * time.Second)
+	apiHandler.SetPresignedMaxExpiry(int64(s.config.Auth.PresignedMaxExpirySeconds))
+	apiHandler.SetSigningDefaults(s.config.Auth.DefaultRegion, s.config.Auth.SigningService)
+	apiHandler.SetListTimeout(time.Duration(s.config.ListTimeoutSeconds) * time.Second)
+	apiHandler.SetErrorPages(s.config.ErrorPages.Mode, s.config.ErrorPages.LoginURL)
+	apiHandler.SetColdReads(s.config.Storage.ColdReads)
+	apiHandler.SetGzipTranscoding(s.config.Storage.GzipTranscoding)
+	apiHandler.SetTrustedProxies(s.config.TrustedProxies)
+	apiHandler.SetMaintenanceMode(s.maintenanceEnabled)
+	if sr, ok := s.storageBackend.(storage.SpaceReporter); ok {
+		apiHandler.SetSpaceReporter(sr)
+	}
 
 	// Start S3 access logger (delivers requests to configured target buckets)
 	s.accessLogger = NewBucketAccessLogger(s.bucketManager, s.objectManager)
 
 	// Apply middleware only to S3 subrouter (not to /metrics)
+	// Correlation ID comes first so every log line below can carry it
+	s3Router.Use(middleware.S3RequestID(s.config.HonorRequestIDHeaders))
 	// Log every S3 request at Info (logrus) first so "first probe" (e.g. VEEAM capabilities) is visible
 	s3Router.Use(middleware.S3RequestLog)
 	// S3 HEADERS MUST BE SECOND - ensures headers are present on ALL responses including auth errors
@@ -1212,11 +1436,21 @@ func (s *Server) setupRoutes() error {
 	}
 	if s.config.Metrics.Enable {
 		s3Router.Use(s.metricsManager.Middleware())
+		// Per-bucket request counts and hot keys
+		s3Router.Use(s.bucketRequestMetricsMiddleware())
+	}
+
+	// Count requests per tenant for usage reporting
+	if s.usageTracker != nil {
+		s3Router.Use(s.tenantUsageMiddleware())
 	}
 
-	// Per-user S3 API rate limiting (security.ratelimit_api_per_second)
+	// Per-user S3 API rate limiting (security.ratelimit_api_per_second), and a
+	// separate per-IP limit for unauthenticated requests
+	// (security.ratelimit_anonymous_per_second)
 	if s.config.Auth.EnableAuth {
 		s3Router.Use(auth.APIRateLimitMiddleware(s.settingsManager, s.apiRateLimiter))
+		s3Router.Use(auth.AnonymousRateLimitMiddleware(s.settingsManager, s.anonRateLimiter, s.config.TrustedProxies, apiHandler.PresignedSignatureValid))
 	}
 
 	// Enforce maximum upload body size (system.max_upload_size_mb)
@@ -1234,14 +1468,22 @@ func (s *Server) setupRoutes() error {
 	})
 
 	// Maintenance mode: block S3 write operations (PUT/DELETE/POST) when enabled.
-	s3Router.Use(middleware.MaintenanceModeS3(func() bool {
-		enabled, _ := s.settingsManager.GetBool("system.maintenance_mode")
-		return enabled
-	}))
+	s3Router.Use(middleware.MaintenanceModeS3(s.maintenanceEnabled))
 
 	// S3 access logging: capture every request after auth so the user is in context.
 	s3Router.Use(s.s3AccessLoggingMiddleware())
 
+	// Per-operation concurrency caps (concurrency.max_put/max_get/max_multipart).
+	// Runs last so throttled requests still show up in metrics and access logs.
+	if s.concurrencyLimiter != nil {
+		s3Router.Use(s.concurrencyLimiter.Middleware())
+		if mm, ok := s.metricsManager.(interface {
+			SetInFlightProvider(metrics.InFlightProvider)
+		}); ok {
+			mm.SetInFlightProvider(s.concurrencyLimiter.InFlight)
+		}
+	}
+
 	// Register API routes on the authenticated subrouter
 	apiHandler.RegisterRoutes(s3Router)
 
@@ -1251,11 +1493,11 @@ func (s *Server) setupRoutes() error {
 	// logS3APIRequests: every request that hits this server (S3 API port) is logged so "capabilities" probe is visible.
 	// The website middleware intercepts requests for "{bucket}.{website_hostname}" before
 	// virtual-hosted-style rewriting or S3 auth, serving them as plain HTML.
-	s.httpServer.Handler = logS3APIRequests(handlers.RecoveryHandler()(
+	s.httpServer.Handler = s.s3ListenerStats.Handler(logS3APIRequests(handlers.RecoveryHandler()(
 		websiteServingMiddleware(s,
 			virtualHostedStyleMiddleware(apiRouter, s.config.PublicAPIURL),
 		),
-	))
+	)))
 
 	// Setup console routes (Web UI)
 	consoleRouter := mux.NewRouter()
@@ -1285,7 +1527,7 @@ func (s *Server) setupRoutes() error {
 			consoleRouter.ServeHTTP(w, r)
 		})
 	}
-	s.consoleServer.Handler = handlers.RecoveryHandler()(middleware.ConsoleHeaders()(consoleHandler))
+	s.consoleServer.Handler = s.consoleListenerStats.Handler(handlers.RecoveryHandler()(middleware.ConsoleHeaders()(middleware.RequestID()(consoleHandler))))
 
 	// Setup cluster inter-node routes (dedicated port, not exposed to clients)
 	if s.clusterServer != nil {
@@ -1509,14 +1751,8 @@ type s3ErrorXML struct {
 // writeWebsiteAccessDenied sends 403 with S3-style XML so the endpoint behaves like
 // the S3 API when access is denied (no hint that the bucket exists or that website is disabled).
 func (s *Server) writeWebsiteAccessDenied(w http.ResponseWriter, r *http.Request) {
-	reqID := fmt.Sprintf("%d", time.Now().UnixNano())
-	hostID := r.Host
-	if hostID == "" {
-		hostID = "unknown"
-	}
+	reqID, hostID := middleware.AmzRequestIDs(w)
 	w.Header().Set("Content-Type", "application/xml")
-	w.Header().Set("X-Amz-Request-Id", reqID)
-	w.Header().Set("X-Amz-Id-2", hostID)
 	w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
 	w.WriteHeader(http.StatusForbidden)
 	if r.Method == http.MethodHead {
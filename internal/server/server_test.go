@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,6 +21,7 @@ import (
 	"github.com/maxiofs/maxiofs/internal/config"
 	"github.com/maxiofs/maxiofs/internal/metadata"
 	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/maxiofs/maxiofs/internal/share"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -427,6 +429,7 @@ func TestHandleListObjects(t *testing.T) {
 		content := []byte("test content " + string(rune('0'+i)))
 		headers := http.Header{}
 		headers.Set("Content-Type", "text/plain")
+		headers.Set("X-Amz-Meta-Index", string(rune('0'+i)))
 		_, err = server.objectManager.PutObject(testCtx, tenantID+"/"+bucketName, objectKey, bytes.NewReader(content), headers)
 		require.NoError(t, err)
 	}
@@ -451,6 +454,78 @@ func TestHandleListObjects(t *testing.T) {
 		assert.GreaterOrEqual(t, len(objects), 3, "Should have at least 3 objects")
 	})
 
+	t.Run("should list objects in descending order across pages", func(t *testing.T) {
+		var keys []string
+		marker := ""
+		for page := 0; page < 10; page++ {
+			req := createAuthenticatedRequest("GET", "/api/v1/buckets/"+bucketName+"/objects?order=desc&max_keys=2&marker="+url.QueryEscape(marker), nil, tenantID, "user-1", false)
+			req = mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+
+			rr := httptest.NewRecorder()
+			server.handleListObjects(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+			var response struct {
+				Data struct {
+					Objects     []ObjectResponse `json:"objects"`
+					IsTruncated bool             `json:"isTruncated"`
+					NextMarker  string           `json:"nextMarker"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.LessOrEqual(t, len(response.Data.Objects), 2)
+			for _, obj := range response.Data.Objects {
+				keys = append(keys, obj.Key)
+			}
+			if !response.Data.IsTruncated {
+				break
+			}
+			marker = response.Data.NextMarker
+		}
+		assert.Equal(t, []string{"test-object-c.txt", "test-object-b.txt", "test-object-a.txt"}, keys)
+	})
+
+	t.Run("should embed user metadata only when includeMetadata is set", func(t *testing.T) {
+		list := func(query string) []ObjectResponse {
+			req := createAuthenticatedRequest("GET", "/api/v1/buckets/"+bucketName+"/objects"+query, nil, tenantID, "user-1", false)
+			req = mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+			rr := httptest.NewRecorder()
+			server.handleListObjects(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			var response struct {
+				Data struct {
+					Objects []ObjectResponse `json:"objects"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			return response.Data.Objects
+		}
+
+		for _, obj := range list("") {
+			assert.Empty(t, obj.Metadata, "the default listing stays lean")
+		}
+		objects := list("?includeMetadata=true")
+		require.Len(t, objects, 3)
+		for i, obj := range objects {
+			assert.Equal(t, string(rune('1'+i)), obj.Metadata["index"], obj.Key)
+		}
+
+		req := createAuthenticatedRequest("GET", "/api/v1/buckets/"+bucketName+"/objects?includeMetadata=true&max_keys=5000", nil, tenantID, "user-1", false)
+		req = mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+		rr := httptest.NewRecorder()
+		server.handleListObjects(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "metadata pages are bounded")
+	})
+
+	t.Run("should reject an invalid order", func(t *testing.T) {
+		req := createAuthenticatedRequest("GET", "/api/v1/buckets/"+bucketName+"/objects?order=random", nil, tenantID, "user-1", false)
+		req = mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+
+		rr := httptest.NewRecorder()
+		server.handleListObjects(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
 	t.Run("should require authentication", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/api/v1/buckets/"+bucketName+"/objects", nil)
 		req = mux.SetURLVars(req, map[string]string{"bucket": bucketName})
@@ -522,6 +597,110 @@ func TestHandleListObjects(t *testing.T) {
 	})
 }
 
+// TestHandleListObjects_FolderNavigation tests that empty folder markers are
+// navigable prefixes and the console folder options
+func TestHandleListObjects_FolderNavigation(t *testing.T) {
+	server := getSharedServer()
+
+	testCtx := context.Background()
+	tenantID := "test-tenant-folders"
+	bucketName := "test-bucket-folders"
+	cleanupTestData(t, tenantID, bucketName)
+
+	require.NoError(t, server.authManager.CreateTenant(testCtx, &auth.Tenant{
+		ID:              tenantID,
+		Name:            "Test Tenant Folders",
+		Status:          "active",
+		MaxStorageBytes: 1000000000,
+		MaxBuckets:      100,
+		MaxAccessKeys:   10,
+	}))
+	require.NoError(t, server.bucketManager.CreateBucket(testCtx, tenantID, bucketName, ""))
+
+	// "empty/" exists only as a zero-byte marker, as tools create it
+	for key, content := range map[string]string{
+		"empty/":        "",
+		"docs/":         "",
+		"docs/a.txt":    "a",
+		"photos/b.jpg":  "b",
+		"readme.txt":    "readme",
+		"notes/2024/c":  "c",
+		"archive.tar":   "tar",
+		"zeta/deep/d.x": "d",
+	} {
+		_, err := server.objectManager.PutObject(testCtx, tenantID+"/"+bucketName, key, bytes.NewReader([]byte(content)), http.Header{})
+		require.NoError(t, err)
+	}
+
+	type listing struct {
+		Data struct {
+			Objects        []ObjectResponse `json:"objects"`
+			CommonPrefixes []string         `json:"commonPrefixes"`
+			IsTruncated    bool             `json:"isTruncated"`
+			NextMarker     string           `json:"nextMarker"`
+		} `json:"data"`
+	}
+	list := func(t *testing.T, query string) listing {
+		t.Helper()
+		req := createAuthenticatedRequest("GET", "/api/v1/buckets/"+bucketName+"/objects?"+query, nil, tenantID, "user-1", false)
+		req = mux.SetURLVars(req, map[string]string{"bucket": bucketName})
+		rr := httptest.NewRecorder()
+		server.handleListObjects(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var response listing
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response
+	}
+	keys := func(objects []ObjectResponse) []string {
+		var out []string
+		for _, obj := range objects {
+			out = append(out, obj.Key)
+		}
+		return out
+	}
+
+	t.Run("empty marker is a navigable prefix", func(t *testing.T) {
+		root := list(t, "delimiter=/")
+		assert.Equal(t, []string{"docs/", "empty/", "notes/", "photos/", "zeta/"}, root.Data.CommonPrefixes)
+		assert.Equal(t, []string{"archive.tar", "readme.txt"}, keys(root.Data.Objects))
+
+		inside := list(t, "delimiter=/&prefix=empty/")
+		assert.Equal(t, []string{"empty/"}, keys(inside.Data.Objects), "the marker itself is listed, as S3 does")
+		assert.Empty(t, inside.Data.CommonPrefixes)
+	})
+
+	t.Run("hideFolderMarkers", func(t *testing.T) {
+		inside := list(t, "delimiter=/&prefix=empty/&hideFolderMarkers=true")
+		assert.Empty(t, inside.Data.Objects)
+
+		docs := list(t, "delimiter=/&prefix=docs/&hideFolderMarkers=true")
+		assert.Equal(t, []string{"docs/a.txt"}, keys(docs.Data.Objects))
+	})
+
+	t.Run("foldersOnly omits files", func(t *testing.T) {
+		root := list(t, "foldersOnly=true")
+		assert.Empty(t, root.Data.Objects)
+		assert.Equal(t, []string{"docs/", "empty/", "notes/", "photos/", "zeta/"}, root.Data.CommonPrefixes)
+		assert.False(t, root.Data.IsTruncated)
+	})
+
+	t.Run("foldersOnly pages by folder", func(t *testing.T) {
+		var prefixes []string
+		marker := ""
+		for page := 0; page < 10; page++ {
+			resp := list(t, "foldersOnly=true&max_keys=2&marker="+url.QueryEscape(marker))
+			assert.Empty(t, resp.Data.Objects)
+			prefixes = append(prefixes, resp.Data.CommonPrefixes...)
+			if !resp.Data.IsTruncated {
+				break
+			}
+			assert.Len(t, resp.Data.CommonPrefixes, 2, "files don't take up room in a page")
+			marker = resp.Data.NextMarker
+		}
+		assert.Equal(t, []string{"docs/", "empty/", "notes/", "photos/", "zeta/"}, prefixes)
+	})
+}
+
 // TestHandleGetObject tests the handleGetObject handler
 func TestHandleGetObject(t *testing.T) {
 	server := getSharedServer()
@@ -2473,6 +2652,65 @@ func TestHandleDeleteShare(t *testing.T) {
 	})
 }
 
+// TestHandleGetShareAccessLog tests listing the downloads of a share
+func TestHandleGetShareAccessLog(t *testing.T) {
+	server := getSharedServer()
+
+	testCtx := context.Background()
+	tenantID := "test-tenant-share-log"
+	bucketName := "test-bucket-share-log"
+
+	tenant := &auth.Tenant{
+		ID:              tenantID,
+		Name:            "Test Tenant Share Log",
+		Status:          "active",
+		MaxStorageBytes: 1000000000,
+	}
+	require.NoError(t, server.authManager.CreateTenant(testCtx, tenant))
+	require.NoError(t, server.bucketManager.CreateBucket(testCtx, tenantID, bucketName, ""))
+	require.NoError(t, server.bucketManager.CreateBucket(testCtx, tenantID, bucketName+"-other", ""))
+
+	sh, err := server.shareManager.CreateShare(testCtx, bucketName, "report.pdf", tenantID, "access-key", "", "user-1", nil, share.ResponseOverrides{}, []string{"example.com"})
+	require.NoError(t, err)
+	for _, ip := range []string{"203.0.113.7", "198.51.100.2"} {
+		require.NoError(t, server.shareManager.RecordAccess(testCtx, &share.Access{ShareID: sh.ID, IP: ip, Bytes: 100}))
+	}
+
+	get := func(bucket, query string) *httptest.ResponseRecorder {
+		req := createAuthenticatedRequest("GET", "/api/v1/buckets/"+bucket+"/shares/"+sh.ID+"/access-log"+query, nil, tenantID, "user-1", false)
+		req = mux.SetURLVars(req, map[string]string{"bucket": bucket, "id": sh.ID})
+		rr := httptest.NewRecorder()
+		server.handleGetShareAccessLog(rr, req)
+		return rr
+	}
+
+	t.Run("should list accesses", func(t *testing.T) {
+		rr := get(bucketName, "")
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		data := resp.Data.(map[string]interface{})
+		assert.Equal(t, float64(2), data["accessCount"])
+		assert.Len(t, data["accesses"], 2)
+	})
+
+	t.Run("should honour limit", func(t *testing.T) {
+		rr := get(bucketName, "?limit=1")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp APIResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data.(map[string]interface{})["accesses"], 1)
+
+		assert.Equal(t, http.StatusBadRequest, get(bucketName, "?limit=0").Code)
+	})
+
+	t.Run("should not find a share of another bucket", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get(bucketName+"-other", "").Code)
+	})
+}
+
 // TestHandleGeneratePresignedURL tests generating presigned URLs
 func TestHandleGeneratePresignedURL(t *testing.T) {
 	server := getSharedServer()
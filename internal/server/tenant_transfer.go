@@ -0,0 +1,746 @@
+package server
+
+import (
+	"archive/tar"
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/sirupsen/logrus"
+)
+
+// A tenant archive is a tar stream read and written one entry at a time:
+//
+//	tenant.json                      tenantArchiveManifest
+//	buckets/<bucket>/bucket.json     the bucket record with its configuration
+//	buckets/<bucket>/objects/<n>     one object version; the data is the entry
+//	                                 body, its metadata the PAX record below
+//
+// Every bucket.json precedes that bucket's objects, and the versions of a key
+// are consecutive, oldest first. Entry names never contain object keys, so
+// extracting an archive with tar can't write outside the target directory.
+const (
+	tenantArchiveFormat       = 1
+	tenantArchiveManifestName = "tenant.json"
+	tenantArchiveObjectRecord = "MAXIOFS.object"
+
+	// tenantImportProgressEvery is how many object versions are imported
+	// between two progress lines.
+	tenantImportProgressEvery = 100
+)
+
+type tenantArchiveManifest struct {
+	Format     int                 `json:"format"`
+	ExportedAt time.Time           `json:"exportedAt"`
+	Tenant     tenantArchiveTenant `json:"tenant"`
+}
+
+type tenantArchiveTenant struct {
+	Name                    string            `json:"name"`
+	DisplayName             string            `json:"displayName"`
+	Description             string            `json:"description"`
+	MaxAccessKeys           int64             `json:"maxAccessKeys"`
+	MaxStorageBytes         int64             `json:"maxStorageBytes"`
+	MaxBandwidthBytesPerSec int64             `json:"maxBandwidthBytesPerSec"`
+	MaxFailedLoginAttempts  int               `json:"maxFailedLoginAttempts,omitempty"`
+	LockoutDurationSeconds  int64             `json:"lockoutDurationSeconds,omitempty"`
+	MaxBuckets              int64             `json:"maxBuckets"`
+	Metadata                map[string]string `json:"metadata,omitempty"`
+}
+
+// tenantArchiveObject describes one object version of the archive.
+type tenantArchiveObject struct {
+	Key                string            `json:"key"`
+	VersionID          string            `json:"versionId,omitempty"`
+	DeleteMarker       bool              `json:"deleteMarker,omitempty"`
+	Size               int64             `json:"size"`
+	ETag               string            `json:"etag"`
+	LastModified       time.Time         `json:"lastModified"`
+	ContentType        string            `json:"contentType,omitempty"`
+	ContentDisposition string            `json:"contentDisposition,omitempty"`
+	ContentEncoding    string            `json:"contentEncoding,omitempty"`
+	CacheControl       string            `json:"cacheControl,omitempty"`
+	ContentLanguage    string            `json:"contentLanguage,omitempty"`
+	StorageClass       string            `json:"storageClass,omitempty"`
+	ChecksumAlgorithm  string            `json:"checksumAlgorithm,omitempty"`
+	ChecksumValue      string            `json:"checksumValue,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	RetentionMode      string            `json:"retentionMode,omitempty"`
+	RetainUntil        *time.Time        `json:"retainUntil,omitempty"`
+	LegalHold          bool              `json:"legalHold,omitempty"`
+}
+
+// tenantImportProgress is one NDJSON line of the import response. Counters
+// are cumulative; the last line has Done set (or Error on failure). Cursor is
+// the last "<bucket>/<key>" whose versions were all imported.
+type tenantImportProgress struct {
+	TenantID string `json:"tenantId"`
+	Buckets  int    `json:"buckets"`
+	Objects  int    `json:"objects"`
+	Skipped  int    `json:"skipped"`
+	Bytes    int64  `json:"bytes"`
+	Cursor   string `json:"cursor"`
+	Done     bool   `json:"done,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// handleExportTenant streams every bucket of a tenant, with its configuration
+// and all object versions, as a tar archive for handleImportTenant.
+// GET /tenants/{tenant}/export[?after=<bucket>/<key>]
+//
+// after resumes an interrupted export behind the cursor of the last import
+// progress line; tenant.json and the bucket.json of the resumed bucket are
+// sent again. Object data is read back decrypted, one entry at a time, so
+// memory use doesn't grow with the tenant. Once streaming has started errors
+// can no longer change the status code: a failure ends the archive without
+// its trailer, which the importer reports as a truncated archive.
+// Only global admins may export a tenant.
+func (s *Server) handleExportTenant(w http.ResponseWriter, r *http.Request) {
+	user := s.getAuthUser(r)
+	if user == nil || !s.isGlobalAdmin(user) {
+		s.writeError(w, "Only global administrators can export tenants", http.StatusForbidden)
+		return
+	}
+
+	tenant, err := s.authManager.GetTenant(r.Context(), mux.Vars(r)["tenant"])
+	if err != nil {
+		if err == auth.ErrUserNotFound {
+			s.writeError(w, "Tenant not found", http.StatusNotFound)
+			return
+		}
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	afterBucket, afterKey, _ := strings.Cut(r.URL.Query().Get("after"), "/")
+
+	buckets, err := s.bucketManager.ListBuckets(r.Context(), tenant.ID)
+	if err != nil {
+		s.writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.tar"`, tenant.Name))
+	w.WriteHeader(http.StatusOK)
+
+	flusher, canFlush := w.(http.Flusher)
+	bw := bufio.NewWriterSize(w, 64*1024)
+	tw := tar.NewWriter(bw)
+	flush := func() error {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	manifest := tenantArchiveManifest{
+		Format:     tenantArchiveFormat,
+		ExportedAt: time.Now().UTC(),
+		Tenant: tenantArchiveTenant{
+			Name:                    tenant.Name,
+			DisplayName:             tenant.DisplayName,
+			Description:             tenant.Description,
+			MaxAccessKeys:           tenant.MaxAccessKeys,
+			MaxStorageBytes:         tenant.MaxStorageBytes,
+			MaxBandwidthBytesPerSec: tenant.MaxBandwidthBytesPerSec,
+			MaxFailedLoginAttempts:  tenant.MaxFailedLoginAttempts,
+			LockoutDurationSeconds:  tenant.LockoutDurationSeconds,
+			MaxBuckets:              tenant.MaxBuckets,
+			Metadata:                tenant.Metadata,
+		},
+	}
+	if err := writeTenantArchiveJSON(tw, tenantArchiveManifestName, &manifest); err != nil {
+		return
+	}
+
+	versions := 0
+	for _, b := range buckets {
+		if b.Name < afterBucket {
+			continue
+		}
+		marker := ""
+		if b.Name == afterBucket {
+			marker = afterKey
+		}
+		n, err := s.exportTenantBucket(r, tw, flush, tenant.ID, b.Name, marker)
+		versions += n
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{"tenant": tenant.Name, "bucket": b.Name}).Error("Tenant export failed")
+			return
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return
+	}
+	if err := flush(); err != nil {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"tenant":   tenant.Name,
+		"buckets":  len(buckets),
+		"versions": versions,
+		"after":    r.URL.Query().Get("after"),
+		"user":     user.Username,
+	}).Info("Tenant exported")
+}
+
+// exportTenantBucket writes a bucket's record and every version of every key
+// after marker, paging through the metadata store.
+func (s *Server) exportTenantBucket(r *http.Request, tw *tar.Writer, flush func() error, tenantID, bucketName, marker string) (int, error) {
+	ctx := r.Context()
+	info, err := s.bucketManager.GetBucketInfo(ctx, tenantID, bucketName)
+	if err != nil {
+		return 0, err
+	}
+	dir := path.Join("buckets", bucketName)
+	if err := writeTenantArchiveJSON(tw, path.Join(dir, "bucket.json"), info); err != nil {
+		return 0, err
+	}
+
+	bucketPath := buildBucketPath(tenantID, bucketName)
+	written := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		objects, nextMarker, err := s.metadataStore.ListObjects(ctx, bucketPath, "", marker, exportPageSize)
+		if err != nil {
+			return written, fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, listed := range objects {
+			// Implicit folders only exist in metadata; importing their
+			// children recreates them
+			if listed.Metadata["x-maxiofs-implicit-folder"] == "true" {
+				continue
+			}
+			records, err := s.tenantArchiveVersions(r, bucketPath, listed)
+			if err != nil {
+				return written, err
+			}
+			for _, rec := range records {
+				name := path.Join(dir, "objects", fmt.Sprintf("%08d", written))
+				if err := s.writeTenantArchiveObject(r, tw, name, bucketPath, rec); err != nil {
+					return written, err
+				}
+				written++
+			}
+		}
+		if err := flush(); err != nil {
+			return written, err
+		}
+		if nextMarker == "" {
+			return written, nil
+		}
+		marker = nextMarker
+	}
+}
+
+// tenantArchiveVersions returns the records of every version of a listed key,
+// oldest first so an import ends with the same latest version. Keys written
+// before versioning was enabled have no version entries, only the current
+// object.
+func (s *Server) tenantArchiveVersions(r *http.Request, bucketPath string, listed *metadata.ObjectMetadata) ([]*tenantArchiveObject, error) {
+	versions, err := s.objectManager.GetObjectVersions(r.Context(), bucketPath, listed.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of %s: %w", listed.Key, err)
+	}
+	if len(versions) == 0 {
+		obj, err := s.objectManager.GetObjectMetadata(r.Context(), bucketPath, listed.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", listed.Key, err)
+		}
+		versions = []object.ObjectVersion{{Object: *obj, IsLatest: true}}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i], versions[j]
+		if a.IsLatest != b.IsLatest {
+			return b.IsLatest
+		}
+		if !a.LastModified.Equal(b.LastModified) {
+			return a.LastModified.Before(b.LastModified)
+		}
+		return a.VersionID < b.VersionID
+	})
+
+	records := make([]*tenantArchiveObject, 0, len(versions))
+	for _, v := range versions {
+		rec := &tenantArchiveObject{
+			Key:                v.Key,
+			VersionID:          v.VersionID,
+			DeleteMarker:       v.IsDeleteMarker,
+			Size:               v.Size,
+			ETag:               v.ETag,
+			LastModified:       v.LastModified.UTC(),
+			ContentType:        v.ContentType,
+			ContentDisposition: v.ContentDisposition,
+			ContentEncoding:    v.ContentEncoding,
+			CacheControl:       v.CacheControl,
+			ContentLanguage:    v.ContentLanguage,
+			StorageClass:       v.StorageClass,
+			ChecksumAlgorithm:  v.ChecksumAlgorithm,
+			ChecksumValue:      v.ChecksumValue,
+			Metadata:           v.Metadata,
+			LegalHold:          v.LegalHold != nil && v.LegalHold.Status == object.LegalHoldStatusOn,
+		}
+		if rec.DeleteMarker {
+			rec.Size = 0
+		}
+		if v.Tags != nil && len(v.Tags.Tags) > 0 {
+			rec.Tags = make(map[string]string, len(v.Tags.Tags))
+			for _, tag := range v.Tags.Tags {
+				rec.Tags[tag.Key] = tag.Value
+			}
+		}
+		if v.Retention != nil {
+			until := v.Retention.RetainUntilDate.UTC()
+			rec.RetentionMode = v.Retention.Mode
+			rec.RetainUntil = &until
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// writeTenantArchiveObject writes one version entry, streaming its data.
+func (s *Server) writeTenantArchiveObject(r *http.Request, tw *tar.Writer, name, bucketPath string, rec *tenantArchiveObject) error {
+	recJSON, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Typeflag:   tar.TypeReg,
+		Name:       name,
+		Mode:       0o644,
+		Size:       rec.Size,
+		ModTime:    rec.LastModified,
+		Format:     tar.FormatPAX,
+		PAXRecords: map[string]string{tenantArchiveObjectRecord: string(recJSON)},
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if rec.Size == 0 {
+		return nil
+	}
+
+	_, reader, err := s.objectManager.GetObject(r.Context(), bucketPath, rec.Key, versionArgs(rec.VersionID)...)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rec.Key, err)
+	}
+	defer reader.Close()
+	if _, err := io.CopyN(tw, reader, rec.Size); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", rec.Key, err)
+	}
+	return nil
+}
+
+func writeTenantArchiveJSON(tw *tar.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// versionArgs turns an optional version ID into the variadic argument of the
+// object manager.
+func versionArgs(versionID string) []string {
+	if versionID == "" {
+		return nil
+	}
+	return []string{versionID}
+}
+
+// handleImportTenant recreates a tenant from an archive written by
+// handleExportTenant, streaming NDJSON progress lines.
+// POST /tenants/import (body: the tar archive)
+//
+// The tenant is matched by name and created when missing. Buckets are created
+// in it with their configuration, and every object version is written with
+// its original version ID, timestamps, metadata, tags, retention and legal
+// hold. Object data is copied straight from the request into storage.
+//
+// Versions that already exist are skipped, so an interrupted import is resumed
+// by sending the archive again, or an export started after the cursor of the
+// last progress line. Settings that act on new writes (default retention, the
+// write lock, no-overwrite, the version cap, lifecycle rules, notifications
+// and the bucket quota) are held back until the bucket's last object is in.
+// Multipart objects are stored as single objects, so their ETag changes.
+// Users, access keys and ACLs are not part of the archive; imported buckets
+// are owned by the tenant. Only global admins may import.
+func (s *Server) handleImportTenant(w http.ResponseWriter, r *http.Request) {
+	user := s.getAuthUser(r)
+	if user == nil || !s.isGlobalAdmin(user) {
+		s.writeError(w, "Only global administrators can import tenants", http.StatusForbidden)
+		return
+	}
+
+	tr := tar.NewReader(r.Body)
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != tenantArchiveManifestName {
+		s.writeError(w, "Not a tenant archive: it must start with "+tenantArchiveManifestName, http.StatusBadRequest)
+		return
+	}
+	var manifest tenantArchiveManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		s.writeError(w, "Invalid "+tenantArchiveManifestName+": "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if manifest.Format != tenantArchiveFormat {
+		s.writeError(w, fmt.Sprintf("Unsupported tenant archive format %d", manifest.Format), http.StatusBadRequest)
+		return
+	}
+	if manifest.Tenant.Name == "" {
+		s.writeError(w, "The archive names no tenant", http.StatusBadRequest)
+		return
+	}
+
+	tenant, err := s.importArchiveTenant(r, &manifest.Tenant)
+	if err != nil {
+		s.writeError(w, "Failed to create tenant: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, canFlush := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	send := func(p *tenantImportProgress) {
+		if err := enc.Encode(p); err == nil && canFlush {
+			flusher.Flush()
+		}
+	}
+
+	imp := &tenantImporter{s: s, r: r, tenantID: tenant.ID}
+	imp.progress.TenantID = tenant.ID
+	err = imp.run(tr, func() { send(&imp.progress) })
+	if err == nil {
+		err = imp.finishBucket()
+	}
+	if err != nil {
+		imp.progress.Error = err.Error()
+		send(&imp.progress)
+		logrus.WithError(err).WithFields(logrus.Fields{"tenant": tenant.Name, "cursor": imp.progress.Cursor}).Error("Tenant import failed")
+		return
+	}
+	if imp.bucket != nil && imp.key != "" {
+		imp.progress.Cursor = imp.bucket.Name + "/" + imp.key
+	}
+	imp.progress.Done = true
+	send(&imp.progress)
+
+	logrus.WithFields(logrus.Fields{
+		"tenant":  tenant.Name,
+		"buckets": imp.progress.Buckets,
+		"objects": imp.progress.Objects,
+		"skipped": imp.progress.Skipped,
+		"user":    user.Username,
+	}).Info("Tenant imported")
+}
+
+// importArchiveTenant returns the tenant named in the archive, creating it
+// when this instance doesn't have it yet.
+func (s *Server) importArchiveTenant(r *http.Request, t *tenantArchiveTenant) (*auth.Tenant, error) {
+	existing, err := s.authManager.GetTenantByName(r.Context(), t.Name)
+	if err == nil {
+		return existing, nil
+	}
+	if err != auth.ErrUserNotFound {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	tenant := &auth.Tenant{
+		ID:                      auth.GenerateTenantID(),
+		Name:                    t.Name,
+		DisplayName:             t.DisplayName,
+		Description:             t.Description,
+		Status:                  "active",
+		MaxAccessKeys:           t.MaxAccessKeys,
+		MaxStorageBytes:         t.MaxStorageBytes,
+		MaxBandwidthBytesPerSec: t.MaxBandwidthBytesPerSec,
+		MaxFailedLoginAttempts:  t.MaxFailedLoginAttempts,
+		LockoutDurationSeconds:  t.LockoutDurationSeconds,
+		MaxBuckets:              t.MaxBuckets,
+		Metadata:                t.Metadata,
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	if err := s.authManager.CreateTenant(r.Context(), tenant); err != nil {
+		return nil, err
+	}
+	s.touchLocalWriteAt(r.Context())
+	if s.tenantSyncMgr != nil {
+		s.tenantSyncMgr.TriggerSync(r.Context())
+	}
+	return tenant, nil
+}
+
+// tenantImporter applies the entries of a tenant archive in order.
+type tenantImporter struct {
+	s        *Server
+	r        *http.Request
+	tenantID string
+	progress tenantImportProgress
+
+	bucket     *bucket.Bucket // archived record of the bucket being imported
+	bucketPath string
+	key        string // key of the last version read
+}
+
+func (imp *tenantImporter) run(tr *tar.Reader, report func()) error {
+	sinceReport := 0
+	for {
+		if err := imp.r.Context().Err(); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("archive is truncated or corrupt: %w", err)
+		}
+
+		if recJSON, ok := hdr.PAXRecords[tenantArchiveObjectRecord]; ok {
+			var rec tenantArchiveObject
+			if err := json.Unmarshal([]byte(recJSON), &rec); err != nil {
+				return fmt.Errorf("invalid object record in %s: %w", hdr.Name, err)
+			}
+			if imp.bucket == nil || !strings.HasPrefix(hdr.Name, "buckets/"+imp.bucket.Name+"/") {
+				return fmt.Errorf("object entry %s precedes its bucket.json", hdr.Name)
+			}
+			if rec.Key != imp.key && imp.key != "" {
+				imp.progress.Cursor = imp.bucket.Name + "/" + imp.key
+			}
+			imp.key = rec.Key
+			if err := imp.importObject(&rec, tr); err != nil {
+				return fmt.Errorf("failed to import %s/%s: %w", imp.bucket.Name, rec.Key, err)
+			}
+			if sinceReport++; sinceReport == tenantImportProgressEvery {
+				sinceReport = 0
+				report()
+			}
+			continue
+		}
+
+		if path.Base(hdr.Name) == "bucket.json" {
+			var archived bucket.Bucket
+			if err := json.NewDecoder(tr).Decode(&archived); err != nil {
+				return fmt.Errorf("invalid %s: %w", hdr.Name, err)
+			}
+			if err := imp.finishBucket(); err != nil {
+				return err
+			}
+			if err := imp.startBucket(&archived); err != nil {
+				return fmt.Errorf("failed to import bucket %s: %w", archived.Name, err)
+			}
+			report()
+		}
+		// Anything else is from a newer format and skipped
+	}
+}
+
+// startBucket creates the bucket if needed and applies its configuration,
+// minus the settings that would act on the versions being imported.
+func (imp *tenantImporter) startBucket(archived *bucket.Bucket) error {
+	ctx := imp.r.Context()
+	current, err := imp.s.bucketManager.GetBucketInfo(ctx, imp.tenantID, archived.Name)
+	if err == bucket.ErrBucketNotFound {
+		if err := imp.s.bucketManager.CreateBucket(ctx, imp.tenantID, archived.Name, ""); err != nil {
+			return err
+		}
+		current, err = imp.s.bucketManager.GetBucketInfo(ctx, imp.tenantID, archived.Name)
+	}
+	if err != nil {
+		return err
+	}
+	applyArchivedBucketConfig(current, archived, true)
+	if err := imp.s.bucketManager.UpdateBucket(ctx, imp.tenantID, archived.Name, current); err != nil {
+		return err
+	}
+
+	if imp.bucket != nil && imp.key != "" {
+		imp.progress.Cursor = imp.bucket.Name + "/" + imp.key
+	}
+	imp.bucket = archived
+	imp.bucketPath = buildBucketPath(imp.tenantID, archived.Name)
+	imp.key = ""
+	imp.progress.Buckets++
+	return nil
+}
+
+// finishBucket applies the full configuration of the bucket being imported.
+// The bucket is read again first: UpdateBucket writes the whole record, and
+// the object count and size have changed since startBucket.
+func (imp *tenantImporter) finishBucket() error {
+	if imp.bucket == nil {
+		return nil
+	}
+	ctx := imp.r.Context()
+	current, err := imp.s.bucketManager.GetBucketInfo(ctx, imp.tenantID, imp.bucket.Name)
+	if err != nil {
+		return err
+	}
+	applyArchivedBucketConfig(current, imp.bucket, false)
+	return imp.s.bucketManager.UpdateBucket(ctx, imp.tenantID, imp.bucket.Name, current)
+}
+
+// applyArchivedBucketConfig copies the configuration of an archived bucket
+// onto the local one, keeping the local identity, ownership and counters.
+// While importing, versioning is turned on (version IDs are only kept by a
+// versioned bucket) and the settings that act on writes are left out.
+func applyArchivedBucketConfig(dst, src *bucket.Bucket, importing bool) {
+	dst.IsPublic = src.IsPublic
+	dst.CreatedAt = src.CreatedAt
+	dst.Region = src.Region
+	dst.Versioning = src.Versioning
+	dst.ObjectLock = src.ObjectLock
+	dst.Policy = src.Policy
+	dst.Lifecycle = src.Lifecycle
+	dst.CORS = src.CORS
+	dst.Encryption = src.Encryption
+	dst.PublicAccessBlock = src.PublicAccessBlock
+	dst.Website = src.Website
+	dst.Notification = src.Notification
+	dst.Logging = src.Logging
+	dst.Tags = src.Tags
+	dst.Metadata = src.Metadata
+	dst.Quota = src.Quota
+	dst.DefaultWriteLockDays = src.DefaultWriteLockDays
+	dst.NoOverwrite = src.NoOverwrite
+	dst.MaxVersionsPerObject = src.MaxVersionsPerObject
+	dst.CacheDefaults = src.CacheDefaults
+	dst.DefaultContentType = src.DefaultContentType
+	dst.ScanUploads = src.ScanUploads
+	if !importing {
+		return
+	}
+
+	if src.Versioning != nil && src.Versioning.Status != "" {
+		versioning := *src.Versioning
+		versioning.Status = "Enabled"
+		dst.Versioning = &versioning
+	}
+	if src.ObjectLock != nil {
+		objectLock := *src.ObjectLock
+		objectLock.Rule = nil
+		dst.ObjectLock = &objectLock
+	}
+	dst.Lifecycle = nil
+	dst.Notification = nil
+	dst.Quota = nil
+	dst.DefaultWriteLockDays = 0
+	dst.NoOverwrite = false
+	dst.MaxVersionsPerObject = 0
+}
+
+// importObject writes one archived version unless it already exists.
+func (imp *tenantImporter) importObject(rec *tenantArchiveObject, data io.Reader) error {
+	ctx := object.WithBypassQuotaEnforcement(imp.r.Context())
+	ctx = object.WithReplicatedLastModified(ctx, rec.LastModified)
+
+	if rec.VersionID != "" {
+		if _, err := imp.s.metadataStore.GetObject(ctx, imp.bucketPath, rec.Key, rec.VersionID); err == nil {
+			imp.progress.Skipped++
+			return nil
+		}
+		ctx = object.WithReplicatedVersionID(ctx, rec.VersionID)
+	} else if existing, err := imp.s.metadataStore.GetObject(ctx, imp.bucketPath, rec.Key); err == nil &&
+		(strings.HasSuffix(rec.Key, "/") || (existing.ETag == rec.ETag && existing.Size == rec.Size)) {
+		// Folder markers are also created implicitly by the objects below them
+		imp.progress.Skipped++
+		return nil
+	}
+
+	if rec.DeleteMarker {
+		if _, err := imp.s.objectManager.DeleteObject(ctx, imp.bucketPath, rec.Key, false); err != nil {
+			return err
+		}
+		imp.progress.Objects++
+		return nil
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", rec.ContentType)
+	headers.Set("Content-Disposition", rec.ContentDisposition)
+	headers.Set("Content-Encoding", rec.ContentEncoding)
+	headers.Set("Cache-Control", rec.CacheControl)
+	headers.Set("Content-Language", rec.ContentLanguage)
+	if rec.StorageClass != "" {
+		headers.Set("x-amz-storage-class", rec.StorageClass)
+	}
+	for k, v := range rec.Metadata {
+		headers.Set("x-amz-meta-"+k, v)
+	}
+	// The data is verified against the digests it was exported with
+	if md5Sum, err := hex.DecodeString(rec.ETag); err == nil && len(md5Sum) == 16 {
+		headers.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum))
+	}
+	if rec.ChecksumAlgorithm != "" {
+		headers.Set("x-amz-checksum-algorithm", rec.ChecksumAlgorithm)
+		headers.Set("x-amz-checksum-"+strings.ToLower(rec.ChecksumAlgorithm), rec.ChecksumValue)
+	}
+
+	obj, err := imp.s.objectManager.PutObject(ctx, imp.bucketPath, rec.Key, data, headers)
+	if err != nil {
+		if errors.Is(err, object.ErrBadDigest) {
+			return fmt.Errorf("data doesn't match the exported ETag")
+		}
+		return err
+	}
+	versions := versionArgs(obj.VersionID)
+
+	if len(rec.Tags) > 0 {
+		tagSet := &object.TagSet{Tags: make([]object.Tag, 0, len(rec.Tags))}
+		for k, v := range rec.Tags {
+			tagSet.Tags = append(tagSet.Tags, object.Tag{Key: k, Value: v})
+		}
+		sort.Slice(tagSet.Tags, func(i, j int) bool { return tagSet.Tags[i].Key < tagSet.Tags[j].Key })
+		if err := imp.s.objectManager.SetObjectTagging(ctx, imp.bucketPath, rec.Key, tagSet, versions...); err != nil {
+			return fmt.Errorf("failed to set tags: %w", err)
+		}
+	}
+	// Expired retention no longer protects anything and isn't carried over
+	if rec.RetainUntil != nil && rec.RetainUntil.After(time.Now()) {
+		retention := &object.RetentionConfig{Mode: rec.RetentionMode, RetainUntilDate: *rec.RetainUntil}
+		if err := imp.s.objectManager.SetObjectRetention(ctx, imp.bucketPath, rec.Key, retention, versions...); err != nil {
+			return fmt.Errorf("failed to set retention: %w", err)
+		}
+	}
+	if rec.LegalHold {
+		hold := &object.LegalHoldConfig{Status: object.LegalHoldStatusOn}
+		if err := imp.s.objectManager.SetObjectLegalHold(ctx, imp.bucketPath, rec.Key, hold, versions...); err != nil {
+			return fmt.Errorf("failed to set legal hold: %w", err)
+		}
+	}
+
+	imp.progress.Objects++
+	imp.progress.Bytes += rec.Size
+	return nil
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tenantSnapshot is everything an import must reproduce, keyed by
+// bucket/key/versionId.
+type tenantSnapshot map[string]string
+
+func snapshotTenant(t *testing.T, server *Server, tenantID string) tenantSnapshot {
+	t.Helper()
+	ctx := context.Background()
+	snap := tenantSnapshot{}
+	buckets, err := server.bucketManager.ListBuckets(ctx, tenantID)
+	require.NoError(t, err)
+	for _, b := range buckets {
+		info, err := server.bucketManager.GetBucketInfo(ctx, tenantID, b.Name)
+		require.NoError(t, err)
+		cfg, _ := json.Marshal(map[string]interface{}{
+			"versioning": info.Versioning, "objectLock": info.ObjectLock, "tags": info.Tags, "noOverwrite": info.NoOverwrite,
+		})
+		snap[b.Name] = string(cfg)
+
+		bucketPath := buildBucketPath(tenantID, b.Name)
+		objects, _, err := server.metadataStore.ListObjects(ctx, bucketPath, "", "", 1000)
+		require.NoError(t, err)
+		for _, listed := range objects {
+			if listed.Metadata["x-maxiofs-implicit-folder"] == "true" {
+				snap[b.Name+"/"+listed.Key] = "implicit folder"
+				continue
+			}
+			versions, err := server.objectManager.GetObjectVersions(ctx, bucketPath, listed.Key)
+			require.NoError(t, err)
+			if len(versions) == 0 {
+				obj, err := server.objectManager.GetObjectMetadata(ctx, bucketPath, listed.Key)
+				require.NoError(t, err, "%s/%s", b.Name, listed.Key)
+				versions = []object.ObjectVersion{{Object: *obj, IsLatest: true}}
+			}
+			for _, v := range versions {
+				state := map[string]interface{}{
+					"latest": v.IsLatest, "deleteMarker": v.IsDeleteMarker, "etag": v.ETag,
+					"lastModified": v.LastModified.Unix(), "contentType": v.ContentType, "metadata": v.Metadata,
+					"tags": v.Tags, "retention": v.Retention, "legalHold": v.LegalHold,
+				}
+				if !v.IsDeleteMarker && v.Size > 0 {
+					_, reader, err := server.objectManager.GetObject(ctx, bucketPath, v.Key, versionArgs(v.VersionID)...)
+					require.NoError(t, err)
+					data, err := io.ReadAll(reader)
+					reader.Close()
+					require.NoError(t, err)
+					state["data"] = string(data)
+				}
+				encoded, _ := json.Marshal(state)
+				snap[b.Name+"/"+v.Key+"?"+v.VersionID] = string(encoded)
+			}
+		}
+	}
+	return snap
+}
+
+func exportTenantArchive(t *testing.T, server *Server, admin *auth.User, tenantID, after string) []byte {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/v1/tenants/"+tenantID+"/export?after="+after, nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", admin))
+	req = mux.SetURLVars(req, map[string]string{"tenant": tenantID})
+	rr := httptest.NewRecorder()
+	server.handleExportTenant(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "application/x-tar", rr.Header().Get("Content-Type"))
+	return rr.Body.Bytes()
+}
+
+func importTenantArchive(t *testing.T, server *Server, admin *auth.User, archive []byte) []tenantImportProgress {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/v1/tenants/import", bytes.NewReader(archive))
+	req = req.WithContext(context.WithValue(req.Context(), "user", admin))
+	rr := httptest.NewRecorder()
+	server.handleImportTenant(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var lines []tenantImportProgress
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var p tenantImportProgress
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &p), "line is not valid JSON: %q", scanner.Text())
+		lines = append(lines, p)
+	}
+	require.NotEmpty(t, lines)
+	return lines
+}
+
+func TestTenantExportImport(t *testing.T) {
+	source, _, cleanupSource := setupTestServer(t)
+	defer cleanupSource()
+	target, _, cleanupTarget := setupTestServer(t)
+	defer cleanupTarget()
+	ctx := context.Background()
+
+	sourceAdmin, err := source.authManager.ValidateJWT(ctx, getAdminToken(t, source))
+	require.NoError(t, err)
+	targetAdmin, err := target.authManager.ValidateJWT(ctx, getAdminToken(t, target))
+	require.NoError(t, err)
+
+	tenant := &auth.Tenant{ID: auth.GenerateTenantID(), Name: "acme", DisplayName: "Acme Corp", Status: "active", MaxBuckets: 10}
+	require.NoError(t, source.authManager.CreateTenant(ctx, tenant))
+
+	// A versioned, object-locked bucket with a default retention rule
+	require.NoError(t, source.bucketManager.CreateBucket(ctx, tenant.ID, "records", ""))
+	records, err := source.bucketManager.GetBucketInfo(ctx, tenant.ID, "records")
+	require.NoError(t, err)
+	records.Versioning = &bucket.VersioningConfig{Status: "Enabled"}
+	records.ObjectLock = &bucket.ObjectLockConfig{ObjectLockEnabled: true}
+	records.Tags = map[string]string{"team": "finance"}
+	require.NoError(t, source.bucketManager.UpdateBucket(ctx, tenant.ID, "records", records))
+
+	recordsPath := buildBucketPath(tenant.ID, "records")
+	put := func(bucketPath, key, body string, headers http.Header) *object.Object {
+		if headers == nil {
+			headers = http.Header{}
+		}
+		headers.Set("Content-Type", "text/plain")
+		obj, err := source.objectManager.PutObject(ctx, bucketPath, key, bytes.NewReader([]byte(body)), headers)
+		require.NoError(t, err)
+		return obj
+	}
+	first := put(recordsPath, "2025/q1.csv", "revenue,1", nil)
+	require.NoError(t, source.objectManager.SetObjectRetention(ctx, recordsPath, "2025/q1.csv",
+		&object.RetentionConfig{Mode: "GOVERNANCE", RetainUntilDate: time.Now().Add(24 * time.Hour).UTC()}, first.VersionID))
+	time.Sleep(1100 * time.Millisecond) // versions are ordered by second-resolution timestamps
+	latest := put(recordsPath, "2025/q1.csv", "revenue,2", http.Header{"X-Amz-Meta-Reviewed": {"yes"}})
+	require.NoError(t, source.objectManager.SetObjectTagging(ctx, recordsPath, "2025/q1.csv",
+		&object.TagSet{Tags: []object.Tag{{Key: "class", Value: "confidential"}}}, latest.VersionID))
+	require.NoError(t, source.objectManager.SetObjectLegalHold(ctx, recordsPath, "2025/q1.csv",
+		&object.LegalHoldConfig{Status: object.LegalHoldStatusOn}, latest.VersionID))
+	put(recordsPath, "2025/draft.csv", "draft", nil)
+	_, err = source.objectManager.DeleteObject(ctx, recordsPath, "2025/draft.csv", false)
+	require.NoError(t, err)
+
+	// The default retention rule is set last so the versions above keep
+	// exactly the retention given to them
+	records, err = source.bucketManager.GetBucketInfo(ctx, tenant.ID, "records")
+	require.NoError(t, err)
+	days := 30
+	records.ObjectLock.Rule = &bucket.ObjectLockRule{DefaultRetention: &bucket.DefaultRetention{Mode: "GOVERNANCE", Days: &days}}
+	require.NoError(t, source.bucketManager.UpdateBucket(ctx, tenant.ID, "records", records))
+
+	// A plain bucket
+	require.NoError(t, source.bucketManager.CreateBucket(ctx, tenant.ID, "website", ""))
+	websitePath := buildBucketPath(tenant.ID, "website")
+	put(websitePath, "index.html", "<h1>acme</h1>", nil)
+	put(websitePath, "assets/logo.svg", "<svg/>", nil)
+
+	want := snapshotTenant(t, source, tenant.ID)
+	require.Contains(t, want, "records/2025/q1.csv?"+latest.VersionID)
+	require.Contains(t, want["records/2025/q1.csv?"+first.VersionID], "GOVERNANCE")
+
+	archive := exportTenantArchive(t, source, sourceAdmin, tenant.ID, "")
+
+	// Entry names never carry object keys
+	tr := tar.NewReader(bytes.NewReader(archive))
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	assert.Equal(t, "tenant.json", names[0])
+	assert.Contains(t, names, "buckets/records/bucket.json")
+	assert.NotContains(t, names, "buckets/website/index.html")
+
+	lines := importTenantArchive(t, target, targetAdmin, archive)
+	final := lines[len(lines)-1]
+	require.True(t, final.Done, final.Error)
+	assert.Equal(t, 2, final.Buckets)
+	assert.Zero(t, final.Skipped)
+
+	imported, err := target.authManager.GetTenantByName(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Corp", imported.DisplayName)
+	assert.Equal(t, final.TenantID, imported.ID)
+	assert.Equal(t, want, snapshotTenant(t, target, imported.ID))
+
+	// Importing again changes nothing, which is what makes an interrupted
+	// import resumable
+	lines = importTenantArchive(t, target, targetAdmin, archive)
+	final = lines[len(lines)-1]
+	require.True(t, final.Done, final.Error)
+	assert.Zero(t, final.Objects)
+	assert.Equal(t, want, snapshotTenant(t, target, imported.ID))
+}
+
+func TestTenantImport_ResumesAfterTruncatedArchive(t *testing.T) {
+	source, _, cleanupSource := setupTestServer(t)
+	defer cleanupSource()
+	target, _, cleanupTarget := setupTestServer(t)
+	defer cleanupTarget()
+	ctx := context.Background()
+
+	sourceAdmin, err := source.authManager.ValidateJWT(ctx, getAdminToken(t, source))
+	require.NoError(t, err)
+	targetAdmin, err := target.authManager.ValidateJWT(ctx, getAdminToken(t, target))
+	require.NoError(t, err)
+
+	tenant := &auth.Tenant{ID: auth.GenerateTenantID(), Name: "globex", Status: "active"}
+	require.NoError(t, source.authManager.CreateTenant(ctx, tenant))
+	require.NoError(t, source.bucketManager.CreateBucket(ctx, tenant.ID, "media", ""))
+	bucketPath := buildBucketPath(tenant.ID, "media")
+	keys := []string{"a.bin", "b.bin", "c.bin", "d.bin"}
+	for _, key := range keys {
+		_, err := source.objectManager.PutObject(ctx, bucketPath, key, bytes.NewReader(bytes.Repeat([]byte(key), 4096)), http.Header{})
+		require.NoError(t, err)
+	}
+	archive := exportTenantArchive(t, source, sourceAdmin, tenant.ID, "")
+
+	// The connection drops in the middle of the archive
+	lines := importTenantArchive(t, target, targetAdmin, archive[:len(archive)/2])
+	failed := lines[len(lines)-1]
+	require.NotEmpty(t, failed.Error)
+	assert.False(t, failed.Done)
+	require.NotEmpty(t, failed.Cursor)
+
+	// Resume with an export that starts after the cursor
+	resumed := exportTenantArchive(t, source, sourceAdmin, tenant.ID, failed.Cursor)
+	assert.Less(t, len(resumed), len(archive))
+	lines = importTenantArchive(t, target, targetAdmin, resumed)
+	final := lines[len(lines)-1]
+	require.True(t, final.Done, final.Error)
+
+	imported, err := target.authManager.GetTenantByName(ctx, "globex")
+	require.NoError(t, err)
+	var got []string
+	objects, _, err := target.metadataStore.ListObjects(ctx, buildBucketPath(imported.ID, "media"), "", "", 100)
+	require.NoError(t, err)
+	for _, obj := range objects {
+		got = append(got, obj.Key)
+	}
+	sort.Strings(got)
+	assert.Equal(t, keys, got)
+}
+
+func TestTenantTransfer_GlobalAdminsOnly(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	tenantAdmin := &auth.User{ID: "u1", Username: "ops", Roles: []string{"admin"}, TenantID: "tenant-1"}
+	req := httptest.NewRequest("GET", "/api/v1/tenants/tenant-1/export", nil)
+	req = mux.SetURLVars(req.WithContext(context.WithValue(req.Context(), "user", tenantAdmin)), map[string]string{"tenant": "tenant-1"})
+	rr := httptest.NewRecorder()
+	server.handleExportTenant(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	req = httptest.NewRequest("POST", "/api/v1/tenants/import", bytes.NewReader(nil))
+	req = req.WithContext(context.WithValue(req.Context(), "user", tenantAdmin))
+	rr = httptest.NewRecorder()
+	server.handleImportTenant(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/metrics"
+)
+
+// newTenantUsageProvider reports the stored bytes and object count of every
+// tenant, summed over its buckets. Tenants without buckets report zero so
+// their series starts as soon as they exist.
+func (s *Server) newTenantUsageProvider() metrics.TenantUsageProvider {
+	return func(ctx context.Context) ([]metrics.TenantUsageSample, error) {
+		tenants, err := s.authManager.ListTenants(ctx)
+		if err != nil {
+			return nil, err
+		}
+		buckets, err := s.bucketManager.ListBuckets(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+
+		byTenant := make(map[string]*metrics.TenantUsageSample, len(tenants))
+		samples := make([]metrics.TenantUsageSample, len(tenants))
+		for i, t := range tenants {
+			samples[i].TenantID = t.ID
+			byTenant[t.ID] = &samples[i]
+		}
+		for _, b := range buckets {
+			if sample, ok := byTenant[b.TenantID]; ok {
+				sample.BytesStored += b.TotalSize
+				sample.ObjectCount += b.ObjectCount
+			}
+		}
+		return samples, nil
+	}
+}
+
+// tenantUsageMiddleware counts every authenticated S3 request against the
+// caller's tenant for usage reporting.
+func (s *Server) tenantUsageMiddleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			if user, ok := auth.GetUserFromContext(r.Context()); ok {
+				s.usageTracker.RecordRequest(user.TenantID)
+			}
+		})
+	}
+}
+
+// handleGetTenantUsage returns a tenant's bytes-stored and request-count series.
+// GET /tenants/{tenant}/usage?start=&end=&granularity=hourly|daily
+//
+// start and end accept Unix seconds or RFC3339 and default to the last 30
+// days; granularity defaults to daily. Global admins can read any tenant,
+// tenant admins only their own.
+func (s *Server) handleGetTenantUsage(w http.ResponseWriter, r *http.Request) {
+	currentUser := s.getAuthUser(r)
+	if currentUser == nil || !s.isAdmin(currentUser) {
+		s.writeError(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenant"]
+	if !s.isGlobalAdmin(currentUser) && tenantID != currentUser.TenantID {
+		s.writeError(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	if s.usageTracker == nil {
+		s.writeError(w, "Usage reporting is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if _, err := s.authManager.GetTenant(r.Context(), tenantID); err != nil {
+		s.writeError(w, "Tenant not found", http.StatusNotFound)
+		return
+	}
+
+	end := time.Now()
+	start := end.Add(-30 * 24 * time.Hour)
+	var err error
+	if v := r.URL.Query().Get("start"); v != "" {
+		if start, err = parseUsageTime(v); err != nil {
+			s.writeError(w, fmt.Sprintf("Invalid start time format: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("end"); v != "" {
+		if end, err = parseUsageTime(v); err != nil {
+			s.writeError(w, fmt.Sprintf("Invalid end time format: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if end.Before(start) {
+		s.writeError(w, "end must not be before start", http.StatusBadRequest)
+		return
+	}
+
+	granularity := r.URL.Query().Get("granularity")
+	if granularity == "" {
+		granularity = metrics.UsageGranularityDaily
+	}
+	if granularity != metrics.UsageGranularityHourly && granularity != metrics.UsageGranularityDaily {
+		s.writeError(w, "granularity must be hourly or daily", http.StatusBadRequest)
+		return
+	}
+
+	points, err := s.usageTracker.GetTenantUsage(r.Context(), tenantID, start, end, granularity)
+	if err != nil {
+		s.writeError(w, fmt.Sprintf("Failed to get tenant usage: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if points == nil {
+		points = []metrics.UsagePoint{}
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"tenantId":    tenantID,
+		"granularity": granularity,
+		"start":       start.Unix(),
+		"end":         end.Unix(),
+		"points":      points,
+		"count":       len(points),
+	})
+}
+
+// parseUsageTime accepts Unix seconds or RFC3339, like the metrics history endpoint.
+func parseUsageTime(v string) (time.Time, error) {
+	if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tenantUsageRequest(user *auth.User, tenantID, query string) *http.Request {
+	req := httptest.NewRequest("GET", "/api/v1/tenants/"+tenantID+"/usage"+query, nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	return mux.SetURLVars(req, map[string]string{"tenant": tenantID})
+}
+
+func TestHandleGetTenantUsage(t *testing.T) {
+	server, _, cleanup := setupTestServer(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{ID: "usage-tenant", Name: "usage-tenant", Status: "active"}))
+	require.NoError(t, server.authManager.CreateTenant(ctx, &auth.Tenant{ID: "other-tenant", Name: "other-tenant", Status: "active"}))
+
+	history, err := metrics.NewBadgerHistoryStore(server.metadataStore, 365)
+	require.NoError(t, err)
+	server.usageTracker = metrics.NewUsageTracker(history, server.newTenantUsageProvider(), time.Minute)
+
+	// Requests through the middleware are counted against the caller's tenant
+	tenantUser := &auth.User{ID: "u1", TenantID: "usage-tenant", Roles: []string{auth.RoleAdmin}}
+	handler := server.tenantUsageMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/bucket/key", nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(context.WithValue(req.Context(), "user", tenantUser)))
+	}
+	require.NoError(t, server.usageTracker.Sample(ctx))
+
+	now := time.Now().UTC()
+	current, err := history.GetUsagePoint("usage-tenant", now)
+	require.NoError(t, err)
+	require.NotNil(t, current, "sampling must record a point for every tenant")
+	assert.Equal(t, uint64(3), current.Requests)
+
+	// Two days of history: hourly points in UTC
+	day1 := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	var points []metrics.UsagePoint
+	for h := 0; h < 48; h++ {
+		points = append(points, metrics.UsagePoint{
+			Timestamp:   day1.Add(time.Duration(h) * time.Hour),
+			BytesStored: int64(1000 * (h/24 + 1)),
+			ObjectCount: int64(h/24 + 1),
+			Requests:    2,
+		})
+	}
+	require.NoError(t, history.SaveUsagePoints("usage-tenant", points))
+
+	admin, err := server.authManager.ValidateJWT(ctx, getAdminToken(t, server))
+	require.NoError(t, err)
+
+	t.Run("daily series for a date range", func(t *testing.T) {
+		query := fmt.Sprintf("?start=%s&end=%s&granularity=daily", day1.Format(time.RFC3339), day1.Add(48*time.Hour-time.Second).Format(time.RFC3339))
+		rr := httptest.NewRecorder()
+		server.handleGetTenantUsage(rr, tenantUsageRequest(admin, "usage-tenant", query))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var body struct {
+			Data struct {
+				TenantID    string               `json:"tenantId"`
+				Granularity string               `json:"granularity"`
+				Points      []metrics.UsagePoint `json:"points"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		resp := body.Data
+		assert.Equal(t, "usage-tenant", resp.TenantID)
+		assert.Equal(t, "daily", resp.Granularity)
+		require.Len(t, resp.Points, 2)
+		assert.True(t, resp.Points[0].Timestamp.Equal(day1))
+		assert.Equal(t, int64(1000), resp.Points[0].BytesStored)
+		assert.Equal(t, uint64(48), resp.Points[0].Requests)
+		assert.True(t, resp.Points[1].Timestamp.Equal(day1.Add(24*time.Hour)))
+		assert.Equal(t, int64(2000), resp.Points[1].BytesStored)
+		assert.Equal(t, int64(2), resp.Points[1].ObjectCount)
+	})
+
+	t.Run("hourly series", func(t *testing.T) {
+		query := fmt.Sprintf("?start=%d&end=%d&granularity=hourly", day1.Unix(), day1.Add(5*time.Hour).Unix())
+		rr := httptest.NewRecorder()
+		server.handleGetTenantUsage(rr, tenantUsageRequest(tenantUser, "usage-tenant", query))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var body struct {
+			Data struct {
+				Points []metrics.UsagePoint `json:"points"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Len(t, body.Data.Points, 6)
+	})
+
+	t.Run("tenant admin cannot read another tenant", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		server.handleGetTenantUsage(rr, tenantUsageRequest(tenantUser, "other-tenant", ""))
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("invalid granularity", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		server.handleGetTenantUsage(rr, tenantUsageRequest(admin, "usage-tenant", "?granularity=weekly"))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
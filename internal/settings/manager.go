@@ -139,6 +139,30 @@ func (m *Manager) insertDefaults() error {
 			Description: "Require special characters in passwords",
 			Editable:    true,
 		},
+		{
+			Key:         "security.password_require_lowercase",
+			Value:       "false",
+			Type:        string(TypeBool),
+			Category:    string(CategorySecurity),
+			Description: "Require lowercase letters in passwords",
+			Editable:    true,
+		},
+		{
+			Key:         "security.password_block_common",
+			Value:       "false",
+			Type:        string(TypeBool),
+			Category:    string(CategorySecurity),
+			Description: "Reject passwords found in the bundled list of common passwords",
+			Editable:    true,
+		},
+		{
+			Key:         "security.password_max_age_days",
+			Value:       "0",
+			Type:        string(TypeInt),
+			Category:    string(CategorySecurity),
+			Description: "Days after which a local user's password expires and must be changed at next login (0 = never)",
+			Editable:    true,
+		},
 
 		// Audit Settings
 		{
@@ -191,6 +215,22 @@ func (m *Manager) insertDefaults() error {
 			Description: "Default object lock retention period in days",
 			Editable:    true,
 		},
+		{
+			Key:         "storage.event_log_retention_hours",
+			Value:       "24",
+			Type:        string(TypeInt),
+			Category:    string(CategoryStorage),
+			Description: "Hours object events are kept in the bucket event log",
+			Editable:    true,
+		},
+		{
+			Key:         "storage.event_log_max_per_bucket",
+			Value:       "10000",
+			Type:        string(TypeInt),
+			Category:    string(CategoryStorage),
+			Description: "Maximum events kept in each bucket's event log",
+			Editable:    true,
+		},
 		// Metrics Settings
 		{
 			Key:         "metrics.enabled",
@@ -234,6 +274,14 @@ func (m *Manager) insertDefaults() error {
 			Description: "Maximum API requests per second per user",
 			Editable:    true,
 		},
+		{
+			Key:         "security.ratelimit_anonymous_per_second",
+			Value:       "20",
+			Type:        string(TypeInt),
+			Category:    string(CategorySecurity),
+			Description: "Maximum unauthenticated S3 requests per second per client IP (0 = no separate limit)",
+			Editable:    true,
+		},
 
 		// Logging Settings
 		{
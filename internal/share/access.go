@@ -0,0 +1,72 @@
+package share
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxAllowedReferers bounds a share's referer allowlist.
+const maxAllowedReferers = 32
+
+// MaxAccessLogEntries is how many downloads a share's access log keeps.
+// Older entries are dropped as new ones are logged; the share's access count
+// still counts every download.
+const MaxAccessLogEntries = 1000
+
+// Access is one anonymous download of a shared object.
+type Access struct {
+	ShareID    string    `json:"shareId"`
+	IP         string    `json:"ip"`
+	Bytes      int64     `json:"bytes"`
+	Referer    string    `json:"referer,omitempty"`
+	AccessedAt time.Time `json:"accessedAt"`
+}
+
+// NormalizeReferers validates a share's referer allowlist and returns it
+// lower-cased. Each entry is a host name such as "example.com", which matches
+// only that host, or "*.example.com", which matches its subdomains.
+func NormalizeReferers(referers []string) ([]string, error) {
+	if len(referers) > maxAllowedReferers {
+		return nil, fmt.Errorf("%w: at most %d domains", ErrInvalidReferer, maxAllowedReferers)
+	}
+	normalized := make([]string, 0, len(referers))
+	for _, referer := range referers {
+		domain := strings.ToLower(strings.TrimSpace(referer))
+		host := strings.TrimPrefix(domain, "*.")
+		if host == "" || strings.ContainsAny(host, "/:*?#@ ,\t") || strings.HasPrefix(host, ".") || strings.HasSuffix(host, ".") {
+			return nil, fmt.Errorf("%w: %q is not a domain name", ErrInvalidReferer, referer)
+		}
+		normalized = append(normalized, domain)
+	}
+	return normalized, nil
+}
+
+// RefererAllowed reports whether a download with the given Referer and Origin
+// headers may be served. A share without an allowlist serves everyone; one
+// with an allowlist serves only requests whose Referer, or Origin when no
+// Referer was sent, names an allowed host.
+func (s *Share) RefererAllowed(referer, origin string) bool {
+	if len(s.AllowedReferers) == 0 {
+		return true
+	}
+	if referer == "" {
+		referer = origin
+	}
+	u, err := url.Parse(referer)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range s.AllowedReferers {
+		if wildcard, ok := strings.CutPrefix(domain, "*."); ok {
+			if strings.HasSuffix(host, "."+wildcard) {
+				return true
+			}
+		} else if host == domain {
+			return true
+		}
+	}
+	return false
+}
@@ -12,7 +12,7 @@ import (
 
 // Manager handles share operations
 type Manager interface {
-	CreateShare(ctx context.Context, bucketName, objectKey, tenantID, accessKeyID, secretKey, userID string, expiresIn *int64) (*Share, error)
+	CreateShare(ctx context.Context, bucketName, objectKey, tenantID, accessKeyID, secretKey, userID string, expiresIn *int64, overrides ResponseOverrides, allowedReferers []string) (*Share, error)
 	GetShare(ctx context.Context, shareID string) (*Share, error)
 	GetShareByToken(ctx context.Context, shareToken string) (*Share, error)
 	GetShareByObject(ctx context.Context, bucketName, objectKey, tenantID string) (*Share, error)
@@ -20,6 +20,8 @@ type Manager interface {
 	ListBucketShares(ctx context.Context, bucketName, tenantID string) ([]*Share, error)
 	DeleteShare(ctx context.Context, shareID string) error
 	DeleteExpiredShares(ctx context.Context) error
+	RecordAccess(ctx context.Context, access *Access) error
+	ListAccesses(ctx context.Context, shareID string, limit int) ([]*Access, error)
 }
 
 // ShareManager implements Manager interface
@@ -51,8 +53,19 @@ func NewManagerWithDB(dataDir string, encryptionKey string) (Manager, error) {
 	return NewManager(store), nil
 }
 
-// CreateShare creates a new share for an object
-func (m *ShareManager) CreateShare(ctx context.Context, bucketName, objectKey, tenantID, accessKeyID, secretKey, userID string, expiresIn *int64) (*Share, error) {
+// CreateShare creates a new share for an object. overrides replace the
+// object's Content-Disposition and Content-Type when it is served through the
+// share, and are validated first. allowedReferers restricts which sites may
+// link to the share, see Share.RefererAllowed.
+func (m *ShareManager) CreateShare(ctx context.Context, bucketName, objectKey, tenantID, accessKeyID, secretKey, userID string, expiresIn *int64, overrides ResponseOverrides, allowedReferers []string) (*Share, error) {
+	if err := overrides.Validate(); err != nil {
+		return nil, err
+	}
+	referers, err := NormalizeReferers(allowedReferers)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate unique share token
 	token, err := generateShareToken()
 	if err != nil {
@@ -83,6 +96,10 @@ func (m *ShareManager) CreateShare(ctx context.Context, bucketName, objectKey, t
 		ExpiresAt:   expiresAt,
 		CreatedAt:   time.Now().UTC(),
 		CreatedBy:   userID,
+
+		ContentDisposition: overrides.ContentDisposition,
+		ContentType:        overrides.ContentType,
+		AllowedReferers:    referers,
 	}
 
 	if err := m.store.CreateShare(ctx, share); err != nil {
@@ -145,6 +162,19 @@ func (m *ShareManager) DeleteExpiredShares(ctx context.Context) error {
 	return m.store.DeleteExpiredShares(ctx)
 }
 
+// RecordAccess records an anonymous download served through a share
+func (m *ShareManager) RecordAccess(ctx context.Context, access *Access) error {
+	if access.AccessedAt.IsZero() {
+		access.AccessedAt = time.Now().UTC()
+	}
+	return m.store.RecordAccess(ctx, access)
+}
+
+// ListAccesses lists a share's most recent downloads, newest first
+func (m *ShareManager) ListAccesses(ctx context.Context, shareID string, limit int) ([]*Access, error) {
+	return m.store.ListAccesses(ctx, shareID, limit)
+}
+
 // Helper functions
 
 func generateShareToken() (string, error) {
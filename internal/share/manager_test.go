@@ -58,7 +58,7 @@ func TestCreateShare(t *testing.T) {
 	ctx := context.Background()
 
 	expiresIn := int64(3600)
-	share, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	share, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 	assert.NotNil(t, share)
 	assert.NotEmpty(t, share.ID)
@@ -79,7 +79,7 @@ func TestCreateShare_NoExpiration(t *testing.T) {
 	manager := NewManager(store)
 	ctx := context.Background()
 
-	share, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", nil)
+	share, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", nil, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 	assert.NotNil(t, share)
 	assert.Nil(t, share.ExpiresAt)
@@ -97,7 +97,7 @@ func TestGetShare(t *testing.T) {
 
 	// Create share
 	expiresIn := int64(3600)
-	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// Get share
@@ -119,7 +119,7 @@ func TestGetShare_Expired(t *testing.T) {
 
 	// Create share that expires in 1 second
 	expiresIn := int64(1)
-	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// Wait for expiration
@@ -142,7 +142,7 @@ func TestGetShareByToken(t *testing.T) {
 
 	// Create share
 	expiresIn := int64(3600)
-	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// Get by token
@@ -163,7 +163,7 @@ func TestGetShareByObject(t *testing.T) {
 
 	// Create share
 	expiresIn := int64(3600)
-	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// Get by object
@@ -184,11 +184,11 @@ func TestListShares(t *testing.T) {
 
 	// Create shares
 	expiresIn := int64(3600)
-	_, err = manager.CreateShare(ctx, "bucket-1", "key-1", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	_, err = manager.CreateShare(ctx, "bucket-1", "key-1", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
-	_, err = manager.CreateShare(ctx, "bucket-2", "key-2", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	_, err = manager.CreateShare(ctx, "bucket-2", "key-2", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
-	_, err = manager.CreateShare(ctx, "bucket-3", "key-3", "tenant-1", "access-key", "secret-key", "user-2", &expiresIn)
+	_, err = manager.CreateShare(ctx, "bucket-3", "key-3", "tenant-1", "access-key", "secret-key", "user-2", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// List shares for user-1
@@ -214,11 +214,11 @@ func TestListBucketShares(t *testing.T) {
 
 	// Create shares
 	expiresIn := int64(3600)
-	_, err = manager.CreateShare(ctx, "bucket-1", "key-1", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	_, err = manager.CreateShare(ctx, "bucket-1", "key-1", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
-	_, err = manager.CreateShare(ctx, "bucket-1", "key-2", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	_, err = manager.CreateShare(ctx, "bucket-1", "key-2", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
-	_, err = manager.CreateShare(ctx, "bucket-2", "key-3", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	_, err = manager.CreateShare(ctx, "bucket-2", "key-3", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// List shares for bucket-1
@@ -244,7 +244,7 @@ func TestDeleteShare(t *testing.T) {
 
 	// Create share
 	expiresIn := int64(3600)
-	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	created, err := manager.CreateShare(ctx, "test-bucket", "test-key", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// Delete share
@@ -268,12 +268,12 @@ func TestDeleteExpiredShares(t *testing.T) {
 
 	// Create expired share
 	expiresIn := int64(-1)
-	_, err = manager.CreateShare(ctx, "bucket-1", "key-1", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn)
+	_, err = manager.CreateShare(ctx, "bucket-1", "key-1", "tenant-1", "access-key", "secret-key", "user-1", &expiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// Create valid share
 	validExpiresIn := int64(3600)
-	valid, err := manager.CreateShare(ctx, "bucket-2", "key-2", "tenant-1", "access-key", "secret-key", "user-1", &validExpiresIn)
+	valid, err := manager.CreateShare(ctx, "bucket-2", "key-2", "tenant-1", "access-key", "secret-key", "user-1", &validExpiresIn, ResponseOverrides{}, nil)
 	require.NoError(t, err)
 
 	// Delete expired shares
@@ -326,3 +326,187 @@ func TestGenerateID(t *testing.T) {
 	assert.NotEmpty(t, id2)
 	assert.NotEqual(t, id1, id2)
 }
+
+func TestCreateShare_ResponseOverrides(t *testing.T) {
+	db, _ := setupTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, "")
+	require.NoError(t, err)
+
+	manager := NewManager(store)
+	ctx := context.Background()
+
+	overrides := ResponseOverrides{
+		ContentDisposition: `attachment; filename="Report 2024.pdf"`,
+		ContentType:        "application/pdf",
+	}
+	created, err := manager.CreateShare(ctx, "test-bucket", "a1b2c3.pdf", "tenant-1", "access-key", "", "user-1", nil, overrides, nil)
+	require.NoError(t, err)
+	assert.Equal(t, overrides.ContentDisposition, created.ContentDisposition)
+
+	stored, err := manager.GetShareByObject(ctx, "test-bucket", "a1b2c3.pdf", "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, overrides.ContentDisposition, stored.ContentDisposition)
+	assert.Equal(t, overrides.ContentType, stored.ContentType)
+
+	invalid := []ResponseOverrides{
+		{ContentDisposition: "download; filename=x.pdf"},
+		{ContentDisposition: `attachment; filename="unterminated`},
+		{ContentDisposition: "attachment; filename=\"a.pdf\"\r\nSet-Cookie: x=y"},
+		{ContentType: "pdf"},
+		{ContentType: "text/html\nX-Injected: 1"},
+	}
+	for _, o := range invalid {
+		_, err := manager.CreateShare(ctx, "test-bucket", "other.pdf", "tenant-1", "access-key", "", "user-1", nil, o, nil)
+		assert.Error(t, err, "%+v", o)
+	}
+	_, err = manager.GetShareByObject(ctx, "test-bucket", "other.pdf", "tenant-1")
+	assert.ErrorIs(t, err, ErrShareNotFound, "rejected shares are not stored")
+}
+
+// TestSQLiteStore_AddsOverrideColumns tests that a shares table from before
+// response overrides existed gains their columns and keeps its shares
+func TestSQLiteStore_AddsOverrideColumns(t *testing.T) {
+	db, _ := setupTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE shares (
+			id TEXT PRIMARY KEY,
+			bucket_name TEXT NOT NULL,
+			object_key TEXT NOT NULL,
+			tenant_id TEXT DEFAULT '',
+			access_key_id TEXT NOT NULL,
+			secret_key TEXT NOT NULL,
+			share_token TEXT NOT NULL UNIQUE,
+			expires_at INTEGER,
+			created_at INTEGER NOT NULL,
+			created_by TEXT NOT NULL,
+			UNIQUE(bucket_name, object_key, tenant_id)
+		)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO shares (id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, created_at, created_by)
+		VALUES ('old', 'bucket', 'key', 'tenant-1', 'ak', '', 'token', ?, 'user-1')`, time.Now().Unix())
+	require.NoError(t, err)
+
+	store, err := NewSQLiteStore(db, "")
+	require.NoError(t, err)
+
+	old, err := store.GetShare(context.Background(), "old")
+	require.NoError(t, err)
+	assert.Empty(t, old.ContentDisposition)
+	assert.Empty(t, old.ContentType)
+	assert.Empty(t, old.AllowedReferers)
+	assert.Zero(t, old.AccessCount)
+}
+
+func TestShare_RefererAllowed(t *testing.T) {
+	referers, err := NormalizeReferers([]string{"Example.com", "*.cdn.example.org"})
+	require.NoError(t, err)
+	s := &Share{AllowedReferers: referers}
+
+	tests := []struct {
+		referer string
+		origin  string
+		allowed bool
+	}{
+		{"https://example.com/page.html", "", true},
+		{"http://EXAMPLE.com:8080/", "", true},
+		{"https://img.cdn.example.org/a", "", true},
+		{"", "https://example.com", true},
+		{"https://www.example.com/", "", false},
+		{"https://cdn.example.org/", "", false},
+		{"https://example.com.evil.net/", "", false},
+		{"https://evil.net/?u=https://example.com", "", false},
+		{"", "", false},
+		{"not a url", "", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, s.RefererAllowed(tt.referer, tt.origin), "referer=%q origin=%q", tt.referer, tt.origin)
+	}
+
+	assert.True(t, (&Share{}).RefererAllowed("", ""), "a share without an allowlist serves everyone")
+
+	for _, invalid := range []string{"", "https://example.com", "example.com/path", "*.", ".example.com", "a b.com"} {
+		_, err := NormalizeReferers([]string{invalid})
+		assert.ErrorIs(t, err, ErrInvalidReferer, invalid)
+	}
+}
+
+func TestShareAccessLog(t *testing.T) {
+	db, _ := setupTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, "")
+	require.NoError(t, err)
+
+	manager := NewManager(store)
+	ctx := context.Background()
+
+	created, err := manager.CreateShare(ctx, "test-bucket", "report.pdf", "tenant-1", "access-key", "", "user-1", nil, ResponseOverrides{}, []string{"example.com"})
+	require.NoError(t, err)
+
+	stored, err := manager.GetShare(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, stored.AllowedReferers)
+
+	start := time.Now().UTC()
+	require.NoError(t, manager.RecordAccess(ctx, &Access{ShareID: created.ID, IP: "203.0.113.7", Bytes: 1024, Referer: "https://example.com/", AccessedAt: start}))
+	require.NoError(t, manager.RecordAccess(ctx, &Access{ShareID: created.ID, IP: "198.51.100.2", Bytes: 512, AccessedAt: start.Add(time.Second)}))
+
+	stored, err = manager.GetShare(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stored.AccessCount)
+
+	accesses, err := manager.ListAccesses(ctx, created.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, accesses, 2)
+	assert.Equal(t, "198.51.100.2", accesses[0].IP, "newest first")
+	assert.Equal(t, int64(1024), accesses[1].Bytes)
+	assert.Equal(t, "https://example.com/", accesses[1].Referer)
+	assert.WithinDuration(t, start, accesses[1].AccessedAt, time.Millisecond)
+
+	accesses, err = manager.ListAccesses(ctx, created.ID, 1)
+	require.NoError(t, err)
+	assert.Len(t, accesses, 1)
+
+	require.NoError(t, manager.DeleteShare(ctx, created.ID))
+	accesses, err = manager.ListAccesses(ctx, created.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, accesses, "deleting a share drops its access log")
+}
+
+func TestShareAccessLogIsCapped(t *testing.T) {
+	db, _ := setupTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, "")
+	require.NoError(t, err)
+
+	manager := NewManager(store)
+	ctx := context.Background()
+
+	created, err := manager.CreateShare(ctx, "test-bucket", "popular.zip", "tenant-1", "access-key", "", "user-1", nil, ResponseOverrides{}, nil)
+	require.NoError(t, err)
+
+	start := time.Now().UTC()
+	total := MaxAccessLogEntries + 5
+	for i := 0; i < total; i++ {
+		require.NoError(t, manager.RecordAccess(ctx, &Access{ShareID: created.ID, IP: "203.0.113.7", Bytes: int64(i), AccessedAt: start.Add(time.Duration(i) * time.Millisecond)}))
+	}
+
+	stored, err := manager.GetShare(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(total), stored.AccessCount, "every download is still counted")
+
+	var rows int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM share_access_log WHERE share_id = ?`, created.ID).Scan(&rows))
+	assert.Equal(t, MaxAccessLogEntries, rows)
+
+	accesses, err := manager.ListAccesses(ctx, created.ID, total)
+	require.NoError(t, err)
+	require.Len(t, accesses, MaxAccessLogEntries)
+	assert.Equal(t, int64(total-1), accesses[0].Bytes, "newest entry kept")
+	assert.Equal(t, int64(5), accesses[len(accesses)-1].Bytes, "oldest entries dropped")
+}
@@ -0,0 +1,60 @@
+package share
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// maxOverrideLength bounds a header override, well within what proxies and
+// browsers accept for a single header.
+const maxOverrideLength = 1024
+
+// ResponseOverrides replace response headers when an object is served through
+// a share, so a share of a1b2c3.pdf can download as "Report 2024.pdf" or
+// display inline. Empty fields keep the object's own headers.
+type ResponseOverrides struct {
+	ContentDisposition string
+	ContentType        string
+}
+
+// Validate checks that the overrides are well-formed header values: the
+// disposition must be inline or attachment with valid parameters, and the
+// content type a type/subtype media type.
+func (o ResponseOverrides) Validate() error {
+	if o.ContentDisposition != "" {
+		if err := checkHeaderValue(o.ContentDisposition); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidContentDisposition, err)
+		}
+		disposition, _, err := mime.ParseMediaType(o.ContentDisposition)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidContentDisposition, err)
+		}
+		if disposition != "inline" && disposition != "attachment" {
+			return fmt.Errorf("%w: must be inline or attachment, got %q", ErrInvalidContentDisposition, disposition)
+		}
+	}
+	if o.ContentType != "" {
+		if err := checkHeaderValue(o.ContentType); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidContentType, err)
+		}
+		mediaType, _, err := mime.ParseMediaType(o.ContentType)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidContentType, err)
+		}
+		if !strings.Contains(mediaType, "/") {
+			return fmt.Errorf("%w: %q is not a type/subtype media type", ErrInvalidContentType, mediaType)
+		}
+	}
+	return nil
+}
+
+func checkHeaderValue(v string) error {
+	if len(v) > maxOverrideLength {
+		return fmt.Errorf("longer than %d bytes", maxOverrideLength)
+	}
+	if strings.ContainsFunc(v, func(r rune) bool { return r < 0x20 && r != '\t' || r == 0x7f }) {
+		return fmt.Errorf("contains control characters")
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -140,6 +141,49 @@ func (s *SQLiteStore) initialize() error {
 		return err
 	}
 
+	// Tables created before shares could override response headers, restrict
+	// referers or count downloads lack their columns
+	for _, column := range []struct{ name, definition string }{
+		{"content_disposition", "TEXT NOT NULL DEFAULT ''"},
+		{"content_type", "TEXT NOT NULL DEFAULT ''"},
+		{"allowed_referers", "TEXT NOT NULL DEFAULT ''"},
+		{"access_count", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		var exists int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('shares') WHERE name = ?`, column.name).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to inspect shares table: %w", err)
+		}
+		if exists == 0 {
+			if _, err := s.db.Exec(`ALTER TABLE shares ADD COLUMN ` + column.name + ` ` + column.definition); err != nil {
+				return fmt.Errorf("failed to add %s column to shares: %w", column.name, err)
+			}
+		}
+	}
+
+	accessLogSchema := `
+	CREATE TABLE IF NOT EXISTS share_access_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		share_id TEXT NOT NULL,
+		ip TEXT NOT NULL,
+		bytes INTEGER NOT NULL,
+		referer TEXT NOT NULL DEFAULT '',
+		accessed_at INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_share_access_log_share ON share_access_log(share_id, accessed_at);
+	`
+	if _, err := s.db.Exec(accessLogSchema); err != nil {
+		return fmt.Errorf("failed to create share access log table: %w", err)
+	}
+
+	// Shares used to keep a copy of the creator's secret access key, which
+	// serving a share never needs. Drop copies left by older versions.
+	if res, err := s.db.Exec(`UPDATE shares SET secret_key = '' WHERE secret_key != ''`); err != nil {
+		return fmt.Errorf("failed to clear share secret keys: %w", err)
+	} else if n, _ := res.RowsAffected(); n > 0 {
+		logrus.Infof("Removed stored secret keys from %d shares", n)
+	}
+
 	return nil
 }
 
@@ -168,15 +212,19 @@ func (s *SQLiteStore) CreateShare(ctx context.Context, share *Share) error {
 	}
 
 	query := `
-		INSERT INTO shares (id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO shares (id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by, content_disposition, content_type, allowed_referers, access_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0)
 		ON CONFLICT(bucket_name, object_key, tenant_id) DO UPDATE SET
 			access_key_id = excluded.access_key_id,
 			secret_key = excluded.secret_key,
 			share_token = excluded.share_token,
 			expires_at = excluded.expires_at,
 			created_at = excluded.created_at,
-			created_by = excluded.created_by
+			created_by = excluded.created_by,
+			content_disposition = excluded.content_disposition,
+			content_type = excluded.content_type,
+			allowed_referers = excluded.allowed_referers,
+			access_count = 0
 	`
 
 	// Encrypt secret_key before persisting
@@ -196,6 +244,9 @@ func (s *SQLiteStore) CreateShare(ctx context.Context, share *Share) error {
 		expiresAt,
 		share.CreatedAt.Unix(),
 		share.CreatedBy,
+		share.ContentDisposition,
+		share.ContentType,
+		strings.Join(share.AllowedReferers, ","),
 	)
 
 	return err
@@ -204,7 +255,7 @@ func (s *SQLiteStore) CreateShare(ctx context.Context, share *Share) error {
 // GetShare retrieves a share by ID
 func (s *SQLiteStore) GetShare(ctx context.Context, shareID string) (*Share, error) {
 	query := `
-		SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by
+		SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by, content_disposition, content_type, allowed_referers, access_count
 		FROM shares
 		WHERE id = ?
 	`
@@ -216,7 +267,7 @@ func (s *SQLiteStore) GetShare(ctx context.Context, shareID string) (*Share, err
 // GetShareByToken retrieves a share by token
 func (s *SQLiteStore) GetShareByToken(ctx context.Context, shareToken string) (*Share, error) {
 	query := `
-		SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by
+		SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by, content_disposition, content_type, allowed_referers, access_count
 		FROM shares
 		WHERE share_token = ?
 		AND (expires_at IS NULL OR expires_at > ?)
@@ -233,7 +284,7 @@ func (s *SQLiteStore) GetShareByObject(ctx context.Context, bucketName, objectKe
 	var row *sql.Row
 	if tenantID == "" {
 		query := `
-			SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by
+			SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by, content_disposition, content_type, allowed_referers, access_count
 			FROM shares
 			WHERE bucket_name = ? AND object_key = ?
 			AND (expires_at IS NULL OR expires_at > ?)
@@ -242,7 +293,7 @@ func (s *SQLiteStore) GetShareByObject(ctx context.Context, bucketName, objectKe
 		row = s.db.QueryRowContext(ctx, query, bucketName, objectKey, time.Now().UTC().Unix())
 	} else {
 		query := `
-			SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by
+			SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by, content_disposition, content_type, allowed_referers, access_count
 			FROM shares
 			WHERE bucket_name = ? AND object_key = ? AND tenant_id = ?
 			AND (expires_at IS NULL OR expires_at > ?)
@@ -255,7 +306,7 @@ func (s *SQLiteStore) GetShareByObject(ctx context.Context, bucketName, objectKe
 // ListShares lists all shares for a user
 func (s *SQLiteStore) ListShares(ctx context.Context, userID string) ([]*Share, error) {
 	query := `
-		SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by
+		SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by, content_disposition, content_type, allowed_referers, access_count
 		FROM shares
 		WHERE created_by = ?
 		ORDER BY created_at DESC
@@ -282,7 +333,7 @@ func (s *SQLiteStore) ListShares(ctx context.Context, userID string) ([]*Share,
 // ListBucketShares lists all shares for a bucket and tenant
 func (s *SQLiteStore) ListBucketShares(ctx context.Context, bucketName, tenantID string) ([]*Share, error) {
 	query := `
-		SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by
+		SELECT id, bucket_name, object_key, tenant_id, access_key_id, secret_key, share_token, expires_at, created_at, created_by, content_disposition, content_type, allowed_referers, access_count
 		FROM shares
 		WHERE bucket_name = ? AND tenant_id = ?
 		AND (expires_at IS NULL OR expires_at > ?)
@@ -324,16 +375,81 @@ func (s *SQLiteStore) DeleteShare(ctx context.Context, shareID string) error {
 		return ErrShareNotFound
 	}
 
-	return nil
+	_, err = s.db.ExecContext(ctx, `DELETE FROM share_access_log WHERE share_id = ?`, shareID)
+	return err
 }
 
-// DeleteExpiredShares deletes all expired shares
+// DeleteExpiredShares deletes all expired shares and their access logs
 func (s *SQLiteStore) DeleteExpiredShares(ctx context.Context) error {
 	query := `DELETE FROM shares WHERE expires_at IS NOT NULL AND expires_at < ?`
-	_, err := s.db.ExecContext(ctx, query, time.Now().UTC().Unix())
+	if _, err := s.db.ExecContext(ctx, query, time.Now().UTC().Unix()); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `DELETE FROM share_access_log WHERE share_id NOT IN (SELECT id FROM shares)`)
 	return err
 }
 
+// RecordAccess appends a download to a share's access log and counts it.
+// The log is trimmed to the newest MaxAccessLogEntries entries.
+func (s *SQLiteStore) RecordAccess(ctx context.Context, access *Access) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO share_access_log (share_id, ip, bytes, referer, accessed_at) VALUES (?, ?, ?, ?, ?)`,
+		access.ShareID, access.IP, access.Bytes, access.Referer, access.AccessedAt.UnixMilli(),
+	); err != nil {
+		return fmt.Errorf("failed to record share access: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM share_access_log
+		WHERE share_id = ? AND id NOT IN (
+			SELECT id FROM share_access_log
+			WHERE share_id = ?
+			ORDER BY accessed_at DESC, id DESC
+			LIMIT ?
+		)
+	`, access.ShareID, access.ShareID, MaxAccessLogEntries); err != nil {
+		return fmt.Errorf("failed to trim share access log: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE shares SET access_count = access_count + 1 WHERE id = ?`, access.ShareID); err != nil {
+		return fmt.Errorf("failed to count share access: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListAccesses lists up to limit of a share's most recent downloads, newest first
+func (s *SQLiteStore) ListAccesses(ctx context.Context, shareID string, limit int) ([]*Access, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT share_id, ip, bytes, referer, accessed_at
+		FROM share_access_log
+		WHERE share_id = ?
+		ORDER BY accessed_at DESC, id DESC
+		LIMIT ?
+	`, shareID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accesses := []*Access{}
+	for rows.Next() {
+		var access Access
+		var accessedAt int64
+		if err := rows.Scan(&access.ShareID, &access.IP, &access.Bytes, &access.Referer, &accessedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan share access: %w", err)
+		}
+		access.AccessedAt = time.UnixMilli(accessedAt).UTC()
+		accesses = append(accesses, &access)
+	}
+
+	return accesses, rows.Err()
+}
+
 // scanShare scans a share from a database row
 func (s *SQLiteStore) scanShare(scanner interface {
 	Scan(dest ...interface{}) error
@@ -341,6 +457,7 @@ func (s *SQLiteStore) scanShare(scanner interface {
 	var share Share
 	var expiresAt sql.NullInt64
 	var createdAt int64
+	var allowedReferers string
 
 	err := scanner.Scan(
 		&share.ID,
@@ -353,6 +470,10 @@ func (s *SQLiteStore) scanShare(scanner interface {
 		&expiresAt,
 		&createdAt,
 		&share.CreatedBy,
+		&share.ContentDisposition,
+		&share.ContentType,
+		&allowedReferers,
+		&share.AccessCount,
 	)
 
 	if err != nil {
@@ -363,6 +484,9 @@ func (s *SQLiteStore) scanShare(scanner interface {
 	}
 
 	share.CreatedAt = time.Unix(createdAt, 0).UTC()
+	if allowedReferers != "" {
+		share.AllowedReferers = strings.Split(allowedReferers, ",")
+	}
 
 	if expiresAt.Valid {
 		expiry := time.Unix(expiresAt.Int64, 0).UTC()
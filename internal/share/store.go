@@ -14,4 +14,6 @@ type Store interface {
 	ListBucketShares(ctx context.Context, bucketName, tenantID string) ([]*Share, error)
 	DeleteShare(ctx context.Context, shareID string) error
 	DeleteExpiredShares(ctx context.Context) error
+	RecordAccess(ctx context.Context, access *Access) error
+	ListAccesses(ctx context.Context, shareID string, limit int) ([]*Access, error)
 }
@@ -17,11 +17,25 @@ type Share struct {
 	ExpiresAt   *time.Time `json:"expiresAt,omitempty"` // nil = never expires
 	CreatedAt   time.Time `json:"createdAt"`
 	CreatedBy   string    `json:"createdBy"` // User ID
+
+	// Response header overrides applied when the object is served through
+	// the share; empty keeps the object's own value
+	ContentDisposition string `json:"contentDisposition,omitempty"`
+	ContentType        string `json:"contentType,omitempty"`
+
+	// Hosts allowed to link to the share, see RefererAllowed; empty allows
+	// any referer
+	AllowedReferers []string `json:"allowedReferers,omitempty"`
+	// Number of anonymous downloads served through the share
+	AccessCount int64 `json:"accessCount"`
 }
 
 // ShareCreateRequest represents a request to create a share
 type ShareCreateRequest struct {
-	ExpiresIn *int64 `json:"expiresIn"` // seconds, nil = never expires
+	ExpiresIn          *int64 `json:"expiresIn"`                    // seconds, nil = never expires
+	ContentDisposition string `json:"contentDisposition,omitempty"` // e.g. attachment; filename="Report 2024.pdf"
+	ContentType        string `json:"contentType,omitempty"`
+	AllowedReferers    []string `json:"allowedReferers,omitempty"` // e.g. example.com, *.example.com
 }
 
 // ShareResponse represents the response when creating/getting a share
@@ -37,6 +51,11 @@ type ShareResponse struct {
 var (
 	ErrShareNotFound = errors.New("share not found")
 	ErrShareExpired  = errors.New("share has expired")
+
+	ErrInvalidContentDisposition = errors.New("invalid content disposition")
+	ErrInvalidContentType        = errors.New("invalid content type")
+	ErrInvalidReferer            = errors.New("invalid referer domain")
+	ErrRefererNotAllowed         = errors.New("referer not allowed for share")
 )
 
 // IsExpired checks if the share has expired
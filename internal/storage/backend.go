@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
 )
 
 // Backend defines the interface for all storage backends
@@ -14,6 +16,15 @@ type Backend interface {
 	Delete(ctx context.Context, path string) error
 	Exists(ctx context.Context, path string) (bool, error)
 
+	// Stat returns the size, modification time, ETag and metadata of an
+	// object without opening its data.
+	Stat(ctx context.Context, path string) (*ObjectInfo, error)
+
+	// GetRange streams length bytes of an object starting at offset; a
+	// negative length reads to the end. Backends serve it without reading the
+	// bytes before offset.
+	GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+
 	// Listing
 	List(ctx context.Context, prefix string, recursive bool) ([]ObjectInfo, error)
 
@@ -36,11 +47,44 @@ type ObjectInfo struct {
 
 // NewBackend creates a new storage backend based on configuration
 func NewBackend(config Config) (Backend, error) {
+	return NewBackendWithMetadataStore(config, nil)
+}
+
+// NewBackendWithMetadataStore creates a storage backend that may keep object
+// metadata in the given store. Backends that can't keep it next to the data,
+// such as s3, require one.
+func NewBackendWithMetadataStore(config Config, metaStore metadata.RawKVStore) (Backend, error) {
 	switch config.Backend {
 	case "filesystem", "":
 		// Empty string defaults to filesystem
 		return NewFilesystemBackend(config)
+	case "s3":
+		if metaStore == nil {
+			return nil, fmt.Errorf("the s3 storage backend requires a metadata store")
+		}
+		return NewS3Backend(config.S3, metaStore)
 	default:
-		return nil, fmt.Errorf("unsupported storage backend: %s (only 'filesystem' is currently supported)", config.Backend)
+		return nil, ValidateBackend(config.Backend)
 	}
 }
+
+// ValidateBackend reports whether name is a supported storage backend
+func ValidateBackend(name string) error {
+	switch name {
+	case "filesystem", "", "s3":
+		return nil
+	default:
+		return fmt.Errorf("unsupported storage backend: %s (supported: filesystem, s3)", name)
+	}
+}
+
+// limitedReadCloser stops reading after a byte limit and closes the wrapped
+// reader.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func newLimitedReadCloser(rc io.ReadCloser, n int64) io.ReadCloser {
+	return &limitedReadCloser{Reader: io.LimitReader(rc, n), Closer: rc}
+}
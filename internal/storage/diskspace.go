@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// freeSpaceCheckInterval is how long a free-space reading is reused before
+// the disk is asked again, so busy write paths don't statfs on every Put.
+const freeSpaceCheckInterval = time.Second
+
+// ErrInsufficientStorage is returned when a write is refused because free
+// disk space is below the reserved headroom, or failed because the disk is
+// full.
+var ErrInsufficientStorage = NewError("InsufficientStorage", "Not enough free disk space to store the object")
+
+// SpaceStatus is the free-space state of a backend's data disk.
+type SpaceStatus struct {
+	FreeBytes     uint64 `json:"free_bytes"`
+	ReservedBytes uint64 `json:"reserved_bytes"`
+	WriteBlocked  bool   `json:"write_blocked"`
+}
+
+// SpaceReporter is implemented by backends that store data on a local disk.
+type SpaceReporter interface {
+	// CheckFreeSpace returns ErrInsufficientStorage if a write should be
+	// refused because the disk is below its reserved headroom.
+	CheckFreeSpace() error
+
+	// SpaceStatus returns the current free space and whether writes are
+	// blocked.
+	SpaceStatus() SpaceStatus
+}
+
+// IsInsufficientStorage reports whether err means the data disk is out of
+// space: a write refused by the free-space check, or one that failed with
+// ENOSPC or EDQUOT.
+func IsInsufficientStorage(err error) bool {
+	var storageErr *StorageError
+	if errors.As(err, &storageErr) && storageErr.Code == ErrInsufficientStorage.Code {
+		return true
+	}
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EDQUOT)
+}
+
+// diskSpaceGuard refuses writes once free space on the disk holding path
+// drops below the reserved headroom. A write that fails because the disk is
+// full blocks writes until the next reading shows space again.
+type diskSpaceGuard struct {
+	path     string
+	reserved uint64
+	freeFunc func(path string) (uint64, error)
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	free      uint64
+	blocked   bool
+}
+
+func newDiskSpaceGuard(path string, reservedMB int64) *diskSpaceGuard {
+	g := &diskSpaceGuard{path: path, freeFunc: diskFree}
+	if reservedMB > 0 {
+		g.reserved = uint64(reservedMB) * 1024 * 1024
+	}
+	return g
+}
+
+func diskFree(path string) (uint64, error) {
+	usage, err := disk.Usage(path)
+	if err != nil {
+		return 0, err
+	}
+	return usage.Free, nil
+}
+
+// refresh re-reads free space if the last reading is stale. Callers hold mu.
+func (g *diskSpaceGuard) refresh() {
+	if time.Since(g.checkedAt) < freeSpaceCheckInterval {
+		return
+	}
+	free, err := g.freeFunc(g.path)
+	if err != nil {
+		// Can't tell; keep the previous state rather than blocking writes
+		return
+	}
+	g.checkedAt = time.Now()
+	g.free = free
+	g.blocked = free == 0 || free < g.reserved
+}
+
+// check returns ErrInsufficientStorage if writes are blocked.
+func (g *diskSpaceGuard) check() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.refresh()
+	if g.blocked {
+		return ErrInsufficientStorage
+	}
+	return nil
+}
+
+// markFull records a write that failed because the disk is full.
+func (g *diskSpaceGuard) markFull() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.blocked = true
+	g.checkedAt = time.Now()
+}
+
+func (g *diskSpaceGuard) status() SpaceStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.refresh()
+	return SpaceStatus{FreeBytes: g.free, ReservedBytes: g.reserved, WriteBlocked: g.blocked}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diskFullReader returns some data, then fails the way a write to a full
+// disk does.
+type diskFullReader struct {
+	data io.Reader
+}
+
+func (r *diskFullReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	if err == io.EOF {
+		return n, &os.PathError{Op: "write", Path: "object", Err: syscall.ENOSPC}
+	}
+	return n, err
+}
+
+func TestFilesystemBackend_DiskFull(t *testing.T) {
+	ctx := context.Background()
+	backend, tmpDir := createTestBackend(t)
+	defer cleanup(tmpDir)
+
+	free := uint64(10 << 30)
+	backend.space.freeFunc = func(string) (uint64, error) { return free, nil }
+
+	require.NoError(t, backend.Put(ctx, "bucket/existing.txt", strings.NewReader("keep me"), nil))
+
+	t.Run("write error cleans up and blocks writes", func(t *testing.T) {
+		err := backend.Put(ctx, "bucket/existing.txt", &diskFullReader{data: bytes.NewReader(make([]byte, 4096))}, nil)
+		require.Error(t, err)
+		assert.True(t, IsInsufficientStorage(err))
+		assert.True(t, errors.Is(err, syscall.ENOSPC))
+
+		// No temp files are left behind and the previous object is intact
+		err = filepath.Walk(tmpDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && (strings.HasPrefix(info.Name(), ".tmp_") || strings.Contains(info.Name(), "-tmp-") || strings.HasSuffix(info.Name(), metadataStagingSuffix)) {
+				t.Errorf("partial file left behind: %s", path)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		reader, _, err := backend.Get(ctx, "bucket/existing.txt")
+		require.NoError(t, err)
+		data, _ := io.ReadAll(reader)
+		reader.Close()
+		assert.Equal(t, "keep me", string(data))
+
+		status := backend.SpaceStatus()
+		assert.True(t, status.WriteBlocked)
+		assert.Equal(t, ErrInsufficientStorage, backend.Put(ctx, "bucket/next.txt", strings.NewReader("x"), nil))
+
+		// Space is checked again once the reading is stale
+		backend.space.checkedAt = time.Time{}
+		assert.False(t, backend.SpaceStatus().WriteBlocked)
+		assert.NoError(t, backend.Put(ctx, "bucket/next.txt", strings.NewReader("x"), nil))
+	})
+
+	t.Run("reserved headroom refuses writes", func(t *testing.T) {
+		backend.space.reserved = 1 << 30
+		free = 512 << 20
+		backend.space.checkedAt = time.Time{}
+
+		assert.Equal(t, ErrInsufficientStorage, backend.CheckFreeSpace())
+		err := backend.Put(ctx, "bucket/big.bin", strings.NewReader("data"), nil)
+		assert.True(t, IsInsufficientStorage(err))
+		exists, err := backend.Exists(ctx, "bucket/big.bin")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		status := backend.SpaceStatus()
+		assert.Equal(t, uint64(512<<20), status.FreeBytes)
+		assert.Equal(t, uint64(1<<30), status.ReservedBytes)
+		assert.True(t, status.WriteBlocked)
+
+		// Deleting still works so space can be freed
+		assert.NoError(t, backend.Delete(ctx, "bucket/existing.txt"))
+
+		free = 2 << 30
+		backend.space.checkedAt = time.Time{}
+		assert.NoError(t, backend.Put(ctx, "bucket/big.bin", strings.NewReader("data"), nil))
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -30,9 +31,17 @@ const pathLockShards = 256
 
 // FilesystemBackend implements the Backend interface for local filesystem storage
 type FilesystemBackend struct {
-	rootPath  string
-	config    Config
-	pathLocks [pathLockShards]sync.Mutex
+	rootPath   string
+	config     Config
+	shardDepth int
+	pathLocks  [pathLockShards]sync.Mutex
+
+	// flatFallback is set while the root still holds flat-layout files that
+	// have not been moved into shard directories (see filesystem_sharding.go).
+	flatFallback atomic.Bool
+
+	// space refuses writes below the reserved free-space headroom
+	space *diskSpaceGuard
 }
 
 // NewFilesystemBackend creates a new filesystem storage backend
@@ -45,13 +54,42 @@ func NewFilesystemBackend(config Config) (*FilesystemBackend, error) {
 	}
 
 	backend := &FilesystemBackend{
-		rootPath: config.Root,
-		config:   config,
+		rootPath:   config.Root,
+		config:     config,
+		shardDepth: config.ShardDepth,
+		space:      newDiskSpaceGuard(config.Root, config.ReservedFreeMB),
+	}
+	if err := backend.initLayout(); err != nil {
+		return nil, err
 	}
 
 	return backend, nil
 }
 
+// CheckFreeSpace returns ErrInsufficientStorage while free space on the data
+// disk is below the reserved headroom or a write has just failed on a full
+// disk.
+func (fs *FilesystemBackend) CheckFreeSpace() error {
+	return fs.space.check()
+}
+
+// SpaceStatus returns the free space of the data disk and whether writes are
+// blocked.
+func (fs *FilesystemBackend) SpaceStatus() SpaceStatus {
+	return fs.space.status()
+}
+
+// writeError wraps a failed write. A full disk becomes ErrInsufficientStorage
+// and blocks further writes until space is freed.
+func (fs *FilesystemBackend) writeError(code, message string, err error) error {
+	if IsInsufficientStorage(err) {
+		fs.space.markFull()
+		logrus.WithError(err).WithField("root", fs.rootPath).Error("Storage: data disk is full, refusing writes")
+		return NewErrorWithCause(ErrInsufficientStorage.Code, ErrInsufficientStorage.Message, err)
+	}
+	return NewErrorWithCause(code, message, err)
+}
+
 // GetRootPath returns the root path of the filesystem backend
 func (fs *FilesystemBackend) GetRootPath() string {
 	return fs.rootPath
@@ -119,21 +157,28 @@ func (fs *FilesystemBackend) Put(ctx context.Context, path string, data io.Reade
 		return fs.saveMetadata(path, metadata)
 	}
 
+	// Refuse the write up front when the disk is below its reserved headroom
+	if err := fs.space.check(); err != nil {
+		return err
+	}
+
 	// Create directory if it doesn't exist
-	dir := filepath.Dir(fullPath)
+	filePath := fs.getWriteFilePath(path)
+	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0750); err != nil {
 		return NewErrorWithCause("CreateDirectory", "Failed to create directory", err)
 	}
 
 	// IMPORTANT: Create .maxiofs-folder markers in all intermediate directories
 	// This ensures that folders are properly detected even when created implicitly
-	// by S3 clients that upload files directly to nested paths
-	fs.ensureFolderMarkersInPath(dir)
+	// by S3 clients that upload files directly to nested paths. Shard
+	// directories are not folders, so start from the object's own directory.
+	fs.ensureFolderMarkersInPath(filepath.Dir(fullPath))
 
 	// Create temporary file
 	tempFile, err := os.CreateTemp(dir, ".tmp_")
 	if err != nil {
-		return NewErrorWithCause("CreateTempFile", "Failed to create temporary file", err)
+		return fs.writeError("CreateTempFile", "Failed to create temporary file", err)
 	}
 	defer os.Remove(tempFile.Name())
 	defer tempFile.Close()
@@ -144,10 +189,13 @@ func (fs *FilesystemBackend) Put(ctx context.Context, path string, data io.Reade
 
 	size, err := io.Copy(multiWriter, data)
 	if err != nil {
-		return NewErrorWithCause("WriteData", "Failed to write data", err)
+		return fs.writeError("WriteData", "Failed to write data", err)
 	}
 
-	tempFile.Close()
+	// Some filesystems only report a full disk when the file is closed
+	if err := tempFile.Close(); err != nil {
+		return fs.writeError("CloseTempFile", "Failed to close temporary file", err)
+	}
 
 	// Add calculated metadata
 	if metadata == nil {
@@ -175,28 +223,30 @@ func (fs *FilesystemBackend) Put(ctx context.Context, path string, data io.Reade
 	defer unlock()
 	fs.repairStagedCommit(path)
 
-	metadataTempPath, err := fs.prepareMetadataTemp(path, metadata)
+	metadataPath := filePath + ".metadata"
+	metadataTempPath, err := fs.prepareMetadataTemp(metadataPath, metadata)
 	if err != nil {
-		return err
+		return fs.writeError("WriteMetadata", "Failed to write metadata file", err)
 	}
 	defer os.Remove(metadataTempPath)
 
-	stagingPath := fs.getStagingMetadataPath(path)
+	stagingPath := metadataPath + metadataStagingSuffix
 	if err := os.Rename(metadataTempPath, stagingPath); err != nil {
 		return NewErrorWithCause("StageMetadata", "Failed to stage metadata file", err)
 	}
 
-	if err := os.Rename(tempFile.Name(), fullPath); err != nil {
+	if err := os.Rename(tempFile.Name(), filePath); err != nil {
 		os.Remove(stagingPath) // old pair stays fully intact
 		return NewErrorWithCause("AtomicMove", "Failed to move file to final location", err)
 	}
 
-	if err := os.Rename(stagingPath, fs.getMetadataPath(path)); err != nil {
+	if err := os.Rename(stagingPath, metadataPath); err != nil {
 		// Data is committed; leave the stage in place so the read-path repair
 		// rolls the metadata commit forward as soon as the rename can succeed.
 		return NewErrorWithCause("AtomicMetadataMove", "Failed to move metadata file to final location", err)
 	}
 
+	fs.removeFlatCopy(path, filePath)
 	return nil
 }
 
@@ -209,7 +259,7 @@ func (fs *FilesystemBackend) Get(ctx context.Context, path string) (io.ReadClose
 	// Resolve any staged sidecar left by a crashed Put before serving.
 	fs.maybeRepair(path)
 
-	fullPath := fs.getFullPath(path)
+	fullPath := fs.getObjectFilePath(path)
 
 	// Check if file exists
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
@@ -240,7 +290,7 @@ func (fs *FilesystemBackend) Delete(ctx context.Context, path string) error {
 		return err
 	}
 
-	fullPath := fs.getFullPath(path)
+	fullPath := fs.getObjectFilePath(path)
 
 	// Check if file exists
 	info, err := os.Stat(fullPath)
@@ -274,12 +324,14 @@ func (fs *FilesystemBackend) Delete(ctx context.Context, path string) error {
 	}
 
 	// Delete metadata (and any staged sidecar from a crashed Put)
-	metadataPath := fs.getMetadataPath(path)
+	metadataPath := fullPath + ".metadata"
 	if _, err := os.Stat(metadataPath); err == nil {
 		os.Remove(metadataPath) // Ignore errors for metadata cleanup
 	}
-	os.Remove(fs.getStagingMetadataPath(path)) //nolint:errcheck
+	os.Remove(metadataPath + metadataStagingSuffix) //nolint:errcheck
 
+	fs.removeFlatCopy(path, fullPath)
+	fs.pruneShardDirs(path, fullPath)
 	return nil
 }
 
@@ -289,7 +341,7 @@ func (fs *FilesystemBackend) Exists(ctx context.Context, path string) (bool, err
 		return false, err
 	}
 
-	fullPath := fs.getFullPath(path)
+	fullPath := fs.getObjectFilePath(path)
 	_, err := os.Stat(fullPath)
 	if os.IsNotExist(err) {
 		return false, nil
@@ -300,6 +352,61 @@ func (fs *FilesystemBackend) Exists(ctx context.Context, path string) (bool, err
 	return true, nil
 }
 
+// Stat returns object information from the file and its metadata
+func (fs *FilesystemBackend) Stat(ctx context.Context, path string) (*ObjectInfo, error) {
+	if err := fs.validatePath(path); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fs.getObjectFilePath(path))
+	if os.IsNotExist(err) {
+		return nil, ErrObjectNotFound
+	} else if err != nil {
+		return nil, NewErrorWithCause("StatFile", "Failed to stat file", err)
+	}
+
+	metadata, err := fs.GetMetadata(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		Path:         path,
+		Size:         info.Size(),
+		LastModified: info.ModTime().Unix(),
+		ETag:         metadata["etag"],
+		Metadata:     metadata,
+	}, nil
+}
+
+// GetRange opens an object file positioned at offset
+func (fs *FilesystemBackend) GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if err := fs.validatePath(path); err != nil {
+		return nil, err
+	}
+	if offset < 0 {
+		return nil, NewError("InvalidRange", "Range offset must not be negative")
+	}
+
+	fs.maybeRepair(path)
+
+	file, err := os.Open(fs.getObjectFilePath(path))
+	if os.IsNotExist(err) {
+		return nil, ErrObjectNotFound
+	} else if err != nil {
+		return nil, NewErrorWithCause("OpenFile", "Failed to open file", err)
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, NewErrorWithCause("SeekFile", "Failed to seek to range start", err)
+	}
+	if length < 0 {
+		return file, nil
+	}
+	return newLimitedReadCloser(file, length), nil
+}
+
 // List lists objects with the given prefix
 func (fs *FilesystemBackend) List(ctx context.Context, prefix string, recursive bool) ([]ObjectInfo, error) {
 	var objects []ObjectInfo
@@ -324,8 +431,8 @@ func (fs *FilesystemBackend) List(ctx context.Context, prefix string, recursive
 			return nil
 		}
 
-		// Skip MaxIOFS internal folder markers
-		if strings.HasSuffix(path, ".maxiofs-folder") {
+		// Skip MaxIOFS internal folder markers and the layout marker
+		if strings.HasSuffix(path, ".maxiofs-folder") || strings.HasSuffix(path, layoutMarkerName) {
 			return nil
 		}
 
@@ -338,6 +445,11 @@ func (fs *FilesystemBackend) List(ctx context.Context, prefix string, recursive
 		// Convert to forward slashes for consistency
 		relPath = filepath.ToSlash(relPath)
 
+		// Report sharded files under their object path
+		if fs.shardDepth > 0 && !info.IsDir() {
+			relPath, _ = unshardAt(relPath, fs.shardDepth)
+		}
+
 		// Check if it matches prefix
 		if !strings.HasPrefix(relPath, prefix) {
 			return nil
@@ -537,7 +649,7 @@ func (fs *FilesystemBackend) getFullPath(path string) string {
 
 // getMetadataPath returns the path for the metadata file
 func (fs *FilesystemBackend) getMetadataPath(path string) string {
-	return fs.getFullPath(path) + ".metadata"
+	return fs.getObjectFilePath(path) + ".metadata"
 }
 
 // getStagingMetadataPath returns the deterministic staged-sidecar path used by
@@ -593,7 +705,7 @@ func (fs *FilesystemBackend) repairStagedCommit(path string) {
 		return
 	}
 
-	fullPath := fs.getFullPath(path)
+	fullPath := fs.getObjectFilePath(path)
 	info, err := os.Stat(fullPath)
 	if err != nil || info.IsDir() {
 		// No data file to commit against (crash before the data commit of a
@@ -622,7 +734,7 @@ func (fs *FilesystemBackend) repairStagedCommit(path string) {
 	}
 
 	if hex.EncodeToString(hasher.Sum(nil)) == staged["etag"] {
-		if err := os.Rename(stagingPath, fs.getMetadataPath(path)); err != nil {
+		if err := os.Rename(stagingPath, fullPath+".metadata"); err != nil {
 			logrus.WithError(err).WithField("path", path).
 				Error("Staged sidecar roll-forward failed — object stays unreadable until repair succeeds")
 			return
@@ -638,7 +750,7 @@ func (fs *FilesystemBackend) repairStagedCommit(path string) {
 func (fs *FilesystemBackend) saveMetadata(path string, metadata map[string]string) error {
 	metadataPath := fs.getMetadataPath(path)
 
-	tempPath, err := fs.prepareMetadataTemp(path, metadata)
+	tempPath, err := fs.prepareMetadataTemp(metadataPath, metadata)
 	if err != nil {
 		return err
 	}
@@ -651,9 +763,9 @@ func (fs *FilesystemBackend) saveMetadata(path string, metadata map[string]strin
 	return nil
 }
 
-func (fs *FilesystemBackend) prepareMetadataTemp(path string, metadata map[string]string) (string, error) {
-	metadataPath := fs.getMetadataPath(path)
-
+// prepareMetadataTemp writes metadata to a temp file next to metadataPath,
+// ready to be renamed over it.
+func (fs *FilesystemBackend) prepareMetadataTemp(metadataPath string, metadata map[string]string) (string, error) {
 	// Create directory for metadata file
 	dir := filepath.Dir(metadataPath)
 	if err := os.MkdirAll(dir, 0750); err != nil {
@@ -674,9 +786,11 @@ func (fs *FilesystemBackend) prepareMetadataTemp(path string, metadata map[strin
 
 	if _, err := tempFile.Write(data); err != nil {
 		tempFile.Close()
+		os.Remove(tempPath)
 		return "", NewErrorWithCause("WriteMetadata", "Failed to write metadata file", err)
 	}
 	if err := tempFile.Close(); err != nil {
+		os.Remove(tempPath)
 		return "", NewErrorWithCause("CloseMetadataTempFile", "Failed to close temporary metadata file", err)
 	}
 
@@ -685,7 +799,7 @@ func (fs *FilesystemBackend) prepareMetadataTemp(path string, metadata map[strin
 
 // generateBasicMetadata generates basic metadata from file stats
 func (fs *FilesystemBackend) generateBasicMetadata(path string) (map[string]string, error) {
-	fullPath := fs.getFullPath(path)
+	fullPath := fs.getObjectFilePath(path)
 
 	stat, err := os.Stat(fullPath)
 	if err != nil {
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MoveObject renames the object file at src, with its metadata sidecar, to
+// dst without copying the data. Used when the storage path of an object
+// changes, e.g. by the migration to the opaque key layout. Directory markers
+// can't be moved. The sidecar moves first: if the process dies in between,
+// the data file is still found at src and a second MoveObject completes the
+// move.
+func (fs *FilesystemBackend) MoveObject(ctx context.Context, src, dst string) error {
+	if err := fs.validatePath(src); err != nil {
+		return err
+	}
+	if err := fs.validatePath(dst); err != nil {
+		return err
+	}
+	if strings.HasSuffix(src, "/") || strings.HasSuffix(dst, "/") {
+		return NewError("MoveDirectory", "directory markers can't be moved")
+	}
+
+	unlock := fs.lockPath(src)
+	defer unlock()
+
+	from := fs.getObjectFilePath(src)
+	info, err := os.Lstat(from)
+	if os.IsNotExist(err) {
+		return ErrObjectNotFound
+	} else if err != nil {
+		return NewErrorWithCause("StatFile", "Failed to stat file", err)
+	}
+	if info.IsDir() {
+		return NewError("MoveDirectory", "directory markers can't be moved")
+	}
+
+	to := fs.getWriteFilePath(dst)
+	if err := os.MkdirAll(filepath.Dir(to), 0750); err != nil {
+		return NewErrorWithCause("CreateDirectory", "Failed to create directory", err)
+	}
+	for _, suffix := range []string{".metadata", ".metadata" + metadataStagingSuffix} {
+		if err := os.Rename(from+suffix, to+suffix); err != nil && !os.IsNotExist(err) {
+			return NewErrorWithCause("MoveFile", "Failed to move metadata", err)
+		}
+	}
+	if err := os.Rename(from, to); err != nil {
+		return NewErrorWithCause("MoveFile", "Failed to move file", err)
+	}
+
+	fs.pruneShardDirs(src, from)
+	return nil
+}
+
+// CommitObject renames a fully written object at src, with its sidecar, over
+// dst, replacing whatever dst held. It uses Put's two-phase commit: the
+// sidecar is staged next to dst, then the data is renamed into place, then
+// the staged sidecar. A crash before the data rename leaves dst's old pair
+// intact (the stage is discarded by the next access) and one after it is
+// rolled forward, so dst's data is never paired with another sidecar. src
+// must be private to the caller; only dst's path lock is taken.
+func (fs *FilesystemBackend) CommitObject(ctx context.Context, src, dst string) error {
+	if err := fs.validatePath(src); err != nil {
+		return err
+	}
+	if err := fs.validatePath(dst); err != nil {
+		return err
+	}
+	if strings.HasSuffix(src, "/") || strings.HasSuffix(dst, "/") {
+		return NewError("MoveDirectory", "directory markers can't be moved")
+	}
+
+	from := fs.getObjectFilePath(src)
+	if _, err := os.Lstat(from + ".metadata"); os.IsNotExist(err) {
+		return ErrObjectNotFound
+	} else if err != nil {
+		return NewErrorWithCause("StatFile", "Failed to stat metadata", err)
+	}
+
+	unlock := fs.lockPath(dst)
+	defer unlock()
+	fs.repairStagedCommit(dst)
+
+	to := fs.getWriteFilePath(dst)
+	if err := os.MkdirAll(filepath.Dir(to), 0750); err != nil {
+		return NewErrorWithCause("CreateDirectory", "Failed to create directory", err)
+	}
+
+	metadataPath := to + ".metadata"
+	stagingPath := metadataPath + metadataStagingSuffix
+	if err := os.Rename(from+".metadata", stagingPath); err != nil {
+		return NewErrorWithCause("StageMetadata", "Failed to stage metadata file", err)
+	}
+	if err := os.Rename(from, to); err != nil {
+		// dst keeps its old pair; hand the sidecar back to src
+		os.Rename(stagingPath, from+".metadata") //nolint:errcheck
+		return NewErrorWithCause("AtomicMove", "Failed to move file to final location", err)
+	}
+	if err := os.Rename(stagingPath, metadataPath); err != nil {
+		// Data is committed; the read-path repair rolls the stage forward
+		return NewErrorWithCause("AtomicMetadataMove", "Failed to move metadata file to final location", err)
+	}
+
+	fs.removeFlatCopy(dst, to)
+	fs.pruneShardDirs(src, from)
+	return nil
+}
+
+// PruneEmptyDirectories removes the directories below path that hold nothing
+// but folder markers, deepest first, together with the sidecars of the folder
+// objects they stood for. path itself and the opaque object directory are
+// kept. Returns the number of directories removed.
+func (fs *FilesystemBackend) PruneEmptyDirectories(path string) (int, error) {
+	if err := fs.validatePath(path); err != nil {
+		return 0, err
+	}
+	root := fs.getFullPath(path)
+
+	var dirs []string
+	err := filepath.WalkDir(root, func(full string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() || full == root {
+			return nil
+		}
+		if d.Name() == OpaqueObjectDir && filepath.Dir(full) == root {
+			return filepath.SkipDir
+		}
+		dirs = append(dirs, full)
+		return nil
+	})
+	if err != nil {
+		return 0, NewErrorWithCause("WalkDirectory", "Failed to walk directory", err)
+	}
+
+	// Deepest first, so a parent is only looked at once its children are gone
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], string(filepath.Separator)) > strings.Count(dirs[j], string(filepath.Separator))
+	})
+
+	removed := 0
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		onlyMarkers := true
+		for _, e := range entries {
+			if e.Name() != ".maxiofs-folder" {
+				onlyMarkers = false
+				break
+			}
+		}
+		if !onlyMarkers {
+			continue
+		}
+		os.Remove(filepath.Join(dir, ".maxiofs-folder")) //nolint:errcheck
+		if os.Remove(dir) != nil {
+			continue
+		}
+		os.Remove(dir + ".metadata")                         //nolint:errcheck
+		os.Remove(dir + ".metadata" + metadataStagingSuffix) //nolint:errcheck
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,343 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Directory sharding
+//
+// With storage.shard_depth > 0 an object file is not stored directly in its
+// directory but ShardDepth levels below it, in subdirectories named after the
+// bytes of a hash of the file name:
+//
+//	bucket/photos/cat.jpg  →  <root>/bucket/photos/3f/a9/cat.jpg
+//
+// Each level fans out 256 ways, so a bucket with millions of keys in one
+// "folder" keeps every directory at a few hundred entries instead of one
+// directory with millions. The file keeps its real name (the offline recovery
+// rebuilds keys from paths) and only the name is hashed, so given the depth the
+// shard of a file can be verified from its relative path alone (see
+// UnshardPath).
+//
+// Directory markers and MaxIOFS-internal files (.maxiofs-bucket, ...) are never
+// sharded. The depth in use is recorded in <root>/.maxiofs-layout; an existing
+// flat root is moved into the sharded layout by MigrateFlatLayout.
+
+// MaxShardDepth is the largest supported storage.shard_depth. Three levels
+// already give 16.7M leaf directories per directory.
+const MaxShardDepth = 3
+
+// layoutMarkerName is the file at the storage root recording the shard depth
+// the tree was written with.
+const layoutMarkerName = ".maxiofs-layout"
+
+type layoutMarker struct {
+	ShardDepth int `json:"shard_depth"`
+}
+
+// shardDirs returns the depth shard directory names for a file name.
+func shardDirs(name string, depth int) []string {
+	h := fnv.New32a()
+	h.Write([]byte(name)) //nolint:errcheck // fnv Write never fails
+	sum := h.Sum32()
+	dirs := make([]string, depth)
+	for i := range dirs {
+		dirs[i] = fmt.Sprintf("%02x", byte(sum>>(24-8*i)))
+	}
+	return dirs
+}
+
+// isShardable reports whether an object path is stored in a shard directory.
+func isShardable(path string) bool {
+	if strings.HasSuffix(path, "/") {
+		return false
+	}
+	return !strings.HasPrefix(pathpkg.Base(path), ".maxiofs")
+}
+
+// UnshardPath maps a slash-separated file path relative to any directory of
+// the storage tree back to the object path it was stored for, removing the
+// depth shard directories above the file. depth is the one recorded for the
+// tree (see ReadShardDepth); a file that doesn't sit in the directories its
+// name hashes to, and any path of the flat layout, is returned unchanged.
+func UnshardPath(rel string, depth int) string {
+	if depth <= 0 {
+		return rel
+	}
+	path, _ := unshardAt(rel, depth)
+	return path
+}
+
+// unshardAt strips depth shard directories from rel, reporting whether they
+// were the ones its file name hashes to.
+func unshardAt(rel string, depth int) (string, bool) {
+	parts := strings.Split(rel, "/")
+	if len(parts) < depth+1 {
+		return rel, false
+	}
+	dirEnd := len(parts) - 1 - depth
+	name := parts[len(parts)-1]
+	if !inShardDirs(parts[dirEnd:len(parts)-1], name) {
+		return rel, false
+	}
+	return strings.Join(append(parts[:dirEnd:dirEnd], name), "/"), true
+}
+
+func inShardDirs(dirs []string, name string) bool {
+	want := shardDirs(name, len(dirs))
+	for i := range dirs {
+		if dirs[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ShardPath returns the slash-separated path a file of the given object path
+// is stored at with depth shard levels, e.g. "photos/cat.jpg" →
+// "photos/3f/a9/cat.jpg". It is the inverse of UnshardPath.
+func ShardPath(path string, depth int) string {
+	dir, name := pathpkg.Split(path)
+	return dir + strings.Join(append(shardDirs(name, depth), name), "/")
+}
+
+// shardedFilePath returns where path's data file lives in the sharded layout.
+func (fs *FilesystemBackend) shardedFilePath(path string) string {
+	return filepath.Join(fs.rootPath, filepath.FromSlash(ShardPath(path, fs.shardDepth)))
+}
+
+// getWriteFilePath returns the data file a Put of path writes to.
+func (fs *FilesystemBackend) getWriteFilePath(path string) string {
+	if fs.shardDepth == 0 || !isShardable(path) {
+		return fs.getFullPath(path)
+	}
+	return fs.shardedFilePath(path)
+}
+
+// getObjectFilePath returns the data file currently holding path. Until a flat
+// root has been migrated, a file missing from its shard directory is looked up
+// at its flat location too.
+func (fs *FilesystemBackend) getObjectFilePath(path string) string {
+	target := fs.getWriteFilePath(path)
+	if !fs.flatFallback.Load() || target == fs.getFullPath(path) {
+		return target
+	}
+	if _, err := os.Lstat(target); os.IsNotExist(err) {
+		flat := fs.getFullPath(path)
+		if info, err := os.Lstat(flat); err == nil && !info.IsDir() {
+			return flat
+		}
+	}
+	return target
+}
+
+// removeFlatCopy deletes a flat-layout copy of path superseded by a write to
+// its shard directory, so it can't resurface once the new file is deleted.
+func (fs *FilesystemBackend) removeFlatCopy(path, written string) {
+	if !fs.flatFallback.Load() {
+		return
+	}
+	flat := fs.getFullPath(path)
+	if flat == written {
+		return
+	}
+	if info, err := os.Lstat(flat); err != nil || info.IsDir() {
+		return
+	}
+	os.Remove(flat)                                       //nolint:errcheck
+	os.Remove(flat + ".metadata")                         //nolint:errcheck
+	os.Remove(flat + ".metadata" + metadataStagingSuffix) //nolint:errcheck
+}
+
+// pruneShardDirs removes the shard directories above a deleted file once they
+// are empty. os.Remove refuses non-empty directories, so a shard still in use
+// stops the walk.
+func (fs *FilesystemBackend) pruneShardDirs(path, filePath string) {
+	if filePath == fs.getFullPath(path) {
+		return
+	}
+	dir := filepath.Dir(filePath)
+	for i := 0; i < fs.shardDepth; i++ {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// initLayout checks the configured shard depth against the one the root was
+// written with. A root with no layout marker holds the flat layout: if it is
+// empty the marker is simply written, otherwise the backend serves flat files
+// until MigrateFlatLayout has moved them.
+func (fs *FilesystemBackend) initLayout() error {
+	if fs.shardDepth < 0 || fs.shardDepth > MaxShardDepth {
+		return NewError("InvalidShardDepth", fmt.Sprintf("shard depth must be between 0 and %d, got %d", MaxShardDepth, fs.shardDepth))
+	}
+
+	marker, found, err := readLayoutMarker(fs.rootPath)
+	if err != nil {
+		return err
+	}
+	if found {
+		if marker.ShardDepth != fs.shardDepth {
+			return NewError("LayoutMismatch", fmt.Sprintf(
+				"storage root %s was written with shard depth %d; changing storage.shard_depth to %d is not supported",
+				fs.rootPath, marker.ShardDepth, fs.shardDepth))
+		}
+		return nil
+	}
+
+	if fs.shardDepth == 0 {
+		return nil // flat root, flat configuration
+	}
+
+	entries, err := os.ReadDir(fs.rootPath)
+	if err != nil {
+		return NewErrorWithCause("ReadLayout", "Failed to read storage root", err)
+	}
+	if len(entries) == 0 {
+		return fs.writeLayoutMarker()
+	}
+	fs.flatFallback.Store(true)
+	return nil
+}
+
+// readLayoutMarker reads the layout marker of a storage root, reporting
+// whether there is one.
+func readLayoutMarker(root string) (layoutMarker, bool, error) {
+	var marker layoutMarker
+	data, err := os.ReadFile(filepath.Join(root, layoutMarkerName))
+	if os.IsNotExist(err) {
+		return marker, false, nil
+	} else if err != nil {
+		return marker, false, NewErrorWithCause("ReadLayout", "Failed to read storage layout marker", err)
+	}
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return marker, false, NewErrorWithCause("ReadLayout", "Failed to parse storage layout marker", err)
+	}
+	return marker, true, nil
+}
+
+// ReadShardDepth returns the shard depth the storage root was written with, as
+// recorded in its layout marker. A root without a marker holds the flat
+// layout, depth 0: a sharded root only records its depth once any flat files
+// have been migrated (MigrateFlatLayout).
+func ReadShardDepth(root string) (int, error) {
+	marker, _, err := readLayoutMarker(root)
+	return marker.ShardDepth, err
+}
+
+func (fs *FilesystemBackend) writeLayoutMarker() error {
+	data, err := json.Marshal(layoutMarker{ShardDepth: fs.shardDepth})
+	if err != nil {
+		return NewErrorWithCause("WriteLayout", "Failed to marshal storage layout marker", err)
+	}
+	if err := os.WriteFile(filepath.Join(fs.rootPath, layoutMarkerName), data, 0640); err != nil {
+		return NewErrorWithCause("WriteLayout", "Failed to write storage layout marker", err)
+	}
+	return nil
+}
+
+// NeedsLayoutMigration reports whether the root still holds flat-layout files
+// that MigrateFlatLayout should move into shard directories.
+func (fs *FilesystemBackend) NeedsLayoutMigration() bool {
+	return fs.flatFallback.Load()
+}
+
+// MigrateFlatLayout moves every object file of a flat-layout root, with its
+// metadata sidecar, into its shard directory and then records the sharded
+// layout. It is safe to re-run after an interruption: files already in their
+// shard directory are left alone. Objects stay readable throughout, but the
+// migration is meant to run at startup before requests are served. It returns
+// the number of files moved.
+func (fs *FilesystemBackend) MigrateFlatLayout(ctx context.Context) (int, error) {
+	if !fs.flatFallback.Load() {
+		return 0, nil
+	}
+
+	moved := 0
+	err := filepath.WalkDir(fs.rootPath, func(full string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if cErr := ctx.Err(); cErr != nil {
+			return cErr
+		}
+		if d.IsDir() || !d.Type().IsRegular() || isLayoutInternalFile(d.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(fs.rootPath, full)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if _, sharded := unshardAt(rel, fs.shardDepth); sharded || !isShardable(rel) {
+			return nil
+		}
+
+		if err := fs.migrateFlatFile(rel); err != nil {
+			return err
+		}
+		moved++
+		if moved%10000 == 0 {
+			logrus.WithField("moved", moved).Info("Storage layout migration in progress")
+		}
+		return nil
+	})
+	if err != nil {
+		return moved, NewErrorWithCause("MigrateLayout", "Failed to migrate storage layout", err)
+	}
+
+	if err := fs.writeLayoutMarker(); err != nil {
+		return moved, err
+	}
+	fs.flatFallback.Store(false)
+	return moved, nil
+}
+
+// migrateFlatFile moves one flat object file and its sidecars into its shard
+// directory. The sidecar moves first: if the process dies in between, the data
+// file is still found flat on the next run and only it remains to be moved.
+func (fs *FilesystemBackend) migrateFlatFile(path string) error {
+	unlock := fs.lockPath(path)
+	defer unlock()
+
+	flat := fs.getFullPath(path)
+	target := fs.shardedFilePath(path)
+
+	if _, err := os.Lstat(target); err == nil {
+		// Already rewritten in the sharded layout — the flat copy is stale.
+		fs.removeFlatCopy(path, target)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return err
+	}
+	for _, suffix := range []string{".metadata", ".metadata" + metadataStagingSuffix} {
+		if err := os.Rename(flat+suffix, target+suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(flat, target)
+}
+
+// isLayoutInternalFile reports whether a file name is a sidecar, marker or
+// temp file rather than object data.
+func isLayoutInternalFile(name string) bool {
+	return strings.HasSuffix(name, ".metadata") ||
+		strings.HasSuffix(name, ".metadata"+metadataStagingSuffix) ||
+		strings.HasPrefix(name, ".maxiofs") ||
+		strings.HasPrefix(name, ".tmp_") ||
+		strings.HasPrefix(name, ".metadata-tmp-")
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShardedBackend(t *testing.T, root string, depth int) *FilesystemBackend {
+	t.Helper()
+	backend, err := NewFilesystemBackend(Config{Root: root, ShardDepth: depth})
+	require.NoError(t, err)
+	return backend
+}
+
+func readAll(t *testing.T, backend *FilesystemBackend, path string) string {
+	t.Helper()
+	reader, _, err := backend.Get(context.Background(), path)
+	require.NoError(t, err, path)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestShardPath_RoundTrip(t *testing.T) {
+	for _, path := range []string{"bkt/photo.jpg", "tenant/bkt/a/b/c.txt", "bkt/.versions/key/1700000000-abc"} {
+		for depth := 1; depth <= MaxShardDepth; depth++ {
+			sharded := ShardPath(path, depth)
+			assert.NotEqual(t, path, sharded)
+			assert.Equal(t, filepath.Base(path), filepath.Base(sharded))
+			assert.Equal(t, path, UnshardPath(sharded, depth), "depth %d", depth)
+		}
+	}
+
+	// Flat paths whose parent directories don't match the name's hash are
+	// left alone.
+	assert.Equal(t, "bkt/00/00/00/photo.jpg", UnshardPath("bkt/00/00/00/photo.jpg", 3))
+	assert.Equal(t, "photo.jpg", UnshardPath("photo.jpg", 1))
+}
+
+func TestUnshardPath_UsesRecordedDepth(t *testing.T) {
+	// A real folder that happens to be named after its file's shard hash
+	hashDir := shardDirs("app.log", 1)[0]
+	key := "logs/" + hashDir + "/app.log"
+
+	// Flat layout: nothing is stripped
+	assert.Equal(t, key, UnshardPath(key, 0))
+	// Sharded layout: exactly depth levels are stripped
+	assert.Equal(t, key, UnshardPath(ShardPath(key, 1), 1))
+	assert.Equal(t, key, UnshardPath(ShardPath(key, 2), 2))
+}
+
+func TestReadShardDepth(t *testing.T) {
+	flat := t.TempDir()
+	newShardedBackend(t, flat, 0)
+	depth, err := ReadShardDepth(flat)
+	require.NoError(t, err)
+	assert.Equal(t, 0, depth, "a root without a layout marker is flat")
+
+	sharded := t.TempDir()
+	newShardedBackend(t, sharded, 2)
+	depth, err = ReadShardDepth(sharded)
+	require.NoError(t, err)
+	assert.Equal(t, 2, depth)
+
+	require.NoError(t, os.WriteFile(filepath.Join(sharded, layoutMarkerName), []byte("{"), 0640))
+	_, err = ReadShardDepth(sharded)
+	assert.Error(t, err)
+}
+
+func TestShardedLayout_PutGetListDelete(t *testing.T) {
+	root := t.TempDir()
+	backend := newShardedBackend(t, root, 2)
+	ctx := context.Background()
+
+	require.NoError(t, backend.Put(ctx, "bkt/.maxiofs-bucket", bytes.NewReader(nil), nil))
+	require.NoError(t, backend.Put(ctx, "bkt/docs/report.pdf", bytes.NewReader([]byte("report")), map[string]string{"content-type": "application/pdf"}))
+	require.NoError(t, backend.Put(ctx, "bkt/top.txt", bytes.NewReader([]byte("top")), nil))
+	require.NoError(t, backend.Put(ctx, "bkt/folder/", nil, nil))
+
+	// Object files live in shard directories, markers do not
+	assert.FileExists(t, filepath.Join(root, filepath.FromSlash(ShardPath("bkt/docs/report.pdf", 2))))
+	assert.FileExists(t, filepath.Join(root, filepath.FromSlash(ShardPath("bkt/docs/report.pdf", 2)))+".metadata")
+	assert.NoFileExists(t, filepath.Join(root, "bkt", "docs", "report.pdf"))
+	assert.FileExists(t, filepath.Join(root, "bkt", ".maxiofs-bucket"))
+	assert.DirExists(t, filepath.Join(root, "bkt", "folder"))
+
+	assert.Equal(t, "report", readAll(t, backend, "bkt/docs/report.pdf"))
+	meta, err := backend.GetMetadata(ctx, "bkt/docs/report.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", meta["content-type"])
+	exists, err := backend.Exists(ctx, "bkt/top.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	objects, err := backend.List(ctx, "bkt/", true)
+	require.NoError(t, err)
+	var paths []string
+	for _, obj := range objects {
+		paths = append(paths, obj.Path)
+	}
+	sort.Strings(paths)
+	assert.Equal(t, []string{"bkt/.maxiofs-bucket", "bkt/docs/report.pdf", "bkt/top.txt"}, paths)
+
+	// Deleting the last file of a shard removes the emptied shard directories
+	require.NoError(t, backend.Delete(ctx, "bkt/docs/report.pdf"))
+	_, _, err = backend.Get(ctx, "bkt/docs/report.pdf")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+	entries, err := os.ReadDir(filepath.Join(root, "bkt", "docs"))
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.False(t, entry.IsDir(), "shard directory %s left behind", entry.Name())
+	}
+}
+
+func TestShardedLayout_MigrateFlatRoot(t *testing.T) {
+	root := t.TempDir()
+	ctx := context.Background()
+
+	flat := newShardedBackend(t, root, 0)
+	keys := map[string]string{
+		"bkt/a.txt":                     "alpha",
+		"bkt/nested/dir/b.txt":          "bravo",
+		"bkt/.versions/c.txt/170000001": "charlie v1",
+		"tenant/bkt2/d.txt":             "delta",
+		"bkt/overwritten.txt":           "old",
+	}
+	for path, body := range keys {
+		require.NoError(t, flat.Put(ctx, path, bytes.NewReader([]byte(body)), map[string]string{"x-test": body}))
+	}
+	require.NoError(t, flat.Put(ctx, "bkt/.maxiofs-bucket", bytes.NewReader(nil), nil))
+	_, err := os.Stat(filepath.Join(root, layoutMarkerName))
+	assert.True(t, os.IsNotExist(err), "a flat root gets no layout marker")
+
+	backend := newShardedBackend(t, root, 2)
+	require.True(t, backend.NeedsLayoutMigration())
+
+	// Flat files stay readable before the migration, and a rewrite goes to
+	// the shard directory and drops the flat copy.
+	assert.Equal(t, "bravo", readAll(t, backend, "bkt/nested/dir/b.txt"))
+	require.NoError(t, backend.Put(ctx, "bkt/overwritten.txt", bytes.NewReader([]byte("new")), map[string]string{"x-test": "new"}))
+	keys["bkt/overwritten.txt"] = "new"
+	assert.NoFileExists(t, filepath.Join(root, "bkt", "overwritten.txt"))
+
+	moved, err := backend.MigrateFlatLayout(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, moved)
+	assert.False(t, backend.NeedsLayoutMigration())
+
+	for path, body := range keys {
+		assert.NoFileExists(t, filepath.Join(root, filepath.FromSlash(path)), path)
+		assert.FileExists(t, filepath.Join(root, filepath.FromSlash(ShardPath(path, 2))), path)
+		assert.Equal(t, body, readAll(t, backend, path))
+		meta, err := backend.GetMetadata(ctx, path)
+		require.NoError(t, err)
+		assert.Equal(t, body, meta["x-test"], "sidecar of %s must move with it", path)
+	}
+	assert.FileExists(t, filepath.Join(root, "bkt", ".maxiofs-bucket"))
+
+	// A second run is a no-op, and the recorded depth can't be changed
+	moved, err = newShardedBackend(t, root, 2).MigrateFlatLayout(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, moved)
+	_, err = NewFilesystemBackend(Config{Root: root, ShardDepth: 3})
+	assert.Error(t, err)
+	_, err = NewFilesystemBackend(Config{Root: root})
+	assert.Error(t, err)
+}
+
+func TestShardedLayout_InvalidDepth(t *testing.T) {
+	_, err := NewFilesystemBackend(Config{Root: t.TempDir(), ShardDepth: MaxShardDepth + 1})
+	assert.Error(t, err)
+}
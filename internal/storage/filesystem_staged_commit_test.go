@@ -189,3 +189,33 @@ func TestStagedCommit_ListSkipsStagedSidecars(t *testing.T) {
 		assert.NotContains(t, o.Path, ".metadata", "sidecar/staging files must not be listed: %s", o.Path)
 	}
 }
+
+// CommitObject replaces an object with one written elsewhere, sidecar and
+// all, and leaves neither the source nor a stage behind.
+func TestStagedCommit_CommitObjectReplacesDestination(t *testing.T) {
+	backend, _ := newStagedTestBackend(t)
+	ctx := context.Background()
+	src, dst := ".maxiofs/tmp/assembled", "bucket/obj.txt"
+
+	require.NoError(t, backend.Put(ctx, dst, bytes.NewReader([]byte("old data")), map[string]string{"x-old": "yes"}))
+	require.NoError(t, backend.Put(ctx, src, bytes.NewReader([]byte("new data")), map[string]string{"x-new": "yes"}))
+
+	require.NoError(t, backend.CommitObject(ctx, src, dst))
+
+	reader, meta, err := backend.Get(ctx, dst)
+	require.NoError(t, err)
+	defer reader.Close()
+	got, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "new data", string(got))
+	assert.Equal(t, "yes", meta["x-new"])
+	assert.Empty(t, meta["x-old"])
+
+	exists, err := backend.Exists(ctx, src)
+	require.NoError(t, err)
+	assert.False(t, exists, "the source must be moved, not copied")
+	_, err = os.Stat(backend.getStagingMetadataPath(dst))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.ErrorIs(t, backend.CommitObject(ctx, src, dst), ErrObjectNotFound)
+}
@@ -544,3 +544,37 @@ func TestClose(t *testing.T) {
 	err := backend.Close()
 	assert.NoError(t, err)
 }
+
+// TestStatAndGetRange tests reading object information and byte ranges
+func TestStatAndGetRange(t *testing.T) {
+	backend, tmpDir := createTestBackend(t)
+	defer cleanup(tmpDir)
+	ctx := context.Background()
+
+	content := []byte("0123456789abcdef")
+	require.NoError(t, backend.Put(ctx, "bucket/range.txt", bytes.NewReader(content), nil))
+
+	info, err := backend.Stat(ctx, "bucket/range.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, info.Metadata["etag"], info.ETag)
+
+	reader, err := backend.GetRange(ctx, "bucket/range.txt", 4, 6)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "456789", string(data))
+
+	reader, err = backend.GetRange(ctx, "bucket/range.txt", 10, -1)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef", string(data))
+
+	_, err = backend.Stat(ctx, "bucket/missing.txt")
+	assert.Equal(t, ErrObjectNotFound, err)
+	_, err = backend.GetRange(ctx, "bucket/missing.txt", 0, -1)
+	assert.Equal(t, ErrObjectNotFound, err)
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Object key layout
+//
+// By default an object's storage path is built from its key:
+//
+//	bucket/photos/cat.jpg
+//	bucket/.versions/photos/cat.jpg/<versionID>
+//
+// so anyone with access to the disk (or the remote bucket of the s3 backend)
+// can read every key, even though the data itself is encrypted. With
+// storage.object_key_layout: opaque the key never appears in a path: objects
+// are stored under an id derived from the bucket and key with a secret kept in
+// the metadata store, and the key itself only lives in the metadata store:
+//
+//	bucket/.maxiofs-objects/9c/9c41…e2
+//	bucket/.maxiofs-objects/9c/9c41…e2.versions/<versionID>
+//
+// The id is an HMAC rather than a plain hash so that guessable keys (emails,
+// patient ids) can't be confirmed by hashing candidates.
+
+// Object key layouts accepted by storage.object_key_layout.
+const (
+	KeyLayoutPath   = "path"
+	KeyLayoutOpaque = "opaque"
+)
+
+// OpaqueObjectDir is the directory of a bucket holding its object files in
+// the opaque layout.
+const OpaqueObjectDir = ".maxiofs-objects"
+
+// KeyPaths maps object keys to storage paths. A nil *KeyPaths is the path
+// layout, so code that never configures a layout keeps the historical paths.
+type KeyPaths struct {
+	secret []byte
+}
+
+// NewOpaqueKeyPaths returns the opaque layout keyed by secret.
+func NewOpaqueKeyPaths(secret []byte) *KeyPaths {
+	return &KeyPaths{secret: append([]byte(nil), secret...)}
+}
+
+// Opaque reports whether keys are hidden from storage paths.
+func (p *KeyPaths) Opaque() bool {
+	return p != nil
+}
+
+// ObjectPath returns the storage path of the unversioned object key.
+func (p *KeyPaths) ObjectPath(bucket, key string) string {
+	if p == nil {
+		return fmt.Sprintf("%s/%s", bucket, key)
+	}
+	id := p.opaqueID(bucket, key)
+	return fmt.Sprintf("%s/%s/%s/%s", bucket, OpaqueObjectDir, id[:2], id)
+}
+
+// VersionPath returns the storage path of one version of key.
+func (p *KeyPaths) VersionPath(bucket, key, versionID string) string {
+	if p == nil {
+		return fmt.Sprintf("%s/.versions/%s/%s", bucket, key, versionID)
+	}
+	return fmt.Sprintf("%s.versions/%s", p.ObjectPath(bucket, key), versionID)
+}
+
+func (p *KeyPaths) opaqueID(bucket, key string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(bucket)) //nolint:errcheck // hash writes never fail
+	mac.Write([]byte{0})      //nolint:errcheck
+	mac.Write([]byte(key))    //nolint:errcheck
+	return hex.EncodeToString(mac.Sum(nil))
+}
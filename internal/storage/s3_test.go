@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/config"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockS3Server is an in-memory, path-style S3 endpoint serving the subset of
+// the API the s3 backend uses.
+type mockS3Server struct {
+	mu          sync.Mutex
+	objects     map[string][]byte
+	uploads     map[string]map[int][]byte
+	partUploads int
+}
+
+func newMockS3Server(t *testing.T) (*mockS3Server, *httptest.Server) {
+	m := &mockS3Server{
+		objects: make(map[string][]byte),
+		uploads: make(map[string]map[int][]byte),
+	}
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+	return m, srv
+}
+
+func (m *mockS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	key := ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+	q := r.URL.Query()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		m.list(w, q.Get("prefix"), q.Get("delimiter"))
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		uploadID := fmt.Sprintf("upload-%d", len(m.uploads)+1)
+		m.uploads[uploadID] = make(map[int][]byte)
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, key, uploadID)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		var n int
+		fmt.Sscanf(q.Get("partNumber"), "%d", &n)
+		body, _ := io.ReadAll(r.Body)
+		m.uploads[q.Get("uploadId")][n] = body
+		m.partUploads++
+		w.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, n))
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		var req struct {
+			Parts []struct {
+				PartNumber int
+			} `xml:"Part"`
+		}
+		xml.NewDecoder(r.Body).Decode(&req)
+		var data []byte
+		for _, p := range req.Parts {
+			data = append(data, m.uploads[q.Get("uploadId")][p.PartNumber]...)
+		}
+		m.objects[key] = data
+		delete(m.uploads, q.Get("uploadId"))
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><Key>%s</Key><ETag>"multipart"</ETag></CompleteMultipartUploadResult>`, key)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		delete(m.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		m.objects[key] = body
+		w.Header().Set("ETag", `"remote-etag"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := m.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("ETag", `"remote-etag"`)
+		http.ServeContent(w, r, key, time.Unix(1700000000, 0), bytes.NewReader(data))
+	case r.Method == http.MethodDelete:
+		delete(m.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (m *mockS3Server) list(w http.ResponseWriter, prefix, delimiter string) {
+	keys := make([]string, 0, len(m.objects))
+	for k := range m.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(`<ListBucketResult><IsTruncated>false</IsTruncated>`)
+	seen := map[string]bool{}
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(k[len(prefix):], delimiter); i >= 0 {
+				cp := k[:len(prefix)+i+1]
+				if !seen[cp] {
+					seen[cp] = true
+					fmt.Fprintf(&b, `<CommonPrefixes><Prefix>%s</Prefix></CommonPrefixes>`, cp)
+				}
+				continue
+			}
+		}
+		fmt.Fprintf(&b, `<Contents><Key>%s</Key><Size>%d</Size><ETag>"remote-etag"</ETag><LastModified>2023-11-14T22:13:20Z</LastModified></Contents>`, k, len(m.objects[k]))
+	}
+	b.WriteString(`</ListBucketResult>`)
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprint(w, b.String())
+}
+
+func createTestS3Backend(t *testing.T) (*S3Backend, *mockS3Server) {
+	mock, srv := newMockS3Server(t)
+
+	store, err := metadata.NewPebbleStore(metadata.PebbleOptions{DataDir: t.TempDir()})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	backend, err := NewS3Backend(config.S3BackendConfig{
+		Endpoint:  srv.URL,
+		Bucket:    "remote",
+		Prefix:    "gateway",
+		AccessKey: "test",
+		SecretKey: "test",
+	}, store)
+	require.NoError(t, err)
+	return backend, mock
+}
+
+func TestS3Backend_PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	backend, mock := createTestS3Backend(t)
+
+	content := []byte("hello from the gateway")
+	err := backend.Put(ctx, "tenant/bucket/greeting.txt", bytes.NewReader(content), map[string]string{"content-type": "text/plain"})
+	require.NoError(t, err)
+	assert.Contains(t, mock.objects, "gateway/tenant/bucket/greeting.txt", "objects are stored under the configured prefix")
+
+	reader, meta, err := backend.Get(ctx, "tenant/bucket/greeting.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.Equal(t, "text/plain", meta["content-type"])
+	assert.Equal(t, fmt.Sprintf("%d", len(content)), meta["size"])
+	sum := md5.Sum(content)
+	assert.Equal(t, hex.EncodeToString(sum[:]), meta["etag"])
+
+	// Metadata updates stay local and survive reads
+	meta["x-amz-meta-owner"] = "alice"
+	require.NoError(t, backend.SetMetadata(ctx, "tenant/bucket/greeting.txt", meta))
+	got, err := backend.GetMetadata(ctx, "tenant/bucket/greeting.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got["x-amz-meta-owner"])
+
+	exists, err := backend.Exists(ctx, "tenant/bucket/greeting.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, backend.Delete(ctx, "tenant/bucket/greeting.txt"))
+	assert.NotContains(t, mock.objects, "gateway/tenant/bucket/greeting.txt")
+
+	exists, err = backend.Exists(ctx, "tenant/bucket/greeting.txt")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, _, err = backend.Get(ctx, "tenant/bucket/greeting.txt")
+	assert.Equal(t, ErrObjectNotFound, err)
+	assert.Equal(t, ErrObjectNotFound, backend.Delete(ctx, "tenant/bucket/greeting.txt"))
+
+	stored, err := backend.loadMetadata(ctx, "tenant/bucket/greeting.txt")
+	require.NoError(t, err)
+	assert.Nil(t, stored, "local metadata is removed with the object")
+}
+
+func TestS3Backend_LargeObjectUsesMultipart(t *testing.T) {
+	ctx := context.Background()
+	backend, mock := createTestS3Backend(t)
+	backend.partSize = 1024
+
+	content := bytes.Repeat([]byte("0123456789"), 350) // 3.5 parts
+	require.NoError(t, backend.Put(ctx, "bucket/large.bin", io.NopCloser(bytes.NewReader(content)), nil))
+
+	assert.Equal(t, 4, mock.partUploads)
+	assert.Empty(t, mock.uploads, "upload is completed")
+	assert.Equal(t, content, mock.objects["gateway/bucket/large.bin"])
+
+	info, err := backend.Stat(ctx, "bucket/large.bin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, info.Metadata["etag"], info.ETag)
+
+	reader, err := backend.GetRange(ctx, "bucket/large.bin", 1020, 10)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content[1020:1030], data)
+
+	reader, err = backend.GetRange(ctx, "bucket/large.bin", 3400, -1)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content[3400:], data)
+}
+
+func TestS3Backend_List(t *testing.T) {
+	ctx := context.Background()
+	backend, _ := createTestS3Backend(t)
+
+	require.NoError(t, backend.Put(ctx, "bucket/a.txt", strings.NewReader("a"), nil))
+	require.NoError(t, backend.Put(ctx, "bucket/docs/b.txt", strings.NewReader("bb"), nil))
+	require.NoError(t, backend.Put(ctx, "bucket/folder/", nil, nil))
+
+	objects, err := backend.List(ctx, "bucket/", true)
+	require.NoError(t, err)
+	var paths []string
+	for _, o := range objects {
+		paths = append(paths, o.Path)
+	}
+	assert.ElementsMatch(t, []string{"bucket/a.txt", "bucket/docs/b.txt", "bucket/folder/"}, paths)
+
+	// Non-recursive: implicit folders (docs/) are not objects, explicit ones are
+	objects, err = backend.List(ctx, "bucket/", false)
+	require.NoError(t, err)
+	paths = nil
+	for _, o := range objects {
+		paths = append(paths, o.Path)
+		if o.Path == "bucket/a.txt" {
+			assert.Equal(t, int64(1), o.Size)
+			assert.Equal(t, o.Metadata["etag"], o.ETag)
+		}
+	}
+	assert.ElementsMatch(t, []string{"bucket/a.txt", "bucket/folder/"}, paths)
+}
+
+func TestNewBackend_S3RequiresMetadataStore(t *testing.T) {
+	_, err := NewBackend(config.StorageConfig{
+		Backend: "s3",
+		S3:      config.S3BackendConfig{Endpoint: "http://127.0.0.1:1", Bucket: "remote"},
+	})
+	assert.Error(t, err)
+}
@@ -3,7 +3,11 @@ package storage
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 )
 
@@ -308,6 +312,79 @@ func BenchmarkConcurrentGets(b *testing.B) {
 	})
 }
 
+// BenchmarkPutGet_BucketObjectCount measures PUT+GET latency of a 10KB object
+// in a bucket that already holds N objects in the same directory, for the flat
+// and the sharded layout. With the flat layout every object of the bucket is
+// one entry of a single directory; sharded, each directory stays small.
+//
+// Counts above MAXIOFS_BENCH_MAX_OBJECTS (default 100000) are skipped, since
+// populating millions of files takes minutes and several GB of inodes:
+//
+//	MAXIOFS_BENCH_MAX_OBJECTS=2000000 go test -run '^$' \
+//	  -bench BucketObjectCount -benchtime 2000x ./internal/storage/
+func BenchmarkPutGet_BucketObjectCount(b *testing.B) {
+	maxObjects := 100000
+	if v := os.Getenv("MAXIOFS_BENCH_MAX_OBJECTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			b.Fatalf("MAXIOFS_BENCH_MAX_OBJECTS: %v", err)
+		}
+		maxObjects = n
+	}
+
+	data := bytes.Repeat([]byte("a"), 10*1024)
+	ctx := context.Background()
+
+	for _, depth := range []int{0, 2} {
+		backend, err := NewFilesystemBackend(Config{Root: b.TempDir(), ShardDepth: depth})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		populated := 0
+		for _, count := range []int{10000, 100000, 1000000, 2000000} {
+			name := fmt.Sprintf("depth=%d/objects=%d", depth, count)
+			if count > maxObjects {
+				b.Run(name, func(b *testing.B) { b.Skip("raise MAXIOFS_BENCH_MAX_OBJECTS to run") })
+				continue
+			}
+
+			// Existing objects only need to occupy directory entries, so they
+			// are created directly instead of through Put.
+			dirs := make(map[string]bool)
+			for ; populated < count; populated++ {
+				filePath := backend.getWriteFilePath(fmt.Sprintf("bkt/existing-%08d", populated))
+				if dir := filepath.Dir(filePath); !dirs[dir] {
+					if err := os.MkdirAll(dir, 0750); err != nil {
+						b.Fatal(err)
+					}
+					dirs[dir] = true
+				}
+				if err := os.WriteFile(filePath, nil, 0640); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.Run(name, func(b *testing.B) {
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					path := fmt.Sprintf("bkt/new-%d-%08d", count, i)
+					if err := backend.Put(ctx, path, bytes.NewReader(data), nil); err != nil {
+						b.Fatal(err)
+					}
+					reader, _, err := backend.Get(ctx, path)
+					if err != nil {
+						b.Fatal(err)
+					}
+					io.Copy(io.Discard, reader)
+					reader.Close()
+				}
+			})
+		}
+	}
+}
+
 // setupBenchBackend creates a temporary filesystem backend for benchmarking
 func setupBenchBackend(b *testing.B) (Backend, func()) {
 	tmpDir := b.TempDir()
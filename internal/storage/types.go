@@ -28,6 +28,11 @@ func (e *StorageError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying cause
+func (e *StorageError) Unwrap() error {
+	return e.Cause
+}
+
 // NewError creates a new storage error
 func NewError(code, message string) *StorageError {
 	return &StorageError{
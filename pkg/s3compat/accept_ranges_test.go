@@ -0,0 +1,92 @@
+package s3compat
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptRangesAdvertised(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "ranges-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	body := []byte("0123456789abcdef")
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/movie.bin", body)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	t.Run("HEAD reports ranges and the full length", func(t *testing.T) {
+		req, w := env.makeS3Request("HEAD", "/"+bucketName+"/movie.bin", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+		assert.Equal(t, "16", w.Header().Get("Content-Length"))
+	})
+
+	t.Run("GET reports ranges", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/movie.bin", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+	})
+
+	t.Run("virtual SOSAPI objects don't", func(t *testing.T) {
+		req, w := env.makeS3Request("HEAD", "/"+bucketName+"/"+systemXMLObject, nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Accept-Ranges"))
+	})
+}
+
+func TestAcceptRangesGzipTranscoding(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	env.handler.SetGzipTranscoding(true)
+
+	bucketName := "ranges-gzip"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write(bytes.Repeat([]byte("log line\n"), 1000))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/app.log", compressed.Bytes())
+	req.Header.Set("Content-Encoding", "gzip")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	for _, method := range []string{"GET", "HEAD"} {
+		req, w := env.makeS3Request(method, "/"+bucketName+"/app.log", nil)
+		req.Header.Set("Accept-Encoding", "identity")
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, method)
+		assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"), "%s: decoded length is readable", method)
+	}
+
+	// Decoded forms whose length can't be read from the trailer are served
+	// whole, so they must not advertise ranges
+	assert.True(t, gzipRangeable(&object.Object{Size: int64(compressed.Len())}))
+	assert.False(t, gzipRangeable(&object.Object{Size: 1 << 32}))
+	assert.False(t, gzipRangeable(&object.Object{Size: 10}))
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Accept-Ranges", "bytes")
+	rec.Header().Set("Content-Encoding", "gzip")
+	setGzipTranscodedHeaders(rec, false)
+	assert.Empty(t, rec.Header().Get("Accept-Ranges"))
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+	rec = httptest.NewRecorder()
+	rec.Header().Set("Accept-Ranges", "bytes")
+	setGzipTranscodedHeaders(rec, true)
+	assert.Equal(t, "bytes", rec.Header().Get("Accept-Ranges"))
+}
@@ -0,0 +1,45 @@
+package s3compat
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3PutObjectWriteOffset(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "append-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+
+	appendAt := func(offset, body string) *http.Response {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/device.log", []byte(body))
+		req.Header.Set("x-amz-write-offset-bytes", offset)
+		env.router.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	require.Equal(t, http.StatusOK, appendAt("0", "hello").StatusCode)
+	resp := appendAt("5", " world")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("ETag"))
+
+	// Another writer still at offset 5 conflicts
+	resp = appendAt("5", " again")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "InvalidWriteOffset")
+
+	assert.Equal(t, http.StatusBadRequest, appendAt("-1", "x").StatusCode)
+
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/device.log", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello world", w.Body.String())
+}
@@ -2,7 +2,6 @@ package s3compat
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"io"
 	"strconv"
@@ -13,11 +12,13 @@ import (
 
 // AwsChunkedReader decodes AWS chunked encoding format
 // Format: {chunk-size-hex}\r\n{chunk-data}\r\n...0\r\n{trailers}\r\n
+// Chunk data is streamed straight to the caller, so memory stays bounded by
+// the read buffer no matter how large a chunk the client declares.
 type AwsChunkedReader struct {
-	reader  *bufio.Reader
-	buffer  bytes.Buffer
-	eof     bool
-	decoded int64
+	reader    *bufio.Reader
+	remaining int64 // bytes of the current chunk not yet read
+	eof       bool
+	decoded   int64
 }
 
 // NewAwsChunkedReader creates a new AWS chunked encoding reader
@@ -29,27 +30,38 @@ func NewAwsChunkedReader(r io.Reader) *AwsChunkedReader {
 
 // Read implements io.Reader, decoding AWS chunked format
 func (r *AwsChunkedReader) Read(p []byte) (n int, err error) {
-	if r.eof && r.buffer.Len() == 0 {
-		return 0, io.EOF
+	if len(p) == 0 {
+		return 0, nil
 	}
-
-	// If we have buffered data, return it first
-	if r.buffer.Len() > 0 {
-		return r.buffer.Read(p)
-	}
-
-	// Read next chunk
-	if err := r.readNextChunk(); err != nil {
-		if err == io.EOF {
-			r.eof = true
+	for r.remaining == 0 {
+		if r.eof {
+			return 0, io.EOF
 		}
-		if r.buffer.Len() > 0 {
-			return r.buffer.Read(p)
+		if err := r.readNextChunk(); err != nil {
+			if err == io.EOF {
+				r.eof = true
+			}
+			return 0, err
 		}
-		return 0, err
 	}
 
-	return r.buffer.Read(p)
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err = r.reader.Read(p)
+	r.remaining -= int64(n)
+	r.decoded += int64(n)
+	if r.remaining == 0 {
+		// Consume the \r\n that ends the chunk data
+		if trailErr := r.readChunkEnd(); trailErr != nil {
+			return n, trailErr
+		}
+		return n, nil
+	}
+	if err == io.EOF {
+		err = fmt.Errorf("failed to read chunk data: %w", io.ErrUnexpectedEOF)
+	}
+	return n, err
 }
 
 // readNextChunk reads and decodes the next chunk from aws-chunked format
@@ -106,20 +118,24 @@ func (r *AwsChunkedReader) readNextChunk() error {
 		return io.EOF
 	}
 
-	// Read chunk data
-	chunkData := make([]byte, chunkSize)
-	_, err = io.ReadFull(r.reader, chunkData)
-	if err != nil {
-		return fmt.Errorf("failed to read chunk data: %w", err)
+	if chunkSize < 0 {
+		return fmt.Errorf("invalid chunk size: %s", sizeLine)
 	}
 
-	// Write decoded chunk to buffer
-	r.buffer.Write(chunkData)
-	r.decoded += chunkSize
+	// The chunk data itself is handed out by Read
+	r.remaining = chunkSize
+	return nil
+}
 
-	// Read trailing \r\n after chunk data
+// readChunkEnd reads the trailing \r\n after a chunk's data
+func (r *AwsChunkedReader) readChunkEnd() error {
 	trailing, err := r.reader.ReadString('\n')
 	if err != nil {
+		if err == io.EOF {
+			// Body ended without the terminating chunk; treat it as the end
+			r.eof = true
+			return nil
+		}
 		return err
 	}
 
@@ -0,0 +1,87 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAwsChunkedReader_LargeChunkBoundedMemory decodes a single 64 MiB chunk
+// and checks it is streamed rather than held in memory at once.
+func TestAwsChunkedReader_LargeChunkBoundedMemory(t *testing.T) {
+	const chunkSize = 64 * 1024 * 1024
+	input := io.MultiReader(
+		strings.NewReader(strconv.FormatInt(chunkSize, 16)+"\r\n"),
+		io.LimitReader(zeroReader{}, chunkSize),
+		strings.NewReader("\r\n0\r\nx-amz-checksum-crc32:AAAAAA==\r\n\r\n"),
+	)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	n, err := io.Copy(io.Discard, NewAwsChunkedReader(input))
+	runtime.ReadMemStats(&after)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(chunkSize), n)
+	allocated := after.TotalAlloc - before.TotalAlloc
+	assert.Less(t, allocated, uint64(4*1024*1024), "decoding allocated %d bytes for a %d byte chunk", allocated, chunkSize)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// TestPutObject_LargeUnsignedChunkedPayload uploads an aws-chunked body made
+// of one large chunk, as streaming clients send it, and checks the object
+// round-trips and no spooled upload is left behind.
+func TestPutObject_LargeUnsignedChunkedPayload(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "chunked-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 512*1024) // 8 MiB
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "%x\r\n", len(payload))
+	body.Write(payload)
+	body.WriteString("\r\n0\r\n\r\n")
+
+	req := httptest.NewRequest("PUT", "/"+bucketName+"/stream.bin", &body)
+	req.Host = "localhost"
+	req.Header.Set("Content-Encoding", "aws-chunked")
+	req.Header.Set("X-Amz-Decoded-Content-Length", strconv.Itoa(len(payload)))
+	signRequestV4(req, env.accessKey, env.secretKey, "us-east-1", "s3")
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/stream.bin", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, bytes.Equal(payload, w.Body.Bytes()), "downloaded object differs from the decoded payload")
+
+	err := filepath.Walk(env.tempDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.HasPrefix(info.Name(), "maxiofs-upload-") {
+			t.Errorf("spooled upload left behind: %s", path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
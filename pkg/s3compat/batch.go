@@ -1,12 +1,16 @@
 package s3compat
 
 import (
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/cluster"
 	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/sirupsen/logrus"
 )
@@ -68,6 +72,10 @@ func (h *Handler) DeleteObjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
+	if err := object.VerifyContentMD5(r.Header.Get("Content-MD5"), body); err != nil {
+		h.writeDigestError(w, r, err, r.URL.Path)
+		return
+	}
 
 	var deleteRequest DeleteObjectsRequest
 	if err := xml.Unmarshal(body, &deleteRequest); err != nil {
@@ -91,75 +99,49 @@ func (h *Handler) DeleteObjects(w http.ResponseWriter, r *http.Request) {
 		"object_count": len(deleteRequest.Objects),
 	}).Debug("Batch delete request received")
 
-	// Process deletions sequentially to avoid concurrent metadata update conflicts.
-	// This is more reliable than parallel processing with retries, especially under high load
-	result := DeleteObjectsResult{
-		Deleted: []DeletedObject{},
-		Errors:  []DeleteError{},
-	}
-
 	ctx := r.Context()
 
-	// Process each object deletion sequentially
+	// MFA Delete: a batch that permanently deletes versions needs a valid
+	// x-amz-mfa code for the whole request
 	for _, obj := range deleteRequest.Objects {
-		if obj.Key == "" {
-			result.Errors = append(result.Errors, DeleteError{
-				Key:     obj.Key,
-				Code:    "InvalidArgument",
-				Message: "Object key cannot be empty",
-			})
-			continue
-		}
-
-		// Delete object with optional version ID.
-		// Batch delete doesn't support bypass governance.
-		var deleteMarkerVersionID string
-		var err error
 		if obj.VersionId != "" {
-			deleteMarkerVersionID, err = h.objectManager.DeleteObject(ctx, bucketPath, obj.Key, false, obj.VersionId)
-		} else {
-			deleteMarkerVersionID, err = h.objectManager.DeleteObject(ctx, bucketPath, obj.Key, false)
+			if !h.requireMFADelete(w, r, h.resolveBucketTenantID(r, bucketName), bucketName, bucketName) {
+				return
+			}
+			break
 		}
+	}
 
-		if err != nil {
-			// Log error but continue with other objects
-			if err == object.ErrObjectNotFound {
-				// S3 spec: DELETE on non-existent object should return success
-				if !deleteRequest.Quiet {
-					result.Deleted = append(result.Deleted, DeletedObject{
-						Key:       obj.Key,
-						VersionId: obj.VersionId,
-					})
-				}
-			} else {
-				// Log the error for debugging
-				logrus.WithError(err).WithFields(logrus.Fields{
-					"bucket": bucketName,
-					"key":    obj.Key,
-				}).Warn("Failed to delete object in batch operation")
-
-				result.Errors = append(result.Errors, DeleteError{
-					Key:       obj.Key,
-					Code:      "InternalError",
-					Message:   err.Error(),
-					VersionId: obj.VersionId,
-				})
-			}
-		} else {
-			// Success - add to deleted list if not quiet mode.
-			// When a delete marker was created (versioned bucket, no VersionId specified),
-			// include DeleteMarker and DeleteMarkerVersionId per S3 spec.
-			if !deleteRequest.Quiet {
-				deleted := DeletedObject{
-					Key:       obj.Key,
-					VersionId: obj.VersionId,
-				}
-				if deleteMarkerVersionID != "" {
-					deleted.DeleteMarker = true
-					deleted.DeleteMarkerVersionId = deleteMarkerVersionID
-				}
-				result.Deleted = append(result.Deleted, deleted)
+	// Delete the keys in parallel; each outcome lands in its request slot so
+	// the response lists keys in request order whatever order they finish in.
+	outcomes := make([]batchDeleteOutcome, len(deleteRequest.Objects))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(h.batchDeleteWorkers, len(deleteRequest.Objects)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				outcomes[idx] = h.deleteBatchObject(ctx, bucketName, bucketPath, deleteRequest.Objects[idx])
 			}
+		}()
+	}
+	for idx := range deleteRequest.Objects {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := DeleteObjectsResult{
+		Deleted: []DeletedObject{},
+		Errors:  []DeleteError{},
+	}
+	for _, outcome := range outcomes {
+		switch {
+		case outcome.err != nil:
+			result.Errors = append(result.Errors, *outcome.err)
+		case !deleteRequest.Quiet:
+			result.Deleted = append(result.Deleted, outcome.deleted)
 		}
 	}
 
@@ -178,3 +160,80 @@ func (h *Handler) DeleteObjects(w http.ResponseWriter, r *http.Request) {
 
 	logrus.Debugf("Batch delete completed: %d deleted, %d errors", len(result.Deleted), len(result.Errors))
 }
+
+// defaultBatchDeleteWorkers is how many keys a DeleteObjects request deletes
+// at once. Deletes of different keys only contend on the bucket's metrics
+// update, which the metadata store serializes per bucket.
+const defaultBatchDeleteWorkers = 16
+
+// batchDeleteOutcome is the result for one key of a DeleteObjects request:
+// err is set when it failed, deleted otherwise.
+type batchDeleteOutcome struct {
+	deleted DeletedObject
+	err     *DeleteError
+}
+
+// deleteBatchObject deletes one key of a DeleteObjects request. Object Lock
+// is checked per key by the object manager; batch deletes never bypass
+// governance retention.
+func (h *Handler) deleteBatchObject(ctx context.Context, bucketName, bucketPath string, obj ObjectToDelete) batchDeleteOutcome {
+	if obj.Key == "" {
+		return batchDeleteOutcome{err: &DeleteError{
+			Key:     obj.Key,
+			Code:    "InvalidArgument",
+			Message: "Object key cannot be empty",
+		}}
+	}
+
+	var deleteMarkerVersionID string
+	var err error
+	if obj.VersionId != "" {
+		deleteMarkerVersionID, err = h.objectManager.DeleteObject(ctx, bucketPath, obj.Key, false, obj.VersionId)
+	} else {
+		deleteMarkerVersionID, err = h.objectManager.DeleteObject(ctx, bucketPath, obj.Key, false)
+	}
+
+	deleted := DeletedObject{Key: obj.Key, VersionId: obj.VersionId}
+	switch {
+	case err == object.ErrObjectNotFound:
+		// S3 spec: DELETE on non-existent object should return success
+		return batchDeleteOutcome{deleted: deleted}
+	case err != nil:
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"bucket": bucketName,
+			"key":    obj.Key,
+		}).Warn("Failed to delete object in batch operation")
+		code, message := batchDeleteErrorCode(err)
+		return batchDeleteOutcome{err: &DeleteError{
+			Key:       obj.Key,
+			Code:      code,
+			Message:   message,
+			VersionId: obj.VersionId,
+		}}
+	}
+
+	// When a delete marker was created (versioned bucket, no VersionId specified),
+	// include DeleteMarker and DeleteMarkerVersionId per S3 spec.
+	if deleteMarkerVersionID != "" {
+		deleted.DeleteMarker = true
+		deleted.DeleteMarkerVersionId = deleteMarkerVersionID
+	}
+	return batchDeleteOutcome{deleted: deleted}
+}
+
+// batchDeleteErrorCode maps a delete failure to the S3 error code reported
+// for that key, mirroring what DeleteObject answers for a single key.
+func batchDeleteErrorCode(err error) (code, message string) {
+	var retErr *object.RetentionError
+	switch {
+	case errors.As(err, &retErr):
+		return "AccessDenied", retErr.Error()
+	case errors.Is(err, object.ErrObjectUnderLegalHold):
+		return "AccessDenied", "Object is under legal hold and cannot be deleted"
+	case errors.Is(err, cluster.ErrClusterDegraded):
+		return "ServiceUnavailable", "Cluster degraded — replication quorum unavailable, retry later"
+	case errors.Is(err, object.ErrBucketNotFound):
+		return "NoSuchBucket", "The specified bucket does not exist"
+	}
+	return "InternalError", err.Error()
+}
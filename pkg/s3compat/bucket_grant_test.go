@@ -0,0 +1,124 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scopedGrantManager is the subset of the auth manager used to grant and revoke
+// access to a bucket owned by a specific tenant.
+type scopedGrantManager interface {
+	GrantBucketAccessScoped(ctx context.Context, bucketName, bucketTenantID, userID, tenantID, permissionLevel, grantedBy string, expiresAt int64) error
+	RevokeBucketAccessScoped(ctx context.Context, bucketName, bucketTenantID, userID, tenantID string) error
+}
+
+func setupBucketGrantEnv(t *testing.T) (env *s3TestEnv, grants scopedGrantManager, bucketName, partnerKey, partnerSecret string) {
+	t.Helper()
+	env = setupCompleteS3Environment(t)
+
+	bucketName = "shared-datasets"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	putTestObject(t, env, bucketName, "reports/q1.csv", []byte("region,total\neu,42\n"))
+	_, partnerKey, partnerSecret = createForeignUser(t, env)
+
+	grants, ok := env.authManager.(scopedGrantManager)
+	require.True(t, ok, "auth manager should support scoped bucket grants")
+	return env, grants, bucketName, partnerKey, partnerSecret
+}
+
+func TestBucketGrant_TenantReadGrant(t *testing.T) {
+	env, grants, bucketName, partnerKey, partnerSecret := setupBucketGrantEnv(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	get := func() int {
+		req, w := makeSignedRequest("GET", "/"+bucketName+"/reports/q1.csv", nil, partnerKey, partnerSecret)
+		env.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusForbidden, get(), "partner tenant must not read without a grant")
+
+	require.NoError(t, grants.GrantBucketAccessScoped(ctx, bucketName, env.tenantID, "", "partner-tenant", auth.PermissionLevelRead, "admin", 0))
+
+	req, w := makeSignedRequest("GET", "/"+bucketName+"/reports/q1.csv", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "region,total\neu,42\n", w.Body.String())
+
+	req, w = makeSignedRequest("HEAD", "/"+bucketName+"/reports/q1.csv", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, w = makeSignedRequest("HEAD", "/"+bucketName, nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, w = makeSignedRequest("GET", "/"+bucketName+"/?list-type=2", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<Key>reports/q1.csv</Key>")
+
+	// A read grant does not allow writes or deletes
+	req, w = makeSignedRequest("PUT", "/"+bucketName+"/reports/q2.csv", []byte("eu,7\n"), partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = makeSignedRequest("DELETE", "/"+bucketName+"/reports/q1.csv", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	require.NoError(t, grants.RevokeBucketAccessScoped(ctx, bucketName, env.tenantID, "", "partner-tenant"))
+	assert.Equal(t, http.StatusForbidden, get(), "revoking the grant must remove access")
+}
+
+func TestBucketGrant_WriteGrantAllowsUploadAndDelete(t *testing.T) {
+	env, grants, bucketName, partnerKey, partnerSecret := setupBucketGrantEnv(t)
+	defer env.cleanup()
+
+	require.NoError(t, grants.GrantBucketAccessScoped(context.Background(), bucketName, env.tenantID, "partner-user-id", "", auth.PermissionLevelWrite, "admin", 0))
+
+	req, w := makeSignedRequest("PUT", "/"+bucketName+"/reports/q2.csv", []byte("eu,7\n"), partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// The upload lands in the owning tenant's bucket
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/reports/q2.csv", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "eu,7\n", w.Body.String())
+
+	req, w = makeSignedRequest("DELETE", "/"+bucketName+"/reports/q1.csv", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestBucketGrant_ExpiredGrantIgnored(t *testing.T) {
+	env, grants, bucketName, partnerKey, partnerSecret := setupBucketGrantEnv(t)
+	defer env.cleanup()
+
+	expired := time.Now().Add(-time.Minute).Unix()
+	require.NoError(t, grants.GrantBucketAccessScoped(context.Background(), bucketName, env.tenantID, "", "partner-tenant", auth.PermissionLevelRead, "admin", expired))
+
+	req, w := makeSignedRequest("GET", "/"+bucketName+"/reports/q1.csv", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestBucketGrant_GrantOnOtherBucketIgnored(t *testing.T) {
+	env, grants, bucketName, partnerKey, partnerSecret := setupBucketGrantEnv(t)
+	defer env.cleanup()
+
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, "public-datasets", ""))
+	require.NoError(t, grants.GrantBucketAccessScoped(context.Background(), "public-datasets", env.tenantID, "", "partner-tenant", auth.PermissionLevelAdmin, "admin", 0))
+
+	req, w := makeSignedRequest("GET", "/"+bucketName+"/reports/q1.csv", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,67 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bucketPathFor(h *Handler, user *auth.User, bucketName string) string {
+	req := httptest.NewRequest(http.MethodGet, "/"+bucketName, nil)
+	if user != nil {
+		req = req.WithContext(setUserInContext(req.Context(), user))
+	}
+	return h.getBucketPath(req, bucketName)
+}
+
+func TestGlobalBucketNamespaceRouting(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, "owned-bucket", ""))
+
+	// Another tenant cannot take the name
+	err := env.bucketManager.CreateBucket(ctx, "other-tenant", "owned-bucket", "")
+	require.Error(t, err)
+
+	// The bucket name alone resolves to the owning tenant, whoever asks
+	assert.Equal(t, env.tenantID+"/owned-bucket", bucketPathFor(env.handler, nil, "owned-bucket"))
+	assert.Equal(t, env.tenantID+"/owned-bucket", bucketPathFor(env.handler, &auth.User{ID: "admin"}, "owned-bucket"))
+	assert.Equal(t, env.tenantID+"/owned-bucket", bucketPathFor(env.handler, &auth.User{ID: "u2", TenantID: "other-tenant"}, "owned-bucket"))
+
+	// And so does a real S3 request
+	req, w := env.makeS3Request("PUT", "/owned-bucket/routed.txt", []byte("routed"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	_, err = env.objectManager.GetObjectMetadata(ctx, env.tenantID+"/owned-bucket", "routed.txt")
+	assert.NoError(t, err)
+}
+
+func TestTenantBucketNamespaceRouting(t *testing.T) {
+	store, err := metadata.NewPebbleStore(metadata.PebbleOptions{DataDir: t.TempDir(), TenantBucketNames: true})
+	require.NoError(t, err)
+	defer store.Close()
+	ctx := context.Background()
+
+	require.NoError(t, store.CreateBucket(ctx, &metadata.BucketMetadata{Name: "shared", TenantID: "tenant-a"}))
+	require.NoError(t, store.CreateBucket(ctx, &metadata.BucketMetadata{Name: "shared", TenantID: "tenant-b"}))
+	require.NoError(t, store.CreateBucket(ctx, &metadata.BucketMetadata{Name: "only-a", TenantID: "tenant-a"}))
+
+	h := NewHandler(nil, nil)
+	h.SetMetadataStore(store)
+
+	// Each tenant reaches its own bucket of a shared name
+	assert.Equal(t, "tenant-a/shared", bucketPathFor(h, &auth.User{ID: "a", TenantID: "tenant-a"}, "shared"))
+	assert.Equal(t, "tenant-b/shared", bucketPathFor(h, &auth.User{ID: "b", TenantID: "tenant-b"}, "shared"))
+
+	// A name the requester's tenant does not own falls back to its owner
+	assert.Equal(t, "tenant-a/only-a", bucketPathFor(h, &auth.User{ID: "b", TenantID: "tenant-b"}, "only-a"))
+	assert.Equal(t, "tenant-a/shared", bucketPathFor(h, &auth.User{ID: "admin"}, "shared"))
+}
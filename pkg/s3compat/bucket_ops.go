@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/maxiofs/maxiofs/internal/acl"
 	"github.com/maxiofs/maxiofs/internal/auth"
 	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/sirupsen/logrus"
 )
 
@@ -53,6 +55,7 @@ func (h *Handler) GetBucketPolicy(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return the policy as JSON
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write(policyJSON)
@@ -78,6 +81,10 @@ func (h *Handler) PutBucketPolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
+	if err := object.VerifyContentMD5(r.Header.Get("Content-MD5"), body); err != nil {
+		h.writeDigestError(w, r, err, bucketName)
+		return
+	}
 
 	// Strip UTF-8 BOM if present (PowerShell adds BOM by default)
 	// Handle both normal BOM (EF BB BF) and double-encoded BOM (C3 AF C2 BB C2 BF)
@@ -92,28 +99,33 @@ func (h *Handler) PutBucketPolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate policy structure
-	if policyDoc.Version == "" {
-		h.writeError(w, "MalformedPolicy", "Policy must contain a Version field", bucketName, r)
+	// Validate policy structure and every statement element
+	if err := bucket.ValidatePolicy(&policyDoc, bucketName); err != nil {
+		h.writeError(w, "MalformedPolicy", err.Error(), bucketName, r)
 		return
 	}
 
-	if len(policyDoc.Statement) == 0 {
-		h.writeError(w, "MalformedPolicy", "Policy must contain at least one Statement", bucketName, r)
+	tenantID := h.getTenantIDFromRequest(r)
+	if pab := h.publicAccessBlock(r.Context(), tenantID, bucketName); pab != nil && pab.BlockPublicPolicy && bucket.IsPolicyPublic(&policyDoc) {
+		h.writeError(w, "AccessDenied", "Access Denied: public bucket policies are blocked by the bucket's public access block", bucketName, r)
 		return
 	}
 
 	// Set the policy
-	tenantID := h.getTenantIDFromRequest(r)
-	if err := h.bucketManager.SetBucketPolicy(r.Context(), tenantID, bucketName, &policyDoc); err != nil {
+	if err := h.bucketManager.SetBucketPolicy(bucketConfigContext(r), tenantID, bucketName, &policyDoc); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -131,15 +143,20 @@ func (h *Handler) DeleteBucketPolicy(w http.ResponseWriter, r *http.Request) {
 
 	// Delete the policy by setting it to nil
 	tenantID := h.getTenantIDFromRequest(r)
-	if err := h.bucketManager.SetBucketPolicy(r.Context(), tenantID, bucketName, nil); err != nil {
+	if err := h.bucketManager.SetBucketPolicy(bucketConfigContext(r), tenantID, bucketName, nil); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -161,10 +178,13 @@ type LifecycleRule struct {
 	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
 }
 
+// LifecycleFilter selects the objects a rule applies to. Per the S3 schema it
+// holds exactly one of Prefix, Tag or And; Prefix is a pointer and Tag a slice
+// so that an empty <Prefix/> or a repeated <Tag> can be told apart on input.
 type LifecycleFilter struct {
-	Prefix string              `xml:"Prefix,omitempty"`
-	Tag    *LifecycleFilterTag `xml:"Tag,omitempty"`
-	And    *LifecycleFilterAnd `xml:"And,omitempty"`
+	Prefix *string              `xml:"Prefix"`
+	Tag    []LifecycleFilterTag `xml:"Tag,omitempty"`
+	And    *LifecycleFilterAnd  `xml:"And,omitempty"`
 }
 
 type LifecycleFilterTag struct {
@@ -190,7 +210,8 @@ type LifecycleTransition struct {
 }
 
 type NoncurrentVersionExpiration struct {
-	NoncurrentDays int `xml:"NoncurrentDays"`
+	NoncurrentDays          int `xml:"NoncurrentDays,omitempty"`
+	NewerNoncurrentVersions int `xml:"NewerNoncurrentVersions,omitempty"`
 }
 
 type NoncurrentVersionTransition struct {
@@ -238,7 +259,20 @@ func (h *Handler) GetBucketLifecycle(w http.ResponseWriter, r *http.Request) {
 		xmlRule := LifecycleRule{
 			ID:     rule.ID,
 			Status: rule.Status,
-			Prefix: rule.Filter.Prefix,
+		}
+		switch {
+		case rule.Filter.Tag != nil:
+			xmlRule.Filter = &LifecycleFilter{
+				Tag: []LifecycleFilterTag{{Key: rule.Filter.Tag.Key, Value: rule.Filter.Tag.Value}},
+			}
+		case rule.Filter.And != nil:
+			and := &LifecycleFilterAnd{Prefix: rule.Filter.And.Prefix}
+			for _, tag := range rule.Filter.And.Tags {
+				and.Tags = append(and.Tags, LifecycleFilterTag{Key: tag.Key, Value: tag.Value})
+			}
+			xmlRule.Filter = &LifecycleFilter{And: and}
+		default:
+			xmlRule.Prefix = rule.Filter.Prefix
 		}
 
 		if rule.Expiration != nil {
@@ -257,7 +291,8 @@ func (h *Handler) GetBucketLifecycle(w http.ResponseWriter, r *http.Request) {
 
 		if rule.NoncurrentVersionExpiration != nil {
 			xmlRule.NoncurrentVersionExpiration = &NoncurrentVersionExpiration{
-				NoncurrentDays: rule.NoncurrentVersionExpiration.NoncurrentDays,
+				NoncurrentDays:          rule.NoncurrentVersionExpiration.NoncurrentDays,
+				NewerNoncurrentVersions: rule.NoncurrentVersionExpiration.NewerNoncurrentVersions,
 			}
 		}
 
@@ -270,6 +305,7 @@ func (h *Handler) GetBucketLifecycle(w http.ResponseWriter, r *http.Request) {
 		xmlConfig.Rules[i] = xmlRule
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	h.writeXMLResponse(w, http.StatusOK, xmlConfig)
 }
 
@@ -305,20 +341,16 @@ func (h *Handler) PutBucketLifecycle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for i, rule := range xmlConfig.Rules {
-		// Resolve prefix from either the legacy top-level <Prefix> element (old-style)
-		// or from the modern <Filter><Prefix> element (sent by aws-cli, Terraform, SDKv2).
-		// Both represent the same concept; prefer the Filter field when it is present.
-		prefix := rule.Prefix
-		if prefix == "" && rule.Filter != nil {
-			prefix = rule.Filter.Prefix
+		filter, err := parseLifecycleFilter(rule)
+		if err != nil {
+			h.writeError(w, "MalformedXML", err.Error(), bucketName, r)
+			return
 		}
 
 		internalRule := bucket.LifecycleRule{
 			ID:     rule.ID,
 			Status: rule.Status,
-			Filter: bucket.LifecycleFilter{
-				Prefix: prefix,
-			},
+			Filter: filter,
 		}
 
 		if rule.Expiration != nil {
@@ -340,12 +372,29 @@ func (h *Handler) PutBucketLifecycle(w http.ResponseWriter, r *http.Request) {
 			internalRule.Expiration = exp
 		}
 
-		if rule.NoncurrentVersionExpiration != nil {
+		if nve := rule.NoncurrentVersionExpiration; nve != nil {
+			if nve.NoncurrentDays < 0 || nve.NewerNoncurrentVersions < 0 || nve.NewerNoncurrentVersions > 100 {
+				h.writeError(w, "InvalidArgument", "NoncurrentDays must not be negative and NewerNoncurrentVersions must be between 1 and 100", bucketName, r)
+				return
+			}
 			internalRule.NoncurrentVersionExpiration = &bucket.NoncurrentVersionExpiration{
-				NoncurrentDays: rule.NoncurrentVersionExpiration.NoncurrentDays,
+				NoncurrentDays:          nve.NoncurrentDays,
+				NewerNoncurrentVersions: nve.NewerNoncurrentVersions,
 			}
 		}
 
+		// Delete markers and in-progress uploads carry no tags, so S3 rejects
+		// these actions on rules that filter by tag
+		hasTagFilter := filter.Tag != nil || (filter.And != nil && len(filter.And.Tags) > 0)
+		if hasTagFilter && rule.Expiration != nil && rule.Expiration.ExpiredObjectDeleteMarker {
+			h.writeError(w, "InvalidRequest", "ExpiredObjectDeleteMarker cannot be specified with a tag-based filter", bucketName, r)
+			return
+		}
+		if hasTagFilter && rule.AbortIncompleteMultipartUpload != nil {
+			h.writeError(w, "InvalidRequest", "AbortIncompleteMultipartUpload cannot be specified with a tag-based filter", bucketName, r)
+			return
+		}
+
 		if rule.AbortIncompleteMultipartUpload != nil {
 			internalRule.AbortIncompleteMultipartUpload = &bucket.LifecycleAbortIncompleteMultipartUpload{
 				DaysAfterInitiation: rule.AbortIncompleteMultipartUpload.DaysAfterInitiation,
@@ -357,18 +406,78 @@ func (h *Handler) PutBucketLifecycle(w http.ResponseWriter, r *http.Request) {
 
 	// Set the lifecycle configuration
 	tenantID := h.getTenantIDFromRequest(r)
-	if err := h.bucketManager.SetLifecycle(r.Context(), tenantID, bucketName, lifecycleConfig); err != nil {
+	if err := h.bucketManager.SetLifecycle(bucketConfigContext(r), tenantID, bucketName, lifecycleConfig); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusOK)
 }
 
+// parseLifecycleFilter resolves a rule's filter from either the legacy top-level
+// <Prefix> element or the modern <Filter> element (sent by aws-cli, Terraform,
+// SDKv2). A Filter may hold only one of Prefix, a single Tag, or And.
+func parseLifecycleFilter(rule LifecycleRule) (bucket.LifecycleFilter, error) {
+	filter := bucket.LifecycleFilter{Prefix: rule.Prefix}
+	if rule.Filter == nil {
+		return filter, nil
+	}
+	f := rule.Filter
+
+	criteria := len(f.Tag)
+	if f.Prefix != nil {
+		criteria++
+	}
+	if f.And != nil {
+		criteria++
+	}
+	if criteria > 1 {
+		return filter, errors.New("Filter must contain only one of Prefix, Tag or And")
+	}
+	if rule.Prefix != "" && criteria > 0 && f.Prefix == nil {
+		return filter, errors.New("a rule cannot combine a top-level Prefix with a Tag or And filter")
+	}
+
+	switch {
+	case f.Prefix != nil:
+		if filter.Prefix == "" {
+			filter.Prefix = *f.Prefix
+		}
+	case len(f.Tag) == 1:
+		if f.Tag[0].Key == "" {
+			return filter, errors.New("Tag filter key must not be empty")
+		}
+		filter.Tag = &bucket.Tag{Key: f.Tag[0].Key, Value: f.Tag[0].Value}
+	case f.And != nil:
+		if len(f.And.Tags) == 0 && f.And.Prefix == "" {
+			return filter, errors.New("And must contain a Prefix or at least one Tag")
+		}
+		and := &bucket.LifecycleFilterAnd{Prefix: f.And.Prefix}
+		seen := make(map[string]bool, len(f.And.Tags))
+		for _, tag := range f.And.Tags {
+			if tag.Key == "" {
+				return filter, errors.New("Tag filter key must not be empty")
+			}
+			if seen[tag.Key] {
+				return filter, errors.New("duplicate Tag keys are not allowed in And")
+			}
+			seen[tag.Key] = true
+			and.Tags = append(and.Tags, bucket.Tag{Key: tag.Key, Value: tag.Value})
+		}
+		filter.And = and
+	}
+	return filter, nil
+}
+
 // DeleteBucketLifecycle removes the bucket lifecycle configuration
 func (h *Handler) DeleteBucketLifecycle(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -378,15 +487,20 @@ func (h *Handler) DeleteBucketLifecycle(w http.ResponseWriter, r *http.Request)
 
 	// Delete the lifecycle configuration by setting it to nil
 	tenantID := h.getTenantIDFromRequest(r)
-	if err := h.bucketManager.SetLifecycle(r.Context(), tenantID, bucketName, nil); err != nil {
+	if err := h.bucketManager.SetLifecycle(bucketConfigContext(r), tenantID, bucketName, nil); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -449,6 +563,7 @@ func (h *Handler) GetBucketCORS(w http.ResponseWriter, r *http.Request) {
 		xmlConfig.CORSRules[i] = xmlRule
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	h.writeXMLResponse(w, http.StatusOK, xmlConfig)
 }
 
@@ -509,15 +624,20 @@ func (h *Handler) PutBucketCORS(w http.ResponseWriter, r *http.Request) {
 
 	// Set the CORS configuration
 	tenantID := h.getTenantIDFromRequest(r)
-	if err := h.bucketManager.SetCORS(r.Context(), tenantID, bucketName, corsConfig); err != nil {
+	if err := h.bucketManager.SetCORS(bucketConfigContext(r), tenantID, bucketName, corsConfig); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -530,15 +650,20 @@ func (h *Handler) DeleteBucketCORS(w http.ResponseWriter, r *http.Request) {
 
 	// Delete the CORS configuration by setting it to nil
 	tenantID := h.getTenantIDFromRequest(r)
-	if err := h.bucketManager.SetCORS(r.Context(), tenantID, bucketName, nil); err != nil {
+	if err := h.bucketManager.SetCORS(bucketConfigContext(r), tenantID, bucketName, nil); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -582,6 +707,7 @@ func (h *Handler) GetBucketTagging(w http.ResponseWriter, r *http.Request) {
 		},
 	}
 
+	w.Header().Set("ETag", bucketRevisionETag(bucketData.Revision))
 	if bucketData.Tags != nil && len(bucketData.Tags) > 0 {
 		for key, value := range bucketData.Tags {
 			response.TagSet.Tags = append(response.TagSet.Tags, Tag{
@@ -645,15 +771,20 @@ func (h *Handler) PutBucketTagging(w http.ResponseWriter, r *http.Request) {
 
 	// Update bucket tags
 	tenantID := h.getTenantIDFromRequest(r)
-	if err := h.bucketManager.SetBucketTags(r.Context(), tenantID, bucketName, tags); err != nil {
+	if err := h.bucketManager.SetBucketTags(bucketConfigContext(r), tenantID, bucketName, tags); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -665,15 +796,20 @@ func (h *Handler) DeleteBucketTagging(w http.ResponseWriter, r *http.Request) {
 	logrus.WithField("bucket", bucketName).Debug("S3 API: DeleteBucketTagging")
 
 	tenantID := h.getTenantIDFromRequest(r)
-	if err := h.bucketManager.SetBucketTags(r.Context(), tenantID, bucketName, nil); err != nil {
+	if err := h.bucketManager.SetBucketTags(bucketConfigContext(r), tenantID, bucketName, nil); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -770,6 +906,9 @@ func (h *Handler) PutBucketACL(w http.ResponseWriter, r *http.Request) {
 		// Convert from S3 format to internal format
 		aclData = acl.FromS3Format(&s3ACL)
 	}
+	if h.rejectPublicACL(w, r, tenantID, bucketName, bucketName, requestSetsPublicACL(r) || aclData.HasPublicGrant()) {
+		return
+	}
 
 	// Set ACL using bucket manager
 	if err := h.bucketManager.SetBucketACL(r.Context(), tenantID, bucketName, aclData); err != nil {
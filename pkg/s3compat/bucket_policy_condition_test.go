@@ -0,0 +1,156 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketPolicy_SourceIpCondition(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "office-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	putTestObject(t, env, bucketName, "report.pdf", []byte("quarterly numbers"))
+	partnerID, partnerKey, partnerSecret := createForeignUser(t, env)
+
+	putBucketPolicy(t, env, bucketName, `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Allow",
+			"Principal": {"AWS": "`+partnerID+`"},
+			"Action": "s3:GetObject",
+			"Resource": "arn:aws:s3:::`+bucketName+`/*",
+			"Condition": {"IpAddress": {"aws:SourceIp": "10.0.0.0/8"}}
+		}]
+	}`)
+
+	t.Run("client inside the range", func(t *testing.T) {
+		req, w := makeSignedRequest("GET", "/"+bucketName+"/report.pdf", nil, partnerKey, partnerSecret)
+		req.RemoteAddr = "10.20.30.40:51000"
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("client outside the range", func(t *testing.T) {
+		req, w := makeSignedRequest("GET", "/"+bucketName+"/report.pdf", nil, partnerKey, partnerSecret)
+		req.RemoteAddr = "203.0.113.7:51000"
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("spoofed X-Forwarded-For from an untrusted peer", func(t *testing.T) {
+		req, w := makeSignedRequest("GET", "/"+bucketName+"/report.pdf", nil, partnerKey, partnerSecret)
+		req.RemoteAddr = "203.0.113.7:51000"
+		req.Header.Set("X-Forwarded-For", "10.1.1.1")
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("spoofed X-Forwarded-For from a private peer not in trusted_proxies", func(t *testing.T) {
+		// 192.168.5.5 is outside the allowed range; a private address alone
+		// does not make it a proxy whose headers the policy trusts
+		req, w := makeSignedRequest("GET", "/"+bucketName+"/report.pdf", nil, partnerKey, partnerSecret)
+		req.RemoteAddr = "192.168.5.5:51000"
+		req.Header.Set("X-Forwarded-For", "10.1.1.1")
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("X-Forwarded-For from a configured proxy", func(t *testing.T) {
+		env.handler.SetTrustedProxies([]string{"192.168.5.5"})
+		defer env.handler.SetTrustedProxies(nil)
+
+		req, w := makeSignedRequest("GET", "/"+bucketName+"/report.pdf", nil, partnerKey, partnerSecret)
+		req.RemoteAddr = "192.168.5.5:51000"
+		req.Header.Set("X-Forwarded-For", "10.1.1.1")
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+}
+
+func TestBucketPolicy_SecureTransportCondition(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "tls-only-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	putTestObject(t, env, bucketName, "secret.txt", []byte("only over https"))
+
+	putBucketPolicy(t, env, bucketName, `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Deny",
+			"Principal": "*",
+			"Action": "s3:*",
+			"Resource": ["arn:aws:s3:::`+bucketName+`", "arn:aws:s3:::`+bucketName+`/*"],
+			"Condition": {"Bool": {"aws:SecureTransport": "false"}}
+		}]
+	}`)
+
+	// Plain HTTP from a public address: denied even for the owning tenant
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/secret.txt", nil)
+	req.RemoteAddr = "203.0.113.7:51000"
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/", nil)
+	req.RemoteAddr = "203.0.113.7:51000"
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "listing is covered by the bucket ARN")
+
+	// X-Forwarded-Proto from a private peer that is not a configured proxy
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/secret.txt", nil)
+	req.RemoteAddr = "10.0.0.5:51000"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// TLS terminated by a reverse proxy listed in trusted_proxies
+	env.handler.SetTrustedProxies([]string{"10.0.0.0/24"})
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/secret.txt", nil)
+	req.RemoteAddr = "10.0.0.5:51000"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestBucketPolicy_PrefixConditionOnListing(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "home-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	putTestObject(t, env, bucketName, "home/partner/notes.txt", []byte("mine"))
+	putTestObject(t, env, bucketName, "home/other/notes.txt", []byte("not mine"))
+	partnerID, partnerKey, partnerSecret := createForeignUser(t, env)
+
+	putBucketPolicy(t, env, bucketName, `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Allow",
+			"Principal": {"AWS": "`+partnerID+`"},
+			"Action": "s3:ListBucket",
+			"Resource": "arn:aws:s3:::`+bucketName+`",
+			"Condition": {"StringLike": {"s3:prefix": "home/partner/*"}}
+		}]
+	}`)
+
+	req, w := makeSignedRequest("GET", "/"+bucketName+"/?list-type=2&prefix=home/partner/", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "home/partner/notes.txt")
+	assert.NotContains(t, w.Body.String(), "home/other/notes.txt")
+
+	req, w = makeSignedRequest("GET", "/"+bucketName+"/?list-type=2&prefix=home/other/", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = makeSignedRequest("GET", "/"+bucketName+"/", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "listing without a prefix does not satisfy the condition")
+}
@@ -0,0 +1,150 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createForeignUser creates a user in a separate tenant and returns its ID and
+// credentials. Without a bucket policy such a user has no access to env's buckets.
+func createForeignUser(t *testing.T, env *s3TestEnv) (userID, accessKey, secretKey string) {
+	t.Helper()
+	ctx := context.Background()
+
+	require.NoError(t, env.authManager.CreateTenant(ctx, &auth.Tenant{
+		ID:              "partner-tenant",
+		Name:            "partner-tenant",
+		DisplayName:     "Partner Tenant",
+		Status:          "active",
+		MaxAccessKeys:   10,
+		MaxStorageBytes: 1024 * 1024 * 1024,
+		MaxBuckets:      10,
+		CreatedAt:       time.Now().Unix(),
+		UpdatedAt:       time.Now().Unix(),
+	}))
+
+	user := &auth.User{
+		ID:          "partner-user-id",
+		Username:    "partneruser",
+		DisplayName: "Partner User",
+		Status:      "active",
+		TenantID:    "partner-tenant",
+		Roles:       []string{"user"},
+		CreatedAt:   time.Now().Unix(),
+		UpdatedAt:   time.Now().Unix(),
+	}
+	require.NoError(t, env.authManager.CreateUser(ctx, user))
+
+	key, err := env.authManager.GenerateAccessKey(ctx, user.ID)
+	require.NoError(t, err)
+	return user.ID, key.AccessKeyID, key.SecretAccessKey
+}
+
+func makeSignedRequest(method, path string, body []byte, accessKey, secretKey string) (*http.Request, *httptest.ResponseRecorder) {
+	var req *http.Request
+	if body != nil {
+		req = httptest.NewRequest(method, path, bytes.NewReader(body))
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	req.Host = "localhost"
+	signRequestV4(req, accessKey, secretKey, "us-east-1", "s3")
+	return req, httptest.NewRecorder()
+}
+
+func putBucketPolicy(t *testing.T, env *s3TestEnv, bucketName, policy string) {
+	t.Helper()
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"?policy", []byte(policy))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+}
+
+func TestBucketPolicy_KeyPrefixAllowForForeignPrincipal(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "shared-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	partnerID, partnerKey, partnerSecret := createForeignUser(t, env)
+
+	putBucketPolicy(t, env, bucketName, `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Allow",
+			"Principal": {"AWS": ["`+partnerID+`"]},
+			"Action": ["s3:PutObject", "s3:GetObject"],
+			"Resource": "arn:aws:s3:::`+bucketName+`/teamA/*"
+		}]
+	}`)
+
+	t.Run("write inside the prefix", func(t *testing.T) {
+		req, w := makeSignedRequest("PUT", "/"+bucketName+"/teamA/report.csv", []byte("a,b,c"), partnerKey, partnerSecret)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("read inside the prefix", func(t *testing.T) {
+		req, w := makeSignedRequest("GET", "/"+bucketName+"/teamA/report.csv", nil, partnerKey, partnerSecret)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "a,b,c", w.Body.String())
+	})
+
+	t.Run("write outside the prefix", func(t *testing.T) {
+		req, w := makeSignedRequest("PUT", "/"+bucketName+"/teamB/report.csv", []byte("x"), partnerKey, partnerSecret)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("action not granted", func(t *testing.T) {
+		req, w := makeSignedRequest("DELETE", "/"+bucketName+"/teamA/report.csv", nil, partnerKey, partnerSecret)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestBucketPolicy_KeyPrefixDenyAppliesToOwnerTenant(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "locked-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	putTestObject(t, env, bucketName, "archive/2023.tar", []byte("old"))
+	putBucketPolicy(t, env, bucketName, `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Deny",
+			"Principal": "*",
+			"Action": ["s3:PutObject", "s3:DeleteObject"],
+			"Resource": "arn:aws:s3:::`+bucketName+`/archive/*"
+		}]
+	}`)
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/archive/2024.tar", []byte("new"))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = env.makeS3Request("DELETE", "/"+bucketName+"/archive/2023.tar", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/archive/big.tar?uploads", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "multipart uploads must honour the deny")
+
+	// Reads are not covered by the statement, and other prefixes stay writable.
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/archive/2023.tar", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	putTestObject(t, env, bucketName, "incoming/2024.tar", []byte("new"))
+}
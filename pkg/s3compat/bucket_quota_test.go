@@ -0,0 +1,90 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBucket_QuotaHeaders(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	req, w := env.makeS3Request("PUT", "/capped-bucket", nil)
+	req.Header.Set(headerBucketMaxObjects, "2")
+	req.Header.Set(headerBucketMaxSizeBytes, "1048576")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	info, err := env.bucketManager.GetBucketInfo(context.Background(), env.tenantID, "capped-bucket")
+	require.NoError(t, err)
+	require.NotNil(t, info.Quota)
+	assert.Equal(t, int64(2), info.Quota.MaxObjectCount)
+	assert.Equal(t, int64(1048576), info.Quota.MaxSizeBytes)
+
+	req, w = env.makeS3Request("PUT", "/bad-quota-bucket", nil)
+	req.Header.Set(headerBucketMaxObjects, "-1")
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	_, err = env.bucketManager.GetBucketInfo(context.Background(), env.tenantID, "bad-quota-bucket")
+	assert.Error(t, err, "bucket must not be created with an invalid quota header")
+}
+
+func TestBucketQuota_ObjectCountCapRejectsUpload(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	// Quota enforcement reads the usage the bucket manager maintains, as in the server
+	om, ok := env.objectManager.(interface {
+		SetBucketManager(bm interface {
+			IncrementObjectCount(ctx context.Context, tenantID, name string, sizeBytes int64) error
+			DecrementObjectCount(ctx context.Context, tenantID, name string, sizeBytes int64) error
+			AdjustBucketSize(ctx context.Context, tenantID, name string, sizeDelta int64) error
+		})
+	})
+	require.True(t, ok)
+	om.SetBucketManager(env.bucketManager)
+
+	req, w := env.makeS3Request("PUT", "/capped-bucket", nil)
+	req.Header.Set(headerBucketMaxObjects, "2")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, "open-bucket", ""))
+
+	putTestObject(t, env, "capped-bucket", "one.txt", []byte("1"))
+	putTestObject(t, env, "capped-bucket", "two.txt", []byte("2"))
+
+	req, w = env.makeS3Request("PUT", "/capped-bucket/three.txt", []byte("3"))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>QuotaExceeded</Code>")
+
+	// Overwriting an existing key adds no object and is still allowed
+	putTestObject(t, env, "capped-bucket", "two.txt", []byte("2 again"))
+
+	// Other buckets are unaffected
+	putTestObject(t, env, "open-bucket", "three.txt", []byte("3"))
+}
+
+func TestCreateBucket_QuotaHeaderAboveTenantQuota(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	// The test tenant has 10GB of storage
+	req, w := env.makeS3Request("PUT", "/oversized-bucket", nil)
+	req.Header.Set(headerBucketMaxSizeBytes, "11811160064") // 11GB
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>InvalidArgument</Code>")
+	_, err := env.bucketManager.GetBucketInfo(context.Background(), env.tenantID, "oversized-bucket")
+	assert.Error(t, err, "bucket must not be created with a quota above the tenant's")
+
+	// Up to the tenant quota is fine
+	req, w = env.makeS3Request("PUT", "/full-size-bucket", nil)
+	req.Header.Set(headerBucketMaxSizeBytes, "10737418240")
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
@@ -0,0 +1,114 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBucketConfig_IfMatchConflict tests that two concurrent config updates
+// sent with the same If-Match revision result in exactly one success and one
+// 409, and that the winner's ETag is the one to use next
+func TestBucketConfig_IfMatchConflict(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "contended"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, env.userID))
+
+	req, w := env.makeS3Request("GET", "/"+bucketName+"?versioning", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	putVersioning := func(status, ifMatch string) *httptest.ResponseRecorder {
+		body := []byte(`<VersioningConfiguration><Status>` + status + `</Status></VersioningConfiguration>`)
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?versioning", body)
+		req.Header.Set("If-Match", ifMatch)
+		env.router.ServeHTTP(w, req)
+		return w
+	}
+
+	results := make([]*httptest.ResponseRecorder, 2)
+	var wg sync.WaitGroup
+	for i, status := range []string{"Enabled", "Suspended"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = putVersioning(status, etag)
+		}()
+	}
+	wg.Wait()
+
+	codes := []int{results[0].Code, results[1].Code}
+	assert.ElementsMatch(t, []int{http.StatusOK, http.StatusConflict}, codes)
+	winner, loser := results[0], results[1]
+	if winner.Code != http.StatusOK {
+		winner, loser = loser, winner
+	}
+	assert.Contains(t, loser.Body.String(), "ConditionalRequestConflict")
+	assert.NotEqual(t, etag, winner.Header().Get("ETag"))
+
+	// The stale ETag keeps failing; the current one applies
+	assert.Equal(t, http.StatusConflict, putVersioning("Enabled", etag).Code)
+	assert.Equal(t, http.StatusOK, putVersioning("Enabled", winner.Header().Get("ETag")).Code)
+
+	// Without If-Match updates are unconditional
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"?tagging", []byte(`<Tagging><TagSet><Tag><Key>a</Key><Value>b</Value></Tag></TagSet></Tagging>`))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+}
+
+// TestObjectLockConfig_IfMatch tests that the Object Lock configuration takes
+// part in the bucket revision checks and doesn't overwrite other settings
+func TestObjectLockConfig_IfMatch(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "locked-config"
+	req, w := env.makeS3Request("PUT", "/"+bucketName, nil)
+	req.Header.Set("x-amz-bucket-object-lock-enabled", "true")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"?object-lock", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Another admin tags the bucket in between
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"?tagging", []byte(`<Tagging><TagSet><Tag><Key>a</Key><Value>b</Value></Tag></TagSet></Tagging>`))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	putLock := func(ifMatch string) *httptest.ResponseRecorder {
+		body := []byte(`<ObjectLockConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><ObjectLockEnabled>Enabled</ObjectLockEnabled><Rule><DefaultRetention><Mode>GOVERNANCE</Mode><Days>30</Days></DefaultRetention></Rule></ObjectLockConfiguration>`)
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?object-lock", body)
+		req.Header.Set("If-Match", ifMatch)
+		env.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = putLock(etag)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "ConditionalRequestConflict")
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"?object-lock", nil)
+	env.router.ServeHTTP(w, req)
+	w = putLock(w.Header().Get("ETag"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	bkt, err := env.bucketManager.GetBucketInfo(context.Background(), env.tenantID, bucketName)
+	require.NoError(t, err)
+	assert.Equal(t, "b", bkt.Tags["a"], "the Object Lock update must keep the bucket's tags")
+	require.NotNil(t, bkt.ObjectLock.Rule)
+	assert.Equal(t, 30, *bkt.ObjectLock.Rule.DefaultRetention.Days)
+}
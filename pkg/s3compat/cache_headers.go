@@ -0,0 +1,64 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/object"
+)
+
+// immutableCacheControl is sent for requests naming a specific version when
+// the bucket opts in (ImmutableVersions): the bytes behind a version ID never
+// change, so a CDN may keep them for a year without revalidating.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// bucketCacheDefaults returns the bucket's response caching defaults, or nil
+// when it has none (or can't be read — caching headers are best effort).
+func (h *Handler) bucketCacheDefaults(ctx context.Context, tenantID, bucketName string) *metadata.BucketCacheDefaults {
+	bkt, err := h.bucketManager.GetBucketInfo(ctx, tenantID, bucketName)
+	if err != nil {
+		return nil
+	}
+	return bkt.CacheDefaults
+}
+
+// setObjectCacheHeaders sets the validators (ETag, Last-Modified) and the
+// caching policy of a GET/HEAD object response. It runs before the
+// conditional headers are evaluated so a 304 carries the same headers as the
+// 200 it stands for, which is what lets a CDN refresh its copy's lifetime.
+//
+// The object's own Cache-Control always wins; otherwise the bucket defaults
+// apply. versionID is the version the client asked for ("" for the latest):
+// only a real version ID is immutable, as the "null" version can be replaced.
+func setObjectCacheHeaders(w http.ResponseWriter, obj *object.Object, defaults *metadata.BucketCacheDefaults, versionID string) {
+	w.Header().Set("ETag", quotedETag(obj.ETag))
+	w.Header().Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+
+	switch {
+	case obj.CacheControl != "":
+		w.Header().Set("Cache-Control", obj.CacheControl)
+	case defaults == nil:
+	case defaults.ImmutableVersions && versionID != "" && versionID != "null":
+		w.Header().Set("Cache-Control", immutableCacheControl)
+	default:
+		if defaults.CacheControl != "" {
+			w.Header().Set("Cache-Control", defaults.CacheControl)
+		}
+		if defaults.ExpiresSeconds > 0 {
+			expires := time.Now().Add(time.Duration(defaults.ExpiresSeconds) * time.Second)
+			w.Header().Set("Expires", expires.UTC().Format(http.TimeFormat))
+		}
+	}
+}
+
+// quotedETag returns etag as an HTTP entity tag. Object ETags are stored as
+// bare digests, but caches only match If-None-Match against quoted values.
+func quotedETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return `"` + strings.Trim(etag, `"`) + `"`
+}
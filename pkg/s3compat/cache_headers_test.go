@@ -0,0 +1,121 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheHeaders_BucketDefaults(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	bucketName := "cdn-assets"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	require.NoError(t, env.bucketManager.SetCacheDefaults(ctx, env.tenantID, bucketName, &metadata.BucketCacheDefaults{
+		CacheControl:   "public, max-age=3600",
+		ExpiresSeconds: 3600,
+	}))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/plain.css", []byte("body {}"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/explicit.css", []byte("p {}"))
+	req.Header.Set("Cache-Control", "no-cache")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	t.Run("objects without Cache-Control inherit the bucket defaults", func(t *testing.T) {
+		for _, method := range []string{"GET", "HEAD"} {
+			req, w := env.makeS3Request(method, "/"+bucketName+"/plain.css", nil)
+			env.router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, method)
+			assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"), method)
+
+			expires, err := http.ParseTime(w.Header().Get("Expires"))
+			require.NoError(t, err, method)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute, method)
+		}
+	})
+
+	t.Run("explicit per-object Cache-Control wins", func(t *testing.T) {
+		for _, method := range []string{"GET", "HEAD"} {
+			req, w := env.makeS3Request(method, "/"+bucketName+"/explicit.css", nil)
+			env.router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, method)
+			assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"), method)
+			assert.Empty(t, w.Header().Get("Expires"), method)
+		}
+	})
+
+	t.Run("validators are quoted and a 304 keeps the caching headers", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/plain.css", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+		lastModified := w.Header().Get("Last-Modified")
+
+		req, w = env.makeS3Request("GET", "/"+bucketName+"/plain.css", nil)
+		req.Header.Set("If-None-Match", etag)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+
+		// Last-Modified has second precision; echoing it back must revalidate.
+		req, w = env.makeS3Request("GET", "/"+bucketName+"/plain.css", nil)
+		req.Header.Set("If-Modified-Since", lastModified)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("removing the defaults stops the injection", func(t *testing.T) {
+		require.NoError(t, env.bucketManager.SetCacheDefaults(ctx, env.tenantID, bucketName, nil))
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/plain.css", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Cache-Control"))
+		assert.Empty(t, w.Header().Get("Expires"))
+	})
+}
+
+func TestCacheHeaders_ImmutableVersions(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	bucketName := "cdn-versions"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	setBucketVersioning(t, env, bucketName, "Enabled")
+	require.NoError(t, env.bucketManager.SetCacheDefaults(ctx, env.tenantID, bucketName, &metadata.BucketCacheDefaults{
+		CacheControl:      "public, max-age=60",
+		ImmutableVersions: true,
+	}))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/app.js", []byte("v1"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	versionID := w.Header().Get("x-amz-version-id")
+	require.NotEmpty(t, versionID)
+
+	for _, method := range []string{"GET", "HEAD"} {
+		req, w := env.makeS3Request(method, "/"+bucketName+"/app.js?versionId="+versionID, nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, method)
+		assert.Equal(t, immutableCacheControl, w.Header().Get("Cache-Control"), method)
+
+		// The latest object under its plain key can still change.
+		req, w = env.makeS3Request(method, "/"+bucketName+"/app.js", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, method)
+		assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"), method)
+	}
+}
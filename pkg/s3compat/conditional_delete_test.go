@@ -0,0 +1,89 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteObject_IfMatch(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "cas-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	put := func(key, body string) string {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+key, []byte(body))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Header().Get("ETag")
+	}
+	head := func(key string) int {
+		req, w := env.makeS3Request("HEAD", "/"+bucketName+"/"+key, nil)
+		env.router.ServeHTTP(w, req)
+		return w.Code
+	}
+	del := func(path, ifMatch string) int {
+		req, w := env.makeS3Request("DELETE", "/"+bucketName+"/"+path, nil)
+		req.Header.Set("If-Match", ifMatch)
+		env.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("stale etag is rejected and object remains", func(t *testing.T) {
+		stale := put("doc.txt", "first")
+		put("doc.txt", "second")
+
+		assert.Equal(t, http.StatusPreconditionFailed, del("doc.txt", stale))
+		assert.Equal(t, http.StatusOK, head("doc.txt"))
+	})
+
+	t.Run("matching etag deletes", func(t *testing.T) {
+		etag := put("doc.txt", "third")
+
+		assert.Equal(t, http.StatusNoContent, del("doc.txt", etag))
+		assert.Equal(t, http.StatusNotFound, head("doc.txt"))
+	})
+
+	t.Run("missing object fails the precondition", func(t *testing.T) {
+		assert.Equal(t, http.StatusPreconditionFailed, del("never-written.txt", "*"))
+	})
+
+	t.Run("versioned bucket", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?versioning",
+			[]byte(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		oldETag := put("v.txt", "old")
+		req, w = env.makeS3Request("PUT", "/"+bucketName+"/v.txt", []byte("new"))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		newETag := w.Header().Get("ETag")
+		newVersionID := w.Header().Get("x-amz-version-id")
+		require.NotEmpty(t, newVersionID)
+
+		// The latest version is what a delete without versionId targets.
+		assert.Equal(t, http.StatusPreconditionFailed, del("v.txt", oldETag))
+		assert.Equal(t, http.StatusOK, head("v.txt"))
+
+		// A specific version is checked against its own ETag.
+		assert.Equal(t, http.StatusPreconditionFailed, del("v.txt?versionId="+newVersionID, oldETag))
+
+		req, w = env.makeS3Request("DELETE", "/"+bucketName+"/v.txt", nil)
+		req.Header.Set("If-Match", newETag)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "true", w.Header().Get("x-amz-delete-marker"))
+
+		// With a delete marker on top there is no current object to match.
+		assert.Equal(t, http.StatusPreconditionFailed, del("v.txt", newETag))
+
+		// The hidden version can still be removed by ID when its ETag matches.
+		assert.Equal(t, http.StatusNoContent, del("v.txt?versionId="+newVersionID, newETag))
+	})
+}
@@ -0,0 +1,85 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObject_ContentLengthMatchesDeliveredBytes(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "content-length"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	plain := make([]byte, 200_000)
+	for i := range plain {
+		plain[i] = byte(i * 7)
+	}
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/single.bin", plain)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Multipart object whose parts are encrypted separately
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/multi.bin?uploads", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var initiated InitiateMultipartUploadResult
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+	multi := append(bytes.Repeat([]byte("m"), 6<<20), plain[:1000]...)
+	var completeXML bytes.Buffer
+	completeXML.WriteString("<CompleteMultipartUpload>")
+	for i, body := range [][]byte{multi[:6<<20], multi[6<<20:]} {
+		req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/multi.bin?partNumber=%d&uploadId=%s", bucketName, i+1, initiated.UploadId), body)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		fmt.Fprintf(&completeXML, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag></Part>", i+1, w.Header().Get("ETag"))
+	}
+	completeXML.WriteString("</CompleteMultipartUpload>")
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/multi.bin?uploadId="+initiated.UploadId, completeXML.Bytes())
+	env.router.ServeHTTP(w, req)
+	require.NotContains(t, w.Body.String(), "<Error>")
+
+	objects := map[string][]byte{"single.bin": plain, "multi.bin": multi}
+	for key, want := range objects {
+		size := int64(len(want))
+		cases := []struct {
+			rangeHeader string
+			start, end  int64
+		}{
+			{"", 0, size - 1},
+			{"bytes=0-0", 0, 0},
+			{"bytes=100-65635", 100, 65635},
+			{"bytes=65536-", 65536, size - 1},
+			{"bytes=-777", size - 777, size - 1},
+			{"bytes=10-" + strconv.FormatInt(size+5000, 10), 10, size - 1},
+		}
+		for _, tc := range cases {
+			t.Run(key+" "+tc.rangeHeader, func(t *testing.T) {
+				for _, method := range []string{"GET", "HEAD"} {
+					req, w := env.makeS3Request(method, "/"+bucketName+"/"+key, nil)
+					if tc.rangeHeader != "" {
+						req.Header.Set("Range", tc.rangeHeader)
+					}
+					env.router.ServeHTTP(w, req)
+					require.Contains(t, []int{http.StatusOK, http.StatusPartialContent}, w.Code, w.Body.String())
+
+					expected := tc.end - tc.start + 1
+					assert.Equal(t, strconv.FormatInt(expected, 10), w.Header().Get("Content-Length"), method)
+					if method == "GET" {
+						assert.Equal(t, expected, int64(w.Body.Len()))
+						assert.True(t, bytes.Equal(want[tc.start:tc.end+1], w.Body.Bytes()), "delivered bytes must be the plaintext range")
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package s3compat
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/maxiofs/maxiofs/internal/object"
+)
+
+// writeDigestError answers a Content-MD5 failure and reports whether err was
+// one: InvalidDigest for a malformed header, BadDigest for a mismatch.
+func (h *Handler) writeDigestError(w http.ResponseWriter, r *http.Request, err error, resource string) bool {
+	switch {
+	case errors.Is(err, object.ErrInvalidDigest):
+		h.writeError(w, "InvalidDigest", "The Content-MD5 you specified is not valid", resource, r)
+	case errors.Is(err, object.ErrBadDigest):
+		h.writeError(w, "BadDigest", "The Content-MD5 you specified did not match what we received", resource, r)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package s3compat
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func contentMD5(data string) string {
+	sum := md5.Sum([]byte(data))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// TestContentMD5Verification tests that uploads and bodies carrying a
+// Content-MD5 header are verified against what was received.
+func TestContentMD5Verification(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "content-md5-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	bucketPath := env.tenantID + "/" + bucketName
+
+	put := func(key, body, md5Header string) *http.Response {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+key, []byte(body))
+		req.Header.Set("Content-MD5", md5Header)
+		env.router.ServeHTTP(w, req)
+		return w.Result()
+	}
+	stored := func(key string) string {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/"+key, nil)
+		env.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return ""
+		}
+		return w.Body.String()
+	}
+
+	t.Run("matching digest is accepted", func(t *testing.T) {
+		resp := put("match.txt", "hello world", contentMD5("hello world"))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello world", stored("match.txt"))
+	})
+
+	t.Run("mismatching digest is rejected and nothing is stored", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/corrupt.txt", []byte("hello w0rld"))
+		req.Header.Set("Content-MD5", contentMD5("hello world"))
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "<Code>BadDigest</Code>")
+		_, err := env.objectManager.GetObjectMetadata(ctx, bucketPath, "corrupt.txt")
+		assert.Error(t, err)
+	})
+
+	t.Run("mismatching digest leaves an existing object untouched", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, put("keep.txt", "original", "").StatusCode)
+		assert.Equal(t, http.StatusBadRequest, put("keep.txt", "replacement", contentMD5("other")).StatusCode)
+		assert.Equal(t, "original", stored("keep.txt"))
+	})
+
+	t.Run("malformed digest", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/malformed.txt", []byte("data"))
+		req.Header.Set("Content-MD5", "not-base64!")
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "<Code>InvalidDigest</Code>")
+	})
+
+	t.Run("aws-chunked body is verified after decoding", func(t *testing.T) {
+		chunked := "5;chunk-signature=abcd\r\nHello\r\n6;chunk-signature=ef01\r\n World\r\n0;chunk-signature=2345\r\n\r\n"
+		for _, tc := range []struct {
+			digest string
+			status int
+		}{{contentMD5("Hello World"), http.StatusOK}, {contentMD5(chunked), http.StatusBadRequest}} {
+			req, w := env.makeS3Request("PUT", "/"+bucketName+"/chunked.txt", []byte(chunked))
+			req.Header.Set("Content-Encoding", "aws-chunked")
+			req.Header.Set("X-Amz-Decoded-Content-Length", "11")
+			req.Header.Set("Content-MD5", tc.digest)
+			env.router.ServeHTTP(w, req)
+			assert.Equal(t, tc.status, w.Code, w.Body.String())
+		}
+		assert.Equal(t, "Hello World", stored("chunked.txt"))
+	})
+
+	t.Run("append verifies the appended bytes", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, put("log.txt", "line1\n", "").StatusCode)
+		appendReq := func(data, digest string) int {
+			req, w := env.makeS3Request("PUT", "/"+bucketName+"/log.txt", []byte(data))
+			req.Header.Set("x-amz-write-offset-bytes", "6")
+			req.Header.Set("Content-MD5", digest)
+			env.router.ServeHTTP(w, req)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusBadRequest, appendReq("line2\n", contentMD5("line1\nline2\n")))
+		assert.Equal(t, "line1\n", stored("log.txt"))
+		assert.Equal(t, http.StatusOK, appendReq("line2\n", contentMD5("line2\n")))
+		assert.Equal(t, "line1\nline2\n", stored("log.txt"))
+	})
+
+	t.Run("upload part", func(t *testing.T) {
+		req, w := env.makeS3Request("POST", "/"+bucketName+"/parts.bin?uploads", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var initiated InitiateMultipartUploadResult
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+
+		uploadPart := func(digest string) *http.Response {
+			req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/parts.bin?partNumber=1&uploadId=%s", bucketName, initiated.UploadId), []byte("part data"))
+			req.Header.Set("Content-MD5", digest)
+			env.router.ServeHTTP(w, req)
+			return w.Result()
+		}
+		assert.Equal(t, http.StatusBadRequest, uploadPart(contentMD5("other")).StatusCode)
+		assert.Equal(t, http.StatusOK, uploadPart(contentMD5("part data")).StatusCode)
+	})
+
+	t.Run("bucket policy", func(t *testing.T) {
+		policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::` + bucketName + `/*"}]}`
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?policy", []byte(policy))
+		req.Header.Set("Content-MD5", contentMD5(policy+" "))
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "BadDigest")
+
+		req, w = env.makeS3Request("PUT", "/"+bucketName+"?policy", []byte(policy))
+		req.Header.Set("Content-MD5", contentMD5(policy))
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	})
+
+	t.Run("delete objects", func(t *testing.T) {
+		body := `<Delete><Object><Key>match.txt</Key></Object></Delete>`
+		req, w := env.makeS3Request("POST", "/"+bucketName+"?delete", []byte(body))
+		req.Header.Set("Content-MD5", contentMD5("<Delete></Delete>"))
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "hello world", stored("match.txt"))
+
+		req, w = env.makeS3Request("POST", "/"+bucketName+"?delete", []byte(body))
+		req.Header.Set("Content-MD5", contentMD5(body))
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Empty(t, stored("match.txt"))
+	})
+}
@@ -1,6 +1,11 @@
 package s3compat
 
 import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,3 +35,111 @@ func TestParseCopySourceHeader_InvalidEncoding(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestCopyObject_FromSourceVersion(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "copy-versions"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	setBucketVersioning(t, env, bucketName, "Enabled")
+
+	versions := make([]string, 3)
+	for i, body := range []string{"first", "second", "third"} {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/doc.txt", []byte(body))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		versions[i] = w.Header().Get("x-amz-version-id")
+	}
+
+	copyFrom := func(t *testing.T, destKey, source string) *httptest.ResponseRecorder {
+		t.Helper()
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+destKey, nil)
+		req.Header.Set("x-amz-copy-source", source)
+		env.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("an older version is copied to a new key", func(t *testing.T) {
+		w := copyFrom(t, "restored.txt", "/"+bucketName+"/doc.txt?versionId="+versions[1])
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, versions[1], w.Header().Get("x-amz-copy-source-version-id"))
+		destVersion := w.Header().Get("x-amz-version-id")
+		require.NotEmpty(t, destVersion)
+		assert.NotContains(t, versions, destVersion, "the destination gets a version of its own")
+
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/restored.txt", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "second", w.Body.String(), "the named version is copied, not the latest")
+		assert.Equal(t, "second", getVersionBody(t, env, bucketName, "restored.txt", destVersion))
+	})
+
+	t.Run("restoring a version onto its own key keeps the history", func(t *testing.T) {
+		w := copyFrom(t, "doc.txt", "/"+bucketName+"/doc.txt?versionId="+versions[0])
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		restored := w.Header().Get("x-amz-version-id")
+
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/doc.txt", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, "first", w.Body.String())
+		assert.Equal(t, restored, w.Header().Get("x-amz-version-id"))
+		for i, body := range []string{"first", "second", "third"} {
+			assert.Equal(t, body, getVersionBody(t, env, bucketName, "doc.txt", versions[i]))
+		}
+	})
+
+	t.Run("a missing version is NoSuchVersion", func(t *testing.T) {
+		w := copyFrom(t, "missing.txt", "/"+bucketName+"/doc.txt?versionId=does-not-exist")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "<Code>NoSuchVersion</Code>")
+	})
+
+	t.Run("a delete marker cannot be copied", func(t *testing.T) {
+		req, w := env.makeS3Request("DELETE", "/"+bucketName+"/gone.txt", nil)
+		env.router.ServeHTTP(w, req)
+		req, w = env.makeS3Request("PUT", "/"+bucketName+"/gone.txt", []byte("gone"))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		req, w = env.makeS3Request("DELETE", "/"+bucketName+"/gone.txt", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, "true", w.Header().Get("x-amz-delete-marker"))
+		marker := w.Header().Get("x-amz-version-id")
+
+		w = copyFrom(t, "copy-of-marker.txt", "/"+bucketName+"/gone.txt?versionId="+marker)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "<Code>InvalidRequest</Code>")
+	})
+
+	t.Run("UploadPartCopy reads the named version", func(t *testing.T) {
+		req, w := env.makeS3Request("POST", "/"+bucketName+"/assembled.txt?uploads", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var created struct {
+			UploadId string `xml:"UploadId"`
+		}
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &created))
+
+		req, w = env.makeS3Request("PUT", fmt.Sprintf("/%s/assembled.txt?partNumber=1&uploadId=%s", bucketName, created.UploadId), nil)
+		req.Header.Set("x-amz-copy-source", "/"+bucketName+"/doc.txt?versionId="+versions[2])
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var part struct {
+			ETag string `xml:"ETag"`
+		}
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &part))
+
+		req, w = env.makeS3Request("PUT", fmt.Sprintf("/%s/assembled.txt?partNumber=2&uploadId=%s", bucketName, created.UploadId), nil)
+		req.Header.Set("x-amz-copy-source", "/"+bucketName+"/doc.txt?versionId=does-not-exist")
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "<Code>NoSuchVersion</Code>")
+
+		completeXML := fmt.Sprintf(`<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>%s</ETag></Part></CompleteMultipartUpload>`, part.ETag)
+		req, w = env.makeS3Request("POST", fmt.Sprintf("/%s/assembled.txt?uploadId=%s", bucketName, created.UploadId), []byte(completeXML))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotContains(t, w.Body.String(), "<Error>")
+		assert.Equal(t, "third", getVersionBody(t, env, bucketName, "assembled.txt", w.Header().Get("x-amz-version-id")))
+	})
+}
@@ -0,0 +1,67 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/cluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staleReplicaClusterManager routes every read to a replica that still holds
+// the version from before the latest delete.
+type staleReplicaClusterManager struct {
+	mockClusterManager
+	proxied int
+}
+
+func (m *staleReplicaClusterManager) SelectReadNodes(_ context.Context, _ string) ([]*cluster.Node, error) {
+	return []*cluster.Node{{ID: "replica-1"}}, nil
+}
+
+func (m *staleReplicaClusterManager) TryProxyRead(_ context.Context, w http.ResponseWriter, _ *http.Request, _ *cluster.Node) (bool, error) {
+	m.proxied++
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("stale content")) //nolint:errcheck
+	return true, nil
+}
+
+func TestGetObject_DeleteMarkerNotServedByReplica(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "replicated-deletes"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"?versioning",
+		[]byte(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for _, key := range []string{"removed.txt", "kept.txt"} {
+		req, w = env.makeS3Request("PUT", "/"+bucketName+"/"+key, []byte("content"))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	req, w = env.makeS3Request("DELETE", "/"+bucketName+"/removed.txt", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	markerVersionID := w.Header().Get("x-amz-version-id")
+
+	cm := &staleReplicaClusterManager{mockClusterManager: mockClusterManager{enabled: true}}
+	env.handler.SetClusterManager(cm)
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/removed.txt", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "true", w.Header().Get("x-amz-delete-marker"))
+	assert.Equal(t, markerVersionID, w.Header().Get("x-amz-version-id"))
+	assert.Zero(t, cm.proxied, "a delete-marked key must not be read from a replica")
+
+	// Keys that still exist keep being load-balanced
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/kept.txt", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, cm.proxied)
+}
@@ -0,0 +1,273 @@
+package s3compat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/middleware"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultDownloadSessionTTL is how long a download session stays valid after
+// it is opened. Long enough for a multi-GB transfer over a slow satellite link.
+const defaultDownloadSessionTTL = 6 * time.Hour
+
+// Limits on open download sessions. Sessions live for hours, so without them
+// a client opening sessions in a loop would grow the store without bound.
+const (
+	maxDownloadSessionsPerOwner = 100
+	maxDownloadSessions         = 10000
+)
+
+// errTooManyDownloadSessions is returned by open once a limit is reached.
+var errTooManyDownloadSessions = errors.New("too many open download sessions")
+
+// downloadSession pins one object version for a resumable download so that an
+// overwrite mid-transfer cannot splice bytes from two different objects.
+type downloadSession struct {
+	owner      string // user ID, or client IP for anonymous requests
+	bucketPath string
+	objectKey  string
+	versionID  string // empty for non-versioned buckets; the ETag pins those
+	etag       string
+	size       int64
+	expiresAt  time.Time
+}
+
+// downloadSessionStore holds open download sessions in memory. Sessions are
+// node-local: cluster routing sends every request for a bucket to the same node.
+type downloadSessionStore struct {
+	mu          sync.Mutex
+	sessions    map[string]*downloadSession
+	ttl         time.Duration
+	maxPerOwner int
+	maxTotal    int
+}
+
+func newDownloadSessionStore(ttl time.Duration) *downloadSessionStore {
+	return &downloadSessionStore{
+		sessions:    make(map[string]*downloadSession),
+		ttl:         ttl,
+		maxPerOwner: maxDownloadSessionsPerOwner,
+		maxTotal:    maxDownloadSessions,
+	}
+}
+
+// open registers a session and returns its token. Expired sessions are purged
+// on the way; if the session's owner or the store still holds as many live
+// sessions as allowed, errTooManyDownloadSessions is returned.
+func (s *downloadSessionStore) open(sess *downloadSession) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	owned := 0
+	for t, existing := range s.sessions {
+		if now.After(existing.expiresAt) {
+			delete(s.sessions, t)
+		} else if existing.owner == sess.owner {
+			owned++
+		}
+	}
+	if owned >= s.maxPerOwner || len(s.sessions) >= s.maxTotal {
+		return "", errTooManyDownloadSessions
+	}
+
+	sess.expiresAt = now.Add(s.ttl)
+	s.sessions[token] = sess
+	return token, nil
+}
+
+// get returns the session for token, or nil if it does not exist or expired.
+func (s *downloadSessionStore) get(token string) *downloadSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[token]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(sess.expiresAt) {
+		delete(s.sessions, token)
+		return nil
+	}
+	return sess
+}
+
+// SetDownloadSessionTTL overrides how long newly opened download sessions stay valid.
+func (h *Handler) SetDownloadSessionTTL(ttl time.Duration) {
+	if ttl > 0 {
+		h.downloadSessions.mu.Lock()
+		h.downloadSessions.ttl = ttl
+		h.downloadSessions.mu.Unlock()
+	}
+}
+
+// DownloadSessionResult is the response body of OpenDownloadSession
+type DownloadSessionResult struct {
+	XMLName   xml.Name `xml:"DownloadSessionResult"`
+	Token     string   `xml:"Token"`
+	Bucket    string   `xml:"Bucket"`
+	Key       string   `xml:"Key"`
+	VersionId string   `xml:"VersionId,omitempty"`
+	ETag      string   `xml:"ETag"`
+	Size      int64    `xml:"Size"`
+	Expires   string   `xml:"Expires"`
+}
+
+// OpenDownloadSession handles POST /{bucket}/{object}?downloadSession.
+// It pins the current (or ?versionId=) version of the object and returns a
+// token; GET /{bucket}/{object}?downloadSession=<token>&offset=N then streams
+// that pinned version from byte N until the session expires.
+func (h *Handler) OpenDownloadSession(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucketName := vars["bucket"]
+	objectKey := getObjectKey(r)
+
+	addS3CompatHeaders(w)
+
+	if h.proxyBucketRequest(w, r, bucketName) {
+		return
+	}
+
+	user, userExists := auth.GetUserFromContext(r.Context())
+	if h.authManager != nil && userExists && r.Header.Get("Authorization") != "" {
+		if !auth.CheckCapabilityInContext(r.Context(), h.authManager, auth.CapObjectDownload) {
+			h.writeError(w, "AccessDenied", "You do not have permission to download objects", objectKey, r)
+			return
+		}
+	}
+
+	tenantID := h.resolveBucketTenantID(r, bucketName)
+	bucketPath := h.getBucketPath(r, bucketName)
+
+	if !h.validateHeadBucketReadPermission(w, r, user, userExists, tenantID, bucketName, objectKey) {
+		return
+	}
+
+	versionID := r.URL.Query().Get("versionId")
+	obj, reader, err := h.objectManager.GetObject(r.Context(), bucketPath, objectKey, versionID)
+	if reader != nil {
+		reader.Close()
+	}
+	if err != nil {
+		if err == object.ErrObjectNotFound {
+			if h.handleVersionedObjectNotFound(w, r, bucketPath, objectKey, versionID) {
+				return
+			}
+			h.writeError(w, "NoSuchKey", "The specified key does not exist", objectKey, r)
+			return
+		}
+		h.writeError(w, "InternalError", err.Error(), objectKey, r)
+		return
+	}
+
+	if !h.validateObjectReadPermission(w, r, user, userExists, false, "", tenantID, bucketPath, bucketName, objectKey) {
+		return
+	}
+
+	owner := "ip:" + middleware.ClientIP(r, h.trustedProxies)
+	if userExists {
+		owner = user.ID
+	}
+	sess := &downloadSession{
+		owner:      owner,
+		bucketPath: bucketPath,
+		objectKey:  objectKey,
+		versionID:  obj.VersionID,
+		etag:       obj.ETag,
+		size:       obj.Size,
+	}
+	if sess.versionID == "null" {
+		sess.versionID = ""
+	}
+	token, err := h.downloadSessions.open(sess)
+	if errors.Is(err, errTooManyDownloadSessions) {
+		h.writeError(w, "SlowDown", "Finish or let expire some download sessions before opening new ones", objectKey, r)
+		return
+	}
+	if err != nil {
+		h.writeError(w, "InternalError", err.Error(), objectKey, r)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"bucket":    bucketName,
+		"object":    objectKey,
+		"versionId": sess.versionID,
+		"expires":   sess.expiresAt,
+	}).Debug("Download session opened")
+
+	if sess.versionID != "" {
+		w.Header().Set("x-amz-version-id", sess.versionID)
+	}
+	h.writeXMLResponse(w, http.StatusOK, DownloadSessionResult{
+		Token:     token,
+		Bucket:    bucketName,
+		Key:       objectKey,
+		VersionId: sess.versionID,
+		ETag:      obj.ETag,
+		Size:      obj.Size,
+		Expires:   sess.expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// resolveDownloadSession looks up the ?downloadSession token of a GetObject
+// request. It returns the pinned session and the requested start offset, or
+// writes an error and returns ok=false. A request without the parameter
+// returns (nil, 0, true).
+func (h *Handler) resolveDownloadSession(w http.ResponseWriter, r *http.Request, bucketPath, objectKey string) (*downloadSession, int64, bool) {
+	q := r.URL.Query()
+	if !q.Has("downloadSession") {
+		return nil, 0, true
+	}
+
+	sess := h.downloadSessions.get(q.Get("downloadSession"))
+	if sess == nil || sess.bucketPath != bucketPath || sess.objectKey != objectKey {
+		h.writeError(w, "InvalidArgument", "The download session does not exist or has expired", objectKey, r)
+		return nil, 0, false
+	}
+
+	var offset int64
+	if raw := q.Get("offset"); raw != "" {
+		var err error
+		offset, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || offset < 0 {
+			h.writeError(w, "InvalidArgument", "offset must be a non-negative integer", objectKey, r)
+			return nil, 0, false
+		}
+	}
+	if offset > 0 && offset >= sess.size {
+		w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(sess.size, 10))
+		h.writeError(w, "InvalidRange", "offset is beyond the end of the object", objectKey, r)
+		return nil, 0, false
+	}
+
+	return sess, offset, true
+}
+
+// checkDownloadSessionObject verifies that the object read for a non-versioned
+// session is still the one that was pinned. Versioned sessions read the pinned
+// version directly, so the check only bites when the object was overwritten in place.
+func (h *Handler) checkDownloadSessionObject(w http.ResponseWriter, r *http.Request, sess *downloadSession, obj *object.Object) bool {
+	if normalizeETag(obj.ETag) == normalizeETag(sess.etag) {
+		return true
+	}
+	h.writeError(w, "PreconditionFailed", "The object changed after the download session was opened", sess.objectKey, r)
+	return false
+}
@@ -0,0 +1,182 @@
+package s3compat
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openDownloadSession opens a download session for bucket/key and returns the parsed result.
+func openDownloadSession(t *testing.T, env *s3TestEnv, bucketName, objectKey string) DownloadSessionResult {
+	t.Helper()
+	req, w := env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?downloadSession", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result DownloadSessionResult
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &result))
+	require.NotEmpty(t, result.Token)
+	return result
+}
+
+// setupDownloadSessionEnv adds the session route (not part of
+// setupCompleteS3Environment) and creates a bucket, optionally versioned.
+func setupDownloadSessionEnv(t *testing.T, versioned bool) (*s3TestEnv, string) {
+	env := setupCompleteS3Environment(t)
+	env.router.HandleFunc("/{bucket}/{object:.+}", env.handler.OpenDownloadSession).Methods("POST").Queries("downloadSession", "")
+
+	bucketName := "resume-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	if versioned {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?versioning",
+			[]byte(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	return env, bucketName
+}
+
+func putTestObject(t *testing.T, env *s3TestEnv, bucketName, objectKey string, body []byte) {
+	t.Helper()
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+objectKey, body)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestDownloadSession_ServesPinnedVersionAfterOverwrite(t *testing.T) {
+	env, bucketName := setupDownloadSessionEnv(t, true)
+	defer env.cleanup()
+
+	original := []byte("original payload streamed over a flaky link")
+	putTestObject(t, env, bucketName, "big.bin", original)
+
+	sess := openDownloadSession(t, env, bucketName, "big.bin")
+	assert.NotEmpty(t, sess.VersionId, "versioned bucket should pin a version id")
+	assert.Equal(t, int64(len(original)), sess.Size)
+
+	// First chunk succeeds, then the connection drops and the object is overwritten.
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/big.bin?downloadSession="+sess.Token+"&offset=0", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, original, w.Body.Bytes())
+
+	putTestObject(t, env, bucketName, "big.bin", []byte("REPLACED CONTENT THAT MUST NOT LEAK INTO THE RESUME"))
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/big.bin?downloadSession="+sess.Token+"&offset=9", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusPartialContent, w.Code, w.Body.String())
+	assert.Equal(t, original[9:], w.Body.Bytes())
+	assert.Equal(t, fmt.Sprintf("bytes 9-%d/%d", len(original)-1, len(original)), w.Header().Get("Content-Range"))
+
+	// A plain GET sees the new object.
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/big.bin", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "REPLACED")
+}
+
+func TestDownloadSession_UnversionedOverwriteFailsPrecondition(t *testing.T) {
+	env, bucketName := setupDownloadSessionEnv(t, false)
+	defer env.cleanup()
+
+	putTestObject(t, env, bucketName, "file.txt", []byte("first version of the file"))
+	sess := openDownloadSession(t, env, bucketName, "file.txt")
+	assert.Empty(t, sess.VersionId)
+
+	putTestObject(t, env, bucketName, "file.txt", []byte("second version of the file"))
+
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/file.txt?downloadSession="+sess.Token+"&offset=6", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code, "in-place overwrite must not be served as a resume")
+}
+
+func TestDownloadSession_InvalidTokenAndOffset(t *testing.T) {
+	env, bucketName := setupDownloadSessionEnv(t, true)
+	defer env.cleanup()
+
+	putTestObject(t, env, bucketName, "a.txt", []byte("0123456789"))
+	putTestObject(t, env, bucketName, "b.txt", []byte("abcdefghij"))
+	sess := openDownloadSession(t, env, bucketName, "a.txt")
+
+	t.Run("unknown token", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/a.txt?downloadSession=deadbeef", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("token for another key", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/b.txt?downloadSession="+sess.Token, nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("offset past end", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/a.txt?downloadSession="+sess.Token+"&offset=10", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	})
+
+	t.Run("negative offset", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/a.txt?downloadSession="+sess.Token+"&offset=-1", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDownloadSession_Expires(t *testing.T) {
+	env, bucketName := setupDownloadSessionEnv(t, true)
+	defer env.cleanup()
+
+	env.handler.SetDownloadSessionTTL(50 * time.Millisecond)
+	putTestObject(t, env, bucketName, "short.txt", []byte("short lived"))
+	sess := openDownloadSession(t, env, bucketName, "short.txt")
+
+	time.Sleep(100 * time.Millisecond)
+
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/short.txt?downloadSession="+sess.Token, nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDownloadSession_PerOwnerLimit(t *testing.T) {
+	env, bucketName := setupDownloadSessionEnv(t, false)
+	defer env.cleanup()
+
+	env.handler.downloadSessions.maxPerOwner = 2
+	putTestObject(t, env, bucketName, "a.txt", []byte("a"))
+	openDownloadSession(t, env, bucketName, "a.txt")
+	openDownloadSession(t, env, bucketName, "a.txt")
+
+	req, w := env.makeS3Request("POST", "/"+bucketName+"/a.txt?downloadSession", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>SlowDown</Code>")
+}
+
+func TestDownloadSessionStore_Limits(t *testing.T) {
+	store := newDownloadSessionStore(50 * time.Millisecond)
+	store.maxPerOwner = 2
+	store.maxTotal = 3
+
+	open := func(owner string) error {
+		_, err := store.open(&downloadSession{owner: owner})
+		return err
+	}
+	require.NoError(t, open("user-1"))
+	require.NoError(t, open("user-1"))
+	assert.ErrorIs(t, open("user-1"), errTooManyDownloadSessions, "per-owner limit")
+	require.NoError(t, open("user-2"))
+	assert.ErrorIs(t, open("user-3"), errTooManyDownloadSessions, "store limit")
+
+	// Expired sessions no longer count
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, open("user-1"))
+	assert.NoError(t, open("user-3"))
+}
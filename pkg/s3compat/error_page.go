@@ -0,0 +1,126 @@
+package s3compat
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Error page modes, see SetErrorPages.
+const (
+	errorPageXML      = "xml"
+	errorPageHTML     = "html"
+	errorPageRedirect = "redirect"
+)
+
+// errorPageTemplate is the page a browser gets instead of the S3 XML error.
+// It deliberately leaves out the message and resource, which can reveal
+// whether a bucket or key exists.
+var errorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Status}} {{.StatusText}}</title>
+<style>
+body{margin:0;min-height:100vh;display:flex;align-items:center;justify-content:center;background:#f4f5f7;color:#1f2933;font-family:system-ui,-apple-system,"Segoe UI",Roboto,sans-serif}
+main{max-width:28rem;padding:2rem 2.5rem;background:#fff;border-radius:8px;box-shadow:0 1px 3px rgba(0,0,0,.12)}
+h1{margin:0 0 .5rem;font-size:1.5rem}
+p{margin:0 0 1rem;line-height:1.5}
+small{color:#7b8794}
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Status}} {{.StatusText}}</h1>
+<p>{{.Hint}}</p>
+<small>{{.Code}} &middot; Request ID {{.RequestID}}</small>
+</main>
+</body>
+</html>
+`))
+
+// SetErrorPages sets how S3 errors are rendered for browsers: "html" returns
+// a minimal error page, "redirect" sends 401/403 errors to loginURL and
+// renders the others as "html". "xml" or empty keeps the S3 XML body.
+func (h *Handler) SetErrorPages(mode, loginURL string) {
+	if mode == errorPageXML {
+		mode = ""
+	}
+	h.errorPageMode = mode
+	h.errorPageLoginURL = loginURL
+}
+
+// writeErrorPage renders an error for a browser in place of the XML body and
+// reports whether it did. Called by writeError before the status is written.
+func (h *Handler) writeErrorPage(w http.ResponseWriter, r *http.Request, statusCode int, code, requestID string) bool {
+	if h.errorPageMode == "" || r == nil {
+		return false
+	}
+	// The same URL answers browsers and SDKs differently, so caches must
+	// key error responses on Accept
+	w.Header().Add("Vary", "Accept")
+	if !isBrowserRequest(r) {
+		return false
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if h.errorPageMode == errorPageRedirect && h.errorPageLoginURL != "" &&
+		(statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden) {
+		w.Header().Del("Content-Type")
+		http.Redirect(w, r, h.errorPageLoginURL, http.StatusFound)
+		return true
+	}
+
+	hint := "The request could not be completed."
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		hint = "You don't have permission to access this resource. Sign in or ask its owner for a share link."
+	case statusCode == http.StatusNotFound:
+		hint = "The resource you requested was not found."
+	case statusCode >= 500:
+		hint = "The server could not handle the request. Please try again later."
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+	errorPageTemplate.Execute(w, struct {
+		Status     int
+		StatusText string
+		Hint       string
+		Code       string
+		RequestID  string
+	}{statusCode, http.StatusText(statusCode), hint, code, requestID})
+	return true
+}
+
+// isBrowserRequest reports whether r looks like a browser navigating to a URL:
+// an unsigned GET whose Accept header prefers HTML over XML. SDKs sign with an
+// Authorization header and don't ask for text/html, so they keep getting XML.
+// Presigned URLs opened in a browser do count as browser requests.
+func isBrowserRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet || r.Header.Get("Authorization") != "" {
+		return false
+	}
+	var htmlQ, xmlQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if name, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(name, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/html", "application/xhtml+xml":
+			htmlQ = max(htmlQ, q)
+		case "application/xml", "text/xml":
+			xmlQ = max(xmlQ, q)
+		}
+	}
+	return htmlQ > 0 && htmlQ > xmlQ
+}
@@ -0,0 +1,125 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,*/*;q=0.8"
+
+// TestErrorPages_BrowserGetsHTMLSDKGetsXML tests that the same 403 is an HTML
+// page for a browser and S3 XML for an SDK client
+func TestErrorPages_BrowserGetsHTMLSDKGetsXML(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	env.handler.SetErrorPages("html", "")
+
+	bucketName := "private-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/secret.txt", []byte("secret"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// A browser opening the URL directly
+	req = httptest.NewRequest("GET", "/"+bucketName+"/secret.txt", nil)
+	req.Header.Set("Accept", browserAccept)
+	w = httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<!DOCTYPE html>")
+	assert.Contains(t, w.Body.String(), "403 Forbidden")
+	assert.NotContains(t, w.Body.String(), "secret.txt", "the page must not echo the resource")
+	assert.Contains(t, w.Header().Values("Vary"), "Accept")
+
+	// The same request from an SDK, which doesn't ask for HTML
+	for _, accept := range []string{"", "*/*", "application/xml"} {
+		req = httptest.NewRequest("GET", "/"+bucketName+"/secret.txt", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w = httptest.NewRecorder()
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, accept)
+		assert.Equal(t, "application/xml", w.Header().Get("Content-Type"), accept)
+		assert.Contains(t, w.Body.String(), "<Code>AccessDenied</Code>", accept)
+	}
+
+	// A signed request is programmatic whatever it accepts
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/missing.txt", nil)
+	req.Header.Set("Accept", browserAccept)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>NoSuchKey</Code>")
+}
+
+// TestErrorPages_Redirect tests that browsers are redirected to the login URL
+// on access errors only
+func TestErrorPages_Redirect(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	env.handler.SetErrorPages("redirect", "https://console.example.com/login")
+
+	bucketName := "private-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req := httptest.NewRequest("GET", "/"+bucketName+"/secret.txt", nil)
+	req.Header.Set("Accept", browserAccept)
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://console.example.com/login", w.Header().Get("Location"))
+
+	req = httptest.NewRequest("GET", "/"+bucketName+"/secret.txt", nil)
+	w = httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>AccessDenied</Code>")
+}
+
+// TestErrorPages_DisabledByDefault tests that browsers get XML unless error
+// pages are configured
+func TestErrorPages_DisabledByDefault(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "private-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req := httptest.NewRequest("GET", "/"+bucketName+"/secret.txt", nil)
+	req.Header.Set("Accept", browserAccept)
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>AccessDenied</Code>")
+	assert.NotContains(t, w.Header().Values("Vary"), "Accept")
+}
+
+func TestIsBrowserRequest(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{browserAccept, true},
+		{"text/html", true},
+		{"", false},
+		{"*/*", false},
+		{"application/xml, text/html;q=0.5", false},
+		{"text/html;q=0", false},
+		{"application/json", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/bucket/key", nil)
+		req.Header.Set("Accept", tt.accept)
+		assert.Equal(t, tt.want, isBrowserRequest(req), tt.accept)
+	}
+
+	req := httptest.NewRequest("HEAD", "/bucket/key", nil)
+	req.Header.Set("Accept", "text/html")
+	assert.False(t, isBrowserRequest(req), "HEAD")
+}
@@ -0,0 +1,58 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/eventlog"
+	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectEventsAreLogged(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	store, err := metadata.NewPebbleStore(metadata.PebbleOptions{DataDir: t.TempDir()})
+	require.NoError(t, err)
+	defer store.Close()
+	events := eventlog.New(store)
+	env.handler.SetEventLog(events)
+
+	ctx := context.Background()
+	bucketName := "event-log-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	bucketPath := env.tenantID + "/" + bucketName
+
+	_, cursor, _, err := events.List(ctx, bucketPath, "", 0)
+	require.NoError(t, err)
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/a.txt", []byte("hello"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req, w = env.makeS3Request("DELETE", "/"+bucketName+"/a.txt", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	logged, next, _, err := events.List(ctx, bucketPath, cursor, 0)
+	require.NoError(t, err)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "s3:ObjectCreated:Put", logged[0].EventName)
+	assert.Equal(t, "a.txt", logged[0].Key)
+	assert.Equal(t, int64(5), logged[0].Size)
+	assert.NotEmpty(t, logged[0].ETag)
+	assert.Equal(t, "s3:ObjectRemoved:Delete", logged[1].EventName)
+
+	// Polling from the returned cursor only yields later events
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/b.txt", []byte("world"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	logged, _, _, err = events.List(ctx, bucketPath, next, 0)
+	require.NoError(t, err)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "b.txt", logged[0].Key)
+}
@@ -0,0 +1,62 @@
+package s3compat
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestS3ListObjects_EmptyFolderMarker tests that a prefix existing only as a
+// zero-byte "folder/" marker object is listed as a common prefix, and the
+// marker as an object when listing inside it, as S3 does
+func TestS3ListObjects_EmptyFolderMarker(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "folders"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	for _, key := range []string{"empty/", "nested/inner/", "file.txt"} {
+		body := []byte("data")
+		if key != "file.txt" {
+			body = []byte{}
+		}
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+key, body)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	list := func(query string) ListBucketResultV2 {
+		t.Helper()
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/?list-type=2&delimiter=%2F"+query, nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result ListBucketResultV2
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+	prefixes := func(result ListBucketResultV2) []string {
+		out := []string{}
+		for _, cp := range result.CommonPrefixes {
+			out = append(out, cp.Prefix)
+		}
+		return out
+	}
+
+	root := list("")
+	assert.Equal(t, []string{"empty/", "nested/"}, prefixes(root))
+	require.Len(t, root.Contents, 1)
+	assert.Equal(t, "file.txt", root.Contents[0].Key)
+
+	nested := list("&prefix=nested%2F")
+	assert.Equal(t, []string{"nested/inner/"}, prefixes(nested))
+
+	inside := list("&prefix=empty%2F")
+	assert.Empty(t, inside.CommonPrefixes)
+	require.Len(t, inside.Contents, 1)
+	assert.Equal(t, "empty/", inside.Contents[0].Key)
+	assert.Equal(t, int64(0), inside.Contents[0].Size)
+}
@@ -0,0 +1,87 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteObject_GovernanceBypassNeedsExplicitPermission(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	bucketName := "governed-bucket"
+	req, w := env.makeS3Request("PUT", "/"+bucketName, nil)
+	req.Header.Set("x-amz-bucket-object-lock-enabled", "true")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// putLocked stores key under GOVERNANCE retention and returns its version
+	putLocked := func(key string) string {
+		t.Helper()
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+key, []byte("locked"))
+		req.Header.Set("x-amz-object-lock-mode", "GOVERNANCE")
+		req.Header.Set("x-amz-object-lock-retain-until-date", time.Now().Add(24*time.Hour).UTC().Format(time.RFC3339))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		versionID := w.Header().Get("x-amz-version-id")
+		require.NotEmpty(t, versionID)
+		return versionID
+	}
+	deleteVersion := func(key, versionID, accessKey, secretKey string) int {
+		req, w := makeSignedRequest("DELETE", "/"+bucketName+"/"+key+"?versionId="+versionID, nil, accessKey, secretKey)
+		req.Header.Set("x-amz-bypass-governance-retention", "true")
+		env.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("key without the bypass permission cannot delete", func(t *testing.T) {
+		// env's user is an admin, which alone no longer allows the bypass
+		versionID := putLocked("no-permission.txt")
+		assert.Equal(t, http.StatusForbidden, deleteVersion("no-permission.txt", versionID, env.accessKey, env.secretKey))
+
+		req, w := env.makeS3Request("HEAD", "/"+bucketName+"/no-permission.txt", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "the locked object is still there")
+	})
+
+	t.Run("key with the bypass capability can delete", func(t *testing.T) {
+		require.NoError(t, env.authManager.SetCapabilityOverride(ctx, env.userID, auth.CapObjectBypassGovernance, "test", true))
+		defer env.authManager.DeleteCapabilityOverride(ctx, env.userID, auth.CapObjectBypassGovernance)
+
+		versionID := putLocked("with-capability.txt")
+		assert.Equal(t, http.StatusNoContent, deleteVersion("with-capability.txt", versionID, env.accessKey, env.secretKey))
+	})
+
+	t.Run("bucket policy delegates the bypass to one user", func(t *testing.T) {
+		partnerID, partnerKey, partnerSecret := createForeignUser(t, env)
+		putBucketPolicy(t, env, bucketName, `{
+			"Version": "2012-10-17",
+			"Statement": [{
+				"Effect": "Allow",
+				"Principal": {"AWS": ["`+partnerID+`"]},
+				"Action": ["s3:DeleteObject", "s3:BypassGovernanceRetention"],
+				"Resource": "arn:aws:s3:::`+bucketName+`/delegated/*"
+			}, {
+				"Effect": "Deny",
+				"Principal": {"AWS": ["`+env.userID+`"]},
+				"Action": "s3:BypassGovernanceRetention",
+				"Resource": "arn:aws:s3:::`+bucketName+`/*"
+			}]
+		}`)
+
+		versionID := putLocked("delegated/report.txt")
+		assert.Equal(t, http.StatusNoContent, deleteVersion("delegated/report.txt", versionID, partnerKey, partnerSecret))
+
+		// An explicit Deny in the policy overrides the capability
+		require.NoError(t, env.authManager.SetCapabilityOverride(ctx, env.userID, auth.CapObjectBypassGovernance, "test", true))
+		versionID = putLocked("denied.txt")
+		assert.Equal(t, http.StatusForbidden, deleteVersion("denied.txt", versionID, env.accessKey, env.secretKey))
+	})
+}
@@ -0,0 +1,159 @@
+package s3compat
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/maxiofs/maxiofs/internal/lru"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/sirupsen/logrus"
+)
+
+// SetGzipTranscoding turns decompressive transcoding on or off: while it is
+// on, GetObject decodes objects stored with Content-Encoding gzip for
+// clients whose Accept-Encoding rules gzip out, ranges included.
+func (h *Handler) SetGzipTranscoding(enabled bool) {
+	h.gzipTranscoding = enabled
+}
+
+// isGzipEncoded reports whether obj was stored gzip-compressed as a whole.
+func isGzipEncoded(obj *object.Object) bool {
+	enc := strings.ToLower(strings.TrimSpace(obj.ContentEncoding))
+	return enc == "gzip" || enc == "x-gzip"
+}
+
+// acceptsGzip reports whether an Accept-Encoding header value allows a
+// gzip-encoded response. No header means any coding is acceptable.
+func acceptsGzip(header string) bool {
+	if strings.TrimSpace(header) == "" {
+		return true
+	}
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+		accepted := true
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				q, err := strconv.ParseFloat(value, 64)
+				accepted = err == nil && q > 0
+			}
+		}
+		if coding == "*" {
+			wildcard = accepted
+			continue
+		}
+		// An explicit gzip entry overrides the wildcard
+		return accepted
+	}
+	return wildcard
+}
+
+// shouldTranscodeGzip reports whether a GET of obj is to be decoded for the
+// client making r.
+func (h *Handler) shouldTranscodeGzip(r *http.Request, obj *object.Object) bool {
+	return h.gzipTranscoding && isGzipEncoded(obj) && !acceptsGzip(r.Header.Get("Accept-Encoding"))
+}
+
+// newGzipTranscoder returns a reader decoding the gzip stream read from
+// reader. When the data isn't gzip after all it returns a reader of the
+// stored bytes, unchanged, and false.
+func newGzipTranscoder(reader io.Reader) (io.Reader, bool) {
+	buffered := bufio.NewReader(reader)
+	if magic, err := buffered.Peek(2); err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return buffered, false
+	}
+	decoded, err := gzip.NewReader(buffered)
+	if err != nil {
+		return buffered, false
+	}
+	return decoded, true
+}
+
+// gzipRangeable reports whether the decoded form of a gzip-stored object can
+// be served in ranges, which needs its length from the gzip trailer: 18 bytes
+// is the smallest header and trailer around an empty deflate stream, and the
+// trailer only holds lengths below 4 GiB.
+func gzipRangeable(obj *object.Object) bool {
+	return obj.Size >= 18 && obj.Size < 1<<32
+}
+
+// gzipSizeCacheEntries bounds how many decoded lengths gzipDecodedSize keeps
+const gzipSizeCacheEntries = 4096
+
+func newGzipSizeCache() *lru.Cache[string, int64] {
+	return lru.New[string, int64](gzipSizeCacheEntries)
+}
+
+// gzipDecodedSize returns the decoded length of a gzip-stored object, read
+// from the ISIZE field of its trailer without decompressing anything. ISIZE
+// holds the length modulo 4 GiB of the last gzip member only, so the result
+// is right for a single-member stream (what gzip tools and libraries write)
+// of less than 4 GiB. It returns -1 when the length can't be had that way:
+// stored data of 4 GiB or more, or an object that changed since obj was read.
+//
+// Reaching the trailer means reading the stored object through, so lengths
+// are cached per version and ETag, and only the first ranged GET of an
+// object pays for it.
+func (h *Handler) gzipDecodedSize(ctx context.Context, bucketPath, objectKey string, obj *object.Object) int64 {
+	if !gzipRangeable(obj) {
+		return -1
+	}
+	cacheKey := bucketPath + "\x00" + objectKey + "\x00" + obj.VersionID + "\x00" + obj.ETag
+	if size, ok := h.gzipSizes.Get(cacheKey); ok {
+		return size
+	}
+	size := h.readGzipISIZE(ctx, bucketPath, objectKey, obj)
+	if size >= 0 {
+		h.gzipSizes.Put(cacheKey, size)
+	}
+	return size
+}
+
+// readGzipISIZE reads the ISIZE field at the end of obj's stored data
+func (h *Handler) readGzipISIZE(ctx context.Context, bucketPath, objectKey string, obj *object.Object) int64 {
+	current, reader, err := h.objectManager.GetObject(ctx, bucketPath, objectKey, obj.VersionID)
+	if err != nil {
+		return -1
+	}
+	defer reader.Close()
+	if current.ETag != obj.ETag {
+		return -1
+	}
+	if _, err := io.CopyN(io.Discard, reader, obj.Size-4); err != nil {
+		logrus.WithError(err).WithField("key", objectKey).Warn("Failed to read gzip trailer")
+		return -1
+	}
+	var isize [4]byte
+	if _, err := io.ReadFull(reader, isize[:]); err != nil {
+		logrus.WithError(err).WithField("key", objectKey).Warn("Failed to read gzip trailer")
+		return -1
+	}
+	return int64(binary.LittleEndian.Uint32(isize[:]))
+}
+
+// setGzipTranscodedHeaders turns the stored object's response headers into
+// those of its decoded form: no Content-Encoding, no checksum, which is of
+// the stored bytes, and no Accept-Ranges unless the decoded bytes can be
+// served in ranges.
+func setGzipTranscodedHeaders(w http.ResponseWriter, rangeable bool) {
+	w.Header().Del("Content-Encoding")
+	if !rangeable {
+		w.Header().Del("Accept-Ranges")
+	}
+	for name := range w.Header() {
+		if strings.HasPrefix(strings.ToLower(name), "x-amz-checksum-") {
+			w.Header().Del(name)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package s3compat
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObject_GzipTranscodingRange(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	env.handler.SetGzipTranscoding(true)
+
+	bucketName := "gzip-transcoding"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	var plain bytes.Buffer
+	for i := 0; plain.Len() < 100_000; i++ {
+		fmt.Fprintf(&plain, "line %06d of the access log\n", i)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write(plain.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	total := int64(plain.Len())
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/access.log", compressed.Bytes())
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Content-Type", "text/plain")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	get := func(rangeHeader, acceptEncoding string) *http.Response {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/access.log", nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		env.router.ServeHTTP(w, req)
+		return w.Result()
+	}
+	readBody := func(resp *http.Response) []byte {
+		var buf bytes.Buffer
+		_, err := buf.ReadFrom(resp.Body)
+		require.NoError(t, err)
+		return buf.Bytes()
+	}
+
+	cases := []struct {
+		rangeHeader string
+		start, end  int64
+	}{
+		{"bytes=10-19", 10, 19},
+		{"bytes=50000-50999", 50000, 50999},
+		{"bytes=-25", total - 25, total - 1},
+		{fmt.Sprintf("bytes=%d-", total-7), total - 7, total - 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.rangeHeader, func(t *testing.T) {
+			resp := get(tc.rangeHeader, "identity")
+			require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+			assert.Equal(t, fmt.Sprintf("bytes %d-%d/%d", tc.start, tc.end, total), resp.Header.Get("Content-Range"))
+			assert.Equal(t, strconv.FormatInt(tc.end-tc.start+1, 10), resp.Header.Get("Content-Length"))
+			assert.Empty(t, resp.Header.Get("Content-Encoding"))
+			assert.Equal(t, plain.Bytes()[tc.start:tc.end+1], readBody(resp))
+		})
+	}
+
+	t.Run("unsatisfiable range uses the decoded length", func(t *testing.T) {
+		resp := get(fmt.Sprintf("bytes=%d-", total+10), "identity")
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+		assert.Equal(t, fmt.Sprintf("bytes */%d", total), resp.Header.Get("Content-Range"))
+	})
+
+	t.Run("decoded length is read once per version", func(t *testing.T) {
+		assert.Equal(t, 1, env.handler.gzipSizes.Stats().Entries)
+		resp := get("bytes=0-9", "identity")
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, 1, env.handler.gzipSizes.Stats().Entries)
+	})
+
+	t.Run("whole object decoded", func(t *testing.T) {
+		resp := get("", "identity")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assert.Contains(t, resp.Header.Values("Vary"), "Accept-Encoding")
+		assert.Equal(t, plain.Bytes(), readBody(resp))
+	})
+
+	t.Run("gzip clients get the stored bytes", func(t *testing.T) {
+		for _, accept := range []string{"", "gzip, deflate", "br;q=1.0, *;q=0.5"} {
+			resp := get("bytes=0-9", accept)
+			require.Equal(t, http.StatusPartialContent, resp.StatusCode, accept)
+			assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"), accept)
+			assert.Equal(t, fmt.Sprintf("bytes 0-9/%d", compressed.Len()), resp.Header.Get("Content-Range"), accept)
+			assert.Equal(t, compressed.Bytes()[:10], readBody(resp), accept)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		env.handler.SetGzipTranscoding(false)
+		defer env.handler.SetGzipTranscoding(true)
+		resp := get("bytes=0-9", "identity")
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+		assert.Equal(t, compressed.Bytes()[:10], readBody(resp))
+	})
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, want := range map[string]bool{
+		"":                   true,
+		"gzip":               true,
+		"GZIP;q=0.8":         true,
+		"identity":           false,
+		"gzip;q=0":           false,
+		"*":                  true,
+		"*;q=0":              false,
+		"gzip;q=0, *":        false,
+		"br, *;q=0.1":        true,
+		"deflate, br":        false,
+		"identity, x-gzip":   true,
+		"x-gzip;q=0.0, br":   false,
+		"gzip;q=invalid, br": false,
+	} {
+		assert.Equal(t, want, acceptsGzip(header), header)
+	}
+}
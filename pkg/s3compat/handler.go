@@ -3,10 +3,8 @@ package s3compat
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
 	"crypto/tls"
 	"encoding/base64"
-	"encoding/hex"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -24,11 +22,15 @@ import (
 	"github.com/maxiofs/maxiofs/internal/bandwidth"
 	"github.com/maxiofs/maxiofs/internal/bucket"
 	"github.com/maxiofs/maxiofs/internal/cluster"
+	"github.com/maxiofs/maxiofs/internal/eventlog"
 	"github.com/maxiofs/maxiofs/internal/inventory"
+	"github.com/maxiofs/maxiofs/internal/lru"
 	"github.com/maxiofs/maxiofs/internal/metadata"
+	"github.com/maxiofs/maxiofs/internal/middleware"
 	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/maxiofs/maxiofs/internal/presigned"
 	"github.com/maxiofs/maxiofs/internal/share"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 )
@@ -58,36 +60,16 @@ func s3URLEncode(s string) string {
 	return buf.String()
 }
 
-// generateRequestID generates a SHORT request ID (like MaxIOFS does)
-// MaxIOFS uses 16 character hex strings, not 32
-func generateRequestID() string {
-	b := make([]byte, 8) // 8 bytes = 16 hex chars
-	rand.Read(b)
-	return strings.ToUpper(hex.EncodeToString(b))
-}
-
-// generateAmzId2 generates a host ID for x-amz-id-2 (base64-encoded like AWS S3 and MinIO)
-func generateAmzId2() string {
-	b := make([]byte, 48) // 48 bytes → 64-char base64
-	rand.Read(b)
-	return base64.StdEncoding.EncodeToString(b)
-}
-
 // addS3CompatHeaders adds S3-compatible headers to all responses
 // This ensures compatibility with Veeam and other S3 clients
 func addS3CompatHeaders(w http.ResponseWriter) {
-	// x-amz-request-id: SHORT request ID (16 chars like MaxIOFS)
-	w.Header().Set("X-Amz-Request-Id", generateRequestID())
-
-	// x-amz-id-2: LONG host ID hash (64 chars like MaxIOFS)
-	w.Header().Set("X-Amz-Id-2", generateAmzId2())
+	// x-amz-request-id and x-amz-id-2: the request's own, assigned by the
+	// request ID middleware
+	middleware.AmzRequestIDs(w)
 
 	// Server header identifying as MaxIOFS
 	w.Header().Set("Server", "MaxIOFS")
 
-	// Accept ranges for partial content
-	w.Header().Set("Accept-Ranges", "bytes")
-
 	// Security headers (S3-compatible)
 	w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
@@ -117,8 +99,10 @@ type Handler struct {
 		DeleteConfigByID(ctx context.Context, id, tenantID string) error
 	}
 	metadataStore interface {
+		GetObject(ctx context.Context, bucket, key string, versionID ...string) (*metadata.ObjectMetadata, error)
 		ListAllObjectVersions(ctx context.Context, bucket, prefix string, maxKeys int) ([]*metadata.ObjectVersion, error)
 		GetBucketByName(ctx context.Context, name string) (*metadata.BucketMetadata, error)
+		BucketExists(ctx context.Context, tenantID, name string) (bool, error)
 		GetMultipartUpload(ctx context.Context, uploadID string) (*metadata.MultipartUploadMetadata, error)
 	}
 	clusterManager interface {
@@ -141,10 +125,44 @@ type Handler struct {
 	replicationManager interface {
 		QueueRealtimeObject(ctx context.Context, tenantID, bucket, objectKey, action string) error
 	}
+	eventLog interface {
+		Append(ctx context.Context, bucketPath string, ev eventlog.Event) error
+	}
 	publicAPIURL     string
-	dataDir          string            // For calculating disk capacity in SOSAPI
-	notifHTTPClient  *http.Client      // HTTP client for notification webhooks; defaults to SSRF-blocking client
-	bandwidthManager *bandwidth.Manager // Per-tenant aggregate transfer throttling; nil = disabled
+	dataDir          string                // For calculating disk capacity in SOSAPI
+	notifHTTPClient  *http.Client          // HTTP client for notification webhooks; defaults to SSRF-blocking client
+	bandwidthManager *bandwidth.Manager    // Per-tenant aggregate transfer throttling; nil = disabled
+	downloadSessions *downloadSessionStore // Resumable download sessions pinned to one object version
+	clockSkew        time.Duration         // Allowed presigned X-Amz-Date drift from the server clock
+	presignedMaxAge  int64                 // Longest accepted X-Amz-Expires in seconds; 0 = SigV4's 7 days
+	defaultRegion    string                // Presigned URL region for buckets without one
+	signingService   string                // SigV4 service presigned URLs are signed for
+	trustedProxies   []string              // Proxies whose X-Forwarded-* headers are honoured (policy conditions)
+
+	// listTimeout bounds how long one listing request may scan the metadata
+	// store; 0 means only a client disconnect stops it.
+	listTimeout time.Duration
+
+	// errorPageMode and errorPageLoginURL decide what browsers get for S3
+	// errors (see SetErrorPages); empty mode means XML for everyone.
+	errorPageMode     string
+	errorPageLoginURL string
+
+	// restores tracks thaws of archived objects running on this node;
+	// coldReads is what reads do with an unrestored one (SetColdReads)
+	restores  *restoreTracker
+	coldReads string
+
+	// gzipTranscoding decodes gzip-stored objects for clients that don't
+	// accept gzip (SetGzipTranscoding)
+	gzipTranscoding bool
+	// gzipSizes caches the decoded lengths of gzip-stored objects, keyed by
+	// version and ETag, for ranged GETs of transcoded objects
+	gzipSizes *lru.Cache[string, int64]
+
+	// batchDeleteWorkers is how many keys of one DeleteObjects request are
+	// deleted at once
+	batchDeleteWorkers int
 }
 
 // NewHandler creates a new S3 compatibility handler
@@ -153,6 +171,16 @@ func NewHandler(bucketManager bucket.Manager, objectManager object.Manager) *Han
 		bucketManager: bucketManager,
 		objectManager: objectManager,
 		shareManager:  nil, // Optional, will be set via SetShareManager
+
+		downloadSessions: newDownloadSessionStore(defaultDownloadSessionTTL),
+		clockSkew:        auth.DefaultClockSkew,
+		defaultRegion:    presigned.DefaultRegion,
+		signingService:   presigned.DefaultService,
+		restores:         newRestoreTracker(),
+		gzipSizes:        newGzipSizeCache(),
+		coldReads:        coldReadsAllow,
+
+		batchDeleteWorkers: defaultBatchDeleteWorkers,
 	}
 }
 
@@ -201,6 +229,77 @@ func (h *Handler) SetDataDir(dataDir string) {
 	h.dataDir = dataDir
 }
 
+// SetTrustedProxies sets the reverse proxies (IPs or CIDRs) whose X-Forwarded-For
+// and X-Forwarded-Proto headers are trusted when evaluating bucket policy conditions.
+func (h *Handler) SetTrustedProxies(proxies []string) {
+	h.trustedProxies = proxies
+}
+
+// SetClockSkew sets how far a presigned URL's X-Amz-Date may drift from the
+// server clock. Header-signed requests are checked by the auth manager.
+func (h *Handler) SetClockSkew(skew time.Duration) {
+	if skew > 0 {
+		h.clockSkew = skew
+	}
+}
+
+// SetPresignedMaxExpiry caps the X-Amz-Expires a presigned URL may carry, in
+// seconds. 0 keeps SigV4's 7-day limit, which also bounds larger values.
+func (h *Handler) SetPresignedMaxExpiry(seconds int64) {
+	h.presignedMaxAge = seconds
+}
+
+// presignedExpiryAllowed reports whether an X-Amz-Expires value lies within
+// the configured presigned URL lifetime.
+func (h *Handler) presignedExpiryAllowed(expiresIn int64) bool {
+	return expiresIn > 0 && expiresIn <= presigned.EffectiveMaxExpiration(h.presignedMaxAge)
+}
+
+// SetSigningDefaults sets the region presigned URLs are scoped to when the
+// bucket has none, and the SigV4 service they are signed for. Empty values
+// keep the defaults (us-east-1 and s3).
+func (h *Handler) SetSigningDefaults(region, service string) {
+	if region != "" {
+		h.defaultRegion = region
+	}
+	if service != "" {
+		h.signingService = service
+	}
+}
+
+// SetListTimeout sets the per-request deadline for ListObjects,
+// ListObjectsV2 and ListObjectVersions. 0 disables it.
+func (h *Handler) SetListTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		h.listTimeout = timeout
+	}
+}
+
+// listContext returns the context a listing scans under: the request's own,
+// which is cancelled when the client disconnects, bounded by listTimeout.
+func (h *Handler) listContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if h.listTimeout > 0 {
+		return context.WithTimeout(r.Context(), h.listTimeout)
+	}
+	return context.WithCancel(r.Context())
+}
+
+// writeListAborted handles a listing that stopped because its context ended
+// and reports whether err was such an error. A listing that ran past
+// listTimeout gets SlowDown so SDKs back off and retry; a client that
+// disconnected gets nothing, as nobody is left to read the response.
+func (h *Handler) writeListAborted(w http.ResponseWriter, r *http.Request, err error, bucketName string) bool {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		h.writeError(w, "SlowDown", "The listing exceeded the server time limit. Narrow the prefix or reduce max-keys.", bucketName, r)
+		return true
+	case errors.Is(err, context.Canceled):
+		logrus.WithField("bucket", bucketName).Debug("S3 API: listing aborted, client disconnected")
+		return true
+	}
+	return false
+}
+
 // SetInventoryManager sets the inventory manager for S3 BucketInventory operations
 func (h *Handler) SetInventoryManager(im interface {
 	GetConfigByID(ctx context.Context, id, tenantID string) (*inventory.InventoryConfig, error)
@@ -285,6 +384,13 @@ func (h *Handler) SetReplicationManager(rm interface {
 	h.replicationManager = rm
 }
 
+// SetEventLog sets the log object events are recorded in for polling clients
+func (h *Handler) SetEventLog(el interface {
+	Append(ctx context.Context, bucketPath string, ev eventlog.Event) error
+}) {
+	h.eventLog = el
+}
+
 // proxyBucketRequest checks if the given bucket should be routed to a remote cluster node
 // and, if so, proxies the request there, writing the response to w and returning true.
 // Returns false when the request should be handled locally.
@@ -344,8 +450,10 @@ func (h *Handler) proxyBucketRequest(w http.ResponseWriter, r *http.Request, buc
 
 // SetMetadataStore sets the metadata store for accessing object versions
 func (h *Handler) SetMetadataStore(ms interface {
+	GetObject(ctx context.Context, bucket, key string, versionID ...string) (*metadata.ObjectMetadata, error)
 	ListAllObjectVersions(ctx context.Context, bucket, prefix string, maxKeys int) ([]*metadata.ObjectVersion, error)
 	GetBucketByName(ctx context.Context, name string) (*metadata.BucketMetadata, error)
+	BucketExists(ctx context.Context, tenantID, name string) (bool, error)
 	GetMultipartUpload(ctx context.Context, uploadID string) (*metadata.MultipartUploadMetadata, error)
 }) {
 	h.metadataStore = ms
@@ -535,70 +643,105 @@ func (h *Handler) ListBuckets(w http.ResponseWriter, r *http.Request) {
 	if isGlobalAdmin {
 		// ONLY global admins see all buckets (already filtered by tenantID="" at manager level)
 		filteredBuckets = buckets
-	} else if user.TenantID != "" {
-		// Tenant users (including tenant admins) see their tenant's buckets + buckets where they have permissions
+	} else {
+		// Everyone else sees the buckets they own (directly or through their tenant)
+		// plus buckets they were granted access to. Permissions are checked against
+		// each bucket's owning tenant, never the caller's.
 		for _, b := range buckets {
-			// Include if bucket belongs to tenant or user owns it
-			if (b.OwnerType == "tenant" && b.OwnerID == user.TenantID) ||
+			if (user.TenantID != "" && b.OwnerType == "tenant" && b.OwnerID == user.TenantID) ||
 				(b.OwnerType == "user" && b.OwnerID == user.ID) {
 				filteredBuckets = append(filteredBuckets, b)
 				continue
 			}
 
-			// Include if user has permissions in bucket policy
-			if h.userHasBucketPermission(r, tenantID, b.Name, user.ID) {
+			// Include if user has permissions via grants or bucket policy
+			if h.userHasBucketPermission(r, b.TenantID, b.Name, user.ID) {
 				filteredBuckets = append(filteredBuckets, b)
 			}
 		}
-	} else {
-		// Non-admin users without tenant: see their buckets + buckets where they have permissions
-		for _, b := range buckets {
-			// Include if user owns the bucket
-			if b.OwnerType == "user" && b.OwnerID == user.ID {
-				filteredBuckets = append(filteredBuckets, b)
-				continue
-			}
 
-			// Include if user has permissions in bucket policy
-			if h.userHasBucketPermission(r, tenantID, b.Name, user.ID) {
-				filteredBuckets = append(filteredBuckets, b)
-			}
-		}
+		// Buckets of other tenants are not in the tenant-scoped listing above;
+		// add the ones shared with this user through permission grants.
+		filteredBuckets = append(filteredBuckets, h.grantedForeignBuckets(r, user, filteredBuckets)...)
+	}
+
+	owner := Owner{
+		ID:          user.ID,
+		DisplayName: user.DisplayName,
+	}
+	if owner.DisplayName == "" {
+		owner.DisplayName = user.Username
 	}
 
 	result := ListAllMyBucketsResult{
-		Owner: Owner{
-			ID:          user.ID,
-			DisplayName: user.DisplayName,
-		},
+		Owner: owner,
 		Buckets: Buckets{
 			Bucket: make([]BucketInfo, len(filteredBuckets)),
 		},
 	}
 
 	for i, bucket := range filteredBuckets {
+		region := bucket.Region
+		if region == "" {
+			region = "us-east-1"
+		}
 		result.Buckets.Bucket[i] = BucketInfo{
 			Name:         bucket.Name,
 			CreationDate: bucket.CreatedAt,
-			BucketRegion: "us-east-1",
+			BucketRegion: region,
 		}
 	}
 
 	h.writeXMLResponse(w, http.StatusOK, result)
 }
 
+// grantedForeignBuckets returns buckets owned by tenants other than the
+// user's that the user can read through an unexpired permission grant.
+// Buckets already present in listed are skipped.
+func (h *Handler) grantedForeignBuckets(r *http.Request, user *auth.User, listed []bucket.Bucket) []bucket.Bucket {
+	if h.authManager == nil {
+		return nil
+	}
+	perms, err := h.authManager.ListUserBucketPermissions(r.Context(), user.ID)
+	if err != nil {
+		logrus.WithContext(r.Context()).WithError(err).Warn("ListBuckets: failed to list bucket permission grants")
+		return nil
+	}
+
+	seen := make(map[string]bool, len(listed))
+	for _, b := range listed {
+		seen[b.TenantID+"/"+b.Name] = true
+	}
+
+	var granted []bucket.Bucket
+	for _, perm := range perms {
+		key := perm.BucketTenantID + "/" + perm.BucketName
+		if perm.BucketTenantID == user.TenantID || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !h.checkBucketGrant(r.Context(), perm.BucketTenantID, perm.BucketName, user.ID, acl.PermissionRead) {
+			continue
+		}
+		b, err := h.bucketManager.GetBucketInfo(r.Context(), perm.BucketTenantID, perm.BucketName)
+		if err != nil {
+			// Bucket deleted since the grant was made, or owned by another node
+			continue
+		}
+		granted = append(granted, *b)
+	}
+	return granted
+}
+
 // userHasBucketPermission checks if user has explicit permissions (ACLs or Policy)
 func (h *Handler) userHasBucketPermission(r *http.Request, tenantID, bucketName, userID string) bool {
 	ctx := context.Background()
 	if r != nil {
 		ctx = r.Context()
 	}
-	// Check bucket permissions table (frontend ACLs)
-	if h.authManager != nil {
-		hasAccess, _, err := h.authManager.CheckBucketAccess(ctx, bucketName, userID)
-		if err == nil && hasAccess {
-			return true
-		}
+	// Check bucket permission grants, scoped to the bucket's owning tenant
+	if h.checkBucketGrant(ctx, tenantID, bucketName, userID, acl.PermissionRead) {
+		return true
 	}
 
 	// Check bucket policy (S3 style)
@@ -635,23 +778,101 @@ func (h *Handler) checkBucketPolicyPermission(r *http.Request, tenantID, bucketN
 		resource = fmt.Sprintf("arn:aws:s3:::%s/*", bucketName)
 	}
 
-	// Build request context with IP and TLS info from the HTTP request
+	if !bucket.IsActionAllowed(ctx, policy, h.policyEvaluationRequest(r, bucketName, userID, action, resource)) {
+		return false
+	}
+	return !h.restrictsPublicPolicy(ctx, tenantID, bucketName, policy)
+}
+
+// policyEvaluationRequest builds the evaluator input for a bucket policy check.
+// When r is non-nil, the condition context is filled in: aws:SourceIp (client IP,
+// honouring X-Forwarded-For only from peers listed in trusted_proxies, not from
+// any private address), aws:SecureTransport and,
+// for listings, s3:prefix / s3:delimiter / s3:max-keys from the query string.
+func (h *Handler) policyEvaluationRequest(r *http.Request, bucketName, principal, action, resource string) bucket.PolicyEvaluationRequest {
 	request := bucket.PolicyEvaluationRequest{
-		Principal: userID,
+		Principal: principal,
 		Action:    action,
 		Resource:  resource,
 		Bucket:    bucketName,
 	}
 	if r != nil {
-		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
-			request.SourceIP = host
-		} else {
-			request.SourceIP = r.RemoteAddr
+		request.SourceIP = middleware.PolicyClientIP(r, h.trustedProxies)
+		request.SecureTransport = middleware.PolicySecureTransport(r, h.trustedProxies)
+
+		q := r.URL.Query()
+		for _, key := range []string{"prefix", "delimiter", "max-keys"} {
+			if q.Has(key) {
+				if request.RequestContext == nil {
+					request.RequestContext = make(map[string]string)
+				}
+				request.RequestContext["s3:"+key] = q.Get(key)
+			}
 		}
-		request.SecureTransport = r.TLS != nil
 	}
+	return request
+}
+
+// policyDecision evaluates the bucket policy for action on resource.
+// No policy yields DecisionDeny (implicit).
+func (h *Handler) policyDecision(r *http.Request, tenantID, bucketName, principal, action, resource string) bucket.PolicyDecision {
+	if h.bucketManager == nil {
+		return bucket.DecisionDeny
+	}
+
+	policy, err := h.bucketManager.GetBucketPolicy(r.Context(), tenantID, bucketName)
+	if err != nil || policy == nil {
+		return bucket.DecisionDeny
+	}
+
+	decision := bucket.EvaluatePolicy(r.Context(), policy, h.policyEvaluationRequest(r, bucketName, principal, action, resource))
+	if decision == bucket.DecisionAllow && h.restrictsPublicPolicy(r.Context(), tenantID, bucketName, policy) {
+		// RestrictPublicBuckets: a public policy only grants the owning tenant
+		return bucket.DecisionDeny
+	}
+	return decision
+}
+
+// objectPolicyDecision evaluates the bucket policy for an object-level action
+// against the object's own ARN (arn:aws:s3:::bucket/key), so statements whose
+// Resource is scoped to a key prefix such as "arn:aws:s3:::bucket/teamA/*" only
+// apply to keys under that prefix.
+func (h *Handler) objectPolicyDecision(r *http.Request, tenantID, bucketName, objectKey, principal, action string) bucket.PolicyDecision {
+	return h.policyDecision(r, tenantID, bucketName, principal, action, fmt.Sprintf("arn:aws:s3:::%s/%s", bucketName, objectKey))
+}
+
+// enforcePolicyDecision turns a policy decision into the enforce*Policy result.
+// An explicit Deny writes AccessDenied and returns ok=false; an explicit Allow
+// returns allowed=true so the caller can skip the ACL cascade, which would
+// otherwise refuse principals from outside the bucket's tenant.
+func (h *Handler) enforcePolicyDecision(w http.ResponseWriter, r *http.Request, decision bucket.PolicyDecision, user *auth.User, bucketName, resourceName, action string) (allowed bool, ok bool) {
+	switch decision {
+	case bucket.DecisionExplicitDeny:
+		logrus.WithFields(logrus.Fields{
+			"bucket":   bucketName,
+			"resource": resourceName,
+			"userID":   getUserIDOrAnonymous(user),
+			"action":   action,
+		}).Warn("Bucket policy explicitly denied access")
+		h.writeError(w, "AccessDenied", "Access Denied", resourceName, r)
+		return false, false
+	case bucket.DecisionAllow:
+		return true, true
+	}
+	return false, true
+}
+
+// enforceObjectPolicy applies the bucket policy to an object request.
+func (h *Handler) enforceObjectPolicy(w http.ResponseWriter, r *http.Request, user *auth.User, tenantID, bucketName, objectKey, action string) (allowed bool, ok bool) {
+	decision := h.objectPolicyDecision(r, tenantID, bucketName, objectKey, getUserIDOrAnonymous(user), action)
+	return h.enforcePolicyDecision(w, r, decision, user, bucketName, objectKey, action)
+}
 
-	return bucket.IsActionAllowed(ctx, policy, request)
+// enforceBucketPolicy applies the bucket policy to a bucket-level request
+// (e.g. s3:ListBucket) evaluated against arn:aws:s3:::bucket.
+func (h *Handler) enforceBucketPolicy(w http.ResponseWriter, r *http.Request, user *auth.User, tenantID, bucketName, action string) (allowed bool, ok bool) {
+	decision := h.policyDecision(r, tenantID, bucketName, getUserIDOrAnonymous(user), action, "arn:aws:s3:::"+bucketName)
+	return h.enforcePolicyDecision(w, r, decision, user, bucketName, bucketName, action)
 }
 
 // Bucket operations
@@ -730,6 +951,13 @@ func (h *Handler) CreateBucket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// MaxIOFS extension: optional per-bucket quota set at creation time
+	quota, err := parseBucketQuotaHeaders(r.Header)
+	if err != nil {
+		h.writeError(w, "InvalidArgument", err.Error(), bucketName, r)
+		return
+	}
+
 	// Determine tenantID - use user's tenantID
 	// Global admins (TenantID="") can create global buckets
 	// Tenant users/admins create buckets within their tenant
@@ -757,6 +985,14 @@ func (h *Handler) CreateBucket(w http.ResponseWriter, r *http.Request) {
 					tenant.CurrentBuckets, tenant.MaxBuckets), bucketName, r)
 			return
 		}
+
+		// The tenant's storage quota is the ceiling for a bucket quota
+		if quota != nil {
+			if err := auth.CheckBucketQuotaWithinTenant(tenant, quota.MaxSizeBytes); err != nil {
+				h.writeError(w, "InvalidArgument", err.Error(), bucketName, r)
+				return
+			}
+		}
 	}
 
 	if err := h.bucketManager.CreateBucket(r.Context(), tenantID, bucketName, user.ID); err != nil {
@@ -802,12 +1038,55 @@ func (h *Handler) CreateBucket(w http.ResponseWriter, r *http.Request) {
 		}).Info("CreateBucket: Object Lock enabled via x-amz-bucket-object-lock-enabled header")
 	}
 
+	if quota != nil {
+		if err := h.bucketManager.SetQuota(r.Context(), tenantID, bucketName, quota); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"bucket":   bucketName,
+				"tenantID": tenantID,
+			}).Error("CreateBucket: failed to set bucket quota")
+			_ = h.bucketManager.DeleteBucket(r.Context(), tenantID, bucketName)
+			h.writeError(w, "InternalError", "Failed to set bucket quota", bucketName, r)
+			return
+		}
+	}
+
 	// AWS S3 requires a Location header on successful bucket creation.
 	// Value is always "/{bucketName}" regardless of addressing style.
 	w.Header().Set("Location", "/"+bucketName)
 	w.WriteHeader(http.StatusOK)
 }
 
+// Bucket quota extension headers accepted on CreateBucket. Either may be
+// omitted; 0 means no limit for that dimension.
+const (
+	headerBucketMaxSizeBytes = "x-maxiofs-bucket-max-size-bytes"
+	headerBucketMaxObjects   = "x-maxiofs-bucket-max-objects"
+)
+
+// parseBucketQuotaHeaders reads the bucket quota extension headers. It returns
+// nil when neither sets a limit.
+func parseBucketQuotaHeaders(header http.Header) (*metadata.BucketQuota, error) {
+	quota := &metadata.BucketQuota{}
+	for name, dst := range map[string]*int64{
+		headerBucketMaxSizeBytes: &quota.MaxSizeBytes,
+		headerBucketMaxObjects:   &quota.MaxObjectCount,
+	} {
+		v := header.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s must be a non-negative integer", name)
+		}
+		*dst = n
+	}
+	if quota.MaxSizeBytes == 0 && quota.MaxObjectCount == 0 {
+		return nil, nil
+	}
+	return quota, nil
+}
+
 func (h *Handler) DeleteBucket(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bucketName := vars["bucket"]
@@ -850,7 +1129,7 @@ func (h *Handler) HeadBucket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tenantID := h.getTenantIDFromRequest(r)
+	tenantID := h.resolveBucketTenantID(r, bucketName)
 
 	// Permission check: Verify user has READ permission via ACL
 	user, userExists := auth.GetUserFromContext(r.Context())
@@ -915,6 +1194,15 @@ func (h *Handler) HeadBucket(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("x-amz-bucket-object-lock-enabled", "true")
 	}
 
+	// The list ETag lets a client check for changes without listing
+	listTag, notModified := h.listETag(w, r, h.getBucketPath(r, bucketName))
+	if notModified {
+		return
+	}
+	if listTag != "" {
+		w.Header().Set("ETag", listTag)
+	}
+
 	// Log all response headers AFTER they are fully set (for Veeam debugging)
 	userAgent := r.Header.Get("User-Agent")
 	if isVeeamClient(userAgent) {
@@ -943,13 +1231,19 @@ func (h *Handler) ListObjects(w http.ResponseWriter, r *http.Request) {
 
 	// Permission check: Verify user has READ permission via ACL
 	user, userExists := auth.GetUserFromContext(r.Context())
-	tenantID := h.getTenantIDFromRequest(r)
+	tenantID := h.resolveBucketTenantID(r, bucketName)
+
+	// Bucket policy: explicit Deny wins, Allow grants listing (s3:prefix conditions apply)
+	allowedByPolicy, ok := h.enforceBucketPolicy(w, r, user, tenantID, bucketName, "s3:ListBucket")
+	if !ok {
+		return
+	}
 
 	// Check if user is authenticated
 	if userExists {
 		// If user belongs to the same tenant as the bucket, allow access automatically
 		// ACLs only apply for cross-tenant or public access
-		if user.TenantID != tenantID {
+		if user.TenantID != tenantID && !allowedByPolicy {
 			// Cross-tenant access - check ACL permissions
 			hasPermission := h.checkBucketACLPermission(r.Context(), tenantID, bucketName, user.ID, acl.PermissionRead)
 
@@ -975,7 +1269,7 @@ func (h *Handler) ListObjects(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		// Same tenant - allow access automatically
-	} else {
+	} else if !allowedByPolicy {
 		// Unauthenticated access - check if bucket is public
 		hasPublicAccess := h.checkPublicBucketAccess(r.Context(), tenantID, bucketName, acl.PermissionRead)
 
@@ -1016,8 +1310,17 @@ func (h *Handler) ListObjects(w http.ResponseWriter, r *http.Request) {
 	}
 
 	bucketPath := h.getBucketPath(r, bucketName)
-	listResult, err := h.objectManager.ListObjects(r.Context(), bucketPath, prefix, delimiter, marker, maxKeys)
+	listTag, notModified := h.listETag(w, r, bucketPath)
+	if notModified {
+		return
+	}
+	listCtx, cancel := h.listContext(r)
+	defer cancel()
+	listResult, err := h.objectManager.ListObjects(listCtx, bucketPath, prefix, delimiter, marker, maxKeys)
 	if err != nil {
+		if h.writeListAborted(w, r, err, bucketName) {
+			return
+		}
 		if err == object.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
@@ -1053,6 +1356,7 @@ func (h *Handler) ListObjects(w http.ResponseWriter, r *http.Request) {
 		Contents:       make([]ObjectInfo, len(listResult.Objects)),
 	}
 
+	bucketOwner := h.listingBucketOwner(r.Context(), tenantID, bucketName)
 	for i, obj := range listResult.Objects {
 		result.Contents[i] = ObjectInfo{
 			Key:          encodeStr(obj.Key),
@@ -1060,13 +1364,13 @@ func (h *Handler) ListObjects(w http.ResponseWriter, r *http.Request) {
 			ETag:         obj.ETag,
 			Size:         obj.Size,
 			StorageClass: storageClassOrStandard(obj.StorageClass),
-			Owner: &Owner{
-				ID:          "maxiofs",
-				DisplayName: "MaxIOFS",
-			},
+			Owner:        listingObjectOwner(&obj, bucketOwner),
 		}
 	}
 
+	if listTag != "" {
+		w.Header().Set("ETag", listTag)
+	}
 	h.writeXMLResponse(w, http.StatusOK, result)
 }
 
@@ -1089,10 +1393,15 @@ func (h *Handler) ListObjectsV2(w http.ResponseWriter, r *http.Request) {
 
 	// Permission check: identical logic to ListObjects
 	user, userExists := auth.GetUserFromContext(r.Context())
-	tenantID := h.getTenantIDFromRequest(r)
+	tenantID := h.resolveBucketTenantID(r, bucketName)
+
+	allowedByPolicy, ok := h.enforceBucketPolicy(w, r, user, tenantID, bucketName, "s3:ListBucket")
+	if !ok {
+		return
+	}
 
 	if userExists {
-		if user.TenantID != tenantID {
+		if user.TenantID != tenantID && !allowedByPolicy {
 			hasPermission := h.checkBucketACLPermission(r.Context(), tenantID, bucketName, user.ID, acl.PermissionRead)
 			if !hasPermission {
 				hasPermission = h.checkAuthenticatedBucketAccess(r.Context(), tenantID, bucketName, acl.PermissionRead)
@@ -1111,7 +1420,7 @@ func (h *Handler) ListObjectsV2(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 		}
-	} else {
+	} else if !allowedByPolicy {
 		if !h.checkPublicBucketAccess(r.Context(), tenantID, bucketName, acl.PermissionRead) {
 			logrus.WithField("bucket", bucketName).Warn("Public access denied for ListObjectsV2")
 			h.writeError(w, "AccessDenied", "Access Denied", bucketName, r)
@@ -1164,8 +1473,17 @@ func (h *Handler) ListObjectsV2(w http.ResponseWriter, r *http.Request) {
 	}
 
 	bucketPath := h.getBucketPath(r, bucketName)
-	listResult, err := h.objectManager.ListObjects(r.Context(), bucketPath, prefix, delimiter, marker, maxKeys)
+	listTag, notModified := h.listETag(w, r, bucketPath)
+	if notModified {
+		return
+	}
+	listCtx, cancel := h.listContext(r)
+	defer cancel()
+	listResult, err := h.objectManager.ListObjects(listCtx, bucketPath, prefix, delimiter, marker, maxKeys)
 	if err != nil {
+		if h.writeListAborted(w, r, err, bucketName) {
+			return
+		}
 		if err == object.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
@@ -1206,6 +1524,11 @@ func (h *Handler) ListObjectsV2(w http.ResponseWriter, r *http.Request) {
 		result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(listResult.NextMarker))
 	}
 
+	// Owner is only included when explicitly requested via fetch-owner=true
+	var bucketOwner *Owner
+	if fetchOwner {
+		bucketOwner = h.listingBucketOwner(r.Context(), tenantID, bucketName)
+	}
 	for i, obj := range listResult.Objects {
 		info := ObjectInfo{
 			Key:          encodeStrV2(obj.Key),
@@ -1214,19 +1537,38 @@ func (h *Handler) ListObjectsV2(w http.ResponseWriter, r *http.Request) {
 			Size:         obj.Size,
 			StorageClass: storageClassOrStandard(obj.StorageClass),
 		}
-		// Owner is only included when explicitly requested via fetch-owner=true
 		if fetchOwner {
-			info.Owner = &Owner{
-				ID:          "maxiofs",
-				DisplayName: "MaxIOFS",
-			}
+			info.Owner = listingObjectOwner(&obj, bucketOwner)
 		}
 		result.Contents[i] = info
 	}
 
+	if listTag != "" {
+		w.Header().Set("ETag", listTag)
+	}
 	h.writeXMLResponse(w, http.StatusOK, result)
 }
 
+// listingBucketOwner returns the bucket's owner as recorded in its ACL, the
+// owner reported for objects that carry no ACL of their own.
+func (h *Handler) listingBucketOwner(ctx context.Context, tenantID, bucketName string) *Owner {
+	if aclInterface, err := h.bucketManager.GetBucketACL(ctx, tenantID, bucketName); err == nil {
+		if aclData, ok := aclInterface.(*acl.ACL); ok && aclData != nil && aclData.Owner.ID != "" {
+			return &Owner{ID: aclData.Owner.ID, DisplayName: aclData.Owner.DisplayName}
+		}
+	}
+	return &Owner{ID: "maxiofs", DisplayName: "MaxIOFS"}
+}
+
+// listingObjectOwner returns the <Owner> of a listed object: the owner in
+// its ACL when it has one, otherwise the bucket owner.
+func listingObjectOwner(obj *object.Object, bucketOwner *Owner) *Owner {
+	if obj.ACL != nil && obj.ACL.Owner.ID != "" {
+		return &Owner{ID: obj.ACL.Owner.ID, DisplayName: obj.ACL.Owner.DisplayName}
+	}
+	return bucketOwner
+}
+
 // Object operations
 func (h *Handler) GetObject(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -1316,19 +1658,25 @@ func (h *Handler) GetObject(w http.ResponseWriter, r *http.Request) {
 	// 1. /bucket/object (global bucket)
 	// 2. /tenant-xxx/bucket/object (tenant bucket)
 	var shareTenantID string
+	var activeShare *share.Share
 	allowedByShare := false
 	if !userExists && !allowedByPresignedURL && h.shareManager != nil {
-		realBucket, realObject, tenantFromShare, err := h.validateShareAccess(r, bucketName, objectKey)
+		s, err := h.validateShareAccess(r, bucketName, objectKey)
+		if errors.Is(err, share.ErrRefererNotAllowed) {
+			h.writeError(w, "AccessDenied", "Access denied. The share cannot be linked from this site.", objectKey, r)
+			return
+		}
 		if err != nil {
 			h.writeError(w, "AccessDenied", "Access denied. Object is not shared.", objectKey, r)
 			return
 		}
 
-		shareTenantID = tenantFromShare
+		activeShare = s
+		shareTenantID = s.TenantID
 		allowedByShare = true // access granted via share (shareTenantID may be "" for global bucket)
 		// Override vars for subsequent processing
-		bucketName = realBucket
-		objectKey = realObject
+		bucketName = s.BucketName
+		objectKey = s.ObjectKey
 	}
 
 	// Build bucket path: use shareTenantID if available, otherwise use auth-based tenant
@@ -1343,17 +1691,38 @@ func (h *Handler) GetObject(w http.ResponseWriter, r *http.Request) {
 		"tenantID":      tenantID,
 	}).Info("GetObject: Using bucketPath")
 
+	// Bucket policy scoped to the object key (explicit Deny wins, Allow grants)
+	allowedByPolicy := false
+	if !allowedByPresignedURL && !allowedByShare {
+		var ok bool
+		if allowedByPolicy, ok = h.enforceObjectPolicy(w, r, user, tenantID, bucketName, objectKey, "s3:GetObject"); !ok {
+			return
+		}
+	}
+
 	// 1. Verificar permiso de BUCKET únicamente (NO verificar ACL de objeto aún)
 	// El objeto puede no existir, así que solo verificamos permisos de bucket
-	if !h.validateBucketReadPermission(w, r, user, userExists, allowedByPresignedURL, allowedByShare, shareTenantID, tenantID, bucketName, objectKey) {
+	if !allowedByPolicy && !h.validateBucketReadPermission(w, r, user, userExists, allowedByPresignedURL, allowedByShare, shareTenantID, tenantID, bucketName, objectKey) {
+		return
+	}
+
+	// Resumable download session (?downloadSession=<token>&offset=N): read the
+	// version pinned when the session was opened, starting at the offset.
+	session, sessionOffset, ok := h.resolveDownloadSession(w, r, bucketPath, objectKey)
+	if !ok {
 		return
 	}
 
 	// 2. Read load balancing with ordered fallback: try each ready replica in
 	// turn. TryProxyRead does not write to w until the response is definitive,
 	// so a 404/5xx from one replica still lets us try the next (and finally
-	// fall through to the local read below).
-	if h.clusterManager != nil {
+	// fall through to the local read below). Sessions live on this node only.
+	if h.clusterManager != nil && session == nil {
+		// A replica that hasn't applied the latest delete yet would still
+		// serve the previous version: answer a delete-marked key here.
+		if r.URL.Query().Get("versionId") == "" && h.handleVersionedObjectNotFound(w, r, bucketPath, objectKey, "") {
+			return
+		}
 		nodes, _ := h.clusterManager.SelectReadNodes(r.Context(), bucketPath)
 		for _, node := range nodes {
 			served, tryErr := h.clusterManager.TryProxyRead(r.Context(), w, r, node)
@@ -1370,6 +1739,9 @@ func (h *Handler) GetObject(w http.ResponseWriter, r *http.Request) {
 	// 3. Intentar obtener el objeto
 	// Si el objeto NO existe, devolver NoSuchKey (404) - esto es correcto para S3
 	versionID := r.URL.Query().Get("versionId")
+	if session != nil {
+		versionID = session.versionID
+	}
 	obj, reader, err := h.objectManager.GetObject(r.Context(), bucketPath, objectKey, versionID)
 	if err != nil {
 		if err == object.ErrObjectNotFound {
@@ -1381,33 +1753,86 @@ func (h *Handler) GetObject(w http.ResponseWriter, r *http.Request) {
 			h.writeError(w, "NoSuchKey", "The specified key does not exist", objectKey, r)
 			return
 		}
+		if err == object.ErrObjectDataMissing {
+			h.writeError(w, "InternalError", objectDataMissingMessage, objectKey, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), objectKey, r)
 		return
 	}
 	defer reader.Close()
 
 	// Handle conditional requests (If-Match, If-None-Match, If-Modified-Since, If-Unmodified-Since)
+	bucketTenantID := tenantID
+	if activeShare != nil {
+		bucketTenantID = shareTenantID
+	}
+	setObjectCacheHeaders(w, obj, h.bucketCacheDefaults(r.Context(), bucketTenantID, bucketName), versionID)
 	if !h.validateConditionalHeaders(w, r, obj.ETag, obj.LastModified) {
 		return
 	}
+	if session != nil && !h.checkDownloadSessionObject(w, r, session, obj) {
+		return
+	}
 
 	// 3. El objeto existe - ahora verificar ACL de objeto (solo para cross-tenant)
-	if !h.validateObjectReadPermission(w, r, user, userExists, allowedByPresignedURL, shareTenantID, tenantID, bucketPath, bucketName, objectKey) {
+	if !allowedByPolicy && !h.validateObjectReadPermission(w, r, user, userExists, allowedByPresignedURL, shareTenantID, tenantID, bucketPath, bucketName, objectKey) {
 		return
 	}
 
-	// Parse Range header if present (for parallel/resumable downloads)
+	// Archived (GLACIER, DEEP_ARCHIVE) objects are readable once restored
+	if !h.checkArchivedObjectReadable(w, r, obj, bucketPath, objectKey, versionID, objectKey) {
+		return
+	}
+
+	// Parse Range header if present (for parallel/resumable downloads). A
+	// stale If-Range means the client's partial copy is of another version of
+	// the object, so it gets the whole object instead of a mismatched range
 	rangeHeader := r.Header.Get("Range")
+	if !ifRangeMatches(r.Header.Get("If-Range"), obj.ETag, obj.LastModified) {
+		rangeHeader = ""
+	}
+	if session != nil && sessionOffset > 0 {
+		rangeHeader = fmt.Sprintf("bytes=%d-", sessionOffset)
+	}
+
+	// A gzip-stored object is decoded for a client that can't take gzip (see
+	// SetGzipTranscoding); sizes and ranges are then those of the decoded
+	// bytes. Without a known decoded length a range can't be placed, and the
+	// whole object is sent.
+	size := obj.Size
+	var body io.ReadCloser = reader
+	transcoded, rangeable := false, true
+	if h.gzipTranscoding && isGzipEncoded(obj) {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	if h.shouldTranscodeGzip(r, obj) {
+		var decoded io.Reader
+		decoded, transcoded = newGzipTranscoder(reader)
+		body = io.NopCloser(decoded)
+		if transcoded {
+			size = -1
+			rangeable = gzipRangeable(obj)
+			if rangeHeader != "" {
+				size = h.gzipDecodedSize(r.Context(), bucketPath, objectKey, obj)
+				if size < 0 {
+					rangeHeader = ""
+					rangeable = false
+				}
+			}
+		}
+	}
+
 	var rangeStart, rangeEnd int64
 	var isRangeRequest bool
 
 	if rangeHeader != "" {
 		// Parse Range header: "bytes=start-end" or "bytes=start-"
 		var parseErr error
-		rangeStart, rangeEnd, parseErr = parseRangeHeader(rangeHeader, obj.Size)
+		rangeStart, rangeEnd, parseErr = parseRangeHeader(rangeHeader, size)
 		if parseErr != nil {
 			// Invalid range - return 416 Range Not Satisfiable
-			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", obj.Size))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
 			h.writeError(w, "InvalidRange", parseErr.Error(), objectKey, r)
 			return
 		}
@@ -1418,30 +1843,56 @@ func (h *Handler) GetObject(w http.ResponseWriter, r *http.Request) {
 			"object":     objectKey,
 			"rangeStart": rangeStart,
 			"rangeEnd":   rangeEnd,
-			"totalSize":  obj.Size,
+			"totalSize":  size,
 		}).Debug("GetObject: Range request detected")
 	} else {
 		// No range header - send entire object
 		rangeStart = 0
-		rangeEnd = obj.Size - 1
+		rangeEnd = size - 1
 		isRangeRequest = false
 	}
 
 	// Set common response headers
 	h.setGetObjectResponseHeaders(w, obj)
+	if transcoded {
+		setGzipTranscodedHeaders(w, rangeable)
+	}
+	if activeShare != nil {
+		// Headers chosen when the share was created, e.g. a friendly filename
+		if activeShare.ContentDisposition != "" {
+			w.Header().Set("Content-Disposition", activeShare.ContentDisposition)
+		}
+		if activeShare.ContentType != "" {
+			w.Header().Set("Content-Type", activeShare.ContentType)
+		}
+	}
 
 	// Throttle the download to the owning tenant's aggregate bandwidth budget
 	// (nil = unlimited; only the bytes actually streamed to the client count).
 	dlLimiter := h.tenantBandwidthLimiter(r.Context(), r, bucketName)
 
+	// Downloads through a share go to its access log with the bytes actually sent
+	out := w
+	if activeShare != nil {
+		counter := &countingResponseWriter{ResponseWriter: w}
+		out = counter
+		defer func() { h.recordShareAccess(r, activeShare, counter.written) }()
+	}
+
 	// Handle range request
 	if isRangeRequest {
-		if err := h.sendRangeResponse(r.Context(), w, reader, rangeStart, rangeEnd, obj.Size, dlLimiter); err != nil {
+		if err := h.sendRangeResponse(r.Context(), out, body, rangeStart, rangeEnd, size, dlLimiter); err != nil {
 			return
 		}
+	} else if transcoded {
+		// The decoded length isn't known up front: no Content-Length
+		out.WriteHeader(http.StatusOK)
+		if _, err := io.Copy(out, bandwidth.ThrottleReader(r.Context(), body, dlLimiter)); err != nil {
+			logrus.WithError(err).WithField("key", objectKey).Error("Failed to write decoded object data")
+		}
 	} else {
 		// Send entire object (no range request)
-		if err := h.sendFullResponse(r.Context(), w, reader, obj.Size, dlLimiter); err != nil {
+		if err := h.sendFullResponse(r.Context(), out, body, size, dlLimiter); err != nil {
 			return
 		}
 	}
@@ -1497,8 +1948,13 @@ func (h *Handler) PutObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	allowedByPolicy, ok := h.enforceObjectPolicy(w, r, user, tenantID, bucketName, objectKey, "s3:PutObject")
+	if !ok {
+		return
+	}
+
 	// Validate WRITE permission via ACL cascading
-	if !h.validateBucketWritePermission(r, user, userExists, tenantID, bucketName) {
+	if !allowedByPolicy && !h.validateBucketWritePermission(r, user, userExists, tenantID, bucketName) {
 		logrus.WithFields(logrus.Fields{
 			"bucket":        bucketName,
 			"object":        objectKey,
@@ -1528,17 +1984,31 @@ func (h *Handler) PutObject(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 		return
 	}
+	if h.rejectPublicACL(w, r, tenantID, bucketName, objectKey, requestSetsPublicACL(r)) {
+		return
+	}
 
 	bucketPath := h.getBucketPath(r, bucketName)
 
 	// Conditional write: If-None-Match: * means "write only if the object does not exist"
 	if r.Header.Get("If-None-Match") == "*" {
-		if existing, err := h.objectManager.GetObjectMetadata(r.Context(), bucketPath, objectKey); err == nil && existing != nil {
+		if exists, err := h.objectManager.ObjectExists(r.Context(), bucketPath, objectKey); err == nil && exists {
 			h.writeError(w, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold", objectKey, r)
 			return
 		}
 	}
 
+	// x-amz-write-offset-bytes appends the body to the object, which must
+	// currently be exactly that many bytes long (0 creates it)
+	writeOffset := int64(-1)
+	if offsetHeader := r.Header.Get("x-amz-write-offset-bytes"); offsetHeader != "" {
+		writeOffset, err = strconv.ParseInt(offsetHeader, 10, 64)
+		if err != nil || writeOffset < 0 {
+			h.writeError(w, "InvalidArgument", "x-amz-write-offset-bytes must be a non-negative integer", objectKey, r)
+			return
+		}
+	}
+
 	// Leer headers de Object Lock si están presentes (para Veeam)
 	lockMode := r.Header.Get("x-amz-object-lock-mode")
 	retainUntilDateStr := r.Header.Get("x-amz-object-lock-retain-until-date")
@@ -1563,7 +2033,12 @@ func (h *Handler) PutObject(w http.ResponseWriter, r *http.Request) {
 		"bucketPath": bucketPath,
 	}).Info("PutObject: Using bucketPath")
 
-	obj, err := h.objectManager.PutObject(r.Context(), bucketPath, objectKey, bodyReader, r.Header)
+	var obj *object.Object
+	if writeOffset >= 0 {
+		obj, err = h.objectManager.AppendObject(r.Context(), bucketPath, objectKey, bodyReader, r.Header, writeOffset)
+	} else {
+		obj, err = h.objectManager.PutObject(r.Context(), bucketPath, objectKey, bodyReader, r.Header)
+	}
 	if err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
 			"bucket": bucketName,
@@ -1575,6 +2050,25 @@ func (h *Handler) PutObject(w http.ResponseWriter, r *http.Request) {
 			h.writeError(w, "ServiceUnavailable", "Cluster degraded — replication quorum unavailable, retry later", objectKey, r)
 			return
 		}
+		if storage.IsInsufficientStorage(err) {
+			h.writeInsufficientStorage(w, r, objectKey)
+			return
+		}
+		if errors.Is(err, object.ErrInvalidStorageClass) {
+			h.writeError(w, "InvalidStorageClass", "The storage class you specified is not valid", objectKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrReservedKeyPrefix) {
+			h.writeError(w, "AccessDenied", "The object key is in a reserved system namespace", objectKey, r)
+			return
+		}
+		if h.writeUploadScanError(w, r, err, objectKey) {
+			return
+		}
+		if errors.Is(err, object.ErrInvalidExpiresAfter) {
+			h.writeError(w, "InvalidArgument", err.Error(), objectKey, r)
+			return
+		}
 		if err == object.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
@@ -1583,6 +2077,30 @@ func (h *Handler) PutObject(w http.ResponseWriter, r *http.Request) {
 			h.writeError(w, "QuotaExceeded", err.Error(), objectKey, r)
 			return
 		}
+		var retErr *object.RetentionError
+		if errors.As(err, &retErr) {
+			h.writeError(w, "AccessDenied", retErr.Error(), objectKey, r)
+			return
+		}
+		if err == object.ErrObjectExists {
+			h.writeError(w, "PreconditionFailed", "The bucket does not allow overwriting existing objects", objectKey, r)
+			return
+		}
+		if err == object.ErrInvalidWriteOffset {
+			h.writeError(w, "InvalidWriteOffset", "The write offset does not match the current object size", objectKey, r)
+			return
+		}
+		if err == object.ErrAppendNotSupported {
+			h.writeError(w, "InvalidRequest", "Appending is not supported in versioned buckets or for folders", objectKey, r)
+			return
+		}
+		if err == object.ErrObjectUnderLegalHold {
+			h.writeError(w, "AccessDenied", "The object is under legal hold", objectKey, r)
+			return
+		}
+		if h.writeDigestError(w, r, err, objectKey) {
+			return
+		}
 		if strings.HasPrefix(err.Error(), "BadDigest:") {
 			h.writeError(w, "BadDigest", err.Error(), objectKey, r)
 			return
@@ -1618,11 +2136,11 @@ func (h *Handler) PutObject(w http.ResponseWriter, r *http.Request) {
 	// Note: Bucket metrics and tenant storage are updated by objectManager.PutObject()
 	// No need to increment here to avoid double-counting on overwrites
 
-	h.setPutObjectResponseHeaders(w, obj)
+	h.setPutObjectResponseHeaders(w, obj, h.writtenVersionID(r, bucketName, obj.VersionID))
 	w.WriteHeader(http.StatusOK)
 
 	// Fire s3:ObjectCreated:Put notification asynchronously.
-	h.fireNotifications(r.Context(), bucketName, tenantID, objectKey, "s3:ObjectCreated:Put", obj.ETag, obj.Size)
+	h.fireNotifications(r.Context(), bucketName, tenantID, objectKey, "s3:ObjectCreated:Put", obj.ETag, obj.VersionID, obj.Size)
 
 	// Queue object for realtime replication (async, best-effort)
 	if h.replicationManager != nil {
@@ -1670,7 +2188,12 @@ func (h *Handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	hasPermission := h.checkDeleteObjectPermission(r.Context(), user, userExists, tenantID, bucketName, bucketPath, objectKey)
+	allowedByPolicy, ok := h.enforceObjectPolicy(w, r, user, tenantID, bucketName, objectKey, "s3:DeleteObject")
+	if !ok {
+		return
+	}
+
+	hasPermission := allowedByPolicy || h.checkDeleteObjectPermission(r.Context(), user, userExists, tenantID, bucketName, bucketPath, objectKey)
 	if !hasPermission {
 		logrus.WithFields(logrus.Fields{
 			"bucket":        bucketName,
@@ -1682,18 +2205,33 @@ func (h *Handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If bypass governance is requested, validate user has admin permission
-	if bypassGovernance {
-		if err := h.validateBypassGovernance(user, userExists); err != nil {
-			h.writeError(w, "AccessDenied", err.Error(), objectKey, r)
-			return
-		}
+	// Like AWS, the bypass header is ignored without the bypass permission:
+	// the delete then fails only if the object is under GOVERNANCE retention.
+	if bypassGovernance && !h.canBypassGovernance(r, user, userExists, tenantID, bucketName, objectKey) {
+		logrus.WithFields(logrus.Fields{
+			"bucket": bucketName,
+			"object": objectKey,
+			"userID": getUserIDOrAnonymous(user),
+		}).Debug("Ignoring x-amz-bypass-governance-retention: caller lacks the bypass permission")
+		bypassGovernance = false
+	}
+
+	// MFA Delete: permanently deleting a version needs a valid x-amz-mfa code
+	if versionID != "" && !h.requireMFADelete(w, r, tenantID, bucketName, objectKey) {
+		return
 	}
 
 	// Get object info before deletion to track size for metrics
 	objectSize := h.getObjectSizeBeforeDeletion(r.Context(), bucketPath, objectKey, versionID)
 
-	deleteMarkerVersionID, err := h.objectManager.DeleteObject(r.Context(), bucketPath, objectKey, bypassGovernance, versionID)
+	// Conditional delete (compare-and-delete): the manager checks the ETag of
+	// the targeted version under the per-key lock.
+	deleteCtx := r.Context()
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		deleteCtx = object.WithDeleteIfMatch(deleteCtx, ifMatch)
+	}
+
+	deleteMarkerVersionID, err := h.objectManager.DeleteObject(deleteCtx, bucketPath, objectKey, bypassGovernance, versionID)
 	if h.handleDeleteObjectErrors(w, r, err, bucketName, objectKey, versionID) {
 		return
 	}
@@ -1711,7 +2249,11 @@ func (h *Handler) DeleteObject(w http.ResponseWriter, r *http.Request) {
 	if deleteMarkerVersionID != "" && versionID == "" {
 		eventName = "s3:ObjectRemoved:DeleteMarkerCreated"
 	}
-	h.fireNotifications(r.Context(), bucketName, tenantID, objectKey, eventName, "", 0)
+	eventVersionID := versionID
+	if eventVersionID == "" {
+		eventVersionID = deleteMarkerVersionID
+	}
+	h.fireNotifications(r.Context(), bucketName, tenantID, objectKey, eventName, "", eventVersionID, 0)
 
 	// Queue delete for realtime replication (async, best-effort)
 	if h.replicationManager != nil {
@@ -1773,8 +2315,13 @@ func (h *Handler) HeadObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	allowedByPolicy, ok := h.enforceObjectPolicy(w, r, user, tenantID, bucketName, objectKey, "s3:GetObject")
+	if !ok {
+		return
+	}
+
 	// Verify BUCKET permissions only (object may not exist)
-	if !h.validateHeadBucketReadPermission(w, r, user, userExists, tenantID, bucketName, objectKey) {
+	if !allowedByPolicy && !h.validateHeadBucketReadPermission(w, r, user, userExists, tenantID, bucketName, objectKey) {
 		return
 	}
 
@@ -1806,16 +2353,37 @@ func (h *Handler) HeadObject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Handle conditional requests (If-Match, If-None-Match, If-Modified-Since, If-Unmodified-Since)
+	setObjectCacheHeaders(w, obj, h.bucketCacheDefaults(r.Context(), tenantID, bucketName), versionID)
 	if !h.validateConditionalHeaders(w, r, obj.ETag, obj.LastModified) {
 		return
 	}
 
 	// Object exists - now check object-level ACLs for cross-tenant access
-	if !h.validateObjectReadPermission(w, r, user, userExists, false, "", tenantID, bucketPath, bucketName, objectKey) {
+	if !allowedByPolicy && !h.validateObjectReadPermission(w, r, user, userExists, false, "", tenantID, bucketPath, bucketName, objectKey) {
 		return
 	}
 
 	h.setHeadObjectResponseHeaders(w, obj)
+	// A client the GET would decode for can only range the decoded bytes when
+	// their length can be read from the gzip trailer
+	if h.shouldTranscodeGzip(r, obj) && !gzipRangeable(obj) {
+		w.Header().Del("Accept-Ranges")
+	}
+
+	// A ranged HEAD describes the response the matching GET would send, so
+	// clients sizing parallel downloads see the range length, not the object size
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" && ifRangeMatches(r.Header.Get("If-Range"), obj.ETag, obj.LastModified) {
+		rangeStart, rangeEnd, err := parseRangeHeader(rangeHeader, obj.Size)
+		if err != nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", obj.Size))
+			h.writeError(w, "InvalidRange", err.Error(), objectKey, r)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(rangeEnd-rangeStart+1, 10))
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", rangeStart, rangeEnd, obj.Size))
+		w.WriteHeader(http.StatusPartialContent)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -1902,9 +2470,14 @@ func (h *Handler) GetBucketVersioning(w http.ResponseWriter, r *http.Request) {
 	if versioningStatus == "" {
 		statusXML = `<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></VersioningConfiguration>`
 	} else {
-		statusXML = fmt.Sprintf(`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>%s</Status></VersioningConfiguration>`, versioningStatus)
+		mfaDelete := ""
+		if bkt.Versioning != nil && bkt.Versioning.MFADelete {
+			mfaDelete = "<MfaDelete>Enabled</MfaDelete>"
+		}
+		statusXML = fmt.Sprintf(`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>%s</Status>%s</VersioningConfiguration>`, versioningStatus, mfaDelete)
 	}
 
+	w.Header().Set("ETag", bucketRevisionETag(bkt.Revision))
 	h.writeXMLResponse(w, http.StatusOK, statusXML)
 }
 
@@ -1930,8 +2503,9 @@ func (h *Handler) PutBucketVersioning(w http.ResponseWriter, r *http.Request) {
 
 	// Parse versioning configuration from request body
 	type VersioningConfiguration struct {
-		XMLName xml.Name `xml:"VersioningConfiguration"`
-		Status  string   `xml:"Status"`
+		XMLName   xml.Name `xml:"VersioningConfiguration"`
+		Status    string   `xml:"Status"`
+		MfaDelete string   `xml:"MfaDelete"`
 	}
 
 	var versioningConfig VersioningConfiguration
@@ -1957,20 +2531,49 @@ func (h *Handler) PutBucketVersioning(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if versioningConfig.MfaDelete != "" && versioningConfig.MfaDelete != "Enabled" && versioningConfig.MfaDelete != "Disabled" {
+		h.writeError(w, "MalformedXML", "MfaDelete must be Enabled or Disabled", bucketName, r)
+		return
+	}
+
+	// MFA Delete: configuring it, and any versioning change while it is
+	// enabled, needs a valid x-amz-mfa code. Without an MfaDelete element the
+	// current setting is kept.
+	mfaDelete, err := h.mfaDeleteEnabled(r.Context(), tenantID, bucketName)
+	if err != nil {
+		logrus.WithError(err).WithField("bucket", bucketName).Error("MFA Delete: failed to read bucket versioning")
+		h.writeError(w, "InternalError", "Failed to read the bucket versioning configuration", bucketName, r)
+		return
+	}
+	if versioningConfig.MfaDelete != "" || mfaDelete {
+		if !h.requireMFA(w, r, bucketName) {
+			return
+		}
+		if versioningConfig.MfaDelete != "" {
+			mfaDelete = versioningConfig.MfaDelete == "Enabled"
+		}
+	}
+
 	// Set versioning configuration
 	config := &bucket.VersioningConfig{
-		Status: versioningConfig.Status,
+		Status:    versioningConfig.Status,
+		MFADelete: mfaDelete,
 	}
 
-	if err := h.bucketManager.SetVersioning(r.Context(), tenantID, bucketName, config); err != nil {
+	if err := h.bucketManager.SetVersioning(bucketConfigContext(r), tenantID, bucketName, config); err != nil {
 		if err == bucket.ErrBucketNotFound {
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -2112,6 +2715,7 @@ func (h *Handler) GetObjectLockConfiguration(w http.ResponseWriter, r *http.Requ
 		"hasRule": config.Rule != nil,
 	}).Info("Returning Object Lock configuration")
 
+	w.Header().Set("ETag", bucketRevisionETag(bkt.Revision))
 	h.writeXMLResponse(w, http.StatusOK, config)
 }
 
@@ -2200,8 +2804,17 @@ func (h *Handler) PutObjectLockConfiguration(w http.ResponseWriter, r *http.Requ
 		}).Info("PutObjectLockConfiguration - Updated default retention rule")
 	}
 
-	// Guardar cambios en el bucket
-	if err := h.bucketManager.UpdateBucket(r.Context(), tenantID, bucketName, bucketInfo); err != nil {
+	// Only the Object Lock configuration is written back, so changes made to
+	// the bucket since it was read above are kept
+	if err := h.bucketManager.SetObjectLockConfig(bucketConfigContext(r), tenantID, bucketName, bucketInfo.ObjectLock); err != nil {
+		if err == bucket.ErrBucketNotFound {
+			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
+			return
+		}
+		if errors.Is(err, bucket.ErrBucketModified) {
+			h.writeError(w, "ConditionalRequestConflict", "The bucket was modified since the If-Match revision", bucketName, r)
+			return
+		}
 		logrus.WithError(err).Error("PutObjectLockConfiguration - Failed to update bucket")
 		h.writeError(w, "InternalError", "Failed to update Object Lock configuration", bucketName, r)
 		return
@@ -2209,6 +2822,7 @@ func (h *Handler) PutObjectLockConfiguration(w http.ResponseWriter, r *http.Requ
 
 	logrus.WithField("bucket", bucketName).Info("PutObjectLockConfiguration - Configuration saved successfully")
 
+	h.setBucketRevisionETag(w, r, tenantID, bucketName)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -2254,8 +2868,15 @@ func (h *Handler) getTenantIDFromRequest(r *http.Request) string {
 // resolveBucketTenantID returns the tenant that actually owns the bucket.
 // Authenticated tenant users may access global buckets through ACLs; in that
 // case the bucket path must remain unprefixed instead of using user.TenantID.
+// When bucket names are tenant-scoped, a bucket of the requester's own tenant
+// takes precedence over another tenant's bucket of the same name.
 func (h *Handler) resolveBucketTenantID(r *http.Request, bucketName string) string {
 	if h.metadataStore != nil {
+		if tenantID := h.getTenantIDFromRequest(r); tenantID != "" {
+			if exists, err := h.metadataStore.BucketExists(r.Context(), tenantID, bucketName); err == nil && exists {
+				return tenantID
+			}
+		}
 		bucketMeta, err := h.metadataStore.GetBucketByName(r.Context(), bucketName)
 		if err == nil && bucketMeta != nil {
 			return bucketMeta.TenantID
@@ -2277,6 +2898,10 @@ func (h *Handler) getBucketPath(r *http.Request, bucketName string) string {
 	return tenantID + "/" + bucketName // Tenant-scoped bucket path
 }
 
+// objectDataMissingMessage is the InternalError message of a GET whose object
+// has metadata but no data on storage (see object.ErrObjectDataMissing).
+const objectDataMissingMessage = "The object data is missing from storage and has been flagged for repair."
+
 func (h *Handler) writeError(w http.ResponseWriter, code, message, resource string, r *http.Request) {
 	w.Header().Set("Content-Type", "application/xml")
 
@@ -2286,14 +2911,15 @@ func (h *Handler) writeError(w http.ResponseWriter, code, message, resource stri
 	case "InvalidArgument", "InvalidBucketName", "InvalidRequest", "MalformedXML", "MalformedPolicy",
 		"MalformedPOSTRequest", "InvalidPolicyDocument", "InvalidTag", "InvalidPart",
 		"IllegalVersioningConfigurationException", "BadDigest", "EntityTooSmall", "EntityTooLarge",
-		"InvalidDigest":
+		"InvalidDigest", "InvalidStorageClass":
 		statusCode = http.StatusBadRequest
 	// 401 Unauthorized
 	case "Unauthorized":
 		statusCode = http.StatusUnauthorized
 	// 403 Forbidden — AWS S3 returns 403 (not 401) for signature/credential errors
 	case "AccessDenied", "AccountProblem", "AllAccessDisabled", "QuotaExceeded",
-		"InvalidAccessKeyId", "SignatureDoesNotMatch", "RequestExpired":
+		"InvalidAccessKeyId", "SignatureDoesNotMatch", "RequestExpired", "RequestTimeTooSkewed",
+		"InvalidObjectState":
 		statusCode = http.StatusForbidden
 	// 404 Not Found (AWS S3 standard)
 	case "NoSuchBucket", "NoSuchKey", "NoSuchUpload", "ObjectLockConfigurationNotFoundError",
@@ -2306,7 +2932,8 @@ func (h *Handler) writeError(w http.ResponseWriter, code, message, resource stri
 	case "MethodNotAllowed":
 		statusCode = http.StatusMethodNotAllowed
 	// 409 Conflict
-	case "BucketAlreadyExists", "BucketAlreadyOwnedByYou", "BucketNotEmpty", "OperationAborted", "InvalidBucketState", "RestoreAlreadyInProgress":
+	case "BucketAlreadyExists", "BucketAlreadyOwnedByYou", "BucketNotEmpty", "OperationAborted", "InvalidBucketState", "RestoreAlreadyInProgress",
+		"InvalidWriteOffset", "ConditionalRequestConflict":
 		statusCode = http.StatusConflict
 	// 412 Precondition Failed
 	case "PreconditionFailed":
@@ -2319,10 +2946,19 @@ func (h *Handler) writeError(w http.ResponseWriter, code, message, resource stri
 		statusCode = http.StatusInternalServerError
 		// Log the real error internally but never expose server internals to clients.
 		// This prevents filesystem paths, hostnames, and other internal details from leaking.
-		logrus.WithFields(logrus.Fields{
+		logEntry := logrus.WithFields(logrus.Fields{
 			"resource": resource,
 			"detail":   message,
-		}).Error("InternalError: suppressing detail from S3 response")
+		})
+		if r != nil {
+			logEntry = logEntry.WithContext(r.Context())
+		}
+		if message == objectDataMissingMessage {
+			// Safe to show, and the one thing the client needs to know
+			logEntry.Error("InternalError: object data missing from storage")
+			break
+		}
+		logEntry.Error("InternalError: suppressing detail from S3 response")
 		message = "We encountered an internal error. Please try again."
 	// 501 Not Implemented
 	case "NotImplemented":
@@ -2332,15 +2968,15 @@ func (h *Handler) writeError(w http.ResponseWriter, code, message, resource stri
 		statusCode = http.StatusServiceUnavailable
 	}
 
-	// Generate IDs for both headers and XML body BEFORE WriteHeader
-	requestID := generateRequestID()
-	hostID := generateAmzId2()
-
-	// Set headers BEFORE WriteHeader
-	w.Header().Set("X-Amz-Request-Id", requestID)
-	w.Header().Set("X-Amz-Id-2", hostID)
+	// The request's IDs go in both the headers and the XML body; set them
+	// BEFORE WriteHeader
+	requestID, hostID := middleware.AmzRequestIDs(w)
 	w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
 
+	if h.writeErrorPage(w, r, statusCode, code, requestID) {
+		return
+	}
+
 	w.WriteHeader(statusCode)
 
 	// RFC 7231: HEAD responses MUST NOT include a message body. Sending an XML
@@ -2428,8 +3064,11 @@ func (h *Handler) writeError(w http.ResponseWriter, code, message, resource stri
 	xml.NewEncoder(w).Encode(errorResponse)
 }
 
-// parseRangeHeader parses HTTP Range header (e.g., "bytes=0-1023" or "bytes=1024-")
-// Returns start offset, end offset (inclusive), and error
+// parseRangeHeader returns the byte range to serve for a Range header.
+// Like S3, one range is served: in a multi-range list ("bytes=0-9,100-")
+// unsatisfiable ranges are dropped and the first satisfiable one is used, so
+// the request only fails (416) when no range can be satisfied. A syntax error
+// in any range fails the whole header.
 func parseRangeHeader(rangeHeader string, objectSize int64) (int64, int64, error) {
 	// Remove "bytes=" prefix
 	if !strings.HasPrefix(rangeHeader, "bytes=") {
@@ -2437,62 +3076,86 @@ func parseRangeHeader(rangeHeader string, objectSize int64) (int64, int64, error
 	}
 	rangeSpec := strings.TrimPrefix(rangeHeader, "bytes=")
 
-	// Split on comma — multi-range: serve the first valid range only (widely accepted behaviour)
-	ranges := strings.Split(rangeSpec, ",")
+	var firstErr error
+	found := false
+	var start, end int64
+	for _, spec := range strings.Split(rangeSpec, ",") {
+		s, e, satisfiable, err := parseByteRangeSpec(strings.TrimSpace(spec), objectSize)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !satisfiable {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("range %q not satisfiable for object size %d", strings.TrimSpace(spec), objectSize)
+			}
+			continue
+		}
+		if !found {
+			start, end, found = s, e, true
+		}
+	}
+	if !found {
+		return 0, 0, firstErr
+	}
+	return start, end, nil
+}
 
-	// Parse start-end
-	parts := strings.Split(strings.TrimSpace(ranges[0]), "-")
+// parseByteRangeSpec parses one "start-end", "start-" or "-suffix" range.
+// It returns an error for malformed specs and satisfiable=false for ranges
+// that don't overlap the object. A suffix longer than the object selects the
+// whole object; an end past the object is clamped to its last byte.
+func parseByteRangeSpec(spec string, objectSize int64) (start, end int64, satisfiable bool, err error) {
+	parts := strings.Split(spec, "-")
 	if len(parts) != 2 {
-		return 0, 0, fmt.Errorf("invalid range format")
+		return 0, 0, false, fmt.Errorf("invalid range format")
 	}
 
-	var start, end int64
-	var err error
-
-	// Handle "start-end" format
-	if parts[0] != "" && parts[1] != "" {
+	switch {
+	case parts[0] != "" && parts[1] != "":
+		// Handle "start-end" format
 		start, err = strconv.ParseInt(parts[0], 10, 64)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid range start: %w", err)
+		if err != nil || start < 0 {
+			return 0, 0, false, fmt.Errorf("invalid range start: %s", parts[0])
 		}
 		end, err = strconv.ParseInt(parts[1], 10, 64)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid range end: %w", err)
+		if err != nil || end < 0 {
+			return 0, 0, false, fmt.Errorf("invalid range end: %s", parts[1])
 		}
-	} else if parts[0] != "" && parts[1] == "" {
+		if start > end {
+			return 0, 0, false, fmt.Errorf("range start greater than end")
+		}
+	case parts[0] != "":
 		// Handle "start-" format (from start to end of file)
 		start, err = strconv.ParseInt(parts[0], 10, 64)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid range start: %w", err)
+		if err != nil || start < 0 {
+			return 0, 0, false, fmt.Errorf("invalid range start: %s", parts[0])
 		}
 		end = objectSize - 1
-	} else if parts[0] == "" && parts[1] != "" {
+	case parts[1] != "":
 		// Handle "-suffix" format (last N bytes)
-		suffix, err := strconv.ParseInt(parts[1], 10, 64)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid range suffix: %w", err)
+		suffix, perr := strconv.ParseInt(parts[1], 10, 64)
+		if perr != nil || suffix < 0 {
+			return 0, 0, false, fmt.Errorf("invalid range suffix: %s", parts[1])
+		}
+		if suffix == 0 {
+			return 0, 0, false, nil
 		}
 		start = objectSize - suffix
 		if start < 0 {
 			start = 0
 		}
 		end = objectSize - 1
-	} else {
-		return 0, 0, fmt.Errorf("invalid range format")
+	default:
+		return 0, 0, false, fmt.Errorf("invalid range format")
 	}
 
-	// Validate range
-	if start < 0 || start >= objectSize {
-		return 0, 0, fmt.Errorf("range start out of bounds")
+	if start >= objectSize {
+		return 0, 0, false, nil
 	}
 	if end >= objectSize {
 		end = objectSize - 1
 	}
-	if start > end {
-		return 0, 0, fmt.Errorf("range start greater than end")
-	}
-
-	return start, end, nil
+	return start, end, true, nil
 }
 
 // ACL Permission Checking Helpers
@@ -2508,6 +3171,11 @@ func getUserIDOrAnonymous(user *auth.User) string {
 // checkBucketACLPermission checks if a user has permission on a bucket via ACL
 // Returns true if access is allowed, false otherwise
 func (h *Handler) checkBucketACLPermission(ctx context.Context, tenantID, bucketName, userID string, permission acl.Permission) bool {
+	// Explicit bucket permission grants extend access beyond the ACL
+	if h.checkBucketGrant(ctx, tenantID, bucketName, userID, permission) {
+		return true
+	}
+
 	// Get bucket ACL
 	bucketACL, err := h.bucketManager.GetBucketACL(ctx, tenantID, bucketName)
 	if err != nil {
@@ -2535,8 +3203,9 @@ func (h *Handler) checkBucketACLPermission(ctx context.Context, tenantID, bucket
 	hasPermission := aclManager.CheckPermission(ctx, aclData, userID, permission)
 
 	// If user doesn't have explicit permission, check if AllUsers has permission
-	// (authenticated users should inherit public permissions)
-	if !hasPermission {
+	// (authenticated users should inherit public permissions) unless the
+	// bucket's IgnorePublicAcls setting disregards public grants
+	if !hasPermission && !h.ignoresPublicACLs(ctx, tenantID, bucketName) {
 		hasPermission = aclManager.CheckPublicAccess(aclData, permission)
 	}
 
@@ -2591,23 +3260,16 @@ func (h *Handler) checkObjectACLPermission(ctx context.Context, bucketPath, obje
 	// Check if user has permission on object ACL
 	hasPermission := aclManager.CheckPermission(ctx, aclData, userID, permission)
 
-	// If user doesn't have explicit permission, check if AllUsers has permission on object
-	if !hasPermission {
+	// If user doesn't have explicit permission, check if AllUsers has permission
+	// on object, unless the bucket's IgnorePublicAcls setting disregards it
+	tenantID, bucketName := bucketPathTenant(bucketPath)
+	if !hasPermission && !h.ignoresPublicACLs(ctx, tenantID, bucketName) {
 		hasPermission = aclManager.CheckPublicAccess(aclData, permission)
 	}
 
 	// If object ACL doesn't grant permission, fall back to bucket ACL
 	// This allows objects to inherit bucket-level permissions
 	if !hasPermission {
-		parts := strings.SplitN(bucketPath, "/", 2)
-		var tenantID, bucketName string
-		if len(parts) == 2 {
-			tenantID = parts[0]
-			bucketName = parts[1]
-		} else {
-			tenantID = ""
-			bucketName = bucketPath
-		}
 		return h.checkBucketACLPermission(ctx, tenantID, bucketName, userID, permission)
 	}
 
@@ -2645,35 +3307,21 @@ func (h *Handler) checkPublicBucketAccess(ctx context.Context, tenantID, bucketN
 
 // checkPublicObjectAccess checks if an object allows public access via ACL
 func (h *Handler) checkPublicObjectAccess(ctx context.Context, bucketPath, objectKey string, permission acl.Permission) bool {
+	// The bucket's public access block overrides object ACLs as well
+	tenantID, bucketName := bucketPathTenant(bucketPath)
+	if pab := h.publicAccessBlock(ctx, tenantID, bucketName); pab != nil && (pab.IgnorePublicAcls || pab.RestrictPublicBuckets) {
+		return false
+	}
+
 	// Get object ACL (bucketPath already contains tenant prefix if needed)
 	objectACL, err := h.objectManager.GetObjectACL(ctx, bucketPath, objectKey)
 	if err != nil {
 		// If object has no ACL, check bucket ACL
-		// Extract bucket name from path
-		parts := strings.SplitN(bucketPath, "/", 2)
-		var tenantID, bucketName string
-		if len(parts) == 2 {
-			tenantID = parts[0]
-			bucketName = parts[1]
-		} else {
-			tenantID = ""
-			bucketName = bucketPath
-		}
 		return h.checkPublicBucketAccess(ctx, tenantID, bucketName, permission)
 	}
 
 	aclData := h.convertObjectACLToInternal(objectACL)
 	if aclData == nil {
-		// Extract bucket name from path
-		parts := strings.SplitN(bucketPath, "/", 2)
-		var tenantID, bucketName string
-		if len(parts) == 2 {
-			tenantID = parts[0]
-			bucketName = parts[1]
-		} else {
-			tenantID = ""
-			bucketName = bucketPath
-		}
 		return h.checkPublicBucketAccess(ctx, tenantID, bucketName, permission)
 	}
 
@@ -2687,6 +3335,9 @@ func (h *Handler) checkPublicObjectAccess(ctx context.Context, bucketPath, objec
 
 // checkAuthenticatedBucketAccess checks if a bucket allows access to any authenticated user
 func (h *Handler) checkAuthenticatedBucketAccess(ctx context.Context, tenantID, bucketName string, permission acl.Permission) bool {
+	if h.ignoresPublicACLs(ctx, tenantID, bucketName) {
+		return false
+	}
 	bucketACL, err := h.bucketManager.GetBucketACL(ctx, tenantID, bucketName)
 	if err != nil {
 		return false
@@ -2705,6 +3356,48 @@ func (h *Handler) checkAuthenticatedBucketAccess(ctx context.Context, tenantID,
 	return aclManager.CheckAuthenticatedAccess(aclData, permission)
 }
 
+// scopedBucketAccessChecker is implemented by auth managers that store bucket
+// permission grants per owning tenant (see auth GrantBucketAccessScoped).
+type scopedBucketAccessChecker interface {
+	CheckBucketAccessScoped(ctx context.Context, bucketName, bucketTenantID, userID string) (bool, string, error)
+}
+
+// checkBucketGrant checks whether an explicit bucket permission grant for the
+// user, one of their groups or their tenant covers permission on the bucket
+// owned by tenantID. Expired grants are ignored by the store. A read grant
+// covers READ, write adds WRITE, and admin covers every permission.
+func (h *Handler) checkBucketGrant(ctx context.Context, tenantID, bucketName, userID string, permission acl.Permission) bool {
+	if h.authManager == nil || userID == "" {
+		return false
+	}
+
+	var hasAccess bool
+	var level string
+	var err error
+	if checker, ok := h.authManager.(scopedBucketAccessChecker); ok {
+		hasAccess, level, err = checker.CheckBucketAccessScoped(ctx, bucketName, tenantID, userID)
+	} else if tenantID == "" {
+		hasAccess, level, err = h.authManager.CheckBucketAccess(ctx, bucketName, userID)
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("bucket", bucketName).Warn("Failed to check bucket permission grants")
+		return false
+	}
+	if !hasAccess {
+		return false
+	}
+
+	switch level {
+	case auth.PermissionLevelAdmin:
+		return true
+	case auth.PermissionLevelWrite:
+		return permission == acl.PermissionRead || permission == acl.PermissionWrite
+	case auth.PermissionLevelRead:
+		return permission == acl.PermissionRead
+	}
+	return false
+}
+
 // getACLManager extracts the ACL manager from bucket manager
 // This is a helper to access the internal ACL manager
 func (h *Handler) getACLManager() acl.Manager {
@@ -2770,6 +3463,11 @@ func (h *Handler) validatePresignedURLAccess(r *http.Request, objectKey string)
 		return "", false, fmt.Errorf("invalid presigned URL: missing access key")
 	}
 
+	// URLs signed before the lifetime cap was lowered stop working at once
+	if expiresIn, err := strconv.ParseInt(r.URL.Query().Get("X-Amz-Expires"), 10, 64); err != nil || !h.presignedExpiryAllowed(expiresIn) {
+		return "", false, fmt.Errorf("presigned URL expiry exceeds the allowed maximum")
+	}
+
 	// Get access key to retrieve secret
 	if h.authManager == nil {
 		return "", false, fmt.Errorf("auth manager not configured")
@@ -2785,7 +3483,7 @@ func (h *Handler) validatePresignedURLAccess(r *http.Request, objectKey string)
 	}
 
 	// Validate presigned URL signature
-	valid, err := presigned.ValidatePresignedURL(r, accessKey.SecretAccessKey)
+	valid, err := presigned.ValidatePresignedURLForService(r, accessKey.SecretAccessKey, h.clockSkew, h.signingService)
 	if err != nil || !valid {
 		logrus.WithFields(logrus.Fields{
 			"accessKeyID": accessKeyID,
@@ -2833,9 +3531,9 @@ func (h *Handler) handleVeeamSOSAPIObject(w http.ResponseWriter, r *http.Request
 // validateShareAccess validates if object is shared and returns real bucket/object names and tenant.
 // For clean share URLs (no tenant in path), lookup is by bucket+object only; tenantID is passed
 // empty so the store finds the share regardless of which tenant owns the bucket.
-func (h *Handler) validateShareAccess(r *http.Request, bucketName, objectKey string) (string, string, string, error) {
+func (h *Handler) validateShareAccess(r *http.Request, bucketName, objectKey string) (*share.Share, error) {
 	if h.shareManager == nil {
-		return "", "", "", fmt.Errorf("share manager not available")
+		return nil, fmt.Errorf("share manager not available")
 	}
 
 	realBucket := bucketName
@@ -2880,23 +3578,33 @@ func (h *Handler) validateShareAccess(r *http.Request, bucketName, objectKey str
 			"tenant": extractedTenant,
 			"error":  err.Error(),
 		}).Warn("Unauthenticated access denied - no active share found")
-		return "", "", "", err
+		return nil, err
 	}
 
 	s, ok := shareInterface.(*share.Share)
 	if !ok || s == nil {
-		return "", "", "", fmt.Errorf("share manager returned invalid type")
+		return nil, fmt.Errorf("share manager returned invalid type")
+	}
+
+	// Hotlink protection: only the share's allowed sites may link to it
+	if !s.RefererAllowed(r.Header.Get("Referer"), r.Header.Get("Origin")) {
+		logrus.WithFields(logrus.Fields{
+			"shareID": s.ID,
+			"referer": r.Header.Get("Referer"),
+			"origin":  r.Header.Get("Origin"),
+		}).Warn("Share access denied - referer not allowed")
+		return nil, share.ErrRefererNotAllowed
 	}
 
-	// Return the share's bucket/object so path resolution uses the canonical stored values
-	shareTenantID := s.TenantID
+	// The caller uses the share's bucket/object so path resolution uses the
+	// canonical stored values
 	logrus.WithFields(logrus.Fields{
 		"bucket":   s.BucketName,
 		"object":   s.ObjectKey,
-		"tenantID": shareTenantID,
+		"tenantID": s.TenantID,
 	}).Info("Shared object access - bypassing authentication")
 
-	return s.BucketName, s.ObjectKey, shareTenantID, nil
+	return s, nil
 }
 
 // sendRangeResponse sends a partial content response for Range requests
@@ -2939,9 +3647,15 @@ func (h *Handler) sendRangeResponse(ctx context.Context, w http.ResponseWriter,
 func (h *Handler) sendFullResponse(ctx context.Context, w http.ResponseWriter, reader io.Reader, size int64, limiter *rate.Limiter) error {
 	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
 
-	// Copy object data to response (throttled to the tenant budget if set).
-	if _, err := io.Copy(w, bandwidth.ThrottleReader(ctx, reader, limiter)); err != nil {
-		logrus.WithError(err).Error("Failed to write object data")
+	// Copy exactly the declared length (throttled to the tenant budget if set).
+	// size is the plaintext size, so a decrypting or decompressing reader that
+	// yields more or fewer bytes must not desync the body from Content-Length.
+	written, err := io.CopyN(w, bandwidth.ThrottleReader(ctx, reader, limiter), size)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"expected": size,
+			"written":  written,
+		}).Error("Failed to write object data")
 		return err
 	}
 
@@ -2959,8 +3673,12 @@ func (h *Handler) checkDeleteObjectPermission(ctx context.Context, user *auth.Us
 		if user.TenantID == tenantID {
 			hasPermission = true
 		} else {
-			// Cross-tenant access - check ACL permissions
-			hasPermission = h.checkObjectACLPermission(ctx, bucketPath, objectKey, user.ID, acl.PermissionWrite)
+			// Cross-tenant access - check bucket grants, then ACL permissions
+			hasPermission = h.checkBucketGrant(ctx, tenantID, bucketName, user.ID, acl.PermissionWrite)
+
+			if !hasPermission {
+				hasPermission = h.checkObjectACLPermission(ctx, bucketPath, objectKey, user.ID, acl.PermissionWrite)
+			}
 
 			// If no explicit object ACL, check bucket WRITE permission
 			if !hasPermission {
@@ -2996,26 +3714,23 @@ func (h *Handler) checkDeleteObjectPermission(ctx context.Context, user *auth.Us
 	return hasPermission
 }
 
-// validateBypassGovernance validates that user is admin if bypass governance is requested
-func (h *Handler) validateBypassGovernance(user *auth.User, userExists bool) error {
+// canBypassGovernance reports whether the caller may delete the object past
+// its GOVERNANCE retention. The bucket policy decides first: an explicit Deny
+// of s3:BypassGovernanceRetention refuses it and an Allow grants it, so a
+// bucket owner can delegate the bypass to one user. Otherwise the user needs
+// the object:bypass_governance capability, which the admin role alone does
+// not give.
+func (h *Handler) canBypassGovernance(r *http.Request, user *auth.User, userExists bool, tenantID, bucketName, objectKey string) bool {
 	if !userExists {
-		return fmt.Errorf("authentication required for bypass governance retention")
-	}
-
-	// Verify user has permission to bypass governance
-	isAdmin := false
-	for _, role := range user.Roles {
-		if role == "admin" {
-			isAdmin = true
-			break
-		}
+		return false
 	}
-
-	if !isAdmin {
-		return fmt.Errorf("only administrators can bypass governance retention")
+	switch h.objectPolicyDecision(r, tenantID, bucketName, objectKey, user.ID, auth.ActionBypassGovernanceRetention) {
+	case bucket.DecisionExplicitDeny:
+		return false
+	case bucket.DecisionAllow:
+		return true
 	}
-
-	return nil
+	return h.authManager != nil && auth.CheckCapabilityInContext(r.Context(), h.authManager, auth.CapObjectBypassGovernance)
 }
 
 // getObjectSizeBeforeDeletion gets object size before deletion for metrics tracking
@@ -3048,6 +3763,11 @@ func (h *Handler) handleDeleteObjectErrors(w http.ResponseWriter, r *http.Reques
 		return true
 	}
 
+	if err == object.ErrPreconditionFailed {
+		h.writeError(w, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold", objectKey, r)
+		return true
+	}
+
 	if versionID != "" && err == object.ErrObjectNotFound {
 		h.writeError(w, "NoSuchVersion", "The specified version does not exist", objectKey, r)
 		return true
@@ -3148,6 +3868,23 @@ func (h *Handler) findExactObjectVersion(ctx context.Context, bucketPath, object
 	if h.metadataStore == nil {
 		return nil, false
 	}
+	if versionID == "" {
+		// Only the latest entry matters here: a single point lookup, so probes
+		// for absent keys never scan the version index.
+		latest, err := h.metadataStore.GetObject(ctx, bucketPath, objectKey)
+		if err != nil || latest == nil {
+			return nil, false
+		}
+		return &metadata.ObjectVersion{
+			VersionID:    latest.VersionID,
+			IsLatest:     true,
+			Key:          objectKey,
+			Size:         latest.Size,
+			ETag:         latest.ETag,
+			LastModified: latest.LastModified,
+			StorageClass: latest.StorageClass,
+		}, true
+	}
 	versions, err := h.metadataStore.ListAllObjectVersions(ctx, bucketPath, objectKey, 0)
 	if err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
@@ -3161,13 +3898,7 @@ func (h *Handler) findExactObjectVersion(ctx context.Context, bucketPath, object
 		if version == nil || version.Key != objectKey {
 			continue
 		}
-		if versionID != "" {
-			if version.VersionID == versionID {
-				return version, true
-			}
-			continue
-		}
-		if version.IsLatest {
+		if version.VersionID == versionID {
 			return version, true
 		}
 	}
@@ -3323,7 +4054,9 @@ func (h *Handler) validateConditionalHeaders(w http.ResponseWriter, r *http.Requ
 	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" && ifNoneMatch == "" {
 		if t, err := http.ParseTime(ifModifiedSince); err == nil {
 			// Object has NOT been modified since the given time → 304.
-			if !lastModified.After(t) {
+			// HTTP dates have second precision, and Last-Modified is sent
+			// truncated, so compare at that precision.
+			if !lastModified.Truncate(time.Second).After(t) {
 				w.WriteHeader(http.StatusNotModified)
 				return false
 			}
@@ -3333,7 +4066,7 @@ func (h *Handler) validateConditionalHeaders(w http.ResponseWriter, r *http.Requ
 	if ifUnmodifiedSince := r.Header.Get("If-Unmodified-Since"); ifUnmodifiedSince != "" && ifMatch == "" {
 		if t, err := http.ParseTime(ifUnmodifiedSince); err == nil {
 			// Object HAS been modified since the given time → 412.
-			if lastModified.After(t) {
+			if lastModified.Truncate(time.Second).After(t) {
 				w.WriteHeader(http.StatusPreconditionFailed)
 				return false
 			}
@@ -3343,6 +4076,24 @@ func (h *Handler) validateConditionalHeaders(w http.ResponseWriter, r *http.Requ
 	return true
 }
 
+// ifRangeMatches reports whether a Range request should be honoured given its
+// If-Range header (RFC 7233 §3.2): true when there is no If-Range, when it is
+// an HTTP date equal to the object's Last-Modified, or otherwise when it is an
+// ETag equal to the object's (quoted or not, as ETags are sent either way).
+// Weak ETags never match.
+func ifRangeMatches(ifRange, etag string, lastModified time.Time) bool {
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, "W/") {
+		return false
+	}
+	if t, err := http.ParseTime(ifRange); err == nil {
+		return lastModified.Truncate(time.Second).Equal(t)
+	}
+	return normalizeETag(ifRange) == normalizeETag(etag)
+}
+
 // normalizeETag strips surrounding double-quote characters from an ETag value so that
 // "abc123" and abc123 compare as equal. This is needed because the stored ETag may or
 // may not include quotes while the If-Match / If-None-Match header value may differ.
@@ -3368,6 +4119,11 @@ func (h *Handler) validateObjectReadPermission(
 		return true
 	}
 
+	// A bucket permission grant covers every object in the bucket
+	if h.checkBucketGrant(r.Context(), tenantID, bucketName, user.ID, acl.PermissionRead) {
+		return true
+	}
+
 	// Cross-tenant: verificar ACL de objeto
 	if h.checkObjectACLPermission(r.Context(), bucketPath, objectKey, user.ID, acl.PermissionRead) {
 		return true
@@ -3384,11 +4140,10 @@ func (h *Handler) validateObjectReadPermission(
 	return false
 }
 
-// setGetObjectResponseHeaders sets all response headers for GetObject operation
+// setGetObjectResponseHeaders sets the response headers for GetObject
+// operation other than the caching ones (see setObjectCacheHeaders)
 func (h *Handler) setGetObjectResponseHeaders(w http.ResponseWriter, obj *object.Object) {
 	w.Header().Set("Content-Type", obj.ContentType)
-	w.Header().Set("ETag", obj.ETag)
-	w.Header().Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
 	w.Header().Set("Accept-Ranges", "bytes")
 	w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
 	w.Header().Set("x-amz-storage-class", storageClassOrStandard(obj.StorageClass))
@@ -3400,21 +4155,17 @@ func (h *Handler) setGetObjectResponseHeaders(w http.ResponseWriter, obj *object
 	if obj.ContentEncoding != "" {
 		w.Header().Set("Content-Encoding", obj.ContentEncoding)
 	}
-	if obj.CacheControl != "" {
-		w.Header().Set("Cache-Control", obj.CacheControl)
-	}
 	if obj.ContentLanguage != "" {
 		w.Header().Set("Content-Language", obj.ContentLanguage)
 	}
 
-	// User-defined metadata (x-amz-meta-*)
-	for k, v := range obj.Metadata {
-		w.Header().Set("x-amz-meta-"+k, v)
-	}
+	// User-defined metadata (x-amz-meta-*), with S3's lowercase names
+	object.SetUserMetadataHeaders(w.Header(), obj.Metadata)
 
-	// Tag count — returned when object has tags
+	// Tag count — returned when object has tags, so clients can skip a
+	// ?tagging request for untagged objects
 	if obj.Tags != nil && len(obj.Tags.Tags) > 0 {
-		w.Header().Set("x-amz-tag-count", strconv.Itoa(len(obj.Tags.Tags)))
+		w.Header().Set("x-amz-tagging-count", strconv.Itoa(len(obj.Tags.Tags)))
 	}
 
 	if obj.VersionID != "" {
@@ -3438,6 +4189,16 @@ func (h *Handler) setGetObjectResponseHeaders(w http.ResponseWriter, obj *object
 	if obj.SSEAlgorithm != "" {
 		w.Header().Set("x-amz-server-side-encryption", obj.SSEAlgorithm)
 	}
+
+	setExpirationHeader(w, obj)
+}
+
+// setExpirationHeader reports an object TTL (x-amz-expires-after-seconds) in
+// the x-amz-expiration form S3 uses for lifecycle expiry.
+func setExpirationHeader(w http.ResponseWriter, obj *object.Object) {
+	if obj.ExpiresAt != nil {
+		w.Header().Set("x-amz-expiration", fmt.Sprintf(`expiry-date="%s"`, obj.ExpiresAt.UTC().Format(http.TimeFormat)))
+	}
 }
 
 // ============================================================================
@@ -3779,13 +4540,30 @@ func (h *Handler) applyObjectCannedACLHeader(ctx context.Context, bucketPath, ob
 	}
 }
 
+// writtenVersionID returns the x-amz-version-id to report for an object just
+// written to bucketName: its version ID on a versioned bucket, "null" when
+// versioning is suspended (the write replaced the null version), and empty
+// when versioning was never enabled.
+func (h *Handler) writtenVersionID(r *http.Request, bucketName, versionID string) string {
+	if versionID != "" {
+		return versionID
+	}
+	// GetVersioning reports never-versioned buckets as Suspended too; only a
+	// stored configuration tells the two apart.
+	bkt, err := h.bucketManager.GetBucketInfo(r.Context(), h.resolveBucketTenantID(r, bucketName), bucketName)
+	if err == nil && bkt.Versioning != nil && bkt.Versioning.Status == "Suspended" {
+		return object.NullVersionID
+	}
+	return ""
+}
+
 // setPutObjectResponseHeaders sets response headers for PutObject operation
-func (h *Handler) setPutObjectResponseHeaders(w http.ResponseWriter, obj *object.Object) {
+func (h *Handler) setPutObjectResponseHeaders(w http.ResponseWriter, obj *object.Object, versionID string) {
 	w.Header().Set("ETag", obj.ETag)
 	w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
 
-	if obj.VersionID != "" {
-		w.Header().Set("x-amz-version-id", obj.VersionID)
+	if versionID != "" {
+		w.Header().Set("x-amz-version-id", versionID)
 	}
 
 	if obj.ChecksumAlgorithm != "" && obj.ChecksumValue != "" {
@@ -3796,6 +4574,8 @@ func (h *Handler) setPutObjectResponseHeaders(w http.ResponseWriter, obj *object
 	if obj.SSEAlgorithm != "" {
 		w.Header().Set("x-amz-server-side-encryption", obj.SSEAlgorithm)
 	}
+
+	setExpirationHeader(w, obj)
 }
 
 // ============================================================================
@@ -3858,12 +4638,14 @@ func (h *Handler) validateHeadBucketReadPermission(
 	return false
 }
 
-// setHeadObjectResponseHeaders sets all response headers for HeadObject operation (metadata only, no body)
+// setHeadObjectResponseHeaders sets the response headers for HeadObject operation (metadata only, no body)
+// other than the caching ones (see setObjectCacheHeaders)
 func (h *Handler) setHeadObjectResponseHeaders(w http.ResponseWriter, obj *object.Object) {
 	w.Header().Set("Content-Type", obj.ContentType)
 	w.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
-	w.Header().Set("ETag", obj.ETag)
-	w.Header().Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+	// Download managers HEAD first and only split the GET into parallel
+	// ranges when this is advertised
+	w.Header().Set("Accept-Ranges", "bytes")
 	w.Header().Set("x-amz-storage-class", storageClassOrStandard(obj.StorageClass))
 
 	// S3 system response headers stored at upload time
@@ -3873,21 +4655,17 @@ func (h *Handler) setHeadObjectResponseHeaders(w http.ResponseWriter, obj *objec
 	if obj.ContentEncoding != "" {
 		w.Header().Set("Content-Encoding", obj.ContentEncoding)
 	}
-	if obj.CacheControl != "" {
-		w.Header().Set("Cache-Control", obj.CacheControl)
-	}
 	if obj.ContentLanguage != "" {
 		w.Header().Set("Content-Language", obj.ContentLanguage)
 	}
 
-	// User-defined metadata (x-amz-meta-*)
-	for k, v := range obj.Metadata {
-		w.Header().Set("x-amz-meta-"+k, v)
-	}
+	// User-defined metadata (x-amz-meta-*), with S3's lowercase names
+	object.SetUserMetadataHeaders(w.Header(), obj.Metadata)
 
-	// Tag count — returned when object has tags
+	// Tag count — returned when object has tags, so clients can skip a
+	// ?tagging request for untagged objects
 	if obj.Tags != nil && len(obj.Tags.Tags) > 0 {
-		w.Header().Set("x-amz-tag-count", strconv.Itoa(len(obj.Tags.Tags)))
+		w.Header().Set("x-amz-tagging-count", strconv.Itoa(len(obj.Tags.Tags)))
 	}
 
 	if obj.VersionID != "" {
@@ -3913,6 +4691,8 @@ func (h *Handler) setHeadObjectResponseHeaders(w http.ResponseWriter, obj *objec
 		w.Header().Set("x-amz-server-side-encryption", obj.SSEAlgorithm)
 	}
 
+	setExpirationHeader(w, obj)
+
 	// Restore status (S3 Glacier restore)
 	if obj.RestoreStatus == "ongoing" {
 		w.Header().Set("x-amz-restore", `ongoing-request="true"`)
@@ -4023,9 +4803,12 @@ func storageClassOrStandard(sc string) string {
 // ============================================================================
 
 // RestoreObject handles POST /{bucket}/{object}?restore.
-// Since MaxIOFS has no cold-storage tier, objects are always "online".
-// The handler accepts the standard RestoreRequest XML, marks the object as
-// restored for the requested number of Days, and returns 200 OK.
+// Archived objects (GLACIER, DEEP_ARCHIVE) are thawed in the background: the
+// request returns 202 Accepted, HEAD reports x-amz-restore
+// ongoing-request="true" until the thaw finishes and GetObject answers 403
+// InvalidObjectState until then. Objects of other storage classes are always
+// online; they are marked as restored for the requested number of Days and
+// the request returns 200 OK.
 // Tools that use S3 lifecycle rules targeting Glacier tiers (Veeam, Commvault,
 // NetBackup) call this endpoint before reading objects; without it they fail
 // with a 405 or 501 even when the data is already accessible.
@@ -4078,6 +4861,22 @@ func (h *Handler) RestoreObject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if obj.NeedsRestore(time.Now()) {
+		// An "ongoing" status without a thaw running here was left by a
+		// restart (or is running on another node); thawing again is harmless
+		_, started, err := h.startRestore(r.Context(), bucketPath, objectKey, versionID, days)
+		if err != nil {
+			h.writeError(w, "InternalError", err.Error(), objectKey, r)
+			return
+		}
+		if !started {
+			h.writeError(w, "RestoreAlreadyInProgress", "Object restore is already in progress", objectKey, r)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	// If already restored and not expired, return 409 RestoreAlreadyInProgress
 	if obj.RestoreStatus == "ongoing" ||
 		(obj.RestoreStatus == "restored" && obj.RestoreExpiresAt != nil && obj.RestoreExpiresAt.After(time.Now())) {
@@ -1367,7 +1367,7 @@ func TestValidateShareAccess_NoShareManager(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
 
 	// No share manager set
-	_, _, _, err := env.handler.validateShareAccess(req, "bucket", "object")
+	_, err := env.handler.validateShareAccess(req, "bucket", "object")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "share manager not available")
 }
@@ -1383,12 +1383,12 @@ func TestValidateShareAccess_TenantBucket(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/tenant-abc/real-bucket/object.txt", nil)
 
 	// Bucket starts with "tenant-", so it extracts tenant and parses object key
-	realBucket, realObject, _, err := env.handler.validateShareAccess(req, "tenant-abc", "real-bucket/object.txt")
+	s, err := env.handler.validateShareAccess(req, "tenant-abc", "real-bucket/object.txt")
 	require.NoError(t, err)
 
 	// Verify the function processed the tenant bucket path
-	assert.Equal(t, "real-bucket", realBucket)
-	assert.Equal(t, "object.txt", realObject)
+	assert.Equal(t, "real-bucket", s.BucketName)
+	assert.Equal(t, "object.txt", s.ObjectKey)
 }
 
 func TestValidateShareAccess_TenantBucketSinglePart(t *testing.T) {
@@ -1401,11 +1401,11 @@ func TestValidateShareAccess_TenantBucketSinglePart(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/tenant-abc/mybucket", nil)
 
 	// When objectKey has no slash, realBucket becomes the whole key, realObject is empty
-	realBucket, realObject, _, err := env.handler.validateShareAccess(req, "tenant-abc", "mybucket")
+	s, err := env.handler.validateShareAccess(req, "tenant-abc", "mybucket")
 	require.NoError(t, err)
 
-	assert.Equal(t, "mybucket", realBucket)
-	assert.Equal(t, "", realObject)
+	assert.Equal(t, "mybucket", s.BucketName)
+	assert.Equal(t, "", s.ObjectKey)
 }
 
 // ============================================
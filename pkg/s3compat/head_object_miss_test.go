@@ -0,0 +1,144 @@
+package s3compat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupHeadMissEnv creates a versioned bucket holding siblings of the probed
+// keys: "dir/file-NNNN" objects with several versions each, plus one key whose
+// latest version is a delete marker. Probing "dir/file" used to scan all of
+// them through the version index before answering 404.
+func setupHeadMissEnv(tb testing.TB, siblings, versions int) (*s3TestEnv, string) {
+	env := setupCompleteS3Environment(tb)
+	bucketName := "probe-bucket"
+	require.NoError(tb, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"?versioning",
+		[]byte(`<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`))
+	env.router.ServeHTTP(w, req)
+	require.Equal(tb, http.StatusOK, w.Code)
+
+	for i := 0; i < siblings; i++ {
+		for v := 0; v < versions; v++ {
+			req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/dir/file-%04d", bucketName, i), []byte(fmt.Sprintf("v%d", v)))
+			env.router.ServeHTTP(w, req)
+			require.Equal(tb, http.StatusOK, w.Code, w.Body.String())
+		}
+	}
+
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/dir/removed", []byte("gone soon"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(tb, http.StatusOK, w.Code)
+	req, w = env.makeS3Request("DELETE", "/"+bucketName+"/dir/removed", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(tb, http.StatusNoContent, w.Code)
+
+	return env, bucketName
+}
+
+func TestHeadObject_MissingKey(t *testing.T) {
+	env, bucketName := setupHeadMissEnv(t, 3, 2)
+	defer env.cleanup()
+
+	t.Run("absent key", func(t *testing.T) {
+		req, w := env.makeS3Request("HEAD", "/"+bucketName+"/dir/file", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("x-amz-delete-marker"))
+	})
+
+	t.Run("latest version is a delete marker", func(t *testing.T) {
+		req, w := env.makeS3Request("HEAD", "/"+bucketName+"/dir/removed", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "true", w.Header().Get("x-amz-delete-marker"))
+		assert.NotEmpty(t, w.Header().Get("x-amz-version-id"))
+	})
+
+	t.Run("existing sibling", func(t *testing.T) {
+		req, w := env.makeS3Request("HEAD", "/"+bucketName+"/dir/file-0001", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("If-None-Match create over a delete marker", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/dir/removed", []byte("back again"))
+		req.Header.Set("If-None-Match", "*")
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		req, w = env.makeS3Request("PUT", "/"+bucketName+"/dir/removed", []byte("again"))
+		req.Header.Set("If-None-Match", "*")
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func TestHeadObject_DataWithoutMetadata(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	bucketName := "orphan-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/orphan.txt", []byte("data on disk"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	store, ok := env.handler.metadataStore.(interface {
+		DeleteObject(ctx context.Context, bucket, key string, versionID ...string) error
+	})
+	require.True(t, ok)
+	require.NoError(t, store.DeleteObject(context.Background(), env.tenantID+"/"+bucketName, "orphan.txt"))
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/orphan.txt", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, "GET serves the file without metadata")
+
+	req, w = env.makeS3Request("HEAD", "/"+bucketName+"/orphan.txt", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "HEAD must agree with GET")
+	assert.Equal(t, "12", w.Header().Get("Content-Length"))
+}
+
+// BenchmarkHeadObject_Miss measures HEAD latency for keys that do not exist
+// (rclone --no-traverse style probing) in a bucket with many versioned
+// siblings. The handler is called directly so SigV4 verification does not
+// dominate the numbers.
+func BenchmarkHeadObject_Miss(b *testing.B) {
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.WarnLevel)
+	defer logrus.SetLevel(level)
+
+	env, bucketName := setupHeadMissEnv(b, 200, 3)
+	defer env.cleanup()
+	user := &auth.User{ID: env.userID, TenantID: env.tenantID, Roles: []string{"admin"}}
+
+	for _, tc := range []struct{ name, key string }{
+		{"absent", "dir/file"},
+		{"delete_marker", "dir/removed"},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest(http.MethodHead, "/"+bucketName+"/"+tc.key, nil)
+				req = mux.SetURLVars(req, map[string]string{"bucket": bucketName, "object": tc.key})
+				req = req.WithContext(setUserInContext(req.Context(), user))
+				w := httptest.NewRecorder()
+				env.handler.HeadObject(w, req)
+				if w.Code != http.StatusNotFound {
+					b.Fatalf("expected 404, got %d", w.Code)
+				}
+			}
+		})
+	}
+}
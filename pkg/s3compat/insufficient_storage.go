@@ -0,0 +1,17 @@
+package s3compat
+
+import (
+	"net/http"
+)
+
+// insufficientStorageRetryAfter is the Retry-After, in seconds, sent with
+// writes refused because the data disk is full.
+const insufficientStorageRetryAfter = "60"
+
+// writeInsufficientStorage answers a write refused because the data disk is
+// full or below its reserved headroom. A 503 with Retry-After makes SDKs back
+// off instead of retrying the upload right away.
+func (h *Handler) writeInsufficientStorage(w http.ResponseWriter, r *http.Request, resource string) {
+	w.Header().Set("Retry-After", insufficientStorageRetryAfter)
+	h.writeError(w, "ServiceUnavailable", "Insufficient storage space on the server, retry later", resource, r)
+}
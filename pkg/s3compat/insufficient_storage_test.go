@@ -0,0 +1,58 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diskFullBody streams some bytes and then fails like a write to a full disk.
+type diskFullBody struct {
+	data io.Reader
+}
+
+func (b *diskFullBody) Read(p []byte) (int, error) {
+	n, err := b.data.Read(p)
+	if err == io.EOF {
+		return n, &os.PathError{Op: "write", Path: "upload", Err: syscall.ENOSPC}
+	}
+	return n, err
+}
+
+func TestPutObject_DiskFull(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "full-disk-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/big.bin", nil)
+	req.Body = io.NopCloser(&diskFullBody{data: bytes.NewReader(make([]byte, 64*1024))})
+	env.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+	assert.Equal(t, insufficientStorageRetryAfter, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "<Code>ServiceUnavailable</Code>")
+
+	// The spooled upload is removed and no object is created
+	err := filepath.Walk(env.tempDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && (strings.HasPrefix(info.Name(), "maxiofs-upload-") || strings.HasPrefix(info.Name(), ".tmp_")) {
+			t.Errorf("partial upload left behind: %s", path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	req, w = env.makeS3Request("HEAD", "/"+bucketName+"/big.bin", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,97 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/lifecycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3BucketLifecycle_TagFilter(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "lifecycle-tag-filter"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	putLifecycle := func(rule string) (int, string) {
+		body := "<LifecycleConfiguration><Rule><ID>expire-temp</ID><Status>Enabled</Status>" + rule + "</Rule></LifecycleConfiguration>"
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?lifecycle", []byte(body))
+		env.router.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	t.Run("filter exclusivity is validated", func(t *testing.T) {
+		for name, filter := range map[string]string{
+			"prefix and tag":     `<Filter><Prefix>tmp/</Prefix><Tag><Key>temp</Key><Value>true</Value></Tag></Filter>`,
+			"two bare tags":      `<Filter><Tag><Key>temp</Key><Value>true</Value></Tag><Tag><Key>team</Key><Value>data</Value></Tag></Filter>`,
+			"tag and And":        `<Filter><Tag><Key>temp</Key><Value>true</Value></Tag><And><Prefix>tmp/</Prefix></And></Filter>`,
+			"duplicate And keys": `<Filter><And><Tag><Key>temp</Key><Value>true</Value></Tag><Tag><Key>temp</Key><Value>yes</Value></Tag></And></Filter>`,
+		} {
+			code, body := putLifecycle(filter + `<Expiration><Days>1</Days></Expiration>`)
+			assert.Equal(t, http.StatusBadRequest, code, name)
+			assert.Contains(t, body, "MalformedXML", name)
+		}
+
+		code, body := putLifecycle(`<Filter><Tag><Key>temp</Key><Value>true</Value></Tag></Filter>` +
+			`<Expiration><ExpiredObjectDeleteMarker>true</ExpiredObjectDeleteMarker></Expiration>`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, body, "InvalidRequest")
+	})
+
+	t.Run("And filter round-trips", func(t *testing.T) {
+		code, body := putLifecycle(`<Filter><And><Prefix>tmp/</Prefix><Tag><Key>temp</Key><Value>true</Value></Tag>` +
+			`<Tag><Key>team</Key><Value>data</Value></Tag></And></Filter><Expiration><Days>1</Days></Expiration>`)
+		require.Equal(t, http.StatusOK, code, body)
+
+		req, w := env.makeS3Request("GET", "/"+bucketName+"?lifecycle", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		body = w.Body.String()
+		assert.Contains(t, body, "<And><Prefix>tmp/</Prefix>")
+		assert.Contains(t, body, "<Tag><Key>team</Key><Value>data</Value></Tag>")
+		assert.Contains(t, body, "<Tag><Key>temp</Key><Value>true</Value></Tag>")
+	})
+
+	t.Run("worker expires only tagged objects", func(t *testing.T) {
+		put := func(key, tagging string) {
+			req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+key, []byte("data"))
+			if tagging != "" {
+				req.Header.Set("x-amz-tagging", tagging)
+			}
+			env.router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		}
+		put("scratch/tagged.txt", "temp=true")
+		put("scratch/not-temp.txt", "temp=false")
+		put("scratch/untagged.txt", "")
+
+		// A date in the future puts every existing object past the cutoff
+		date := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour).Format(time.RFC3339)
+		code, body := putLifecycle(`<Filter><Tag><Key>temp</Key><Value>true</Value></Tag></Filter>` +
+			`<Expiration><Date>` + date + `</Date></Expiration>`)
+		require.Equal(t, http.StatusOK, code, body)
+
+		head := func(key string) int {
+			req, w := env.makeS3Request("HEAD", "/"+bucketName+"/"+key, nil)
+			env.router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		worker := lifecycle.NewWorker(env.bucketManager, env.objectManager, nil)
+		worker.Start(ctx, 20*time.Millisecond)
+		defer worker.Stop()
+
+		require.Eventually(t, func() bool {
+			return head("scratch/tagged.txt") == http.StatusNotFound
+		}, 5*time.Second, 20*time.Millisecond)
+		assert.Equal(t, http.StatusOK, head("scratch/not-temp.txt"))
+		assert.Equal(t, http.StatusOK, head("scratch/untagged.txt"))
+	})
+}
@@ -0,0 +1,78 @@
+package s3compat
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBuckets_NonAdminSeesOnlyAccessibleBuckets(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	partnerID, partnerKey, partnerSecret := createForeignUser(t, env)
+
+	// Owner tenant: one bucket shared with the partner user, one private
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, "shared-datasets", ""))
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, "private-data", ""))
+	grants, ok := env.authManager.(scopedGrantManager)
+	require.True(t, ok, "auth manager should support scoped bucket grants")
+	require.NoError(t, grants.GrantBucketAccessScoped(ctx, "shared-datasets", env.tenantID, partnerID, "", auth.PermissionLevelRead, "admin", 0))
+
+	// Partner tenant: the user's own bucket and a colleague's bucket
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, "partner-tenant", "partner-own", partnerID))
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, "partner-tenant", "partner-colleague", "colleague-user-id"))
+
+	req, w := makeSignedRequest("GET", "/", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result struct {
+		Owner struct {
+			ID          string `xml:"ID"`
+			DisplayName string `xml:"DisplayName"`
+		} `xml:"Owner"`
+		Buckets []struct {
+			Name         string `xml:"Name"`
+			CreationDate string `xml:"CreationDate"`
+			BucketRegion string `xml:"BucketRegion"`
+		} `xml:"Buckets>Bucket"`
+	}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &result))
+
+	assert.Equal(t, partnerID, result.Owner.ID)
+	assert.Equal(t, "Partner User", result.Owner.DisplayName)
+
+	listed := make(map[string]string)
+	for _, b := range result.Buckets {
+		listed[b.Name] = b.CreationDate
+		assert.Equal(t, "us-east-1", b.BucketRegion)
+	}
+	assert.Len(t, listed, 2, "listed: %v", listed)
+	assert.NotContains(t, listed, "private-data")
+	assert.NotContains(t, listed, "partner-colleague")
+
+	for name, tenantID := range map[string]string{
+		"partner-own":     "partner-tenant",
+		"shared-datasets": env.tenantID,
+	} {
+		require.Contains(t, listed, name)
+		info, err := env.bucketManager.GetBucketInfo(ctx, tenantID, name)
+		require.NoError(t, err)
+		assert.Equal(t, info.CreatedAt.UTC().Format("2006-01-02T15:04:05.000Z"), listed[name], name)
+	}
+
+	// Once the grant is revoked the shared bucket disappears from the listing
+	require.NoError(t, grants.RevokeBucketAccessScoped(ctx, "shared-datasets", env.tenantID, partnerID, ""))
+	req, w = makeSignedRequest("GET", "/", nil, partnerKey, partnerSecret)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "<Name>shared-datasets</Name>")
+	assert.Contains(t, w.Body.String(), "<Name>partner-own</Name>")
+}
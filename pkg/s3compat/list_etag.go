@@ -0,0 +1,45 @@
+package s3compat
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// listETag returns the entity tag of the bucket's listings and reports
+// whether the request's If-None-Match already names it, in which case a 304
+// Not Modified carrying the tag has been written and the listing can be
+// skipped. A bucket whose objects did not change keeps its tag, so a sync
+// tool relisting an idle bucket costs one point lookup instead of a scan.
+//
+// Call it before listing; on "" (the tag could not be read) the listing is
+// served uncached. The caller sets the ETag header on its 200 response only,
+// so error responses never carry one.
+func (h *Handler) listETag(w http.ResponseWriter, r *http.Request, bucketPath string) (string, bool) {
+	etag, err := h.objectManager.ListETag(r.Context(), bucketPath)
+	if err != nil {
+		logrus.WithError(err).WithField("bucket", bucketPath).Debug("Failed to read bucket list ETag")
+		return "", false
+	}
+	etag = quotedETag(etag)
+	if ifNoneMatchIncludes(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return etag, true
+	}
+	return etag, false
+}
+
+// ifNoneMatchIncludes reports whether an If-None-Match header names etag,
+// using the weak comparison RFC 7232 prescribes for it: "*" or any listed
+// tag, with or without W/, matches.
+func ifNoneMatchIncludes(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || (candidate != "" && normalizeETag(candidate) == normalizeETag(etag)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestS3ListObjectsV2StartAfter tests resuming a listing after a known key
+// without a continuation token, as sync tools do.
+func TestS3ListObjectsV2StartAfter(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "start-after-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	bucketPath := env.tenantID + "/" + bucketName
+	for _, key := range []string{"file1.txt", "file2.txt", "file3.txt", "file4.txt", "file5.txt", "logs/a.txt", "logs/b.txt", "logs/sub/c.txt", "z.txt"} {
+		_, err := env.objectManager.PutObject(ctx, bucketPath, key, bytes.NewReader([]byte("content")), http.Header{})
+		require.NoError(t, err)
+	}
+
+	list := func(params url.Values) ListBucketResultV2 {
+		t.Helper()
+		params.Set("list-type", "2")
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/?"+params.Encode(), nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result ListBucketResultV2
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+	keys := func(result ListBucketResultV2) []string {
+		out := []string{}
+		for _, obj := range result.Contents {
+			out = append(out, obj.Key)
+		}
+		return out
+	}
+	prefixes := func(result ListBucketResultV2) []string {
+		out := []string{}
+		for _, cp := range result.CommonPrefixes {
+			out = append(out, cp.Prefix)
+		}
+		return out
+	}
+
+	t.Run("keys strictly after start-after", func(t *testing.T) {
+		result := list(url.Values{"start-after": {"file3.txt"}})
+		assert.Equal(t, []string{"file4.txt", "file5.txt", "logs/a.txt", "logs/b.txt", "logs/sub/c.txt", "z.txt"}, keys(result))
+		assert.Equal(t, "file3.txt", result.StartAfter)
+	})
+
+	t.Run("start-after need not be an existing key", func(t *testing.T) {
+		result := list(url.Values{"start-after": {"file2.zip"}})
+		assert.Equal(t, "file3.txt", keys(result)[0])
+	})
+
+	t.Run("with delimiter", func(t *testing.T) {
+		result := list(url.Values{"start-after": {"file3.txt"}, "delimiter": {"/"}})
+		assert.Equal(t, []string{"file4.txt", "file5.txt", "z.txt"}, keys(result))
+		assert.Equal(t, []string{"logs/"}, prefixes(result))
+	})
+
+	t.Run("with prefix and delimiter", func(t *testing.T) {
+		result := list(url.Values{"start-after": {"logs/a.txt"}, "prefix": {"logs/"}, "delimiter": {"/"}})
+		assert.Equal(t, []string{"logs/b.txt"}, keys(result))
+		assert.Equal(t, []string{"logs/sub/"}, prefixes(result))
+	})
+
+	t.Run("start-after before the prefix lists the whole prefix", func(t *testing.T) {
+		result := list(url.Values{"start-after": {"file3.txt"}, "prefix": {"logs/"}})
+		assert.Equal(t, []string{"logs/a.txt", "logs/b.txt", "logs/sub/c.txt"}, keys(result))
+	})
+
+	t.Run("start-after past the prefix lists nothing", func(t *testing.T) {
+		result := list(url.Values{"start-after": {"logs/zzz"}, "prefix": {"logs/"}})
+		assert.Empty(t, keys(result))
+		assert.False(t, result.IsTruncated)
+	})
+
+	t.Run("continuation token takes precedence", func(t *testing.T) {
+		first := list(url.Values{"start-after": {"file1.txt"}, "max-keys": {"2"}})
+		assert.Equal(t, []string{"file2.txt", "file3.txt"}, keys(first))
+		require.True(t, first.IsTruncated)
+		require.NotEmpty(t, first.NextContinuationToken)
+
+		// A start-after further along is ignored in favour of the token
+		next := list(url.Values{"start-after": {"z.txt"}, "continuation-token": {first.NextContinuationToken}, "max-keys": {"2"}})
+		assert.Equal(t, []string{"file4.txt", "file5.txt"}, keys(next))
+		assert.Equal(t, "z.txt", next.StartAfter)
+	})
+}
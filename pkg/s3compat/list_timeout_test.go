@@ -0,0 +1,51 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListObjects_ListTimeout(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "timeout-listing"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	putTestObject(t, env, bucketName, "a.txt", []byte("a"))
+
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/?list-type=2", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// A deadline that has already passed by the time the scan starts
+	env.handler.SetListTimeout(time.Nanosecond)
+
+	for _, path := range []string{"/" + bucketName + "/?list-type=2", "/" + bucketName + "/", "/" + bucketName + "/?versions"} {
+		req, w = env.makeS3Request("GET", path, nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, path)
+		assert.Contains(t, w.Body.String(), "<Code>SlowDown</Code>", path)
+	}
+}
+
+func TestListObjects_ClientDisconnected(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "abandoned-listing"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	putTestObject(t, env, bucketName, "a.txt", []byte("a"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/?list-type=2", nil)
+	env.router.ServeHTTP(w, req.WithContext(ctx))
+
+	// Nothing is listed for a client that has gone away
+	assert.NotContains(t, w.Body.String(), "<Key>a.txt</Key>")
+}
@@ -0,0 +1,56 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestS3ReadOnlyMaintenanceMode tests that writes are refused and reads still
+// served while read-only maintenance mode is on.
+func TestS3ReadOnlyMaintenanceMode(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	var readOnly atomic.Bool
+	env.router.Use(middleware.MaintenanceModeS3(readOnly.Load))
+
+	ctx := context.Background()
+	bucketName := "maintenance-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/before.txt", []byte("written before"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	readOnly.Store(true)
+
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/during.txt", []byte("refused"))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>ServiceUnavailable</Code>")
+
+	req, w = env.makeS3Request("DELETE", "/"+bucketName+"/before.txt", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/before.txt", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "written before", w.Body.String())
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/?list-type=2", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	readOnly.Store(false)
+
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/during.txt", []byte("accepted"))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
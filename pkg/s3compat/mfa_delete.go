@@ -0,0 +1,63 @@
+package s3compat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// mfaDeleteEnabled reports whether MFA Delete is enabled on the bucket. A
+// missing bucket has nothing to protect; any other error is returned so
+// callers fail closed instead of skipping the MFA check.
+func (h *Handler) mfaDeleteEnabled(ctx context.Context, tenantID, bucketName string) (bool, error) {
+	cfg, err := h.bucketManager.GetVersioning(ctx, tenantID, bucketName)
+	if err != nil {
+		if errors.Is(err, bucket.ErrBucketNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return cfg != nil && cfg.MFADelete, nil
+}
+
+// requireMFADelete enforces the bucket's MFA Delete setting for a request that
+// permanently deletes a version. It writes the error response and returns
+// false when the request may not proceed.
+func (h *Handler) requireMFADelete(w http.ResponseWriter, r *http.Request, tenantID, bucketName, resource string) bool {
+	enabled, err := h.mfaDeleteEnabled(r.Context(), tenantID, bucketName)
+	if err != nil {
+		logrus.WithError(err).WithField("bucket", bucketName).Error("MFA Delete: failed to read bucket versioning")
+		h.writeError(w, "InternalError", "Failed to read the bucket versioning configuration", resource, r)
+		return false
+	}
+	return !enabled || h.requireMFA(w, r, resource)
+}
+
+// requireMFA checks the request's x-amz-mfa header against the 2FA of the
+// authenticated user. It writes AccessDenied and returns false when the
+// header is missing or the code is not valid.
+func (h *Handler) requireMFA(w http.ResponseWriter, r *http.Request, resource string) bool {
+	userID := ""
+	if user, ok := auth.GetUserFromContext(r.Context()); ok {
+		userID = user.ID
+	}
+
+	err := auth.ErrMFARequired
+	if h.authManager != nil {
+		err = auth.VerifyMFAHeader(r.Context(), h.authManager, userID, r.Header.Get("x-amz-mfa"), middleware.ClientIP(r, h.trustedProxies))
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"resource": resource,
+			"userID":   userID,
+		}).Warn("MFA Delete: request rejected without a valid x-amz-mfa code")
+		h.writeError(w, "AccessDenied", err.Error(), resource, r)
+		return false
+	}
+	return true
+}
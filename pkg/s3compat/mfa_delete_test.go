@@ -0,0 +1,182 @@
+package s3compat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFADelete(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	bucketName := "mfa-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+
+	setup, err := auth.Generate2FASecret("mfa-user", "MaxIOFS")
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(setup.Secret, time.Now())
+	require.NoError(t, err)
+	backupCodes, err := env.authManager.Enable2FA(ctx, env.userID, code, setup.Secret)
+	require.NoError(t, err)
+
+	// A TOTP code is accepted once per time step, so after the first one
+	// the tests use backup codes, each good for one request too
+	const serial = "arn:aws:iam::123456789012:mfa/user "
+	mfaHeader := func() string {
+		require.NotEmpty(t, backupCodes)
+		code := backupCodes[0]
+		backupCodes = backupCodes[1:]
+		return serial + code
+	}
+	putVersioning := func(body, mfa string) int {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?versioning", []byte(body))
+		if mfa != "" {
+			req.Header.Set("x-amz-mfa", mfa)
+		}
+		env.router.ServeHTTP(w, req)
+		return w.Code
+	}
+	putVersion := func(key, body string) string {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+key, []byte(body))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Header().Get("x-amz-version-id")
+	}
+	deleteVersion := func(key, versionID, mfa string) int {
+		req, w := env.makeS3Request("DELETE", "/"+bucketName+"/"+key+"?versionId="+versionID, nil)
+		if mfa != "" {
+			req.Header.Set("x-amz-mfa", mfa)
+		}
+		env.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	enableXML := `<VersioningConfiguration><Status>Enabled</Status><MfaDelete>Enabled</MfaDelete></VersioningConfiguration>`
+
+	t.Run("enabling MFA Delete requires a code", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, putVersioning(enableXML, ""))
+		assert.Equal(t, http.StatusForbidden, putVersioning(enableXML, serial+"000000"))
+		code, err := totp.GenerateCode(setup.Secret, time.Now())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, putVersioning(enableXML, serial+code))
+		assert.Equal(t, http.StatusForbidden, putVersioning(enableXML, serial+code), "a TOTP code can't be replayed")
+
+		req, w := env.makeS3Request("GET", "/"+bucketName+"?versioning", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "<MfaDelete>Enabled</MfaDelete>")
+	})
+
+	t.Run("versioning changes need a code while enabled", func(t *testing.T) {
+		suspend := `<VersioningConfiguration><Status>Suspended</Status></VersioningConfiguration>`
+		assert.Equal(t, http.StatusForbidden, putVersioning(suspend, ""))
+
+		cfg, err := env.bucketManager.GetVersioning(ctx, env.tenantID, bucketName)
+		require.NoError(t, err)
+		assert.Equal(t, "Enabled", cfg.Status)
+		assert.True(t, cfg.MFADelete)
+	})
+
+	t.Run("version delete requires a code", func(t *testing.T) {
+		v1 := putVersion("doc.txt", "one")
+		putVersion("doc.txt", "two")
+		require.NotEmpty(t, v1)
+
+		assert.Equal(t, http.StatusForbidden, deleteVersion("doc.txt", v1, ""))
+		assert.Equal(t, http.StatusNoContent, deleteVersion("doc.txt", v1, mfaHeader()))
+	})
+
+	t.Run("simple delete does not require a code", func(t *testing.T) {
+		req, w := env.makeS3Request("DELETE", "/"+bucketName+"/doc.txt", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("batch version delete requires a code", func(t *testing.T) {
+		v := putVersion("batch.txt", "data")
+		body := []byte(`<Delete><Object><Key>batch.txt</Key><VersionId>` + v + `</VersionId></Object></Delete>`)
+
+		req, w := env.makeS3Request("POST", "/"+bucketName+"?delete", body)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		req, w = env.makeS3Request("POST", "/"+bucketName+"?delete", body)
+		req.Header.Set("x-amz-mfa", mfaHeader())
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "<Deleted>")
+	})
+
+	t.Run("disabling MFA Delete", func(t *testing.T) {
+		disable := `<VersioningConfiguration><Status>Enabled</Status><MfaDelete>Disabled</MfaDelete></VersioningConfiguration>`
+		require.Equal(t, http.StatusOK, putVersioning(disable, mfaHeader()))
+
+		v := putVersion("free.txt", "data")
+		assert.Equal(t, http.StatusNoContent, deleteVersion("free.txt", v, ""))
+	})
+}
+
+func TestMFADelete_LockoutAfterInvalidCodes(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	bucketName := "mfa-lockout-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	setup, err := auth.Generate2FASecret("mfa-user", "MaxIOFS")
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(setup.Secret, time.Now())
+	require.NoError(t, err)
+	backupCodes, err := env.authManager.Enable2FA(ctx, env.userID, code, setup.Secret)
+	require.NoError(t, err)
+
+	putVersioning := func(code string) *httptest.ResponseRecorder {
+		body := `<VersioningConfiguration><Status>Enabled</Status><MfaDelete>Enabled</MfaDelete></VersioningConfiguration>`
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?versioning", []byte(body))
+		req.Header.Set("x-amz-mfa", "arn:aws:iam::123456789012:mfa/user "+code)
+		env.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The default lockout threshold is 5 failed attempts
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusForbidden, putVersioning("000000").Code)
+	}
+	locked, _, err := env.authManager.IsAccountLocked(ctx, env.userID)
+	require.NoError(t, err)
+	assert.True(t, locked, "invalid MFA codes lock the account like failed logins")
+
+	w := putVersioning(backupCodes[0])
+	assert.Equal(t, http.StatusForbidden, w.Code, "a valid code is refused while locked")
+	assert.Contains(t, w.Body.String(), auth.ErrMFALocked.Error())
+}
+
+// versioningErrBucketMgr fails every GetVersioning call
+type versioningErrBucketMgr struct {
+	bucket.Manager
+}
+
+func (m *versioningErrBucketMgr) GetVersioning(ctx context.Context, tenantID, name string) (*bucket.VersioningConfig, error) {
+	return nil, errors.New("metadata store unavailable")
+}
+
+func TestRequireMFADelete_FailsClosed(t *testing.T) {
+	h := NewHandler(&versioningErrBucketMgr{}, nil)
+
+	req := httptest.NewRequest("DELETE", "/bucket/key?versionId=v1", nil)
+	w := httptest.NewRecorder()
+	assert.False(t, h.requireMFADelete(w, req, "", "bucket", "key"), "an unreadable versioning config must not skip the MFA check")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "InternalError")
+}
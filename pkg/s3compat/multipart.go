@@ -18,6 +18,7 @@ import (
 	"github.com/maxiofs/maxiofs/internal/cluster"
 	"github.com/maxiofs/maxiofs/internal/metadata"
 	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -75,6 +76,7 @@ type Part struct {
 	LastModified time.Time `xml:"LastModified"`
 	ETag         string    `xml:"ETag"`
 	Size         int64     `xml:"Size"`
+	objectAttributesCksum
 }
 
 type CompleteMultipartUploadRequest struct {
@@ -85,6 +87,7 @@ type CompleteMultipartUploadRequest struct {
 type CompletePart struct {
 	PartNumber int    `xml:"PartNumber"`
 	ETag       string `xml:"ETag"`
+	objectAttributesCksum
 }
 
 type CompleteMultipartUploadResult struct {
@@ -93,6 +96,7 @@ type CompleteMultipartUploadResult struct {
 	Bucket   string   `xml:"Bucket"`
 	Key      string   `xml:"Key"`
 	ETag     string   `xml:"ETag"`
+	objectAttributesCksum
 }
 
 // CreateMultipartUpload initiates a multipart upload
@@ -115,6 +119,16 @@ func (h *Handler) CreateMultipartUpload(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// A policy Deny on the key covers multipart uploads too (s3:PutObject)
+	user, _ := auth.GetUserFromContext(r.Context())
+	tenantID := h.resolveBucketTenantID(r, bucketName)
+	if _, ok := h.enforceObjectPolicy(w, r, user, tenantID, bucketName, objectKey, "s3:PutObject"); !ok {
+		return
+	}
+	if h.rejectPublicACL(w, r, tenantID, bucketName, objectKey, requestSetsPublicACL(r)) {
+		return
+	}
+
 	bucketPath := h.getBucketPath(r, bucketName)
 	// Create multipart upload
 	upload, err := h.objectManager.CreateMultipartUpload(r.Context(), bucketPath, objectKey, r.Header)
@@ -123,6 +137,22 @@ func (h *Handler) CreateMultipartUpload(w http.ResponseWriter, r *http.Request)
 			h.writeError(w, "NoSuchBucket", "The specified bucket does not exist", bucketName, r)
 			return
 		}
+		if errors.Is(err, object.ErrInvalidStorageClass) {
+			h.writeError(w, "InvalidStorageClass", "The storage class you specified is not valid", objectKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrReservedKeyPrefix) {
+			h.writeError(w, "AccessDenied", "The object key is in a reserved system namespace", objectKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrInvalidChecksumAlgorithm) {
+			h.writeError(w, "InvalidRequest", "Value for x-amz-checksum-algorithm header is invalid", objectKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrTooManyMultipartUploads) {
+			h.writeError(w, "SlowDown", "Complete or abort multipart uploads before starting new ones: "+err.Error(), objectKey, r)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), objectKey, r)
 		return
 	}
@@ -132,6 +162,12 @@ func (h *Handler) CreateMultipartUpload(w http.ResponseWriter, r *http.Request)
 		Key:      objectKey,
 		UploadId: upload.UploadID,
 	}
+	if sse := upload.Metadata["x-amz-server-side-encryption"]; sse != "" {
+		w.Header().Set("x-amz-server-side-encryption", sse)
+	}
+	if algo := upload.Metadata["x-amz-checksum-algorithm"]; algo != "" {
+		w.Header().Set("x-amz-checksum-algorithm", algo)
+	}
 
 	h.writeXMLResponse(w, http.StatusOK, result)
 }
@@ -204,8 +240,8 @@ func (h *Handler) UploadPart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	partNumber, err := strconv.Atoi(partNumberStr)
-	if err != nil || partNumber < 1 || partNumber > 10000 {
-		h.writeError(w, "InvalidArgument", "Part number must be between 1 and 10000", objectKey, r)
+	if err != nil || partNumber < 1 || partNumber > object.MaxMultipartParts {
+		h.writeError(w, "InvalidArgument", fmt.Sprintf("Part number must be an integer between 1 and %d", object.MaxMultipartParts), objectKey, r)
 		return
 	}
 
@@ -276,23 +312,59 @@ func (h *Handler) UploadPart(w http.ResponseWriter, r *http.Request) {
 		r.Header.Del("Content-Encoding")
 	}
 
+	// Content-MD5 covers the decoded part data
+	bodyReader, err = object.NewContentMD5Reader(bodyReader, r.Header.Get("Content-MD5"))
+	if err != nil {
+		h.writeDigestError(w, r, err, objectKey)
+		return
+	}
+
 	// Throttle the part upload to the owning tenant's aggregate bandwidth budget
 	// (no-op when unlimited); shares the same tenant limiter as other transfers.
 	bodyReader = bandwidth.ThrottleReader(r.Context(), bodyReader, h.tenantBandwidthLimiter(r.Context(), r, bucketName))
 
 	// Upload the part
-	part, err := h.objectManager.UploadPart(r.Context(), uploadID, partNumber, bodyReader)
+	part, err := h.objectManager.UploadPart(r.Context(), uploadID, partNumber, bodyReader, r.Header)
 	if err != nil {
 		if err == object.ErrUploadNotFound {
 			h.writeError(w, "NoSuchUpload", "The specified multipart upload does not exist", uploadID, r)
 			return
 		}
+		if errors.Is(err, object.ErrBadChecksum) {
+			h.writeError(w, "BadDigest", "The checksum you specified did not match what we received", objectKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrInvalidChecksumAlgorithm) {
+			h.writeError(w, "InvalidRequest", err.Error(), objectKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrBucketQuotaExceeded) {
+			h.writeError(w, "QuotaExceeded", err.Error(), objectKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrTooManyParts) || errors.Is(err, object.ErrInvalidPartNumber) {
+			h.writeError(w, "InvalidArgument", err.Error(), objectKey, r)
+			return
+		}
+		if h.writeDigestError(w, r, err, objectKey) {
+			return
+		}
+		if storage.IsInsufficientStorage(err) {
+			h.writeInsufficientStorage(w, r, objectKey)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), objectKey, r)
 		return
 	}
 
 	// Return ETag in response header
 	w.Header().Set("ETag", part.ETag)
+	if part.SSEAlgorithm != "" {
+		w.Header().Set("x-amz-server-side-encryption", part.SSEAlgorithm)
+	}
+	if part.ChecksumValue != "" {
+		w.Header().Set(object.ChecksumHeader(part.ChecksumAlgorithm), part.ChecksumValue)
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -318,8 +390,8 @@ func (h *Handler) ListParts(w http.ResponseWriter, r *http.Request) {
 	partNumberMarker := 0
 	if markerStr := r.URL.Query().Get("part-number-marker"); markerStr != "" {
 		parsed, err := strconv.Atoi(markerStr)
-		if err != nil || parsed < 0 {
-			h.writeError(w, "InvalidArgument", "Argument part-number-marker must be a non-negative integer", uploadID, r)
+		if err != nil || parsed < 0 || parsed > object.MaxMultipartParts {
+			h.writeError(w, "InvalidArgument", fmt.Sprintf("Argument part-number-marker must be an integer between 0 and %d", object.MaxMultipartParts), uploadID, r)
 			return
 		}
 		partNumberMarker = parsed
@@ -455,10 +527,11 @@ func paginateMultipartParts(parts []object.Part, partNumberMarker, maxParts int)
 		}
 
 		filteredParts = append(filteredParts, Part{
-			PartNumber:   part.PartNumber,
-			LastModified: part.LastModified,
-			ETag:         part.ETag,
-			Size:         part.Size,
+			PartNumber:            part.PartNumber,
+			LastModified:          part.LastModified,
+			ETag:                  part.ETag,
+			Size:                  part.Size,
+			objectAttributesCksum: newObjectAttributesCksum(part.ChecksumAlgorithm, part.ChecksumValue),
 		})
 	}
 
@@ -510,6 +583,10 @@ func (h *Handler) CompleteMultipartUpload(w http.ResponseWriter, r *http.Request
 		h.writeError(w, "InvalidRequest", "You must specify at least one part", objectKey, r)
 		return
 	}
+	if len(completeRequest.Parts) > object.MaxMultipartParts {
+		h.writeError(w, "InvalidRequest", fmt.Sprintf("A multipart upload can have at most %d parts", object.MaxMultipartParts), objectKey, r)
+		return
+	}
 
 	// Convert to internal Part structure
 	parts := make([]object.Part, len(completeRequest.Parts))
@@ -518,30 +595,55 @@ func (h *Handler) CompleteMultipartUpload(w http.ResponseWriter, r *http.Request
 			PartNumber: part.PartNumber,
 			ETag:       part.ETag,
 		}
+		parts[i].ChecksumAlgorithm, parts[i].ChecksumValue = part.checksum()
 	}
 
 	// Resolve bucket path and capture x-amz-acl BEFORE launching the goroutine.
 	// The upload metadata (which stores the ACL header from CreateMultipartUpload)
 	// is deleted during CompleteMultipartUpload, so we must read it now.
 	bucketPath := h.getBucketPath(r, bucketName)
-	var storedCannedACL string
+	var storedCannedACL, storedSSE string
 	if h.metadataStore != nil {
 		if uploadMeta, metaErr := h.metadataStore.GetMultipartUpload(r.Context(), uploadID); metaErr == nil && uploadMeta != nil {
 			if uploadMeta.Metadata != nil {
 				storedCannedACL = uploadMeta.Metadata["x-amz-acl"]
+				storedSSE = uploadMeta.Metadata["x-amz-server-side-encryption"]
 			}
 		}
 	}
 
-	// AWS S3 behaviour for long-running completions: send 200 OK immediately, then
-	// stream whitespace to keep the TCP connection alive while the server combines
-	// the parts. The actual result XML (success or error) is flushed at the end.
-	// Without this, clients time out waiting for the status line on large objects.
-	w.Header().Set("Content-Type", "application/xml")
-	w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
-	w.WriteHeader(http.StatusOK)
-	if f, ok := w.(http.Flusher); ok {
-		f.Flush()
+	// A write-once bucket refuses completing onto an existing key. Checked
+	// here so the client still gets a real 412 instead of an error embedded
+	// in a 200 response; the object manager repeats the check under the key lock.
+	if bucketInfo, err := h.bucketManager.GetBucketInfo(r.Context(), h.resolveBucketTenantID(r, bucketName), bucketName); err == nil && bucketInfo.NoOverwrite {
+		if exists, err := h.objectManager.ObjectExists(r.Context(), bucketPath, objectKey); err == nil && exists {
+			h.writeError(w, "PreconditionFailed", "The bucket does not allow overwriting existing objects", objectKey, r)
+			return
+		}
+	}
+
+	// AWS S3 behaviour for long-running completions: once the combine outlasts
+	// the first keep-alive interval, send 200 OK and stream whitespace to keep
+	// the TCP connection alive while the server combines the parts. The actual
+	// result XML (success or error) is flushed at the end. Without this,
+	// clients time out waiting for the status line on large objects.
+	// Completions that finish within the interval get their headers with the
+	// result, so x-amz-version-id can be reported.
+	headersSent := false
+	sendHeaders := func(versionID string) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+		if storedSSE != "" {
+			w.Header().Set("x-amz-server-side-encryption", storedSSE)
+		}
+		if versionID != "" {
+			w.Header().Set("x-amz-version-id", versionID)
+		}
+		w.WriteHeader(http.StatusOK)
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		headersSent = true
 	}
 
 	// Run the heavy processing in the background.
@@ -570,6 +672,9 @@ func (h *Handler) CompleteMultipartUpload(w http.ResponseWriter, r *http.Request
 		case res = <-resultCh:
 			goto done
 		case <-ticker.C:
+			if !headersSent {
+				sendHeaders("")
+			}
 			w.Write([]byte(" ")) //nolint:errcheck
 			if f, ok := w.(http.Flusher); ok {
 				f.Flush()
@@ -583,17 +688,35 @@ func (h *Handler) CompleteMultipartUpload(w http.ResponseWriter, r *http.Request
 
 done:
 	if res.err != nil {
-		// We already committed 200 OK, so embed the error in the body.
-		// AWS S3 uses this same pattern; compliant clients parse the body.
+		var rejected *object.UploadRejectedError
 		code := "InternalError"
+		message := res.err.Error()
 		if res.err == object.ErrInvalidUploadID || res.err == object.ErrUploadNotFound {
+			// Also what a request racing a winning completion of the same
+			// upload ID gets.
 			code = "NoSuchUpload"
+			message = "The specified multipart upload does not exist"
 		} else if res.err == object.ErrInvalidPart {
 			code = "InvalidPart"
 		} else if res.err == object.ErrInvalidPartOrder {
 			code = "InvalidPartOrder"
-		} else if errors.Is(res.err, cluster.ErrClusterDegraded) {
+		} else if errors.Is(res.err, object.ErrPartTooSmall) {
+			code = "EntityTooSmall"
+		} else if errors.Is(res.err, object.ErrTooManyParts) {
+			code = "InvalidArgument"
+		} else if errors.Is(res.err, cluster.ErrClusterDegraded) || storage.IsInsufficientStorage(res.err) {
+			code = "ServiceUnavailable"
+		} else if _, ok := res.err.(*object.RetentionError); ok {
+			code = "AccessDenied"
+		} else if errors.Is(res.err, object.ErrReservedKeyPrefix) {
+			code = "AccessDenied"
+			message = "The object key is in a reserved system namespace"
+		} else if errors.As(res.err, &rejected) {
+			code = "AccessDenied"
+		} else if errors.Is(res.err, object.ErrUploadScannerUnavailable) {
 			code = "ServiceUnavailable"
+		} else if res.err == object.ErrObjectExists {
+			code = "PreconditionFailed"
 		} else if strings.Contains(res.err.Error(), "storage quota exceeded") || strings.Contains(res.err.Error(), "quota exceeded") {
 			code = "QuotaExceeded"
 		}
@@ -602,7 +725,13 @@ done:
 			"object":   objectKey,
 			"uploadId": uploadID,
 			"code":     code,
-		}).WithError(res.err).Error("CompleteMultipartUpload failed after 200 OK was sent")
+		}).WithError(res.err).Error("CompleteMultipartUpload failed")
+		if !headersSent {
+			h.writeError(w, code, message, objectKey, r)
+			return
+		}
+		// The 200 OK is already committed, so embed the error in the body.
+		// AWS S3 uses this same pattern; compliant clients parse the body.
 		w.Write([]byte(xml.Header)) //nolint:errcheck
 		errResp := struct {
 			XMLName  xml.Name `xml:"Error"`
@@ -611,7 +740,7 @@ done:
 			Resource string   `xml:"Resource"`
 		}{
 			Code:     code,
-			Message:  res.err.Error(),
+			Message:  message,
 			Resource: objectKey,
 		}
 		xml.NewEncoder(w).Encode(errResp) //nolint:errcheck
@@ -622,10 +751,13 @@ done:
 	}
 
 	// Return version ID if versioning is enabled
-	if res.obj.VersionID != "" {
+	versionID := h.writtenVersionID(r, bucketName, res.obj.VersionID)
+	if !headersSent {
+		sendHeaders(versionID)
+	} else if versionID != "" {
 		// Headers already sent; version ID can only be delivered in a trailer,
 		// which most S3 clients don't support. Log it and move on.
-		logrus.WithField("versionID", res.obj.VersionID).Debug("CompleteMultipartUpload: version ID set after early 200, cannot set header")
+		logrus.WithField("versionID", versionID).Debug("CompleteMultipartUpload: version ID set after early 200, cannot set header")
 	}
 
 	// Apply x-amz-acl originally set during CreateMultipartUpload (if any).
@@ -634,10 +766,11 @@ done:
 	}
 
 	result := CompleteMultipartUploadResult{
-		Location: h.buildLocationURL(r, bucketName, objectKey),
-		Bucket:   bucketName,
-		Key:      objectKey,
-		ETag:     res.obj.ETag,
+		Location:              h.buildLocationURL(r, bucketName, objectKey),
+		Bucket:                bucketName,
+		Key:                   objectKey,
+		ETag:                  res.obj.ETag,
+		objectAttributesCksum: newObjectAttributesCksum(res.obj.ChecksumAlgorithm, res.obj.ChecksumValue),
 	}
 	if err := xml.NewEncoder(w).Encode(result); err != nil {
 		logrus.WithError(err).Error("Failed to encode CompleteMultipartUpload response")
@@ -648,7 +781,7 @@ done:
 
 	// Fire s3:ObjectCreated:CompleteMultipartUpload notification asynchronously.
 	tenantID := h.getTenantIDFromRequest(r)
-	h.fireNotifications(bgCtx, bucketName, tenantID, objectKey, "s3:ObjectCreated:CompleteMultipartUpload", res.obj.ETag, res.obj.Size)
+	h.fireNotifications(bgCtx, bucketName, tenantID, objectKey, "s3:ObjectCreated:CompleteMultipartUpload", res.obj.ETag, res.obj.VersionID, res.obj.Size)
 }
 
 // AbortMultipartUpload aborts a multipart upload
@@ -746,11 +879,7 @@ func (h *Handler) UploadPartCopy(w http.ResponseWriter, r *http.Request, uploadI
 	}
 	if err != nil {
 		if err == object.ErrObjectNotFound {
-			if copySourceVersionID != "" {
-				h.writeError(w, "NoSuchVersion", "The specified version does not exist", sourceKey, r)
-				return
-			}
-			h.writeError(w, "NoSuchKey", "The specified source key does not exist", sourceKey, r)
+			h.writeCopySourceNotFound(w, r, sourceBucketPath, sourceKey, copySourceVersionID)
 			return
 		}
 		h.writeError(w, "InternalError", err.Error(), sourceKey, r)
@@ -761,6 +890,9 @@ func (h *Handler) UploadPartCopy(w http.ResponseWriter, r *http.Request, uploadI
 	if !h.validateObjectReadPermission(w, r, user, userExists, false, "", sourceTenantID, sourceBucketPath, sourceBucket, sourceKey) {
 		return
 	}
+	if !h.checkArchivedObjectReadable(w, r, sourceObj, sourceBucketPath, sourceKey, copySourceVersionID, sourceKey) {
+		return
+	}
 	if !h.validateCopySourceConditionals(w, r, sourceObj, sourceKey) {
 		return
 	}
@@ -818,6 +950,18 @@ func (h *Handler) UploadPartCopy(w http.ResponseWriter, r *http.Request, uploadI
 			h.writeError(w, "NoSuchUpload", "The specified multipart upload does not exist", uploadID, r)
 			return
 		}
+		if errors.Is(err, object.ErrBucketQuotaExceeded) {
+			h.writeError(w, "QuotaExceeded", err.Error(), uploadID, r)
+			return
+		}
+		if errors.Is(err, object.ErrTooManyParts) || errors.Is(err, object.ErrInvalidPartNumber) {
+			h.writeError(w, "InvalidArgument", err.Error(), uploadID, r)
+			return
+		}
+		if storage.IsInsufficientStorage(err) {
+			h.writeInsufficientStorage(w, r, uploadID)
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), uploadID, r)
 		return
 	}
@@ -833,6 +977,9 @@ func (h *Handler) UploadPartCopy(w http.ResponseWriter, r *http.Request, uploadI
 		LastModified: time.Now(),
 		ETag:         part.ETag,
 	}
+	if part.SSEAlgorithm != "" {
+		w.Header().Set("x-amz-server-side-encryption", part.SSEAlgorithm)
+	}
 
 	h.writeXMLResponse(w, http.StatusOK, result)
 }
@@ -0,0 +1,172 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func crc32cBase64(data []byte) string {
+	sum := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum, crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
+// startChecksumUpload creates a CRC32C multipart upload and uploads parts,
+// sending each part's checksum header. It returns the upload ID and the part
+// checksums reported by UploadPart.
+func startChecksumUpload(t *testing.T, env *s3TestEnv, bucketName, objectKey string, parts [][]byte) (string, []string) {
+	t.Helper()
+	req, w := env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?uploads", nil)
+	req.Header.Set("x-amz-checksum-algorithm", "CRC32C")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "CRC32C", w.Header().Get("x-amz-checksum-algorithm"))
+	var initiated InitiateMultipartUploadResult
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+
+	var checksums []string
+	for i, body := range parts {
+		req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/%s?partNumber=%d&uploadId=%s", bucketName, objectKey, i+1, initiated.UploadId), body)
+		req.Header.Set("x-amz-sdk-checksum-algorithm", "CRC32C")
+		req.Header.Set("x-amz-checksum-crc32c", crc32cBase64(body))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		checksums = append(checksums, w.Header().Get("x-amz-checksum-crc32c"))
+	}
+	return initiated.UploadId, checksums
+}
+
+func TestMultipartUpload_CompositeChecksum(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "checksums"
+	objectKey := "disk.img"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	parts := [][]byte{bytes.Repeat([]byte("a"), 6<<20), bytes.Repeat([]byte("b"), 6<<20), []byte("tail")}
+	uploadID, checksums := startChecksumUpload(t, env, bucketName, objectKey, parts)
+	for i, body := range parts {
+		assert.Equal(t, crc32cBase64(body), checksums[i], "UploadPart returns the part checksum")
+	}
+
+	// A part whose data doesn't match its checksum is rejected and not stored
+	req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/%s?partNumber=4&uploadId=%s", bucketName, objectKey, uploadID), []byte("corrupted"))
+	req.Header.Set("x-amz-checksum-crc32c", crc32cBase64([]byte("original")))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>BadDigest</Code>")
+
+	// A part checksum of another algorithm than the upload's is refused
+	req, w = env.makeS3Request("PUT", fmt.Sprintf("/%s/%s?partNumber=4&uploadId=%s", bucketName, objectKey, uploadID), []byte("other"))
+	req.Header.Set("x-amz-sdk-checksum-algorithm", "SHA256")
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>InvalidRequest</Code>")
+
+	// ListParts reports the stored checksums
+	req, w = env.makeS3Request("GET", fmt.Sprintf("/%s/%s?uploadId=%s", bucketName, objectKey, uploadID), nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed ListPartsResult
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Parts, 3)
+	for i, part := range listed.Parts {
+		assert.Equal(t, checksums[i], part.ChecksumCRC32C)
+	}
+
+	// The composite is the CRC32C of the concatenated binary part CRCs
+	var concatenated []byte
+	for _, c := range checksums {
+		raw, err := base64.StdEncoding.DecodeString(c)
+		require.NoError(t, err)
+		concatenated = append(concatenated, raw...)
+	}
+	want := crc32cBase64(concatenated) + "-3"
+
+	var completeXML bytes.Buffer
+	completeXML.WriteString("<CompleteMultipartUpload>")
+	for i, part := range listed.Parts {
+		fmt.Fprintf(&completeXML, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag><ChecksumCRC32C>%s</ChecksumCRC32C></Part>",
+			i+1, part.ETag, checksums[i])
+	}
+	completeXML.WriteString("</CompleteMultipartUpload>")
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?uploadId="+uploadID, completeXML.Bytes())
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "<Error>", w.Body.String())
+	var completed CompleteMultipartUploadResult
+	require.NoError(t, xml.Unmarshal(bytes.TrimSpace(w.Body.Bytes()), &completed))
+	assert.Equal(t, want, completed.ChecksumCRC32C)
+
+	for _, method := range []string{"HEAD", "GET"} {
+		req, w := env.makeS3Request(method, "/"+bucketName+"/"+objectKey, nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, method)
+		assert.Equal(t, want, w.Header().Get("x-amz-checksum-crc32c"), method)
+	}
+
+	req = httptest.NewRequest("GET", "/"+bucketName+"/"+objectKey+"?attributes", nil)
+	req = mux.SetURLVars(req, map[string]string{"bucket": bucketName, "object": objectKey})
+	req = req.WithContext(context.WithValue(req.Context(), "user", &auth.User{
+		ID:       env.userID,
+		TenantID: env.tenantID,
+		Roles:    []string{"admin"},
+	}))
+	req.Header.Set("x-amz-object-attributes", "Checksum,ObjectParts")
+	w = httptest.NewRecorder()
+	env.handler.GetObjectAttributes(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var attrs getObjectAttributesResponse
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &attrs))
+	require.NotNil(t, attrs.Checksum)
+	assert.Equal(t, want, attrs.Checksum.ChecksumCRC32C)
+	require.NotNil(t, attrs.ObjectParts)
+	assert.Equal(t, 3, attrs.ObjectParts.TotalPartsCount)
+}
+
+func TestMultipartUpload_CompleteRejectsMismatchedPartChecksum(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "checksums"
+	objectKey := "disk.img"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	parts := [][]byte{bytes.Repeat([]byte("a"), 6<<20), []byte("tail")}
+	uploadID, checksums := startChecksumUpload(t, env, bucketName, objectKey, parts)
+
+	completeXML := fmt.Sprintf("<CompleteMultipartUpload>"+
+		"<Part><PartNumber>1</PartNumber><ChecksumCRC32C>%s</ChecksumCRC32C></Part>"+
+		"<Part><PartNumber>2</PartNumber><ChecksumCRC32C>%s</ChecksumCRC32C></Part>"+
+		"</CompleteMultipartUpload>", checksums[0], crc32cBase64([]byte("something else")))
+	req, w := env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?uploadId="+uploadID, []byte(completeXML))
+	env.router.ServeHTTP(w, req)
+	assert.Contains(t, w.Body.String(), "<Code>InvalidPart</Code>")
+
+	// Nothing was written, and the upload can still be completed correctly
+	req, w = env.makeS3Request("HEAD", "/"+bucketName+"/"+objectKey, nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	completeXML = fmt.Sprintf("<CompleteMultipartUpload>"+
+		"<Part><PartNumber>1</PartNumber><ChecksumCRC32C>%s</ChecksumCRC32C></Part>"+
+		"<Part><PartNumber>2</PartNumber><ChecksumCRC32C>%s</ChecksumCRC32C></Part>"+
+		"</CompleteMultipartUpload>", checksums[0], checksums[1])
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?uploadId="+uploadID, []byte(completeXML))
+	env.router.ServeHTTP(w, req)
+	require.NotContains(t, w.Body.String(), "<Error>", w.Body.String())
+}
@@ -0,0 +1,73 @@
+package s3compat
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Two clients completing the same upload ID at once: one wins, the other
+// gets NoSuchUpload, and the object holds the completed upload.
+func TestCompleteMultipartUpload_SameUploadTwice(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "complete-twice"
+	objectKey := "report.bin"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+objectKey, []byte("previous version"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?uploads", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var initiated InitiateMultipartUploadResult
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+
+	req, w = env.makeS3Request("PUT", fmt.Sprintf("/%s/%s?partNumber=1&uploadId=%s", bucketName, objectKey, initiated.UploadId), []byte("completed upload"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	completeXML := fmt.Sprintf(`<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>%s</ETag></Part></CompleteMultipartUpload>`, w.Header().Get("ETag"))
+
+	recorders := make([]*httptest.ResponseRecorder, 2)
+	var wg sync.WaitGroup
+	for i := range recorders {
+		req, w := env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?uploadId="+initiated.UploadId, []byte(completeXML))
+		recorders[i] = w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			env.router.ServeHTTP(w, req)
+		}()
+	}
+	wg.Wait()
+
+	var succeeded, noSuchUpload int
+	for _, w := range recorders {
+		switch {
+		case w.Code == http.StatusOK && strings.Contains(w.Body.String(), "<CompleteMultipartUploadResult"):
+			succeeded++
+		case w.Code == http.StatusNotFound && strings.Contains(w.Body.String(), "<Code>NoSuchUpload</Code>"):
+			noSuchUpload++
+		default:
+			t.Errorf("unexpected response %d: %s", w.Code, w.Body.String())
+		}
+	}
+	assert.Equal(t, 1, succeeded, "exactly one completion must win")
+	assert.Equal(t, 1, noSuchUpload, "the other completion must get NoSuchUpload")
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/"+objectKey, nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "completed upload", w.Body.String())
+}
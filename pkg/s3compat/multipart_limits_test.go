@@ -0,0 +1,61 @@
+package s3compat
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartLimits(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "multipart-limits"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("POST", "/"+bucketName+"/big.bin?uploads", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var initiated InitiateMultipartUploadResult
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+	uploadID := initiated.UploadId
+
+	for _, partNumber := range []string{"0", "10001", "-3", "abc"} {
+		t.Run("part number "+partNumber, func(t *testing.T) {
+			req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/big.bin?partNumber=%s&uploadId=%s", bucketName, partNumber, uploadID), []byte("data"))
+			env.router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "InvalidArgument")
+		})
+	}
+
+	t.Run("part number 10000 accepted", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/big.bin?partNumber=10000&uploadId=%s", bucketName, uploadID), []byte("data"))
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("part-number-marker beyond the part range", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", fmt.Sprintf("/%s/big.bin?uploadId=%s&part-number-marker=10001", bucketName, uploadID), nil)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("complete with more than 10000 parts", func(t *testing.T) {
+		var body []byte
+		body = append(body, "<CompleteMultipartUpload>"...)
+		for i := 1; i <= 10001; i++ {
+			body = append(body, fmt.Sprintf("<Part><PartNumber>%d</PartNumber><ETag>x</ETag></Part>", i)...)
+		}
+		body = append(body, "</CompleteMultipartUpload>"...)
+		req, w := env.makeS3Request("POST", fmt.Sprintf("/%s/big.bin?uploadId=%s", bucketName, uploadID), body)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "InvalidRequest")
+	})
+}
@@ -1,10 +1,18 @@
 package s3compat
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/maxiofs/maxiofs/internal/object"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPaginateMultipartUploads_DoesNotTruncateExactPage(t *testing.T) {
@@ -76,3 +84,62 @@ func TestPaginateMultipartParts_DoesNotTruncateExactPage(t *testing.T) {
 	assert.False(t, truncated)
 	assert.Zero(t, nextMarker)
 }
+
+func TestListParts_PagesWithSizeAndLastModified(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "resumable"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("POST", "/"+bucketName+"/backup.tar?uploads", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var initiated InitiateMultipartUploadResult
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+
+	// Upload out of order, each part a different size
+	etags := map[int]string{}
+	for _, n := range []int{4, 1, 5, 3, 2} {
+		body := bytes.Repeat([]byte{byte('a' + n)}, n*100)
+		req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/backup.tar?partNumber=%d&uploadId=%s", bucketName, n, initiated.UploadId), body)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		etags[n] = w.Header().Get("ETag")
+	}
+
+	var listed []Part
+	marker := ""
+	for page := 0; ; page++ {
+		require.Less(t, page, 3, "5 parts at 2 per page take 3 pages")
+		url := fmt.Sprintf("/%s/backup.tar?uploadId=%s&max-parts=2", bucketName, initiated.UploadId)
+		if marker != "" {
+			url += "&part-number-marker=" + marker
+		}
+		req, w := env.makeS3Request("GET", url, nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var result ListPartsResult
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, 2, result.MaxParts)
+		listed = append(listed, result.Parts...)
+		if !result.IsTruncated {
+			assert.Len(t, result.Parts, 1)
+			assert.Zero(t, result.NextPartNumberMarker)
+			break
+		}
+		assert.Len(t, result.Parts, 2)
+		assert.Equal(t, result.Parts[1].PartNumber, result.NextPartNumberMarker)
+		marker = strconv.Itoa(result.NextPartNumberMarker)
+	}
+
+	require.Len(t, listed, 5)
+	for i, part := range listed {
+		assert.Equal(t, i+1, part.PartNumber, "parts are listed in ascending order")
+		assert.Equal(t, int64(part.PartNumber*100), part.Size)
+		assert.Equal(t, etags[part.PartNumber], part.ETag)
+		assert.False(t, part.LastModified.IsZero())
+		assert.WithinDuration(t, time.Now(), part.LastModified, time.Minute)
+	}
+}
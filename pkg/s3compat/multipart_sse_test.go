@@ -0,0 +1,58 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipartUpload_EncryptedObjectReportsSSE(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "sse-multipart"
+	objectKey := "large.bin"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?uploads", nil)
+	req.Header.Set("x-amz-server-side-encryption", "AES256")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "AES256", w.Header().Get("x-amz-server-side-encryption"))
+	var initiated InitiateMultipartUploadResult
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+
+	var completeXML bytes.Buffer
+	completeXML.WriteString("<CompleteMultipartUpload>")
+	for i, body := range [][]byte{bytes.Repeat([]byte("a"), 6<<20), []byte("tail")} {
+		req, w := env.makeS3Request("PUT", fmt.Sprintf("/%s/%s?partNumber=%d&uploadId=%s", bucketName, objectKey, i+1, initiated.UploadId), body)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "AES256", w.Header().Get("x-amz-server-side-encryption"), "UploadPart response")
+		fmt.Fprintf(&completeXML, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag></Part>", i+1, w.Header().Get("ETag"))
+	}
+	completeXML.WriteString("</CompleteMultipartUpload>")
+
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/"+objectKey+"?uploadId="+initiated.UploadId, completeXML.Bytes())
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "<Error>")
+	assert.Equal(t, "AES256", w.Header().Get("x-amz-server-side-encryption"), "CompleteMultipartUpload response")
+
+	for _, method := range []string{"HEAD", "GET"} {
+		req, w := env.makeS3Request(method, "/"+bucketName+"/"+objectKey, nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, method)
+		assert.Equal(t, "AES256", w.Header().Get("x-amz-server-side-encryption"), method)
+		assert.Regexp(t, `^"?[0-9a-f]{32}-2"?$`, w.Header().Get("ETag"), method)
+		if method == "GET" {
+			assert.Equal(t, 6<<20+4, w.Body.Len())
+		}
+	}
+}
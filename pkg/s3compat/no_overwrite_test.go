@@ -0,0 +1,70 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoOverwriteBucket_S3Responses(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	bucketName := "write-once"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	require.NoError(t, env.bucketManager.SetNoOverwrite(ctx, env.tenantID, bucketName, true))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/event.json", []byte(`{"n":1}`))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	t.Run("second PUT", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/event.json", []byte(`{"n":2}`))
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Contains(t, w.Body.String(), "PreconditionFailed")
+	})
+
+	t.Run("PUT to a new key", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/event-2.json", []byte(`{"n":2}`))
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("copy onto an existing key", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/event.json", nil)
+		req.Header.Set("x-amz-copy-source", "/"+bucketName+"/event-2.json")
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("multipart completion onto an existing key", func(t *testing.T) {
+		req, w := env.makeS3Request("POST", "/"+bucketName+"/event.json?uploads", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var initiated InitiateMultipartUploadResult
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+
+		req, w = env.makeS3Request("PUT", fmt.Sprintf("/%s/event.json?partNumber=1&uploadId=%s", bucketName, initiated.UploadId), []byte("part"))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var completeXML bytes.Buffer
+		fmt.Fprintf(&completeXML, "<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>%s</ETag></Part></CompleteMultipartUpload>", w.Header().Get("ETag"))
+		req, w = env.makeS3Request("POST", "/"+bucketName+"/event.json?uploadId="+initiated.UploadId, completeXML.Bytes())
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/event.json", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"n":1}`, w.Body.String())
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/eventlog"
 	"github.com/sirupsen/logrus"
 )
 
@@ -141,9 +142,21 @@ type s3EventPayload struct {
 	Records []s3EventRecord `json:"Records"`
 }
 
-// fireNotifications looks up the bucket's notification config and dispatches matching
-// events to configured webhook endpoints. The dispatch is non-blocking (goroutine).
-func (h *Handler) fireNotifications(ctx context.Context, bucketName, tenantID, objectKey, eventName, etag string, size int64) {
+// fireNotifications records the event in the bucket's event log, then looks up the
+// bucket's notification config and dispatches matching events to configured webhook
+// endpoints. The dispatch is non-blocking (goroutine).
+func (h *Handler) fireNotifications(ctx context.Context, bucketName, tenantID, objectKey, eventName, etag, versionID string, size int64) {
+	if h.eventLog != nil {
+		bucketPath := bucketName
+		if tenantID != "" {
+			bucketPath = tenantID + "/" + bucketName
+		}
+		ev := eventlog.Event{EventName: eventName, Key: objectKey, VersionID: versionID, Size: size, ETag: etag}
+		if err := h.eventLog.Append(ctx, bucketPath, ev); err != nil {
+			logrus.WithError(err).WithField("bucket", bucketPath).Warn("notifications: failed to record event")
+		}
+	}
+
 	cfg, err := h.bucketManager.GetNotification(ctx, tenantID, bucketName)
 	if err != nil || cfg == nil {
 		return
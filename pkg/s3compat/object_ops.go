@@ -2,6 +2,7 @@ package s3compat
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"github.com/maxiofs/maxiofs/internal/acl"
 	"github.com/maxiofs/maxiofs/internal/auth"
 	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -256,6 +258,38 @@ type objectAttributesCksum struct {
 	ChecksumSHA256 string `xml:"ChecksumSHA256,omitempty"`
 }
 
+// newObjectAttributesCksum puts a checksum into the element of its algorithm.
+// An unknown algorithm leaves every element empty.
+func newObjectAttributesCksum(algo, value string) objectAttributesCksum {
+	var ck objectAttributesCksum
+	switch strings.ToUpper(algo) {
+	case "CRC32":
+		ck.ChecksumCRC32 = value
+	case "CRC32C":
+		ck.ChecksumCRC32C = value
+	case "SHA1":
+		ck.ChecksumSHA1 = value
+	case "SHA256":
+		ck.ChecksumSHA256 = value
+	}
+	return ck
+}
+
+// checksum returns the algorithm and value of the first element set.
+func (ck objectAttributesCksum) checksum() (algo, value string) {
+	switch {
+	case ck.ChecksumCRC32 != "":
+		return "CRC32", ck.ChecksumCRC32
+	case ck.ChecksumCRC32C != "":
+		return "CRC32C", ck.ChecksumCRC32C
+	case ck.ChecksumSHA1 != "":
+		return "SHA1", ck.ChecksumSHA1
+	case ck.ChecksumSHA256 != "":
+		return "SHA256", ck.ChecksumSHA256
+	}
+	return "", ""
+}
+
 type objectAttributesParts struct {
 	TotalPartsCount int `xml:"TotalPartsCount"`
 }
@@ -317,18 +351,8 @@ func (h *Handler) GetObjectAttributes(w http.ResponseWriter, r *http.Request) {
 		resp.ObjectSize = &size
 	}
 	if requested["Checksum"] && obj.ChecksumAlgorithm != "" && obj.ChecksumValue != "" {
-		ck := &objectAttributesCksum{}
-		switch strings.ToUpper(obj.ChecksumAlgorithm) {
-		case "CRC32":
-			ck.ChecksumCRC32 = obj.ChecksumValue
-		case "CRC32C":
-			ck.ChecksumCRC32C = obj.ChecksumValue
-		case "SHA1":
-			ck.ChecksumSHA1 = obj.ChecksumValue
-		case "SHA256":
-			ck.ChecksumSHA256 = obj.ChecksumValue
-		}
-		resp.Checksum = ck
+		ck := newObjectAttributesCksum(obj.ChecksumAlgorithm, obj.ChecksumValue)
+		resp.Checksum = &ck
 	}
 	if requested["ObjectParts"] {
 		// Infer part count from multipart ETag format: <md5>-<N>
@@ -669,6 +693,11 @@ func (h *Handler) PutObjectACL(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	tenantID, bucketOnly := bucketPathTenant(bucketPath)
+	if h.rejectPublicACL(w, r, tenantID, bucketOnly, objectKey, requestSetsPublicACL(r) || objectGrantsPublicAccess(aclData.Grants)) {
+		return
+	}
+
 	// Set ACL using object manager
 	if err := h.objectManager.SetObjectACL(r.Context(), bucketPath, objectKey, aclData, versionID); err != nil {
 		if err == object.ErrObjectNotFound {
@@ -742,39 +771,36 @@ func (h *Handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, "AccessDenied", "Access Denied", destKey, r)
 		return
 	}
+	if h.rejectPublicACL(w, r, destTenantID, destBucket, destKey, requestSetsPublicACL(r)) {
+		return
+	}
 
 	sourceBucketPath := h.getBucketPath(r, sourceBucket)
-	// Get source object, requesting a specific version if indicated in the copy source.
+	// Get source object, requesting a specific version if indicated in the copy
+	// source: the destination then gets that version's bytes, not the latest.
 	var sourceObj *object.Object
 	var reader io.ReadCloser
 	if copySourceVersionID != "" {
-		var getErr error
-		sourceObj, reader, getErr = h.objectManager.GetObject(r.Context(), sourceBucketPath, sourceKey, copySourceVersionID)
-		if getErr != nil {
-			if getErr == object.ErrObjectNotFound {
-				h.writeError(w, "NoSuchKey", "The specified source key does not exist", sourceKey, r)
-				return
-			}
-			h.writeError(w, "InternalError", getErr.Error(), sourceKey, r)
-			return
-		}
+		sourceObj, reader, err = h.objectManager.GetObject(r.Context(), sourceBucketPath, sourceKey, copySourceVersionID)
 	} else {
-		var getErr error
-		sourceObj, reader, getErr = h.objectManager.GetObject(r.Context(), sourceBucketPath, sourceKey)
-		if getErr != nil {
-			if getErr == object.ErrObjectNotFound {
-				h.writeError(w, "NoSuchKey", "The specified source key does not exist", sourceKey, r)
-				return
-			}
-			h.writeError(w, "InternalError", getErr.Error(), sourceKey, r)
+		sourceObj, reader, err = h.objectManager.GetObject(r.Context(), sourceBucketPath, sourceKey)
+	}
+	if err != nil {
+		if err == object.ErrObjectNotFound {
+			h.writeCopySourceNotFound(w, r, sourceBucketPath, sourceKey, copySourceVersionID)
 			return
 		}
+		h.writeError(w, "InternalError", err.Error(), sourceKey, r)
+		return
 	}
 	defer reader.Close()
 
 	if !h.validateObjectReadPermission(w, r, user, userExists, false, "", sourceTenantID, sourceBucketPath, sourceBucket, sourceKey) {
 		return
 	}
+	if !h.checkArchivedObjectReadable(w, r, sourceObj, sourceBucketPath, sourceKey, copySourceVersionID, sourceKey) {
+		return
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"source_bucket": sourceBucket,
@@ -850,9 +876,12 @@ func (h *Handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 			headers.Set("Content-Language", sourceObj.ContentLanguage)
 		}
 		// Propagate user-defined metadata
-		for k, v := range sourceObj.Metadata {
-			headers.Set("X-Amz-Meta-"+k, v)
-		}
+		object.SetUserMetadataHeaders(headers, sourceObj.Metadata)
+	}
+
+	// The destination gets the requested storage class, STANDARD otherwise
+	if sc := r.Header.Get("x-amz-storage-class"); sc != "" {
+		headers.Set("x-amz-storage-class", sc)
 	}
 
 	destBucketPath := h.getBucketPath(r, destBucket)
@@ -865,6 +894,25 @@ func (h *Handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 			h.writeError(w, "NoSuchBucket", "The destination bucket does not exist", destBucket, r)
 			return
 		}
+		if err == object.ErrObjectExists {
+			h.writeError(w, "PreconditionFailed", "The bucket does not allow overwriting existing objects", destKey, r)
+			return
+		}
+		if storage.IsInsufficientStorage(err) {
+			h.writeInsufficientStorage(w, r, destKey)
+			return
+		}
+		if errors.Is(err, object.ErrInvalidStorageClass) {
+			h.writeError(w, "InvalidStorageClass", "The storage class you specified is not valid", destKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrReservedKeyPrefix) {
+			h.writeError(w, "AccessDenied", "The object key is in a reserved system namespace", destKey, r)
+			return
+		}
+		if h.writeUploadScanError(w, r, err, destKey) {
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), destKey, r)
 		return
 	}
@@ -893,8 +941,8 @@ func (h *Handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 
 	// Set version ID response headers before writing XML body
 	// x-amz-version-id: version ID of the newly created destination object
-	if destObj.VersionID != "" {
-		w.Header().Set("x-amz-version-id", destObj.VersionID)
+	if versionID := h.writtenVersionID(r, destBucket, destObj.VersionID); versionID != "" {
+		w.Header().Set("x-amz-version-id", versionID)
 	}
 	// x-amz-copy-source-version-id: version ID of the source object that was copied
 	if sourceObj.VersionID != "" {
@@ -916,7 +964,22 @@ func (h *Handler) CopyObject(w http.ResponseWriter, r *http.Request) {
 	h.writeXMLResponse(w, http.StatusOK, result)
 
 	// Fire s3:ObjectCreated:Copy notification asynchronously.
-	h.fireNotifications(r.Context(), destBucket, destTenantID, destKey, "s3:ObjectCreated:Copy", destObj.ETag, destObj.Size)
+	h.fireNotifications(r.Context(), destBucket, destTenantID, destKey, "s3:ObjectCreated:Copy", destObj.ETag, destObj.VersionID, destObj.Size)
+}
+
+// writeCopySourceNotFound answers a copy whose source could not be read. A
+// source naming a version gets NoSuchVersion, or InvalidRequest when that
+// version is a delete marker, as S3 refuses to copy one.
+func (h *Handler) writeCopySourceNotFound(w http.ResponseWriter, r *http.Request, sourceBucketPath, sourceKey, versionID string) {
+	if versionID == "" {
+		h.writeError(w, "NoSuchKey", "The specified source key does not exist", sourceKey, r)
+		return
+	}
+	if version, found := h.findExactObjectVersion(r.Context(), sourceBucketPath, sourceKey, versionID); found && isS3DeleteMarkerVersion(version) {
+		h.writeError(w, "InvalidRequest", "The source of a copy request may not specifically refer to a delete marker by version id", sourceKey, r)
+		return
+	}
+	h.writeError(w, "NoSuchVersion", "The specified version does not exist", sourceKey, r)
 }
 
 func parseCopySourceHeader(copySource string) (bucketName, objectKey, versionID string, err error) {
@@ -360,3 +360,81 @@ func TestPresignedPost_Redirect(t *testing.T) {
 	assert.Contains(t, loc, "key="+objectKey, "Redirect should contain key")
 	assert.Contains(t, loc, "etag=", "Redirect should contain etag")
 }
+
+// TestPresignedPost_MetadataAndTaggingAllowedByPolicy tests that metadata and
+// tags named by the policy are stored with the object.
+func TestPresignedPost_MetadataAndTaggingAllowedByPolicy(t *testing.T) {
+	env := setupPresignedPostEnv(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "post-presigned-meta"
+	objectKey := "uploads/report.pdf"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+
+	tagging := "<Tagging><TagSet><Tag><Key>source</Key><Value>browser</Value></Tag></TagSet></Tagging>"
+	conditions := []interface{}{
+		[]interface{}{"starts-with", "$x-amz-meta-department", "finance-"},
+		[]interface{}{"eq", "$x-amz-meta-uploader", "alice"},
+		map[string]string{"tagging": tagging},
+	}
+	req := buildPostPresignedRequest(t, bucketName, objectKey, []byte("pdf bytes"), "", env.accessKey, env.secretKey, conditions,
+		map[string]string{
+			"x-amz-meta-department": "finance-emea",
+			"X-Amz-Meta-Uploader":   "alice",
+			"tagging":               tagging,
+		})
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	bucketPath := env.tenantID + "/" + bucketName
+	obj, err := env.objectManager.GetObjectMetadata(ctx, bucketPath, objectKey)
+	require.NoError(t, err)
+	assert.Equal(t, "finance-emea", obj.Metadata["department"])
+	assert.Equal(t, "alice", obj.Metadata["uploader"])
+	tags, err := env.objectManager.GetObjectTagging(ctx, bucketPath, objectKey)
+	require.NoError(t, err)
+	require.Len(t, tags.Tags, 1)
+	assert.Equal(t, "source", tags.Tags[0].Key)
+	assert.Equal(t, "browser", tags.Tags[0].Value)
+}
+
+// TestPresignedPost_MetadataViolatesPolicy tests that metadata outside the
+// policy's conditions is rejected with 403 and nothing is stored.
+func TestPresignedPost_MetadataViolatesPolicy(t *testing.T) {
+	env := setupPresignedPostEnv(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "post-presigned-meta-deny"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	bucketPath := env.tenantID + "/" + bucketName
+
+	conditions := []interface{}{
+		[]interface{}{"starts-with", "$x-amz-meta-department", "finance-"},
+	}
+	cases := map[string]map[string]string{
+		"starts-with violated": {"x-amz-meta-department": "marketing"},
+		"field not in policy": {
+			"x-amz-meta-department": "finance-emea",
+			"x-amz-meta-owner":      "mallory",
+		},
+		"tagging not in policy": {
+			"x-amz-meta-department": "finance-emea",
+			"tagging":               "<Tagging><TagSet><Tag><Key>a</Key><Value>b</Value></Tag></TagSet></Tagging>",
+		},
+	}
+	for name, fields := range cases {
+		t.Run(name, func(t *testing.T) {
+			objectKey := strings.ReplaceAll(name, " ", "-") + ".txt"
+			req := buildPostPresignedRequest(t, bucketName, objectKey, []byte("data"), "", env.accessKey, env.secretKey, conditions, fields)
+			w := httptest.NewRecorder()
+			env.router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			_, err := env.objectManager.GetObjectMetadata(ctx, bucketPath, objectKey)
+			assert.Error(t, err, "rejected upload must not be stored")
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -18,6 +20,9 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/maxiofs/maxiofs/internal/presigned"
+	"github.com/maxiofs/maxiofs/internal/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -30,6 +35,7 @@ type PresignedURLConfig struct {
 	Method     string
 	Expiration time.Duration
 	Headers    map[string]string
+	Region     string // Bucket region; empty uses the handler's default region
 }
 
 // GeneratePresignedURL generates a presigned URL for S3 operations
@@ -62,8 +68,17 @@ func (h *Handler) generatePresignedURLV4(config PresignedURLConfig) (string, err
 	amzDate := now.Format("20060102T150405Z")
 
 	// Construct the canonical request
-	region := "us-east-1"
-	service := "s3"
+	region := config.Region
+	if region == "" {
+		region = h.defaultRegion
+	}
+	if region == "" {
+		region = presigned.DefaultRegion
+	}
+	service := h.signingService
+	if service == "" {
+		service = presigned.DefaultService
+	}
 
 	// Build URL path
 	path := s3URLEncode(fmt.Sprintf("/%s/%s", config.BucketName, config.ObjectKey))
@@ -240,8 +255,9 @@ func (h *Handler) validatePresignedURLV4(r *http.Request) error {
 	if err != nil {
 		return fmt.Errorf("invalid expires parameter: %v", err)
 	}
-	if expiresIn <= 0 || expiresIn > int64((7*24*time.Hour).Seconds()) {
-		return &presignedValidationError{"InvalidArgument", "X-Amz-Expires must be between 1 and 604800 seconds."}
+	if !h.presignedExpiryAllowed(expiresIn) {
+		return &presignedValidationError{"InvalidArgument", fmt.Sprintf("X-Amz-Expires must be between 1 and %d seconds.",
+			presigned.EffectiveMaxExpiration(h.presignedMaxAge))}
 	}
 
 	// Parse date
@@ -250,9 +266,15 @@ func (h *Handler) validatePresignedURLV4(r *http.Request) error {
 		return fmt.Errorf("invalid date format: %v", err)
 	}
 
-	// Check if URL has expired
+	// A URL signed by a fast clock is accepted up to the clock skew window
+	// ahead of now; expiry itself is exact, X-Amz-Date + X-Amz-Expires, so
+	// the skew never extends a URL's lifetime.
+	now := time.Now().UTC()
+	if requestTime.After(now.Add(h.clockSkew)) {
+		return &presignedValidationError{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large."}
+	}
 	expirationTime := requestTime.Add(time.Duration(expiresIn) * time.Second)
-	if time.Now().UTC().After(expirationTime) {
+	if now.After(expirationTime) {
 		return &presignedValidationError{"RequestExpired", "Request has expired."}
 	}
 
@@ -635,6 +657,16 @@ func (h *Handler) HandlePresignedPost(w http.ResponseWriter, r *http.Request) {
 		}
 		return ""
 	}
+	// Metadata and tagging field names are matched case-insensitively, as
+	// their policy conditions are.
+	governedField := func(name string) string {
+		for k, vs := range form.Value {
+			if strings.EqualFold(k, name) && len(vs) > 0 {
+				return vs[0]
+			}
+		}
+		return ""
+	}
 
 	// Determine signature version and extract fields.
 	isV4 := field("x-amz-algorithm") == "AWS4-HMAC-SHA256"
@@ -729,6 +761,8 @@ func (h *Handler) HandlePresignedPost(w http.ResponseWriter, r *http.Request) {
 	objectKey := field("key")
 	contentType := field("Content-Type")
 	var minLen, maxLen int64 = 0, 1<<63 - 1
+	// Metadata and tagging fields the policy has a condition for
+	allowed := make(map[string]bool)
 
 	for _, raw := range policy.Conditions {
 		// Try object form first: {"field": "value"}
@@ -752,7 +786,15 @@ func (h *Handler) HandlePresignedPost(w http.ResponseWriter, r *http.Request) {
 						h.writeError(w, "AccessDenied", "Content-Type condition does not match", bucketName, r)
 						return
 					}
-					// x-amz-* fields are informational — already validated via signature.
+				default:
+					// Other x-amz-* fields are informational — already validated via signature.
+					if isPostPolicyGovernedField(k) {
+						allowed[strings.ToLower(k)] = true
+						if val != governedField(k) {
+							h.writeError(w, "AccessDenied", fmt.Sprintf("Field %s does not match the policy", k), bucketName, r)
+							return
+						}
+					}
 				}
 			}
 			continue
@@ -765,24 +807,38 @@ func (h *Handler) HandlePresignedPost(w http.ResponseWriter, r *http.Request) {
 		}
 		op, _ := arrCond[0].(string)
 		switch strings.ToLower(op) {
-		case "starts-with":
+		case "starts-with", "eq":
 			if len(arrCond) < 3 {
 				continue
 			}
 			fname, _ := arrCond[1].(string)
-			prefix, _ := arrCond[2].(string)
+			want, _ := arrCond[2].(string)
 			fname = strings.TrimPrefix(fname, "$")
 			var formVal string
 			switch strings.ToLower(fname) {
+			case "bucket":
+				formVal = bucketName
 			case "key":
 				formVal = objectKey
 			case "content-type":
 				formVal = contentType
 			default:
-				formVal = field(fname)
+				if isPostPolicyGovernedField(fname) {
+					allowed[strings.ToLower(fname)] = true
+					formVal = governedField(fname)
+				} else {
+					formVal = field(fname)
+				}
+			}
+			if strings.EqualFold(op, "eq") {
+				if formVal != want {
+					h.writeError(w, "AccessDenied", fmt.Sprintf("Field %s does not match the policy", fname), bucketName, r)
+					return
+				}
+				continue
 			}
 			// Empty prefix means any value is allowed.
-			if prefix != "" && !strings.HasPrefix(formVal, prefix) {
+			if want != "" && !strings.HasPrefix(formVal, want) {
 				h.writeError(w, "AccessDenied", fmt.Sprintf("Field %s does not start with required prefix", fname), bucketName, r)
 				return
 			}
@@ -795,6 +851,20 @@ func (h *Handler) HandlePresignedPost(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Metadata and tags are only stored when the policy names them, so the
+	// signer decides what a browser may attach (AWS rejects extra fields too).
+	for k := range form.Value {
+		if isPostPolicyGovernedField(k) && !allowed[strings.ToLower(k)] {
+			h.writeError(w, "AccessDenied", fmt.Sprintf("Field %s is not allowed by the policy", k), bucketName, r)
+			return
+		}
+	}
+	tags, err := parsePostPolicyTagging(governedField("tagging"))
+	if err != nil {
+		h.writeError(w, "InvalidTag", err.Error(), bucketName, r)
+		return
+	}
+
 	// Get the uploaded file — the file field must be named "file" or "content".
 	var fileHeader *multipart.FileHeader
 	for _, name := range []string{"file", "content"} {
@@ -830,8 +900,10 @@ func (h *Handler) HandlePresignedPost(w http.ResponseWriter, r *http.Request) {
 	syntheticHeaders.Set("Content-Length", strconv.FormatInt(fileSize, 10))
 	// Forward any x-amz-meta-* fields from the form.
 	for k, vs := range form.Value {
-		if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") && len(vs) > 0 {
-			syntheticHeaders.Set(k, vs[0])
+		if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") {
+			for _, v := range vs {
+				syntheticHeaders.Add(k, v)
+			}
 		}
 	}
 
@@ -841,9 +913,30 @@ func (h *Handler) HandlePresignedPost(w http.ResponseWriter, r *http.Request) {
 	result, err := h.objectManager.PutObject(r.Context(), bucketPath, objectKey, src, syntheticHeaders)
 	if err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{"bucket": bucketName, "key": objectKey}).Error("POST presigned: PutObject failed")
+		if storage.IsInsufficientStorage(err) {
+			h.writeInsufficientStorage(w, r, bucketName)
+			return
+		}
+		if errors.Is(err, object.ErrInvalidStorageClass) {
+			h.writeError(w, "InvalidStorageClass", "The storage class you specified is not valid", objectKey, r)
+			return
+		}
+		if errors.Is(err, object.ErrReservedKeyPrefix) {
+			h.writeError(w, "AccessDenied", "The object key is in a reserved system namespace", objectKey, r)
+			return
+		}
+		if h.writeUploadScanError(w, r, err, objectKey) {
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
+	if tags != nil {
+		if err := h.objectManager.SetObjectTagging(r.Context(), bucketPath, objectKey, tags); err != nil {
+			h.writeError(w, "InternalError", err.Error(), objectKey, r)
+			return
+		}
+	}
 
 	// Check for success_action_redirect.
 	if redirect := field("success_action_redirect"); redirect != "" {
@@ -893,6 +986,41 @@ func (h *Handler) HandlePresignedPost(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(statusCode)
 }
 
+// isPostPolicyGovernedField reports whether a POST form field is one that is
+// stored with the object and so must be allowed by a policy condition.
+func isPostPolicyGovernedField(name string) bool {
+	name = strings.ToLower(name)
+	return name == "tagging" || strings.HasPrefix(name, "x-amz-meta-")
+}
+
+// parsePostPolicyTagging parses the XML Tagging document of a POST form's
+// tagging field. An empty field means no tags.
+func parsePostPolicyTagging(raw string) (*object.TagSet, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var tagging Tagging
+	if err := xml.Unmarshal([]byte(raw), &tagging); err != nil {
+		return nil, fmt.Errorf("tagging field is not a well-formed Tagging document")
+	}
+	if len(tagging.TagSet.Tags) > 10 {
+		return nil, fmt.Errorf("object cannot have more than 10 tags")
+	}
+	tags := &object.TagSet{Tags: make([]object.Tag, 0, len(tagging.TagSet.Tags))}
+	seen := make(map[string]bool, len(tagging.TagSet.Tags))
+	for _, tag := range tagging.TagSet.Tags {
+		if tag.Key == "" {
+			return nil, fmt.Errorf("tag key cannot be empty")
+		}
+		if seen[tag.Key] {
+			return nil, fmt.Errorf("duplicate tag key %q", tag.Key)
+		}
+		seen[tag.Key] = true
+		tags.Tags = append(tags.Tags, object.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	return tags, nil
+}
+
 // toFloat64 converts json.Number / float64 / int values to float64.
 func toFloat64(v interface{}) float64 {
 	switch n := v.(type) {
@@ -0,0 +1,107 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/maxiofs/maxiofs/internal/acl"
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/sirupsen/logrus"
+)
+
+// publicAccessBlock returns the bucket's public access block settings, or nil
+// when none are configured.
+func (h *Handler) publicAccessBlock(ctx context.Context, tenantID, bucketName string) *bucket.PublicAccessBlock {
+	if h.bucketManager == nil {
+		return nil
+	}
+	pab, err := h.bucketManager.GetPublicAccessBlock(ctx, tenantID, bucketName)
+	if err != nil {
+		return nil
+	}
+	return pab
+}
+
+// bucketPathTenant splits a "tenant/bucket" storage path into its tenant ID
+// and bucket name; a global bucket path has no tenant.
+func bucketPathTenant(bucketPath string) (tenantID, bucketName string) {
+	if tenant, name, ok := strings.Cut(bucketPath, "/"); ok {
+		return tenant, name
+	}
+	return "", bucketPath
+}
+
+// ignoresPublicACLs reports whether IgnorePublicAcls is set on the bucket, in
+// which case grants to the AllUsers and AuthenticatedUsers groups are not
+// honoured for anyone.
+func (h *Handler) ignoresPublicACLs(ctx context.Context, tenantID, bucketName string) bool {
+	pab := h.publicAccessBlock(ctx, tenantID, bucketName)
+	return pab != nil && pab.IgnorePublicAcls
+}
+
+// isPublicCannedACL reports whether a canned ACL grants access to the
+// AllUsers or AuthenticatedUsers group
+func isPublicCannedACL(cannedACL string) bool {
+	switch cannedACL {
+	case acl.CannedACLPublicRead, acl.CannedACLPublicReadWrite, acl.CannedACLAuthenticatedRead:
+		return true
+	}
+	return false
+}
+
+// objectGrantsPublicAccess reports whether any object ACL grant goes to a
+// public group
+func objectGrantsPublicAccess(grants []object.Grant) bool {
+	for _, grant := range grants {
+		if acl.IsPublicGroup(grant.Grantee.URI) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestSetsPublicACL reports whether the request asks for a public ACL,
+// through x-amz-acl or an x-amz-grant-* header naming a public group.
+func requestSetsPublicACL(r *http.Request) bool {
+	if isPublicCannedACL(r.Header.Get("x-amz-acl")) {
+		return true
+	}
+	for name, values := range r.Header {
+		if !strings.HasPrefix(strings.ToLower(name), "x-amz-grant-") {
+			continue
+		}
+		for _, value := range values {
+			if strings.Contains(value, acl.GroupAllUsers) || strings.Contains(value, acl.GroupAuthenticatedUsers) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rejectPublicACL writes AccessDenied and returns true when public is set and
+// the bucket's BlockPublicAcls setting forbids public ACLs.
+func (h *Handler) rejectPublicACL(w http.ResponseWriter, r *http.Request, tenantID, bucketName, resource string, public bool) bool {
+	if !public {
+		return false
+	}
+	pab := h.publicAccessBlock(r.Context(), tenantID, bucketName)
+	if pab == nil || !pab.BlockPublicAcls {
+		return false
+	}
+	logrus.WithFields(logrus.Fields{
+		"bucket":   bucketName,
+		"resource": resource,
+	}).Warn("Public ACL rejected by BlockPublicAcls")
+	h.writeError(w, "AccessDenied", "Access Denied: public ACLs are blocked by the bucket's public access block", resource, r)
+	return true
+}
+
+// restrictsPublicPolicy reports whether an Allow from the bucket policy must be
+// discarded because RestrictPublicBuckets is set, the policy is public and the
+// caller is anonymous or belongs to another tenant.
+func (h *Handler) restrictsPublicPolicy(ctx context.Context, tenantID, bucketName string, policy *bucket.Policy) bool {
+	return bucket.RestrictsPublicPolicy(ctx, h.publicAccessBlock(ctx, tenantID, bucketName), policy, tenantID)
+}
@@ -0,0 +1,119 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/bucket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pabTestEnv is an S3 environment with one bucket holding doc.txt
+type pabTestEnv struct {
+	*s3TestEnv
+	t *testing.T
+}
+
+func setupPublicAccessBlockTest(t *testing.T) *pabTestEnv {
+	env := &pabTestEnv{s3TestEnv: setupCompleteS3Environment(t), t: t}
+	t.Cleanup(env.cleanup)
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, "pab-bucket", env.userID))
+	require.Equal(t, http.StatusOK, env.signed("PUT", "/pab-bucket/doc.txt", []byte("hello"), nil).Code)
+	return env
+}
+
+// signed sends a request signed by the bucket owner
+func (env *pabTestEnv) signed(method, path string, body []byte, headers map[string]string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Host = "localhost"
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	signRequestV4(req, env.accessKey, env.secretKey, "us-east-1", "s3")
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+// anonymous sends an unsigned request
+func (env *pabTestEnv) anonymous(method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = "localhost"
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+func (env *pabTestEnv) block(pab bucket.PublicAccessBlock) {
+	require.NoError(env.t, env.bucketManager.SetPublicAccessBlock(context.Background(), env.tenantID, "pab-bucket", &pab))
+}
+
+func TestPublicAccessBlock_BlockPublicAcls(t *testing.T) {
+	env := setupPublicAccessBlockTest(t)
+	env.block(bucket.PublicAccessBlock{BlockPublicAcls: true})
+
+	publicACL := map[string]string{"x-amz-acl": "public-read"}
+	w := env.signed("PUT", "/pab-bucket/new.txt", []byte("x"), publicACL)
+	assert.Equal(t, http.StatusForbidden, w.Code, "PutObject with a public canned ACL")
+	assert.Contains(t, w.Body.String(), "AccessDenied")
+
+	assert.Equal(t, http.StatusForbidden, env.signed("PUT", "/pab-bucket?acl", nil, publicACL).Code, "PutBucketAcl")
+	assert.Equal(t, http.StatusForbidden, env.signed("PUT", "/pab-bucket/doc.txt?acl", nil, publicACL).Code, "PutObjectAcl")
+	assert.Equal(t, http.StatusForbidden, env.signed("POST", "/pab-bucket/big.bin?uploads", nil, publicACL).Code, "CreateMultipartUpload")
+	assert.Equal(t, http.StatusForbidden, env.signed("PUT", "/pab-bucket/copy.txt", nil, map[string]string{
+		"x-amz-copy-source": "/pab-bucket/doc.txt",
+		"x-amz-acl":         "public-read",
+	}).Code, "CopyObject")
+	assert.Equal(t, http.StatusForbidden, env.signed("PUT", "/pab-bucket/grant.txt", []byte("x"), map[string]string{
+		"x-amz-grant-read": `uri="http://acs.amazonaws.com/groups/global/AllUsers"`,
+	}).Code, "grant header naming AllUsers")
+
+	// Private ACLs are still accepted
+	assert.Equal(t, http.StatusOK, env.signed("PUT", "/pab-bucket/new.txt", []byte("x"), map[string]string{"x-amz-acl": "private"}).Code)
+	assert.Equal(t, http.StatusOK, env.signed("PUT", "/pab-bucket?acl", nil, map[string]string{"x-amz-acl": "private"}).Code)
+}
+
+func TestPublicAccessBlock_IgnorePublicAcls(t *testing.T) {
+	env := setupPublicAccessBlockTest(t)
+	require.Equal(t, http.StatusOK, env.signed("PUT", "/pab-bucket?acl", nil, map[string]string{"x-amz-acl": "public-read"}).Code)
+	require.Equal(t, http.StatusOK, env.anonymous("GET", "/pab-bucket/doc.txt").Code, "public-read bucket is readable anonymously")
+
+	env.block(bucket.PublicAccessBlock{IgnorePublicAcls: true})
+	assert.Equal(t, http.StatusForbidden, env.anonymous("GET", "/pab-bucket/doc.txt").Code)
+
+	// The stored ACL is untouched and the owner keeps access
+	assert.Equal(t, http.StatusOK, env.signed("GET", "/pab-bucket/doc.txt", nil, nil).Code)
+}
+
+func TestPublicAccessBlock_BlockPublicPolicy(t *testing.T) {
+	env := setupPublicAccessBlockTest(t)
+	env.block(bucket.PublicAccessBlock{BlockPublicPolicy: true})
+
+	public := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::pab-bucket/*"}]}`)
+	w := env.signed("PUT", "/pab-bucket?policy", public, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "AccessDenied")
+
+	scoped := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"partner-user"},"Action":"s3:GetObject","Resource":"arn:aws:s3:::pab-bucket/*"}]}`)
+	assert.Equal(t, http.StatusNoContent, env.signed("PUT", "/pab-bucket?policy", scoped, nil).Code)
+}
+
+func TestPublicAccessBlock_RestrictPublicBuckets(t *testing.T) {
+	env := setupPublicAccessBlockTest(t)
+	public := []byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::pab-bucket/*"}]}`)
+	require.Equal(t, http.StatusNoContent, env.signed("PUT", "/pab-bucket?policy", public, nil).Code)
+	require.Equal(t, http.StatusOK, env.anonymous("GET", "/pab-bucket/doc.txt").Code, "public policy grants anonymous reads")
+
+	env.block(bucket.PublicAccessBlock{RestrictPublicBuckets: true})
+	assert.Equal(t, http.StatusForbidden, env.anonymous("GET", "/pab-bucket/doc.txt").Code)
+	assert.Equal(t, http.StatusOK, env.signed("GET", "/pab-bucket/doc.txt", nil, nil).Code, "owner tenant keeps access")
+}
@@ -0,0 +1,74 @@
+package s3compat
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDHeaders_SuccessAndError(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	handler := middleware.S3RequestID(true)(env.router)
+
+	bucketName := "request-ids"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/hello.txt", []byte("hello"))
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	t.Run("successful GET", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/hello.txt", nil)
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, w.Header().Get("X-Amz-Request-Id"), 16)
+		assert.NotEmpty(t, w.Header().Get("X-Amz-Id-2"))
+		assert.Equal(t, w.Header().Get("X-Amz-Request-Id"), w.Header().Get("X-Request-Id"))
+	})
+
+	t.Run("incoming id is echoed", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/hello.txt", nil)
+		req.Header.Set("X-Amz-Request-Id", "upstream-7f3a")
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "upstream-7f3a", w.Header().Get("X-Amz-Request-Id"))
+	})
+
+	errorIDs := func(t *testing.T, w *httptest.ResponseRecorder) {
+		var body struct {
+			RequestID string `xml:"RequestId"`
+			HostID    string `xml:"HostId"`
+		}
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+		assert.NotEmpty(t, w.Header().Get("X-Amz-Request-Id"))
+		assert.NotEmpty(t, w.Header().Get("X-Amz-Id-2"))
+		assert.Equal(t, w.Header().Get("X-Amz-Request-Id"), body.RequestID, "the body quotes the header's id")
+		if body.HostID != "" {
+			assert.Equal(t, w.Header().Get("X-Amz-Id-2"), body.HostID)
+		}
+	}
+
+	t.Run("error response", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/missing.txt", nil)
+		req.Header.Set("X-Amz-Request-Id", "upstream-404")
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "upstream-404", w.Header().Get("X-Amz-Request-Id"))
+		errorIDs(t, w)
+	})
+
+	t.Run("auth error response", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/"+bucketName+"/hello.txt", nil)
+		req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=NOPE/20260101/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=00")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.GreaterOrEqual(t, w.Code, 400)
+		errorIDs(t, w)
+	})
+}
@@ -0,0 +1,155 @@
+package s3compat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/maxiofs/maxiofs/internal/object"
+	"github.com/sirupsen/logrus"
+)
+
+// What reads of an archived object that has not been restored do, see
+// SetColdReads.
+const (
+	coldReadsAllow = "allow"
+	coldReadsDeny  = "deny"
+	coldReadsWait  = "wait"
+)
+
+// restoreTracker keeps the thaws running on this node, so that a restore is
+// never run twice at once and GetObject can wait for one to finish.
+type restoreTracker struct {
+	mu       sync.Mutex
+	inFlight map[string]*restoreThaw
+}
+
+type restoreThaw struct {
+	done chan struct{}
+	err  error
+}
+
+func newRestoreTracker() *restoreTracker {
+	return &restoreTracker{inFlight: make(map[string]*restoreThaw)}
+}
+
+// restoreKey identifies an object version in a restoreTracker.
+func restoreKey(bucketPath, objectKey, versionID string) string {
+	return bucketPath + "\x00" + objectKey + "\x00" + versionID
+}
+
+// start runs thaw in the background unless a thaw of the same object is
+// already running, in which case it returns the running one and started is
+// false. begin runs under the tracker's lock before a new thaw starts, so
+// marking the object and starting its thaw is one step; if begin fails no
+// thaw is started.
+func (t *restoreTracker) start(key string, begin, thaw func() error) (running *restoreThaw, started bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if running, ok := t.inFlight[key]; ok {
+		return running, false, nil
+	}
+	if err := begin(); err != nil {
+		return nil, false, err
+	}
+	running = &restoreThaw{done: make(chan struct{})}
+	t.inFlight[key] = running
+	go func() {
+		running.err = thaw()
+		t.mu.Lock()
+		delete(t.inFlight, key)
+		t.mu.Unlock()
+		close(running.done)
+	}()
+	return running, true, nil
+}
+
+// SetColdReads sets what reads (GetObject, CopyObject and UploadPartCopy
+// sources, SelectObjectContent) of an archived object that has not been
+// restored do: "allow" (the default) reads it like any other object, "deny"
+// answers 403 InvalidObjectState as S3 does, "wait" restores it for a day and
+// serves it once the thaw finishes.
+func (h *Handler) SetColdReads(mode string) {
+	switch mode {
+	case coldReadsDeny, coldReadsWait:
+		h.coldReads = mode
+	default:
+		h.coldReads = coldReadsAllow
+	}
+}
+
+// startRestore marks an archived object as being restored and thaws it in the
+// background: its data is read back from storage in full, and once that
+// succeeds the object is restored for the given number of days. A failed thaw
+// clears the restore status so the client can request it again. If a thaw of
+// the object is already running here it is returned and started is false.
+func (h *Handler) startRestore(ctx context.Context, bucketPath, objectKey, versionID string, days int) (*restoreThaw, bool, error) {
+	key := restoreKey(bucketPath, objectKey, versionID)
+	begin := func() error {
+		return h.objectManager.SetRestoreStatus(ctx, bucketPath, objectKey, "ongoing", nil, versionID)
+	}
+	return h.restores.start(key, begin, func() error {
+		thawCtx := context.Background()
+		if err := h.thawObject(thawCtx, bucketPath, objectKey, versionID); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"bucket": bucketPath,
+				"object": objectKey,
+			}).Error("Restore: failed to thaw archived object")
+			if resetErr := h.objectManager.SetRestoreStatus(thawCtx, bucketPath, objectKey, "", nil, versionID); resetErr != nil {
+				logrus.WithError(resetErr).Warn("Restore: failed to clear restore status")
+			}
+			return err
+		}
+		expiresAt := time.Now().UTC().AddDate(0, 0, days)
+		return h.objectManager.SetRestoreStatus(thawCtx, bucketPath, objectKey, "restored", &expiresAt, versionID)
+	})
+}
+
+// thawObject reads the object's data back from storage. MaxIOFS keeps
+// archived objects on the same storage as the others, so this verifies the
+// data is readable rather than moving it.
+func (h *Handler) thawObject(ctx context.Context, bucketPath, objectKey, versionID string) error {
+	_, reader, err := h.objectManager.GetObject(ctx, bucketPath, objectKey, versionID)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return fmt.Errorf("read object data: %w", err)
+	}
+	return nil
+}
+
+// checkArchivedObjectReadable lets a request read obj unless it is archived
+// and not restored and cold reads aren't allowed, in which case it answers
+// 403 InvalidObjectState, or in "wait" mode restores the object and blocks
+// until the thaw has finished. resource names the object in the error.
+func (h *Handler) checkArchivedObjectReadable(w http.ResponseWriter, r *http.Request, obj *object.Object, bucketPath, objectKey, versionID, resource string) bool {
+	if h.coldReads == coldReadsAllow || !obj.NeedsRestore(time.Now()) {
+		return true
+	}
+	if h.coldReads != coldReadsWait {
+		h.writeError(w, "InvalidObjectState", "The operation is not valid for the object's storage class", resource, r)
+		return false
+	}
+
+	thaw, _, err := h.startRestore(r.Context(), bucketPath, objectKey, versionID, 1)
+	if err != nil {
+		h.writeError(w, "InternalError", err.Error(), resource, r)
+		return false
+	}
+	select {
+	case <-thaw.done:
+	case <-r.Context().Done():
+		// The client went away; the thaw carries on for the next read
+		return false
+	}
+	if thaw.err != nil {
+		h.writeError(w, "InternalError", thaw.err.Error(), resource, r)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package s3compat
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/maxiofs/maxiofs/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// putArchivedObject writes an object with the GLACIER storage class
+func putArchivedObject(t *testing.T, env *s3TestEnv, bucketName, objectKey string, body []byte) {
+	t.Helper()
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, env.userID))
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+objectKey, body)
+	req.Header.Set("x-amz-storage-class", "GLACIER")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func restoreObject(env *s3TestEnv, bucketName, objectKey string) *httptest.ResponseRecorder {
+	body := []byte(`<RestoreRequest><Days>3</Days><GlacierJobParameters><Tier>Standard</Tier></GlacierJobParameters></RestoreRequest>`)
+	req := httptest.NewRequest("POST", "/"+bucketName+"/"+objectKey+"?restore=", bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"bucket": bucketName, "object": objectKey})
+	req = req.WithContext(context.WithValue(req.Context(), "user", &auth.User{
+		ID:       env.userID,
+		TenantID: env.tenantID,
+		Roles:    []string{"admin"},
+	}))
+	w := httptest.NewRecorder()
+	env.handler.RestoreObject(w, req)
+	return w
+}
+
+func headRestore(t *testing.T, env *s3TestEnv, path string) string {
+	t.Helper()
+	req, w := env.makeS3Request("HEAD", path, nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Header().Get("x-amz-restore")
+}
+
+// TestRestoreObject_ArchivedObjectThaws tests that in "deny" mode an archived
+// object is refused until restored, that HEAD reports the restore progress,
+// and that it reads normally once thawed
+func TestRestoreObject_ArchivedObjectThaws(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	env.handler.SetColdReads("deny")
+
+	bucketName := "archive"
+	objectKey := "backup.tar"
+	path := "/" + bucketName + "/" + objectKey
+	putArchivedObject(t, env, bucketName, objectKey, []byte("archived data"))
+
+	req, w := env.makeS3Request("GET", path, nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>InvalidObjectState</Code>")
+	assert.Empty(t, headRestore(t, env, path))
+
+	// A restore in progress is reported on HEAD and still can't be read
+	bucketPath := env.tenantID + "/" + bucketName
+	require.NoError(t, env.objectManager.SetRestoreStatus(context.Background(), bucketPath, objectKey, "ongoing", nil))
+	assert.Equal(t, `ongoing-request="true"`, headRestore(t, env, path))
+	req, w = env.makeS3Request("GET", path, nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// That restore isn't running on this node (e.g. it was interrupted by a
+	// restart), so requesting it again thaws the object
+	w = restoreObject(env, bucketName, objectKey)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	require.Eventually(t, func() bool {
+		return strings.HasPrefix(headRestore(t, env, path), `ongoing-request="false", expiry-date=`)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	req, w = env.makeS3Request("GET", path, nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "archived data", w.Body.String())
+
+	restored, err := env.objectManager.GetObjectMetadata(context.Background(), bucketPath, objectKey)
+	require.NoError(t, err)
+	require.NotNil(t, restored.RestoreExpiresAt)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 3), *restored.RestoreExpiresAt, time.Minute)
+
+	// Once the restored copy expires the object is archived again
+	expired := time.Now().Add(-time.Minute)
+	require.NoError(t, env.objectManager.SetRestoreStatus(context.Background(), bucketPath, objectKey, "restored", &expired))
+	req, w = env.makeS3Request("GET", path, nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// TestRestoreObject_ColdReadsAllowByDefault tests that by default archived
+// objects read like any other, so objects stored with an archive storage
+// class before restores existed stay readable
+func TestRestoreObject_ColdReadsAllowByDefault(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "archive"
+	putArchivedObject(t, env, bucketName, "backup.tar", []byte("archived data"))
+
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/backup.tar", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "archived data", w.Body.String())
+}
+
+// TestRestoreObject_ColdReadsDenyGatesCopy tests that in "deny" mode an
+// unrestored archived object can't be read through CopyObject either
+func TestRestoreObject_ColdReadsDenyGatesCopy(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	env.handler.SetColdReads("deny")
+
+	bucketName := "archive"
+	putArchivedObject(t, env, bucketName, "backup.tar", []byte("archived data"))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/copy.tar", nil)
+	req.Header.Set("x-amz-copy-source", "/"+bucketName+"/backup.tar")
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>InvalidObjectState</Code>")
+}
+
+// TestRestoreObject_ColdReadsWait tests that in "wait" mode a GET of an
+// archived object restores it and serves it
+func TestRestoreObject_ColdReadsWait(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	env.handler.SetColdReads("wait")
+
+	bucketName := "archive"
+	objectKey := "backup.tar"
+	putArchivedObject(t, env, bucketName, objectKey, []byte("archived data"))
+
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/"+objectKey, nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "archived data", w.Body.String())
+	assert.True(t, strings.HasPrefix(headRestore(t, env, "/"+bucketName+"/"+objectKey), `ongoing-request="false"`))
+}
+
+// TestRestoreObject_InstantRetrievalReadsDirectly tests that only GLACIER and
+// DEEP_ARCHIVE objects need a restore
+func TestRestoreObject_InstantRetrievalReadsDirectly(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "archive"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, env.userID))
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/ir.txt", []byte("instant"))
+	req.Header.Set("x-amz-storage-class", "GLACIER_IR")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/ir.txt", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "instant", w.Body.String())
+}
@@ -48,7 +48,7 @@ type s3TestEnv struct {
 }
 
 // setupCompleteS3Environment creates a fully functional S3 API test environment with authentication
-func setupCompleteS3Environment(t *testing.T) *s3TestEnv {
+func setupCompleteS3Environment(t testing.TB) *s3TestEnv {
 	// Create temporary directory for test data
 	tempDir, err := os.MkdirTemp("", "maxiofs-s3-test-*")
 	require.NoError(t, err, "Failed to create temp dir")
@@ -119,7 +119,8 @@ func setupCompleteS3Environment(t *testing.T) *s3TestEnv {
 	// Create managers
 	bucketManager := bucket.NewManager(storageBackend, metadataStore)
 	storageConfig := config.StorageConfig{
-		Root: tempDir,
+		Root:                tempDir,
+		ReservedKeyPrefixes: []string{".system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c/"},
 	}
 	objectManager := object.NewManager(storageBackend, metadataStore, storageConfig)
 
@@ -243,8 +244,13 @@ func setupCompleteS3Environment(t *testing.T) *s3TestEnv {
 
 // signRequestV4 signs an HTTP request using AWS Signature Version 4
 func signRequestV4(req *http.Request, accessKey, secretKey, region, service string) {
+	signRequestV4At(req, accessKey, secretKey, region, service, time.Now())
+}
+
+// signRequestV4At signs an HTTP request as if the client clock read signTime
+func signRequestV4At(req *http.Request, accessKey, secretKey, region, service string, signTime time.Time) {
 	// Set required headers
-	now := time.Now().UTC()
+	now := signTime.UTC()
 	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
 	req.Header.Set("Host", req.Host)
 
@@ -572,7 +578,9 @@ func TestS3HeadErrorNoBody(t *testing.T) {
 	})
 }
 
-func TestS3GetHeadMetadataPresentMissingDataReturnsNoSuchKey(t *testing.T) {
+// TestS3GetHeadMetadataPresentMissingData checks GET reports the missing data
+// (read repair flags the object) while HEAD keeps answering 404.
+func TestS3GetHeadMetadataPresentMissingData(t *testing.T) {
 	env := setupCompleteS3Environment(t)
 	defer env.cleanup()
 
@@ -588,8 +596,9 @@ func TestS3GetHeadMetadataPresentMissingDataReturnsNoSuchKey(t *testing.T) {
 
 	req, w := env.makeS3Request("GET", "/"+bucketName+"/"+objectKey, nil)
 	env.router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.Contains(t, w.Body.String(), "<Code>NoSuchKey</Code>")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>InternalError</Code>")
+	assert.Contains(t, w.Body.String(), objectDataMissingMessage)
 
 	req, w = env.makeS3Request("HEAD", "/"+bucketName+"/"+objectKey, nil)
 	env.router.ServeHTTP(w, req)
@@ -597,6 +606,85 @@ func TestS3GetHeadMetadataPresentMissingDataReturnsNoSuchKey(t *testing.T) {
 	assert.Empty(t, w.Body.Bytes(), "HEAD error response must have no body")
 }
 
+func TestS3PutReservedKeyPrefix(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "reserved-prefix"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	systemKey := ".system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c/system.xml"
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+systemKey, []byte("<xml/>"))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "<Code>AccessDenied</Code>")
+
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/"+systemKey+"?uploads", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/data/system.xml", []byte("<xml/>"))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestS3ListObjectsConditional(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "list-etag"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/a.txt", []byte("a"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/?list-type=2", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Unchanged bucket: 304 on both list versions and HEAD
+	for _, path := range []string{"/" + bucketName + "/?list-type=2", "/" + bucketName + "/"} {
+		req, w = env.makeS3Request("GET", path, nil)
+		req.Header.Set("If-None-Match", etag)
+		env.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code, path)
+		assert.Empty(t, w.Body.Bytes())
+	}
+	req, w = env.makeS3Request("HEAD", "/"+bucketName, nil)
+	req.Header.Set("If-None-Match", etag)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// Changed bucket: 200 with a new ETag
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/b.txt", []byte("b"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/?list-type=2", nil)
+	req.Header.Set("If-None-Match", etag)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<Key>b.txt</Key>")
+	newETag := w.Header().Get("ETag")
+	assert.NotEmpty(t, newETag)
+	assert.NotEqual(t, etag, newETag)
+
+	// Deleting an object changes it again
+	req, w = env.makeS3Request("DELETE", "/"+bucketName+"/a.txt", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/", nil)
+	req.Header.Set("If-None-Match", newETag)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 func removeStoredObjectDataFile(t *testing.T, root, objectKey string) {
 	t.Helper()
 
@@ -700,6 +788,21 @@ func TestS3BucketPolicy(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "AllowPublicRead", "Should contain policy statement")
 	})
 
+	t.Run("Malformed policy is rejected and the stored one kept", func(t *testing.T) {
+		otherBucket := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::another-bucket/*"}]}`
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"?policy", []byte(otherBucket))
+		req.Header.Set("Content-Type", "application/json")
+		env.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "<Code>MalformedPolicy</Code>")
+		assert.Contains(t, w.Body.String(), `refers to bucket &#34;another-bucket&#34;`)
+
+		req, w = env.makeS3Request("GET", "/"+bucketName+"?policy", nil)
+		env.router.ServeHTTP(w, req)
+		assert.Contains(t, w.Body.String(), "AllowPublicRead")
+	})
+
 	t.Run("Delete bucket policy", func(t *testing.T) {
 		req, w := env.makeS3Request("DELETE", "/"+bucketName+"?policy", nil)
 		env.router.ServeHTTP(w, req)
@@ -756,6 +859,44 @@ func TestS3BucketLifecycle(t *testing.T) {
 	})
 }
 
+// TestS3BucketLifecycle_NewerNoncurrentVersions tests the keep-N noncurrent
+// versions rule round-trips through the S3 API
+func TestS3BucketLifecycle_NewerNoncurrentVersions(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "lifecycle-keep-versions"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	lifecycleXML := `<LifecycleConfiguration>
+		<Rule>
+			<ID>keep-3</ID>
+			<Status>Enabled</Status>
+			<Filter><Prefix></Prefix></Filter>
+			<NoncurrentVersionExpiration>
+				<NoncurrentDays>30</NoncurrentDays>
+				<NewerNoncurrentVersions>3</NewerNoncurrentVersions>
+			</NoncurrentVersionExpiration>
+		</Rule>
+	</LifecycleConfiguration>`
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"?lifecycle", []byte(lifecycleXML))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"?lifecycle", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<NewerNoncurrentVersions>3</NewerNoncurrentVersions>")
+	assert.Contains(t, w.Body.String(), "<NoncurrentDays>30</NoncurrentDays>")
+
+	invalidXML := strings.Replace(lifecycleXML, "<NewerNoncurrentVersions>3<", "<NewerNoncurrentVersions>-1<", 1)
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"?lifecycle", []byte(invalidXML))
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "InvalidArgument")
+}
+
 // TestS3BucketCORS tests CORS configuration via S3 API
 func TestS3BucketCORS(t *testing.T) {
 	env := setupCompleteS3Environment(t)
@@ -935,17 +1076,16 @@ func TestS3MultipartUpload(t *testing.T) {
 		assert.Contains(t, w.Body.String(), objectKey, "Response should contain object key")
 	})
 
-	t.Run("Complete multipart upload with invalid uploadId returns NoSuchUpload in body", func(t *testing.T) {
-		// AWS S3 behaviour: CompleteMultipartUpload always returns 200 OK immediately
-		// to prevent client timeouts on large objects. If processing fails, the error
-		// is embedded as XML in the body (clients must parse the body on 200 responses).
+	t.Run("Complete multipart upload with invalid uploadId returns NoSuchUpload", func(t *testing.T) {
+		// The 200 OK is only committed once a completion outlasts the first
+		// keep-alive interval; a failure before that gets a real error status.
 		completeXML := `<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>"abc"</ETag></Part></CompleteMultipartUpload>`
 		req, w := env.makeS3Request("POST", fmt.Sprintf("/%s/nonexistent.dat?uploadId=invalid-upload-id-xyz", bucketName), []byte(completeXML))
 		req.Header.Set("Content-Type", "application/xml")
 		env.router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusOK, w.Code, "CompleteMultipartUpload always returns 200 OK immediately (AWS S3 compatible)")
-		assert.Contains(t, w.Body.String(), "NoSuchUpload", "Error body must contain NoSuchUpload code")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "<Code>NoSuchUpload</Code>")
 	})
 
 	t.Run("List parts", func(t *testing.T) {
@@ -1420,6 +1560,127 @@ func TestS3RangeRequests(t *testing.T) {
 		assert.Equal(t, content, w.Body.Bytes(), "Should return complete content")
 		assert.Equal(t, "62", w.Header().Get("Content-Length"), "Content-Length should be 62")
 	})
+
+	t.Run("Suffix range larger than object serves whole object", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/"+objectKey, nil)
+		req.Header.Set("Range", "bytes=-1000")
+		env.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, content, w.Body.Bytes())
+		assert.Equal(t, "bytes 0-61/62", w.Header().Get("Content-Range"))
+		assert.Equal(t, "62", w.Header().Get("Content-Length"))
+	})
+
+	t.Run("Unsatisfiable ranges in a list are dropped", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/"+objectKey, nil)
+		req.Header.Set("Range", "bytes=1000-2000,0-9")
+		env.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPartialContent, w.Code, "one satisfiable range is enough")
+		assert.Equal(t, "0123456789", w.Body.String())
+		assert.Equal(t, "bytes 0-9/62", w.Header().Get("Content-Range"))
+	})
+
+	t.Run("Satisfiable range first in a mixed list", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/"+objectKey, nil)
+		req.Header.Set("Range", "bytes=0-9,1000-2000")
+		env.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, "0123456789", w.Body.String())
+	})
+
+	t.Run("No satisfiable range in a list returns 416", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/"+objectKey, nil)
+		req.Header.Set("Range", "bytes=100-200,62-,-0")
+		env.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+		assert.Equal(t, "bytes */62", w.Header().Get("Content-Range"))
+	})
+
+	req, w := env.makeS3Request("HEAD", "/"+bucketName+"/"+objectKey, nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	require.NotEmpty(t, etag)
+	require.NotEmpty(t, lastModified)
+
+	for _, tc := range []struct {
+		name    string
+		ifRange string
+		partial bool
+	}{
+		{"If-Range with the current ETag serves the range", etag, true},
+		{"If-Range with the current Last-Modified serves the range", lastModified, true},
+		{"If-Range with a stale ETag serves the full object", `"0123456789abcdef0123456789abcdef"`, false},
+		{"If-Range with an older date serves the full object", "Mon, 02 Jan 2006 15:04:05 GMT", false},
+		{"If-Range with a weak ETag serves the full object", "W/" + etag, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, method := range []string{"GET", "HEAD"} {
+				req, w := env.makeS3Request(method, "/"+bucketName+"/"+objectKey, nil)
+				req.Header.Set("Range", "bytes=0-9")
+				req.Header.Set("If-Range", tc.ifRange)
+				env.router.ServeHTTP(w, req)
+
+				if tc.partial {
+					assert.Equal(t, http.StatusPartialContent, w.Code, method)
+					assert.Equal(t, "bytes 0-9/62", w.Header().Get("Content-Range"), method)
+					assert.Equal(t, "10", w.Header().Get("Content-Length"), method)
+					if method == "GET" {
+						assert.Equal(t, "0123456789", w.Body.String())
+					}
+				} else {
+					assert.Equal(t, http.StatusOK, w.Code, method)
+					assert.Empty(t, w.Header().Get("Content-Range"), method)
+					assert.Equal(t, "62", w.Header().Get("Content-Length"), method)
+					if method == "GET" {
+						assert.Equal(t, content, w.Body.Bytes())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseRangeHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		size       int64
+		start, end int64
+		wantErr    bool
+	}{
+		{"single range", "bytes=0-9", 50, 0, 9, false},
+		{"end clamped to object", "bytes=40-100", 50, 40, 49, false},
+		{"open range", "bytes=10-", 50, 10, 49, false},
+		{"suffix", "bytes=-10", 50, 40, 49, false},
+		{"suffix larger than object", "bytes=-500", 50, 0, 49, false},
+		{"mixed list keeps satisfiable range", "bytes=0-9,1000-2000", 50, 0, 9, false},
+		{"mixed list skips leading unsatisfiable range", "bytes=1000-2000, 5-6", 50, 5, 6, false},
+		{"no satisfiable range", "bytes=1000-2000,50-", 50, 0, 0, true},
+		{"zero suffix is unsatisfiable", "bytes=-0", 50, 0, 0, true},
+		{"empty object", "bytes=0-", 0, 0, 0, true},
+		{"malformed range in list", "bytes=0-9,abc", 50, 0, 0, true},
+		{"start after end", "bytes=9-0", 50, 0, 0, true},
+		{"wrong unit", "items=0-9", 50, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseRangeHeader(tt.header, tt.size)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
 }
 
 // TestS3ListObjectVersions tests listing object versions in versioned buckets
@@ -2302,6 +2563,8 @@ func TestS3VersionedDeleteMarkerResponses(t *testing.T) {
 	req, w = env.makeS3Request("PUT", "/"+bucketName+"/"+objectKey, []byte("versioned content"))
 	env.router.ServeHTTP(w, req)
 	require.Equal(t, http.StatusOK, w.Code)
+	priorVersionID := w.Header().Get("x-amz-version-id")
+	require.NotEmpty(t, priorVersionID)
 
 	req, w = env.makeS3Request("DELETE", "/"+bucketName+"/"+objectKey, nil)
 	env.router.ServeHTTP(w, req)
@@ -2346,6 +2609,24 @@ func TestS3VersionedDeleteMarkerResponses(t *testing.T) {
 		assert.Empty(t, w.Body.String())
 	})
 
+	t.Run("HEAD explicit prior version returns the object", func(t *testing.T) {
+		req, w := env.makeS3Request("HEAD", "/"+bucketName+"/"+objectKey+"?versionId="+url.QueryEscape(priorVersionID), nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("x-amz-delete-marker"))
+		assert.Equal(t, priorVersionID, w.Header().Get("x-amz-version-id"))
+		assert.Equal(t, "17", w.Header().Get("Content-Length"))
+	})
+
+	t.Run("GET explicit prior version returns the object", func(t *testing.T) {
+		req, w := env.makeS3Request("GET", "/"+bucketName+"/"+objectKey+"?versionId="+url.QueryEscape(priorVersionID), nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("x-amz-delete-marker"))
+		assert.Equal(t, priorVersionID, w.Header().Get("x-amz-version-id"))
+		assert.Equal(t, "versioned content", w.Body.String())
+	})
+
 	t.Run("GET missing explicit version returns NoSuchVersion", func(t *testing.T) {
 		req, w := env.makeS3Request("GET", "/"+bucketName+"/"+objectKey+"?versionId=missing-version", nil)
 		env.router.ServeHTTP(w, req)
@@ -2618,11 +2899,12 @@ func TestDeleteObjectErrorCases(t *testing.T) {
 		env.router.ServeHTTP(w, req)
 		require.Equal(t, http.StatusOK, w.Code)
 
-		// Try to delete with bypass governance header (will be accepted if user is admin)
+		// The bypass header is ignored without the bypass permission; an object
+		// without retention is deleted either way
 		req, w = env.makeS3Request("DELETE", "/"+bucketName+"/governance-object.txt", nil)
 		req.Header.Set("x-amz-bypass-governance-retention", "true")
 		env.router.ServeHTTP(w, req)
-		// Should succeed since test user has admin role
+		// Should succeed since the object has no retention
 		assert.Equal(t, http.StatusNoContent, w.Code, "Should delete with bypass governance")
 	})
 
@@ -2838,6 +3120,34 @@ func TestS3ListObjectsV2(t *testing.T) {
 		env.router.ServeHTTP(w, req)
 		require.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), "<Owner>", "Owner element must be present when fetch-owner=true")
+
+		var listing ListBucketResultV2
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &listing))
+		require.Len(t, listing.Contents, len(testObjects))
+		for _, obj := range listing.Contents {
+			require.NotNil(t, obj.Owner, obj.Key)
+			assert.Equal(t, env.tenantID, obj.Owner.ID, "objects without their own ACL report the bucket owner")
+		}
+	})
+
+	t.Run("fetch-owner reports the owner in the object's ACL", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/z-owned.txt", []byte("content"))
+		req.Header.Set("x-amz-acl", "private")
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		defer func() {
+			_, err := env.objectManager.DeleteObject(ctx, bucketPath, "z-owned.txt", false)
+			require.NoError(t, err)
+		}()
+
+		req, w = env.makeS3Request("GET", "/"+bucketName+"/?list-type=2&fetch-owner=true&prefix=z-", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var listing ListBucketResultV2
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &listing))
+		require.Len(t, listing.Contents, 1)
+		require.NotNil(t, listing.Contents[0].Owner)
+		assert.Equal(t, env.userID, listing.Contents[0].Owner.ID)
 	})
 
 	// ── prefix filtering ─────────────────────────────────────────────────────
@@ -3710,6 +4020,59 @@ func TestS3MultipartStorageClass(t *testing.T) {
 	})
 }
 
+// TestS3ObjectStorageClass verifies that accepted x-amz-storage-class values are
+// stored and echoed on GET, HEAD and listings, and that unknown values are
+// rejected with InvalidStorageClass.
+func TestS3ObjectStorageClass(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "storage-class-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+
+	for _, sc := range []string{"STANDARD", "REDUCED_REDUNDANCY", "STANDARD_IA", "ONEZONE_IA", "INTELLIGENT_TIERING", "GLACIER_IR"} {
+		t.Run("accepts "+sc, func(t *testing.T) {
+			key := strings.ToLower(sc) + ".txt"
+			req, w := env.makeS3Request("PUT", "/"+bucketName+"/"+key, []byte("data"))
+			req.Header.Set("x-amz-storage-class", sc)
+			env.router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			for _, method := range []string{"GET", "HEAD"} {
+				req, w = env.makeS3Request(method, "/"+bucketName+"/"+key, nil)
+				env.router.ServeHTTP(w, req)
+				require.Equal(t, http.StatusOK, w.Code)
+				assert.Equal(t, sc, w.Header().Get("x-amz-storage-class"), method)
+			}
+
+			req, w = env.makeS3Request("GET", "/"+bucketName+"/?list-type=2&prefix="+key, nil)
+			env.router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), "<StorageClass>"+sc+"</StorageClass>")
+		})
+	}
+
+	for _, sc := range []string{"standard_ia", "EXPRESS_ONEZONE", "FAST"} {
+		t.Run("rejects "+sc, func(t *testing.T) {
+			req, w := env.makeS3Request("PUT", "/"+bucketName+"/rejected.txt", []byte("data"))
+			req.Header.Set("x-amz-storage-class", sc)
+			env.router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "<Code>InvalidStorageClass</Code>")
+
+			req, w = env.makeS3Request("POST", "/"+bucketName+"/rejected.bin?uploads", nil)
+			req.Header.Set("x-amz-storage-class", sc)
+			env.router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "<Code>InvalidStorageClass</Code>")
+		})
+	}
+
+	req, w := env.makeS3Request("HEAD", "/"+bucketName+"/rejected.txt", nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "rejected uploads store nothing")
+}
+
 // TestNormalizeETag verifies that normalizeETag strips surrounding double-quotes.
 func TestNormalizeETag(t *testing.T) {
 	tests := []struct {
@@ -3950,6 +4313,54 @@ func TestObjectTaggingHonorsVersionID(t *testing.T) {
 	assert.NotContains(t, w.Body.String(), "<Key>backup</Key>")
 }
 
+func TestTaggingCountHeader(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "tagging-count-bucket"
+	bucketPath := env.tenantID + "/" + bucketName
+
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, env.userID))
+	require.NoError(t, env.bucketManager.SetVersioning(ctx, env.tenantID, bucketName, &bucket.VersioningConfig{Status: "Enabled"}))
+
+	put := func(key, body string) *object.Object {
+		obj, err := env.objectManager.PutObject(ctx, bucketPath, key, bytes.NewReader([]byte(body)), http.Header{
+			"Content-Type": []string{"text/plain"},
+		})
+		require.NoError(t, err)
+		return obj
+	}
+	tag := func(path, tagSet string) {
+		req, w := env.makeS3Request("PUT", path, []byte("<Tagging><TagSet>"+tagSet+"</TagSet></Tagging>"))
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	countHeader := func(method, path string) string {
+		req, w := env.makeS3Request(method, path, nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Header().Get("x-amz-tagging-count")
+	}
+
+	first := put("tagged.txt", "first")
+	put("tagged.txt", "second")
+	put("untagged.txt", "plain")
+
+	tag("/"+bucketName+"/tagged.txt?tagging=&versionId="+url.QueryEscape(first.VersionID),
+		"<Tag><Key>a</Key><Value>1</Value></Tag>")
+	tag("/"+bucketName+"/tagged.txt?tagging",
+		"<Tag><Key>a</Key><Value>1</Value></Tag><Tag><Key>b</Key><Value>2</Value></Tag><Tag><Key>c</Key><Value>3</Value></Tag>")
+
+	for _, method := range []string{"HEAD", "GET"} {
+		t.Run(method, func(t *testing.T) {
+			assert.Equal(t, "3", countHeader(method, "/"+bucketName+"/tagged.txt"), "current version")
+			assert.Equal(t, "1", countHeader(method, "/"+bucketName+"/tagged.txt?versionId="+url.QueryEscape(first.VersionID)), "addressed version")
+			assert.Empty(t, countHeader(method, "/"+bucketName+"/untagged.txt"), "untagged objects omit the header")
+		})
+	}
+}
+
 func TestRestoreObjectHonorsVersionID(t *testing.T) {
 	env := setupCompleteS3Environment(t)
 	defer env.cleanup()
@@ -553,7 +553,7 @@ func (h *Handler) SelectObjectContent(w http.ResponseWriter, r *http.Request) {
 
 	// ── Fetch object data ────────────────────────────────────────────────────
 
-	_, reader, err := h.objectManager.GetObject(r.Context(), bucketPath, objectKey)
+	obj, reader, err := h.objectManager.GetObject(r.Context(), bucketPath, objectKey)
 	if err != nil {
 		if err == object.ErrObjectNotFound {
 			h.writeError(w, "NoSuchKey", "The specified key does not exist", objectKey, r)
@@ -563,6 +563,9 @@ func (h *Handler) SelectObjectContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer reader.Close()
+	if !h.checkArchivedObjectReadable(w, r, obj, bucketPath, objectKey, "", objectKey) {
+		return
+	}
 
 	// ── Load into in-memory SQLite ───────────────────────────────────────────
 
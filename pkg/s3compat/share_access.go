@@ -0,0 +1,47 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/maxiofs/maxiofs/internal/middleware"
+	"github.com/maxiofs/maxiofs/internal/share"
+	"github.com/sirupsen/logrus"
+)
+
+// shareAccessRecorder is implemented by share managers that keep an access
+// log of anonymous downloads, see recordShareAccess.
+type shareAccessRecorder interface {
+	RecordAccess(ctx context.Context, access *share.Access) error
+}
+
+// countingResponseWriter counts the body bytes written to a response.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+func (cw *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := cw.ResponseWriter.Write(p)
+	cw.written += int64(n)
+	return n, err
+}
+
+// recordShareAccess adds a download of bytes served through s to the share's
+// access log. Failing to record it is logged and does not fail the download.
+func (h *Handler) recordShareAccess(r *http.Request, s *share.Share, bytes int64) {
+	recorder, ok := h.shareManager.(shareAccessRecorder)
+	if !ok {
+		return
+	}
+	access := &share.Access{
+		ShareID: s.ID,
+		IP:      middleware.ClientIP(r, h.trustedProxies),
+		Bytes:   bytes,
+		Referer: r.Header.Get("Referer"),
+	}
+	// The client may already have gone away; the download still counts
+	if err := recorder.RecordAccess(context.WithoutCancel(r.Context()), access); err != nil {
+		logrus.WithError(err).WithField("shareID", s.ID).Warn("Failed to record share access")
+	}
+}
@@ -0,0 +1,77 @@
+package s3compat
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/share"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetObject_ShareHotlinkProtection tests that a share with a referer
+// allowlist refuses downloads linked from other sites, and that each
+// download it serves is counted in its access log
+func TestGetObject_ShareHotlinkProtection(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "shares.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	store, err := share.NewSQLiteStore(db, "")
+	require.NoError(t, err)
+	shares := share.NewManager(store)
+	env.handler.SetShareManager(shareLookup{shares})
+
+	ctx := context.Background()
+	bucketName := "media"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/logo.png", []byte("PNGDATA"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	created, err := shares.CreateShare(ctx, bucketName, "logo.png", env.tenantID, env.accessKey, "", env.userID, nil, share.ResponseOverrides{}, []string{"example.com"})
+	require.NoError(t, err)
+
+	download := func(referer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/"+bucketName+"/logo.png", nil)
+		req.RemoteAddr = "203.0.113.7:51000"
+		if referer != "" {
+			req.Header.Set("Referer", referer)
+		}
+		w := httptest.NewRecorder()
+		env.router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, referer := range []string{"https://evil.net/page", ""} {
+		w := download(referer)
+		assert.Equal(t, http.StatusForbidden, w.Code, "referer %q", referer)
+		assert.Contains(t, w.Body.String(), "AccessDenied")
+	}
+
+	w = download("https://example.com/gallery")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "PNGDATA", w.Body.String())
+
+	// Authenticated reads are not share downloads and are not counted
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/logo.png", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	stored, err := shares.GetShare(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stored.AccessCount)
+
+	accesses, err := shares.ListAccesses(ctx, created.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, accesses, 1)
+	assert.Equal(t, "203.0.113.7", accesses[0].IP)
+	assert.Equal(t, int64(len("PNGDATA")), accesses[0].Bytes)
+	assert.Equal(t, "https://example.com/gallery", accesses[0].Referer)
+}
@@ -0,0 +1,67 @@
+package s3compat
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxiofs/maxiofs/internal/share"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetObject_ShareResponseOverrides tests that an object served through a
+// share gets the Content-Disposition and Content-Type chosen for the share,
+// while authenticated reads keep the object's own headers
+func TestGetObject_ShareResponseOverrides(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "shares.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	store, err := share.NewSQLiteStore(db, "")
+	require.NoError(t, err)
+	shares := share.NewManager(store)
+	env.handler.SetShareManager(shareLookup{shares})
+
+	ctx := context.Background()
+	bucketName := "reports"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/a1b2c3.pdf", []byte("%PDF-1.7"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	_, err = shares.CreateShare(ctx, bucketName, "a1b2c3.pdf", env.tenantID, env.accessKey, "", env.userID, nil, share.ResponseOverrides{
+		ContentDisposition: `attachment; filename="Report 2024.pdf"`,
+		ContentType:        "application/pdf",
+	}, nil)
+	require.NoError(t, err)
+
+	req = httptest.NewRequest("GET", "/"+bucketName+"/a1b2c3.pdf", nil)
+	w = httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `attachment; filename="Report 2024.pdf"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, "%PDF-1.7", w.Body.String())
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/a1b2c3.pdf", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+}
+
+// shareLookup adapts share.Manager to the handler's share manager interface
+type shareLookup struct {
+	share.Manager
+}
+
+func (s shareLookup) GetShareByObject(ctx context.Context, bucketName, objectKey, tenantID string) (interface{}, error) {
+	return s.Manager.GetShareByObject(ctx, bucketName, objectKey, tenantID)
+}
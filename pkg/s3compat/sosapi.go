@@ -60,10 +60,14 @@ type CapacityInfo struct {
 // - .system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c/system.xml (root)
 // - salva/.system-d26a9498-cb7c-4a87-a44a-8ae204f5ba6c/system.xml (in folder)
 func isVeeamSOSAPIObject(objectKey string) bool {
-	return strings.HasSuffix(objectKey, systemXMLObject) ||
-		strings.HasSuffix(objectKey, capacityXMLObject) ||
-		objectKey == systemXMLObject ||
-		objectKey == capacityXMLObject
+	return isSOSAPIFile(objectKey, systemXMLObject) || isSOSAPIFile(objectKey, capacityXMLObject)
+}
+
+// isSOSAPIFile reports whether objectKey is the SOSAPI file name at the bucket
+// root or under a folder. The match starts at a path segment, the same rule
+// the object manager uses to refuse client writes to reserved prefixes.
+func isSOSAPIFile(objectKey, name string) bool {
+	return objectKey == name || strings.HasSuffix(objectKey, "/"+name)
 }
 
 // isVeeamClient checks if the User-Agent indicates a VEEAM client
@@ -135,7 +139,7 @@ func generateCapacityXML(totalCapacity, availableCapacity int64) ([]byte, error)
 // quota can be reported as the advertised capacity.
 func (h *Handler) getSOSAPIVirtualObject(ctx context.Context, bucketName, tenantID, objectKey string) ([]byte, string, error) {
 	// Check if it's a system.xml file (with or without prefix path)
-	if strings.HasSuffix(objectKey, systemXMLObject) || objectKey == systemXMLObject {
+	if isSOSAPIFile(objectKey, systemXMLObject) {
 		data, err := generateSystemXML()
 		if err != nil {
 			return nil, "", err
@@ -144,7 +148,7 @@ func (h *Handler) getSOSAPIVirtualObject(ctx context.Context, bucketName, tenant
 	}
 
 	// Check if it's a capacity.xml file (with or without prefix path)
-	if strings.HasSuffix(objectKey, capacityXMLObject) || objectKey == capacityXMLObject {
+	if isSOSAPIFile(objectKey, capacityXMLObject) {
 		totalCapacity := int64(1024 * 1024 * 1024 * 1024)    // Default: 1TB
 		availableCapacity := int64(900 * 1024 * 1024 * 1024) // Default: 900GB
 
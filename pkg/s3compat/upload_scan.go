@@ -0,0 +1,29 @@
+package s3compat
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/maxiofs/maxiofs/internal/object"
+)
+
+// uploadScanRetryAfter is the Retry-After of an upload refused because the
+// upload scanner couldn't judge it.
+const uploadScanRetryAfter = "30"
+
+// writeUploadScanError answers a write the upload scanner refused: 403
+// AccessDenied with the scanner's reason for a rejected upload, 503 for one
+// it couldn't judge. It reports whether err was such an error.
+func (h *Handler) writeUploadScanError(w http.ResponseWriter, r *http.Request, err error, resource string) bool {
+	var rejected *object.UploadRejectedError
+	switch {
+	case errors.As(err, &rejected):
+		h.writeError(w, "AccessDenied", rejected.Error(), resource, r)
+	case errors.Is(err, object.ErrUploadScannerUnavailable):
+		w.Header().Set("Retry-After", uploadScanRetryAfter)
+		h.writeError(w, "ServiceUnavailable", "The upload scanner is unavailable, retry later", resource, r)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package s3compat
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserMetadataRoundTrip checks x-amz-meta-* headers come back the way AWS
+// returns them: lowercase names and repeated headers joined with ",".
+func TestUserMetadataRoundTrip(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	ctx := context.Background()
+	bucketName := "meta-bucket"
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, bucketName, ""))
+
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/doc.txt", []byte("hello"))
+	req.Header["x-amz-meta-MyKey"] = []string{"Mixed Value"}
+	req.Header.Add("X-Amz-Meta-Color", "red")
+	req.Header.Add("X-Amz-Meta-Color", "green")
+	// Spelled differently on the wire, but the same S3 metadata name
+	req.Header["x-amz-meta-COLOR"] = []string{"blue"}
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assertUserMetadata := func(t *testing.T, h http.Header) {
+		t.Helper()
+		assert.Equal(t, []string{"Mixed Value"}, h["x-amz-meta-mykey"])
+		assert.Equal(t, []string{"red,green,blue"}, h["x-amz-meta-color"])
+		// Not canonicalized into X-Amz-Meta-Mykey
+		assert.NotContains(t, h, "X-Amz-Meta-Mykey")
+		assert.NotContains(t, h, "X-Amz-Meta-Color")
+	}
+
+	for _, method := range []string{"GET", "HEAD"} {
+		t.Run(method, func(t *testing.T) {
+			req, w := env.makeS3Request(method, "/"+bucketName+"/doc.txt", nil)
+			env.router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+			assertUserMetadata(t, w.Header())
+		})
+	}
+
+	t.Run("copy keeps metadata", func(t *testing.T) {
+		req, w := env.makeS3Request("PUT", "/"+bucketName+"/copy.txt", nil)
+		req.Header.Set("x-amz-copy-source", "/"+bucketName+"/doc.txt")
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		req, w = env.makeS3Request("HEAD", "/"+bucketName+"/copy.txt", nil)
+		env.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assertUserMetadata(t, w.Header())
+	})
+}
@@ -0,0 +1,116 @@
+package s3compat
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setBucketVersioning sets the bucket's versioning status through the S3 API.
+func setBucketVersioning(t *testing.T, env *s3TestEnv, bucketName, status string) {
+	t.Helper()
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"?versioning",
+		[]byte(`<VersioningConfiguration><Status>`+status+`</Status></VersioningConfiguration>`))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+// getVersionBody GETs one version of an object and returns its body.
+func getVersionBody(t *testing.T, env *s3TestEnv, bucketName, key, versionID string) string {
+	t.Helper()
+	req, w := env.makeS3Request("GET", "/"+bucketName+"/"+key+"?versionId="+versionID, nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return w.Body.String()
+}
+
+func TestVersionIDHeaders_VersionedBucket(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+
+	bucketName := "version-headers"
+	require.NoError(t, env.bucketManager.CreateBucket(context.Background(), env.tenantID, bucketName, ""))
+	setBucketVersioning(t, env, bucketName, "Enabled")
+
+	// PutObject
+	req, w := env.makeS3Request("PUT", "/"+bucketName+"/doc.txt", []byte("first"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	firstVersion := w.Header().Get("x-amz-version-id")
+	require.NotEmpty(t, firstVersion)
+
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/doc.txt", []byte("second"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEqual(t, firstVersion, w.Header().Get("x-amz-version-id"))
+	assert.Equal(t, "first", getVersionBody(t, env, bucketName, "doc.txt", firstVersion))
+
+	// CopyObject
+	req, w = env.makeS3Request("PUT", "/"+bucketName+"/copy.txt", nil)
+	req.Header.Set("x-amz-copy-source", "/"+bucketName+"/doc.txt?versionId="+firstVersion)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	copyVersion := w.Header().Get("x-amz-version-id")
+	require.NotEmpty(t, copyVersion)
+	assert.Equal(t, "first", getVersionBody(t, env, bucketName, "copy.txt", copyVersion))
+
+	// CompleteMultipartUpload
+	req, w = env.makeS3Request("POST", "/"+bucketName+"/multi.bin?uploads", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var created struct {
+		UploadId string `xml:"UploadId"`
+	}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &created))
+	req, w = env.makeS3Request("PUT", fmt.Sprintf("/%s/multi.bin?partNumber=1&uploadId=%s", bucketName, created.UploadId), []byte("multipart body"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	completeXML := fmt.Sprintf(`<CompleteMultipartUpload><Part><PartNumber>1</PartNumber><ETag>%s</ETag></Part></CompleteMultipartUpload>`, w.Header().Get("ETag"))
+	req, w = env.makeS3Request("POST", fmt.Sprintf("/%s/multi.bin?uploadId=%s", bucketName, created.UploadId), []byte(completeXML))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "<Error>")
+	multipartVersion := w.Header().Get("x-amz-version-id")
+	require.NotEmpty(t, multipartVersion)
+	assert.Equal(t, "multipart body", getVersionBody(t, env, bucketName, "multi.bin", multipartVersion))
+
+	// DeleteObject creates a delete marker and reports its version ID
+	req, w = env.makeS3Request("DELETE", "/"+bucketName+"/doc.txt", nil)
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "true", w.Header().Get("x-amz-delete-marker"))
+	markerVersion := w.Header().Get("x-amz-version-id")
+	require.NotEmpty(t, markerVersion)
+
+	req, w = env.makeS3Request("GET", "/"+bucketName+"/doc.txt?versionId="+markerVersion, nil)
+	env.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code, "a delete marker version is not an object")
+	assert.Equal(t, "first", getVersionBody(t, env, bucketName, "doc.txt", firstVersion))
+}
+
+func TestVersionIDHeaders_SuspendedAndUnversionedBuckets(t *testing.T) {
+	env := setupCompleteS3Environment(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, "never-versioned", ""))
+	req, w := env.makeS3Request("PUT", "/never-versioned/doc.txt", []byte("plain"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("x-amz-version-id"), "unversioned buckets report no version ID")
+
+	require.NoError(t, env.bucketManager.CreateBucket(ctx, env.tenantID, "suspended", ""))
+	setBucketVersioning(t, env, "suspended", "Enabled")
+	setBucketVersioning(t, env, "suspended", "Suspended")
+
+	req, w = env.makeS3Request("PUT", "/suspended/doc.txt", []byte("null version"))
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "null", w.Header().Get("x-amz-version-id"))
+	assert.Equal(t, "null version", getVersionBody(t, env, "suspended", "doc.txt", "null"))
+}
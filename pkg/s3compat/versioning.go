@@ -89,9 +89,19 @@ func (h *Handler) ListBucketVersions(w http.ResponseWriter, r *http.Request) {
 		return s
 	}
 
+	listTag, notModified := h.listETag(w, r, bucketPath)
+	if notModified {
+		return
+	}
+
 	// Get all versions directly from metadata (don't rely on ListObjects which excludes deleted objects)
-	allObjectVersions, err := h.metadataStore.ListAllObjectVersions(r.Context(), bucketPath, prefix, maxKeys*10)
+	listCtx, cancel := h.listContext(r)
+	defer cancel()
+	allObjectVersions, err := h.metadataStore.ListAllObjectVersions(listCtx, bucketPath, prefix, maxKeys*10)
 	if err != nil {
+		if h.writeListAborted(w, r, err, bucketName) {
+			return
+		}
 		h.writeError(w, "InternalError", err.Error(), bucketName, r)
 		return
 	}
@@ -205,5 +215,8 @@ func (h *Handler) ListBucketVersions(w http.ResponseWriter, r *http.Request) {
 		DeleteMarkers:       allDeleteMarkers,
 	}
 
+	if listTag != "" {
+		w.Header().Set("ETag", listTag)
+	}
 	h.writeXMLResponse(w, http.StatusOK, result)
 }
@@ -25,6 +25,7 @@ import type {
   EditUserForm,
   APIError,
   AccessKey,
+  AccessKeyInfo,
   Tenant,
   CreateTenantRequest,
   UpdateTenantRequest,
@@ -540,6 +541,8 @@ export class APIClient {
     const payload = {
       username: credentials.username,
       password: credentials.password,
+      ...(credentials.new_password ? { new_password: credentials.new_password } : {}),
+      ...(credentials.totp_code ? { totp_code: credentials.totp_code } : {}),
     };
 
     try {
@@ -578,6 +581,15 @@ export class APIClient {
           sso_hint: true,
         };
       }
+      // An expired password is replaced by logging in again with new_password
+      if (isErrorWithResponse(err) && err.response?.data?.password_expired) {
+        return {
+          success: false,
+          error: err.response.data.error,
+          password_expired: true,
+          requires_2fa: err.response.data.requires_2fa,
+        };
+      }
       throw err;
     }
   }
@@ -653,8 +665,14 @@ export class APIClient {
     return response.data.data || [];
   }
 
-  static async createAccessKey(keyData: { userId: string }): Promise<AccessKey> {
-    const response = await apiClient.post<APIResponse<any>>(`/users/${keyData.userId}/access-keys`);
+  static async createAccessKey(keyData: { userId: string } & AccessKeyInfo): Promise<AccessKey> {
+    const { userId, ...info } = keyData;
+    const response = await apiClient.post<APIResponse<any>>(`/users/${userId}/access-keys`, info);
+    return response.data.data!;
+  }
+
+  static async updateAccessKey(userId: string, keyId: string, info: AccessKeyInfo): Promise<AccessKey> {
+    const response = await apiClient.put<APIResponse<AccessKey>>(`/users/${userId}/access-keys/${keyId}`, info);
     return response.data.data!;
   }
 
@@ -752,6 +770,9 @@ export class APIClient {
     if (request.maxKeys) params.append('max_keys', request.maxKeys.toString());
     if (request.continuationToken) params.append('marker', request.continuationToken);
     if (request.tenantId) params.append('tenantId', request.tenantId);
+    if (request.hideFolderMarkers) params.append('hideFolderMarkers', 'true');
+    if (request.foldersOnly) params.append('foldersOnly', 'true');
+    if (request.includeMetadata) params.append('includeMetadata', 'true');
 
     const response = await apiClient.get<APIResponse<ListObjectsResponse>>(
       `/buckets/${request.bucket}/objects?${params.toString()}`
@@ -941,13 +962,19 @@ export class APIClient {
     await apiClient.delete(url);
   }
 
-  static async shareObject(bucket: string, key: string, expiresIn: number | null = 3600, tenantId?: string): Promise<{ id: string; url: string; expiresAt?: string; createdAt: string; isExpired: boolean; existing: boolean }> {
+  static async shareObject(
+    bucket: string,
+    key: string,
+    expiresIn: number | null = 3600,
+    tenantId?: string,
+    overrides?: { contentDisposition?: string; contentType?: string; allowedReferers?: string[] },
+  ): Promise<{ id: string; url: string; expiresAt?: string; createdAt: string; isExpired: boolean; existing: boolean; contentDisposition?: string; contentType?: string; allowedReferers?: string[]; accessCount: number }> {
     const url = tenantId 
       ? `/buckets/${bucket}/objects/${encodeURIComponent(key)}/share?tenantId=${encodeURIComponent(tenantId)}`
       : `/buckets/${bucket}/objects/${encodeURIComponent(key)}/share`;
-    const response = await apiClient.post<APIResponse<{ id: string; url: string; expiresAt?: string; createdAt: string; isExpired: boolean; existing: boolean }>>(
+    const response = await apiClient.post<APIResponse<{ id: string; url: string; expiresAt?: string; createdAt: string; isExpired: boolean; existing: boolean; contentDisposition?: string; contentType?: string; allowedReferers?: string[]; accessCount: number }>>(
       url,
-      { expiresIn }
+      { expiresIn, ...overrides }
     );
     return response.data.data!;
   }
@@ -960,6 +987,22 @@ export class APIClient {
     return response.data.data || {};
   }
 
+  static async getShareAccessLog(
+    bucket: string,
+    shareId: string,
+    limit?: number,
+    tenantId?: string,
+  ): Promise<{ shareId: string; objectKey: string; accessCount: number; accesses: { shareId: string; ip: string; bytes: number; referer?: string; accessedAt: string }[] }> {
+    const params = new URLSearchParams();
+    if (limit) params.set('limit', limit.toString());
+    if (tenantId) params.set('tenantId', tenantId);
+    const query = params.toString();
+    const response = await apiClient.get<APIResponse<{ shareId: string; objectKey: string; accessCount: number; accesses: { shareId: string; ip: string; bytes: number; referer?: string; accessedAt: string }[] }>>(
+      `/buckets/${bucket}/shares/${encodeURIComponent(shareId)}/access-log${query ? `?${query}` : ''}`
+    );
+    return response.data.data!;
+  }
+
   static async deleteShare(bucket: string, key: string, tenantId?: string): Promise<void> {
     const url = tenantId
       ? `/buckets/${bucket}/objects/${encodeURIComponent(key)}/share?tenantId=${encodeURIComponent(tenantId)}`
@@ -1742,6 +1785,11 @@ export class APIClient {
     return response.data.data!;
   }
 
+  static async rotateTenantEncryptionKey(tenantId: string): Promise<{ tenantId: string; newVersion: number }> {
+    const response = await apiClient.post<APIResponse<{ tenantId: string; newVersion: number }>>(`/tenants/${tenantId}/encryption/rotate-key`, {});
+    return response.data.data!;
+  }
+
   static async runEncryptionWorker(): Promise<{ started: boolean; reason?: string }> {
     const response = await apiClient.post<APIResponse<{ started: boolean; reason?: string }>>('/settings/encryption/worker-run', {});
     return response.data.data!;
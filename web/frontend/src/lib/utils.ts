@@ -91,7 +91,7 @@ function isErrorWithMessage(err: unknown): err is { message: string } {
 
 export function isErrorWithResponse(err: unknown): err is {
   message?: string;
-  response?: { status?: number; data?: { error?: string; Error?: string; sso_hint?: boolean } };
+  response?: { status?: number; data?: { error?: string; Error?: string; sso_hint?: boolean; password_expired?: boolean; requires_2fa?: boolean } };
 } {
   return typeof err === 'object' && err !== null && 'response' in err;
 }
@@ -434,6 +434,7 @@ export default function AuditLogsPage() {
                 <option value="bucket_deleted">{t('bucketDeleted')}</option>
                 <option value="access_key_created">{t('accessKeyCreated')}</option>
                 <option value="access_key_deleted">{t('accessKeyDeleted')}</option>
+                <option value="access_key_updated">{t('accessKeyUpdated')}</option>
                 <option value="tenant_created">{t('tenantCreated')}</option>
                 <option value="tenant_deleted">{t('tenantDeleted')}</option>
                 <option value="tenant_updated">{t('tenantUpdated')}</option>
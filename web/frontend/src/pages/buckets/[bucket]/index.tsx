@@ -165,6 +165,8 @@ export default function BucketDetailsPage() {
           ...(tenantId && { tenantId }),
           prefix: currentPrefix,
           delimiter: '/',
+          // The object details view shows the user metadata from the row
+          includeMetadata: true,
         }),
   });
 
@@ -22,10 +22,15 @@ export default function LoginPage() {
   const [formData, setFormData] = useState({
     username: '',
     password: '',
+    newPassword: '',
+    totpCode: '',
   });
   const [focusedField, setFocusedField] = useState<string | null>(null);
   const [show2FA, setShow2FA] = useState(false);
   const [userId, setUserId] = useState<string | null>(null);
+  const [passwordExpired, setPasswordExpired] = useState(false);
+  // With 2FA, an expired password is only replaced along with a TOTP code
+  const [passwordExpired2FA, setPasswordExpired2FA] = useState(false);
 
   // Get version from public endpoint (no auth required)
   const { data: versionData } = useQuery({
@@ -52,8 +57,19 @@ export default function LoginPage() {
       const response = await APIClient.login({
         username: formData.username,
         password: formData.password,
+        new_password: passwordExpired ? formData.newPassword : undefined,
+        totp_code: passwordExpired2FA ? formData.totpCode : undefined,
       });
 
+      // An expired password must be replaced before signing in
+      if (response.password_expired) {
+        setPasswordExpired(true);
+        setPasswordExpired2FA(!!response.requires_2fa);
+        setError(t('passwordExpired'));
+        setLoading(false);
+        return;
+      }
+
       // Check if 2FA is required
       if (response.requires_2fa && response.user_id) {
         setUserId(response.user_id);
@@ -402,6 +418,81 @@ export default function LoginPage() {
                       </div>
                     </div>
 
+                    {/* New Password Input (expired password) */}
+                    {passwordExpired && (
+                      <div className="relative">
+                        <div className="relative">
+                          <svg
+                            className="absolute left-0 top-5 h-6 w-6 transition-colors duration-200"
+                            style={{
+                              color: focusedField === 'newPassword' || formData.newPassword ? '#2563eb' : '#9ca3af'
+                            }}
+                            fill="none"
+                            viewBox="0 0 24 24"
+                            stroke="currentColor"
+                          >
+                            <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M12 15v2m-6 4h12a2 2 0 002-2v-6a2 2 0 00-2-2H6a2 2 0 00-2 2v6a2 2 0 002 2zm10-10V7a4 4 0 00-8 0v4h8z" />
+                          </svg>
+                          <input
+                            id="newPassword"
+                            name="newPassword"
+                            type="password"
+                            required
+                            className="peer w-full pl-8 pr-4 py-3 pt-6 pb-2 border-b-2 border-gray-300 dark:border-gray-500 bg-transparent text-foreground placeholder-transparent focus:outline-none focus:border-blue-600 dark:focus:border-blue-400 transition-colors duration-200"
+                            placeholder={t('newPassword')}
+                            value={formData.newPassword}
+                            onChange={handleChange}
+                            onFocus={() => handleFocus('newPassword')}
+                            onBlur={(e) => handleBlur('newPassword', e.target.value)}
+                            disabled={loading}
+                          />
+                          <label
+                            htmlFor="newPassword"
+                            className="absolute left-8 text-sm font-bold transition-all duration-200 pointer-events-none"
+                            style={{
+                              top: focusedField === 'newPassword' || formData.newPassword ? '0' : '1.25rem',
+                              fontSize: focusedField === 'newPassword' || formData.newPassword ? '0.75rem' : '1rem',
+                              color: focusedField === 'newPassword' || formData.newPassword ? '#2563eb' : '#9ca3af'
+                            }}
+                          >
+                            {t('newPassword')}
+                          </label>
+                        </div>
+                      </div>
+                    )}
+
+                    {/* TOTP code sent with the new password (expired password, 2FA) */}
+                    {passwordExpired && passwordExpired2FA && (
+                      <div className="relative">
+                        <input
+                          id="totpCode"
+                          name="totpCode"
+                          type="text"
+                          inputMode="numeric"
+                          autoComplete="one-time-code"
+                          required
+                          className="peer w-full pl-8 pr-4 py-3 pt-6 pb-2 border-b-2 border-gray-300 dark:border-gray-500 bg-transparent text-foreground placeholder-transparent focus:outline-none focus:border-blue-600 dark:focus:border-blue-400 transition-colors duration-200"
+                          placeholder={t('twoFactorCode')}
+                          value={formData.totpCode}
+                          onChange={handleChange}
+                          onFocus={() => handleFocus('totpCode')}
+                          onBlur={(e) => handleBlur('totpCode', e.target.value)}
+                          disabled={loading}
+                        />
+                        <label
+                          htmlFor="totpCode"
+                          className="absolute left-8 text-sm font-bold transition-all duration-200 pointer-events-none"
+                          style={{
+                            top: focusedField === 'totpCode' || formData.totpCode ? '0' : '1.25rem',
+                            fontSize: focusedField === 'totpCode' || formData.totpCode ? '0.75rem' : '1rem',
+                            color: focusedField === 'totpCode' || formData.totpCode ? '#2563eb' : '#9ca3af'
+                          }}
+                        >
+                          {t('twoFactorCode')}
+                        </label>
+                      </div>
+                    )}
+
                     {/* Submit Button */}
                     <div className="pt-4">
                       <button
@@ -44,6 +44,19 @@ import { ConfirmModal } from '@/components/ui/Modal';
 import { EmptyState } from '@/components/ui/EmptyState';
 import { escapeHtml } from '@/lib/utils';
 
+// parseKeyTags turns "team=ops, env=prod" into a tag map; entries without a
+// key are dropped.
+const parseKeyTags = (input: string): Record<string, string> | undefined => {
+  const tags: Record<string, string> = {};
+  for (const entry of input.split(',')) {
+    const [key, ...rest] = entry.split('=');
+    if (key.trim()) {
+      tags[key.trim()] = rest.join('=').trim();
+    }
+  }
+  return Object.keys(tags).length > 0 ? tags : undefined;
+};
+
 export default function UserDetailsPage() {
   const { t } = useTranslation('users');
   const { user } = useParams<{ user: string }>();
@@ -58,7 +71,9 @@ export default function UserDetailsPage() {
     status: 'active',
     tenantId: undefined,
   });
-  const [_newKeyName, setNewKeyName] = useState('');
+  const [newKeyName, setNewKeyName] = useState('');
+  const [newKeyDescription, setNewKeyDescription] = useState('');
+  const [newKeyTags, setNewKeyTags] = useState('');
   const [_showSecretKeys, _setShowSecretKeys] = useState<Record<string, boolean>>({});
   const [createdKey, setCreatedKey] = useState<AccessKey | null>(null);
   const [isChangePasswordOpen, setIsChangePasswordOpen] = useState(false);
@@ -215,7 +230,13 @@ export default function UserDetailsPage() {
 
   // Create access key mutation
   const createAccessKeyMutation = useMutation({
-    mutationFn: () => APIClient.createAccessKey({ userId }),
+    mutationFn: () =>
+      APIClient.createAccessKey({
+        userId,
+        name: newKeyName.trim() || undefined,
+        description: newKeyDescription.trim() || undefined,
+        tags: parseKeyTags(newKeyTags),
+      }),
     onSuccess: (response) => {
       // Refetch to update immediately
       queryClient.refetchQueries({ queryKey: ['accessKeys', userId] }); // Update user's keys
@@ -225,6 +246,8 @@ export default function UserDetailsPage() {
       setCreatedKey(response);
       setIsCreateKeyModalOpen(false);
       setNewKeyName('');
+      setNewKeyDescription('');
+      setNewKeyTags('');
       ModalManager.toast('success', t('accessKeyCreatedSuccess'));
     },
     onError: (error) => {
@@ -997,6 +1020,7 @@ export default function UserDetailsPage() {
               <TableHeader>
                 <TableRow>
                   <TableHead>{t('accessKeyId')}</TableHead>
+                  <TableHead>{t('accessKeyName')}</TableHead>
                   <TableHead>{t('status')}</TableHead>
                   <TableHead>{t('created')}</TableHead>
                   <TableHead>{t('lastTimeUsed')}</TableHead>
@@ -1020,6 +1044,23 @@ export default function UserDetailsPage() {
                         </Button>
                       </div>
                     </TableCell>
+                    <TableCell>
+                      <div className="text-sm text-foreground" title={key.description || undefined}>
+                        {key.name || <span className="text-muted-foreground">—</span>}
+                      </div>
+                      {key.tags && Object.keys(key.tags).length > 0 && (
+                        <div className="flex flex-wrap gap-1 mt-1">
+                          {Object.entries(key.tags).map(([tagKey, tagValue]) => (
+                            <span
+                              key={tagKey}
+                              className="text-xs bg-gray-100 dark:bg-gray-800 text-muted-foreground px-1.5 py-0.5 rounded"
+                            >
+                              {tagKey}={tagValue}
+                            </span>
+                          ))}
+                        </div>
+                      )}
+                    </TableCell>
                     <TableCell>
                       <span className={`inline-flex items-center px-2 py-1 rounded-full text-xs font-medium border ${
                         key.status === 'active'
@@ -1048,7 +1089,7 @@ export default function UserDetailsPage() {
                         <Button
                           variant="ghost"
                           size="sm"
-                          onClick={() => handleDeleteAccessKey(key.id, key.id)}
+                          onClick={() => handleDeleteAccessKey(key.id, key.name ? `${key.name} (${key.id})` : key.id)}
                           title={t('deleteAccessKey')}
                         >
                           <Trash2 className="h-4 w-4" />
@@ -1198,6 +1239,35 @@ export default function UserDetailsPage() {
             </p>
           </div>
 
+          <div>
+            <label className="text-sm font-medium text-foreground mb-2 block">{t('accessKeyName')}</label>
+            <Input
+              value={newKeyName}
+              maxLength={64}
+              placeholder={t('accessKeyNamePlaceholder')}
+              onChange={(e) => setNewKeyName(e.target.value)}
+              className="bg-card text-foreground border-border"
+            />
+          </div>
+          <div>
+            <label className="text-sm font-medium text-foreground mb-2 block">{t('accessKeyDescription')}</label>
+            <Input
+              value={newKeyDescription}
+              maxLength={256}
+              onChange={(e) => setNewKeyDescription(e.target.value)}
+              className="bg-card text-foreground border-border"
+            />
+          </div>
+          <div>
+            <label className="text-sm font-medium text-foreground mb-2 block">{t('accessKeyTags')}</label>
+            <Input
+              value={newKeyTags}
+              placeholder="team=ops, env=prod"
+              onChange={(e) => setNewKeyTags(e.target.value)}
+              className="bg-card text-foreground border-border"
+            />
+          </div>
+
           <div className="flex justify-end space-x-2 pt-4">
             <Button
               type="button"
@@ -37,6 +37,15 @@ export interface AccessKey {
   permissions: string[];
   createdAt: string | number;
   lastUsed?: string | number;
+  name?: string;
+  description?: string;
+  tags?: Record<string, string>;
+}
+
+export interface AccessKeyInfo {
+  name?: string;
+  description?: string;
+  tags?: Record<string, string>;
 }
 
 export interface AuthToken {
@@ -50,6 +59,8 @@ export interface LoginRequest {
   username: string;
   password: string;
   rememberMe?: boolean;
+  new_password?: string;
+  totp_code?: string;
 }
 
 export interface LoginResponse {
@@ -63,6 +74,7 @@ export interface LoginResponse {
   message?: string;
   default_password?: boolean;
   sso_hint?: boolean;
+  password_expired?: boolean;
 }
 
 export interface CreateUserRequest {
@@ -503,6 +515,9 @@ export interface ListObjectsRequest {
   continuationToken?: string;
   fetchOwner?: boolean;
   startAfter?: string;
+  hideFolderMarkers?: boolean; // omit zero-byte "folder/" marker objects
+  foldersOnly?: boolean;       // return only commonPrefixes, for tree navigation
+  includeMetadata?: boolean;   // embed each object's user metadata (max 1000 keys per page)
 }
 
 export interface ObjectSearchFilter {